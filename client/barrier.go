@@ -0,0 +1,168 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/fagongzi/util/protoc"
+	"go.uber.org/zap"
+
+	"github.com/matrixorigin/matrixcube/pb/metapb"
+	"github.com/matrixorigin/matrixcube/pb/rpcpb"
+	"github.com/matrixorigin/matrixcube/raftstore"
+)
+
+var (
+	// ErrBarrierShardsChanged the shards of the group are changed during the
+	// barrier, e.g. the shard is split, the barrier should be retried.
+	ErrBarrierShardsChanged = errors.New("shards of the group changed during the barrier")
+)
+
+// Barrier proposes a barrier entry to all the shards of the group, and returns
+// the raft log index of the barrier entry of each shard once all the shards have
+// applied it, shard id -> index. All the writes completed before calling Barrier
+// are applied on the shards at the returned indexes, it's a consistent point of
+// the group for the backups or the schema changes. `ErrBarrierShardsChanged` is
+// returned if the shards of the group are changed before all the barriers are
+// applied.
+func (s *client) Barrier(ctx context.Context, group uint64) (map[uint64]uint64, error) {
+	shards := s.groupShards(group)
+	if len(shards) == 0 {
+		return nil, fmt.Errorf("no shards of group %d", group)
+	}
+
+	resps, err := s.barrier(ctx, group, shards, rpcpb.BarrierRequest{})
+	if err != nil {
+		return nil, err
+	}
+	indexes := make(map[uint64]uint64, len(resps))
+	for id, resp := range resps {
+		indexes[id] = resp.Index
+	}
+
+	if !sameShards(shards, s.groupShards(group)) {
+		return nil, ErrBarrierShardsChanged
+	}
+	return indexes, nil
+}
+
+// Backup creates the backups of all the shards of the group in two phases. All
+// the shards are fenced by the barriers first, no write can be applied on any
+// shard of the group once fenced until all shards are unfenced, then the backups
+// of the fenced shards are created by another barrier, so the backups are a
+// consistent cut of the group and need no reconciliation across the shards when
+// restoring. The shards are unfenced once all the backups are created or
+// failed, if unfencing failed, the shards are unfenced by their leaders after
+// the fence timeout.
+func (s *client) Backup(ctx context.Context, group uint64, path string) (metapb.BackupManifest, error) {
+	shards := s.groupShards(group)
+	if len(shards) == 0 {
+		return metapb.BackupManifest{}, fmt.Errorf("no shards of group %d", group)
+	}
+
+	resps, err := s.fenceAndBackup(ctx, group, shards, path)
+	if _, e := s.barrier(ctx, group, shards, rpcpb.BarrierRequest{Unfence: true}); e != nil {
+		s.logger.Warn("failed to unfence the shards after backup, wait for fence timeout",
+			zap.Uint64("group", group),
+			zap.Error(e))
+	}
+	if err != nil {
+		return metapb.BackupManifest{}, err
+	}
+	if !sameShards(shards, s.groupShards(group)) {
+		return metapb.BackupManifest{}, ErrBarrierShardsChanged
+	}
+
+	manifest := metapb.BackupManifest{
+		Group:     group,
+		CreatedAt: time.Now().Unix(),
+	}
+	for _, shard := range shards {
+		resp := resps[shard.ID]
+		manifest.Shards = append(manifest.Shards, metapb.ShardBackup{
+			Shard: resp.Shard,
+			Index: resp.Index,
+			Path:  resp.BackupPath,
+		})
+	}
+	return manifest, nil
+}
+
+// fenceAndBackup fences all the shards, then backs up the fenced shards.
+func (s *client) fenceAndBackup(ctx context.Context, group uint64, shards []raftstore.Shard,
+	path string) (map[uint64]rpcpb.BarrierResponse, error) {
+	if _, err := s.barrier(ctx, group, shards, rpcpb.BarrierRequest{Fence: true}); err != nil {
+		return nil, err
+	}
+	return s.barrier(ctx, group, shards, rpcpb.BarrierRequest{BackupPath: path})
+}
+
+// barrier proposes the barrier request to the shards, and returns the response
+// of each shard once all shards have applied it, shard id -> response.
+func (s *client) barrier(ctx context.Context, group uint64, shards []raftstore.Shard,
+	req rpcpb.BarrierRequest) (map[uint64]rpcpb.BarrierResponse, error) {
+	payload := protoc.MustMarshal(&req)
+	futures := make([]*Future, 0, len(shards))
+	for _, shard := range shards {
+		futures = append(futures, s.exec(ctx, uint64(rpcpb.AdminBarrier), payload,
+			rpcpb.Admin, nil, WithShardGroup(group), WithShard(shard.ID)))
+	}
+
+	var err error
+	resps := make(map[uint64]rpcpb.BarrierResponse, len(shards))
+	for idx, f := range futures {
+		v, e := f.Get()
+		if e == nil {
+			resp := rpcpb.BarrierResponse{}
+			protoc.MustUnmarshal(&resp, v)
+			resps[shards[idx].ID] = resp
+		} else if err == nil {
+			err = fmt.Errorf("barrier of shard %d failed: %w", shards[idx].ID, e)
+		}
+		f.Close()
+	}
+	if err != nil {
+		return nil, err
+	}
+	return resps, nil
+}
+
+func (s *client) groupShards(group uint64) []raftstore.Shard {
+	var shards []raftstore.Shard
+	s.Router().ForeachShards(group, func(shard raftstore.Shard) bool {
+		shards = append(shards, shard)
+		return true
+	})
+	return shards
+}
+
+func sameShards(a, b []raftstore.Shard) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	epochs := make(map[uint64]uint64, len(a))
+	for _, shard := range a {
+		epochs[shard.ID] = shard.Epoch.Generation
+	}
+	for _, shard := range b {
+		if v, ok := epochs[shard.ID]; !ok || v != shard.Epoch.Generation {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,90 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+
+	"github.com/matrixorigin/matrixcube/pb/rpcpb"
+	"github.com/matrixorigin/matrixcube/util/uuid"
+)
+
+var (
+	// ErrEmptyWriteOps the batch write has no write operations.
+	ErrEmptyWriteOps = errors.New("batch write without write ops")
+)
+
+// WriteOp is a write operation of the `BatchWrite`, it's executed by the
+// executor like the write request of the `Write`.
+type WriteOp struct {
+	// RequestType the custom type of the write request
+	RequestType uint64
+	// Key the key written by the operation, all the keys of a batch write must
+	// be in the same shard
+	Key []byte
+	// Payload the payload of the write request
+	Payload []byte
+}
+
+// BatchWrite packs the write operations into one write request, the operations are
+// proposed in the same raft log entry and executed atomically by the shard. All
+// the keys of the operations must be in the same shard, the request is routed by
+// the key of the first operation unless the route is set by the opts, and is failed
+// with `raftstore.ErrKeysNotInShard` if the keys are not in the shard. Use
+// `Future.GetBatchWrite` to get the responses of the operations. The batch write
+// without operations is failed with `ErrEmptyWriteOps`.
+func (s *client) BatchWrite(ctx context.Context, ops []WriteOp, opts ...Option) *Future {
+	if len(ops) == 0 {
+		f := newFuture(ctx, rpcpb.Request{ID: uuid.NewV4().Bytes(), Type: rpcpb.Write}, nil)
+		f.done(f.req.ID, nil, ErrEmptyWriteOps)
+		return f
+	}
+
+	writeOps := make([]rpcpb.WriteOp, 0, len(ops))
+	from, to := ops[0].Key, ops[0].Key
+	for _, op := range ops {
+		writeOps = append(writeOps, rpcpb.WriteOp{
+			CustomType: op.RequestType,
+			Key:        op.Key,
+			Cmd:        op.Payload,
+		})
+		if bytes.Compare(op.Key, from) < 0 {
+			from = op.Key
+		}
+		if bytes.Compare(op.Key, to) > 0 {
+			to = op.Key
+		}
+	}
+
+	opts = append([]Option{withWriteOps(writeOps),
+		WithRouteKey(ops[0].Key),
+		WithKeysRange(from, keyAfter(to))}, opts...)
+	return s.exec(ctx, 0, nil, rpcpb.Write, nil, opts...)
+}
+
+func withWriteOps(ops []rpcpb.WriteOp) Option {
+	return func(c *Future) {
+		c.req.WriteOps = ops
+	}
+}
+
+// keyAfter returns the smallest key after the key, it's the exclusive end of
+// the keys range including the key.
+func keyAfter(key []byte) []byte {
+	next := make([]byte, len(key)+1)
+	copy(next, key)
+	return next
+}
@@ -14,16 +14,21 @@
 package client
 
 import (
+	"bytes"
 	"context"
+	"io"
 	"sync"
+	"time"
 
-	"github.com/fagongzi/util/hack"
 	"github.com/fagongzi/util/protoc"
 	"github.com/matrixorigin/matrixcube/components/log"
+	"github.com/matrixorigin/matrixcube/components/prophet"
+	"github.com/matrixorigin/matrixcube/metric"
 	"github.com/matrixorigin/matrixcube/pb/metapb"
 	"github.com/matrixorigin/matrixcube/pb/rpcpb"
 	"github.com/matrixorigin/matrixcube/pb/txnpb"
 	"github.com/matrixorigin/matrixcube/raftstore"
+	"github.com/matrixorigin/matrixcube/storage"
 	"github.com/matrixorigin/matrixcube/util/uuid"
 	"go.uber.org/zap"
 )
@@ -45,6 +50,15 @@ func WithRouteKey(key []byte) Option {
 	}
 }
 
+// WithPartition use the logical partition to route request, the shard group must be a
+// hash routing group. The route key of the request is the partition key returned by
+// `raftstore.EncodePartitionKey`.
+func WithPartition(partition uint64) Option {
+	return func(c *Future) {
+		c.req.Key = raftstore.EncodePartitionKey(partition)
+	}
+}
+
 // WithKeysRange If the current request operates on multiple Keys, set the range [from, to) of Keys
 // operated by the current request. The client needs to split the request again if it wants
 // to re-route according to KeysRange after the data management scope of the Shard has
@@ -62,39 +76,157 @@ func WithShard(shard uint64) Option {
 	}
 }
 
-// WithReplicaSelectPolicy set the ReplicaSelectPolicy for request, default is SelectLeader
+// WithReplicaSelectPolicy set the ReplicaSelectPolicy for request, default is SelectLeader.
+// The read requests routed to the followers by SelectRandom or SelectFollower are
+// served by the followers with a ReadIndex of the leader, as consistent as the reads
+// served by the leader.
 func WithReplicaSelectPolicy(policy rpcpb.ReplicaSelectPolicy) Option {
 	return func(f *Future) {
 		f.req.ReplicaSelectPolicy = policy
 	}
 }
 
-// Future is used to obtain response data synchronously.
+// WithMaxStaleness set the max staleness of the read request, the request can be
+// served by any replica whose resolved timestamp is within the staleness without
+// ReadIndex round trips. Use it with the SelectRandom ReplicaSelectPolicy to read
+// from followers. The staleness is rounded up to milliseconds, zero or negative
+// values disable the stale read.
+func WithMaxStaleness(staleness time.Duration) Option {
+	return func(f *Future) {
+		f.req.MaxStaleness = 0
+		if staleness > 0 {
+			f.req.MaxStaleness = uint64((staleness + time.Millisecond - 1) / time.Millisecond)
+		}
+	}
+}
+
+// WithReadSnapshot set the read snapshot of the read request, the request reads
+// the data in the read snapshot created by `CreateReadSnapshot` instead of the
+// current data. The route key of the request must be in the shard of the read
+// snapshot.
+func WithReadSnapshot(name string) Option {
+	return func(f *Future) {
+		f.req.ReadSnapshot = name
+	}
+}
+
+// WithMaxResponseBytes set the max size of the response of the read request, the
+// storage engine truncates the result exceeding it instead of failing the
+// request by the max body size of the transport. The truncated result is returned
+// with a PartialResultErr carrying the key to continue reading from. 0 means no
+// limit.
+func WithMaxResponseBytes(n uint64) Option {
+	return func(f *Future) {
+		f.req.MaxResponseBytes = n
+	}
+}
+
+// WithTenant set the tenant of the request, the custom requests to the shards with
+// the ACL are rejected with `raftstore.AccessDeniedErr` unless the tenant is allowed
+// by the ACL.
+func WithTenant(tenant string) Option {
+	return func(f *Future) {
+		f.req.Tenant = tenant
+	}
+}
+
+// WithDurability set the durability level the write request is acknowledged at,
+// the write is acknowledged once committed by the quorum by default. The write
+// with AllReplicasApplied waits for the unavailable replicas until the request
+// times out.
+func WithDurability(durability rpcpb.WriteDurability) Option {
+	return func(f *Future) {
+		f.req.Durability = durability
+	}
+}
+
+// PartialResultErr is an error indicates the result of the read request is
+// truncated to the max response bytes, the value returned with it is the partial
+// result.
+type PartialResultErr struct {
+	continuationKey []byte
+}
+
+// NewPartialResultErr returns a wrapped error that the result is truncated
+func NewPartialResultErr(continuationKey []byte) error {
+	return PartialResultErr{continuationKey: continuationKey}
+}
+
+// String implements error interface
+func (err PartialResultErr) Error() string {
+	return "partial result, the response exceeds the max response bytes"
+}
+
+// ContinuationKey returns the key to continue reading the rest of the result
+// from, it's set by the storage engine.
+func (err PartialResultErr) ContinuationKey() []byte {
+	return err.continuationKey
+}
+
+// IsPartialResultErr checks if an error is PartialResultErr
+func IsPartialResultErr(err error) bool {
+	_, ok := err.(PartialResultErr)
+	return ok
+}
+
+var futurePool = sync.Pool{
+	New: func() interface{} {
+		return &Future{c: make(chan struct{}, 1)}
+	},
+}
+
+// Future is used to obtain response data synchronously. The Futures are pooled,
+// so the `Future` must not be used after `Close` is called.
 type Future struct {
-	txnResponse txnpb.TxnBatchResponse
-	value       []byte
-	err         error
-	req         rpcpb.Request
-	ctx         context.Context
-	c           chan struct{}
+	txnResponse  txnpb.TxnBatchResponse
+	value        []byte
+	values       [][]byte
+	readValue    *storage.ReadValue
+	err          error
+	appliedIndex uint64
+	appliedTerm  uint64
+	req          rpcpb.Request
+	ctx          context.Context
+	c            chan struct{}
+	inflights    *inflightTable
+	start        time.Time
+	// shardStats and shard are set if the shard stats collector is enabled
+	shardStats *ShardStatsCollector
+	shard      uint64
 
 	mu struct {
 		sync.Mutex
-		closed bool
+		closed    bool
+		completed bool
 	}
 }
 
-func newFuture(ctx context.Context, req rpcpb.Request) *Future {
-	return &Future{
-		ctx: ctx,
-		req: req,
-		c:   make(chan struct{}, 1),
+// newFuture returns a pooled Future of the request with the options applied. The
+// late response or retry of the previous request of the Future may race with
+// the reuse, so the fields are set with the lock held.
+func newFuture(ctx context.Context, req rpcpb.Request, inflights *inflightTable, opts ...Option) *Future {
+	f := futurePool.Get().(*Future)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.ctx = ctx
+	f.req = req
+	f.inflights = inflights
+	f.start = time.Now()
+	f.mu.closed = false
+	f.mu.completed = false
+	for _, opt := range opts {
+		opt(f)
 	}
+	return f
 }
 
 // Get get the response data synchronously, blocking until `context.Done` or the response is received.
 // This method cannot be called more than once. After calling `Get`, `Close` must be called to close
-// `Future`.
+// `Future`. If the zero copy read is enabled, the returned value of the read request is owned
+// by the storage engine, and is only valid until `Close` is called. If the result of the read
+// request is truncated by `WithMaxResponseBytes`, the partial result is returned with a
+// `PartialResultErr`.
 func (f *Future) Get() ([]byte, error) {
 	select {
 	case <-f.ctx.Done():
@@ -116,13 +248,68 @@ func (f *Future) GetTxn() (txnpb.TxnBatchResponse, error) {
 	}
 }
 
-// Close close the future.
+// GetBatchWrite get the responses of the write operations of the `BatchWrite`
+// synchronously in the order of the operations, blocking until `context.Done` or
+// the response is received. This method cannot be called more than once. After
+// calling `GetBatchWrite`, `Close` must be called to close `Future`.
+func (f *Future) GetBatchWrite() ([][]byte, error) {
+	select {
+	case <-f.ctx.Done():
+		return nil, f.ctx.Err()
+	case <-f.c:
+		return f.values, f.err
+	}
+}
+
+// AppliedIndexTerm returns the applied index and term of the shard replica
+// served the request, it's the raft log index of the write request, or the
+// applied index when the read request is executed. It can be used as a causal
+// token of the request, and is only valid after `Get` returns the response.
+func (f *Future) AppliedIndexTerm() (uint64, uint64) {
+	return f.appliedIndex, f.appliedTerm
+}
+
+// Close close the future, and the future is put back to the pool for the
+// next request.
 func (f *Future) Close() {
 	f.mu.Lock()
-	defer f.mu.Unlock()
-
-	close(f.c)
+	if f.mu.closed {
+		f.mu.Unlock()
+		return
+	}
 	f.mu.closed = true
+	if f.shardStats != nil && !f.mu.completed {
+		// closed before the response is received, e.g. timeout
+		f.shardStats.observe(f.shard, false, time.Since(f.start))
+	}
+	if f.inflights != nil {
+		f.inflights.removeFuture(toRequestID(f.req.ID), f)
+	}
+
+	// the response of the closed request may still arrive, it is dropped by
+	// the request id check in `done`
+	select {
+	case <-f.c:
+	default:
+	}
+	if f.readValue != nil {
+		f.readValue.Release()
+		f.readValue = nil
+	}
+	f.txnResponse = txnpb.TxnBatchResponse{}
+	f.value = nil
+	f.values = nil
+	f.err = nil
+	f.appliedIndex = 0
+	f.appliedTerm = 0
+	f.req = rpcpb.Request{}
+	f.ctx = nil
+	f.inflights = nil
+	f.start = time.Time{}
+	f.shardStats = nil
+	f.shard = 0
+	f.mu.Unlock()
+	futurePool.Put(f)
 }
 
 func (f *Future) canRetry() bool {
@@ -134,16 +321,39 @@ func (f *Future) canRetry() bool {
 	}
 }
 
-func (f *Future) done(value []byte, txnRespopnse *txnpb.TxnBatchResponse, err error) {
+// retryRequest returns the request to retry, it returns false if the future
+// is closed or reused by another request.
+func (f *Future) retryRequest(id []byte) (rpcpb.Request, bool) {
 	f.mu.Lock()
 	defer f.mu.Unlock()
 
-	if !f.mu.closed {
-		if txnRespopnse != nil {
-			f.txnResponse = *txnRespopnse
+	if f.mu.closed ||
+		!bytes.Equal(id, f.req.ID) ||
+		!f.canRetry() ||
+		isDiagnoseRequest(f.req) {
+		return rpcpb.Request{}, false
+	}
+	return f.req, true
+}
+
+// done completes the future with the response, resp is nil if the request is
+// failed without a response.
+func (f *Future) done(id []byte, resp *rpcpb.Response, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if !f.mu.closed && bytes.Equal(id, f.req.ID) {
+		if resp != nil {
+			if resp.TxnBatchResponse != nil {
+				f.txnResponse = *resp.TxnBatchResponse
+			}
+			f.value = resp.Value
+			f.values = resp.WriteOpValues
+			f.appliedIndex = resp.AppliedIndex
+			f.appliedTerm = resp.AppliedTerm
 		}
-		f.value = value
 		f.err = err
+		f.observe()
 		select {
 		case f.c <- struct{}{}:
 		default:
@@ -152,6 +362,42 @@ func (f *Future) done(value []byte, txnRespopnse *txnpb.TxnBatchResponse, err er
 	}
 }
 
+// doneWithReadValue completes the future with the zero copy read value, the
+// future takes the ownership of the value and releases it on close.
+func (f *Future) doneWithReadValue(resp rpcpb.Response, value *storage.ReadValue, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.mu.closed || !bytes.Equal(resp.ID, f.req.ID) {
+		value.Release()
+		return
+	}
+	f.readValue = value
+	f.value = value.Data()
+	f.appliedIndex = resp.AppliedIndex
+	f.appliedTerm = resp.AppliedTerm
+	f.err = err
+	f.observe()
+	select {
+	case f.c <- struct{}{}:
+	default:
+		panic("BUG")
+	}
+}
+
+// observe updates the metrics of the completed request, the partial result of
+// the read request is not a failure.
+func (f *Future) observe() {
+	f.mu.completed = true
+	requestType := f.req.Type.String()
+	succeeded := f.err == nil || IsPartialResultErr(f.err)
+	metric.IncClientRequestCount(requestType, succeeded)
+	metric.ObserveClientRequestDuration(requestType, f.start)
+	if f.shardStats != nil {
+		f.shardStats.observe(f.shard, succeeded, time.Since(f.start))
+	}
+}
+
 // Client is a cube client, providing read and write access to the external.
 type Client interface {
 	// Start start the cube client
@@ -171,30 +417,103 @@ type Client interface {
 	Read(ctx context.Context, requestType uint64, payload []byte, opts ...Option) *Future
 	// Txn exec the transaction request, and use the `Future` to get the response
 	Txn(ctx context.Context, request txnpb.TxnBatchRequest, opts ...Option) *Future
+	// BatchWrite exec the write operations on the same shard atomically in a single
+	// raft log entry, and use the `Future.GetBatchWrite` to get the responses.
+	BatchWrite(ctx context.Context, ops []WriteOp, opts ...Option) *Future
 
 	// AddLabelToShard add lable to shard, and use the `Future` to get the response
 	AddLabelToShard(ctx context.Context, name, value string, shard uint64) *Future
+	// CreateReadSnapshot creates a named read snapshot of the shard on all the replicas,
+	// the read requests with `WithReadSnapshot` read the data of the shard at the time
+	// of the creation until it's released by `ReleaseReadSnapshot`, or not read within
+	// the ttl, 0 means the default TTL of the data storage. The read snapshot is kept
+	// in the memory of the replicas and lost after the replica restarted. The response
+	// of the `Future` is the encoded `rpcpb.CreateReadSnapshotResponse`.
+	CreateReadSnapshot(ctx context.Context, shard uint64, name string, ttl time.Duration) *Future
+	// ReleaseReadSnapshot releases the named read snapshot of the shard on all the
+	// replicas, and use the `Future` to get the response.
+	ReleaseReadSnapshot(ctx context.Context, shard uint64, name string) *Future
+	// SplitShard splits the shard at the split keys proactively instead of waiting for
+	// the split check of the shard size, e.g. at the known hot boundaries. The keys must
+	// be in the range of the shard in ascending order, and the keys of the hash routing
+	// groups must be the partition keys. The response of the `Future` is the encoded
+	// `rpcpb.BatchSplitResponse` with the new shards once the split applied.
+	SplitShard(ctx context.Context, shard uint64, splitKeys [][]byte) *Future
+	// CompactShard compacts the data of the shard in the data storages of all the
+	// replicas, e.g. to reclaim the space after the bulk deletes. The response of the
+	// `Future` is the encoded `rpcpb.CompactShardResponse` once the compactions are
+	// scheduled, the compactions run in the background of the replicas.
+	CompactShard(ctx context.Context, shard uint64) *Future
+	// UpdateDurabilityPolicy updates the durability policy of the shard, the shards
+	// split from it inherit the policy. The prophet promotes the learners or demotes
+	// the voters of the shard to match the new policy in the background.
+	UpdateDurabilityPolicy(ctx context.Context, shard uint64, policy metapb.DurabilityPolicy) *Future
+	// ExportShardDiagnostics collects the diagnostic info of the shard from all
+	// replicas, and writes them to w as a tar bundle. The logEntries is the number
+	// of the last log entries reported by each replica.
+	ExportShardDiagnostics(ctx context.Context, shard uint64, logEntries uint64, w io.Writer) error
+	// Barrier proposes a barrier entry to all the shards of the group, and returns the
+	// raft log index of the barrier entry of each shard once every shard has applied it.
+	Barrier(ctx context.Context, group uint64) (map[uint64]uint64, error)
+	// Backup creates a consistent backup of all the shards of the group under the path
+	// on the stores of the replicas, and returns the manifest of the backup. The writes
+	// of the group are fenced while the backups are being created, so the backups of
+	// all the shards are at a single consistent point of the group.
+	Backup(ctx context.Context, group uint64, path string) (metapb.BackupManifest, error)
+	// FanOutRead executes the same read request on all the shards of the group, and
+	// calls fn with the result of each shard as soon as it's received.
+	FanOutRead(ctx context.Context, group uint64, requestType uint64, payload []byte,
+		fn func(FanOutResult), opts ...FanOutOption) error
+	// Scan executes the read request on the shards of the range [start, end) one by
+	// one in key order, and calls fn with the result of each request until fn returns
+	// false. The background scans can be paced and resumed by the scan options.
+	Scan(ctx context.Context, start, end []byte, requestType uint64, payload []byte,
+		fn func(ScanResult) bool, opts ...ScanOption) error
+	// ReplicateGroup copies the existing data of the group being migrated to the
+	// destination cluster by the target client, fn converts the results of the
+	// scan of the group to the write operations of the destination cluster.
+	ReplicateGroup(ctx context.Context, group uint64, target Client, requestType uint64,
+		payload []byte, fn func(ScanResult) ([]WriteOp, error), opts ...ScanOption) error
+	// SwitchGroupMigration switches the group being migrated to the destination
+	// cluster atomically on all the proxies, see `raftstore.GroupMigration`.
+	SwitchGroupMigration(ctx context.Context, group uint64) error
+
+	// PutPlacementRuleGroupBundle resets the placement rule group and all its rules by
+	// the prophet, e.g. to pin the key range of a table to the labeled stores. The
+	// version of the bundle must be the current version of the group, 0 means the
+	// group must not exist. It returns the new version of the group.
+	PutPlacementRuleGroupBundle(bundle rpcpb.PlacementRuleGroupBundle) (uint64, error)
+	// GetPlacementRuleGroupBundle returns the placement rule group and all its rules.
+	GetPlacementRuleGroupBundle(id string) (rpcpb.PlacementRuleGroupBundle, error)
+	// DeletePlacementRuleGroupBundle removes the placement rule group and all its
+	// rules, the version must be the current version of the group.
+	DeletePlacementRuleGroupBundle(id string, version uint64) error
 }
 
 var _ Client = (*client)(nil)
 
 // client a tcp application server
 type client struct {
-	logger      *zap.Logger
-	shardsProxy raftstore.ShardsProxy
-	inflights   sync.Map // request id -> *Future
-
+	logger        *zap.Logger
+	shardsProxy   raftstore.ShardsProxy
+	prophetClient prophet.Client
+	inflights     *inflightTable
+	shardStats    *ShardStatsCollector
 }
 
 // NewClient creates and return a cube client
 func NewClient(cfg Cfg) Client {
-	return NewClientWithOptions(CreateWithLogger(cfg.Store.GetConfig().Logger.Named("cube-client")),
-		CreateWithShardsProxy(cfg.Store.GetShardsProxy()))
+	opts := []CreateOption{CreateWithLogger(cfg.Store.GetConfig().Logger.Named("cube-client")),
+		CreateWithShardsProxy(cfg.Store.GetShardsProxy())}
+	if pd := cfg.Store.Prophet(); pd != nil {
+		opts = append(opts, CreateWithProphetClient(pd.GetClient()))
+	}
+	return NewClientWithOptions(opts...)
 }
 
 // NewClientWithOptions create client wiht options
 func NewClientWithOptions(options ...CreateOption) Client {
-	c := &client{}
+	c := &client{inflights: newInflightTable()}
 	for _, opt := range options {
 		opt(c)
 	}
@@ -215,6 +534,7 @@ func (s *client) adjust() {
 func (s *client) Start() error {
 	s.logger.Info("begin to start cube client")
 	s.shardsProxy.SetCallback(s.done, s.doneError)
+	s.shardsProxy.SetReadValueCallback(s.doneWithReadValue)
 	s.shardsProxy.SetRetryController(s)
 	s.logger.Info("cube client started")
 	return nil
@@ -253,6 +573,34 @@ func (s *client) AddLabelToShard(ctx context.Context, name, value string, shard
 	return s.exec(ctx, uint64(rpcpb.AdminUpdateLabels), payload, rpcpb.Admin, nil, WithShard(shard))
 }
 
+func (s *client) CreateReadSnapshot(ctx context.Context, shard uint64, name string, ttl time.Duration) *Future {
+	payload := protoc.MustMarshal(&rpcpb.CreateReadSnapshotRequest{
+		Name: name,
+		TTL:  uint64(ttl / time.Millisecond),
+	})
+	return s.exec(ctx, uint64(rpcpb.AdminCreateReadSnapshot), payload, rpcpb.Admin, nil, WithShard(shard))
+}
+
+func (s *client) ReleaseReadSnapshot(ctx context.Context, shard uint64, name string) *Future {
+	payload := protoc.MustMarshal(&rpcpb.ReleaseReadSnapshotRequest{Name: name})
+	return s.exec(ctx, uint64(rpcpb.AdminReleaseReadSnapshot), payload, rpcpb.Admin, nil, WithShard(shard))
+}
+
+func (s *client) SplitShard(ctx context.Context, shard uint64, splitKeys [][]byte) *Future {
+	payload := protoc.MustMarshal(&rpcpb.SplitShardRequest{Keys: splitKeys})
+	return s.exec(ctx, uint64(rpcpb.AdminSplitShard), payload, rpcpb.Admin, nil, WithShard(shard))
+}
+
+func (s *client) CompactShard(ctx context.Context, shard uint64) *Future {
+	payload := protoc.MustMarshal(&rpcpb.CompactShardRequest{})
+	return s.exec(ctx, uint64(rpcpb.AdminCompactShard), payload, rpcpb.Admin, nil, WithShard(shard))
+}
+
+func (s *client) UpdateDurabilityPolicy(ctx context.Context, shard uint64, policy metapb.DurabilityPolicy) *Future {
+	payload := protoc.MustMarshal(&rpcpb.UpdateDurabilityPolicyRequest{Policy: policy})
+	return s.exec(ctx, uint64(rpcpb.AdminUpdateDurabilityPolicy), payload, rpcpb.Admin, nil, WithShard(shard))
+}
+
 func (s *client) exec(ctx context.Context, requestType uint64, payload []byte, cmdType rpcpb.CmdType, txnRequest *txnpb.TxnBatchRequest, opts ...Option) *Future {
 	req := rpcpb.Request{}
 	req.ID = uuid.NewV4().Bytes()
@@ -260,37 +608,41 @@ func (s *client) exec(ctx context.Context, requestType uint64, payload []byte, c
 	req.CustomType = requestType
 	req.Cmd = payload
 	req.TxnBatchRequest = txnRequest
-
-	f := newFuture(ctx, req)
-	for _, opt := range opts {
-		opt(f)
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		s.logger.Fatal("cube client must use timeout context")
 	}
-	s.inflights.Store(hack.SliceToString(f.req.ID), f)
+	req.Deadline = deadline.UnixNano()
+
+	if s.shardStats != nil {
+		opts = append(opts[:len(opts):len(opts)], func(f *Future) {
+			f.shardStats = s.shardStats
+			f.shard = f.req.ToShard
+			if f.shard == 0 {
+				f.shard = s.Router().SelectShardIDByKey(f.req.Group, f.req.Key)
+			}
+		})
+	}
+	f := newFuture(ctx, req, s.inflights, opts...)
+	s.inflights.add(toRequestID(f.req.ID), f)
 
 	if len(req.Key) > 0 && req.ToShard > 0 {
 		s.logger.Fatal("route with key and route with shard cannot be set at the same time")
 	}
-	if _, ok := ctx.Deadline(); !ok {
-		s.logger.Fatal("cube client must use timeout context")
-	}
-
 	if ce := s.logger.Check(zap.DebugLevel, "begin to send request"); ce != nil {
 		ce.Write(log.RequestIDField(req.ID))
 	}
 
 	if err := s.shardsProxy.Dispatch(f.req); err != nil {
-		f.done(nil, nil, err)
+		s.inflights.remove(toRequestID(f.req.ID))
+		f.done(req.ID, nil, err)
 	}
 	return f
 }
 
 func (s *client) Retry(requestID []byte) (rpcpb.Request, bool) {
-	id := hack.SliceToString(requestID)
-	if c, ok := s.inflights.Load(id); ok {
-		f := c.(*Future)
-		if f.canRetry() {
-			return f.req, true
-		}
+	if f, ok := s.inflights.get(toRequestID(requestID)); ok {
+		return f.retryRequest(requestID)
 	}
 
 	return rpcpb.Request{}, false
@@ -301,10 +653,8 @@ func (s *client) done(resp rpcpb.Response) {
 		ce.Write(log.RequestIDField(resp.ID))
 	}
 
-	id := hack.SliceToString(resp.ID)
-	if c, ok := s.inflights.Load(hack.SliceToString(resp.ID)); ok {
-		s.inflights.Delete(id)
-		c.(*Future).done(resp.Value, resp.TxnBatchResponse, nil)
+	if f, ok := s.inflights.remove(toRequestID(resp.ID)); ok {
+		f.done(resp.ID, &resp, partialResultErr(resp))
 	} else {
 		if ce := s.logger.Check(zap.DebugLevel, "response skipped"); ce != nil {
 			ce.Write(log.RequestIDField(resp.ID), log.ReasonField("missing ctx"))
@@ -312,14 +662,34 @@ func (s *client) done(resp rpcpb.Response) {
 	}
 }
 
+func (s *client) doneWithReadValue(resp rpcpb.Response, value *storage.ReadValue) {
+	if ce := s.logger.Check(zap.DebugLevel, "response received"); ce != nil {
+		ce.Write(log.RequestIDField(resp.ID))
+	}
+
+	if f, ok := s.inflights.remove(toRequestID(resp.ID)); ok {
+		f.doneWithReadValue(resp, value, partialResultErr(resp))
+	} else {
+		value.Release()
+		if ce := s.logger.Check(zap.DebugLevel, "response skipped"); ce != nil {
+			ce.Write(log.RequestIDField(resp.ID), log.ReasonField("missing ctx"))
+		}
+	}
+}
+
+func partialResultErr(resp rpcpb.Response) error {
+	if len(resp.ContinuationKey) == 0 {
+		return nil
+	}
+	return NewPartialResultErr(resp.ContinuationKey)
+}
+
 func (s *client) doneError(requestID []byte, err error) {
 	if ce := s.logger.Check(zap.DebugLevel, "error response received"); ce != nil {
 		ce.Write(log.RequestIDField(requestID), zap.Error(err))
 	}
 
-	id := hack.SliceToString(requestID)
-	if c, ok := s.inflights.Load(id); ok {
-		s.inflights.Delete(id)
-		c.(*Future).done(nil, nil, err)
+	if f, ok := s.inflights.remove(toRequestID(requestID)); ok {
+		f.done(requestID, nil, err)
 	}
 }
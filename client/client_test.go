@@ -14,17 +14,30 @@
 package client
 
 import (
+	"archive/tar"
+	"bytes"
 	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/matrixorigin/matrixcube/config"
 	"github.com/matrixorigin/matrixcube/pb/metapb"
+	"github.com/matrixorigin/matrixcube/pb/rpcpb"
 	"github.com/matrixorigin/matrixcube/raftstore"
 	"github.com/matrixorigin/matrixcube/storage"
 	"github.com/matrixorigin/matrixcube/storage/executor/simple"
+	"github.com/matrixorigin/matrixcube/storage/kv/mem"
 	"github.com/matrixorigin/matrixcube/util/leaktest"
+	"github.com/matrixorigin/matrixcube/util/uuid"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestExec(t *testing.T) {
@@ -101,6 +114,282 @@ func TestAddShardLabel(t *testing.T) {
 	c.WaitShardByLabel(sid, "l1", "v1", time.Minute)
 }
 
+func TestBarrier(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	c := raftstore.NewSingleTestClusterStore(t, raftstore.WithAppendTestClusterAdjustConfigFunc(func(node int, cfg *config.Config) {
+		cfg.Customize.CustomInitShardsFactory = func() []metapb.Shard {
+			return []metapb.Shard{{Start: []byte("a"), End: []byte("b")}, {Start: []byte("b")}}
+		}
+	}))
+	defer c.Stop()
+
+	c.Start()
+	s := NewClient(Cfg{Store: c.GetStore(0)})
+	s.Start()
+	defer s.Stop()
+
+	c.WaitShardByCount(2, time.Minute)
+	c.WaitLeadersByCount(2, time.Minute)
+	waitRouterShards(t, s, 0, 2)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	req := newTestWriteCustomRequest("b1", "v")
+	f := s.Write(ctx, req.CmdType, req.Cmd, WithRouteKey(req.Key))
+	_, err := f.Get()
+	assert.NoError(t, err)
+	writeIndex, _ := f.AppliedIndexTerm()
+	f.Close()
+
+	indexes, err := s.Barrier(ctx, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(indexes))
+	for i := 0; i < 2; i++ {
+		sid := c.GetShardByIndex(0, i).ID
+		assert.True(t, indexes[sid] > 0)
+	}
+	sid := c.GetShardByIndex(0, 0).ID
+	if !bytes.Equal(c.GetShardByIndex(0, 0).Start, []byte("b")) {
+		sid = c.GetShardByIndex(0, 1).ID
+	}
+	assert.True(t, indexes[sid] > writeIndex)
+}
+
+func TestBackup(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	c := raftstore.NewSingleTestClusterStore(t, raftstore.WithAppendTestClusterAdjustConfigFunc(func(node int, cfg *config.Config) {
+		cfg.Customize.CustomInitShardsFactory = func() []metapb.Shard {
+			return []metapb.Shard{{Start: []byte("a"), End: []byte("b")}, {Start: []byte("b")}}
+		}
+	}))
+	defer c.Stop()
+
+	c.Start()
+	s := NewClient(Cfg{Store: c.GetStore(0)})
+	s.Start()
+	defer s.Stop()
+
+	c.WaitShardByCount(2, time.Minute)
+	c.WaitLeadersByCount(2, time.Minute)
+	waitRouterShards(t, s, 0, 2)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	req := newTestWriteCustomRequest("b1", "v")
+	f := s.Write(ctx, req.CmdType, req.Cmd, WithRouteKey(req.Key))
+	_, err := f.Get()
+	assert.NoError(t, err)
+	writeIndex, _ := f.AppliedIndexTerm()
+	f.Close()
+
+	manifest, err := s.Backup(ctx, 0, t.TempDir())
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(0), manifest.Group)
+	assert.Equal(t, 2, len(manifest.Shards))
+	for _, sb := range manifest.Shards {
+		assert.True(t, sb.Index > 0)
+		assert.NotEmpty(t, sb.Path)
+		_, err := os.Stat(sb.Path)
+		assert.NoError(t, err)
+		if bytes.Equal(sb.Shard.Start, []byte("b")) {
+			assert.True(t, sb.Index > writeIndex)
+		}
+	}
+
+	// the shards are unfenced after the backup
+	f = s.Write(ctx, req.CmdType, req.Cmd, WithRouteKey(req.Key))
+	_, err = f.Get()
+	assert.NoError(t, err)
+	f.Close()
+}
+
+func TestFanOutRead(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	c := raftstore.NewSingleTestClusterStore(t, raftstore.WithAppendTestClusterAdjustConfigFunc(func(node int, cfg *config.Config) {
+		cfg.Customize.CustomInitShardsFactory = func() []metapb.Shard {
+			return []metapb.Shard{{End: []byte("b")}, {Start: []byte("b")}}
+		}
+	}))
+	defer c.Stop()
+
+	c.Start()
+	s := NewClient(Cfg{Store: c.GetStore(0)})
+	s.Start()
+	defer s.Stop()
+
+	c.WaitShardByCount(2, time.Minute)
+	c.WaitLeadersByCount(2, time.Minute)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	for _, k := range []string{"a", "b"} {
+		req := newTestWriteCustomRequest(k, "v-"+k)
+		f := s.Write(ctx, req.CmdType, req.Cmd, WithRouteKey(req.Key))
+		_, err := f.Get()
+		assert.NoError(t, err)
+		f.Close()
+	}
+
+	// the get request reads the route key, which is the start of the range in
+	// each shard
+	values := make(map[string]string)
+	req := simple.NewReadRequest(nil)
+	assert.NoError(t, s.FanOutRead(ctx, 0, req.CmdType, nil, func(r FanOutResult) {
+		assert.NoError(t, r.Err)
+		values[string(r.Start)] = string(r.Value)
+	}, WithFanOutKeysRange([]byte("a"), nil)))
+	assert.Equal(t, map[string]string{"a": "v-a", "b": "v-b"}, values)
+
+	values = make(map[string]string)
+	assert.NoError(t, s.FanOutRead(ctx, 0, req.CmdType, nil, func(r FanOutResult) {
+		values[string(r.Start)] = string(r.Value)
+	}, WithFanOutKeysRange([]byte("b1"), nil)))
+	assert.Equal(t, map[string]string{"b1": ""}, values)
+}
+
+func TestScan(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	c := raftstore.NewSingleTestClusterStore(t, raftstore.WithAppendTestClusterAdjustConfigFunc(func(node int, cfg *config.Config) {
+		cfg.Customize.CustomInitShardsFactory = func() []metapb.Shard {
+			return []metapb.Shard{{End: []byte("b")}, {Start: []byte("b")}}
+		}
+	}))
+	defer c.Stop()
+
+	c.Start()
+	s := NewClient(Cfg{Store: c.GetStore(0)})
+	s.Start()
+	defer s.Stop()
+
+	c.WaitShardByCount(2, time.Minute)
+	c.WaitLeadersByCount(2, time.Minute)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	for _, k := range []string{"a", "b"} {
+		req := newTestWriteCustomRequest(k, "v-"+k)
+		f := s.Write(ctx, req.CmdType, req.Cmd, WithRouteKey(req.Key))
+		_, err := f.Get()
+		assert.NoError(t, err)
+		f.Close()
+	}
+
+	// the get request reads the route key, which is the start of the range in
+	// each shard
+	var starts, ends, values []string
+	req := simple.NewReadRequest(nil)
+	assert.NoError(t, s.Scan(ctx, []byte("a"), nil, req.CmdType, nil, func(r ScanResult) bool {
+		starts = append(starts, string(r.Start))
+		ends = append(ends, string(r.End))
+		values = append(values, string(r.Value))
+		return true
+	}))
+	assert.Equal(t, []string{"a", "b"}, starts)
+	assert.Equal(t, []string{"b", ""}, ends)
+	assert.Equal(t, []string{"v-a", "v-b"}, values)
+
+	// stops if fn returns false
+	values = values[:0]
+	assert.NoError(t, s.Scan(ctx, nil, nil, req.CmdType, nil, func(r ScanResult) bool {
+		values = append(values, string(r.Value))
+		return false
+	}))
+	assert.Equal(t, []string{""}, values)
+
+	// stops at the end of the range
+	values = values[:0]
+	assert.NoError(t, s.Scan(ctx, []byte("a"), []byte("b"), req.CmdType, nil, func(r ScanResult) bool {
+		values = append(values, string(r.Value))
+		return true
+	}))
+	assert.Equal(t, []string{"v-a"}, values)
+
+	// resumes from the checkpoint with the rate limit
+	kv := mem.NewStorage()
+	defer kv.Close()
+	checkpoint := NewKVScanCheckpoint(kv, []byte("scan"))
+	values = values[:0]
+	assert.NoError(t, s.Scan(ctx, []byte("a"), nil, req.CmdType, nil, func(r ScanResult) bool {
+		values = append(values, string(r.Value))
+		return false
+	}, WithScanCheckpoint(checkpoint), WithScanRateLimit(10)))
+	assert.Equal(t, []string{"v-a"}, values)
+	values = values[:0]
+	assert.NoError(t, s.Scan(ctx, []byte("a"), nil, req.CmdType, nil, func(r ScanResult) bool {
+		values = append(values, string(r.Value))
+		return true
+	}, WithScanCheckpoint(checkpoint), WithScanRateLimit(10)))
+	assert.Equal(t, []string{"v-b"}, values)
+
+	// the finished scan starts over
+	values = values[:0]
+	assert.NoError(t, s.Scan(ctx, []byte("a"), nil, req.CmdType, nil, func(r ScanResult) bool {
+		values = append(values, string(r.Value))
+		return false
+	}, WithScanCheckpoint(checkpoint)))
+	assert.Equal(t, []string{"v-a"}, values)
+
+	// the checkpoint of another range is not resumed
+	values = values[:0]
+	assert.NoError(t, s.Scan(ctx, []byte("a"), []byte("c"), req.CmdType, nil, func(r ScanResult) bool {
+		values = append(values, string(r.Value))
+		return true
+	}, WithScanCheckpoint(checkpoint)))
+	assert.Equal(t, []string{"v-a", "v-b"}, values)
+
+	// stops at the cancelled ctx while waiting for the rate limit
+	cancelCtx, cancel := context.WithCancel(ctx)
+	values = values[:0]
+	assert.Equal(t, context.Canceled, s.Scan(cancelCtx, []byte("a"), nil, req.CmdType, nil, func(r ScanResult) bool {
+		values = append(values, string(r.Value))
+		cancel()
+		return true
+	}, WithScanRateLimit(0.001)))
+	assert.Equal(t, []string{"v-a"}, values)
+}
+
+func TestScanToken(t *testing.T) {
+	token := encodeScanToken(scanInProgress, []byte("a"), nil, []byte("b"))
+	state, start, end, next, err := decodeScanToken(token)
+	assert.NoError(t, err)
+	assert.Equal(t, scanInProgress, state)
+	assert.Equal(t, []byte("a"), start)
+	assert.Empty(t, end)
+	assert.Equal(t, []byte("b"), next)
+
+	_, _, _, _, err = decodeScanToken(token[:2])
+	assert.Error(t, err)
+	_, _, _, _, err = decodeScanToken([]byte{scanFinished + 1})
+	assert.Error(t, err)
+}
+
+func TestClipKeysRange(t *testing.T) {
+	cases := []struct {
+		shard      metapb.Shard
+		start, end string
+		from, to   string
+	}{
+		{shard: metapb.Shard{}, start: "", end: "", from: "", to: ""},
+		{shard: metapb.Shard{Start: []byte("b"), End: []byte("d")}, start: "", end: "", from: "b", to: "d"},
+		{shard: metapb.Shard{Start: []byte("b"), End: []byte("d")}, start: "c", end: "e", from: "c", to: "d"},
+		{shard: metapb.Shard{Start: []byte("b")}, start: "a", end: "c", from: "b", to: "c"},
+		{shard: metapb.Shard{End: []byte("d")}, start: "a", end: "", from: "a", to: "d"},
+	}
+	for i, c := range cases {
+		from, to := clipKeysRange(c.shard, []byte(c.start), []byte(c.end))
+		assert.Equal(t, c.from, string(from), "index %d", i)
+		assert.Equal(t, c.to, string(to), "index %d", i)
+	}
+}
+
 func TestKeysRangeNotInShard(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 
@@ -128,6 +417,493 @@ func TestKeysRangeNotInShard(t *testing.T) {
 	assert.Empty(t, v)
 }
 
+func TestBatchWrite(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	c := raftstore.NewSingleTestClusterStore(t, raftstore.WithAppendTestClusterAdjustConfigFunc(func(node int, cfg *config.Config) {
+		cfg.Customize.CustomInitShardsFactory = func() []metapb.Shard {
+			return []metapb.Shard{{Start: []byte("k1"), End: []byte("k5")}, {Start: []byte("k5")}}
+		}
+	}))
+	defer c.Stop()
+
+	c.Start()
+	c.WaitShardByCountPerNode(2, time.Minute)
+	s := NewClient(Cfg{Store: c.GetStore(0)})
+	s.Start()
+	defer s.Stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	var ops []WriteOp
+	for _, k := range []string{"k3", "k1", "k2"} {
+		req := newTestWriteCustomRequest(k, "v"+k)
+		ops = append(ops, WriteOp{RequestType: req.CmdType, Key: req.Key, Payload: req.Cmd})
+	}
+	f := s.BatchWrite(ctx, ops)
+	defer f.Close()
+	values, err := f.GetBatchWrite()
+	assert.NoError(t, err)
+	assert.Equal(t, [][]byte{simple.OK, simple.OK, simple.OK}, values)
+	for _, k := range []string{"k1", "k2", "k3"} {
+		req := simple.NewReadRequest([]byte(k))
+		rf := s.Read(ctx, req.CmdType, req.Cmd, WithRouteKey(req.Key))
+		v, err := rf.Get()
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("v"+k), v)
+		rf.Close()
+	}
+
+	// the keys of the batch write across the shards
+	ops = append(ops, WriteOp{RequestType: ops[0].RequestType, Key: []byte("k6"), Payload: []byte("v")})
+	f2 := s.BatchWrite(ctx, ops)
+	defer f2.Close()
+	_, err = f2.GetBatchWrite()
+	assert.Equal(t, raftstore.ErrKeysNotInShard, err)
+
+	f3 := s.BatchWrite(ctx, nil)
+	defer f3.Close()
+	_, err = f3.GetBatchWrite()
+	assert.Equal(t, ErrEmptyWriteOps, err)
+}
+
+func TestZeroCopyRead(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	c := raftstore.NewSingleTestClusterStore(t, raftstore.WithAppendTestClusterAdjustConfigFunc(func(node int, cfg *config.Config) {
+		cfg.Raft.EnableZeroCopyRead = true
+	}))
+	defer c.Stop()
+
+	c.Start()
+	s := NewClient(Cfg{Store: c.GetStore(0)})
+	s.Start()
+	defer s.Stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	req := newTestWriteCustomRequest("k", "v")
+	f := s.Write(ctx, req.CmdType, req.Cmd, WithRouteKey(req.Key))
+	_, err := f.Get()
+	f.Close()
+	assert.NoError(t, err)
+
+	req = simple.NewReadRequest([]byte("k"))
+	f = s.Read(ctx, req.CmdType, req.Cmd, WithRouteKey(req.Key))
+	v, err := f.Get()
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("v"), v)
+	assert.NotNil(t, f.readValue)
+	f.Close()
+}
+
 func newTestWriteCustomRequest(k, v string) storage.Request {
 	return simple.NewWriteRequest([]byte(k), []byte(v))
 }
+
+func TestWithPartition(t *testing.T) {
+	f := &Future{}
+	WithPartition(10)(f)
+	assert.Equal(t, raftstore.EncodePartitionKey(10), f.req.Key)
+}
+
+func TestWithMaxStaleness(t *testing.T) {
+	cases := []struct {
+		staleness time.Duration
+		expect    uint64
+	}{
+		{staleness: 0, expect: 0},
+		{staleness: -time.Millisecond, expect: 0},
+		{staleness: time.Microsecond, expect: 1},
+		{staleness: time.Millisecond, expect: 1},
+		{staleness: time.Millisecond + time.Microsecond, expect: 2},
+		{staleness: time.Second, expect: 1000},
+	}
+
+	for i, c := range cases {
+		f := newFuture(context.Background(), rpcpb.Request{}, nil)
+		WithMaxStaleness(c.staleness)(f)
+		assert.Equal(t, c.expect, f.req.MaxStaleness, "index %d", i)
+		f.Close()
+	}
+}
+
+func TestWithTenant(t *testing.T) {
+	f := newFuture(context.Background(), rpcpb.Request{}, nil)
+	defer f.Close()
+	WithTenant("t1")(f)
+	assert.Equal(t, "t1", f.req.Tenant)
+}
+
+func TestFutureDropsResponseAfterClose(t *testing.T) {
+	inflights := newInflightTable()
+	id := uuid.NewV4().Bytes()
+	f := newFuture(context.Background(), rpcpb.Request{ID: id}, inflights)
+	inflights.add(toRequestID(id), f)
+	f.Close()
+	assert.Equal(t, 0, inflights.len())
+
+	// the future may be reused by another request
+	newID := uuid.NewV4().Bytes()
+	f.ctx = context.Background()
+	f.req = rpcpb.Request{ID: newID}
+	f.mu.closed = false
+	f.done(id, &rpcpb.Response{Value: []byte("stale")}, nil)
+	_, ok := f.retryRequest(id)
+	assert.False(t, ok)
+
+	f.done(newID, &rpcpb.Response{Value: []byte("value")}, nil)
+	v, err := f.Get()
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("value"), v)
+	_, ok = f.retryRequest(newID)
+	assert.True(t, ok)
+}
+
+func TestFutureLateResponseRacesWithReuse(t *testing.T) {
+	reused := 0
+	for i := 0; i < 100; i++ {
+		id := uuid.NewV4().Bytes()
+		f := newFuture(context.Background(), rpcpb.Request{ID: id}, nil)
+		f.Close()
+
+		// the late response and retry of the closed request race with the
+		// reuse of the future by the next request
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			f.done(id, &rpcpb.Response{Value: []byte("stale")}, nil)
+			_, ok := f.retryRequest(id)
+			assert.False(t, ok)
+		}()
+		newID := uuid.NewV4().Bytes()
+		nf := newFuture(context.Background(), rpcpb.Request{ID: newID}, nil, WithTenant("t1"))
+		wg.Wait()
+		if nf == f {
+			reused++
+		}
+
+		select {
+		case <-nf.c:
+			assert.Fail(t, "completed by the stale response")
+		default:
+		}
+		nf.done(newID, &rpcpb.Response{Value: []byte("value")}, nil)
+		v, err := nf.Get()
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("value"), v)
+		nf.Close()
+	}
+	assert.True(t, reused > 0)
+}
+
+func TestInflightsRemovedAfterDone(t *testing.T) {
+	s := NewClientWithOptions(CreateWithShardsProxy(&benchShardsProxy{})).(*client)
+	assert.NoError(t, s.Start())
+	defer s.Stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	f := s.Read(ctx, 1, []byte("value"), WithRouteKey([]byte("key")))
+	v, err := f.Get()
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("value"), v)
+	assert.Equal(t, 0, s.inflights.len())
+	f.Close()
+	assert.Equal(t, 0, s.inflights.len())
+}
+
+func TestShardStatsCollector(t *testing.T) {
+	collector := NewShardStatsCollector(0.5)
+	s := NewClientWithOptions(CreateWithShardsProxy(&benchShardsProxy{}),
+		CreateWithShardStatsCollector(collector)).(*client)
+	assert.NoError(t, s.Start())
+	defer s.Stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	for i := 0; i < 2; i++ {
+		f := s.Read(ctx, 1, []byte("value"), WithShard(1))
+		_, err := f.Get()
+		assert.NoError(t, err)
+		f.Close()
+	}
+	// closed before the response is received
+	f := newFuture(ctx, rpcpb.Request{ID: uuid.NewV4().Bytes()}, s.inflights)
+	f.shardStats = collector
+	f.shard = 2
+	f.Close()
+
+	stats, ok := collector.Get(1)
+	assert.True(t, ok)
+	assert.Equal(t, uint64(2), stats.Succeeded)
+	assert.Equal(t, uint64(0), stats.Failed)
+	assert.True(t, stats.LastFailedAt.IsZero())
+	stats, ok = collector.Get(2)
+	assert.True(t, ok)
+	assert.Equal(t, uint64(1), stats.Failed)
+	assert.False(t, stats.LastFailedAt.IsZero())
+	assert.Equal(t, 2, len(collector.All()))
+
+	collector.Remove(1)
+	_, ok = collector.Get(1)
+	assert.False(t, ok)
+}
+
+func TestShardStatsLatencyEWMA(t *testing.T) {
+	collector := NewShardStatsCollector(0.5)
+	collector.observe(1, true, time.Second)
+	collector.observe(1, true, time.Second*3)
+	stats, _ := collector.Get(1)
+	assert.Equal(t, time.Second*2, stats.LatencyEWMA)
+
+	collector.observe(0, true, time.Second)
+	assert.Equal(t, 1, len(collector.All()))
+}
+
+func TestReadWithMaxResponseBytes(t *testing.T) {
+	for _, zeroCopy := range []bool{false, true} {
+		p := &benchShardsProxy{zeroCopy: zeroCopy}
+		s := NewClientWithOptions(CreateWithShardsProxy(p)).(*client)
+		assert.NoError(t, s.Start())
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		f := s.Read(ctx, 1, []byte("value"), WithRouteKey([]byte("key")), WithMaxResponseBytes(2))
+		v, err := f.Get()
+		assert.True(t, IsPartialResultErr(err))
+		assert.Equal(t, []byte("va"), v)
+		assert.Equal(t, []byte("lue"), err.(PartialResultErr).ContinuationKey())
+		f.Close()
+
+		f = s.Read(ctx, 1, []byte("value"), WithRouteKey([]byte("key")), WithMaxResponseBytes(5))
+		v, err = f.Get()
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("value"), v)
+		f.Close()
+
+		cancel()
+		assert.NoError(t, s.Stop())
+	}
+}
+
+func TestFutureAppliedIndexTerm(t *testing.T) {
+	for _, zeroCopy := range []bool{false, true} {
+		p := &benchShardsProxy{zeroCopy: zeroCopy, appliedIndex: 10}
+		s := NewClientWithOptions(CreateWithShardsProxy(p)).(*client)
+		assert.NoError(t, s.Start())
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		f := s.Read(ctx, 1, []byte("value"), WithRouteKey([]byte("key")))
+		_, err := f.Get()
+		assert.NoError(t, err)
+		index, term := f.AppliedIndexTerm()
+		assert.Equal(t, uint64(10), index)
+		assert.Equal(t, uint64(1), term)
+		f.Close()
+
+		cancel()
+		assert.NoError(t, s.Stop())
+	}
+}
+
+func TestFutureReleasesReadValueOnClose(t *testing.T) {
+	p := &benchShardsProxy{zeroCopy: true}
+	s := NewClientWithOptions(CreateWithShardsProxy(p)).(*client)
+	assert.NoError(t, s.Start())
+	defer s.Stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	f := s.Read(ctx, 1, []byte("value"), WithRouteKey([]byte("key")))
+	v, err := f.Get()
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("value"), v)
+	assert.Equal(t, int32(0), atomic.LoadInt32(&p.released))
+	f.Close()
+	assert.Equal(t, int32(1), atomic.LoadInt32(&p.released))
+
+	// the read value of the closed future is released directly
+	f = newFuture(ctx, rpcpb.Request{ID: uuid.NewV4().Bytes()}, nil)
+	id := f.req.ID
+	f.Close()
+	f.doneWithReadValue(rpcpb.Response{ID: id}, storage.NewReadValue([]byte("value"), func() {
+		atomic.AddInt32(&p.released, 1)
+	}), nil)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&p.released))
+}
+
+func TestExportShardDiagnostics(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	c := raftstore.NewSingleTestClusterStore(t)
+	defer c.Stop()
+
+	c.Start()
+	s := NewClient(Cfg{Store: c.GetStore(0)})
+	s.Start()
+	defer s.Stop()
+
+	c.WaitShardByCount(1, time.Minute)
+	c.WaitLeadersByCount(1, time.Minute)
+	shard := c.GetShardByIndex(0, 0)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	req := newTestWriteCustomRequest("k", "v")
+	f := s.Write(ctx, req.CmdType, req.Cmd, WithRouteKey(req.Key))
+	_, err := f.Get()
+	f.Close()
+	assert.NoError(t, err)
+
+	var buf bytes.Buffer
+	assert.NoError(t, s.ExportShardDiagnostics(ctx, shard.ID, 1, &buf))
+
+	files := make(map[string][]byte)
+	tr := tar.NewReader(&buf)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		assert.NoError(t, err)
+		data, err := ioutil.ReadAll(tr)
+		assert.NoError(t, err)
+		files[hdr.Name] = data
+	}
+
+	var manifest ShardDiagnosticManifest
+	assert.NoError(t, json.Unmarshal(files[fmt.Sprintf("shard-%d/manifest.json", shard.ID)], &manifest))
+	assert.Equal(t, shard.ID, manifest.Shard.ID)
+	assert.Empty(t, manifest.Errors)
+	assert.Equal(t, 1, len(manifest.Replicas))
+
+	var d raftstore.ReplicaDiagnostic
+	name := manifest.Replicas[shard.Replicas[0].ID]
+	assert.NoError(t, json.Unmarshal(files[fmt.Sprintf("shard-%d/%s", shard.ID, name)], &d))
+	assert.Equal(t, shard.Replicas[0], d.Replica)
+	assert.True(t, d.Leader)
+	assert.NotEmpty(t, d.RaftStatus)
+	assert.Empty(t, d.Errors)
+	assert.Equal(t, 1, len(d.LogEntries))
+	assert.Equal(t, d.LastIndex, d.LogEntries[0].Index)
+	assert.True(t, d.LogEntries[0].Size > 0)
+}
+
+type benchShardsProxy struct {
+	success      raftstore.SuccessCallback
+	readValue    raftstore.ReadValueCallback
+	zeroCopy     bool
+	released     int32
+	appliedIndex uint64
+}
+
+func (p *benchShardsProxy) Start() error                                            { return nil }
+func (p *benchShardsProxy) Stop() error                                             { return nil }
+func (p *benchShardsProxy) DispatchTo(rpcpb.Request, raftstore.Shard, string) error { return nil }
+func (p *benchShardsProxy) SetRetryController(raftstore.RetryController)            {}
+func (p *benchShardsProxy) OnResponse(rpcpb.ResponseBatch)                          {}
+func (p *benchShardsProxy) OnCredits([]rpcpb.ShardCredits)                          {}
+func (p *benchShardsProxy) Router() raftstore.Router                                { return nil }
+
+func (p *benchShardsProxy) SetGroupMigration(raftstore.GroupMigration) error { return nil }
+func (p *benchShardsProxy) RemoveGroupMigration(uint64)                      {}
+func (p *benchShardsProxy) GetGroupMigration(uint64) (raftstore.GroupMigrationStatus, bool) {
+	return raftstore.GroupMigrationStatus{}, false
+}
+func (p *benchShardsProxy) SetCallback(success raftstore.SuccessCallback, failure raftstore.FailureCallback) {
+	p.success = success
+}
+func (p *benchShardsProxy) SetReadValueCallback(cb raftstore.ReadValueCallback) {
+	p.readValue = cb
+}
+func (p *benchShardsProxy) OnReadValueResponse(rpcpb.Response, *storage.ReadValue) {}
+func (p *benchShardsProxy) Dispatch(req rpcpb.Request) error {
+	resp := rpcpb.Response{ID: req.ID, AppliedIndex: p.appliedIndex, AppliedTerm: 1}
+	value := req.Cmd
+	if n := req.MaxResponseBytes; n > 0 && uint64(len(value)) > n {
+		value, resp.ContinuationKey = value[:n], value[n:]
+	}
+	if p.zeroCopy {
+		p.readValue(resp, storage.NewReadValue(value, func() {
+			atomic.AddInt32(&p.released, 1)
+		}))
+		return nil
+	}
+	resp.Value = value
+	p.success(resp)
+	return nil
+}
+
+func BenchmarkExec(b *testing.B) {
+	s := NewClientWithOptions(CreateWithShardsProxy(&benchShardsProxy{}))
+	s.Start()
+	defer s.Stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+	defer cancel()
+
+	payload := []byte("value")
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			f := s.Read(ctx, 1, payload, WithRouteKey(payload))
+			if _, err := f.Get(); err != nil {
+				b.Fatal(err)
+			}
+			f.Close()
+		}
+	})
+}
+
+func waitRouterShards(t *testing.T, s Client, group uint64, n int) {
+	require.Eventually(t, func() bool {
+		count := 0
+		s.Router().ForeachShards(group, func(shard raftstore.Shard) bool {
+			count++
+			return true
+		})
+		return count == n
+	}, time.Minute, time.Millisecond*10)
+}
+
+func TestPlacementRuleGroupBundle(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	c := raftstore.NewSingleTestClusterStore(t)
+	defer c.Stop()
+
+	c.Start()
+	s := NewClient(Cfg{Store: c.GetStore(0)})
+	s.Start()
+	defer s.Stop()
+
+	bundle := rpcpb.PlacementRuleGroupBundle{
+		ID:    "g1",
+		Index: 1,
+		Rules: []rpcpb.PlacementRule{{GroupID: "g1", ID: "r1", Count: 1}},
+	}
+	version, err := s.PutPlacementRuleGroupBundle(bundle)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), version)
+
+	v, err := s.GetPlacementRuleGroupBundle("g1")
+	require.NoError(t, err)
+	assert.Equal(t, version, v.Version)
+	assert.Equal(t, 1, len(v.Rules))
+
+	assert.Error(t, s.DeletePlacementRuleGroupBundle("g1", 0))
+	assert.NoError(t, s.DeletePlacementRuleGroupBundle("g1", version))
+
+	// without the prophet client
+	s2 := NewClientWithOptions(CreateWithShardsProxy(c.GetStore(0).GetShardsProxy()))
+	_, err = s2.GetPlacementRuleGroupBundle("g1")
+	assert.Equal(t, ErrProphetClientNotSet, err)
+}
@@ -0,0 +1,153 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"archive/tar"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/matrixorigin/matrixcube/pb/metapb"
+	"github.com/matrixorigin/matrixcube/pb/rpcpb"
+	"github.com/matrixorigin/matrixcube/raftstore"
+	"github.com/matrixorigin/matrixcube/util/uuid"
+	"go.uber.org/zap"
+)
+
+// ShardDiagnosticManifest is the manifest.json of the shard diagnostic bundle.
+type ShardDiagnosticManifest struct {
+	// Shard is the shard metadata in the route table of the client
+	Shard metapb.Shard `json:"shard"`
+	// CreatedAt is the time the bundle created
+	CreatedAt time.Time `json:"created-at"`
+	// Replicas is the files of the replicas diagnostic info in the bundle,
+	// replica id -> file name
+	Replicas map[uint64]string `json:"replicas"`
+	// Errors is the errors of the replicas that failed to diagnose,
+	// replica id -> error
+	Errors map[uint64]string `json:"errors,omitempty"`
+}
+
+// ExportShardDiagnostics collects the diagnostic info from all replicas of the
+// shard, and writes them to w as a tar bundle for support cases. The bundle
+// contains a manifest.json and a JSON file of `raftstore.ReplicaDiagnostic`
+// for each replica. The replicas that failed to diagnose before the context is
+// done are recorded in the manifest, and don't fail the export.
+func (s *client) ExportShardDiagnostics(ctx context.Context, shardID uint64,
+	logEntries uint64, w io.Writer) error {
+	if _, ok := ctx.Deadline(); !ok {
+		s.logger.Fatal("cube client must use timeout context")
+	}
+
+	shard := s.Router().GetShard(shardID)
+	if shard.ID == 0 {
+		return fmt.Errorf("shard %d not found", shardID)
+	}
+	payload, err := json.Marshal(raftstore.ShardDiagnosticRequest{LogEntries: logEntries})
+	if err != nil {
+		return err
+	}
+
+	futures := make([]*Future, 0, len(shard.Replicas))
+	for _, r := range shard.Replicas {
+		f := newFuture(ctx, rpcpb.Request{
+			ID:         uuid.NewV4().Bytes(),
+			Group:      shard.Group,
+			ToShard:    shard.ID,
+			Type:       rpcpb.Admin,
+			CustomType: uint64(rpcpb.AdminDiagnose),
+			Cmd:        payload,
+		}, s.inflights)
+		futures = append(futures, f)
+		s.inflights.add(toRequestID(f.req.ID), f)
+
+		// send to the store of the replica directly, the AdminDiagnose
+		// requests are not retried by the proxy to avoid to being served
+		// by other replicas
+		store := s.Router().GetStore(r.StoreID)
+		if store.ClientAddress == "" {
+			f.done(f.req.ID, nil, fmt.Errorf("store %d not found", r.StoreID))
+			continue
+		}
+		if err := s.shardsProxy.DispatchTo(f.req, shard, store.ClientAddress); err != nil {
+			f.done(f.req.ID, nil, err)
+		}
+	}
+
+	prefix := fmt.Sprintf("shard-%d", shard.ID)
+	manifest := ShardDiagnosticManifest{
+		Shard:     shard,
+		CreatedAt: time.Now(),
+		Replicas:  make(map[uint64]string),
+		Errors:    make(map[uint64]string),
+	}
+	values := make([][]byte, len(futures))
+	for idx, f := range futures {
+		replica := shard.Replicas[idx]
+		v, err := f.Get()
+		f.Close()
+		if err != nil {
+			s.logger.Error("fail to diagnose replica",
+				zap.Uint64("shard", shard.ID),
+				zap.Uint64("replica", replica.ID),
+				zap.Error(err))
+			manifest.Errors[replica.ID] = err.Error()
+			continue
+		}
+		values[idx] = v
+	}
+
+	tw := tar.NewWriter(w)
+	for idx, v := range values {
+		if v == nil {
+			continue
+		}
+		replica := shard.Replicas[idx]
+		name := fmt.Sprintf("replica-%d.json", replica.ID)
+		if err := writeTarFile(tw, prefix+"/"+name, v); err != nil {
+			return err
+		}
+		manifest.Replicas[replica.ID] = name
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := writeTarFile(tw, prefix+"/manifest.json", data); err != nil {
+		return err
+	}
+	return tw.Close()
+}
+
+func writeTarFile(tw *tar.Writer, name string, data []byte) error {
+	if err := tw.WriteHeader(&tar.Header{
+		Name:    name,
+		Mode:    0644,
+		Size:    int64(len(data)),
+		ModTime: time.Now(),
+	}); err != nil {
+		return err
+	}
+	_, err := tw.Write(data)
+	return err
+}
+
+func isDiagnoseRequest(req rpcpb.Request) bool {
+	return req.Type == rpcpb.Admin &&
+		rpcpb.AdminCmdType(req.CustomType) == rpcpb.AdminDiagnose
+}
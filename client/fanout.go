@@ -0,0 +1,177 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/matrixorigin/matrixcube/pb/metapb"
+	"github.com/matrixorigin/matrixcube/pb/rpcpb"
+	"github.com/matrixorigin/matrixcube/raftstore"
+)
+
+const (
+	defaultFanOutRetryInterval = time.Millisecond * 100
+)
+
+// FanOutResult is the result of the read request on a shard of the fan-out read.
+type FanOutResult struct {
+	// Shard is the shard served the read request
+	Shard raftstore.Shard
+	// Start and End are the key range [Start, End) of the read request, it's the
+	// part of the fan-out range in the shard.
+	Start, End []byte
+	// Value is the response of the read request, it's only valid in the callback.
+	Value []byte
+	// Err is the error of the read request on the shard
+	Err error
+}
+
+// FanOutOption is the option of the fan-out read
+type FanOutOption func(*fanOutOptions)
+
+type fanOutOptions struct {
+	start, end    []byte
+	maxFailures   int
+	retryInterval time.Duration
+	opts          []Option
+}
+
+// WithFanOutKeysRange set the key range [start, end) of the fan-out read, only
+// the shards overlapping the range are read. Default is all shards of the group.
+func WithFanOutKeysRange(start, end []byte) FanOutOption {
+	return func(o *fanOutOptions) {
+		o.start = start
+		o.end = end
+	}
+}
+
+// WithFanOutMaxFailures set the max number of the failed shards tolerated by the
+// fan-out read, negative value means all failures are tolerated. Default is 0,
+// the fan-out read fails if the read request of any shard fails.
+func WithFanOutMaxFailures(n int) FanOutOption {
+	return func(o *fanOutOptions) {
+		o.maxFailures = n
+	}
+}
+
+// WithFanOutRequestOptions set the options of the read request of each shard,
+// e.g. `WithReplicaSelectPolicy`. The route options are ignored.
+func WithFanOutRequestOptions(opts ...Option) FanOutOption {
+	return func(o *fanOutOptions) {
+		o.opts = append(o.opts, opts...)
+	}
+}
+
+type fanOutResponse struct {
+	f          *Future
+	shard      raftstore.Shard
+	start, end []byte
+	value      []byte
+	err        error
+}
+
+// FanOutRead executes the same read request on all the shards of the group in the
+// range, and calls fn with the result of each shard as soon as it's received. The
+// route key of each request is the start key of the part of the range in the shard,
+// the request is routed with the `WithKeysRange` of the part, so the executor reads
+// from the route key to the end of the shard. If a shard is split before the request
+// is served, the part of the range is re-routed to the new shards. An error is
+// returned if the number of the failed shards exceeds the `WithFanOutMaxFailures`.
+func (s *client) FanOutRead(ctx context.Context, group uint64, requestType uint64,
+	payload []byte, fn func(FanOutResult), opts ...FanOutOption) error {
+	o := fanOutOptions{retryInterval: defaultFanOutRetryInterval}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	results := make(chan fanOutResponse)
+	inflight := 0
+	dispatch := func(start, end []byte) int {
+		n := 0
+		s.Router().AscendRange(group, start, end, rpcpb.SelectLeader, func(shard raftstore.Shard, _ metapb.Store) bool {
+			from, to := clipKeysRange(shard, start, end)
+			if len(to) > 0 && bytes.Compare(from, to) >= 0 {
+				return true
+			}
+
+			requestOpts := append(o.opts[:len(o.opts):len(o.opts)], WithShardGroup(group),
+				WithRouteKey(from), WithKeysRange(from, to))
+			f := s.exec(ctx, requestType, payload, rpcpb.Read, nil, requestOpts...)
+			go func() {
+				v, err := f.Get()
+				results <- fanOutResponse{f: f, shard: shard, start: from, end: to, value: v, err: err}
+			}()
+			n++
+			return true
+		})
+		return n
+	}
+
+	if inflight = dispatch(o.start, o.end); inflight == 0 {
+		return fmt.Errorf("no shards of group %d in the range", group)
+	}
+
+	total, failed := 0, 0
+	var firstErr error
+	for inflight > 0 {
+		r := <-results
+		inflight--
+
+		// the shard is split, re-route the range by the new shards
+		if r.err == raftstore.ErrKeysNotInShard {
+			select {
+			case <-ctx.Done():
+				r.err = ctx.Err()
+			case <-time.After(o.retryInterval):
+				if n := dispatch(r.start, r.end); n > 0 {
+					r.f.Close()
+					inflight += n
+					continue
+				}
+			}
+		}
+
+		total++
+		fn(FanOutResult{Shard: r.shard, Start: r.start, End: r.end, Value: r.value, Err: r.err})
+		r.f.Close()
+		if r.err != nil {
+			failed++
+			if firstErr == nil {
+				firstErr = r.err
+			}
+		}
+	}
+
+	if failed > 0 && o.maxFailures >= 0 && failed > o.maxFailures {
+		return fmt.Errorf("fan-out read failed on %d of %d shards: %w", failed, total, firstErr)
+	}
+	return nil
+}
+
+// clipKeysRange returns the intersection of the shard range and [start, end), the
+// empty end means the end of the key space.
+func clipKeysRange(shard raftstore.Shard, start, end []byte) ([]byte, []byte) {
+	from, to := start, end
+	if bytes.Compare(shard.Start, from) > 0 {
+		from = shard.Start
+	}
+	if len(shard.End) > 0 && (len(to) == 0 || bytes.Compare(shard.End, to) < 0) {
+		to = shard.End
+	}
+	return from, to
+}
@@ -0,0 +1,105 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"sync"
+
+	"github.com/matrixorigin/matrixcube/util/uuid"
+)
+
+const (
+	inflightBuckets = 64
+)
+
+// requestID is the fixed size key of the inflight requests, the request ids are
+// generated by uuid, so it can be used as the map key without allocations.
+type requestID uuid.UUID
+
+func toRequestID(id []byte) requestID {
+	var v requestID
+	copy(v[:], id)
+	return v
+}
+
+// inflightTable is the table of the inflight requests, request id -> *Future.
+// The table is split into buckets to reduce the lock contention.
+type inflightTable struct {
+	buckets [inflightBuckets]inflightBucket
+}
+
+type inflightBucket struct {
+	sync.Mutex
+	futures map[requestID]*Future
+}
+
+func newInflightTable() *inflightTable {
+	t := &inflightTable{}
+	for idx := range t.buckets {
+		t.buckets[idx].futures = make(map[requestID]*Future)
+	}
+	return t
+}
+
+func (t *inflightTable) bucket(id requestID) *inflightBucket {
+	// the last byte of the uuid v4 is random
+	return &t.buckets[id[len(id)-1]%inflightBuckets]
+}
+
+func (t *inflightTable) add(id requestID, f *Future) {
+	b := t.bucket(id)
+	b.Lock()
+	b.futures[id] = f
+	b.Unlock()
+}
+
+func (t *inflightTable) get(id requestID) (*Future, bool) {
+	b := t.bucket(id)
+	b.Lock()
+	f, ok := b.futures[id]
+	b.Unlock()
+	return f, ok
+}
+
+func (t *inflightTable) remove(id requestID) (*Future, bool) {
+	b := t.bucket(id)
+	b.Lock()
+	f, ok := b.futures[id]
+	if ok {
+		delete(b.futures, id)
+	}
+	b.Unlock()
+	return f, ok
+}
+
+// removeFuture removes the id only if it still belongs to the future.
+func (t *inflightTable) removeFuture(id requestID, f *Future) {
+	b := t.bucket(id)
+	b.Lock()
+	if v, ok := b.futures[id]; ok && v == f {
+		delete(b.futures, id)
+	}
+	b.Unlock()
+}
+
+func (t *inflightTable) len() int {
+	n := 0
+	for idx := range t.buckets {
+		b := &t.buckets[idx]
+		b.Lock()
+		n += len(b.futures)
+		b.Unlock()
+	}
+	return n
+}
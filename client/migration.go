@@ -0,0 +1,209 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/matrixorigin/matrixcube/pb/metapb"
+	"github.com/matrixorigin/matrixcube/pb/rpcpb"
+	"github.com/matrixorigin/matrixcube/raftstore"
+)
+
+var (
+	defaultMigrationTimeout      = time.Second * 30
+	defaultMigrationPollInterval = time.Millisecond * 10
+)
+
+// NewMigrationTarget returns the `raftstore.MigrationTarget` executing the requests
+// of the migrated group on the destination cluster by the client of it, see the
+// `raftstore.ShardsProxy.SetGroupMigration`.
+func NewMigrationTarget(c Client) raftstore.MigrationTarget {
+	return &migrationTarget{c: c}
+}
+
+type migrationTarget struct {
+	c Client
+}
+
+func (t *migrationTarget) Dispatch(req rpcpb.Request, cb func(rpcpb.Response, error)) {
+	deadline := time.Now().Add(defaultMigrationTimeout)
+	if req.Deadline > 0 {
+		deadline = time.Unix(0, req.Deadline)
+	}
+	ctx, cancel := context.WithDeadline(context.Background(), deadline)
+	var f *Future
+	switch req.Type {
+	case rpcpb.Write:
+		f = t.c.Write(ctx, req.CustomType, req.Cmd, withMigratedRequest(req))
+	case rpcpb.Read:
+		f = t.c.Read(ctx, req.CustomType, req.Cmd, withMigratedRequest(req))
+	default:
+		cancel()
+		cb(rpcpb.Response{}, fmt.Errorf("%s request can not be migrated", req.Type.String()))
+		return
+	}
+	go func() {
+		defer cancel()
+		defer f.Close()
+		v, err := f.Get()
+		if IsPartialResultErr(err) {
+			err = nil
+		}
+		cb(rpcpb.Response{
+			ID:         req.ID,
+			Type:       req.Type,
+			CustomType: req.CustomType,
+			Value:      append([]byte(nil), v...),
+		}, err)
+	}()
+}
+
+// withMigratedRequest executes the migrated request as is, except the shard and
+// the epoch local to the source cluster.
+func withMigratedRequest(req rpcpb.Request) Option {
+	return func(f *Future) {
+		id, deadline := f.req.ID, f.req.Deadline
+		f.req = req
+		f.req.ID = id
+		f.req.Deadline = deadline
+		f.req.PID = 0
+		f.req.ToShard = 0
+		f.req.Epoch = metapb.ShardEpoch{}
+	}
+}
+
+// ReplicateGroup copies the existing data of the group to the destination cluster
+// while the group is in the `raftstore.MigrationReplicating` phase on all the
+// proxies. The range of the group is scanned by the read request of the requestType
+// and the payload, and fn converts each scan result to the write operations
+// executed on the destination cluster by the target client. The operations of
+// each result are batched by the shards of the destination cluster, the keys in
+// the same shard are written atomically in order.
+//
+// The writes acknowledged by the source cluster meanwhile are queued by the
+// proxies and replayed once the group moves to the dual write phase, so the
+// writes must be replayable on top of the copied data, e.g. put or delete.
+func (s *client) ReplicateGroup(ctx context.Context, group uint64, target Client,
+	requestType uint64, payload []byte, fn func(ScanResult) ([]WriteOp, error),
+	opts ...ScanOption) error {
+	status, ok := s.shardsProxy.GetGroupMigration(group)
+	if !ok || status.Phase != raftstore.MigrationReplicating {
+		return raftstore.ErrInvalidMigrationPhase
+	}
+
+	var err error
+	opts = append(opts, WithScanShardGroup(group))
+	if e := s.Scan(ctx, nil, nil, requestType, payload, func(result ScanResult) bool {
+		var ops []WriteOp
+		if ops, err = fn(result); err != nil {
+			return false
+		}
+		err = writeMigratedOps(ctx, group, target, ops)
+		return err == nil
+	}, opts...); e != nil {
+		return e
+	}
+	return err
+}
+
+// writeMigratedOps writes the ops to the shards of the destination cluster.
+func writeMigratedOps(ctx context.Context, group uint64, target Client, ops []WriteOp) error {
+	var shards []uint64
+	batches := make(map[uint64][]WriteOp)
+	for _, op := range ops {
+		shard, _ := target.Router().SelectShardWithPolicy(group, op.Key, rpcpb.SelectLeader)
+		if _, ok := batches[shard.ID]; !ok {
+			shards = append(shards, shard.ID)
+		}
+		batches[shard.ID] = append(batches[shard.ID], op)
+	}
+
+	futures := make([]*Future, 0, len(shards))
+	for _, id := range shards {
+		futures = append(futures, target.BatchWrite(ctx, batches[id], WithShardGroup(group)))
+	}
+	var err error
+	for _, f := range futures {
+		if _, e := f.GetBatchWrite(); e != nil && err == nil {
+			err = e
+		}
+		f.Close()
+	}
+	return err
+}
+
+// SwitchGroupMigration switches the group to the destination cluster atomically.
+// All the shards of the group are fenced as migrated on the source cluster first,
+// so the requests of all the proxies are rejected by the source cluster and
+// retried on the destination cluster, see `raftstore.GroupMigration`. The group
+// is switched on the proxy of the client once all its writes are replicated. The
+// shards are unfenced and the group is not switched if the replicated writes or
+// the verified reads of the proxy diverged, or the shards of the group changed.
+func (s *client) SwitchGroupMigration(ctx context.Context, group uint64) error {
+	status, ok := s.shardsProxy.GetGroupMigration(group)
+	if !ok || status.Phase != raftstore.MigrationDualWrite {
+		return raftstore.ErrInvalidMigrationPhase
+	}
+	shards := s.groupShards(group)
+	if len(shards) == 0 {
+		return fmt.Errorf("no shards of group %d", group)
+	}
+
+	err := s.fenceAndSwitch(ctx, group, shards, status.Verify)
+	if err == nil {
+		return nil
+	}
+	if _, e := s.barrier(ctx, group, shards, rpcpb.BarrierRequest{Unfence: true}); e != nil {
+		s.logger.Error("failed to unfence the shards after the switch failed",
+			zap.Uint64("group", group),
+			zap.Error(e))
+	}
+	return err
+}
+
+// fenceAndSwitch fences the shards as migrated, then switches the proxy once all
+// the writes before the fence are replicated.
+func (s *client) fenceAndSwitch(ctx context.Context, group uint64, shards []raftstore.Shard,
+	verify bool) error {
+	if _, err := s.barrier(ctx, group, shards,
+		rpcpb.BarrierRequest{Fence: true, Migrated: true}); err != nil {
+		return err
+	}
+	if !sameShards(shards, s.groupShards(group)) {
+		return ErrBarrierShardsChanged
+	}
+
+	ticker := time.NewTicker(defaultMigrationPollInterval)
+	defer ticker.Stop()
+	for {
+		err := s.shardsProxy.SetGroupMigration(raftstore.GroupMigration{
+			Group:  group,
+			Phase:  raftstore.MigrationSwitched,
+			Verify: verify,
+		})
+		if err != raftstore.ErrMigrationPending {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package client
 
 import (
+	"github.com/matrixorigin/matrixcube/components/prophet"
 	"github.com/matrixorigin/matrixcube/raftstore"
 	"go.uber.org/zap"
 )
@@ -21,3 +22,19 @@ func CreateWithShardsProxy(shardsProxy raftstore.ShardsProxy) CreateOption {
 		c.shardsProxy = shardsProxy
 	}
 }
+
+// CreateWithProphetClient set the prophet client for the cube client, which serves
+// the cluster management requests, e.g. the placement rules
+func CreateWithProphetClient(prophetClient prophet.Client) CreateOption {
+	return func(c *client) {
+		c.prophetClient = prophetClient
+	}
+}
+
+// CreateWithShardStatsCollector set the collector of the per shard stats of the
+// requests completed by the client
+func CreateWithShardStatsCollector(collector *ShardStatsCollector) CreateOption {
+	return func(c *client) {
+		c.shardStats = collector
+	}
+}
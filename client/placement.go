@@ -0,0 +1,47 @@
+// Copyright 2022 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"errors"
+
+	"github.com/matrixorigin/matrixcube/pb/rpcpb"
+)
+
+var (
+	// ErrProphetClientNotSet the cube client is created without the prophet client,
+	// see `CreateWithProphetClient`.
+	ErrProphetClientNotSet = errors.New("prophet client not set")
+)
+
+func (s *client) PutPlacementRuleGroupBundle(bundle rpcpb.PlacementRuleGroupBundle) (uint64, error) {
+	if s.prophetClient == nil {
+		return 0, ErrProphetClientNotSet
+	}
+	return s.prophetClient.PutPlacementRuleGroupBundle(bundle)
+}
+
+func (s *client) GetPlacementRuleGroupBundle(id string) (rpcpb.PlacementRuleGroupBundle, error) {
+	if s.prophetClient == nil {
+		return rpcpb.PlacementRuleGroupBundle{}, ErrProphetClientNotSet
+	}
+	return s.prophetClient.GetPlacementRuleGroupBundle(id)
+}
+
+func (s *client) DeletePlacementRuleGroupBundle(id string, version uint64) error {
+	if s.prophetClient == nil {
+		return ErrProphetClientNotSet
+	}
+	return s.prophetClient.DeletePlacementRuleGroupBundle(id, version)
+}
@@ -0,0 +1,276 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"time"
+
+	"github.com/juju/ratelimit"
+	"github.com/matrixorigin/matrixcube/pb/metapb"
+	"github.com/matrixorigin/matrixcube/pb/rpcpb"
+	"github.com/matrixorigin/matrixcube/raftstore"
+	"github.com/matrixorigin/matrixcube/storage"
+)
+
+const (
+	defaultScanRetryInterval = time.Millisecond * 100
+)
+
+// the state of the scan in the first byte of the position token
+const (
+	scanInProgress byte = iota
+	scanFinished
+)
+
+// ScanResult is the result of a read request of the scan.
+type ScanResult struct {
+	// Shard is the shard served the read request
+	Shard raftstore.Shard
+	// Start and End are the key range [Start, End) covered by the result. End is
+	// the continuation key if the result is truncated by `WithMaxResponseBytes`.
+	Start, End []byte
+	// Value is the response of the read request, it's only valid in the callback.
+	Value []byte
+}
+
+// ScanOption is the option of the scan
+type ScanOption func(*scanOptions)
+
+type scanOptions struct {
+	group         uint64
+	retryInterval time.Duration
+	opts          []Option
+	limiter       *ratelimit.Bucket
+	checkpoint    ScanCheckpoint
+}
+
+// WithScanShardGroup set the shard group to scan, default is 0.
+func WithScanShardGroup(group uint64) ScanOption {
+	return func(o *scanOptions) {
+		o.group = group
+	}
+}
+
+// WithScanRequestOptions set the options of the read requests of the scan, e.g.
+// `WithMaxResponseBytes`. The route options are ignored.
+func WithScanRequestOptions(opts ...Option) ScanOption {
+	return func(o *scanOptions) {
+		o.opts = append(o.opts, opts...)
+	}
+}
+
+// WithScanRateLimit set the max number of the read requests per second of the scan,
+// e.g. to keep the background full group scans from affecting the foreground
+// traffic. Default is unlimited.
+func WithScanRateLimit(requestsPerSecond float64) ScanOption {
+	return func(o *scanOptions) {
+		o.limiter = ratelimit.NewBucketWithRate(requestsPerSecond, 1)
+	}
+}
+
+// WithScanCheckpoint set the ScanCheckpoint persisting the position of the scan,
+// the scan resumes from the persisted position, e.g. after the background job is
+// restarted. The position is only resumed by the scan of the same range, the scan
+// of another range or after the persisted scan is finished starts from the start
+// of the range and overwrites the checkpoint.
+func WithScanCheckpoint(checkpoint ScanCheckpoint) ScanOption {
+	return func(o *scanOptions) {
+		o.checkpoint = checkpoint
+	}
+}
+
+// ScanCheckpoint persists the position token of the scan. The token is updated
+// after each result is handled by the callback of the scan, the keys before the
+// position are never scanned again once resumed.
+type ScanCheckpoint interface {
+	// Load returns the persisted token, nil if the scan is not started
+	Load() ([]byte, error)
+	// Save persists the token
+	Save(token []byte) error
+}
+
+var _ ScanCheckpoint = (*kvScanCheckpoint)(nil)
+
+type kvScanCheckpoint struct {
+	kv  storage.KVStore
+	key []byte
+}
+
+// NewKVScanCheckpoint returns a ScanCheckpoint persisting the token in the key of
+// the kv store.
+func NewKVScanCheckpoint(kv storage.KVStore, key []byte) ScanCheckpoint {
+	return &kvScanCheckpoint{kv: kv, key: key}
+}
+
+func (c *kvScanCheckpoint) Load() ([]byte, error) {
+	return c.kv.Get(c.key)
+}
+
+func (c *kvScanCheckpoint) Save(token []byte) error {
+	return c.kv.Set(c.key, token, true)
+}
+
+// encodeScanToken encodes the position token: state, the length prefixed start
+// and end of the scanned range, and the next key.
+func encodeScanToken(state byte, start, end, next []byte) []byte {
+	token := make([]byte, 0, 1+2*binary.MaxVarintLen64+len(start)+len(end)+len(next))
+	token = append(token, state)
+	token = appendScanTokenKey(token, start)
+	token = appendScanTokenKey(token, end)
+	return append(token, next...)
+}
+
+func appendScanTokenKey(token, key []byte) []byte {
+	var size [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(size[:], uint64(len(key)))
+	token = append(token, size[:n]...)
+	return append(token, key...)
+}
+
+func decodeScanToken(token []byte) (state byte, start, end, next []byte, err error) {
+	invalid := fmt.Errorf("invalid scan position token %+v", token)
+	if len(token) == 0 || token[0] > scanFinished {
+		return 0, nil, nil, nil, invalid
+	}
+	state, token = token[0], token[1:]
+	if start, token, err = decodeScanTokenKey(token); err != nil {
+		return 0, nil, nil, nil, invalid
+	}
+	if end, token, err = decodeScanTokenKey(token); err != nil {
+		return 0, nil, nil, nil, invalid
+	}
+	return state, start, end, token, nil
+}
+
+func decodeScanTokenKey(token []byte) ([]byte, []byte, error) {
+	n, size := binary.Uvarint(token)
+	if size <= 0 || uint64(len(token)-size) < n {
+		return nil, nil, fmt.Errorf("invalid key length")
+	}
+	token = token[size:]
+	return token[:n], token[n:], nil
+}
+
+// waitScan waits for d, returns the error of the ctx if it's done before.
+func waitScan(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// Scan executes the read request on the shards of the range [start, end) one by one
+// in key order, and calls fn with the result of each request until fn returns false.
+// The empty end means the end of the key space. Like `FanOutRead`, the read request
+// of each shard is routed with the start key of the part of the range in the shard
+// and the `WithKeysRange` of the part. If the shard is split before the request is
+// served, the request is re-routed from the same key. If the result is truncated by
+// `WithMaxResponseBytes`, the next request continues from the continuation key. The
+// scan stops at the first failed request and returns its error. The requests are
+// paced by `WithScanRateLimit`, and the scan resumes from the position persisted
+// by `WithScanCheckpoint` if the range is the same.
+func (s *client) Scan(ctx context.Context, start, end []byte, requestType uint64,
+	payload []byte, fn func(ScanResult) bool, opts ...ScanOption) error {
+	o := scanOptions{retryInterval: defaultScanRetryInterval}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	from := start
+	if o.checkpoint != nil {
+		token, err := o.checkpoint.Load()
+		if err != nil {
+			return err
+		}
+		if len(token) > 0 {
+			state, tokenStart, tokenEnd, next, err := decodeScanToken(token)
+			if err != nil {
+				return err
+			}
+			if state == scanInProgress &&
+				bytes.Equal(tokenStart, start) &&
+				bytes.Equal(tokenEnd, end) &&
+				bytes.Compare(next, from) > 0 {
+				from = next
+			}
+		}
+	}
+
+	for {
+		if o.limiter != nil {
+			if err := waitScan(ctx, o.limiter.Take(1)); err != nil {
+				return err
+			}
+		}
+
+		var shard raftstore.Shard
+		found := false
+		s.Router().AscendRangeWithLimit(o.group, from, end, 1, rpcpb.SelectLeader, func(sd raftstore.Shard, _ metapb.Store) bool {
+			shard, found = sd, true
+			return false
+		})
+		if !found {
+			return fmt.Errorf("no shard of group %d contains key %+v", o.group, from)
+		}
+
+		rangeStart, rangeEnd := clipKeysRange(shard, from, end)
+		requestOpts := append(o.opts[:len(o.opts):len(o.opts)], WithShardGroup(o.group),
+			WithRouteKey(rangeStart), WithKeysRange(rangeStart, rangeEnd))
+		f := s.exec(ctx, requestType, payload, rpcpb.Read, nil, requestOpts...)
+		v, err := f.Get()
+
+		// the shard is split, re-route from the same key after the route is updated
+		if err == raftstore.ErrKeysNotInShard {
+			f.Close()
+			if err := waitScan(ctx, o.retryInterval); err != nil {
+				return err
+			}
+			continue
+		}
+
+		next := rangeEnd
+		if pe, ok := err.(PartialResultErr); ok {
+			next, err = pe.ContinuationKey(), nil
+		}
+		if err != nil {
+			f.Close()
+			return err
+		}
+
+		more := fn(ScanResult{Shard: shard, Start: rangeStart, End: next, Value: v})
+		f.Close()
+		finished := len(next) == 0 || (len(end) > 0 && bytes.Compare(next, end) >= 0)
+		if o.checkpoint != nil {
+			state := scanInProgress
+			if finished {
+				state = scanFinished
+			}
+			if err := o.checkpoint.Save(encodeScanToken(state, start, end, next)); err != nil {
+				return err
+			}
+		}
+		if !more || finished {
+			return nil
+		}
+		from = next
+	}
+}
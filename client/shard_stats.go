@@ -0,0 +1,122 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	defaultLatencyEWMAAlpha = 0.2
+)
+
+// ShardStats is the stats of the requests of a shard completed by the client.
+type ShardStats struct {
+	// ShardID is the id of the shard
+	ShardID uint64
+	// Succeeded is the number of the succeeded requests
+	Succeeded uint64
+	// Failed is the number of the failed requests, including the requests closed
+	// before the response is received, e.g. timeout
+	Failed uint64
+	// LatencyEWMA is the exponentially weighted moving average of the latency of
+	// the completed requests
+	LatencyEWMA time.Duration
+	// LastFailedAt is the time of the last failed request
+	LastFailedAt time.Time
+}
+
+// ShardStatsCollector collects the stats of the requests per shard, the stats
+// can be queried by the application to implement its own circuit breaking or
+// traffic shaping per shard. The shard of the request is the target shard of
+// `WithShard`, or the shard of the route key in the route table of the client
+// when the request is sent.
+type ShardStatsCollector struct {
+	alpha float64
+
+	mu struct {
+		sync.RWMutex
+		shards map[uint64]*ShardStats
+	}
+}
+
+// NewShardStatsCollector returns a ShardStatsCollector, the alpha in (0, 1] is the
+// weight of the latest latency in the latency EWMA, 0.2 is used if it's 0.
+func NewShardStatsCollector(alpha float64) *ShardStatsCollector {
+	if alpha <= 0 || alpha > 1 {
+		alpha = defaultLatencyEWMAAlpha
+	}
+	c := &ShardStatsCollector{alpha: alpha}
+	c.mu.shards = make(map[uint64]*ShardStats)
+	return c
+}
+
+// Get returns the stats of the shard, false if no request of the shard has
+// completed.
+func (c *ShardStatsCollector) Get(shardID uint64) (ShardStats, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if s, ok := c.mu.shards[shardID]; ok {
+		return *s, true
+	}
+	return ShardStats{}, false
+}
+
+// All returns the stats of all the shards in the order of the shard id.
+func (c *ShardStatsCollector) All() []ShardStats {
+	c.mu.RLock()
+	stats := make([]ShardStats, 0, len(c.mu.shards))
+	for _, s := range c.mu.shards {
+		stats = append(stats, *s)
+	}
+	c.mu.RUnlock()
+
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].ShardID < stats[j].ShardID
+	})
+	return stats
+}
+
+// Remove removes the stats of the shard, e.g. the shard is merged or removed.
+func (c *ShardStatsCollector) Remove(shardID uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.mu.shards, shardID)
+}
+
+func (c *ShardStatsCollector) observe(shardID uint64, succeeded bool, latency time.Duration) {
+	if shardID == 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s, ok := c.mu.shards[shardID]
+	if !ok {
+		s = &ShardStats{ShardID: shardID, LatencyEWMA: latency}
+		c.mu.shards[shardID] = s
+	}
+	if succeeded {
+		s.Succeeded++
+	} else {
+		s.Failed++
+		s.LastFailedAt = time.Now()
+	}
+	if ok {
+		s.LatencyEWMA = time.Duration(c.alpha*float64(latency) +
+			(1-c.alpha)*float64(s.LatencyEWMA))
+	}
+}
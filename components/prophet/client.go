@@ -16,6 +16,8 @@ package prophet
 import (
 	"context"
 	"errors"
+	"fmt"
+	"math/rand"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -25,6 +27,7 @@ import (
 	"github.com/matrixorigin/matrixcube/components/prophet/util"
 	"github.com/matrixorigin/matrixcube/pb/metapb"
 	"github.com/matrixorigin/matrixcube/pb/rpcpb"
+	"github.com/matrixorigin/matrixcube/pb/txnpb"
 	"github.com/matrixorigin/matrixcube/util/stop"
 	"go.uber.org/zap"
 )
@@ -34,8 +37,34 @@ var (
 	ErrClosed = errors.New("client is closed")
 	// ErrTimeout timeout
 	ErrTimeout = errors.New("rpc timeout")
+	// ErrConnectionLost the connection to the prophet leader is lost before the
+	// response received, the request may or may not be handled by the leader.
+	ErrConnectionLost = errors.New("connection to prophet leader lost")
 )
 
+var (
+	minRetryBackoff = time.Millisecond * 50
+	maxRetryBackoff = time.Second
+)
+
+// RetryExhaustedError is returned if the request is still failed after the max
+// number of retries, e.g. the prophet leader is not elected.
+type RetryExhaustedError struct {
+	// Retries the number of retries
+	Retries int
+	// Err the error of the last retry
+	Err error
+}
+
+func (e *RetryExhaustedError) Error() string {
+	return fmt.Sprintf("request failed after %d retries: %v", e.Retries, e.Err)
+}
+
+// Unwrap returns the error of the last retry
+func (e *RetryExhaustedError) Unwrap() error {
+	return e.Err
+}
+
 var (
 	stateRunning = int32(0)
 	stateStopped = int32(1)
@@ -48,6 +77,50 @@ type Client interface {
 	CreateDestroying(id uint64, index uint64, removeData bool, replicas []uint64) (metapb.ShardState, error)
 	ReportDestroyed(id uint64, replicaID uint64) (metapb.ShardState, error)
 	GetDestroying(id uint64) (*metapb.DestroyingStatus, error)
+	// ListDestroyingShards returns all the shards in the Destroying state with the
+	// confirmation status of their replicas, a shard is stuck if no replica confirmed
+	// the destroying for the destroying-stuck-timeout.
+	ListDestroyingShards() ([]rpcpb.DestroyingShard, error)
+	// DetectDeadlock adds the wait-for edge of the txn waiting for the pessimistic lock to
+	// the wait-for graph kept by the prophet leader, returns true if the edge causes a
+	// deadlock, and the edge is not added.
+	DetectDeadlock(edge txnpb.WaitForEdge) (bool, error)
+	// CleanUpWaitFor removes all the wait-for edges of the txn, e.g. the txn got the lock
+	// or stopped waiting.
+	CleanUpWaitFor(txnID []byte) error
+	// UpdateShardLabels updates the labels of the shards in the ShardIDs, or the shards
+	// of the group overlapped with the key range if ShardIDs is empty. The labels are
+	// updated asynchronously by a job, the progress of the job is returned. If the
+	// request is steered, the labels are kept added to the shards of the key range
+	// by a label steering until it's canceled.
+	UpdateShardLabels(req rpcpb.UpdateShardLabelsReq) (rpcpb.ShardLabelsJob, error)
+	// GetShardLabelsJob returns the progress of the shard labels job.
+	GetShardLabelsJob(id uint64) (rpcpb.ShardLabelsJob, error)
+	// CancelLabelSteering cancels the label steering created by UpdateShardLabels,
+	// the labels of the shards are kept.
+	CancelLabelSteering(id uint64) error
+	// UpdateGCSafePoint advances the GC safe point of the shard group, which is used
+	// by the stores to drop the data filtered by the compaction filters. The safe point
+	// never goes backwards, the safe point after updated is returned.
+	UpdateGCSafePoint(group, safePoint uint64) (uint64, error)
+	// GetGCSafePoint returns the GC safe point of the shard group, 0 if not set.
+	GetGCSafePoint(group uint64) (uint64, error)
+	// ListSnapshotProgresses returns the progresses of the snapshots being sent and
+	// received reported by the store heartbeats, with the descriptions of the operators
+	// of the shards. 0 storeID or shardID means all the stores or shards.
+	ListSnapshotProgresses(storeID, shardID uint64) ([]metapb.SnapshotProgress, error)
+	// SetStoreMaintenance sets or clears the maintenance mode of the store, no new replica
+	// or leader is scheduled to the store in the maintenance mode, but the existing replicas
+	// remain. The mode is persisted and shown in the store metadata.
+	SetStoreMaintenance(storeID uint64, maintenance bool) error
+	// UpdateEvictLeaderStores adds or removes the store of the evict leader scheduler, which
+	// keeps moving the leaders off the stores. The scheduler is added with the first store and
+	// removed with the last store. Returns the stores of the scheduler after updated.
+	UpdateEvictLeaderStores(storeID uint64, remove bool) ([]uint64, error)
+	// ScatterShards spreads the replicas and the leaders of the shards evenly across the stores
+	// with the location labels, e.g. right after pre-splitting the shards before a bulk import.
+	// Returns the shards failed to scatter, e.g. not fully replicated or without leader.
+	ScatterShards(shardIDs []uint64) ([]uint64, error)
 	PutStore(container metapb.Store) error
 	GetStore(containerID uint64) (*metapb.Store, error)
 	ShardHeartbeat(meta metapb.Shard, hb rpcpb.ShardHeartbeatReq) error
@@ -62,6 +135,10 @@ type Client interface {
 	// AsyncAddShardsWithLeastPeers same of `AsyncAddShards`, but if the number of peers successfully
 	// allocated exceed the `leastPeers`, no error will be returned.
 	AsyncAddShardsWithLeastPeers(resources []metapb.Shard, leastPeers []int) error
+	// AsyncBulkAddShards same of `AsyncAddShards`, but without the batch size limit. Prophet
+	// allocates and persists the resources in batches, and notifies all the waiting resources
+	// by a single event. Used to bootstrap the cluster with a large number of resources.
+	AsyncBulkAddShards(resources ...metapb.Shard) error
 	// AsyncRemoveShards remove resource asynchronously. The operation only update the resource state
 	// on the prophet leader cache and embed etcd. The resource actual destroy triggered in three ways as below:
 	// a) Each cube node starts a backgroud goroutine to check all the resources state, and resource will
@@ -69,11 +146,29 @@ type Client interface {
 	// b) Shard heartbeat received a DestroyDirectly schedule command.
 	// c) If received a resource removed event.
 	AsyncRemoveShards(ids ...uint64) error
+	// AsyncDestroyShards marks all the shards of the group in the key range [start, end) with
+	// all the labels as destroying, empty end means no upper bound, at least one of the key range
+	// and the labels must be specified. There is no batch size limit. The replicas of the shards
+	// are destroyed asynchronously by the two phase destroy protocol, same as the shards destroyed
+	// after split. The IDs of the matched shards are returned, use `GetDestroyShardsProgress` to
+	// check the progress.
+	AsyncDestroyShards(group uint64, start, end []byte, labels []metapb.Label, removeData bool) ([]uint64, error)
 	// CheckShardState returns resources state
 	CheckShardState(resources *roaring64.Bitmap) (rpcpb.CheckShardStateRsp, error)
 
 	// PutPlacementRule put placement rule
 	PutPlacementRule(rule rpcpb.PlacementRule) error
+	// PutPlacementRuleGroupBundle resets the placement rule group and all its rules, the
+	// old rules of the group are dropped, e.g. to pin the key range of a table to the
+	// labeled stores. The version of the bundle must be the current version of the group
+	// returned by `GetPlacementRuleGroupBundle`, 0 means the group must not exist, so
+	// the concurrent updates of the group are rejected. It returns the new version.
+	PutPlacementRuleGroupBundle(bundle rpcpb.PlacementRuleGroupBundle) (uint64, error)
+	// GetPlacementRuleGroupBundle returns the placement rule group and all its rules.
+	GetPlacementRuleGroupBundle(id string) (rpcpb.PlacementRuleGroupBundle, error)
+	// DeletePlacementRuleGroupBundle removes the placement rule group and all its rules,
+	// the version must be the current version of the group.
+	DeletePlacementRuleGroupBundle(id string, version uint64) error
 	// GetAppliedRules returns applied rules of the resource
 	GetAppliedRules(id uint64) ([]rpcpb.PlacementRule, error)
 
@@ -90,6 +185,79 @@ type Client interface {
 	RemoveJob(metapb.Job) error
 	// ExecuteJob execute on job and returns the execute result
 	ExecuteJob(metapb.Job, []byte) ([]byte, error)
+
+	// UpdateScheduleConfig updates the schedule config with the JSON encoded changes,
+	// the fields not in the changes are kept unchanged. The changes are validated
+	// and persisted by the prophet leader, and rolled back automatically if more than
+	// `rollbackMaxOperators` operators are created within the `rollbackWindow` after
+	// the changes applied, zero window disables the rollback. The JSON encoded schedule
+	// config applied is returned.
+	UpdateScheduleConfig(changes []byte, rollbackWindow time.Duration, rollbackMaxOperators uint64) ([]byte, error)
+
+	// GetClusterTopology returns the topology of the cluster in one call, zones -> hosts
+	// -> stores -> shard replicas with the leader markers and the approximate sizes. The
+	// stores are grouped by the values of the `zoneLabel` and `hostLabel` labels, empty
+	// label means the default "zone" and "host" labels.
+	GetClusterTopology(zoneLabel, hostLabel string) ([]rpcpb.TopologyZone, error)
+
+	// SetPreferredLeader pins the leader of the shard to the store, the leader is moved
+	// to the preferred store once the store is healthy, and the leader balance never
+	// moves it away. Zero `storeID` clears the preferred leader.
+	SetPreferredLeader(shardID, storeID uint64) error
+
+	// GetShardRoute returns the authoritative routes of the shards held by the prophet,
+	// the shards are looked up by the `ShardIDs` of the request, and by the `Keys` of
+	// the `Group`. The shards not found are omitted from the response.
+	GetShardRoute(req rpcpb.GetShardRouteReq) (rpcpb.GetShardRouteRsp, error)
+
+	// RelocateRange moves all the replicas of the shards of the group overlapping the key
+	// range [start, end) to the target stores, e.g. to isolate a tenant on dedicated
+	// hardware, empty end means no upper bound. The replicas moved out of the target stores
+	// later are moved back until the relocation is canceled. The ID of the relocation is
+	// returned, use `GetRangeRelocation` to check the progress.
+	RelocateRange(group uint64, start, end []byte, stores []uint64) (uint64, error)
+	// GetRangeRelocation returns the range relocation and its progress.
+	GetRangeRelocation(id uint64) (rpcpb.GetRangeRelocationRsp, error)
+	// CancelRangeRelocation cancels the range relocation and its running operators, the
+	// replicas already moved are kept on the target stores.
+	CancelRangeRelocation(id uint64) error
+}
+
+// DestroyShardsProgress is the progress of the shards destroyed by `AsyncDestroyShards`
+type DestroyShardsProgress struct {
+	// Total is the number of the shards
+	Total int
+	// Destroying is the number of the shards which are being destroyed
+	Destroying int
+	// Destroyed is the number of the shards whose replicas are all destroyed
+	Destroyed int
+}
+
+// Completed returns true if all the shards are destroyed
+func (p DestroyShardsProgress) Completed() bool {
+	return p.Destroyed == p.Total
+}
+
+// GetDestroyShardsProgress returns the progress of the shards destroyed by `AsyncDestroyShards`
+func GetDestroyShardsProgress(c Client, ids []uint64) (DestroyShardsProgress, error) {
+	if len(ids) == 0 {
+		return DestroyShardsProgress{}, nil
+	}
+
+	bm := roaring64.BitmapOf(ids...)
+	rsp, err := c.CheckShardState(bm)
+	if err != nil {
+		return DestroyShardsProgress{}, err
+	}
+
+	destroyed := util.MustUnmarshalBM64(rsp.Destroyed)
+	destroying := util.MustUnmarshalBM64(rsp.Destroying)
+	destroying.AndNot(destroyed)
+	return DestroyShardsProgress{
+		Total:      int(bm.GetCardinality()),
+		Destroying: int(destroying.GetCardinality()),
+		Destroyed:  int(destroyed.GetCardinality()),
+	}, nil
 }
 
 type asyncClient struct {
@@ -98,6 +266,11 @@ type asyncClient struct {
 	containerID uint64
 	id          uint64
 	leaderConn  goetty.IOSession
+	// leaderAddr the address of the connected leader, only accessed in the write
+	// loop
+	leaderAddr string
+	// leaderHint the leader address returned by the prophet follower
+	leaderHint atomic.Value
 
 	resetReadC            chan string
 	resetLeaderConnC      chan struct{}
@@ -257,6 +430,152 @@ func (c *asyncClient) GetDestroying(id uint64) (*metapb.DestroyingStatus, error)
 	return rsp.GetDestroying.Status, nil
 }
 
+func (c *asyncClient) ListDestroyingShards() ([]rpcpb.DestroyingShard, error) {
+	if !c.running() {
+		return nil, ErrClosed
+	}
+
+	req := &rpcpb.ProphetRequest{}
+	req.Type = rpcpb.TypeListDestroyingShardsReq
+
+	rsp, err := c.syncDo(req)
+	if err != nil {
+		return nil, err
+	}
+
+	return rsp.ListDestroyingShards.Shards, nil
+}
+
+func (c *asyncClient) DetectDeadlock(edge txnpb.WaitForEdge) (bool, error) {
+	if !c.running() {
+		return false, ErrClosed
+	}
+
+	req := &rpcpb.ProphetRequest{}
+	req.Type = rpcpb.TypeDetectDeadlockReq
+	req.DetectDeadlock.Edge = edge
+
+	rsp, err := c.syncDo(req)
+	if err != nil {
+		return false, err
+	}
+
+	return rsp.DetectDeadlock.Deadlock, nil
+}
+
+func (c *asyncClient) CleanUpWaitFor(txnID []byte) error {
+	if !c.running() {
+		return ErrClosed
+	}
+
+	req := &rpcpb.ProphetRequest{}
+	req.Type = rpcpb.TypeDetectDeadlockReq
+	req.DetectDeadlock.Edge.TxnID = txnID
+	req.DetectDeadlock.CleanUp = true
+
+	_, err := c.syncDo(req)
+	return err
+}
+
+func (c *asyncClient) UpdateShardLabels(request rpcpb.UpdateShardLabelsReq) (rpcpb.ShardLabelsJob, error) {
+	if !c.running() {
+		return rpcpb.ShardLabelsJob{}, ErrClosed
+	}
+
+	req := &rpcpb.ProphetRequest{}
+	req.Type = rpcpb.TypeUpdateShardLabelsReq
+	req.UpdateShardLabels = request
+
+	rsp, err := c.syncDo(req)
+	if err != nil {
+		return rpcpb.ShardLabelsJob{}, err
+	}
+
+	return rsp.UpdateShardLabels.Job, nil
+}
+
+func (c *asyncClient) GetShardLabelsJob(id uint64) (rpcpb.ShardLabelsJob, error) {
+	if !c.running() {
+		return rpcpb.ShardLabelsJob{}, ErrClosed
+	}
+
+	req := &rpcpb.ProphetRequest{}
+	req.Type = rpcpb.TypeGetShardLabelsJobReq
+	req.GetShardLabelsJob.ID = id
+
+	rsp, err := c.syncDo(req)
+	if err != nil {
+		return rpcpb.ShardLabelsJob{}, err
+	}
+
+	return rsp.GetShardLabelsJob.Job, nil
+}
+
+func (c *asyncClient) ListSnapshotProgresses(storeID, shardID uint64) ([]metapb.SnapshotProgress, error) {
+	if !c.running() {
+		return nil, ErrClosed
+	}
+
+	req := &rpcpb.ProphetRequest{}
+	req.Type = rpcpb.TypeListSnapshotProgressesReq
+	req.ListSnapshotProgresses.StoreID = storeID
+	req.ListSnapshotProgresses.ShardID = shardID
+
+	rsp, err := c.syncDo(req)
+	if err != nil {
+		return nil, err
+	}
+
+	return rsp.ListSnapshotProgresses.Progresses, nil
+}
+
+func (c *asyncClient) SetStoreMaintenance(storeID uint64, maintenance bool) error {
+	if !c.running() {
+		return ErrClosed
+	}
+
+	req := &rpcpb.ProphetRequest{}
+	req.Type = rpcpb.TypeSetStoreMaintenanceReq
+	req.SetStoreMaintenance.StoreID = storeID
+	req.SetStoreMaintenance.Maintenance = maintenance
+
+	_, err := c.syncDo(req)
+	return err
+}
+
+func (c *asyncClient) UpdateEvictLeaderStores(storeID uint64, remove bool) ([]uint64, error) {
+	if !c.running() {
+		return nil, ErrClosed
+	}
+
+	req := &rpcpb.ProphetRequest{}
+	req.Type = rpcpb.TypeUpdateEvictLeaderStoresReq
+	req.UpdateEvictLeaderStores.StoreID = storeID
+	req.UpdateEvictLeaderStores.Remove = remove
+
+	rsp, err := c.syncDo(req)
+	if err != nil {
+		return nil, err
+	}
+	return rsp.UpdateEvictLeaderStores.StoreIDs, nil
+}
+
+func (c *asyncClient) ScatterShards(shardIDs []uint64) ([]uint64, error) {
+	if !c.running() {
+		return nil, ErrClosed
+	}
+
+	req := &rpcpb.ProphetRequest{}
+	req.Type = rpcpb.TypeScatterShardsReq
+	req.ScatterShards.ShardIDs = shardIDs
+
+	rsp, err := c.syncDo(req)
+	if err != nil {
+		return nil, err
+	}
+	return rsp.ScatterShards.FailedShardIDs, nil
+}
+
 func (c *asyncClient) ReportDestroyed(id uint64, replicaID uint64) (metapb.ShardState, error) {
 	if !c.running() {
 		return metapb.ShardState_Destroying, ErrClosed
@@ -389,6 +708,30 @@ func (c *asyncClient) AsyncAddShardsWithLeastPeers(shards []metapb.Shard, leastP
 	return nil
 }
 
+func (c *asyncClient) AsyncBulkAddShards(shards ...metapb.Shard) error {
+	if !c.running() {
+		return ErrClosed
+	}
+
+	req := &rpcpb.ProphetRequest{}
+	req.Type = rpcpb.TypeCreateShardsReq
+	req.CreateShards.Bulk = true
+	for _, res := range shards {
+		data, err := res.Marshal()
+		if err != nil {
+			return err
+		}
+		req.CreateShards.Shards = append(req.CreateShards.Shards, data)
+	}
+
+	_, err := c.syncDo(req)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
 func (c *asyncClient) AsyncRemoveShards(ids ...uint64) error {
 	if !c.running() {
 		return ErrClosed
@@ -406,6 +749,27 @@ func (c *asyncClient) AsyncRemoveShards(ids ...uint64) error {
 	return nil
 }
 
+func (c *asyncClient) AsyncDestroyShards(group uint64, start, end []byte, labels []metapb.Label, removeData bool) ([]uint64, error) {
+	if !c.running() {
+		return nil, ErrClosed
+	}
+
+	req := &rpcpb.ProphetRequest{}
+	req.Type = rpcpb.TypeDestroyShardsReq
+	req.DestroyShards.Group = group
+	req.DestroyShards.Start = start
+	req.DestroyShards.End = end
+	req.DestroyShards.Labels = labels
+	req.DestroyShards.RemoveData = removeData
+
+	rsp, err := c.syncDo(req)
+	if err != nil {
+		return nil, err
+	}
+
+	return rsp.DestroyShards.IDs, nil
+}
+
 func (c *asyncClient) CheckShardState(resources *roaring64.Bitmap) (rpcpb.CheckShardStateRsp, error) {
 	if !c.running() {
 		return rpcpb.CheckShardStateRsp{}, ErrClosed
@@ -440,6 +804,54 @@ func (c *asyncClient) PutPlacementRule(rule rpcpb.PlacementRule) error {
 	return nil
 }
 
+func (c *asyncClient) PutPlacementRuleGroupBundle(bundle rpcpb.PlacementRuleGroupBundle) (uint64, error) {
+	if !c.running() {
+		return 0, ErrClosed
+	}
+
+	req := &rpcpb.ProphetRequest{}
+	req.Type = rpcpb.TypePutPlacementRuleGroupBundleReq
+	req.PutPlacementRuleGroupBundle.Bundle = bundle
+
+	rsp, err := c.syncDo(req)
+	if err != nil {
+		return 0, err
+	}
+
+	return rsp.PutPlacementRuleGroupBundle.Version, nil
+}
+
+func (c *asyncClient) GetPlacementRuleGroupBundle(id string) (rpcpb.PlacementRuleGroupBundle, error) {
+	if !c.running() {
+		return rpcpb.PlacementRuleGroupBundle{}, ErrClosed
+	}
+
+	req := &rpcpb.ProphetRequest{}
+	req.Type = rpcpb.TypeGetPlacementRuleGroupBundleReq
+	req.GetPlacementRuleGroupBundle.ID = id
+
+	rsp, err := c.syncDo(req)
+	if err != nil {
+		return rpcpb.PlacementRuleGroupBundle{}, err
+	}
+
+	return rsp.GetPlacementRuleGroupBundle.Bundle, nil
+}
+
+func (c *asyncClient) DeletePlacementRuleGroupBundle(id string, version uint64) error {
+	if !c.running() {
+		return ErrClosed
+	}
+
+	req := &rpcpb.ProphetRequest{}
+	req.Type = rpcpb.TypeDeletePlacementRuleGroupBundleReq
+	req.DeletePlacementRuleGroupBundle.ID = id
+	req.DeletePlacementRuleGroupBundle.Version = version
+
+	_, err := c.syncDo(req)
+	return err
+}
+
 func (c *asyncClient) GetAppliedRules(id uint64) ([]rpcpb.PlacementRule, error) {
 	if !c.running() {
 		return nil, ErrClosed
@@ -543,6 +955,172 @@ func (c *asyncClient) ExecuteJob(job metapb.Job, data []byte) ([]byte, error) {
 	return rsp.ExecuteJob.Data, nil
 }
 
+func (c *asyncClient) UpdateScheduleConfig(changes []byte, rollbackWindow time.Duration, rollbackMaxOperators uint64) ([]byte, error) {
+	if !c.running() {
+		return nil, ErrClosed
+	}
+
+	req := &rpcpb.ProphetRequest{}
+	req.Type = rpcpb.TypeUpdateScheduleConfigReq
+	req.UpdateScheduleConfig.Changes = changes
+	req.UpdateScheduleConfig.RollbackWindow = int64(rollbackWindow)
+	req.UpdateScheduleConfig.RollbackMaxOperators = rollbackMaxOperators
+
+	rsp, err := c.syncDo(req)
+	if err != nil {
+		return nil, err
+	}
+
+	return rsp.UpdateScheduleConfig.Config, nil
+}
+
+func (c *asyncClient) GetClusterTopology(zoneLabel, hostLabel string) ([]rpcpb.TopologyZone, error) {
+	if !c.running() {
+		return nil, ErrClosed
+	}
+
+	req := &rpcpb.ProphetRequest{}
+	req.Type = rpcpb.TypeGetClusterTopologyReq
+	req.GetClusterTopology.ZoneLabel = zoneLabel
+	req.GetClusterTopology.HostLabel = hostLabel
+
+	rsp, err := c.syncDo(req)
+	if err != nil {
+		return nil, err
+	}
+
+	return rsp.GetClusterTopology.Zones, nil
+}
+
+func (c *asyncClient) SetPreferredLeader(shardID, storeID uint64) error {
+	if !c.running() {
+		return ErrClosed
+	}
+
+	req := &rpcpb.ProphetRequest{}
+	req.Type = rpcpb.TypeSetPreferredLeaderReq
+	req.SetPreferredLeader.ShardID = shardID
+	req.SetPreferredLeader.StoreID = storeID
+
+	_, err := c.syncDo(req)
+	return err
+}
+
+func (c *asyncClient) GetShardRoute(route rpcpb.GetShardRouteReq) (rpcpb.GetShardRouteRsp, error) {
+	if !c.running() {
+		return rpcpb.GetShardRouteRsp{}, ErrClosed
+	}
+
+	req := &rpcpb.ProphetRequest{}
+	req.Type = rpcpb.TypeGetShardRouteReq
+	req.GetShardRoute = route
+
+	rsp, err := c.syncDo(req)
+	if err != nil {
+		return rpcpb.GetShardRouteRsp{}, err
+	}
+
+	return rsp.GetShardRoute, nil
+}
+
+func (c *asyncClient) RelocateRange(group uint64, start, end []byte, stores []uint64) (uint64, error) {
+	if !c.running() {
+		return 0, ErrClosed
+	}
+
+	req := &rpcpb.ProphetRequest{}
+	req.Type = rpcpb.TypeRelocateRangeReq
+	req.RelocateRange.Group = group
+	req.RelocateRange.Start = start
+	req.RelocateRange.End = end
+	req.RelocateRange.Stores = stores
+
+	rsp, err := c.syncDo(req)
+	if err != nil {
+		return 0, err
+	}
+
+	return rsp.RelocateRange.ID, nil
+}
+
+func (c *asyncClient) GetRangeRelocation(id uint64) (rpcpb.GetRangeRelocationRsp, error) {
+	if !c.running() {
+		return rpcpb.GetRangeRelocationRsp{}, ErrClosed
+	}
+
+	req := &rpcpb.ProphetRequest{}
+	req.Type = rpcpb.TypeGetRangeRelocationReq
+	req.GetRangeRelocation.ID = id
+
+	rsp, err := c.syncDo(req)
+	if err != nil {
+		return rpcpb.GetRangeRelocationRsp{}, err
+	}
+
+	return rsp.GetRangeRelocation, nil
+}
+
+func (c *asyncClient) CancelLabelSteering(id uint64) error {
+	if !c.running() {
+		return ErrClosed
+	}
+
+	req := &rpcpb.ProphetRequest{}
+	req.Type = rpcpb.TypeCancelLabelSteeringReq
+	req.CancelLabelSteering.ID = id
+
+	_, err := c.syncDo(req)
+	return err
+}
+
+func (c *asyncClient) UpdateGCSafePoint(group, safePoint uint64) (uint64, error) {
+	if !c.running() {
+		return 0, ErrClosed
+	}
+
+	req := &rpcpb.ProphetRequest{}
+	req.Type = rpcpb.TypeUpdateGCSafePointReq
+	req.UpdateGCSafePoint.Group = group
+	req.UpdateGCSafePoint.SafePoint = safePoint
+
+	rsp, err := c.syncDo(req)
+	if err != nil {
+		return 0, err
+	}
+
+	return rsp.UpdateGCSafePoint.SafePoint, nil
+}
+
+func (c *asyncClient) GetGCSafePoint(group uint64) (uint64, error) {
+	if !c.running() {
+		return 0, ErrClosed
+	}
+
+	req := &rpcpb.ProphetRequest{}
+	req.Type = rpcpb.TypeGetGCSafePointReq
+	req.GetGCSafePoint.Group = group
+
+	rsp, err := c.syncDo(req)
+	if err != nil {
+		return 0, err
+	}
+
+	return rsp.GetGCSafePoint.SafePoint, nil
+}
+
+func (c *asyncClient) CancelRangeRelocation(id uint64) error {
+	if !c.running() {
+		return ErrClosed
+	}
+
+	req := &rpcpb.ProphetRequest{}
+	req.Type = rpcpb.TypeCancelRangeRelocationReq
+	req.CancelRangeRelocation.ID = id
+
+	_, err := c.syncDo(req)
+	return err
+}
+
 func (c *asyncClient) start() {
 	c.stopper.RunTask(context.Background(), c.readLoop)
 	c.stopper.RunTask(context.Background(), c.writeLoop)
@@ -558,7 +1136,18 @@ func (c *asyncClient) running() bool {
 }
 
 func (c *asyncClient) syncDo(req *rpcpb.ProphetRequest) (*rpcpb.ProphetResponse, error) {
-	for {
+	if c.opts.hedgedReadDelay > 0 && isFollowerReadable(req.Type) {
+		return c.hedgedDo(req)
+	}
+	return c.doWithRetry(req)
+}
+
+// doWithRetry sends the request to the leader, the request is retried with
+// backoff if the leader changed. ErrConnectionLost is only retried for the
+// read-only requests, since other requests may have been handled.
+func (c *asyncClient) doWithRetry(req *rpcpb.ProphetRequest) (*rpcpb.ProphetResponse, error) {
+	backoff := minRetryBackoff
+	for retries := 0; ; retries++ {
 		ctx := newSyncCtx(req)
 		if err := c.do(ctx); err != nil {
 			return nil, err
@@ -566,8 +1155,16 @@ func (c *asyncClient) syncDo(req *rpcpb.ProphetRequest) (*rpcpb.ProphetResponse,
 
 		ctx.wait()
 		if ctx.err != nil {
-			if ctx.err == util.ErrNotLeader {
-				time.Sleep(time.Millisecond * 100)
+			if ctx.err == util.ErrNotLeader ||
+				(ctx.err == ErrConnectionLost && isReadOnly(req.Type)) {
+				if retries >= c.opts.maxRetries {
+					return nil, &RetryExhaustedError{Retries: retries, Err: ctx.err}
+				}
+
+				time.Sleep(backoff)
+				if backoff *= 2; backoff > maxRetryBackoff {
+					backoff = maxRetryBackoff
+				}
 				continue
 			}
 
@@ -578,6 +1175,119 @@ func (c *asyncClient) syncDo(req *rpcpb.ProphetRequest) (*rpcpb.ProphetResponse,
 	}
 }
 
+type doResult struct {
+	resp *rpcpb.ProphetResponse
+	err  error
+}
+
+// hedgedDo sends the read-only request to the leader, and to a prophet follower
+// if the response of the leader is not received within the hedged read delay.
+// The first success response is returned.
+func (c *asyncClient) hedgedDo(req *rpcpb.ProphetRequest) (*rpcpb.ProphetResponse, error) {
+	hedged := *req
+	leaderC := make(chan doResult, 1)
+	go func() {
+		resp, err := c.doWithRetry(req)
+		leaderC <- doResult{resp: resp, err: err}
+	}()
+
+	timer := time.NewTimer(c.opts.hedgedReadDelay)
+	defer timer.Stop()
+	select {
+	case r := <-leaderC:
+		return r.resp, r.err
+	case <-timer.C:
+	}
+
+	var targets []string
+	if c.opts.hedgedReadTargets != nil {
+		targets = c.opts.hedgedReadTargets()
+	}
+	if len(targets) == 0 {
+		r := <-leaderC
+		return r.resp, r.err
+	}
+
+	hedgedC := make(chan doResult, 1)
+	go func() {
+		resp, err := c.doHedgedRead(targets[rand.Intn(len(targets))], &hedged)
+		hedgedC <- doResult{resp: resp, err: err}
+	}()
+
+	select {
+	case r := <-leaderC:
+		return r.resp, r.err
+	case r := <-hedgedC:
+		if r.err == nil {
+			return r.resp, nil
+		}
+		c.opts.logger.Debug("fail to do hedged read",
+			zap.String("type", req.Type.String()),
+			zap.Error(r.err))
+	}
+	r := <-leaderC
+	return r.resp, r.err
+}
+
+// doHedgedRead sends the read-only request to the prophet follower using a
+// separate connection.
+func (c *asyncClient) doHedgedRead(addr string, req *rpcpb.ProphetRequest) (*rpcpb.ProphetResponse, error) {
+	conn := createConn(c.opts.logger)
+	defer conn.Close()
+	if _, err := conn.Connect(addr, c.opts.rpcTimeout); err != nil {
+		return nil, err
+	}
+
+	// close the connection to stop reading if timeout
+	timer := time.AfterFunc(c.opts.rpcTimeout, func() {
+		conn.Close()
+	})
+	defer timer.Stop()
+
+	req.ID = c.nextID()
+	if err := conn.WriteAndFlush(req); err != nil {
+		return nil, err
+	}
+	for {
+		msg, err := conn.Read()
+		if err != nil {
+			return nil, err
+		}
+
+		resp := msg.(*rpcpb.ProphetResponse)
+		if resp.ID != req.ID {
+			continue
+		}
+		if resp.Error != "" {
+			return nil, errors.New(resp.Error)
+		}
+		return resp, nil
+	}
+}
+
+// isReadOnly returns true if the request doesn't change the state of prophet.
+func isReadOnly(t rpcpb.Type) bool {
+	switch t {
+	case rpcpb.TypeGetStoreReq,
+		rpcpb.TypeGetDestroyingReq,
+		rpcpb.TypeListDestroyingShardsReq,
+		rpcpb.TypeGetShardLabelsJobReq,
+		rpcpb.TypeListSnapshotProgressesReq,
+		rpcpb.TypeCheckShardStateReq,
+		rpcpb.TypeGetAppliedRulesReq,
+		rpcpb.TypeGetScheduleGroupRuleReq,
+		rpcpb.TypeGetClusterTopologyReq:
+		return true
+	}
+	return false
+}
+
+// isFollowerReadable returns true if the read-only request can be handled by the
+// prophet followers, the data is read from the storage of prophet.
+func isFollowerReadable(t rpcpb.Type) bool {
+	return t == rpcpb.TypeGetStoreReq
+}
+
 func (c *asyncClient) asyncDo(req *rpcpb.ProphetRequest, cb func(*rpcpb.ProphetResponse, error)) {
 	c.do(newAsyncCtx(req, cb))
 }
@@ -644,6 +1354,8 @@ func (c *asyncClient) nextID() uint64 {
 
 func (c *asyncClient) writeLoop(stopCtx context.Context) {
 	c.opts.logger.Info("write loop started")
+	leaderCheckTicker := time.NewTicker(c.opts.leaderCheckInterval)
+	defer leaderCheckTicker.Stop()
 	for {
 		select {
 		case <-stopCtx.Done():
@@ -654,6 +1366,8 @@ func (c *asyncClient) writeLoop(stopCtx context.Context) {
 			if ok {
 				c.doWrite(ctx)
 			}
+		case <-leaderCheckTicker.C:
+			c.checkLeaderChanged()
 		case _, ok := <-c.resetLeaderConnC:
 			if ok {
 				if err := c.resetLeaderConn(); err != nil {
@@ -689,6 +1403,12 @@ OUTER:
 						c.opts.logger.Error("fail to read resp from leader",
 							zap.String("leader", leader),
 							zap.Error(err))
+						// the responses of the inflight requests will never be received
+						if c.running() {
+							c.requestsDoneWithError(ErrConnectionLost)
+						} else {
+							c.requestsDoneWithError(ErrClosed)
+						}
 						if !c.scheduleResetLeaderConn() {
 							return
 						}
@@ -698,12 +1418,18 @@ OUTER:
 
 					resp := msg.(*rpcpb.ProphetResponse)
 					if resp.Error != "" && util.IsNotLeaderError(resp.Error) {
+						if resp.Leader != "" && resp.Leader != leader {
+							c.leaderHint.Store(resp.Leader)
+						}
 						if !c.scheduleResetLeaderConn() {
 							return
 						}
 
-						// retry
+						// the other inflight requests are also rejected by the
+						// follower, and the responses are not read anymore, retry
+						// all of them on the new leader.
 						c.requestDoneWithRetry(resp)
+						c.requestsDoneWithError(util.ErrNotLeader)
 						c.opts.logger.Info("read loop actived, ready to read from leader, exit, not leader")
 						continue OUTER
 					}
@@ -729,6 +1455,36 @@ func (c *asyncClient) requestDoneWithRetry(resp *rpcpb.ProphetResponse) {
 	}
 }
 
+// requestsDoneWithError completes all the inflight requests with the error.
+func (c *asyncClient) requestsDoneWithError(err error) {
+	c.contextsMu.Lock()
+	defer c.contextsMu.Unlock()
+
+	for id, ctx := range c.contextsMu.contexts {
+		delete(c.contextsMu.contexts, id)
+		ctx.done(nil, err)
+	}
+}
+
+// checkLeaderChanged closes the leader connection if the leader changed, then
+// the read loop resets the connection to the new leader.
+func (c *asyncClient) checkLeaderChanged() {
+	if c.leaderAddr == "" {
+		return
+	}
+
+	leader := c.opts.leaderGetter()
+	if leader == nil || leader.Addr == "" || leader.Addr == c.leaderAddr {
+		return
+	}
+
+	c.opts.logger.Info("prophet leader changed",
+		zap.String("from", c.leaderAddr),
+		zap.String("to", leader.Addr))
+	c.leaderAddr = ""
+	c.leaderConn.Close()
+}
+
 func (c *asyncClient) requestDone(resp *rpcpb.ProphetResponse) {
 	c.contextsMu.Lock()
 	defer c.contextsMu.Unlock()
@@ -756,6 +1512,12 @@ func (c *asyncClient) maybeAddShardHeartbeatResp(resp *rpcpb.ProphetResponse) bo
 }
 
 func (c *asyncClient) doWrite(ctx *ctx) {
+	// the request is completed while waiting to be written, e.g. failed due to
+	// the leader changed and retried with a new context.
+	if ctx.completed() {
+		return
+	}
+
 	err := c.leaderConn.Write(ctx.req)
 	if err != nil {
 		c.opts.logger.Error("fail to send request",
@@ -785,6 +1547,14 @@ func (c *asyncClient) initLeaderConn(conn goetty.IOSession, timeout time.Duratio
 			if leader != nil {
 				addr = leader.Addr
 			}
+			// fast failover to the leader returned by the follower, the leader
+			// getter may be not updated yet.
+			if registerStore {
+				if hint, ok := c.leaderHint.Load().(string); ok && hint != "" {
+					addr = hint
+					c.leaderHint.Store("")
+				}
+			}
 
 			if addr != "" {
 				c.opts.logger.Info("start connect to leader",
@@ -799,6 +1569,7 @@ func (c *asyncClient) initLeaderConn(conn goetty.IOSession, timeout time.Duratio
 					c.opts.logger.Info("connect to leader succeed",
 						zap.String("leader", addr))
 					if registerStore {
+						c.leaderAddr = addr
 						select {
 						case c.resetReadC <- addr:
 						default:
@@ -866,6 +1637,10 @@ func (c *ctx) done(resp *rpcpb.ProphetResponse, err error) {
 	}
 }
 
+func (c *ctx) completed() bool {
+	return atomic.LoadUint64(&c.state) == 1
+}
+
 func (c *ctx) wait() {
 	if c.sync {
 		<-c.c
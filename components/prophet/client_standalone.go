@@ -0,0 +1,736 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prophet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/RoaringBitmap/roaring/roaring64"
+	"github.com/matrixorigin/matrixcube/components/log"
+	"github.com/matrixorigin/matrixcube/components/prophet/cluster"
+	"github.com/matrixorigin/matrixcube/components/prophet/core"
+	"github.com/matrixorigin/matrixcube/components/prophet/event"
+	"github.com/matrixorigin/matrixcube/components/prophet/id"
+	"github.com/matrixorigin/matrixcube/components/prophet/storage"
+	"github.com/matrixorigin/matrixcube/components/prophet/util"
+	"github.com/matrixorigin/matrixcube/pb/metapb"
+	"github.com/matrixorigin/matrixcube/pb/rpcpb"
+	"github.com/matrixorigin/matrixcube/pb/txnpb"
+	"github.com/matrixorigin/matrixcube/util/stop"
+	"go.uber.org/zap"
+)
+
+var (
+	// ErrNotSupportedInStandalone the request is not supported by the standalone prophet
+	ErrNotSupportedInStandalone = errors.New("not supported in standalone mode")
+)
+
+const (
+	standaloneChangedEventLimit = 10000
+	standaloneWatcherEventLimit = 128
+)
+
+// standaloneClient is the client of the standalone prophet. The requests are
+// handled locally: the heartbeats update the cached cluster metadata, the new
+// shards are created with a single replica on the local store, and the changes
+// are notified to the watchers. It is a stub scheduler, no operator is created
+// and the placement and scheduling rules are ignored.
+type standaloneClient struct {
+	logger  *zap.Logger
+	idGen   id.Generator
+	storage storage.Storage
+	cluster *core.BasicCluster
+	stopper *stop.Stopper
+	eventC  chan rpcpb.EventNotify
+	// hbRspC never receives, there is no schedule command in standalone mode
+	hbRspC chan rpcpb.ShardHeartbeatRsp
+
+	// mu serializes the changes of the cluster metadata and the events
+	mu sync.RWMutex
+
+	watchers struct {
+		sync.Mutex
+		seq    uint64
+		closed bool
+		values map[uint64]*standaloneWatcher
+	}
+}
+
+func newStandaloneClient(idGen id.Generator, storage storage.Storage,
+	cluster *core.BasicCluster, logger *zap.Logger) *standaloneClient {
+	c := &standaloneClient{
+		logger:  log.Adjust(logger).Named("standalone-client"),
+		idGen:   idGen,
+		storage: storage,
+		cluster: cluster,
+		eventC:  make(chan rpcpb.EventNotify, standaloneChangedEventLimit),
+		hbRspC:  make(chan rpcpb.ShardHeartbeatRsp),
+	}
+	c.stopper = stop.NewStopper("standalone-client", stop.WithLogger(c.logger))
+	c.watchers.values = make(map[uint64]*standaloneWatcher)
+	return c
+}
+
+func (c *standaloneClient) start() {
+	c.stopper.RunTask(context.Background(), func(ctx context.Context) {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case evt := <-c.eventC:
+				c.doNotify(ctx, evt)
+			}
+		}
+	})
+}
+
+func (c *standaloneClient) Close() error {
+	c.stopper.Stop()
+
+	c.watchers.Lock()
+	defer c.watchers.Unlock()
+	c.watchers.closed = true
+	for _, w := range c.watchers.values {
+		c.doRemoveWatcherLocked(w)
+	}
+	return nil
+}
+
+func (c *standaloneClient) AllocID() (uint64, error) {
+	return c.idGen.AllocID()
+}
+
+func (c *standaloneClient) CreateDestroying(id uint64, index uint64, removeData bool, replicas []uint64) (metapb.ShardState, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.cluster.AlreadyRemoved(id) {
+		return metapb.ShardState_Destroyed, nil
+	}
+	if status := c.cluster.GetDestroyingStatus(id); status != nil {
+		return status.State, nil
+	}
+
+	now := time.Now().Unix()
+	status := &metapb.DestroyingStatus{
+		State:      metapb.ShardState_Destroying,
+		Index:      index,
+		Replicas:   make(map[uint64]bool),
+		RemoveData: removeData,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	for _, id := range replicas {
+		status.Replicas[id] = false
+	}
+	if err := c.saveDestroyingStatusLocked(id, status); err != nil {
+		return metapb.ShardState_Destroying, err
+	}
+	return status.State, nil
+}
+
+func (c *standaloneClient) ReportDestroyed(id uint64, replicaID uint64) (metapb.ShardState, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.cluster.AlreadyRemoved(id) {
+		return metapb.ShardState_Destroyed, nil
+	}
+	status := c.cluster.GetDestroyingStatus(id)
+	if status == nil {
+		return metapb.ShardState_Destroying, fmt.Errorf("missing destroying status of shard %d", id)
+	}
+	if status.State == metapb.ShardState_Destroyed {
+		return metapb.ShardState_Destroyed, nil
+	}
+	if v, ok := status.Replicas[replicaID]; !ok || v {
+		return status.State, nil
+	}
+
+	status.Replicas[replicaID] = true
+	status.UpdatedAt = time.Now().Unix()
+	n := 0
+	for _, destroyed := range status.Replicas {
+		if destroyed {
+			n++
+		}
+	}
+	if n == len(status.Replicas) {
+		status.State = metapb.ShardState_Destroyed
+		status.Replicas = nil
+	}
+	if err := c.saveDestroyingStatusLocked(id, status); err != nil {
+		return metapb.ShardState_Destroying, err
+	}
+	return status.State, nil
+}
+
+func (c *standaloneClient) GetDestroying(id uint64) (*metapb.DestroyingStatus, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.cluster.GetDestroyingStatus(id), nil
+}
+
+func (c *standaloneClient) ListDestroyingShards() ([]rpcpb.DestroyingShard, error) {
+	return nil, ErrNotSupportedInStandalone
+}
+
+func (c *standaloneClient) DetectDeadlock(edge txnpb.WaitForEdge) (bool, error) {
+	return false, ErrNotSupportedInStandalone
+}
+
+func (c *standaloneClient) CleanUpWaitFor(txnID []byte) error {
+	return ErrNotSupportedInStandalone
+}
+
+func (c *standaloneClient) UpdateShardLabels(req rpcpb.UpdateShardLabelsReq) (rpcpb.ShardLabelsJob, error) {
+	return rpcpb.ShardLabelsJob{}, ErrNotSupportedInStandalone
+}
+
+func (c *standaloneClient) GetShardLabelsJob(id uint64) (rpcpb.ShardLabelsJob, error) {
+	return rpcpb.ShardLabelsJob{}, ErrNotSupportedInStandalone
+}
+
+func (c *standaloneClient) ListSnapshotProgresses(storeID, shardID uint64) ([]metapb.SnapshotProgress, error) {
+	return nil, ErrNotSupportedInStandalone
+}
+
+func (c *standaloneClient) SetStoreMaintenance(storeID uint64, maintenance bool) error {
+	return ErrNotSupportedInStandalone
+}
+
+func (c *standaloneClient) UpdateEvictLeaderStores(storeID uint64, remove bool) ([]uint64, error) {
+	return nil, ErrNotSupportedInStandalone
+}
+
+func (c *standaloneClient) ScatterShards(shardIDs []uint64) ([]uint64, error) {
+	return nil, ErrNotSupportedInStandalone
+}
+
+func (c *standaloneClient) saveDestroyingStatusLocked(id uint64, status *metapb.DestroyingStatus) error {
+	if status.State == metapb.ShardState_Destroyed {
+		c.cluster.AddRemovedShards(id)
+		if res := c.cluster.GetShard(id); res != nil {
+			res.Meta.SetState(metapb.ShardState_Destroyed)
+			if err := c.storage.PutShard(res.Meta); err != nil {
+				return err
+			}
+		}
+	}
+	c.cluster.UpdateDestroyingStatus(id, status)
+	return nil
+}
+
+func (c *standaloneClient) PutStore(store metapb.Store) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.storage.PutStore(store); err != nil {
+		return err
+	}
+	c.cluster.PutStore(core.NewCachedStore(store))
+	c.addNotifyLocked(event.NewStoreEvent(store))
+	return nil
+}
+
+func (c *standaloneClient) GetStore(storeID uint64) (*metapb.Store, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if s := c.cluster.GetStore(storeID); s != nil {
+		meta := s.Meta
+		return &meta, nil
+	}
+	return nil, fmt.Errorf("store %d not found", storeID)
+}
+
+func (c *standaloneClient) ShardHeartbeat(meta metapb.Shard, hb rpcpb.ShardHeartbeatReq) error {
+	res := core.ShardFromHeartbeat(hb, meta)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.cluster.AlreadyRemoved(res.Meta.GetID()) {
+		return nil
+	}
+	origin, err := c.cluster.PreCheckPutShard(res)
+	if err != nil {
+		return err
+	}
+
+	// keep the destroying state until destroyed, see RaftCluster.processShardHeartbeat
+	if origin != nil && origin.Meta.GetState() == metapb.ShardState_Destroying &&
+		res.Meta.GetState() != metapb.ShardState_Destroying {
+		res = res.Clone(core.WithState(metapb.ShardState_Destroying))
+	}
+
+	changed := origin == nil
+	if origin != nil {
+		r, o := res.Meta.GetEpoch(), origin.Meta.GetEpoch()
+		changed = r.GetGeneration() != o.GetGeneration() ||
+			r.GetConfigVer() != o.GetConfigVer() ||
+			res.GetLeader().GetID() != origin.GetLeader().GetID() ||
+			res.Meta.GetState() != origin.Meta.GetState()
+	}
+	c.cluster.PutShard(res)
+	if changed {
+		if err := c.storage.PutShard(res.Meta); err != nil {
+			c.logger.Error("fail to save shard",
+				log.ShardIDField(res.Meta.GetID()),
+				zap.Error(err))
+		}
+		c.addNotifyLocked(event.NewShardEvent(res.Meta, res.GetLeader().GetID(), false, false))
+	}
+	c.addNotifyLocked(event.NewShardStatsEvent(res.GetStat()))
+	return nil
+}
+
+func (c *standaloneClient) StoreHeartbeat(hb rpcpb.StoreHeartbeatReq) (rpcpb.StoreHeartbeatRsp, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	s := c.cluster.GetStore(hb.Stats.GetStoreID())
+	if s == nil {
+		return rpcpb.StoreHeartbeatRsp{}, fmt.Errorf("store %d not found", hb.Stats.GetStoreID())
+	}
+	c.cluster.PutStore(s.Clone(core.SetStoreStats(&hb.Stats), core.SetLastHeartbeatTS(time.Now())))
+	c.addNotifyLocked(event.NewStoreStatsEvent(&hb.Stats))
+	return rpcpb.StoreHeartbeatRsp{}, nil
+}
+
+func (c *standaloneClient) AskBatchSplit(res metapb.Shard, count uint32) ([]rpcpb.SplitID, error) {
+	splitIDs := make([]rpcpb.SplitID, 0, count)
+	for i := uint32(0); i < count; i++ {
+		newID, err := c.idGen.AllocID()
+		if err != nil {
+			return nil, err
+		}
+
+		replicaIDs := make([]uint64, len(res.GetReplicas()))
+		for j := range replicaIDs {
+			if replicaIDs[j], err = c.idGen.AllocID(); err != nil {
+				return nil, err
+			}
+		}
+		splitIDs = append(splitIDs, rpcpb.SplitID{
+			NewID:         newID,
+			NewReplicaIDs: replicaIDs,
+		})
+	}
+	return splitIDs, nil
+}
+
+func (c *standaloneClient) NewWatcher(flag uint32) (EventWatcher, error) {
+	// no event can be added while the init snapshot is taken and the watcher
+	// is added
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	var initEvent *rpcpb.EventNotify
+	if event.MatchEvent(event.InitEvent, flag) {
+		snap := event.Snapshot{
+			Leaders: make(map[uint64]uint64),
+		}
+		for _, s := range c.cluster.GetStores() {
+			snap.Stores = append(snap.Stores, s.Meta)
+		}
+		for _, res := range c.cluster.GetShards() {
+			snap.Shards = append(snap.Shards, res.Meta)
+			if leader := res.GetLeader(); leader != nil {
+				snap.Leaders[res.Meta.GetID()] = leader.ID
+			}
+		}
+
+		data, err := event.NewInitEvent(snap)
+		if err != nil {
+			return nil, err
+		}
+		initEvent = &rpcpb.EventNotify{Type: event.InitEvent, InitEvent: data}
+	}
+
+	c.watchers.Lock()
+	defer c.watchers.Unlock()
+	if c.watchers.closed {
+		return nil, ErrClosed
+	}
+
+	c.watchers.seq++
+	ctx, cancel := context.WithCancel(context.Background())
+	w := &standaloneWatcher{
+		id:     c.watchers.seq,
+		ctx:    ctx,
+		cancel: cancel,
+		flag:   flag,
+		client: c,
+		eventC: make(chan rpcpb.EventNotify, standaloneWatcherEventLimit),
+	}
+	if initEvent != nil {
+		w.eventC <- *initEvent
+	}
+	c.watchers.values[w.id] = w
+	return w, nil
+}
+
+func (c *standaloneClient) GetShardHeartbeatRspNotifier() (chan rpcpb.ShardHeartbeatRsp, error) {
+	return c.hbRspC, nil
+}
+
+func (c *standaloneClient) AsyncAddShards(shards ...metapb.Shard) error {
+	return c.addShards(shards)
+}
+
+func (c *standaloneClient) AsyncAddShardsWithLeastPeers(shards []metapb.Shard, leastPeers []int) error {
+	return c.addShards(shards)
+}
+
+func (c *standaloneClient) AsyncBulkAddShards(shards ...metapb.Shard) error {
+	return c.addShards(shards)
+}
+
+// addShards creates the shards with a single replica on the local store, the
+// shards with the same unique as the created shards are skipped.
+func (c *standaloneClient) addShards(shards []metapb.Shard) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var storeID uint64
+	for _, s := range c.cluster.GetStores() {
+		if !s.IsTombstone() {
+			storeID = s.Meta.GetID()
+			break
+		}
+	}
+	if storeID == 0 {
+		return fmt.Errorf("no store available to create shards")
+	}
+
+	uniques := make(map[string]struct{})
+	for _, res := range c.cluster.GetShards() {
+		if unique := res.Meta.GetUnique(); unique != "" {
+			uniques[unique] = struct{}{}
+		}
+	}
+	c.cluster.ForeachWaitingCreateShards(func(res metapb.Shard) {
+		if unique := res.GetUnique(); unique != "" {
+			uniques[unique] = struct{}{}
+		}
+	})
+
+	var created []metapb.Shard
+	for _, res := range shards {
+		if len(res.GetReplicas()) > 0 {
+			return fmt.Errorf("cann't assign peers in create resources")
+		}
+		if unique := res.GetUnique(); unique != "" {
+			if _, ok := uniques[unique]; ok {
+				c.logger.Info("shard already created",
+					zap.String("unique", unique))
+				continue
+			}
+			uniques[unique] = struct{}{}
+		}
+
+		shardID, err := c.idGen.AllocID()
+		if err != nil {
+			return err
+		}
+		replicaID, err := c.idGen.AllocID()
+		if err != nil {
+			return err
+		}
+		res.SetID(shardID)
+		res.SetState(metapb.ShardState_Creating)
+		res.SetEpoch(metapb.ShardEpoch{ConfigVer: 1})
+		res.SetReplicas([]metapb.Replica{{ID: replicaID, StoreID: storeID, InitialMember: true}})
+		if _, err := c.cluster.PreCheckPutShard(core.NewCachedShard(res, nil)); err != nil {
+			return err
+		}
+		created = append(created, res)
+	}
+	if len(created) == 0 {
+		return nil
+	}
+
+	if err := c.storage.PutShards(created...); err != nil {
+		return err
+	}
+	c.cluster.AddWaitingCreateShards(created...)
+	if len(created) == 1 {
+		c.addNotifyLocked(event.NewShardEvent(created[0], 0, false, true))
+		return nil
+	}
+	evt, err := event.NewCreateShardsEvent(created)
+	if err != nil {
+		return err
+	}
+	c.addNotifyLocked(evt)
+	return nil
+}
+
+func (c *standaloneClient) AsyncRemoveShards(ids ...uint64) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var targets []metapb.Shard
+	for _, id := range ids {
+		if c.cluster.AlreadyRemoved(id) {
+			continue
+		}
+
+		v := c.cluster.GetShard(id)
+		if v == nil {
+			return fmt.Errorf("shard %d not found", id)
+		}
+		res := v.Meta
+		res.SetState(metapb.ShardState_Destroyed)
+		targets = append(targets, res)
+	}
+	if err := c.storage.PutShards(targets...); err != nil {
+		return err
+	}
+
+	c.cluster.AddRemovedShards(ids...)
+	for _, shard := range targets {
+		c.addNotifyLocked(event.NewShardEvent(shard, 0, true, false))
+	}
+	return nil
+}
+
+func (c *standaloneClient) AsyncDestroyShards(group uint64, start, end []byte, labels []metapb.Label, removeData bool) ([]uint64, error) {
+	req := rpcpb.DestroyShardsReq{
+		Group:      group,
+		Start:      start,
+		End:        end,
+		Labels:     labels,
+		RemoveData: removeData,
+	}
+	if len(start) == 0 && len(end) == 0 && len(labels) == 0 {
+		return nil, fmt.Errorf("missing key range or labels to match the destroy shards")
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var ids []uint64
+	for _, res := range c.cluster.GetShards() {
+		id := res.Meta.GetID()
+		if !cluster.MatchDestroyShards(req, res.Meta) || c.cluster.AlreadyRemoved(id) {
+			continue
+		}
+
+		ids = append(ids, id)
+		if res.Meta.GetState() == metapb.ShardState_Destroying ||
+			c.cluster.GetDestroyingStatus(id) != nil {
+			continue
+		}
+
+		res = res.Clone(core.WithState(metapb.ShardState_Destroying))
+		now := time.Now().Unix()
+		status := &metapb.DestroyingStatus{
+			State:      metapb.ShardState_Destroying,
+			Replicas:   make(map[uint64]bool),
+			RemoveData: removeData,
+			CreatedAt:  now,
+			UpdatedAt:  now,
+		}
+		for _, r := range res.Meta.GetReplicas() {
+			status.Replicas[r.ID] = false
+		}
+		if err := c.storage.PutShard(res.Meta); err != nil {
+			return nil, err
+		}
+		c.cluster.PutShard(res)
+		c.cluster.UpdateDestroyingStatus(id, status)
+		c.addNotifyLocked(event.NewShardEvent(res.Meta, res.GetLeader().GetID(), false, false))
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return ids[i] < ids[j]
+	})
+	return ids, nil
+}
+
+func (c *standaloneClient) CheckShardState(shards *roaring64.Bitmap) (rpcpb.CheckShardStateRsp, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	destroyed, destroying := c.cluster.GetDestroyShards(shards)
+	return rpcpb.CheckShardStateRsp{
+		Destroyed:  util.MustMarshalBM64(destroyed),
+		Destroying: util.MustMarshalBM64(destroying),
+	}, nil
+}
+
+// PutPlacementRule does nothing, there is no scheduling in standalone mode
+func (c *standaloneClient) PutPlacementRule(rule rpcpb.PlacementRule) error {
+	return nil
+}
+
+func (c *standaloneClient) PutPlacementRuleGroupBundle(bundle rpcpb.PlacementRuleGroupBundle) (uint64, error) {
+	return 0, ErrNotSupportedInStandalone
+}
+
+func (c *standaloneClient) GetPlacementRuleGroupBundle(id string) (rpcpb.PlacementRuleGroupBundle, error) {
+	return rpcpb.PlacementRuleGroupBundle{}, ErrNotSupportedInStandalone
+}
+
+func (c *standaloneClient) DeletePlacementRuleGroupBundle(id string, version uint64) error {
+	return ErrNotSupportedInStandalone
+}
+
+func (c *standaloneClient) GetAppliedRules(id uint64) ([]rpcpb.PlacementRule, error) {
+	return nil, nil
+}
+
+// AddSchedulingRule does nothing, there is no scheduling in standalone mode
+func (c *standaloneClient) AddSchedulingRule(groupID uint64, ruleName string, labelName string) error {
+	return nil
+}
+
+func (c *standaloneClient) GetSchedulingRules() ([]metapb.ScheduleGroupRule, error) {
+	return nil, nil
+}
+
+func (c *standaloneClient) CreateJob(metapb.Job) error {
+	return ErrNotSupportedInStandalone
+}
+
+func (c *standaloneClient) RemoveJob(metapb.Job) error {
+	return ErrNotSupportedInStandalone
+}
+
+func (c *standaloneClient) ExecuteJob(metapb.Job, []byte) ([]byte, error) {
+	return nil, ErrNotSupportedInStandalone
+}
+
+func (c *standaloneClient) UpdateScheduleConfig(changes []byte, rollbackWindow time.Duration, rollbackMaxOperators uint64) ([]byte, error) {
+	return nil, ErrNotSupportedInStandalone
+}
+
+func (c *standaloneClient) GetClusterTopology(zoneLabel, hostLabel string) ([]rpcpb.TopologyZone, error) {
+	return nil, ErrNotSupportedInStandalone
+}
+
+func (c *standaloneClient) SetPreferredLeader(shardID, storeID uint64) error {
+	return ErrNotSupportedInStandalone
+}
+
+func (c *standaloneClient) GetShardRoute(req rpcpb.GetShardRouteReq) (rpcpb.GetShardRouteRsp, error) {
+	return rpcpb.GetShardRouteRsp{}, ErrNotSupportedInStandalone
+}
+
+func (c *standaloneClient) RelocateRange(group uint64, start, end []byte, stores []uint64) (uint64, error) {
+	return 0, ErrNotSupportedInStandalone
+}
+
+func (c *standaloneClient) GetRangeRelocation(id uint64) (rpcpb.GetRangeRelocationRsp, error) {
+	return rpcpb.GetRangeRelocationRsp{}, ErrNotSupportedInStandalone
+}
+
+func (c *standaloneClient) CancelLabelSteering(id uint64) error {
+	return ErrNotSupportedInStandalone
+}
+
+// UpdateGCSafePoint advances the GC safe point of the shard group in the storage,
+// the stores of the standalone mode drop the filtered data too.
+func (c *standaloneClient) UpdateGCSafePoint(group, safePoint uint64) (uint64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	current, err := c.storage.GetGCSafePoint(group)
+	if err != nil {
+		return 0, err
+	}
+	if safePoint <= current {
+		return current, nil
+	}
+	if err := c.storage.PutGCSafePoint(group, safePoint); err != nil {
+		return 0, err
+	}
+	return safePoint, nil
+}
+
+func (c *standaloneClient) GetGCSafePoint(group uint64) (uint64, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.storage.GetGCSafePoint(group)
+}
+
+func (c *standaloneClient) CancelRangeRelocation(id uint64) error {
+	return ErrNotSupportedInStandalone
+}
+
+func (c *standaloneClient) addNotifyLocked(evt rpcpb.EventNotify) {
+	c.eventC <- evt
+}
+
+func (c *standaloneClient) doNotify(ctx context.Context, evt rpcpb.EventNotify) {
+	c.watchers.Lock()
+	defer c.watchers.Unlock()
+
+	for _, w := range c.watchers.values {
+		w.notify(ctx, evt)
+	}
+}
+
+func (c *standaloneClient) removeWatcher(w *standaloneWatcher) {
+	c.watchers.Lock()
+	defer c.watchers.Unlock()
+
+	if _, ok := c.watchers.values[w.id]; ok {
+		c.doRemoveWatcherLocked(w)
+	}
+}
+
+func (c *standaloneClient) doRemoveWatcherLocked(w *standaloneWatcher) {
+	delete(c.watchers.values, w.id)
+	w.cancel()
+	close(w.eventC)
+}
+
+type standaloneWatcher struct {
+	id     uint64
+	ctx    context.Context
+	cancel context.CancelFunc
+	flag   uint32
+	client *standaloneClient
+	eventC chan rpcpb.EventNotify
+}
+
+func (w *standaloneWatcher) GetNotify() chan rpcpb.EventNotify {
+	return w.eventC
+}
+
+func (w *standaloneWatcher) Close() {
+	// cancel first to unblock the pending notify
+	w.cancel()
+	w.client.removeWatcher(w)
+}
+
+func (w *standaloneWatcher) notify(ctx context.Context, evt rpcpb.EventNotify) {
+	if !event.MatchEvent(evt.Type, w.flag) {
+		return
+	}
+	select {
+	case w.eventC <- evt:
+	case <-w.ctx.Done():
+	case <-ctx.Done():
+	}
+}
@@ -14,6 +14,8 @@
 package prophet
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"testing"
 	"time"
@@ -154,6 +156,43 @@ func TestScheduleGroupRule(t *testing.T) {
 	assert.Equal(t, 10, len(rules))
 }
 
+func TestUpdateScheduleConfig(t *testing.T) {
+	p := newTestSingleProphet(t, nil)
+	defer p.Stop()
+
+	c := p.GetClient()
+	data, err := c.UpdateScheduleConfig([]byte(`{"max-merge-resource-size": 100}`), 0, 0)
+	assert.NoError(t, err)
+	cfg := &config.ScheduleConfig{}
+	assert.NoError(t, json.Unmarshal(data, cfg))
+	assert.Equal(t, uint64(100), cfg.MaxMergeShardSize)
+	assert.Equal(t, uint64(100), p.(*defaultProphet).GetPersistOptions().GetMaxMergeShardSize())
+
+	_, err = c.UpdateScheduleConfig([]byte(`{"unknown": 100}`), 0, 0)
+	assert.Error(t, err)
+}
+
+func TestGetClusterTopology(t *testing.T) {
+	p := newTestSingleProphet(t, nil)
+	defer p.Stop()
+
+	c := p.GetClient()
+	for id := uint64(1); id <= 2; id++ {
+		s := newTestStoreMeta(id)
+		s.Labels = []metapb.Label{{Key: "zone", Value: "z1"}, {Key: "host", Value: fmt.Sprintf("h%d", id)}}
+		assert.NoError(t, c.PutStore(s))
+	}
+
+	topology, err := c.GetClusterTopology("", "")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(topology))
+	assert.Equal(t, "z1", topology[0].Name)
+	assert.Equal(t, 2, len(topology[0].Hosts))
+	assert.Equal(t, "h2", topology[0].Hosts[1].Name)
+	assert.Equal(t, uint64(2), topology[0].Hosts[1].Stores[0].Store.ID)
+	assert.Equal(t, "127.0.0.1:2", topology[0].Hosts[1].Stores[0].Store.ClientAddress)
+}
+
 func TestPutPlacementRule(t *testing.T) {
 	p := newTestSingleProphet(t, nil)
 	defer p.Stop()
@@ -188,6 +227,33 @@ func TestPutPlacementRule(t *testing.T) {
 	assert.Equal(t, 1, len(rules))
 }
 
+func TestPlacementRuleGroupBundle(t *testing.T) {
+	p := newTestSingleProphet(t, nil)
+	defer p.Stop()
+
+	c := p.GetClient()
+	bundle := rpcpb.PlacementRuleGroupBundle{
+		ID:    "group01",
+		Index: 1,
+		Rules: []rpcpb.PlacementRule{{GroupID: "group01", ID: "rule01", Count: 3}},
+	}
+	version, err := c.PutPlacementRuleGroupBundle(bundle)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), version)
+	_, err = c.PutPlacementRuleGroupBundle(bundle)
+	assert.Error(t, err)
+
+	v, err := c.GetPlacementRuleGroupBundle("group01")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), v.Version)
+	assert.Equal(t, 1, len(v.Rules))
+
+	assert.Error(t, c.DeletePlacementRuleGroupBundle("group01", 0))
+	assert.NoError(t, c.DeletePlacementRuleGroupBundle("group01", 1))
+	_, err = c.GetPlacementRuleGroupBundle("group01")
+	assert.Error(t, err)
+}
+
 func TestIssue106(t *testing.T) {
 	clusterSize := 3
 	cluster := newTestClusterProphet(t, clusterSize, func(c *config.Config) {
@@ -254,6 +320,70 @@ func TestIssue112(t *testing.T) {
 	}
 }
 
+func TestClientRetryExhausted(t *testing.T) {
+	p := newTestSingleProphet(t, nil)
+	defer p.Stop()
+
+	c := NewClient(WithLeaderGetter(p.GetLeader), WithMaxRetries(2))
+	defer c.Close()
+	id, err := c.AllocID()
+	assert.NoError(t, err)
+	assert.True(t, id > 0)
+
+	p.GetConfig().TestContext.EnableResponseNotLeader()
+	_, err = c.AllocID()
+	var retryErr *RetryExhaustedError
+	if assert.True(t, errors.As(err, &retryErr)) {
+		assert.Equal(t, 2, retryErr.Retries)
+	}
+	assert.True(t, errors.Is(err, util.ErrNotLeader))
+
+	p.GetConfig().TestContext.DisableResponseNotLeader()
+	id, err = c.AllocID()
+	assert.NoError(t, err)
+	assert.True(t, id > 0)
+}
+
+func TestClientHedgedRead(t *testing.T) {
+	clusterSize := 3
+	cluster := newTestClusterProphet(t, clusterSize, nil)
+	defer func() {
+		for _, p := range cluster {
+			p.Stop()
+		}
+	}()
+
+	leader := findProphetLeader(t, cluster, clusterSize)
+	assert.NotNil(t, leader)
+	var follower Prophet
+	for _, p := range cluster {
+		if p != leader {
+			follower = p
+			break
+		}
+	}
+
+	assert.NoError(t, leader.GetClient().PutStore(newTestStoreMeta(1)))
+
+	c := NewClient(WithLeaderGetter(leader.GetLeader),
+		WithHedgedReads(time.Millisecond*50, func() []string {
+			return []string{follower.GetConfig().AdvertiseRPCAddr}
+		}))
+	defer c.Close()
+
+	// the leader doesn't response, the store is read from the follower
+	leader.GetConfig().TestContext.EnableSkipResponse()
+	defer leader.GetConfig().TestContext.DisableSkipResponse()
+	start := time.Now()
+	store, err := c.GetStore(1)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), store.ID)
+	assert.True(t, time.Since(start) < time.Second*5)
+
+	_, err = c.GetStore(100)
+	assert.Error(t, err)
+}
+
 func newTestShardMeta(resourceID uint64, peers ...metapb.Replica) metapb.Shard {
 	return metapb.Shard{
 		ID:       resourceID,
@@ -54,6 +54,13 @@ const (
 	// since the once the store is added or removed, we shouldn't return an error even if the store limit is failed to persist.
 	persistLimitRetryTimes = 5
 	persistLimitWaitTime   = 100 * time.Millisecond
+	// bulkCreateShardsBatchSize is the number of shards persisted in one etcd
+	// txn when creating shards in bulk, it must be less than the max txn ops
+	// of etcd.
+	bulkCreateShardsBatchSize = 64
+	// destroyShardsBatchSize is the number of shards marked as destroying in one
+	// etcd txn, the meta and the destroying status are saved for each shard.
+	destroyShardsBatchSize = bulkCreateShardsBatchSize / 2
 )
 
 var (
@@ -103,6 +110,21 @@ type RaftCluster struct {
 	coordinator      *coordinator
 	suspectShards    *cache.TTLUint64 // suspectShards are resources that may need fix
 	suspectKeyRanges *cache.TTLString // suspect key-range resources that may need fix
+	// duplicateShards shard id -> the duplicate shard overlapped by another shard
+	// with a newer epoch
+	duplicateShards map[uint64]*duplicateShard
+	// stuckDestroyings shard id -> the update time of the destroying status when
+	// the shard is found stuck
+	stuckDestroyings map[uint64]int64
+	deadlockDetector *deadlockDetector
+	// shardLabelsJobs job id -> the job updating the labels of many shards
+	shardLabelsJobs  map[uint64]*shardLabelsJob
+	shardLabelsJobID uint64
+	// labelSteerings steering id -> the labels kept added to the shards of the
+	// key range
+	labelSteerings map[uint64]metapb.LabelSteering
+	// evictLeaderMu serializes the updates of the evict leader stores
+	evictLeaderMu sync.Mutex
 
 	wg   sync.WaitGroup
 	quit chan struct{}
@@ -152,6 +174,11 @@ func (c *RaftCluster) InitCluster(opt *config.PersistOptions, storage storage.St
 	c.prepareChecker = newPrepareChecker()
 	c.suspectShards = cache.NewIDTTL(c.ctx, time.Minute, 3*time.Minute)
 	c.suspectKeyRanges = cache.NewStringTTL(c.ctx, time.Minute, 3*time.Minute)
+	c.duplicateShards = make(map[uint64]*duplicateShard)
+	c.stuckDestroyings = make(map[uint64]int64)
+	c.deadlockDetector = newDeadlockDetector(defaultWaitForEdgeTTL)
+	c.shardLabelsJobs = make(map[uint64]*shardLabelsJob)
+	c.labelSteerings = make(map[uint64]metapb.LabelSteering)
 
 	c.changedEvents = make(chan rpcpb.EventNotify, defaultChangedEventLimit)
 	c.createShardC = make(chan struct{}, 1)
@@ -234,6 +261,45 @@ func (c *RaftCluster) LoadClusterInfo() (*RaftCluster, error) {
 	c.logger.Info("resource group rules loaded",
 		zap.Int("count", c.core.GetShardGroupRuleCount()),
 		zap.Duration("cost", time.Since(start)))
+
+	// load preferred leaders
+	start = time.Now()
+	count := 0
+	if err := c.storage.LoadPreferredLeaders(batch, func(shardID, storeID uint64) {
+		c.core.SetShardPreferredLeader(shardID, storeID)
+		count++
+	}); err != nil {
+		return nil, err
+	}
+	c.logger.Info("preferred leaders loaded",
+		zap.Int("count", count),
+		zap.Duration("cost", time.Since(start)))
+
+	// load range relocations
+	start = time.Now()
+	count = 0
+	if err := c.storage.LoadRangeRelocations(batch, func(r metapb.RangeRelocation) {
+		c.core.PutRangeRelocation(r)
+		count++
+	}); err != nil {
+		return nil, err
+	}
+	c.logger.Info("range relocations loaded",
+		zap.Int("count", count),
+		zap.Duration("cost", time.Since(start)))
+
+	// load label steerings
+	start = time.Now()
+	count = 0
+	if err := c.storage.LoadLabelSteerings(batch, func(r metapb.LabelSteering) {
+		c.labelSteerings[r.ID] = r
+		count++
+	}); err != nil {
+		return nil, err
+	}
+	c.logger.Info("label steerings loaded",
+		zap.Int("count", count),
+		zap.Duration("cost", time.Since(start)))
 	return c, nil
 }
 
@@ -266,6 +332,9 @@ func (c *RaftCluster) runBackgroundJobs(interval time.Duration) {
 			c.collectMetrics()
 			c.coordinator.opController.PruneHistory()
 			c.doNotifyCreateShards()
+			c.checkDestroyingShards()
+			c.pruneDuplicateShards()
+			c.checkShardLabelsJobs()
 		case <-c.createShardC:
 			c.doNotifyCreateShards()
 		}
@@ -315,7 +384,7 @@ func (c *RaftCluster) IsRunning() bool {
 	return c.running
 }
 
-//GetScheduleGroupKeys returns group keys
+// GetScheduleGroupKeys returns group keys
 func (c *RaftCluster) GetScheduleGroupKeys() []string {
 	c.RLock()
 	defer c.RUnlock()
@@ -433,6 +502,15 @@ func (c *RaftCluster) ClearSuspectKeyRanges() {
 
 // HandleStoreHeartbeat updates the container status.
 func (c *RaftCluster) HandleStoreHeartbeat(stats *metapb.StoreStats) error {
+	if err := c.doHandleStoreHeartbeat(stats); err != nil {
+		return err
+	}
+
+	c.maybeShedLeaders(stats.GetStoreID())
+	return nil
+}
+
+func (c *RaftCluster) doHandleStoreHeartbeat(stats *metapb.StoreStats) error {
 	c.Lock()
 	defer c.Unlock()
 
@@ -441,6 +519,8 @@ func (c *RaftCluster) HandleStoreHeartbeat(stats *metapb.StoreStats) error {
 	if container == nil {
 		return fmt.Errorf("container %v not found", containerID)
 	}
+	// the maintenance mode is only known by the prophet
+	stats.Maintenance = container.IsMaintenance()
 	newStore := container.Clone(core.SetStoreStats(stats), core.SetLastHeartbeatTS(time.Now()))
 	if newStore.IsLowSpace(c.opt.GetLowSpaceRatio()) {
 		c.logger.Warn("container does not have enough disk space, capacity %d, available %d",
@@ -478,12 +558,150 @@ func (c *RaftCluster) HandleStoreHeartbeat(stats *metapb.StoreStats) error {
 	return nil
 }
 
+// getDuplicateShardOverlap returns the shard overlapping the reported shard
+// with a newer epoch, if the reported shard is not in the cache. The shards in
+// the cache never overlap, so the reported shard is a duplicate created by the
+// create or split race, or the parent of a split reporting the range before
+// split.
+func (c *RaftCluster) getDuplicateShardOverlap(res *core.CachedShard) *core.CachedShard {
+	if c.core.GetShard(res.Meta.GetID()) != nil {
+		return nil
+	}
+	for _, item := range c.core.GetOverlaps(res) {
+		if core.IsOverlappedByNewer(res, item) {
+			return item
+		}
+	}
+	return nil
+}
+
+// duplicateShard is a shard overlapped by another shard with a newer epoch.
+type duplicateShard struct {
+	// foundAt the time the shard is found overlapped
+	foundAt time.Time
+	// reportedAt the time the shard reported the overlapped range last time
+	reportedAt time.Time
+}
+
+// handleDuplicateShard handles the duplicate shard overlapped by another shard
+// with a newer epoch. The duplicate shard is destroyed if it keeps reporting
+// the overlapped range after the destroy delay, which is long enough for the
+// lagging parent of a split to report the new range.
+func (c *RaftCluster) handleDuplicateShard(res, overlap *core.CachedShard, err error) error {
+	c.Lock()
+	defer c.Unlock()
+
+	id := res.Meta.GetID()
+	now := time.Now()
+	dup, ok := c.duplicateShards[id]
+	if !ok {
+		c.duplicateShards[id] = &duplicateShard{foundAt: now, reportedAt: now}
+		c.logger.Warn("duplicate shard found",
+			log.ShardField("shard", res.Meta),
+			log.ShardField("overlap", overlap.Meta))
+		evt, e := event.NewDuplicateShardEvent(res.Meta, overlap.Meta)
+		if e != nil {
+			c.logger.Error("failed to create the duplicate shard event",
+				zap.Uint64("shard", id),
+				zap.Error(e))
+		} else {
+			c.addNotifyLocked(evt)
+		}
+		resourceEventCounter.WithLabelValues("duplicate").Inc()
+		return err
+	}
+	dup.reportedAt = now
+	if now.Sub(dup.foundAt) < c.opt.GetDuplicateShardDestroyDelay() {
+		return err
+	}
+
+	c.logger.Warn("destroy duplicate shard",
+		log.ShardField("shard", res.Meta),
+		log.ShardField("overlap", overlap.Meta))
+	resourceEventCounter.WithLabelValues("destroy_duplicate").Inc()
+	return errShardDestroyed
+}
+
+// pruneDuplicateShards removes the duplicate shards not reporting the overlapped
+// range for the destroy delay, e.g. the destroyed duplicate shards.
+func (c *RaftCluster) pruneDuplicateShards() {
+	c.Lock()
+	defer c.Unlock()
+
+	now := time.Now()
+	delay := c.opt.GetDuplicateShardDestroyDelay()
+	for id, dup := range c.duplicateShards {
+		if now.Sub(dup.reportedAt) >= delay {
+			delete(c.duplicateShards, id)
+		}
+	}
+}
+
+// checkDestroyingShards alerts the shards in the Destroying state without
+// progress for the stuck timeout, once until a replica confirms the destroying.
+func (c *RaftCluster) checkDestroyingShards() {
+	c.Lock()
+	defer c.Unlock()
+
+	shards, err := c.listDestroyingShardsLocked(time.Now())
+	if err != nil {
+		c.logger.Error("failed to list the destroying shards",
+			zap.Error(err))
+		return
+	}
+
+	destroying := make(map[uint64]struct{}, len(shards))
+	for _, s := range shards {
+		destroying[s.ID] = struct{}{}
+		if !s.Stuck {
+			continue
+		}
+		if updatedAt, ok := c.stuckDestroyings[s.ID]; ok && updatedAt == s.Status.UpdatedAt {
+			continue
+		}
+
+		c.stuckDestroyings[s.ID] = s.Status.UpdatedAt
+		var pending []uint64
+		for id, destroyed := range s.Status.Replicas {
+			if !destroyed {
+				pending = append(pending, id)
+			}
+		}
+		c.logger.Warn("destroying shard stuck",
+			zap.Uint64("shard", s.ID),
+			zap.Time("updated-at", time.Unix(s.Status.UpdatedAt, 0)),
+			zap.Any("pending-replicas", pending))
+
+		shard := metapb.Shard{ID: s.ID, State: metapb.ShardState_Destroying}
+		if res := c.core.GetShard(s.ID); res != nil {
+			shard = res.Meta
+		}
+		evt, e := event.NewDestroyingStuckEvent(shard)
+		if e != nil {
+			c.logger.Error("failed to create the destroying stuck event",
+				zap.Uint64("shard", s.ID),
+				zap.Error(e))
+		} else {
+			c.addNotifyLocked(evt)
+		}
+		resourceEventCounter.WithLabelValues("destroying_stuck").Inc()
+	}
+	for id := range c.stuckDestroyings {
+		if _, ok := destroying[id]; !ok {
+			delete(c.stuckDestroyings, id)
+		}
+	}
+}
+
 // processShardHeartbeat updates the resource information.
 func (c *RaftCluster) processShardHeartbeat(res *core.CachedShard) error {
 	c.RLock()
 	origin, err := c.core.PreCheckPutShard(res)
 	if err != nil {
 		c.RUnlock()
+		if overlap := c.getDuplicateShardOverlap(res); overlap != nil {
+			return c.handleDuplicateShard(res, overlap, err)
+		}
 		return err
 	}
 
@@ -507,6 +725,13 @@ func (c *RaftCluster) processShardHeartbeat(res *core.CachedShard) error {
 		return errShardDestroyed
 	}
 
+	// The shard marked as destroying by prophet keeps the destroying state until
+	// destroyed, the replicas report the running state before destroyed.
+	if origin != nil && origin.Meta.GetState() == metapb.ShardState_Destroying &&
+		res.Meta.GetState() != metapb.ShardState_Destroying {
+		res = res.Clone(core.WithState(metapb.ShardState_Destroying))
+	}
+
 	// Save to storage if meta is updated.
 	// Save to cache if meta or leader is updated, or contains any down/pending peer.
 	// Mark isNew if the resource in cache does not have leader.
@@ -556,6 +781,9 @@ func (c *RaftCluster) processShardHeartbeat(res *core.CachedShard) error {
 		if res.Meta.GetState() != origin.Meta.GetState() {
 			saveKV, saveCache = true, true
 		}
+		if !labelsEqual(res.Meta.GetLabels(), origin.Meta.GetLabels()) {
+			saveKV, saveCache = true, true
+		}
 		if res.GetGroupKey() != origin.GetGroupKey() {
 			saveCache = true
 		}
@@ -576,6 +804,7 @@ func (c *RaftCluster) processShardHeartbeat(res *core.CachedShard) error {
 	}
 
 	c.Lock()
+	delete(c.duplicateShards, res.Meta.GetID())
 	inCreating := c.core.IsWaitingCreateShard(res.Meta.GetID())
 	if isNew && inCreating {
 		if c.resourceStateChangedHandler != nil {
@@ -709,6 +938,16 @@ func (c *RaftCluster) ScanShards(group uint64, startKey, endKey []byte, limit in
 	return c.core.ScanRange(group, startKey, endKey, limit)
 }
 
+// GetShardPreferredLeader returns the preferred leader store of the resource
+func (c *RaftCluster) GetShardPreferredLeader(id uint64) uint64 {
+	return c.core.GetShardPreferredLeader(id)
+}
+
+// GetShardRangeRelocation returns the range relocation of the resource
+func (c *RaftCluster) GetShardRangeRelocation(res *core.CachedShard) (metapb.RangeRelocation, bool) {
+	return c.core.GetShardRangeRelocation(res)
+}
+
 // GetDestroyingShards returns all resources in destroying state
 func (c *RaftCluster) GetDestroyingShards() []*core.CachedShard {
 	return c.core.GetDestroyingShards()
@@ -0,0 +1,176 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"sync"
+	"time"
+
+	"github.com/matrixorigin/matrixcube/components/prophet/config"
+	"github.com/matrixorigin/matrixcube/components/prophet/util"
+	"go.uber.org/zap"
+)
+
+var (
+	// configGuardCheckInterval is the interval to check the operators created
+	// after the schedule config changed.
+	configGuardCheckInterval = time.Second
+)
+
+// ScheduleConfigGuard decides whether the schedule config change is rolled back
+// automatically. The change is rolled back if more than MaxOperators operators
+// are created within the Window after the change is applied.
+type ScheduleConfigGuard struct {
+	Window       time.Duration
+	MaxOperators uint64
+}
+
+func (g ScheduleConfigGuard) enabled() bool {
+	return g.Window > 0 && g.MaxOperators > 0
+}
+
+// configGuard watches the operators created after a schedule config change.
+type configGuard struct {
+	sync.Mutex
+	guard    ScheduleConfigGuard
+	previous *config.ScheduleConfig
+	applied  *config.ScheduleConfig
+	baseline uint64
+	deadline time.Time
+}
+
+func (g *configGuard) watch(guard ScheduleConfigGuard, previous, applied *config.ScheduleConfig, baseline uint64) {
+	g.Lock()
+	defer g.Unlock()
+	g.guard = guard
+	g.previous = previous
+	g.applied = applied
+	g.baseline = baseline
+	g.deadline = time.Now().Add(guard.Window)
+}
+
+// check returns the configs to roll back if the operators created since the
+// change exceed the limit, the watching is done once the window passed.
+func (g *configGuard) check(created uint64, now time.Time) (previous, applied *config.ScheduleConfig, operators uint64, rollback bool) {
+	g.Lock()
+	defer g.Unlock()
+	if g.applied == nil {
+		return nil, nil, 0, false
+	}
+
+	operators = created - g.baseline
+	if operators > g.guard.MaxOperators {
+		previous, applied = g.previous, g.applied
+		g.reset()
+		return previous, applied, operators, true
+	}
+	if now.After(g.deadline) {
+		g.reset()
+	}
+	return nil, nil, 0, false
+}
+
+func (g *configGuard) reset() {
+	g.guard = ScheduleConfigGuard{}
+	g.previous = nil
+	g.applied = nil
+	g.baseline = 0
+	g.deadline = time.Time{}
+}
+
+// UpdateScheduleConfig validates and applies the JSON encoded changes of the
+// schedule config, see config.PersistOptions.UpdateScheduleConfig. If the guard
+// is enabled, the coordinator watches the operators created after the change,
+// and rolls the change back if the operators are created too fast, e.g. the
+// max merge size is set too high and lots of merge operators are created. The
+// applied config is returned.
+func (c *RaftCluster) UpdateScheduleConfig(data []byte, guard ScheduleConfigGuard) (*config.ScheduleConfig, error) {
+	c.RLock()
+	running, co := c.running, c.coordinator
+	c.RUnlock()
+	if !running {
+		return nil, util.ErrNotLeader
+	}
+
+	var baseline uint64
+	if co != nil {
+		baseline = co.opController.CreatedOperatorCount()
+	}
+	previous, applied, err := c.opt.UpdateScheduleConfig(c.storage, data)
+	if err != nil {
+		return nil, err
+	}
+	if co != nil && guard.enabled() {
+		co.configGuard.watch(guard, previous, applied, baseline)
+	}
+	return applied, nil
+}
+
+// checkScheduleConfigChange rolls back the schedule config change if the
+// operators created after the change exceed the limit of the guard.
+func (c *coordinator) checkScheduleConfigChange() {
+	previous, applied, operators, rollback := c.configGuard.check(c.opController.CreatedOperatorCount(), time.Now())
+	if !rollback {
+		return
+	}
+
+	restored, err := c.cluster.opt.RollbackScheduleConfig(c.cluster.storage, applied, previous)
+	if err != nil {
+		c.cluster.logger.Error("fail to persist the rolled back schedule config",
+			zap.Error(err))
+	}
+	if restored {
+		c.cluster.logger.Warn("schedule config change rolled back, too many operators created",
+			zap.Uint64("operators", operators))
+	}
+}
+
+// checkScheduleTimeWindow applies the schedule time window contains the current
+// time, so the limits of the off-peak windows take effect automatically.
+func (c *coordinator) checkScheduleTimeWindow(now time.Time) {
+	w, changed := c.cluster.opt.ApplyScheduleTimeWindow(now)
+	if !changed {
+		return
+	}
+	if w == nil {
+		c.cluster.logger.Info("schedule time window ended, the limits of the schedule config applied")
+		return
+	}
+	c.cluster.logger.Info("schedule time window applied",
+		zap.String("start", w.Start),
+		zap.String("end", w.End),
+		zap.Uint64("leader-schedule-limit", c.cluster.opt.GetLeaderScheduleLimit()),
+		zap.Uint64("resource-schedule-limit", c.cluster.opt.GetShardScheduleLimit()),
+		zap.Uint64("replica-schedule-limit", c.cluster.opt.GetReplicaScheduleLimit()),
+		zap.Uint64("merge-schedule-limit", c.cluster.opt.GetMergeScheduleLimit()),
+		zap.Uint64("hot-resource-schedule-limit", c.cluster.opt.GetHotShardScheduleLimit()))
+}
+
+// watchScheduleConfigChanges checks the operators created after the schedule
+// config changes and the schedule time windows periodically.
+func (c *coordinator) watchScheduleConfigChanges() {
+	defer c.wg.Done()
+	ticker := time.NewTicker(configGuardCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.ctx.Done():
+			c.cluster.logger.Info("schedule config watcher has been stopped")
+			return
+		case <-ticker.C:
+			c.checkScheduleConfigChange()
+			c.checkScheduleTimeWindow(time.Now())
+		}
+	}
+}
@@ -0,0 +1,115 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"testing"
+	"time"
+
+	"github.com/matrixorigin/matrixcube/components/prophet/config"
+	"github.com/matrixorigin/matrixcube/components/prophet/util"
+	"github.com/matrixorigin/matrixcube/pb/metapb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpdateScheduleConfig(t *testing.T) {
+	tc, co, cleanup := prepare(t, nil, nil, nil)
+	defer cleanup()
+	tc.coordinator = co
+
+	_, err := tc.UpdateScheduleConfig([]byte(`{"max-merge-resource-size": 100}`), ScheduleConfigGuard{})
+	assert.Equal(t, util.ErrNotLeader, err)
+
+	tc.running = true
+	cfg, err := tc.UpdateScheduleConfig([]byte(`{"max-merge-resource-size": 100}`), ScheduleConfigGuard{})
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(100), cfg.MaxMergeShardSize)
+	assert.Equal(t, uint64(100), tc.opt.GetMaxMergeShardSize())
+
+	_, err = tc.UpdateScheduleConfig([]byte(`{"leader-schedule-policy": "none"}`), ScheduleConfigGuard{})
+	assert.Error(t, err)
+	assert.Equal(t, cfg, tc.opt.GetScheduleConfig())
+}
+
+func TestScheduleConfigRollback(t *testing.T) {
+	tc, co, cleanup := prepare(t, nil, nil, nil)
+	defer cleanup()
+	tc.coordinator = co
+	tc.running = true
+
+	assert.NoError(t, tc.addLeaderStore(1, 0))
+	assert.NoError(t, tc.addLeaderStore(2, 0))
+	assert.NoError(t, tc.addLeaderStore(3, 0))
+	for id := uint64(1); id <= 6; id++ {
+		assert.NoError(t, tc.addLeaderShard(id, 1, 2, 3))
+	}
+	assert.NoError(t, tc.updateLeaderCount(1, 6))
+
+	previous := tc.opt.GetScheduleConfig()
+	guard := ScheduleConfigGuard{Window: time.Minute, MaxOperators: 2}
+	_, err := tc.UpdateScheduleConfig([]byte(`{"max-merge-resource-size": 100}`), guard)
+	assert.NoError(t, err)
+
+	// within the limit
+	co.checkScheduleConfigChange()
+	assert.Equal(t, uint64(100), tc.opt.GetMaxMergeShardSize())
+
+	// 3 operators created
+	assert.NoError(t, tc.HandleStoreHeartbeat(&metapb.StoreStats{StoreID: 1, LeaderSoftLimit: 3}))
+	co.checkScheduleConfigChange()
+	assert.Equal(t, previous, tc.opt.GetScheduleConfig())
+	cfg := &config.Config{}
+	ok, err := tc.storage.LoadConfig(cfg)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, previous.MaxMergeShardSize, cfg.Schedule.MaxMergeShardSize)
+}
+
+func TestScheduleConfigGuardWindow(t *testing.T) {
+	var g configGuard
+	now := time.Now()
+	g.watch(ScheduleConfigGuard{Window: time.Minute, MaxOperators: 2}, nil, nil, 0)
+	_, _, _, rollback := g.check(10, now)
+	assert.False(t, rollback, "nothing to rollback without the applied config")
+
+	applied := &config.ScheduleConfig{}
+	g.watch(ScheduleConfigGuard{Window: time.Minute, MaxOperators: 2}, nil, applied, 10)
+	_, _, _, rollback = g.check(12, now)
+	assert.False(t, rollback)
+	// the window passed
+	_, _, _, rollback = g.check(12, now.Add(2*time.Minute))
+	assert.False(t, rollback)
+	_, _, _, rollback = g.check(100, now.Add(2*time.Minute))
+	assert.False(t, rollback)
+}
+
+func TestCheckScheduleTimeWindow(t *testing.T) {
+	tc, co, cleanup := prepare(t, nil, nil, nil)
+	defer cleanup()
+	tc.coordinator = co
+	tc.running = true
+
+	limit := tc.opt.GetShardScheduleLimit()
+	_, err := tc.UpdateScheduleConfig([]byte(`{"time-windows": [{"start": "00:00", "end": "00:00", "resource-schedule-limit": 100}]}`), ScheduleConfigGuard{})
+	assert.NoError(t, err)
+	assert.Equal(t, limit, tc.opt.GetShardScheduleLimit(), "not applied before checked by the coordinator")
+
+	co.checkScheduleTimeWindow(time.Now())
+	assert.Equal(t, uint64(100), tc.opt.GetShardScheduleLimit())
+
+	_, err = tc.UpdateScheduleConfig([]byte(`{"time-windows": []}`), ScheduleConfigGuard{})
+	assert.NoError(t, err)
+	co.checkScheduleTimeWindow(time.Now())
+	assert.Equal(t, limit, tc.opt.GetShardScheduleLimit())
+}
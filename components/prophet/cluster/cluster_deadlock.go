@@ -0,0 +1,123 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"sync"
+	"time"
+
+	"github.com/fagongzi/util/hack"
+	"github.com/matrixorigin/matrixcube/pb/rpcpb"
+	"github.com/matrixorigin/matrixcube/pb/txnpb"
+)
+
+const (
+	// defaultWaitForEdgeTTL is the duration a wait-for edge is kept without being
+	// added again. The waiters add their edges again periodically as long as they
+	// wait, so the edges of the crashed waiters and the stale edges pointing to the
+	// previous holders of the locks are removed soon.
+	defaultWaitForEdgeTTL = time.Second * 10
+)
+
+// HandleDetectDeadlock adds the wait-for edge of the pessimistic lock to the
+// wait-for graph, the edge is rejected if it causes a deadlock. The wait-for
+// graph is only kept in memory of the prophet leader, the waiters add their
+// edges again after the leader changed.
+func (c *RaftCluster) HandleDetectDeadlock(request *rpcpb.ProphetRequest) (*rpcpb.DetectDeadlockRsp, error) {
+	req := request.DetectDeadlock
+	if req.CleanUp {
+		c.deadlockDetector.cleanUp(req.Edge.TxnID)
+		return &rpcpb.DetectDeadlockRsp{}, nil
+	}
+
+	return &rpcpb.DetectDeadlockRsp{
+		Deadlock: c.deadlockDetector.detect(req.Edge, time.Now()),
+	}, nil
+}
+
+// deadlockDetector keeps the wait-for graph of the txns waiting for the
+// pessimistic locks.
+type deadlockDetector struct {
+	sync.Mutex
+	ttl time.Duration
+	// waitFor waiting txn id -> txn id holding the lock -> the time the edge added
+	waitFor map[string]map[string]time.Time
+}
+
+func newDeadlockDetector(ttl time.Duration) *deadlockDetector {
+	return &deadlockDetector{
+		ttl:     ttl,
+		waitFor: make(map[string]map[string]time.Time),
+	}
+}
+
+// detect returns true if the edge causes a deadlock, otherwise the edge is added.
+func (d *deadlockDetector) detect(edge txnpb.WaitForEdge, now time.Time) bool {
+	d.Lock()
+	defer d.Unlock()
+
+	txn := string(edge.TxnID)
+	waitFor := string(edge.WaitForTxnID)
+	if d.reachableLocked(waitFor, txn, now) {
+		return true
+	}
+
+	holders, ok := d.waitFor[txn]
+	if !ok {
+		holders = make(map[string]time.Time)
+		d.waitFor[txn] = holders
+	}
+	holders[waitFor] = now
+	return false
+}
+
+// reachableLocked returns true if the txn `to` is reachable from the txn `from`
+// in the wait-for graph, the expired edges visited are removed.
+func (d *deadlockDetector) reachableLocked(from, to string, now time.Time) bool {
+	visited := make(map[string]struct{})
+	stack := []string{from}
+	for len(stack) > 0 {
+		txn := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if txn == to {
+			return true
+		}
+		if _, ok := visited[txn]; ok {
+			continue
+		}
+		visited[txn] = struct{}{}
+
+		holders := d.waitFor[txn]
+		for holder, addedAt := range holders {
+			if now.Sub(addedAt) >= d.ttl {
+				delete(holders, holder)
+				continue
+			}
+			stack = append(stack, holder)
+		}
+		if len(holders) == 0 {
+			delete(d.waitFor, txn)
+		}
+	}
+	return false
+}
+
+// cleanUp removes all the wait-for edges of the waiting txn, e.g. the txn got
+// the lock or stopped waiting.
+func (d *deadlockDetector) cleanUp(txnID []byte) {
+	d.Lock()
+	defer d.Unlock()
+
+	delete(d.waitFor, hack.SliceToString(txnID))
+}
@@ -0,0 +1,45 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"testing"
+	"time"
+
+	"github.com/matrixorigin/matrixcube/pb/txnpb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeadlockDetector(t *testing.T) {
+	edge := func(txn, waitFor string) txnpb.WaitForEdge {
+		return txnpb.WaitForEdge{TxnID: []byte(txn), WaitForTxnID: []byte(waitFor)}
+	}
+
+	now := time.Now()
+	d := newDeadlockDetector(time.Minute)
+	assert.False(t, d.detect(edge("t1", "t2"), now))
+	assert.False(t, d.detect(edge("t2", "t3"), now))
+	assert.True(t, d.detect(edge("t3", "t1"), now))
+	assert.False(t, d.detect(edge("t4", "t1"), now))
+
+	// t2 got the lock
+	d.cleanUp([]byte("t2"))
+	assert.False(t, d.detect(edge("t3", "t1"), now))
+
+	// the expired edges are removed
+	d = newDeadlockDetector(time.Minute)
+	assert.False(t, d.detect(edge("t1", "t2"), now))
+	assert.False(t, d.detect(edge("t2", "t1"), now.Add(time.Minute)))
+	assert.Equal(t, 1, len(d.waitFor))
+}
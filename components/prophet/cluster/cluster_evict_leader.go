@@ -0,0 +1,84 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/matrixorigin/matrixcube/components/prophet/schedule"
+	"github.com/matrixorigin/matrixcube/components/prophet/schedulers"
+	"github.com/matrixorigin/matrixcube/pb/rpcpb"
+	"go.uber.org/zap"
+)
+
+// HandleUpdateEvictLeaderStores adds or removes the store of the evict leader
+// scheduler, which keeps moving the leaders off the stores, e.g. the stores with
+// degraded disks but healthy network. The scheduler is added with the first store
+// and removed with the last store, the stores are persisted with the config of the
+// scheduler.
+func (c *RaftCluster) HandleUpdateEvictLeaderStores(req rpcpb.UpdateEvictLeaderStoresReq) (*rpcpb.UpdateEvictLeaderStoresRsp, error) {
+	c.evictLeaderMu.Lock()
+	defer c.evictLeaderMu.Unlock()
+
+	s, ok := c.coordinator.getEvictLeaderScheduler()
+	if req.Remove {
+		if !ok {
+			return &rpcpb.UpdateEvictLeaderStoresRsp{}, nil
+		}
+		last, err := s.RemoveStore(c, req.StoreID)
+		if err != nil {
+			return nil, err
+		}
+		if last {
+			if err := c.RemoveScheduler(schedulers.EvictLeaderName); err != nil {
+				return nil, err
+			}
+			c.logger.Info("evict leader scheduler removed with the last container",
+				zap.Uint64("container", req.StoreID))
+			return &rpcpb.UpdateEvictLeaderStoresRsp{}, nil
+		}
+		return &rpcpb.UpdateEvictLeaderStoresRsp{StoreIDs: s.GetStores()}, nil
+	}
+
+	container := c.GetStore(req.StoreID)
+	if container == nil {
+		return nil, fmt.Errorf("container %d not found", req.StoreID)
+	}
+	if container.IsTombstone() {
+		return nil, fmt.Errorf("container %d is tombstone", req.StoreID)
+	}
+	if ok {
+		if err := s.AddStore(c, req.StoreID); err != nil {
+			return nil, err
+		}
+		return &rpcpb.UpdateEvictLeaderStoresRsp{StoreIDs: s.GetStores()}, nil
+	}
+
+	args := []string{strconv.FormatUint(req.StoreID, 10)}
+	scheduler, err := schedule.CreateScheduler(schedulers.EvictLeaderType, c.coordinator.opController,
+		c.storage, schedule.ConfigSliceDecoder(schedulers.EvictLeaderType, args))
+	if err != nil {
+		return nil, err
+	}
+	if err := c.AddScheduler(scheduler, args...); err != nil {
+		return nil, err
+	}
+	if err := c.opt.Persist(c.storage); err != nil {
+		return nil, err
+	}
+	c.logger.Info("evict leader scheduler added",
+		zap.Uint64("container", req.StoreID))
+	return &rpcpb.UpdateEvictLeaderStoresRsp{StoreIDs: []uint64{req.StoreID}}, nil
+}
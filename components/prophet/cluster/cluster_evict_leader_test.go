@@ -0,0 +1,76 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/matrixorigin/matrixcube/components/prophet/schedulers"
+	"github.com/matrixorigin/matrixcube/pb/rpcpb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleUpdateEvictLeaderStores(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	tc, co, cleanup := prepare(t, nil, nil, func(co *coordinator) { co.run() })
+	defer cleanup()
+	tc.coordinator = co
+	for id := uint64(1); id <= 3; id++ {
+		require.NoError(t, tc.addLeaderStore(id, 1))
+	}
+
+	_, err := tc.HandleUpdateEvictLeaderStores(rpcpb.UpdateEvictLeaderStoresReq{StoreID: 100})
+	assert.Error(t, err)
+
+	rsp, err := tc.HandleUpdateEvictLeaderStores(rpcpb.UpdateEvictLeaderStoresReq{StoreID: 1})
+	require.NoError(t, err)
+	assert.Equal(t, []uint64{1}, rsp.StoreIDs)
+	rsp, err = tc.HandleUpdateEvictLeaderStores(rpcpb.UpdateEvictLeaderStoresReq{StoreID: 2})
+	require.NoError(t, err)
+	assert.Equal(t, []uint64{1, 2}, rsp.StoreIDs)
+	assert.False(t, tc.GetStore(1).AllowLeaderTransfer())
+	assert.False(t, tc.GetStore(2).AllowLeaderTransfer())
+	assert.True(t, tc.GetStore(3).AllowLeaderTransfer())
+
+	// the stores are restored after the coordinator restarted
+	co.stop()
+	co.wg.Wait()
+	co = newCoordinator(ctx, tc.RaftCluster, co.hbStreams)
+	co.run()
+	tc.coordinator = co
+	s, ok := co.getEvictLeaderScheduler()
+	require.True(t, ok)
+	assert.Equal(t, []uint64{1, 2}, s.GetStores())
+
+	rsp, err = tc.HandleUpdateEvictLeaderStores(rpcpb.UpdateEvictLeaderStoresReq{StoreID: 1, Remove: true})
+	require.NoError(t, err)
+	assert.Equal(t, []uint64{2}, rsp.StoreIDs)
+	assert.True(t, tc.GetStore(1).AllowLeaderTransfer())
+
+	// the scheduler is removed with the last store
+	rsp, err = tc.HandleUpdateEvictLeaderStores(rpcpb.UpdateEvictLeaderStoresReq{StoreID: 2, Remove: true})
+	require.NoError(t, err)
+	assert.Empty(t, rsp.StoreIDs)
+	assert.Eventually(t, func() bool {
+		return tc.GetStore(2).AllowLeaderTransfer()
+	}, time.Second*5, time.Millisecond*10)
+	_, ok = co.getEvictLeaderScheduler()
+	assert.False(t, ok)
+	assert.NotContains(t, co.getSchedulers(), schedulers.EvictLeaderName)
+}
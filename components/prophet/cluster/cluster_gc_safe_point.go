@@ -0,0 +1,55 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"github.com/matrixorigin/matrixcube/pb/rpcpb"
+	"go.uber.org/zap"
+)
+
+// HandleUpdateGCSafePoint advances the GC safe point of the shard group, which is
+// used by the stores to drop the data filtered by the compaction filters. The safe
+// point is advanced by the txn layer once no txn reads before it, so it never goes
+// backwards, the safe point lower than the current one is ignored.
+func (c *RaftCluster) HandleUpdateGCSafePoint(req rpcpb.UpdateGCSafePointReq) (*rpcpb.UpdateGCSafePointRsp, error) {
+	c.Lock()
+	defer c.Unlock()
+
+	current, err := c.storage.GetGCSafePoint(req.Group)
+	if err != nil {
+		return nil, err
+	}
+	if req.SafePoint <= current {
+		return &rpcpb.UpdateGCSafePointRsp{SafePoint: current}, nil
+	}
+	if err := c.storage.PutGCSafePoint(req.Group, req.SafePoint); err != nil {
+		return nil, err
+	}
+	c.logger.Debug("gc safe point updated",
+		zap.Uint64("group", req.Group),
+		zap.Uint64("safe-point", req.SafePoint))
+	return &rpcpb.UpdateGCSafePointRsp{SafePoint: req.SafePoint}, nil
+}
+
+// HandleGetGCSafePoint returns the GC safe point of the shard group.
+func (c *RaftCluster) HandleGetGCSafePoint(req rpcpb.GetGCSafePointReq) (*rpcpb.GetGCSafePointRsp, error) {
+	c.RLock()
+	defer c.RUnlock()
+
+	safePoint, err := c.storage.GetGCSafePoint(req.Group)
+	if err != nil {
+		return nil, err
+	}
+	return &rpcpb.GetGCSafePointRsp{SafePoint: safePoint}, nil
+}
@@ -0,0 +1,51 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"testing"
+
+	"github.com/matrixorigin/matrixcube/components/prophet/core"
+	"github.com/matrixorigin/matrixcube/components/prophet/storage"
+	"github.com/matrixorigin/matrixcube/pb/rpcpb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleGCSafePoint(t *testing.T) {
+	_, opt, err := newTestScheduleConfig()
+	require.NoError(t, err)
+	cluster := newTestRaftCluster(opt, storage.NewTestStorage(), core.NewBasicCluster(nil))
+
+	get := func(group uint64) uint64 {
+		rsp, err := cluster.HandleGetGCSafePoint(rpcpb.GetGCSafePointReq{Group: group})
+		require.NoError(t, err)
+		return rsp.SafePoint
+	}
+	update := func(group, safePoint uint64) uint64 {
+		rsp, err := cluster.HandleUpdateGCSafePoint(rpcpb.UpdateGCSafePointReq{Group: group, SafePoint: safePoint})
+		require.NoError(t, err)
+		return rsp.SafePoint
+	}
+
+	assert.Equal(t, uint64(0), get(1))
+	assert.Equal(t, uint64(100), update(1, 100))
+	assert.Equal(t, uint64(100), get(1))
+	assert.Equal(t, uint64(0), get(2))
+
+	// the safe point never goes backwards
+	assert.Equal(t, uint64(100), update(1, 50))
+	assert.Equal(t, uint64(100), get(1))
+	assert.Equal(t, uint64(200), update(1, 200))
+}
@@ -0,0 +1,344 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/matrixorigin/matrixcube/components/prophet/core"
+	"github.com/matrixorigin/matrixcube/components/prophet/event"
+	"github.com/matrixorigin/matrixcube/pb/metapb"
+	"github.com/matrixorigin/matrixcube/pb/rpcpb"
+	"go.uber.org/zap"
+)
+
+const (
+	// shardLabelsJobRetention is the duration the finished shard labels jobs are
+	// kept for the progress queries.
+	shardLabelsJobRetention = 10 * time.Minute
+)
+
+// shardLabelsJob updates the labels of many shards. The update labels requests
+// are sent to the leaders of all the shards at once by the shard heartbeat
+// responses, and resent to the shards whose labels are not updated yet on every
+// background tick, the labels updated are confirmed by the shard heartbeats.
+type shardLabelsJob struct {
+	id uint64
+	// steering the label steering created with the job, 0 if not steered
+	steering   uint64
+	request    rpcpb.UpdateLabelsRequest
+	shards     []uint64
+	pending    map[uint64]struct{}
+	createdAt  time.Time
+	finishedAt time.Time
+}
+
+func (job *shardLabelsJob) finished() bool {
+	return !job.finishedAt.IsZero()
+}
+
+func (job *shardLabelsJob) progress() rpcpb.ShardLabelsJob {
+	pending := make([]uint64, 0, len(job.pending))
+	for id := range job.pending {
+		pending = append(pending, id)
+	}
+	sort.Slice(pending, func(i, j int) bool {
+		return pending[i] < pending[j]
+	})
+	return rpcpb.ShardLabelsJob{
+		ID:        job.id,
+		Total:     uint64(len(job.shards)),
+		Pending:   pending,
+		Finished:  job.finished(),
+		CreatedAt: job.createdAt.Unix(),
+		Steering:  job.steering,
+	}
+}
+
+// HandleUpdateShardLabels creates a shard labels job updating the labels of the
+// shards, and returns the progress of the job. A label steering is created with
+// the job if the request is steered.
+func (c *RaftCluster) HandleUpdateShardLabels(req rpcpb.UpdateShardLabelsReq) (*rpcpb.UpdateShardLabelsRsp, error) {
+	if req.Steer {
+		if len(req.ShardIDs) > 0 || req.Policy != rpcpb.Add || len(req.Labels) == 0 {
+			return nil, fmt.Errorf("only the labels added to a key range can be steered")
+		}
+	}
+
+	c.Lock()
+	var steering uint64
+	if req.Steer {
+		id, err := c.AllocID()
+		if err != nil {
+			c.Unlock()
+			return nil, err
+		}
+		r := metapb.LabelSteering{
+			ID:     id,
+			Group:  req.Group,
+			Start:  req.Start,
+			End:    req.End,
+			Labels: req.Labels,
+		}
+		if err := c.storage.PutLabelSteering(r); err != nil {
+			c.Unlock()
+			return nil, err
+		}
+		c.labelSteerings[id] = r
+		steering = id
+	}
+
+	var shards []*core.CachedShard
+	if len(req.ShardIDs) > 0 {
+		for _, id := range req.ShardIDs {
+			res := c.core.GetShard(id)
+			if res == nil {
+				c.Unlock()
+				return nil, fmt.Errorf("shard %d not found", id)
+			}
+			shards = append(shards, res)
+		}
+	} else {
+		shards = c.core.ScanRange(req.Group, req.Start, req.End, 0)
+	}
+
+	c.shardLabelsJobID++
+	job := &shardLabelsJob{
+		id: c.shardLabelsJobID,
+		request: rpcpb.UpdateLabelsRequest{
+			Labels: req.Labels,
+			Policy: req.Policy,
+		},
+		steering:  steering,
+		pending:   make(map[uint64]struct{}),
+		createdAt: time.Now(),
+	}
+	var targets []*core.CachedShard
+	for _, res := range shards {
+		id := res.Meta.GetID()
+		if _, ok := job.pending[id]; ok {
+			continue
+		}
+		job.shards = append(job.shards, id)
+		if !labelsUpdated(res.Meta.GetLabels(), job.request) {
+			job.pending[id] = struct{}{}
+			targets = append(targets, res)
+		}
+	}
+	c.shardLabelsJobs[job.id] = job
+	c.maybeFinishShardLabelsJobLocked(job)
+	rsp := &rpcpb.UpdateShardLabelsRsp{Job: job.progress()}
+	c.Unlock()
+
+	c.logger.Info("shard labels job created",
+		zap.Uint64("job", job.id),
+		zap.Uint64("steering", steering),
+		zap.Int("shards", len(job.shards)),
+		zap.Int("pending", len(targets)))
+	c.sendUpdateLabels(targets, job.request)
+	return rsp, nil
+}
+
+// HandleGetShardLabelsJob returns the progress of the shard labels job.
+func (c *RaftCluster) HandleGetShardLabelsJob(req rpcpb.GetShardLabelsJobReq) (*rpcpb.GetShardLabelsJobRsp, error) {
+	c.Lock()
+	defer c.Unlock()
+
+	job, ok := c.shardLabelsJobs[req.ID]
+	if !ok {
+		return nil, fmt.Errorf("shard labels job %d not found", req.ID)
+	}
+	c.refreshShardLabelsJobLocked(job)
+	return &rpcpb.GetShardLabelsJobRsp{Job: job.progress()}, nil
+}
+
+// HandleCancelLabelSteering removes the label steering, the labels of the shards
+// are kept.
+func (c *RaftCluster) HandleCancelLabelSteering(req rpcpb.CancelLabelSteeringReq) error {
+	c.Lock()
+	defer c.Unlock()
+
+	if _, ok := c.labelSteerings[req.ID]; !ok {
+		return fmt.Errorf("label steering %d not found", req.ID)
+	}
+	if err := c.storage.RemoveLabelSteering(req.ID); err != nil {
+		return err
+	}
+	delete(c.labelSteerings, req.ID)
+	c.logger.Info("label steering canceled",
+		zap.Uint64("steering", req.ID))
+	return nil
+}
+
+// checkShardLabelsJobs refreshes the progress of the shard labels jobs, resends
+// the update labels requests to the pending shards, and removes the expired
+// finished jobs. The labels of the label steerings are added to the shards of
+// their key ranges missing them.
+func (c *RaftCluster) checkShardLabelsJobs() {
+	type resend struct {
+		shards  []*core.CachedShard
+		request rpcpb.UpdateLabelsRequest
+	}
+
+	var resends []resend
+	now := time.Now()
+	c.Lock()
+	for id, job := range c.shardLabelsJobs {
+		if job.finished() {
+			if now.Sub(job.finishedAt) >= shardLabelsJobRetention {
+				delete(c.shardLabelsJobs, id)
+			}
+			continue
+		}
+
+		c.refreshShardLabelsJobLocked(job)
+		var shards []*core.CachedShard
+		for id := range job.pending {
+			if res := c.core.GetShard(id); res != nil {
+				shards = append(shards, res)
+			}
+		}
+		if len(shards) > 0 {
+			resends = append(resends, resend{shards: shards, request: job.request})
+		}
+	}
+	for _, r := range c.labelSteerings {
+		request := rpcpb.UpdateLabelsRequest{Labels: r.Labels, Policy: rpcpb.Add}
+		var shards []*core.CachedShard
+		for _, res := range c.core.ScanRange(r.Group, r.Start, r.End, 0) {
+			if !labelsUpdated(res.Meta.GetLabels(), request) {
+				shards = append(shards, res)
+			}
+		}
+		if len(shards) > 0 {
+			resends = append(resends, resend{shards: shards, request: request})
+		}
+	}
+	c.Unlock()
+
+	for _, r := range resends {
+		c.sendUpdateLabels(r.shards, r.request)
+	}
+}
+
+// refreshShardLabelsJobLocked removes the shards whose labels are updated or are
+// removed from the pending shards of the job.
+func (c *RaftCluster) refreshShardLabelsJobLocked(job *shardLabelsJob) {
+	if job.finished() {
+		return
+	}
+
+	for id := range job.pending {
+		res := c.core.GetShard(id)
+		if res == nil || labelsUpdated(res.Meta.GetLabels(), job.request) {
+			delete(job.pending, id)
+		}
+	}
+	c.maybeFinishShardLabelsJobLocked(job)
+}
+
+// maybeFinishShardLabelsJobLocked finishes the job if no shard is pending, and
+// notifies the watchers by a single event with all the updated shards.
+func (c *RaftCluster) maybeFinishShardLabelsJobLocked(job *shardLabelsJob) {
+	if job.finished() || len(job.pending) > 0 {
+		return
+	}
+
+	job.finishedAt = time.Now()
+	c.logger.Info("shard labels job finished",
+		zap.Uint64("job", job.id),
+		zap.Int("shards", len(job.shards)),
+		zap.Duration("cost", job.finishedAt.Sub(job.createdAt)))
+
+	shards := make([]metapb.Shard, 0, len(job.shards))
+	for _, id := range job.shards {
+		if res := c.core.GetShard(id); res != nil {
+			shards = append(shards, res.Meta)
+		}
+	}
+	evt, err := event.NewShardLabelsUpdatedEvent(shards)
+	if err != nil {
+		c.logger.Error("failed to create the shard labels updated event",
+			zap.Uint64("job", job.id),
+			zap.Error(err))
+		return
+	}
+	c.addNotifyLocked(evt)
+	resourceEventCounter.WithLabelValues("shard_labels_updated").Inc()
+}
+
+func (c *RaftCluster) sendUpdateLabels(shards []*core.CachedShard, request rpcpb.UpdateLabelsRequest) {
+	if len(shards) == 0 {
+		return
+	}
+
+	hbStreams := c.GetHeartbeatStreams()
+	for _, res := range shards {
+		req := request
+		hbStreams.SendMsg(res, &rpcpb.ShardHeartbeatRsp{UpdateLabels: &req})
+	}
+}
+
+// labelsUpdated returns true if the labels are the result of the update labels
+// request.
+func labelsUpdated(labels []metapb.Label, req rpcpb.UpdateLabelsRequest) bool {
+	values := make(map[string]string, len(labels))
+	for _, label := range labels {
+		values[label.Key] = label.Value
+	}
+
+	switch req.Policy {
+	case rpcpb.Add:
+		for _, label := range req.Labels {
+			if value, ok := values[label.Key]; !ok || value != label.Value {
+				return false
+			}
+		}
+		return true
+	case rpcpb.Remove:
+		for _, label := range req.Labels {
+			if _, ok := values[label.Key]; ok {
+				return false
+			}
+		}
+		return true
+	case rpcpb.Reset:
+		if len(labels) != len(req.Labels) {
+			return false
+		}
+		for _, label := range req.Labels {
+			if value, ok := values[label.Key]; !ok || value != label.Value {
+				return false
+			}
+		}
+		return true
+	case rpcpb.Clear:
+		return len(labels) == 0
+	}
+	return false
+}
+
+func labelsEqual(a, b []metapb.Label) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Key != b[i].Key || a[i].Value != b[i].Value {
+			return false
+		}
+	}
+	return true
+}
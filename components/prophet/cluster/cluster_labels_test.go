@@ -0,0 +1,204 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/matrixorigin/matrixcube/components/prophet/core"
+	"github.com/matrixorigin/matrixcube/components/prophet/event"
+	"github.com/matrixorigin/matrixcube/components/prophet/schedule/hbstream"
+	"github.com/matrixorigin/matrixcube/components/prophet/storage"
+	"github.com/matrixorigin/matrixcube/pb/metapb"
+	"github.com/matrixorigin/matrixcube/pb/rpcpb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpdateShardLabels(t *testing.T) {
+	_, opt, err := newTestScheduleConfig()
+	assert.NoError(t, err)
+	cluster := newTestRaftCluster(opt, storage.NewTestStorage(), core.NewBasicCluster(nil))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	hbStreams := hbstream.NewTestHeartbeatStreams(ctx, cluster.clusterID, cluster, false, nil)
+	cluster.coordinator = &coordinator{hbStreams: hbStreams}
+
+	labels := []metapb.Label{{Key: "k1", Value: "v1"}}
+	withLabels := func(res *core.CachedShard) *core.CachedShard {
+		meta := res.Meta
+		meta.Labels = labels
+		return core.NewCachedShard(meta, res.GetLeader())
+	}
+	shards := newTestShards(4, 3)
+	shards[1] = withLabels(shards[1])
+	for _, res := range shards {
+		assert.NoError(t, cluster.processShardHeartbeat(res))
+	}
+
+	_, err = cluster.HandleUpdateShardLabels(rpcpb.UpdateShardLabelsReq{ShardIDs: []uint64{1, 100}})
+	assert.Error(t, err)
+	assert.Equal(t, 0, hbStreams.MsgLength())
+
+	// the shards in [0, 4) except the shard 1 already labeled
+	rsp, err := cluster.HandleUpdateShardLabels(rpcpb.UpdateShardLabelsReq{
+		Start:  []byte{0},
+		End:    []byte{4},
+		Labels: labels,
+		Policy: rpcpb.Add,
+	})
+	assert.NoError(t, err)
+	job := rsp.Job
+	assert.Equal(t, uint64(4), job.Total)
+	assert.Equal(t, []uint64{0, 2, 3}, job.Pending)
+	assert.False(t, job.Finished)
+	assert.Equal(t, 3, hbStreams.MsgLength())
+	assert.NoError(t, hbStreams.Drain(3))
+
+	assert.NoError(t, cluster.processShardHeartbeat(withLabels(shards[0])))
+	assert.NoError(t, cluster.processShardHeartbeat(withLabels(shards[2])))
+	getRsp, err := cluster.HandleGetShardLabelsJob(rpcpb.GetShardLabelsJobReq{ID: job.ID})
+	assert.NoError(t, err)
+	assert.Equal(t, []uint64{3}, getRsp.Job.Pending)
+	assert.False(t, getRsp.Job.Finished)
+
+	// resend to the pending shards
+	cluster.checkShardLabelsJobs()
+	assert.Equal(t, 1, hbStreams.MsgLength())
+	assert.NoError(t, hbStreams.Drain(1))
+
+	assert.NoError(t, cluster.processShardHeartbeat(withLabels(shards[3])))
+	cluster.checkShardLabelsJobs()
+	assert.Equal(t, 0, hbStreams.MsgLength())
+	getRsp, err = cluster.HandleGetShardLabelsJob(rpcpb.GetShardLabelsJobReq{ID: job.ID})
+	assert.NoError(t, err)
+	assert.Empty(t, getRsp.Job.Pending)
+	assert.True(t, getRsp.Job.Finished)
+
+	var evt rpcpb.EventNotify
+	for evt.Type != event.ShardLabelsUpdatedEvent {
+		evt = <-cluster.ChangedEventNotifier()
+	}
+	assert.Equal(t, 4, len(evt.InitEvent.Shards))
+	for _, data := range evt.InitEvent.Shards {
+		var res metapb.Shard
+		assert.NoError(t, res.Unmarshal(data))
+		assert.Equal(t, labels, res.Labels)
+	}
+
+	// the finished job is removed after the retention
+	cluster.shardLabelsJobs[job.ID].finishedAt = time.Now().Add(-shardLabelsJobRetention)
+	cluster.checkShardLabelsJobs()
+	_, err = cluster.HandleGetShardLabelsJob(rpcpb.GetShardLabelsJobReq{ID: job.ID})
+	assert.Error(t, err)
+}
+
+func TestLabelSteering(t *testing.T) {
+	_, opt, err := newTestScheduleConfig()
+	assert.NoError(t, err)
+	s := storage.NewTestStorage()
+	cluster := newTestRaftCluster(opt, s, core.NewBasicCluster(nil))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	hbStreams := hbstream.NewTestHeartbeatStreams(ctx, cluster.clusterID, cluster, false, nil)
+	cluster.coordinator = &coordinator{hbStreams: hbStreams}
+
+	labels := []metapb.Label{{Key: "storage", Value: "cold"}}
+	withLabels := func(res *core.CachedShard, labels []metapb.Label) *core.CachedShard {
+		meta := res.Meta
+		meta.Labels = labels
+		return core.NewCachedShard(meta, res.GetLeader())
+	}
+	shards := newTestShards(4, 3)
+	for _, res := range shards[:2] {
+		assert.NoError(t, cluster.processShardHeartbeat(res))
+	}
+
+	// only the labels added to a key range can be steered
+	_, err = cluster.HandleUpdateShardLabels(rpcpb.UpdateShardLabelsReq{
+		ShardIDs: []uint64{0}, Labels: labels, Policy: rpcpb.Add, Steer: true})
+	assert.Error(t, err)
+	_, err = cluster.HandleUpdateShardLabels(rpcpb.UpdateShardLabelsReq{
+		Start: []byte{0}, End: []byte{4}, Labels: labels, Policy: rpcpb.Reset, Steer: true})
+	assert.Error(t, err)
+
+	rsp, err := cluster.HandleUpdateShardLabels(rpcpb.UpdateShardLabelsReq{
+		Start:  []byte{0},
+		End:    []byte{4},
+		Labels: labels,
+		Policy: rpcpb.Add,
+		Steer:  true,
+	})
+	assert.NoError(t, err)
+	steering := rsp.Job.Steering
+	assert.NotEqual(t, uint64(0), steering)
+	assert.Equal(t, 2, hbStreams.MsgLength())
+	assert.NoError(t, hbStreams.Drain(2))
+	assert.NoError(t, cluster.processShardHeartbeat(withLabels(shards[0], labels)))
+	assert.NoError(t, cluster.processShardHeartbeat(withLabels(shards[1], labels)))
+	cluster.checkShardLabelsJobs()
+	assert.Equal(t, 0, hbStreams.MsgLength())
+
+	// the shards created in the range later and the shards whose labels removed
+	// are labeled again
+	assert.NoError(t, cluster.processShardHeartbeat(shards[2]))
+	assert.NoError(t, cluster.processShardHeartbeat(withLabels(shards[1], nil)))
+	cluster.checkShardLabelsJobs()
+	assert.Equal(t, 2, hbStreams.MsgLength())
+	assert.NoError(t, hbStreams.Drain(2))
+
+	// the steering is persisted
+	count := 0
+	assert.NoError(t, s.LoadLabelSteerings(10, func(r metapb.LabelSteering) {
+		assert.Equal(t, steering, r.ID)
+		assert.Equal(t, labels, r.Labels)
+		count++
+	}))
+	assert.Equal(t, 1, count)
+
+	assert.NoError(t, cluster.HandleCancelLabelSteering(rpcpb.CancelLabelSteeringReq{ID: steering}))
+	assert.Error(t, cluster.HandleCancelLabelSteering(rpcpb.CancelLabelSteeringReq{ID: steering}))
+	cluster.checkShardLabelsJobs()
+	assert.NoError(t, hbStreams.Drain(hbStreams.MsgLength()))
+	assert.NoError(t, cluster.processShardHeartbeat(shards[3]))
+	cluster.checkShardLabelsJobs()
+	assert.Equal(t, 0, hbStreams.MsgLength())
+	count = 0
+	assert.NoError(t, s.LoadLabelSteerings(10, func(r metapb.LabelSteering) {
+		count++
+	}))
+	assert.Equal(t, 0, count)
+}
+
+func TestLabelsUpdated(t *testing.T) {
+	labels := []metapb.Label{{Key: "k1", Value: "v1"}, {Key: "k2", Value: "v2"}}
+	cases := []struct {
+		req     rpcpb.UpdateLabelsRequest
+		updated bool
+	}{
+		{rpcpb.UpdateLabelsRequest{Policy: rpcpb.Add, Labels: []metapb.Label{{Key: "k1", Value: "v1"}}}, true},
+		{rpcpb.UpdateLabelsRequest{Policy: rpcpb.Add, Labels: []metapb.Label{{Key: "k1", Value: "v2"}}}, false},
+		{rpcpb.UpdateLabelsRequest{Policy: rpcpb.Add, Labels: []metapb.Label{{Key: "k3", Value: "v3"}}}, false},
+		{rpcpb.UpdateLabelsRequest{Policy: rpcpb.Remove, Labels: []metapb.Label{{Key: "k3"}}}, true},
+		{rpcpb.UpdateLabelsRequest{Policy: rpcpb.Remove, Labels: []metapb.Label{{Key: "k1"}}}, false},
+		{rpcpb.UpdateLabelsRequest{Policy: rpcpb.Reset, Labels: labels}, true},
+		{rpcpb.UpdateLabelsRequest{Policy: rpcpb.Reset, Labels: labels[:1]}, false},
+		{rpcpb.UpdateLabelsRequest{Policy: rpcpb.Clear}, false},
+	}
+	for i, c := range cases {
+		assert.Equal(t, c.updated, labelsUpdated(labels, c.req), "index %d", i)
+	}
+	assert.True(t, labelsUpdated(nil, rpcpb.UpdateLabelsRequest{Policy: rpcpb.Clear}))
+}
@@ -0,0 +1,52 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"fmt"
+
+	"github.com/matrixorigin/matrixcube/components/prophet/core"
+	"github.com/matrixorigin/matrixcube/pb/rpcpb"
+	"go.uber.org/zap"
+)
+
+// HandleSetStoreMaintenance sets or clears the maintenance mode of the store. The
+// schedulers and the checkers stop placing new replicas or leaders on the store
+// in the maintenance mode, but the existing replicas remain, e.g. for a planned
+// disk swap where removing the store is overkill. The mode is persisted with the
+// store metadata.
+func (c *RaftCluster) HandleSetStoreMaintenance(req rpcpb.SetStoreMaintenanceReq) (*rpcpb.SetStoreMaintenanceRsp, error) {
+	c.Lock()
+	defer c.Unlock()
+
+	container := c.GetStore(req.StoreID)
+	if container == nil {
+		return nil, fmt.Errorf("container %d not found", req.StoreID)
+	}
+	if container.IsTombstone() {
+		return nil, fmt.Errorf("container %d is tombstone", req.StoreID)
+	}
+	if container.IsMaintenance() == req.Maintenance {
+		return &rpcpb.SetStoreMaintenanceRsp{}, nil
+	}
+
+	newStore := container.Clone(core.SetStoreMaintenance(req.Maintenance))
+	if err := c.putStoreLocked(newStore); err != nil {
+		return nil, err
+	}
+	c.logger.Info("container maintenance mode changed",
+		zap.Uint64("container", req.StoreID),
+		zap.Bool("maintenance", req.Maintenance))
+	return &rpcpb.SetStoreMaintenanceRsp{}, nil
+}
@@ -0,0 +1,63 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"testing"
+
+	"github.com/matrixorigin/matrixcube/components/prophet/core"
+	"github.com/matrixorigin/matrixcube/components/prophet/storage"
+	"github.com/matrixorigin/matrixcube/pb/metapb"
+	"github.com/matrixorigin/matrixcube/pb/rpcpb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleSetStoreMaintenance(t *testing.T) {
+	_, opt, err := newTestScheduleConfig()
+	require.NoError(t, err)
+	s := storage.NewTestStorage()
+	cluster := newTestRaftCluster(opt, s, core.NewBasicCluster(nil))
+	for _, container := range newTestStores(2, "") {
+		require.NoError(t, cluster.PutStore(container.Meta))
+	}
+
+	_, err = cluster.HandleSetStoreMaintenance(rpcpb.SetStoreMaintenanceReq{StoreID: 100, Maintenance: true})
+	assert.Error(t, err)
+
+	_, err = cluster.HandleSetStoreMaintenance(rpcpb.SetStoreMaintenanceReq{StoreID: 1, Maintenance: true})
+	assert.NoError(t, err)
+	assert.True(t, cluster.GetStore(1).IsMaintenance())
+	assert.False(t, cluster.GetStore(2).IsMaintenance())
+	meta, err := s.GetStore(1)
+	require.NoError(t, err)
+	assert.True(t, meta.Maintenance)
+
+	// the maintenance mode is shown in the store stats reported by the heartbeats
+	assert.True(t, cluster.GetStore(1).GetStoreStats().Maintenance)
+	require.NoError(t, cluster.HandleStoreHeartbeat(&metapb.StoreStats{StoreID: 1}))
+	assert.True(t, cluster.GetStore(1).GetStoreStats().Maintenance)
+
+	// the maintenance mode is kept after the store registered again
+	require.NoError(t, cluster.PutStore(metapb.Store{ID: 1, ClientAddress: meta.ClientAddress}))
+	assert.True(t, cluster.GetStore(1).IsMaintenance())
+
+	_, err = cluster.HandleSetStoreMaintenance(rpcpb.SetStoreMaintenanceReq{StoreID: 1})
+	assert.NoError(t, err)
+	assert.False(t, cluster.GetStore(1).IsMaintenance())
+	assert.False(t, cluster.GetStore(1).GetStoreStats().Maintenance)
+	meta, err = s.GetStore(1)
+	require.NoError(t, err)
+	assert.False(t, meta.Maintenance)
+}
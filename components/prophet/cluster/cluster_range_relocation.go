@@ -0,0 +1,185 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/matrixorigin/matrixcube/components/log"
+	"github.com/matrixorigin/matrixcube/components/prophet/core"
+	"github.com/matrixorigin/matrixcube/components/prophet/schedule"
+	"github.com/matrixorigin/matrixcube/components/prophet/schedule/checker"
+	"github.com/matrixorigin/matrixcube/pb/metapb"
+	"github.com/matrixorigin/matrixcube/pb/rpcpb"
+	"go.uber.org/zap"
+)
+
+// HandleRelocateRange creates a range relocation moving all the replicas of the
+// shards of the group which overlap the key range to the target stores. The
+// replicas are moved by the range relocation checker, and the replica, rule and
+// hot checkers and the balance schedulers never move the replicas or the leaders
+// out of the target stores. The relocation is kept after all
+// the shards are relocated, so the replicas moved out later are moved back,
+// until the relocation is canceled.
+func (c *RaftCluster) HandleRelocateRange(request *rpcpb.ProphetRequest) (*rpcpb.RelocateRangeRsp, error) {
+	req := request.RelocateRange
+	if len(req.Stores) == 0 {
+		return nil, fmt.Errorf("missing target stores of the range relocation")
+	}
+	if len(req.End) > 0 && bytes.Compare(req.Start, req.End) >= 0 {
+		return nil, fmt.Errorf("invalid range [%+v, %+v) of the range relocation",
+			req.Start, req.End)
+	}
+	if len(req.Stores) < c.GetOpts().GetMaxReplicas() {
+		return nil, fmt.Errorf("%d target stores can not hold %d replicas",
+			len(req.Stores), c.GetOpts().GetMaxReplicas())
+	}
+
+	c.Lock()
+	defer c.Unlock()
+
+	stores := make(map[uint64]struct{}, len(req.Stores))
+	for _, id := range req.Stores {
+		if _, ok := stores[id]; ok {
+			return nil, fmt.Errorf("duplicated target store %d", id)
+		}
+		stores[id] = struct{}{}
+		store := c.core.GetStore(id)
+		if store == nil || store.IsTombstone() {
+			return nil, fmt.Errorf("store %d not found", id)
+		}
+	}
+
+	id, err := c.AllocID()
+	if err != nil {
+		return nil, err
+	}
+	r := metapb.RangeRelocation{
+		ID:     id,
+		Group:  req.Group,
+		Start:  req.Start,
+		End:    req.End,
+		Stores: req.Stores,
+	}
+	if err := c.storage.PutRangeRelocation(r); err != nil {
+		return nil, err
+	}
+	c.core.PutRangeRelocation(r)
+
+	count := 0
+	for _, res := range c.core.GetShards() {
+		if core.IsRangeRelocated(r, res.Meta) {
+			c.suspectShards.Put(res.Meta.GetID(), nil)
+			count++
+		}
+	}
+	c.logger.Info("range relocation created",
+		zap.Uint64("relocation", id),
+		zap.Uint64("group", r.Group),
+		log.HexField("start", r.Start),
+		log.HexField("end", r.End),
+		zap.Uint64s("stores", r.Stores),
+		zap.Int("shards", count))
+	return &rpcpb.RelocateRangeRsp{ID: id}, nil
+}
+
+// HandleGetRangeRelocation returns the range relocation and its progress.
+func (c *RaftCluster) HandleGetRangeRelocation(request *rpcpb.ProphetRequest) (*rpcpb.GetRangeRelocationRsp, error) {
+	c.RLock()
+	defer c.RUnlock()
+
+	r, ok := c.core.GetRangeRelocation(request.GetRangeRelocation.ID)
+	if !ok {
+		return nil, fmt.Errorf("range relocation %d not found", request.GetRangeRelocation.ID)
+	}
+
+	rsp := &rpcpb.GetRangeRelocationRsp{Relocation: r}
+	c.forEachRelocatedShardLocked(r, func(res *core.CachedShard, op bool) {
+		rsp.Progress.Total++
+		if isRangeRelocationCompleted(r, res) {
+			rsp.Progress.Relocated++
+		} else if op {
+			rsp.Progress.Relocating++
+		}
+	})
+	return rsp, nil
+}
+
+// HandleCancelRangeRelocation removes the range relocation, the running
+// operators created for the relocation are canceled.
+func (c *RaftCluster) HandleCancelRangeRelocation(request *rpcpb.ProphetRequest) error {
+	c.Lock()
+	defer c.Unlock()
+
+	r, ok := c.core.GetRangeRelocation(request.CancelRangeRelocation.ID)
+	if !ok {
+		return fmt.Errorf("range relocation %d not found", request.CancelRangeRelocation.ID)
+	}
+	if err := c.storage.RemoveRangeRelocation(r.ID); err != nil {
+		return err
+	}
+
+	var ops []uint64
+	c.forEachRelocatedShardLocked(r, func(res *core.CachedShard, op bool) {
+		if relocation, _ := c.core.GetShardRangeRelocation(res); op && relocation.ID == r.ID {
+			ops = append(ops, res.Meta.GetID())
+		}
+	})
+	c.core.RemoveRangeRelocation(r.ID)
+	if c.coordinator != nil {
+		for _, id := range ops {
+			if op := c.coordinator.opController.GetOperator(id); op != nil {
+				c.coordinator.opController.RemoveOperator(op, "range relocation canceled")
+			}
+		}
+	}
+	c.logger.Info("range relocation canceled",
+		zap.Uint64("relocation", r.ID),
+		zap.Int("canceled-operators", len(ops)))
+	return nil
+}
+
+// forEachRelocatedShardLocked calls the fn with all the shards overlapping the
+// range relocation and whether the shard has a running range relocation
+// operator.
+func (c *RaftCluster) forEachRelocatedShardLocked(r metapb.RangeRelocation, fn func(*core.CachedShard, bool)) {
+	var opController *schedule.OperatorController
+	if c.coordinator != nil {
+		opController = c.coordinator.opController
+	}
+	for _, res := range c.core.GetShards() {
+		if !core.IsRangeRelocated(r, res.Meta) || c.core.AlreadyRemoved(res.Meta.GetID()) {
+			continue
+		}
+		op := false
+		if opController != nil {
+			if o := opController.GetOperator(res.Meta.GetID()); o != nil {
+				op = o.Desc() == checker.RangeRelocationDesc
+			}
+		}
+		fn(res, op)
+	}
+}
+
+// isRangeRelocationCompleted returns true if all the replicas of the shard are
+// on the target stores of the range relocation.
+func isRangeRelocationCompleted(r metapb.RangeRelocation, res *core.CachedShard) bool {
+	for _, p := range res.Meta.GetReplicas() {
+		if !core.IsRangeRelocationTarget(r, p.StoreID) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,109 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"testing"
+
+	"github.com/matrixorigin/matrixcube/components/prophet/core"
+	"github.com/matrixorigin/matrixcube/components/prophet/storage"
+	"github.com/matrixorigin/matrixcube/pb/metapb"
+	"github.com/matrixorigin/matrixcube/pb/rpcpb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleRangeRelocation(t *testing.T) {
+	_, opt, err := newTestScheduleConfig()
+	assert.NoError(t, err)
+	cluster := newTestRaftCluster(opt, storage.NewTestStorage(), core.NewBasicCluster(nil))
+	for _, s := range newTestStores(5, "") {
+		assert.NoError(t, cluster.putStoreLocked(s))
+	}
+	resources := newTestShards(3, 3)
+	for _, res := range resources {
+		assert.NoError(t, cluster.processShardHeartbeat(res))
+	}
+
+	relocate := func(stores ...uint64) (uint64, error) {
+		rsp, err := cluster.HandleRelocateRange(&rpcpb.ProphetRequest{
+			RelocateRange: rpcpb.RelocateRangeReq{Start: []byte{1}, Stores: stores},
+		})
+		if err != nil {
+			return 0, err
+		}
+		return rsp.ID, nil
+	}
+	getProgress := func(id uint64) (rpcpb.RangeRelocationProgress, error) {
+		rsp, err := cluster.HandleGetRangeRelocation(&rpcpb.ProphetRequest{
+			GetRangeRelocation: rpcpb.GetRangeRelocationReq{ID: id},
+		})
+		return rsp.GetProgress(), err
+	}
+
+	_, err = relocate()
+	assert.Error(t, err)
+	_, err = relocate(1, 2)
+	assert.Error(t, err)
+	_, err = relocate(1, 2, 100)
+	assert.Error(t, err)
+	_, err = relocate(1, 2, 2)
+	assert.Error(t, err)
+	_, err = cluster.HandleRelocateRange(&rpcpb.ProphetRequest{
+		RelocateRange: rpcpb.RelocateRangeReq{Start: []byte{2}, End: []byte{1}, Stores: []uint64{1, 2, 4}},
+	})
+	assert.Error(t, err)
+
+	id, err := relocate(1, 2, 4)
+	require.NoError(t, err)
+	_, ok := cluster.GetShardRangeRelocation(resources[0])
+	assert.False(t, ok)
+	r, ok := cluster.GetShardRangeRelocation(resources[1])
+	assert.True(t, ok)
+	assert.Equal(t, id, r.ID)
+	assert.Subset(t, cluster.GetSuspectShards(), []uint64{1, 2})
+
+	progress, err := getProgress(id)
+	assert.NoError(t, err)
+	assert.Equal(t, rpcpb.RangeRelocationProgress{Total: 2}, progress)
+
+	// shard 2 is relocated
+	meta := resources[2].Meta
+	meta.Epoch.ConfigVer++
+	meta.Replicas = []metapb.Replica{{ID: 10, StoreID: 1}, {ID: 11, StoreID: 2}, {ID: 12, StoreID: 4}}
+	assert.NoError(t, cluster.processShardHeartbeat(core.NewCachedShard(meta, &meta.Replicas[0])))
+	progress, err = getProgress(id)
+	assert.NoError(t, err)
+	assert.Equal(t, rpcpb.RangeRelocationProgress{Total: 2, Relocated: 1}, progress)
+
+	// restart
+	cluster = newTestRaftCluster(opt, cluster.storage, core.NewBasicCluster(nil))
+	_, err = cluster.LoadClusterInfo()
+	assert.NoError(t, err)
+	r, ok = cluster.GetShardRangeRelocation(resources[1])
+	assert.True(t, ok)
+	assert.Equal(t, id, r.ID)
+
+	cancel := func(id uint64) error {
+		return cluster.HandleCancelRangeRelocation(&rpcpb.ProphetRequest{
+			CancelRangeRelocation: rpcpb.CancelRangeRelocationReq{ID: id},
+		})
+	}
+	assert.NoError(t, cancel(id))
+	assert.Error(t, cancel(id))
+	_, err = getProgress(id)
+	assert.Error(t, err)
+	_, ok = cluster.GetShardRangeRelocation(resources[1])
+	assert.False(t, ok)
+}
@@ -0,0 +1,76 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/matrixorigin/matrixcube/components/prophet/schedule/operator"
+	"github.com/matrixorigin/matrixcube/pb/rpcpb"
+	"go.uber.org/zap"
+)
+
+// HandleScatterShards creates the operators spreading the replicas and the leaders
+// of the shards evenly across the stores with the location labels, e.g. the shards
+// pre-split before a bulk import. Returns the shards failed to scatter, including
+// the unknown shards. The failed shards are not retried in the rpc handler, the
+// caller can scatter them again later, e.g. after their leaders are elected.
+func (c *RaftCluster) HandleScatterShards(req rpcpb.ScatterShardsReq) (*rpcpb.ScatterShardsRsp, error) {
+	if len(req.ShardIDs) == 0 {
+		return nil, fmt.Errorf("no shards to scatter")
+	}
+
+	scatterer := c.GetShardScatter()
+	failures := make(map[uint64]error)
+	var ops []*operator.Operator
+	for _, id := range req.ShardIDs {
+		res := c.GetShard(id)
+		if res == nil {
+			failures[id] = fmt.Errorf("shard %d not found", id)
+			continue
+		}
+		op, err := scatterer.Scatter(res, "")
+		if err != nil {
+			failures[id] = err
+			continue
+		}
+		if op != nil {
+			ops = append(ops, op)
+		}
+	}
+
+	opController := c.GetOperatorController()
+	for _, op := range ops {
+		if !opController.AddOperator(op) {
+			failures[op.ShardID()] = fmt.Errorf("failed to add the scatter operator of shard %d", op.ShardID())
+		}
+	}
+
+	rsp := &rpcpb.ScatterShardsRsp{}
+	for id, err := range failures {
+		rsp.FailedShardIDs = append(rsp.FailedShardIDs, id)
+		c.logger.Warn("failed to scatter shard",
+			zap.Uint64("shard", id),
+			zap.Error(err))
+	}
+	sort.Slice(rsp.FailedShardIDs, func(i, j int) bool {
+		return rsp.FailedShardIDs[i] < rsp.FailedShardIDs[j]
+	})
+	c.logger.Info("shards scattered",
+		zap.Int("shards", len(req.ShardIDs)),
+		zap.Int("operators", len(ops)),
+		zap.Int("failed", len(rsp.FailedShardIDs)))
+	return rsp, nil
+}
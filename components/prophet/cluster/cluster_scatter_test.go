@@ -0,0 +1,55 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"testing"
+
+	"github.com/matrixorigin/matrixcube/pb/rpcpb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleScatterShards(t *testing.T) {
+	tc, co, cleanup := prepare(t, nil, nil, nil)
+	defer cleanup()
+	tc.coordinator = co
+	for id := uint64(1); id <= 6; id++ {
+		require.NoError(t, tc.addShardStore(id, 0))
+	}
+	// the pre-split shards are all placed on the stores 1, 2 and 3
+	for id := uint64(1); id <= 6; id++ {
+		require.NoError(t, tc.addLeaderShard(id, 1, 2, 3))
+	}
+	// the shard without leader can not be scattered
+	require.NoError(t, tc.LoadShard(7, 1, 2, 3))
+
+	rsp, err := tc.HandleScatterShards(rpcpb.ScatterShardsReq{ShardIDs: []uint64{1, 2, 3, 4, 5, 6, 7, 100}})
+	require.NoError(t, err)
+	assert.Equal(t, []uint64{7, 100}, rsp.FailedShardIDs)
+
+	ops := co.opController.GetOperators()
+	assert.NotEmpty(t, ops)
+	for _, op := range ops {
+		assert.NotEqual(t, uint64(7), op.ShardID())
+	}
+
+	// all the shards are unknown
+	rsp, err = tc.HandleScatterShards(rpcpb.ScatterShardsReq{ShardIDs: []uint64{100, 101}})
+	require.NoError(t, err)
+	assert.Equal(t, []uint64{100, 101}, rsp.FailedShardIDs)
+
+	_, err = tc.HandleScatterShards(rpcpb.ScatterShardsReq{})
+	assert.Error(t, err)
+}
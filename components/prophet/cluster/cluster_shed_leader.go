@@ -0,0 +1,155 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"github.com/matrixorigin/matrixcube/components/prophet/core"
+	"github.com/matrixorigin/matrixcube/components/prophet/schedule/filter"
+	"github.com/matrixorigin/matrixcube/components/prophet/schedule/operator"
+	"github.com/matrixorigin/matrixcube/components/prophet/schedule/opt"
+	"go.uber.org/zap"
+)
+
+const (
+	shedLeaderName = "shed-leader"
+)
+
+// maybeShedLeaders transfers the excess leaders of the store to the followers
+// on other stores, if the store reports a leader soft limit in the heartbeat
+// and holds more leaders than it. So the leaders are rebalanced quickly after
+// a store fails or returns, instead of waiting for the slow pace of the
+// balance-leader scheduler.
+func (c *RaftCluster) maybeShedLeaders(storeID uint64) {
+	c.RLock()
+	co := c.coordinator
+	c.RUnlock()
+	if co == nil {
+		return
+	}
+
+	source := c.GetStore(storeID)
+	if source == nil {
+		return
+	}
+	limit := source.GetStoreStats().GetLeaderSoftLimit()
+	count := uint64(source.GetTotalLeaderCount())
+	if limit == 0 || count <= limit {
+		return
+	}
+
+	ops := c.createShedLeaderOperators(co, source, count-limit)
+	if len(ops) == 0 {
+		return
+	}
+
+	// add to the running operators directly rather than the waiting queue, so
+	// the leaders being transferred can be found with the next heartbeat
+	added := 0
+	for _, op := range ops {
+		if co.opController.AddOperator(op) {
+			added++
+		}
+	}
+	c.logger.Info("shed excess leaders",
+		zap.Uint64("store", storeID),
+		zap.Uint64("leader-count", count),
+		zap.Uint64("leader-soft-limit", limit),
+		zap.Int("operators", added))
+}
+
+func (c *RaftCluster) createShedLeaderOperators(co *coordinator, source *core.CachedStore, excess uint64) []*operator.Operator {
+	sourceCount := uint64(source.GetTotalLeaderCount())
+	// the leaders being transferred are not counted as excess
+	var pending uint64
+	var shards []*core.CachedShard
+	for _, res := range c.core.GetShards() {
+		if res.GetLeader().GetStoreID() != source.Meta.GetID() {
+			continue
+		}
+		if op := co.opController.GetOperator(res.Meta.GetID()); op != nil {
+			if op.Kind()&operator.OpLeader != 0 {
+				pending++
+			}
+			continue
+		}
+		shards = append(shards, res)
+	}
+	if pending >= excess {
+		return nil
+	}
+	excess -= pending
+	sourceCount -= pending
+
+	running := co.opController.OperatorCount(operator.OpLeader)
+	scheduleLimit := c.opt.GetLeaderScheduleLimit()
+	// planned leader count of the target stores
+	planned := make(map[uint64]uint64)
+
+	var ops []*operator.Operator
+	for _, res := range shards {
+		if uint64(len(ops)) >= excess ||
+			running+uint64(len(ops)) >= scheduleLimit {
+			break
+		}
+		if !opt.IsShardHealthy(c, res) {
+			continue
+		}
+
+		target := c.selectShedLeaderTarget(res, sourceCount, planned)
+		if target == nil {
+			continue
+		}
+
+		op, err := operator.CreateTransferLeaderOperator(shedLeaderName, c, res,
+			source.Meta.GetID(), target.Meta.GetID(), operator.OpLeader)
+		if err != nil {
+			c.logger.Debug("fail to create shed leader operator",
+				zap.Uint64("resource", res.Meta.GetID()),
+				zap.Error(err))
+			continue
+		}
+		op.SetPriorityLevel(core.HighPriority)
+
+		planned[target.Meta.GetID()]++
+		sourceCount--
+		ops = append(ops, op)
+	}
+	return ops
+}
+
+// selectShedLeaderTarget returns the follower store with the fewest leaders,
+// the stores that reach their own leader soft limit are skipped.
+func (c *RaftCluster) selectShedLeaderTarget(res *core.CachedShard, sourceCount uint64, planned map[uint64]uint64) *core.CachedStore {
+	candidates := filter.NewCandidates(c.GetFollowerStores(res)).
+		FilterTarget(c.opt, &filter.StoreStateFilter{ActionScope: shedLeaderName, TransferLeader: true})
+
+	var target *core.CachedStore
+	var targetCount uint64
+	for _, store := range candidates.Stores {
+		count := uint64(store.GetTotalLeaderCount()) + planned[store.Meta.GetID()]
+		if limit := store.GetStoreStats().GetLeaderSoftLimit(); limit > 0 && count >= limit {
+			continue
+		}
+		// avoid moving the leaders back and forth
+		if count+1 >= sourceCount {
+			continue
+		}
+
+		if target == nil || count < targetCount {
+			target = store
+			targetCount = count
+		}
+	}
+	return target
+}
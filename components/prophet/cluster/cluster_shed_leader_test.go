@@ -0,0 +1,90 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"testing"
+
+	"github.com/matrixorigin/matrixcube/components/prophet/schedule/operator"
+	"github.com/matrixorigin/matrixcube/pb/metapb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShedLeaders(t *testing.T) {
+	tc, co, cleanup := prepare(t, nil, nil, nil)
+	defer cleanup()
+	tc.coordinator = co
+
+	assert.NoError(t, tc.addLeaderStore(1, 0))
+	assert.NoError(t, tc.addLeaderStore(2, 0))
+	assert.NoError(t, tc.addLeaderStore(3, 0))
+	for id := uint64(1); id <= 6; id++ {
+		assert.NoError(t, tc.addLeaderShard(id, 1, 2, 3))
+	}
+	assert.NoError(t, tc.updateLeaderCount(1, 6))
+	assert.NoError(t, tc.updateLeaderCount(3, 1))
+
+	// no soft limit
+	assert.NoError(t, tc.HandleStoreHeartbeat(&metapb.StoreStats{StoreID: 1}))
+	assert.Equal(t, uint64(0), co.opController.OperatorCount(operator.OpLeader))
+
+	// within the soft limit
+	assert.NoError(t, tc.HandleStoreHeartbeat(&metapb.StoreStats{StoreID: 1, LeaderSoftLimit: 6}))
+	assert.Equal(t, uint64(0), co.opController.OperatorCount(operator.OpLeader))
+
+	assert.NoError(t, tc.HandleStoreHeartbeat(&metapb.StoreStats{StoreID: 1, LeaderSoftLimit: 3}))
+	assert.Equal(t, uint64(3), co.opController.OperatorCount(operator.OpLeader))
+	targets := make(map[uint64]int)
+	for _, op := range co.opController.GetOperators() {
+		assert.Equal(t, shedLeaderName, op.Desc())
+		step, ok := op.Step(0).(operator.TransferLeader)
+		assert.True(t, ok)
+		assert.Equal(t, uint64(1), step.FromStore)
+		targets[step.ToStore]++
+	}
+	// the store with fewer leaders takes more leaders
+	assert.Equal(t, 2, targets[2])
+	assert.Equal(t, 1, targets[3])
+
+	// the leaders being transferred are not shed again
+	assert.NoError(t, tc.HandleStoreHeartbeat(&metapb.StoreStats{StoreID: 1, LeaderSoftLimit: 3}))
+	assert.Equal(t, uint64(3), co.opController.OperatorCount(operator.OpLeader))
+}
+
+func TestShedLeadersWithTargetSoftLimit(t *testing.T) {
+	tc, co, cleanup := prepare(t, nil, nil, nil)
+	defer cleanup()
+	tc.coordinator = co
+
+	assert.NoError(t, tc.addLeaderStore(1, 0))
+	assert.NoError(t, tc.addLeaderStore(2, 0))
+	assert.NoError(t, tc.addLeaderStore(3, 0))
+	for id := uint64(1); id <= 6; id++ {
+		assert.NoError(t, tc.addLeaderShard(id, 1, 2, 3))
+	}
+	assert.NoError(t, tc.updateLeaderCount(1, 6))
+	assert.NoError(t, tc.updateLeaderCount(2, 2))
+	assert.NoError(t, tc.updateLeaderCount(3, 2))
+	assert.NoError(t, tc.HandleStoreHeartbeat(&metapb.StoreStats{StoreID: 2, LeaderSoftLimit: 2}))
+	assert.NoError(t, tc.HandleStoreHeartbeat(&metapb.StoreStats{StoreID: 3, LeaderSoftLimit: 3}))
+
+	// only 1 leader can be shed to store 3
+	assert.NoError(t, tc.HandleStoreHeartbeat(&metapb.StoreStats{StoreID: 1, LeaderSoftLimit: 2}))
+	assert.Equal(t, uint64(1), co.opController.OperatorCount(operator.OpLeader))
+	for _, op := range co.opController.GetOperators() {
+		step, ok := op.Step(0).(operator.TransferLeader)
+		assert.True(t, ok)
+		assert.Equal(t, uint64(3), step.ToStore)
+	}
+}
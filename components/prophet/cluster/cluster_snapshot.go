@@ -0,0 +1,63 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"sort"
+
+	"github.com/matrixorigin/matrixcube/components/prophet/schedule"
+	"github.com/matrixorigin/matrixcube/pb/metapb"
+	"github.com/matrixorigin/matrixcube/pb/rpcpb"
+)
+
+// HandleListSnapshotProgresses returns the progresses of the snapshots being
+// transferred reported by the store heartbeats. The progresses of the shards
+// with the running operators are attached with the descriptions of the
+// operators, so the rebalances and repairs waiting for the snapshots can be
+// told. The disconnected stores are skipped as their reports are stale.
+func (c *RaftCluster) HandleListSnapshotProgresses(req rpcpb.ListSnapshotProgressesReq) (*rpcpb.ListSnapshotProgressesRsp, error) {
+	c.RLock()
+	var progresses []metapb.SnapshotProgress
+	for _, s := range c.core.GetStores() {
+		if (req.StoreID > 0 && s.Meta.GetID() != req.StoreID) ||
+			s.IsDisconnected() {
+			continue
+		}
+		for _, p := range s.GetStoreStats().GetSnapshotProgresses() {
+			if req.ShardID == 0 || p.ShardID == req.ShardID {
+				progresses = append(progresses, p)
+			}
+		}
+	}
+	var opController *schedule.OperatorController
+	if c.coordinator != nil {
+		opController = c.coordinator.opController
+	}
+	c.RUnlock()
+
+	if opController != nil {
+		for i := range progresses {
+			if op := opController.GetOperator(progresses[i].ShardID); op != nil {
+				progresses[i].Operator = op.Desc()
+			}
+		}
+	}
+	sort.Slice(progresses, func(i, j int) bool {
+		if progresses[i].StoreID != progresses[j].StoreID {
+			return progresses[i].StoreID < progresses[j].StoreID
+		}
+		return progresses[i].ShardID < progresses[j].ShardID
+	})
+	return &rpcpb.ListSnapshotProgressesRsp{Progresses: progresses}, nil
+}
@@ -0,0 +1,81 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/matrixorigin/matrixcube/components/prophet/core"
+	"github.com/matrixorigin/matrixcube/components/prophet/schedule"
+	"github.com/matrixorigin/matrixcube/components/prophet/schedule/hbstream"
+	"github.com/matrixorigin/matrixcube/components/prophet/schedule/operator"
+	"github.com/matrixorigin/matrixcube/pb/metapb"
+	"github.com/matrixorigin/matrixcube/pb/rpcpb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleListSnapshotProgresses(t *testing.T) {
+	_, opt, err := newTestScheduleConfig()
+	require.NoError(t, err)
+	tc := newTestCluster(opt)
+
+	progresses := map[uint64][]metapb.SnapshotProgress{
+		1: {{StoreID: 1, ShardID: 2, Sending: true}, {StoreID: 1, ShardID: 1, Sending: true}},
+		2: {{StoreID: 2, ShardID: 1}},
+		3: {{StoreID: 3, ShardID: 2}},
+	}
+	for _, s := range newTestStores(3, "") {
+		heartbeat := time.Now()
+		if s.Meta.GetID() == 3 {
+			heartbeat = heartbeat.Add(-time.Hour)
+		}
+		tc.core.PutStore(s.Clone(
+			core.SetStoreStats(&metapb.StoreStats{
+				StoreID:            s.Meta.GetID(),
+				SnapshotProgresses: progresses[s.Meta.GetID()],
+			}),
+			core.SetLastHeartbeatTS(heartbeat)))
+	}
+	require.NoError(t, tc.addLeaderShard(1, 1, 2))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	hbStreams := hbstream.NewTestHeartbeatStreams(ctx, tc.clusterID, tc, false, nil)
+	tc.coordinator = &coordinator{opController: schedule.NewOperatorController(ctx, tc, hbStreams)}
+	op := operator.NewOperator("add-rule-peer", "test", 1,
+		metapb.ShardEpoch{Generation: 1, ConfigVer: 1}, operator.OpShard,
+		operator.AddLearner{ToStore: 3, PeerID: 10})
+	require.True(t, tc.coordinator.opController.AddOperator(op))
+
+	// the disconnected store 3 is skipped
+	rsp, err := tc.HandleListSnapshotProgresses(rpcpb.ListSnapshotProgressesReq{})
+	require.NoError(t, err)
+	assert.Equal(t, []metapb.SnapshotProgress{
+		{StoreID: 1, ShardID: 1, Sending: true, Operator: "add-rule-peer"},
+		{StoreID: 1, ShardID: 2, Sending: true},
+		{StoreID: 2, ShardID: 1, Operator: "add-rule-peer"},
+	}, rsp.Progresses)
+
+	rsp, err = tc.HandleListSnapshotProgresses(rpcpb.ListSnapshotProgressesReq{StoreID: 1})
+	require.NoError(t, err)
+	assert.Equal(t, 2, len(rsp.Progresses))
+
+	rsp, err = tc.HandleListSnapshotProgresses(rpcpb.ListSnapshotProgressesReq{ShardID: 2})
+	require.NoError(t, err)
+	require.Equal(t, 1, len(rsp.Progresses))
+	assert.Equal(t, uint64(1), rsp.Progresses[0].StoreID)
+}
@@ -21,14 +21,17 @@ import (
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/matrixorigin/matrixcube/components/log"
 	"github.com/matrixorigin/matrixcube/components/prophet/config"
 	"github.com/matrixorigin/matrixcube/components/prophet/core"
+	"github.com/matrixorigin/matrixcube/components/prophet/event"
 	"github.com/matrixorigin/matrixcube/components/prophet/schedule/opt"
 	"github.com/matrixorigin/matrixcube/components/prophet/schedule/placement"
 	"github.com/matrixorigin/matrixcube/components/prophet/storage"
 	"github.com/matrixorigin/matrixcube/pb/metapb"
+	"github.com/matrixorigin/matrixcube/pb/rpcpb"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -553,6 +556,78 @@ func TestHeartbeatSplit(t *testing.T) {
 	checkShard(t, cluster.GetShardByKey(0, []byte("n")), resource3)
 }
 
+func TestDuplicateShard(t *testing.T) {
+	_, opt, err := newTestScheduleConfig()
+	assert.NoError(t, err)
+	cluster := newTestRaftCluster(opt, storage.NewTestStorage(), core.NewBasicCluster(nil))
+
+	// 1: [nil, m)
+	resource1 := core.NewCachedShard(metapb.Shard{
+		ID:    1,
+		End:   []byte("m"),
+		Epoch: metapb.ShardEpoch{Generation: 2, ConfigVer: 1},
+	}, nil)
+	assert.NoError(t, cluster.processShardHeartbeat(resource1))
+
+	// 2: [a, z) is overlapped by 1 with a newer epoch
+	resource2 := core.NewCachedShard(metapb.Shard{
+		ID:    2,
+		Start: []byte("a"),
+		End:   []byte("z"),
+		Epoch: metapb.ShardEpoch{Generation: 1, ConfigVer: 1},
+	}, nil)
+	err = cluster.processShardHeartbeat(resource2)
+	assert.Error(t, err)
+	assert.NotEqual(t, errShardDestroyed, err)
+	assert.Contains(t, cluster.duplicateShards, uint64(2))
+	var evt rpcpb.EventNotify
+	for evt.Type != event.DuplicateShardEvent {
+		evt = <-cluster.ChangedEventNotifier()
+	}
+	assert.Equal(t, 2, len(evt.InitEvent.Shards))
+	checkShard(t, cluster.GetShardByKey(0, []byte("b")), resource1)
+	assert.Nil(t, cluster.GetShardByKey(0, []byte("n")))
+
+	// the duplicate shard is destroyed after the delay
+	assert.Error(t, cluster.processShardHeartbeat(resource2))
+	cluster.duplicateShards[2].foundAt = time.Now().Add(-opt.GetDuplicateShardDestroyDelay())
+	assert.Equal(t, errShardDestroyed, cluster.processShardHeartbeat(resource2))
+
+	// the destroyed duplicate shard stops reporting
+	cluster.pruneDuplicateShards()
+	assert.Contains(t, cluster.duplicateShards, uint64(2))
+	cluster.duplicateShards[2].reportedAt = time.Now().Add(-opt.GetDuplicateShardDestroyDelay())
+	cluster.pruneDuplicateShards()
+	assert.NotContains(t, cluster.duplicateShards, uint64(2))
+
+	// 2 reports the range not overlapped
+	resource2 = resource2.Clone(
+		core.WithStartKey([]byte("m")),
+		core.WithIncVersion(),
+	)
+	assert.NoError(t, cluster.processShardHeartbeat(resource2))
+	assert.NotContains(t, cluster.duplicateShards, uint64(2))
+	checkShard(t, cluster.GetShardByKey(0, []byte("n")), resource2)
+
+	// 3 and 4 are overlapped with the same epoch, the shard with the larger id
+	// wins in any order of the heartbeats
+	resource3 := core.NewCachedShard(metapb.Shard{
+		ID:    3,
+		Start: []byte("z"),
+		Epoch: metapb.ShardEpoch{Generation: 1, ConfigVer: 1},
+	}, nil)
+	resource4 := core.NewCachedShard(metapb.Shard{
+		ID:    4,
+		Start: []byte("z"),
+		Epoch: metapb.ShardEpoch{Generation: 1, ConfigVer: 1},
+	}, nil)
+	assert.NoError(t, cluster.processShardHeartbeat(resource3))
+	assert.NoError(t, cluster.processShardHeartbeat(resource4))
+	assert.Error(t, cluster.processShardHeartbeat(resource3))
+	assert.Contains(t, cluster.duplicateShards, uint64(3))
+	checkShard(t, cluster.GetShardByKey(0, []byte("z")), resource4)
+}
+
 func TestShardSplitAndMerge(t *testing.T) {
 	_, opt, err := newTestScheduleConfig()
 	assert.NoError(t, err)
@@ -0,0 +1,102 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"sort"
+
+	"github.com/matrixorigin/matrixcube/pb/rpcpb"
+)
+
+const (
+	// DefaultTopologyZoneLabel is the store label used to group the stores into
+	// zones if no zone label is specified.
+	DefaultTopologyZoneLabel = "zone"
+	// DefaultTopologyHostLabel is the store label used to group the stores into
+	// hosts if no host label is specified.
+	DefaultTopologyHostLabel = "host"
+)
+
+// GetTopology returns the topology of the cluster, zones -> hosts -> stores ->
+// shard replicas. The stores are grouped by the values of the zoneLabel and
+// hostLabel labels, the stores without the label are grouped into the zone or
+// host with the empty name. Tombstone stores are not included. All levels are
+// sorted, so the result is stable between calls.
+func (c *RaftCluster) GetTopology(zoneLabel, hostLabel string) []rpcpb.TopologyZone {
+	if zoneLabel == "" {
+		zoneLabel = DefaultTopologyZoneLabel
+	}
+	if hostLabel == "" {
+		hostLabel = DefaultTopologyHostLabel
+	}
+
+	replicas := make(map[uint64][]rpcpb.TopologyReplica)
+	for _, res := range c.GetShards() {
+		leader := res.GetLeader()
+		for _, r := range res.Meta.GetReplicas() {
+			replicas[r.StoreID] = append(replicas[r.StoreID], rpcpb.TopologyReplica{
+				ShardID:         res.Meta.GetID(),
+				Group:           res.Meta.GetGroup(),
+				Replica:         r,
+				Leader:          leader != nil && leader.ID == r.ID,
+				ApproximateSize: uint64(res.GetApproximateSize()),
+				ApproximateKeys: uint64(res.GetApproximateKeys()),
+			})
+		}
+	}
+
+	zones := make(map[string]map[string][]rpcpb.TopologyStore)
+	for _, s := range c.GetStores() {
+		if s.IsTombstone() {
+			continue
+		}
+
+		id := s.Meta.GetID()
+		rs := replicas[id]
+		sort.Slice(rs, func(i, j int) bool {
+			return rs[i].ShardID < rs[j].ShardID
+		})
+		zone, host := s.GetLabelValue(zoneLabel), s.GetLabelValue(hostLabel)
+		if _, ok := zones[zone]; !ok {
+			zones[zone] = make(map[string][]rpcpb.TopologyStore)
+		}
+		zones[zone][host] = append(zones[zone][host], rpcpb.TopologyStore{
+			Store:       s.Meta,
+			Capacity:    s.GetCapacity(),
+			Available:   s.GetAvailable(),
+			UsedSize:    s.GetUsedSize(),
+			LeaderCount: uint64(s.GetTotalLeaderCount()),
+			Replicas:    rs,
+		})
+	}
+
+	topology := make([]rpcpb.TopologyZone, 0, len(zones))
+	for zone, hosts := range zones {
+		z := rpcpb.TopologyZone{Name: zone}
+		for host, stores := range hosts {
+			sort.Slice(stores, func(i, j int) bool {
+				return stores[i].Store.ID < stores[j].Store.ID
+			})
+			z.Hosts = append(z.Hosts, rpcpb.TopologyHost{Name: host, Stores: stores})
+		}
+		sort.Slice(z.Hosts, func(i, j int) bool {
+			return z.Hosts[i].Name < z.Hosts[j].Name
+		})
+		topology = append(topology, z)
+	}
+	sort.Slice(topology, func(i, j int) bool {
+		return topology[i].Name < topology[j].Name
+	})
+	return topology
+}
@@ -0,0 +1,77 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"testing"
+
+	"github.com/matrixorigin/matrixcube/components/prophet/core"
+	"github.com/matrixorigin/matrixcube/pb/metapb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetTopology(t *testing.T) {
+	_, opt, err := newTestScheduleConfig()
+	assert.NoError(t, err)
+	tc := newTestCluster(opt)
+
+	labels := map[uint64][]metapb.Label{
+		1: {{Key: "zone", Value: "z1"}, {Key: "host", Value: "h1"}},
+		2: {{Key: "zone", Value: "z1"}, {Key: "host", Value: "h2"}},
+		3: {{Key: "zone", Value: "z2"}, {Key: "host", Value: "h3"}},
+		4: {{Key: "zone", Value: "z2"}, {Key: "host", Value: "h3"}},
+		5: nil,
+	}
+	for id := uint64(5); id >= 1; id-- {
+		tc.core.PutStore(core.NewCachedStore(metapb.Store{ID: id, Labels: labels[id], State: metapb.StoreState_Up}))
+	}
+	tc.core.PutStore(core.NewCachedStore(metapb.Store{ID: 6, State: metapb.StoreState_StoreTombstone}))
+	assert.NoError(t, tc.addLeaderShard(2, 1, 2, 3))
+	assert.NoError(t, tc.addLeaderShard(1, 4, 1))
+
+	topology := tc.GetTopology("", "")
+	assert.Equal(t, 3, len(topology))
+	assert.Equal(t, "", topology[0].Name)
+	assert.Equal(t, 1, len(topology[0].Hosts))
+	assert.Equal(t, uint64(5), topology[0].Hosts[0].Stores[0].Store.ID)
+	assert.Empty(t, topology[0].Hosts[0].Stores[0].Replicas)
+
+	z1 := topology[1]
+	assert.Equal(t, "z1", z1.Name)
+	assert.Equal(t, 2, len(z1.Hosts))
+	assert.Equal(t, "h1", z1.Hosts[0].Name)
+	s1 := z1.Hosts[0].Stores[0]
+	assert.Equal(t, uint64(1), s1.Store.ID)
+	assert.Equal(t, 2, len(s1.Replicas))
+	assert.Equal(t, uint64(1), s1.Replicas[0].ShardID)
+	assert.False(t, s1.Replicas[0].Leader)
+	assert.Equal(t, uint64(2), s1.Replicas[1].ShardID)
+	assert.True(t, s1.Replicas[1].Leader)
+	assert.Equal(t, uint64(10), s1.Replicas[1].ApproximateSize)
+	assert.Equal(t, uint64(10), s1.Replicas[1].ApproximateKeys)
+
+	z2 := topology[2]
+	assert.Equal(t, "z2", z2.Name)
+	assert.Equal(t, 1, len(z2.Hosts))
+	assert.Equal(t, 2, len(z2.Hosts[0].Stores))
+	assert.Equal(t, uint64(3), z2.Hosts[0].Stores[0].Store.ID)
+	assert.Equal(t, uint64(4), z2.Hosts[0].Stores[1].Store.ID)
+	assert.True(t, z2.Hosts[0].Stores[1].Replicas[0].Leader)
+
+	// group by the host label only
+	topology = tc.GetTopology("host", "zone")
+	assert.Equal(t, 4, len(topology))
+	assert.Equal(t, "h3", topology[3].Name)
+	assert.Equal(t, "z2", topology[3].Hosts[0].Name)
+}
@@ -15,9 +15,13 @@
 package cluster
 
 import (
+	"bytes"
 	"fmt"
+	"sort"
+	"time"
 
 	"github.com/fagongzi/util/protoc"
+	"github.com/matrixorigin/matrixcube/components/log"
 	"github.com/matrixorigin/matrixcube/components/prophet/core"
 	"github.com/matrixorigin/matrixcube/components/prophet/event"
 	"github.com/matrixorigin/matrixcube/components/prophet/id"
@@ -64,11 +68,14 @@ func (c *RaftCluster) HandleCreateDestroying(req rpcpb.CreateDestroyingReq) (met
 		return status.State, nil
 	}
 
+	now := time.Now().Unix()
 	status = &metapb.DestroyingStatus{
 		State:      metapb.ShardState_Destroying,
 		Index:      req.Index,
 		Replicas:   make(map[uint64]bool),
 		RemoveData: req.RemoveData,
+		CreatedAt:  now,
+		UpdatedAt:  now,
 	}
 	for _, id := range req.Replicas {
 		status.Replicas[id] = false
@@ -107,6 +114,7 @@ func (c *RaftCluster) HandleReportDestroyed(req rpcpb.ReportDestroyedReq) (metap
 	}
 
 	status.Replicas[req.ReplicaID] = true
+	status.UpdatedAt = time.Now().Unix()
 	n := 0
 	for _, destroyed := range status.Replicas {
 		if destroyed {
@@ -132,6 +140,58 @@ func (c *RaftCluster) HandleGetDestroying(req rpcpb.GetDestroyingReq) (*metapb.D
 	return c.getDestroyingStatusLocked(req.ID)
 }
 
+// HandleListDestroyingShards returns all the shards in the Destroying state with
+// the confirmation status of their replicas in the order of the shard id.
+func (c *RaftCluster) HandleListDestroyingShards(req rpcpb.ListDestroyingShardsReq) (*rpcpb.ListDestroyingShardsRsp, error) {
+	c.RLock()
+	defer c.RUnlock()
+
+	shards, err := c.listDestroyingShardsLocked(time.Now())
+	if err != nil {
+		return nil, err
+	}
+	return &rpcpb.ListDestroyingShardsRsp{Shards: shards}, nil
+}
+
+// listDestroyingShardsLocked returns the shards in the Destroying state, a shard
+// is stuck if no replica confirmed the destroying for the stuck timeout. The
+// status created before the update time was recorded is never stuck.
+func (c *RaftCluster) listDestroyingShardsLocked(now time.Time) ([]rpcpb.DestroyingShard, error) {
+	ids := make(map[uint64]struct{})
+	for _, res := range c.core.GetDestroyingShards() {
+		ids[res.Meta.GetID()] = struct{}{}
+	}
+	for _, id := range c.core.GetDestroyingStatusIDs() {
+		ids[id] = struct{}{}
+	}
+
+	timeout := c.opt.GetDestroyingStuckTimeout()
+	shards := make([]rpcpb.DestroyingShard, 0, len(ids))
+	for id := range ids {
+		if c.core.AlreadyRemoved(id) {
+			continue
+		}
+		status, err := c.getDestroyingStatusLocked(id)
+		if err != nil {
+			return nil, err
+		}
+		if status == nil || status.State != metapb.ShardState_Destroying {
+			continue
+		}
+
+		shards = append(shards, rpcpb.DestroyingShard{
+			ID:     id,
+			Status: *status,
+			Stuck: status.UpdatedAt > 0 &&
+				now.Sub(time.Unix(status.UpdatedAt, 0)) >= timeout,
+		})
+	}
+	sort.Slice(shards, func(i, j int) bool {
+		return shards[i].ID < shards[j].ID
+	})
+	return shards, nil
+}
+
 // ValidRequestShard is used to decide if the resource is valid.
 func (c *RaftCluster) ValidRequestShard(reqShard *metapb.Shard) error {
 	startKey, _ := reqShard.GetRange()
@@ -203,6 +263,10 @@ func (c *RaftCluster) HandleAskBatchSplit(request *rpcpb.ProphetRequest) (*rpcpb
 
 // HandleCreateShards handle create resources. It will create resources with full replica peers.
 func (c *RaftCluster) HandleCreateShards(request *rpcpb.ProphetRequest) (*rpcpb.CreateShardsRsp, error) {
+	if request.CreateShards.Bulk {
+		return c.handleBulkCreateShards(request)
+	}
+
 	if len(request.CreateShards.Shards) > 4 {
 		return nil, fmt.Errorf("exceed the maximum batch size of create resources, max is %d current %d",
 			4, len(request.CreateShards.Shards))
@@ -215,9 +279,7 @@ func (c *RaftCluster) HandleCreateShards(request *rpcpb.ProphetRequest) (*rpcpb.
 	c.RLock()
 	defer c.RUnlock()
 
-	var shardsMeta []metapb.Shard
 	var createdShards []metapb.Shard
-	var leastPeers []int
 	for idx, data := range request.CreateShards.Shards {
 		res := metapb.Shard{}
 		err := res.Unmarshal(data)
@@ -252,54 +314,144 @@ func (c *RaftCluster) HandleCreateShards(request *rpcpb.ProphetRequest) (*rpcpb.
 			continue
 		}
 
-		id, err := c.storage.AllocID()
+		res, err = c.newCreatingShardLocked(res, int(request.CreateShards.LeastReplicas[idx]))
 		if err != nil {
 			return nil, err
 		}
-		res.SetID(id)
-		res.SetState(metapb.ShardState_Creating)
+		createdShards = append(createdShards, res)
+	}
 
-		_, err = c.core.PreCheckPutShard(core.NewCachedShard(res, nil))
-		if err != nil {
-			return nil, err
+	err := c.storage.PutShards(createdShards...)
+	if err != nil {
+		return nil, err
+	}
+
+	c.core.AddWaitingCreateShards(createdShards...)
+	c.triggerNotifyCreateShards()
+	return &rpcpb.CreateShardsRsp{}, nil
+}
+
+// handleBulkCreateShards creates a large number of shards in batches. The IDs
+// and replicas of the shards in a batch are allocated together and persisted
+// in one etcd txn, and all the waiting shards are notified to the watchers by
+// a single consolidated event instead of one event per shard. The batches
+// persisted before an error are kept, shards with the same non-empty unique
+// are skipped, so the request can be retried.
+func (c *RaftCluster) handleBulkCreateShards(request *rpcpb.ProphetRequest) (*rpcpb.CreateShardsRsp, error) {
+	req := request.CreateShards
+	if req.LeastReplicas != nil && len(req.LeastReplicas) != len(req.Shards) {
+		return nil, fmt.Errorf("least replicas length %d != shards length %d",
+			len(req.LeastReplicas), len(req.Shards))
+	}
+
+	c.RLock()
+	defer c.RUnlock()
+
+	uniques := make(map[string]struct{})
+	for _, cr := range c.core.GetShards() {
+		if unique := cr.Meta.GetUnique(); unique != "" {
+			uniques[unique] = struct{}{}
 		}
-		shardsMeta = append(shardsMeta, res)
-		leastPeers = append(leastPeers, int(request.CreateShards.LeastReplicas[idx]))
 	}
+	c.core.ForeachWaitingCreateShards(func(res metapb.Shard) {
+		if unique := res.GetUnique(); unique != "" {
+			uniques[unique] = struct{}{}
+		}
+	})
 
-	for idx, res := range shardsMeta {
-		cachedShard := core.NewCachedShard(res, nil)
-		err := c.coordinator.checkers.FillReplicas(cachedShard, leastPeers[idx])
-		if err != nil {
-			return nil, err
+	created := 0
+	batch := make([]metapb.Shard, 0, bulkCreateShardsBatchSize)
+	for start := 0; start < len(req.Shards); start += bulkCreateShardsBatchSize {
+		end := start + bulkCreateShardsBatchSize
+		if end > len(req.Shards) {
+			end = len(req.Shards)
 		}
 
-		cachedShard.Meta.SetEpoch(metapb.ShardEpoch{ConfigVer: uint64(len(cachedShard.Meta.GetReplicas()))})
-		for idx := range cachedShard.Meta.GetReplicas() {
-			id, err := c.storage.AllocID()
+		batch = batch[:0]
+		for idx := start; idx < end; idx++ {
+			res := metapb.Shard{}
+			if err := res.Unmarshal(req.Shards[idx]); err != nil {
+				return nil, err
+			}
+			if len(res.GetReplicas()) > 0 {
+				return nil, fmt.Errorf("cann't assign peers in create resources")
+			}
+			if unique := res.GetUnique(); unique != "" {
+				if _, ok := uniques[unique]; ok {
+					c.logger.Info("resource already created",
+						zap.String("unique", unique))
+					continue
+				}
+				uniques[unique] = struct{}{}
+			}
+
+			leastPeers := 0
+			if req.LeastReplicas != nil {
+				leastPeers = int(req.LeastReplicas[idx])
+			}
+			res, err := c.newCreatingShardLocked(res, leastPeers)
 			if err != nil {
 				return nil, err
 			}
+			if len(res.GetReplicas()) == 0 {
+				return nil, fmt.Errorf("no store available to create resource %s", res.GetUnique())
+			}
+			batch = append(batch, res)
+		}
+		if len(batch) == 0 {
+			continue
+		}
 
-			cachedShard.Meta.GetReplicas()[idx].ID = id
-			cachedShard.Meta.GetReplicas()[idx].InitialMember = true
+		if err := c.storage.PutShards(batch...); err != nil {
+			return nil, err
 		}
+		c.core.AddBulkWaitingCreateShards(batch...)
+		created += len(batch)
+	}
 
-		c.logger.Info("resource created",
-			zap.Uint64("resource", cachedShard.Meta.GetID()),
-			zap.Any("peers", cachedShard.Meta.GetReplicas()))
+	c.logger.Info("resources created in bulk",
+		zap.Int("request", len(req.Shards)),
+		zap.Int("created", created))
+	c.triggerNotifyCreateShards()
+	return &rpcpb.CreateShardsRsp{}, nil
+}
 
-		createdShards = append(createdShards, cachedShard.Meta)
+// newCreatingShardLocked allocates the ID and the replicas of the shard to be
+// created.
+func (c *RaftCluster) newCreatingShardLocked(res metapb.Shard, leastPeers int) (metapb.Shard, error) {
+	id, err := c.storage.AllocID()
+	if err != nil {
+		return res, err
 	}
+	res.SetID(id)
+	res.SetState(metapb.ShardState_Creating)
 
-	err := c.storage.PutShards(createdShards...)
+	cachedShard := core.NewCachedShard(res, nil)
+	_, err = c.core.PreCheckPutShard(cachedShard)
 	if err != nil {
-		return nil, err
+		return res, err
 	}
 
-	c.core.AddWaitingCreateShards(createdShards...)
-	c.triggerNotifyCreateShards()
-	return &rpcpb.CreateShardsRsp{}, nil
+	err = c.coordinator.checkers.FillReplicas(cachedShard, leastPeers)
+	if err != nil {
+		return res, err
+	}
+
+	cachedShard.Meta.SetEpoch(metapb.ShardEpoch{ConfigVer: uint64(len(cachedShard.Meta.GetReplicas()))})
+	for idx := range cachedShard.Meta.GetReplicas() {
+		id, err := c.storage.AllocID()
+		if err != nil {
+			return res, err
+		}
+
+		cachedShard.Meta.GetReplicas()[idx].ID = id
+		cachedShard.Meta.GetReplicas()[idx].InitialMember = true
+	}
+
+	c.logger.Info("resource created",
+		zap.Uint64("resource", cachedShard.Meta.GetID()),
+		zap.Any("peers", cachedShard.Meta.GetReplicas()))
+	return cachedShard.Meta, nil
 }
 
 // HandleRemoveShards handle remove resources
@@ -341,6 +493,203 @@ func (c *RaftCluster) HandleRemoveShards(request *rpcpb.ProphetRequest) (*rpcpb.
 	return &rpcpb.RemoveShardsRsp{}, nil
 }
 
+// HandleDestroyShards marks all the shards of the group in the key range with
+// all the labels as destroying. The shards are destroyed by the two phase destroy
+// protocol: the stores find the destroying shards by the shard state check and
+// report to prophet once the replica destroyed, the shard is destroyed once all
+// the replicas reported. The destroying status is created by prophet at index 0,
+// so the replicas are destroyed without waiting for any log. The shards are
+// persisted in batches, and the request can be retried. The IDs of all the
+// matched shards which are not destroyed yet are returned.
+func (c *RaftCluster) HandleDestroyShards(request *rpcpb.ProphetRequest) (*rpcpb.DestroyShardsRsp, error) {
+	req := request.DestroyShards
+	if len(req.Start) == 0 && len(req.End) == 0 && len(req.Labels) == 0 {
+		return nil, fmt.Errorf("missing key range or labels to match the destroy shards")
+	}
+
+	c.Lock()
+	defer c.Unlock()
+
+	rsp := &rpcpb.DestroyShardsRsp{}
+	batch := make([]*core.CachedShard, 0, destroyShardsBatchSize)
+	for _, res := range c.core.GetShards() {
+		id := res.Meta.GetID()
+		if !MatchDestroyShards(req, res.Meta) || c.core.AlreadyRemoved(id) {
+			continue
+		}
+
+		rsp.IDs = append(rsp.IDs, id)
+		if res.Meta.GetState() == metapb.ShardState_Destroying {
+			continue
+		}
+		status, err := c.getDestroyingStatusLocked(id)
+		if err != nil {
+			return nil, err
+		}
+		if status != nil {
+			continue
+		}
+
+		batch = append(batch, res)
+		if len(batch) == destroyShardsBatchSize {
+			if err := c.doDestroyShardsLocked(batch, req.RemoveData); err != nil {
+				return nil, err
+			}
+			batch = batch[:0]
+		}
+	}
+	if err := c.doDestroyShardsLocked(batch, req.RemoveData); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(rsp.IDs, func(i, j int) bool {
+		return rsp.IDs[i] < rsp.IDs[j]
+	})
+	c.logger.Info("resources marked as destroying",
+		zap.Uint64("group", req.Group),
+		log.HexField("start", req.Start),
+		log.HexField("end", req.End),
+		zap.Any("labels", req.Labels),
+		zap.Int("count", len(rsp.IDs)))
+	return rsp, nil
+}
+
+// HandleSetPreferredLeader sets the preferred leader store of the shard, the
+// leader is moved to the preferred store by the checkers once the store is
+// healthy. Zero store clears the preferred leader.
+func (c *RaftCluster) HandleSetPreferredLeader(request *rpcpb.ProphetRequest) error {
+	req := request.SetPreferredLeader
+	c.Lock()
+	defer c.Unlock()
+
+	if c.core.GetShard(req.ShardID) == nil {
+		return fmt.Errorf("shard %d not found", req.ShardID)
+	}
+	if req.StoreID == 0 {
+		if err := c.storage.RemovePreferredLeader(req.ShardID); err != nil {
+			return err
+		}
+	} else {
+		if c.core.GetStore(req.StoreID) == nil {
+			return fmt.Errorf("store %d not found", req.StoreID)
+		}
+		if err := c.storage.PutPreferredLeader(req.ShardID, req.StoreID); err != nil {
+			return err
+		}
+	}
+
+	c.core.SetShardPreferredLeader(req.ShardID, req.StoreID)
+	c.suspectShards.Put(req.ShardID, nil)
+	c.logger.Info("shard preferred leader changed",
+		log.ResourceField(req.ShardID),
+		log.StoreIDField(req.StoreID))
+	return nil
+}
+
+// HandleGetShardRoute returns the shards and their leaders held by the prophet,
+// the shards are looked up by the ids, and by the keys of the group. The shards
+// not found are omitted, and a shard is returned once even if it holds several
+// requested keys.
+func (c *RaftCluster) HandleGetShardRoute(request *rpcpb.ProphetRequest) (rpcpb.GetShardRouteRsp, error) {
+	req := request.GetShardRoute
+	rsp := rpcpb.GetShardRouteRsp{}
+	added := make(map[uint64]struct{})
+	addRoute := func(res *core.CachedShard) {
+		if res == nil {
+			return
+		}
+		if _, ok := added[res.Meta.ID]; ok {
+			return
+		}
+
+		added[res.Meta.ID] = struct{}{}
+		route := rpcpb.ShardRoute{Shard: res.Meta}
+		if leader := res.GetLeader(); leader != nil && leader.ID > 0 {
+			route.Leader = *leader
+			if store := c.core.GetStore(leader.StoreID); store != nil {
+				route.LeaderStore = store.Meta
+			}
+		}
+		rsp.Routes = append(rsp.Routes, route)
+	}
+
+	for _, id := range req.ShardIDs {
+		addRoute(c.core.GetShard(id))
+	}
+	for _, key := range req.Keys {
+		addRoute(c.core.SearchShard(req.Group, key))
+	}
+	return rsp, nil
+}
+
+func (c *RaftCluster) doDestroyShardsLocked(shards []*core.CachedShard, removeData bool) error {
+	if len(shards) == 0 {
+		return nil
+	}
+
+	metas := make([]metapb.Shard, 0, len(shards))
+	extras := make([][]byte, 0, len(shards))
+	statuses := make([]*metapb.DestroyingStatus, 0, len(shards))
+	now := time.Now().Unix()
+	for idx, res := range shards {
+		shards[idx] = res.Clone(core.WithState(metapb.ShardState_Destroying))
+		status := &metapb.DestroyingStatus{
+			State:      metapb.ShardState_Destroying,
+			Replicas:   make(map[uint64]bool),
+			RemoveData: removeData,
+			CreatedAt:  now,
+			UpdatedAt:  now,
+		}
+		for _, r := range res.Meta.GetReplicas() {
+			status.Replicas[r.ID] = false
+		}
+		metas = append(metas, shards[idx].Meta)
+		extras = append(extras, protoc.MustMarshal(status))
+		statuses = append(statuses, status)
+	}
+	if err := c.storage.PutShardsAndExtras(metas, extras); err != nil {
+		return err
+	}
+
+	for idx, res := range shards {
+		c.core.PutShard(res)
+		c.core.UpdateDestroyingStatus(res.Meta.GetID(), statuses[idx])
+		c.addNotifyLocked(event.NewShardEvent(res.Meta, res.GetLeader().GetID(), false, false))
+	}
+	return nil
+}
+
+// MatchDestroyShards returns true if the shard is in the group and the key range
+// of the request, and has all the labels of the request.
+func MatchDestroyShards(req rpcpb.DestroyShardsReq, shard metapb.Shard) bool {
+	if shard.GetGroup() != req.Group {
+		return false
+	}
+
+	start, end := shard.GetRange()
+	if bytes.Compare(start, req.Start) < 0 {
+		return false
+	}
+	if len(req.End) > 0 &&
+		(len(end) == 0 || bytes.Compare(end, req.End) > 0) {
+		return false
+	}
+
+	for _, l := range req.Labels {
+		found := false
+		for _, sl := range shard.GetLabels() {
+			if sl.Key == l.Key && sl.Value == l.Value {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
 // HandleCheckShardState handle check resource state
 func (c *RaftCluster) HandleCheckShardState(request *rpcpb.ProphetRequest) (*rpcpb.CheckShardStateRsp, error) {
 	c.RLock()
@@ -358,6 +707,36 @@ func (c *RaftCluster) HandlePutPlacementRule(request *rpcpb.ProphetRequest) erro
 	return c.GetRuleManager().SetRule(placement.NewRuleFromRPC(request.PutPlacementRule.Rule))
 }
 
+// HandlePutPlacementRuleGroupBundle handle put placement rule group bundle
+func (c *RaftCluster) HandlePutPlacementRuleGroupBundle(request *rpcpb.ProphetRequest) (*rpcpb.PutPlacementRuleGroupBundleRsp, error) {
+	if !c.GetOpts().IsPlacementRulesEnabled() {
+		return nil, fmt.Errorf("placement rules feature is disabled")
+	}
+	version, err := c.GetRuleManager().CompareAndSetGroupBundle(
+		placement.NewGroupBundleFromRPC(request.PutPlacementRuleGroupBundle.Bundle))
+	if err != nil {
+		return nil, err
+	}
+	return &rpcpb.PutPlacementRuleGroupBundleRsp{Version: version}, nil
+}
+
+// HandleGetPlacementRuleGroupBundle handle get placement rule group bundle
+func (c *RaftCluster) HandleGetPlacementRuleGroupBundle(request *rpcpb.ProphetRequest) (*rpcpb.GetPlacementRuleGroupBundleRsp, error) {
+	id := request.GetPlacementRuleGroupBundle.ID
+	if c.GetRuleManager().GetRuleGroup(id) == nil {
+		return nil, fmt.Errorf("placement rule group %s not found", id)
+	}
+	return &rpcpb.GetPlacementRuleGroupBundleRsp{
+		Bundle: placement.RPCGroupBundle(c.GetRuleManager().GetGroupBundle(id)),
+	}, nil
+}
+
+// HandleDeletePlacementRuleGroupBundle handle delete placement rule group bundle
+func (c *RaftCluster) HandleDeletePlacementRuleGroupBundle(request *rpcpb.ProphetRequest) error {
+	req := request.DeletePlacementRuleGroupBundle
+	return c.GetRuleManager().CompareAndDeleteGroupBundle(req.ID, req.Version)
+}
+
 // HandleAppliedRules handle get applied rules
 func (c *RaftCluster) HandleAppliedRules(request *rpcpb.ProphetRequest) (*rpcpb.GetAppliedRulesRsp, error) {
 	res := c.GetShard(request.GetAppliedRules.ShardID)
@@ -424,9 +803,33 @@ func (c *RaftCluster) triggerNotifyCreateShards() {
 }
 
 func (c *RaftCluster) doNotifyCreateShards() {
+	var shards []metapb.Shard
 	c.core.ForeachWaitingCreateShards(func(res metapb.Shard) {
-		c.addNotifyLocked(event.NewShardEvent(res, 0, false, true))
+		shards = append(shards, res)
 	})
+
+	var bulkShards []metapb.Shard
+	for _, res := range shards {
+		if c.core.IsBulkCreateShard(res.GetID()) {
+			bulkShards = append(bulkShards, res)
+			continue
+		}
+		c.addNotifyLocked(event.NewShardEvent(res, 0, false, true))
+	}
+	if len(bulkShards) == 0 {
+		return
+	}
+
+	// notify all the waiting shards created in bulk by a single event, avoid filling
+	// up the changed events queue with thousands of events after a bulk creation
+	evt, err := event.NewCreateShardsEvent(bulkShards)
+	if err != nil {
+		c.logger.Error("failed to create the create shards event",
+			zap.Int("count", len(bulkShards)),
+			zap.Error(err))
+		return
+	}
+	c.addNotifyLocked(evt)
 }
 
 func (c *RaftCluster) getDestroyingStatusLocked(id uint64) (*metapb.DestroyingStatus, error) {
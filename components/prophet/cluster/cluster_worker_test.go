@@ -15,6 +15,7 @@
 package cluster
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/RoaringBitmap/roaring/roaring64"
@@ -125,6 +126,73 @@ func TestCreateShardsRestart(t *testing.T) {
 	assert.True(t, e.ShardEvent.Create)
 }
 
+func TestBulkCreateShards(t *testing.T) {
+	cluster, co, cleanup := prepare(t, nil, nil, nil)
+	defer cleanup()
+
+	cluster.coordinator = co
+
+	n := bulkCreateShardsBatchSize*2 + 1
+	req := &rpcpb.ProphetRequest{}
+	req.CreateShards.Bulk = true
+	for i := 0; i < n; i++ {
+		res := newTestShardMeta(uint64(i + 1))
+		res.SetUnique(fmt.Sprintf("res%d", i))
+		data, err := res.Marshal()
+		assert.NoError(t, err)
+		req.CreateShards.Shards = append(req.CreateShards.Shards, data)
+	}
+
+	// no store available
+	_, err := cluster.HandleCreateShards(req)
+	assert.Error(t, err)
+	assert.Equal(t, 0, len(cluster.core.WaitingCreateShards))
+
+	cluster.addShardStore(1, 1)
+	cluster.addShardStore(2, 1)
+	cluster.addShardStore(3, 1)
+	_, err = cluster.HandleCreateShards(req)
+	assert.NoError(t, err)
+	assert.Equal(t, n, len(cluster.core.WaitingCreateShards))
+
+	// recreate
+	_, err = cluster.HandleCreateShards(req)
+	assert.NoError(t, err)
+	assert.Equal(t, n, len(cluster.core.WaitingCreateShards))
+
+	for _, res := range cluster.core.WaitingCreateShards {
+		assert.Equal(t, 3, len(res.GetReplicas()))
+		v, err := cluster.storage.GetShard(res.GetID())
+		assert.NoError(t, err)
+		assert.Equal(t, metapb.ShardState_Creating, v.GetState())
+	}
+
+	// the shards not created in bulk are notified one by one
+	req = &rpcpb.ProphetRequest{}
+	for i := 0; i < 2; i++ {
+		res := newTestShardMeta(uint64(n + i + 1))
+		res.SetUnique(fmt.Sprintf("res%d", n+i))
+		data, err := res.Marshal()
+		assert.NoError(t, err)
+		req.CreateShards.Shards = append(req.CreateShards.Shards, data)
+		req.CreateShards.LeastReplicas = append(req.CreateShards.LeastReplicas, 0)
+	}
+	_, err = cluster.HandleCreateShards(req)
+	assert.NoError(t, err)
+	assert.Equal(t, n+2, len(cluster.core.WaitingCreateShards))
+
+	cluster.doNotifyCreateShards()
+	for i := 0; i < 2; i++ {
+		e := <-cluster.ChangedEventNotifier()
+		assert.Equal(t, event.ShardEvent, e.Type)
+		assert.True(t, e.ShardEvent.Create)
+	}
+	e := <-cluster.ChangedEventNotifier()
+	assert.Equal(t, event.CreateShardsEvent, e.Type)
+	assert.Equal(t, n, len(e.InitEvent.Shards))
+	assert.Equal(t, 0, len(cluster.ChangedEventNotifier()))
+}
+
 func TestRemoveShards(t *testing.T) {
 	_, opt, err := newTestScheduleConfig()
 	assert.NoError(t, err)
@@ -191,6 +259,178 @@ func TestRemoveShards(t *testing.T) {
 	assert.Equal(t, 1, cache.GetShardCount())
 }
 
+func TestDestroyShards(t *testing.T) {
+	_, opt, err := newTestScheduleConfig()
+	assert.NoError(t, err)
+	cluster := newTestRaftCluster(opt, storage.NewTestStorage(), core.NewBasicCluster(nil))
+	cache := cluster.core.Shards
+	nc := cluster.ChangedEventNotifier()
+
+	n, np := uint64(7), uint64(3)
+	resources := newTestShards(n, np)
+	resources[5].Meta.Labels = []metapb.Label{{Key: "table", Value: "t1"}}
+	resources[6].Meta.Labels = []metapb.Label{{Key: "table", Value: "t1"}}
+	// add 1,2,3,4,5,6
+	for i := uint64(1); i < n; i++ {
+		cluster.processShardHeartbeat(resources[i])
+		checkNotifyCount(t, nc, event.ShardEvent, event.ShardStatsEvent)
+	}
+
+	_, err = cluster.HandleDestroyShards(&rpcpb.ProphetRequest{})
+	assert.Error(t, err)
+
+	// destroy by key range
+	rsp, err := cluster.HandleDestroyShards(&rpcpb.ProphetRequest{
+		DestroyShards: rpcpb.DestroyShardsReq{Start: []byte{1}, End: []byte{3}, RemoveData: true},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []uint64{1, 2}, rsp.IDs)
+	checkNotifyCount(t, nc, event.ShardEvent, event.ShardEvent)
+	for _, id := range rsp.IDs {
+		assert.Equal(t, metapb.ShardState_Destroying, cache.GetShard(id).Meta.GetState())
+		status := cluster.core.GetDestroyingStatus(id)
+		assert.NotNil(t, status)
+		assert.True(t, status.RemoveData)
+		assert.Equal(t, int(np), len(status.Replicas))
+	}
+	assert.Equal(t, metapb.ShardState_Running, cache.GetShard(3).Meta.GetState())
+
+	// retry returns the same shards without new changes
+	rsp, err = cluster.HandleDestroyShards(&rpcpb.ProphetRequest{
+		DestroyShards: rpcpb.DestroyShardsReq{Start: []byte{1}, End: []byte{3}, RemoveData: true},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []uint64{1, 2}, rsp.IDs)
+	select {
+	case <-nc:
+		assert.FailNow(t, "unexpected notify")
+	default:
+	}
+
+	// destroy by labels
+	rsp, err = cluster.HandleDestroyShards(&rpcpb.ProphetRequest{
+		DestroyShards: rpcpb.DestroyShardsReq{Labels: []metapb.Label{{Key: "table", Value: "t1"}}},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []uint64{5, 6}, rsp.IDs)
+	checkNotifyCount(t, nc, event.ShardEvent, event.ShardEvent)
+
+	// heartbeat of the running replicas keeps the destroying state
+	assert.NoError(t, cluster.processShardHeartbeat(resources[1]))
+	assert.Equal(t, metapb.ShardState_Destroying, cache.GetShard(1).Meta.GetState())
+
+	cnt := 0
+	cluster.storage.LoadShards(10, func(r metapb.Shard) {
+		if r.GetID() == 1 || r.GetID() == 2 || r.GetID() == 5 || r.GetID() == 6 {
+			assert.Equal(t, metapb.ShardState_Destroying, r.GetState())
+			cnt++
+		}
+	})
+	assert.Equal(t, 4, cnt)
+
+	// all replicas reported
+	var state metapb.ShardState
+	for _, r := range resources[1].Meta.GetReplicas() {
+		state, err = cluster.HandleReportDestroyed(rpcpb.ReportDestroyedReq{ID: 1, ReplicaID: r.ID})
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, metapb.ShardState_Destroyed, state)
+	assert.True(t, cluster.core.DestroyedShards.Contains(1))
+}
+
+func TestListDestroyingShards(t *testing.T) {
+	_, opt, err := newTestScheduleConfig()
+	assert.NoError(t, err)
+	cluster := newTestRaftCluster(opt, storage.NewTestStorage(), core.NewBasicCluster(nil))
+	nc := cluster.ChangedEventNotifier()
+
+	n, np := uint64(4), uint64(3)
+	resources := newTestShards(n, np)
+	for i := uint64(1); i < n; i++ {
+		cluster.processShardHeartbeat(resources[i])
+		checkNotifyCount(t, nc, event.ShardEvent, event.ShardStatsEvent)
+	}
+
+	_, err = cluster.HandleDestroyShards(&rpcpb.ProphetRequest{
+		DestroyShards: rpcpb.DestroyShardsReq{Start: []byte{1}, End: []byte{3}},
+	})
+	assert.NoError(t, err)
+	checkNotifyCount(t, nc, event.ShardEvent, event.ShardEvent)
+	_, err = cluster.HandleCreateDestroying(rpcpb.CreateDestroyingReq{ID: 10, Index: 1, Replicas: []uint64{100, 101}})
+	assert.NoError(t, err)
+
+	rsp, err := cluster.HandleListDestroyingShards(rpcpb.ListDestroyingShardsReq{})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(rsp.Shards))
+	for idx, id := range []uint64{1, 2, 10} {
+		assert.Equal(t, id, rsp.Shards[idx].ID)
+		assert.False(t, rsp.Shards[idx].Stuck)
+		assert.True(t, rsp.Shards[idx].Status.CreatedAt > 0)
+	}
+
+	// shard 2 is stuck, alerted once
+	status := cluster.core.GetDestroyingStatus(2)
+	status.UpdatedAt -= int64(opt.GetDestroyingStuckTimeout().Seconds())
+	cluster.core.UpdateDestroyingStatus(2, status)
+	cluster.checkDestroyingShards()
+	checkNotifyCount(t, nc, event.DestroyingStuckEvent)
+	cluster.checkDestroyingShards()
+	select {
+	case <-nc:
+		assert.FailNow(t, "unexpected notify")
+	default:
+	}
+	rsp, err = cluster.HandleListDestroyingShards(rpcpb.ListDestroyingShardsReq{})
+	assert.NoError(t, err)
+	assert.True(t, rsp.Shards[1].Stuck)
+
+	// the confirmation of a replica makes progress
+	replicaID := resources[2].Meta.GetReplicas()[0].ID
+	_, err = cluster.HandleReportDestroyed(rpcpb.ReportDestroyedReq{ID: 2, ReplicaID: replicaID})
+	assert.NoError(t, err)
+	rsp, err = cluster.HandleListDestroyingShards(rpcpb.ListDestroyingShardsReq{})
+	assert.NoError(t, err)
+	assert.False(t, rsp.Shards[1].Stuck)
+	assert.True(t, rsp.Shards[1].Status.Replicas[replicaID])
+
+	// the destroyed shard is not listed
+	for _, r := range resources[1].Meta.GetReplicas() {
+		_, err = cluster.HandleReportDestroyed(rpcpb.ReportDestroyedReq{ID: 1, ReplicaID: r.ID})
+		assert.NoError(t, err)
+	}
+	rsp, err = cluster.HandleListDestroyingShards(rpcpb.ListDestroyingShardsReq{})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(rsp.Shards))
+	assert.Equal(t, uint64(2), rsp.Shards[0].ID)
+}
+
+func TestMatchDestroyShards(t *testing.T) {
+	labels := []metapb.Label{{Key: "table", Value: "t1"}, {Key: "zone", Value: "z1"}}
+	shard := metapb.Shard{Group: 1, Start: []byte("b"), End: []byte("c"), Labels: labels}
+	cases := []struct {
+		req    rpcpb.DestroyShardsReq
+		expect bool
+	}{
+		{req: rpcpb.DestroyShardsReq{Group: 1, Start: []byte("a"), End: []byte("d")}, expect: true},
+		{req: rpcpb.DestroyShardsReq{Group: 1, Start: []byte("b"), End: []byte("c")}, expect: true},
+		{req: rpcpb.DestroyShardsReq{Group: 1, Start: []byte("a")}, expect: true},
+		{req: rpcpb.DestroyShardsReq{Group: 1, Start: []byte("bb")}, expect: false},
+		{req: rpcpb.DestroyShardsReq{Group: 1, End: []byte("bb")}, expect: false},
+		{req: rpcpb.DestroyShardsReq{Group: 2, Start: []byte("a")}, expect: false},
+		{req: rpcpb.DestroyShardsReq{Group: 1, Labels: labels[:1]}, expect: true},
+		{req: rpcpb.DestroyShardsReq{Group: 1, Labels: []metapb.Label{{Key: "table", Value: "t2"}}}, expect: false},
+		{req: rpcpb.DestroyShardsReq{Group: 1, Start: []byte("a"), Labels: labels}, expect: true},
+	}
+	for i, c := range cases {
+		assert.Equal(t, c.expect, MatchDestroyShards(c.req, shard), "index %d", i)
+	}
+
+	// the last shard with empty end key only matches the range without end
+	shard.End = nil
+	assert.False(t, MatchDestroyShards(rpcpb.DestroyShardsReq{Group: 1, End: []byte("d")}, shard))
+	assert.True(t, MatchDestroyShards(rpcpb.DestroyShardsReq{Group: 1, Start: []byte("a")}, shard))
+}
+
 func TestShardHeartbeatAtRemovedState(t *testing.T) {
 	cluster, co, cleanup := prepare(t, nil, nil, nil)
 	defer cleanup()
@@ -276,3 +516,76 @@ func checkNotifyCount(t *testing.T, nc <-chan rpcpb.EventNotify, expectNotifyTyp
 		}
 	}
 }
+
+func TestHandleSetPreferredLeader(t *testing.T) {
+	_, opt, err := newTestScheduleConfig()
+	assert.NoError(t, err)
+	cluster := newTestRaftCluster(opt, storage.NewTestStorage(), core.NewBasicCluster(nil))
+	for _, s := range newTestStores(3, "") {
+		assert.NoError(t, cluster.putStoreLocked(s))
+	}
+	resources := newTestShards(3, 3)
+	for _, res := range resources {
+		cluster.processShardHeartbeat(res)
+	}
+
+	setPreferredLeader := func(shardID, storeID uint64) error {
+		return cluster.HandleSetPreferredLeader(&rpcpb.ProphetRequest{
+			SetPreferredLeader: rpcpb.SetPreferredLeaderReq{ShardID: shardID, StoreID: storeID},
+		})
+	}
+	assert.Error(t, setPreferredLeader(100, 1))
+	assert.Error(t, setPreferredLeader(1, 100))
+
+	assert.NoError(t, setPreferredLeader(1, 2))
+	assert.NoError(t, setPreferredLeader(2, 3))
+	assert.Equal(t, uint64(2), cluster.GetShardPreferredLeader(1))
+	assert.Equal(t, uint64(3), cluster.GetShardPreferredLeader(2))
+	assert.Equal(t, uint64(0), cluster.GetShardPreferredLeader(0))
+	assert.Subset(t, cluster.GetSuspectShards(), []uint64{1, 2})
+
+	assert.NoError(t, setPreferredLeader(2, 0))
+	assert.Equal(t, uint64(0), cluster.GetShardPreferredLeader(2))
+
+	// restart
+	cluster = newTestRaftCluster(opt, cluster.storage, core.NewBasicCluster(nil))
+	_, err = cluster.LoadClusterInfo()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), cluster.GetShardPreferredLeader(1))
+	assert.Equal(t, uint64(0), cluster.GetShardPreferredLeader(2))
+}
+
+func TestHandleGetShardRoute(t *testing.T) {
+	_, opt, err := newTestScheduleConfig()
+	assert.NoError(t, err)
+	cluster := newTestRaftCluster(opt, storage.NewTestStorage(), core.NewBasicCluster(nil))
+	for _, s := range newTestStores(3, "") {
+		assert.NoError(t, cluster.putStoreLocked(s))
+	}
+	resources := newTestShards(3, 3)
+	for _, res := range resources {
+		cluster.processShardHeartbeat(res)
+	}
+
+	getShardRoute := func(req rpcpb.GetShardRouteReq) rpcpb.GetShardRouteRsp {
+		rsp, err := cluster.HandleGetShardRoute(&rpcpb.ProphetRequest{GetShardRoute: req})
+		assert.NoError(t, err)
+		return rsp
+	}
+	assert.Empty(t, getShardRoute(rpcpb.GetShardRouteReq{ShardIDs: []uint64{100}}).Routes)
+	assert.Empty(t, getShardRoute(rpcpb.GetShardRouteReq{Group: 1, Keys: [][]byte{{1}}}).Routes)
+
+	rsp := getShardRoute(rpcpb.GetShardRouteReq{ShardIDs: []uint64{1, 100}})
+	assert.Equal(t, 1, len(rsp.Routes))
+	assert.Equal(t, resources[1].Meta, rsp.Routes[0].Shard)
+	assert.Equal(t, *resources[1].GetLeader(), rsp.Routes[0].Leader)
+	assert.Equal(t, resources[1].GetLeader().StoreID, rsp.Routes[0].LeaderStore.ID)
+
+	// the shard holding several keys is returned once
+	rsp = getShardRoute(rpcpb.GetShardRouteReq{ShardIDs: []uint64{2},
+		Keys: [][]byte{{1}, {1, 1}, {2}}})
+	assert.Equal(t, 2, len(rsp.Routes))
+	assert.Equal(t, uint64(2), rsp.Routes[0].Shard.ID)
+	assert.Equal(t, resources[2].GetLeader().StoreID, rsp.Routes[0].LeaderStore.ID)
+	assert.Equal(t, uint64(1), rsp.Routes[1].Shard.ID)
+}
@@ -28,6 +28,7 @@ import (
 	"github.com/matrixorigin/matrixcube/components/prophet/schedule"
 	"github.com/matrixorigin/matrixcube/components/prophet/schedule/hbstream"
 	"github.com/matrixorigin/matrixcube/components/prophet/schedule/operator"
+	"github.com/matrixorigin/matrixcube/components/prophet/schedule/opt"
 	"github.com/matrixorigin/matrixcube/components/prophet/schedulers"
 	"github.com/matrixorigin/matrixcube/components/prophet/statistics"
 	"github.com/matrixorigin/matrixcube/components/prophet/storage"
@@ -63,6 +64,7 @@ type coordinator struct {
 	opController      *schedule.OperatorController
 	hbStreams         *hbstream.HeartbeatStreams
 	pluginInterface   *schedule.PluginInterface
+	configGuard       configGuard
 }
 
 // newCoordinator creates a new coordinator.
@@ -403,7 +405,13 @@ func (c *coordinator) run() {
 		c.cluster.logger.Info("create scheduler",
 			zap.String("name", s.GetName()),
 			zap.Any("args", schedulerCfg.Args))
-		if err = c.addScheduler(s, schedulerCfg.Args...); err != nil && err != errSchedulerExists {
+		err = c.addScheduler(s, schedulerCfg.Args...)
+		if err == errSchedulerExists {
+			// creating with the args overwrites the independent configuration of
+			// the scheduler already added
+			c.saveSchedulerConfig(s.GetName())
+		}
+		if err != nil && err != errSchedulerExists {
 			c.cluster.logger.Error("fail to add scheduler",
 				zap.String("name", s.GetName()),
 				zap.Any("args", schedulerCfg.Args),
@@ -423,10 +431,11 @@ func (c *coordinator) run() {
 			zap.Error(err))
 	}
 
-	c.wg.Add(2)
+	c.wg.Add(3)
 	// Starts to patrol resources.
 	go c.patrolShards()
 	go c.drivePushOperator()
+	go c.watchScheduleConfigChanges()
 }
 
 func (c *coordinator) stop() {
@@ -459,6 +468,10 @@ func (c *coordinator) getHotReadShards() *statistics.StoreHotPeersInfos {
 	c.RLock()
 	defer c.RUnlock()
 	s, ok := c.schedulers[schedulers.HotShardName]
+	if !ok {
+		// the hot read shards are also balanced by the read only scheduler
+		s, ok = c.schedulers[schedulers.HotReadShardName]
+	}
 	if !ok {
 		return nil
 	}
@@ -468,6 +481,41 @@ func (c *coordinator) getHotReadShards() *statistics.StoreHotPeersInfos {
 	return nil
 }
 
+func (c *coordinator) saveSchedulerConfig(name string) {
+	c.RLock()
+	defer c.RUnlock()
+	s, ok := c.schedulers[name]
+	if !ok {
+		return
+	}
+	data, err := s.EncodeConfig()
+	if err == nil {
+		err = c.cluster.storage.SaveScheduleConfig(name, data)
+	}
+	if err != nil {
+		c.cluster.logger.Error("fail to save scheduler config",
+			zap.String("name", name),
+			zap.Error(err))
+	}
+}
+
+type hasEvictLeaderStores interface {
+	AddStore(cluster opt.Cluster, storeID uint64) error
+	RemoveStore(cluster opt.Cluster, storeID uint64) (bool, error)
+	GetStores() []uint64
+}
+
+func (c *coordinator) getEvictLeaderScheduler() (hasEvictLeaderStores, bool) {
+	c.RLock()
+	defer c.RUnlock()
+	s, ok := c.schedulers[schedulers.EvictLeaderName]
+	if !ok {
+		return nil, false
+	}
+	h, ok := s.Scheduler.(hasEvictLeaderStores)
+	return h, ok
+}
+
 func (c *coordinator) getSchedulers() []string {
 	c.RLock()
 	defer c.RUnlock()
@@ -17,10 +17,12 @@ package config
 import (
 	"errors"
 	"fmt"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/BurntSushi/toml"
+	"github.com/matrixorigin/matrixcube/components/prophet/core"
 	"github.com/matrixorigin/matrixcube/components/prophet/limit"
 	"github.com/matrixorigin/matrixcube/components/prophet/metadata"
 	"github.com/matrixorigin/matrixcube/components/prophet/storage"
@@ -38,6 +40,20 @@ type Config struct {
 	RPCAddr          string            `toml:"rpc-addr"`
 	AdvertiseRPCAddr string            `toml:"rpc-advertise-addr"`
 	RPCTimeout       typeutil.Duration `toml:"rpc-timeout"`
+	// RPCMaxRetries the max number of retries of the client requests failed due to
+	// the prophet leader changed.
+	RPCMaxRetries int64 `toml:"rpc-max-retries"`
+	// HedgedReadDelay if the response of a read-only request is not received from
+	// the leader within the delay, the request is also sent to the local prophet
+	// follower, 0 means disabled.
+	HedgedReadDelay typeutil.Duration `toml:"hedged-read-delay"`
+	// WatcherQueueSize the max number of the events queued for each event watcher,
+	// the events are sent to the watchers by their own workers, so a slow watcher
+	// does not delay the others.
+	WatcherQueueSize uint64 `toml:"watcher-queue-size"`
+	// WatcherOverflowPolicy the action taken on the event watcher whose queue is
+	// full, WatcherOverflowDisconnect or WatcherOverflowDropToSnapshot.
+	WatcherOverflowPolicy string `toml:"watcher-overflow-policy"`
 
 	// etcd configuration
 	ProphetNode  bool            `toml:"prophet-node"`
@@ -183,11 +199,26 @@ type ScheduleConfig struct {
 	// EnableCrossTableMerge is the option to enable cross table merge. This means two resources can be merged with different table IDs.
 	// This option only works when key type is "table".
 	EnableCrossTableMerge bool `toml:"enable-cross-table-merge" json:"enable-cross-table-merge,string"`
+	// EmptyShardMergeWindow is the duration a shard must keep reporting the size
+	// not greater than EmptyShardMaxSize and the keys not greater than
+	// EmptyShardMaxKeys to be merged as an empty shard, e.g. after a mass
+	// deletion. The empty shards are merged into the adjacent shards regardless
+	// of the size of the target. 0 disables the empty shard merge.
+	EmptyShardMergeWindow typeutil.Duration `toml:"empty-resource-merge-window" json:"empty-resource-merge-window"`
+	EmptyShardMaxSize     uint64            `toml:"empty-resource-max-size" json:"empty-resource-max-size"`
+	EmptyShardMaxKeys     uint64            `toml:"empty-resource-max-keys" json:"empty-resource-max-keys"`
 	// PatrolShardInterval is the interval for scanning resource during patrol.
 	PatrolShardInterval typeutil.Duration `toml:"patrol-resource-interval" json:"patrol-resource-interval"`
 	// MaxStoreDownTime is the max duration after which
 	// a container will be considered to be down if it hasn't reported heartbeats.
 	MaxStoreDownTime typeutil.Duration `toml:"max-container-down-time" json:"max-container-down-time"`
+	// DuplicateShardDestroyDelay is the duration after which a shard overlapped
+	// by another shard with a newer epoch is destroyed, if the shard keeps
+	// reporting the overlapped range.
+	DuplicateShardDestroyDelay typeutil.Duration `toml:"duplicate-shard-destroy-delay" json:"duplicate-shard-destroy-delay"`
+	// DestroyingStuckTimeout is the duration after which a shard in the Destroying
+	// state is considered stuck, if none of its replicas confirmed the destroying.
+	DestroyingStuckTimeout typeutil.Duration `toml:"destroying-stuck-timeout" json:"destroying-stuck-timeout"`
 	// LeaderScheduleLimit is the max coexist leader schedules.
 	LeaderScheduleLimit uint64 `toml:"leader-schedule-limit" json:"leader-schedule-limit"`
 	// LeaderSchedulePolicy is the option to balance leader, there are some policies supported: ["count", "size"], default: "count"
@@ -239,6 +270,10 @@ type ScheduleConfig struct {
 	EnableDebugMetrics bool `toml:"enable-debug-metrics" json:"enable-debug-metrics,string"`
 	// EnableJointConsensus is the option to enable using joint consensus as a operator step.
 	EnableJointConsensus bool `toml:"enable-joint-consensus" json:"enable-joint-consensus,string"`
+	// EnableLearnerCatchUp is the option to create and catch up the target replica
+	// as a learner before the membership change removing the source replica when
+	// moving a replica.
+	EnableLearnerCatchUp bool `toml:"enable-learner-catch-up" json:"enable-learner-catch-up,string"`
 
 	// Schedulers support for loading customized schedulers
 	Schedulers SchedulerConfigs `toml:"schedulers" json:"schedulers-v2"` // json v2 is for the sake of compatible upgrade
@@ -253,6 +288,16 @@ type ScheduleConfig struct {
 	// is overwritten, the value is fixed until it is deleted.
 	// Default: manual
 	StoreLimitMode string `toml:"container-limit-mode" json:"container-limit-mode"`
+
+	// TimeWindows are the schedule limits applied in the daily time windows, e.g.
+	// the aggressive balancing in the off-peak hours. The first window contains
+	// the current time is applied by the coordinator.
+	TimeWindows []ScheduleTimeWindow `toml:"time-windows" json:"time-windows"`
+
+	// GroupLimits are the schedule limits of the shard groups, e.g. the lower limits
+	// of a bulk group, so the latency sensitive groups are not starved by its
+	// rebalancing. The operators of a group are also limited by the global limits.
+	GroupLimits []GroupScheduleLimit `toml:"group-limits" json:"group-limits"`
 }
 
 // SchedulerConfigs is a slice of customized scheduler configuration.
@@ -293,6 +338,95 @@ type StoreLimitConfig struct {
 	RemovePeer float64 `toml:"remove-peer" json:"remove-peer"`
 }
 
+// ScheduleTimeWindow is the schedule limits applied in a daily time window, the
+// zero limits keep the ones of the schedule config.
+type ScheduleTimeWindow struct {
+	// Start and End are the local times of day in the format of 15:04, the
+	// window crosses midnight if End is not after Start.
+	Start string `toml:"start" json:"start"`
+	End   string `toml:"end" json:"end"`
+	// LeaderScheduleLimit is the max coexist leader schedules in the window.
+	LeaderScheduleLimit uint64 `toml:"leader-schedule-limit" json:"leader-schedule-limit"`
+	// ShardScheduleLimit is the max coexist resource schedules in the window.
+	ShardScheduleLimit uint64 `toml:"resource-schedule-limit" json:"resource-schedule-limit"`
+	// ReplicaScheduleLimit is the max coexist replica schedules in the window.
+	ReplicaScheduleLimit uint64 `toml:"replica-schedule-limit" json:"replica-schedule-limit"`
+	// MergeScheduleLimit is the max coexist merge schedules in the window.
+	MergeScheduleLimit uint64 `toml:"merge-schedule-limit" json:"merge-schedule-limit"`
+	// HotShardScheduleLimit is the max coexist hot resource schedules in the window.
+	HotShardScheduleLimit uint64 `toml:"hot-resource-schedule-limit" json:"hot-resource-schedule-limit"`
+}
+
+// GroupScheduleLimit is the schedule limits of a shard group, the zero limits keep
+// the ones of the schedule config.
+type GroupScheduleLimit struct {
+	Group uint64 `toml:"group" json:"group"`
+	// LeaderScheduleLimit is the max coexist leader schedules of the group.
+	LeaderScheduleLimit uint64 `toml:"leader-schedule-limit" json:"leader-schedule-limit"`
+	// ShardScheduleLimit is the max coexist resource schedules of the group.
+	ShardScheduleLimit uint64 `toml:"resource-schedule-limit" json:"resource-schedule-limit"`
+	// HotShardScheduleLimit is the max coexist hot resource schedules of the group.
+	HotShardScheduleLimit uint64 `toml:"hot-resource-schedule-limit" json:"hot-resource-schedule-limit"`
+}
+
+// Contains returns true if the time of day of t is in the window.
+func (w ScheduleTimeWindow) Contains(t time.Time) bool {
+	start, err := parseTimeOfDay(w.Start)
+	if err != nil {
+		return false
+	}
+	end, err := parseTimeOfDay(w.End)
+	if err != nil {
+		return false
+	}
+	v := t.Hour()*60 + t.Minute()
+	if start < end {
+		return v >= start && v < end
+	}
+	return v >= start || v < end
+}
+
+func (w ScheduleTimeWindow) validate() error {
+	if _, err := parseTimeOfDay(w.Start); err != nil {
+		return fmt.Errorf("invalid time window start %s", w.Start)
+	}
+	if _, err := parseTimeOfDay(w.End); err != nil {
+		return fmt.Errorf("invalid time window end %s", w.End)
+	}
+	return nil
+}
+
+// parseTimeOfDay returns the minutes since midnight of the time of day
+func parseTimeOfDay(v string) (int, error) {
+	t, err := time.Parse("15:04", v)
+	if err != nil {
+		return 0, err
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// GetTimeWindow returns the first time window contains the time, nil is
+// returned if no window contains it.
+func (c *ScheduleConfig) GetTimeWindow(t time.Time) *ScheduleTimeWindow {
+	for i := range c.TimeWindows {
+		if c.TimeWindows[i].Contains(t) {
+			return &c.TimeWindows[i]
+		}
+	}
+	return nil
+}
+
+// GetGroupLimit returns the schedule limits of the shard group, nil is returned
+// if the group has no limits.
+func (c *ScheduleConfig) GetGroupLimit(group uint64) *GroupScheduleLimit {
+	for i := range c.GroupLimits {
+		if c.GroupLimits[i].Group == group {
+			return &c.GroupLimits[i]
+		}
+	}
+	return nil
+}
+
 // Clone returns a cloned scheduling configuration.
 func (c *ScheduleConfig) Clone() *ScheduleConfig {
 	schedulers := append(c.Schedulers[:0:0], c.Schedulers...)
@@ -306,6 +440,8 @@ func (c *ScheduleConfig) Clone() *ScheduleConfig {
 	cfg := *c
 	cfg.StoreLimit = containerLimit
 	cfg.Schedulers = schedulers
+	cfg.TimeWindows = append(c.TimeWindows[:0:0], c.TimeWindows...)
+	cfg.GroupLimits = append(c.GroupLimits[:0:0], c.GroupLimits...)
 	cfg.SchedulersPayload = nil
 	return &cfg
 }
@@ -326,6 +462,8 @@ func (c *ScheduleConfig) adjust(meta *configMetaData, reloading bool) error {
 	adjustDuration(&c.SplitMergeInterval, defaultSplitMergeInterval)
 	adjustDuration(&c.PatrolShardInterval, defaultPatrolShardInterval)
 	adjustDuration(&c.MaxStoreDownTime, defaultMaxStoreDownTime)
+	adjustDuration(&c.DuplicateShardDestroyDelay, defaultDuplicateShardDestroyDelay)
+	adjustDuration(&c.DestroyingStuckTimeout, defaultDestroyingStuckTimeout)
 	if !meta.IsDefined("leader-schedule-limit") {
 		adjustUint64(&c.LeaderScheduleLimit, defaultLeaderScheduleLimit)
 	}
@@ -359,6 +497,9 @@ func (c *ScheduleConfig) adjust(meta *configMetaData, reloading bool) error {
 	if !meta.IsDefined("enable-joint-consensus") {
 		c.EnableJointConsensus = defaultEnableJointConsensus
 	}
+	if !meta.IsDefined("enable-learner-catch-up") {
+		c.EnableLearnerCatchUp = defaultEnableLearnerCatchUp
+	}
 	if !meta.IsDefined("enable-cross-table-merge") {
 		c.EnableCrossTableMerge = defaultEnableCrossTableMerge
 	}
@@ -376,8 +517,6 @@ func (c *ScheduleConfig) adjust(meta *configMetaData, reloading bool) error {
 		c.StoreLimit = make(map[uint64]StoreLimitConfig)
 	}
 
-	// TODO: disable JointConsensus. Consider opening again in the future
-	c.EnableJointConsensus = false
 	return c.Validate()
 }
 
@@ -400,6 +539,47 @@ func (c *ScheduleConfig) Validate() error {
 			return fmt.Errorf("create func of %v is not registered, maybe misspelled", scheduleConfig.Type)
 		}
 	}
+	for _, w := range c.TimeWindows {
+		if err := w.validate(); err != nil {
+			return err
+		}
+	}
+	groups := make(map[uint64]struct{}, len(c.GroupLimits))
+	for _, l := range c.GroupLimits {
+		if _, ok := groups[l.Group]; ok {
+			return fmt.Errorf("duplicate schedule limits of group %d", l.Group)
+		}
+		groups[l.Group] = struct{}{}
+	}
+	return nil
+}
+
+// validateUpdate validates the scheduling configurations updated at runtime,
+// besides Validate, it checks the fields only adjusted at startup, since the
+// config updated at runtime is applied without being adjusted.
+func (c *ScheduleConfig) validateUpdate() error {
+	if err := c.Validate(); err != nil {
+		return err
+	}
+	if c.LeaderSchedulePolicy != core.ByCount.String() &&
+		c.LeaderSchedulePolicy != core.BySize.String() {
+		return fmt.Errorf("invalid leader-schedule-policy %s", c.LeaderSchedulePolicy)
+	}
+	if c.StoreLimitMode != "auto" && c.StoreLimitMode != "manual" {
+		return fmt.Errorf("invalid container-limit-mode %s", c.StoreLimitMode)
+	}
+	if c.PatrolShardInterval.Duration <= 0 {
+		return errors.New("patrol-resource-interval should be positive")
+	}
+	if c.MaxStoreDownTime.Duration <= 0 {
+		return errors.New("max-container-down-time should be positive")
+	}
+	if c.SplitMergeInterval.Duration < 0 {
+		return errors.New("split-merge-interval should be nonnegative")
+	}
+	if c.EmptyShardMergeWindow.Duration < 0 {
+		return errors.New("empty-resource-merge-window should be nonnegative")
+	}
 	return nil
 }
 
@@ -431,16 +611,62 @@ type ReplicationConfig struct {
 
 	// Groups shard groups
 	Groups []uint64
+
+	// LeaderPriorities the leader election priorities of the replicas of the shard
+	// groups on the stores matching the labels, e.g. prefer the replicas on the SSD
+	// stores to become leader. Leadership is moved to the replica with the highest
+	// priority, the replicas on the stores not matching any label have priority 0.
+	LeaderPriorities []LeaderPriority `toml:"leader-priorities" json:"leader-priorities"`
+}
+
+// LeaderPriority is the leader election priority of the replicas of a shard
+// group on the stores with the label.
+type LeaderPriority struct {
+	Group    uint64 `toml:"group" json:"group"`
+	Key      string `toml:"key" json:"key"`
+	Value    string `toml:"value" json:"value"`
+	Priority int    `toml:"priority" json:"priority"`
 }
 
 // Clone makes a deep copy of the config.
 func (c *ReplicationConfig) Clone() *ReplicationConfig {
 	locationLabels := append(c.LocationLabels[:0:0], c.LocationLabels...)
+	leaderPriorities := append(c.LeaderPriorities[:0:0], c.LeaderPriorities...)
 	cfg := *c
 	cfg.LocationLabels = locationLabels
+	cfg.LeaderPriorities = leaderPriorities
 	return &cfg
 }
 
+// GetLeaderPriority returns the highest leader priority of the group matching
+// the labels of the store.
+func (c *ReplicationConfig) GetLeaderPriority(group uint64, labels []metapb.Label) int {
+	priority := 0
+	for _, lp := range c.LeaderPriorities {
+		if lp.Group != group || lp.Priority <= priority {
+			continue
+		}
+		for _, label := range labels {
+			if strings.EqualFold(label.Key, lp.Key) && strings.EqualFold(label.Value, lp.Value) {
+				priority = lp.Priority
+				break
+			}
+		}
+	}
+	return priority
+}
+
+// GetMaxLeaderPriority returns the highest leader priority configured for the group.
+func (c *ReplicationConfig) GetMaxLeaderPriority(group uint64) int {
+	priority := 0
+	for _, lp := range c.LeaderPriorities {
+		if lp.Group == group && lp.Priority > priority {
+			priority = lp.Priority
+		}
+	}
+	return priority
+}
+
 // Validate is used to validate if some replication configurations are right.
 func (c *ReplicationConfig) Validate() error {
 	foundIsolationLevel := false
@@ -457,6 +683,14 @@ func (c *ReplicationConfig) Validate() error {
 	if c.IsolationLevel != "" && !foundIsolationLevel {
 		return errors.New("isolation-level must be one of location-labels or empty")
 	}
+	for _, lp := range c.LeaderPriorities {
+		if err := ValidateLabels([]metapb.Label{{Key: lp.Key, Value: lp.Value}}); err != nil {
+			return err
+		}
+		if lp.Priority < 0 {
+			return fmt.Errorf("leader priority of label %s=%s must be non-negative", lp.Key, lp.Value)
+		}
+	}
 	return nil
 }
 
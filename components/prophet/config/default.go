@@ -48,9 +48,12 @@ const (
 	// hot resource.
 	defaultHotShardCacheHitsThreshold  = 3
 	defaultSchedulerMaxWaitingOperator = 5
+	defaultDuplicateShardDestroyDelay  = time.Minute
+	defaultDestroyingStuckTimeout      = 10 * time.Minute
 	defaultLeaderSchedulePolicy        = "count"
 	defaultStoreLimitMode              = "manual"
 	defaultEnableJointConsensus        = false
+	defaultEnableLearnerCatchUp        = false
 	defaultEnableCrossTableMerge       = true
 )
 
@@ -60,6 +63,16 @@ var (
 	DefaultStoreLimit = StoreLimit{AddPeer: 15, RemovePeer: 15}
 )
 
+const (
+	// WatcherOverflowDisconnect the event watcher whose queue is full is
+	// disconnected, the client creates the watcher again with the init event.
+	WatcherOverflowDisconnect = "disconnect"
+	// WatcherOverflowDropToSnapshot the queued events of the event watcher whose
+	// queue is full are dropped, a snapshot of the cluster is sent instead by the
+	// init event. The watchers not watching the init event are disconnected.
+	WatcherOverflowDropToSnapshot = "drop-to-snapshot"
+)
+
 const (
 	defaultLeaderLease             = int64(3)
 	defaultNextRetryDelay          = time.Second
@@ -70,6 +83,8 @@ const (
 	defaultName                = "prophet"
 	defaultRPCAddr             = "127.0.0.1:10001"
 	defaultRPCTimeout          = time.Second * 10
+	defaultRPCMaxRetries       = int64(50)
+	defaultWatcherQueueSize    = uint64(1024)
 	defaultClientUrls          = "http://127.0.0.1:2379"
 	defaultPeerUrls            = "http://127.0.0.1:2380"
 	defaultInitialClusterState = embed.ClusterStateFlagNew
@@ -108,6 +123,9 @@ func (c *Config) Adjust(meta *toml.MetaData, reloading bool) error {
 	adjustString(&c.RPCAddr, defaultRPCAddr)
 	adjustString(&c.AdvertiseRPCAddr, c.RPCAddr)
 	adjustDuration(&c.RPCTimeout, defaultRPCTimeout)
+	adjustInt64(&c.RPCMaxRetries, defaultRPCMaxRetries)
+	adjustUint64(&c.WatcherQueueSize, defaultWatcherQueueSize)
+	adjustString(&c.WatcherOverflowPolicy, WatcherOverflowDisconnect)
 
 	if err := c.Validate(); err != nil {
 		return err
@@ -170,6 +188,12 @@ func (c *Config) Adjust(meta *toml.MetaData, reloading bool) error {
 
 // Validate is used to validate if some configurations are right.
 func (c *Config) Validate() error {
+	if c.WatcherOverflowPolicy != "" &&
+		c.WatcherOverflowPolicy != WatcherOverflowDisconnect &&
+		c.WatcherOverflowPolicy != WatcherOverflowDropToSnapshot {
+		return fmt.Errorf("invalid watcher overflow policy %s", c.WatcherOverflowPolicy)
+	}
+
 	// if c.Join != "" && c.InitialCluster != "" {
 	// 	return errors.New("-initial-cluster and -join can not be provided at the same time")
 	// }
@@ -15,10 +15,13 @@
 package config
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"reflect"
 	"strconv"
 	"strings"
+	"sync"
 	"sync/atomic"
 	"time"
 	"unsafe"
@@ -43,6 +46,10 @@ type PersistOptions struct {
 	replication    atomic.Value
 	labelProperty  atomic.Value
 	clusterVersion unsafe.Pointer
+	// timeWindow is the schedule time window applied by the coordinator
+	timeWindow atomic.Value
+	// updateMu serializes the schedule config updates and rollbacks
+	updateMu sync.Mutex
 }
 
 // NewPersistOptions creates a new PersistOptions instance.
@@ -66,6 +73,87 @@ func (o *PersistOptions) SetScheduleConfig(cfg *ScheduleConfig) {
 	o.schedule.Store(cfg)
 }
 
+// UpdateScheduleConfig applies the JSON encoded changes of the scheduling
+// configurations on a copy of the current config, the fields not in the data
+// are kept unchanged and the unknown fields are rejected. The updated config is
+// validated before it is applied and persisted, the current config is kept if
+// any of the steps fails. The previous and the applied configs are returned,
+// so the change can be rolled back by RollbackScheduleConfig.
+func (o *PersistOptions) UpdateScheduleConfig(storage storage.Storage, data []byte) (*ScheduleConfig, *ScheduleConfig, error) {
+	o.updateMu.Lock()
+	defer o.updateMu.Unlock()
+
+	old := o.GetScheduleConfig()
+	cfg := old.Clone()
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(cfg); err != nil {
+		return nil, nil, fmt.Errorf("invalid schedule config: %w", err)
+	}
+	if err := cfg.validateUpdate(); err != nil {
+		return nil, nil, fmt.Errorf("invalid schedule config: %w", err)
+	}
+
+	o.SetScheduleConfig(cfg)
+	if err := o.Persist(storage); err != nil {
+		o.SetScheduleConfig(old)
+		return nil, nil, err
+	}
+	o.logger.Info("schedule config updated",
+		zap.ByteString("changes", data))
+	return old, cfg, nil
+}
+
+// RollbackScheduleConfig restores the previous scheduling configurations if the
+// current config is still the applied one returned by UpdateScheduleConfig.
+// False is returned if the config has been changed again since then.
+func (o *PersistOptions) RollbackScheduleConfig(storage storage.Storage, applied, previous *ScheduleConfig) (bool, error) {
+	o.updateMu.Lock()
+	defer o.updateMu.Unlock()
+
+	if o.GetScheduleConfig() != applied {
+		return false, nil
+	}
+	o.SetScheduleConfig(previous)
+	if err := o.Persist(storage); err != nil {
+		return true, err
+	}
+	return true, nil
+}
+
+// ApplyScheduleTimeWindow applies the schedule time window contains the time,
+// the limits of the window override the ones of the schedule config until
+// another window is applied. The applied window is returned, true is returned
+// if the window is changed.
+func (o *PersistOptions) ApplyScheduleTimeWindow(now time.Time) (*ScheduleTimeWindow, bool) {
+	w := o.GetScheduleConfig().GetTimeWindow(now)
+	if w == o.GetScheduleTimeWindow() {
+		return w, false
+	}
+	o.timeWindow.Store(w)
+	return w, true
+}
+
+// GetScheduleTimeWindow returns the applied schedule time window, nil is
+// returned if no window is applied.
+func (o *PersistOptions) GetScheduleTimeWindow() *ScheduleTimeWindow {
+	if v := o.timeWindow.Load(); v != nil {
+		return v.(*ScheduleTimeWindow)
+	}
+	return nil
+}
+
+// getTimeWindowUintOr returns the limit of the applied time window, or the
+// default value if no window is applied or the limit of the window is zero.
+func (o *PersistOptions) getTimeWindowUintOr(limit func(*ScheduleTimeWindow) uint64, defaultValue uint64) uint64 {
+	if w := o.GetScheduleTimeWindow(); w != nil {
+		if v := limit(w); v > 0 {
+			return v
+		}
+	}
+	return defaultValue
+}
+
 // GetReplicationConfig returns replication configurations.
 func (o *PersistOptions) GetReplicationConfig() *ReplicationConfig {
 	return o.replication.Load().(*ReplicationConfig)
@@ -200,6 +288,22 @@ func (o *PersistOptions) GetMaxMergeShardKeys() uint64 {
 	return o.getTTLUintOr(maxMergeShardKeysKey, o.GetScheduleConfig().MaxMergeShardKeys)
 }
 
+// GetEmptyShardMergeWindow returns the duration a shard must keep empty to be
+// merged as an empty shard, 0 means the empty shard merge is disabled.
+func (o *PersistOptions) GetEmptyShardMergeWindow() time.Duration {
+	return o.GetScheduleConfig().EmptyShardMergeWindow.Duration
+}
+
+// GetEmptyShardMaxSize returns the max size of the empty shard.
+func (o *PersistOptions) GetEmptyShardMaxSize() uint64 {
+	return o.GetScheduleConfig().EmptyShardMaxSize
+}
+
+// GetEmptyShardMaxKeys returns the max number of keys of the empty shard.
+func (o *PersistOptions) GetEmptyShardMaxKeys() uint64 {
+	return o.GetScheduleConfig().EmptyShardMaxKeys
+}
+
 // GetSplitMergeInterval returns the interval between finishing split and starting to merge.
 func (o *PersistOptions) GetSplitMergeInterval() time.Duration {
 	return o.GetScheduleConfig().SplitMergeInterval.Duration
@@ -278,29 +382,73 @@ func (o *PersistOptions) GetMaxStoreDownTime() time.Duration {
 	return o.GetScheduleConfig().MaxStoreDownTime.Duration
 }
 
+// GetDuplicateShardDestroyDelay returns the delay of destroying the duplicate
+// shard.
+func (o *PersistOptions) GetDuplicateShardDestroyDelay() time.Duration {
+	return o.GetScheduleConfig().DuplicateShardDestroyDelay.Duration
+}
+
+// GetDestroyingStuckTimeout returns the duration after which a destroying shard
+// without progress is considered stuck.
+func (o *PersistOptions) GetDestroyingStuckTimeout() time.Duration {
+	return o.GetScheduleConfig().DestroyingStuckTimeout.Duration
+}
+
 // GetLeaderScheduleLimit returns the limit for leader schedule.
 func (o *PersistOptions) GetLeaderScheduleLimit() uint64 {
-	return o.getTTLUintOr(leaderScheduleLimitKey, o.GetScheduleConfig().LeaderScheduleLimit)
+	return o.getTTLUintOr(leaderScheduleLimitKey, o.getTimeWindowUintOr(func(w *ScheduleTimeWindow) uint64 {
+		return w.LeaderScheduleLimit
+	}, o.GetScheduleConfig().LeaderScheduleLimit))
 }
 
 // GetShardScheduleLimit returns the limit for resource schedule.
 func (o *PersistOptions) GetShardScheduleLimit() uint64 {
-	return o.getTTLUintOr(resourceScheduleLimitKey, o.GetScheduleConfig().ShardScheduleLimit)
+	return o.getTTLUintOr(resourceScheduleLimitKey, o.getTimeWindowUintOr(func(w *ScheduleTimeWindow) uint64 {
+		return w.ShardScheduleLimit
+	}, o.GetScheduleConfig().ShardScheduleLimit))
 }
 
 // GetReplicaScheduleLimit returns the limit for replica schedule.
 func (o *PersistOptions) GetReplicaScheduleLimit() uint64 {
-	return o.getTTLUintOr(replicaRescheduleLimitKey, o.GetScheduleConfig().ReplicaScheduleLimit)
+	return o.getTTLUintOr(replicaRescheduleLimitKey, o.getTimeWindowUintOr(func(w *ScheduleTimeWindow) uint64 {
+		return w.ReplicaScheduleLimit
+	}, o.GetScheduleConfig().ReplicaScheduleLimit))
 }
 
 // GetMergeScheduleLimit returns the limit for merge schedule.
 func (o *PersistOptions) GetMergeScheduleLimit() uint64 {
-	return o.getTTLUintOr(mergeScheduleLimitKey, o.GetScheduleConfig().MergeScheduleLimit)
+	return o.getTTLUintOr(mergeScheduleLimitKey, o.getTimeWindowUintOr(func(w *ScheduleTimeWindow) uint64 {
+		return w.MergeScheduleLimit
+	}, o.GetScheduleConfig().MergeScheduleLimit))
 }
 
 // GetHotShardScheduleLimit returns the limit for hot resource schedule.
 func (o *PersistOptions) GetHotShardScheduleLimit() uint64 {
-	return o.getTTLUintOr(hotShardScheduleLimitKey, o.GetScheduleConfig().HotShardScheduleLimit)
+	return o.getTTLUintOr(hotShardScheduleLimitKey, o.getTimeWindowUintOr(func(w *ScheduleTimeWindow) uint64 {
+		return w.HotShardScheduleLimit
+	}, o.GetScheduleConfig().HotShardScheduleLimit))
+}
+
+// GetGroupScheduleLimit returns the schedule limits of the shard group, the zero
+// limits of the group are replaced by the global limits. False is returned if the
+// group has no limits, it's only limited by the global limits.
+func (o *PersistOptions) GetGroupScheduleLimit(group uint64) (GroupScheduleLimit, bool) {
+	l := o.GetScheduleConfig().GetGroupLimit(group)
+	if l == nil {
+		return GroupScheduleLimit{}, false
+	}
+
+	limit := *l
+	if limit.LeaderScheduleLimit == 0 {
+		limit.LeaderScheduleLimit = o.GetLeaderScheduleLimit()
+	}
+	if limit.ShardScheduleLimit == 0 {
+		limit.ShardScheduleLimit = o.GetShardScheduleLimit()
+	}
+	if limit.HotShardScheduleLimit == 0 {
+		limit.HotShardScheduleLimit = o.GetHotShardScheduleLimit()
+	}
+	return limit, true
 }
 
 // GetStoreLimit returns the limit of a container.
@@ -452,6 +600,19 @@ func (o *PersistOptions) SetEnableJointConsensus(enableJointConsensus bool) {
 	o.SetScheduleConfig(v)
 }
 
+// IsLearnerCatchUpEnabled returns if catching up the target learner before the
+// membership change is enabled when moving a replica.
+func (o *PersistOptions) IsLearnerCatchUpEnabled() bool {
+	return o.GetScheduleConfig().EnableLearnerCatchUp
+}
+
+// SetEnableLearnerCatchUp sets whether to enable learner catch up. It's only used to test.
+func (o *PersistOptions) SetEnableLearnerCatchUp(enableLearnerCatchUp bool) {
+	v := o.GetScheduleConfig().Clone()
+	v.EnableLearnerCatchUp = enableLearnerCatchUp
+	o.SetScheduleConfig(v)
+}
+
 // GetHotShardCacheHitsThreshold is a threshold to decide if a resource is hot.
 func (o *PersistOptions) GetHotShardCacheHitsThreshold() int {
 	return int(o.GetScheduleConfig().HotShardCacheHitsThreshold)
@@ -2,6 +2,7 @@ package config
 
 import (
 	"testing"
+	"time"
 
 	"github.com/matrixorigin/matrixcube/components/prophet/storage"
 	"github.com/matrixorigin/matrixcube/pb/metapb"
@@ -17,3 +18,117 @@ func TestIssue85(t *testing.T) {
 	s := storage.NewTestStorage()
 	assert.NoError(t, pc.Persist(s))
 }
+
+func TestUpdateScheduleConfig(t *testing.T) {
+	for _, s := range DefaultSchedulers {
+		RegisterScheduler(s.Type)
+	}
+	cfg := NewConfig()
+	assert.NoError(t, cfg.Adjust(nil, false))
+	pc := NewPersistOptions(cfg, nil)
+	s := storage.NewTestStorage()
+
+	old := pc.GetScheduleConfig()
+	previous, applied, err := pc.UpdateScheduleConfig(s, []byte(`{"max-merge-resource-size": 100, "leader-schedule-limit": 8}`))
+	assert.NoError(t, err)
+	assert.True(t, previous == old)
+	assert.True(t, applied == pc.GetScheduleConfig())
+	assert.Equal(t, uint64(100), pc.GetMaxMergeShardSize())
+	assert.Equal(t, uint64(8), pc.GetLeaderScheduleLimit())
+	assert.Equal(t, old.MaxMergeShardKeys, pc.GetMaxMergeShardKeys())
+
+	// unknown fields and invalid values are rejected
+	for _, changes := range []string{
+		`{"max-merge-size": 100}`,
+		`{"max-merge-resource-size": -1}`,
+		`{"low-space-ratio": 2}`,
+		`{"container-limit-mode": "none"}`,
+		`{"max-container-down-time": "0s"}`,
+	} {
+		_, _, err := pc.UpdateScheduleConfig(s, []byte(changes))
+		assert.Error(t, err, changes)
+		assert.True(t, applied == pc.GetScheduleConfig(), changes)
+	}
+
+	// not rolled back if changed again
+	_, applied2, err := pc.UpdateScheduleConfig(s, []byte(`{"max-merge-resource-size": 200}`))
+	assert.NoError(t, err)
+	ok, err := pc.RollbackScheduleConfig(s, applied, previous)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	assert.Equal(t, uint64(200), pc.GetMaxMergeShardSize())
+
+	ok, err = pc.RollbackScheduleConfig(s, applied2, applied)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, uint64(100), pc.GetMaxMergeShardSize())
+	persisted := &Config{}
+	_, err = s.LoadConfig(persisted)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(100), persisted.Schedule.MaxMergeShardSize)
+}
+
+func TestScheduleTimeWindow(t *testing.T) {
+	at := func(v string) time.Time {
+		tm, err := time.Parse("15:04", v)
+		assert.NoError(t, err)
+		return tm
+	}
+	w := ScheduleTimeWindow{Start: "02:00", End: "06:00"}
+	assert.True(t, w.Contains(at("02:00")))
+	assert.True(t, w.Contains(at("05:59")))
+	assert.False(t, w.Contains(at("06:00")))
+	assert.False(t, w.Contains(at("01:59")))
+	// crosses midnight
+	w = ScheduleTimeWindow{Start: "22:00", End: "02:00"}
+	assert.True(t, w.Contains(at("23:00")))
+	assert.True(t, w.Contains(at("01:00")))
+	assert.False(t, w.Contains(at("12:00")))
+
+	for _, s := range DefaultSchedulers {
+		RegisterScheduler(s.Type)
+	}
+	cfg := NewConfig()
+	assert.NoError(t, cfg.Adjust(nil, false))
+	pc := NewPersistOptions(cfg, nil)
+	s := storage.NewTestStorage()
+	_, _, err := pc.UpdateScheduleConfig(s, []byte(`{"time-windows": [{"start": "2:00", "end": "25:00"}]}`))
+	assert.Error(t, err)
+	_, _, err = pc.UpdateScheduleConfig(s, []byte(`{"leader-schedule-limit": 4, "replica-schedule-limit": 8, "time-windows": [{"start": "02:00", "end": "06:00", "leader-schedule-limit": 16}]}`))
+	assert.NoError(t, err)
+
+	w2, changed := pc.ApplyScheduleTimeWindow(at("03:00"))
+	assert.True(t, changed)
+	assert.Equal(t, "02:00", w2.Start)
+	assert.Equal(t, uint64(16), pc.GetLeaderScheduleLimit())
+	assert.Equal(t, uint64(8), pc.GetReplicaScheduleLimit(), "zero limit keeps the schedule config")
+	_, changed = pc.ApplyScheduleTimeWindow(at("04:00"))
+	assert.False(t, changed)
+
+	w2, changed = pc.ApplyScheduleTimeWindow(at("07:00"))
+	assert.True(t, changed)
+	assert.Nil(t, w2)
+	assert.Equal(t, uint64(4), pc.GetLeaderScheduleLimit())
+}
+
+func TestGroupScheduleLimit(t *testing.T) {
+	for _, s := range DefaultSchedulers {
+		RegisterScheduler(s.Type)
+	}
+	cfg := NewConfig()
+	assert.NoError(t, cfg.Adjust(nil, false))
+	pc := NewPersistOptions(cfg, nil)
+	s := storage.NewTestStorage()
+	_, _, err := pc.UpdateScheduleConfig(s, []byte(`{"group-limits": [{"group": 1}, {"group": 1}]}`))
+	assert.Error(t, err)
+	_, _, err = pc.UpdateScheduleConfig(s, []byte(`{"leader-schedule-limit": 4, "resource-schedule-limit": 8, "group-limits": [{"group": 1, "leader-schedule-limit": 1}]}`))
+	assert.NoError(t, err)
+
+	limit, ok := pc.GetGroupScheduleLimit(1)
+	assert.True(t, ok)
+	assert.Equal(t, uint64(1), limit.LeaderScheduleLimit)
+	assert.Equal(t, uint64(8), limit.ShardScheduleLimit, "zero limit keeps the schedule config")
+	assert.Equal(t, pc.GetHotShardScheduleLimit(), limit.HotShardScheduleLimit)
+	_, ok = pc.GetGroupScheduleLimit(2)
+	assert.False(t, ok)
+}
@@ -37,9 +37,12 @@ type BasicCluster struct {
 	Shards              *ShardsContainer
 	DestroyedShards     *roaring64.Bitmap
 	WaitingCreateShards map[uint64]metapb.Shard
+	BulkCreateShards    map[uint64]struct{}
 	DestroyingStatuses  map[uint64]*metapb.DestroyingStatus
 	ScheduleGroupRules  ScheduleGroupRuleCache
 	ScheduleGroupKeys   map[string]struct{}
+	PreferredLeaders    map[uint64]uint64
+	RangeRelocations    map[uint64]metapb.RangeRelocation
 }
 
 // NewBasicCluster creates a BasicCluster.
@@ -49,6 +52,8 @@ func NewBasicCluster(logger *zap.Logger) *BasicCluster {
 		DestroyingStatuses: make(map[uint64]*metapb.DestroyingStatus),
 		ScheduleGroupKeys:  make(map[string]struct{}),
 		ScheduleGroupRules: NewScheduleGroupRuleCache(),
+		PreferredLeaders:   make(map[uint64]uint64),
+		RangeRelocations:   make(map[uint64]metapb.RangeRelocation),
 	}
 	bc.Reset()
 	return bc
@@ -63,6 +68,7 @@ func (bc *BasicCluster) Reset() {
 	bc.Shards = NewCachedShards()
 	bc.DestroyedShards = roaring64.NewBitmap()
 	bc.WaitingCreateShards = make(map[uint64]metapb.Shard)
+	bc.BulkCreateShards = make(map[uint64]struct{})
 	bc.ScheduleGroupRules.Clear()
 }
 
@@ -88,6 +94,25 @@ func (bc *BasicCluster) AddWaitingCreateShards(resources ...metapb.Shard) {
 	}
 }
 
+// AddBulkWaitingCreateShards add waiting create shards created in bulk
+func (bc *BasicCluster) AddBulkWaitingCreateShards(resources ...metapb.Shard) {
+	bc.Lock()
+	defer bc.Unlock()
+	for _, res := range resources {
+		bc.WaitingCreateShards[res.GetID()] = res
+		bc.BulkCreateShards[res.GetID()] = struct{}{}
+	}
+}
+
+// IsBulkCreateShard returns true means the waiting create resource is created in bulk
+func (bc *BasicCluster) IsBulkCreateShard(id uint64) bool {
+	bc.RLock()
+	defer bc.RUnlock()
+
+	_, ok := bc.BulkCreateShards[id]
+	return ok
+}
+
 // ForeachWaitingCreateShards do func for every waiting create shards
 func (bc *BasicCluster) ForeachWaitingCreateShards(fn func(res metapb.Shard)) {
 	bc.RLock()
@@ -420,7 +445,7 @@ func (bc *BasicCluster) PreCheckPutShard(res *CachedShard) (*CachedShard, error)
 		!bytes.Equal(origin.GetStartKey(), res.GetStartKey()) ||
 		!bytes.Equal(origin.GetEndKey(), res.GetEndKey()) {
 		for _, item := range bc.Shards.GetOverlaps(res) {
-			if res.Meta.GetEpoch().Generation < item.Meta.GetEpoch().Generation {
+			if IsOverlappedByNewer(res, item) {
 				bc.RUnlock()
 				return nil, errShardIsStale(res.Meta, item.Meta)
 			}
@@ -443,6 +468,18 @@ func (bc *BasicCluster) PreCheckPutShard(res *CachedShard) (*CachedShard, error)
 	return origin, nil
 }
 
+// IsOverlappedByNewer returns true if the shard is overlapped by the shard with
+// a newer epoch. The overlapped shards with the same epoch are resolved by the
+// ids, the shard with the larger id is created later and considered newer, so
+// the heartbeats in any order keep the same shard.
+func IsOverlappedByNewer(res, overlap *CachedShard) bool {
+	gen, overlapGen := res.Meta.GetEpoch().Generation, overlap.Meta.GetEpoch().Generation
+	if gen != overlapGen {
+		return gen < overlapGen
+	}
+	return res.Meta.GetID() < overlap.Meta.GetID()
+}
+
 // PutShard put a resource, returns overlap shards
 func (bc *BasicCluster) PutShard(res *CachedShard) []*CachedShard {
 	bc.Lock()
@@ -450,6 +487,7 @@ func (bc *BasicCluster) PutShard(res *CachedShard) []*CachedShard {
 
 	if _, ok := bc.WaitingCreateShards[res.Meta.GetID()]; ok {
 		delete(bc.WaitingCreateShards, res.Meta.GetID())
+		delete(bc.BulkCreateShards, res.Meta.GetID())
 		if res.Meta.GetState() == metapb.ShardState_Creating {
 			res.Meta.SetState(metapb.ShardState_Running)
 		}
@@ -529,6 +567,21 @@ func (bc *BasicCluster) GetDestroyingStatus(id uint64) *metapb.DestroyingStatus
 	return cloneValue
 }
 
+// GetDestroyingStatusIDs returns the ids of the shards whose DestroyingStatus is
+// in the Destroying state
+func (bc *BasicCluster) GetDestroyingStatusIDs() []uint64 {
+	bc.RLock()
+	defer bc.RUnlock()
+
+	var ids []uint64
+	for id, status := range bc.DestroyingStatuses {
+		if status.State == metapb.ShardState_Destroying {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
 // UpdateDestroyingStatus update DestroyingStatus
 func (bc *BasicCluster) UpdateDestroyingStatus(id uint64, status *metapb.DestroyingStatus) {
 	bc.Lock()
@@ -537,6 +590,95 @@ func (bc *BasicCluster) UpdateDestroyingStatus(id uint64, status *metapb.Destroy
 	bc.DestroyingStatuses[id] = status
 }
 
+// GetShardPreferredLeader returns the preferred leader store of the shard, 0 is
+// returned if not set
+func (bc *BasicCluster) GetShardPreferredLeader(id uint64) uint64 {
+	bc.RLock()
+	defer bc.RUnlock()
+
+	return bc.PreferredLeaders[id]
+}
+
+// SetShardPreferredLeader sets the preferred leader store of the shard, 0
+// store clears the preferred leader
+func (bc *BasicCluster) SetShardPreferredLeader(id, storeID uint64) {
+	bc.Lock()
+	defer bc.Unlock()
+
+	if storeID == 0 {
+		delete(bc.PreferredLeaders, id)
+		return
+	}
+	bc.PreferredLeaders[id] = storeID
+}
+
+// PutRangeRelocation adds or updates the range relocation
+func (bc *BasicCluster) PutRangeRelocation(r metapb.RangeRelocation) {
+	bc.Lock()
+	defer bc.Unlock()
+
+	bc.RangeRelocations[r.ID] = r
+}
+
+// RemoveRangeRelocation removes the range relocation
+func (bc *BasicCluster) RemoveRangeRelocation(id uint64) {
+	bc.Lock()
+	defer bc.Unlock()
+
+	delete(bc.RangeRelocations, id)
+}
+
+// GetRangeRelocation returns the range relocation, false is returned if not
+// found
+func (bc *BasicCluster) GetRangeRelocation(id uint64) (metapb.RangeRelocation, bool) {
+	bc.RLock()
+	defer bc.RUnlock()
+
+	r, ok := bc.RangeRelocations[id]
+	return r, ok
+}
+
+// GetShardRangeRelocation returns the range relocation of the shard, the one
+// with the smallest ID is returned if the shard overlaps multiple range
+// relocations, false is returned if the shard is not relocated.
+func (bc *BasicCluster) GetShardRangeRelocation(res *CachedShard) (metapb.RangeRelocation, bool) {
+	bc.RLock()
+	defer bc.RUnlock()
+
+	var found metapb.RangeRelocation
+	for _, r := range bc.RangeRelocations {
+		if (found.ID == 0 || r.ID < found.ID) && IsRangeRelocated(r, res.Meta) {
+			found = r
+		}
+	}
+	return found, found.ID > 0
+}
+
+// IsRangeRelocated returns true if the shard is in the group of the range
+// relocation and overlaps its key range.
+func IsRangeRelocated(r metapb.RangeRelocation, shard metapb.Shard) bool {
+	if shard.GetGroup() != r.Group {
+		return false
+	}
+
+	start, end := shard.GetRange()
+	if len(r.End) > 0 && bytes.Compare(start, r.End) >= 0 {
+		return false
+	}
+	return len(end) == 0 || bytes.Compare(end, r.Start) > 0
+}
+
+// IsRangeRelocationTarget returns true if the store is a target store of the
+// range relocation.
+func IsRangeRelocationTarget(r metapb.RangeRelocation, storeID uint64) bool {
+	for _, id := range r.Stores {
+		if id == storeID {
+			return true
+		}
+	}
+	return false
+}
+
 func (bc *BasicCluster) AddScheduleGroupRule(rule metapb.ScheduleGroupRule) error {
 	return bc.ScheduleGroupRules.AddRule(rule)
 }
@@ -561,6 +703,8 @@ type ShardSetInformer interface {
 	GetAdjacentShards(res *CachedShard) (*CachedShard, *CachedShard)
 	ScanShards(group uint64, startKey, endKey []byte, limit int) []*CachedShard
 	GetShardByKey(group uint64, resKey []byte) *CachedShard
+	GetShardPreferredLeader(id uint64) uint64
+	GetShardRangeRelocation(res *CachedShard) (metapb.RangeRelocation, bool)
 }
 
 // StoreSetInformer provides access to a shared informer of stores.
@@ -253,6 +253,17 @@ func (r *CachedShard) GetPendingLearner(peerID uint64) (metapb.Replica, bool) {
 	return metapb.Replica{}, false
 }
 
+// IsRecoveringPeer returns true if the peer is recovering from the snapshot
+// sent by the leader.
+func (r *CachedShard) IsRecoveringPeer(peerID uint64) bool {
+	for _, id := range r.stats.RecoveringReplicas {
+		if id == peerID {
+			return true
+		}
+	}
+	return false
+}
+
 // GetStorePeer returns the peer in specified store.
 func (r *CachedShard) GetStorePeer(storeID uint64) (metapb.Replica, bool) {
 	for _, peer := range r.Meta.GetReplicas() {
@@ -358,6 +369,47 @@ func (r *CachedShard) GetInterval() *metapb.TimeInterval {
 	return r.stats.Interval
 }
 
+// GetCommitIndex returns the committed index of the raft log reported by the leader.
+func (r *CachedShard) GetCommitIndex() uint64 {
+	return r.stats.CommitIndex
+}
+
+// GetAppliedIndex returns the applied index of the state machine reported by the
+// leader.
+func (r *CachedShard) GetAppliedIndex() uint64 {
+	return r.stats.AppliedIndex
+}
+
+// GetTruncatedIndex returns the index of the last raft log entry truncated by the
+// log compaction reported by the leader.
+func (r *CachedShard) GetTruncatedIndex() uint64 {
+	return r.stats.TruncatedIndex
+}
+
+// GetApplyLag returns the number of the committed raft log entries not applied by
+// the leader, which keeps growing if the apply is stuck.
+func (r *CachedShard) GetApplyLag() uint64 {
+	if r.stats.CommitIndex <= r.stats.AppliedIndex {
+		return 0
+	}
+	return r.stats.CommitIndex - r.stats.AppliedIndex
+}
+
+// GetReplicationLag returns the number of the committed raft log entries not
+// replicated to the replica known by the leader, false if the replica is not
+// tracked by the leader.
+func (r *CachedShard) GetReplicationLag(replicaID uint64) (uint64, bool) {
+	for _, m := range r.stats.ReplicaMatchIndexes {
+		if m.ReplicaID == replicaID {
+			if r.stats.CommitIndex <= m.MatchIndex {
+				return 0, true
+			}
+			return r.stats.CommitIndex - m.MatchIndex, true
+		}
+	}
+	return 0, false
+}
+
 // GetDownPeers returns the down peers of the shard.
 func (r *CachedShard) GetDownPeers() []metapb.ReplicaStats {
 	return r.downReplicas
@@ -50,6 +50,13 @@ func WithPendingPeers(pendingReplicas []metapb.Replica) ShardCreateOption {
 	}
 }
 
+// WithRecoveringPeers sets the peers recovering from the snapshots for the shard.
+func WithRecoveringPeers(peerIDs []uint64) ShardCreateOption {
+	return func(res *CachedShard) {
+		res.stats.RecoveringReplicas = append(peerIDs[:0:0], peerIDs...)
+	}
+}
+
 // WithLearners sets the learners for the shard.
 func WithLearners(learners []metapb.Replica) ShardCreateOption {
 	return func(res *CachedShard) {
@@ -22,6 +22,7 @@ import (
 	"testing"
 
 	"github.com/matrixorigin/matrixcube/pb/metapb"
+	"github.com/matrixorigin/matrixcube/pb/rpcpb"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -354,3 +355,39 @@ func checkShards(t *testing.T, resources *ShardsContainer, msg string) {
 		assert.Equal(t, int(pendingPeerMap[key]), value.length(), msg)
 	}
 }
+
+func TestShardRaftWatermarks(t *testing.T) {
+	res := ShardFromHeartbeat(rpcpb.ShardHeartbeatReq{
+		Term: 2,
+		Stats: metapb.ShardStats{
+			Term:           2,
+			CommitIndex:    100,
+			AppliedIndex:   90,
+			TruncatedIndex: 50,
+			ReplicaMatchIndexes: []metapb.ReplicaMatchIndex{
+				{ReplicaID: 1, MatchIndex: 100},
+				{ReplicaID: 2, MatchIndex: 80},
+			},
+		},
+	}, metapb.Shard{ID: 1})
+	assert.Equal(t, uint64(100), res.GetCommitIndex())
+	assert.Equal(t, uint64(90), res.GetAppliedIndex())
+	assert.Equal(t, uint64(50), res.GetTruncatedIndex())
+	assert.Equal(t, uint64(10), res.GetApplyLag())
+	assert.Equal(t, uint64(2), res.GetStat().Term)
+	assert.Equal(t, uint64(10), res.Clone().GetApplyLag())
+	lag, ok := res.GetReplicationLag(1)
+	assert.True(t, ok)
+	assert.Equal(t, uint64(0), lag)
+	lag, ok = res.GetReplicationLag(2)
+	assert.True(t, ok)
+	assert.Equal(t, uint64(20), lag)
+	_, ok = res.GetReplicationLag(3)
+	assert.False(t, ok)
+
+	// the applied index is ahead of the committed index known by the leader
+	res = ShardFromHeartbeat(rpcpb.ShardHeartbeatReq{
+		Stats: metapb.ShardStats{CommitIndex: 100, AppliedIndex: 101},
+	}, metapb.Shard{ID: 1})
+	assert.Equal(t, uint64(0), res.GetApplyLag())
+}
@@ -166,6 +166,12 @@ func (cr *CachedStore) IsPhysicallyDestroyed() bool {
 	return cr.Meta.GetDestroyed()
 }
 
+// IsMaintenance checks if the store is in the maintenance mode, no new replica
+// or leader is scheduled to the store.
+func (cr *CachedStore) IsMaintenance() bool {
+	return cr.Meta.GetMaintenance()
+}
+
 // DownTime returns the time elapsed since last heartbeat.
 func (cr *CachedStore) DownTime() time.Duration {
 	return time.Since(cr.GetLastHeartbeatTS())
@@ -81,6 +81,14 @@ func TombstoneStore() StoreCreateOption {
 	}
 }
 
+// SetStoreMaintenance sets or clears the maintenance mode of the cachedStore.
+func SetStoreMaintenance(maintenance bool) StoreCreateOption {
+	return func(cachedStore *CachedStore) {
+		cachedStore.Meta.SetMaintenance(maintenance)
+		cachedStore.storeStats.setMaintenance(maintenance)
+	}
+}
+
 // PauseLeaderTransfer prevents the cachedStore from been selected as source or
 // target cachedStore of TransferLeader.
 func PauseLeaderTransfer() StoreCreateOption {
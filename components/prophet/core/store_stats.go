@@ -60,6 +60,22 @@ func (ss *storeStats) updateRawStats(rawStats *metapb.StoreStats) {
 	ss.avgMaxAvailableDeviation.Add(ss.maxAvailableDeviation.Get())
 }
 
+// setMaintenance sets the maintenance mode in the statistics information, the
+// raw stats are shared by the clones of the store, so they are copied on write.
+func (ss *storeStats) setMaintenance(maintenance bool) {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	if ss.rawStats.GetMaintenance() == maintenance {
+		return
+	}
+	var stats metapb.StoreStats
+	if ss.rawStats != nil {
+		stats = *ss.rawStats
+	}
+	stats.Maintenance = maintenance
+	ss.rawStats = &stats
+}
+
 // GetStoreStats returns the statistics information of the store.
 func (ss *storeStats) GetStoreStats() *metapb.StoreStats {
 	ss.mu.RLock()
@@ -29,16 +29,31 @@ var (
 	ShardStatsEvent uint32 = 1 << 4
 	// StoreStatsEvent store stats
 	StoreStatsEvent uint32 = 1 << 5
+	// CreateShardsEvent consolidated shards creation event
+	CreateShardsEvent uint32 = 1 << 6
+	// DuplicateShardEvent a shard overlapped by another shard with a newer epoch
+	// is found
+	DuplicateShardEvent uint32 = 1 << 7
+	// DestroyingStuckEvent a shard in the Destroying state has no progress for the
+	// destroying-stuck-timeout
+	DestroyingStuckEvent uint32 = 1 << 8
+	// ShardLabelsUpdatedEvent the labels of all the shards of a shard labels job are
+	// updated
+	ShardLabelsUpdatedEvent uint32 = 1 << 9
 	// AllEvent all event
 	AllEvent uint32 = 0xffffffff
 
 	names = map[uint32]string{
-		InitEvent:       "init",
-		ShardEvent:      "shard",
-		ShardStatsEvent: "shard-stats",
-		StoreEvent:      "store",
-		StoreStatsEvent: "store-stats",
-		AllEvent:        "all",
+		InitEvent:               "init",
+		ShardEvent:              "shard",
+		ShardStatsEvent:         "shard-stats",
+		StoreEvent:              "store",
+		StoreStatsEvent:         "store-stats",
+		CreateShardsEvent:       "create-shards",
+		DuplicateShardEvent:     "duplicate-shard",
+		DestroyingStuckEvent:    "destroying-stuck",
+		ShardLabelsUpdatedEvent: "shard-labels-updated",
+		AllEvent:                "all",
 	}
 )
 
@@ -103,6 +118,84 @@ func NewShardEvent(target metapb.Shard, leaderID uint64, removed bool, create bo
 	}
 }
 
+// NewCreateShardsEvent create a consolidated shards creation event, the shards
+// are carried in the InitEvent field to avoid one event per shard.
+func NewCreateShardsEvent(shards []metapb.Shard) (rpcpb.EventNotify, error) {
+	data := &rpcpb.InitEventData{}
+	for _, v := range shards {
+		value, err := v.Marshal()
+		if err != nil {
+			return rpcpb.EventNotify{}, err
+		}
+
+		data.Shards = append(data.Shards, value)
+		data.Leaders = append(data.Leaders, 0)
+	}
+
+	return rpcpb.EventNotify{
+		Type:      CreateShardsEvent,
+		InitEvent: data,
+	}, nil
+}
+
+// NewDuplicateShardEvent create a duplicate shard event, the duplicate shard
+// and the shard overlapping it with a newer epoch are carried in the InitEvent
+// field in order.
+func NewDuplicateShardEvent(duplicate, overlap metapb.Shard) (rpcpb.EventNotify, error) {
+	data := &rpcpb.InitEventData{}
+	for _, v := range []metapb.Shard{duplicate, overlap} {
+		value, err := v.Marshal()
+		if err != nil {
+			return rpcpb.EventNotify{}, err
+		}
+
+		data.Shards = append(data.Shards, value)
+		data.Leaders = append(data.Leaders, 0)
+	}
+
+	return rpcpb.EventNotify{
+		Type:      DuplicateShardEvent,
+		InitEvent: data,
+	}, nil
+}
+
+// NewDestroyingStuckEvent create a destroying stuck event, the stuck shard is
+// carried in the InitEvent field.
+func NewDestroyingStuckEvent(shard metapb.Shard) (rpcpb.EventNotify, error) {
+	value, err := shard.Marshal()
+	if err != nil {
+		return rpcpb.EventNotify{}, err
+	}
+
+	return rpcpb.EventNotify{
+		Type: DestroyingStuckEvent,
+		InitEvent: &rpcpb.InitEventData{
+			Shards:  [][]byte{value},
+			Leaders: []uint64{0},
+		},
+	}, nil
+}
+
+// NewShardLabelsUpdatedEvent create a shard labels updated event, all the updated
+// shards of the job are carried in the InitEvent field, so the watchers are notified
+// once for the whole job.
+func NewShardLabelsUpdatedEvent(shards []metapb.Shard) (rpcpb.EventNotify, error) {
+	data := &rpcpb.InitEventData{}
+	for _, shard := range shards {
+		value, err := shard.Marshal()
+		if err != nil {
+			return rpcpb.EventNotify{}, err
+		}
+		data.Shards = append(data.Shards, value)
+		data.Leaders = append(data.Leaders, 0)
+	}
+
+	return rpcpb.EventNotify{
+		Type:      ShardLabelsUpdatedEvent,
+		InitEvent: data,
+	}, nil
+}
+
 // NewShardStatsEvent create shard stats event
 func NewShardStatsEvent(stats *metapb.ShardStats) rpcpb.EventNotify {
 	return rpcpb.EventNotify{
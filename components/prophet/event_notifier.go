@@ -22,6 +22,7 @@ import (
 	"github.com/fagongzi/goetty"
 	"github.com/matrixorigin/matrixcube/components/log"
 	"github.com/matrixorigin/matrixcube/components/prophet/cluster"
+	"github.com/matrixorigin/matrixcube/components/prophet/config"
 	"github.com/matrixorigin/matrixcube/components/prophet/event"
 	"github.com/matrixorigin/matrixcube/pb/rpcpb"
 	"github.com/matrixorigin/matrixcube/util/stop"
@@ -32,6 +33,12 @@ type watcherSession struct {
 	seq     uint64
 	flag    uint32
 	session goetty.IOSession
+	// queue the events to be sent by the worker of the watcher
+	queue chan rpcpb.EventNotify
+	// resyncC notifies the worker to send a snapshot instead of the events
+	// dropped from the overflowed queue
+	resyncC chan struct{}
+	stopC   chan struct{}
 }
 
 func (wt *watcherSession) notify(evt rpcpb.EventNotify) error {
@@ -48,17 +55,22 @@ func (wt *watcherSession) notify(evt rpcpb.EventNotify) error {
 type eventNotifier struct {
 	sync.Mutex
 
-	logger   *zap.Logger
-	watchers map[uint64]*watcherSession
-	cluster  *cluster.RaftCluster
-	stopper  *stop.Stopper
+	logger         *zap.Logger
+	watchers       map[uint64]*watcherSession
+	cluster        *cluster.RaftCluster
+	stopper        *stop.Stopper
+	queueSize      uint64
+	overflowPolicy string
 }
 
-func newWatcherNotifier(cluster *cluster.RaftCluster, logger *zap.Logger) *eventNotifier {
+func newWatcherNotifier(cluster *cluster.RaftCluster, queueSize uint64,
+	overflowPolicy string, logger *zap.Logger) *eventNotifier {
 	wn := &eventNotifier{
-		logger:   log.Adjust(logger).Named("watch-notify"),
-		cluster:  cluster,
-		watchers: make(map[uint64]*watcherSession),
+		logger:         log.Adjust(logger).Named("watch-notify"),
+		cluster:        cluster,
+		watchers:       make(map[uint64]*watcherSession),
+		queueSize:      queueSize,
+		overflowPolicy: overflowPolicy,
 	}
 	wn.stopper = stop.NewStopper("event-notifier", stop.WithLogger(wn.logger))
 	return wn
@@ -72,21 +84,7 @@ func (wn *eventNotifier) handleCreateWatcher(req *rpcpb.ProphetRequest, resp *rp
 		wn.cluster.RLock()
 		defer wn.cluster.RUnlock()
 		if event.MatchEvent(event.InitEvent, req.CreateWatcher.Flag) {
-			snap := event.Snapshot{
-				Leaders: make(map[uint64]uint64),
-			}
-			for _, c := range wn.cluster.GetStores() {
-				snap.Stores = append(snap.Stores, c.Meta)
-			}
-			for _, res := range wn.cluster.GetShards() {
-				snap.Shards = append(snap.Shards, res.Meta)
-				leader := res.GetLeader()
-				if leader != nil {
-					snap.Leaders[res.Meta.GetID()] = leader.ID
-				}
-			}
-
-			rsp, err := event.NewInitEvent(snap)
+			rsp, err := wn.newInitEventLocked()
 			if err != nil {
 				return err
 			}
@@ -101,6 +99,25 @@ func (wn *eventNotifier) handleCreateWatcher(req *rpcpb.ProphetRequest, resp *rp
 	return nil
 }
 
+// newInitEventLocked returns the init event with the snapshot of the stores and
+// the shards of the cluster, the cluster must be locked.
+func (wn *eventNotifier) newInitEventLocked() (*rpcpb.InitEventData, error) {
+	snap := event.Snapshot{
+		Leaders: make(map[uint64]uint64),
+	}
+	for _, c := range wn.cluster.GetStores() {
+		snap.Stores = append(snap.Stores, c.Meta)
+	}
+	for _, res := range wn.cluster.GetShards() {
+		snap.Shards = append(snap.Shards, res.Meta)
+		leader := res.GetLeader()
+		if leader != nil {
+			snap.Leaders[res.Meta.GetID()] = leader.ID
+		}
+	}
+	return event.NewInitEvent(snap)
+}
+
 func (wn *eventNotifier) addWatcher(flag uint32, session goetty.IOSession) error {
 	wn.Lock()
 	defer wn.Unlock()
@@ -109,28 +126,129 @@ func (wn *eventNotifier) addWatcher(flag uint32, session goetty.IOSession) error
 		return fmt.Errorf("watcher notifier stopped")
 	}
 
-	wn.watchers[session.ID()] = &watcherSession{
+	wt := &watcherSession{
 		flag:    flag,
 		session: session,
+		queue:   make(chan rpcpb.EventNotify, wn.queueSize),
+		resyncC: make(chan struct{}, 1),
+		stopC:   make(chan struct{}),
 	}
+	if err := wn.stopper.RunNamedTask(context.Background(),
+		fmt.Sprintf("event-watcher-%d", session.ID()),
+		func(ctx context.Context) {
+			wn.runWatcher(ctx, wt)
+		}); err != nil {
+		return err
+	}
+	wn.watchers[session.ID()] = wt
 	return nil
 }
 
+// runWatcher sends the queued events to the watcher until the watcher is
+// removed, each watcher has its own worker so a slow watcher does not delay the
+// others.
+func (wn *eventNotifier) runWatcher(ctx context.Context, wt *watcherSession) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-wt.stopC:
+			return
+		case <-wt.resyncC:
+			if err := wn.resync(wt); err != nil {
+				wn.removeWatcher(wt, err)
+				return
+			}
+		case evt := <-wt.queue:
+			watcherLagGauge.WithLabelValues(wt.session.RemoteAddr()).Set(float64(len(wt.queue)))
+			if err := wt.notify(evt); err != nil {
+				wn.removeWatcher(wt, err)
+				return
+			}
+		}
+	}
+}
+
+// resync sends the snapshot of the cluster to the watcher by the init event,
+// the watcher resets its state with the snapshot.
+func (wn *eventNotifier) resync(wt *watcherSession) error {
+	wn.cluster.RLock()
+	data, err := wn.newInitEventLocked()
+	wn.cluster.RUnlock()
+	if err != nil {
+		return err
+	}
+
+	wn.logger.Info("send snapshot to the overflowed watcher",
+		zap.String("address", wt.session.RemoteAddr()))
+	return wt.notify(rpcpb.EventNotify{Type: event.InitEvent, InitEvent: data})
+}
+
+func (wn *eventNotifier) removeWatcher(wt *watcherSession, err error) {
+	wn.Lock()
+	defer wn.Unlock()
+
+	if wn.watchers[wt.session.ID()] == wt {
+		wn.logger.Error("fail to notify watcher",
+			zap.String("address", wt.session.RemoteAddr()),
+			zap.Error(err))
+		wn.doClearWatcherLocked(wt)
+	}
+}
+
 func (wn *eventNotifier) doClearWatcherLocked(w *watcherSession) {
 	delete(wn.watchers, w.session.ID())
+	close(w.stopC)
 	w.session.Close()
+	watcherLagGauge.DeleteLabelValues(w.session.RemoteAddr())
 	wn.logger.Info("watcher removed",
 		zap.String("address", w.session.RemoteAddr()))
 }
 
+// handleOverflowLocked handles the watcher whose queue is full according to the
+// overflow policy.
+func (wn *eventNotifier) handleOverflowLocked(wt *watcherSession) {
+	watcherOverflowCounter.WithLabelValues(wn.overflowPolicy).Inc()
+	if wn.overflowPolicy != config.WatcherOverflowDropToSnapshot ||
+		!event.MatchEvent(event.InitEvent, wt.flag) {
+		wn.logger.Warn("watcher overflowed, disconnect",
+			zap.String("address", wt.session.RemoteAddr()),
+			zap.Uint64("queue-size", wn.queueSize))
+		wn.doClearWatcherLocked(wt)
+		return
+	}
+
+	wn.logger.Warn("watcher overflowed, drop the queued events",
+		zap.String("address", wt.session.RemoteAddr()),
+		zap.Uint64("queue-size", wn.queueSize))
+drain:
+	for {
+		select {
+		case <-wt.queue:
+		default:
+			break drain
+		}
+	}
+	select {
+	case wt.resyncC <- struct{}{}:
+	default:
+	}
+}
+
 func (wn *eventNotifier) doNotify(evt rpcpb.EventNotify) {
 	wn.Lock()
 	defer wn.Unlock()
 
 	for _, wt := range wn.watchers {
-		err := wt.notify(evt)
-		if err != nil {
-			wn.doClearWatcherLocked(wt)
+		if !event.MatchEvent(evt.Type, wt.flag) {
+			continue
+		}
+
+		select {
+		case wt.queue <- evt:
+			watcherLagGauge.WithLabelValues(wt.session.RemoteAddr()).Set(float64(len(wt.queue)))
+		default:
+			wn.handleOverflowLocked(wt)
 		}
 	}
 }
@@ -0,0 +1,144 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prophet
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fagongzi/goetty"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/matrixorigin/matrixcube/components/log"
+	"github.com/matrixorigin/matrixcube/components/prophet/config"
+	"github.com/matrixorigin/matrixcube/components/prophet/event"
+	"github.com/matrixorigin/matrixcube/pb/rpcpb"
+)
+
+type testWatcherSession struct {
+	goetty.IOSession
+
+	id       uint64
+	blockC   chan struct{}
+	mu       sync.Mutex
+	received int
+	closed   bool
+}
+
+func newTestWatcherSession(id uint64, block bool) *testWatcherSession {
+	s := &testWatcherSession{id: id}
+	if block {
+		s.blockC = make(chan struct{})
+	}
+	return s
+}
+
+func (s *testWatcherSession) ID() uint64         { return s.id }
+func (s *testWatcherSession) RemoteAddr() string { return fmt.Sprintf("watcher-%d", s.id) }
+
+func (s *testWatcherSession) WriteAndFlush(msg interface{}) error {
+	s.mu.Lock()
+	s.received++
+	s.mu.Unlock()
+	if s.blockC != nil {
+		<-s.blockC
+	}
+	return nil
+}
+
+func (s *testWatcherSession) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	return nil
+}
+
+func (s *testWatcherSession) getReceived() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.received
+}
+
+func (s *testWatcherSession) isClosed() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.closed
+}
+
+func TestEventNotifierSlowWatcher(t *testing.T) {
+	wn := newWatcherNotifier(nil, 2, config.WatcherOverflowDisconnect, log.GetDefaultZapLogger())
+	defer wn.stop()
+
+	slow := newTestWatcherSession(1, true)
+	defer close(slow.blockC)
+	fast := newTestWatcherSession(2, false)
+	require.NoError(t, wn.addWatcher(event.AllEvent, slow))
+	require.NoError(t, wn.addWatcher(event.AllEvent, fast))
+
+	// the slow watcher is blocked in sending the first event, the next 2 events
+	// are queued and the last one overflows the queue
+	for i := 1; i <= 4; i++ {
+		wn.doNotify(rpcpb.EventNotify{Type: event.ShardEvent})
+		require.Eventually(t, func() bool { return fast.getReceived() == i },
+			time.Second, time.Millisecond)
+		if i == 1 {
+			require.Eventually(t, func() bool { return slow.getReceived() == 1 },
+				time.Second, time.Millisecond)
+		}
+	}
+	assert.True(t, slow.isClosed())
+	assert.False(t, fast.isClosed())
+
+	wn.Lock()
+	defer wn.Unlock()
+	assert.Equal(t, 1, len(wn.watchers))
+	assert.NotNil(t, wn.watchers[fast.ID()])
+}
+
+func TestEventNotifierDropToSnapshot(t *testing.T) {
+	wn := newWatcherNotifier(nil, 1, config.WatcherOverflowDropToSnapshot, log.GetDefaultZapLogger())
+	defer wn.stop()
+
+	newWatcher := func(id uint64, flag uint32) *watcherSession {
+		wt := &watcherSession{
+			flag:    flag,
+			session: newTestWatcherSession(id, false),
+			queue:   make(chan rpcpb.EventNotify, wn.queueSize),
+			resyncC: make(chan struct{}, 1),
+			stopC:   make(chan struct{}),
+		}
+		wn.watchers[id] = wt
+		return wt
+	}
+	// the watchers have no workers, the queues are never consumed
+	resync := newWatcher(1, event.InitEvent|event.ShardEvent)
+	noInit := newWatcher(2, event.ShardEvent)
+
+	wn.doNotify(rpcpb.EventNotify{Type: event.ShardEvent})
+	assert.Equal(t, 1, len(resync.queue))
+	assert.Equal(t, 0, len(resync.resyncC))
+
+	wn.doNotify(rpcpb.EventNotify{Type: event.ShardEvent})
+	assert.Equal(t, 0, len(resync.queue))
+	assert.Equal(t, 1, len(resync.resyncC))
+	assert.False(t, resync.session.(*testWatcherSession).isClosed())
+	assert.True(t, noInit.session.(*testWatcherSession).isClosed())
+
+	wn.Lock()
+	defer wn.Unlock()
+	assert.Equal(t, 1, len(wn.watchers))
+}
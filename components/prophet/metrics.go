@@ -0,0 +1,39 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prophet
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var watcherLagGauge = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Namespace: "prophet",
+		Subsystem: "event",
+		Name:      "watcher_lag",
+		Help:      "Number of the events queued but not sent to the event watcher.",
+	}, []string{"watcher"})
+
+var watcherOverflowCounter = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: "prophet",
+		Subsystem: "event",
+		Name:      "watcher_overflow_count",
+		Help:      "Counter of the event watchers whose queue is full.",
+	}, []string{"policy"})
+
+func init() {
+	prometheus.MustRegister(watcherLagGauge)
+	prometheus.MustRegister(watcherOverflowCounter)
+}
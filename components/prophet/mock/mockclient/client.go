@@ -6,12 +6,14 @@ package mockclient
 
 import (
 	reflect "reflect"
+	time "time"
 
 	roaring64 "github.com/RoaringBitmap/roaring/roaring64"
 	gomock "github.com/golang/mock/gomock"
 	prophet "github.com/matrixorigin/matrixcube/components/prophet"
 	metapb "github.com/matrixorigin/matrixcube/pb/metapb"
 	rpcpb "github.com/matrixorigin/matrixcube/pb/rpcpb"
+	txnpb "github.com/matrixorigin/matrixcube/pb/txnpb"
 )
 
 // MockClient is a mock of Client interface.
@@ -113,6 +115,24 @@ func (mr *MockClientMockRecorder) AsyncAddShardsWithLeastPeers(resources, leastP
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AsyncAddShardsWithLeastPeers", reflect.TypeOf((*MockClient)(nil).AsyncAddShardsWithLeastPeers), resources, leastPeers)
 }
 
+// AsyncBulkAddShards mocks base method.
+func (m *MockClient) AsyncBulkAddShards(resources ...metapb.Shard) error {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{}
+	for _, a := range resources {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "AsyncBulkAddShards", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AsyncBulkAddShards indicates an expected call of AsyncBulkAddShards.
+func (mr *MockClientMockRecorder) AsyncBulkAddShards(resources ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AsyncBulkAddShards", reflect.TypeOf((*MockClient)(nil).AsyncBulkAddShards), resources...)
+}
+
 // AsyncRemoveShards mocks base method.
 func (m *MockClient) AsyncRemoveShards(ids ...uint64) error {
 	m.ctrl.T.Helper()
@@ -308,6 +328,183 @@ func (mr *MockClientMockRecorder) PutPlacementRule(rule interface{}) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PutPlacementRule", reflect.TypeOf((*MockClient)(nil).PutPlacementRule), rule)
 }
 
+// PutPlacementRuleGroupBundle mocks base method.
+func (m *MockClient) PutPlacementRuleGroupBundle(bundle rpcpb.PlacementRuleGroupBundle) (uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PutPlacementRuleGroupBundle", bundle)
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PutPlacementRuleGroupBundle indicates an expected call of PutPlacementRuleGroupBundle.
+func (mr *MockClientMockRecorder) PutPlacementRuleGroupBundle(bundle interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PutPlacementRuleGroupBundle", reflect.TypeOf((*MockClient)(nil).PutPlacementRuleGroupBundle), bundle)
+}
+
+// GetPlacementRuleGroupBundle mocks base method.
+func (m *MockClient) GetPlacementRuleGroupBundle(id string) (rpcpb.PlacementRuleGroupBundle, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPlacementRuleGroupBundle", id)
+	ret0, _ := ret[0].(rpcpb.PlacementRuleGroupBundle)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPlacementRuleGroupBundle indicates an expected call of GetPlacementRuleGroupBundle.
+func (mr *MockClientMockRecorder) GetPlacementRuleGroupBundle(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPlacementRuleGroupBundle", reflect.TypeOf((*MockClient)(nil).GetPlacementRuleGroupBundle), id)
+}
+
+// DeletePlacementRuleGroupBundle mocks base method.
+func (m *MockClient) DeletePlacementRuleGroupBundle(id string, version uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeletePlacementRuleGroupBundle", id, version)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeletePlacementRuleGroupBundle indicates an expected call of DeletePlacementRuleGroupBundle.
+func (mr *MockClientMockRecorder) DeletePlacementRuleGroupBundle(id, version interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePlacementRuleGroupBundle", reflect.TypeOf((*MockClient)(nil).DeletePlacementRuleGroupBundle), id, version)
+}
+
+// ListDestroyingShards mocks base method.
+func (m *MockClient) ListDestroyingShards() ([]rpcpb.DestroyingShard, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListDestroyingShards")
+	ret0, _ := ret[0].([]rpcpb.DestroyingShard)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListDestroyingShards indicates an expected call of ListDestroyingShards.
+func (mr *MockClientMockRecorder) ListDestroyingShards() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDestroyingShards", reflect.TypeOf((*MockClient)(nil).ListDestroyingShards))
+}
+
+// DetectDeadlock mocks base method.
+func (m *MockClient) DetectDeadlock(edge txnpb.WaitForEdge) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DetectDeadlock", edge)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DetectDeadlock indicates an expected call of DetectDeadlock.
+func (mr *MockClientMockRecorder) DetectDeadlock(edge interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DetectDeadlock", reflect.TypeOf((*MockClient)(nil).DetectDeadlock), edge)
+}
+
+// CleanUpWaitFor mocks base method.
+func (m *MockClient) CleanUpWaitFor(txnID []byte) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CleanUpWaitFor", txnID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CleanUpWaitFor indicates an expected call of CleanUpWaitFor.
+func (mr *MockClientMockRecorder) CleanUpWaitFor(txnID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CleanUpWaitFor", reflect.TypeOf((*MockClient)(nil).CleanUpWaitFor), txnID)
+}
+
+// UpdateShardLabels mocks base method.
+func (m *MockClient) UpdateShardLabels(req rpcpb.UpdateShardLabelsReq) (rpcpb.ShardLabelsJob, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateShardLabels", req)
+	ret0, _ := ret[0].(rpcpb.ShardLabelsJob)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateShardLabels indicates an expected call of UpdateShardLabels.
+func (mr *MockClientMockRecorder) UpdateShardLabels(req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateShardLabels", reflect.TypeOf((*MockClient)(nil).UpdateShardLabels), req)
+}
+
+// GetShardLabelsJob mocks base method.
+func (m *MockClient) GetShardLabelsJob(id uint64) (rpcpb.ShardLabelsJob, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetShardLabelsJob", id)
+	ret0, _ := ret[0].(rpcpb.ShardLabelsJob)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetShardLabelsJob indicates an expected call of GetShardLabelsJob.
+func (mr *MockClientMockRecorder) GetShardLabelsJob(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetShardLabelsJob", reflect.TypeOf((*MockClient)(nil).GetShardLabelsJob), id)
+}
+
+// ListSnapshotProgresses mocks base method.
+func (m *MockClient) ListSnapshotProgresses(storeID, shardID uint64) ([]metapb.SnapshotProgress, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSnapshotProgresses", storeID, shardID)
+	ret0, _ := ret[0].([]metapb.SnapshotProgress)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSnapshotProgresses indicates an expected call of ListSnapshotProgresses.
+func (mr *MockClientMockRecorder) ListSnapshotProgresses(storeID, shardID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSnapshotProgresses", reflect.TypeOf((*MockClient)(nil).ListSnapshotProgresses), storeID, shardID)
+}
+
+// SetStoreMaintenance mocks base method.
+func (m *MockClient) SetStoreMaintenance(storeID uint64, maintenance bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetStoreMaintenance", storeID, maintenance)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetStoreMaintenance indicates an expected call of SetStoreMaintenance.
+func (mr *MockClientMockRecorder) SetStoreMaintenance(storeID, maintenance interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetStoreMaintenance", reflect.TypeOf((*MockClient)(nil).SetStoreMaintenance), storeID, maintenance)
+}
+
+// UpdateEvictLeaderStores mocks base method.
+func (m *MockClient) UpdateEvictLeaderStores(storeID uint64, remove bool) ([]uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateEvictLeaderStores", storeID, remove)
+	ret0, _ := ret[0].([]uint64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateEvictLeaderStores indicates an expected call of UpdateEvictLeaderStores.
+func (mr *MockClientMockRecorder) UpdateEvictLeaderStores(storeID, remove interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateEvictLeaderStores", reflect.TypeOf((*MockClient)(nil).UpdateEvictLeaderStores), storeID, remove)
+}
+
+// ScatterShards mocks base method.
+func (m *MockClient) ScatterShards(shardIDs []uint64) ([]uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ScatterShards", shardIDs)
+	ret0, _ := ret[0].([]uint64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ScatterShards indicates an expected call of ScatterShards.
+func (mr *MockClientMockRecorder) ScatterShards(shardIDs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ScatterShards", reflect.TypeOf((*MockClient)(nil).ScatterShards), shardIDs)
+}
+
 // PutStore mocks base method.
 func (m *MockClient) PutStore(container metapb.Store) error {
 	m.ctrl.T.Helper()
@@ -379,3 +576,165 @@ func (mr *MockClientMockRecorder) StoreHeartbeat(hb interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StoreHeartbeat", reflect.TypeOf((*MockClient)(nil).StoreHeartbeat), hb)
 }
+
+// UpdateScheduleConfig mocks base method.
+func (m *MockClient) UpdateScheduleConfig(changes []byte, rollbackWindow time.Duration, rollbackMaxOperators uint64) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateScheduleConfig", changes, rollbackWindow, rollbackMaxOperators)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateScheduleConfig indicates an expected call of UpdateScheduleConfig.
+func (mr *MockClientMockRecorder) UpdateScheduleConfig(changes, rollbackWindow, rollbackMaxOperators interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateScheduleConfig", reflect.TypeOf((*MockClient)(nil).UpdateScheduleConfig), changes, rollbackWindow, rollbackMaxOperators)
+}
+
+// GetClusterTopology mocks base method.
+func (m *MockClient) GetClusterTopology(zoneLabel, hostLabel string) ([]rpcpb.TopologyZone, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetClusterTopology", zoneLabel, hostLabel)
+	ret0, _ := ret[0].([]rpcpb.TopologyZone)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetClusterTopology indicates an expected call of GetClusterTopology.
+func (mr *MockClientMockRecorder) GetClusterTopology(zoneLabel, hostLabel interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetClusterTopology", reflect.TypeOf((*MockClient)(nil).GetClusterTopology), zoneLabel, hostLabel)
+}
+
+// AsyncDestroyShards mocks base method.
+func (m *MockClient) AsyncDestroyShards(group uint64, start, end []byte, labels []metapb.Label, removeData bool) ([]uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AsyncDestroyShards", group, start, end, labels, removeData)
+	ret0, _ := ret[0].([]uint64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AsyncDestroyShards indicates an expected call of AsyncDestroyShards.
+func (mr *MockClientMockRecorder) AsyncDestroyShards(group, start, end, labels, removeData interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AsyncDestroyShards", reflect.TypeOf((*MockClient)(nil).AsyncDestroyShards), group, start, end, labels, removeData)
+}
+
+// SetPreferredLeader mocks base method.
+func (m *MockClient) SetPreferredLeader(shardID, storeID uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetPreferredLeader", shardID, storeID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetPreferredLeader indicates an expected call of SetPreferredLeader.
+func (mr *MockClientMockRecorder) SetPreferredLeader(shardID, storeID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPreferredLeader", reflect.TypeOf((*MockClient)(nil).SetPreferredLeader), shardID, storeID)
+}
+
+// GetShardRoute mocks base method.
+func (m *MockClient) GetShardRoute(req rpcpb.GetShardRouteReq) (rpcpb.GetShardRouteRsp, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetShardRoute", req)
+	ret0, _ := ret[0].(rpcpb.GetShardRouteRsp)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetShardRoute indicates an expected call of GetShardRoute.
+func (mr *MockClientMockRecorder) GetShardRoute(req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetShardRoute", reflect.TypeOf((*MockClient)(nil).GetShardRoute), req)
+}
+
+// RelocateRange mocks base method.
+func (m *MockClient) RelocateRange(group uint64, start, end []byte, stores []uint64) (uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RelocateRange", group, start, end, stores)
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RelocateRange indicates an expected call of RelocateRange.
+func (mr *MockClientMockRecorder) RelocateRange(group, start, end, stores interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RelocateRange", reflect.TypeOf((*MockClient)(nil).RelocateRange), group, start, end, stores)
+}
+
+// GetRangeRelocation mocks base method.
+func (m *MockClient) GetRangeRelocation(id uint64) (rpcpb.GetRangeRelocationRsp, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRangeRelocation", id)
+	ret0, _ := ret[0].(rpcpb.GetRangeRelocationRsp)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRangeRelocation indicates an expected call of GetRangeRelocation.
+func (mr *MockClientMockRecorder) GetRangeRelocation(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRangeRelocation", reflect.TypeOf((*MockClient)(nil).GetRangeRelocation), id)
+}
+
+// CancelLabelSteering mocks base method.
+func (m *MockClient) CancelLabelSteering(id uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelLabelSteering", id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CancelLabelSteering indicates an expected call of CancelLabelSteering.
+func (mr *MockClientMockRecorder) CancelLabelSteering(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelLabelSteering", reflect.TypeOf((*MockClient)(nil).CancelLabelSteering), id)
+}
+
+// UpdateGCSafePoint mocks base method.
+func (m *MockClient) UpdateGCSafePoint(group, safePoint uint64) (uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateGCSafePoint", group, safePoint)
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateGCSafePoint indicates an expected call of UpdateGCSafePoint.
+func (mr *MockClientMockRecorder) UpdateGCSafePoint(group, safePoint interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateGCSafePoint", reflect.TypeOf((*MockClient)(nil).UpdateGCSafePoint), group, safePoint)
+}
+
+// GetGCSafePoint mocks base method.
+func (m *MockClient) GetGCSafePoint(group uint64) (uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetGCSafePoint", group)
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetGCSafePoint indicates an expected call of GetGCSafePoint.
+func (mr *MockClientMockRecorder) GetGCSafePoint(group interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetGCSafePoint", reflect.TypeOf((*MockClient)(nil).GetGCSafePoint), group)
+}
+
+// CancelRangeRelocation mocks base method.
+func (m *MockClient) CancelRangeRelocation(id uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelRangeRelocation", id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CancelRangeRelocation indicates an expected call of CancelRangeRelocation.
+func (mr *MockClientMockRecorder) CancelRangeRelocation(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelRangeRelocation", reflect.TypeOf((*MockClient)(nil).CancelRangeRelocation), id)
+}
@@ -36,6 +36,11 @@ func (mc *Cluster) SetSplitMergeInterval(v time.Duration) {
 	mc.updateScheduleConfig(func(s *config.ScheduleConfig) { s.SplitMergeInterval = typeutil.NewDuration(v) })
 }
 
+// SetEmptyShardMergeWindow updates the EmptyShardMergeWindow configuration.
+func (mc *Cluster) SetEmptyShardMergeWindow(v time.Duration) {
+	mc.updateScheduleConfig(func(s *config.ScheduleConfig) { s.EmptyShardMergeWindow = typeutil.NewDuration(v) })
+}
+
 // SetEnableOneWayMerge updates the EnableOneWayMerge configuration.
 func (mc *Cluster) SetEnableOneWayMerge(v bool) {
 	mc.updateScheduleConfig(func(s *config.ScheduleConfig) { s.EnableOneWayMerge = v })
@@ -129,6 +134,11 @@ func (mc *Cluster) SetLocationLabels(v []string) {
 	mc.updateReplicationConfig(func(r *config.ReplicationConfig) { r.LocationLabels = v })
 }
 
+// SetLeaderPriorities updates the LeaderPriorities configuration.
+func (mc *Cluster) SetLeaderPriorities(v []config.LeaderPriority) {
+	mc.updateReplicationConfig(func(r *config.ReplicationConfig) { r.LeaderPriorities = v })
+}
+
 func (mc *Cluster) updateScheduleConfig(f func(*config.ScheduleConfig)) {
 	s := mc.GetScheduleConfig().Clone()
 	f(s)
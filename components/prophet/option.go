@@ -28,9 +28,13 @@ import (
 type Option func(*options)
 
 type options struct {
-	logger       *zap.Logger
-	leaderGetter func() *metapb.Member
-	rpcTimeout   time.Duration
+	logger              *zap.Logger
+	leaderGetter        func() *metapb.Member
+	rpcTimeout          time.Duration
+	maxRetries          int
+	leaderCheckInterval time.Duration
+	hedgedReadDelay     time.Duration
+	hedgedReadTargets   func() []string
 }
 
 func (opts *options) adjust() {
@@ -38,6 +42,14 @@ func (opts *options) adjust() {
 		opts.rpcTimeout = time.Second * 10
 	}
 
+	if opts.maxRetries == 0 {
+		opts.maxRetries = 50
+	}
+
+	if opts.leaderCheckInterval == 0 {
+		opts.leaderCheckInterval = time.Second
+	}
+
 	opts.logger = log.Adjust(opts.logger).Named("client")
 }
 
@@ -62,6 +74,25 @@ func WithRPCTimeout(value time.Duration) Option {
 	}
 }
 
+// WithMaxRetries set the max number of retries of the requests failed due to the
+// prophet leader changed, `RetryExhaustedError` is returned if exceeded.
+func WithMaxRetries(value int) Option {
+	return func(opts *options) {
+		opts.maxRetries = value
+	}
+}
+
+// WithHedgedReads enable the hedged reads. If the response of a read-only request
+// is not received from the leader within the delay, the request is also sent to
+// one of the prophet followers returned by the targets func, and the first success
+// response is used.
+func WithHedgedReads(delay time.Duration, targets func() []string) Option {
+	return func(opts *options) {
+		opts.hedgedReadDelay = delay
+		opts.hedgedReadTargets = targets
+	}
+}
+
 func createConn(logger *zap.Logger) goetty.IOSession {
 	encoder, decoder := codec.NewClientCodec(10 * buf.MB)
 	return goetty.NewIOSession(goetty.WithCodec(encoder, decoder),
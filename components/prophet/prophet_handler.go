@@ -14,8 +14,10 @@
 package prophet
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/fagongzi/goetty"
 	"github.com/matrixorigin/matrixcube/components/prophet/cluster"
@@ -61,6 +63,9 @@ func (p *defaultProphet) handleRPCRequest(rs goetty.IOSession, data interface{},
 	resp.ID = req.ID
 	rc := p.GetRaftCluster()
 	if p.cfg.Prophet.TestContext.ResponseNotLeader() || rc == nil || (p.member != nil && !p.member.IsLeader()) {
+		if !p.cfg.Prophet.TestContext.ResponseNotLeader() && isFollowerReadable(req.Type) {
+			return p.handleFollowerRead(rs, req, resp)
+		}
 		resp.Error = util.ErrNotLeader.Error()
 		resp.Leader = p.member.GetLeader().GetAddr()
 		return rs.WriteAndFlush(resp)
@@ -85,6 +90,78 @@ func (p *defaultProphet) handleRPCRequest(rs goetty.IOSession, data interface{},
 		if err != nil {
 			resp.Error = err.Error()
 		}
+	case rpcpb.TypeDetectDeadlockReq:
+		resp.Type = rpcpb.TypeDetectDeadlockRsp
+		rsp, err := rc.HandleDetectDeadlock(req)
+		if err != nil {
+			resp.Error = err.Error()
+		} else {
+			resp.DetectDeadlock = *rsp
+		}
+	case rpcpb.TypeListDestroyingShardsReq:
+		resp.Type = rpcpb.TypeListDestroyingShardsRsp
+		err := p.handleListDestroyingShards(rc, req, resp)
+		if err != nil {
+			resp.Error = err.Error()
+		}
+	case rpcpb.TypeUpdateShardLabelsReq:
+		resp.Type = rpcpb.TypeUpdateShardLabelsRsp
+		err := p.handleUpdateShardLabels(rc, req, resp)
+		if err != nil {
+			resp.Error = err.Error()
+		}
+	case rpcpb.TypeGetShardLabelsJobReq:
+		resp.Type = rpcpb.TypeGetShardLabelsJobRsp
+		err := p.handleGetShardLabelsJob(rc, req, resp)
+		if err != nil {
+			resp.Error = err.Error()
+		}
+	case rpcpb.TypeCancelLabelSteeringReq:
+		resp.Type = rpcpb.TypeCancelLabelSteeringRsp
+		err := rc.HandleCancelLabelSteering(req.CancelLabelSteering)
+		if err != nil {
+			resp.Error = err.Error()
+		}
+	case rpcpb.TypeListSnapshotProgressesReq:
+		resp.Type = rpcpb.TypeListSnapshotProgressesRsp
+		err := p.handleListSnapshotProgresses(rc, req, resp)
+		if err != nil {
+			resp.Error = err.Error()
+		}
+	case rpcpb.TypeUpdateGCSafePointReq:
+		resp.Type = rpcpb.TypeUpdateGCSafePointRsp
+		rsp, err := rc.HandleUpdateGCSafePoint(req.UpdateGCSafePoint)
+		if err != nil {
+			resp.Error = err.Error()
+		} else {
+			resp.UpdateGCSafePoint = *rsp
+		}
+	case rpcpb.TypeGetGCSafePointReq:
+		resp.Type = rpcpb.TypeGetGCSafePointRsp
+		rsp, err := rc.HandleGetGCSafePoint(req.GetGCSafePoint)
+		if err != nil {
+			resp.Error = err.Error()
+		} else {
+			resp.GetGCSafePoint = *rsp
+		}
+	case rpcpb.TypeSetStoreMaintenanceReq:
+		resp.Type = rpcpb.TypeSetStoreMaintenanceRsp
+		err := p.handleSetStoreMaintenance(rc, req, resp)
+		if err != nil {
+			resp.Error = err.Error()
+		}
+	case rpcpb.TypeUpdateEvictLeaderStoresReq:
+		resp.Type = rpcpb.TypeUpdateEvictLeaderStoresRsp
+		err := p.handleUpdateEvictLeaderStores(rc, req, resp)
+		if err != nil {
+			resp.Error = err.Error()
+		}
+	case rpcpb.TypeScatterShardsReq:
+		resp.Type = rpcpb.TypeScatterShardsRsp
+		err := p.handleScatterShards(rc, req, resp)
+		if err != nil {
+			resp.Error = err.Error()
+		}
 	case rpcpb.TypeCreateDestroyingReq:
 		resp.Type = rpcpb.TypeCreateDestroyingRsp
 		err := p.handleCreateDestroying(rc, req, resp)
@@ -158,6 +235,24 @@ func (p *defaultProphet) handleRPCRequest(rs goetty.IOSession, data interface{},
 		if err != nil {
 			resp.Error = err.Error()
 		}
+	case rpcpb.TypePutPlacementRuleGroupBundleReq:
+		resp.Type = rpcpb.TypePutPlacementRuleGroupBundleRsp
+		err := p.handlePutPlacementRuleGroupBundle(rc, req, resp)
+		if err != nil {
+			resp.Error = err.Error()
+		}
+	case rpcpb.TypeGetPlacementRuleGroupBundleReq:
+		resp.Type = rpcpb.TypeGetPlacementRuleGroupBundleRsp
+		err := p.handleGetPlacementRuleGroupBundle(rc, req, resp)
+		if err != nil {
+			resp.Error = err.Error()
+		}
+	case rpcpb.TypeDeletePlacementRuleGroupBundleReq:
+		resp.Type = rpcpb.TypeDeletePlacementRuleGroupBundleRsp
+		err := rc.HandleDeletePlacementRuleGroupBundle(req)
+		if err != nil {
+			resp.Error = err.Error()
+		}
 	case rpcpb.TypeGetAppliedRulesReq:
 		resp.Type = rpcpb.TypeGetAppliedRulesRsp
 		err := p.handleGetAppliedRule(rc, req, resp)
@@ -194,6 +289,52 @@ func (p *defaultProphet) handleRPCRequest(rs goetty.IOSession, data interface{},
 		if err != nil {
 			resp.Error = err.Error()
 		}
+	case rpcpb.TypeUpdateScheduleConfigReq:
+		resp.Type = rpcpb.TypeUpdateScheduleConfigRsp
+		err := p.handleUpdateScheduleConfig(rc, req, resp)
+		if err != nil {
+			resp.Error = err.Error()
+		}
+	case rpcpb.TypeGetClusterTopologyReq:
+		resp.Type = rpcpb.TypeGetClusterTopologyRsp
+		resp.GetClusterTopology.Zones = rc.GetTopology(req.GetClusterTopology.ZoneLabel,
+			req.GetClusterTopology.HostLabel)
+	case rpcpb.TypeDestroyShardsReq:
+		resp.Type = rpcpb.TypeDestroyShardsRsp
+		err := p.handleDestroyShards(rc, req, resp)
+		if err != nil {
+			resp.Error = err.Error()
+		}
+	case rpcpb.TypeSetPreferredLeaderReq:
+		resp.Type = rpcpb.TypeSetPreferredLeaderRsp
+		err := p.handleSetPreferredLeader(rc, req, resp)
+		if err != nil {
+			resp.Error = err.Error()
+		}
+	case rpcpb.TypeGetShardRouteReq:
+		resp.Type = rpcpb.TypeGetShardRouteRsp
+		err := p.handleGetShardRoute(rc, req, resp)
+		if err != nil {
+			resp.Error = err.Error()
+		}
+	case rpcpb.TypeRelocateRangeReq:
+		resp.Type = rpcpb.TypeRelocateRangeRsp
+		err := p.handleRelocateRange(rc, req, resp)
+		if err != nil {
+			resp.Error = err.Error()
+		}
+	case rpcpb.TypeGetRangeRelocationReq:
+		resp.Type = rpcpb.TypeGetRangeRelocationRsp
+		err := p.handleGetRangeRelocation(rc, req, resp)
+		if err != nil {
+			resp.Error = err.Error()
+		}
+	case rpcpb.TypeCancelRangeRelocationReq:
+		resp.Type = rpcpb.TypeCancelRangeRelocationRsp
+		err := rc.HandleCancelRangeRelocation(req)
+		if err != nil {
+			resp.Error = err.Error()
+		}
 	default:
 		return fmt.Errorf("type %s not support", req.Type.String())
 	}
@@ -301,6 +442,69 @@ func (p *defaultProphet) handleGetDestroying(rc *cluster.RaftCluster, req *rpcpb
 	return nil
 }
 
+func (p *defaultProphet) handleListDestroyingShards(rc *cluster.RaftCluster, req *rpcpb.ProphetRequest, resp *rpcpb.ProphetResponse) error {
+	rsp, err := rc.HandleListDestroyingShards(req.ListDestroyingShards)
+	if err != nil {
+		return err
+	}
+	resp.ListDestroyingShards = *rsp
+	return nil
+}
+
+func (p *defaultProphet) handleUpdateShardLabels(rc *cluster.RaftCluster, req *rpcpb.ProphetRequest, resp *rpcpb.ProphetResponse) error {
+	rsp, err := rc.HandleUpdateShardLabels(req.UpdateShardLabels)
+	if err != nil {
+		return err
+	}
+	resp.UpdateShardLabels = *rsp
+	return nil
+}
+
+func (p *defaultProphet) handleGetShardLabelsJob(rc *cluster.RaftCluster, req *rpcpb.ProphetRequest, resp *rpcpb.ProphetResponse) error {
+	rsp, err := rc.HandleGetShardLabelsJob(req.GetShardLabelsJob)
+	if err != nil {
+		return err
+	}
+	resp.GetShardLabelsJob = *rsp
+	return nil
+}
+
+func (p *defaultProphet) handleListSnapshotProgresses(rc *cluster.RaftCluster, req *rpcpb.ProphetRequest, resp *rpcpb.ProphetResponse) error {
+	rsp, err := rc.HandleListSnapshotProgresses(req.ListSnapshotProgresses)
+	if err != nil {
+		return err
+	}
+	resp.ListSnapshotProgresses = *rsp
+	return nil
+}
+
+func (p *defaultProphet) handleSetStoreMaintenance(rc *cluster.RaftCluster, req *rpcpb.ProphetRequest, resp *rpcpb.ProphetResponse) error {
+	rsp, err := rc.HandleSetStoreMaintenance(req.SetStoreMaintenance)
+	if err != nil {
+		return err
+	}
+	resp.SetStoreMaintenance = *rsp
+	return nil
+}
+
+func (p *defaultProphet) handleUpdateEvictLeaderStores(rc *cluster.RaftCluster, req *rpcpb.ProphetRequest, resp *rpcpb.ProphetResponse) error {
+	rsp, err := rc.HandleUpdateEvictLeaderStores(req.UpdateEvictLeaderStores)
+	if err != nil {
+		return err
+	}
+	resp.UpdateEvictLeaderStores = *rsp
+	return nil
+}
+
+func (p *defaultProphet) handleScatterShards(rc *cluster.RaftCluster, req *rpcpb.ProphetRequest, resp *rpcpb.ProphetResponse) error {
+	rsp, err := rc.HandleScatterShards(req.ScatterShards)
+	if err != nil {
+		return err
+	}
+	resp.ScatterShards = *rsp
+	return nil
+}
+
 func (p *defaultProphet) handleReportDestroyed(rc *cluster.RaftCluster, req *rpcpb.ProphetRequest, resp *rpcpb.ProphetResponse) error {
 	state, err := rc.HandleReportDestroyed(req.ReportDestroyed)
 	if err != nil {
@@ -327,6 +531,26 @@ func (p *defaultProphet) handleGetStore(rc *cluster.RaftCluster, req *rpcpb.Prop
 	return nil
 }
 
+// handleFollowerRead handles the read-only requests on the prophet follower,
+// e.g. the hedged reads. The data is read from the storage.
+func (p *defaultProphet) handleFollowerRead(rs goetty.IOSession, req *rpcpb.ProphetRequest, resp *rpcpb.ProphetResponse) error {
+	switch req.Type {
+	case rpcpb.TypeGetStoreReq:
+		resp.Type = rpcpb.TypeGetStoreRsp
+		store, err := p.storage.GetStore(req.GetStore.ID)
+		if err == nil && store == nil {
+			err = fmt.Errorf("invalid container ID %d, not found", req.GetStore.ID)
+		}
+		if err == nil {
+			resp.GetStore.Data, err = store.Marshal()
+		}
+		if err != nil {
+			resp.Error = err.Error()
+		}
+	}
+	return rs.WriteAndFlush(resp)
+}
+
 func (p *defaultProphet) handleAllocID(rc *cluster.RaftCluster, req *rpcpb.ProphetRequest, resp *rpcpb.ProphetResponse) error {
 	id, err := p.storage.AllocID()
 	if err != nil {
@@ -367,6 +591,50 @@ func (p *defaultProphet) handleRemoveShards(rc *cluster.RaftCluster, req *rpcpb.
 	return nil
 }
 
+func (p *defaultProphet) handleDestroyShards(rc *cluster.RaftCluster, req *rpcpb.ProphetRequest, resp *rpcpb.ProphetResponse) error {
+	rsp, err := rc.HandleDestroyShards(req)
+	if err != nil {
+		return err
+	}
+
+	resp.DestroyShards = *rsp
+	return nil
+}
+
+func (p *defaultProphet) handleRelocateRange(rc *cluster.RaftCluster, req *rpcpb.ProphetRequest, resp *rpcpb.ProphetResponse) error {
+	rsp, err := rc.HandleRelocateRange(req)
+	if err != nil {
+		return err
+	}
+
+	resp.RelocateRange = *rsp
+	return nil
+}
+
+func (p *defaultProphet) handleGetRangeRelocation(rc *cluster.RaftCluster, req *rpcpb.ProphetRequest, resp *rpcpb.ProphetResponse) error {
+	rsp, err := rc.HandleGetRangeRelocation(req)
+	if err != nil {
+		return err
+	}
+
+	resp.GetRangeRelocation = *rsp
+	return nil
+}
+
+func (p *defaultProphet) handleSetPreferredLeader(rc *cluster.RaftCluster, req *rpcpb.ProphetRequest, resp *rpcpb.ProphetResponse) error {
+	return rc.HandleSetPreferredLeader(req)
+}
+
+func (p *defaultProphet) handleGetShardRoute(rc *cluster.RaftCluster, req *rpcpb.ProphetRequest, resp *rpcpb.ProphetResponse) error {
+	rsp, err := rc.HandleGetShardRoute(req)
+	if err != nil {
+		return err
+	}
+
+	resp.GetShardRoute = rsp
+	return nil
+}
+
 func (p *defaultProphet) handleCheckShardState(rc *cluster.RaftCluster, req *rpcpb.ProphetRequest, resp *rpcpb.ProphetResponse) error {
 	rsp, err := rc.HandleCheckShardState(req)
 	if err != nil {
@@ -381,6 +649,26 @@ func (p *defaultProphet) handlePutPlacementRule(rc *cluster.RaftCluster, req *rp
 	return rc.HandlePutPlacementRule(req)
 }
 
+func (p *defaultProphet) handlePutPlacementRuleGroupBundle(rc *cluster.RaftCluster, req *rpcpb.ProphetRequest, resp *rpcpb.ProphetResponse) error {
+	rsp, err := rc.HandlePutPlacementRuleGroupBundle(req)
+	if err != nil {
+		return err
+	}
+
+	resp.PutPlacementRuleGroupBundle = *rsp
+	return nil
+}
+
+func (p *defaultProphet) handleGetPlacementRuleGroupBundle(rc *cluster.RaftCluster, req *rpcpb.ProphetRequest, resp *rpcpb.ProphetResponse) error {
+	rsp, err := rc.HandleGetPlacementRuleGroupBundle(req)
+	if err != nil {
+		return err
+	}
+
+	resp.GetPlacementRuleGroupBundle = *rsp
+	return nil
+}
+
 func (p *defaultProphet) handleGetAppliedRule(rc *cluster.RaftCluster, req *rpcpb.ProphetRequest, resp *rpcpb.ProphetResponse) error {
 	rsp, err := rc.HandleAppliedRules(req)
 	if err != nil {
@@ -408,6 +696,22 @@ func (p *defaultProphet) handleGetScheduleGroupRule(rc *cluster.RaftCluster, req
 	return nil
 }
 
+func (p *defaultProphet) handleUpdateScheduleConfig(rc *cluster.RaftCluster, req *rpcpb.ProphetRequest, resp *rpcpb.ProphetResponse) error {
+	cfg, err := rc.UpdateScheduleConfig(req.UpdateScheduleConfig.Changes, cluster.ScheduleConfigGuard{
+		Window:       time.Duration(req.UpdateScheduleConfig.RollbackWindow),
+		MaxOperators: req.UpdateScheduleConfig.RollbackMaxOperators,
+	})
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		return err
+	}
+	resp.UpdateScheduleConfig.Config = data
+	return nil
+}
+
 // checkStore returns an error response if the store exists and is in tombstone state.
 // It returns nil if it can't get the store.
 func checkStore(rc *cluster.RaftCluster, storeID uint64) error {
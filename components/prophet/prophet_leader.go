@@ -77,7 +77,8 @@ func (p *defaultProphet) createEventNotifer() {
 	defer p.mu.Unlock()
 
 	p.stopEventNotifer()
-	p.mu.wn = newWatcherNotifier(p.cluster, p.logger)
+	p.mu.wn = newWatcherNotifier(p.cluster, p.cfg.Prophet.WatcherQueueSize,
+		p.cfg.Prophet.WatcherOverflowPolicy, p.logger)
 	p.mu.wn.start()
 }
 
@@ -92,7 +93,17 @@ func (p *defaultProphet) initClient() {
 	p.clientOnce.Do(func() {
 		p.client = NewClient(
 			WithRPCTimeout(p.cfg.Prophet.RPCTimeout.Duration),
+			WithMaxRetries(int(p.cfg.Prophet.RPCMaxRetries)),
+			WithHedgedReads(p.cfg.Prophet.HedgedReadDelay.Duration, p.getHedgedReadTargets),
 			WithLeaderGetter(p.GetLeader),
 			WithLogger(p.logger))
 	})
 }
+
+// getHedgedReadTargets returns the local prophet node if it's a follower.
+func (p *defaultProphet) getHedgedReadTargets() []string {
+	if !p.cfg.Prophet.ProphetNode || p.member == nil || p.member.IsLeader() {
+		return nil
+	}
+	return []string{p.cfg.Prophet.AdvertiseRPCAddr}
+}
@@ -0,0 +1,210 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prophet
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/matrixorigin/matrixcube/components/log"
+	pconfig "github.com/matrixorigin/matrixcube/components/prophet/config"
+	"github.com/matrixorigin/matrixcube/components/prophet/core"
+	"github.com/matrixorigin/matrixcube/components/prophet/member"
+	"github.com/matrixorigin/matrixcube/components/prophet/storage"
+	"github.com/matrixorigin/matrixcube/config"
+	"github.com/matrixorigin/matrixcube/pb/metapb"
+	"github.com/matrixorigin/matrixcube/util/fileutil"
+	"go.uber.org/zap"
+)
+
+const (
+	standaloneMetaFile        = "standalone"
+	standaloneIDBatch  uint64 = 512
+)
+
+// standaloneMeta is the persistent state of the standalone prophet, the
+// cluster ID and the end of the allocated ID range.
+type standaloneMeta struct {
+	clusterID uint64
+	maxID     uint64
+}
+
+func (m *standaloneMeta) Marshal() ([]byte, error) {
+	data := make([]byte, 16)
+	binary.BigEndian.PutUint64(data, m.clusterID)
+	binary.BigEndian.PutUint64(data[8:], m.maxID)
+	return data, nil
+}
+
+func (m *standaloneMeta) Unmarshal(data []byte) error {
+	if len(data) != 16 {
+		return fmt.Errorf("invalid standalone meta length %d", len(data))
+	}
+	m.clusterID = binary.BigEndian.Uint64(data)
+	m.maxID = binary.BigEndian.Uint64(data[8:])
+	return nil
+}
+
+// standaloneProphet is the prophet used in the single node dev mode. It runs
+// without the embedded etcd and the leader election, the store becomes the
+// prophet leader once started and bootstraps the shards locally. The IDs are
+// allocated locally in batches, the end of the allocated range is persisted
+// in the prophet data dir to keep the IDs unique across restarts. The cluster
+// metadata is kept in memory only and rebuilt from the heartbeats of the local
+// store after restart, there is no scheduling.
+type standaloneProphet struct {
+	logger       *zap.Logger
+	cfg          *config.Config
+	stopOnce     sync.Once
+	storage      storage.Storage
+	basicCluster *core.BasicCluster
+	client       *standaloneClient
+
+	mu struct {
+		sync.Mutex
+		meta standaloneMeta
+		base uint64
+	}
+}
+
+// NewStandaloneProphet returns a prophet instance for the single node dev mode,
+// used in the unit tests and the local development of the applications which
+// embed matrixcube.
+func NewStandaloneProphet(cfg *config.Config) Prophet {
+	logger := log.Adjust(cfg.Logger).Named("prophet").With(log.NodeField(cfg.Prophet.Name))
+	p := &standaloneProphet{
+		logger:       logger,
+		cfg:          cfg,
+		basicCluster: core.NewBasicCluster(logger),
+	}
+	p.storage = storage.NewStorage(rootPath, storage.NewMemKV(), p)
+	p.client = newStandaloneClient(p, p.storage, p.basicCluster, logger)
+	return p
+}
+
+func (p *standaloneProphet) Start() {
+	p.logger.Info("begin to start standalone prophet")
+	if err := p.loadOrCreateMeta(); err != nil {
+		p.logger.Fatal("fail to init standalone prophet", zap.Error(err))
+	}
+	p.client.start()
+	p.logger.Info("standalone prophet started",
+		zap.Uint64("cluster-id", p.GetClusterID()))
+
+	if p.cfg.Prophet.Handler != nil {
+		p.cfg.Prophet.Handler.ProphetBecomeLeader()
+	}
+}
+
+func (p *standaloneProphet) Stop() {
+	p.stopOnce.Do(func() {
+		p.client.Close()
+		p.logger.Info("standalone prophet stopped")
+	})
+}
+
+func (p *standaloneProphet) GetStorage() storage.Storage {
+	return p.storage
+}
+
+func (p *standaloneProphet) GetClient() Client {
+	return p.client
+}
+
+func (p *standaloneProphet) GetLeader() *metapb.Member {
+	return &metapb.Member{
+		Name: p.cfg.Prophet.Name,
+		Addr: p.cfg.Prophet.RPCAddr,
+	}
+}
+
+// GetMember returns nil, the standalone prophet has no election member.
+func (p *standaloneProphet) GetMember() *member.Member {
+	return nil
+}
+
+func (p *standaloneProphet) GetConfig() *pconfig.Config {
+	return &p.cfg.Prophet
+}
+
+func (p *standaloneProphet) GetClusterID() uint64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.mu.meta.clusterID
+}
+
+func (p *standaloneProphet) GetBasicCluster() *core.BasicCluster {
+	return p.basicCluster
+}
+
+// AllocID allocates the ID from the local range, a new range is allocated and
+// persisted once the current range is used up.
+func (p *standaloneProphet) AllocID() (uint64, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.mu.base == p.mu.meta.maxID {
+		meta := p.mu.meta
+		meta.maxID += standaloneIDBatch
+		if err := p.saveMeta(meta); err != nil {
+			return 0, err
+		}
+		p.mu.meta = meta
+	}
+	p.mu.base++
+	return p.mu.base, nil
+}
+
+func (p *standaloneProphet) loadOrCreateMeta() error {
+	dir, fs := p.cfg.Prophet.DataDir, p.cfg.FS
+	if err := fileutil.MkdirAll(dir, fs); err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if fileutil.HasFlagFile(dir, standaloneMetaFile, fs) {
+		if err := fileutil.GetFlagFileContent(dir, standaloneMetaFile, &p.mu.meta, fs); err != nil {
+			return err
+		}
+		p.mu.base = p.mu.meta.maxID
+		return nil
+	}
+
+	meta := standaloneMeta{
+		clusterID: (uint64(time.Now().Unix()) << 32) + uint64(rand.Uint32()),
+	}
+	if err := p.saveMeta(meta); err != nil {
+		return err
+	}
+	p.mu.meta = meta
+	return nil
+}
+
+// saveMeta writes the meta to a temp file and renames it, so the meta file is
+// never left partially written.
+func (p *standaloneProphet) saveMeta(meta standaloneMeta) error {
+	dir, fs := p.cfg.Prophet.DataDir, p.cfg.FS
+	tmp := standaloneMetaFile + ".tmp"
+	if err := fileutil.CreateFlagFile(dir, tmp, &meta, fs); err != nil {
+		return err
+	}
+	if err := fs.Rename(fs.PathJoin(dir, tmp), fs.PathJoin(dir, standaloneMetaFile)); err != nil {
+		return err
+	}
+	return fileutil.SyncDir(dir, fs)
+}
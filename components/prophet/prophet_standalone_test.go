@@ -0,0 +1,147 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prophet
+
+import (
+	"testing"
+	"time"
+
+	"github.com/RoaringBitmap/roaring/roaring64"
+	"github.com/matrixorigin/matrixcube/components/prophet/event"
+	"github.com/matrixorigin/matrixcube/components/prophet/util"
+	"github.com/matrixorigin/matrixcube/config"
+	"github.com/matrixorigin/matrixcube/pb/metapb"
+	"github.com/matrixorigin/matrixcube/pb/rpcpb"
+	"github.com/matrixorigin/matrixcube/util/leaktest"
+	"github.com/matrixorigin/matrixcube/vfs"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestStandaloneProphet(t *testing.T, fs vfs.FS) Prophet {
+	cfg := &config.Config{FS: fs}
+	cfg.Prophet.DataDir = "/standalone"
+	p := NewStandaloneProphet(cfg)
+	p.Start()
+	return p
+}
+
+func readTestEvent(t *testing.T, w EventWatcher) rpcpb.EventNotify {
+	select {
+	case evt := <-w.GetNotify():
+		return evt
+	case <-time.After(time.Second):
+		assert.FailNow(t, "timeout")
+	}
+	return rpcpb.EventNotify{}
+}
+
+func TestStandaloneAllocIDAfterRestart(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	fs := vfs.GetTestFS()
+	p := newTestStandaloneProphet(t, fs)
+	clusterID := p.GetClusterID()
+	assert.NotEqual(t, uint64(0), clusterID)
+	var last uint64
+	for i := 0; i < int(standaloneIDBatch)+1; i++ {
+		id, err := p.GetClient().AllocID()
+		assert.NoError(t, err)
+		assert.True(t, id > last)
+		last = id
+	}
+	p.Stop()
+
+	p = newTestStandaloneProphet(t, fs)
+	defer p.Stop()
+	assert.Equal(t, clusterID, p.GetClusterID())
+	id, err := p.GetClient().AllocID()
+	assert.NoError(t, err)
+	assert.True(t, id > last)
+}
+
+func TestStandaloneCreateAndRemoveShards(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	p := newTestStandaloneProphet(t, vfs.GetTestFS())
+	defer p.Stop()
+	c := p.GetClient()
+
+	w, err := c.NewWatcher(event.AllEvent)
+	assert.NoError(t, err)
+	defer w.Close()
+	assert.Equal(t, event.InitEvent, readTestEvent(t, w).Type)
+
+	assert.Error(t, c.AsyncAddShards(metapb.Shard{Unique: "s1", End: []byte("b")}))
+	assert.NoError(t, c.PutStore(metapb.Store{ID: 1}))
+	assert.Equal(t, event.StoreEvent, readTestEvent(t, w).Type)
+
+	assert.NoError(t, c.AsyncAddShards(metapb.Shard{Unique: "s1", End: []byte("b")}))
+	evt := readTestEvent(t, w)
+	assert.Equal(t, event.ShardEvent, evt.Type)
+	assert.True(t, evt.ShardEvent.Create)
+	shard := metapb.Shard{}
+	assert.NoError(t, shard.Unmarshal(evt.ShardEvent.Data))
+	assert.Equal(t, "s1", shard.Unique)
+	assert.Equal(t, 1, len(shard.Replicas))
+	assert.Equal(t, uint64(1), shard.Replicas[0].StoreID)
+
+	// created shard is skipped
+	assert.NoError(t, c.AsyncAddShards(metapb.Shard{Unique: "s1"}, metapb.Shard{Unique: "s2", Start: []byte("b")}))
+	evt = readTestEvent(t, w)
+	assert.Equal(t, event.ShardEvent, evt.Type)
+	assert.True(t, evt.ShardEvent.Create)
+
+	shard.State = metapb.ShardState_Running
+	assert.NoError(t, c.ShardHeartbeat(shard, rpcpb.ShardHeartbeatReq{Leader: &shard.Replicas[0]}))
+	evt = readTestEvent(t, w)
+	assert.Equal(t, event.ShardEvent, evt.Type)
+	assert.Equal(t, shard.Replicas[0].ID, evt.ShardEvent.Leader)
+	assert.Equal(t, event.ShardStatsEvent, readTestEvent(t, w).Type)
+
+	assert.NoError(t, c.AsyncRemoveShards(shard.ID))
+	evt = readTestEvent(t, w)
+	assert.Equal(t, event.ShardEvent, evt.Type)
+	assert.True(t, evt.ShardEvent.Removed)
+
+	bm := roaring64.NewBitmap()
+	bm.Add(shard.ID)
+	rsp, err := c.CheckShardState(bm)
+	assert.NoError(t, err)
+	assert.True(t, util.MustUnmarshalBM64(rsp.Destroyed).Contains(shard.ID))
+}
+
+func TestStandaloneDestroying(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	p := newTestStandaloneProphet(t, vfs.GetTestFS())
+	defer p.Stop()
+	c := p.GetClient()
+
+	state, err := c.CreateDestroying(1, 10, true, []uint64{2, 3})
+	assert.NoError(t, err)
+	assert.Equal(t, metapb.ShardState_Destroying, state)
+
+	state, err = c.ReportDestroyed(1, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, metapb.ShardState_Destroying, state)
+
+	state, err = c.ReportDestroyed(1, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, metapb.ShardState_Destroyed, state)
+
+	status, err := c.GetDestroying(1)
+	assert.NoError(t, err)
+	assert.Equal(t, metapb.ShardState_Destroyed, status.State)
+	assert.Equal(t, uint64(10), status.Index)
+}
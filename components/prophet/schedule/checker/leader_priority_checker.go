@@ -0,0 +1,88 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package checker
+
+import (
+	"github.com/matrixorigin/matrixcube/components/prophet/core"
+	"github.com/matrixorigin/matrixcube/components/prophet/schedule/filter"
+	"github.com/matrixorigin/matrixcube/components/prophet/schedule/operator"
+	"github.com/matrixorigin/matrixcube/components/prophet/schedule/opt"
+	"go.uber.org/zap"
+)
+
+const leaderPriorityCheckerName = "leader-priority-checker"
+
+// LeaderPriorityChecker moves the leader of the resource to the healthy voter
+// with the highest leader priority, if its priority is higher than the leader.
+type LeaderPriorityChecker struct {
+	cluster opt.Cluster
+}
+
+// NewLeaderPriorityChecker creates a leader priority checker.
+func NewLeaderPriorityChecker(cluster opt.Cluster) *LeaderPriorityChecker {
+	return &LeaderPriorityChecker{
+		cluster: cluster,
+	}
+}
+
+// GetType returns the checker type.
+func (c *LeaderPriorityChecker) GetType() string {
+	return leaderPriorityCheckerName
+}
+
+// Check verifies the leader of the resource has the highest leader priority
+// among the healthy voters, creating a transfer leader operator if need.
+func (c *LeaderPriorityChecker) Check(res *core.CachedShard) *operator.Operator {
+	// the leader is kept on the preferred store by the preferred leader checker
+	if c.cluster.GetShardPreferredLeader(res.Meta.GetID()) != 0 {
+		return nil
+	}
+	leaderStore := c.cluster.GetStore(res.GetLeader().GetStoreID())
+	if leaderStore == nil {
+		return nil
+	}
+
+	checkerCounter.WithLabelValues("leader_priority_checker", "check").Inc()
+	priorities := opt.NewLeaderPriorities(c.cluster, res)
+	priority := priorities.Get(leaderStore)
+	filters := []filter.Filter{
+		&filter.StoreStateFilter{ActionScope: leaderPriorityCheckerName, TransferLeader: true},
+	}
+	if leaderFilter := filter.NewPlacementLeaderSafeguard(leaderPriorityCheckerName, c.cluster, res, leaderStore); leaderFilter != nil {
+		filters = append(filters, leaderFilter)
+	}
+
+	var target *core.CachedStore
+	for _, store := range filter.SelectTargetStores(c.cluster.GetFollowerStores(res), filters, c.cluster.GetOpts()) {
+		if p := priorities.Get(store); p > priority ||
+			(p == priority && target != nil && store.Meta.GetID() < target.Meta.GetID()) {
+			target, priority = store, p
+		}
+	}
+	if target == nil {
+		return nil
+	}
+
+	op, err := operator.CreateTransferLeaderOperator("leader-priority", c.cluster, res,
+		leaderStore.Meta.GetID(), target.Meta.GetID(), operator.OpLeader)
+	if err != nil {
+		c.cluster.GetLogger().Debug("fail to create transfer leader to higher priority store operator",
+			zap.Uint64("resource", res.Meta.GetID()),
+			zap.Uint64("store", target.Meta.GetID()),
+			zap.Error(err))
+		return nil
+	}
+	checkerCounter.WithLabelValues("leader_priority_checker", "new-operator").Inc()
+	return op
+}
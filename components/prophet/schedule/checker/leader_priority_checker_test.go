@@ -0,0 +1,85 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package checker
+
+import (
+	"testing"
+
+	"github.com/matrixorigin/matrixcube/components/prophet/config"
+	"github.com/matrixorigin/matrixcube/components/prophet/mock/mockcluster"
+	"github.com/matrixorigin/matrixcube/components/prophet/schedule/operator"
+	"github.com/matrixorigin/matrixcube/components/prophet/schedule/placement"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLeaderPriorityChecker(t *testing.T) {
+	cluster := mockcluster.NewCluster(config.NewTestOptions())
+	pc := NewLeaderPriorityChecker(cluster)
+	cluster.AddLabelsStore(1, 1, map[string]string{"disk": "hdd", "zone": "z1"})
+	cluster.AddLabelsStore(2, 1, map[string]string{"disk": "ssd", "zone": "z2"})
+	cluster.AddLabelsStore(3, 1, map[string]string{"disk": "hdd", "zone": "z3"})
+	res := cluster.AddLeaderShard(1, 1, 2, 3)
+
+	// no leader priorities
+	assert.Nil(t, pc.Check(res))
+
+	// the priorities of other groups are ignored
+	cluster.SetLeaderPriorities([]config.LeaderPriority{{Group: 1, Key: "disk", Value: "ssd", Priority: 10}})
+	assert.Nil(t, pc.Check(res))
+
+	cluster.SetLeaderPriorities([]config.LeaderPriority{{Key: "disk", Value: "ssd", Priority: 10}})
+	op := pc.Check(res)
+	require.NotNil(t, op)
+	assert.Equal(t, "leader-priority", op.Desc())
+	v, ok := op.Step(0).(operator.TransferLeader)
+	assert.True(t, ok)
+	assert.Equal(t, uint64(1), v.FromStore)
+	assert.Equal(t, uint64(2), v.ToStore)
+
+	// already on the store with the highest priority
+	assert.Nil(t, pc.Check(cluster.AddLeaderShard(2, 2, 1, 3)))
+
+	// the priority of the placement rule is higher
+	cluster.SetEnablePlacementRules(true)
+	require.NoError(t, cluster.SetRule(&placement.Rule{
+		GroupID:          "prophet",
+		ID:               "default",
+		Role:             placement.Voter,
+		Count:            2,
+		LabelConstraints: []placement.LabelConstraint{{Key: "zone", Op: placement.NotIn, Values: []string{"z3"}}},
+	}))
+	require.NoError(t, cluster.SetRule(&placement.Rule{
+		GroupID:          "prophet",
+		ID:               "z3",
+		Role:             placement.Voter,
+		Count:            1,
+		LabelConstraints: []placement.LabelConstraint{{Key: "zone", Op: placement.In, Values: []string{"z3"}}},
+		LeaderPriority:   20,
+	}))
+	op = pc.Check(res)
+	require.NotNil(t, op)
+	assert.Equal(t, uint64(3), op.Step(0).(operator.TransferLeader).ToStore)
+
+	// the preferred leader takes precedence
+	cluster.SetShardPreferredLeader(1, 1)
+	assert.Nil(t, pc.Check(res))
+	cluster.SetShardPreferredLeader(1, 0)
+
+	// the store with the highest priority is unhealthy
+	cluster.SetStoreDown(3)
+	op = pc.Check(res)
+	require.NotNil(t, op)
+	assert.Equal(t, uint64(2), op.Step(0).(operator.TransferLeader).ToStore)
+}
@@ -18,6 +18,7 @@ import (
 	"github.com/matrixorigin/matrixcube/components/prophet/core"
 	"github.com/matrixorigin/matrixcube/components/prophet/schedule/operator"
 	"github.com/matrixorigin/matrixcube/components/prophet/schedule/opt"
+	"github.com/matrixorigin/matrixcube/pb/metapb"
 	"go.uber.org/zap"
 )
 
@@ -35,6 +36,9 @@ func NewLearnerChecker(cluster opt.Cluster) *LearnerChecker {
 
 // Check verifies a resource's role, creating an Operator if need.
 func (l *LearnerChecker) Check(res *core.CachedShard) *operator.Operator {
+	if res.Meta.GetDurabilityPolicy() == metapb.DurabilityPolicy_LeaderOnlyVoter {
+		return l.checkLeaderOnly(res)
+	}
 	for _, p := range res.GetLearners() {
 		op, err := operator.CreatePromoteLearnerOperator("promote-learner", l.cluster, res, p)
 		if err != nil {
@@ -46,3 +50,49 @@ func (l *LearnerChecker) Check(res *core.CachedShard) *operator.Operator {
 	}
 	return nil
 }
+
+// checkLeaderOnly keeps one voter of the leader-only shard. The learners are
+// replicated asynchronously by design, one of them is promoted only if the voter
+// is on a store not up, e.g. the store is going offline, then the old voter is
+// replaced by the replica checker. The extra voters, e.g. after the policy is
+// changed to the leader-only, are demoted to the learners.
+func (l *LearnerChecker) checkLeaderOnly(res *core.CachedShard) *operator.Operator {
+	var upVoters []metapb.Replica
+	for _, p := range res.GetVoters() {
+		if s := l.cluster.GetStore(p.StoreID); s != nil && s.IsUp() {
+			upVoters = append(upVoters, p)
+		}
+	}
+
+	if len(upVoters) == 0 {
+		for _, p := range res.GetLearners() {
+			if s := l.cluster.GetStore(p.StoreID); s == nil || !s.IsUp() {
+				continue
+			}
+			op, err := operator.CreatePromoteLearnerOperator("promote-learner", l.cluster, res, p)
+			if err != nil {
+				l.cluster.GetLogger().Debug("fail to create promote learner operator",
+					zap.Error(err))
+				continue
+			}
+			return op
+		}
+		return nil
+	}
+
+	if len(upVoters) > 1 {
+		for _, p := range upVoters {
+			if p.StoreID == res.GetLeader().GetStoreID() {
+				continue
+			}
+			op, err := operator.CreateDemoteVoterOperator("demote-voter", l.cluster, res, p)
+			if err != nil {
+				l.cluster.GetLogger().Debug("fail to create demote voter operator",
+					zap.Error(err))
+				continue
+			}
+			return op
+		}
+	}
+	return nil
+}
@@ -56,3 +56,79 @@ func TestPromoteLearner(t *testing.T) {
 	op = lc.Check(resource)
 	assert.Nil(t, op)
 }
+
+func TestLeaderOnlyLearnerNotPromoted(t *testing.T) {
+	cluster := mockcluster.NewCluster(config.NewTestOptions())
+	lc := NewLearnerChecker(cluster)
+	for id := uint64(1); id <= 2; id++ {
+		cluster.PutStoreWithLabels(id)
+	}
+
+	resource := core.NewCachedShard(
+		metapb.Shard{
+			ID:               1,
+			DurabilityPolicy: metapb.DurabilityPolicy_LeaderOnlyVoter,
+			Replicas: []metapb.Replica{
+				{ID: 101, StoreID: 1},
+				{ID: 102, StoreID: 2, Role: metapb.ReplicaRole_Learner},
+			},
+		}, &metapb.Replica{ID: 101, StoreID: 1})
+	assert.Nil(t, lc.Check(resource))
+}
+
+func TestLeaderOnlyLearnerPromotedOnOfflineVoter(t *testing.T) {
+	cluster := mockcluster.NewCluster(config.NewTestOptions())
+	lc := NewLearnerChecker(cluster)
+	for id := uint64(1); id <= 3; id++ {
+		cluster.PutStoreWithLabels(id)
+	}
+
+	resource := core.NewCachedShard(
+		metapb.Shard{
+			ID:               1,
+			DurabilityPolicy: metapb.DurabilityPolicy_LeaderOnlyVoter,
+			Replicas: []metapb.Replica{
+				{ID: 101, StoreID: 1},
+				{ID: 102, StoreID: 2, Role: metapb.ReplicaRole_Learner},
+				{ID: 103, StoreID: 3, Role: metapb.ReplicaRole_Learner},
+			},
+		}, &metapb.Replica{ID: 101, StoreID: 1})
+
+	cluster.SetStoreOffline(2)
+	assert.Nil(t, lc.Check(resource))
+
+	// the learner on the up store takes over the voter
+	cluster.SetStoreOffline(1)
+	op := lc.Check(resource)
+	assert.NotNil(t, op)
+	assert.Equal(t, "promote-learner", op.Desc())
+	v, ok := op.Step(0).(operator.PromoteLearner)
+	assert.True(t, ok)
+	assert.Equal(t, uint64(3), v.ToStore)
+}
+
+func TestLeaderOnlyVoterDemoted(t *testing.T) {
+	cluster := mockcluster.NewCluster(config.NewTestOptions())
+	lc := NewLearnerChecker(cluster)
+	for id := uint64(1); id <= 2; id++ {
+		cluster.PutStoreWithLabels(id)
+	}
+
+	resource := core.NewCachedShard(
+		metapb.Shard{
+			ID:               1,
+			DurabilityPolicy: metapb.DurabilityPolicy_LeaderOnlyVoter,
+			Replicas: []metapb.Replica{
+				{ID: 101, StoreID: 1},
+				{ID: 102, StoreID: 2},
+			},
+		}, &metapb.Replica{ID: 101, StoreID: 1})
+
+	op := lc.Check(resource)
+	assert.NotNil(t, op)
+	assert.Equal(t, "demote-voter", op.Desc())
+	assert.Equal(t, 1, op.Len())
+	v, ok := op.Step(0).(operator.DemoteFollower)
+	assert.True(t, ok)
+	assert.Equal(t, uint64(2), v.ToStore)
+}
@@ -35,17 +35,27 @@ type MergeChecker struct {
 	cluster    opt.Cluster
 	opts       *config.PersistOptions
 	splitCache *util.TTLUint64
+	// emptyCache records the shards found empty, shard id -> emptyShard
+	emptyCache *util.TTLUint64
 	startTime  time.Time // it's used to judge whether server recently start.
 }
 
+// emptyShard is the shard found empty since the time at the generation.
+type emptyShard struct {
+	since      time.Time
+	generation uint64
+}
+
 // NewMergeChecker creates a merge checker.
 func NewMergeChecker(ctx context.Context, cluster opt.Cluster) *MergeChecker {
 	opts := cluster.GetOpts()
 	splitCache := util.NewIDTTL(ctx, time.Minute, opts.GetSplitMergeInterval())
+	emptyCache := util.NewIDTTL(ctx, time.Minute, opts.GetEmptyShardMergeWindow())
 	return &MergeChecker{
 		cluster:    cluster,
 		opts:       opts,
 		splitCache: splitCache,
+		emptyCache: emptyCache,
 		startTime:  time.Now(),
 	}
 }
@@ -78,18 +88,23 @@ func (m *MergeChecker) Check(res *core.CachedShard) []*operator.Operator {
 
 	checkerCounter.WithLabelValues("merge_checker", "check").Inc()
 
+	empty := m.checkEmpty(res)
+	if empty {
+		checkerCounter.WithLabelValues("merge_checker", "empty-resource").Inc()
+	}
+
 	// when pd just started, it will load resource meta from etcd
 	// but the size for these loaded resource info is 0
 	// pd don't know the real size of one resource until the first heartbeat of the resource
 	// thus here when size is 0, just skip.
-	if res.GetApproximateSize() == 0 {
+	if !empty && res.GetApproximateSize() == 0 {
 		checkerCounter.WithLabelValues("merge_checker", "skip").Inc()
 		return nil
 	}
 
 	// resource is not small enough
-	if res.GetApproximateSize() > int64(m.opts.GetMaxMergeShardSize()) ||
-		res.GetApproximateKeys() > int64(m.opts.GetMaxMergeShardKeys()) {
+	if !empty && (res.GetApproximateSize() > int64(m.opts.GetMaxMergeShardSize()) ||
+		res.GetApproximateKeys() > int64(m.opts.GetMaxMergeShardKeys())) {
 		checkerCounter.WithLabelValues("merge_checker", "no-need").Inc()
 		return nil
 	}
@@ -128,7 +143,8 @@ func (m *MergeChecker) Check(res *core.CachedShard) []*operator.Operator {
 		return nil
 	}
 
-	if target.GetApproximateSize() > maxTargetShardSize {
+	// merging the empty resource doesn't make the target larger
+	if !empty && target.GetApproximateSize() > maxTargetShardSize {
 		checkerCounter.WithLabelValues("merge_checker", "target-too-large").Inc()
 		return nil
 	}
@@ -151,6 +167,32 @@ func (m *MergeChecker) Check(res *core.CachedShard) []*operator.Operator {
 	return ops
 }
 
+// checkEmpty returns true if the resource has been reporting no more data than
+// the empty shard thresholds in the heartbeats for the empty shard merge window.
+// The resource loaded from the storage without heartbeats is never empty, its
+// size is unknown.
+func (m *MergeChecker) checkEmpty(res *core.CachedShard) bool {
+	window := m.opts.GetEmptyShardMergeWindow()
+	id := res.Meta.GetID()
+	if window == 0 ||
+		res.GetInterval() == nil ||
+		res.GetApproximateSize() > int64(m.opts.GetEmptyShardMaxSize()) ||
+		res.GetApproximateKeys() > int64(m.opts.GetEmptyShardMaxKeys()) {
+		m.emptyCache.Remove(id)
+		return false
+	}
+
+	// the range of the resource is changed by the split or merge
+	now := time.Now()
+	e := emptyShard{since: now, generation: res.Meta.GetEpoch().Generation}
+	if v, ok := m.emptyCache.Get(id); ok && v.(emptyShard).generation == e.generation {
+		e.since = v.(emptyShard).since
+	}
+	// the resources not checked for a while are removed from the cache
+	m.emptyCache.PutWithTTL(id, e, 2*window)
+	return now.Sub(e.since) >= window
+}
+
 func (m *MergeChecker) checkTarget(region, adjacent *core.CachedShard) bool {
 	return adjacent != nil && !m.splitCache.Exists(adjacent.Meta.GetID()) && !m.cluster.IsShardHot(adjacent) &&
 		AllowMerge(m.cluster, region, adjacent) && opt.IsShardHealthy(m.cluster, adjacent) &&
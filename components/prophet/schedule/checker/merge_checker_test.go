@@ -225,6 +225,45 @@ func TestBasic(t *testing.T) {
 	assert.Nil(t, ops)
 }
 
+func TestMergeEmptyShard(t *testing.T) {
+	s := &testMergeChecker{}
+	s.setup()
+	defer s.tearDown()
+
+	s.cluster.SetSplitMergeInterval(0)
+	s.cluster.SetEmptyShardMergeWindow(time.Hour)
+	s.mc = NewMergeChecker(s.ctx, s.cluster)
+
+	// the size is unknown without heartbeats
+	res := s.resources[1].Clone(core.SetApproximateSize(0), core.SetApproximateKeys(0))
+	s.cluster.PutShard(res)
+	assert.Empty(t, s.mc.Check(res))
+	assert.False(t, s.mc.emptyCache.Exists(res.Meta.GetID()))
+
+	// empty in the heartbeats, but not for the window
+	res = res.Clone(core.SetReportInterval(10))
+	s.cluster.PutShard(res)
+	assert.Empty(t, s.mc.Check(res))
+	assert.True(t, s.mc.emptyCache.Exists(res.Meta.GetID()))
+
+	// the target is too large for the normal merge
+	s.cluster.PutShard(s.resources[2].Clone(core.SetApproximateSize(600)))
+	s.mc.emptyCache.Put(res.Meta.GetID(), emptyShard{since: time.Now().Add(-2 * time.Hour)})
+	ops := s.mc.Check(res)
+	assert.NotNil(t, ops)
+	assert.Equal(t, res.Meta.GetID(), ops[0].ShardID())
+	assert.Equal(t, s.resources[2].Meta.GetID(), ops[1].ShardID())
+
+	// the range is changed
+	res = res.Clone(core.WithIncVersion())
+	assert.Empty(t, s.mc.Check(res))
+
+	// not empty any more
+	res = res.Clone(core.SetApproximateKeys(1))
+	assert.Empty(t, s.mc.Check(res))
+	assert.False(t, s.mc.emptyCache.Exists(res.Meta.GetID()))
+}
+
 func TestMatchPeers(t *testing.T) {
 	s := &testMergeChecker{}
 	s.setup()
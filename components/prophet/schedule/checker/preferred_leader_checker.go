@@ -0,0 +1,78 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package checker
+
+import (
+	"github.com/matrixorigin/matrixcube/components/prophet/core"
+	"github.com/matrixorigin/matrixcube/components/prophet/schedule/filter"
+	"github.com/matrixorigin/matrixcube/components/prophet/schedule/operator"
+	"github.com/matrixorigin/matrixcube/components/prophet/schedule/opt"
+	"go.uber.org/zap"
+)
+
+const preferredLeaderCheckerName = "preferred-leader-checker"
+
+// PreferredLeaderChecker moves the leader of the resource to the preferred
+// leader store once the store is healthy.
+type PreferredLeaderChecker struct {
+	cluster opt.Cluster
+	filters []filter.Filter
+}
+
+// NewPreferredLeaderChecker creates a preferred leader checker.
+func NewPreferredLeaderChecker(cluster opt.Cluster) *PreferredLeaderChecker {
+	return &PreferredLeaderChecker{
+		cluster: cluster,
+		filters: []filter.Filter{
+			&filter.StoreStateFilter{ActionScope: preferredLeaderCheckerName, TransferLeader: true},
+		},
+	}
+}
+
+// GetType returns the checker type.
+func (c *PreferredLeaderChecker) GetType() string {
+	return preferredLeaderCheckerName
+}
+
+// Check verifies the leader of the resource is on the preferred leader store,
+// creating a transfer leader operator if need.
+func (c *PreferredLeaderChecker) Check(res *core.CachedShard) *operator.Operator {
+	storeID := c.cluster.GetShardPreferredLeader(res.Meta.GetID())
+	if storeID == 0 || res.GetLeader().GetStoreID() == storeID {
+		return nil
+	}
+
+	checkerCounter.WithLabelValues("preferred_leader_checker", "check").Inc()
+	if _, ok := res.GetStoreVoter(storeID); !ok {
+		checkerCounter.WithLabelValues("preferred_leader_checker", "no-voter").Inc()
+		return nil
+	}
+	store := c.cluster.GetStore(storeID)
+	if store == nil || !filter.Target(c.cluster.GetOpts(), store, c.filters) {
+		checkerCounter.WithLabelValues("preferred_leader_checker", "unhealthy-store").Inc()
+		return nil
+	}
+
+	op, err := operator.CreateTransferLeaderOperator("preferred-leader", c.cluster, res,
+		res.GetLeader().GetStoreID(), storeID, operator.OpLeader)
+	if err != nil {
+		c.cluster.GetLogger().Debug("fail to create transfer leader to preferred store operator",
+			zap.Uint64("resource", res.Meta.GetID()),
+			zap.Uint64("store", storeID),
+			zap.Error(err))
+		return nil
+	}
+	checkerCounter.WithLabelValues("preferred_leader_checker", "new-operator").Inc()
+	return op
+}
@@ -0,0 +1,59 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package checker
+
+import (
+	"testing"
+
+	"github.com/matrixorigin/matrixcube/components/prophet/config"
+	"github.com/matrixorigin/matrixcube/components/prophet/mock/mockcluster"
+	"github.com/matrixorigin/matrixcube/components/prophet/schedule/operator"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPreferredLeaderChecker(t *testing.T) {
+	cluster := mockcluster.NewCluster(config.NewTestOptions())
+	pc := NewPreferredLeaderChecker(cluster)
+	for id := uint64(1); id <= 4; id++ {
+		cluster.AddLeaderStore(id, 1)
+	}
+	res := cluster.AddLeaderShard(1, 1, 2, 3)
+
+	// no preferred leader
+	assert.Nil(t, pc.Check(res))
+
+	// already on the preferred store
+	cluster.SetShardPreferredLeader(1, 1)
+	assert.Nil(t, pc.Check(res))
+
+	// no replica on the preferred store
+	cluster.SetShardPreferredLeader(1, 4)
+	assert.Nil(t, pc.Check(res))
+
+	cluster.SetShardPreferredLeader(1, 2)
+	op := pc.Check(res)
+	assert.NotNil(t, op)
+	assert.Equal(t, "preferred-leader", op.Desc())
+	v, ok := op.Step(0).(operator.TransferLeader)
+	assert.True(t, ok)
+	assert.Equal(t, uint64(1), v.FromStore)
+	assert.Equal(t, uint64(2), v.ToStore)
+
+	// the preferred store is unhealthy
+	cluster.SetStoreDown(2)
+	assert.Nil(t, pc.Check(res))
+
+	cluster.SetShardPreferredLeader(1, 0)
+	assert.Nil(t, pc.Check(res))
+}
@@ -0,0 +1,108 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package checker
+
+import (
+	"github.com/matrixorigin/matrixcube/components/prophet/core"
+	"github.com/matrixorigin/matrixcube/components/prophet/schedule/filter"
+	"github.com/matrixorigin/matrixcube/components/prophet/schedule/operator"
+	"github.com/matrixorigin/matrixcube/components/prophet/schedule/opt"
+	"github.com/matrixorigin/matrixcube/pb/metapb"
+	"go.uber.org/zap"
+)
+
+const (
+	rangeRelocationCheckerName = "range-relocation-checker"
+	// RangeRelocationDesc is the desc of the operators created for the range
+	// relocations.
+	RangeRelocationDesc = "relocate-range"
+)
+
+// RangeRelocationChecker moves the replicas of the resource in the key range of
+// a range relocation to the target stores of the relocation, one replica at a
+// time.
+type RangeRelocationChecker struct {
+	cluster opt.Cluster
+	filters []filter.Filter
+}
+
+// NewRangeRelocationChecker creates a range relocation checker.
+func NewRangeRelocationChecker(cluster opt.Cluster) *RangeRelocationChecker {
+	return &RangeRelocationChecker{
+		cluster: cluster,
+		filters: []filter.Filter{
+			&filter.StoreStateFilter{ActionScope: rangeRelocationCheckerName, MoveShard: true},
+		},
+	}
+}
+
+// GetType returns the checker type.
+func (c *RangeRelocationChecker) GetType() string {
+	return rangeRelocationCheckerName
+}
+
+// Check verifies all the replicas of the resource are on the target stores of
+// its range relocation, creating a move peer operator if need.
+func (c *RangeRelocationChecker) Check(res *core.CachedShard) *operator.Operator {
+	r, ok := c.cluster.GetShardRangeRelocation(res)
+	if !ok {
+		return nil
+	}
+
+	var source *metapb.Replica
+	for _, p := range res.Meta.GetReplicas() {
+		if !core.IsRangeRelocationTarget(r, p.StoreID) {
+			p := p
+			source = &p
+			break
+		}
+	}
+	if source == nil {
+		return nil
+	}
+
+	checkerCounter.WithLabelValues("range_relocation_checker", "check").Inc()
+	var stores []*core.CachedStore
+	for _, id := range r.Stores {
+		if _, ok := res.GetStorePeer(id); ok {
+			continue
+		}
+		if store := c.cluster.GetStore(id); store != nil {
+			stores = append(stores, store)
+		}
+	}
+	target := filter.NewCandidates(stores).
+		FilterTarget(c.cluster.GetOpts(), c.filters...).
+		Sort(filter.ShardScoreComparer(res.GetGroupKey(), c.cluster.GetOpts())).
+		PickFirst()
+	if target == nil {
+		checkerCounter.WithLabelValues("range_relocation_checker", "no-target-store").Inc()
+		return nil
+	}
+
+	newPeer := metapb.Replica{StoreID: target.Meta.GetID(), Role: source.Role}
+	op, err := operator.CreateMovePeerOperator(RangeRelocationDesc, c.cluster, res,
+		operator.OpShard, source.StoreID, newPeer)
+	if err != nil {
+		c.cluster.GetLogger().Debug("fail to create range relocation operator",
+			zap.Uint64("resource", res.Meta.GetID()),
+			zap.Uint64("relocation", r.ID),
+			zap.Uint64("source", source.StoreID),
+			zap.Uint64("target", newPeer.StoreID),
+			zap.Error(err))
+		return nil
+	}
+	checkerCounter.WithLabelValues("range_relocation_checker", "new-operator").Inc()
+	return op
+}
@@ -0,0 +1,68 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package checker
+
+import (
+	"testing"
+
+	"github.com/matrixorigin/matrixcube/components/prophet/config"
+	"github.com/matrixorigin/matrixcube/components/prophet/mock/mockcluster"
+	"github.com/matrixorigin/matrixcube/components/prophet/schedule/operator"
+	"github.com/matrixorigin/matrixcube/components/prophet/util/cache"
+	"github.com/matrixorigin/matrixcube/pb/metapb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRangeRelocationChecker(t *testing.T) {
+	cluster := mockcluster.NewCluster(config.NewTestOptions())
+	rc := NewRangeRelocationChecker(cluster)
+	for id := uint64(1); id <= 5; id++ {
+		cluster.AddLeaderStore(id, 1)
+	}
+	res := cluster.AddLeaderShard(1, 1, 2, 3)
+
+	// not relocated
+	assert.Nil(t, rc.Check(res))
+
+	// other group
+	cluster.PutRangeRelocation(metapb.RangeRelocation{ID: 1, Group: 1, Stores: []uint64{4, 5}})
+	assert.Nil(t, rc.Check(res))
+
+	cluster.PutRangeRelocation(metapb.RangeRelocation{ID: 2, Stores: []uint64{2, 3, 4}})
+	op := rc.Check(res)
+	assert.NotNil(t, op)
+	assert.Equal(t, RangeRelocationDesc, op.Desc())
+	v, ok := op.Step(0).(operator.AddLearner)
+	assert.True(t, ok)
+	assert.Equal(t, uint64(4), v.ToStore)
+
+	// the target store is unhealthy
+	cluster.SetStoreDown(4)
+	assert.Nil(t, rc.Check(res))
+
+	// already relocated
+	cluster.RemoveRangeRelocation(2)
+	cluster.PutRangeRelocation(metapb.RangeRelocation{ID: 3, Stores: []uint64{1, 2, 3}})
+	assert.Nil(t, rc.Check(res))
+
+	// the missing replica is only added to the target stores
+	cluster.RemoveRangeRelocation(3)
+	cluster.PutRangeRelocation(metapb.RangeRelocation{ID: 4, Stores: []uint64{1, 2, 5}})
+	cluster.UpdateShardCount(5, 100)
+	op = NewReplicaChecker(cluster, cache.NewDefaultCache(10)).Check(cluster.AddLeaderShard(2, 1, 2))
+	assert.NotNil(t, op)
+	v, ok = op.Step(0).(operator.AddLearner)
+	assert.True(t, ok)
+	assert.Equal(t, uint64(5), v.ToStore)
+}
@@ -80,7 +80,11 @@ func (r *ReplicaChecker) FillReplicas(res *core.CachedShard, leastPeers int) err
 		}
 
 		peers := res.Meta.GetReplicas()
-		peers = append(peers, metapb.Replica{StoreID: container})
+		peer := metapb.Replica{StoreID: container}
+		if res.Meta.GetDurabilityPolicy() == metapb.DurabilityPolicy_LeaderOnlyVoter && len(peers) > 0 {
+			peer.Role = metapb.ReplicaRole_Learner
+		}
+		peers = append(peers, peer)
 		res.Meta.SetReplicas(peers)
 	}
 
@@ -155,8 +159,9 @@ func (r *ReplicaChecker) checkOfflinePeer(res *core.CachedShard) *operator.Opera
 		return nil
 	}
 
-	// just skip learner
-	if len(res.GetLearners()) != 0 {
+	// just skip learner, the learners of the leader-only shards are never promoted
+	if len(res.GetLearners()) != 0 &&
+		res.Meta.GetDurabilityPolicy() != metapb.DurabilityPolicy_LeaderOnlyVoter {
 		return nil
 	}
 
@@ -201,7 +206,7 @@ func (r *ReplicaChecker) checkMakeUpReplica(res *core.CachedShard) *operator.Ope
 		r.resourceWaitingList.Put(res.Meta.GetID(), nil)
 		return nil
 	}
-	newPeer := metapb.Replica{StoreID: target}
+	newPeer := metapb.Replica{StoreID: target, Role: r.newPeerRole(res)}
 	op, err := operator.CreateAddPeerOperator("make-up-replica", r.cluster, res, newPeer, operator.OpReplica)
 	if err != nil {
 		r.cluster.GetLogger().Debug("fail to create make-up-replica operator",
@@ -296,6 +301,17 @@ func (r *ReplicaChecker) fixPeer(res *core.CachedShard, containerID uint64, stat
 		return nil
 	}
 	newPeer := metapb.Replica{StoreID: target}
+	if res.Meta.GetDurabilityPolicy() == metapb.DurabilityPolicy_LeaderOnlyVoter {
+		// keep the replaced replica's role, so a lost learner is not replaced by
+		// a voter, and a voter is replaced by a learner if a learner has been
+		// promoted to take over it
+		if old, ok := res.GetStorePeer(containerID); ok {
+			newPeer.Role = old.Role
+			if len(res.GetVoters()) > 1 {
+				newPeer.Role = metapb.ReplicaRole_Learner
+			}
+		}
+	}
 	replace := fmt.Sprintf("replace-%s-replica", status)
 	op, err := operator.CreateMovePeerOperator(replace, r.cluster, res, operator.OpReplica, containerID, newPeer)
 	if err != nil {
@@ -306,6 +322,16 @@ func (r *ReplicaChecker) fixPeer(res *core.CachedShard, containerID uint64, stat
 	return op
 }
 
+// newPeerRole returns the role of the new replica of the shard. The leader-only
+// shard has only one voter, the other replicas are learners.
+func (r *ReplicaChecker) newPeerRole(res *core.CachedShard) metapb.ReplicaRole {
+	if res.Meta.GetDurabilityPolicy() == metapb.DurabilityPolicy_LeaderOnlyVoter &&
+		len(res.GetVoters()) > 0 {
+		return metapb.ReplicaRole_Learner
+	}
+	return metapb.ReplicaRole_Voter
+}
+
 func (r *ReplicaChecker) strategy(res *core.CachedShard) *ReplicaStrategy {
 	return &ReplicaStrategy{
 		checkerName:    replicaCheckerName,
@@ -205,6 +205,63 @@ func TestFillReplicas(t *testing.T) {
 	assert.Equal(t, rc.cluster.GetOpts().GetMaxReplicas(), len(res.Meta.GetReplicas()))
 }
 
+func TestLeaderOnlyReplicas(t *testing.T) {
+	opt := config.NewTestOptions()
+	tc := mockcluster.NewCluster(opt)
+	tc.SetMaxReplicas(2)
+	rc := NewReplicaChecker(tc, cache.NewDefaultCache(10))
+	tc.AddShardStore(1, 1)
+	tc.AddShardStore(2, 1)
+	tc.AddShardStore(3, 1)
+
+	// only the first replica is the voter
+	res := core.NewTestCachedShard(nil, nil)
+	res.Meta.DurabilityPolicy = metapb.DurabilityPolicy_LeaderOnlyVoter
+	assert.NoError(t, rc.FillReplicas(res, 0))
+	assert.Equal(t, 2, len(res.Meta.GetReplicas()))
+	assert.Equal(t, metapb.ReplicaRole_Voter, res.Meta.GetReplicas()[0].Role)
+	assert.Equal(t, metapb.ReplicaRole_Learner, res.Meta.GetReplicas()[1].Role)
+
+	// the lost learner is made up by a learner
+	peers := []metapb.Replica{{ID: 4, StoreID: 1}}
+	r := core.NewCachedShard(metapb.Shard{ID: 2, Replicas: peers,
+		DurabilityPolicy: metapb.DurabilityPolicy_LeaderOnlyVoter}, &peers[0])
+	tc.PutShard(r)
+	op := rc.Check(r)
+	assert.NotNil(t, op)
+	assert.Equal(t, "make-up-replica", op.Desc())
+	assert.Equal(t, 1, op.Len())
+	_, ok := op.Step(0).(operator.AddLearner)
+	assert.True(t, ok)
+
+	// the offline learner is replaced by a learner
+	peers = []metapb.Replica{{ID: 4, StoreID: 1}, {ID: 5, StoreID: 2, Role: metapb.ReplicaRole_Learner}}
+	r = core.NewCachedShard(metapb.Shard{ID: 2, Replicas: peers,
+		DurabilityPolicy: metapb.DurabilityPolicy_LeaderOnlyVoter}, &peers[0])
+	tc.PutShard(r)
+	tc.SetStoreOffline(2)
+	op = rc.Check(r)
+	assert.NotNil(t, op)
+	assert.Equal(t, "replace-offline-replica", op.Desc())
+	assert.Equal(t, uint64(3), op.Step(0).(operator.AddLearner).ToStore)
+	assert.Equal(t, uint64(2), op.Step(1).(operator.RemovePeer).FromStore)
+	assert.Equal(t, 2, op.Len())
+
+	// the offline voter is replaced by a learner once a learner is promoted
+	tc.SetStoreUP(2)
+	tc.AddShardStore(4, 1)
+	peers = []metapb.Replica{{ID: 4, StoreID: 1}, {ID: 5, StoreID: 2}}
+	r = core.NewCachedShard(metapb.Shard{ID: 2, Replicas: peers,
+		DurabilityPolicy: metapb.DurabilityPolicy_LeaderOnlyVoter}, &peers[1])
+	tc.PutShard(r)
+	tc.SetStoreOffline(1)
+	op = rc.Check(r)
+	assert.NotNil(t, op)
+	assert.Equal(t, "replace-offline-replica", op.Desc())
+	assert.Equal(t, uint64(1), op.Step(0).(operator.RemovePeer).FromStore)
+	assert.Equal(t, uint64(4), op.Step(1).(operator.AddLearner).ToStore)
+}
+
 func TestDownPeer(t *testing.T) {
 	s := &testReplicaChecker{}
 	s.setup()
@@ -66,6 +66,9 @@ func (s *ReplicaStrategy) SelectStoreToAdd(coLocationStores []*core.CachedStore,
 	if len(s.extraFilters) > 0 {
 		filters = append(filters, s.extraFilters...)
 	}
+	if relocationFilter := filter.NewRangeRelocationFilter(s.checkerName, s.cluster, s.resource); relocationFilter != nil {
+		filters = append(filters, relocationFilter)
+	}
 
 	isolationComparer := filter.IsolationComparer(s.locationLabels, coLocationStores)
 	strictStateFilter := &filter.StoreStateFilter{ActionScope: s.checkerName, MoveShard: true}
@@ -76,7 +76,7 @@ func (c *RuleChecker) FillReplicas(res *core.CachedShard, leastPeers int) error
 				break
 			}
 
-			p := metapb.Replica{StoreID: container}
+			p := metapb.Replica{StoreID: container, IsWitness: rf.Rule.IsWitness}
 			switch rf.Rule.Role {
 			case placement.Voter, placement.Follower, placement.Leader:
 				p.Role = metapb.ReplicaRole_Voter
@@ -197,7 +197,7 @@ func (c *RuleChecker) addRulePeer(res *core.CachedShard, rf *placement.RuleFit)
 		c.resourceWaitingList.Put(res.Meta.GetID(), nil)
 		return nil, errors.New("no container to add peer")
 	}
-	peer := metapb.Replica{StoreID: container, Role: rf.Rule.Role.MetaPeerRole()}
+	peer := metapb.Replica{StoreID: container, Role: rf.Rule.Role.MetaPeerRole(), IsWitness: rf.Rule.IsWitness}
 	return operator.CreateAddPeerOperator("add-rule-peer", c.cluster, res, peer, operator.OpReplica)
 }
 
@@ -216,7 +216,7 @@ func (c *RuleChecker) replaceRulePeer(res *core.CachedShard, rf *placement.RuleF
 		c.resourceWaitingList.Put(res.Meta.GetID(), nil)
 		return nil, errors.New("no container to replace peer")
 	}
-	newPeer := metapb.Replica{StoreID: container, Role: rf.Rule.Role.MetaPeerRole()}
+	newPeer := metapb.Replica{StoreID: container, Role: rf.Rule.Role.MetaPeerRole(), IsWitness: rf.Rule.IsWitness}
 	return operator.CreateMovePeerOperator("replace-rule-"+status+"-peer",
 		c.cluster, res, operator.OpReplica, peer.StoreID, newPeer)
 }
@@ -239,7 +239,7 @@ func (c *RuleChecker) fixLooseMatchPeer(res *core.CachedShard, fit *placement.Sh
 		checkerCounter.WithLabelValues("rule_checker", "not-allow-leader")
 		return nil, errors.New("peer cannot be leader")
 	}
-	if res.GetLeader().GetID() == peer.GetID() && rf.Rule.Role == placement.Follower {
+	if res.GetLeader().GetID() == peer.GetID() && (rf.Rule.Role == placement.Follower || rf.Rule.IsWitness) {
 		checkerCounter.WithLabelValues("rule_checker", "fix-follower-role").Inc()
 		for _, p := range res.Meta.GetReplicas() {
 			if c.allowLeader(fit, p) {
@@ -250,11 +250,15 @@ func (c *RuleChecker) fixLooseMatchPeer(res *core.CachedShard, fit *placement.Sh
 		checkerCounter.WithLabelValues("rule_checker", "no-new-leader").Inc()
 		return nil, errors.New("no new leader")
 	}
+	if !peer.IsWitness && rf.Rule.IsWitness {
+		checkerCounter.WithLabelValues("rule_checker", "fix-witness-role").Inc()
+		return operator.CreateBecomeWitnessOperator("fix-witness-role", c.cluster, res, peer)
+	}
 	return nil, nil
 }
 
 func (c *RuleChecker) allowLeader(fit *placement.ShardFit, peer metapb.Replica) bool {
-	if metadata.IsLearner(peer) {
+	if metadata.IsLearner(peer) || peer.IsWitness {
 		return false
 	}
 	s := c.cluster.GetStore(peer.StoreID)
@@ -296,7 +300,7 @@ func (c *RuleChecker) fixBetterLocation(res *core.CachedShard, rf *placement.Rul
 		return nil, nil
 	}
 	checkerCounter.WithLabelValues("rule_checker", "move-to-better-location").Inc()
-	newPeer := metapb.Replica{StoreID: newStore, Role: rf.Rule.Role.MetaPeerRole()}
+	newPeer := metapb.Replica{StoreID: newStore, Role: rf.Rule.Role.MetaPeerRole(), IsWitness: rf.Rule.IsWitness}
 	return operator.CreateMovePeerOperator("move-to-better-location", c.cluster, res, operator.OpReplica, oldStore, newPeer)
 }
 
@@ -564,3 +564,66 @@ func TestIssue3299(t *testing.T) {
 		}
 	}
 }
+
+func TestFixWitness(t *testing.T) {
+	s := &testRuleChecker{}
+	s.setup()
+
+	s.cluster.AddLabelsStore(1, 1, map[string]string{"role": "full"})
+	s.cluster.AddLabelsStore(2, 1, map[string]string{"role": "full"})
+	s.cluster.AddLabelsStore(3, 1, map[string]string{"role": "witness"})
+	s.cluster.AddLeaderShardWithRange(1, "", "", 1, 2, 3)
+	s.ruleManager.SetRule(&placement.Rule{
+		GroupID:  "prophet",
+		ID:       "default",
+		Override: true,
+		Role:     placement.Voter,
+		Count:    2,
+		LabelConstraints: []placement.LabelConstraint{
+			{Key: "role", Op: "in", Values: []string{"full"}},
+		},
+	})
+	s.ruleManager.SetRule(&placement.Rule{
+		GroupID:   "prophet",
+		ID:        "witness",
+		Index:     100,
+		Role:      placement.Follower,
+		Count:     1,
+		IsWitness: true,
+		LabelConstraints: []placement.LabelConstraint{
+			{Key: "role", Op: "in", Values: []string{"witness"}},
+		},
+	})
+	op := s.rc.Check(s.cluster.GetShard(1))
+	assert.NotNil(t, op)
+	assert.Equal(t, "fix-witness-role", op.Desc())
+	assert.Equal(t, uint64(3), op.Step(0).(operator.BecomeWitness).ToStore)
+
+	// the witness matches the rule
+	r := s.cluster.GetShard(1)
+	p, _ := r.GetStorePeer(3)
+	p.IsWitness = true
+	r = r.Clone(core.WithRemoveStorePeer(3), core.WithAddPeer(p))
+	s.cluster.PutShard(r)
+	assert.Nil(t, s.rc.Check(r))
+
+	// the witness is replaced by a full replica
+	s.cluster.AddLabelsStore(4, 1, map[string]string{"role": "full"})
+	s.ruleManager.DeleteRule("prophet", "witness")
+	s.ruleManager.SetRule(&placement.Rule{
+		GroupID:  "prophet",
+		ID:       "default",
+		Override: true,
+		Role:     placement.Voter,
+		Count:    3,
+		LabelConstraints: []placement.LabelConstraint{
+			{Key: "role", Op: "in", Values: []string{"full"}},
+		},
+	})
+	op = s.rc.Check(r)
+	assert.NotNil(t, op)
+	assert.Equal(t, "add-rule-peer", op.Desc())
+	add := op.Step(0).(operator.AddLearner)
+	assert.Equal(t, uint64(4), add.ToStore)
+	assert.False(t, add.IsWitness)
+}
@@ -39,6 +39,9 @@ type CheckerController struct {
 	ruleChecker         *checker.RuleChecker
 	mergeChecker        *checker.MergeChecker
 	jointStateChecker   *checker.JointStateChecker
+	preferLeaderChecker *checker.PreferredLeaderChecker
+	priorityChecker     *checker.LeaderPriorityChecker
+	relocationChecker   *checker.RangeRelocationChecker
 	resourceWaitingList cache.Cache
 }
 
@@ -55,6 +58,9 @@ func NewCheckerController(ctx context.Context, cluster opt.Cluster, ruleManager
 		ruleChecker:         checker.NewRuleChecker(cluster, ruleManager, resourceWaitingList),
 		mergeChecker:        checker.NewMergeChecker(ctx, cluster),
 		jointStateChecker:   checker.NewJointStateChecker(cluster),
+		preferLeaderChecker: checker.NewPreferredLeaderChecker(cluster),
+		priorityChecker:     checker.NewLeaderPriorityChecker(cluster),
+		relocationChecker:   checker.NewRangeRelocationChecker(cluster),
 		resourceWaitingList: resourceWaitingList,
 	}
 }
@@ -99,6 +105,28 @@ func (c *CheckerController) CheckShard(res *core.CachedShard) []*operator.Operat
 		}
 	}
 
+	if op := c.relocationChecker.Check(res); op != nil {
+		if opController.OperatorCount(operator.OpShard) < c.opts.GetShardScheduleLimit() {
+			return []*operator.Operator{op}
+		}
+		operator.OperatorLimitCounter.WithLabelValues(c.relocationChecker.GetType(), operator.OpShard.String()).Inc()
+		c.resourceWaitingList.Put(res.Meta.GetID(), nil)
+	}
+
+	if op := c.preferLeaderChecker.Check(res); op != nil {
+		if opController.OperatorCount(operator.OpLeader) < c.opts.GetLeaderScheduleLimit() {
+			return []*operator.Operator{op}
+		}
+		operator.OperatorLimitCounter.WithLabelValues(c.preferLeaderChecker.GetType(), operator.OpLeader.String()).Inc()
+	}
+
+	if op := c.priorityChecker.Check(res); op != nil {
+		if opController.OperatorCount(operator.OpLeader) < c.opts.GetLeaderScheduleLimit() {
+			return []*operator.Operator{op}
+		}
+		operator.OperatorLimitCounter.WithLabelValues(c.priorityChecker.GetType(), operator.OpLeader.String()).Inc()
+	}
+
 	if c.mergeChecker != nil && opController.OperatorCount(operator.OpMerge) < c.opts.GetMergeScheduleLimit() {
 		allowed := opController.OperatorCount(operator.OpMerge) < c.opts.GetMergeScheduleLimit()
 		if !allowed {
@@ -339,6 +339,11 @@ func (f *StoreStateFilter) isOffline(opt *config.PersistOptions, container *core
 	return container.IsOffline()
 }
 
+func (f *StoreStateFilter) isMaintenance(opt *config.PersistOptions, container *core.CachedStore) bool {
+	f.Reason = "maintenance"
+	return container.IsMaintenance()
+}
+
 func (f *StoreStateFilter) pauseLeaderTransfer(opt *config.PersistOptions, container *core.CachedStore) bool {
 	f.Reason = "pause-leader"
 	return !container.AllowLeaderTransfer()
@@ -388,13 +393,13 @@ func (f *StoreStateFilter) hasRejectLeaderProperty(opts *config.PersistOptions,
 // N: the condition is expected to be true for a long time.
 // X means when the condition is true, the container CANNOT be selected.
 //
-// Condition      Down Offline Tomb Pause Disconn Busy RmLimit AddLimit Snap Pending Reject
-// IsTemporary    N    N       N    N     Y       Y    Y       Y        Y    Y       N
+// Condition      Down Offline Tomb Pause Disconn Busy RmLimit AddLimit Snap Pending Reject Maint
+// IsTemporary    N    N       N    N     Y       Y    Y       Y        Y    Y       N      N
 //
 // LeaderSource   X            X    X     X
 // ShardSource                                  X    X                X
-// LeaderTarget   X    X       X    X     X       X                                  X
-// ShardTarget X    X       X          X       X            X        X    X
+// LeaderTarget   X    X       X    X     X       X                                  X      X
+// ShardTarget X    X       X          X       X            X        X    X              X
 
 const (
 	leaderSource = iota
@@ -413,12 +418,13 @@ func (f *StoreStateFilter) anyConditionMatch(typ int, opt *config.PersistOptions
 		funcs = []conditionFunc{f.isBusy, f.exceedRemoveLimit, f.tooManySnapshots}
 	case leaderTarget:
 		funcs = []conditionFunc{f.isTombstone, f.isOffline, f.isDown, f.pauseLeaderTransfer,
-			f.isDisconnected, f.isBusy, f.hasRejectLeaderProperty}
+			f.isDisconnected, f.isBusy, f.hasRejectLeaderProperty, f.isMaintenance}
 	case resourceTarget:
 		funcs = []conditionFunc{f.isTombstone, f.isOffline, f.isDown, f.isDisconnected, f.isBusy,
-			f.exceedAddLimit, f.tooManySnapshots, f.tooManyPendingPeers}
+			f.exceedAddLimit, f.tooManySnapshots, f.tooManyPendingPeers, f.isMaintenance}
 	case scatterShardTarget:
-		funcs = []conditionFunc{f.isTombstone, f.isOffline, f.isDown, f.isDisconnected, f.isBusy}
+		funcs = []conditionFunc{f.isTombstone, f.isOffline, f.isDown, f.isDisconnected, f.isBusy,
+			f.isMaintenance}
 
 	}
 	for _, cf := range funcs {
@@ -607,6 +613,83 @@ func NewPlacementLeaderSafeguard(scope string, cluster opt.Cluster, res *core.Ca
 	return nil
 }
 
+type leaderPriorityFilter struct {
+	scope          string
+	priorities     *opt.LeaderPriorities
+	sourceStoreID  uint64
+	sourcePriority int
+}
+
+// NewLeaderPriorityFilter creates a filter that ensures the leader is not
+// transferred to a replica with a lower leader priority than the source. It
+// returns nil if the replica on the source store has the lowest priority.
+func NewLeaderPriorityFilter(scope string, cluster opt.Cluster, res *core.CachedShard, sourceStore *core.CachedStore) Filter {
+	priorities := opt.NewLeaderPriorities(cluster, res)
+	sourcePriority := priorities.Get(sourceStore)
+	if sourcePriority == 0 {
+		return nil
+	}
+	return &leaderPriorityFilter{
+		scope:          scope,
+		priorities:     priorities,
+		sourceStoreID:  sourceStore.Meta.GetID(),
+		sourcePriority: sourcePriority,
+	}
+}
+
+func (f *leaderPriorityFilter) Scope() string {
+	return f.scope
+}
+
+func (f *leaderPriorityFilter) Type() string {
+	return "leader-priority-filter"
+}
+
+func (f *leaderPriorityFilter) Source(opt *config.PersistOptions, container *core.CachedStore) bool {
+	return true
+}
+
+func (f *leaderPriorityFilter) Target(opt *config.PersistOptions, container *core.CachedStore) bool {
+	return f.priorities.Get(container) >= f.sourcePriority
+}
+
+// GetSourceStoreID implements the ComparingFilter
+func (f *leaderPriorityFilter) GetSourceStoreID() uint64 {
+	return f.sourceStoreID
+}
+
+type rangeRelocationFilter struct {
+	scope      string
+	relocation metapb.RangeRelocation
+}
+
+// NewRangeRelocationFilter creates a filter that ensures the replicas of the
+// resource under a range relocation are only moved to the target stores of the
+// relocation. It returns nil if the resource is not relocated.
+func NewRangeRelocationFilter(scope string, cluster opt.Cluster, res *core.CachedShard) Filter {
+	r, ok := cluster.GetShardRangeRelocation(res)
+	if !ok {
+		return nil
+	}
+	return &rangeRelocationFilter{scope: scope, relocation: r}
+}
+
+func (f *rangeRelocationFilter) Scope() string {
+	return f.scope
+}
+
+func (f *rangeRelocationFilter) Type() string {
+	return "range-relocation-filter"
+}
+
+func (f *rangeRelocationFilter) Source(opt *config.PersistOptions, container *core.CachedStore) bool {
+	return true
+}
+
+func (f *rangeRelocationFilter) Target(opt *config.PersistOptions, container *core.CachedStore) bool {
+	return core.IsRangeRelocationTarget(f.relocation, container.Meta.GetID())
+}
+
 type engineFilter struct {
 	scope      string
 	constraint placement.LabelConstraint
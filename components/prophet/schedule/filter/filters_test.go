@@ -175,6 +175,40 @@ func TestStoreStateFilter(t *testing.T) {
 		{3, true, true},
 	}
 	check(container, testCases)
+
+	// Too many snapshots, the stores report the real snapshot counts, which are
+	// limited by the max-snapshot-count
+	container = container.Clone(core.SetStoreStats(&metapb.StoreStats{
+		SendingSnapCount: opt.GetMaxSnapshotCount()}))
+	testCases = []testCase{
+		{1, true, true},
+	}
+	check(container, testCases)
+	container = container.Clone(core.SetStoreStats(&metapb.StoreStats{
+		SendingSnapCount: opt.GetMaxSnapshotCount() + 1}))
+	testCases = []testCase{
+		{0, true, true},
+		{1, false, false},
+		{3, true, true},
+	}
+	check(container, testCases)
+	container = container.Clone(core.SetStoreStats(&metapb.StoreStats{
+		ReceivingSnapCount: opt.GetMaxSnapshotCount() + 1}))
+	testCases = []testCase{
+		{1, false, false},
+	}
+	check(container, testCases)
+
+	// Maintenance
+	container = container.Clone(core.SetStoreStats(&metapb.StoreStats{})).
+		Clone(core.SetStoreMaintenance(true))
+	testCases = []testCase{
+		{0, true, false},
+		{1, true, false},
+		{2, true, false},
+		{3, true, false},
+	}
+	check(container, testCases)
 }
 
 func TestIsolationFilter(t *testing.T) {
@@ -271,3 +305,21 @@ func TestPlacementGuard(t *testing.T) {
 	expected = reflect.ValueOf(newRuleFitFilter("", testCluster, resource, 1))
 	assert.True(t, obtained.Type().AssignableTo(expected.Type()))
 }
+
+func TestLeaderPriorityFilter(t *testing.T) {
+	testCluster := mockcluster.NewCluster(config.NewTestOptions())
+	testCluster.AddLabelsStore(1, 1, map[string]string{"disk": "hdd"})
+	testCluster.AddLabelsStore(2, 1, map[string]string{"disk": "ssd"})
+	testCluster.AddLabelsStore(3, 1, map[string]string{"disk": "ssd"})
+	resource := testCluster.AddLeaderShard(1, 2, 1, 3)
+
+	// the source has the lowest priority
+	assert.Nil(t, NewLeaderPriorityFilter("", testCluster, resource, testCluster.GetStore(2)))
+
+	testCluster.SetLeaderPriorities([]config.LeaderPriority{{Key: "disk", Value: "ssd", Priority: 10}})
+	assert.Nil(t, NewLeaderPriorityFilter("", testCluster, resource, testCluster.GetStore(1)))
+	f := NewLeaderPriorityFilter("", testCluster, resource, testCluster.GetStore(2))
+	assert.NotNil(t, f)
+	assert.False(t, f.Target(testCluster.GetOpts(), testCluster.GetStore(1)))
+	assert.True(t, f.Target(testCluster.GetOpts(), testCluster.GetStore(3)))
+}
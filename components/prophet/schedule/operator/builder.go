@@ -61,6 +61,7 @@ type Builder struct {
 	useJointConsensus bool
 	lightWeight       bool
 	forceTargetLeader bool
+	learnerCatchUp    bool
 
 	// intermediate states
 	currentPeers                         peersMap
@@ -151,6 +152,7 @@ func NewBuilder(desc string, cluster opt.Cluster, res *core.CachedShard, opts ..
 	b.targetPeers = originPeers.Copy()
 	b.allowDemote = cluster.JointConsensusEnabled()
 	b.useJointConsensus = cluster.JointConsensusEnabled() && cluster.GetOpts().IsUseJointConsensus()
+	b.learnerCatchUp = cluster.GetOpts().IsLearnerCatchUpEnabled()
 	b.err = err
 	return b
 }
@@ -201,9 +203,10 @@ func (b *Builder) PromoteLearner(containerID uint64) *Builder {
 		b.err = fmt.Errorf("cannot promote peer %d: unhealthy", containerID)
 	} else {
 		b.targetPeers.Set(metapb.Replica{
-			ID:      peer.ID,
-			StoreID: peer.StoreID,
-			Role:    metapb.ReplicaRole_Voter,
+			ID:        peer.ID,
+			StoreID:   peer.StoreID,
+			Role:      metapb.ReplicaRole_Voter,
+			IsWitness: peer.IsWitness,
 		})
 	}
 	return b
@@ -220,9 +223,10 @@ func (b *Builder) DemoteVoter(containerID uint64) *Builder {
 		b.err = fmt.Errorf("cannot demote voter %d: is already learner", containerID)
 	} else {
 		b.targetPeers.Set(metapb.Replica{
-			ID:      peer.ID,
-			StoreID: peer.StoreID,
-			Role:    metapb.ReplicaRole_Learner,
+			ID:        peer.ID,
+			StoreID:   peer.StoreID,
+			Role:      metapb.ReplicaRole_Learner,
+			IsWitness: peer.IsWitness,
 		})
 	}
 	return b
@@ -237,6 +241,8 @@ func (b *Builder) SetLeader(containerID uint64) *Builder {
 		b.err = fmt.Errorf("cannot transfer leader to %d: not found", containerID)
 	} else if metadata.IsLearner(peer) {
 		b.err = fmt.Errorf("cannot transfer leader to %d: not voter", containerID)
+	} else if peer.IsWitness {
+		b.err = fmt.Errorf("cannot transfer leader to %d: witness", containerID)
 	} else if _, ok := b.unhealthyPeers[containerID]; ok {
 		b.err = fmt.Errorf("cannot transfer leader to %d: unhealthy", containerID)
 	} else {
@@ -364,13 +370,20 @@ func (b *Builder) prepareBuild() (string, error) {
 			continue
 		}
 
+		// The witness can not be changed by the builder, keep it as the origin.
+		if o.IsWitness != n.IsWitness {
+			n.IsWitness = o.IsWitness
+			b.targetPeers.Set(n)
+		}
+
 		// If the peer id in the target is different from that in the origin,
 		// modify it to the peer id of the origin.
 		if o.ID != n.ID {
 			n = metapb.Replica{
-				ID:      o.ID,
-				StoreID: o.StoreID,
-				Role:    n.Role,
+				ID:        o.ID,
+				StoreID:   o.StoreID,
+				Role:      n.Role,
+				IsWitness: n.IsWitness,
 			}
 		}
 
@@ -402,9 +415,10 @@ func (b *Builder) prepareBuild() (string, error) {
 					return "", err
 				}
 				n = metapb.Replica{
-					ID:      id,
-					StoreID: n.StoreID,
-					Role:    n.Role,
+					ID:        id,
+					StoreID:   n.StoreID,
+					Role:      n.Role,
+					IsWitness: n.IsWitness,
 				}
 			}
 			// It is a pair with `b.toRemove.Set(o)` when `o != nil`.
@@ -431,6 +445,12 @@ func (b *Builder) prepareBuild() (string, error) {
 		b.useJointConsensus = false
 	}
 
+	// Only catch up the learners when moving replicas, the fault tolerance is
+	// reduced in the window between promoting the target and removing the source.
+	if len(b.toRemove) == 0 || b.lightWeight {
+		b.learnerCatchUp = false
+	}
+
 	b.peerAddStep = make(map[uint64]int)
 
 	return b.brief(), nil
@@ -467,9 +487,10 @@ func (b *Builder) buildStepsWithJointConsensus(kind OpKind) (OpKind, error) {
 		peer := b.toAdd[add]
 		if !metadata.IsLearner(peer) {
 			b.execAddPeer(metapb.Replica{
-				ID:      peer.ID,
-				StoreID: peer.StoreID,
-				Role:    metapb.ReplicaRole_Learner,
+				ID:        peer.ID,
+				StoreID:   peer.StoreID,
+				Role:      metapb.ReplicaRole_Learner,
+				IsWitness: peer.IsWitness,
 			})
 			b.toPromote.Set(peer)
 		} else {
@@ -477,6 +498,13 @@ func (b *Builder) buildStepsWithJointConsensus(kind OpKind) (OpKind, error) {
 		}
 		kind |= OpShard
 	}
+	if b.learnerCatchUp {
+		for _, p := range b.toPromote.IDs() {
+			if _, ok := b.originPeers[b.toPromote[p].StoreID]; !ok {
+				b.execCatchUpLearner(b.toPromote[p])
+			}
+		}
+	}
 
 	b.setTargetLeaderIfNotExist()
 	if b.targetLeaderStoreID == 0 {
@@ -488,9 +516,10 @@ func (b *Builder) buildStepsWithJointConsensus(kind OpKind) (OpKind, error) {
 		peer := b.toRemove[remove]
 		if !metadata.IsLearner(peer) {
 			b.toDemote.Set(metapb.Replica{
-				ID:      peer.ID,
-				StoreID: peer.StoreID,
-				Role:    metapb.ReplicaRole_Learner,
+				ID:        peer.ID,
+				StoreID:   peer.StoreID,
+				Role:      metapb.ReplicaRole_Learner,
+				IsWitness: peer.IsWitness,
 			})
 		}
 	}
@@ -569,7 +598,7 @@ func (b *Builder) preferLeaderRoleAsLeader(targetLeaderStoreID uint64) int {
 
 func (b *Builder) preferUPStoreAsLeader(targetLeaderStoreID uint64) int {
 	container := b.cluster.GetStore(targetLeaderStoreID)
-	return typeutil.BoolToInt(container != nil && container.IsUp())
+	return typeutil.BoolToInt(container != nil && container.IsUp() && !container.IsMaintenance())
 }
 
 func (b *Builder) preferCurrentLeader(targetLeaderStoreID uint64) int {
@@ -653,11 +682,14 @@ func (b *Builder) execDemoteFollower(peer metapb.Replica) {
 
 func (b *Builder) execAddPeer(peer metapb.Replica) {
 	if b.lightWeight {
-		b.steps = append(b.steps, AddLightLearner{ToStore: peer.StoreID, PeerID: peer.ID})
+		b.steps = append(b.steps, AddLightLearner{ToStore: peer.StoreID, PeerID: peer.ID, IsWitness: peer.IsWitness})
 	} else {
-		b.steps = append(b.steps, AddLearner{ToStore: peer.StoreID, PeerID: peer.ID})
+		b.steps = append(b.steps, AddLearner{ToStore: peer.StoreID, PeerID: peer.ID, IsWitness: peer.IsWitness})
 	}
 	if !metadata.IsLearner(peer) {
+		if b.learnerCatchUp {
+			b.execCatchUpLearner(peer)
+		}
 		b.steps = append(b.steps, PromoteLearner{ToStore: peer.StoreID, PeerID: peer.ID})
 	}
 	b.currentPeers.Set(peer)
@@ -665,6 +697,10 @@ func (b *Builder) execAddPeer(peer metapb.Replica) {
 	delete(b.toAdd, peer.StoreID)
 }
 
+func (b *Builder) execCatchUpLearner(peer metapb.Replica) {
+	b.steps = append(b.steps, CatchUpLearner{ToStore: peer.StoreID, PeerID: peer.ID})
+}
+
 func (b *Builder) execRemovePeer(peer metapb.Replica) {
 	b.steps = append(b.steps, RemovePeer{FromStore: peer.StoreID, PeerID: peer.ID})
 	delete(b.currentPeers, peer.StoreID)
@@ -706,6 +742,9 @@ func (b *Builder) execChangePeerV2(needEnter bool, needTransferLeader bool) {
 // check if the peer is allowed to become the leader.
 func (b *Builder) allowLeader(peer metapb.Replica, ignoreClusterLimit bool) bool {
 	// these peer roles are not allowed to become leader.
+	if peer.IsWitness {
+		return false
+	}
 	switch peer.Role {
 	case metapb.ReplicaRole_Learner, metapb.ReplicaRole_DemotingVoter:
 		return false
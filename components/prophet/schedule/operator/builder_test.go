@@ -477,6 +477,54 @@ func TestBuild(t *testing.T) {
 	}
 }
 
+func TestBuildWithLearnerCatchUp(t *testing.T) {
+	s := &testBuilder{}
+	s.setup()
+	s.cluster.SetEnableLearnerCatchUp(true)
+
+	originPeers := []metapb.Replica{{ID: 1, StoreID: 1}, {ID: 2, StoreID: 2}, {ID: 3, StoreID: 3}}
+	resource := core.NewCachedShard(metapb.Shard{ID: 1, Replicas: originPeers}, &originPeers[0])
+	build := func(useJointConsensus bool, targetPeers ...metapb.Replica) *Operator {
+		builder := NewBuilder("test", s.cluster, resource)
+		builder.allowDemote = useJointConsensus
+		builder.useJointConsensus = useJointConsensus
+		m := make(map[uint64]metapb.Replica)
+		for _, p := range targetPeers {
+			m[p.GetStoreID()] = p
+		}
+		op, err := builder.SetPeers(m).SetLeader(1).Build(0)
+		assert.NoError(t, err)
+		return op
+	}
+
+	// move peer
+	op := build(false, metapb.Replica{ID: 1, StoreID: 1}, metapb.Replica{ID: 2, StoreID: 2},
+		metapb.Replica{ID: 4, StoreID: 4})
+	assert.Equal(t, 4, op.Len())
+	assert.Equal(t, uint64(4), op.Step(0).(AddLearner).ToStore)
+	assert.Equal(t, CatchUpLearner{ToStore: 4, PeerID: 4}, op.Step(1))
+	assert.Equal(t, uint64(4), op.Step(2).(PromoteLearner).ToStore)
+	assert.Equal(t, uint64(3), op.Step(3).(RemovePeer).FromStore)
+
+	// move peers with joint consensus
+	op = build(true, metapb.Replica{ID: 1, StoreID: 1}, metapb.Replica{ID: 4, StoreID: 4},
+		metapb.Replica{ID: 5, StoreID: 5})
+	assert.Equal(t, 8, op.Len())
+	assert.Equal(t, uint64(4), op.Step(0).(AddLearner).ToStore)
+	assert.Equal(t, uint64(5), op.Step(1).(AddLearner).ToStore)
+	assert.Equal(t, CatchUpLearner{ToStore: 4, PeerID: 4}, op.Step(2))
+	assert.Equal(t, CatchUpLearner{ToStore: 5, PeerID: 5}, op.Step(3))
+	_, ok := op.Step(4).(ChangePeerV2Enter)
+	assert.True(t, ok)
+
+	// add peer only
+	op = build(false, metapb.Replica{ID: 1, StoreID: 1}, metapb.Replica{ID: 2, StoreID: 2},
+		metapb.Replica{ID: 3, StoreID: 3}, metapb.Replica{ID: 4, StoreID: 4})
+	assert.Equal(t, 2, op.Len())
+	assert.Equal(t, uint64(4), op.Step(0).(AddLearner).ToStore)
+	assert.Equal(t, uint64(4), op.Step(1).(PromoteLearner).ToStore)
+}
+
 // Test for not set unhealthy peer as target for promote learner and transfer leader
 func TestTargetUnhealthyPeer(t *testing.T) {
 	s := &testBuilder{}
@@ -42,6 +42,13 @@ func CreatePromoteLearnerOperator(desc string, cluster opt.Cluster, res *core.Ca
 		Build(0)
 }
 
+// CreateDemoteVoterOperator creates an operator that demotes a voter.
+func CreateDemoteVoterOperator(desc string, cluster opt.Cluster, res *core.CachedShard, peer metapb.Replica) (*Operator, error) {
+	return NewBuilder(desc, cluster, res).
+		DemoteVoter(peer.StoreID).
+		Build(0)
+}
+
 // CreateRemovePeerOperator creates an operator that removes a peer from resource.
 func CreateRemovePeerOperator(desc string, cluster opt.Cluster, kind OpKind, res *core.CachedShard, containerID uint64) (*Operator, error) {
 	return NewBuilder(desc, cluster, res).
@@ -119,6 +126,25 @@ func CreateSplitShardOperator(desc string, res *core.CachedShard, kind OpKind, p
 	return NewOperator(desc, brief, res.Meta.GetID(), res.Meta.GetEpoch(), kind|OpSplit, step), nil
 }
 
+// CreateBecomeWitnessOperator creates an operator that demotes a follower peer
+// to witness. The witness can not be promoted back, it is replaced by a new
+// peer.
+func CreateBecomeWitnessOperator(desc string, cluster opt.Cluster, res *core.CachedShard, peer metapb.Replica) (*Operator, error) {
+	if metadata.IsInJointState(res.Meta.GetReplicas()...) {
+		return nil, errors.New("cannot demote peer of resource which is in joint state")
+	}
+	if peer.IsWitness {
+		return nil, fmt.Errorf("peer %d is already witness", peer.ID)
+	}
+	if res.GetLeader().GetID() == peer.ID {
+		return nil, fmt.Errorf("cannot demote leader peer %d to witness", peer.ID)
+	}
+
+	step := BecomeWitness{ToStore: peer.StoreID, PeerID: peer.ID}
+	brief := fmt.Sprintf("become witness: container %d", peer.StoreID)
+	return NewOperator(desc, brief, res.Meta.GetID(), res.Meta.GetEpoch(), OpShard, step), nil
+}
+
 // CreateMergeShardOperator creates an operator that merge two resource into one.
 func CreateMergeShardOperator(desc string, cluster opt.Cluster, source *core.CachedShard, target *core.CachedShard, kind OpKind) ([]*Operator, error) {
 	if metadata.IsInJointState(source.Meta.GetReplicas()...) || metadata.IsInJointState(target.Meta.GetReplicas()...) {
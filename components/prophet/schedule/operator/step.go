@@ -140,6 +140,7 @@ func (ap AddPeer) CheckSafety(res *core.CachedShard) error {
 // AddLearner is an OpStep that adds a resource learner peer.
 type AddLearner struct {
 	ToStore, PeerID uint64
+	IsWitness       bool
 }
 
 // ConfVerChanged returns the delta value for version increased by this step.
@@ -228,6 +229,51 @@ func (pl PromoteLearner) CheckSafety(res *core.CachedShard) error {
 // Influence calculates the container difference that current step makes.
 func (pl PromoteLearner) Influence(opInfluence OpInfluence, res *core.CachedShard) {}
 
+// CatchUpLearner is an OpStep that waits for the added learner peer to catch up
+// with the leader, including receiving the snapshot, before the membership
+// change promoting it.
+type CatchUpLearner struct {
+	ToStore, PeerID uint64
+}
+
+// ConfVerChanged returns the delta value for version increased by this step.
+func (cl CatchUpLearner) ConfVerChanged(res *core.CachedShard) uint64 {
+	return 0 // CatchUpLearner never change the conf version
+}
+
+func (cl CatchUpLearner) String() string {
+	return fmt.Sprintf("catch up learner peer %v on container %v", cl.PeerID, cl.ToStore)
+}
+
+// IsFinish checks if current step is finished.
+func (cl CatchUpLearner) IsFinish(res *core.CachedShard) bool {
+	if peer, ok := res.GetStoreLearner(cl.ToStore); !ok || peer.ID != cl.PeerID {
+		return false
+	}
+	if _, ok := res.GetPendingPeer(cl.PeerID); ok {
+		return false
+	}
+	if _, ok := res.GetDownPeer(cl.PeerID); ok {
+		return false
+	}
+	return !res.IsRecoveringPeer(cl.PeerID)
+}
+
+// CheckSafety checks if the step meets the safety properties.
+func (cl CatchUpLearner) CheckSafety(res *core.CachedShard) error {
+	peer, ok := res.GetStorePeer(cl.ToStore)
+	if !ok || peer.ID != cl.PeerID {
+		return errors.New("peer does not exist")
+	}
+	if !metadata.IsLearner(peer) {
+		return errors.New("peer already is a voter")
+	}
+	return nil
+}
+
+// Influence calculates the container difference that current step makes.
+func (cl CatchUpLearner) Influence(opInfluence OpInfluence, res *core.CachedShard) {}
+
 // RemovePeer is an OpStep that removes a resource peer.
 type RemovePeer struct {
 	FromStore, PeerID uint64
@@ -427,6 +473,7 @@ func (ap AddLightPeer) Influence(opInfluence OpInfluence, res *core.CachedShard)
 // AddLightLearner is an OpStep that adds a resource learner peer without considering the influence.
 type AddLightLearner struct {
 	ToStore, PeerID uint64
+	IsWitness       bool
 }
 
 // ConfVerChanged returns the delta value for version increased by this step.
@@ -515,6 +562,51 @@ func (df DemoteFollower) CheckSafety(res *core.CachedShard) error {
 // Influence calculates the container difference that current step makes.
 func (df DemoteFollower) Influence(opInfluence OpInfluence, res *core.CachedShard) {}
 
+// BecomeWitness is an OpStep that demotes a resource follower peer to witness,
+// the data of the peer is dropped once it becomes witness.
+type BecomeWitness struct {
+	ToStore, PeerID uint64
+}
+
+func (bw BecomeWitness) String() string {
+	return fmt.Sprintf("demote peer %v on container %v to witness", bw.PeerID, bw.ToStore)
+}
+
+// ConfVerChanged returns the delta value for version increased by this step.
+func (bw BecomeWitness) ConfVerChanged(res *core.CachedShard) uint64 {
+	return typeutil.BoolToUint64(bw.IsFinish(res))
+}
+
+// IsFinish checks if current step is finished.
+func (bw BecomeWitness) IsFinish(res *core.CachedShard) bool {
+	if peer, ok := res.GetStorePeer(bw.ToStore); ok {
+		return peer.ID == bw.PeerID && peer.IsWitness
+	}
+	return false
+}
+
+// CheckSafety checks if the step meets the safety properties.
+func (bw BecomeWitness) CheckSafety(res *core.CachedShard) error {
+	peer, _ := res.GetStorePeer(bw.ToStore)
+	if peer.ID != bw.PeerID {
+		return errors.New("peer does not exist")
+	}
+	if peer.ID == res.GetLeader().GetID() {
+		return errors.New("cannot demote leader peer to witness")
+	}
+	return nil
+}
+
+// Influence calculates the container difference that current step makes.
+func (bw BecomeWitness) Influence(opInfluence OpInfluence, res *core.CachedShard) {
+	from := opInfluence.GetStoreInfluence(bw.ToStore)
+
+	groupKey := res.GetGroupKey()
+	stats := from.InfluenceStats[groupKey]
+	stats.ShardSize -= res.GetApproximateSize()
+	from.InfluenceStats[groupKey] = stats
+}
+
 // DemoteVoter is very similar to DemoteFollower. But it allows Demote Leader.
 // Note: It is not an OpStep, only a sub step in ChangePeerV2Enter and ChangePeerV2Leave.
 type DemoteVoter struct {
@@ -96,6 +96,59 @@ func TestDemoteFollower(t *testing.T) {
 	checkStep(t, df, "demote follower peer 2 on container 2 to learner", cases)
 }
 
+func TestCatchUpLearner(t *testing.T) {
+	cl := CatchUpLearner{ToStore: 2, PeerID: 2}
+	cases := []testCase{
+		{ // before adding learner
+			[]metapb.Replica{
+				{ID: 1, StoreID: 1, Role: metapb.ReplicaRole_Voter},
+				{ID: 3, StoreID: 3, Role: metapb.ReplicaRole_Voter},
+			},
+			0,
+			false,
+			"NotNil",
+		},
+		{ // learner caught up
+			[]metapb.Replica{
+				{ID: 1, StoreID: 1, Role: metapb.ReplicaRole_Voter},
+				{ID: 2, StoreID: 2, Role: metapb.ReplicaRole_Learner},
+				{ID: 3, StoreID: 3, Role: metapb.ReplicaRole_Voter},
+			},
+			0,
+			true,
+			"IsNil",
+		},
+		{ // miss peer id
+			[]metapb.Replica{
+				{ID: 1, StoreID: 1, Role: metapb.ReplicaRole_Voter},
+				{ID: 4, StoreID: 2, Role: metapb.ReplicaRole_Learner},
+				{ID: 3, StoreID: 3, Role: metapb.ReplicaRole_Voter},
+			},
+			0,
+			false,
+			"NotNil",
+		},
+		{ // already voter
+			[]metapb.Replica{
+				{ID: 1, StoreID: 1, Role: metapb.ReplicaRole_Voter},
+				{ID: 2, StoreID: 2, Role: metapb.ReplicaRole_Voter},
+				{ID: 3, StoreID: 3, Role: metapb.ReplicaRole_Voter},
+			},
+			0,
+			false,
+			"NotNil",
+		},
+	}
+	checkStep(t, cl, "catch up learner peer 2 on container 2", cases)
+
+	peers := cases[1].Peers
+	resource := core.NewCachedShard(metapb.Shard{ID: 1, Replicas: peers}, &peers[0])
+	assert.False(t, cl.IsFinish(resource.Clone(core.WithPendingPeers(peers[1:2]))))
+	assert.False(t, cl.IsFinish(resource.Clone(core.WithDownPeers([]metapb.ReplicaStats{{Replica: peers[1]}}))))
+	assert.False(t, cl.IsFinish(resource.Clone(core.WithRecoveringPeers([]uint64{2}))))
+	assert.True(t, cl.IsFinish(resource.Clone(core.WithPendingPeers(peers[2:]))))
+}
+
 func TestChangePeerV2Enter(t *testing.T) {
 	cpe := ChangePeerV2Enter{
 		PromoteLearners: []PromoteLearner{{PeerID: 3, ToStore: 3}, {PeerID: 4, ToStore: 4}},
@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/matrixorigin/matrixcube/components/log"
@@ -61,11 +62,14 @@ type OperatorController struct {
 	hbStreams       *hbstream.HeartbeatStreams
 	histories       *list.List
 	counts          map[operator.OpKind]uint64
+	groupCounts     map[uint64]map[operator.OpKind]uint64
 	opRecords       *OperatorRecords
 	containersLimit map[uint64]map[limit.Type]*limit.StoreLimit
 	wop             WaitingOperator
 	wopStatus       *WaitingOperatorStatus
 	opNotifierQueue operatorQueue
+	// createdCount is the number of the operators created, updated atomically
+	createdCount uint64
 }
 
 // NewOperatorController creates a OperatorController.
@@ -77,6 +81,7 @@ func NewOperatorController(ctx context.Context, cluster opt.Cluster, hbStreams *
 		hbStreams:       hbStreams,
 		histories:       list.New(),
 		counts:          make(map[operator.OpKind]uint64),
+		groupCounts:     make(map[uint64]map[operator.OpKind]uint64),
 		opRecords:       NewOperatorRecords(ctx),
 		containersLimit: make(map[uint64]map[limit.Type]*limit.StoreLimit),
 		wop:             NewRandBuckets(),
@@ -185,7 +190,7 @@ func (oc *OperatorController) checkStaleOperator(op *operator.Operator, step ope
 func (oc *OperatorController) getNextPushOperatorTime(step operator.OpStep, now time.Time) time.Time {
 	nextTime := slowNotifyInterval
 	switch step.(type) {
-	case operator.TransferLeader, operator.PromoteLearner, operator.DemoteFollower, operator.ChangePeerV2Enter, operator.ChangePeerV2Leave:
+	case operator.TransferLeader, operator.PromoteLearner, operator.DemoteFollower, operator.BecomeWitness, operator.ChangePeerV2Enter, operator.ChangePeerV2Leave:
 		nextTime = fastNotifyInterval
 	}
 	return now.Add(nextTime)
@@ -364,6 +369,7 @@ func (oc *OperatorController) PromoteWaitingOperator() {
 // - The epoch of the operator and the epoch of the corresponding resource are no longer consistent.
 // - The resource already has a higher priority or same priority operator.
 // - Exceed the max number of waiting operators
+// - Exceed the schedule limits of the shard group
 // - At least one operator is expired.
 func (oc *OperatorController) checkAddOperator(ops ...*operator.Operator) bool {
 	for _, op := range ops {
@@ -391,6 +397,14 @@ func (oc *OperatorController) checkAddOperator(ops ...*operator.Operator) bool {
 			operatorWaitCounter.WithLabelValues(op.Desc(), "already-have").Inc()
 			return false
 		}
+		if oc.exceedGroupLimitLocked(res.Meta.Group, op) {
+			oc.cluster.GetLogger().Debug("group schedule limit exceeded, cancel add operator",
+				log.ResourceField(op.ShardID()),
+				zap.Uint64("group", res.Meta.Group),
+				zap.Stringer("op", op))
+			operatorWaitCounter.WithLabelValues(op.Desc(), "exceed-group-limit").Inc()
+			return false
+		}
 		if op.Status() != operator.CREATED {
 			oc.cluster.GetLogger().Error("resource trying to add operator with unexpected status",
 				log.ResourceField(op.ShardID()),
@@ -418,6 +432,21 @@ func (oc *OperatorController) checkAddOperator(ops ...*operator.Operator) bool {
 	return !expired
 }
 
+// exceedGroupLimitLocked returns true if the running operators of the shard group
+// reach the schedule limits of the group for the kind of the operator. The admin
+// operators are not limited.
+func (oc *OperatorController) exceedGroupLimitLocked(group uint64, op *operator.Operator) bool {
+	limit, ok := oc.cluster.GetOpts().GetGroupScheduleLimit(group)
+	kind := op.Kind()
+	if !ok || kind&operator.OpAdmin != 0 {
+		return false
+	}
+
+	return (kind&operator.OpLeader != 0 && oc.groupOperatorCountLocked(group, operator.OpLeader) >= limit.LeaderScheduleLimit) ||
+		(kind&operator.OpShard != 0 && oc.groupOperatorCountLocked(group, operator.OpShard) >= limit.ShardScheduleLimit) ||
+		(kind&operator.OpHotShard != 0 && oc.groupOperatorCountLocked(group, operator.OpHotShard) >= limit.HotShardScheduleLimit)
+}
+
 func isHigherPriorityOperator(new, old *operator.Operator) bool {
 	return new.GetPriorityLevel() > old.GetPriorityLevel()
 }
@@ -477,6 +506,7 @@ func (oc *OperatorController) addOperatorLocked(op *operator.Operator) bool {
 	}
 
 	heap.Push(&oc.opNotifierQueue, &operatorWithTime{op: op, time: oc.getNextPushOperatorTime(step, time.Now())})
+	atomic.AddUint64(&oc.createdCount, 1)
 	operatorCounter.WithLabelValues(op.Desc(), "create").Inc()
 	for _, counter := range op.Counters {
 		counter.Inc()
@@ -668,9 +698,10 @@ func (oc *OperatorController) SendScheduleCommand(res *core.CachedShard, step op
 			ConfigChange: &rpcpb.ConfigChange{
 				ChangeType: metapb.ConfigChangeType_AddLearnerNode,
 				Replica: metapb.Replica{
-					ID:      st.PeerID,
-					StoreID: st.ToStore,
-					Role:    metapb.ReplicaRole_Learner,
+					ID:        st.PeerID,
+					StoreID:   st.ToStore,
+					Role:      metapb.ReplicaRole_Learner,
+					IsWitness: st.IsWitness,
 				},
 			},
 		}
@@ -683,12 +714,16 @@ func (oc *OperatorController) SendScheduleCommand(res *core.CachedShard, step op
 			ConfigChange: &rpcpb.ConfigChange{
 				ChangeType: metapb.ConfigChangeType_AddLearnerNode,
 				Replica: metapb.Replica{
-					ID:      st.PeerID,
-					StoreID: st.ToStore,
-					Role:    metapb.ReplicaRole_Learner,
+					ID:        st.PeerID,
+					StoreID:   st.ToStore,
+					Role:      metapb.ReplicaRole_Learner,
+					IsWitness: st.IsWitness,
 				},
 			},
 		}
+	case operator.CatchUpLearner:
+		// The leader is replicating the logs or sending the snapshot to the learner.
+		return
 	case operator.PromoteLearner:
 		cmd = &rpcpb.ShardHeartbeatRsp{
 			ConfigChange: &rpcpb.ConfigChange{
@@ -739,6 +774,13 @@ func (oc *OperatorController) SendScheduleCommand(res *core.CachedShard, step op
 				Keys:   st.SplitKeys,
 			},
 		}
+	case operator.BecomeWitness:
+		p, _ := res.GetStorePeer(st.ToStore)
+		cmd = &rpcpb.ShardHeartbeatRsp{
+			BecomeWitness: &rpcpb.BecomeWitness{
+				Replica: p,
+			},
+		}
 	case operator.ChangePeerV2Enter:
 		cmd = &rpcpb.ShardHeartbeatRsp{
 			ConfigChangeV2: st.GetRequest(),
@@ -795,8 +837,19 @@ func (oc *OperatorController) updateCounts(operators map[uint64]*operator.Operat
 	for k := range oc.counts {
 		delete(oc.counts, k)
 	}
+	for k := range oc.groupCounts {
+		delete(oc.groupCounts, k)
+	}
 	for _, op := range operators {
 		oc.counts[op.Kind()]++
+		if res := oc.cluster.GetShard(op.ShardID()); res != nil {
+			counts, ok := oc.groupCounts[res.Meta.Group]
+			if !ok {
+				counts = make(map[operator.OpKind]uint64)
+				oc.groupCounts[res.Meta.Group] = counts
+			}
+			counts[op.Kind()]++
+		}
 	}
 }
 
@@ -813,6 +866,29 @@ func (oc *OperatorController) OperatorCount(mask operator.OpKind) uint64 {
 	return total
 }
 
+// GroupOperatorCount gets the count of operators of the shard group filtered by mask.
+func (oc *OperatorController) GroupOperatorCount(group uint64, mask operator.OpKind) uint64 {
+	oc.RLock()
+	defer oc.RUnlock()
+	return oc.groupOperatorCountLocked(group, mask)
+}
+
+func (oc *OperatorController) groupOperatorCountLocked(group uint64, mask operator.OpKind) uint64 {
+	var total uint64
+	for k, count := range oc.groupCounts[group] {
+		if k&mask != 0 {
+			total += count
+		}
+	}
+	return total
+}
+
+// CreatedOperatorCount returns the number of the operators created since the
+// controller started.
+func (oc *OperatorController) CreatedOperatorCount() uint64 {
+	return atomic.LoadUint64(&oc.createdCount)
+}
+
 // GetOpInfluence gets OpInfluence.
 func (oc *OperatorController) GetOpInfluence(cluster opt.Cluster) operator.OpInfluence {
 	influence := operator.OpInfluence{
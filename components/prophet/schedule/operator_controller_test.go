@@ -713,6 +713,50 @@ func TestAddWaitingOperator(t *testing.T) {
 	assert.Equal(t, 0, controller.AddWaitingOperator(addPeerOp(0)))
 }
 
+func TestGroupScheduleLimit(t *testing.T) {
+	s := &testOperatorController{}
+	s.setup(t)
+	defer s.tearDown()
+
+	opt := config.NewTestOptions()
+	cfg := opt.GetScheduleConfig().Clone()
+	cfg.GroupLimits = []config.GroupScheduleLimit{{Group: 1, LeaderScheduleLimit: 1}}
+	opt.SetScheduleConfig(cfg)
+	tc := mockcluster.NewCluster(opt)
+	stream := hbstream.NewTestHeartbeatStreams(s.ctx, tc.ID, tc, false /* no need to run */, nil)
+	oc := NewOperatorController(s.ctx, tc, stream)
+	tc.AddLeaderStore(1, 3)
+	tc.AddLeaderStore(2, 0)
+	for i := uint64(1); i <= 3; i++ {
+		tc.AddLeaderShard(i, 1, 2)
+		if i < 3 {
+			res := tc.GetShard(i)
+			meta := res.Meta
+			meta.Group = 1
+			tc.PutShard(core.NewCachedShard(meta, res.GetLeader()))
+		}
+	}
+	transferLeader := func(id uint64, kind operator.OpKind) *operator.Operator {
+		res := tc.GetShard(id)
+		return operator.NewOperator("test", "test", id, res.Meta.GetEpoch(), kind,
+			operator.TransferLeader{FromStore: 1, ToStore: 2})
+	}
+
+	op1 := transferLeader(1, operator.OpLeader)
+	assert.True(t, oc.AddOperator(op1))
+	assert.Equal(t, uint64(1), oc.GroupOperatorCount(1, operator.OpLeader))
+	// the group limit is reached
+	assert.False(t, oc.AddOperator(transferLeader(2, operator.OpLeader)))
+	// the other groups and the admin operators are not limited
+	assert.True(t, oc.AddOperator(transferLeader(3, operator.OpLeader)))
+	assert.True(t, oc.AddOperator(transferLeader(2, operator.OpLeader|operator.OpAdmin)))
+	assert.Equal(t, uint64(3), oc.OperatorCount(operator.OpLeader))
+
+	checkRemoveOperatorSuccess(t, oc, op1)
+	assert.Equal(t, uint64(1), oc.GroupOperatorCount(1, operator.OpLeader))
+	assert.Equal(t, uint64(1), oc.GroupOperatorCount(0, operator.OpLeader))
+}
+
 func checkRemoveOperatorSuccess(t *testing.T, oc *OperatorController, op *operator.Operator) {
 	assert.True(t, oc.RemoveOperator(op, ""))
 	assert.True(t, op.IsEnd())
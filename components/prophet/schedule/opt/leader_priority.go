@@ -0,0 +1,62 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package opt
+
+import (
+	"github.com/matrixorigin/matrixcube/components/prophet/core"
+	"github.com/matrixorigin/matrixcube/components/prophet/schedule/placement"
+)
+
+// LeaderPriorities is the leader election priorities of the replicas of a
+// resource. The priority of a replica is the higher one of the priority of the
+// resource group on the store and the priority of the placement rule the replica
+// fits.
+type LeaderPriorities struct {
+	cluster Cluster
+	res     *core.CachedShard
+	fit     *placement.ShardFit
+}
+
+// NewLeaderPriorities creates the leader priorities of the replicas of the
+// resource.
+func NewLeaderPriorities(cluster Cluster, res *core.CachedShard) *LeaderPriorities {
+	lp := &LeaderPriorities{cluster: cluster, res: res}
+	if cluster.GetOpts().IsPlacementRulesEnabled() {
+		lp.fit = cluster.FitShard(res)
+	}
+	return lp
+}
+
+// Get returns the leader priority of the replica on the store.
+func (lp *LeaderPriorities) Get(store *core.CachedStore) int {
+	priority := lp.cluster.GetOpts().GetReplicationConfig().
+		GetLeaderPriority(lp.res.Meta.GetGroup(), store.Meta.GetLabels())
+	if lp.fit == nil {
+		return priority
+	}
+
+	storeID := store.Meta.GetID()
+	for _, rf := range lp.fit.RuleFits {
+		if rf.Rule.LeaderPriority <= priority {
+			continue
+		}
+		for _, peer := range rf.Peers {
+			if peer.StoreID == storeID {
+				priority = rf.Rule.LeaderPriority
+				break
+			}
+		}
+	}
+	return priority
+}
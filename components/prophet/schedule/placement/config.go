@@ -149,6 +149,35 @@ func (p *ruleConfigPatch) trim() {
 	}
 }
 
+// bumpGroupVersions increases the versions of the versioned groups changed by
+// the patch, so the group bundle CAS fails if the rules or the group are changed
+// concurrently by the other APIs, e.g. SetRule.
+func (p *ruleConfigPatch) bumpGroupVersions() {
+	changed := make(map[string]struct{})
+	for key := range p.mut.rules {
+		changed[key[0]] = struct{}{}
+	}
+	for id := range p.mut.groups {
+		changed[id] = struct{}{}
+	}
+	for id := range changed {
+		current, ok := p.c.groups[id]
+		if !ok || current.Version == 0 {
+			continue
+		}
+		g, ok := p.mut.groups[id]
+		if !ok {
+			g = current
+		} else if g.isDefault() || g.Version > current.Version {
+			// removed, or already bumped by the group bundle
+			continue
+		}
+		bumped := *g
+		bumped.Version = current.Version + 1
+		p.mut.groups[id] = &bumped
+	}
+}
+
 // merge all mutations to ruleConfig.
 func (p *ruleConfigPatch) commit() {
 	for key, rule := range p.mut.rules {
@@ -178,6 +178,7 @@ func (w *fitWorker) fitRule(index int) bool {
 		for _, p := range w.peers {
 			if MatchLabelConstraints(p.container, w.rules[index].LabelConstraints) &&
 				p.matchRoleLoose(w.rules[index].Role) &&
+				p.matchWitnessLoose(w.rules[index].IsWitness) &&
 				!p.selected {
 				candidates = append(candidates, p)
 			}
@@ -255,7 +256,7 @@ func newRuleFit(rule *Rule, peers []*fitPeer) *RuleFit {
 	rf := &RuleFit{Rule: rule, IsolationScore: isolationScore(peers, rule.LocationLabels)}
 	for _, p := range peers {
 		rf.Peers = append(rf.Peers, p.Replica)
-		if !p.matchRoleStrict(rule.Role) {
+		if !p.matchRoleStrict(rule.Role) || p.IsWitness != rule.IsWitness {
 			rf.PeersWithDifferentRole = append(rf.PeersWithDifferentRole, p.Replica)
 		}
 	}
@@ -290,6 +291,12 @@ func (p *fitPeer) matchRoleLoose(role ReplicaRoleType) bool {
 	return role != Learner || metadata.IsLearner(p.Replica)
 }
 
+func (p *fitPeer) matchWitnessLoose(isWitness bool) bool {
+	// witness cannot become full replica as it has no data, it is replaced by
+	// a new full replica. A full replica can be demoted to witness.
+	return isWitness || !p.IsWitness
+}
+
 func isolationScore(peers []*fitPeer, labels []string) float64 {
 	var score float64
 	if len(labels) == 0 || len(peers) <= 1 {
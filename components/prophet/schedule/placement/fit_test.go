@@ -192,3 +192,30 @@ func TestIsolationScore(t *testing.T) {
 		}
 	}
 }
+
+func TestFitWitness(t *testing.T) {
+	containers := makeTestStores()
+	witness := makeTestRule("1/voter//")
+	witness.IsWitness = true
+
+	res := makeTestShard("1111,1112,1113")
+	peers := res.Meta.GetReplicas()
+	peers[2].IsWitness = true
+	res = core.NewCachedShard(metapb.Shard{Replicas: peers}, nil)
+	rf := FitShard(containers, res, []*Rule{makeTestRule("2/voter//"), witness})
+	assert.True(t, rf.IsSatisfied())
+	assert.True(t, checkPeerMatch(rf.RuleFits[0].Peers, "1111,1112"))
+	assert.True(t, checkPeerMatch(rf.RuleFits[1].Peers, "1113"))
+
+	// the witness can not match the rule of the full replicas
+	rf = FitShard(containers, res, []*Rule{makeTestRule("3/voter//")})
+	assert.False(t, rf.IsSatisfied())
+	assert.True(t, checkPeerMatch(rf.RuleFits[0].Peers, "1111,1112"))
+	assert.True(t, checkPeerMatch(rf.OrphanPeers, "1113"))
+
+	// the full replica can be demoted to the witness
+	rf = FitShard(containers, makeTestShard("1111,1112,1113"), []*Rule{makeTestRule("2/voter//"), witness})
+	assert.False(t, rf.IsSatisfied())
+	assert.Equal(t, 1, len(rf.RuleFits[1].Peers))
+	assert.Equal(t, rf.RuleFits[1].Peers, rf.RuleFits[1].PeersWithDifferentRole)
+}
@@ -95,6 +95,8 @@ type Rule struct {
 	LabelConstraints []LabelConstraint `json:"label_constraints,omitempty"` // used to select containers to place peers
 	LocationLabels   []string          `json:"location_labels,omitempty"`   // used to make peers isolated physically
 	IsolationLevel   string            `json:"isolation_level,omitempty"`   // used to isolate replicas explicitly and forcibly
+	LeaderPriority   int               `json:"leader_priority,omitempty"`   // the leader is moved to the peer with the highest priority
+	IsWitness        bool              `json:"is_witness,omitempty"`        // the peers are witnesses storing the raft log without the data
 
 	group *RuleGroup // only set at runtime, no need to {,un}marshal or persist.
 }
@@ -115,6 +117,8 @@ func RPCRules(rules []*Rule) []rpcpb.PlacementRule {
 			LabelConstraints: toRPCLabelConstraints(rule.LabelConstraints),
 			LocationLabels:   rule.LocationLabels,
 			IsolationLevel:   rule.IsolationLevel,
+			LeaderPriority:   uint32(rule.LeaderPriority),
+			IsWitness:        rule.IsWitness,
 		})
 	}
 	return values
@@ -134,6 +138,8 @@ func NewRuleFromRPC(rule rpcpb.PlacementRule) *Rule {
 		LabelConstraints: newLabelConstraintsFromRPC(rule.LabelConstraints),
 		LocationLabels:   rule.LocationLabels,
 		IsolationLevel:   rule.IsolationLevel,
+		LeaderPriority:   int(rule.LeaderPriority),
+		IsWitness:        rule.IsWitness,
 	}
 }
 
@@ -164,10 +170,11 @@ type RuleGroup struct {
 	ID       string `json:"id,omitempty"`
 	Index    int    `json:"index,omitempty"`
 	Override bool   `json:"override,omitempty"`
+	Version  uint64 `json:"version,omitempty"` // increased each time the group bundle is reset
 }
 
 func (g *RuleGroup) isDefault() bool {
-	return g.Index == 0 && !g.Override
+	return g.Index == 0 && !g.Override && g.Version == 0
 }
 
 func (g *RuleGroup) String() string {
@@ -240,9 +247,39 @@ type GroupBundle struct {
 	Index    int     `json:"group_index"`
 	Override bool    `json:"group_override"`
 	Rules    []*Rule `json:"rules"`
+	Version  uint64  `json:"group_version,omitempty"`
 }
 
 func (g GroupBundle) String() string {
 	b, _ := json.Marshal(g)
 	return string(b)
 }
+
+// RPCGroupBundle converts the group bundle to rpc placement rule group bundle
+func RPCGroupBundle(g GroupBundle) rpcpb.PlacementRuleGroupBundle {
+	var rules []rpcpb.PlacementRule
+	if len(g.Rules) > 0 {
+		rules = RPCRules(g.Rules)
+	}
+	return rpcpb.PlacementRuleGroupBundle{
+		ID:       g.ID,
+		Index:    uint32(g.Index),
+		Override: g.Override,
+		Rules:    rules,
+		Version:  g.Version,
+	}
+}
+
+// NewGroupBundleFromRPC creates the group bundle from rpc
+func NewGroupBundleFromRPC(g rpcpb.PlacementRuleGroupBundle) GroupBundle {
+	b := GroupBundle{
+		ID:       g.ID,
+		Index:    int(g.Index),
+		Override: g.Override,
+		Version:  g.Version,
+	}
+	for _, r := range g.Rules {
+		b.Rules = append(b.Rules, NewRuleFromRPC(r))
+	}
+	return b
+}
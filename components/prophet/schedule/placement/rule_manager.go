@@ -205,6 +205,12 @@ func (m *RuleManager) adjustRule(r *Rule, groupID string) error {
 	if r.Role == Leader && r.Count > 1 {
 		return fmt.Errorf("define multiple leaders by count %d", r.Count)
 	}
+	if r.IsWitness && r.Role != Voter && r.Role != Follower {
+		return fmt.Errorf("witness can not be %s", r.Role)
+	}
+	if r.LeaderPriority < 0 {
+		return fmt.Errorf("invalid leader priority %d", r.LeaderPriority)
+	}
 	for _, c := range r.LabelConstraints {
 		if !validateOp(c.Op) {
 			return fmt.Errorf("invalid op %s", c.Op)
@@ -329,6 +335,7 @@ func (m *RuleManager) tryCommitPatch(patch *ruleConfigPatch) error {
 	}
 
 	patch.trim()
+	patch.bumpGroupVersions()
 
 	// save updates
 	err = m.savePatch(patch.mut)
@@ -528,6 +535,7 @@ func (m *RuleManager) GetAllGroupBundles() []GroupBundle {
 			ID:       g.ID,
 			Index:    g.Index,
 			Override: g.Override,
+			Version:  g.Version,
 		})
 	}
 	for _, r := range m.ruleConfig.rules {
@@ -553,7 +561,7 @@ func (m *RuleManager) GetGroupBundle(id string) (b GroupBundle) {
 	defer m.RUnlock()
 	b.ID = id
 	if g := m.ruleConfig.groups[id]; g != nil {
-		b.Index, b.Override = g.Index, g.Override
+		b.Index, b.Override, b.Version = g.Index, g.Override, g.Version
 		for _, r := range m.ruleConfig.rules {
 			if r.GroupID == id {
 				b.Rules = append(b.Rules, r)
@@ -614,8 +622,34 @@ func (m *RuleManager) SetAllGroupBundles(groups []GroupBundle, override bool) er
 func (m *RuleManager) SetGroupBundle(group GroupBundle) error {
 	m.Lock()
 	defer m.Unlock()
+	_, err := m.setGroupBundleLocked(group)
+	return err
+}
+
+// CompareAndSetGroupBundle resets a Group and all rules belong to it if the
+// version of the group bundle is the current version of the Group, 0 means the
+// Group must not exist. It returns the new version of the Group.
+func (m *RuleManager) CompareAndSetGroupBundle(group GroupBundle) (uint64, error) {
+	if group.ID == "" {
+		return 0, errors.New("group ID should not be empty")
+	}
+	if len(group.Rules) == 0 {
+		return 0, fmt.Errorf("group %s has no rules", group.ID)
+	}
+
+	m.Lock()
+	defer m.Unlock()
+	if err := m.checkGroupVersionLocked(group.ID, group.Version); err != nil {
+		return 0, err
+	}
+	return m.setGroupBundleLocked(group)
+}
+
+func (m *RuleManager) setGroupBundleLocked(group GroupBundle) (uint64, error) {
 	p := m.beginPatch()
-	if _, ok := m.ruleConfig.groups[group.ID]; ok {
+	version := uint64(1)
+	if g, ok := m.ruleConfig.groups[group.ID]; ok {
+		version = g.Version + 1
 		for k := range m.ruleConfig.rules {
 			if k[0] == group.ID {
 				p.deleteRule(k[0], k[1])
@@ -626,19 +660,68 @@ func (m *RuleManager) SetGroupBundle(group GroupBundle) error {
 		ID:       group.ID,
 		Index:    group.Index,
 		Override: group.Override,
+		Version:  version,
 	})
 	for _, r := range group.Rules {
 		if err := m.adjustRule(r, group.ID); err != nil {
-			return err
+			return 0, err
 		}
 		p.setRule(r)
 	}
 	if err := m.tryCommitPatch(p); err != nil {
-		return err
+		return 0, err
 	}
 
 	m.logger.Info("group is reset",
-		zap.Stringer("group", group))
+		zap.Stringer("group", group),
+		zap.Uint64("version", version))
+	return version, nil
+}
+
+// checkGroupVersionLocked returns an error if the version is not the current
+// version of the Group, 0 means the Group must not exist.
+func (m *RuleManager) checkGroupVersionLocked(id string, version uint64) error {
+	current := uint64(0)
+	if g, ok := m.ruleConfig.groups[id]; ok {
+		current = g.Version
+		if current == 0 {
+			// the group created by the rules has no version
+			return fmt.Errorf("group %s is not managed by the group bundles", id)
+		}
+	}
+	if version != current {
+		return fmt.Errorf("group %s version mismatch, expect %d, current %d",
+			id, version, current)
+	}
+	return nil
+}
+
+// CompareAndDeleteGroupBundle removes a Group and all rules belong to it if the
+// version is the current version of the Group.
+func (m *RuleManager) CompareAndDeleteGroupBundle(id string, version uint64) error {
+	m.Lock()
+	defer m.Unlock()
+	if _, ok := m.ruleConfig.groups[id]; !ok {
+		return fmt.Errorf("group %s not found", id)
+	}
+	if err := m.checkGroupVersionLocked(id, version); err != nil {
+		return err
+	}
+
+	p := m.beginPatch()
+	for k := range m.ruleConfig.rules {
+		if k[0] == id {
+			p.deleteRule(k[0], k[1])
+		}
+	}
+	p.deleteGroup(id)
+	if err := m.tryCommitPatch(p); err != nil {
+		return err
+	}
+
+	m.logger.Info("group is removed",
+		zap.String("group", id),
+		zap.Uint64("version", version))
 	return nil
 }
 
@@ -338,6 +338,53 @@ func TestGroupConfig(t *testing.T) {
 	assert.True(t, reflect.DeepEqual([]*RuleGroup{g2}, s.manager.GetRuleGroups()))
 }
 
+func TestCompareAndSetGroupBundle(t *testing.T) {
+	s := &testManager{}
+	s.setup(t)
+
+	bundle := GroupBundle{ID: "g", Index: 1, Rules: []*Rule{
+		{ID: "1", Role: "voter", Count: 3, StartKeyHex: "11", EndKeyHex: "22"},
+	}}
+	// version 0 means the group must not exist
+	v, err := s.manager.CompareAndSetGroupBundle(bundle)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), v)
+	_, err = s.manager.CompareAndSetGroupBundle(bundle)
+	assert.Error(t, err)
+
+	bundle.Version = 1
+	bundle.Rules[0].Count = 5
+	v, err = s.manager.CompareAndSetGroupBundle(bundle)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), v)
+	b := s.manager.GetGroupBundle("g")
+	assert.Equal(t, uint64(2), b.Version)
+	assert.Equal(t, 5, b.Rules[0].Count)
+
+	// the rules changed by the other APIs bump the version
+	assert.NoError(t, s.manager.SetRule(&Rule{GroupID: "g", ID: "2", Role: "voter", Count: 1,
+		StartKeyHex: "22", EndKeyHex: "33"}))
+	assert.Equal(t, uint64(3), s.manager.GetGroupBundle("g").Version)
+	_, err = s.manager.CompareAndSetGroupBundle(bundle)
+	assert.Error(t, err)
+	assert.NoError(t, s.manager.DeleteRule("g", "2"))
+	assert.Equal(t, uint64(4), s.manager.GetGroupBundle("g").Version)
+
+	// the group not created by the group bundles
+	_, err = s.manager.CompareAndSetGroupBundle(GroupBundle{ID: "prophet",
+		Rules: []*Rule{{ID: "1", Role: "voter", Count: 3}}})
+	assert.Error(t, err)
+
+	// the version is persisted
+	m := NewRuleManager(s.storage, nil, nil)
+	assert.NoError(t, m.Initialize(3, []string{"zone", "rack", "host"}))
+	assert.Equal(t, uint64(4), m.GetGroupBundle("g").Version)
+
+	assert.Error(t, s.manager.CompareAndDeleteGroupBundle("g", 3))
+	assert.NoError(t, s.manager.CompareAndDeleteGroupBundle("g", 4))
+	assert.Empty(t, s.manager.GetGroupBundle("g").Rules)
+}
+
 func TestCheckApplyRules(t *testing.T) {
 	s := &testManager{}
 	s.setup(t)
@@ -63,9 +63,10 @@ func ApplyOperatorStep(resource *core.CachedShard, op *operator.Operator) *core.
 				panic("Add learner that exists")
 			}
 			peer := metapb.Replica{
-				ID:      s.PeerID,
-				StoreID: s.ToStore,
-				Role:    metapb.ReplicaRole_Learner,
+				ID:        s.PeerID,
+				StoreID:   s.ToStore,
+				Role:      metapb.ReplicaRole_Learner,
+				IsWitness: s.IsWitness,
 			}
 			resource = resource.Clone(core.WithAddPeer(peer))
 		case operator.AddLightLearner:
@@ -73,19 +74,31 @@ func ApplyOperatorStep(resource *core.CachedShard, op *operator.Operator) *core.
 				panic("Add learner that exists")
 			}
 			peer := metapb.Replica{
-				ID:      s.PeerID,
-				StoreID: s.ToStore,
-				Role:    metapb.ReplicaRole_Learner,
+				ID:        s.PeerID,
+				StoreID:   s.ToStore,
+				Role:      metapb.ReplicaRole_Learner,
+				IsWitness: s.IsWitness,
 			}
 			resource = resource.Clone(core.WithAddPeer(peer))
+		case operator.CatchUpLearner:
+			resource = resource.Clone(core.WithPendingPeers(nil), core.WithRecoveringPeers(nil))
 		case operator.PromoteLearner:
-			if _, ok := resource.GetStoreLearner(s.ToStore); !ok {
+			learner, ok := resource.GetStoreLearner(s.ToStore)
+			if !ok {
 				panic("Promote peer that doesn't exist")
 			}
 			peer := metapb.Replica{
-				ID:      s.PeerID,
-				StoreID: s.ToStore,
+				ID:        s.PeerID,
+				StoreID:   s.ToStore,
+				IsWitness: learner.IsWitness,
+			}
+			resource = resource.Clone(core.WithRemoveStorePeer(s.ToStore), core.WithAddPeer(peer))
+		case operator.BecomeWitness:
+			peer, ok := resource.GetStorePeer(s.ToStore)
+			if !ok {
+				panic("Demote peer that doesn't exist")
 			}
+			peer.IsWitness = true
 			resource = resource.Clone(core.WithRemoveStorePeer(s.ToStore), core.WithAddPeer(peer))
 		default:
 			panic("Unknown operator step")
@@ -234,6 +234,12 @@ func (l *balanceLeaderScheduler) transferLeaderOut(groupKey string, cluster opt.
 	if leaderFilter := filter.NewPlacementLeaderSafeguard(l.GetName(), cluster, resource, source); leaderFilter != nil {
 		finalFilters = append(l.filters, leaderFilter)
 	}
+	if priorityFilter := filter.NewLeaderPriorityFilter(l.GetName(), cluster, resource, source); priorityFilter != nil {
+		finalFilters = append(finalFilters, priorityFilter)
+	}
+	if relocationFilter := filter.NewRangeRelocationFilter(l.GetName(), cluster, resource); relocationFilter != nil {
+		finalFilters = append(finalFilters, relocationFilter)
+	}
 	targets = filter.SelectTargetStores(targets, finalFilters, cluster.GetOpts())
 	leaderSchedulePolicy := l.opController.GetLeaderSchedulePolicy()
 	sort.Slice(targets, func(i, j int) bool {
@@ -289,6 +295,12 @@ func (l *balanceLeaderScheduler) transferLeaderIn(groupKey string, cluster opt.C
 	if leaderFilter := filter.NewPlacementLeaderSafeguard(l.GetName(), cluster, resource, source); leaderFilter != nil {
 		finalFilters = append(l.filters, leaderFilter)
 	}
+	if priorityFilter := filter.NewLeaderPriorityFilter(l.GetName(), cluster, resource, source); priorityFilter != nil {
+		finalFilters = append(finalFilters, priorityFilter)
+	}
+	if relocationFilter := filter.NewRangeRelocationFilter(l.GetName(), cluster, resource); relocationFilter != nil {
+		finalFilters = append(finalFilters, relocationFilter)
+	}
 	targets = filter.SelectTargetStores(targets, finalFilters, cluster.GetOpts())
 	if len(targets) < 1 {
 		cluster.GetLogger().Debug("selected random follower resource has no target container",
@@ -318,6 +330,17 @@ func (l *balanceLeaderScheduler) createOperator(cluster opt.Cluster, res *core.C
 	sourceID := source.Meta.GetID()
 	targetID := target.Meta.GetID()
 
+	// the leader is kept on or moved to the preferred store by the checker
+	if preferred := cluster.GetShardPreferredLeader(res.Meta.GetID()); preferred != 0 && preferred != targetID {
+		cluster.GetLogger().Debug("ignore resource with preferred leader",
+			rebalanceLeaderField,
+			l.scheduleField,
+			resourceField(res.Meta.GetID()),
+			zap.Uint64("preferred", preferred))
+		schedulerCounter.WithLabelValues(l.GetName(), "preferred-leader").Inc()
+		return nil
+	}
+
 	opInfluence := l.opController.GetOpInfluence(cluster)
 	kind := core.NewScheduleKind(metapb.ShardType_LeaderOnly, cluster.GetOpts().GetLeaderSchedulePolicy())
 	shouldBalance, sourceScore, targetScore := shouldBalance(cluster, source, target, res, kind, opInfluence, l.GetName())
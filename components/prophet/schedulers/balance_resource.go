@@ -261,6 +261,9 @@ func (s *balanceShardScheduler) transferPeer(group string, cluster opt.Cluster,
 		filter.NewSpecialUseFilter(s.GetName()),
 		&filter.StoreStateFilter{ActionScope: s.GetName(), MoveShard: true},
 	}
+	if relocationFilter := filter.NewRangeRelocationFilter(s.GetName(), cluster, res); relocationFilter != nil {
+		filters = append(filters, relocationFilter)
+	}
 
 	candidates := filter.NewCandidates(cluster.GetStores()).
 		FilterTarget(cluster.GetOpts(), filters...).
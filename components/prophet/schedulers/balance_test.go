@@ -397,6 +397,30 @@ func TestBalanceFilter(t *testing.T) {
 	assert.Empty(t, s.schedule())
 }
 
+func TestBalanceLeaderWithPreferredLeader(t *testing.T) {
+	s := &testBalanceLeaderScheduler{}
+	s.setup(t)
+	defer s.tearDown()
+
+	// containers:     1    2    3    4
+	// Leaders:        1    2    3   16
+	// resource1:      F    F    F    L
+	s.tc.AddLeaderStore(1, 1)
+	s.tc.AddLeaderStore(2, 2)
+	s.tc.AddLeaderStore(3, 3)
+	s.tc.AddLeaderStore(4, 16)
+	s.tc.AddLeaderShard(1, 4, 1, 2, 3)
+	testutil.CheckTransferLeader(t, s.schedule()[0], operator.OpKind(0), 4, 1)
+
+	// never move the leader away from the preferred store
+	s.tc.SetShardPreferredLeader(1, 4)
+	assert.Empty(t, s.schedule())
+
+	// only move the leader to the preferred store
+	s.tc.SetShardPreferredLeader(1, 2)
+	testutil.CheckTransferLeader(t, s.schedule()[0], operator.OpKind(0), 4, 2)
+}
+
 func TestLeaderWeight(t *testing.T) {
 	s := &testBalanceLeaderScheduler{}
 	s.setup(t)
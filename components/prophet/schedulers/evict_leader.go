@@ -16,6 +16,7 @@ package schedulers
 
 import (
 	"errors"
+	"sort"
 	"strconv"
 	"sync"
 
@@ -125,15 +126,18 @@ func (conf *evictLeaderSchedulerConfig) getSchedulerName() string {
 	return EvictLeaderName
 }
 
-type evictLeaderScheduler struct {
-	*BaseScheduler
-	conf *evictLeaderSchedulerConfig
+func (conf *evictLeaderSchedulerConfig) getStores() []uint64 {
+	conf.mu.RLock()
+	defer conf.mu.RUnlock()
+	ids := make([]uint64, 0, len(conf.StoreIDWithRanges))
+	for id := range conf.StoreIDWithRanges {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
 }
 
-// newEvictLeaderScheduler creates an admin scheduler that transfers all leaders
-// out of a container.
-func newEvictLeaderScheduler(opController *schedule.OperatorController, conf *evictLeaderSchedulerConfig) schedule.Scheduler {
-	base := NewBaseScheduler(opController)
+func (conf *evictLeaderSchedulerConfig) buildGroupStoreIDWithRangesLocked() {
 	conf.groupStoreIDWithRanges = make(map[uint64]map[uint64][]core.KeyRange)
 	for _, group := range conf.cluster.GetOpts().GetReplicationConfig().Groups {
 		ms := make(map[uint64][]core.KeyRange)
@@ -142,6 +146,18 @@ func newEvictLeaderScheduler(opController *schedule.OperatorController, conf *ev
 		}
 		conf.groupStoreIDWithRanges[group] = ms
 	}
+}
+
+type evictLeaderScheduler struct {
+	*BaseScheduler
+	conf *evictLeaderSchedulerConfig
+}
+
+// newEvictLeaderScheduler creates an admin scheduler that transfers all leaders
+// out of a container.
+func newEvictLeaderScheduler(opController *schedule.OperatorController, conf *evictLeaderSchedulerConfig) schedule.Scheduler {
+	base := NewBaseScheduler(opController)
+	conf.buildGroupStoreIDWithRangesLocked()
 	return &evictLeaderScheduler{
 		BaseScheduler: base,
 		conf:          conf,
@@ -182,6 +198,49 @@ func (s *evictLeaderScheduler) Cleanup(cluster opt.Cluster) {
 	}
 }
 
+// AddStore adds the store to evict all the leaders from, the config of the scheduler
+// is persisted.
+func (s *evictLeaderScheduler) AddStore(cluster opt.Cluster, storeID uint64) error {
+	s.conf.mu.Lock()
+	if _, ok := s.conf.StoreIDWithRanges[storeID]; ok {
+		s.conf.mu.Unlock()
+		return nil
+	}
+	if err := cluster.PauseLeaderTransfer(storeID); err != nil {
+		s.conf.mu.Unlock()
+		return err
+	}
+	s.conf.StoreIDWithRanges[storeID] = nil
+	s.conf.buildGroupStoreIDWithRangesLocked()
+	s.conf.mu.Unlock()
+	return s.conf.Persist()
+}
+
+// RemoveStore removes the store to evict the leaders from, returns true if it is
+// the last store of the scheduler, the config is not persisted in this case and
+// the scheduler should be removed by the caller.
+func (s *evictLeaderScheduler) RemoveStore(cluster opt.Cluster, storeID uint64) (bool, error) {
+	s.conf.mu.Lock()
+	if _, ok := s.conf.StoreIDWithRanges[storeID]; !ok {
+		s.conf.mu.Unlock()
+		return false, nil
+	}
+	if len(s.conf.StoreIDWithRanges) == 1 {
+		s.conf.mu.Unlock()
+		return true, nil
+	}
+	delete(s.conf.StoreIDWithRanges, storeID)
+	cluster.ResumeLeaderTransfer(storeID)
+	s.conf.buildGroupStoreIDWithRangesLocked()
+	s.conf.mu.Unlock()
+	return false, s.conf.Persist()
+}
+
+// GetStores returns the stores to evict the leaders from.
+func (s *evictLeaderScheduler) GetStores() []uint64 {
+	return s.conf.getStores()
+}
+
 func (s *evictLeaderScheduler) IsScheduleAllowed(cluster opt.Cluster) bool {
 	allowed := s.OpController.OperatorCount(operator.OpLeader) < cluster.GetOpts().GetLeaderScheduleLimit()
 	if !allowed {
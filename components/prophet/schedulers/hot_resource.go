@@ -50,21 +50,33 @@ func init() {
 		return newHotScheduler(opController, conf), nil
 	})
 
-	// FIXME: remove this two schedule after the balance test move in schedulers package
+	schedule.RegisterSliceDecoderBuilder(HotReadShardType, func(args []string) schedule.ConfigDecoder {
+		return func(v interface{}) error {
+			return nil
+		}
+	})
+	schedule.RegisterScheduler(HotReadShardType, func(opController *schedule.OperatorController, storage storage.Storage, decoder schedule.ConfigDecoder) (schedule.Scheduler, error) {
+		conf := initHotShardScheduleConfig()
+		if err := decoder(conf); err != nil {
+			return nil, err
+		}
+		conf.storage = storage
+		return newHotReadScheduler(opController, conf), nil
+	})
+
+	// FIXME: remove this schedule after the balance test move in schedulers package
 	{
 		schedule.RegisterScheduler(HotWriteShardType, func(opController *schedule.OperatorController, storage storage.Storage, decoder schedule.ConfigDecoder) (schedule.Scheduler, error) {
 			return newHotWriteScheduler(opController, initHotShardScheduleConfig()), nil
 		})
-		schedule.RegisterScheduler(HotReadShardType, func(opController *schedule.OperatorController, storage storage.Storage, decoder schedule.ConfigDecoder) (schedule.Scheduler, error) {
-			return newHotReadScheduler(opController, initHotShardScheduleConfig()), nil
-		})
-
 	}
 }
 
 const (
 	// HotShardName is balance hot resource scheduler name.
 	HotShardName = "balance-hot-resource-scheduler"
+	// HotReadShardName is balance hot read shard scheduler name.
+	HotReadShardName = "balance-hot-read-shard-scheduler"
 	// HotShardType is balance hot resource scheduler type.
 	HotShardType = "hot-resource"
 	// HotReadShardType is hot read resource scheduler type.
@@ -81,6 +93,7 @@ var schedulePeerPr = 0.66
 
 type hotScheduler struct {
 	name string
+	tp   string
 	*BaseScheduler
 	sync.RWMutex
 	leaderLimit uint64
@@ -108,6 +121,7 @@ func newHotScheduler(opController *schedule.OperatorController, conf *hotShardSc
 	base := NewBaseScheduler(opController)
 	ret := &hotScheduler{
 		name:             HotShardName,
+		tp:               HotShardType,
 		BaseScheduler:    base,
 		leaderLimit:      1,
 		peerLimit:        1,
@@ -125,7 +139,8 @@ func newHotScheduler(opController *schedule.OperatorController, conf *hotShardSc
 
 func newHotReadScheduler(opController *schedule.OperatorController, conf *hotShardSchedulerConfig) *hotScheduler {
 	ret := newHotScheduler(opController, conf)
-	ret.name = ""
+	ret.name = HotReadShardName
+	ret.tp = HotReadShardType
 	ret.types = []rwType{read}
 	return ret
 }
@@ -142,7 +157,7 @@ func (h *hotScheduler) GetName() string {
 }
 
 func (h *hotScheduler) GetType() string {
-	return HotShardType
+	return h.tp
 }
 
 func (h *hotScheduler) GetMinInterval() time.Duration {
@@ -584,6 +599,7 @@ func (bs *balanceSolver) allowBalance() bool {
 // its expectation * ratio, the container would be selected as hot source container
 func (bs *balanceSolver) filterSrcStores() map[uint64]*containerLoadDetail {
 	ret := make(map[uint64]*containerLoadDetail)
+	srcToleranceRatio := bs.getSrcToleranceRatio()
 	for id, detail := range bs.stLoadDetail {
 		if bs.cluster.GetStore(id) == nil {
 			bs.cluster.GetLogger().Error("source container not found",
@@ -594,8 +610,8 @@ func (bs *balanceSolver) filterSrcStores() map[uint64]*containerLoadDetail {
 		if len(detail.HotPeers) == 0 {
 			continue
 		}
-		if detail.LoadPred.min().ByteRate > bs.sche.conf.GetSrcToleranceRatio()*detail.LoadPred.Expect.ByteRate &&
-			detail.LoadPred.min().KeyRate > bs.sche.conf.GetSrcToleranceRatio()*detail.LoadPred.Expect.KeyRate {
+		if detail.LoadPred.min().ByteRate > srcToleranceRatio*detail.LoadPred.Expect.ByteRate &&
+			detail.LoadPred.min().KeyRate > srcToleranceRatio*detail.LoadPred.Expect.KeyRate {
 			ret[id] = detail
 			hotSchedulerResultCounter.WithLabelValues("src-container-succ", strconv.FormatUint(id, 10)).Inc()
 		}
@@ -604,6 +620,24 @@ func (bs *balanceSolver) filterSrcStores() map[uint64]*containerLoadDetail {
 	return ret
 }
 
+// getSrcToleranceRatio returns the tolerance ratio of the source stores by the
+// perspective of balance
+func (bs *balanceSolver) getSrcToleranceRatio() float64 {
+	if bs.rwTy == read {
+		return bs.sche.conf.GetReadSrcToleranceRatio()
+	}
+	return bs.sche.conf.GetSrcToleranceRatio()
+}
+
+// getDstToleranceRatio returns the tolerance ratio of the destination stores by
+// the perspective of balance
+func (bs *balanceSolver) getDstToleranceRatio() float64 {
+	if bs.rwTy == read {
+		return bs.sche.conf.GetReadDstToleranceRatio()
+	}
+	return bs.sche.conf.GetDstToleranceRatio()
+}
+
 // filterHotPeers filtered hot peers from statistics.HotPeerStat and deleted the peer if its resource is in pending status.
 // The returned hotPeer count in controlled by `max-peer-number`.
 func (bs *balanceSolver) filterHotPeers() []*statistics.HotPeerStat {
@@ -747,6 +781,9 @@ func (bs *balanceSolver) filterDstStores() map[uint64]*containerLoadDetail {
 			filter.NewSpecialUseFilter(bs.sche.GetName(), filter.SpecialUseHotShard),
 			filter.NewPlacementSafeguard(bs.sche.GetName(), bs.cluster, bs.cur.resource, srcStore),
 		}
+		if relocationFilter := filter.NewRangeRelocationFilter(bs.sche.GetName(), bs.cluster, bs.cur.resource); relocationFilter != nil {
+			filters = append(filters, relocationFilter)
+		}
 
 		for containerID := range bs.stLoadDetail {
 			candidates = append(candidates, bs.cluster.GetStore(containerID))
@@ -760,6 +797,12 @@ func (bs *balanceSolver) filterDstStores() map[uint64]*containerLoadDetail {
 		if leaderFilter := filter.NewPlacementLeaderSafeguard(bs.sche.GetName(), bs.cluster, bs.cur.resource, srcStore); leaderFilter != nil {
 			filters = append(filters, leaderFilter)
 		}
+		if priorityFilter := filter.NewLeaderPriorityFilter(bs.sche.GetName(), bs.cluster, bs.cur.resource, srcStore); priorityFilter != nil {
+			filters = append(filters, priorityFilter)
+		}
+		if relocationFilter := filter.NewRangeRelocationFilter(bs.sche.GetName(), bs.cluster, bs.cur.resource); relocationFilter != nil {
+			filters = append(filters, relocationFilter)
+		}
 
 		for _, container := range bs.cluster.GetFollowerStores(bs.cur.resource) {
 			if _, ok := bs.stLoadDetail[container.Meta.GetID()]; ok {
@@ -775,7 +818,7 @@ func (bs *balanceSolver) filterDstStores() map[uint64]*containerLoadDetail {
 
 func (bs *balanceSolver) pickDstStores(filters []filter.Filter, candidates []*core.CachedStore) map[uint64]*containerLoadDetail {
 	ret := make(map[uint64]*containerLoadDetail, len(candidates))
-	dstToleranceRatio := bs.sche.conf.GetDstToleranceRatio()
+	dstToleranceRatio := bs.getDstToleranceRatio()
 	for _, container := range candidates {
 		if filter.Target(bs.cluster.GetOpts(), container, filters) {
 			detail := bs.stLoadDetail[container.Meta.GetID()]
@@ -1001,13 +1044,25 @@ func (bs *balanceSolver) buildOperators() ([]*operator.Operator, []Influence) {
 		srcPeer, _ := bs.cur.resource.GetStorePeer(bs.cur.srcStoreID) // checked in getShardAndSrcPeer
 		dstPeer := metapb.Replica{StoreID: bs.cur.dstStoreID, Role: srcPeer.Role}
 		desc := "move-hot-" + bs.rwTy.String() + "-peer"
-		op, err = operator.CreateMovePeerOperator(
-			desc,
-			bs.cluster,
-			bs.cur.resource,
-			operator.OpHotShard,
-			bs.cur.srcStoreID,
-			dstPeer)
+		if bs.rwTy == read && bs.cur.resource.GetLeader().GetStoreID() == bs.cur.srcStoreID {
+			// the reads are served by the leader, move the leader peer and
+			// transfer the leadership to the new peer
+			op, err = operator.CreateMoveLeaderOperator(
+				desc,
+				bs.cluster,
+				bs.cur.resource,
+				operator.OpHotShard,
+				bs.cur.srcStoreID,
+				dstPeer)
+		} else {
+			op, err = operator.CreateMovePeerOperator(
+				desc,
+				bs.cluster,
+				bs.cur.resource,
+				operator.OpHotShard,
+				bs.cur.srcStoreID,
+				dstPeer)
+		}
 
 		counters = append(counters,
 			hotDirectionCounter.WithLabelValues("move-peer", bs.rwTy.String(), strconv.FormatUint(bs.cur.srcStoreID, 10), "out"),
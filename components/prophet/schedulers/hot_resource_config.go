@@ -37,6 +37,8 @@ func initHotShardScheduleConfig() *hotShardSchedulerConfig {
 		MaxPeerNum:            1000,
 		SrcToleranceRatio:     1.05, // Tolerate 5% difference
 		DstToleranceRatio:     1.05, // Tolerate 5% difference
+		ReadSrcToleranceRatio: 1.05, // Tolerate 5% difference
+		ReadDstToleranceRatio: 1.05, // Tolerate 5% difference
 	}
 }
 
@@ -58,6 +60,11 @@ type hotShardSchedulerConfig struct {
 	MinorDecRatio         float64 `json:"minor-dec-ratio"`
 	SrcToleranceRatio     float64 `json:"src-tolerance-ratio"`
 	DstToleranceRatio     float64 `json:"dst-tolerance-ratio"`
+	// read tolerance ratio decide the load difference tolerated by the read
+	// balancing, the read load of the stores fluctuates more than the write
+	// load, so it is configured separately
+	ReadSrcToleranceRatio float64 `json:"read-src-tolerance-ratio"`
+	ReadDstToleranceRatio float64 `json:"read-dst-tolerance-ratio"`
 }
 
 func (conf *hotShardSchedulerConfig) EncodeConfig() ([]byte, error) {
@@ -102,6 +109,30 @@ func (conf *hotShardSchedulerConfig) SetDstToleranceRatio(tol float64) {
 	conf.DstToleranceRatio = tol
 }
 
+func (conf *hotShardSchedulerConfig) GetReadSrcToleranceRatio() float64 {
+	conf.RLock()
+	defer conf.RUnlock()
+	return conf.ReadSrcToleranceRatio
+}
+
+func (conf *hotShardSchedulerConfig) SetReadSrcToleranceRatio(tol float64) {
+	conf.Lock()
+	defer conf.Unlock()
+	conf.ReadSrcToleranceRatio = tol
+}
+
+func (conf *hotShardSchedulerConfig) GetReadDstToleranceRatio() float64 {
+	conf.RLock()
+	defer conf.RUnlock()
+	return conf.ReadDstToleranceRatio
+}
+
+func (conf *hotShardSchedulerConfig) SetReadDstToleranceRatio(tol float64) {
+	conf.Lock()
+	defer conf.Unlock()
+	conf.ReadDstToleranceRatio = tol
+}
+
 func (conf *hotShardSchedulerConfig) GetByteRankStepRatio() float64 {
 	conf.RLock()
 	defer conf.RUnlock()
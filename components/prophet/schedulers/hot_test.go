@@ -618,266 +618,265 @@ func TestWithRuleEnabled(t *testing.T) {
 	}
 }
 
-// TODO: fix testcase
-// func TestHotReadByteRateOnly(t *testing.T) {
-// 	ctx, cancel := context.WithCancel(context.Background())
-// 	defer cancel()
-// 	opt := config.NewTestOptions()
-// 	tc := mockcluster.NewCluster(opt)
-// 	tc.DisableJointConsensus()
-// 	hb, err := schedule.CreateScheduler(HotReadShardType, schedule.NewOperatorController(ctx, tc, nil), storage.NewTestStorage(), nil)
-// 	assert.NoError(t, err)
-// 	tc.SetHotShardCacheHitsThreshold(0)
-
-// 	// Add containers 1, 2, 3, 4, 5 with resource counts 3, 2, 2, 2, 0.
-// 	tc.AddShardStore(1, 3)
-// 	tc.AddShardStore(2, 2)
-// 	tc.AddShardStore(3, 2)
-// 	tc.AddShardStore(4, 2)
-// 	tc.AddShardStore(5, 0)
-
-// 	//| container_id | read_bytes_rate |
-// 	//|--------------|-----------------|
-// 	//|       1      |     7.5MB       |
-// 	//|       2      |     4.9MB       |
-// 	//|       3      |     3.7MB       |
-// 	//|       4      |       6MB       |
-// 	//|       5      |       0MB       |
-// 	tc.UpdateStorageReadBytes(1, 7.5*MB*statistics.StoreHeartBeatReportInterval)
-// 	tc.UpdateStorageReadBytes(2, 4.9*MB*statistics.StoreHeartBeatReportInterval)
-// 	tc.UpdateStorageReadBytes(3, 3.7*MB*statistics.StoreHeartBeatReportInterval)
-// 	tc.UpdateStorageReadBytes(4, 6*MB*statistics.StoreHeartBeatReportInterval)
-// 	tc.UpdateStorageReadBytes(5, 0)
-
-// 	//| resource_id | leader_container | follower_container | follower_container |   read_bytes_rate  |
-// 	//|-------------|------------------|--------------------|--------------------|--------------------|
-// 	//|     1       |       1          |        2           |       3            |        512KB       |
-// 	//|     2       |       2          |        1           |       3            |        512KB       |
-// 	//|     3       |       1          |        2           |       3            |        512KB       |
-// 	//|     11      |       1          |        2           |       3            |          7KB       |
-// 	// resource 1, 2 and 3 are hot resources.
-// 	addCachedShard(tc, read, []testCachedShard{
-// 		{1, []uint64{1, 2, 3}, 512 * KB, 512 * KB / 100},
-// 		{2, []uint64{2, 1, 3}, 512 * KB, 512 * KB / 100},
-// 		{3, []uint64{1, 2, 3}, 512 * KB, 512 * KB / 100},
-// 		{11, []uint64{1, 2, 3}, 7 * KB, 7 * KB / 100},
-// 	})
-
-// 	assert.True(t, tc.IsShardHot(tc.GetShard(1)))
-// 	assert.False(t, tc.IsShardHot(tc.GetShard(11)))
-// 	// check randomly pick hot resource
-// 	r := tc.RandHotShardFromStore(2, statistics.ReadFlow)
-// 	assert.NotNil(t, r)
-// 	assert.Equal(t, uint64(2), r.Meta.ID())
-// 	// check hot items
-// 	stats := tc.HotCache.ShardStats(statistics.ReadFlow, 0)
-// 	assert.Equal(t, 2, len(stats))
-// 	for _, ss := range stats {
-// 		for _, s := range ss {
-// 			assert.Equal(t, 512.0*KB, s.GetByteRate())
-// 		}
-// 	}
-
-// 	testutil.CheckTransferLeader(t, hb.Schedule(tc)[0], operator.OpHotShard, 1, 3)
-// 	hb.(*hotScheduler).clearPendingInfluence()
-// 	// assume handle the operator
-// 	tc.AddLeaderShardWithReadInfo(3, 3, 512*KB*statistics.ShardHeartBeatReportInterval, 0, statistics.ShardHeartBeatReportInterval, []uint64{1, 2})
-// 	// After transfer a hot resource leader from container 1 to container 3
-// 	// the three resource leader will be evenly distributed in three containers
-
-// 	//| container_id | read_bytes_rate |
-// 	//|----------|-----------------|
-// 	//|    1     |       6MB       |
-// 	//|    2     |       5.5MB     |
-// 	//|    3     |       5.5MB     |
-// 	//|    4     |       3.4MB     |
-// 	//|    5     |       3MB       |
-// 	tc.UpdateStorageReadBytes(1, 6*MB*statistics.StoreHeartBeatReportInterval)
-// 	tc.UpdateStorageReadBytes(2, 5.5*MB*statistics.StoreHeartBeatReportInterval)
-// 	tc.UpdateStorageReadBytes(3, 5.5*MB*statistics.StoreHeartBeatReportInterval)
-// 	tc.UpdateStorageReadBytes(4, 3.4*MB*statistics.StoreHeartBeatReportInterval)
-// 	tc.UpdateStorageReadBytes(5, 3*MB*statistics.StoreHeartBeatReportInterval)
-
-// 	//| resource_id | leader_container | follower_container | follower_container |   read_bytes_rate  |
-// 	//|-----------|--------------|----------------|----------------|--------------------|
-// 	//|     1     |       1      |        2       |       3        |        512KB       |
-// 	//|     2     |       2      |        1       |       3        |        512KB       |
-// 	//|     3     |       3      |        2       |       1        |        512KB       |
-// 	//|     4     |       1      |        2       |       3        |        512KB       |
-// 	//|     5     |       4      |        2       |       5        |        512KB       |
-// 	//|     11    |       1      |        2       |       3        |         24KB       |
-// 	addCachedShard(tc, read, []testCachedShard{
-// 		{4, []uint64{1, 2, 3}, 512 * KB, 0},
-// 		{5, []uint64{4, 2, 5}, 512 * KB, 0},
-// 	})
-
-// 	// We will move leader peer of resource 1 from 1 to 5
-// 	testutil.CheckTransferPeerWithLeaderTransfer(t, hb.Schedule(tc)[0], operator.OpHotShard, 1, 5)
-// 	hb.(*hotScheduler).clearPendingInfluence()
-
-// 	// Should not panic if resource not found.
-// 	for i := uint64(1); i <= 3; i++ {
-// 		tc.Shards.RemoveShard(tc.GetShard(i))
-// 	}
-// 	hb.Schedule(tc)
-// 	hb.(*hotScheduler).clearPendingInfluence()
-// }
-
-// TODO: fix testcase
-// func TestHotReadWithKeyRate(t *testing.T) {
-// 	ctx, cancel := context.WithCancel(context.Background())
-// 	defer cancel()
-// 	statistics.Denoising = false
-// 	opt := config.NewTestOptions()
-
-// 	tc := mockcluster.NewCluster(opt)
-// 	tc.SetHotShardCacheHitsThreshold(0)
-// 	tc.AddShardStore(1, 20)
-// 	tc.AddShardStore(2, 20)
-// 	tc.AddShardStore(3, 20)
-// 	tc.AddShardStore(4, 20)
-// 	tc.AddShardStore(5, 20)
-
-// 	tc.UpdateStorageReadStats(1, 10.5*MB*statistics.StoreHeartBeatReportInterval, 10.5*MB*statistics.StoreHeartBeatReportInterval)
-// 	tc.UpdateStorageReadStats(2, 9.5*MB*statistics.StoreHeartBeatReportInterval, 9.5*MB*statistics.StoreHeartBeatReportInterval)
-// 	tc.UpdateStorageReadStats(3, 9.5*MB*statistics.StoreHeartBeatReportInterval, 9.8*MB*statistics.StoreHeartBeatReportInterval)
-// 	tc.UpdateStorageReadStats(4, 9*MB*statistics.StoreHeartBeatReportInterval, 9*MB*statistics.StoreHeartBeatReportInterval)
-// 	tc.UpdateStorageReadStats(5, 8.9*MB*statistics.StoreHeartBeatReportInterval, 9.2*MB*statistics.StoreHeartBeatReportInterval)
-
-// 	hb, err := schedule.CreateScheduler(HotReadShardType, schedule.NewOperatorController(ctx, tc, nil), storage.NewTestStorage(), nil)
-// 	assert.NoError(t, err)
-// 	hb.(*hotScheduler).conf.SetSrcToleranceRatio(1)
-// 	hb.(*hotScheduler).conf.SetDstToleranceRatio(1)
-
-// 	addCachedShard(tc, read, []testCachedShard{
-// 		{1, []uint64{1, 2, 4}, 0.5 * MB, 0.5 * MB},
-// 		{2, []uint64{1, 2, 4}, 0.5 * MB, 0.5 * MB},
-// 		{3, []uint64{3, 4, 5}, 0.05 * MB, 0.1 * MB},
-// 	})
-
-// 	for i := 0; i < 100; i++ {
-// 		hb.(*hotScheduler).clearPendingInfluence()
-// 		op := hb.Schedule(tc)[0]
-// 		// byteDecRatio <= 0.95 && keyDecRatio <= 0.95
-// 		testutil.CheckTransferLeader(t, op, operator.OpHotShard, 1, 4)
-// 		// container byte rate (min, max): (10, 10.5) | 9.5 | 9.5 | (9, 9.5) | 8.9
-// 		// container key rate (min, max):  (10, 10.5) | 9.5 | 9.8 | (9, 9.5) | 9.2
-
-// 		op = hb.Schedule(tc)[0]
-// 		// byteDecRatio <= 0.99 && keyDecRatio <= 0.95
-// 		testutil.CheckTransferLeader(t, op, operator.OpHotShard, 3, 5)
-// 		// container byte rate (min, max): (10, 10.5) | 9.5 | (9.45, 9.5) | (9, 9.5) | (8.9, 8.95)
-// 		// container key rate (min, max):  (10, 10.5) | 9.5 | (9.7, 9.8) | (9, 9.5) | (9.2, 9.3)
-
-// 		// byteDecRatio <= 0.95
-// 		// FIXME: cover this case
-// 		// op = hb.Schedule(tc)[0]
-// 		// testutil.CheckTransferPeerWithLeaderTransfer(t, op, operator.OpHotShard, 1, 5)
-// 		// container byte rate (min, max): (9.5, 10.5) | 9.5 | (9.45, 9.5) | (9, 9.5) | (8.9, 9.45)
-// 		// container key rate (min, max):  (9.2, 10.2) | 9.5 | (9.7, 9.8) | (9, 9.5) | (9.2, 9.8)
-// 	}
-// }
-
-// TODO: fix testcase
-// func TestHotReadWithPendingInfluence(t *testing.T) {
-// 	ctx, cancel := context.WithCancel(context.Background())
-// 	defer cancel()
-// 	opt := config.NewTestOptions()
-// 	hb, err := schedule.CreateScheduler(HotReadShardType, schedule.NewOperatorController(ctx, mockcluster.NewCluster(opt), nil), storage.NewTestStorage(), nil)
-// 	assert.NoError(t, err)
-// 	// For test
-// 	hb.(*hotScheduler).conf.GreatDecRatio = 0.99
-// 	hb.(*hotScheduler).conf.MinorDecRatio = 1
-// 	hb.(*hotScheduler).conf.DstToleranceRatio = 1
-
-// 	for i := 0; i < 2; i++ {
-// 		// 0: byte rate
-// 		// 1: key rate
-// 		tc := mockcluster.NewCluster(opt)
-// 		tc.SetHotShardCacheHitsThreshold(0)
-// 		tc.DisableJointConsensus()
-// 		tc.AddShardStore(1, 20)
-// 		tc.AddShardStore(2, 20)
-// 		tc.AddShardStore(3, 20)
-// 		tc.AddShardStore(4, 20)
-
-// 		updateStore := tc.UpdateStorageReadBytes // byte rate
-// 		if i == 1 {                                  // key rate
-// 			updateStore = tc.UpdateStorageReadKeys
-// 		}
-// 		updateStore(1, 7.1*MB*statistics.StoreHeartBeatReportInterval)
-// 		updateStore(2, 6.1*MB*statistics.StoreHeartBeatReportInterval)
-// 		updateStore(3, 6*MB*statistics.StoreHeartBeatReportInterval)
-// 		updateStore(4, 5*MB*statistics.StoreHeartBeatReportInterval)
-
-// 		if i == 0 { // byte rate
-// 			addCachedShard(tc, read, []testCachedShard{
-// 				{1, []uint64{1, 2, 3}, 512 * KB, 0},
-// 				{2, []uint64{1, 2, 3}, 512 * KB, 0},
-// 				{3, []uint64{1, 2, 3}, 512 * KB, 0},
-// 				{4, []uint64{1, 2, 3}, 512 * KB, 0},
-// 				{5, []uint64{2, 1, 3}, 512 * KB, 0},
-// 				{6, []uint64{2, 1, 3}, 512 * KB, 0},
-// 				{7, []uint64{3, 2, 1}, 512 * KB, 0},
-// 				{8, []uint64{3, 2, 1}, 512 * KB, 0},
-// 			})
-// 		} else if i == 1 { // key rate
-// 			addCachedShard(tc, read, []testCachedShard{
-// 				{1, []uint64{1, 2, 3}, 0, 512 * KB},
-// 				{2, []uint64{1, 2, 3}, 0, 512 * KB},
-// 				{3, []uint64{1, 2, 3}, 0, 512 * KB},
-// 				{4, []uint64{1, 2, 3}, 0, 512 * KB},
-// 				{5, []uint64{2, 1, 3}, 0, 512 * KB},
-// 				{6, []uint64{2, 1, 3}, 0, 512 * KB},
-// 				{7, []uint64{3, 2, 1}, 0, 512 * KB},
-// 				{8, []uint64{3, 2, 1}, 0, 512 * KB},
-// 			})
-// 		}
-
-// 		for i := 0; i < 20; i++ {
-// 			hb.(*hotScheduler).clearPendingInfluence()
-
-// 			op1 := hb.Schedule(tc)[0]
-// 			testutil.CheckTransferLeader(t, op1, operator.OpLeader, 1, 3)
-// 			// container byte/key rate (min, max): (6.6, 7.1) | 6.1 | (6, 6.5) | 5
-
-// 			op2 := hb.Schedule(tc)[0]
-// 			testutil.CheckTransferPeerWithLeaderTransfer(t, op2, operator.OpHotShard, 1, 4)
-// 			// container byte/key rate (min, max): (6.1, 7.1) | 6.1 | (6, 6.5) | (5, 5.5)
-
-// 			ops := hb.Schedule(tc)
-// 			t.Logf("%v", ops)
-// 			assert.Empty(t, ops)
-// 		}
-// 		for i := 0; i < 20; i++ {
-// 			hb.(*hotScheduler).clearPendingInfluence()
-
-// 			op1 := hb.Schedule(tc)[0]
-// 			testutil.CheckTransferLeader(t, op1, operator.OpLeader, 1, 3)
-// 			// container byte/key rate (min, max): (6.6, 7.1) | 6.1 | (6, 6.5) | 5
-
-// 			op2 := hb.Schedule(tc)[0]
-// 			testutil.CheckTransferPeerWithLeaderTransfer(t, op2, operator.OpHotShard, 1, 4)
-// 			// container bytekey rate (min, max): (6.1, 7.1) | 6.1 | (6, 6.5) | (5, 5.5)
-// 			assert.True(t, op2.Cancel())
-// 			// container byte/key rate (min, max): (6.6, 7.1) | 6.1 | (6, 6.5) | 5
-
-// 			op2 = hb.Schedule(tc)[0]
-// 			testutil.CheckTransferPeerWithLeaderTransfer(t, op2, operator.OpHotShard, 1, 4)
-// 			// container byte/key rate (min, max): (6.1, 7.1) | 6.1 | (6, 6.5) | (5, 5.5)
-
-// 			assert.True(t, op1.Cancel())
-// 			// container byte/key rate (min, max): (6.6, 7.1) | 6.1 | 6 | (5, 5.5)
-
-// 			op3 := hb.Schedule(tc)[0]
-// 			testutil.CheckTransferPeerWithLeaderTransfer(t, op3, operator.OpHotShard, 1, 4)
-// 			// container byte/key rate (min, max): (6.1, 7.1) | 6.1 | 6 | (5, 6)
-
-// 			ops := hb.Schedule(tc)
-// 			assert.Empty(t, ops)
-// 		}
-// 	}
-// }
+func TestHotReadByteRateOnly(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	opt := config.NewTestOptions()
+	tc := mockcluster.NewCluster(opt)
+	tc.DisableJointConsensus()
+	hb, err := schedule.CreateScheduler(HotReadShardType, schedule.NewOperatorController(ctx, tc, nil), storage.NewTestStorage(), schedule.ConfigJSONDecoder([]byte("null")))
+	assert.NoError(t, err)
+	tc.SetHotShardCacheHitsThreshold(0)
+	assert.Equal(t, HotReadShardName, hb.GetName())
+	assert.Equal(t, HotReadShardType, hb.GetType())
+
+	// Add containers 1, 2, 3, 4, 5 with resource counts 3, 2, 2, 2, 0.
+	tc.AddShardStore(1, 3)
+	tc.AddShardStore(2, 2)
+	tc.AddShardStore(3, 2)
+	tc.AddShardStore(4, 2)
+	tc.AddShardStore(5, 0)
+
+	//| container_id | read_bytes_rate |
+	//|--------------|-----------------|
+	//|       1      |     7.5MB       |
+	//|       2      |     4.9MB       |
+	//|       3      |     3.7MB       |
+	//|       4      |       6MB       |
+	//|       5      |       0MB       |
+	tc.UpdateStorageReadBytes(1, 7.5*MB*statistics.StoreHeartBeatReportInterval)
+	tc.UpdateStorageReadBytes(2, 4.9*MB*statistics.StoreHeartBeatReportInterval)
+	tc.UpdateStorageReadBytes(3, 3.7*MB*statistics.StoreHeartBeatReportInterval)
+	tc.UpdateStorageReadBytes(4, 6*MB*statistics.StoreHeartBeatReportInterval)
+	tc.UpdateStorageReadBytes(5, 0)
+
+	//| resource_id | leader_container | follower_container | follower_container |   read_bytes_rate  |
+	//|-------------|------------------|--------------------|--------------------|--------------------|
+	//|     1       |       1          |        2           |       3            |        512KB       |
+	//|     2       |       2          |        1           |       3            |        512KB       |
+	//|     3       |       1          |        2           |       3            |        512KB       |
+	//|     11      |       1          |        2           |       3            |          7KB       |
+	// resource 1, 2 and 3 are hot resources.
+	addCachedShard(tc, read, []testCachedShard{
+		{1, []uint64{1, 2, 3}, 512 * KB, 512 * KB / 100},
+		{2, []uint64{2, 1, 3}, 512 * KB, 512 * KB / 100},
+		{3, []uint64{1, 2, 3}, 512 * KB, 512 * KB / 100},
+		{11, []uint64{1, 2, 3}, 7 * KB, 7 * KB / 100},
+	})
+
+	assert.True(t, tc.IsShardHot(tc.GetShard(1)))
+	assert.False(t, tc.IsShardHot(tc.GetShard(11)))
+	// check randomly pick hot resource
+	r := tc.RandHotShardFromStore(2, statistics.ReadFlow)
+	assert.NotNil(t, r)
+	assert.Equal(t, uint64(2), r.Meta.GetID())
+	// check hot items
+	stats := tc.HotCache.ShardStats(statistics.ReadFlow, 0)
+	assert.Equal(t, 2, len(stats))
+	for _, ss := range stats {
+		for _, s := range ss {
+			assert.Equal(t, 512.0*KB, s.GetByteRate())
+		}
+	}
+
+	testutil.CheckTransferLeader(t, hb.Schedule(tc)[0], operator.OpHotShard, 1, 3)
+	hb.(*hotScheduler).clearPendingInfluence()
+	// assume handle the operator
+	tc.AddLeaderShardWithReadInfo(3, 3, 512*KB*statistics.ShardHeartBeatReportInterval, 0, statistics.ShardHeartBeatReportInterval, []uint64{1, 2})
+	// After transfer a hot resource leader from container 1 to container 3
+	// the three resource leader will be evenly distributed in three containers
+
+	//| container_id | read_bytes_rate |
+	//|----------|-----------------|
+	//|    1     |       6MB       |
+	//|    2     |       5.5MB     |
+	//|    3     |       5.5MB     |
+	//|    4     |       3.4MB     |
+	//|    5     |       3MB       |
+	tc.UpdateStorageReadBytes(1, 6*MB*statistics.StoreHeartBeatReportInterval)
+	tc.UpdateStorageReadBytes(2, 5.5*MB*statistics.StoreHeartBeatReportInterval)
+	tc.UpdateStorageReadBytes(3, 5.5*MB*statistics.StoreHeartBeatReportInterval)
+	tc.UpdateStorageReadBytes(4, 3.4*MB*statistics.StoreHeartBeatReportInterval)
+	tc.UpdateStorageReadBytes(5, 3*MB*statistics.StoreHeartBeatReportInterval)
+
+	//| resource_id | leader_container | follower_container | follower_container |   read_bytes_rate  |
+	//|-----------|--------------|----------------|----------------|--------------------|
+	//|     1     |       1      |        2       |       3        |        512KB       |
+	//|     2     |       2      |        1       |       3        |        512KB       |
+	//|     3     |       3      |        2       |       1        |        512KB       |
+	//|     4     |       1      |        2       |       3        |        512KB       |
+	//|     5     |       4      |        2       |       5        |        512KB       |
+	//|     11    |       1      |        2       |       3        |         24KB       |
+	addCachedShard(tc, read, []testCachedShard{
+		{4, []uint64{1, 2, 3}, 512 * KB, 0},
+		{5, []uint64{4, 2, 5}, 512 * KB, 0},
+	})
+
+	// We will move leader peer of resource 1 from 1 to 5
+	testutil.CheckTransferPeerWithLeaderTransfer(t, hb.Schedule(tc)[0], operator.OpHotShard, 1, 5)
+	hb.(*hotScheduler).clearPendingInfluence()
+
+	// Should not panic if resource not found.
+	for i := uint64(1); i <= 3; i++ {
+		tc.Shards.RemoveShard(tc.GetShard(i))
+	}
+	hb.Schedule(tc)
+	hb.(*hotScheduler).clearPendingInfluence()
+}
+
+func TestHotReadWithKeyRate(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	statistics.Denoising = false
+	opt := config.NewTestOptions()
+
+	tc := mockcluster.NewCluster(opt)
+	tc.SetHotShardCacheHitsThreshold(0)
+	tc.AddShardStore(1, 20)
+	tc.AddShardStore(2, 20)
+	tc.AddShardStore(3, 20)
+	tc.AddShardStore(4, 20)
+	tc.AddShardStore(5, 20)
+
+	tc.UpdateStorageReadStats(1, 10.5*MB*statistics.StoreHeartBeatReportInterval, 10.5*MB*statistics.StoreHeartBeatReportInterval)
+	tc.UpdateStorageReadStats(2, 9.5*MB*statistics.StoreHeartBeatReportInterval, 9.5*MB*statistics.StoreHeartBeatReportInterval)
+	tc.UpdateStorageReadStats(3, 9.5*MB*statistics.StoreHeartBeatReportInterval, 9.8*MB*statistics.StoreHeartBeatReportInterval)
+	tc.UpdateStorageReadStats(4, 9*MB*statistics.StoreHeartBeatReportInterval, 9*MB*statistics.StoreHeartBeatReportInterval)
+	tc.UpdateStorageReadStats(5, 8.9*MB*statistics.StoreHeartBeatReportInterval, 9.2*MB*statistics.StoreHeartBeatReportInterval)
+
+	hb, err := schedule.CreateScheduler(HotReadShardType, schedule.NewOperatorController(ctx, tc, nil), storage.NewTestStorage(), schedule.ConfigJSONDecoder([]byte("null")))
+	assert.NoError(t, err)
+	hb.(*hotScheduler).conf.SetReadSrcToleranceRatio(1)
+	hb.(*hotScheduler).conf.SetReadDstToleranceRatio(1)
+
+	addCachedShard(tc, read, []testCachedShard{
+		{1, []uint64{1, 2, 4}, 0.5 * MB, 0.5 * MB},
+		{2, []uint64{1, 2, 4}, 0.5 * MB, 0.5 * MB},
+		{3, []uint64{3, 4, 5}, 0.05 * MB, 0.1 * MB},
+	})
+
+	for i := 0; i < 100; i++ {
+		hb.(*hotScheduler).clearPendingInfluence()
+		op := hb.Schedule(tc)[0]
+		// byteDecRatio <= 0.95 && keyDecRatio <= 0.95
+		testutil.CheckTransferLeader(t, op, operator.OpHotShard, 1, 4)
+		// container byte rate (min, max): (10, 10.5) | 9.5 | 9.5 | (9, 9.5) | 8.9
+		// container key rate (min, max):  (10, 10.5) | 9.5 | 9.8 | (9, 9.5) | 9.2
+
+		op = hb.Schedule(tc)[0]
+		// byteDecRatio <= 0.99 && keyDecRatio <= 0.95
+		testutil.CheckTransferLeader(t, op, operator.OpHotShard, 3, 5)
+		// container byte rate (min, max): (10, 10.5) | 9.5 | (9.45, 9.5) | (9, 9.5) | (8.9, 8.95)
+		// container key rate (min, max):  (10, 10.5) | 9.5 | (9.7, 9.8) | (9, 9.5) | (9.2, 9.3)
+
+		// byteDecRatio <= 0.95
+		// FIXME: cover this case
+		// op = hb.Schedule(tc)[0]
+		// testutil.CheckTransferPeerWithLeaderTransfer(t, op, operator.OpHotShard, 1, 5)
+		// container byte rate (min, max): (9.5, 10.5) | 9.5 | (9.45, 9.5) | (9, 9.5) | (8.9, 9.45)
+		// container key rate (min, max):  (9.2, 10.2) | 9.5 | (9.7, 9.8) | (9, 9.5) | (9.2, 9.8)
+	}
+}
+
+func TestHotReadWithPendingInfluence(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	opt := config.NewTestOptions()
+	hb, err := schedule.CreateScheduler(HotReadShardType, schedule.NewOperatorController(ctx, mockcluster.NewCluster(opt), nil), storage.NewTestStorage(), schedule.ConfigJSONDecoder([]byte("null")))
+	assert.NoError(t, err)
+	// For test
+	hb.(*hotScheduler).conf.GreatDecRatio = 0.99
+	hb.(*hotScheduler).conf.MinorDecRatio = 1
+	hb.(*hotScheduler).conf.ReadDstToleranceRatio = 1
+
+	for i := 0; i < 2; i++ {
+		// 0: byte rate
+		// 1: key rate
+		tc := mockcluster.NewCluster(opt)
+		tc.SetHotShardCacheHitsThreshold(0)
+		tc.DisableJointConsensus()
+		tc.AddShardStore(1, 20)
+		tc.AddShardStore(2, 20)
+		tc.AddShardStore(3, 20)
+		tc.AddShardStore(4, 20)
+
+		updateStore := tc.UpdateStorageReadBytes // byte rate
+		if i == 1 {                              // key rate
+			updateStore = tc.UpdateStorageReadKeys
+		}
+		updateStore(1, 7.1*MB*statistics.StoreHeartBeatReportInterval)
+		updateStore(2, 6.1*MB*statistics.StoreHeartBeatReportInterval)
+		updateStore(3, 6*MB*statistics.StoreHeartBeatReportInterval)
+		updateStore(4, 5*MB*statistics.StoreHeartBeatReportInterval)
+
+		if i == 0 { // byte rate
+			addCachedShard(tc, read, []testCachedShard{
+				{1, []uint64{1, 2, 3}, 512 * KB, 0},
+				{2, []uint64{1, 2, 3}, 512 * KB, 0},
+				{3, []uint64{1, 2, 3}, 512 * KB, 0},
+				{4, []uint64{1, 2, 3}, 512 * KB, 0},
+				{5, []uint64{2, 1, 3}, 512 * KB, 0},
+				{6, []uint64{2, 1, 3}, 512 * KB, 0},
+				{7, []uint64{3, 2, 1}, 512 * KB, 0},
+				{8, []uint64{3, 2, 1}, 512 * KB, 0},
+			})
+		} else if i == 1 { // key rate
+			addCachedShard(tc, read, []testCachedShard{
+				{1, []uint64{1, 2, 3}, 0, 512 * KB},
+				{2, []uint64{1, 2, 3}, 0, 512 * KB},
+				{3, []uint64{1, 2, 3}, 0, 512 * KB},
+				{4, []uint64{1, 2, 3}, 0, 512 * KB},
+				{5, []uint64{2, 1, 3}, 0, 512 * KB},
+				{6, []uint64{2, 1, 3}, 0, 512 * KB},
+				{7, []uint64{3, 2, 1}, 0, 512 * KB},
+				{8, []uint64{3, 2, 1}, 0, 512 * KB},
+			})
+		}
+
+		for i := 0; i < 20; i++ {
+			hb.(*hotScheduler).clearPendingInfluence()
+
+			op1 := hb.Schedule(tc)[0]
+			testutil.CheckTransferLeader(t, op1, operator.OpLeader, 1, 3)
+			// container byte/key rate (min, max): (6.6, 7.1) | 6.1 | (6, 6.5) | 5
+
+			op2 := hb.Schedule(tc)[0]
+			testutil.CheckTransferPeerWithLeaderTransfer(t, op2, operator.OpHotShard, 1, 4)
+			// container byte/key rate (min, max): (6.1, 7.1) | 6.1 | (6, 6.5) | (5, 5.5)
+
+			ops := hb.Schedule(tc)
+			t.Logf("%v", ops)
+			assert.Empty(t, ops)
+		}
+		for i := 0; i < 20; i++ {
+			hb.(*hotScheduler).clearPendingInfluence()
+
+			op1 := hb.Schedule(tc)[0]
+			testutil.CheckTransferLeader(t, op1, operator.OpLeader, 1, 3)
+			// container byte/key rate (min, max): (6.6, 7.1) | 6.1 | (6, 6.5) | 5
+
+			op2 := hb.Schedule(tc)[0]
+			testutil.CheckTransferPeerWithLeaderTransfer(t, op2, operator.OpHotShard, 1, 4)
+			// container bytekey rate (min, max): (6.1, 7.1) | 6.1 | (6, 6.5) | (5, 5.5)
+			assert.True(t, op2.Cancel())
+			// container byte/key rate (min, max): (6.6, 7.1) | 6.1 | (6, 6.5) | 5
+
+			op2 = hb.Schedule(tc)[0]
+			testutil.CheckTransferPeerWithLeaderTransfer(t, op2, operator.OpHotShard, 1, 4)
+			// container byte/key rate (min, max): (6.1, 7.1) | 6.1 | (6, 6.5) | (5, 5.5)
+
+			assert.True(t, op1.Cancel())
+			// container byte/key rate (min, max): (6.6, 7.1) | 6.1 | 6 | (5, 5.5)
+
+			op3 := hb.Schedule(tc)[0]
+			testutil.CheckTransferPeerWithLeaderTransfer(t, op3, operator.OpHotShard, 1, 4)
+			// container byte/key rate (min, max): (6.1, 7.1) | 6.1 | 6 | (5, 6)
+
+			ops := hb.Schedule(tc)
+			assert.Empty(t, ops)
+		}
+	}
+}
 
 func TestUpdateCache(t *testing.T) {
 	opt := config.NewTestOptions()
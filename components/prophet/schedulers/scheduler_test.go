@@ -139,7 +139,7 @@ func TestAbnormalReplica(t *testing.T) {
 	opt := config.NewTestOptions()
 	tc := mockcluster.NewCluster(opt)
 	tc.SetLeaderScheduleLimit(0)
-	hb, err := schedule.CreateScheduler(HotReadShardType, schedule.NewOperatorController(ctx, tc, nil), storage.NewTestStorage(), nil)
+	hb, err := schedule.CreateScheduler(HotReadShardType, schedule.NewOperatorController(ctx, tc, nil), storage.NewTestStorage(), schedule.ConfigJSONDecoder([]byte("null")))
 	assert.NoError(t, err)
 
 	tc.AddShardStore(1, 3)
@@ -206,7 +206,7 @@ func (r *RollingStoreStats) Set(stats *metapb.StoreStats) {
 	r.timeMedians[StoreWriteBytes].Set(float64(stats.WrittenBytes) / float64(interval))
 	r.timeMedians[StoreReadBytes].Set(float64(stats.ReadBytes) / float64(interval))
 	r.timeMedians[StoreWriteKeys].Set(float64(stats.WrittenKeys) / float64(interval))
-	r.timeMedians[StoreReadKeys].Set(float64(stats.WrittenKeys) / float64(interval))
+	r.timeMedians[StoreReadKeys].Set(float64(stats.ReadKeys) / float64(interval))
 	r.movingAvgs[StoreCPUUsage].Set(collect(stats.GetCpuUsages()))
 	r.movingAvgs[StoreDiskReadRate].Set(collect(stats.GetReadIORates()))
 	r.movingAvgs[StoreDiskWriteRate].Set(collect(stats.GetWriteIORates()))
@@ -37,6 +37,7 @@ type containerStatistics struct {
 	Offline         int
 	Tombstone       int
 	LowSpace        int
+	Maintenance     int
 	StorageSize     uint64
 	StorageCapacity uint64
 	ShardCount      int
@@ -87,6 +88,9 @@ func (s *containerStatistics) Observe(container *core.CachedStore, stats *Stores
 	if container.IsLowSpace(s.opt.GetLowSpaceRatio()) {
 		s.LowSpace++
 	}
+	if container.IsMaintenance() {
+		s.Maintenance++
+	}
 
 	// Store stats.
 	s.StorageSize += container.StorageSize()
@@ -138,6 +142,7 @@ func (s *containerStatistics) Collect() {
 	metrics["container_offline_count"] = float64(s.Offline)
 	metrics["container_tombstone_count"] = float64(s.Tombstone)
 	metrics["container_low_space_count"] = float64(s.LowSpace)
+	metrics["container_maintenance_count"] = float64(s.Maintenance)
 	metrics["resource_count"] = float64(s.ShardCount)
 	metrics["leader_count"] = float64(s.LeaderCount)
 	metrics["storage_size"] = float64(s.StorageSize)
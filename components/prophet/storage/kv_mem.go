@@ -35,7 +35,8 @@ type memStorage struct {
 	tree *btree.BTree
 }
 
-func newMemKV() KV {
+// NewMemKV returns a KV based on memory, used in testing and the standalone mode
+func NewMemKV() KV {
 	return &memStorage{
 		tree: btree.New(2),
 	}
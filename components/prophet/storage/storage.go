@@ -90,6 +90,8 @@ type ShardStorage interface {
 
 	// PutShardAndExtra puts the meta and the extra data to the storage
 	PutShardAndExtra(meta metapb.Shard, extra []byte) error
+	// PutShardsAndExtras puts the metas and the extra data of the shards in batch
+	PutShardsAndExtras(metas []metapb.Shard, extras [][]byte) error
 	// PutShardExtra returns the resource extra data
 	PutShardExtra(id uint64, extra []byte) error
 	// GetShardExtra returns the resource extra data
@@ -97,6 +99,32 @@ type ShardStorage interface {
 
 	PutScheduleGroupRule(metapb.ScheduleGroupRule) error
 	LoadScheduleGroupRules(limit int64, do func(metapb.ScheduleGroupRule)) error
+
+	// PutPreferredLeader puts the preferred leader store of the shard
+	PutPreferredLeader(shardID, storeID uint64) error
+	// RemovePreferredLeader removes the preferred leader store of the shard
+	RemovePreferredLeader(shardID uint64) error
+	// LoadPreferredLeaders loads the preferred leader stores of all shards
+	LoadPreferredLeaders(limit int64, do func(shardID, storeID uint64)) error
+
+	// PutRangeRelocation puts the range relocation
+	PutRangeRelocation(metapb.RangeRelocation) error
+	// RemoveRangeRelocation removes the range relocation
+	RemoveRangeRelocation(id uint64) error
+	// LoadRangeRelocations loads all the range relocations
+	LoadRangeRelocations(limit int64, do func(metapb.RangeRelocation)) error
+
+	// PutLabelSteering puts the label steering
+	PutLabelSteering(metapb.LabelSteering) error
+	// RemoveLabelSteering removes the label steering
+	RemoveLabelSteering(id uint64) error
+	// LoadLabelSteerings loads all the label steerings
+	LoadLabelSteerings(limit int64, do func(metapb.LabelSteering)) error
+
+	// PutGCSafePoint puts the GC safe point of the shard group
+	PutGCSafePoint(group, safePoint uint64) error
+	// GetGCSafePoint returns the GC safe point of the shard group, 0 if not set
+	GetGCSafePoint(group uint64) (uint64, error)
 }
 
 // ConfigStorage  config storage
@@ -164,6 +192,10 @@ type storage struct {
 	resourcePath             string
 	resourceExtraPath        string
 	scheduleGroupRulePath    string
+	preferredLeaderPath      string
+	rangeRelocationPath      string
+	labelSteeringPath        string
+	gcSafePointPath          string
 	containerPath            string
 	rulePath                 string
 	ruleGroupPath            string
@@ -177,7 +209,7 @@ type storage struct {
 
 // NewTestStorage create test storage
 func NewTestStorage() Storage {
-	return NewStorage("/test", newMemKV(), id.NewMemGenerator())
+	return NewStorage("/test", NewMemKV(), id.NewMemGenerator())
 }
 
 // NewStorage returns a metadata storage
@@ -190,6 +222,10 @@ func NewStorage(rootPath string, kv KV, idGen id.Generator) Storage {
 		resourcePath:             fmt.Sprintf("%s/resources", rootPath),
 		resourceExtraPath:        fmt.Sprintf("%s/resources-extra", rootPath),
 		scheduleGroupRulePath:    fmt.Sprintf("%s/schdule-group-rules", rootPath),
+		preferredLeaderPath:      fmt.Sprintf("%s/preferred-leaders", rootPath),
+		rangeRelocationPath:      fmt.Sprintf("%s/range-relocations", rootPath),
+		labelSteeringPath:        fmt.Sprintf("%s/label-steerings", rootPath),
+		gcSafePointPath:          fmt.Sprintf("%s/gc-safe-points", rootPath),
 		containerPath:            fmt.Sprintf("%s/containers", rootPath),
 		rulePath:                 fmt.Sprintf("%s/rules", rootPath),
 		ruleGroupPath:            fmt.Sprintf("%s/rule-groups", rootPath),
@@ -332,6 +368,75 @@ func (s *storage) LoadScheduleGroupRules(limit int64, do func(metapb.ScheduleGro
 	})
 }
 
+func (s *storage) PutPreferredLeader(shardID, storeID uint64) error {
+	return s.kv.Save(s.getKey(shardID, s.preferredLeaderPath), format.Uint64ToString(storeID))
+}
+
+func (s *storage) RemovePreferredLeader(shardID uint64) error {
+	return s.kv.Remove(s.getKey(shardID, s.preferredLeaderPath))
+}
+
+func (s *storage) LoadPreferredLeaders(limit int64, do func(shardID, storeID uint64)) error {
+	return s.LoadRangeByPrefix(limit, s.preferredLeaderPath+"/", func(k, v string) error {
+		shardID, err := strconv.ParseUint(k, 10, 64)
+		if err != nil {
+			return err
+		}
+		storeID, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			return err
+		}
+		do(shardID, storeID)
+		return nil
+	})
+}
+
+func (s *storage) PutRangeRelocation(r metapb.RangeRelocation) error {
+	return s.kv.Save(s.getKey(r.ID, s.rangeRelocationPath), string(protoc.MustMarshal(&r)))
+}
+
+func (s *storage) RemoveRangeRelocation(id uint64) error {
+	return s.kv.Remove(s.getKey(id, s.rangeRelocationPath))
+}
+
+func (s *storage) LoadRangeRelocations(limit int64, do func(metapb.RangeRelocation)) error {
+	return s.LoadRangeByPrefix(limit, s.rangeRelocationPath+"/", func(k, v string) error {
+		var r metapb.RangeRelocation
+		protoc.MustUnmarshal(&r, []byte(v))
+		do(r)
+		return nil
+	})
+}
+
+func (s *storage) PutGCSafePoint(group, safePoint uint64) error {
+	return s.kv.Save(s.getKey(group, s.gcSafePointPath), format.Uint64ToString(safePoint))
+}
+
+func (s *storage) GetGCSafePoint(group uint64) (uint64, error) {
+	v, err := s.kv.Load(s.getKey(group, s.gcSafePointPath))
+	if err != nil || v == "" {
+		return 0, err
+	}
+	return strconv.ParseUint(v, 10, 64)
+}
+
+func (s *storage) PutLabelSteering(r metapb.LabelSteering) error {
+	return s.kv.Save(s.getKey(r.ID, s.labelSteeringPath), string(protoc.MustMarshal(&r)))
+}
+
+func (s *storage) RemoveLabelSteering(id uint64) error {
+	return s.kv.Remove(s.getKey(id, s.labelSteeringPath))
+}
+
+func (s *storage) LoadLabelSteerings(limit int64, do func(metapb.LabelSteering)) error {
+	return s.LoadRangeByPrefix(limit, s.labelSteeringPath+"/", func(k, v string) error {
+		var r metapb.LabelSteering
+		protoc.MustUnmarshal(&r, []byte(v))
+		do(r)
+		return nil
+	})
+}
+
 func (s *storage) PutShardAndExtra(res metapb.Shard, extra []byte) error {
 	data, err := res.Marshal()
 	if err != nil {
@@ -346,6 +451,26 @@ func (s *storage) PutShardAndExtra(res metapb.Shard, extra []byte) error {
 	return s.kv.Batch(batch)
 }
 
+func (s *storage) PutShardsAndExtras(resources []metapb.Shard, extras [][]byte) error {
+	if len(resources) != len(extras) {
+		return fmt.Errorf("resources length %d != extras length %d",
+			len(resources), len(extras))
+	}
+
+	batch := &Batch{}
+	for idx, res := range resources {
+		data, err := res.Marshal()
+		if err != nil {
+			return err
+		}
+		batch.SaveKeys = append(batch.SaveKeys, s.getKey(res.GetID(), s.resourcePath))
+		batch.SaveValues = append(batch.SaveValues, string(data))
+		batch.SaveKeys = append(batch.SaveKeys, s.getKey(res.GetID(), s.resourceExtraPath))
+		batch.SaveValues = append(batch.SaveValues, string(extras[idx]))
+	}
+	return s.kv.Batch(batch)
+}
+
 func (s *storage) GetShardExtra(id uint64) ([]byte, error) {
 	key := s.getKey(id, s.resourceExtraPath)
 	data, err := s.kv.Load(key)
@@ -301,6 +301,21 @@ func TestScheduleGroupRule(t *testing.T) {
 	assert.Equal(t, ruleCache.RuleCount(), 10)
 }
 
+func TestPreferredLeaders(t *testing.T) {
+	s := NewTestStorage()
+	for id := uint64(1); id <= 5; id++ {
+		assert.NoError(t, s.PutPreferredLeader(id, id+100))
+	}
+	assert.NoError(t, s.PutPreferredLeader(5, 200))
+	assert.NoError(t, s.RemovePreferredLeader(4))
+
+	leaders := make(map[uint64]uint64)
+	assert.NoError(t, s.LoadPreferredLeaders(10, func(shardID, storeID uint64) {
+		leaders[shardID] = storeID
+	}))
+	assert.Equal(t, map[uint64]uint64{1: 101, 2: 102, 3: 103, 5: 200}, leaders)
+}
+
 func TestPutAndDeleteAndLoadCustomData(t *testing.T) {
 	stopC, port := mock.StartTestSingleEtcd(t)
 	defer close(stopC)
@@ -384,3 +399,30 @@ func waitLeaderReady(t *testing.T, ls *election.Leadership) {
 		time.Sleep(time.Millisecond * 50)
 	}
 }
+
+func TestRangeRelocations(t *testing.T) {
+	s := NewTestStorage()
+	for id := uint64(1); id <= 3; id++ {
+		assert.NoError(t, s.PutRangeRelocation(metapb.RangeRelocation{ID: id, Stores: []uint64{id}}))
+	}
+	assert.NoError(t, s.RemoveRangeRelocation(2))
+
+	var relocations []metapb.RangeRelocation
+	assert.NoError(t, s.LoadRangeRelocations(10, func(r metapb.RangeRelocation) {
+		relocations = append(relocations, r)
+	}))
+	assert.Equal(t, []metapb.RangeRelocation{{ID: 1, Stores: []uint64{1}}, {ID: 3, Stores: []uint64{3}}}, relocations)
+}
+
+func TestGCSafePoints(t *testing.T) {
+	s := NewTestStorage()
+	v, err := s.GetGCSafePoint(1)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(0), v)
+
+	assert.NoError(t, s.PutGCSafePoint(1, 100))
+	assert.NoError(t, s.PutGCSafePoint(2, 200))
+	v, err = s.GetGCSafePoint(1)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(100), v)
+}
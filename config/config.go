@@ -14,6 +14,7 @@
 package config
 
 import (
+	"fmt"
 	"path"
 	"time"
 
@@ -24,6 +25,7 @@ import (
 	"github.com/matrixorigin/matrixcube/metric"
 	"github.com/matrixorigin/matrixcube/pb/metapb"
 	"github.com/matrixorigin/matrixcube/pb/rpcpb"
+	"github.com/matrixorigin/matrixcube/snapshot"
 	"github.com/matrixorigin/matrixcube/storage"
 	"github.com/matrixorigin/matrixcube/transport"
 	"github.com/matrixorigin/matrixcube/vfs"
@@ -37,19 +39,45 @@ var (
 	defaultSendRaftBatchSize        uint64 = 64
 	defaultMaxConcurrencySnapChunks uint64 = 8
 	defaultSnapChunkSize                   = 4 * mb
+	defaultMaxRecoveringRequests    uint64 = 1024
+	defaultRemoteSnapshotRetention         = time.Hour
 	defaultRaftMaxWorkers           uint64 = 64
+	defaultSplitCheckWorkers        uint64 = 4
+	defaultMaxWaitToSplitCheck      uint64 = 1024
+	defaultSplitCheckIOBudget              = 1024 * mb
+	defaultStartupWorkers           uint64 = 16
+	defaultOrphanDataGCInterval            = time.Hour
+	defaultOrphanDataGCBytes               = 8 * mb
 	defaultRaftElectionTick                = 10
 	defaultRaftHeartbeatTick               = 2
 	defaultShardStateCheckDuration         = time.Second * 60
 	defaultCompactLogCheckDuration         = time.Second * 60
+	defaultFenceTimeout                    = time.Minute
 	defaultMaxEntryBytes                   = 10 * mb
 	defaultMaxAllowTransferLag      uint64 = 2
 	defaultCompactThreshold         uint64 = 256
+	defaultRaftLogCompressThreshold        = 4 * kb
 	defaultRaftTickDuration                = time.Second
 	defaultMaxPeerDownTime                 = time.Minute * 30
 	defaultShardHeartbeatDuration          = time.Second * 2
 	defaultStoreHeartbeatDuration          = time.Second * 10
 	defaultMaxInflightMsgs                 = 8
+	defaultMaxLeaseClockDriftTicks         = time.Duration(2)
+	defaultResolvedTSHeartbeats            = time.Duration(5)
+	defaultMaxResolvedTSAdvanceRate uint64 = 1024
+	defaultLeaderWarmupKeys         uint64 = 64
+	defaultLeaderWarmupTimeout             = time.Second
+	defaultAdminProposalTimeout            = time.Minute
+	defaultDrainGracePeriod                = time.Minute
+	defaultProxyDispatchBatchSize   uint64 = 64
+	defaultProxyMaxShardCredits     uint64 = 1024
+	defaultProxyCreditsInterval            = time.Millisecond * 100
+	defaultProxyMaxPacedRequests    uint64 = 4096
+	defaultWriteStallCheckInterval         = time.Second
+	defaultWriteStallL0Files        int64  = 20
+	defaultAdmissionMaxInflight     uint64 = 4096
+	defaultAdmissionMaxAppliedLag   uint64 = 16384
+	defaultProxyRouteDoubts                = 3
 	defaultDataPath                        = "/tmp/matrixcube"
 	defaultSnapshotDirName                 = "snapshots"
 	defaultProphetDirName                  = "prophet"
@@ -71,8 +99,12 @@ type Config struct {
 	// Capacity max capacity can use
 	Capacity           typeutil.ByteSize `toml:"capacity"`
 	UseMemoryAsStorage bool              `toml:"use-memory-as-storage"`
-	Replication        ReplicationConfig `toml:"replication"`
-	Snapshot           SnapshotConfig    `toml:"snapshot"`
+	// Standalone single node dev mode, the store bootstraps the shards locally
+	// without the embedded etcd and the prophet cluster, used in unit tests and
+	// local development.
+	Standalone  bool              `toml:"standalone"`
+	Replication ReplicationConfig `toml:"replication"`
+	Snapshot    SnapshotConfig    `toml:"snapshot"`
 	// Raft raft config
 	Raft RaftConfig `toml:"raft"`
 	// Worker worker config
@@ -89,6 +121,20 @@ type Config struct {
 	Metric metric.Cfg `toml:"metric"`
 	// FS used in MatrixCube
 	FS vfs.FS `json:"-" toml:"-"`
+	// Chaos fault injection config of the raft transport
+	Chaos ChaosConfig `toml:"chaos"`
+	// OrphanDataGC orphan data gc config
+	OrphanDataGC OrphanDataGCConfig `toml:"orphan-data-gc"`
+	// Proxy shards proxy config
+	Proxy ProxyConfig `toml:"proxy"`
+	// Memory memory settings of the store
+	Memory MemoryConfig `toml:"memory"`
+	// WriteStall write stall detection and mitigation config
+	WriteStall WriteStallConfig `toml:"write-stall"`
+	// IOPriority disk io priority of the pebble storage of the logdb
+	IOPriority IOPriorityConfig `toml:"io-priority"`
+	// Admission admission control of the write requests
+	Admission AdmissionConfig `toml:"admission"`
 	// Test only used in testing
 	Test TestConfig
 }
@@ -136,6 +182,11 @@ func (c *Config) Adjust() {
 	c.Prophet.StoreHeartbeatDataProcessor = c.Customize.CustomStoreHeartbeatDataProcessor
 	(&c.Prophet).Adjust(nil, false)
 	(&c.Worker).adjust()
+	(&c.OrphanDataGC).adjust()
+	(&c.Proxy).adjust()
+	(&c.Memory).adjust()
+	(&c.WriteStall).adjust()
+	(&c.Admission).adjust()
 
 	if c.Test.ShardStateAware != nil {
 		if c.Customize.CustomShardStateAwareFactory != nil {
@@ -155,6 +206,11 @@ func (c *Config) Adjust() {
 
 	if len(c.Prophet.Replication.Groups) == 0 {
 		c.Storage.ForeachDataStorageFunc(func(g uint64, ds storage.DataStorage) {
+			for _, v := range c.Prophet.Replication.Groups {
+				if v == g {
+					return
+				}
+			}
 			c.Prophet.Replication.Groups = append(c.Prophet.Replication.Groups, g)
 		})
 	}
@@ -168,6 +224,8 @@ func (c *Config) validate() {
 	if c.Storage.ForeachDataStorageFunc == nil {
 		panic("missing Config.Storage.ForeachDataStorageFunc")
 	}
+
+	c.Customize.validate()
 }
 
 // SnapshotDir returns snapshot dir
@@ -188,6 +246,20 @@ type ReplicationConfig struct {
 	ShardStateCheckDuration typeutil.Duration `toml:"shard-state-check-duration"`
 	CompactLogCheckDuration typeutil.Duration `toml:"compact-log-check-duration"`
 	AllowRemoveLeader       bool              `toml:"allow-remove-leader"`
+	// BulkBootstrapShards if the number of the init shards provided by the
+	// CustomInitShardsFactory is greater than it, the init shards are created
+	// by prophet in bulk and assigned to the stores instead of being created on
+	// the bootstrap store, 0 means disabled.
+	BulkBootstrapShards int `toml:"bulk-bootstrap-shards"`
+	// LeaderSoftLimit the soft limit of the leader count of the store, reported
+	// to prophet by the store heartbeat. When the store holds more leaders than
+	// it, prophet transfers the excess leaders to the stores with fewer leaders
+	// immediately, 0 means no limit.
+	LeaderSoftLimit uint64 `toml:"leader-soft-limit"`
+	// FenceTimeout the max duration of a shard fenced by a barrier, e.g. for a
+	// consistent backup of the group. The leader unfences the shard once the
+	// timeout is exceeded in case the client failed to unfence it.
+	FenceTimeout typeutil.Duration `toml:"fence-timeout"`
 }
 
 func (c *ReplicationConfig) adjust() {
@@ -210,12 +282,61 @@ func (c *ReplicationConfig) adjust() {
 	if c.CompactLogCheckDuration.Duration == 0 {
 		c.CompactLogCheckDuration.Duration = defaultCompactLogCheckDuration
 	}
+
+	if c.FenceTimeout.Duration == 0 {
+		c.FenceTimeout.Duration = defaultFenceTimeout
+	}
 }
 
+const (
+	// RecoveringQueue the requests received while the replica is recovering from
+	// a snapshot are queued, and rejected once the queue is full
+	RecoveringQueue = "queue"
+	// RecoveringReject the requests received while the replica is recovering
+	// from a snapshot are rejected with a retryable recovering error
+	RecoveringReject = "reject"
+)
+
 // SnapshotConfig snapshot config
 type SnapshotConfig struct {
 	MaxConcurrencySnapChunks uint64            `toml:"max-concurrency-snap-chunks"`
 	SnapChunkSize            typeutil.ByteSize `toml:"snap-chunk-size"`
+	// RecoveringPolicy how the requests are handled while the replica is
+	// recovering from a snapshot, queue or reject.
+	RecoveringPolicy string `toml:"recovering-policy"`
+	// MaxRecoveringRequests the max number of the requests queued while the
+	// replica is recovering from a snapshot with the queue policy.
+	MaxRecoveringRequests uint64 `toml:"max-recovering-requests"`
+	// SendRateLimit the max total bandwidth per second used for sending the
+	// snapshots from the store, 0 means unlimited.
+	SendRateLimit typeutil.ByteSize `toml:"send-rate-limit"`
+	// SendRatePerStoreLimit the max bandwidth per second used for sending the
+	// snapshots to each target store, 0 means unlimited.
+	SendRatePerStoreLimit typeutil.ByteSize `toml:"send-rate-per-store-limit"`
+	// Remote the remote snapshot storage the snapshots are transferred through
+	// instead of streaming store-to-store.
+	Remote RemoteSnapshotConfig `toml:"remote"`
+}
+
+// RemoteSnapshotConfig the config of the remote snapshot storage, e.g. an object
+// store. The snapshot images are uploaded to it and only the manifests are sent
+// to the target stores, which download the images from it directly.
+type RemoteSnapshotConfig struct {
+	// Endpoint the endpoint of the remote snapshot storage, disabled if empty.
+	// A "file://" endpoint is a dir shared by all the stores, e.g. a NFS mount,
+	// other endpoints require the CustomizeConfig.CustomSnapshotStorageFactory.
+	Endpoint string `toml:"endpoint"`
+	// Bucket the bucket of the snapshot images
+	Bucket string `toml:"bucket"`
+	// Region the region of the object store
+	Region string `toml:"region"`
+	// AccessKeyID the access key id of the object store
+	AccessKeyID string `toml:"access-key-id"`
+	// SecretAccessKey the secret access key of the object store
+	SecretAccessKey string `json:"-" toml:"secret-access-key"`
+	// Retention the uploaded snapshot images not downloaded by the target stores
+	// within it are removed by the sending store.
+	Retention typeutil.Duration `toml:"retention"`
 }
 
 func (c *SnapshotConfig) adjust() {
@@ -226,17 +347,317 @@ func (c *SnapshotConfig) adjust() {
 	if c.SnapChunkSize == 0 {
 		c.SnapChunkSize = typeutil.ByteSize(defaultSnapChunkSize)
 	}
+
+	if c.RecoveringPolicy == "" {
+		c.RecoveringPolicy = RecoveringQueue
+	}
+
+	if c.RecoveringPolicy != RecoveringQueue &&
+		c.RecoveringPolicy != RecoveringReject {
+		panic(fmt.Sprintf("invalid recovering policy %s", c.RecoveringPolicy))
+	}
+
+	if c.MaxRecoveringRequests == 0 {
+		c.MaxRecoveringRequests = defaultMaxRecoveringRequests
+	}
+
+	if c.Remote.Retention.Duration == 0 {
+		c.Remote.Retention.Duration = defaultRemoteSnapshotRetention
+	}
 }
 
 // WorkerConfig worker config
 type WorkerConfig struct {
 	RaftEventWorkers uint64 `toml:"raft-event-workers"`
+	// SplitCheckWorkers the max number of concurrent split checks on the store
+	SplitCheckWorkers uint64 `toml:"split-check-workers"`
+	// MaxWaitToSplitCheck the max number of shards waiting to be split checked,
+	// the shard exceeds the split check size least is dropped if exceeded.
+	MaxWaitToSplitCheck uint64 `toml:"max-wait-to-split-check"`
+	// SplitCheckIOBudget the max total approximate size of the shards being split
+	// checked concurrently on the store, since a split check may scan the whole
+	// shard.
+	SplitCheckIOBudget typeutil.ByteSize `toml:"split-check-io-budget"`
+	// StartupWorkers the max number of the data storages loaded and the replicas
+	// started concurrently while the store is starting.
+	StartupWorkers uint64 `toml:"startup-workers"`
 }
 
 func (c *WorkerConfig) adjust() {
 	if c.RaftEventWorkers == 0 {
 		c.RaftEventWorkers = defaultRaftMaxWorkers
 	}
+
+	if c.SplitCheckWorkers == 0 {
+		c.SplitCheckWorkers = defaultSplitCheckWorkers
+	}
+
+	if c.MaxWaitToSplitCheck == 0 {
+		c.MaxWaitToSplitCheck = defaultMaxWaitToSplitCheck
+	}
+
+	if c.SplitCheckIOBudget == 0 {
+		c.SplitCheckIOBudget = typeutil.ByteSize(defaultSplitCheckIOBudget)
+	}
+
+	if c.StartupWorkers == 0 {
+		c.StartupWorkers = defaultStartupWorkers
+	}
+}
+
+const (
+	// OrphanDataGCDisabled the orphan data gc is disabled
+	OrphanDataGCDisabled = "disabled"
+	// OrphanDataGCDryRun the orphan data is only reported
+	OrphanDataGCDryRun = "dry-run"
+	// OrphanDataGCDelete the orphan data is reported and deleted
+	OrphanDataGCDelete = "delete"
+	// OrphanDataGCQuarantine the orphan data is reported and moved out of the
+	// data key space of the data storage, it can be restored manually
+	OrphanDataGCQuarantine = "quarantine"
+)
+
+// OrphanDataGCConfig orphan data gc config. The orphan data is the data left in
+// the data storage for the key ranges no shard owns on the store, e.g. the data
+// of the replicas destroyed without removing data.
+type OrphanDataGCConfig struct {
+	// Mode the orphan data gc mode, disabled, dry-run, delete or quarantine.
+	Mode string `toml:"mode"`
+	// Interval interval to scan the orphan data
+	Interval typeutil.Duration `toml:"interval"`
+	// BytesPerSecond the max bytes of the orphan data scanned per second
+	BytesPerSecond typeutil.ByteSize `toml:"bytes-per-second"`
+}
+
+func (c *OrphanDataGCConfig) adjust() {
+	if c.Mode == "" {
+		c.Mode = OrphanDataGCDisabled
+	}
+
+	if c.Mode != OrphanDataGCDisabled &&
+		c.Mode != OrphanDataGCDryRun &&
+		c.Mode != OrphanDataGCDelete &&
+		c.Mode != OrphanDataGCQuarantine {
+		panic(fmt.Sprintf("invalid orphan data gc mode %s", c.Mode))
+	}
+
+	if c.Interval.Duration == 0 {
+		c.Interval.Duration = defaultOrphanDataGCInterval
+	}
+
+	if c.BytesPerSecond == 0 {
+		c.BytesPerSecond = typeutil.ByteSize(defaultOrphanDataGCBytes)
+	}
+}
+
+// ProxyConfig shards proxy config
+type ProxyConfig struct {
+	// EnableDispatchWorkers dispatch the requests to each target store through
+	// the independent queue and worker of the store, so the dispatching to a
+	// busy store does not block the requests to other stores.
+	EnableDispatchWorkers bool `toml:"enable-dispatch-workers"`
+	// DispatchBatchSize max number of requests dispatched by the worker of a
+	// target store in a batch
+	DispatchBatchSize uint64 `toml:"dispatch-batch-size"`
+	// DispatchBatchWindow how long the worker waits for more requests before
+	// dispatching a batch which is not full, 0 means no waiting.
+	DispatchBatchWindow typeutil.Duration `toml:"dispatch-batch-window"`
+	// EnableFlowControl paces the write requests dispatched to each shard by the
+	// proposal credits advertised by the leader of the shard, the write requests
+	// exceeding the credits are queued in the proxy until more credits are
+	// advertised, instead of overloading the busy shard.
+	EnableFlowControl bool `toml:"enable-flow-control"`
+	// MaxShardCredits max number of the write requests queued, proposing and
+	// waiting to be applied in a shard, the credits of the shard are the rest of
+	// it. The credits are advertised to each proxy.
+	MaxShardCredits uint64 `toml:"max-shard-credits"`
+	// CreditsHeartbeatInterval how often the store advertises the credits of the
+	// busy shards to the local and remote proxies. A shard not advertised in 3
+	// intervals is no longer paced.
+	CreditsHeartbeatInterval typeutil.Duration `toml:"credits-heartbeat-interval"`
+	// MaxPacedRequests max number of the write requests to a shard queued in the
+	// proxy waiting for the credits, the write requests exceeding it are rejected
+	// with `raftstore.ErrTooManyPacedRequests`.
+	MaxPacedRequests uint64 `toml:"max-paced-requests"`
+	// EnableConsistentRouting looks up the route of a shard from the prophet
+	// directly once the route is missing in the router or is doubted repeatedly,
+	// and updates the router with the authoritative shard and leader, instead of
+	// waiting for the asynchronous watcher events.
+	EnableConsistentRouting bool `toml:"enable-consistent-routing"`
+	// RouteDoubtsThreshold number of the doubts, e.g. the NotLeader errors, of the
+	// route of a shard after which the route is looked up from the prophet.
+	RouteDoubtsThreshold int `toml:"route-doubts-threshold"`
+}
+
+func (c *ProxyConfig) adjust() {
+	if c.DispatchBatchSize == 0 {
+		c.DispatchBatchSize = defaultProxyDispatchBatchSize
+	}
+
+	if c.MaxShardCredits == 0 {
+		c.MaxShardCredits = defaultProxyMaxShardCredits
+	}
+
+	if c.CreditsHeartbeatInterval.Duration == 0 {
+		c.CreditsHeartbeatInterval.Duration = defaultProxyCreditsInterval
+	}
+
+	if c.MaxPacedRequests == 0 {
+		c.MaxPacedRequests = defaultProxyMaxPacedRequests
+	}
+
+	if c.RouteDoubtsThreshold == 0 {
+		c.RouteDoubtsThreshold = defaultProxyRouteDoubts
+	}
+}
+
+const (
+	// MemoryProfileSmall the memory preset for the constrained environments, e.g.
+	// the edge devices with hundreds of MB memory
+	MemoryProfileSmall = "small"
+	// MemoryProfileMedium the default memory preset
+	MemoryProfileMedium = "medium"
+	// MemoryProfileLarge the memory preset for the dedicated servers, the larger
+	// blocks reduce the index memory and suit the large memory pages
+	MemoryProfileLarge = "large"
+)
+
+var (
+	memoryProfiles = map[string]MemoryConfig{
+		MemoryProfileSmall: {
+			CacheSize:                   4 * 1024 * 1024,
+			BlockSize:                   4 * 1024,
+			MemTableSize:                8 * 1024 * 1024,
+			MemTableStopWritesThreshold: 4,
+			GCPercent:                   50,
+		},
+		MemoryProfileMedium: {
+			CacheSize:                   8 * 1024 * 1024,
+			BlockSize:                   4 * 1024,
+			MemTableSize:                64 * 1024 * 1024,
+			MemTableStopWritesThreshold: 8,
+		},
+		MemoryProfileLarge: {
+			CacheSize:                   256 * 1024 * 1024,
+			BlockSize:                   32 * 1024,
+			MemTableSize:                128 * 1024 * 1024,
+			MemTableStopWritesThreshold: 8,
+		},
+	}
+)
+
+// MemoryConfig memory settings of the store, the settings not set are adjusted by
+// the preset of the profile. The pebble settings are used by the logdb of the store,
+// and can be used by the data storage created by the embedders.
+type MemoryConfig struct {
+	// Profile the preset of the memory settings, one of small, medium and large.
+	// Default is medium.
+	Profile string `toml:"profile"`
+	// CacheSize block cache size of the pebble
+	CacheSize typeutil.ByteSize `toml:"cache-size"`
+	// BlockSize target uncompressed size of the sstable blocks of the pebble
+	BlockSize typeutil.ByteSize `toml:"block-size"`
+	// MemTableSize size of a memtable of the pebble
+	MemTableSize typeutil.ByteSize `toml:"memtable-size"`
+	// MemTableStopWritesThreshold max number of the queued memtables of the pebble
+	// before the writes are stopped
+	MemTableStopWritesThreshold int `toml:"memtable-stop-writes-threshold"`
+	// GCPercent the GOGC of the go runtime set when the store starts, it applies to
+	// the whole process. 0 means the go runtime setting is not changed.
+	GCPercent int `toml:"gc-percent"`
+}
+
+func (c *MemoryConfig) adjust() {
+	if c.Profile == "" {
+		c.Profile = MemoryProfileMedium
+	}
+
+	preset, ok := memoryProfiles[c.Profile]
+	if !ok {
+		panic(fmt.Sprintf("unknown memory profile %s", c.Profile))
+	}
+
+	if c.CacheSize == 0 {
+		c.CacheSize = preset.CacheSize
+	}
+
+	if c.BlockSize == 0 {
+		c.BlockSize = preset.BlockSize
+	}
+
+	if c.MemTableSize == 0 {
+		c.MemTableSize = preset.MemTableSize
+	}
+
+	if c.MemTableStopWritesThreshold == 0 {
+		c.MemTableStopWritesThreshold = preset.MemTableStopWritesThreshold
+	}
+
+	if c.GCPercent == 0 {
+		c.GCPercent = preset.GCPercent
+	}
+}
+
+// WriteStallConfig write stall config. The data storages are checked for the
+// write stalls periodically, a data storage is stalled if it's stalling the writes
+// or has too many L0 files. Once a data storage is stalled, the proposal credits
+// of the shards of the groups using it are reduced, and the store is reported as
+// busy to the prophet, so the balancing avoids the store until it recovers.
+type WriteStallConfig struct {
+	// CheckInterval how often the data storages are checked for the write stalls
+	CheckInterval typeutil.Duration `toml:"check-interval"`
+	// L0FilesThreshold a data storage with no less than the L0 files is stalled
+	L0FilesThreshold int64 `toml:"l0-files-threshold"`
+	// DisableMitigation only the metrics of the write stalls are updated, the
+	// proposals are not slowed down and the store is not reported as busy.
+	DisableMitigation bool `toml:"disable-mitigation"`
+}
+
+func (c *WriteStallConfig) adjust() {
+	if c.CheckInterval.Duration == 0 {
+		c.CheckInterval.Duration = defaultWriteStallCheckInterval
+	}
+
+	if c.L0FilesThreshold == 0 {
+		c.L0FilesThreshold = defaultWriteStallL0Files
+	}
+}
+
+// IOPriorityConfig disk io priority config of the pebble storage, the apply writes
+// are prioritized over the compaction writes. It's applied to the pebble storage of
+// the logdb created by the store, the data storages created by the applications can
+// use it by the pebble.NewIOPriorityConfig.
+type IOPriorityConfig struct {
+	// CompactionBytesPerSecond the rate limit of the compaction writes while the
+	// apply writes are busy, 0 means the io priority is disabled
+	CompactionBytesPerSecond typeutil.ByteSize `toml:"compaction-bytes-per-second"`
+	// ApplyBusyWindow the apply writes are considered busy within the window after
+	// the last apply write. Default is 1s.
+	ApplyBusyWindow typeutil.Duration `toml:"apply-busy-window"`
+}
+
+// AdmissionConfig admission control config. The write requests to a shard are
+// rejected with the retryable ServerIsBusy error by the store, before they are
+// queued in the leader replica, once the shard exceeds any of the thresholds.
+type AdmissionConfig struct {
+	// Disable the write requests are always admitted
+	Disable bool `toml:"disable"`
+	// MaxInflightRequests max number of the requests queued, proposing and
+	// waiting to be applied of a shard
+	MaxInflightRequests uint64 `toml:"max-inflight-requests"`
+	// MaxAppliedLag max number of the committed log entries of a shard not
+	// applied yet
+	MaxAppliedLag uint64 `toml:"max-applied-lag"`
+}
+
+func (c *AdmissionConfig) adjust() {
+	if c.MaxInflightRequests == 0 {
+		c.MaxInflightRequests = defaultAdmissionMaxInflight
+	}
+
+	if c.MaxAppliedLag == 0 {
+		c.MaxAppliedLag = defaultAdmissionMaxAppliedLag
+	}
 }
 
 // ShardConfig shard config
@@ -264,6 +685,71 @@ type RaftConfig struct {
 	MaxEntryBytes typeutil.ByteSize `toml:"max-entry-bytes"`
 	// SendRaftBatchSize raft message sender count
 	SendRaftBatchSize uint64 `toml:"send-raft-batch-size"`
+	// EnableLeaseRead allows the leader to serve read requests locally without
+	// ReadIndex round trips and queueing behind writes while it holds a valid
+	// lease.
+	EnableLeaseRead bool `toml:"enable-lease-read"`
+	// MaxLeaseClockDrift the tolerated clock drift between stores, the leader
+	// lease is the election timeout minus this value.
+	MaxLeaseClockDrift typeutil.Duration `toml:"max-lease-clock-drift"`
+	// ResolvedTSInterval the leader issues a ReadIndex if no ReadIndex was
+	// issued or confirmed within this interval to advance the resolved
+	// timestamp of the shard, default is 5 times the raft heartbeat interval.
+	// It's never shorter than the heartbeat interval, as the ReadIndex is
+	// confirmed by the heartbeats.
+	ResolvedTSInterval typeutil.Duration `toml:"resolved-ts-interval"`
+	// MaxResolvedTSAdvanceRate the max number of the ReadIndexes issued per
+	// second by all the leaders of the store to advance the resolved timestamps,
+	// the leaders exceeding it retry in the next ticks.
+	MaxResolvedTSAdvanceRate uint64 `toml:"max-resolved-ts-advance-rate"`
+	// DisableResolvedTSAdvance disables the ReadIndex issued for advancing the
+	// resolved timestamp, the resolved timestamp of the shards only advances
+	// when serving reads through ReadIndex.
+	DisableResolvedTSAdvance bool `toml:"disable-resolved-ts-advance"`
+	// EnableZeroCopyRead allows the local read requests to return the values
+	// owned by the storage engine to the client without copying. The value
+	// returned by the `Future` is only valid until the `Future` is closed, and
+	// pins the storage memory holding it until then.
+	EnableZeroCopyRead bool `toml:"enable-zero-copy-read"`
+	// EnableLeaderWarmup makes the outgoing leader ship a digest of the hot keys
+	// of the shard to the leader transfer target, which prefetches the keys
+	// from the data storage to warm up the caches before announcing the
+	// leadership to prophet and the router.
+	EnableLeaderWarmup bool `toml:"enable-leader-warmup"`
+	// LeaderWarmupKeys the max number of hot keys in the digest
+	LeaderWarmupKeys uint64 `toml:"leader-warmup-keys"`
+	// LeaderWarmupTimeout the max time the new leader spends on prefetching, the
+	// leadership is announced once the timeout expired.
+	LeaderWarmupTimeout typeutil.Duration `toml:"leader-warmup-timeout"`
+	// EnableLeaderTransferCoordination makes the planned leader transfers nearly
+	// invisible to the clients. The outgoing leader points the shard in the
+	// router of its store to the transfer target before sending MsgTimeoutNow,
+	// and the new leader updates the router of its store, reports to prophet
+	// and establishes its lease right after the switch instead of waiting for
+	// the next heartbeat and the first read.
+	EnableLeaderTransferCoordination bool `toml:"enable-leader-transfer-coordination"`
+	// AdminProposalTimeout the max time an admin request, e.g. split and config
+	// change, stays pending in the leader since it was proposed. The expired
+	// admin request is responded with the AdminTimeout error, and a split
+	// started by the split check or prophet is dropped if it can not be
+	// proposed before the deadline. The merge not completed before the deadline
+	// of its prepare merge request is rolled back.
+	AdminProposalTimeout typeutil.Duration `toml:"admin-proposal-timeout"`
+	// CoalesceHeartbeatInterval the interval of sending the coalesced raft
+	// heartbeats. If set, the heartbeats of all the shards shared by two stores
+	// are sent in a single message every interval instead of one message per
+	// shard, 0 means disabled.
+	CoalesceHeartbeatInterval typeutil.Duration `toml:"coalesce-heartbeat-interval"`
+	// StopDrainTimeout the max time the store spends on draining before it stops.
+	// If set, the store transfers the leaderships of its replicas away, rejects the
+	// new requests of the transferring leaders with the transfer target as the
+	// leader hint, and waits for the proxy queues to be flushed, so the rolling
+	// restarts are barely noticed by the clients. 0 means stop without draining.
+	StopDrainTimeout typeutil.Duration `toml:"stop-drain-timeout"`
+	// DrainGracePeriod the time the replicas of the store keep rejecting the new
+	// leader elections after the `Store.DrainLeadership` completed, so the store
+	// can be restarted without taking back the leaderships in the meantime.
+	DrainGracePeriod typeutil.Duration `toml:"drain-grace-period"`
 	// RaftLog raft log 配置
 	RaftLog RaftLogConfig `toml:"raft-log"`
 }
@@ -273,6 +759,19 @@ func (c *RaftConfig) GetElectionTimeoutDuration() time.Duration {
 	return time.Duration(c.ElectionTimeoutTicks) * c.TickInterval.Duration
 }
 
+// GetLeaseReadDuration returns the duration of the leader lease, zero means the
+// lease read is disabled.
+func (c *RaftConfig) GetLeaseReadDuration() time.Duration {
+	if !c.EnableLeaseRead {
+		return 0
+	}
+	timeout := c.GetElectionTimeoutDuration()
+	if timeout <= c.MaxLeaseClockDrift.Duration {
+		return 0
+	}
+	return timeout - c.MaxLeaseClockDrift.Duration
+}
+
 // GetHeartbeatDuration returns HeartbeatTicks * TickInterval
 func (c *RaftConfig) GetHeartbeatDuration() time.Duration {
 	return time.Duration(c.HeartbeatTicks) * c.TickInterval.Duration
@@ -303,6 +802,36 @@ func (c *RaftConfig) adjust() {
 		c.MaxEntryBytes = typeutil.ByteSize(defaultMaxEntryBytes)
 	}
 
+	if c.MaxLeaseClockDrift.Duration == 0 {
+		c.MaxLeaseClockDrift.Duration = c.TickInterval.Duration * defaultMaxLeaseClockDriftTicks
+	}
+
+	if c.ResolvedTSInterval.Duration == 0 {
+		c.ResolvedTSInterval.Duration = c.GetHeartbeatDuration() * defaultResolvedTSHeartbeats
+	} else if c.ResolvedTSInterval.Duration < c.GetHeartbeatDuration() {
+		c.ResolvedTSInterval.Duration = c.GetHeartbeatDuration()
+	}
+
+	if c.MaxResolvedTSAdvanceRate == 0 {
+		c.MaxResolvedTSAdvanceRate = defaultMaxResolvedTSAdvanceRate
+	}
+
+	if c.LeaderWarmupKeys == 0 {
+		c.LeaderWarmupKeys = defaultLeaderWarmupKeys
+	}
+
+	if c.LeaderWarmupTimeout.Duration == 0 {
+		c.LeaderWarmupTimeout.Duration = defaultLeaderWarmupTimeout
+	}
+
+	if c.AdminProposalTimeout.Duration == 0 {
+		c.AdminProposalTimeout.Duration = defaultAdminProposalTimeout
+	}
+
+	if c.DrainGracePeriod.Duration == 0 {
+		c.DrainGracePeriod.Duration = defaultDrainGracePeriod
+	}
+
 	(&c.RaftLog).adjust()
 }
 
@@ -311,6 +840,23 @@ type RaftLogConfig struct {
 	DisableSync         bool   `toml:"disable-sync"`
 	CompactThreshold    uint64 `toml:"compact-threshold"`
 	MaxAllowTransferLag uint64 `toml:"max-allow-transfer-lag"`
+	// Compression the compression of the raft log entries saved in the logdb,
+	// "none" or "snappy". The entries saved with another compression remain
+	// readable after changed.
+	Compression string `toml:"compression"`
+	// CompressThreshold the entries larger than it are compressed
+	CompressThreshold typeutil.ByteSize `toml:"compress-threshold"`
+	// EnableGroupCommit coalesces the raft logs saved concurrently by the raft
+	// workers of different replicas into a single write and fsync, reduces the
+	// fsync count when there are many active shards.
+	EnableGroupCommit bool `toml:"enable-group-commit"`
+	// EnableSyncPiggyback marks the writes of the data storages sharing the WAL
+	// with the logdb persistent by the fsync of the raft logs, instead of the
+	// sampled fsync of the data storages, which halves the fsync count of the
+	// write path. Only the data storages implementing the
+	// storage.SyncPiggybackStorage created on the StorageConfig.LogDBStorage
+	// are affected.
+	EnableSyncPiggyback bool `toml:"enable-sync-piggyback"`
 }
 
 func (c *RaftLogConfig) adjust() {
@@ -321,6 +867,23 @@ func (c *RaftLogConfig) adjust() {
 	if c.CompactThreshold == 0 {
 		c.CompactThreshold = defaultCompactThreshold
 	}
+
+	if c.CompressThreshold == 0 {
+		c.CompressThreshold = typeutil.ByteSize(defaultRaftLogCompressThreshold)
+	}
+}
+
+// ChaosConfig chaos config, used for resilience testing in staging environments.
+type ChaosConfig struct {
+	// Enable wraps the raft transport with the chaos transport, the fault
+	// injection options can be updated at runtime using the ChaosController
+	// returned by Store.GetChaosController.
+	Enable bool `toml:"enable"`
+	// AdminAddr the address of the chaos admin http API, which is used to
+	// update the fault injection options at runtime, disabled if empty.
+	AdminAddr string `toml:"addr-admin"`
+	// Options the initial fault injection options
+	Options transport.ChaosOptions `toml:"options"`
 }
 
 // StorageConfig storage config
@@ -328,8 +891,22 @@ type StorageConfig struct {
 
 	// DataStorageFactory is a storage factory  to store application's data
 	DataStorageFactory func(group uint64) storage.DataStorage `json:"-" toml:"-"`
-	// ForeachDataStorageFunc do in every storage
+	// ShardDataStorageFactory is an optional storage factory resolving the data
+	// storage of the shard, e.g. by the key range or the labels of the shard, so
+	// the hot and cold ranges of a group can be stored in different storages. The
+	// shard is moved to the resolved storage by the snapshot when the result is
+	// changed, e.g. the shard labels are updated by prophet. Falls back to the
+	// DataStorageFactory if nil or nil is returned.
+	ShardDataStorageFactory func(group uint64, shard metapb.Shard) storage.DataStorage `json:"-" toml:"-"`
+	// ForeachDataStorageFunc do in every storage, all the storages returned by the
+	// ShardDataStorageFactory must be included, a group may be visited multiple
+	// times with different storages.
 	ForeachDataStorageFunc func(cb func(uint64, storage.DataStorage)) `json:"-" toml:"-"`
+	// LogDBStorage is an optional kv storage of the logdb, which is created in
+	// the DataPath if nil. It's not closed by the store. The data storages can be
+	// created on it to share the WAL with the logdb, the keys written by them
+	// must not overlap with the keys of the logdb.
+	LogDBStorage storage.KVStorage `json:"-" toml:"-"`
 }
 
 // CustomizeConfig customize config
@@ -349,6 +926,61 @@ type CustomizeConfig struct {
 	CustomWrapNewTransport func(transport.Trans) transport.Trans `json:"-" toml:"-"`
 	// CustomShardProxyRequestHandler custom ShardProxy request handler
 	CustomShardProxyRequestHandler func(req rpcpb.Request, cb func(resp rpcpb.ResponseBatch)) (bool, error) `json:"-" toml:"-"`
+	// CustomAdminCmdHandlers the handlers of the custom admin commands, the key is
+	// the admin cmd type which can not be less than rpcpb.MinCustomAdminCmdType.
+	CustomAdminCmdHandlers map[rpcpb.AdminCmdType]CustomAdminCmdHandler `json:"-" toml:"-"`
+	// CustomShardMetadataInterceptor intercepts the shard metadata persisted in the
+	// local data storage, it's used to mirror the shard state into an external catalog.
+	CustomShardMetadataInterceptor ShardMetadataInterceptor `json:"-" toml:"-"`
+	// CustomSnapshotStorageFactory creates the remote snapshot storage of the
+	// SnapshotConfig.Remote whose endpoint is not a "file://" one, e.g. an S3
+	// client.
+	CustomSnapshotStorageFactory func(RemoteSnapshotConfig) (snapshot.SnapshotStorage, error) `json:"-" toml:"-"`
+}
+
+// CustomAdminCmdHandler handles a custom admin command. The custom admin commands
+// are sent by the client as the admin requests, proposed to raft like the
+// predefined admin commands and applied by each replica of the shard in the
+// raft log order.
+type CustomAdminCmdHandler struct {
+	// Validate validates the command on the leader before it is proposed, the
+	// request fails with the returned error without proposing. It's optional.
+	Validate func(shard metapb.Shard, cmd []byte) error
+	// Apply applies the command in the state machine of each replica, the
+	// returned value is the value of the response, and the returned error is
+	// returned to the client. It must be deterministic, and must be idempotent
+	// as the command may be applied again after restart.
+	Apply func(shard metapb.Shard, index uint64, cmd []byte, ds storage.DataStorage) ([]byte, error)
+}
+
+// ShardMetadataInterceptor intercepts the shard metadata persisted in the local data
+// storage, e.g. created, split, updated or destroyed, so the embedders can mirror the
+// shard state into their own catalog. The metadata passed to the interceptor is always
+// synced to disk before. If the store crashes before the interceptor is called, the missing
+// metadata is passed to Replay on the restart, so the interceptor must be idempotent
+// and use the LogIndex of the metadata to drop the stale updates.
+type ShardMetadataInterceptor interface {
+	// Replay is called for each group on the store start, before any replica of the
+	// group is started, with all the shard metadata persisted in the data storage of
+	// the group.
+	Replay(group uint64, metadata []metapb.ShardMetadata) error
+	// OnSaved is called after the shard metadata is persisted. The calls of a shard
+	// are in the raft log order, and the split metadata of the old shard and the new
+	// shards is passed in a single call. It's called in the apply path of the shard,
+	// so it should be fast. The store panics if an error is returned, as the catalog
+	// can not be kept consistent.
+	OnSaved(group uint64, metadata []metapb.ShardMetadata) error
+}
+
+func (c *CustomizeConfig) validate() {
+	for cmdType, h := range c.CustomAdminCmdHandlers {
+		if cmdType < rpcpb.MinCustomAdminCmdType {
+			panic(fmt.Sprintf("custom admin cmd type %d is reserved", cmdType))
+		}
+		if h.Apply == nil {
+			panic(fmt.Sprintf("missing Apply of the custom admin cmd type %d", cmdType))
+		}
+	}
 }
 
 // GetLabels returns lables
@@ -14,6 +14,7 @@ require (
 	github.com/fagongzi/util v0.0.0-20210923134909-bccc37b5040d
 	github.com/gogo/protobuf v1.3.2
 	github.com/golang/mock v1.3.1
+	github.com/golang/snappy v0.0.2-0.20190904063534-ff6b7dc882cf
 	github.com/google/btree v1.0.1
 	github.com/juju/ratelimit v1.0.1
 	github.com/lni/goutils v1.3.0
@@ -40,6 +40,9 @@ var (
 var (
 	localPrefix   byte = 0x01
 	storeIdentKey      = []byte{localPrefix, 0x01}
+	// bulkInitShardsKey keeps the init shards to be created by prophet in bulk
+	// until they are created.
+	bulkInitShardsKey = []byte{localPrefix, 0x03}
 	// We save two types shard data in the KVStore, they are raft and other meta
 	// data. When the store starts, we should iterate all shard meta data to
 	// launch replicas, to avoid iterating large volume of data, we separate them
@@ -64,6 +67,11 @@ func GetStoreIdentKey() []byte {
 	return storeIdentKey
 }
 
+// GetBulkInitShardsKey return key of the init shards created in bulk
+func GetBulkInitShardsKey() []byte {
+	return bulkInitShardsKey
+}
+
 // GetSnapshotKey returns the key used to store snapshot metadata in LogDB.
 func GetSnapshotKey(shardID uint64, index uint64, key []byte) []byte {
 	key = getKeySlice(key, indexedIDKeyLength)
@@ -0,0 +1,106 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logdb
+
+import (
+	"fmt"
+
+	"github.com/fagongzi/util/protoc"
+	"github.com/golang/snappy"
+	"go.etcd.io/etcd/raft/v3/raftpb"
+)
+
+// CompressionType is the compression of the raft log entries saved in the LogDB.
+type CompressionType byte
+
+const (
+	// NoCompression the entries are saved uncompressed
+	NoCompression CompressionType = iota
+	// Snappy the entries are compressed by snappy
+	Snappy
+)
+
+// compressedEntryMagic is the first byte of the compressed entries, followed by
+// the CompressionType and the compressed entry. The marshaled raftpb.Entry
+// always begins with the tag of its first field which is never 0, so the entries
+// saved uncompressed, e.g. before the compression enabled, remain readable.
+const compressedEntryMagic byte = 0
+
+// ParseCompressionType parses the CompressionType, empty means NoCompression.
+func ParseCompressionType(v string) (CompressionType, error) {
+	switch v {
+	case "", "none":
+		return NoCompression, nil
+	case "snappy":
+		return Snappy, nil
+	}
+	return NoCompression, fmt.Errorf("invalid raft log compression %s", v)
+}
+
+func (ct CompressionType) String() string {
+	switch ct {
+	case NoCompression:
+		return "none"
+	case Snappy:
+		return "snappy"
+	}
+	return fmt.Sprintf("unknown(%d)", byte(ct))
+}
+
+// encodeEntry marshals the entry, and compresses it if it's larger than the
+// threshold.
+func encodeEntry(e *raftpb.Entry, ct CompressionType, threshold int) []byte {
+	v := protoc.MustMarshal(e)
+	if ct == NoCompression || len(v) <= threshold {
+		return v
+	}
+
+	var compressed []byte
+	switch ct {
+	case Snappy:
+		compressed = snappy.Encode(nil, v)
+	default:
+		panic(fmt.Sprintf("unknown raft log compression %d", byte(ct)))
+	}
+	if len(compressed)+2 >= len(v) {
+		// not worth to compress
+		return v
+	}
+	return append([]byte{compressedEntryMagic, byte(ct)}, compressed...)
+}
+
+// decodeEntry unmarshals the compressed or uncompressed entry.
+func decodeEntry(v []byte, e *raftpb.Entry) error {
+	if len(v) == 0 || v[0] != compressedEntryMagic {
+		protoc.MustUnmarshal(e, v)
+		return nil
+	}
+	if len(v) < 2 {
+		return fmt.Errorf("invalid compressed raft log entry %+v", v)
+	}
+
+	var data []byte
+	var err error
+	switch ct := CompressionType(v[1]); ct {
+	case Snappy:
+		data, err = snappy.Decode(nil, v[2:])
+	default:
+		err = fmt.Errorf("unknown raft log compression %s", ct)
+	}
+	if err != nil {
+		return err
+	}
+	protoc.MustUnmarshal(e, data)
+	return nil
+}
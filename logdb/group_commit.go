@@ -0,0 +1,109 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logdb
+
+import (
+	"sync"
+
+	"github.com/matrixorigin/matrixcube/storage"
+	"github.com/matrixorigin/matrixcube/util"
+)
+
+// groupCommitter coalesces the raft states saved concurrently by the workers of
+// the worker pool into a single write and fsync of the logdb. The first writer
+// becomes the leader of a group and writes the batches of all the writers
+// arrived before it starts, the writers arrived during the write are queued for
+// the next group, whose leader is the first queued one.
+type groupCommitter struct {
+	ms storage.KVMetadataStore
+
+	mu struct {
+		sync.Mutex
+		// writing is true if a group is being written by a leader
+		writing bool
+		pending []*groupWrite
+	}
+}
+
+type groupWrite struct {
+	wb util.WriteBatch
+	// resultC receives the result of the group write, or the lead signal if the
+	// writer becomes the leader of the next group
+	resultC chan groupWriteResult
+}
+
+type groupWriteResult struct {
+	lead bool
+	err  error
+}
+
+func newGroupCommitter(ms storage.KVMetadataStore) *groupCommitter {
+	return &groupCommitter{ms: ms}
+}
+
+// write writes the batch with fsync, returns after the batch is persistent.
+func (c *groupCommitter) write(wb util.WriteBatch) error {
+	w := &groupWrite{wb: wb, resultC: make(chan groupWriteResult, 1)}
+	c.mu.Lock()
+	c.mu.pending = append(c.mu.pending, w)
+	if c.mu.writing {
+		c.mu.Unlock()
+		if r := <-w.resultC; !r.lead {
+			return r.err
+		}
+		c.mu.Lock()
+	}
+	c.mu.writing = true
+	group := c.mu.pending
+	c.mu.pending = nil
+	c.mu.Unlock()
+
+	err := c.commit(group)
+
+	c.mu.Lock()
+	if len(c.mu.pending) > 0 {
+		c.mu.pending[0].resultC <- groupWriteResult{lead: true}
+	} else {
+		c.mu.writing = false
+	}
+	c.mu.Unlock()
+	for _, v := range group {
+		if v != w {
+			v.resultC <- groupWriteResult{err: err}
+		}
+	}
+	return err
+}
+
+// commit merges the batches of the group into one if supported, otherwise the
+// batches are written without fsync except the last one, the fsync of which
+// persists all the writes before it.
+func (c *groupCommitter) commit(group []*groupWrite) error {
+	wb := group[0].wb
+	if m, ok := wb.(util.WriteBatchMerger); ok {
+		for _, v := range group[1:] {
+			if err := m.Merge(v.wb); err != nil {
+				return err
+			}
+		}
+		return c.ms.Write(wb, true)
+	}
+
+	for idx, v := range group {
+		if err := c.ms.Write(v.wb, idx == len(group)-1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
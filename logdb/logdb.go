@@ -121,15 +121,67 @@ type LogDB interface {
 type KVLogDB struct {
 	logger *zap.Logger
 	ms     storage.KVMetadataStore
+	// compression the compression of the entries larger than the
+	// compressionThreshold bytes
+	compression          CompressionType
+	compressionThreshold int
+	// committer coalesces the concurrent writes, nil if disabled
+	committer *groupCommitter
+	// syncListener is notified around the fsync of the raft states, nil if
+	// disabled
+	syncListener SyncListener
 }
 
 var _ LogDB = (*KVLogDB)(nil)
 
-func NewKVLogDB(ms storage.KVMetadataStore, logger *zap.Logger) *KVLogDB {
-	return &KVLogDB{
+// Option the option to create the KVLogDB
+type Option func(*KVLogDB)
+
+// WithEntryCompression compresses the entries larger than the threshold bytes.
+// The compression of each entry is saved with it, so the compression can be
+// changed without migrating the saved entries.
+func WithEntryCompression(ct CompressionType, threshold int) Option {
+	return func(l *KVLogDB) {
+		l.compression = ct
+		l.compressionThreshold = threshold
+	}
+}
+
+// WithGroupCommit coalesces the raft states saved concurrently by different
+// workers into a single write and fsync.
+func WithGroupCommit() Option {
+	return func(l *KVLogDB) {
+		l.committer = newGroupCommitter(l.ms)
+	}
+}
+
+// SyncListener is notified around the fsync of the raft states, the storages
+// sharing the WAL with the logdb use it to mark their writes persistent without
+// the fsync of their own.
+type SyncListener interface {
+	// PrepareSync is called before the fsync, the returned func is called after
+	// the fsync succeeded, the writes of the shared WAL completed before
+	// PrepareSync are persisted by the fsync.
+	PrepareSync() func()
+}
+
+// WithSyncListener sets the SyncListener notified around the fsync of the raft
+// states.
+func WithSyncListener(listener SyncListener) Option {
+	return func(l *KVLogDB) {
+		l.syncListener = listener
+	}
+}
+
+func NewKVLogDB(ms storage.KVMetadataStore, logger *zap.Logger, opts ...Option) *KVLogDB {
+	l := &KVLogDB{
 		logger: logger,
 		ms:     ms,
 	}
+	for _, opt := range opts {
+		opt(l)
+	}
+	return l
 }
 
 func (l *KVLogDB) Name() string {
@@ -210,14 +262,32 @@ func (l *KVLogDB) SaveRaftState(shardID uint64,
 
 	for _, e := range rd.Entries {
 		// TODO: use reusable buf here
-		d := protoc.MustMarshal(&e)
+		d := encodeEntry(&e, l.compression, l.compressionThreshold)
 		ctx.wb.Set(keys.GetRaftLogKey(shardID, e.Index, nil), d)
 	}
 	if len(rd.Entries) > 0 {
 		binary.BigEndian.PutUint64(ctx.idBuf, rd.Entries[len(rd.Entries)-1].Index)
 		ctx.wb.Set(keys.GetMaxIndexKey(shardID, nil), ctx.idBuf)
 	}
-	return l.ms.Write(ctx.wb, true)
+
+	var synced func()
+	if l.syncListener != nil {
+		synced = l.syncListener.PrepareSync()
+	}
+	if err := l.write(ctx.wb); err != nil {
+		return err
+	}
+	if synced != nil {
+		synced()
+	}
+	return nil
+}
+
+func (l *KVLogDB) write(wb util.WriteBatch) error {
+	if l.committer != nil {
+		return l.committer.write(wb)
+	}
+	return l.ms.Write(wb, true)
 }
 
 func (l *KVLogDB) IterateEntries(ents []raftpb.Entry,
@@ -234,7 +304,9 @@ func (l *KVLogDB) IterateEntries(ents []raftpb.Entry,
 			return nil, 0, raft.ErrUnavailable
 		}
 		e := raftpb.Entry{}
-		protoc.MustUnmarshal(&e, v)
+		if err := decodeEntry(v, &e); err != nil {
+			return nil, 0, err
+		}
 		if e.Index != nextIndex {
 			l.logger.Fatal("raft log index not match",
 				log.ShardIDField(shardID),
@@ -256,7 +328,9 @@ func (l *KVLogDB) IterateEntries(ents []raftpb.Entry,
 	endKey := keys.GetRaftLogKey(shardID, high, nil)
 	if err := l.ms.Scan(startKey, endKey, func(key, value []byte) (bool, error) {
 		e := raftpb.Entry{}
-		protoc.MustUnmarshal(&e, value)
+		if err := decodeEntry(value, &e); err != nil {
+			return false, err
+		}
 		// May meet gap or has been compacted.
 		if e.Index != nextIndex {
 			return false, nil
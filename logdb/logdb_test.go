@@ -28,13 +28,18 @@
 package logdb
 
 import (
+	"bytes"
 	"math"
 	"reflect"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/cockroachdb/errors"
 	cpebble "github.com/cockroachdb/pebble"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.etcd.io/etcd/raft/v3"
 	"go.etcd.io/etcd/raft/v3/raftpb"
 
@@ -42,6 +47,7 @@ import (
 	"github.com/matrixorigin/matrixcube/keys"
 	"github.com/matrixorigin/matrixcube/storage"
 	"github.com/matrixorigin/matrixcube/storage/kv/pebble"
+	"github.com/matrixorigin/matrixcube/util"
 	"github.com/matrixorigin/matrixcube/util/leaktest"
 	"github.com/matrixorigin/matrixcube/vfs"
 )
@@ -484,3 +490,184 @@ func TestLogDBRemoveReplicaData(t *testing.T) {
 	fs := vfs.GetTestFS()
 	runLogDBTest(t, tf, fs)
 }
+
+func TestParseCompressionType(t *testing.T) {
+	cases := []struct {
+		value  string
+		ct     CompressionType
+		hasErr bool
+	}{
+		{"", NoCompression, false},
+		{"none", NoCompression, false},
+		{"snappy", Snappy, false},
+		{"lz4", NoCompression, true},
+	}
+	for i, c := range cases {
+		ct, err := ParseCompressionType(c.value)
+		assert.Equal(t, c.ct, ct, "index %d", i)
+		assert.Equal(t, c.hasErr, err != nil, "index %d", i)
+	}
+}
+
+func TestEncodeEntry(t *testing.T) {
+	large := raftpb.Entry{Index: 1, Term: 1, Data: bytes.Repeat([]byte("a"), 1024)}
+	small := raftpb.Entry{Index: 2, Term: 1, Data: []byte("a")}
+
+	v := encodeEntry(&large, Snappy, 64)
+	assert.Equal(t, compressedEntryMagic, v[0])
+	assert.Equal(t, byte(Snappy), v[1])
+	assert.True(t, len(v) < large.Size())
+	var e raftpb.Entry
+	require.NoError(t, decodeEntry(v, &e))
+	assert.Equal(t, large, e)
+
+	// not compressed below the threshold or without the compression
+	for _, v := range [][]byte{encodeEntry(&small, Snappy, 64), encodeEntry(&large, NoCompression, 64)} {
+		assert.NotEqual(t, compressedEntryMagic, v[0])
+		e = raftpb.Entry{}
+		require.NoError(t, decodeEntry(v, &e))
+	}
+
+	assert.Error(t, decodeEntry([]byte{compressedEntryMagic, 100, 1}, &e))
+}
+
+func TestLogDBIterateCompressedEntries(t *testing.T) {
+	tf := func(t *testing.T, db *KVLogDB) {
+		data := bytes.Repeat([]byte("a"), 1024)
+		// the entries saved before the compression enabled remain readable
+		rd := raft.Ready{
+			Entries:   []raftpb.Entry{{Index: 4, Term: 1, Data: data}, {Index: 5, Term: 1, Data: data}},
+			HardState: raftpb.HardState{Commit: 4, Term: 1, Vote: 2},
+		}
+		wc := db.NewWorkerContext()
+		require.NoError(t, db.SaveRaftState(testShardID, testReplicaID, rd, wc))
+
+		WithEntryCompression(Snappy, 64)(db)
+		rd.Entries = []raftpb.Entry{{Index: 6, Term: 1, Data: data}, {Index: 7, Term: 1}}
+		wc.Reset()
+		require.NoError(t, db.SaveRaftState(testShardID, testReplicaID, rd, wc))
+		v, err := db.ms.Get(keys.GetRaftLogKey(testShardID, 6, nil))
+		require.NoError(t, err)
+		assert.Equal(t, compressedEntryMagic, v[0])
+
+		ents, _, err := db.IterateEntries(nil, 0, testShardID, testReplicaID, 4, 8, math.MaxUint64)
+		require.NoError(t, err)
+		require.Equal(t, 4, len(ents))
+		for i, e := range ents {
+			assert.Equal(t, uint64(i+4), e.Index)
+		}
+		assert.Equal(t, data, ents[2].Data)
+
+		ents, _, err = db.IterateEntries(nil, 0, testShardID, testReplicaID, 6, 7, math.MaxUint64)
+		require.NoError(t, err)
+		require.Equal(t, 1, len(ents))
+		assert.Equal(t, data, ents[0].Data)
+	}
+	fs := vfs.GetTestFS()
+	runLogDBTest(t, tf, fs)
+}
+
+type blockingMetadataStore struct {
+	storage.KVMetadataStore
+	writes   uint64
+	startedC chan struct{}
+	blockC   chan struct{}
+}
+
+func (s *blockingMetadataStore) Write(wb util.WriteBatch, sync bool) error {
+	// the first write is blocked until released
+	if atomic.AddUint64(&s.writes, 1) == 1 {
+		close(s.startedC)
+		<-s.blockC
+	}
+	return s.KVMetadataStore.Write(wb, sync)
+}
+
+func TestLogDBGroupCommit(t *testing.T) {
+	tf := func(t *testing.T, db *KVLogDB) {
+		ms := &blockingMetadataStore{
+			KVMetadataStore: db.ms,
+			startedC:        make(chan struct{}),
+			blockC:          make(chan struct{}),
+		}
+		db.ms = ms
+		WithGroupCommit()(db)
+
+		var wg sync.WaitGroup
+		save := func(shardID uint64) {
+			defer wg.Done()
+			wc := db.NewWorkerContext()
+			defer wc.Close()
+			rd := raft.Ready{
+				Entries:   []raftpb.Entry{{Index: 1, Term: 1}},
+				HardState: raftpb.HardState{Commit: 1, Term: 1},
+			}
+			assert.NoError(t, db.SaveRaftState(shardID, testReplicaID, rd, wc))
+		}
+		wg.Add(1)
+		go save(1)
+		<-ms.startedC
+
+		// the writes arrived during the write of the first group are coalesced
+		for shardID := uint64(2); shardID <= 4; shardID++ {
+			wg.Add(1)
+			go save(shardID)
+		}
+		for {
+			db.committer.mu.Lock()
+			n := len(db.committer.mu.pending)
+			db.committer.mu.Unlock()
+			if n == 3 {
+				break
+			}
+			time.Sleep(time.Millisecond)
+		}
+		close(ms.blockC)
+		wg.Wait()
+		assert.Equal(t, uint64(2), atomic.LoadUint64(&ms.writes))
+		assert.False(t, db.committer.mu.writing)
+
+		for shardID := uint64(1); shardID <= 4; shardID++ {
+			ents, _, err := db.IterateEntries(nil, 0, shardID, testReplicaID, 1, 2, math.MaxUint64)
+			require.NoError(t, err)
+			assert.Equal(t, 1, len(ents))
+		}
+	}
+	fs := vfs.GetTestFS()
+	runLogDBTest(t, tf, fs)
+}
+
+type testSyncListener struct {
+	prepared uint64
+	synced   uint64
+}
+
+func (l *testSyncListener) PrepareSync() func() {
+	l.prepared++
+	return func() {
+		l.synced++
+	}
+}
+
+func TestLogDBSyncListener(t *testing.T) {
+	tf := func(t *testing.T, db *KVLogDB) {
+		listener := &testSyncListener{}
+		WithSyncListener(listener)(db)
+
+		wc := db.NewWorkerContext()
+		defer wc.Close()
+		// nothing written
+		assert.NoError(t, db.SaveRaftState(testShardID, testReplicaID, raft.Ready{}, wc))
+		assert.Equal(t, uint64(0), listener.prepared)
+
+		rd := raft.Ready{
+			Entries:   []raftpb.Entry{{Index: 1, Term: 1}},
+			HardState: raftpb.HardState{Commit: 1, Term: 1},
+		}
+		assert.NoError(t, db.SaveRaftState(testShardID, testReplicaID, rd, wc))
+		assert.Equal(t, uint64(1), listener.prepared)
+		assert.Equal(t, uint64(1), listener.synced)
+	}
+	fs := vfs.GetTestFS()
+	runLogDBTest(t, tf, fs)
+}
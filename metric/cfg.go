@@ -23,6 +23,12 @@ type Cfg struct {
 	Interval int    `toml:"interval"`
 	Job      string `toml:"job"`
 	Instance string `toml:"instance"`
+	// ProposalStagesByGroup observes the durations of the proposal stages per
+	// group, otherwise per store only.
+	ProposalStagesByGroup bool `toml:"proposal-stages-by-group"`
+	// ListenAddr the address of the http listener to scrape the metrics at the
+	// /metrics path, the listener is disabled if it's empty.
+	ListenAddr string `toml:"listen-addr"`
 }
 
 func (c Cfg) instance() string {
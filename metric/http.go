@@ -0,0 +1,33 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metric
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const (
+	// Path the http path to scrape the metrics
+	Path = "/metrics"
+)
+
+// NewHandler returns the http handler serving the metrics at the `Path` in the
+// prometheus text format.
+func NewHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle(Path, promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+	return mux
+}
@@ -31,17 +31,36 @@ func init() {
 	registry.MustRegister(batchGauge)
 	registry.MustRegister(storeStorageGauge)
 	registry.MustRegister(shardCountGauge)
+	registry.MustRegister(splitCheckQueueAgeGauge)
+	registry.MustRegister(orphanDataGauge)
+	registry.MustRegister(proxyDispatchQueueGauge)
+	registry.MustRegister(proxyDispatchQueueAgeGauge)
+	registry.MustRegister(memoryGauge)
+	registry.MustRegister(storageWriteStallGauge)
+	registry.MustRegister(storageL0FilesGauge)
+	registry.MustRegister(startupReplicasGauge)
+	registry.MustRegister(proxyBackendQueueGauge)
 
 	registry.MustRegister(raftReadyCounter)
 	registry.MustRegister(raftMsgsCounter)
+	registry.MustRegister(raftDroppedMsgsCounter)
 	registry.MustRegister(raftCommandCounter)
 	registry.MustRegister(raftAdminCommandCounter)
+	registry.MustRegister(storageIOBytesCounter)
+	registry.MustRegister(storageIOThrottledCounter)
+	registry.MustRegister(proxyRetryCounter)
+	registry.MustRegister(clientRequestCounter)
+	registry.MustRegister(admissionRejectedCounter)
 
 	registry.MustRegister(raftLogLagHistogram)
 	registry.MustRegister(raftLogAppendDurationHistogram)
 	registry.MustRegister(raftLogApplyDurationHistogram)
 	registry.MustRegister(raftProposalSizeHistogram)
+	registry.MustRegister(raftProposalStageDurationHistogram)
 	registry.MustRegister(snapshotSizeHistogram)
 	registry.MustRegister(snapshotBuildingDurationHistogram)
 	registry.MustRegister(snapshotSendingDurationHistogram)
+	registry.MustRegister(splitCheckWaitDurationHistogram)
+	registry.MustRegister(proxyDispatchWaitDurationHistogram)
+	registry.MustRegister(clientRequestDurationHistogram)
 }
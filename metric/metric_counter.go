@@ -14,6 +14,8 @@
 package metric
 
 import (
+	"time"
+
 	"github.com/prometheus/client_golang/prometheus"
 )
 
@@ -34,6 +36,14 @@ var (
 			Help:      "Total number of raft ready sent messages.",
 		}, []string{"type"})
 
+	raftDroppedMsgsCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "matrixcube",
+			Subsystem: "raftstore",
+			Name:      "raft_dropped_msg_total",
+			Help:      "Total number of received raft messages dropped before stepping the raft.",
+		}, []string{"reason"})
+
 	raftCommandCounter = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
 			Namespace: "matrixcube",
@@ -49,6 +59,46 @@ var (
 			Name:      "command_admin_total",
 			Help:      "Total number of admin commands processed.",
 		}, []string{"type", "status"})
+
+	storageIOBytesCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "matrixcube",
+			Subsystem: "storage",
+			Name:      "io_written_bytes_total",
+			Help:      "Total bytes written by the storage of each io class.",
+		}, []string{"storage", "class"})
+
+	storageIOThrottledCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "matrixcube",
+			Subsystem: "storage",
+			Name:      "io_throttled_seconds_total",
+			Help:      "Total time of the storage writes throttled of each io class.",
+		}, []string{"storage", "class"})
+
+	proxyRetryCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "matrixcube",
+			Subsystem: "raftstore",
+			Name:      "proxy_retry_total",
+			Help:      "Total number of the requests failed to dispatch by the proxy and retried or given up.",
+		}, []string{"result"})
+
+	clientRequestCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "matrixcube",
+			Subsystem: "client",
+			Name:      "request_total",
+			Help:      "Total number of the requests completed by the client.",
+		}, []string{"type", "result"})
+
+	admissionRejectedCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "matrixcube",
+			Subsystem: "raftstore",
+			Name:      "admission_rejected_total",
+			Help:      "Total number of the write requests rejected by the admission control.",
+		}, []string{"reason"})
 )
 
 // IncComandCount inc the command received
@@ -116,11 +166,32 @@ func AddRaftTransferLeaderMsgsCount(value uint64) {
 	raftMsgsCounter.WithLabelValues("transfer").Add(float64(value))
 }
 
+// AddRaftDroppedStaleTermMsgsCount add the received raft messages dropped by
+// the stale term
+func AddRaftDroppedStaleTermMsgsCount(value uint64) {
+	raftDroppedMsgsCounter.WithLabelValues("stale_term").Add(float64(value))
+}
+
+// AddRaftDroppedDuplicateMsgsCount add the duplicated raft messages dropped
+func AddRaftDroppedDuplicateMsgsCount(value uint64) {
+	raftDroppedMsgsCounter.WithLabelValues("duplicate").Add(float64(value))
+}
+
 // AddRaftProposalReadLocalCount add read local
 func AddRaftProposalReadLocalCount(value uint64) {
 	raftMsgsCounter.WithLabelValues("read-local").Add(float64(value))
 }
 
+// AddRaftProposalReadStaleCount add read stale
+func AddRaftProposalReadStaleCount(value uint64) {
+	raftMsgsCounter.WithLabelValues("read-stale").Add(float64(value))
+}
+
+// AddRaftProposalReadFollowerCount add read follower
+func AddRaftProposalReadFollowerCount(value uint64) {
+	raftMsgsCounter.WithLabelValues("read-follower").Add(float64(value))
+}
+
 // AddRaftProposalReadIndexCount add read index
 func AddRaftProposalReadIndexCount(value uint64) {
 	raftMsgsCounter.WithLabelValues("read-index").Add(float64(value))
@@ -166,6 +237,16 @@ func AddRaftAdminCommandSplitSucceedCount(value uint64) {
 	raftAdminCommandCounter.WithLabelValues("split", "succeed").Add(float64(value))
 }
 
+// AddRaftAdminCommandMergeCount admin command of merge shard
+func AddRaftAdminCommandMergeCount(value uint64) {
+	raftAdminCommandCounter.WithLabelValues("merge", "total").Add(float64(value))
+}
+
+// AddRaftAdminCommandMergeSucceedCount admin command of merge shard succeed
+func AddRaftAdminCommandMergeSucceedCount(value uint64) {
+	raftAdminCommandCounter.WithLabelValues("merge", "succeed").Add(float64(value))
+}
+
 // AddRaftAdminCommandCompactCount admin command of compact raft log
 func AddRaftAdminCommandCompactCount(value uint64) {
 	raftAdminCommandCounter.WithLabelValues("compact", "succeed").Add(float64(value))
@@ -175,3 +256,41 @@ func AddRaftAdminCommandCompactCount(value uint64) {
 func AddRaftAdminCommandCompactSucceedCount(value uint64) {
 	raftAdminCommandCounter.WithLabelValues("compact", "succeed").Add(float64(value))
 }
+
+// AddStorageIOWrittenBytes add the bytes written by the storage of the io class
+func AddStorageIOWrittenBytes(storage, class string, value uint64) {
+	storageIOBytesCounter.WithLabelValues(storage, class).Add(float64(value))
+}
+
+// AddStorageIOThrottledDuration add the duration of the storage writes of the
+// io class throttled
+func AddStorageIOThrottledDuration(storage, class string, value time.Duration) {
+	storageIOThrottledCounter.WithLabelValues(storage, class).Add(value.Seconds())
+}
+
+// IncProxyRetryCount inc the requests failed to dispatch by the proxy and
+// scheduled to retry
+func IncProxyRetryCount() {
+	proxyRetryCounter.WithLabelValues("retry").Inc()
+}
+
+// IncProxyRetryExhaustedCount inc the requests failed to dispatch by the proxy
+// and not allowed to retry anymore
+func IncProxyRetryExhaustedCount() {
+	proxyRetryCounter.WithLabelValues("exhausted").Inc()
+}
+
+// IncClientRequestCount inc the requests of the type completed by the client
+func IncClientRequestCount(requestType string, succeed bool) {
+	result := "succeed"
+	if !succeed {
+		result = "failed"
+	}
+	clientRequestCounter.WithLabelValues(requestType, result).Inc()
+}
+
+// IncAdmissionRejectedCount inc the write requests rejected by the admission
+// control for the reason
+func IncAdmissionRejectedCount(reason string) {
+	admissionRejectedCounter.WithLabelValues(reason).Inc()
+}
@@ -14,6 +14,8 @@
 package metric
 
 import (
+	"time"
+
 	"github.com/prometheus/client_golang/prometheus"
 )
 
@@ -42,6 +44,78 @@ var (
 			Help:      "Total number of store shards.",
 		}, []string{"type"})
 
+	splitCheckQueueAgeGauge = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: "matrixcube",
+			Subsystem: "raftstore",
+			Name:      "split_check_queue_age_seconds",
+			Help:      "Waiting time of the oldest shard in the split check queue.",
+		})
+
+	proxyDispatchQueueGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "matrixcube",
+			Subsystem: "raftstore",
+			Name:      "proxy_dispatch_queue_size",
+			Help:      "Total size of the proxy dispatch queue of the target store.",
+		}, []string{"store"})
+
+	proxyDispatchQueueAgeGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "matrixcube",
+			Subsystem: "raftstore",
+			Name:      "proxy_dispatch_queue_age_seconds",
+			Help:      "Waiting time of the head request in the proxy dispatch queue of the target store.",
+		}, []string{"store"})
+
+	proxyBackendQueueGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "matrixcube",
+			Subsystem: "raftstore",
+			Name:      "proxy_backend_queue_size",
+			Help:      "Total size of the requests queued to be sent by the proxy backend of the remote store.",
+		}, []string{"addr"})
+
+	orphanDataGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "matrixcube",
+			Subsystem: "raftstore",
+			Name:      "store_orphan_data",
+			Help:      "Orphan data found by the last orphan data gc of the store.",
+		}, []string{"type"})
+
+	memoryGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "matrixcube",
+			Subsystem: "raftstore",
+			Name:      "memory_bytes",
+			Help:      "Memory used by each subsystem of the store.",
+		}, []string{"subsystem"})
+
+	storageWriteStallGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "matrixcube",
+			Subsystem: "raftstore",
+			Name:      "storage_write_stall",
+			Help:      "Write stall state of the data storage of the group, 1 means stalled.",
+		}, []string{"group"})
+
+	storageL0FilesGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "matrixcube",
+			Subsystem: "raftstore",
+			Name:      "storage_l0_files",
+			Help:      "Number of the L0 files of the data storage of the group.",
+		}, []string{"group"})
+
+	startupReplicasGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "matrixcube",
+			Subsystem: "raftstore",
+			Name:      "startup_replicas",
+			Help:      "Number of the replicas loaded and started while the store is starting.",
+		}, []string{"type"})
+
 	storeStorageGauge = prometheus.NewGaugeVec(
 		prometheus.GaugeOpts{
 			Namespace: "matrixcube",
@@ -86,6 +160,45 @@ func SetRaftSnapQueueMetric(size int64) {
 	queueGauge.WithLabelValues("sent-snap").Set(float64(size))
 }
 
+// SetSplitCheckQueueMetric set split check queue size and the waiting time of
+// the oldest shard in the queue
+func SetSplitCheckQueueMetric(size int, age time.Duration) {
+	queueGauge.WithLabelValues("split-check").Set(float64(size))
+	splitCheckQueueAgeGauge.Set(age.Seconds())
+}
+
+// SetProxyDispatchQueueMetric set the proxy dispatch queue size of the target
+// store and the waiting time of the head request in the queue
+func SetProxyDispatchQueueMetric(store string, size int64, age time.Duration) {
+	proxyDispatchQueueGauge.WithLabelValues(store).Set(float64(size))
+	proxyDispatchQueueAgeGauge.WithLabelValues(store).Set(age.Seconds())
+}
+
+// RemoveProxyDispatchQueueMetric removes the proxy dispatch queue metrics of the
+// target store
+func RemoveProxyDispatchQueueMetric(store string) {
+	proxyDispatchQueueGauge.DeleteLabelValues(store)
+	proxyDispatchQueueAgeGauge.DeleteLabelValues(store)
+}
+
+// SetProxyBackendQueueMetric set the size of the requests queued to be sent by
+// the proxy backend of the remote store
+func SetProxyBackendQueueMetric(addr string, size int64) {
+	proxyBackendQueueGauge.WithLabelValues(addr).Set(float64(size))
+}
+
+// RemoveProxyBackendQueueMetric removes the queue size metric of the proxy
+// backend of the remote store
+func RemoveProxyBackendQueueMetric(addr string) {
+	proxyBackendQueueGauge.DeleteLabelValues(addr)
+}
+
+// SetVacuumQueueMetric set the number of the vacuum tasks pending or being
+// processed by the vacuum cleaner
+func SetVacuumQueueMetric(size int64) {
+	queueGauge.WithLabelValues("vacuum").Set(float64(size))
+}
+
 // SetRaftProposalBatchMetric set proposal batch size
 func SetRaftProposalBatchMetric(size int64) {
 	batchGauge.WithLabelValues("proposal").Set(float64(size))
@@ -97,8 +210,39 @@ func SetShardsOnStore(leader int, count int) {
 	shardCountGauge.WithLabelValues("leader").Set(float64(leader))
 }
 
+// SetOrphanDataOnStore set the orphan ranges, keys and bytes found by the last
+// orphan data gc on the current store
+func SetOrphanDataOnStore(ranges int, keys uint64, bytes uint64) {
+	orphanDataGauge.WithLabelValues("ranges").Set(float64(ranges))
+	orphanDataGauge.WithLabelValues("keys").Set(float64(keys))
+	orphanDataGauge.WithLabelValues("bytes").Set(float64(bytes))
+}
+
 // SetStorageOnStore set total and free storage on the current store
 func SetStorageOnStore(total uint64, free uint64) {
 	storeStorageGauge.WithLabelValues("total").Set(float64(total))
 	storeStorageGauge.WithLabelValues("free").Set(float64(free))
 }
+
+// SetMemoryMetric set the memory used by the subsystem of the store
+func SetMemoryMetric(subsystem string, bytes uint64) {
+	memoryGauge.WithLabelValues(subsystem).Set(float64(bytes))
+}
+
+// SetStorageWriteStallMetric set the write stall state and the number of the L0
+// files of the data storage of the group
+func SetStorageWriteStallMetric(group string, stalled bool, l0Files int64) {
+	v := float64(0)
+	if stalled {
+		v = 1
+	}
+	storageWriteStallGauge.WithLabelValues(group).Set(v)
+	storageL0FilesGauge.WithLabelValues(group).Set(float64(l0Files))
+}
+
+// SetStartupReplicasMetric set the number of the replicas loaded from the data
+// storages and started while the store is starting
+func SetStartupReplicasMetric(loaded uint64, started uint64) {
+	startupReplicasGauge.WithLabelValues("loaded").Set(float64(loaded))
+	startupReplicasGauge.WithLabelValues("started").Set(float64(started))
+}
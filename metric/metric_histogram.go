@@ -47,6 +47,15 @@ var (
 			Buckets:   prometheus.ExponentialBuckets(0.0005, 2.0, 20),
 		})
 
+	splitCheckWaitDurationHistogram = prometheus.NewHistogram(
+		prometheus.HistogramOpts{
+			Namespace: "matrixcube",
+			Subsystem: "raftstore",
+			Name:      "split_check_wait_duration_seconds",
+			Help:      "Bucketed histogram of shard waiting to be split checked duration.",
+			Buckets:   prometheus.ExponentialBuckets(0.0005, 2.0, 20),
+		})
+
 	snapshotSizeHistogram = prometheus.NewHistogram(
 		prometheus.HistogramOpts{
 			Namespace: "matrixcube",
@@ -73,6 +82,33 @@ var (
 			Help:      "Bucketed histogram of server send snapshots duration.",
 		})
 
+	proxyDispatchWaitDurationHistogram = prometheus.NewHistogram(
+		prometheus.HistogramOpts{
+			Namespace: "matrixcube",
+			Subsystem: "raftstore",
+			Name:      "proxy_dispatch_wait_duration_seconds",
+			Help:      "Bucketed histogram of request waiting in the proxy dispatch queue duration.",
+			Buckets:   prometheus.ExponentialBuckets(0.00005, 2.0, 20),
+		})
+
+	clientRequestDurationHistogram = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: "matrixcube",
+			Subsystem: "client",
+			Name:      "request_duration_seconds",
+			Help:      "Bucketed histogram of the duration of the requests completed by the client.",
+			Buckets:   prometheus.ExponentialBuckets(0.0005, 2.0, 20),
+		}, []string{"type"})
+
+	raftProposalStageDurationHistogram = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: "matrixcube",
+			Subsystem: "raftstore",
+			Name:      "raft_proposal_stage_duration_seconds",
+			Help:      "Bucketed histogram of the duration of each stage of the proposal path.",
+			Buckets:   prometheus.ExponentialBuckets(0.00005, 2.0, 20),
+		}, []string{"store", "group", "stage"})
+
 	raftLogLagHistogram = prometheus.NewHistogram(
 		prometheus.HistogramOpts{
 			Namespace: "matrixcube",
@@ -83,6 +119,73 @@ var (
 		})
 )
 
+// ProposalStage is a stage of the proposal path
+type ProposalStage int
+
+const (
+	// ProposalStageQueueWait is the time of the request waiting in the request
+	// queue of the replica
+	ProposalStageQueueWait ProposalStage = iota
+	// ProposalStageRaftStep is the time of the proposal stepped into raft
+	ProposalStageRaftStep
+	// ProposalStageLogDBAppend is the time of the raft state appended and synced
+	// to the logdb
+	ProposalStageLogDBAppend
+	// ProposalStageReplication is the time from the proposal made to committed
+	// by the quorum
+	ProposalStageReplication
+	// ProposalStageCommitWait is the time of the committed proposal waiting to be
+	// applied
+	ProposalStageCommitWait
+	// ProposalStageApply is the time of the entry applied to the state machine
+	ProposalStageApply
+	// ProposalStageCallback is the time of the response callback
+	ProposalStageCallback
+
+	proposalStageCount
+)
+
+var proposalStageNames = [proposalStageCount]string{
+	"queue-wait",
+	"raft-step",
+	"logdb-append",
+	"replication",
+	"commit-wait",
+	"apply",
+	"callback",
+}
+
+func (s ProposalStage) String() string {
+	return proposalStageNames[s]
+}
+
+// ProposalStageObservers observes the durations of the proposal stages of a
+// replica, the observers are resolved once to avoid looking up the labels on
+// the proposal path. A nil ProposalStageObservers observes nothing.
+type ProposalStageObservers struct {
+	observers [proposalStageCount]prometheus.Observer
+}
+
+// NewProposalStageObservers returns the observers of the proposal stages with
+// the store and group labels, the group label is empty if the durations are
+// not observed per group.
+func NewProposalStageObservers(store, group string) *ProposalStageObservers {
+	o := &ProposalStageObservers{}
+	for i := range o.observers {
+		o.observers[i] = raftProposalStageDurationHistogram.WithLabelValues(store,
+			group, proposalStageNames[i])
+	}
+	return o
+}
+
+// Observe observes the duration of the stage started at the specified time
+func (o *ProposalStageObservers) Observe(stage ProposalStage, start time.Time) {
+	if o == nil || start.IsZero() {
+		return
+	}
+	o.observers[stage].Observe(time.Since(start).Seconds())
+}
+
 // ObserveProposalBytes observe bytes per raft proposal
 func ObserveProposalBytes(size int64) {
 	raftProposalSizeHistogram.Observe(float64(size))
@@ -117,3 +220,21 @@ func ObserveRaftLogApplyDuration(start time.Time) {
 func ObserveRaftLogLag(size uint64) {
 	raftLogLagHistogram.Observe(float64(size))
 }
+
+// ObserveSplitCheckWaitDuration observe the duration of shard waiting to be
+// split checked
+func ObserveSplitCheckWaitDuration(start time.Time) {
+	splitCheckWaitDurationHistogram.Observe(time.Now().Sub(start).Seconds())
+}
+
+// ObserveProxyDispatchWaitDuration observe the duration of request waiting in
+// the proxy dispatch queue of the target store
+func ObserveProxyDispatchWaitDuration(start time.Time) {
+	proxyDispatchWaitDurationHistogram.Observe(time.Now().Sub(start).Seconds())
+}
+
+// ObserveClientRequestDuration observe the duration of the request of the type
+// completed by the client
+func ObserveClientRequestDuration(requestType string, start time.Time) {
+	clientRequestDurationHistogram.WithLabelValues(requestType).Observe(time.Now().Sub(start).Seconds())
+}
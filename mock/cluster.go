@@ -0,0 +1,391 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package mock provides an in-memory fake of the matrixcube cluster for the
+// unit tests of the applications embedding matrixcube. The fake implements the
+// `raftstore.Store`, `raftstore.Router` and `client.Client` without starting the
+// raft, the requests are executed synchronously by a `Handler` in the order of
+// dispatching, and the shard topology and the errors are controlled by the test.
+package mock
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/matrixorigin/matrixcube/client"
+	"github.com/matrixorigin/matrixcube/components/log"
+	"github.com/matrixorigin/matrixcube/pb/errorpb"
+	"github.com/matrixorigin/matrixcube/pb/metapb"
+	"github.com/matrixorigin/matrixcube/pb/rpcpb"
+	"github.com/matrixorigin/matrixcube/raftstore"
+	"go.uber.org/zap"
+)
+
+// Handler executes the request on the shard, the returned value is the value of
+// the response. The returned error is responded like the raftstore does, and the
+// client retries the request until the context is done.
+type Handler func(shard metapb.Shard, req rpcpb.Request) ([]byte, error)
+
+// ErrorInjector returns the error responded to the request instead of executing
+// it, nil means the request is executed by the `Handler`.
+type ErrorInjector func(shard metapb.Shard, req rpcpb.Request) *errorpb.Error
+
+// Option the option to create the Cluster
+type Option func(*options)
+
+type options struct {
+	logger  *zap.Logger
+	handler Handler
+}
+
+// WithLogger set the logger of the Cluster
+func WithLogger(logger *zap.Logger) Option {
+	return func(opts *options) {
+		opts.logger = logger
+	}
+}
+
+// WithHandler set the Handler to execute the requests, the in-memory KV created
+// by `NewKVHandler` is used by default.
+func WithHandler(handler Handler) Option {
+	return func(opts *options) {
+		opts.handler = handler
+	}
+}
+
+// Cluster is an in-memory fake of the matrixcube cluster. The topology of the
+// cluster is maintained by the test with `AddStore`, `AddShard`, `SetLeader` and
+// `Split`, and all the stores, routers and clients created by the Cluster see
+// the same topology.
+type Cluster struct {
+	logger  *zap.Logger
+	handler Handler
+	router  raftstore.Router
+
+	mu struct {
+		sync.RWMutex
+		id       uint64
+		stores   map[uint64]*store
+		shards   map[uint64]metapb.Shard
+		leaders  map[uint64]uint64 // shard id -> store id
+		injector ErrorInjector
+		requests []rpcpb.Request
+		proxies  []raftstore.ShardsProxy
+	}
+}
+
+// NewCluster returns an in-memory fake of the matrixcube cluster without any
+// store and shard.
+func NewCluster(opts ...Option) *Cluster {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.handler == nil {
+		o.handler = NewKVHandler()
+	}
+
+	c := &Cluster{
+		logger:  log.Adjust(o.logger).Named("mock-cluster"),
+		handler: o.handler,
+		router:  raftstore.NewMockRouter(),
+	}
+	c.mu.stores = make(map[uint64]*store)
+	c.mu.shards = make(map[uint64]metapb.Shard)
+	c.mu.leaders = make(map[uint64]uint64)
+	return c
+}
+
+// Close closes the clients created by the Cluster.
+func (c *Cluster) Close() {
+	c.mu.Lock()
+	proxies := c.mu.proxies
+	c.mu.proxies = nil
+	c.mu.Unlock()
+
+	for _, sp := range proxies {
+		if err := sp.Stop(); err != nil {
+			c.logger.Error("fail to stop shards proxy", zap.Error(err))
+		}
+	}
+}
+
+// AddStore adds a store to the cluster, the ClientAddress of the store is set by
+// the store id if it's empty.
+func (c *Cluster) AddStore(meta metapb.Store) raftstore.Store {
+	if meta.ClientAddress == "" {
+		meta.ClientAddress = fmt.Sprintf("mock-store-%d", meta.ID)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if meta.ID > c.mu.id {
+		c.mu.id = meta.ID
+	}
+	s := newStore(c, meta)
+	c.mu.stores[meta.ID] = s
+	c.router.UpdateStore(meta)
+	return s
+}
+
+// GetStore returns the store added by `AddStore`, nil if the store is not found.
+func (c *Cluster) GetStore(id uint64) raftstore.Store {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if s, ok := c.mu.stores[id]; ok {
+		return s
+	}
+	return nil
+}
+
+// AddShard adds or updates a shard of the cluster, the replica on the leader
+// store is the leader of the shard, and the shard has no leader if the leader
+// store is 0. The stores of the replicas must be added before.
+func (c *Cluster) AddShard(shard metapb.Shard, leaderStore uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, r := range shard.Replicas {
+		if _, ok := c.mu.stores[r.StoreID]; !ok {
+			c.logger.Fatal("store of the replica not added",
+				log.ShardField("shard", shard),
+				zap.Uint64("store", r.StoreID))
+		}
+	}
+	if shard.ID > c.mu.id {
+		c.mu.id = shard.ID
+	}
+	c.mu.shards[shard.ID] = shard
+	c.router.UpdateShard(shard)
+	c.setLeaderLocked(shard.ID, leaderStore)
+}
+
+// SetLeader moves the leader of the shard to the replica on the store.
+func (c *Cluster) SetLeader(shardID uint64, storeID uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.setLeaderLocked(shardID, storeID)
+}
+
+func (c *Cluster) setLeaderLocked(shardID uint64, storeID uint64) {
+	if storeID == 0 {
+		delete(c.mu.leaders, shardID)
+		return
+	}
+
+	shard, ok := c.mu.shards[shardID]
+	if !ok {
+		c.logger.Fatal("shard not added",
+			log.ShardIDField(shardID))
+	}
+	for _, r := range shard.Replicas {
+		if r.StoreID == storeID {
+			c.mu.leaders[shardID] = storeID
+			c.router.UpdateLeader(shardID, r.ID)
+			return
+		}
+	}
+	c.logger.Fatal("no replica on the leader store",
+		log.ShardField("shard", shard),
+		zap.Uint64("store", storeID))
+}
+
+// Split splits the shard at the key like the raftstore, the shard keeps the range
+// before the key, the new shard with the newShardID serves the range from the key,
+// and has the same replicas, leader and durability policy as the shard. The ids
+// of the replicas of the new shard are allocated by the Cluster.
+func (c *Cluster) Split(shardID uint64, key []byte, newShardID uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	shard, ok := c.mu.shards[shardID]
+	if !ok {
+		c.logger.Fatal("shard not added",
+			log.ShardIDField(shardID))
+	}
+	if newShardID > c.mu.id {
+		c.mu.id = newShardID
+	}
+
+	newShard := shard
+	newShard.ID = newShardID
+	newShard.Start = key
+	newShard.Replicas = make([]metapb.Replica, 0, len(shard.Replicas))
+	for _, r := range shard.Replicas {
+		c.mu.id++
+		r.ID = c.mu.id
+		newShard.Replicas = append(newShard.Replicas, r)
+	}
+	shard.End = key
+	shard.Epoch.Generation++
+	newShard.Epoch = shard.Epoch
+
+	c.mu.shards[shard.ID] = shard
+	c.mu.shards[newShard.ID] = newShard
+	c.router.UpdateShard(shard)
+	c.router.UpdateShard(newShard)
+	if leader, ok := c.mu.leaders[shardID]; ok {
+		c.setLeaderLocked(newShardID, leader)
+	}
+}
+
+// SetErrorInjector sets the ErrorInjector, nil means no error is injected.
+func (c *Cluster) SetErrorInjector(injector ErrorInjector) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.mu.injector = injector
+}
+
+// Requests returns all the requests received by the cluster in order, including
+// the requests responded with the injected errors.
+func (c *Cluster) Requests() []rpcpb.Request {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return append([]rpcpb.Request(nil), c.mu.requests...)
+}
+
+// Router returns the router with the topology of the cluster.
+func (c *Cluster) Router() raftstore.Router {
+	return c.router
+}
+
+// NewClient returns a started client dispatching the requests to the cluster,
+// the client is stopped by `Close`.
+func (c *Cluster) NewClient() client.Client {
+	cli := client.NewClientWithOptions(client.CreateWithLogger(c.logger.Named("client")),
+		client.CreateWithShardsProxy(c.newShardsProxy()))
+	if err := cli.Start(); err != nil {
+		c.logger.Fatal("fail to start client", zap.Error(err))
+	}
+	return cli
+}
+
+func (c *Cluster) newShardsProxy() raftstore.ShardsProxy {
+	sp, err := raftstore.NewMockShardsProxy(c.router, c.handle)
+	if err != nil {
+		c.logger.Fatal("fail to create shards proxy", zap.Error(err))
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.mu.proxies = append(c.mu.proxies, sp)
+	return sp
+}
+
+func (c *Cluster) allocID() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.mu.id++
+	return c.mu.id
+}
+
+func (c *Cluster) isLeader(shardID, storeID uint64) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	leader, ok := c.mu.leaders[shardID]
+	return ok && leader == storeID
+}
+
+// handle executes the request dispatched by the shards proxy, the request is
+// sent to the leader of the shard.
+func (c *Cluster) handle(req rpcpb.Request) (rpcpb.ResponseBatch, error) {
+	shardID := req.ToShard
+	if shardID == 0 {
+		shardID = c.router.SelectShardIDByKey(req.Group, req.Key)
+	}
+
+	c.mu.RLock()
+	leader := c.mu.leaders[shardID]
+	c.mu.RUnlock()
+	return c.execute(req, shardID, leader), nil
+}
+
+// execute executes the request on the replica of the shard on the store.
+func (c *Cluster) execute(req rpcpb.Request, shardID uint64, storeID uint64) rpcpb.ResponseBatch {
+	c.mu.Lock()
+	c.mu.requests = append(c.mu.requests, req)
+	shard, ok := c.mu.shards[shardID]
+	s := c.mu.stores[storeID]
+	leader := c.mu.leaders[shardID]
+	injector := c.mu.injector
+	c.mu.Unlock()
+
+	resp := rpcpb.Response{Type: req.Type, ID: req.ID, PID: req.PID}
+	respWithError := func(err errorpb.Error) rpcpb.ResponseBatch {
+		return rpcpb.ResponseBatch{
+			Header:    rpcpb.ResponseBatchHeader{Error: err},
+			Responses: []rpcpb.Response{resp},
+		}
+	}
+
+	if !ok {
+		return respWithError(errorpb.Error{
+			Message:       fmt.Sprintf("shard %d not found", shardID),
+			ShardNotFound: &errorpb.ShardNotFound{ShardID: shardID},
+		})
+	}
+	if s == nil {
+		return respWithError(errorpb.Error{
+			Message:       fmt.Sprintf("store %d not match", storeID),
+			StoreMismatch: &errorpb.StoreMismatch{},
+		})
+	}
+	if leader != storeID {
+		err := &errorpb.NotLeader{ShardID: shardID}
+		for _, r := range shard.Replicas {
+			if r.StoreID == leader {
+				err.Leader = r
+			}
+		}
+		return respWithError(errorpb.Error{
+			Message:   fmt.Sprintf("store %d is not the leader of shard %d", storeID, shardID),
+			NotLeader: err,
+		})
+	}
+	if s.isGroupStopped(shard.Group) {
+		return respWithError(errorpb.Error{
+			Message:      fmt.Sprintf("group %d is stopped", shard.Group),
+			GroupStopped: &errorpb.GroupStopped{Group: shard.Group},
+		})
+	}
+	if injector != nil {
+		if err := injector(shard, req); err != nil {
+			return respWithError(*err)
+		}
+	}
+
+	// the write ops of the batch write are executed by the Handler one by one as
+	// the write requests
+	if len(req.WriteOps) > 0 {
+		for _, op := range req.WriteOps {
+			opReq := req
+			opReq.CustomType = op.CustomType
+			opReq.Key = op.Key
+			opReq.Cmd = op.Cmd
+			opReq.WriteOps = nil
+			value, err := c.handler(shard, opReq)
+			if err != nil {
+				return respWithError(errorpb.Error{Message: err.Error()})
+			}
+			resp.WriteOpValues = append(resp.WriteOpValues, value)
+		}
+		return rpcpb.ResponseBatch{Responses: []rpcpb.Response{resp}}
+	}
+
+	value, err := c.handler(shard, req)
+	if err != nil {
+		return respWithError(errorpb.Error{Message: err.Error()})
+	}
+	resp.Value = value
+	return rpcpb.ResponseBatch{Responses: []rpcpb.Response{resp}}
+}
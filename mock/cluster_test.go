@@ -0,0 +1,326 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mock
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/fagongzi/util/protoc"
+	"github.com/matrixorigin/matrixcube/client"
+	"github.com/matrixorigin/matrixcube/pb/errorpb"
+	"github.com/matrixorigin/matrixcube/pb/metapb"
+	"github.com/matrixorigin/matrixcube/pb/rpcpb"
+	"github.com/matrixorigin/matrixcube/raftstore"
+	"github.com/matrixorigin/matrixcube/util/leaktest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestCluster() *Cluster {
+	c := NewCluster()
+	c.AddStore(metapb.Store{ID: 1})
+	c.AddStore(metapb.Store{ID: 2})
+	c.AddShard(metapb.Shard{ID: 10, Replicas: []metapb.Replica{
+		{ID: 11, StoreID: 1},
+		{ID: 12, StoreID: 2},
+	}}, 1)
+	return c
+}
+
+func write(t *testing.T, cli client.Client, key, value string) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+	f := cli.Write(ctx, 0, []byte(value), client.WithRouteKey([]byte(key)))
+	defer f.Close()
+	v, err := f.Get()
+	require.NoError(t, err)
+	assert.Equal(t, OK, v)
+}
+
+func read(cli client.Client, key string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+	f := cli.Read(ctx, 0, nil, client.WithRouteKey([]byte(key)))
+	defer f.Close()
+	return f.Get()
+}
+
+func TestClientReadAndWrite(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	c := newTestCluster()
+	defer c.Close()
+	cli := c.NewClient()
+
+	write(t, cli, "k1", "v1")
+	v, err := read(cli, "k1")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("v1"), v)
+
+	requests := c.Requests()
+	require.Equal(t, 2, len(requests))
+	assert.Equal(t, rpcpb.Write, requests[0].Type)
+	assert.Equal(t, rpcpb.Read, requests[1].Type)
+}
+
+func TestClientBatchWrite(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	c := newTestCluster()
+	defer c.Close()
+	cli := c.NewClient()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+	f := cli.BatchWrite(ctx, []client.WriteOp{
+		{Key: []byte("k1"), Payload: []byte("v1")},
+		{Key: []byte("k2"), Payload: []byte("v2")},
+	})
+	defer f.Close()
+	values, err := f.GetBatchWrite()
+	require.NoError(t, err)
+	assert.Equal(t, [][]byte{OK, OK}, values)
+
+	v, err := read(cli, "k2")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("v2"), v)
+}
+
+func TestSplitAndMoveLeader(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	var shards []uint64
+	c := NewCluster(WithHandler(func(shard metapb.Shard, req rpcpb.Request) ([]byte, error) {
+		shards = append(shards, shard.ID)
+		return OK, nil
+	}))
+	defer c.Close()
+	c.AddStore(metapb.Store{ID: 1})
+	c.AddStore(metapb.Store{ID: 2})
+	c.AddShard(metapb.Shard{ID: 10, Replicas: []metapb.Replica{
+		{ID: 11, StoreID: 1},
+		{ID: 12, StoreID: 2},
+	}}, 1)
+	cli := c.NewClient()
+
+	c.Split(10, []byte("m"), 20)
+	assert.Equal(t, []byte("m"), c.Router().GetShard(10).End)
+	assert.Equal(t, []byte("m"), c.Router().GetShard(20).Start)
+	assert.True(t, c.GetStore(1).MaybeLeader(20))
+
+	c.SetLeader(20, 2)
+	assert.False(t, c.GetStore(1).MaybeLeader(20))
+	assert.True(t, c.GetStore(2).MaybeLeader(20))
+	write(t, cli, "a", "v")
+	write(t, cli, "z", "v")
+	assert.Equal(t, []uint64{10, 20}, shards)
+}
+
+func TestInjectedErrors(t *testing.T) {
+	// no leak check, the retries are scheduled by the global timeout wheel whose
+	// goroutines may be reported as leaked.
+	c := newTestCluster()
+	defer c.Close()
+	cli := c.NewClient()
+
+	// the retryable errors are retried by the client
+	failures := 2
+	c.SetErrorInjector(func(shard metapb.Shard, req rpcpb.Request) *errorpb.Error {
+		if failures == 0 {
+			return nil
+		}
+		failures--
+		return &errorpb.Error{Message: "not leader", NotLeader: &errorpb.NotLeader{
+			ShardID: shard.ID,
+			Leader:  shard.Replicas[0],
+		}}
+	})
+	write(t, cli, "k1", "v1")
+	assert.Equal(t, 3, len(c.Requests()))
+
+	c.SetErrorInjector(func(shard metapb.Shard, req rpcpb.Request) *errorpb.Error {
+		return &errorpb.Error{Message: "injected",
+			ShardUnavailable: &errorpb.ShardUnavailable{ShardID: shard.ID}}
+	})
+	_, err := read(cli, "k1")
+	assert.Error(t, err)
+
+	c.SetErrorInjector(nil)
+	v, err := read(cli, "k1")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("v1"), v)
+}
+
+func TestStoreRequests(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	c := newTestCluster()
+	defer c.Close()
+
+	var resp rpcpb.ResponseBatch
+	cb := func(r rpcpb.ResponseBatch) { resp = r }
+	req := rpcpb.Request{ID: []byte("r1"), Type: rpcpb.Write, Key: []byte("k1"), Cmd: []byte("v1")}
+
+	assert.NoError(t, c.GetStore(2).OnRequestWithCB(req, cb))
+	require.NotNil(t, resp.Header.Error.NotLeader)
+	assert.Equal(t, uint64(11), resp.Header.Error.NotLeader.Leader.ID)
+
+	s := c.GetStore(1)
+	s.StopGroup(0)
+	assert.NoError(t, s.OnRequestWithCB(req, cb))
+	assert.NotNil(t, resp.Header.Error.GroupStopped)
+
+	s.StartGroup(0)
+	assert.NoError(t, s.OnRequestWithCB(req, cb))
+	assert.False(t, errorpb.HasError(resp.Header.Error))
+	require.Equal(t, 1, len(resp.Responses))
+	assert.Equal(t, OK, resp.Responses[0].Value)
+
+	cli := client.NewClient(client.Cfg{Store: s})
+	assert.NoError(t, cli.Start())
+	v, err := read(cli, "k1")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("v1"), v)
+	assert.NotEqual(t, uint64(0), s.MustAllocID())
+}
+
+// newMigrationHandler returns the KV handler of the group migration test, the
+// read request of type 1 responds all the key values of the shard, and the
+// barrier request with the Migrated fences the cluster.
+func newMigrationHandler(migrated *int32) Handler {
+	kv := NewKVHandler()
+	var mu sync.Mutex
+	keys := make(map[string]struct{})
+	return func(shard metapb.Shard, req rpcpb.Request) ([]byte, error) {
+		switch {
+		case req.Type == rpcpb.Admin:
+			var barrier rpcpb.BarrierRequest
+			protoc.MustUnmarshal(&barrier, req.Cmd)
+			if barrier.Migrated {
+				atomic.StoreInt32(migrated, 1)
+			} else if barrier.Unfence {
+				atomic.StoreInt32(migrated, 0)
+			}
+			return protoc.MustMarshal(&rpcpb.BarrierResponse{Index: 1, Shard: shard}), nil
+		case req.Type == rpcpb.Read && req.CustomType == 1:
+			mu.Lock()
+			defer mu.Unlock()
+			var values []string
+			for k := range keys {
+				v, _ := kv(shard, rpcpb.Request{Type: rpcpb.Read, Key: []byte(k)})
+				values = append(values, k+"="+string(v))
+			}
+			sort.Strings(values)
+			return []byte(strings.Join(values, ",")), nil
+		case req.Type == rpcpb.Write:
+			mu.Lock()
+			keys[string(req.Key)] = struct{}{}
+			mu.Unlock()
+		}
+		return kv(shard, req)
+	}
+}
+
+func TestGroupMigration(t *testing.T) {
+	// no leak check, the retries are scheduled by the global timeout wheel whose
+	// goroutines may be reported as leaked.
+	var migrated int32
+	source := NewCluster(WithHandler(newMigrationHandler(&migrated)))
+	source.AddStore(metapb.Store{ID: 1})
+	source.AddShard(metapb.Shard{ID: 10, Replicas: []metapb.Replica{{ID: 11, StoreID: 1}}}, 1)
+	defer source.Close()
+	source.SetErrorInjector(func(shard metapb.Shard, req rpcpb.Request) *errorpb.Error {
+		if req.Type == rpcpb.Admin || atomic.LoadInt32(&migrated) == 0 {
+			return nil
+		}
+		return &errorpb.Error{Message: "migrated", GroupMigrated: &errorpb.GroupMigrated{Group: shard.Group}}
+	})
+	dest := newTestCluster()
+	defer dest.Close()
+
+	cli1, cli2, target := source.NewClient(), source.NewClient(), dest.NewClient()
+	proxies := source.mu.proxies
+	setPhase := func(phase raftstore.MigrationPhase) {
+		for _, sp := range proxies {
+			require.NoError(t, sp.SetGroupMigration(raftstore.GroupMigration{
+				Phase:  phase,
+				Target: client.NewMigrationTarget(target),
+				Verify: true,
+			}))
+		}
+	}
+	waitReplicated := func() {
+		require.Eventually(t, func() bool {
+			for _, sp := range proxies {
+				if status, _ := sp.GetGroupMigration(0); status.Pending > 0 {
+					return false
+				}
+			}
+			return true
+		}, time.Second*10, time.Millisecond*10)
+	}
+
+	// the writes are queued while the existing data is copied
+	write(t, cli1, "k1", "v1")
+	setPhase(raftstore.MigrationReplicating)
+	write(t, cli2, "k2", "v2")
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+	require.NoError(t, cli1.ReplicateGroup(ctx, 0, target, 1, nil, func(r client.ScanResult) ([]client.WriteOp, error) {
+		var ops []client.WriteOp
+		for _, kv := range strings.Split(string(r.Value), ",") {
+			fields := strings.SplitN(kv, "=", 2)
+			ops = append(ops, client.WriteOp{Key: []byte(fields[0]), Payload: []byte(fields[1])})
+		}
+		return ops, nil
+	}))
+	v, err := read(target, "k1")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("v1"), v)
+	_, err = read(target, "k2")
+	require.NoError(t, err)
+
+	// the queued writes are replayed, and the writes and reads are verified
+	setPhase(raftstore.MigrationDualWrite)
+	write(t, cli2, "k3", "v3")
+	_, err = read(cli2, "k3")
+	require.NoError(t, err)
+	waitReplicated()
+	for k, expected := range map[string]string{"k2": "v2", "k3": "v3"} {
+		v, err := read(target, k)
+		require.NoError(t, err)
+		assert.Equal(t, []byte(expected), v)
+	}
+
+	// switched on all the proxies
+	require.NoError(t, cli1.SwitchGroupMigration(ctx, 0))
+	status, _ := proxies[0].GetGroupMigration(0)
+	assert.Equal(t, raftstore.MigrationSwitched, status.Phase)
+	assert.Equal(t, uint64(0), status.DivergedWrites)
+	write(t, cli2, "k4", "v4")
+	status, _ = proxies[1].GetGroupMigration(0)
+	assert.Equal(t, raftstore.MigrationSwitched, status.Phase)
+	assert.Equal(t, uint64(2), status.DualWrites)
+	assert.Equal(t, uint64(1), status.VerifiedReads)
+	assert.Equal(t, uint64(0), status.MismatchedReads)
+	v, err = read(target, "k4")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("v4"), v)
+}
@@ -0,0 +1,56 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mock
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/matrixorigin/matrixcube/pb/metapb"
+	"github.com/matrixorigin/matrixcube/pb/rpcpb"
+)
+
+var (
+	// OK the response value of the write requests executed by the KV handler
+	OK = []byte("OK")
+)
+
+type kvKey struct {
+	group uint64
+	key   string
+}
+
+// NewKVHandler returns a Handler of an in-memory KV shared by all the shards. The
+// Write request sets the `Cmd` of the request as the value of the `Key` and
+// responds OK, the Read request responds the value of the `Key`, and the other
+// requests are responded with an error.
+func NewKVHandler() Handler {
+	var mu sync.Mutex
+	kv := make(map[kvKey][]byte)
+	return func(shard metapb.Shard, req rpcpb.Request) ([]byte, error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		k := kvKey{group: shard.Group, key: string(req.Key)}
+		switch req.Type {
+		case rpcpb.Write:
+			kv[k] = append([]byte(nil), req.Cmd...)
+			return OK, nil
+		case rpcpb.Read:
+			return kv[k], nil
+		default:
+			return nil, fmt.Errorf("request type %s not supported by the KV handler", req.Type)
+		}
+	}
+}
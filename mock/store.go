@@ -0,0 +1,214 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mock
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/matrixorigin/matrixcube/components/prophet"
+	"github.com/matrixorigin/matrixcube/config"
+	"github.com/matrixorigin/matrixcube/pb/metapb"
+	"github.com/matrixorigin/matrixcube/pb/rpcpb"
+	"github.com/matrixorigin/matrixcube/raftstore"
+	"github.com/matrixorigin/matrixcube/storage"
+	"github.com/matrixorigin/matrixcube/transport"
+)
+
+var (
+	// ErrNotSupported the operation is not supported by the mock store
+	ErrNotSupported = errors.New("not supported by the mock store")
+)
+
+// store is a fake `raftstore.Store` of the Cluster, the requests received by the
+// store are executed on the replicas of the store, and the requests to the shards
+// not led by the store are responded with the NotLeader error.
+type store struct {
+	c    *Cluster
+	meta metapb.Store
+	cfg  *config.Config
+
+	sync.RWMutex
+	sp            raftstore.ShardsProxy
+	stoppedGroups map[uint64]struct{}
+}
+
+var _ raftstore.Store = (*store)(nil)
+
+func newStore(c *Cluster, meta metapb.Store) *store {
+	return &store{
+		c:             c,
+		meta:          meta,
+		cfg:           &config.Config{Logger: c.logger},
+		stoppedGroups: make(map[uint64]struct{}),
+	}
+}
+
+func (s *store) Start() {}
+
+func (s *store) Stop() {}
+
+func (s *store) GetConfig() *config.Config {
+	return s.cfg
+}
+
+func (s *store) Meta() metapb.Store {
+	return s.meta
+}
+
+func (s *store) GetRouter() raftstore.Router {
+	return s.c.router
+}
+
+// GetShardsProxy returns the shards proxy dispatching the requests to the
+// cluster, it's created on the first call and stopped by `Cluster.Close`.
+func (s *store) GetShardsProxy() raftstore.ShardsProxy {
+	s.Lock()
+	defer s.Unlock()
+	if s.sp == nil {
+		s.sp = s.c.newShardsProxy()
+	}
+	return s.sp
+}
+
+func (s *store) OnRequest(req rpcpb.Request) error {
+	return s.OnRequestWithCB(req, func(rpcpb.ResponseBatch) {})
+}
+
+func (s *store) OnRequestWithCB(req rpcpb.Request, cb func(resp rpcpb.ResponseBatch)) error {
+	shardID := req.ToShard
+	if shardID == 0 {
+		shardID = s.c.router.SelectShardIDByKey(req.Group, req.Key)
+	}
+	cb(s.c.execute(req, shardID, s.meta.ID))
+	return nil
+}
+
+// DataStorageByGroup returns nil, the mock store has no data storage.
+func (s *store) DataStorageByGroup(uint64) storage.DataStorage {
+	return nil
+}
+
+// DataStorageByShard returns nil, the mock store has no data storage.
+func (s *store) DataStorageByShard(raftstore.Shard) storage.DataStorage {
+	return nil
+}
+
+func (s *store) MaybeLeader(shardID uint64) bool {
+	return s.c.isLeader(shardID, s.meta.ID)
+}
+
+func (s *store) MustAllocID() uint64 {
+	return s.c.allocID()
+}
+
+// Prophet returns nil, the mock store has no prophet.
+func (s *store) Prophet() prophet.Prophet {
+	return nil
+}
+
+func (s *store) CreateShardPool(...metapb.ShardPoolJobMeta) (raftstore.ShardsPool, error) {
+	return nil, ErrNotSupported
+}
+
+func (s *store) GetShardPool() raftstore.ShardsPool {
+	return nil
+}
+
+func (s *store) GetChaosController() transport.ChaosController {
+	return nil
+}
+
+// StopGroup stops the group on the store, the requests of the group received by
+// the store are responded with the GroupStopped error.
+func (s *store) StopGroup(group uint64) {
+	s.Lock()
+	defer s.Unlock()
+	s.stoppedGroups[group] = struct{}{}
+}
+
+func (s *store) StartGroup(group uint64) {
+	s.Lock()
+	defer s.Unlock()
+	delete(s.stoppedGroups, group)
+}
+
+func (s *store) GetOrphanDataReport() raftstore.OrphanDataReport {
+	return raftstore.OrphanDataReport{}
+}
+
+func (s *store) GetStartupProgress() raftstore.StartupProgress {
+	return raftstore.StartupProgress{Ready: true}
+}
+
+func (s *store) GetRecoveryReport() (raftstore.RecoveryReport, bool) {
+	return raftstore.RecoveryReport{}, true
+}
+
+func (s *store) GetSnapshotProgresses(shard uint64) []metapb.SnapshotProgress {
+	return nil
+}
+
+func (s *store) CompactRange(group uint64, start, end []byte) error {
+	return nil
+}
+
+func (s *store) GetCompactRangeProgress(group uint64) (raftstore.CompactRangeProgress, bool) {
+	return raftstore.CompactRangeProgress{}, false
+}
+
+func (s *store) GetShardByKey(group uint64, key []byte) (raftstore.Shard, bool) {
+	shard := s.c.router.GetShard(s.c.router.SelectShardIDByKey(group, key))
+	for _, r := range shard.Replicas {
+		if r.StoreID == s.meta.ID {
+			return shard, true
+		}
+	}
+	return raftstore.Shard{}, false
+}
+
+// GetKeyCoverageGaps returns nil, the replicas of the mock store are always
+// the same as the router.
+func (s *store) GetKeyCoverageGaps(group uint64) []raftstore.KeyCoverageGap {
+	return nil
+}
+
+// DiagnoseStoreNotMatch returns the request is matched, the mock store never
+// rejects the requests with the store not match error.
+func (s *store) DiagnoseStoreNotMatch(req rpcpb.Request) raftstore.StoreNotMatchDiagnostic {
+	shardID := req.ToShard
+	if shardID == 0 {
+		shardID = s.c.router.SelectShardIDByKey(req.Group, req.Key)
+	}
+	shard := s.c.router.GetShard(shardID)
+	return raftstore.StoreNotMatchDiagnostic{
+		StoreID:     s.meta.ID,
+		Matched:     true,
+		LocalShard:  shard,
+		RouterShard: shard,
+	}
+}
+
+// DrainLeadership returns nil, the mock store has no raft replica to drain.
+func (s *store) DrainLeadership(ctx context.Context) error {
+	return nil
+}
+
+func (s *store) isGroupStopped(group uint64) bool {
+	s.RLock()
+	defer s.RUnlock()
+	_, ok := s.stoppedGroups[group]
+	return ok
+}
@@ -22,5 +22,12 @@ func HasError(err Error) bool {
 func Retryable(err Error) bool {
 	return HasError(err) &&
 		err.RaftEntryTooLarge == nil && // can not retry
-		err.ShardUnavailable == nil
+		err.ShardUnavailable == nil &&
+		err.GroupStopped == nil &&
+		err.ReadSnapshotNotFound == nil &&
+		err.AccessDenied == nil &&
+		err.AdminTimeout == nil &&
+		err.RequestTimeout == nil &&
+		err.GroupMigrated == nil &&
+		err.ResponseTooLarge == nil
 }
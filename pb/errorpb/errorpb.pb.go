@@ -338,6 +338,9 @@ func (m *StaleEpoch) GetNewShards() []metapb.Shard {
 
 // ServerIsBusy the server is busy
 type ServerIsBusy struct {
+	ShardID uint64 `protobuf:"varint,1,opt,name=shardID,proto3" json:"shardID,omitempty"`
+	// reason why the shard is busy
+	Reason               string   `protobuf:"bytes,2,opt,name=reason,proto3" json:"reason,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -376,6 +379,20 @@ func (m *ServerIsBusy) XXX_DiscardUnknown() {
 
 var xxx_messageInfo_ServerIsBusy proto.InternalMessageInfo
 
+func (m *ServerIsBusy) GetShardID() uint64 {
+	if m != nil {
+		return m.ShardID
+	}
+	return 0
+}
+
+func (m *ServerIsBusy) GetReason() string {
+	if m != nil {
+		return m.Reason
+	}
+	return ""
+}
+
 // StaleCommand the command is stale, need to retry
 type StaleCommand struct {
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
@@ -472,35 +489,26 @@ func (m *RaftEntryTooLarge) GetEntrySize() uint64 {
 	return 0
 }
 
-// Error is a raft error
-type Error struct {
-	Message              string             `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
-	NotLeader            *NotLeader         `protobuf:"bytes,2,opt,name=notLeader,proto3" json:"notLeader,omitempty"`
-	ShardNotFound        *ShardNotFound     `protobuf:"bytes,3,opt,name=shardNotFound,proto3" json:"shardNotFound,omitempty"`
-	KeyNotInShard        *KeyNotInShard     `protobuf:"bytes,4,opt,name=KeyNotInShard,proto3" json:"KeyNotInShard,omitempty"`
-	StaleEpoch           *StaleEpoch        `protobuf:"bytes,5,opt,name=staleEpoch,proto3" json:"staleEpoch,omitempty"`
-	ServerIsBusy         *ServerIsBusy      `protobuf:"bytes,6,opt,name=serverIsBusy,proto3" json:"serverIsBusy,omitempty"`
-	StaleCommand         *StaleCommand      `protobuf:"bytes,7,opt,name=staleCommand,proto3" json:"staleCommand,omitempty"`
-	StoreMismatch        *StoreMismatch     `protobuf:"bytes,8,opt,name=storeMismatch,proto3" json:"storeMismatch,omitempty"`
-	RaftEntryTooLarge    *RaftEntryTooLarge `protobuf:"bytes,9,opt,name=raftEntryTooLarge,proto3" json:"raftEntryTooLarge,omitempty"`
-	ShardUnavailable     *ShardUnavailable  `protobuf:"bytes,10,opt,name=shardUnavailable,proto3" json:"shardUnavailable,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}           `json:"-"`
-	XXX_unrecognized     []byte             `json:"-"`
-	XXX_sizecache        int32              `json:"-"`
+// GroupStopped the shard group is stopped on the store
+type GroupStopped struct {
+	Group                uint64   `protobuf:"varint,1,opt,name=group,proto3" json:"group,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
-func (m *Error) Reset()         { *m = Error{} }
-func (m *Error) String() string { return proto.CompactTextString(m) }
-func (*Error) ProtoMessage()    {}
-func (*Error) Descriptor() ([]byte, []int) {
+func (m *GroupStopped) Reset()         { *m = GroupStopped{} }
+func (m *GroupStopped) String() string { return proto.CompactTextString(m) }
+func (*GroupStopped) ProtoMessage()    {}
+func (*GroupStopped) Descriptor() ([]byte, []int) {
 	return fileDescriptor_390aa86757fd1154, []int{9}
 }
-func (m *Error) XXX_Unmarshal(b []byte) error {
+func (m *GroupStopped) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
 }
-func (m *Error) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+func (m *GroupStopped) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
 	if deterministic {
-		return xxx_messageInfo_Error.Marshal(b, m, deterministic)
+		return xxx_messageInfo_GroupStopped.Marshal(b, m, deterministic)
 	} else {
 		b = b[:cap(b)]
 		n, err := m.MarshalTo(b)
@@ -510,224 +518,671 @@ func (m *Error) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
 		return b[:n], nil
 	}
 }
-func (m *Error) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_Error.Merge(m, src)
+func (m *GroupStopped) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GroupStopped.Merge(m, src)
 }
-func (m *Error) XXX_Size() int {
+func (m *GroupStopped) XXX_Size() int {
 	return m.Size()
 }
-func (m *Error) XXX_DiscardUnknown() {
-	xxx_messageInfo_Error.DiscardUnknown(m)
+func (m *GroupStopped) XXX_DiscardUnknown() {
+	xxx_messageInfo_GroupStopped.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_Error proto.InternalMessageInfo
+var xxx_messageInfo_GroupStopped proto.InternalMessageInfo
 
-func (m *Error) GetMessage() string {
+func (m *GroupStopped) GetGroup() uint64 {
 	if m != nil {
-		return m.Message
+		return m.Group
 	}
-	return ""
+	return 0
 }
 
-func (m *Error) GetNotLeader() *NotLeader {
-	if m != nil {
-		return m.NotLeader
+// ShardRecovering the shard replica is recovering from a snapshot, the request
+// can be retried after the estimated time
+type ShardRecovering struct {
+	ShardID              uint64   `protobuf:"varint,1,opt,name=shardID,proto3" json:"shardID,omitempty"`
+	EstimatedMillis      uint64   `protobuf:"varint,2,opt,name=estimatedMillis,proto3" json:"estimatedMillis,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ShardRecovering) Reset()         { *m = ShardRecovering{} }
+func (m *ShardRecovering) String() string { return proto.CompactTextString(m) }
+func (*ShardRecovering) ProtoMessage()    {}
+func (*ShardRecovering) Descriptor() ([]byte, []int) {
+	return fileDescriptor_390aa86757fd1154, []int{10}
+}
+func (m *ShardRecovering) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *ShardRecovering) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_ShardRecovering.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalTo(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
 	}
-	return nil
+}
+func (m *ShardRecovering) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ShardRecovering.Merge(m, src)
+}
+func (m *ShardRecovering) XXX_Size() int {
+	return m.Size()
+}
+func (m *ShardRecovering) XXX_DiscardUnknown() {
+	xxx_messageInfo_ShardRecovering.DiscardUnknown(m)
 }
 
-func (m *Error) GetShardNotFound() *ShardNotFound {
+var xxx_messageInfo_ShardRecovering proto.InternalMessageInfo
+
+func (m *ShardRecovering) GetShardID() uint64 {
 	if m != nil {
-		return m.ShardNotFound
+		return m.ShardID
 	}
-	return nil
+	return 0
 }
 
-func (m *Error) GetKeyNotInShard() *KeyNotInShard {
+func (m *ShardRecovering) GetEstimatedMillis() uint64 {
 	if m != nil {
-		return m.KeyNotInShard
+		return m.EstimatedMillis
 	}
-	return nil
+	return 0
 }
 
-func (m *Error) GetStaleEpoch() *StaleEpoch {
-	if m != nil {
-		return m.StaleEpoch
+// ReadSnapshotNotFound the read snapshot is not found on the shard replica, it
+// is released or lost after the replica restarted
+type ReadSnapshotNotFound struct {
+	ShardID              uint64   `protobuf:"varint,1,opt,name=shardID,proto3" json:"shardID,omitempty"`
+	Name                 string   `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ReadSnapshotNotFound) Reset()         { *m = ReadSnapshotNotFound{} }
+func (m *ReadSnapshotNotFound) String() string { return proto.CompactTextString(m) }
+func (*ReadSnapshotNotFound) ProtoMessage()    {}
+func (*ReadSnapshotNotFound) Descriptor() ([]byte, []int) {
+	return fileDescriptor_390aa86757fd1154, []int{11}
+}
+func (m *ReadSnapshotNotFound) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *ReadSnapshotNotFound) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_ReadSnapshotNotFound.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalTo(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
 	}
-	return nil
+}
+func (m *ReadSnapshotNotFound) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ReadSnapshotNotFound.Merge(m, src)
+}
+func (m *ReadSnapshotNotFound) XXX_Size() int {
+	return m.Size()
+}
+func (m *ReadSnapshotNotFound) XXX_DiscardUnknown() {
+	xxx_messageInfo_ReadSnapshotNotFound.DiscardUnknown(m)
 }
 
-func (m *Error) GetServerIsBusy() *ServerIsBusy {
+var xxx_messageInfo_ReadSnapshotNotFound proto.InternalMessageInfo
+
+func (m *ReadSnapshotNotFound) GetShardID() uint64 {
 	if m != nil {
-		return m.ServerIsBusy
+		return m.ShardID
 	}
-	return nil
+	return 0
 }
 
-func (m *Error) GetStaleCommand() *StaleCommand {
+func (m *ReadSnapshotNotFound) GetName() string {
 	if m != nil {
-		return m.StaleCommand
+		return m.Name
 	}
-	return nil
+	return ""
 }
 
-func (m *Error) GetStoreMismatch() *StoreMismatch {
-	if m != nil {
-		return m.StoreMismatch
+// AccessDenied the request is rejected by the access control list of the shard
+type AccessDenied struct {
+	ShardID              uint64   `protobuf:"varint,1,opt,name=shardID,proto3" json:"shardID,omitempty"`
+	Tenant               string   `protobuf:"bytes,2,opt,name=tenant,proto3" json:"tenant,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *AccessDenied) Reset()         { *m = AccessDenied{} }
+func (m *AccessDenied) String() string { return proto.CompactTextString(m) }
+func (*AccessDenied) ProtoMessage()    {}
+func (*AccessDenied) Descriptor() ([]byte, []int) {
+	return fileDescriptor_390aa86757fd1154, []int{12}
+}
+func (m *AccessDenied) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *AccessDenied) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_AccessDenied.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalTo(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
 	}
-	return nil
+}
+func (m *AccessDenied) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AccessDenied.Merge(m, src)
+}
+func (m *AccessDenied) XXX_Size() int {
+	return m.Size()
+}
+func (m *AccessDenied) XXX_DiscardUnknown() {
+	xxx_messageInfo_AccessDenied.DiscardUnknown(m)
 }
 
-func (m *Error) GetRaftEntryTooLarge() *RaftEntryTooLarge {
+var xxx_messageInfo_AccessDenied proto.InternalMessageInfo
+
+func (m *AccessDenied) GetShardID() uint64 {
 	if m != nil {
-		return m.RaftEntryTooLarge
+		return m.ShardID
 	}
-	return nil
+	return 0
 }
 
-func (m *Error) GetShardUnavailable() *ShardUnavailable {
+func (m *AccessDenied) GetTenant() string {
 	if m != nil {
-		return m.ShardUnavailable
+		return m.Tenant
 	}
-	return nil
+	return ""
 }
 
-func init() {
-	proto.RegisterType((*NotLeader)(nil), "errorpb.NotLeader")
-	proto.RegisterType((*StoreMismatch)(nil), "errorpb.StoreMismatch")
-	proto.RegisterType((*ShardUnavailable)(nil), "errorpb.ShardUnavailable")
-	proto.RegisterType((*ShardNotFound)(nil), "errorpb.ShardNotFound")
-	proto.RegisterType((*KeyNotInShard)(nil), "errorpb.KeyNotInShard")
-	proto.RegisterType((*StaleEpoch)(nil), "errorpb.StaleEpoch")
-	proto.RegisterType((*ServerIsBusy)(nil), "errorpb.ServerIsBusy")
-	proto.RegisterType((*StaleCommand)(nil), "errorpb.StaleCommand")
-	proto.RegisterType((*RaftEntryTooLarge)(nil), "errorpb.RaftEntryTooLarge")
-	proto.RegisterType((*Error)(nil), "errorpb.Error")
+type AdminTimeout struct {
+	ShardID              uint64   `protobuf:"varint,1,opt,name=shardID,proto3" json:"shardID,omitempty"`
+	AdminType            uint64   `protobuf:"varint,2,opt,name=adminType,proto3" json:"adminType,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
-func init() { proto.RegisterFile("errorpb.proto", fileDescriptor_390aa86757fd1154) }
-
-var fileDescriptor_390aa86757fd1154 = []byte{
-	// 554 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x7c, 0x94, 0x5d, 0x6f, 0xda, 0x3e,
-	0x14, 0xc6, 0x9b, 0xf2, 0xf6, 0xcf, 0x81, 0xfc, 0x4b, 0xbd, 0x17, 0x79, 0x68, 0x62, 0x28, 0x57,
-	0x4c, 0x5a, 0x61, 0xa3, 0x57, 0x95, 0x26, 0x4d, 0x62, 0x63, 0x1a, 0x6a, 0xc7, 0x85, 0xe9, 0x3e,
-	0x80, 0x93, 0xb8, 0x21, 0x1a, 0x89, 0x91, 0x6d, 0xba, 0xb1, 0x4f, 0xd8, 0xdd, 0xf5, 0x13, 0x4c,
-	0x1b, 0x9f, 0x64, 0x8a, 0x09, 0xc1, 0x01, 0xad, 0x57, 0xe4, 0xf8, 0xfc, 0x9e, 0xc7, 0xca, 0x73,
-	0x0e, 0x01, 0x87, 0x09, 0xc1, 0xc5, 0xc2, 0xeb, 0x2d, 0x04, 0x57, 0x1c, 0xd5, 0xb2, 0xb2, 0x75,
-	0x11, 0x46, 0x6a, 0xb6, 0xf4, 0x7a, 0x3e, 0x8f, 0xfb, 0x31, 0x55, 0x22, 0xfa, 0xce, 0x45, 0x14,
-	0x46, 0x49, 0x56, 0xf8, 0x4b, 0x8f, 0xf5, 0x17, 0x5e, 0x3f, 0x66, 0x8a, 0xe6, 0x3f, 0x1b, 0x8f,
-	0xd6, 0x99, 0x21, 0x0d, 0x79, 0xc8, 0xfb, 0xfa, 0xd8, 0x5b, 0xde, 0xe8, 0x4a, 0x17, 0xfa, 0x69,
-	0x83, 0xbb, 0xd7, 0x60, 0x4f, 0xb8, 0xba, 0x62, 0x34, 0x60, 0x02, 0x61, 0xa8, 0xc9, 0x19, 0x15,
-	0xc1, 0xf8, 0x03, 0xb6, 0x3a, 0x56, 0xb7, 0x4c, 0xb6, 0x25, 0x3a, 0x83, 0xea, 0x5c, 0x33, 0xf8,
-	0xb8, 0x63, 0x75, 0xeb, 0x83, 0x93, 0x5e, 0x76, 0x29, 0x61, 0x8b, 0x79, 0xe4, 0xd3, 0x61, 0xf9,
-	0xee, 0xd7, 0x8b, 0x23, 0x92, 0x41, 0xee, 0x09, 0x38, 0x53, 0xc5, 0x05, 0xfb, 0x1c, 0xc9, 0x98,
-	0x2a, 0x7f, 0xe6, 0xbe, 0x82, 0xe6, 0x34, 0xb5, 0xfa, 0x92, 0xd0, 0x5b, 0x1a, 0xcd, 0xa9, 0x37,
-	0x67, 0xff, 0xbe, 0xcd, 0x7d, 0x09, 0x8e, 0xa6, 0x27, 0x5c, 0x7d, 0xe4, 0xcb, 0x24, 0x78, 0x00,
-	0xf5, 0xc1, 0xb9, 0x64, 0xab, 0x09, 0x57, 0xe3, 0x44, 0x4b, 0x50, 0x13, 0x4a, 0x5f, 0xd9, 0x4a,
-	0x63, 0x0d, 0x92, 0x3e, 0x9a, 0xe2, 0xe3, 0xe2, 0x5b, 0x3d, 0x86, 0x8a, 0x54, 0x54, 0x28, 0x5c,
-	0xd2, 0xf4, 0xa6, 0x48, 0x1d, 0x58, 0x12, 0xe0, 0xf2, 0xc6, 0x81, 0x25, 0x81, 0xfb, 0x0e, 0x60,
-	0xaa, 0xe8, 0x9c, 0x8d, 0x16, 0xdc, 0x9f, 0xa1, 0x37, 0x60, 0x27, 0xec, 0x9b, 0xbe, 0x4d, 0x62,
-	0xab, 0x53, 0xea, 0xd6, 0x07, 0xce, 0x36, 0x0e, 0x7d, 0x9a, 0x85, 0xb1, 0xa3, 0xdc, 0xff, 0xa1,
-	0x31, 0x65, 0xe2, 0x96, 0x89, 0xb1, 0x1c, 0x2e, 0xe5, 0x4a, 0xd7, 0xa9, 0xe1, 0x7b, 0x1e, 0xc7,
-	0x34, 0x09, 0xdc, 0x4b, 0x38, 0x25, 0xf4, 0x46, 0x8d, 0x12, 0x25, 0x56, 0xd7, 0x9c, 0x5f, 0x51,
-	0x11, 0x3e, 0x90, 0x0f, 0x7a, 0x0e, 0x36, 0x4b, 0xd1, 0x69, 0xf4, 0x83, 0x65, 0xef, 0xb4, 0x3b,
-	0x70, 0x7f, 0x96, 0xa1, 0x32, 0x4a, 0x17, 0x29, 0x75, 0x88, 0x99, 0x94, 0x34, 0x64, 0xda, 0xc1,
-	0x26, 0xdb, 0x12, 0xbd, 0x06, 0x3b, 0xd9, 0x8e, 0x3d, 0x1b, 0x29, 0xea, 0x6d, 0x97, 0x31, 0x5f,
-	0x08, 0xb2, 0x83, 0xd0, 0x5b, 0x70, 0xa4, 0x39, 0x13, 0x9d, 0x59, 0x7d, 0xf0, 0x34, 0x57, 0x15,
-	0x26, 0x46, 0x8a, 0x70, 0xaa, 0x2e, 0x8c, 0x49, 0xa7, 0x6b, 0xaa, 0x0b, 0x5d, 0xb2, 0x37, 0xd3,
-	0x73, 0x00, 0x99, 0xe7, 0x8f, 0x2b, 0x5a, 0xfa, 0x68, 0x77, 0x71, 0xde, 0x22, 0x06, 0x86, 0x2e,
-	0xa0, 0x21, 0x8d, 0xcc, 0x71, 0x55, 0xcb, 0x9e, 0xec, 0x64, 0x46, 0x93, 0x14, 0x50, 0x2d, 0x35,
-	0xc6, 0x83, 0x6b, 0xfb, 0x52, 0xa3, 0x49, 0x0a, 0xa8, 0x8e, 0xc9, 0xdc, 0x7c, 0xfc, 0xdf, 0x7e,
-	0x4c, 0x66, 0x97, 0x14, 0x61, 0xf4, 0x09, 0x4e, 0xc5, 0xfe, 0x1e, 0x60, 0x5b, 0x3b, 0xb4, 0x72,
-	0x87, 0x83, 0x4d, 0x21, 0x87, 0x22, 0x34, 0x82, 0xa6, 0xdc, 0xfb, 0xc3, 0x61, 0xd0, 0x46, 0xcf,
-	0x8a, 0x13, 0x33, 0x00, 0x72, 0x20, 0x19, 0x36, 0xef, 0xff, 0xb4, 0x8f, 0xee, 0xd6, 0x6d, 0xeb,
-	0x7e, 0xdd, 0xb6, 0x7e, 0xaf, 0xdb, 0x96, 0x57, 0xd5, 0xdf, 0x8d, 0xf3, 0xbf, 0x01, 0x00, 0x00,
-	0xff, 0xff, 0xfa, 0x38, 0xbc, 0x30, 0xbb, 0x04, 0x00, 0x00,
+func (m *AdminTimeout) Reset()         { *m = AdminTimeout{} }
+func (m *AdminTimeout) String() string { return proto.CompactTextString(m) }
+func (*AdminTimeout) ProtoMessage()    {}
+func (*AdminTimeout) Descriptor() ([]byte, []int) {
+	return fileDescriptor_390aa86757fd1154, []int{13}
 }
-
-func (m *NotLeader) Marshal() (dAtA []byte, err error) {
-	size := m.Size()
-	dAtA = make([]byte, size)
-	n, err := m.MarshalTo(dAtA)
-	if err != nil {
-		return nil, err
+func (m *AdminTimeout) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *AdminTimeout) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_AdminTimeout.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalTo(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
 	}
-	return dAtA[:n], nil
+}
+func (m *AdminTimeout) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AdminTimeout.Merge(m, src)
+}
+func (m *AdminTimeout) XXX_Size() int {
+	return m.Size()
+}
+func (m *AdminTimeout) XXX_DiscardUnknown() {
+	xxx_messageInfo_AdminTimeout.DiscardUnknown(m)
 }
 
-func (m *NotLeader) MarshalTo(dAtA []byte) (int, error) {
-	var i int
-	_ = i
-	var l int
-	_ = l
-	if m.ShardID != 0 {
-		dAtA[i] = 0x8
-		i++
-		i = encodeVarintErrorpb(dAtA, i, uint64(m.ShardID))
-	}
-	dAtA[i] = 0x12
-	i++
-	i = encodeVarintErrorpb(dAtA, i, uint64(m.Leader.Size()))
-	n1, err := m.Leader.MarshalTo(dAtA[i:])
-	if err != nil {
-		return 0, err
-	}
-	i += n1
-	if m.XXX_unrecognized != nil {
-		i += copy(dAtA[i:], m.XXX_unrecognized)
+var xxx_messageInfo_AdminTimeout proto.InternalMessageInfo
+
+func (m *AdminTimeout) GetShardID() uint64 {
+	if m != nil {
+		return m.ShardID
 	}
-	return i, nil
+	return 0
 }
 
-func (m *StoreMismatch) Marshal() (dAtA []byte, err error) {
-	size := m.Size()
-	dAtA = make([]byte, size)
-	n, err := m.MarshalTo(dAtA)
-	if err != nil {
-		return nil, err
+func (m *AdminTimeout) GetAdminType() uint64 {
+	if m != nil {
+		return m.AdminType
 	}
-	return dAtA[:n], nil
+	return 0
 }
 
-func (m *StoreMismatch) MarshalTo(dAtA []byte) (int, error) {
-	var i int
-	_ = i
-	var l int
-	_ = l
-	if m.XXX_unrecognized != nil {
-		i += copy(dAtA[i:], m.XXX_unrecognized)
+// RequestTimeout the deadline of the request passed before it was proposed to
+// the raft log of the shard
+type RequestTimeout struct {
+	ShardID              uint64   `protobuf:"varint,1,opt,name=shardID,proto3" json:"shardID,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RequestTimeout) Reset()         { *m = RequestTimeout{} }
+func (m *RequestTimeout) String() string { return proto.CompactTextString(m) }
+func (*RequestTimeout) ProtoMessage()    {}
+func (*RequestTimeout) Descriptor() ([]byte, []int) {
+	return fileDescriptor_390aa86757fd1154, []int{14}
+}
+func (m *RequestTimeout) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *RequestTimeout) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_RequestTimeout.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalTo(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
 	}
-	return i, nil
+}
+func (m *RequestTimeout) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RequestTimeout.Merge(m, src)
+}
+func (m *RequestTimeout) XXX_Size() int {
+	return m.Size()
+}
+func (m *RequestTimeout) XXX_DiscardUnknown() {
+	xxx_messageInfo_RequestTimeout.DiscardUnknown(m)
 }
 
-func (m *ShardUnavailable) Marshal() (dAtA []byte, err error) {
-	size := m.Size()
-	dAtA = make([]byte, size)
-	n, err := m.MarshalTo(dAtA)
-	if err != nil {
-		return nil, err
+var xxx_messageInfo_RequestTimeout proto.InternalMessageInfo
+
+func (m *RequestTimeout) GetShardID() uint64 {
+	if m != nil {
+		return m.ShardID
 	}
-	return dAtA[:n], nil
+	return 0
 }
 
-func (m *ShardUnavailable) MarshalTo(dAtA []byte) (int, error) {
-	var i int
-	_ = i
-	var l int
-	_ = l
-	if m.ShardID != 0 {
-		dAtA[i] = 0x8
-		i++
-		i = encodeVarintErrorpb(dAtA, i, uint64(m.ShardID))
+// GroupMigrated the shard group is migrated to another cluster, the shards of
+// the group are fenced by the migrated barrier and reject all the requests
+type GroupMigrated struct {
+	Group                uint64   `protobuf:"varint,1,opt,name=group,proto3" json:"group,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GroupMigrated) Reset()         { *m = GroupMigrated{} }
+func (m *GroupMigrated) String() string { return proto.CompactTextString(m) }
+func (*GroupMigrated) ProtoMessage()    {}
+func (*GroupMigrated) Descriptor() ([]byte, []int) {
+	return fileDescriptor_390aa86757fd1154, []int{15}
+}
+func (m *GroupMigrated) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *GroupMigrated) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_GroupMigrated.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalTo(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
 	}
-	if m.XXX_unrecognized != nil {
-		i += copy(dAtA[i:], m.XXX_unrecognized)
+}
+func (m *GroupMigrated) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GroupMigrated.Merge(m, src)
+}
+func (m *GroupMigrated) XXX_Size() int {
+	return m.Size()
+}
+func (m *GroupMigrated) XXX_DiscardUnknown() {
+	xxx_messageInfo_GroupMigrated.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GroupMigrated proto.InternalMessageInfo
+
+func (m *GroupMigrated) GetGroup() uint64 {
+	if m != nil {
+		return m.Group
 	}
-	return i, nil
+	return 0
 }
 
-func (m *ShardNotFound) Marshal() (dAtA []byte, err error) {
+// ResponseTooLarge the result of the read request exceeds the max response
+// bytes of the request, and is not truncated by the storage executor
+type ResponseTooLarge struct {
+	ShardID              uint64   `protobuf:"varint,1,opt,name=shardID,proto3" json:"shardID,omitempty"`
+	MaxResponseBytes     uint64   `protobuf:"varint,2,opt,name=maxResponseBytes,proto3" json:"maxResponseBytes,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ResponseTooLarge) Reset()         { *m = ResponseTooLarge{} }
+func (m *ResponseTooLarge) String() string { return proto.CompactTextString(m) }
+func (*ResponseTooLarge) ProtoMessage()    {}
+func (*ResponseTooLarge) Descriptor() ([]byte, []int) {
+	return fileDescriptor_390aa86757fd1154, []int{16}
+}
+func (m *ResponseTooLarge) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *ResponseTooLarge) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_ResponseTooLarge.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalTo(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *ResponseTooLarge) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ResponseTooLarge.Merge(m, src)
+}
+func (m *ResponseTooLarge) XXX_Size() int {
+	return m.Size()
+}
+func (m *ResponseTooLarge) XXX_DiscardUnknown() {
+	xxx_messageInfo_ResponseTooLarge.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ResponseTooLarge proto.InternalMessageInfo
+
+func (m *ResponseTooLarge) GetShardID() uint64 {
+	if m != nil {
+		return m.ShardID
+	}
+	return 0
+}
+
+func (m *ResponseTooLarge) GetMaxResponseBytes() uint64 {
+	if m != nil {
+		return m.MaxResponseBytes
+	}
+	return 0
+}
+
+// Error is a raft error
+type Error struct {
+	Message              string                `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
+	NotLeader            *NotLeader            `protobuf:"bytes,2,opt,name=notLeader,proto3" json:"notLeader,omitempty"`
+	ShardNotFound        *ShardNotFound        `protobuf:"bytes,3,opt,name=shardNotFound,proto3" json:"shardNotFound,omitempty"`
+	KeyNotInShard        *KeyNotInShard        `protobuf:"bytes,4,opt,name=KeyNotInShard,proto3" json:"KeyNotInShard,omitempty"`
+	StaleEpoch           *StaleEpoch           `protobuf:"bytes,5,opt,name=staleEpoch,proto3" json:"staleEpoch,omitempty"`
+	ServerIsBusy         *ServerIsBusy         `protobuf:"bytes,6,opt,name=serverIsBusy,proto3" json:"serverIsBusy,omitempty"`
+	StaleCommand         *StaleCommand         `protobuf:"bytes,7,opt,name=staleCommand,proto3" json:"staleCommand,omitempty"`
+	StoreMismatch        *StoreMismatch        `protobuf:"bytes,8,opt,name=storeMismatch,proto3" json:"storeMismatch,omitempty"`
+	RaftEntryTooLarge    *RaftEntryTooLarge    `protobuf:"bytes,9,opt,name=raftEntryTooLarge,proto3" json:"raftEntryTooLarge,omitempty"`
+	ShardUnavailable     *ShardUnavailable     `protobuf:"bytes,10,opt,name=shardUnavailable,proto3" json:"shardUnavailable,omitempty"`
+	GroupStopped         *GroupStopped         `protobuf:"bytes,11,opt,name=groupStopped,proto3" json:"groupStopped,omitempty"`
+	ShardRecovering      *ShardRecovering      `protobuf:"bytes,12,opt,name=shardRecovering,proto3" json:"shardRecovering,omitempty"`
+	ReadSnapshotNotFound *ReadSnapshotNotFound `protobuf:"bytes,13,opt,name=readSnapshotNotFound,proto3" json:"readSnapshotNotFound,omitempty"`
+	AccessDenied         *AccessDenied         `protobuf:"bytes,14,opt,name=accessDenied,proto3" json:"accessDenied,omitempty"`
+	AdminTimeout         *AdminTimeout         `protobuf:"bytes,15,opt,name=adminTimeout,proto3" json:"adminTimeout,omitempty"`
+	RequestTimeout       *RequestTimeout       `protobuf:"bytes,16,opt,name=requestTimeout,proto3" json:"requestTimeout,omitempty"`
+	GroupMigrated        *GroupMigrated        `protobuf:"bytes,17,opt,name=groupMigrated,proto3" json:"groupMigrated,omitempty"`
+	ResponseTooLarge     *ResponseTooLarge     `protobuf:"bytes,18,opt,name=responseTooLarge,proto3" json:"responseTooLarge,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}              `json:"-"`
+	XXX_unrecognized     []byte                `json:"-"`
+	XXX_sizecache        int32                 `json:"-"`
+}
+
+func (m *Error) Reset()         { *m = Error{} }
+func (m *Error) String() string { return proto.CompactTextString(m) }
+func (*Error) ProtoMessage()    {}
+func (*Error) Descriptor() ([]byte, []int) {
+	return fileDescriptor_390aa86757fd1154, []int{17}
+}
+func (m *Error) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *Error) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_Error.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalTo(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *Error) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Error.Merge(m, src)
+}
+func (m *Error) XXX_Size() int {
+	return m.Size()
+}
+func (m *Error) XXX_DiscardUnknown() {
+	xxx_messageInfo_Error.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Error proto.InternalMessageInfo
+
+func (m *Error) GetMessage() string {
+	if m != nil {
+		return m.Message
+	}
+	return ""
+}
+
+func (m *Error) GetNotLeader() *NotLeader {
+	if m != nil {
+		return m.NotLeader
+	}
+	return nil
+}
+
+func (m *Error) GetShardNotFound() *ShardNotFound {
+	if m != nil {
+		return m.ShardNotFound
+	}
+	return nil
+}
+
+func (m *Error) GetKeyNotInShard() *KeyNotInShard {
+	if m != nil {
+		return m.KeyNotInShard
+	}
+	return nil
+}
+
+func (m *Error) GetStaleEpoch() *StaleEpoch {
+	if m != nil {
+		return m.StaleEpoch
+	}
+	return nil
+}
+
+func (m *Error) GetServerIsBusy() *ServerIsBusy {
+	if m != nil {
+		return m.ServerIsBusy
+	}
+	return nil
+}
+
+func (m *Error) GetStaleCommand() *StaleCommand {
+	if m != nil {
+		return m.StaleCommand
+	}
+	return nil
+}
+
+func (m *Error) GetStoreMismatch() *StoreMismatch {
+	if m != nil {
+		return m.StoreMismatch
+	}
+	return nil
+}
+
+func (m *Error) GetRaftEntryTooLarge() *RaftEntryTooLarge {
+	if m != nil {
+		return m.RaftEntryTooLarge
+	}
+	return nil
+}
+
+func (m *Error) GetShardUnavailable() *ShardUnavailable {
+	if m != nil {
+		return m.ShardUnavailable
+	}
+	return nil
+}
+
+func (m *Error) GetGroupStopped() *GroupStopped {
+	if m != nil {
+		return m.GroupStopped
+	}
+	return nil
+}
+
+func (m *Error) GetShardRecovering() *ShardRecovering {
+	if m != nil {
+		return m.ShardRecovering
+	}
+	return nil
+}
+
+func (m *Error) GetReadSnapshotNotFound() *ReadSnapshotNotFound {
+	if m != nil {
+		return m.ReadSnapshotNotFound
+	}
+	return nil
+}
+
+func (m *Error) GetAccessDenied() *AccessDenied {
+	if m != nil {
+		return m.AccessDenied
+	}
+	return nil
+}
+
+func (m *Error) GetAdminTimeout() *AdminTimeout {
+	if m != nil {
+		return m.AdminTimeout
+	}
+	return nil
+}
+
+func (m *Error) GetRequestTimeout() *RequestTimeout {
+	if m != nil {
+		return m.RequestTimeout
+	}
+	return nil
+}
+
+func (m *Error) GetGroupMigrated() *GroupMigrated {
+	if m != nil {
+		return m.GroupMigrated
+	}
+	return nil
+}
+
+func (m *Error) GetResponseTooLarge() *ResponseTooLarge {
+	if m != nil {
+		return m.ResponseTooLarge
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterType((*NotLeader)(nil), "errorpb.NotLeader")
+	proto.RegisterType((*StoreMismatch)(nil), "errorpb.StoreMismatch")
+	proto.RegisterType((*ShardUnavailable)(nil), "errorpb.ShardUnavailable")
+	proto.RegisterType((*ShardNotFound)(nil), "errorpb.ShardNotFound")
+	proto.RegisterType((*KeyNotInShard)(nil), "errorpb.KeyNotInShard")
+	proto.RegisterType((*StaleEpoch)(nil), "errorpb.StaleEpoch")
+	proto.RegisterType((*ServerIsBusy)(nil), "errorpb.ServerIsBusy")
+	proto.RegisterType((*StaleCommand)(nil), "errorpb.StaleCommand")
+	proto.RegisterType((*RaftEntryTooLarge)(nil), "errorpb.RaftEntryTooLarge")
+	proto.RegisterType((*GroupStopped)(nil), "errorpb.GroupStopped")
+	proto.RegisterType((*ShardRecovering)(nil), "errorpb.ShardRecovering")
+	proto.RegisterType((*ReadSnapshotNotFound)(nil), "errorpb.ReadSnapshotNotFound")
+	proto.RegisterType((*AccessDenied)(nil), "errorpb.AccessDenied")
+	proto.RegisterType((*AdminTimeout)(nil), "errorpb.AdminTimeout")
+	proto.RegisterType((*RequestTimeout)(nil), "errorpb.RequestTimeout")
+	proto.RegisterType((*GroupMigrated)(nil), "errorpb.GroupMigrated")
+	proto.RegisterType((*ResponseTooLarge)(nil), "errorpb.ResponseTooLarge")
+	proto.RegisterType((*Error)(nil), "errorpb.Error")
+}
+
+func init() { proto.RegisterFile("errorpb.proto", fileDescriptor_390aa86757fd1154) }
+
+var fileDescriptor_390aa86757fd1154 = []byte{
+	// 861 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x56, 0x51, 0x6f, 0x1b, 0x45,
+	0x10, 0xae, 0x1b, 0x27, 0xa9, 0x27, 0x76, 0xec, 0x2c, 0xa1, 0x2c, 0x11, 0x84, 0xe8, 0x04, 0x52,
+	0xa8, 0x68, 0x02, 0xe9, 0x53, 0xa5, 0x4a, 0xa5, 0x26, 0x29, 0x44, 0x6d, 0x22, 0xb1, 0x4e, 0x25,
+	0x5e, 0xd7, 0x77, 0xd3, 0xf3, 0x09, 0xdf, 0xee, 0xb1, 0xbb, 0x0e, 0x35, 0xbf, 0xb0, 0x8f, 0x7d,
+	0xe3, 0x0d, 0x41, 0x7e, 0x09, 0xba, 0xf5, 0xf9, 0xbc, 0xbb, 0x4e, 0xdd, 0x3e, 0xf9, 0x66, 0xe6,
+	0xfb, 0x66, 0x6e, 0xbf, 0xdb, 0xf9, 0x64, 0xe8, 0xa0, 0x52, 0x52, 0x15, 0xc3, 0xa3, 0x42, 0x49,
+	0x23, 0xc9, 0x66, 0x15, 0xee, 0x3d, 0x4e, 0x33, 0x33, 0x9a, 0x0c, 0x8f, 0x62, 0x99, 0x1f, 0xe7,
+	0xdc, 0xa8, 0xec, 0x8d, 0x54, 0x59, 0x9a, 0x89, 0x2a, 0x88, 0x27, 0x43, 0x3c, 0x2e, 0x86, 0xc7,
+	0x39, 0x1a, 0x5e, 0xff, 0xcc, 0x7a, 0xec, 0x3d, 0x74, 0xa8, 0xa9, 0x4c, 0xe5, 0xb1, 0x4d, 0x0f,
+	0x27, 0xaf, 0x6d, 0x64, 0x03, 0xfb, 0x34, 0x83, 0x47, 0x57, 0xd0, 0xba, 0x94, 0xe6, 0x25, 0xf2,
+	0x04, 0x15, 0xa1, 0xb0, 0xa9, 0x47, 0x5c, 0x25, 0xe7, 0xa7, 0xb4, 0x71, 0xd0, 0x38, 0x6c, 0xb2,
+	0x79, 0x48, 0x1e, 0xc2, 0xc6, 0xd8, 0x62, 0xe8, 0xdd, 0x83, 0xc6, 0xe1, 0xd6, 0x49, 0xf7, 0xa8,
+	0x1a, 0xca, 0xb0, 0x18, 0x67, 0x31, 0xef, 0x37, 0xdf, 0xfe, 0xf3, 0xd5, 0x1d, 0x56, 0x81, 0xa2,
+	0x2e, 0x74, 0x06, 0x46, 0x2a, 0xbc, 0xc8, 0x74, 0xce, 0x4d, 0x3c, 0x8a, 0xbe, 0x83, 0xde, 0xa0,
+	0x6c, 0xf5, 0x4a, 0xf0, 0x6b, 0x9e, 0x8d, 0xf9, 0x70, 0x8c, 0xef, 0x9f, 0x16, 0x7d, 0x0b, 0x1d,
+	0x8b, 0xbe, 0x94, 0xe6, 0xb9, 0x9c, 0x88, 0x64, 0x05, 0x34, 0x86, 0xce, 0x0b, 0x9c, 0x5e, 0x4a,
+	0x73, 0x2e, 0x2c, 0x85, 0xf4, 0x60, 0xed, 0x77, 0x9c, 0x5a, 0x58, 0x9b, 0x95, 0x8f, 0x2e, 0xf9,
+	0xae, 0x7f, 0xaa, 0x5d, 0x58, 0xd7, 0x86, 0x2b, 0x43, 0xd7, 0x2c, 0x7a, 0x16, 0x94, 0x1d, 0x50,
+	0x24, 0xb4, 0x39, 0xeb, 0x80, 0x22, 0x89, 0x9e, 0x02, 0x0c, 0x0c, 0x1f, 0xe3, 0x59, 0x21, 0xe3,
+	0x11, 0xf9, 0x01, 0x5a, 0x02, 0xff, 0xb4, 0xd3, 0x34, 0x6d, 0x1c, 0xac, 0x1d, 0x6e, 0x9d, 0x74,
+	0xe6, 0x72, 0xd8, 0x6c, 0x25, 0xc6, 0x02, 0x15, 0xfd, 0x08, 0xed, 0x01, 0xaa, 0x6b, 0x54, 0xe7,
+	0xba, 0x3f, 0xd1, 0xd3, 0x15, 0x42, 0xdf, 0x87, 0x0d, 0x85, 0x5c, 0x4b, 0x61, 0xdf, 0xb5, 0xc5,
+	0xaa, 0x28, 0xda, 0x86, 0xb6, 0x7d, 0x85, 0x9f, 0x64, 0x9e, 0x73, 0x91, 0x44, 0x2f, 0x60, 0x87,
+	0xf1, 0xd7, 0xe6, 0x4c, 0x18, 0x35, 0xbd, 0x92, 0xf2, 0x25, 0x57, 0xe9, 0x0a, 0x45, 0xc9, 0x17,
+	0xd0, 0xc2, 0x12, 0x3a, 0xc8, 0xfe, 0xc2, 0x4a, 0x85, 0x45, 0x22, 0xfa, 0x1a, 0xda, 0x3f, 0x2b,
+	0x39, 0x29, 0x06, 0x46, 0x16, 0x05, 0x26, 0xa5, 0x2e, 0x69, 0x19, 0x57, 0x5d, 0x66, 0x41, 0xf4,
+	0x0a, 0xba, 0xf6, 0x38, 0x0c, 0x63, 0x79, 0x8d, 0x2a, 0x13, 0xe9, 0x8a, 0x81, 0x87, 0xd0, 0x45,
+	0x6d, 0xb2, 0x9c, 0x1b, 0x4c, 0x2e, 0xb2, 0xf1, 0x38, 0xd3, 0xd5, 0xd8, 0x30, 0x1d, 0x9d, 0xc2,
+	0x2e, 0x43, 0x9e, 0x0c, 0x04, 0x2f, 0xf4, 0x48, 0x9a, 0x0f, 0x7f, 0x73, 0x42, 0xa0, 0x29, 0x78,
+	0x8e, 0x95, 0x42, 0xf6, 0xb9, 0x54, 0xf8, 0x59, 0x1c, 0xa3, 0xd6, 0xa7, 0x28, 0x32, 0x4c, 0x56,
+	0x2b, 0x6c, 0x50, 0x70, 0x61, 0xe6, 0x0a, 0xcf, 0xa2, 0xe8, 0x39, 0xb4, 0x9f, 0x25, 0x79, 0x26,
+	0xae, 0xb2, 0x1c, 0xe5, 0xc4, 0xac, 0x16, 0x93, 0x5b, 0xe4, 0xb4, 0xa8, 0xc5, 0xac, 0x13, 0xd1,
+	0x03, 0xd8, 0x66, 0xf8, 0xc7, 0x04, 0xb5, 0xf9, 0x60, 0xa7, 0xe8, 0x1b, 0xe8, 0x58, 0xe1, 0x2f,
+	0xb2, 0x54, 0x71, 0xf3, 0x5e, 0xe5, 0x7f, 0x83, 0x1e, 0x43, 0x5d, 0x48, 0xa1, 0xf1, 0x23, 0xbe,
+	0xf5, 0x03, 0xe8, 0xe5, 0xfc, 0xcd, 0x9c, 0xd0, 0x9f, 0x1a, 0x9c, 0x6b, 0xbf, 0x94, 0x8f, 0xfe,
+	0xbe, 0x07, 0xeb, 0x67, 0x4a, 0x49, 0xbb, 0xfb, 0x39, 0x6a, 0xcd, 0x53, 0xb4, 0xfd, 0x5a, 0x6c,
+	0x1e, 0x92, 0xef, 0xa1, 0x25, 0xe6, 0x16, 0x51, 0xad, 0x3f, 0x39, 0x9a, 0x1b, 0x57, 0x6d, 0x1e,
+	0x6c, 0x01, 0x22, 0x4f, 0xa0, 0xa3, 0xdd, 0xfd, 0xb5, 0xfb, 0xb5, 0x75, 0x72, 0xbf, 0x66, 0x79,
+	0xdb, 0xcd, 0x7c, 0x30, 0x79, 0x12, 0xac, 0x34, 0x6d, 0x06, 0x6c, 0xaf, 0xca, 0x82, 0xfd, 0x7f,
+	0x04, 0xa0, 0xeb, 0x5d, 0xa5, 0xeb, 0x96, 0xfa, 0xc9, 0x62, 0x70, 0x5d, 0x62, 0x0e, 0x8c, 0x3c,
+	0x86, 0xb6, 0x76, 0xf6, 0x93, 0x6e, 0x58, 0xda, 0xa7, 0x0b, 0x9a, 0x53, 0x64, 0x1e, 0xd4, 0x52,
+	0x9d, 0xc5, 0xa4, 0x9b, 0x21, 0xd5, 0x29, 0x32, 0x0f, 0x6a, 0x65, 0x72, 0x5d, 0x92, 0xde, 0x0b,
+	0x65, 0x72, 0xab, 0xcc, 0x07, 0x93, 0x5f, 0x60, 0x47, 0x85, 0x0e, 0x40, 0x5b, 0xb6, 0xc3, 0x5e,
+	0xdd, 0x61, 0xc9, 0x23, 0xd8, 0x32, 0x89, 0x9c, 0x41, 0x4f, 0x07, 0xe6, 0x4c, 0xc1, 0x36, 0xfa,
+	0xdc, 0xff, 0x62, 0x0e, 0x80, 0x2d, 0x51, 0x4a, 0x25, 0x52, 0xc7, 0x45, 0xe8, 0x56, 0xa0, 0x84,
+	0x6b, 0x31, 0xcc, 0x83, 0x92, 0x3e, 0x74, 0xb5, 0x6f, 0x2d, 0xb4, 0x6d, 0xd9, 0xd4, 0x7f, 0x81,
+	0x45, 0x9d, 0x85, 0x04, 0xf2, 0x2b, 0xec, 0xaa, 0x5b, 0x7c, 0x84, 0x76, 0x6c, 0xa3, 0x2f, 0x17,
+	0x92, 0xdc, 0x02, 0x62, 0xb7, 0x52, 0xcb, 0x13, 0x71, 0xc7, 0x54, 0xe8, 0x76, 0x70, 0x22, 0xd7,
+	0x71, 0x98, 0x07, 0xb5, 0x54, 0xc7, 0x4d, 0x68, 0x37, 0xa4, 0x3a, 0x45, 0xe6, 0x41, 0xc9, 0x53,
+	0xd8, 0x56, 0x9e, 0x81, 0xd0, 0x9e, 0x25, 0x7f, 0xe6, 0x1c, 0xc1, 0x2d, 0xb3, 0x00, 0x5e, 0xde,
+	0xab, 0xd4, 0x75, 0x15, 0xba, 0x13, 0xdc, 0x2b, 0xcf, 0x73, 0x98, 0x0f, 0x2e, 0x6f, 0x83, 0x0a,
+	0xcc, 0x86, 0x92, 0xe0, 0x36, 0x84, 0x6e, 0xc4, 0x96, 0x28, 0xfd, 0xde, 0xbb, 0xff, 0xf6, 0xef,
+	0xbc, 0xbd, 0xd9, 0x6f, 0xbc, 0xbb, 0xd9, 0x6f, 0xfc, 0x7b, 0xb3, 0xdf, 0x18, 0x6e, 0xd8, 0x7f,
+	0x1c, 0x8f, 0xfe, 0x1f, 0x00, 0x4e, 0x4b, 0xc5, 0x77, 0xf5, 0x08, 0x00, 0x00,
+}
+
+func (m *NotLeader) Marshal() (dAtA []byte, err error) {
 	size := m.Size()
 	dAtA = make([]byte, size)
 	n, err := m.MarshalTo(dAtA)
@@ -737,7 +1192,7 @@ func (m *ShardNotFound) Marshal() (dAtA []byte, err error) {
 	return dAtA[:n], nil
 }
 
-func (m *ShardNotFound) MarshalTo(dAtA []byte) (int, error) {
+func (m *NotLeader) MarshalTo(dAtA []byte) (int, error) {
 	var i int
 	_ = i
 	var l int
@@ -747,13 +1202,21 @@ func (m *ShardNotFound) MarshalTo(dAtA []byte) (int, error) {
 		i++
 		i = encodeVarintErrorpb(dAtA, i, uint64(m.ShardID))
 	}
+	dAtA[i] = 0x12
+	i++
+	i = encodeVarintErrorpb(dAtA, i, uint64(m.Leader.Size()))
+	n1, err := m.Leader.MarshalTo(dAtA[i:])
+	if err != nil {
+		return 0, err
+	}
+	i += n1
 	if m.XXX_unrecognized != nil {
 		i += copy(dAtA[i:], m.XXX_unrecognized)
 	}
 	return i, nil
 }
 
-func (m *KeyNotInShard) Marshal() (dAtA []byte, err error) {
+func (m *StoreMismatch) Marshal() (dAtA []byte, err error) {
 	size := m.Size()
 	dAtA = make([]byte, size)
 	n, err := m.MarshalTo(dAtA)
@@ -763,16 +1226,89 @@ func (m *KeyNotInShard) Marshal() (dAtA []byte, err error) {
 	return dAtA[:n], nil
 }
 
-func (m *KeyNotInShard) MarshalTo(dAtA []byte) (int, error) {
+func (m *StoreMismatch) MarshalTo(dAtA []byte) (int, error) {
 	var i int
 	_ = i
 	var l int
 	_ = l
-	if len(m.Key) > 0 {
-		dAtA[i] = 0xa
-		i++
-		i = encodeVarintErrorpb(dAtA, i, uint64(len(m.Key)))
-		i += copy(dAtA[i:], m.Key)
+	if m.XXX_unrecognized != nil {
+		i += copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	return i, nil
+}
+
+func (m *ShardUnavailable) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *ShardUnavailable) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if m.ShardID != 0 {
+		dAtA[i] = 0x8
+		i++
+		i = encodeVarintErrorpb(dAtA, i, uint64(m.ShardID))
+	}
+	if m.XXX_unrecognized != nil {
+		i += copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	return i, nil
+}
+
+func (m *ShardNotFound) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *ShardNotFound) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if m.ShardID != 0 {
+		dAtA[i] = 0x8
+		i++
+		i = encodeVarintErrorpb(dAtA, i, uint64(m.ShardID))
+	}
+	if m.XXX_unrecognized != nil {
+		i += copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	return i, nil
+}
+
+func (m *KeyNotInShard) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *KeyNotInShard) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.Key) > 0 {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintErrorpb(dAtA, i, uint64(len(m.Key)))
+		i += copy(dAtA[i:], m.Key)
 	}
 	if m.ShardID != 0 {
 		dAtA[i] = 0x10
@@ -845,6 +1381,17 @@ func (m *ServerIsBusy) MarshalTo(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if m.ShardID != 0 {
+		dAtA[i] = 0x8
+		i++
+		i = encodeVarintErrorpb(dAtA, i, uint64(m.ShardID))
+	}
+	if len(m.Reason) > 0 {
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintErrorpb(dAtA, i, uint64(len(m.Reason)))
+		i += copy(dAtA[i:], m.Reason)
+	}
 	if m.XXX_unrecognized != nil {
 		i += copy(dAtA[i:], m.XXX_unrecognized)
 	}
@@ -903,7 +1450,7 @@ func (m *RaftEntryTooLarge) MarshalTo(dAtA []byte) (int, error) {
 	return i, nil
 }
 
-func (m *Error) Marshal() (dAtA []byte, err error) {
+func (m *GroupStopped) Marshal() (dAtA []byte, err error) {
 	size := m.Size()
 	dAtA = make([]byte, size)
 	n, err := m.MarshalTo(dAtA)
@@ -913,106 +1460,46 @@ func (m *Error) Marshal() (dAtA []byte, err error) {
 	return dAtA[:n], nil
 }
 
-func (m *Error) MarshalTo(dAtA []byte) (int, error) {
+func (m *GroupStopped) MarshalTo(dAtA []byte) (int, error) {
 	var i int
 	_ = i
 	var l int
 	_ = l
-	if len(m.Message) > 0 {
-		dAtA[i] = 0xa
-		i++
-		i = encodeVarintErrorpb(dAtA, i, uint64(len(m.Message)))
-		i += copy(dAtA[i:], m.Message)
-	}
-	if m.NotLeader != nil {
-		dAtA[i] = 0x12
-		i++
-		i = encodeVarintErrorpb(dAtA, i, uint64(m.NotLeader.Size()))
-		n2, err := m.NotLeader.MarshalTo(dAtA[i:])
-		if err != nil {
-			return 0, err
-		}
-		i += n2
-	}
-	if m.ShardNotFound != nil {
-		dAtA[i] = 0x1a
-		i++
-		i = encodeVarintErrorpb(dAtA, i, uint64(m.ShardNotFound.Size()))
-		n3, err := m.ShardNotFound.MarshalTo(dAtA[i:])
-		if err != nil {
-			return 0, err
-		}
-		i += n3
-	}
-	if m.KeyNotInShard != nil {
-		dAtA[i] = 0x22
-		i++
-		i = encodeVarintErrorpb(dAtA, i, uint64(m.KeyNotInShard.Size()))
-		n4, err := m.KeyNotInShard.MarshalTo(dAtA[i:])
-		if err != nil {
-			return 0, err
-		}
-		i += n4
-	}
-	if m.StaleEpoch != nil {
-		dAtA[i] = 0x2a
-		i++
-		i = encodeVarintErrorpb(dAtA, i, uint64(m.StaleEpoch.Size()))
-		n5, err := m.StaleEpoch.MarshalTo(dAtA[i:])
-		if err != nil {
-			return 0, err
-		}
-		i += n5
-	}
-	if m.ServerIsBusy != nil {
-		dAtA[i] = 0x32
+	if m.Group != 0 {
+		dAtA[i] = 0x8
 		i++
-		i = encodeVarintErrorpb(dAtA, i, uint64(m.ServerIsBusy.Size()))
-		n6, err := m.ServerIsBusy.MarshalTo(dAtA[i:])
-		if err != nil {
-			return 0, err
-		}
-		i += n6
+		i = encodeVarintErrorpb(dAtA, i, uint64(m.Group))
 	}
-	if m.StaleCommand != nil {
-		dAtA[i] = 0x3a
-		i++
-		i = encodeVarintErrorpb(dAtA, i, uint64(m.StaleCommand.Size()))
-		n7, err := m.StaleCommand.MarshalTo(dAtA[i:])
-		if err != nil {
-			return 0, err
-		}
-		i += n7
+	if m.XXX_unrecognized != nil {
+		i += copy(dAtA[i:], m.XXX_unrecognized)
 	}
-	if m.StoreMismatch != nil {
-		dAtA[i] = 0x42
-		i++
-		i = encodeVarintErrorpb(dAtA, i, uint64(m.StoreMismatch.Size()))
-		n8, err := m.StoreMismatch.MarshalTo(dAtA[i:])
-		if err != nil {
-			return 0, err
-		}
-		i += n8
+	return i, nil
+}
+
+func (m *ShardRecovering) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
 	}
-	if m.RaftEntryTooLarge != nil {
-		dAtA[i] = 0x4a
+	return dAtA[:n], nil
+}
+
+func (m *ShardRecovering) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if m.ShardID != 0 {
+		dAtA[i] = 0x8
 		i++
-		i = encodeVarintErrorpb(dAtA, i, uint64(m.RaftEntryTooLarge.Size()))
-		n9, err := m.RaftEntryTooLarge.MarshalTo(dAtA[i:])
-		if err != nil {
-			return 0, err
-		}
-		i += n9
+		i = encodeVarintErrorpb(dAtA, i, uint64(m.ShardID))
 	}
-	if m.ShardUnavailable != nil {
-		dAtA[i] = 0x52
+	if m.EstimatedMillis != 0 {
+		dAtA[i] = 0x10
 		i++
-		i = encodeVarintErrorpb(dAtA, i, uint64(m.ShardUnavailable.Size()))
-		n10, err := m.ShardUnavailable.MarshalTo(dAtA[i:])
-		if err != nil {
-			return 0, err
-		}
-		i += n10
+		i = encodeVarintErrorpb(dAtA, i, uint64(m.EstimatedMillis))
 	}
 	if m.XXX_unrecognized != nil {
 		i += copy(dAtA[i:], m.XXX_unrecognized)
@@ -1020,227 +1507,1512 @@ func (m *Error) MarshalTo(dAtA []byte) (int, error) {
 	return i, nil
 }
 
-func encodeVarintErrorpb(dAtA []byte, offset int, v uint64) int {
-	for v >= 1<<7 {
-		dAtA[offset] = uint8(v&0x7f | 0x80)
-		v >>= 7
-		offset++
+func (m *ReadSnapshotNotFound) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
 	}
-	dAtA[offset] = uint8(v)
-	return offset + 1
+	return dAtA[:n], nil
 }
-func (m *NotLeader) Size() (n int) {
-	if m == nil {
-		return 0
-	}
+
+func (m *ReadSnapshotNotFound) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
 	var l int
 	_ = l
 	if m.ShardID != 0 {
-		n += 1 + sovErrorpb(uint64(m.ShardID))
+		dAtA[i] = 0x8
+		i++
+		i = encodeVarintErrorpb(dAtA, i, uint64(m.ShardID))
+	}
+	if len(m.Name) > 0 {
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintErrorpb(dAtA, i, uint64(len(m.Name)))
+		i += copy(dAtA[i:], m.Name)
 	}
-	l = m.Leader.Size()
-	n += 1 + l + sovErrorpb(uint64(l))
 	if m.XXX_unrecognized != nil {
-		n += len(m.XXX_unrecognized)
+		i += copy(dAtA[i:], m.XXX_unrecognized)
 	}
-	return n
+	return i, nil
 }
 
-func (m *StoreMismatch) Size() (n int) {
-	if m == nil {
-		return 0
-	}
-	var l int
-	_ = l
-	if m.XXX_unrecognized != nil {
-		n += len(m.XXX_unrecognized)
+func (m *AccessDenied) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
 	}
-	return n
+	return dAtA[:n], nil
 }
 
-func (m *ShardUnavailable) Size() (n int) {
-	if m == nil {
-		return 0
-	}
+func (m *AccessDenied) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
 	var l int
 	_ = l
 	if m.ShardID != 0 {
-		n += 1 + sovErrorpb(uint64(m.ShardID))
+		dAtA[i] = 0x8
+		i++
+		i = encodeVarintErrorpb(dAtA, i, uint64(m.ShardID))
 	}
-	if m.XXX_unrecognized != nil {
-		n += len(m.XXX_unrecognized)
+	if len(m.Tenant) > 0 {
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintErrorpb(dAtA, i, uint64(len(m.Tenant)))
+		i += copy(dAtA[i:], m.Tenant)
 	}
-	return n
+	if m.XXX_unrecognized != nil {
+		i += copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	return i, nil
 }
 
-func (m *ShardNotFound) Size() (n int) {
-	if m == nil {
-		return 0
+func (m *AdminTimeout) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
 	}
+	return dAtA[:n], nil
+}
+
+func (m *AdminTimeout) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
 	var l int
 	_ = l
 	if m.ShardID != 0 {
-		n += 1 + sovErrorpb(uint64(m.ShardID))
+		dAtA[i] = 0x8
+		i++
+		i = encodeVarintErrorpb(dAtA, i, uint64(m.ShardID))
+	}
+	if m.AdminType != 0 {
+		dAtA[i] = 0x10
+		i++
+		i = encodeVarintErrorpb(dAtA, i, uint64(m.AdminType))
 	}
 	if m.XXX_unrecognized != nil {
-		n += len(m.XXX_unrecognized)
+		i += copy(dAtA[i:], m.XXX_unrecognized)
 	}
-	return n
+	return i, nil
 }
 
-func (m *KeyNotInShard) Size() (n int) {
-	if m == nil {
-		return 0
+func (m *RequestTimeout) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
 	}
+	return dAtA[:n], nil
+}
+
+func (m *RequestTimeout) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
 	var l int
 	_ = l
-	l = len(m.Key)
-	if l > 0 {
-		n += 1 + l + sovErrorpb(uint64(l))
-	}
 	if m.ShardID != 0 {
-		n += 1 + sovErrorpb(uint64(m.ShardID))
+		dAtA[i] = 0x8
+		i++
+		i = encodeVarintErrorpb(dAtA, i, uint64(m.ShardID))
 	}
-	l = len(m.Start)
-	if l > 0 {
-		n += 1 + l + sovErrorpb(uint64(l))
+	if m.XXX_unrecognized != nil {
+		i += copy(dAtA[i:], m.XXX_unrecognized)
 	}
-	l = len(m.End)
-	if l > 0 {
-		n += 1 + l + sovErrorpb(uint64(l))
+	return i, nil
+}
+
+func (m *GroupMigrated) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *GroupMigrated) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if m.Group != 0 {
+		dAtA[i] = 0x8
+		i++
+		i = encodeVarintErrorpb(dAtA, i, uint64(m.Group))
 	}
 	if m.XXX_unrecognized != nil {
-		n += len(m.XXX_unrecognized)
+		i += copy(dAtA[i:], m.XXX_unrecognized)
 	}
-	return n
+	return i, nil
 }
 
-func (m *StaleEpoch) Size() (n int) {
-	if m == nil {
-		return 0
+func (m *ResponseTooLarge) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
 	}
+	return dAtA[:n], nil
+}
+
+func (m *ResponseTooLarge) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
 	var l int
 	_ = l
-	if len(m.NewShards) > 0 {
-		for _, e := range m.NewShards {
-			l = e.Size()
-			n += 1 + l + sovErrorpb(uint64(l))
-		}
+	if m.ShardID != 0 {
+		dAtA[i] = 0x8
+		i++
+		i = encodeVarintErrorpb(dAtA, i, uint64(m.ShardID))
+	}
+	if m.MaxResponseBytes != 0 {
+		dAtA[i] = 0x10
+		i++
+		i = encodeVarintErrorpb(dAtA, i, uint64(m.MaxResponseBytes))
 	}
 	if m.XXX_unrecognized != nil {
-		n += len(m.XXX_unrecognized)
+		i += copy(dAtA[i:], m.XXX_unrecognized)
 	}
-	return n
+	return i, nil
 }
 
-func (m *ServerIsBusy) Size() (n int) {
-	if m == nil {
-		return 0
+func (m *Error) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
 	}
+	return dAtA[:n], nil
+}
+
+func (m *Error) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
 	var l int
 	_ = l
-	if m.XXX_unrecognized != nil {
-		n += len(m.XXX_unrecognized)
+	if len(m.Message) > 0 {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintErrorpb(dAtA, i, uint64(len(m.Message)))
+		i += copy(dAtA[i:], m.Message)
+	}
+	if m.NotLeader != nil {
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintErrorpb(dAtA, i, uint64(m.NotLeader.Size()))
+		n2, err := m.NotLeader.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n2
+	}
+	if m.ShardNotFound != nil {
+		dAtA[i] = 0x1a
+		i++
+		i = encodeVarintErrorpb(dAtA, i, uint64(m.ShardNotFound.Size()))
+		n3, err := m.ShardNotFound.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n3
+	}
+	if m.KeyNotInShard != nil {
+		dAtA[i] = 0x22
+		i++
+		i = encodeVarintErrorpb(dAtA, i, uint64(m.KeyNotInShard.Size()))
+		n4, err := m.KeyNotInShard.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n4
+	}
+	if m.StaleEpoch != nil {
+		dAtA[i] = 0x2a
+		i++
+		i = encodeVarintErrorpb(dAtA, i, uint64(m.StaleEpoch.Size()))
+		n5, err := m.StaleEpoch.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n5
+	}
+	if m.ServerIsBusy != nil {
+		dAtA[i] = 0x32
+		i++
+		i = encodeVarintErrorpb(dAtA, i, uint64(m.ServerIsBusy.Size()))
+		n6, err := m.ServerIsBusy.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n6
+	}
+	if m.StaleCommand != nil {
+		dAtA[i] = 0x3a
+		i++
+		i = encodeVarintErrorpb(dAtA, i, uint64(m.StaleCommand.Size()))
+		n7, err := m.StaleCommand.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n7
+	}
+	if m.StoreMismatch != nil {
+		dAtA[i] = 0x42
+		i++
+		i = encodeVarintErrorpb(dAtA, i, uint64(m.StoreMismatch.Size()))
+		n8, err := m.StoreMismatch.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n8
+	}
+	if m.RaftEntryTooLarge != nil {
+		dAtA[i] = 0x4a
+		i++
+		i = encodeVarintErrorpb(dAtA, i, uint64(m.RaftEntryTooLarge.Size()))
+		n9, err := m.RaftEntryTooLarge.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n9
+	}
+	if m.ShardUnavailable != nil {
+		dAtA[i] = 0x52
+		i++
+		i = encodeVarintErrorpb(dAtA, i, uint64(m.ShardUnavailable.Size()))
+		n10, err := m.ShardUnavailable.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n10
+	}
+	if m.GroupStopped != nil {
+		dAtA[i] = 0x5a
+		i++
+		i = encodeVarintErrorpb(dAtA, i, uint64(m.GroupStopped.Size()))
+		n11, err := m.GroupStopped.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n11
+	}
+	if m.ShardRecovering != nil {
+		dAtA[i] = 0x62
+		i++
+		i = encodeVarintErrorpb(dAtA, i, uint64(m.ShardRecovering.Size()))
+		n12, err := m.ShardRecovering.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n12
+	}
+	if m.ReadSnapshotNotFound != nil {
+		dAtA[i] = 0x6a
+		i++
+		i = encodeVarintErrorpb(dAtA, i, uint64(m.ReadSnapshotNotFound.Size()))
+		n13, err := m.ReadSnapshotNotFound.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n13
+	}
+	if m.AccessDenied != nil {
+		dAtA[i] = 0x72
+		i++
+		i = encodeVarintErrorpb(dAtA, i, uint64(m.AccessDenied.Size()))
+		n14, err := m.AccessDenied.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n14
+	}
+	if m.AdminTimeout != nil {
+		dAtA[i] = 0x7a
+		i++
+		i = encodeVarintErrorpb(dAtA, i, uint64(m.AdminTimeout.Size()))
+		n15, err := m.AdminTimeout.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n15
+	}
+	if m.RequestTimeout != nil {
+		dAtA[i] = 0x82
+		i++
+		dAtA[i] = 0x1
+		i++
+		i = encodeVarintErrorpb(dAtA, i, uint64(m.RequestTimeout.Size()))
+		n16, err := m.RequestTimeout.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n16
+	}
+	if m.GroupMigrated != nil {
+		dAtA[i] = 0x8a
+		i++
+		dAtA[i] = 0x1
+		i++
+		i = encodeVarintErrorpb(dAtA, i, uint64(m.GroupMigrated.Size()))
+		n17, err := m.GroupMigrated.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n17
+	}
+	if m.ResponseTooLarge != nil {
+		dAtA[i] = 0x92
+		i++
+		dAtA[i] = 0x1
+		i++
+		i = encodeVarintErrorpb(dAtA, i, uint64(m.ResponseTooLarge.Size()))
+		n18, err := m.ResponseTooLarge.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n18
+	}
+	if m.XXX_unrecognized != nil {
+		i += copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	return i, nil
+}
+
+func encodeVarintErrorpb(dAtA []byte, offset int, v uint64) int {
+	for v >= 1<<7 {
+		dAtA[offset] = uint8(v&0x7f | 0x80)
+		v >>= 7
+		offset++
+	}
+	dAtA[offset] = uint8(v)
+	return offset + 1
+}
+func (m *NotLeader) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.ShardID != 0 {
+		n += 1 + sovErrorpb(uint64(m.ShardID))
+	}
+	l = m.Leader.Size()
+	n += 1 + l + sovErrorpb(uint64(l))
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *StoreMismatch) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *ShardUnavailable) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.ShardID != 0 {
+		n += 1 + sovErrorpb(uint64(m.ShardID))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *ShardNotFound) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.ShardID != 0 {
+		n += 1 + sovErrorpb(uint64(m.ShardID))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *KeyNotInShard) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Key)
+	if l > 0 {
+		n += 1 + l + sovErrorpb(uint64(l))
+	}
+	if m.ShardID != 0 {
+		n += 1 + sovErrorpb(uint64(m.ShardID))
+	}
+	l = len(m.Start)
+	if l > 0 {
+		n += 1 + l + sovErrorpb(uint64(l))
+	}
+	l = len(m.End)
+	if l > 0 {
+		n += 1 + l + sovErrorpb(uint64(l))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *StaleEpoch) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if len(m.NewShards) > 0 {
+		for _, e := range m.NewShards {
+			l = e.Size()
+			n += 1 + l + sovErrorpb(uint64(l))
+		}
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *ServerIsBusy) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.ShardID != 0 {
+		n += 1 + sovErrorpb(uint64(m.ShardID))
+	}
+	l = len(m.Reason)
+	if l > 0 {
+		n += 1 + l + sovErrorpb(uint64(l))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *StaleCommand) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *RaftEntryTooLarge) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.ShardID != 0 {
+		n += 1 + sovErrorpb(uint64(m.ShardID))
+	}
+	if m.EntrySize != 0 {
+		n += 1 + sovErrorpb(uint64(m.EntrySize))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *GroupStopped) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.Group != 0 {
+		n += 1 + sovErrorpb(uint64(m.Group))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *ShardRecovering) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.ShardID != 0 {
+		n += 1 + sovErrorpb(uint64(m.ShardID))
+	}
+	if m.EstimatedMillis != 0 {
+		n += 1 + sovErrorpb(uint64(m.EstimatedMillis))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *ReadSnapshotNotFound) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.ShardID != 0 {
+		n += 1 + sovErrorpb(uint64(m.ShardID))
+	}
+	l = len(m.Name)
+	if l > 0 {
+		n += 1 + l + sovErrorpb(uint64(l))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *AccessDenied) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.ShardID != 0 {
+		n += 1 + sovErrorpb(uint64(m.ShardID))
+	}
+	l = len(m.Tenant)
+	if l > 0 {
+		n += 1 + l + sovErrorpb(uint64(l))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *AdminTimeout) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.ShardID != 0 {
+		n += 1 + sovErrorpb(uint64(m.ShardID))
+	}
+	if m.AdminType != 0 {
+		n += 1 + sovErrorpb(uint64(m.AdminType))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *RequestTimeout) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.ShardID != 0 {
+		n += 1 + sovErrorpb(uint64(m.ShardID))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *GroupMigrated) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.Group != 0 {
+		n += 1 + sovErrorpb(uint64(m.Group))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *ResponseTooLarge) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.ShardID != 0 {
+		n += 1 + sovErrorpb(uint64(m.ShardID))
+	}
+	if m.MaxResponseBytes != 0 {
+		n += 1 + sovErrorpb(uint64(m.MaxResponseBytes))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *Error) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Message)
+	if l > 0 {
+		n += 1 + l + sovErrorpb(uint64(l))
+	}
+	if m.NotLeader != nil {
+		l = m.NotLeader.Size()
+		n += 1 + l + sovErrorpb(uint64(l))
+	}
+	if m.ShardNotFound != nil {
+		l = m.ShardNotFound.Size()
+		n += 1 + l + sovErrorpb(uint64(l))
+	}
+	if m.KeyNotInShard != nil {
+		l = m.KeyNotInShard.Size()
+		n += 1 + l + sovErrorpb(uint64(l))
+	}
+	if m.StaleEpoch != nil {
+		l = m.StaleEpoch.Size()
+		n += 1 + l + sovErrorpb(uint64(l))
+	}
+	if m.ServerIsBusy != nil {
+		l = m.ServerIsBusy.Size()
+		n += 1 + l + sovErrorpb(uint64(l))
+	}
+	if m.StaleCommand != nil {
+		l = m.StaleCommand.Size()
+		n += 1 + l + sovErrorpb(uint64(l))
+	}
+	if m.StoreMismatch != nil {
+		l = m.StoreMismatch.Size()
+		n += 1 + l + sovErrorpb(uint64(l))
+	}
+	if m.RaftEntryTooLarge != nil {
+		l = m.RaftEntryTooLarge.Size()
+		n += 1 + l + sovErrorpb(uint64(l))
+	}
+	if m.ShardUnavailable != nil {
+		l = m.ShardUnavailable.Size()
+		n += 1 + l + sovErrorpb(uint64(l))
+	}
+	if m.GroupStopped != nil {
+		l = m.GroupStopped.Size()
+		n += 1 + l + sovErrorpb(uint64(l))
+	}
+	if m.ShardRecovering != nil {
+		l = m.ShardRecovering.Size()
+		n += 1 + l + sovErrorpb(uint64(l))
+	}
+	if m.ReadSnapshotNotFound != nil {
+		l = m.ReadSnapshotNotFound.Size()
+		n += 1 + l + sovErrorpb(uint64(l))
+	}
+	if m.AccessDenied != nil {
+		l = m.AccessDenied.Size()
+		n += 1 + l + sovErrorpb(uint64(l))
+	}
+	if m.AdminTimeout != nil {
+		l = m.AdminTimeout.Size()
+		n += 1 + l + sovErrorpb(uint64(l))
+	}
+	if m.RequestTimeout != nil {
+		l = m.RequestTimeout.Size()
+		n += 2 + l + sovErrorpb(uint64(l))
+	}
+	if m.GroupMigrated != nil {
+		l = m.GroupMigrated.Size()
+		n += 2 + l + sovErrorpb(uint64(l))
+	}
+	if m.ResponseTooLarge != nil {
+		l = m.ResponseTooLarge.Size()
+		n += 2 + l + sovErrorpb(uint64(l))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func sovErrorpb(x uint64) (n int) {
+	for {
+		n++
+		x >>= 7
+		if x == 0 {
+			break
+		}
+	}
+	return n
+}
+func sozErrorpb(x uint64) (n int) {
+	return sovErrorpb(uint64((x << 1) ^ uint64((int64(x) >> 63))))
+}
+func (m *NotLeader) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowErrorpb
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: NotLeader: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: NotLeader: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ShardID", wireType)
+			}
+			m.ShardID = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowErrorpb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.ShardID |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Leader", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowErrorpb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthErrorpb
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthErrorpb
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if err := m.Leader.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipErrorpb(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthErrorpb
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthErrorpb
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *StoreMismatch) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowErrorpb
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: StoreMismatch: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: StoreMismatch: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		default:
+			iNdEx = preIndex
+			skippy, err := skipErrorpb(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthErrorpb
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthErrorpb
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *ShardUnavailable) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowErrorpb
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: ShardUnavailable: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: ShardUnavailable: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ShardID", wireType)
+			}
+			m.ShardID = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowErrorpb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.ShardID |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipErrorpb(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthErrorpb
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthErrorpb
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *ShardNotFound) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowErrorpb
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: ShardNotFound: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: ShardNotFound: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ShardID", wireType)
+			}
+			m.ShardID = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowErrorpb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.ShardID |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipErrorpb(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthErrorpb
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthErrorpb
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *KeyNotInShard) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowErrorpb
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: KeyNotInShard: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: KeyNotInShard: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Key", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowErrorpb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthErrorpb
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthErrorpb
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Key = append(m.Key[:0], dAtA[iNdEx:postIndex]...)
+			if m.Key == nil {
+				m.Key = []byte{}
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ShardID", wireType)
+			}
+			m.ShardID = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowErrorpb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.ShardID |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Start", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowErrorpb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthErrorpb
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthErrorpb
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Start = append(m.Start[:0], dAtA[iNdEx:postIndex]...)
+			if m.Start == nil {
+				m.Start = []byte{}
+			}
+			iNdEx = postIndex
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field End", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowErrorpb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthErrorpb
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthErrorpb
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.End = append(m.End[:0], dAtA[iNdEx:postIndex]...)
+			if m.End == nil {
+				m.End = []byte{}
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipErrorpb(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthErrorpb
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthErrorpb
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *StaleEpoch) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowErrorpb
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: StaleEpoch: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: StaleEpoch: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field NewShards", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowErrorpb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthErrorpb
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthErrorpb
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.NewShards = append(m.NewShards, metapb.Shard{})
+			if err := m.NewShards[len(m.NewShards)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipErrorpb(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthErrorpb
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthErrorpb
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
 	}
-	return n
-}
 
-func (m *StaleCommand) Size() (n int) {
-	if m == nil {
-		return 0
-	}
-	var l int
-	_ = l
-	if m.XXX_unrecognized != nil {
-		n += len(m.XXX_unrecognized)
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
 	}
-	return n
+	return nil
 }
-
-func (m *RaftEntryTooLarge) Size() (n int) {
-	if m == nil {
-		return 0
-	}
-	var l int
-	_ = l
-	if m.ShardID != 0 {
-		n += 1 + sovErrorpb(uint64(m.ShardID))
-	}
-	if m.EntrySize != 0 {
-		n += 1 + sovErrorpb(uint64(m.EntrySize))
-	}
-	if m.XXX_unrecognized != nil {
-		n += len(m.XXX_unrecognized)
+func (m *ServerIsBusy) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowErrorpb
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: ServerIsBusy: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: ServerIsBusy: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ShardID", wireType)
+			}
+			m.ShardID = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowErrorpb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.ShardID |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Reason", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowErrorpb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthErrorpb
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthErrorpb
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Reason = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipErrorpb(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthErrorpb
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthErrorpb
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
 	}
-	return n
-}
 
-func (m *Error) Size() (n int) {
-	if m == nil {
-		return 0
-	}
-	var l int
-	_ = l
-	l = len(m.Message)
-	if l > 0 {
-		n += 1 + l + sovErrorpb(uint64(l))
-	}
-	if m.NotLeader != nil {
-		l = m.NotLeader.Size()
-		n += 1 + l + sovErrorpb(uint64(l))
-	}
-	if m.ShardNotFound != nil {
-		l = m.ShardNotFound.Size()
-		n += 1 + l + sovErrorpb(uint64(l))
-	}
-	if m.KeyNotInShard != nil {
-		l = m.KeyNotInShard.Size()
-		n += 1 + l + sovErrorpb(uint64(l))
-	}
-	if m.StaleEpoch != nil {
-		l = m.StaleEpoch.Size()
-		n += 1 + l + sovErrorpb(uint64(l))
-	}
-	if m.ServerIsBusy != nil {
-		l = m.ServerIsBusy.Size()
-		n += 1 + l + sovErrorpb(uint64(l))
-	}
-	if m.StaleCommand != nil {
-		l = m.StaleCommand.Size()
-		n += 1 + l + sovErrorpb(uint64(l))
-	}
-	if m.StoreMismatch != nil {
-		l = m.StoreMismatch.Size()
-		n += 1 + l + sovErrorpb(uint64(l))
-	}
-	if m.RaftEntryTooLarge != nil {
-		l = m.RaftEntryTooLarge.Size()
-		n += 1 + l + sovErrorpb(uint64(l))
-	}
-	if m.ShardUnavailable != nil {
-		l = m.ShardUnavailable.Size()
-		n += 1 + l + sovErrorpb(uint64(l))
-	}
-	if m.XXX_unrecognized != nil {
-		n += len(m.XXX_unrecognized)
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
 	}
-	return n
+	return nil
 }
-
-func sovErrorpb(x uint64) (n int) {
-	for {
-		n++
-		x >>= 7
-		if x == 0 {
-			break
+func (m *StaleCommand) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowErrorpb
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: StaleCommand: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: StaleCommand: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		default:
+			iNdEx = preIndex
+			skippy, err := skipErrorpb(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthErrorpb
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthErrorpb
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
 		}
 	}
-	return n
-}
-func sozErrorpb(x uint64) (n int) {
-	return sovErrorpb(uint64((x << 1) ^ uint64((int64(x) >> 63))))
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
 }
-func (m *NotLeader) Unmarshal(dAtA []byte) error {
+func (m *RaftEntryTooLarge) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -1263,10 +3035,10 @@ func (m *NotLeader) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: NotLeader: wiretype end group for non-group")
+			return fmt.Errorf("proto: RaftEntryTooLarge: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: NotLeader: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: RaftEntryTooLarge: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
@@ -1288,11 +3060,11 @@ func (m *NotLeader) Unmarshal(dAtA []byte) error {
 					break
 				}
 			}
-		case 2:
-			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Leader", wireType)
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field EntrySize", wireType)
 			}
-			var msglen int
+			m.EntrySize = 0
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowErrorpb
@@ -1302,25 +3074,11 @@ func (m *NotLeader) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				msglen |= int(b&0x7F) << shift
+				m.EntrySize |= uint64(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			if msglen < 0 {
-				return ErrInvalidLengthErrorpb
-			}
-			postIndex := iNdEx + msglen
-			if postIndex < 0 {
-				return ErrInvalidLengthErrorpb
-			}
-			if postIndex > l {
-				return io.ErrUnexpectedEOF
-			}
-			if err := m.Leader.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
-				return err
-			}
-			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipErrorpb(dAtA[iNdEx:])
@@ -1346,7 +3104,7 @@ func (m *NotLeader) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *StoreMismatch) Unmarshal(dAtA []byte) error {
+func (m *GroupStopped) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -1369,12 +3127,31 @@ func (m *StoreMismatch) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: StoreMismatch: wiretype end group for non-group")
+			return fmt.Errorf("proto: GroupStopped: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: StoreMismatch: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: GroupStopped: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Group", wireType)
+			}
+			m.Group = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowErrorpb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Group |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
 		default:
 			iNdEx = preIndex
 			skippy, err := skipErrorpb(dAtA[iNdEx:])
@@ -1400,7 +3177,7 @@ func (m *StoreMismatch) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *ShardUnavailable) Unmarshal(dAtA []byte) error {
+func (m *ShardRecovering) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -1423,10 +3200,10 @@ func (m *ShardUnavailable) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: ShardUnavailable: wiretype end group for non-group")
+			return fmt.Errorf("proto: ShardRecovering: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: ShardUnavailable: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: ShardRecovering: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
@@ -1448,6 +3225,25 @@ func (m *ShardUnavailable) Unmarshal(dAtA []byte) error {
 					break
 				}
 			}
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field EstimatedMillis", wireType)
+			}
+			m.EstimatedMillis = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowErrorpb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.EstimatedMillis |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
 		default:
 			iNdEx = preIndex
 			skippy, err := skipErrorpb(dAtA[iNdEx:])
@@ -1473,7 +3269,7 @@ func (m *ShardUnavailable) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *ShardNotFound) Unmarshal(dAtA []byte) error {
+func (m *ReadSnapshotNotFound) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -1496,10 +3292,10 @@ func (m *ShardNotFound) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: ShardNotFound: wiretype end group for non-group")
+			return fmt.Errorf("proto: ReadSnapshotNotFound: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: ShardNotFound: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: ReadSnapshotNotFound: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
@@ -1521,6 +3317,38 @@ func (m *ShardNotFound) Unmarshal(dAtA []byte) error {
 					break
 				}
 			}
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Name", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowErrorpb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthErrorpb
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthErrorpb
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Name = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipErrorpb(dAtA[iNdEx:])
@@ -1546,7 +3374,7 @@ func (m *ShardNotFound) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *KeyNotInShard) Unmarshal(dAtA []byte) error {
+func (m *AccessDenied) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -1569,47 +3397,13 @@ func (m *KeyNotInShard) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: KeyNotInShard: wiretype end group for non-group")
+			return fmt.Errorf("proto: AccessDenied: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: KeyNotInShard: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: AccessDenied: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
-			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Key", wireType)
-			}
-			var byteLen int
-			for shift := uint(0); ; shift += 7 {
-				if shift >= 64 {
-					return ErrIntOverflowErrorpb
-				}
-				if iNdEx >= l {
-					return io.ErrUnexpectedEOF
-				}
-				b := dAtA[iNdEx]
-				iNdEx++
-				byteLen |= int(b&0x7F) << shift
-				if b < 0x80 {
-					break
-				}
-			}
-			if byteLen < 0 {
-				return ErrInvalidLengthErrorpb
-			}
-			postIndex := iNdEx + byteLen
-			if postIndex < 0 {
-				return ErrInvalidLengthErrorpb
-			}
-			if postIndex > l {
-				return io.ErrUnexpectedEOF
-			}
-			m.Key = append(m.Key[:0], dAtA[iNdEx:postIndex]...)
-			if m.Key == nil {
-				m.Key = []byte{}
-			}
-			iNdEx = postIndex
-		case 2:
 			if wireType != 0 {
 				return fmt.Errorf("proto: wrong wireType = %d for field ShardID", wireType)
 			}
@@ -1628,45 +3422,11 @@ func (m *KeyNotInShard) Unmarshal(dAtA []byte) error {
 					break
 				}
 			}
-		case 3:
-			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Start", wireType)
-			}
-			var byteLen int
-			for shift := uint(0); ; shift += 7 {
-				if shift >= 64 {
-					return ErrIntOverflowErrorpb
-				}
-				if iNdEx >= l {
-					return io.ErrUnexpectedEOF
-				}
-				b := dAtA[iNdEx]
-				iNdEx++
-				byteLen |= int(b&0x7F) << shift
-				if b < 0x80 {
-					break
-				}
-			}
-			if byteLen < 0 {
-				return ErrInvalidLengthErrorpb
-			}
-			postIndex := iNdEx + byteLen
-			if postIndex < 0 {
-				return ErrInvalidLengthErrorpb
-			}
-			if postIndex > l {
-				return io.ErrUnexpectedEOF
-			}
-			m.Start = append(m.Start[:0], dAtA[iNdEx:postIndex]...)
-			if m.Start == nil {
-				m.Start = []byte{}
-			}
-			iNdEx = postIndex
-		case 4:
+		case 2:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field End", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Tenant", wireType)
 			}
-			var byteLen int
+			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowErrorpb
@@ -1676,25 +3436,23 @@ func (m *KeyNotInShard) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				byteLen |= int(b&0x7F) << shift
+				stringLen |= uint64(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			if byteLen < 0 {
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
 				return ErrInvalidLengthErrorpb
 			}
-			postIndex := iNdEx + byteLen
+			postIndex := iNdEx + intStringLen
 			if postIndex < 0 {
 				return ErrInvalidLengthErrorpb
 			}
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.End = append(m.End[:0], dAtA[iNdEx:postIndex]...)
-			if m.End == nil {
-				m.End = []byte{}
-			}
+			m.Tenant = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
@@ -1721,7 +3479,7 @@ func (m *KeyNotInShard) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *StaleEpoch) Unmarshal(dAtA []byte) error {
+func (m *AdminTimeout) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -1744,17 +3502,17 @@ func (m *StaleEpoch) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: StaleEpoch: wiretype end group for non-group")
+			return fmt.Errorf("proto: AdminTimeout: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: StaleEpoch: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: AdminTimeout: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
-			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field NewShards", wireType)
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ShardID", wireType)
 			}
-			var msglen int
+			m.ShardID = 0
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowErrorpb
@@ -1764,26 +3522,30 @@ func (m *StaleEpoch) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				msglen |= int(b&0x7F) << shift
+				m.ShardID |= uint64(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			if msglen < 0 {
-				return ErrInvalidLengthErrorpb
-			}
-			postIndex := iNdEx + msglen
-			if postIndex < 0 {
-				return ErrInvalidLengthErrorpb
-			}
-			if postIndex > l {
-				return io.ErrUnexpectedEOF
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field AdminType", wireType)
 			}
-			m.NewShards = append(m.NewShards, metapb.Shard{})
-			if err := m.NewShards[len(m.NewShards)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
-				return err
+			m.AdminType = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowErrorpb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.AdminType |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
 			}
-			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipErrorpb(dAtA[iNdEx:])
@@ -1809,7 +3571,7 @@ func (m *StaleEpoch) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *ServerIsBusy) Unmarshal(dAtA []byte) error {
+func (m *RequestTimeout) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -1832,12 +3594,31 @@ func (m *ServerIsBusy) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: ServerIsBusy: wiretype end group for non-group")
+			return fmt.Errorf("proto: RequestTimeout: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: ServerIsBusy: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: RequestTimeout: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ShardID", wireType)
+			}
+			m.ShardID = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowErrorpb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.ShardID |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
 		default:
 			iNdEx = preIndex
 			skippy, err := skipErrorpb(dAtA[iNdEx:])
@@ -1863,7 +3644,7 @@ func (m *ServerIsBusy) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *StaleCommand) Unmarshal(dAtA []byte) error {
+func (m *GroupMigrated) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -1886,12 +3667,31 @@ func (m *StaleCommand) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: StaleCommand: wiretype end group for non-group")
+			return fmt.Errorf("proto: GroupMigrated: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: StaleCommand: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: GroupMigrated: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Group", wireType)
+			}
+			m.Group = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowErrorpb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Group |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
 		default:
 			iNdEx = preIndex
 			skippy, err := skipErrorpb(dAtA[iNdEx:])
@@ -1917,7 +3717,7 @@ func (m *StaleCommand) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *RaftEntryTooLarge) Unmarshal(dAtA []byte) error {
+func (m *ResponseTooLarge) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -1940,10 +3740,10 @@ func (m *RaftEntryTooLarge) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: RaftEntryTooLarge: wiretype end group for non-group")
+			return fmt.Errorf("proto: ResponseTooLarge: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: RaftEntryTooLarge: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: ResponseTooLarge: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
@@ -1967,9 +3767,9 @@ func (m *RaftEntryTooLarge) Unmarshal(dAtA []byte) error {
 			}
 		case 2:
 			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field EntrySize", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field MaxResponseBytes", wireType)
 			}
-			m.EntrySize = 0
+			m.MaxResponseBytes = 0
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowErrorpb
@@ -1979,7 +3779,7 @@ func (m *RaftEntryTooLarge) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				m.EntrySize |= uint64(b&0x7F) << shift
+				m.MaxResponseBytes |= uint64(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
@@ -2394,6 +4194,294 @@ func (m *Error) Unmarshal(dAtA []byte) error {
 				return err
 			}
 			iNdEx = postIndex
+		case 11:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field GroupStopped", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowErrorpb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthErrorpb
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthErrorpb
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.GroupStopped == nil {
+				m.GroupStopped = &GroupStopped{}
+			}
+			if err := m.GroupStopped.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 12:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ShardRecovering", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowErrorpb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthErrorpb
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthErrorpb
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.ShardRecovering == nil {
+				m.ShardRecovering = &ShardRecovering{}
+			}
+			if err := m.ShardRecovering.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 13:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ReadSnapshotNotFound", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowErrorpb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthErrorpb
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthErrorpb
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.ReadSnapshotNotFound == nil {
+				m.ReadSnapshotNotFound = &ReadSnapshotNotFound{}
+			}
+			if err := m.ReadSnapshotNotFound.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 14:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field AccessDenied", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowErrorpb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthErrorpb
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthErrorpb
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.AccessDenied == nil {
+				m.AccessDenied = &AccessDenied{}
+			}
+			if err := m.AccessDenied.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 15:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field AdminTimeout", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowErrorpb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthErrorpb
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthErrorpb
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.AdminTimeout == nil {
+				m.AdminTimeout = &AdminTimeout{}
+			}
+			if err := m.AdminTimeout.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 16:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field RequestTimeout", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowErrorpb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthErrorpb
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthErrorpb
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.RequestTimeout == nil {
+				m.RequestTimeout = &RequestTimeout{}
+			}
+			if err := m.RequestTimeout.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 17:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field GroupMigrated", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowErrorpb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthErrorpb
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthErrorpb
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.GroupMigrated == nil {
+				m.GroupMigrated = &GroupMigrated{}
+			}
+			if err := m.GroupMigrated.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 18:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ResponseTooLarge", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowErrorpb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthErrorpb
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthErrorpb
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.ResponseTooLarge == nil {
+				m.ResponseTooLarge = &ResponseTooLarge{}
+			}
+			if err := m.ResponseTooLarge.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipErrorpb(dAtA[iNdEx:])
@@ -124,6 +124,10 @@ func (m *Store) SetLastHeartbeat(value int64) {
 	m.LastHeartbeatTime = value
 }
 
+func (m *Store) SetMaintenance(value bool) {
+	m.Maintenance = value
+}
+
 // ContainsKey returns true if the shard contains the key
 func (m *Shard) ContainsKey(key []byte) bool {
 	return (len(m.Start) == 0 || bytes.Compare(key, m.Start) >= 0) &&
@@ -188,6 +188,67 @@ func (ReplicaRole) EnumDescriptor() ([]byte, []int) {
 	return fileDescriptor_77b4d575d5a68dda, []int{4}
 }
 
+// OperationClass the class of the custom requests controlled by the ShardACL
+type OperationClass int32
+
+const (
+	// ReadOperation the custom read requests
+	OperationClass_ReadOperation OperationClass = 0
+	// WriteOperation the custom write requests, including the batch writes and
+	// the transaction requests
+	OperationClass_WriteOperation OperationClass = 1
+)
+
+var OperationClass_name = map[int32]string{
+	0: "ReadOperation",
+	1: "WriteOperation",
+}
+
+var OperationClass_value = map[string]int32{
+	"ReadOperation":  0,
+	"WriteOperation": 1,
+}
+
+func (x OperationClass) String() string {
+	return proto.EnumName(OperationClass_name, int32(x))
+}
+
+func (OperationClass) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_77b4d575d5a68dda, []int{5}
+}
+
+// DurabilityPolicy the policy of acknowledging the writes of the shard
+type DurabilityPolicy int32
+
+const (
+	// AllReplicas the writes are acknowledged once replicated to the majority of
+	// the voters.
+	DurabilityPolicy_AllReplicas DurabilityPolicy = 0
+	// LeaderOnlyVoter the leader is the only voter of the shard, the other
+	// replicas are the learners replicated asynchronously. The writes are
+	// acknowledged once persisted by the leader, so the acknowledged writes not
+	// replicated yet are lost if the leader is lost.
+	DurabilityPolicy_LeaderOnlyVoter DurabilityPolicy = 1
+)
+
+var DurabilityPolicy_name = map[int32]string{
+	0: "AllReplicas",
+	1: "LeaderOnlyVoter",
+}
+
+var DurabilityPolicy_value = map[string]int32{
+	"AllReplicas":     0,
+	"LeaderOnlyVoter": 1,
+}
+
+func (x DurabilityPolicy) String() string {
+	return proto.EnumName(DurabilityPolicy_name, int32(x))
+}
+
+func (DurabilityPolicy) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_77b4d575d5a68dda, []int{6}
+}
+
 // CheckPolicy check policy
 type CheckPolicy int32
 
@@ -214,7 +275,7 @@ func (x CheckPolicy) String() string {
 }
 
 func (CheckPolicy) EnumDescriptor() ([]byte, []int) {
-	return fileDescriptor_77b4d575d5a68dda, []int{5}
+	return fileDescriptor_77b4d575d5a68dda, []int{7}
 }
 
 // OperatorStatus Operator Status
@@ -249,7 +310,7 @@ func (x OperatorStatus) String() string {
 }
 
 func (OperatorStatus) EnumDescriptor() ([]byte, []int) {
-	return fileDescriptor_77b4d575d5a68dda, []int{6}
+	return fileDescriptor_77b4d575d5a68dda, []int{8}
 }
 
 // JobType job type
@@ -281,7 +342,7 @@ func (x JobType) String() string {
 }
 
 func (JobType) EnumDescriptor() ([]byte, []int) {
-	return fileDescriptor_77b4d575d5a68dda, []int{7}
+	return fileDescriptor_77b4d575d5a68dda, []int{9}
 }
 
 // JobState job state
@@ -313,7 +374,7 @@ func (x JobState) String() string {
 }
 
 func (JobState) EnumDescriptor() ([]byte, []int) {
-	return fileDescriptor_77b4d575d5a68dda, []int{8}
+	return fileDescriptor_77b4d575d5a68dda, []int{10}
 }
 
 // ReplicaState the state of the shard peer
@@ -342,7 +403,7 @@ func (x ReplicaState) String() string {
 }
 
 func (ReplicaState) EnumDescriptor() ([]byte, []int) {
-	return fileDescriptor_77b4d575d5a68dda, []int{9}
+	return fileDescriptor_77b4d575d5a68dda, []int{11}
 }
 
 // ShardsPoolCmdType shards pool cmd
@@ -368,7 +429,7 @@ func (x ShardsPoolCmdType) String() string {
 }
 
 func (ShardsPoolCmdType) EnumDescriptor() ([]byte, []int) {
-	return fileDescriptor_77b4d575d5a68dda, []int{10}
+	return fileDescriptor_77b4d575d5a68dda, []int{12}
 }
 
 // ShardEpoch shard epoch
@@ -431,13 +492,16 @@ func (m *ShardEpoch) GetGeneration() uint64 {
 
 // Replica of the shard
 type Replica struct {
-	ID                   uint64      `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
-	StoreID              uint64      `protobuf:"varint,2,opt,name=storeID,proto3" json:"storeID,omitempty"`
-	Role                 ReplicaRole `protobuf:"varint,3,opt,name=role,proto3,enum=metapb.ReplicaRole" json:"role,omitempty"`
-	InitialMember        bool        `protobuf:"varint,4,opt,name=initialMember,proto3" json:"initialMember,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}    `json:"-"`
-	XXX_unrecognized     []byte      `json:"-"`
-	XXX_sizecache        int32       `json:"-"`
+	ID            uint64      `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	StoreID       uint64      `protobuf:"varint,2,opt,name=storeID,proto3" json:"storeID,omitempty"`
+	Role          ReplicaRole `protobuf:"varint,3,opt,name=role,proto3,enum=metapb.ReplicaRole" json:"role,omitempty"`
+	InitialMember bool        `protobuf:"varint,4,opt,name=initialMember,proto3" json:"initialMember,omitempty"`
+	// IsWitness the witness replica stores the raft log but not the shard data,
+	// it votes but never serves the requests as the leader.
+	IsWitness            bool     `protobuf:"varint,5,opt,name=isWitness,proto3" json:"isWitness,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
 func (m *Replica) Reset()         { *m = Replica{} }
@@ -501,6 +565,13 @@ func (m *Replica) GetInitialMember() bool {
 	return false
 }
 
+func (m *Replica) GetIsWitness() bool {
+	if m != nil {
+		return m.IsWitness
+	}
+	return false
+}
+
 // ReplicaStats replica stats
 type ReplicaStats struct {
 	Replica              Replica  `protobuf:"bytes,1,opt,name=replica,proto3" json:"replica"`
@@ -630,10 +701,25 @@ type ShardStats struct {
 	// approximate count of keys in the shard
 	ApproximateKeys uint64 `protobuf:"varint,7,opt,name=approximateKeys,proto3" json:"approximateKeys,omitempty"`
 	// Actually reported time interval
-	Interval             *TimeInterval `protobuf:"bytes,8,opt,name=interval,proto3" json:"interval,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
-	XXX_unrecognized     []byte        `json:"-"`
-	XXX_sizecache        int32         `json:"-"`
+	Interval *TimeInterval `protobuf:"bytes,8,opt,name=interval,proto3" json:"interval,omitempty"`
+	// resolved timestamp of the shard in unix milliseconds, all writes
+	// acknowledged before it are visible to reads
+	ResolvedTS uint64 `protobuf:"varint,9,opt,name=resolvedTS,proto3" json:"resolvedTS,omitempty"`
+	// the replicas recovering from the snapshots sent by the leader
+	RecoveringReplicas []uint64 `protobuf:"varint,10,rep,packed,name=recoveringReplicas,proto3" json:"recoveringReplicas,omitempty"`
+	// raft term of the leader
+	Term uint64 `protobuf:"varint,11,opt,name=term,proto3" json:"term,omitempty"`
+	// committed index of the raft log on the leader
+	CommitIndex uint64 `protobuf:"varint,12,opt,name=commitIndex,proto3" json:"commitIndex,omitempty"`
+	// applied index of the state machine on the leader
+	AppliedIndex uint64 `protobuf:"varint,13,opt,name=appliedIndex,proto3" json:"appliedIndex,omitempty"`
+	// index of the last raft log entry truncated by the log compaction
+	TruncatedIndex uint64 `protobuf:"varint,14,opt,name=truncatedIndex,proto3" json:"truncatedIndex,omitempty"`
+	// the match indexes of the replicas tracked by the leader
+	ReplicaMatchIndexes  []ReplicaMatchIndex `protobuf:"bytes,15,rep,name=replicaMatchIndexes,proto3" json:"replicaMatchIndexes"`
+	XXX_NoUnkeyedLiteral struct{}            `json:"-"`
+	XXX_unrecognized     []byte              `json:"-"`
+	XXX_sizecache        int32               `json:"-"`
 }
 
 func (m *ShardStats) Reset()         { *m = ShardStats{} }
@@ -725,6 +811,112 @@ func (m *ShardStats) GetInterval() *TimeInterval {
 	return nil
 }
 
+func (m *ShardStats) GetResolvedTS() uint64 {
+	if m != nil {
+		return m.ResolvedTS
+	}
+	return 0
+}
+
+func (m *ShardStats) GetRecoveringReplicas() []uint64 {
+	if m != nil {
+		return m.RecoveringReplicas
+	}
+	return nil
+}
+
+func (m *ShardStats) GetTerm() uint64 {
+	if m != nil {
+		return m.Term
+	}
+	return 0
+}
+
+func (m *ShardStats) GetCommitIndex() uint64 {
+	if m != nil {
+		return m.CommitIndex
+	}
+	return 0
+}
+
+func (m *ShardStats) GetAppliedIndex() uint64 {
+	if m != nil {
+		return m.AppliedIndex
+	}
+	return 0
+}
+
+func (m *ShardStats) GetTruncatedIndex() uint64 {
+	if m != nil {
+		return m.TruncatedIndex
+	}
+	return 0
+}
+
+func (m *ShardStats) GetReplicaMatchIndexes() []ReplicaMatchIndex {
+	if m != nil {
+		return m.ReplicaMatchIndexes
+	}
+	return nil
+}
+
+// ReplicaMatchIndex the index of the last raft log entry replicated to the replica
+// known by the leader
+type ReplicaMatchIndex struct {
+	ReplicaID            uint64   `protobuf:"varint,1,opt,name=replicaID,proto3" json:"replicaID,omitempty"`
+	MatchIndex           uint64   `protobuf:"varint,2,opt,name=matchIndex,proto3" json:"matchIndex,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ReplicaMatchIndex) Reset()         { *m = ReplicaMatchIndex{} }
+func (m *ReplicaMatchIndex) String() string { return proto.CompactTextString(m) }
+func (*ReplicaMatchIndex) ProtoMessage()    {}
+func (*ReplicaMatchIndex) Descriptor() ([]byte, []int) {
+	return fileDescriptor_77b4d575d5a68dda, []int{5}
+}
+func (m *ReplicaMatchIndex) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *ReplicaMatchIndex) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_ReplicaMatchIndex.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalTo(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *ReplicaMatchIndex) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ReplicaMatchIndex.Merge(m, src)
+}
+func (m *ReplicaMatchIndex) XXX_Size() int {
+	return m.Size()
+}
+func (m *ReplicaMatchIndex) XXX_DiscardUnknown() {
+	xxx_messageInfo_ReplicaMatchIndex.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ReplicaMatchIndex proto.InternalMessageInfo
+
+func (m *ReplicaMatchIndex) GetReplicaID() uint64 {
+	if m != nil {
+		return m.ReplicaID
+	}
+	return 0
+}
+
+func (m *ReplicaMatchIndex) GetMatchIndex() uint64 {
+	if m != nil {
+		return m.MatchIndex
+	}
+	return 0
+}
+
 // StoreStats store stats
 type StoreStats struct {
 	// Store id
@@ -764,17 +956,27 @@ type StoreStats struct {
 	// Threads' write disk I/O rates in the store
 	WriteIORates []RecordPair `protobuf:"bytes,18,rep,name=writeIORates,proto3" json:"writeIORates"`
 	// Operations' latencies in the store
-	OpLatencies          []RecordPair `protobuf:"bytes,19,rep,name=opLatencies,proto3" json:"opLatencies"`
-	XXX_NoUnkeyedLiteral struct{}     `json:"-"`
-	XXX_unrecognized     []byte       `json:"-"`
-	XXX_sizecache        int32        `json:"-"`
+	OpLatencies []RecordPair `protobuf:"bytes,19,rep,name=opLatencies,proto3" json:"opLatencies"`
+	// Soft limit of the leader count, the excess leaders are shed to other stores. 0 means no limit.
+	LeaderSoftLimit uint64 `protobuf:"varint,20,opt,name=leaderSoftLimit,proto3" json:"leaderSoftLimit,omitempty"`
+	// The shard groups stopped on the store
+	StoppedGroups []uint64 `protobuf:"varint,21,rep,packed,name=stoppedGroups,proto3" json:"stoppedGroups,omitempty"`
+	// If the snapshot sending of the store is throttled by the rate limit
+	SnapshotThrottled bool `protobuf:"varint,22,opt,name=snapshotThrottled,proto3" json:"snapshotThrottled,omitempty"`
+	// The snapshots being sent or received by the store
+	SnapshotProgresses []SnapshotProgress `protobuf:"bytes,23,rep,name=snapshotProgresses,proto3" json:"snapshotProgresses"`
+	// If the store is in the maintenance mode, set by the prophet
+	Maintenance          bool     `protobuf:"varint,24,opt,name=maintenance,proto3" json:"maintenance,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
 func (m *StoreStats) Reset()         { *m = StoreStats{} }
 func (m *StoreStats) String() string { return proto.CompactTextString(m) }
 func (*StoreStats) ProtoMessage()    {}
 func (*StoreStats) Descriptor() ([]byte, []int) {
-	return fileDescriptor_77b4d575d5a68dda, []int{5}
+	return fileDescriptor_77b4d575d5a68dda, []int{6}
 }
 func (m *StoreStats) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -936,6 +1138,184 @@ func (m *StoreStats) GetOpLatencies() []RecordPair {
 	return nil
 }
 
+func (m *StoreStats) GetLeaderSoftLimit() uint64 {
+	if m != nil {
+		return m.LeaderSoftLimit
+	}
+	return 0
+}
+
+func (m *StoreStats) GetStoppedGroups() []uint64 {
+	if m != nil {
+		return m.StoppedGroups
+	}
+	return nil
+}
+
+func (m *StoreStats) GetSnapshotThrottled() bool {
+	if m != nil {
+		return m.SnapshotThrottled
+	}
+	return false
+}
+
+func (m *StoreStats) GetSnapshotProgresses() []SnapshotProgress {
+	if m != nil {
+		return m.SnapshotProgresses
+	}
+	return nil
+}
+
+func (m *StoreStats) GetMaintenance() bool {
+	if m != nil {
+		return m.Maintenance
+	}
+	return false
+}
+
+// SnapshotProgress the progress of a snapshot being sent or received by a store
+type SnapshotProgress struct {
+	// StoreID the store sending or receiving the snapshot
+	StoreID uint64  `protobuf:"varint,1,opt,name=storeID,proto3" json:"storeID,omitempty"`
+	ShardID uint64  `protobuf:"varint,2,opt,name=shardID,proto3" json:"shardID,omitempty"`
+	From    Replica `protobuf:"bytes,3,opt,name=from,proto3" json:"from"`
+	To      Replica `protobuf:"bytes,4,opt,name=to,proto3" json:"to"`
+	Index   uint64  `protobuf:"varint,5,opt,name=index,proto3" json:"index,omitempty"`
+	// Sending is true if the snapshot is sent by the store, false if received
+	Sending          bool   `protobuf:"varint,6,opt,name=sending,proto3" json:"sending,omitempty"`
+	TotalBytes       uint64 `protobuf:"varint,7,opt,name=totalBytes,proto3" json:"totalBytes,omitempty"`
+	TransferredBytes uint64 `protobuf:"varint,8,opt,name=transferredBytes,proto3" json:"transferredBytes,omitempty"`
+	// StartTime unix timestamp in seconds of the transfer started
+	StartTime int64 `protobuf:"varint,9,opt,name=startTime,proto3" json:"startTime,omitempty"`
+	// Rate the average transfer rate in bytes per second
+	Rate uint64 `protobuf:"varint,10,opt,name=rate,proto3" json:"rate,omitempty"`
+	// ETA the estimated seconds to finish the transfer, 0 if unknown
+	ETA uint64 `protobuf:"varint,11,opt,name=eta,proto3" json:"eta,omitempty"`
+	// Operator the description of the prophet operator of the shard which the
+	// snapshot is sent for, only set by the prophet
+	Operator             string   `protobuf:"bytes,12,opt,name=operator,proto3" json:"operator,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SnapshotProgress) Reset()         { *m = SnapshotProgress{} }
+func (m *SnapshotProgress) String() string { return proto.CompactTextString(m) }
+func (*SnapshotProgress) ProtoMessage()    {}
+func (*SnapshotProgress) Descriptor() ([]byte, []int) {
+	return fileDescriptor_77b4d575d5a68dda, []int{7}
+}
+func (m *SnapshotProgress) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *SnapshotProgress) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_SnapshotProgress.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalTo(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *SnapshotProgress) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SnapshotProgress.Merge(m, src)
+}
+func (m *SnapshotProgress) XXX_Size() int {
+	return m.Size()
+}
+func (m *SnapshotProgress) XXX_DiscardUnknown() {
+	xxx_messageInfo_SnapshotProgress.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SnapshotProgress proto.InternalMessageInfo
+
+func (m *SnapshotProgress) GetStoreID() uint64 {
+	if m != nil {
+		return m.StoreID
+	}
+	return 0
+}
+
+func (m *SnapshotProgress) GetShardID() uint64 {
+	if m != nil {
+		return m.ShardID
+	}
+	return 0
+}
+
+func (m *SnapshotProgress) GetFrom() Replica {
+	if m != nil {
+		return m.From
+	}
+	return Replica{}
+}
+
+func (m *SnapshotProgress) GetTo() Replica {
+	if m != nil {
+		return m.To
+	}
+	return Replica{}
+}
+
+func (m *SnapshotProgress) GetIndex() uint64 {
+	if m != nil {
+		return m.Index
+	}
+	return 0
+}
+
+func (m *SnapshotProgress) GetSending() bool {
+	if m != nil {
+		return m.Sending
+	}
+	return false
+}
+
+func (m *SnapshotProgress) GetTotalBytes() uint64 {
+	if m != nil {
+		return m.TotalBytes
+	}
+	return 0
+}
+
+func (m *SnapshotProgress) GetTransferredBytes() uint64 {
+	if m != nil {
+		return m.TransferredBytes
+	}
+	return 0
+}
+
+func (m *SnapshotProgress) GetStartTime() int64 {
+	if m != nil {
+		return m.StartTime
+	}
+	return 0
+}
+
+func (m *SnapshotProgress) GetRate() uint64 {
+	if m != nil {
+		return m.Rate
+	}
+	return 0
+}
+
+func (m *SnapshotProgress) GetETA() uint64 {
+	if m != nil {
+		return m.ETA
+	}
+	return 0
+}
+
+func (m *SnapshotProgress) GetOperator() string {
+	if m != nil {
+		return m.Operator
+	}
+	return ""
+}
+
 // RecordPair record pair
 type RecordPair struct {
 	Key                  string   `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
@@ -949,7 +1329,7 @@ func (m *RecordPair) Reset()         { *m = RecordPair{} }
 func (m *RecordPair) String() string { return proto.CompactTextString(m) }
 func (*RecordPair) ProtoMessage()    {}
 func (*RecordPair) Descriptor() ([]byte, []int) {
-	return fileDescriptor_77b4d575d5a68dda, []int{6}
+	return fileDescriptor_77b4d575d5a68dda, []int{8}
 }
 func (m *RecordPair) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1006,7 +1386,7 @@ func (m *Member) Reset()         { *m = Member{} }
 func (m *Member) String() string { return proto.CompactTextString(m) }
 func (*Member) ProtoMessage()    {}
 func (*Member) Descriptor() ([]byte, []int) {
-	return fileDescriptor_77b4d575d5a68dda, []int{7}
+	return fileDescriptor_77b4d575d5a68dda, []int{9}
 }
 func (m *Member) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1069,7 +1449,7 @@ func (m *ProphetCluster) Reset()         { *m = ProphetCluster{} }
 func (m *ProphetCluster) String() string { return proto.CompactTextString(m) }
 func (*ProphetCluster) ProtoMessage()    {}
 func (*ProphetCluster) Descriptor() ([]byte, []int) {
-	return fileDescriptor_77b4d575d5a68dda, []int{8}
+	return fileDescriptor_77b4d575d5a68dda, []int{10}
 }
 func (m *ProphetCluster) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1127,7 +1507,7 @@ func (m *TimeInterval) Reset()         { *m = TimeInterval{} }
 func (m *TimeInterval) String() string { return proto.CompactTextString(m) }
 func (*TimeInterval) ProtoMessage()    {}
 func (*TimeInterval) Descriptor() ([]byte, []int) {
-	return fileDescriptor_77b4d575d5a68dda, []int{9}
+	return fileDescriptor_77b4d575d5a68dda, []int{11}
 }
 func (m *TimeInterval) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1184,7 +1564,7 @@ func (m *Job) Reset()         { *m = Job{} }
 func (m *Job) String() string { return proto.CompactTextString(m) }
 func (*Job) ProtoMessage()    {}
 func (*Job) Descriptor() ([]byte, []int) {
-	return fileDescriptor_77b4d575d5a68dda, []int{10}
+	return fileDescriptor_77b4d575d5a68dda, []int{12}
 }
 func (m *Job) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1247,7 +1627,7 @@ func (m *RemoveShardJob) Reset()         { *m = RemoveShardJob{} }
 func (m *RemoveShardJob) String() string { return proto.CompactTextString(m) }
 func (*RemoveShardJob) ProtoMessage()    {}
 func (*RemoveShardJob) Descriptor() ([]byte, []int) {
-	return fileDescriptor_77b4d575d5a68dda, []int{11}
+	return fileDescriptor_77b4d575d5a68dda, []int{13}
 }
 func (m *RemoveShardJob) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1302,7 +1682,7 @@ func (m *ShardPoolJob) Reset()         { *m = ShardPoolJob{} }
 func (m *ShardPoolJob) String() string { return proto.CompactTextString(m) }
 func (*ShardPoolJob) ProtoMessage()    {}
 func (*ShardPoolJob) Descriptor() ([]byte, []int) {
-	return fileDescriptor_77b4d575d5a68dda, []int{12}
+	return fileDescriptor_77b4d575d5a68dda, []int{14}
 }
 func (m *ShardPoolJob) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1352,7 +1732,7 @@ func (m *ShardPoolJobMeta) Reset()         { *m = ShardPoolJobMeta{} }
 func (m *ShardPoolJobMeta) String() string { return proto.CompactTextString(m) }
 func (*ShardPoolJobMeta) ProtoMessage()    {}
 func (*ShardPoolJobMeta) Descriptor() ([]byte, []int) {
-	return fileDescriptor_77b4d575d5a68dda, []int{13}
+	return fileDescriptor_77b4d575d5a68dda, []int{15}
 }
 func (m *ShardPoolJobMeta) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1404,20 +1784,24 @@ func (m *ShardPoolJobMeta) GetRangePrefix() []byte {
 
 // DestroyingStatus destroying status
 type DestroyingStatus struct {
-	Index                uint64          `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`
-	Replicas             map[uint64]bool `protobuf:"bytes,2,rep,name=replicas,proto3" json:"replicas,omitempty" protobuf_key:"varint,1,opt,name=key,proto3" protobuf_val:"varint,2,opt,name=value,proto3"`
-	State                ShardState      `protobuf:"varint,3,opt,name=state,proto3,enum=metapb.ShardState" json:"state,omitempty"`
-	RemoveData           bool            `protobuf:"varint,4,opt,name=removeData,proto3" json:"removeData,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}        `json:"-"`
-	XXX_unrecognized     []byte          `json:"-"`
-	XXX_sizecache        int32           `json:"-"`
+	Index      uint64          `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`
+	Replicas   map[uint64]bool `protobuf:"bytes,2,rep,name=replicas,proto3" json:"replicas,omitempty" protobuf_key:"varint,1,opt,name=key,proto3" protobuf_val:"varint,2,opt,name=value,proto3"`
+	State      ShardState      `protobuf:"varint,3,opt,name=state,proto3,enum=metapb.ShardState" json:"state,omitempty"`
+	RemoveData bool            `protobuf:"varint,4,opt,name=removeData,proto3" json:"removeData,omitempty"`
+	// createdAt is the unix seconds when the destroying is created
+	CreatedAt int64 `protobuf:"varint,5,opt,name=createdAt,proto3" json:"createdAt,omitempty"`
+	// updatedAt is the unix seconds of the last replica confirmation
+	UpdatedAt            int64    `protobuf:"varint,6,opt,name=updatedAt,proto3" json:"updatedAt,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
 func (m *DestroyingStatus) Reset()         { *m = DestroyingStatus{} }
 func (m *DestroyingStatus) String() string { return proto.CompactTextString(m) }
 func (*DestroyingStatus) ProtoMessage()    {}
 func (*DestroyingStatus) Descriptor() ([]byte, []int) {
-	return fileDescriptor_77b4d575d5a68dda, []int{14}
+	return fileDescriptor_77b4d575d5a68dda, []int{16}
 }
 func (m *DestroyingStatus) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1474,6 +1858,20 @@ func (m *DestroyingStatus) GetRemoveData() bool {
 	return false
 }
 
+func (m *DestroyingStatus) GetCreatedAt() int64 {
+	if m != nil {
+		return m.CreatedAt
+	}
+	return 0
+}
+
+func (m *DestroyingStatus) GetUpdatedAt() int64 {
+	if m != nil {
+		return m.UpdatedAt
+	}
+	return 0
+}
+
 // ShardExtra shard extra
 type ShardExtra struct {
 	Labels               map[string]string `protobuf:"bytes,1,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
@@ -1487,7 +1885,7 @@ func (m *ShardExtra) Reset()         { *m = ShardExtra{} }
 func (m *ShardExtra) String() string { return proto.CompactTextString(m) }
 func (*ShardExtra) ProtoMessage()    {}
 func (*ShardExtra) Descriptor() ([]byte, []int) {
-	return fileDescriptor_77b4d575d5a68dda, []int{15}
+	return fileDescriptor_77b4d575d5a68dda, []int{17}
 }
 func (m *ShardExtra) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1545,7 +1943,7 @@ func (m *ScheduleGroupRule) Reset()         { *m = ScheduleGroupRule{} }
 func (m *ScheduleGroupRule) String() string { return proto.CompactTextString(m) }
 func (*ScheduleGroupRule) ProtoMessage()    {}
 func (*ScheduleGroupRule) Descriptor() ([]byte, []int) {
-	return fileDescriptor_77b4d575d5a68dda, []int{16}
+	return fileDescriptor_77b4d575d5a68dda, []int{18}
 }
 func (m *ScheduleGroupRule) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1602,19 +2000,187 @@ func (m *ScheduleGroupRule) GetGroupByLabel() string {
 	return ""
 }
 
+// RangeRelocation relocates all the replicas of the shards of the group which
+// overlap the key range [start, end) to the target stores, empty end means no
+// upper bound.
+type RangeRelocation struct {
+	ID                   uint64   `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Group                uint64   `protobuf:"varint,2,opt,name=group,proto3" json:"group,omitempty"`
+	Start                []byte   `protobuf:"bytes,3,opt,name=start,proto3" json:"start,omitempty"`
+	End                  []byte   `protobuf:"bytes,4,opt,name=end,proto3" json:"end,omitempty"`
+	Stores               []uint64 `protobuf:"varint,5,rep,packed,name=stores,proto3" json:"stores,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RangeRelocation) Reset()         { *m = RangeRelocation{} }
+func (m *RangeRelocation) String() string { return proto.CompactTextString(m) }
+func (*RangeRelocation) ProtoMessage()    {}
+func (*RangeRelocation) Descriptor() ([]byte, []int) {
+	return fileDescriptor_77b4d575d5a68dda, []int{19}
+}
+func (m *RangeRelocation) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *RangeRelocation) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_RangeRelocation.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalTo(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *RangeRelocation) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RangeRelocation.Merge(m, src)
+}
+func (m *RangeRelocation) XXX_Size() int {
+	return m.Size()
+}
+func (m *RangeRelocation) XXX_DiscardUnknown() {
+	xxx_messageInfo_RangeRelocation.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RangeRelocation proto.InternalMessageInfo
+
+func (m *RangeRelocation) GetID() uint64 {
+	if m != nil {
+		return m.ID
+	}
+	return 0
+}
+
+func (m *RangeRelocation) GetGroup() uint64 {
+	if m != nil {
+		return m.Group
+	}
+	return 0
+}
+
+func (m *RangeRelocation) GetStart() []byte {
+	if m != nil {
+		return m.Start
+	}
+	return nil
+}
+
+func (m *RangeRelocation) GetEnd() []byte {
+	if m != nil {
+		return m.End
+	}
+	return nil
+}
+
+func (m *RangeRelocation) GetStores() []uint64 {
+	if m != nil {
+		return m.Stores
+	}
+	return nil
+}
+
+// LabelSteering keeps the labels added to all the shards of the group which
+// overlap the key range [start, end), including the shards created in the range
+// later, e.g. the labels steering the shards to the data storage of the store.
+// Empty end means no upper bound.
+type LabelSteering struct {
+	ID                   uint64   `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Group                uint64   `protobuf:"varint,2,opt,name=group,proto3" json:"group,omitempty"`
+	Start                []byte   `protobuf:"bytes,3,opt,name=start,proto3" json:"start,omitempty"`
+	End                  []byte   `protobuf:"bytes,4,opt,name=end,proto3" json:"end,omitempty"`
+	Labels               []Label  `protobuf:"bytes,5,rep,name=labels,proto3" json:"labels"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *LabelSteering) Reset()         { *m = LabelSteering{} }
+func (m *LabelSteering) String() string { return proto.CompactTextString(m) }
+func (*LabelSteering) ProtoMessage()    {}
+func (*LabelSteering) Descriptor() ([]byte, []int) {
+	return fileDescriptor_77b4d575d5a68dda, []int{20}
+}
+func (m *LabelSteering) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *LabelSteering) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_LabelSteering.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalTo(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *LabelSteering) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_LabelSteering.Merge(m, src)
+}
+func (m *LabelSteering) XXX_Size() int {
+	return m.Size()
+}
+func (m *LabelSteering) XXX_DiscardUnknown() {
+	xxx_messageInfo_LabelSteering.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_LabelSteering proto.InternalMessageInfo
+
+func (m *LabelSteering) GetID() uint64 {
+	if m != nil {
+		return m.ID
+	}
+	return 0
+}
+
+func (m *LabelSteering) GetGroup() uint64 {
+	if m != nil {
+		return m.Group
+	}
+	return 0
+}
+
+func (m *LabelSteering) GetStart() []byte {
+	if m != nil {
+		return m.Start
+	}
+	return nil
+}
+
+func (m *LabelSteering) GetEnd() []byte {
+	if m != nil {
+		return m.End
+	}
+	return nil
+}
+
+func (m *LabelSteering) GetLabels() []Label {
+	if m != nil {
+		return m.Labels
+	}
+	return nil
+}
+
 // RaftMessageBatch is a group of messages sent to the same store.
 type RaftMessageBatch struct {
-	Messages             []RaftMessage `protobuf:"bytes,1,rep,name=messages,proto3" json:"messages"`
-	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
-	XXX_unrecognized     []byte        `json:"-"`
-	XXX_sizecache        int32         `json:"-"`
+	Messages []RaftMessage `protobuf:"bytes,1,rep,name=messages,proto3" json:"messages"`
+	// Heartbeats the coalesced heartbeats of all the shards shared by the two
+	// stores, expanded back into the raft messages by the receiver
+	Heartbeats           []RaftHeartbeat `protobuf:"bytes,2,rep,name=heartbeats,proto3" json:"heartbeats"`
+	XXX_NoUnkeyedLiteral struct{}        `json:"-"`
+	XXX_unrecognized     []byte          `json:"-"`
+	XXX_sizecache        int32           `json:"-"`
 }
 
 func (m *RaftMessageBatch) Reset()         { *m = RaftMessageBatch{} }
 func (m *RaftMessageBatch) String() string { return proto.CompactTextString(m) }
 func (*RaftMessageBatch) ProtoMessage()    {}
 func (*RaftMessageBatch) Descriptor() ([]byte, []int) {
-	return fileDescriptor_77b4d575d5a68dda, []int{17}
+	return fileDescriptor_77b4d575d5a68dda, []int{21}
 }
 func (m *RaftMessageBatch) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1650,31 +2216,194 @@ func (m *RaftMessageBatch) GetMessages() []RaftMessage {
 	return nil
 }
 
+func (m *RaftMessageBatch) GetHeartbeats() []RaftHeartbeat {
+	if m != nil {
+		return m.Heartbeats
+	}
+	return nil
+}
+
+// RaftHeartbeat is the compact form of a MsgHeartbeat or MsgHeartbeatResp
+// RaftMessage, carrying only the fields used by the heartbeats.
+type RaftHeartbeat struct {
+	ShardID uint64  `protobuf:"varint,1,opt,name=shardID,proto3" json:"shardID,omitempty"`
+	Group   uint64  `protobuf:"varint,2,opt,name=group,proto3" json:"group,omitempty"`
+	From    Replica `protobuf:"bytes,3,opt,name=from,proto3" json:"from"`
+	To      Replica `protobuf:"bytes,4,opt,name=to,proto3" json:"to"`
+	// Response is true for MsgHeartbeatResp
+	Response             bool       `protobuf:"varint,5,opt,name=response,proto3" json:"response,omitempty"`
+	Term                 uint64     `protobuf:"varint,6,opt,name=term,proto3" json:"term,omitempty"`
+	Commit               uint64     `protobuf:"varint,7,opt,name=commit,proto3" json:"commit,omitempty"`
+	ShardEpoch           ShardEpoch `protobuf:"bytes,8,opt,name=shardEpoch,proto3" json:"shardEpoch"`
+	CommitIndex          uint64     `protobuf:"varint,9,opt,name=commitIndex,proto3" json:"commitIndex,omitempty"`
+	AppliedIndex         uint64     `protobuf:"varint,10,opt,name=appliedIndex,proto3" json:"appliedIndex,omitempty"`
+	ResolvedTS           uint64     `protobuf:"varint,11,opt,name=resolvedTS,proto3" json:"resolvedTS,omitempty"`
+	ResolvedIndex        uint64     `protobuf:"varint,12,opt,name=resolvedIndex,proto3" json:"resolvedIndex,omitempty"`
+	SendTime             uint64     `protobuf:"varint,13,opt,name=sendTime,proto3" json:"sendTime,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}   `json:"-"`
+	XXX_unrecognized     []byte     `json:"-"`
+	XXX_sizecache        int32      `json:"-"`
+}
+
+func (m *RaftHeartbeat) Reset()         { *m = RaftHeartbeat{} }
+func (m *RaftHeartbeat) String() string { return proto.CompactTextString(m) }
+func (*RaftHeartbeat) ProtoMessage()    {}
+func (*RaftHeartbeat) Descriptor() ([]byte, []int) {
+	return fileDescriptor_77b4d575d5a68dda, []int{22}
+}
+func (m *RaftHeartbeat) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *RaftHeartbeat) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_RaftHeartbeat.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalTo(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *RaftHeartbeat) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RaftHeartbeat.Merge(m, src)
+}
+func (m *RaftHeartbeat) XXX_Size() int {
+	return m.Size()
+}
+func (m *RaftHeartbeat) XXX_DiscardUnknown() {
+	xxx_messageInfo_RaftHeartbeat.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RaftHeartbeat proto.InternalMessageInfo
+
+func (m *RaftHeartbeat) GetShardID() uint64 {
+	if m != nil {
+		return m.ShardID
+	}
+	return 0
+}
+
+func (m *RaftHeartbeat) GetGroup() uint64 {
+	if m != nil {
+		return m.Group
+	}
+	return 0
+}
+
+func (m *RaftHeartbeat) GetFrom() Replica {
+	if m != nil {
+		return m.From
+	}
+	return Replica{}
+}
+
+func (m *RaftHeartbeat) GetTo() Replica {
+	if m != nil {
+		return m.To
+	}
+	return Replica{}
+}
+
+func (m *RaftHeartbeat) GetResponse() bool {
+	if m != nil {
+		return m.Response
+	}
+	return false
+}
+
+func (m *RaftHeartbeat) GetTerm() uint64 {
+	if m != nil {
+		return m.Term
+	}
+	return 0
+}
+
+func (m *RaftHeartbeat) GetCommit() uint64 {
+	if m != nil {
+		return m.Commit
+	}
+	return 0
+}
+
+func (m *RaftHeartbeat) GetShardEpoch() ShardEpoch {
+	if m != nil {
+		return m.ShardEpoch
+	}
+	return ShardEpoch{}
+}
+
+func (m *RaftHeartbeat) GetCommitIndex() uint64 {
+	if m != nil {
+		return m.CommitIndex
+	}
+	return 0
+}
+
+func (m *RaftHeartbeat) GetAppliedIndex() uint64 {
+	if m != nil {
+		return m.AppliedIndex
+	}
+	return 0
+}
+
+func (m *RaftHeartbeat) GetResolvedTS() uint64 {
+	if m != nil {
+		return m.ResolvedTS
+	}
+	return 0
+}
+
+func (m *RaftHeartbeat) GetResolvedIndex() uint64 {
+	if m != nil {
+		return m.ResolvedIndex
+	}
+	return 0
+}
+
+func (m *RaftHeartbeat) GetSendTime() uint64 {
+	if m != nil {
+		return m.SendTime
+	}
+	return 0
+}
+
 // RaftMessage the message wrapped raft msg with shard info
 type RaftMessage struct {
-	ShardID              uint64         `protobuf:"varint,1,opt,name=shardID,proto3" json:"shardID,omitempty"`
-	Group                uint64         `protobuf:"varint,2,opt,name=group,proto3" json:"group,omitempty"`
-	From                 Replica        `protobuf:"bytes,3,opt,name=from,proto3" json:"from"`
-	To                   Replica        `protobuf:"bytes,4,opt,name=to,proto3" json:"to"`
-	Message              raftpb.Message `protobuf:"bytes,5,opt,name=message,proto3" json:"message"`
-	ShardEpoch           ShardEpoch     `protobuf:"bytes,6,opt,name=shardEpoch,proto3" json:"shardEpoch"`
-	IsTombstone          bool           `protobuf:"varint,7,opt,name=isTombstone,proto3" json:"isTombstone,omitempty"`
-	Start                []byte         `protobuf:"bytes,8,opt,name=start,proto3" json:"start,omitempty"`
-	End                  []byte         `protobuf:"bytes,9,opt,name=end,proto3" json:"end,omitempty"`
-	Unique               string         `protobuf:"bytes,10,opt,name=unique,proto3" json:"unique,omitempty"`
-	RuleGroups           []string       `protobuf:"bytes,11,rep,name=ruleGroups,proto3" json:"ruleGroups,omitempty"`
-	CommitIndex          uint64         `protobuf:"varint,12,opt,name=commitIndex,proto3" json:"commitIndex,omitempty"`
-	SendTime             uint64         `protobuf:"varint,13,opt,name=sendTime,proto3" json:"sendTime,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}       `json:"-"`
-	XXX_unrecognized     []byte         `json:"-"`
-	XXX_sizecache        int32          `json:"-"`
+	ShardID     uint64         `protobuf:"varint,1,opt,name=shardID,proto3" json:"shardID,omitempty"`
+	Group       uint64         `protobuf:"varint,2,opt,name=group,proto3" json:"group,omitempty"`
+	From        Replica        `protobuf:"bytes,3,opt,name=from,proto3" json:"from"`
+	To          Replica        `protobuf:"bytes,4,opt,name=to,proto3" json:"to"`
+	Message     raftpb.Message `protobuf:"bytes,5,opt,name=message,proto3" json:"message"`
+	ShardEpoch  ShardEpoch     `protobuf:"bytes,6,opt,name=shardEpoch,proto3" json:"shardEpoch"`
+	IsTombstone bool           `protobuf:"varint,7,opt,name=isTombstone,proto3" json:"isTombstone,omitempty"`
+	Start       []byte         `protobuf:"bytes,8,opt,name=start,proto3" json:"start,omitempty"`
+	End         []byte         `protobuf:"bytes,9,opt,name=end,proto3" json:"end,omitempty"`
+	Unique      string         `protobuf:"bytes,10,opt,name=unique,proto3" json:"unique,omitempty"`
+	RuleGroups  []string       `protobuf:"bytes,11,rep,name=ruleGroups,proto3" json:"ruleGroups,omitempty"`
+	CommitIndex uint64         `protobuf:"varint,12,opt,name=commitIndex,proto3" json:"commitIndex,omitempty"`
+	SendTime    uint64         `protobuf:"varint,13,opt,name=sendTime,proto3" json:"sendTime,omitempty"`
+	// ResolvedTS and ResolvedIndex are sent by the leader, all writes acknowledged
+	// before ResolvedTS (unix milliseconds) are at or below ResolvedIndex
+	ResolvedTS    uint64 `protobuf:"varint,14,opt,name=resolvedTS,proto3" json:"resolvedTS,omitempty"`
+	ResolvedIndex uint64 `protobuf:"varint,15,opt,name=resolvedIndex,proto3" json:"resolvedIndex,omitempty"`
+	// WarmupKeys the hot keys of the shard shipped by the outgoing leader to the
+	// transfer target along with the MsgTimeoutNow
+	WarmupKeys [][]byte `protobuf:"bytes,16,rep,name=warmupKeys,proto3" json:"warmupKeys,omitempty"`
+	// AppliedIndex the applied index of the sender, the leader uses it as the
+	// base index of the delta snapshots sent to the replica
+	AppliedIndex         uint64   `protobuf:"varint,17,opt,name=appliedIndex,proto3" json:"appliedIndex,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
 func (m *RaftMessage) Reset()         { *m = RaftMessage{} }
 func (m *RaftMessage) String() string { return proto.CompactTextString(m) }
 func (*RaftMessage) ProtoMessage()    {}
 func (*RaftMessage) Descriptor() ([]byte, []int) {
-	return fileDescriptor_77b4d575d5a68dda, []int{18}
+	return fileDescriptor_77b4d575d5a68dda, []int{23}
 }
 func (m *RaftMessage) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1794,33 +2523,64 @@ func (m *RaftMessage) GetSendTime() uint64 {
 	return 0
 }
 
-type SnapshotChunk struct {
-	StoreID              uint64           `protobuf:"varint,1,opt,name=storeID,proto3" json:"storeID,omitempty"`
-	ShardID              uint64           `protobuf:"varint,2,opt,name=shardID,proto3" json:"shardID,omitempty"`
-	ReplicaID            uint64           `protobuf:"varint,3,opt,name=replicaID,proto3" json:"replicaID,omitempty"`
-	From                 uint64           `protobuf:"varint,4,opt,name=from,proto3" json:"from,omitempty"`
-	ChunkID              uint64           `protobuf:"varint,5,opt,name=chunkID,proto3" json:"chunkID,omitempty"`
-	ChunkSize            uint64           `protobuf:"varint,6,opt,name=chunkSize,proto3" json:"chunkSize,omitempty"`
-	ChunkCount           uint64           `protobuf:"varint,7,opt,name=chunkCount,proto3" json:"chunkCount,omitempty"`
-	Index                uint64           `protobuf:"varint,8,opt,name=index,proto3" json:"index,omitempty"`
-	Term                 uint64           `protobuf:"varint,9,opt,name=term,proto3" json:"term,omitempty"`
-	FilePath             string           `protobuf:"bytes,10,opt,name=filePath,proto3" json:"filePath,omitempty"`
-	FileSize             uint64           `protobuf:"varint,11,opt,name=fileSize,proto3" json:"fileSize,omitempty"`
-	FileChunkID          uint64           `protobuf:"varint,12,opt,name=fileChunkID,proto3" json:"fileChunkID,omitempty"`
-	FileChunkCount       uint64           `protobuf:"varint,13,opt,name=fileChunkCount,proto3" json:"fileChunkCount,omitempty"`
-	Data                 []byte           `protobuf:"bytes,14,opt,name=data,proto3" json:"data,omitempty"`
-	Extra                []byte           `protobuf:"bytes,15,opt,name=extra,proto3" json:"extra,omitempty"`
-	ConfState            raftpb.ConfState `protobuf:"bytes,16,opt,name=confState,proto3" json:"confState"`
-	XXX_NoUnkeyedLiteral struct{}         `json:"-"`
-	XXX_unrecognized     []byte           `json:"-"`
-	XXX_sizecache        int32            `json:"-"`
+func (m *RaftMessage) GetResolvedTS() uint64 {
+	if m != nil {
+		return m.ResolvedTS
+	}
+	return 0
+}
+
+func (m *RaftMessage) GetResolvedIndex() uint64 {
+	if m != nil {
+		return m.ResolvedIndex
+	}
+	return 0
+}
+
+func (m *RaftMessage) GetWarmupKeys() [][]byte {
+	if m != nil {
+		return m.WarmupKeys
+	}
+	return nil
+}
+
+func (m *RaftMessage) GetAppliedIndex() uint64 {
+	if m != nil {
+		return m.AppliedIndex
+	}
+	return 0
+}
+
+type SnapshotChunk struct {
+	StoreID        uint64           `protobuf:"varint,1,opt,name=storeID,proto3" json:"storeID,omitempty"`
+	ShardID        uint64           `protobuf:"varint,2,opt,name=shardID,proto3" json:"shardID,omitempty"`
+	ReplicaID      uint64           `protobuf:"varint,3,opt,name=replicaID,proto3" json:"replicaID,omitempty"`
+	From           uint64           `protobuf:"varint,4,opt,name=from,proto3" json:"from,omitempty"`
+	ChunkID        uint64           `protobuf:"varint,5,opt,name=chunkID,proto3" json:"chunkID,omitempty"`
+	ChunkSize      uint64           `protobuf:"varint,6,opt,name=chunkSize,proto3" json:"chunkSize,omitempty"`
+	ChunkCount     uint64           `protobuf:"varint,7,opt,name=chunkCount,proto3" json:"chunkCount,omitempty"`
+	Index          uint64           `protobuf:"varint,8,opt,name=index,proto3" json:"index,omitempty"`
+	Term           uint64           `protobuf:"varint,9,opt,name=term,proto3" json:"term,omitempty"`
+	FilePath       string           `protobuf:"bytes,10,opt,name=filePath,proto3" json:"filePath,omitempty"`
+	FileSize       uint64           `protobuf:"varint,11,opt,name=fileSize,proto3" json:"fileSize,omitempty"`
+	FileChunkID    uint64           `protobuf:"varint,12,opt,name=fileChunkID,proto3" json:"fileChunkID,omitempty"`
+	FileChunkCount uint64           `protobuf:"varint,13,opt,name=fileChunkCount,proto3" json:"fileChunkCount,omitempty"`
+	Data           []byte           `protobuf:"bytes,14,opt,name=data,proto3" json:"data,omitempty"`
+	Extra          []byte           `protobuf:"bytes,15,opt,name=extra,proto3" json:"extra,omitempty"`
+	ConfState      raftpb.ConfState `protobuf:"bytes,16,opt,name=confState,proto3" json:"confState"`
+	// SnapshotSize the total bytes of the snapshot image
+	SnapshotSize         uint64   `protobuf:"varint,17,opt,name=snapshotSize,proto3" json:"snapshotSize,omitempty"`
+	FromStoreID          uint64   `protobuf:"varint,18,opt,name=fromStoreID,proto3" json:"fromStoreID,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
 func (m *SnapshotChunk) Reset()         { *m = SnapshotChunk{} }
 func (m *SnapshotChunk) String() string { return proto.CompactTextString(m) }
 func (*SnapshotChunk) ProtoMessage()    {}
 func (*SnapshotChunk) Descriptor() ([]byte, []int) {
-	return fileDescriptor_77b4d575d5a68dda, []int{19}
+	return fileDescriptor_77b4d575d5a68dda, []int{24}
 }
 func (m *SnapshotChunk) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1961,6 +2721,20 @@ func (m *SnapshotChunk) GetConfState() raftpb.ConfState {
 	return raftpb.ConfState{}
 }
 
+func (m *SnapshotChunk) GetSnapshotSize() uint64 {
+	if m != nil {
+		return m.SnapshotSize
+	}
+	return 0
+}
+
+func (m *SnapshotChunk) GetFromStoreID() uint64 {
+	if m != nil {
+		return m.FromStoreID
+	}
+	return 0
+}
+
 // StoreIdent store ident
 type StoreIdent struct {
 	ClusterID            uint64   `protobuf:"varint,1,opt,name=clusterID,proto3" json:"clusterID,omitempty"`
@@ -1974,7 +2748,7 @@ func (m *StoreIdent) Reset()         { *m = StoreIdent{} }
 func (m *StoreIdent) String() string { return proto.CompactTextString(m) }
 func (*StoreIdent) ProtoMessage()    {}
 func (*StoreIdent) Descriptor() ([]byte, []int) {
-	return fileDescriptor_77b4d575d5a68dda, []int{20}
+	return fileDescriptor_77b4d575d5a68dda, []int{25}
 }
 func (m *StoreIdent) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -2019,26 +2793,33 @@ func (m *StoreIdent) GetStoreID() uint64 {
 
 // Shard a shard [start,end) of the data
 type Shard struct {
-	ID                   uint64     `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
-	Start                []byte     `protobuf:"bytes,2,opt,name=start,proto3" json:"start,omitempty"`
-	End                  []byte     `protobuf:"bytes,3,opt,name=end,proto3" json:"end,omitempty"`
-	Epoch                ShardEpoch `protobuf:"bytes,4,opt,name=epoch,proto3" json:"epoch"`
-	State                ShardState `protobuf:"varint,5,opt,name=state,proto3,enum=metapb.ShardState" json:"state,omitempty"`
-	Replicas             []Replica  `protobuf:"bytes,6,rep,name=replicas,proto3" json:"replicas"`
-	Group                uint64     `protobuf:"varint,7,opt,name=group,proto3" json:"group,omitempty"`
-	Unique               string     `protobuf:"bytes,8,opt,name=unique,proto3" json:"unique,omitempty"`
-	RuleGroups           []string   `protobuf:"bytes,9,rep,name=ruleGroups,proto3" json:"ruleGroups,omitempty"`
-	Labels               []Label    `protobuf:"bytes,10,rep,name=labels,proto3" json:"labels"`
-	XXX_NoUnkeyedLiteral struct{}   `json:"-"`
-	XXX_unrecognized     []byte     `json:"-"`
-	XXX_sizecache        int32      `json:"-"`
+	ID         uint64     `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Start      []byte     `protobuf:"bytes,2,opt,name=start,proto3" json:"start,omitempty"`
+	End        []byte     `protobuf:"bytes,3,opt,name=end,proto3" json:"end,omitempty"`
+	Epoch      ShardEpoch `protobuf:"bytes,4,opt,name=epoch,proto3" json:"epoch"`
+	State      ShardState `protobuf:"varint,5,opt,name=state,proto3,enum=metapb.ShardState" json:"state,omitempty"`
+	Replicas   []Replica  `protobuf:"bytes,6,rep,name=replicas,proto3" json:"replicas"`
+	Group      uint64     `protobuf:"varint,7,opt,name=group,proto3" json:"group,omitempty"`
+	Unique     string     `protobuf:"bytes,8,opt,name=unique,proto3" json:"unique,omitempty"`
+	RuleGroups []string   `protobuf:"bytes,9,rep,name=ruleGroups,proto3" json:"ruleGroups,omitempty"`
+	Labels     []Label    `protobuf:"bytes,10,rep,name=labels,proto3" json:"labels"`
+	// DurabilityPolicy the policy of acknowledging the writes, it's inherited by
+	// the new shards split from the shard.
+	DurabilityPolicy DurabilityPolicy `protobuf:"varint,11,opt,name=durabilityPolicy,proto3,enum=metapb.DurabilityPolicy" json:"durabilityPolicy,omitempty"`
+	// ACL the access control list of the shard enforced by the state machine, nil
+	// means no access control. It's inherited by the new shards split from the
+	// shard.
+	ACL                  *ShardACL `protobuf:"bytes,12,opt,name=acl,proto3" json:"acl,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}  `json:"-"`
+	XXX_unrecognized     []byte    `json:"-"`
+	XXX_sizecache        int32     `json:"-"`
 }
 
 func (m *Shard) Reset()         { *m = Shard{} }
 func (m *Shard) String() string { return proto.CompactTextString(m) }
 func (*Shard) ProtoMessage()    {}
 func (*Shard) Descriptor() ([]byte, []int) {
-	return fileDescriptor_77b4d575d5a68dda, []int{21}
+	return fileDescriptor_77b4d575d5a68dda, []int{26}
 }
 func (m *Shard) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -2137,6 +2918,81 @@ func (m *Shard) GetLabels() []Label {
 	return nil
 }
 
+func (m *Shard) GetDurabilityPolicy() DurabilityPolicy {
+	if m != nil {
+		return m.DurabilityPolicy
+	}
+	return DurabilityPolicy_AllReplicas
+}
+
+func (m *Shard) GetACL() *ShardACL {
+	if m != nil {
+		return m.ACL
+	}
+	return nil
+}
+
+// ShardACL the access control list of the shard, the custom requests of the
+// other tenants or of the operations not allowed are rejected
+type ShardACL struct {
+	// Owner the tenant owns the shard, only the requests of the owner are allowed.
+	// Empty means the requests of all the tenants are allowed.
+	Owner string `protobuf:"bytes,1,opt,name=owner,proto3" json:"owner,omitempty"`
+	// AllowedOperations the operations allowed on the shard, empty means all the
+	// operations are allowed
+	AllowedOperations    []OperationClass `protobuf:"varint,2,rep,packed,name=allowedOperations,proto3,enum=metapb.OperationClass" json:"allowedOperations,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}         `json:"-"`
+	XXX_unrecognized     []byte           `json:"-"`
+	XXX_sizecache        int32            `json:"-"`
+}
+
+func (m *ShardACL) Reset()         { *m = ShardACL{} }
+func (m *ShardACL) String() string { return proto.CompactTextString(m) }
+func (*ShardACL) ProtoMessage()    {}
+func (*ShardACL) Descriptor() ([]byte, []int) {
+	return fileDescriptor_77b4d575d5a68dda, []int{27}
+}
+func (m *ShardACL) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *ShardACL) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_ShardACL.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalTo(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *ShardACL) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ShardACL.Merge(m, src)
+}
+func (m *ShardACL) XXX_Size() int {
+	return m.Size()
+}
+func (m *ShardACL) XXX_DiscardUnknown() {
+	xxx_messageInfo_ShardACL.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ShardACL proto.InternalMessageInfo
+
+func (m *ShardACL) GetOwner() string {
+	if m != nil {
+		return m.Owner
+	}
+	return ""
+}
+
+func (m *ShardACL) GetAllowedOperations() []OperationClass {
+	if m != nil {
+		return m.AllowedOperations
+	}
+	return nil
+}
+
 // LogIndex is used to indicate a position in the log.
 type LogIndex struct {
 	Index                uint64   `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`
@@ -2150,7 +3006,7 @@ func (m *LogIndex) Reset()         { *m = LogIndex{} }
 func (m *LogIndex) String() string { return proto.CompactTextString(m) }
 func (*LogIndex) ProtoMessage()    {}
 func (*LogIndex) Descriptor() ([]byte, []int) {
-	return fileDescriptor_77b4d575d5a68dda, []int{22}
+	return fileDescriptor_77b4d575d5a68dda, []int{28}
 }
 func (m *LogIndex) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -2208,7 +3064,7 @@ func (m *ShardMetadata) Reset()         { *m = ShardMetadata{} }
 func (m *ShardMetadata) String() string { return proto.CompactTextString(m) }
 func (*ShardMetadata) ProtoMessage()    {}
 func (*ShardMetadata) Descriptor() ([]byte, []int) {
-	return fileDescriptor_77b4d575d5a68dda, []int{23}
+	return fileDescriptor_77b4d575d5a68dda, []int{29}
 }
 func (m *ShardMetadata) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -2264,7 +3120,19 @@ type ShardLocalState struct {
 	State ReplicaState `protobuf:"varint,2,opt,name=state,proto3,enum=metapb.ReplicaState" json:"state,omitempty"`
 	// RemoveData Whether or not the local Shard data needs to be deleted,
 	// which needs to be specified when the Shard status is set to Destroying
-	RemoveData           bool     `protobuf:"varint,3,opt,name=removeData,proto3" json:"removeData,omitempty"`
+	RemoveData bool `protobuf:"varint,3,opt,name=removeData,proto3" json:"removeData,omitempty"`
+	// MergeTarget the target shard the shard is being merged into, the shard is
+	// frozen since the prepare merge is applied
+	MergeTarget uint64 `protobuf:"varint,4,opt,name=mergeTarget,proto3" json:"mergeTarget,omitempty"`
+	// FenceIndex the index of the barrier entry fencing the shard, the writes
+	// after it are rejected until the shard is unfenced, 0 means not fenced
+	FenceIndex uint64 `protobuf:"varint,5,opt,name=fenceIndex,proto3" json:"fenceIndex,omitempty"`
+	// MergeTargetEpoch the epoch of the target shard when the prepare merge is
+	// proposed, the merge is rolled back once the epoch of the target changed.
+	MergeTargetEpoch ShardEpoch `protobuf:"bytes,6,opt,name=mergeTargetEpoch,proto3" json:"mergeTargetEpoch"`
+	// Migrated the shard is fenced as its group is migrated to another cluster,
+	// all the requests are rejected until the shard is unfenced
+	Migrated             bool     `protobuf:"varint,7,opt,name=migrated,proto3" json:"migrated,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -2274,7 +3142,7 @@ func (m *ShardLocalState) Reset()         { *m = ShardLocalState{} }
 func (m *ShardLocalState) String() string { return proto.CompactTextString(m) }
 func (*ShardLocalState) ProtoMessage()    {}
 func (*ShardLocalState) Descriptor() ([]byte, []int) {
-	return fileDescriptor_77b4d575d5a68dda, []int{24}
+	return fileDescriptor_77b4d575d5a68dda, []int{30}
 }
 func (m *ShardLocalState) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -2324,29 +3192,194 @@ func (m *ShardLocalState) GetRemoveData() bool {
 	return false
 }
 
+func (m *ShardLocalState) GetMergeTarget() uint64 {
+	if m != nil {
+		return m.MergeTarget
+	}
+	return 0
+}
+
+func (m *ShardLocalState) GetFenceIndex() uint64 {
+	if m != nil {
+		return m.FenceIndex
+	}
+	return 0
+}
+
+func (m *ShardLocalState) GetMergeTargetEpoch() ShardEpoch {
+	if m != nil {
+		return m.MergeTargetEpoch
+	}
+	return ShardEpoch{}
+}
+
+func (m *ShardLocalState) GetMigrated() bool {
+	if m != nil {
+		return m.Migrated
+	}
+	return false
+}
+
+// BackupManifest the manifest of a consistent backup of all the shards of a
+// shard group, the shards are backed up at the barrier entries applied while
+// the writes of the whole group are fenced
+type BackupManifest struct {
+	Group uint64 `protobuf:"varint,1,opt,name=group,proto3" json:"group,omitempty"`
+	// CreatedAt the unix timestamp in seconds when the backup is created
+	CreatedAt            int64         `protobuf:"varint,2,opt,name=createdAt,proto3" json:"createdAt,omitempty"`
+	Shards               []ShardBackup `protobuf:"bytes,3,rep,name=shards,proto3" json:"shards"`
+	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
+	XXX_unrecognized     []byte        `json:"-"`
+	XXX_sizecache        int32         `json:"-"`
+}
+
+func (m *BackupManifest) Reset()         { *m = BackupManifest{} }
+func (m *BackupManifest) String() string { return proto.CompactTextString(m) }
+func (*BackupManifest) ProtoMessage()    {}
+func (*BackupManifest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_77b4d575d5a68dda, []int{31}
+}
+func (m *BackupManifest) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *BackupManifest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_BackupManifest.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalTo(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *BackupManifest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_BackupManifest.Merge(m, src)
+}
+func (m *BackupManifest) XXX_Size() int {
+	return m.Size()
+}
+func (m *BackupManifest) XXX_DiscardUnknown() {
+	xxx_messageInfo_BackupManifest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_BackupManifest proto.InternalMessageInfo
+
+func (m *BackupManifest) GetGroup() uint64 {
+	if m != nil {
+		return m.Group
+	}
+	return 0
+}
+
+func (m *BackupManifest) GetCreatedAt() int64 {
+	if m != nil {
+		return m.CreatedAt
+	}
+	return 0
+}
+
+func (m *BackupManifest) GetShards() []ShardBackup {
+	if m != nil {
+		return m.Shards
+	}
+	return nil
+}
+
+// ShardBackup the backup of a shard
+type ShardBackup struct {
+	// Shard the shard metadata at the backup index
+	Shard Shard `protobuf:"bytes,1,opt,name=shard,proto3" json:"shard"`
+	// Index the raft log index of the barrier entry creating the backup
+	Index uint64 `protobuf:"varint,2,opt,name=index,proto3" json:"index,omitempty"`
+	// Path the path of the backup on the store of the replica
+	Path                 string   `protobuf:"bytes,3,opt,name=path,proto3" json:"path,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ShardBackup) Reset()         { *m = ShardBackup{} }
+func (m *ShardBackup) String() string { return proto.CompactTextString(m) }
+func (*ShardBackup) ProtoMessage()    {}
+func (*ShardBackup) Descriptor() ([]byte, []int) {
+	return fileDescriptor_77b4d575d5a68dda, []int{32}
+}
+func (m *ShardBackup) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *ShardBackup) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_ShardBackup.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalTo(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *ShardBackup) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ShardBackup.Merge(m, src)
+}
+func (m *ShardBackup) XXX_Size() int {
+	return m.Size()
+}
+func (m *ShardBackup) XXX_DiscardUnknown() {
+	xxx_messageInfo_ShardBackup.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ShardBackup proto.InternalMessageInfo
+
+func (m *ShardBackup) GetShard() Shard {
+	if m != nil {
+		return m.Shard
+	}
+	return Shard{}
+}
+
+func (m *ShardBackup) GetIndex() uint64 {
+	if m != nil {
+		return m.Index
+	}
+	return 0
+}
+
+func (m *ShardBackup) GetPath() string {
+	if m != nil {
+		return m.Path
+	}
+	return ""
+}
+
 // Store the host store metadata
 type Store struct {
-	ID                   uint64     `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
-	RaftAddress          string     `protobuf:"bytes,2,opt,name=raftAddress,proto3" json:"raftAddress,omitempty"`
-	ClientAddress        string     `protobuf:"bytes,3,opt,name=clientAddress,proto3" json:"clientAddress,omitempty"`
-	Labels               []Label    `protobuf:"bytes,4,rep,name=labels,proto3" json:"labels"`
-	State                StoreState `protobuf:"varint,5,opt,name=state,proto3,enum=metapb.StoreState" json:"state,omitempty"`
-	StartTime            int64      `protobuf:"varint,6,opt,name=startTime,proto3" json:"startTime,omitempty"`
-	LastHeartbeatTime    int64      `protobuf:"varint,7,opt,name=lastHeartbeatTime,proto3" json:"lastHeartbeatTime,omitempty"`
-	Version              string     `protobuf:"bytes,8,opt,name=version,proto3" json:"version,omitempty"`
-	CommitID             string     `protobuf:"bytes,9,opt,name=commitID,proto3" json:"commitID,omitempty"`
-	DeployPath           string     `protobuf:"bytes,10,opt,name=deployPath,proto3" json:"deployPath,omitempty"`
-	Destroyed            bool       `protobuf:"varint,11,opt,name=destroyed,proto3" json:"destroyed,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}   `json:"-"`
-	XXX_unrecognized     []byte     `json:"-"`
-	XXX_sizecache        int32      `json:"-"`
+	ID                uint64     `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	RaftAddress       string     `protobuf:"bytes,2,opt,name=raftAddress,proto3" json:"raftAddress,omitempty"`
+	ClientAddress     string     `protobuf:"bytes,3,opt,name=clientAddress,proto3" json:"clientAddress,omitempty"`
+	Labels            []Label    `protobuf:"bytes,4,rep,name=labels,proto3" json:"labels"`
+	State             StoreState `protobuf:"varint,5,opt,name=state,proto3,enum=metapb.StoreState" json:"state,omitempty"`
+	StartTime         int64      `protobuf:"varint,6,opt,name=startTime,proto3" json:"startTime,omitempty"`
+	LastHeartbeatTime int64      `protobuf:"varint,7,opt,name=lastHeartbeatTime,proto3" json:"lastHeartbeatTime,omitempty"`
+	Version           string     `protobuf:"bytes,8,opt,name=version,proto3" json:"version,omitempty"`
+	CommitID          string     `protobuf:"bytes,9,opt,name=commitID,proto3" json:"commitID,omitempty"`
+	DeployPath        string     `protobuf:"bytes,10,opt,name=deployPath,proto3" json:"deployPath,omitempty"`
+	Destroyed         bool       `protobuf:"varint,11,opt,name=destroyed,proto3" json:"destroyed,omitempty"`
+	// maintenance the store is in the maintenance mode, no new replica or leader
+	// is scheduled to the store, the existing replicas on it remain.
+	Maintenance          bool     `protobuf:"varint,12,opt,name=maintenance,proto3" json:"maintenance,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
 func (m *Store) Reset()         { *m = Store{} }
 func (m *Store) String() string { return proto.CompactTextString(m) }
 func (*Store) ProtoMessage()    {}
 func (*Store) Descriptor() ([]byte, []int) {
-	return fileDescriptor_77b4d575d5a68dda, []int{25}
+	return fileDescriptor_77b4d575d5a68dda, []int{33}
 }
 func (m *Store) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -2452,6 +3485,13 @@ func (m *Store) GetDestroyed() bool {
 	return false
 }
 
+func (m *Store) GetMaintenance() bool {
+	if m != nil {
+		return m.Maintenance
+	}
+	return false
+}
+
 // ShardsPool shards pool
 type ShardsPool struct {
 	Pools                map[uint64]*ShardPool `protobuf:"bytes,1,rep,name=pools,proto3" json:"pools,omitempty" protobuf_key:"varint,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
@@ -2464,7 +3504,7 @@ func (m *ShardsPool) Reset()         { *m = ShardsPool{} }
 func (m *ShardsPool) String() string { return proto.CompactTextString(m) }
 func (*ShardsPool) ProtoMessage()    {}
 func (*ShardsPool) Descriptor() ([]byte, []int) {
-	return fileDescriptor_77b4d575d5a68dda, []int{26}
+	return fileDescriptor_77b4d575d5a68dda, []int{34}
 }
 func (m *ShardsPool) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -2516,7 +3556,7 @@ func (m *ShardPool) Reset()         { *m = ShardPool{} }
 func (m *ShardPool) String() string { return proto.CompactTextString(m) }
 func (*ShardPool) ProtoMessage()    {}
 func (*ShardPool) Descriptor() ([]byte, []int) {
-	return fileDescriptor_77b4d575d5a68dda, []int{27}
+	return fileDescriptor_77b4d575d5a68dda, []int{35}
 }
 func (m *ShardPool) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -2594,7 +3634,7 @@ func (m *AllocatedShard) Reset()         { *m = AllocatedShard{} }
 func (m *AllocatedShard) String() string { return proto.CompactTextString(m) }
 func (*AllocatedShard) ProtoMessage()    {}
 func (*AllocatedShard) Descriptor() ([]byte, []int) {
-	return fileDescriptor_77b4d575d5a68dda, []int{28}
+	return fileDescriptor_77b4d575d5a68dda, []int{36}
 }
 func (m *AllocatedShard) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -2658,7 +3698,7 @@ func (m *ShardsPoolCmd) Reset()         { *m = ShardsPoolCmd{} }
 func (m *ShardsPoolCmd) String() string { return proto.CompactTextString(m) }
 func (*ShardsPoolCmd) ProtoMessage()    {}
 func (*ShardsPoolCmd) Descriptor() ([]byte, []int) {
-	return fileDescriptor_77b4d575d5a68dda, []int{29}
+	return fileDescriptor_77b4d575d5a68dda, []int{37}
 }
 func (m *ShardsPoolCmd) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -2719,7 +3759,7 @@ func (m *ShardsPoolCreateCmd) Reset()         { *m = ShardsPoolCreateCmd{} }
 func (m *ShardsPoolCreateCmd) String() string { return proto.CompactTextString(m) }
 func (*ShardsPoolCreateCmd) ProtoMessage()    {}
 func (*ShardsPoolCreateCmd) Descriptor() ([]byte, []int) {
-	return fileDescriptor_77b4d575d5a68dda, []int{30}
+	return fileDescriptor_77b4d575d5a68dda, []int{38}
 }
 func (m *ShardsPoolCreateCmd) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -2761,7 +3801,7 @@ func (m *ShardsPoolAllocCmd) Reset()         { *m = ShardsPoolAllocCmd{} }
 func (m *ShardsPoolAllocCmd) String() string { return proto.CompactTextString(m) }
 func (*ShardsPoolAllocCmd) ProtoMessage()    {}
 func (*ShardsPoolAllocCmd) Descriptor() ([]byte, []int) {
-	return fileDescriptor_77b4d575d5a68dda, []int{31}
+	return fileDescriptor_77b4d575d5a68dda, []int{39}
 }
 func (m *ShardsPoolAllocCmd) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -2806,8 +3846,17 @@ func (m *ShardsPoolAllocCmd) GetPurpose() []byte {
 
 // SnapshotInfo contains additional information associated with a snapshot.
 type SnapshotInfo struct {
-	Extra                uint64   `protobuf:"varint,1,opt,name=extra,proto3" json:"extra,omitempty"`
-	Dummy                bool     `protobuf:"varint,2,opt,name=dummy,proto3" json:"dummy,omitempty"`
+	Extra uint64 `protobuf:"varint,1,opt,name=extra,proto3" json:"extra,omitempty"`
+	Dummy bool   `protobuf:"varint,2,opt,name=dummy,proto3" json:"dummy,omitempty"`
+	// DeltaBase the base index of the delta snapshot, only the data changed
+	// since it is included. 0 for the full snapshot.
+	DeltaBase uint64 `protobuf:"varint,3,opt,name=deltaBase,proto3" json:"deltaBase,omitempty"`
+	// Witness the snapshot sent to the witness replica, it only contains the
+	// metadata of the shard.
+	Witness bool `protobuf:"varint,4,opt,name=witness,proto3" json:"witness,omitempty"`
+	// Remote the snapshot image is uploaded to the remote snapshot storage, the
+	// snapshot chunk only contains the SnapshotManifest of the image.
+	Remote               bool     `protobuf:"varint,5,opt,name=remote,proto3" json:"remote,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -2817,7 +3866,7 @@ func (m *SnapshotInfo) Reset()         { *m = SnapshotInfo{} }
 func (m *SnapshotInfo) String() string { return proto.CompactTextString(m) }
 func (*SnapshotInfo) ProtoMessage()    {}
 func (*SnapshotInfo) Descriptor() ([]byte, []int) {
-	return fileDescriptor_77b4d575d5a68dda, []int{32}
+	return fileDescriptor_77b4d575d5a68dda, []int{40}
 }
 func (m *SnapshotInfo) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -2860,45 +3909,191 @@ func (m *SnapshotInfo) GetDummy() bool {
 	return false
 }
 
-func init() {
-	proto.RegisterEnum("metapb.ShardType", ShardType_name, ShardType_value)
-	proto.RegisterEnum("metapb.StoreState", StoreState_name, StoreState_value)
-	proto.RegisterEnum("metapb.ShardState", ShardState_name, ShardState_value)
-	proto.RegisterEnum("metapb.ConfigChangeType", ConfigChangeType_name, ConfigChangeType_value)
-	proto.RegisterEnum("metapb.ReplicaRole", ReplicaRole_name, ReplicaRole_value)
-	proto.RegisterEnum("metapb.CheckPolicy", CheckPolicy_name, CheckPolicy_value)
-	proto.RegisterEnum("metapb.OperatorStatus", OperatorStatus_name, OperatorStatus_value)
-	proto.RegisterEnum("metapb.JobType", JobType_name, JobType_value)
-	proto.RegisterEnum("metapb.JobState", JobState_name, JobState_value)
-	proto.RegisterEnum("metapb.ReplicaState", ReplicaState_name, ReplicaState_value)
-	proto.RegisterEnum("metapb.ShardsPoolCmdType", ShardsPoolCmdType_name, ShardsPoolCmdType_value)
-	proto.RegisterType((*ShardEpoch)(nil), "metapb.ShardEpoch")
-	proto.RegisterType((*Replica)(nil), "metapb.Replica")
-	proto.RegisterType((*ReplicaStats)(nil), "metapb.ReplicaStats")
-	proto.RegisterType((*Label)(nil), "metapb.Label")
-	proto.RegisterType((*ShardStats)(nil), "metapb.ShardStats")
-	proto.RegisterType((*StoreStats)(nil), "metapb.StoreStats")
-	proto.RegisterType((*RecordPair)(nil), "metapb.RecordPair")
-	proto.RegisterType((*Member)(nil), "metapb.Member")
-	proto.RegisterType((*ProphetCluster)(nil), "metapb.ProphetCluster")
-	proto.RegisterType((*TimeInterval)(nil), "metapb.TimeInterval")
-	proto.RegisterType((*Job)(nil), "metapb.Job")
-	proto.RegisterType((*RemoveShardJob)(nil), "metapb.RemoveShardJob")
-	proto.RegisterType((*ShardPoolJob)(nil), "metapb.ShardPoolJob")
-	proto.RegisterType((*ShardPoolJobMeta)(nil), "metapb.ShardPoolJobMeta")
-	proto.RegisterType((*DestroyingStatus)(nil), "metapb.DestroyingStatus")
-	proto.RegisterMapType((map[uint64]bool)(nil), "metapb.DestroyingStatus.ReplicasEntry")
-	proto.RegisterType((*ShardExtra)(nil), "metapb.ShardExtra")
-	proto.RegisterMapType((map[string]string)(nil), "metapb.ShardExtra.LabelsEntry")
-	proto.RegisterType((*ScheduleGroupRule)(nil), "metapb.ScheduleGroupRule")
-	proto.RegisterType((*RaftMessageBatch)(nil), "metapb.RaftMessageBatch")
-	proto.RegisterType((*RaftMessage)(nil), "metapb.RaftMessage")
-	proto.RegisterType((*SnapshotChunk)(nil), "metapb.SnapshotChunk")
-	proto.RegisterType((*StoreIdent)(nil), "metapb.StoreIdent")
+func (m *SnapshotInfo) GetDeltaBase() uint64 {
+	if m != nil {
+		return m.DeltaBase
+	}
+	return 0
+}
+
+func (m *SnapshotInfo) GetWitness() bool {
+	if m != nil {
+		return m.Witness
+	}
+	return false
+}
+
+func (m *SnapshotInfo) GetRemote() bool {
+	if m != nil {
+		return m.Remote
+	}
+	return false
+}
+
+// SnapshotManifest the manifest of the snapshot image uploaded to the remote
+// snapshot storage.
+type SnapshotManifest struct {
+	// Prefix the key prefix of the files of the image
+	Prefix               string         `protobuf:"bytes,1,opt,name=prefix,proto3" json:"prefix,omitempty"`
+	Files                []SnapshotFile `protobuf:"bytes,2,rep,name=files,proto3" json:"files"`
+	XXX_NoUnkeyedLiteral struct{}       `json:"-"`
+	XXX_unrecognized     []byte         `json:"-"`
+	XXX_sizecache        int32          `json:"-"`
+}
+
+func (m *SnapshotManifest) Reset()         { *m = SnapshotManifest{} }
+func (m *SnapshotManifest) String() string { return proto.CompactTextString(m) }
+func (*SnapshotManifest) ProtoMessage()    {}
+func (*SnapshotManifest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_77b4d575d5a68dda, []int{41}
+}
+func (m *SnapshotManifest) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *SnapshotManifest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_SnapshotManifest.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalTo(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *SnapshotManifest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SnapshotManifest.Merge(m, src)
+}
+func (m *SnapshotManifest) XXX_Size() int {
+	return m.Size()
+}
+func (m *SnapshotManifest) XXX_DiscardUnknown() {
+	xxx_messageInfo_SnapshotManifest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SnapshotManifest proto.InternalMessageInfo
+
+func (m *SnapshotManifest) GetPrefix() string {
+	if m != nil {
+		return m.Prefix
+	}
+	return ""
+}
+
+func (m *SnapshotManifest) GetFiles() []SnapshotFile {
+	if m != nil {
+		return m.Files
+	}
+	return nil
+}
+
+// SnapshotFile a file of the snapshot image
+type SnapshotFile struct {
+	// Path the path relative to the snapshot dir
+	Path                 string   `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	FileSize             uint64   `protobuf:"varint,2,opt,name=fileSize,proto3" json:"fileSize,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SnapshotFile) Reset()         { *m = SnapshotFile{} }
+func (m *SnapshotFile) String() string { return proto.CompactTextString(m) }
+func (*SnapshotFile) ProtoMessage()    {}
+func (*SnapshotFile) Descriptor() ([]byte, []int) {
+	return fileDescriptor_77b4d575d5a68dda, []int{42}
+}
+func (m *SnapshotFile) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *SnapshotFile) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_SnapshotFile.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalTo(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *SnapshotFile) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SnapshotFile.Merge(m, src)
+}
+func (m *SnapshotFile) XXX_Size() int {
+	return m.Size()
+}
+func (m *SnapshotFile) XXX_DiscardUnknown() {
+	xxx_messageInfo_SnapshotFile.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SnapshotFile proto.InternalMessageInfo
+
+func (m *SnapshotFile) GetPath() string {
+	if m != nil {
+		return m.Path
+	}
+	return ""
+}
+
+func (m *SnapshotFile) GetFileSize() uint64 {
+	if m != nil {
+		return m.FileSize
+	}
+	return 0
+}
+
+func init() {
+	proto.RegisterEnum("metapb.ShardType", ShardType_name, ShardType_value)
+	proto.RegisterEnum("metapb.StoreState", StoreState_name, StoreState_value)
+	proto.RegisterEnum("metapb.ShardState", ShardState_name, ShardState_value)
+	proto.RegisterEnum("metapb.ConfigChangeType", ConfigChangeType_name, ConfigChangeType_value)
+	proto.RegisterEnum("metapb.ReplicaRole", ReplicaRole_name, ReplicaRole_value)
+	proto.RegisterEnum("metapb.OperationClass", OperationClass_name, OperationClass_value)
+	proto.RegisterEnum("metapb.DurabilityPolicy", DurabilityPolicy_name, DurabilityPolicy_value)
+	proto.RegisterEnum("metapb.CheckPolicy", CheckPolicy_name, CheckPolicy_value)
+	proto.RegisterEnum("metapb.OperatorStatus", OperatorStatus_name, OperatorStatus_value)
+	proto.RegisterEnum("metapb.JobType", JobType_name, JobType_value)
+	proto.RegisterEnum("metapb.JobState", JobState_name, JobState_value)
+	proto.RegisterEnum("metapb.ReplicaState", ReplicaState_name, ReplicaState_value)
+	proto.RegisterEnum("metapb.ShardsPoolCmdType", ShardsPoolCmdType_name, ShardsPoolCmdType_value)
+	proto.RegisterType((*ShardEpoch)(nil), "metapb.ShardEpoch")
+	proto.RegisterType((*Replica)(nil), "metapb.Replica")
+	proto.RegisterType((*ReplicaStats)(nil), "metapb.ReplicaStats")
+	proto.RegisterType((*Label)(nil), "metapb.Label")
+	proto.RegisterType((*ShardStats)(nil), "metapb.ShardStats")
+	proto.RegisterType((*ReplicaMatchIndex)(nil), "metapb.ReplicaMatchIndex")
+	proto.RegisterType((*StoreStats)(nil), "metapb.StoreStats")
+	proto.RegisterType((*SnapshotProgress)(nil), "metapb.SnapshotProgress")
+	proto.RegisterType((*RecordPair)(nil), "metapb.RecordPair")
+	proto.RegisterType((*Member)(nil), "metapb.Member")
+	proto.RegisterType((*ProphetCluster)(nil), "metapb.ProphetCluster")
+	proto.RegisterType((*TimeInterval)(nil), "metapb.TimeInterval")
+	proto.RegisterType((*Job)(nil), "metapb.Job")
+	proto.RegisterType((*RemoveShardJob)(nil), "metapb.RemoveShardJob")
+	proto.RegisterType((*ShardPoolJob)(nil), "metapb.ShardPoolJob")
+	proto.RegisterType((*ShardPoolJobMeta)(nil), "metapb.ShardPoolJobMeta")
+	proto.RegisterType((*DestroyingStatus)(nil), "metapb.DestroyingStatus")
+	proto.RegisterMapType((map[uint64]bool)(nil), "metapb.DestroyingStatus.ReplicasEntry")
+	proto.RegisterType((*ShardExtra)(nil), "metapb.ShardExtra")
+	proto.RegisterMapType((map[string]string)(nil), "metapb.ShardExtra.LabelsEntry")
+	proto.RegisterType((*ScheduleGroupRule)(nil), "metapb.ScheduleGroupRule")
+	proto.RegisterType((*RangeRelocation)(nil), "metapb.RangeRelocation")
+	proto.RegisterType((*LabelSteering)(nil), "metapb.LabelSteering")
+	proto.RegisterType((*RaftMessageBatch)(nil), "metapb.RaftMessageBatch")
+	proto.RegisterType((*RaftHeartbeat)(nil), "metapb.RaftHeartbeat")
+	proto.RegisterType((*RaftMessage)(nil), "metapb.RaftMessage")
+	proto.RegisterType((*SnapshotChunk)(nil), "metapb.SnapshotChunk")
+	proto.RegisterType((*StoreIdent)(nil), "metapb.StoreIdent")
 	proto.RegisterType((*Shard)(nil), "metapb.Shard")
+	proto.RegisterType((*ShardACL)(nil), "metapb.ShardACL")
 	proto.RegisterType((*LogIndex)(nil), "metapb.LogIndex")
 	proto.RegisterType((*ShardMetadata)(nil), "metapb.ShardMetadata")
 	proto.RegisterType((*ShardLocalState)(nil), "metapb.ShardLocalState")
+	proto.RegisterType((*BackupManifest)(nil), "metapb.BackupManifest")
+	proto.RegisterType((*ShardBackup)(nil), "metapb.ShardBackup")
 	proto.RegisterType((*Store)(nil), "metapb.Store")
 	proto.RegisterType((*ShardsPool)(nil), "metapb.ShardsPool")
 	proto.RegisterMapType((map[uint64]*ShardPool)(nil), "metapb.ShardsPool.PoolsEntry")
@@ -2908,163 +4103,220 @@ func init() {
 	proto.RegisterType((*ShardsPoolCreateCmd)(nil), "metapb.ShardsPoolCreateCmd")
 	proto.RegisterType((*ShardsPoolAllocCmd)(nil), "metapb.ShardsPoolAllocCmd")
 	proto.RegisterType((*SnapshotInfo)(nil), "metapb.SnapshotInfo")
+	proto.RegisterType((*SnapshotManifest)(nil), "metapb.SnapshotManifest")
+	proto.RegisterType((*SnapshotFile)(nil), "metapb.SnapshotFile")
 }
 
 func init() { proto.RegisterFile("metapb.proto", fileDescriptor_77b4d575d5a68dda) }
 
 var fileDescriptor_77b4d575d5a68dda = []byte{
-	// 2416 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x59, 0x4f, 0x73, 0x23, 0x47,
-	0x15, 0xf7, 0x8c, 0x64, 0x5b, 0x7a, 0xf2, 0x9f, 0x71, 0xef, 0x12, 0x84, 0x09, 0x1b, 0xd7, 0x10,
-	0x12, 0x47, 0x24, 0x76, 0xd8, 0xdd, 0xa4, 0x92, 0x40, 0x51, 0xc8, 0x92, 0x49, 0x94, 0xf5, 0x7a,
-	0x5d, 0x23, 0x3b, 0xc0, 0xb1, 0xa5, 0x69, 0xc9, 0x53, 0x3b, 0x33, 0x3d, 0x99, 0x69, 0x39, 0x2b,
-	0xaa, 0xa8, 0xe2, 0x48, 0x71, 0xe0, 0x5b, 0x70, 0xe3, 0x13, 0x70, 0xa7, 0xc8, 0x8d, 0x9c, 0x39,
-	0xa4, 0x60, 0xbf, 0x02, 0x57, 0x8a, 0xa2, 0xfa, 0x75, 0xcf, 0x4c, 0x8f, 0xe4, 0x3f, 0x7b, 0xb1,
-	0xe6, 0xbd, 0x7e, 0xdd, 0xef, 0xf5, 0xfb, 0xd7, 0xbf, 0x6e, 0xc3, 0x46, 0xc4, 0x04, 0x4d, 0x46,
-	0x07, 0x49, 0xca, 0x05, 0x27, 0x6b, 0x8a, 0xda, 0x7d, 0x6f, 0x1a, 0x88, 0xcb, 0xd9, 0xe8, 0x60,
-	0xcc, 0xa3, 0xc3, 0x29, 0x9f, 0xf2, 0x43, 0x1c, 0x1e, 0xcd, 0x26, 0x48, 0x21, 0x81, 0x5f, 0x6a,
-	0xda, 0xee, 0x3b, 0x53, 0x7e, 0xc0, 0xc4, 0xd8, 0x3f, 0x08, 0xf8, 0xa1, 0xfc, 0x3d, 0x4c, 0xe9,
-	0x44, 0x1c, 0x5e, 0x3d, 0xc2, 0xdf, 0x64, 0x84, 0x3f, 0x4a, 0xd4, 0xfd, 0x1c, 0x60, 0x78, 0x49,
-	0x53, 0xff, 0x38, 0xe1, 0xe3, 0x4b, 0xf2, 0x3a, 0x34, 0xc7, 0x3c, 0x9e, 0x04, 0xd3, 0x2f, 0x58,
-	0xda, 0xb6, 0xf6, 0xac, 0xfd, 0xba, 0x57, 0x32, 0xc8, 0x03, 0x80, 0x29, 0x8b, 0x59, 0x4a, 0x45,
-	0xc0, 0xe3, 0xb6, 0x8d, 0xc3, 0x06, 0xc7, 0xfd, 0xa3, 0x05, 0xeb, 0x1e, 0x4b, 0xc2, 0x60, 0x4c,
-	0xc9, 0x6b, 0x60, 0x07, 0xbe, 0x5a, 0xe2, 0x68, 0xed, 0xe5, 0xb7, 0x6f, 0xd8, 0x83, 0xbe, 0x67,
-	0x07, 0x3e, 0x69, 0xc3, 0x7a, 0x26, 0x78, 0xca, 0x06, 0x7d, 0xbd, 0x40, 0x4e, 0x92, 0xb7, 0xa1,
-	0x9e, 0xf2, 0x90, 0xb5, 0x6b, 0x7b, 0xd6, 0xfe, 0xd6, 0xc3, 0x7b, 0x07, 0xda, 0x11, 0x7a, 0x41,
-	0x8f, 0x87, 0xcc, 0x43, 0x01, 0xf2, 0x26, 0x6c, 0x06, 0x71, 0x20, 0x02, 0x1a, 0x3e, 0x65, 0xd1,
-	0x88, 0xa5, 0xed, 0xfa, 0x9e, 0xb5, 0xdf, 0xf0, 0xaa, 0x4c, 0x97, 0xc2, 0x86, 0x9e, 0x3a, 0x14,
-	0x54, 0x64, 0xe4, 0x10, 0xd6, 0x53, 0x45, 0xa3, 0x55, 0xad, 0x87, 0xdb, 0x0b, 0x1a, 0x8e, 0xea,
-	0x5f, 0x7f, 0xfb, 0xc6, 0x8a, 0x97, 0x4b, 0x91, 0x3d, 0x68, 0xf9, 0xfc, 0xab, 0x78, 0xc8, 0xc6,
-	0x3c, 0xf6, 0x33, 0x6d, 0xad, 0xc9, 0x72, 0x0f, 0x61, 0xf5, 0x84, 0x8e, 0x58, 0x48, 0x1c, 0xa8,
-	0x3d, 0x67, 0x73, 0x5c, 0xb7, 0xe9, 0xc9, 0x4f, 0x72, 0x1f, 0x56, 0xaf, 0x68, 0x38, 0x63, 0x38,
-	0xad, 0xe9, 0x29, 0xc2, 0xfd, 0x8b, 0xad, 0xbd, 0xad, 0x4c, 0x92, 0xbe, 0x90, 0xd4, 0xa0, 0xaf,
-	0x7d, 0x9d, 0x93, 0xc4, 0x85, 0x8d, 0xaf, 0xd2, 0x40, 0x08, 0x16, 0x1f, 0xcd, 0x05, 0xcb, 0x95,
-	0x57, 0x78, 0xd2, 0x3e, 0x4d, 0x3f, 0x61, 0xf3, 0x0c, 0xdd, 0x56, 0xf7, 0x4c, 0x96, 0x8c, 0x66,
-	0xca, 0xa8, 0xaf, 0x96, 0xa8, 0xab, 0x68, 0x16, 0x0c, 0xb2, 0x0b, 0x0d, 0x49, 0xe0, 0xe4, 0x55,
-	0x1c, 0x2c, 0x68, 0xb2, 0x0f, 0xdb, 0x34, 0x49, 0x52, 0xfe, 0x22, 0x88, 0xa8, 0x60, 0xc3, 0xe0,
-	0xb7, 0xac, 0xbd, 0x86, 0x22, 0x8b, 0xec, 0x05, 0x49, 0x5c, 0x6c, 0x7d, 0x49, 0x12, 0xd7, 0x7c,
-	0x1f, 0x1a, 0x41, 0x2c, 0x58, 0x7a, 0x45, 0xc3, 0x76, 0x03, 0x23, 0x70, 0x3f, 0x8f, 0xc0, 0x79,
-	0x10, 0xb1, 0x81, 0x1e, 0xf3, 0x0a, 0x29, 0xf7, 0xbf, 0xab, 0x00, 0x43, 0x99, 0x1d, 0xa5, 0xbb,
-	0x74, 0xea, 0x58, 0xd5, 0xd4, 0x79, 0x1d, 0x9a, 0x99, 0xa0, 0xa9, 0x90, 0xeb, 0x68, 0x5f, 0x95,
-	0x8c, 0x8a, 0xe2, 0xda, 0xab, 0x28, 0x96, 0xae, 0x19, 0xd3, 0x84, 0x8e, 0x03, 0x31, 0xd7, 0x7e,
-	0x2b, 0x68, 0xa9, 0x8b, 0x5e, 0xd1, 0x20, 0xa4, 0xa3, 0x90, 0x69, 0xbf, 0x95, 0x0c, 0x39, 0x73,
-	0x96, 0x31, 0xdf, 0xf0, 0x58, 0x41, 0x93, 0xd7, 0x60, 0x2d, 0xc8, 0x8e, 0x66, 0xd9, 0x1c, 0x3d,
-	0xd4, 0xf0, 0x34, 0x25, 0xcb, 0x0a, 0xe3, 0xde, 0xe3, 0xb3, 0x58, 0xa0, 0x6b, 0xea, 0x9e, 0xc1,
-	0x21, 0x1d, 0x70, 0x32, 0x16, 0xfb, 0x41, 0x3c, 0x1d, 0xc6, 0x34, 0x51, 0x52, 0x4d, 0x94, 0x5a,
-	0xe2, 0x93, 0x03, 0x20, 0x29, 0x1b, 0xb3, 0xe0, 0xaa, 0x22, 0x0d, 0x28, 0x7d, 0xcd, 0x08, 0x79,
-	0x17, 0x76, 0x68, 0x92, 0x84, 0xf3, 0x8a, 0x78, 0x0b, 0xc5, 0x97, 0x07, 0x96, 0xd2, 0x72, 0xe3,
-	0x9a, 0xb4, 0xac, 0x24, 0xdd, 0xe6, 0x62, 0xd2, 0x2d, 0x24, 0xed, 0xd6, 0x72, 0xd2, 0x9a, 0x69,
-	0xb9, 0xbd, 0x90, 0x96, 0x1f, 0x42, 0x73, 0x9c, 0xcc, 0x2e, 0x32, 0x3a, 0x65, 0x59, 0xdb, 0xd9,
-	0xab, 0xed, 0xb7, 0x1e, 0x92, 0xb2, 0x8a, 0xc7, 0x3c, 0xf5, 0xcf, 0x68, 0x90, 0xea, 0x42, 0x2e,
-	0x45, 0xc9, 0x27, 0xd0, 0x92, 0x6b, 0x0c, 0x9e, 0x79, 0x54, 0x5a, 0xb5, 0x73, 0xc7, 0x4c, 0x53,
-	0x98, 0xfc, 0x4c, 0xed, 0x99, 0xe5, 0x93, 0xc9, 0x1d, 0x93, 0x2b, 0xd2, 0x52, 0x33, 0x4f, 0x4e,
-	0xa8, 0x60, 0xf1, 0x38, 0x60, 0x59, 0xfb, 0xde, 0x5d, 0x9a, 0x0d, 0x61, 0xf7, 0x31, 0x40, 0x29,
-	0x70, 0x57, 0x8f, 0xa9, 0xe7, 0x3d, 0xe6, 0x33, 0x58, 0x53, 0x1d, 0xf0, 0xc6, 0x16, 0x4c, 0xa0,
-	0x1e, 0xd3, 0x28, 0x6f, 0x4d, 0xf8, 0x2d, 0x79, 0xd4, 0xf7, 0x53, 0xac, 0x8f, 0xa6, 0x87, 0xdf,
-	0xae, 0x07, 0x5b, 0x67, 0x29, 0x4f, 0x2e, 0x99, 0xe8, 0x85, 0xb3, 0x4c, 0xdc, 0xb2, 0xe2, 0x3e,
-	0x6c, 0x47, 0xf4, 0x85, 0xee, 0xa3, 0x2a, 0x87, 0xe4, 0xe2, 0x9b, 0xde, 0x22, 0xdb, 0xfd, 0x10,
-	0x36, 0xcc, 0x9a, 0x93, 0x7b, 0xc0, 0x42, 0xd5, 0x15, 0xad, 0x08, 0xb9, 0x57, 0x16, 0xfb, 0x7a,
-	0x5f, 0xf2, 0xd3, 0x0d, 0xa1, 0xf6, 0x39, 0x1f, 0x91, 0x1f, 0x42, 0x5d, 0xcc, 0x13, 0x86, 0xd2,
-	0x5b, 0x65, 0x07, 0xff, 0x9c, 0x8f, 0xce, 0xe7, 0x09, 0xf3, 0x70, 0x50, 0xf6, 0x89, 0x31, 0x8f,
-	0x05, 0xd3, 0x56, 0x6c, 0x78, 0x39, 0x49, 0xde, 0x42, 0x6d, 0x22, 0x3f, 0x63, 0x1c, 0x63, 0xbe,
-	0x6c, 0x31, 0xcc, 0x53, 0xc3, 0x2e, 0x83, 0x2d, 0x8f, 0x45, 0xfc, 0x8a, 0x61, 0xb3, 0x96, 0x8a,
-	0xf7, 0x16, 0x5a, 0x75, 0xb1, 0xfd, 0xa2, 0x65, 0xff, 0x44, 0xe6, 0x2d, 0xee, 0x54, 0xb6, 0xeb,
-	0xda, 0xcd, 0x07, 0x4c, 0x21, 0xe6, 0xf6, 0x61, 0x03, 0x15, 0x9c, 0x71, 0x1e, 0x4a, 0x25, 0x8f,
-	0x61, 0x35, 0xe1, 0x3c, 0xcc, 0xda, 0x16, 0xce, 0x6f, 0xe7, 0xf3, 0x4d, 0xa1, 0xa7, 0x4c, 0xe4,
-	0x0b, 0x29, 0x61, 0x77, 0x02, 0xce, 0xa2, 0x80, 0x74, 0xeb, 0x34, 0xe5, 0xb3, 0x24, 0x77, 0x2b,
-	0x12, 0x95, 0xb6, 0x66, 0x2f, 0xb4, 0xb5, 0x3d, 0x68, 0xa5, 0x34, 0x9e, 0xb2, 0xb3, 0x94, 0x4d,
-	0x82, 0x17, 0xe8, 0xa0, 0x0d, 0xcf, 0x64, 0xb9, 0xff, 0xb1, 0xc0, 0xe9, 0xb3, 0x4c, 0xa4, 0x1c,
-	0x9b, 0x82, 0xa0, 0x62, 0x96, 0x49, 0x45, 0x41, 0xec, 0xb3, 0x17, 0xb9, 0x22, 0x24, 0xc8, 0xd1,
-	0x92, 0x2f, 0xde, 0xca, 0xf7, 0xb2, 0xb8, 0x42, 0xee, 0x9c, 0xec, 0x38, 0x16, 0xe9, 0xbc, 0x74,
-	0x0e, 0xd9, 0xaf, 0xc6, 0x8a, 0x54, 0x9c, 0x61, 0x46, 0x4b, 0xf6, 0xcf, 0x14, 0xa3, 0xd5, 0xa7,
-	0x82, 0x6a, 0x30, 0x60, 0x70, 0x76, 0x7f, 0x0a, 0x9b, 0x15, 0x25, 0x66, 0x29, 0xd5, 0xaf, 0x29,
-	0xa5, 0x86, 0x2e, 0xa5, 0x4f, 0xec, 0x8f, 0x2c, 0xf7, 0x6f, 0x56, 0x0e, 0x90, 0x5e, 0x88, 0x94,
-	0x92, 0x0f, 0x61, 0x2d, 0x94, 0x47, 0x7e, 0x1e, 0xa3, 0x07, 0x15, 0xb3, 0x50, 0xe6, 0x00, 0x31,
-	0x81, 0xde, 0x8f, 0x96, 0x26, 0x7d, 0x70, 0xfc, 0x85, 0x9d, 0xa3, 0x2e, 0x23, 0xca, 0x8b, 0x9e,
-	0xf1, 0x96, 0x66, 0xec, 0x7e, 0x0c, 0x2d, 0x63, 0xf1, 0x57, 0x85, 0x1d, 0xb8, 0x8f, 0xdf, 0xc1,
-	0xce, 0x70, 0x7c, 0xc9, 0xfc, 0x59, 0xc8, 0x3e, 0x95, 0xc9, 0xe0, 0xcd, 0x42, 0x76, 0x1b, 0x48,
-	0xc3, 0x8c, 0x29, 0x41, 0x9a, 0x26, 0x8b, 0xde, 0x51, 0x33, 0x7a, 0x87, 0x0b, 0x1b, 0x38, 0x7c,
-	0x34, 0x47, 0xe3, 0x30, 0x02, 0x4d, 0xaf, 0xc2, 0x73, 0x07, 0xe0, 0x78, 0x74, 0x22, 0x9e, 0xb2,
-	0x4c, 0x76, 0xe4, 0x23, 0x2a, 0xc6, 0x97, 0xe4, 0x03, 0x68, 0x44, 0x8a, 0xce, 0xbd, 0x59, 0x82,
-	0x3e, 0x43, 0x56, 0x57, 0x4d, 0x2e, 0xea, 0xfe, 0xb5, 0x06, 0x2d, 0x63, 0xfc, 0x16, 0x14, 0x55,
-	0x54, 0x81, 0x6d, 0x56, 0xc1, 0x3b, 0x50, 0x9f, 0xa4, 0x3c, 0xd2, 0x50, 0xe0, 0x86, 0x22, 0x45,
-	0x11, 0xf2, 0x23, 0xb0, 0x05, 0xc7, 0xfd, 0xdc, 0x28, 0x68, 0x0b, 0x2e, 0xa1, 0xa5, 0xb6, 0x0e,
-	0x01, 0x81, 0x94, 0x55, 0x40, 0xfb, 0xa0, 0xba, 0x87, 0x5c, 0x8a, 0x7c, 0xa4, 0x4f, 0x7c, 0x04,
-	0xdd, 0x88, 0x13, 0x5a, 0x0b, 0x09, 0x8e, 0x23, 0x7a, 0x9a, 0x21, 0x2b, 0xcb, 0x34, 0xc8, 0xce,
-	0x79, 0x34, 0xca, 0x04, 0x8f, 0x99, 0x06, 0x12, 0x26, 0xab, 0xec, 0xa8, 0x0d, 0x2c, 0xe1, 0x6a,
-	0x47, 0x6d, 0x22, 0x4f, 0x7e, 0x4a, 0x34, 0x32, 0x8b, 0x83, 0x2f, 0x67, 0x0c, 0xd1, 0x41, 0xd3,
-	0xd3, 0x14, 0x56, 0x53, 0x9e, 0x24, 0x59, 0xbb, 0xb5, 0x57, 0xdb, 0x6f, 0x7a, 0x06, 0x47, 0x5a,
-	0x30, 0xe6, 0x51, 0x14, 0x88, 0x01, 0xd6, 0xbd, 0x82, 0x00, 0x26, 0x4b, 0xb6, 0x19, 0x89, 0x4b,
-	0x10, 0x8c, 0x29, 0x00, 0x50, 0xd0, 0xee, 0x3f, 0x6b, 0xb0, 0x29, 0xf1, 0x44, 0x76, 0xc9, 0x45,
-	0xef, 0x72, 0x16, 0x3f, 0xbf, 0x05, 0xd5, 0x19, 0x81, 0xb5, 0xab, 0x81, 0x45, 0x8c, 0x81, 0x51,
-	0x18, 0xf4, 0x35, 0xf0, 0x2d, 0x19, 0x32, 0x47, 0x31, 0xc0, 0x0a, 0xb9, 0xa9, 0x48, 0xca, 0x33,
-	0x41, 0xaa, 0x1b, 0xf4, 0x35, 0x66, 0xcb, 0x49, 0xbc, 0xf2, 0xc8, 0x4f, 0x03, 0xb2, 0x95, 0x0c,
-	0xe9, 0x0d, 0x24, 0xd4, 0xa1, 0xa6, 0x90, 0xad, 0xc1, 0x29, 0xfb, 0x5f, 0xc3, 0xec, 0x7f, 0x04,
-	0xea, 0x82, 0xa5, 0x91, 0x46, 0x69, 0xf8, 0x2d, 0xbd, 0x32, 0x09, 0x42, 0x76, 0x46, 0xc5, 0xa5,
-	0xf6, 0x78, 0x41, 0xe7, 0x63, 0x68, 0x82, 0x02, 0x5f, 0x05, 0x2d, 0xfd, 0x2d, 0xbf, 0x7b, 0xda,
-	0x7a, 0xed, 0x6f, 0x83, 0x45, 0xde, 0x82, 0xad, 0x82, 0x54, 0x76, 0x2a, 0xaf, 0x2f, 0x70, 0xa5,
-	0x55, 0xbe, 0xec, 0x90, 0x5b, 0x98, 0x04, 0xf8, 0x2d, 0xed, 0x67, 0xb2, 0x69, 0x21, 0xd4, 0xda,
-	0xf0, 0x14, 0x41, 0x3e, 0x50, 0xd7, 0x40, 0xec, 0xb2, 0x6d, 0x07, 0xd3, 0x73, 0x27, 0x4f, 0xe9,
-	0x5e, 0x3e, 0x50, 0xc0, 0xac, 0x9c, 0xe1, 0xf6, 0x35, 0x5c, 0x1f, 0xf8, 0xf2, 0xb0, 0x95, 0x8e,
-	0x55, 0xb8, 0xa1, 0x08, 0x6d, 0xc9, 0xb8, 0xf9, 0x1e, 0xe8, 0xfe, 0xc3, 0x86, 0x55, 0xac, 0x81,
-	0x1b, 0xdb, 0x53, 0x91, 0xe2, 0xf6, 0x35, 0x29, 0x5e, 0x2b, 0x53, 0xfc, 0x00, 0x56, 0x19, 0x56,
-	0x58, 0xfd, 0x8e, 0x0a, 0x53, 0x62, 0xe5, 0x91, 0xb3, 0x7a, 0xd7, 0x91, 0x63, 0x1e, 0xf6, 0x6b,
-	0xaf, 0x74, 0xd8, 0x97, 0xcd, 0x68, 0xdd, 0x6c, 0x46, 0x65, 0x15, 0x36, 0x6e, 0xa9, 0xc2, 0xe6,
-	0x52, 0x15, 0xfe, 0xb8, 0x38, 0x87, 0x00, 0xd5, 0x6f, 0xe6, 0xea, 0xb1, 0xdd, 0x6a, 0xe5, 0x5a,
-	0xc4, 0x7d, 0x0c, 0x8d, 0x13, 0x3e, 0x55, 0xc5, 0x79, 0xfd, 0x81, 0x9d, 0x27, 0xac, 0x5d, 0x26,
-	0xac, 0xfb, 0x7b, 0x0b, 0x36, 0x71, 0xe7, 0x12, 0x51, 0x60, 0xb2, 0xdc, 0xdc, 0x69, 0x77, 0xa1,
-	0x11, 0x6a, 0x0d, 0x39, 0xb2, 0xc8, 0x69, 0xf2, 0xb1, 0x6c, 0xf3, 0x6a, 0x05, 0xdd, 0x73, 0xbf,
-	0x5b, 0x71, 0xec, 0x09, 0x1f, 0xd3, 0xd0, 0xcc, 0xa8, 0x42, 0xdc, 0xfd, 0x83, 0x05, 0xdb, 0x0b,
-	0x32, 0xe4, 0x1d, 0x58, 0x45, 0xad, 0xfa, 0x16, 0xbf, 0x59, 0x59, 0x2b, 0x8f, 0x27, 0x4a, 0x90,
-	0x4e, 0x1e, 0x4f, 0x1b, 0xe3, 0x79, 0x7f, 0x21, 0x44, 0xb7, 0x80, 0x88, 0xda, 0x22, 0x88, 0x70,
-	0xff, 0x27, 0xb3, 0x52, 0x66, 0xe8, 0x8d, 0x59, 0x89, 0x08, 0x6a, 0x22, 0xba, 0xbe, 0x9f, 0xb2,
-	0x2c, 0xd3, 0x27, 0xb0, 0xc9, 0x22, 0x6f, 0xc2, 0xe6, 0x38, 0x0c, 0x58, 0x5c, 0xc8, 0xa8, 0x53,
-	0xb4, 0xca, 0x34, 0x42, 0x5b, 0xbf, 0x33, 0xb4, 0x37, 0xa7, 0x6c, 0x7e, 0x6d, 0x2e, 0x36, 0x58,
-	0xb9, 0x23, 0xcb, 0x3e, 0x57, 0x33, 0xef, 0xc8, 0xef, 0xc2, 0x4e, 0x48, 0x33, 0xf1, 0x19, 0xa3,
-	0xa9, 0x18, 0x31, 0xaa, 0xa4, 0xd6, 0x51, 0x6a, 0x79, 0x40, 0x26, 0xc2, 0x15, 0x4b, 0xb3, 0x80,
-	0xc7, 0x3a, 0x6d, 0x73, 0x12, 0x21, 0xa6, 0x3a, 0x0a, 0xfa, 0xd8, 0xfd, 0x9a, 0x5e, 0x41, 0x4b,
-	0x17, 0xfb, 0x2c, 0x09, 0xf9, 0xdc, 0xe8, 0x81, 0x06, 0x47, 0x5a, 0xa8, 0x11, 0x0f, 0xf3, 0xb1,
-	0x0d, 0x36, 0xbc, 0x92, 0xe1, 0xfe, 0x29, 0x07, 0x62, 0x99, 0x04, 0xba, 0xe4, 0x51, 0x15, 0x2b,
-	0xff, 0xa0, 0x92, 0x06, 0x28, 0x72, 0x20, 0xff, 0x68, 0x18, 0xa6, 0x64, 0x77, 0x9f, 0x00, 0x94,
-	0xcc, 0x6b, 0x60, 0xe0, 0xdb, 0x26, 0x7c, 0x92, 0x3d, 0x6f, 0x11, 0x80, 0x9b, 0x88, 0xea, 0xef,
-	0x16, 0x34, 0x8b, 0x81, 0x0a, 0xb6, 0xb6, 0x6e, 0xc7, 0xd6, 0xf6, 0x12, 0xb6, 0x26, 0xbf, 0x80,
-	0x6d, 0x1a, 0x86, 0x7c, 0x4c, 0x05, 0xf3, 0xd5, 0x0e, 0xda, 0x35, 0xdc, 0xd7, 0x6b, 0xb9, 0x09,
-	0xdd, 0xca, 0xb0, 0xb7, 0x28, 0x2e, 0x37, 0x93, 0xb1, 0x2f, 0xf5, 0x99, 0x27, 0x3f, 0xf1, 0x65,
-	0x26, 0x17, 0x7a, 0x36, 0x99, 0x64, 0x4c, 0xe8, 0xa3, 0x6f, 0x91, 0xed, 0x4e, 0x60, 0xab, 0xba,
-	0xfc, 0x2d, 0x95, 0xbe, 0x07, 0xad, 0x62, 0x7a, 0x57, 0xe4, 0xaf, 0x62, 0x06, 0x4b, 0xce, 0x4d,
-	0x66, 0x69, 0xc2, 0x33, 0xa6, 0x7b, 0x71, 0x4e, 0xba, 0x7f, 0xce, 0x3b, 0x0a, 0xc6, 0xa7, 0x17,
-	0xf9, 0xe4, 0xbd, 0xca, 0x7d, 0xee, 0x7b, 0xcb, 0x41, 0xec, 0x45, 0xbe, 0x71, 0xb3, 0x7b, 0x04,
-	0x6b, 0xe3, 0x94, 0xe5, 0x15, 0xdd, 0x7a, 0xf8, 0xfd, 0x6b, 0x26, 0xe0, 0x78, 0x2f, 0xf2, 0x3d,
-	0x2d, 0x4a, 0xde, 0x87, 0x55, 0x34, 0x4f, 0x37, 0x9f, 0xdd, 0xe5, 0x39, 0xb8, 0x79, 0x39, 0x45,
-	0x09, 0xba, 0xdf, 0x81, 0x7b, 0xd7, 0x2c, 0xe8, 0xf6, 0x81, 0x2c, 0xcf, 0xb9, 0xe1, 0xaa, 0x65,
-	0x38, 0xc1, 0xae, 0x3a, 0xe1, 0x13, 0xd8, 0xc8, 0x01, 0xd0, 0x20, 0x9e, 0xf0, 0xf2, 0x04, 0xd6,
-	0xf3, 0xd5, 0x09, 0x7c, 0x1f, 0x56, 0xfd, 0x59, 0x14, 0xcd, 0xf3, 0x0b, 0x09, 0x12, 0x9d, 0x8e,
-	0xce, 0x38, 0xe9, 0x12, 0xb2, 0x05, 0x70, 0xc2, 0xa8, 0xcf, 0xd2, 0x67, 0x71, 0x38, 0x77, 0x56,
-	0xc8, 0x26, 0x34, 0xbb, 0x61, 0xa8, 0x2c, 0x74, 0xac, 0xce, 0x43, 0xe3, 0xed, 0x8c, 0x91, 0x35,
-	0xb0, 0x2f, 0x12, 0x67, 0x85, 0x34, 0xa0, 0xde, 0xe7, 0x5f, 0xc5, 0x8e, 0x45, 0x08, 0x6c, 0xe1,
-	0x78, 0x81, 0x1c, 0x1d, 0xbb, 0xf3, 0x4b, 0xe3, 0x79, 0x92, 0x91, 0x16, 0xac, 0x7b, 0xb3, 0x38,
-	0x0e, 0xe2, 0xa9, 0xb3, 0x42, 0x36, 0xa0, 0x81, 0x9e, 0x90, 0x94, 0x25, 0x75, 0x97, 0xd7, 0x15,
-	0xc7, 0x96, 0xba, 0xfb, 0x79, 0xa5, 0x3a, 0xb5, 0xce, 0x10, 0x9c, 0x1e, 0xbe, 0x1a, 0xf7, 0x2e,
-	0x65, 0x92, 0xa3, 0xb9, 0x2d, 0x58, 0xef, 0xfa, 0xfe, 0x29, 0xf7, 0x99, 0xb3, 0x22, 0xe7, 0xab,
-	0x0b, 0x36, 0xd2, 0xb8, 0xde, 0x45, 0xe2, 0x53, 0xa1, 0x68, 0x5b, 0x1a, 0xd7, 0xf5, 0xfd, 0x13,
-	0x46, 0xd3, 0x98, 0xa5, 0xc8, 0xab, 0x75, 0x9e, 0x40, 0xcb, 0x78, 0x0b, 0x26, 0x4d, 0x58, 0xfd,
-	0x82, 0x0b, 0x96, 0x3a, 0x2b, 0x72, 0x69, 0x2d, 0xea, 0x58, 0x64, 0x07, 0x36, 0x07, 0xf1, 0x98,
-	0x47, 0x41, 0x3c, 0x55, 0xe3, 0xb6, 0x64, 0xf5, 0x59, 0xc4, 0x45, 0xc1, 0xaa, 0x75, 0x1e, 0x43,
-	0xab, 0x77, 0xc9, 0xc6, 0xcf, 0xcf, 0x78, 0x18, 0x8c, 0xe7, 0xd2, 0x2d, 0xc3, 0x5e, 0xf7, 0xd4,
-	0x59, 0x21, 0xdb, 0xd0, 0xea, 0x9e, 0x9d, 0x79, 0xcf, 0x7e, 0x3d, 0x78, 0xda, 0x3d, 0x3f, 0x76,
-	0x2c, 0x02, 0xb0, 0x76, 0x31, 0x3c, 0x7e, 0x72, 0xfc, 0x1b, 0xc7, 0xee, 0x9c, 0xc1, 0xd6, 0xb3,
-	0x84, 0xa5, 0x54, 0xf0, 0x54, 0xdf, 0x7f, 0x5b, 0xb0, 0x3e, 0xbc, 0xe8, 0xf5, 0x8e, 0x87, 0x43,
-	0x65, 0xc7, 0xf9, 0xe0, 0xe9, 0xf1, 0xb3, 0x8b, 0x73, 0x35, 0xaf, 0xd7, 0x3d, 0xed, 0x1d, 0x9f,
-	0x38, 0x36, 0x7a, 0xf2, 0xf8, 0xec, 0xa4, 0xdb, 0x3b, 0x76, 0x6a, 0x48, 0x5c, 0x9c, 0x9e, 0x0e,
-	0x4e, 0x3f, 0x75, 0xea, 0x9d, 0x23, 0x58, 0xd7, 0x8f, 0x17, 0x52, 0xb3, 0xf1, 0xe8, 0xe0, 0xac,
-	0x90, 0x7b, 0xb0, 0xad, 0x92, 0xaf, 0xe8, 0x32, 0x6a, 0x7b, 0xbd, 0x59, 0x26, 0x78, 0x34, 0x94,
-	0xbd, 0xbb, 0x2b, 0x1c, 0xbf, 0xf3, 0x08, 0x1a, 0xf9, 0x03, 0x86, 0x5c, 0x5c, 0xcd, 0xf1, 0x95,
-	0x3d, 0xbf, 0xe2, 0xe9, 0x73, 0x15, 0xb2, 0x4d, 0x68, 0xf6, 0x78, 0x94, 0x84, 0x4c, 0x8e, 0xd9,
-	0x9d, 0x9f, 0x57, 0x9e, 0xc7, 0x99, 0x34, 0xf7, 0x94, 0xa7, 0x11, 0x0d, 0x55, 0xac, 0xbb, 0xfa,
-	0xed, 0xcf, 0xb1, 0xc8, 0x7d, 0x70, 0xb4, 0xa4, 0x99, 0x2a, 0x8f, 0x61, 0x67, 0xa9, 0x4a, 0xe5,
-	0x16, 0x0c, 0x8b, 0x55, 0x9c, 0xb1, 0x50, 0x14, 0x6d, 0x1d, 0x39, 0xdf, 0xfc, 0xfb, 0x81, 0xf5,
-	0xf5, 0xcb, 0x07, 0xd6, 0x37, 0x2f, 0x1f, 0x58, 0xff, 0x7a, 0xf9, 0xc0, 0x1a, 0xad, 0xe1, 0xbf,
-	0x21, 0x1e, 0xfd, 0x3f, 0x00, 0x00, 0xff, 0xff, 0x5b, 0xf3, 0x40, 0x81, 0xf8, 0x18, 0x00, 0x00,
+	// 3292 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xcc, 0x5a, 0x4b, 0x6f, 0x24, 0xc7,
+	0x91, 0x66, 0x3f, 0xd8, 0xec, 0x8e, 0x6e, 0x92, 0xc5, 0x9c, 0xd1, 0xa8, 0xc5, 0xd5, 0x8e, 0x88,
+	0x5a, 0xad, 0x44, 0x51, 0x12, 0x29, 0xcd, 0x8c, 0xb4, 0x7a, 0x2c, 0x84, 0x6d, 0x36, 0x29, 0x89,
+	0x1a, 0x0e, 0x87, 0xaa, 0xe6, 0x48, 0xbb, 0xc0, 0x5e, 0x92, 0x5d, 0xd9, 0xcd, 0xc2, 0x54, 0x57,
+	0x96, 0xaa, 0xb2, 0xc9, 0xa1, 0x01, 0xc3, 0x3e, 0xf9, 0xe0, 0x83, 0x0f, 0xfe, 0x05, 0xba, 0x18,
+	0xf0, 0xc1, 0x80, 0x7f, 0x80, 0x6f, 0x86, 0x0d, 0xeb, 0x28, 0xff, 0x01, 0xc1, 0x9e, 0xb3, 0xff,
+	0x82, 0x01, 0x23, 0x23, 0x33, 0xab, 0xb2, 0xaa, 0xf9, 0x18, 0x19, 0x36, 0xe0, 0x0b, 0x59, 0xf1,
+	0x65, 0xe4, 0x33, 0x1e, 0x19, 0x11, 0xd9, 0xd0, 0x99, 0x30, 0x41, 0xe3, 0xe3, 0xcd, 0x38, 0xe1,
+	0x82, 0x93, 0x86, 0xa2, 0x56, 0xdf, 0x1c, 0x07, 0xe2, 0x64, 0x7a, 0xbc, 0x39, 0xe4, 0x93, 0xad,
+	0x31, 0x1f, 0xf3, 0x2d, 0x6c, 0x3e, 0x9e, 0x8e, 0x90, 0x42, 0x02, 0xbf, 0x54, 0xb7, 0xd5, 0xd7,
+	0xc6, 0x7c, 0x93, 0x89, 0xa1, 0xbf, 0x19, 0xf0, 0x2d, 0xf9, 0x7f, 0x2b, 0xa1, 0x23, 0xb1, 0x75,
+	0x7a, 0x17, 0xff, 0xc7, 0xc7, 0xf8, 0x4f, 0xb1, 0xba, 0x9f, 0x01, 0x0c, 0x4e, 0x68, 0xe2, 0xef,
+	0xc6, 0x7c, 0x78, 0x42, 0x5e, 0x84, 0xd6, 0x90, 0x47, 0xa3, 0x60, 0xfc, 0x05, 0x4b, 0xba, 0x95,
+	0xb5, 0xca, 0x7a, 0xdd, 0xcb, 0x01, 0x72, 0x1b, 0x60, 0xcc, 0x22, 0x96, 0x50, 0x11, 0xf0, 0xa8,
+	0x5b, 0xc5, 0x66, 0x0b, 0x71, 0x7f, 0x59, 0x81, 0x05, 0x8f, 0xc5, 0x61, 0x30, 0xa4, 0xe4, 0x16,
+	0x54, 0x03, 0x5f, 0x0d, 0xb1, 0xdd, 0x78, 0xfa, 0xdd, 0x4b, 0xd5, 0xbd, 0x1d, 0xaf, 0x1a, 0xf8,
+	0xa4, 0x0b, 0x0b, 0xa9, 0xe0, 0x09, 0xdb, 0xdb, 0xd1, 0x03, 0x18, 0x92, 0xbc, 0x0a, 0xf5, 0x84,
+	0x87, 0xac, 0x5b, 0x5b, 0xab, 0xac, 0x2f, 0xdd, 0xb9, 0xb1, 0xa9, 0x0f, 0x42, 0x0f, 0xe8, 0xf1,
+	0x90, 0x79, 0xc8, 0x40, 0x5e, 0x86, 0xc5, 0x20, 0x0a, 0x44, 0x40, 0xc3, 0x07, 0x6c, 0x72, 0xcc,
+	0x92, 0x6e, 0x7d, 0xad, 0xb2, 0xde, 0xf4, 0x8a, 0xa0, 0xdc, 0x4a, 0x90, 0x7e, 0x19, 0x88, 0x88,
+	0xa5, 0x69, 0x77, 0x1e, 0x39, 0x72, 0xc0, 0xa5, 0xd0, 0xd1, 0x03, 0x0f, 0x04, 0x15, 0x29, 0xd9,
+	0x82, 0x85, 0x44, 0xd1, 0xb8, 0xe6, 0xf6, 0x9d, 0xe5, 0xd2, 0xfc, 0xdb, 0xf5, 0x6f, 0xbe, 0x7b,
+	0x69, 0xce, 0x33, 0x5c, 0x64, 0x0d, 0xda, 0x3e, 0x3f, 0x8b, 0x06, 0x6c, 0xc8, 0x23, 0x3f, 0xd5,
+	0x7b, 0xb1, 0x21, 0x77, 0x0b, 0xe6, 0xf7, 0xe9, 0x31, 0x0b, 0x89, 0x03, 0xb5, 0xc7, 0xec, 0x1c,
+	0xc7, 0x6d, 0x79, 0xf2, 0x93, 0xdc, 0x84, 0xf9, 0x53, 0x1a, 0x4e, 0x19, 0x76, 0x6b, 0x79, 0x8a,
+	0x70, 0xff, 0x58, 0xd7, 0xb2, 0x50, 0x4b, 0x92, 0x27, 0x25, 0xa9, 0xbd, 0x1d, 0x2d, 0x09, 0x43,
+	0x12, 0x17, 0x3a, 0x67, 0x49, 0x20, 0x04, 0x8b, 0xb6, 0xcf, 0x05, 0x33, 0x93, 0x17, 0x30, 0xb9,
+	0x3e, 0x4d, 0xdf, 0x67, 0xe7, 0x29, 0x1e, 0x6a, 0xdd, 0xb3, 0x21, 0x79, 0x40, 0x09, 0xa3, 0xbe,
+	0x1a, 0xa2, 0xae, 0x64, 0x9d, 0x01, 0x64, 0x15, 0x9a, 0x92, 0xc0, 0xce, 0xf3, 0xd8, 0x98, 0xd1,
+	0x64, 0x1d, 0x96, 0x69, 0x1c, 0x27, 0xfc, 0x49, 0x30, 0xa1, 0x82, 0x0d, 0x82, 0x1f, 0xb0, 0x6e,
+	0x03, 0x59, 0xca, 0x70, 0x89, 0x13, 0x07, 0x5b, 0x98, 0xe1, 0xc4, 0x31, 0xdf, 0x82, 0x66, 0x10,
+	0x09, 0x96, 0x9c, 0xd2, 0xb0, 0xdb, 0x44, 0x09, 0xdc, 0x34, 0x12, 0x38, 0x0a, 0x26, 0x6c, 0x4f,
+	0xb7, 0x79, 0x19, 0x97, 0xd4, 0xc6, 0x84, 0xa5, 0x3c, 0x3c, 0x65, 0xfe, 0xd1, 0xa0, 0xdb, 0x52,
+	0xda, 0x98, 0x23, 0x64, 0x13, 0x48, 0xc2, 0x86, 0xfc, 0x94, 0x25, 0x41, 0x34, 0xd6, 0x52, 0x4c,
+	0xbb, 0xb0, 0x56, 0x5b, 0xaf, 0x7b, 0x17, 0xb4, 0x10, 0x02, 0x75, 0xc1, 0x92, 0x49, 0xb7, 0x8d,
+	0x23, 0xe1, 0xb7, 0x3c, 0xc5, 0x21, 0x9f, 0x4c, 0x02, 0xb1, 0x17, 0xf9, 0xec, 0x49, 0xb7, 0xa3,
+	0x4e, 0xd1, 0x82, 0xa4, 0x2c, 0x68, 0x1c, 0x87, 0x01, 0xf3, 0x15, 0xcb, 0xa2, 0x92, 0x85, 0x8d,
+	0x91, 0x57, 0x60, 0x49, 0x24, 0xd3, 0x68, 0x48, 0x85, 0xe1, 0x5a, 0x42, 0xae, 0x12, 0x4a, 0x3e,
+	0x87, 0x1b, 0x5a, 0xbd, 0x1e, 0x50, 0x31, 0x3c, 0x41, 0x90, 0xa5, 0xdd, 0xe5, 0xb5, 0xda, 0x7a,
+	0xfb, 0xce, 0x0b, 0x25, 0x85, 0xcc, 0x59, 0xb4, 0x6a, 0x5e, 0xd4, 0xd7, 0xfd, 0x1c, 0x56, 0x66,
+	0xf8, 0x95, 0xe4, 0x11, 0xcc, 0x74, 0x2b, 0x07, 0xe4, 0xb9, 0x4e, 0x32, 0x5e, 0x63, 0xe5, 0x39,
+	0xe2, 0xfe, 0x65, 0x01, 0x60, 0x20, 0x6d, 0x36, 0x57, 0x53, 0x6d, 0xd0, 0x95, 0xa2, 0x41, 0xbf,
+	0x08, 0xad, 0x54, 0xd0, 0x44, 0x48, 0xf9, 0xe9, 0x71, 0x72, 0xa0, 0x20, 0xf0, 0xda, 0x33, 0x09,
+	0x7c, 0x15, 0x9a, 0x43, 0x1a, 0xd3, 0x61, 0x20, 0xce, 0xb5, 0xbe, 0x66, 0xb4, 0x9c, 0x8b, 0x9e,
+	0xd2, 0x20, 0xa4, 0xc7, 0x21, 0xd3, 0xfa, 0x9a, 0x03, 0xb2, 0xe7, 0x34, 0x65, 0xbe, 0xa5, 0xa9,
+	0x19, 0x4d, 0x6e, 0x41, 0x23, 0x48, 0xb7, 0xa7, 0xe9, 0x39, 0x6a, 0x66, 0xd3, 0xd3, 0x94, 0x3c,
+	0x06, 0xb4, 0xb7, 0x3e, 0x9f, 0x46, 0x02, 0x55, 0xb2, 0xee, 0x59, 0x08, 0xd9, 0x00, 0x27, 0x65,
+	0x91, 0x1f, 0x44, 0xe3, 0x41, 0x44, 0x63, 0xc5, 0xa5, 0x94, 0x70, 0x06, 0xd7, 0xaa, 0xc8, 0x82,
+	0xd3, 0x02, 0x37, 0x20, 0xf7, 0x05, 0x2d, 0xe4, 0x0d, 0x58, 0x91, 0x0a, 0x74, 0x5e, 0x60, 0x57,
+	0x7a, 0x39, 0xdb, 0x30, 0xe3, 0x0e, 0x3a, 0x17, 0xb8, 0x83, 0x82, 0xb1, 0x2f, 0x96, 0x8d, 0xbd,
+	0xe4, 0x2c, 0x96, 0x66, 0x9d, 0x85, 0xed, 0x0e, 0x96, 0x4b, 0xee, 0xe0, 0x5d, 0x68, 0x0d, 0xe3,
+	0xe9, 0xa3, 0x94, 0x8e, 0x59, 0xda, 0x75, 0x50, 0x59, 0x49, 0xae, 0xac, 0x43, 0x9e, 0xf8, 0x87,
+	0x34, 0x48, 0xb4, 0x96, 0xe6, 0xac, 0xe4, 0x03, 0x68, 0xcb, 0x31, 0xf6, 0x1e, 0x7a, 0x54, 0xae,
+	0x6a, 0xe5, 0x9a, 0x9e, 0x36, 0x33, 0xf9, 0x6f, 0xb5, 0x67, 0x66, 0x3a, 0x93, 0x6b, 0x3a, 0x17,
+	0xb8, 0xe5, 0xcc, 0x3c, 0xde, 0xa7, 0x82, 0x45, 0xc3, 0x80, 0xa5, 0xdd, 0x1b, 0xd7, 0xcd, 0x6c,
+	0x31, 0x4b, 0x97, 0x16, 0x32, 0xea, 0xb3, 0x64, 0xc0, 0x47, 0x62, 0x3f, 0x98, 0x04, 0xa2, 0x7b,
+	0x53, 0xb9, 0xb4, 0x12, 0x2c, 0xef, 0xa9, 0x54, 0xf0, 0x38, 0x66, 0xfe, 0x27, 0x09, 0x9f, 0xc6,
+	0x69, 0xf7, 0x39, 0xf4, 0x3d, 0x45, 0x50, 0xca, 0x3a, 0x8d, 0x68, 0x9c, 0x9e, 0x70, 0x71, 0x74,
+	0x92, 0x70, 0x21, 0x42, 0xe6, 0x77, 0x6f, 0xa1, 0x2a, 0xce, 0x36, 0x90, 0x03, 0x20, 0x06, 0x3c,
+	0x4c, 0xf8, 0x38, 0x61, 0x69, 0xca, 0xd2, 0xee, 0xf3, 0xb8, 0x81, 0xae, 0xd9, 0xc0, 0xa0, 0xc4,
+	0xa1, 0xb7, 0x71, 0x41, 0x4f, 0x29, 0xf9, 0x09, 0x95, 0x16, 0x16, 0xd1, 0x68, 0xc8, 0xba, 0x5d,
+	0x9c, 0xd7, 0x86, 0xdc, 0xbf, 0x56, 0xc1, 0x29, 0x0f, 0x78, 0x85, 0xd1, 0x5b, 0xb7, 0x56, 0xb5,
+	0x78, 0x6b, 0xbd, 0x06, 0xf5, 0x51, 0xc2, 0x27, 0xdd, 0xda, 0x55, 0xf7, 0x2b, 0xb2, 0x90, 0xff,
+	0x84, 0xaa, 0xe0, 0xdd, 0xfa, 0x55, 0x8c, 0x55, 0xc1, 0xe5, 0x35, 0x1a, 0xa0, 0x93, 0x52, 0x06,
+	0xaf, 0x08, 0x5c, 0x81, 0x32, 0x40, 0xb4, 0xf5, 0xa6, 0x67, 0x48, 0x69, 0xd2, 0x82, 0x0b, 0x1a,
+	0x2a, 0x2b, 0x50, 0x17, 0x91, 0x85, 0x48, 0x93, 0x16, 0x09, 0x8d, 0xd2, 0x11, 0x4b, 0x12, 0xa6,
+	0x6d, 0x45, 0x19, 0xfe, 0x0c, 0x5e, 0x74, 0x6e, 0xd2, 0xee, 0x6b, 0xb6, 0x73, 0x23, 0x50, 0x4f,
+	0xa8, 0x60, 0xda, 0xc4, 0xf1, 0x9b, 0xbc, 0x00, 0x35, 0x26, 0xa8, 0x32, 0xe3, 0xed, 0x85, 0xa7,
+	0xdf, 0xbd, 0x54, 0xdb, 0x3d, 0xea, 0x79, 0x12, 0x93, 0xd6, 0xc5, 0x63, 0x96, 0x50, 0xc1, 0x13,
+	0xb4, 0xde, 0x96, 0x97, 0xd1, 0xee, 0x3d, 0x80, 0x5c, 0x21, 0xaf, 0x8b, 0x25, 0xea, 0x26, 0x96,
+	0xf8, 0x14, 0x1a, 0x3a, 0x0e, 0xba, 0x2c, 0x10, 0x23, 0x50, 0x8f, 0xe8, 0xc4, 0x84, 0x20, 0xf8,
+	0x2d, 0x31, 0xea, 0xfb, 0x09, 0x8a, 0xa8, 0xe5, 0xe1, 0xb7, 0xeb, 0xc1, 0xd2, 0x61, 0xc2, 0xe3,
+	0x13, 0x26, 0xfa, 0xe1, 0x34, 0x15, 0x57, 0x8c, 0xb8, 0x0e, 0xcb, 0x13, 0xfa, 0x44, 0x8b, 0x49,
+	0xf9, 0x2c, 0x39, 0xf8, 0xa2, 0x57, 0x86, 0xdd, 0x77, 0xa1, 0x63, 0xfb, 0x78, 0xb9, 0x07, 0x3c,
+	0x3b, 0xad, 0x4c, 0x8a, 0x90, 0x7b, 0x65, 0x91, 0xaf, 0xf7, 0x25, 0x3f, 0xdd, 0x10, 0x6a, 0x9f,
+	0xf1, 0x63, 0xf2, 0x1f, 0x50, 0x17, 0xe7, 0x31, 0x43, 0xee, 0xa5, 0x5c, 0x41, 0x3e, 0xe3, 0xc7,
+	0x47, 0xe7, 0x31, 0xf3, 0xb0, 0x51, 0xaa, 0xc1, 0x90, 0x4b, 0x2d, 0x56, 0xab, 0xe8, 0x78, 0x86,
+	0x24, 0xaf, 0xe0, 0x6c, 0xc2, 0x44, 0x9a, 0x8e, 0xd5, 0x5f, 0x5e, 0x69, 0xcc, 0x53, 0xcd, 0x2e,
+	0x83, 0x25, 0x8f, 0x4d, 0xf8, 0x29, 0xc3, 0xa0, 0x4c, 0x4e, 0xbc, 0x56, 0x0a, 0xc9, 0xb2, 0xed,
+	0x1b, 0x98, 0xbc, 0x2d, 0xfd, 0xa4, 0x0e, 0x35, 0xaa, 0x68, 0x95, 0x97, 0xe8, 0x6f, 0xc6, 0xe6,
+	0xee, 0x40, 0x07, 0x27, 0x38, 0xe4, 0x3c, 0x94, 0x93, 0xdc, 0x83, 0xf9, 0x98, 0xf3, 0x30, 0xed,
+	0x56, 0x4a, 0x56, 0x6d, 0x31, 0x3d, 0x60, 0xc2, 0x0c, 0xa4, 0x98, 0xdd, 0x11, 0x38, 0x65, 0x06,
+	0x79, 0xac, 0x63, 0xe9, 0x64, 0xcc, 0xb1, 0x22, 0x51, 0xb8, 0x46, 0xab, 0xa5, 0x6b, 0x74, 0x0d,
+	0xda, 0x09, 0x8d, 0xc6, 0xec, 0x30, 0x61, 0xa3, 0xe0, 0x09, 0x1e, 0x50, 0xc7, 0xb3, 0x21, 0xf7,
+	0xd7, 0x55, 0x70, 0x76, 0x58, 0x2a, 0x12, 0x8e, 0x97, 0x90, 0xa0, 0x62, 0x9a, 0xe6, 0x86, 0x58,
+	0xb1, 0x0d, 0x71, 0x7b, 0xe6, 0x2c, 0x5e, 0x31, 0x7b, 0x29, 0x8f, 0x60, 0x0e, 0x27, 0xdd, 0x8d,
+	0x44, 0x72, 0x9e, 0x1f, 0x0e, 0x59, 0x2f, 0xca, 0x8a, 0x14, 0x0e, 0xc3, 0x96, 0x96, 0x0a, 0x07,
+	0xa5, 0xb4, 0x76, 0xa8, 0xa0, 0x3a, 0x25, 0xb0, 0x10, 0x4c, 0x6d, 0x12, 0x46, 0x05, 0xf3, 0x7b,
+	0x02, 0x1d, 0x46, 0xcd, 0xcb, 0x01, 0xd9, 0x3a, 0x8d, 0x7d, 0xdd, 0xda, 0x50, 0xad, 0x19, 0xb0,
+	0xfa, 0x21, 0x2c, 0x16, 0x16, 0x68, 0x9b, 0x61, 0xfd, 0x02, 0x33, 0x6c, 0x6a, 0x33, 0xfc, 0xa0,
+	0xfa, 0x5e, 0xc5, 0xfd, 0x7d, 0xc5, 0xa4, 0x58, 0x4f, 0x44, 0x42, 0xc9, 0xbb, 0xd0, 0x08, 0x65,
+	0x5a, 0x60, 0xe4, 0x7b, 0xbb, 0xb0, 0x25, 0xe4, 0xd9, 0xc4, 0xbc, 0x41, 0x9f, 0x85, 0xe6, 0x26,
+	0x3b, 0xe0, 0xf8, 0xa5, 0x53, 0xc3, 0xb9, 0x2c, 0x0d, 0x29, 0x9f, 0xaa, 0x37, 0xd3, 0x63, 0xf5,
+	0x7d, 0x68, 0x5b, 0x83, 0x3f, 0x6b, 0x6a, 0x82, 0xfb, 0xf8, 0x21, 0xac, 0x0c, 0x86, 0x27, 0xcc,
+	0x9f, 0x86, 0x0c, 0xaf, 0x2e, 0x6f, 0x1a, 0xb2, 0xab, 0xd2, 0x3c, 0xd4, 0xb6, 0xfc, 0x1a, 0xd0,
+	0x64, 0xe6, 0x77, 0x6a, 0x96, 0xdf, 0x71, 0xa1, 0x83, 0xcd, 0xdb, 0xe7, 0xb8, 0x38, 0x94, 0x5e,
+	0xcb, 0x2b, 0x60, 0xee, 0x8f, 0x60, 0xd9, 0x93, 0x7a, 0xe8, 0xb1, 0x90, 0x0f, 0x31, 0xdf, 0xbc,
+	0x74, 0xf2, 0x4c, 0xef, 0xab, 0xb6, 0xde, 0x67, 0x4e, 0x46, 0x69, 0x75, 0xd1, 0xc9, 0xd4, 0x11,
+	0x93, 0x9f, 0x32, 0x20, 0xc4, 0xcb, 0x4c, 0xe6, 0x3d, 0xf2, 0xbe, 0xd6, 0x94, 0xfb, 0xf3, 0x0a,
+	0x2c, 0xe2, 0x52, 0x06, 0x82, 0x61, 0xe6, 0xf0, 0x4f, 0x9a, 0xff, 0xf5, 0x4c, 0x41, 0xe6, 0x51,
+	0x41, 0x16, 0x8d, 0x78, 0x71, 0x72, 0x6d, 0xf5, 0x9a, 0xc5, 0xfd, 0x49, 0x05, 0x1c, 0x8f, 0x8e,
+	0xc4, 0x03, 0x96, 0xca, 0xa0, 0x6a, 0x5b, 0x86, 0xe9, 0xe4, 0x1d, 0x68, 0x4e, 0x14, 0x6d, 0x94,
+	0x2c, 0xcf, 0xa6, 0x2d, 0x5e, 0xed, 0x88, 0x0c, 0x2b, 0xf9, 0x10, 0xe0, 0x84, 0xd1, 0x44, 0x1c,
+	0x33, 0x2a, 0x8c, 0xc5, 0x3e, 0x67, 0x77, 0xfc, 0xd4, 0xb4, 0xea, 0xae, 0x16, 0xbb, 0xfb, 0x9b,
+	0x1a, 0x2c, 0x16, 0x78, 0xae, 0xc8, 0x5f, 0x2f, 0x3e, 0x9f, 0x7f, 0x7c, 0x7c, 0x80, 0x41, 0x6b,
+	0x1a, 0xf3, 0x28, 0x65, 0xba, 0x02, 0x90, 0xd1, 0x59, 0xb6, 0xd7, 0xb0, 0xb2, 0xbd, 0x5b, 0xd0,
+	0x50, 0xa9, 0x9d, 0x8e, 0x0d, 0x34, 0x45, 0xde, 0xd3, 0xa9, 0x00, 0xd6, 0x48, 0x74, 0x76, 0x5a,
+	0xf4, 0x44, 0xd8, 0x62, 0x4e, 0x25, 0xe7, 0x2d, 0xe7, 0x8f, 0xad, 0xeb, 0xf3, 0x47, 0xb8, 0x20,
+	0x7f, 0x2c, 0x66, 0xba, 0xed, 0x99, 0x4c, 0xf7, 0x65, 0x58, 0x34, 0x94, 0x9d, 0xa7, 0x16, 0x41,
+	0x79, 0x1a, 0x32, 0x10, 0xc2, 0x80, 0x45, 0x65, 0x00, 0x19, 0xed, 0xfe, 0xae, 0x0e, 0x6d, 0x4b,
+	0x35, 0xfe, 0x05, 0x64, 0xb7, 0x05, 0x0b, 0x5a, 0x31, 0xbb, 0xf3, 0x9a, 0x57, 0x15, 0xaf, 0x36,
+	0x8b, 0xea, 0x6b, 0xb8, 0x4a, 0x42, 0x6a, 0x7c, 0x3f, 0x21, 0x05, 0xe9, 0x11, 0x9f, 0x1c, 0xa7,
+	0x82, 0x47, 0x4c, 0xa7, 0x81, 0x36, 0x94, 0x9b, 0x6e, 0xf3, 0x02, 0xd3, 0x6d, 0x15, 0x5c, 0xc7,
+	0x34, 0x0a, 0xbe, 0x9a, 0xaa, 0xc0, 0xaf, 0xe5, 0x69, 0x0a, 0x05, 0x68, 0xdc, 0x66, 0xda, 0x6d,
+	0xaf, 0xd5, 0xd6, 0x5b, 0x9e, 0x85, 0x3c, 0x43, 0x99, 0xe1, 0x0a, 0xe1, 0x95, 0xd4, 0x63, 0xe9,
+	0x7a, 0xf5, 0x58, 0xbe, 0x48, 0x3d, 0x6e, 0x03, 0x9c, 0xd1, 0x64, 0x32, 0x8d, 0x31, 0xc7, 0x93,
+	0x69, 0x5c, 0xc7, 0xb3, 0x90, 0x19, 0x45, 0x5d, 0x99, 0x55, 0x54, 0xf7, 0xeb, 0x3a, 0x2c, 0x9a,
+	0x5c, 0xa1, 0x7f, 0x32, 0x8d, 0x1e, 0xff, 0x5d, 0x89, 0x42, 0xa1, 0x3c, 0x51, 0x2b, 0x97, 0x27,
+	0x88, 0x56, 0x35, 0x55, 0x01, 0xc0, 0x6f, 0x8c, 0xf5, 0xe4, 0x74, 0x7b, 0x3b, 0x3a, 0x15, 0x30,
+	0x24, 0xde, 0xfa, 0xf2, 0xd3, 0x4a, 0xfd, 0x73, 0x40, 0xee, 0x19, 0x09, 0x15, 0xac, 0xea, 0x84,
+	0x20, 0x47, 0xf2, 0xb8, 0xa6, 0x69, 0xc7, 0x35, 0xc6, 0x75, 0xb4, 0x2c, 0xd7, 0xb1, 0x0a, 0xcd,
+	0x51, 0x10, 0xb2, 0x43, 0x2a, 0x4e, 0xb4, 0xec, 0x33, 0xda, 0xb4, 0xe1, 0x12, 0x94, 0xf1, 0x66,
+	0xb4, 0x94, 0xbc, 0xfc, 0xee, 0xeb, 0xd5, 0x6b, 0xc9, 0x5b, 0x90, 0x2c, 0x1e, 0x65, 0xa4, 0x5a,
+	0xa7, 0x92, 0x7f, 0x09, 0x95, 0xab, 0xf2, 0xa9, 0xa0, 0x28, 0xff, 0x8e, 0x87, 0xdf, 0x72, 0xfd,
+	0x4c, 0x06, 0x14, 0x28, 0xf1, 0x8e, 0xa7, 0x08, 0xf2, 0x8e, 0x2a, 0xf2, 0x62, 0xf4, 0xd4, 0x75,
+	0xd0, 0x50, 0x56, 0x8c, 0x71, 0xf5, 0x4d, 0x43, 0x96, 0xae, 0x1b, 0x40, 0x2a, 0x80, 0x49, 0x20,
+	0x71, 0x2b, 0x5a, 0x01, 0x6c, 0x0c, 0xb7, 0x93, 0xf0, 0xc9, 0x40, 0x8b, 0x9c, 0xe8, 0xed, 0xe4,
+	0x90, 0xbb, 0xa3, 0x8b, 0x47, 0x7b, 0xbe, 0x0c, 0xc5, 0xa5, 0x78, 0x54, 0x56, 0x91, 0x57, 0xa2,
+	0x32, 0xe0, 0xf2, 0x5a, 0xb1, 0xfb, 0xdb, 0x1a, 0xcc, 0xa3, 0x4d, 0x5f, 0x75, 0x07, 0x2b, 0x93,
+	0xad, 0x5e, 0x60, 0xb2, 0xb5, 0xdc, 0x64, 0x37, 0x61, 0x9e, 0xa1, 0xc7, 0xa8, 0x5f, 0xe3, 0x31,
+	0x14, 0x5b, 0x1e, 0x90, 0xce, 0x5f, 0x17, 0x90, 0xda, 0xa9, 0x40, 0xe3, 0x99, 0x52, 0x81, 0xdc,
+	0xb9, 0x2e, 0xd8, 0xce, 0x35, 0xf7, 0x2a, 0xcd, 0x2b, 0xbc, 0x4a, 0x6b, 0xc6, 0xab, 0xe4, 0x81,
+	0x04, 0x5c, 0x1b, 0x48, 0x60, 0x78, 0x39, 0x4d, 0xe8, 0x71, 0x10, 0x06, 0xe2, 0xfc, 0x90, 0x87,
+	0xc1, 0xf0, 0x1c, 0x95, 0x75, 0xc9, 0x0a, 0x2f, 0x4b, 0xed, 0xde, 0x4c, 0x0f, 0xf2, 0x3a, 0xd4,
+	0xe8, 0x30, 0x44, 0x35, 0x6e, 0xdf, 0x71, 0x0a, 0x67, 0xd3, 0xeb, 0xef, 0xab, 0xac, 0xb7, 0xd7,
+	0xdf, 0xf7, 0x24, 0x97, 0x3b, 0x82, 0xa6, 0x69, 0x91, 0x3b, 0xe7, 0x67, 0x91, 0x7e, 0x74, 0x68,
+	0x79, 0x8a, 0x20, 0x3b, 0xb0, 0x42, 0xc3, 0x90, 0x9f, 0x31, 0xff, 0x61, 0xac, 0x1f, 0x19, 0x54,
+	0x60, 0xb2, 0x74, 0xe7, 0x96, 0x19, 0x3c, 0x6b, 0xe9, 0x87, 0x34, 0x4d, 0xbd, 0xd9, 0x0e, 0xee,
+	0x3d, 0x68, 0xee, 0xf3, 0xb1, 0xf2, 0x72, 0x17, 0x67, 0x2a, 0xc6, 0xa2, 0xab, 0xb9, 0x45, 0xbb,
+	0x3f, 0xae, 0xc0, 0x22, 0x2e, 0x4f, 0xa6, 0x52, 0x68, 0x4d, 0x97, 0x5f, 0x8a, 0xab, 0xd0, 0x0c,
+	0xf5, 0x0c, 0x26, 0xa5, 0x32, 0x34, 0x79, 0x5f, 0x06, 0x63, 0x6a, 0x04, 0x7d, 0x3d, 0x3e, 0x5f,
+	0x38, 0x97, 0x7d, 0x3e, 0xa4, 0xa1, 0x6d, 0x72, 0x19, 0xbb, 0xfb, 0xab, 0x2a, 0x2c, 0x97, 0x78,
+	0xc8, 0x6b, 0x30, 0x8f, 0xb3, 0xea, 0x67, 0x8a, 0xc5, 0xc2, 0x58, 0x46, 0x55, 0x91, 0x83, 0x6c,
+	0x18, 0x55, 0xad, 0xa2, 0x1c, 0x6f, 0x96, 0xb4, 0xef, 0x8a, 0xec, 0xa9, 0x36, 0x93, 0x3d, 0xc9,
+	0x3a, 0x11, 0x4b, 0xc6, 0xec, 0x88, 0x26, 0x63, 0x26, 0xb4, 0xf3, 0xb5, 0x21, 0x39, 0xc2, 0x88,
+	0x45, 0x43, 0xb6, 0x67, 0x55, 0x64, 0x2c, 0x44, 0x2a, 0x98, 0xc5, 0xfe, 0x6c, 0xb7, 0xf4, 0x4c,
+	0x0f, 0x79, 0xd2, 0x93, 0x60, 0x9c, 0x50, 0xc1, 0x7c, 0x7d, 0x51, 0x67, 0xb4, 0x7b, 0x06, 0x4b,
+	0xdb, 0x74, 0xf8, 0x78, 0x1a, 0x3f, 0xa0, 0x51, 0x30, 0x62, 0xa9, 0xb8, 0x24, 0x01, 0x2e, 0x64,
+	0x82, 0xd5, 0x72, 0x26, 0xf8, 0x36, 0x34, 0xf0, 0xf8, 0xe4, 0x9b, 0x49, 0x21, 0x74, 0x56, 0x27,
+	0x8c, 0x13, 0x18, 0xdb, 0x51, 0x8c, 0xee, 0x31, 0xb4, 0xad, 0xc6, 0xef, 0x23, 0xa2, 0x4c, 0x1d,
+	0xab, 0x25, 0x75, 0x8c, 0xe5, 0x45, 0xa2, 0x53, 0x24, 0xf9, 0xed, 0x7e, 0x2d, 0x3d, 0x9e, 0xf4,
+	0x7e, 0x97, 0x7a, 0x3c, 0xcc, 0xdd, 0x47, 0xa2, 0xe7, 0xfb, 0xb2, 0x44, 0xa7, 0xf3, 0x37, 0x1b,
+	0x92, 0x81, 0xc0, 0x30, 0x0c, 0x58, 0x94, 0xf1, 0xa8, 0x09, 0x8a, 0xa0, 0xe5, 0x36, 0xea, 0xd7,
+	0xbb, 0x8d, 0x4b, 0xdd, 0xa1, 0x79, 0x20, 0xc8, 0x34, 0xac, 0x50, 0x30, 0x6b, 0x94, 0x0b, 0x66,
+	0x6f, 0xc0, 0x4a, 0x48, 0xd3, 0x3c, 0x7b, 0x40, 0xae, 0x05, 0xe4, 0x9a, 0x6d, 0x90, 0x96, 0x78,
+	0xca, 0x92, 0x54, 0xbe, 0x42, 0x2a, 0x97, 0x68, 0x48, 0x2c, 0x6e, 0xa8, 0xb0, 0x69, 0x07, 0xef,
+	0xe7, 0x96, 0x97, 0xd1, 0x52, 0x43, 0x7d, 0x16, 0x87, 0xfc, 0xdc, 0xba, 0xa5, 0x2d, 0x44, 0xae,
+	0x50, 0xe7, 0xcb, 0xcc, 0x47, 0xdf, 0xd7, 0xf4, 0x72, 0xa0, 0x5c, 0x29, 0xed, 0xcc, 0x56, 0x4a,
+	0x7f, 0x66, 0x12, 0xfd, 0x54, 0x16, 0x61, 0xc8, 0xdd, 0x62, 0x1d, 0xe7, 0xdf, 0x0b, 0x6a, 0x80,
+	0x2c, 0x9b, 0xf2, 0x8f, 0x4e, 0xf3, 0x15, 0xef, 0xea, 0x7d, 0x80, 0x1c, 0xbc, 0xa0, 0xcc, 0xf0,
+	0xaa, 0x9d, 0x9e, 0xcb, 0x7b, 0xbb, 0x5c, 0x1c, 0xb2, 0x33, 0xf6, 0x3f, 0x54, 0xa0, 0x95, 0x35,
+	0x14, 0xea, 0x3e, 0x95, 0xab, 0xeb, 0x3e, 0xd5, 0x99, 0xba, 0x0f, 0xf9, 0x1f, 0x58, 0x96, 0x9e,
+	0x15, 0x5f, 0xab, 0x06, 0xb6, 0x7d, 0x64, 0x8e, 0xb8, 0x57, 0x68, 0xf6, 0xca, 0xec, 0x72, 0x33,
+	0x29, 0xfb, 0x4a, 0xbb, 0x0e, 0xf9, 0x89, 0xaf, 0x83, 0x86, 0xe9, 0xe1, 0x68, 0x94, 0x32, 0xa1,
+	0xfd, 0x46, 0x19, 0x76, 0x47, 0xb0, 0x54, 0x1c, 0xfe, 0x0a, 0x67, 0xbc, 0x06, 0xed, 0xac, 0xbb,
+	0x36, 0xf0, 0xba, 0x67, 0x43, 0xb2, 0x6f, 0x3c, 0x4d, 0x62, 0x9e, 0x32, 0x1d, 0x09, 0x18, 0xd2,
+	0xfd, 0x85, 0x71, 0xfa, 0x28, 0x9f, 0xfe, 0xc4, 0x27, 0x6f, 0x16, 0x6a, 0x8d, 0x2f, 0xcc, 0x0a,
+	0xb1, 0x3f, 0xf1, 0xad, 0xaa, 0xe3, 0x5d, 0x68, 0x28, 0x57, 0xa2, 0x05, 0xf4, 0x6f, 0x17, 0x74,
+	0xc0, 0xf6, 0xfe, 0xc4, 0xf7, 0x34, 0x2b, 0x79, 0x0b, 0xe6, 0x71, 0x79, 0xfa, 0x7e, 0x58, 0x9d,
+	0xed, 0x83, 0x9b, 0x97, 0x5d, 0x14, 0xa3, 0xfb, 0x1c, 0xdc, 0xb8, 0x60, 0x40, 0x77, 0x07, 0xc8,
+	0x6c, 0x9f, 0x4b, 0xbc, 0xa0, 0x75, 0x08, 0xd5, 0xe2, 0x21, 0xfc, 0xb4, 0x02, 0x1d, 0x13, 0xc5,
+	0xef, 0x45, 0x23, 0x9e, 0x87, 0x91, 0x7a, 0x00, 0x24, 0x24, 0xea, 0x4f, 0x27, 0x93, 0x73, 0x53,
+	0xf1, 0x42, 0x42, 0x19, 0x51, 0x28, 0xe8, 0x36, 0xd5, 0xa7, 0x5b, 0xf7, 0x72, 0x40, 0x4e, 0x7a,
+	0xa6, 0x9f, 0xe4, 0x55, 0x85, 0xce, 0x90, 0x32, 0xc8, 0x91, 0xd7, 0x8d, 0x30, 0x99, 0xba, 0xa6,
+	0xdc, 0xff, 0xcf, 0x5f, 0x1f, 0x32, 0xb7, 0x7e, 0x0b, 0x1a, 0xb1, 0x52, 0x54, 0x15, 0x2d, 0x68,
+	0x4a, 0x9e, 0xa3, 0x0c, 0x8a, 0x4d, 0xed, 0xe2, 0x66, 0xf9, 0x3d, 0xe4, 0xe3, 0x20, 0x34, 0x97,
+	0xac, 0x62, 0x74, 0x3f, 0x82, 0x8e, 0xdd, 0x98, 0x79, 0xde, 0x4a, 0xee, 0x79, 0x0b, 0xe1, 0x7b,
+	0xb5, 0x18, 0xbe, 0x6f, 0x6c, 0x68, 0x03, 0x93, 0x1a, 0x40, 0x96, 0x00, 0xf6, 0xf1, 0x09, 0xe8,
+	0x61, 0x14, 0x9e, 0x3b, 0x73, 0x64, 0x11, 0x5a, 0xbd, 0x30, 0x54, 0x02, 0x71, 0x2a, 0x1b, 0x77,
+	0xac, 0x67, 0x53, 0x46, 0x1a, 0x50, 0x7d, 0x14, 0x3b, 0x73, 0xa4, 0x09, 0xf5, 0x1d, 0x7e, 0x16,
+	0x39, 0x15, 0x42, 0x60, 0x09, 0xdb, 0xb3, 0xb4, 0xd3, 0xa9, 0x6e, 0x7c, 0x6c, 0xfd, 0x22, 0x80,
+	0x91, 0x36, 0x2c, 0x78, 0xd3, 0x28, 0x0a, 0xa2, 0xb1, 0x33, 0x47, 0x3a, 0xd0, 0x44, 0xc1, 0x4b,
+	0xaa, 0x22, 0xe7, 0xce, 0xab, 0x7f, 0x4e, 0x55, 0xce, 0xbd, 0x63, 0x5c, 0x97, 0x53, 0xdb, 0x18,
+	0x80, 0xd3, 0xc7, 0x9f, 0x71, 0xf4, 0x4f, 0xa4, 0x4d, 0xe3, 0x72, 0xdb, 0xb0, 0xd0, 0xf3, 0xfd,
+	0x03, 0xee, 0x33, 0x67, 0x4e, 0xf6, 0x57, 0xb5, 0x6e, 0xa4, 0x71, 0xbc, 0x47, 0x58, 0xfe, 0x44,
+	0xba, 0x2a, 0x17, 0xd7, 0xf3, 0xfd, 0x7d, 0x46, 0x93, 0x88, 0x25, 0x88, 0xd5, 0x36, 0xee, 0x43,
+	0xdb, 0xfa, 0x71, 0x06, 0x69, 0xc1, 0xfc, 0x17, 0x5c, 0xb0, 0xc4, 0x99, 0x93, 0x43, 0x6b, 0x56,
+	0xa7, 0x42, 0x56, 0x60, 0x71, 0x2f, 0x1a, 0xf2, 0x49, 0x10, 0x8d, 0x55, 0x7b, 0x55, 0x42, 0x3b,
+	0x52, 0xbc, 0x19, 0x54, 0xdb, 0xf8, 0x2f, 0x58, 0x2a, 0x46, 0x72, 0x92, 0xc9, 0x63, 0x34, 0x0f,
+	0xe4, 0x9c, 0x39, 0xb9, 0x8a, 0x2f, 0x93, 0x40, 0xb0, 0x1c, 0xab, 0x6c, 0xbc, 0x07, 0x4e, 0x39,
+	0x30, 0x25, 0xcb, 0xd0, 0xee, 0x85, 0xa1, 0x5e, 0x5c, 0xea, 0xcc, 0x91, 0x1b, 0xb0, 0x9c, 0x8b,
+	0x46, 0x4d, 0x59, 0xd9, 0xb8, 0x07, 0xed, 0xfe, 0x09, 0x1b, 0x3e, 0xd6, 0x9d, 0x9a, 0x50, 0x1f,
+	0xf4, 0x7b, 0x07, 0xce, 0x1c, 0x76, 0x3f, 0x3c, 0xf4, 0x1e, 0xfe, 0xef, 0xde, 0x83, 0xde, 0xd1,
+	0xae, 0x53, 0x21, 0x00, 0x8d, 0x47, 0x83, 0xdd, 0xfb, 0xbb, 0xff, 0xe7, 0x54, 0x37, 0x0e, 0xcd,
+	0x42, 0x79, 0xa2, 0xab, 0xdf, 0x6d, 0x58, 0x18, 0x3c, 0xea, 0xf7, 0x77, 0x07, 0x03, 0xb5, 0xf5,
+	0xa3, 0xbd, 0x07, 0xbb, 0x0f, 0x1f, 0x1d, 0xa9, 0x7e, 0xfd, 0xde, 0x41, 0x7f, 0x77, 0xdf, 0xa9,
+	0xa2, 0xf0, 0x76, 0x0f, 0xf7, 0x7b, 0xfd, 0x5d, 0xa7, 0x86, 0xc4, 0xa3, 0x83, 0x83, 0xbd, 0x83,
+	0x4f, 0x9c, 0xfa, 0xc6, 0x36, 0x2c, 0xe8, 0xa7, 0x0b, 0x39, 0xb3, 0xf5, 0xe4, 0xa0, 0x16, 0xae,
+	0xcc, 0x3b, 0xf3, 0xe3, 0xea, 0x44, 0xfb, 0xd3, 0x54, 0xc8, 0xa4, 0x8a, 0x26, 0xa2, 0x27, 0x1c,
+	0x7f, 0xe3, 0x2e, 0x34, 0xcd, 0xf3, 0x85, 0x1c, 0x5c, 0xf5, 0xf1, 0xd5, 0x7a, 0xbe, 0xe4, 0xc9,
+	0x63, 0xa5, 0x25, 0x8b, 0xd0, 0xea, 0xf3, 0x49, 0x1c, 0x32, 0xd9, 0x56, 0xdd, 0xf8, 0xa8, 0xf0,
+	0x23, 0x18, 0x26, 0x97, 0x7b, 0xc0, 0x93, 0x09, 0x0d, 0x95, 0x7a, 0xf5, 0xf4, 0x4b, 0xb3, 0x53,
+	0x21, 0x37, 0xc1, 0xd1, 0x9c, 0xb6, 0x76, 0xde, 0x83, 0x95, 0x19, 0x3f, 0x28, 0xb7, 0x60, 0xad,
+	0x58, 0xa9, 0x16, 0xba, 0x22, 0x45, 0x57, 0xb6, 0x9d, 0x6f, 0xff, 0x7c, 0xbb, 0xf2, 0xcd, 0xd3,
+	0xdb, 0x95, 0x6f, 0x9f, 0xde, 0xae, 0xfc, 0xe9, 0xe9, 0xed, 0xca, 0x71, 0x03, 0x7f, 0x8a, 0x74,
+	0xf7, 0x6f, 0x03, 0x00, 0x7b, 0x46, 0x21, 0xd8, 0xfc, 0x24, 0x00, 0x00,
 }
 
 func (m *ShardEpoch) Marshal() (dAtA []byte, err error) {
@@ -3138,6 +4390,16 @@ func (m *Replica) MarshalTo(dAtA []byte) (int, error) {
 		}
 		i++
 	}
+	if m.IsWitness {
+		dAtA[i] = 0x28
+		i++
+		if m.IsWitness {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i++
+	}
 	if m.XXX_unrecognized != nil {
 		i += copy(dAtA[i:], m.XXX_unrecognized)
 	}
@@ -3271,6 +4533,91 @@ func (m *ShardStats) MarshalTo(dAtA []byte) (int, error) {
 		}
 		i += n2
 	}
+	if m.ResolvedTS != 0 {
+		dAtA[i] = 0x48
+		i++
+		i = encodeVarintMetapb(dAtA, i, uint64(m.ResolvedTS))
+	}
+	if len(m.RecoveringReplicas) > 0 {
+		dAtA4 := make([]byte, len(m.RecoveringReplicas)*10)
+		var j3 int
+		for _, num := range m.RecoveringReplicas {
+			for num >= 1<<7 {
+				dAtA4[j3] = uint8(uint64(num)&0x7f | 0x80)
+				num >>= 7
+				j3++
+			}
+			dAtA4[j3] = uint8(num)
+			j3++
+		}
+		dAtA[i] = 0x52
+		i++
+		i = encodeVarintMetapb(dAtA, i, uint64(j3))
+		i += copy(dAtA[i:], dAtA4[:j3])
+	}
+	if m.Term != 0 {
+		dAtA[i] = 0x58
+		i++
+		i = encodeVarintMetapb(dAtA, i, uint64(m.Term))
+	}
+	if m.CommitIndex != 0 {
+		dAtA[i] = 0x60
+		i++
+		i = encodeVarintMetapb(dAtA, i, uint64(m.CommitIndex))
+	}
+	if m.AppliedIndex != 0 {
+		dAtA[i] = 0x68
+		i++
+		i = encodeVarintMetapb(dAtA, i, uint64(m.AppliedIndex))
+	}
+	if m.TruncatedIndex != 0 {
+		dAtA[i] = 0x70
+		i++
+		i = encodeVarintMetapb(dAtA, i, uint64(m.TruncatedIndex))
+	}
+	if len(m.ReplicaMatchIndexes) > 0 {
+		for _, msg := range m.ReplicaMatchIndexes {
+			dAtA[i] = 0x7a
+			i++
+			i = encodeVarintMetapb(dAtA, i, uint64(msg.Size()))
+			n, err := msg.MarshalTo(dAtA[i:])
+			if err != nil {
+				return 0, err
+			}
+			i += n
+		}
+	}
+	if m.XXX_unrecognized != nil {
+		i += copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	return i, nil
+}
+
+func (m *ReplicaMatchIndex) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *ReplicaMatchIndex) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if m.ReplicaID != 0 {
+		dAtA[i] = 0x8
+		i++
+		i = encodeVarintMetapb(dAtA, i, uint64(m.ReplicaID))
+	}
+	if m.MatchIndex != 0 {
+		dAtA[i] = 0x10
+		i++
+		i = encodeVarintMetapb(dAtA, i, uint64(m.MatchIndex))
+	}
 	if m.XXX_unrecognized != nil {
 		i += copy(dAtA[i:], m.XXX_unrecognized)
 	}
@@ -3306,11 +4653,11 @@ func (m *StoreStats) MarshalTo(dAtA []byte) (int, error) {
 		dAtA[i] = 0x1a
 		i++
 		i = encodeVarintMetapb(dAtA, i, uint64(m.Interval.Size()))
-		n3, err := m.Interval.MarshalTo(dAtA[i:])
+		n5, err := m.Interval.MarshalTo(dAtA[i:])
 		if err != nil {
 			return 0, err
 		}
-		i += n3
+		i += n5
 	}
 	if m.Capacity != 0 {
 		dAtA[i] = 0x20
@@ -3433,37 +4780,69 @@ func (m *StoreStats) MarshalTo(dAtA []byte) (int, error) {
 			i += n
 		}
 	}
-	if m.XXX_unrecognized != nil {
-		i += copy(dAtA[i:], m.XXX_unrecognized)
-	}
-	return i, nil
-}
-
-func (m *RecordPair) Marshal() (dAtA []byte, err error) {
-	size := m.Size()
-	dAtA = make([]byte, size)
-	n, err := m.MarshalTo(dAtA)
-	if err != nil {
-		return nil, err
+	if m.LeaderSoftLimit != 0 {
+		dAtA[i] = 0xa0
+		i++
+		dAtA[i] = 0x1
+		i++
+		i = encodeVarintMetapb(dAtA, i, uint64(m.LeaderSoftLimit))
+	}
+	if len(m.StoppedGroups) > 0 {
+		dAtA7 := make([]byte, len(m.StoppedGroups)*10)
+		var j6 int
+		for _, num := range m.StoppedGroups {
+			for num >= 1<<7 {
+				dAtA7[j6] = uint8(uint64(num)&0x7f | 0x80)
+				num >>= 7
+				j6++
+			}
+			dAtA7[j6] = uint8(num)
+			j6++
+		}
+		dAtA[i] = 0xaa
+		i++
+		dAtA[i] = 0x1
+		i++
+		i = encodeVarintMetapb(dAtA, i, uint64(j6))
+		i += copy(dAtA[i:], dAtA7[:j6])
 	}
-	return dAtA[:n], nil
-}
-
-func (m *RecordPair) MarshalTo(dAtA []byte) (int, error) {
-	var i int
-	_ = i
-	var l int
-	_ = l
-	if len(m.Key) > 0 {
-		dAtA[i] = 0xa
+	if m.SnapshotThrottled {
+		dAtA[i] = 0xb0
+		i++
+		dAtA[i] = 0x1
+		i++
+		if m.SnapshotThrottled {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
 		i++
-		i = encodeVarintMetapb(dAtA, i, uint64(len(m.Key)))
-		i += copy(dAtA[i:], m.Key)
 	}
-	if m.Value != 0 {
-		dAtA[i] = 0x10
+	if len(m.SnapshotProgresses) > 0 {
+		for _, msg := range m.SnapshotProgresses {
+			dAtA[i] = 0xba
+			i++
+			dAtA[i] = 0x1
+			i++
+			i = encodeVarintMetapb(dAtA, i, uint64(msg.Size()))
+			n, err := msg.MarshalTo(dAtA[i:])
+			if err != nil {
+				return 0, err
+			}
+			i += n
+		}
+	}
+	if m.Maintenance {
+		dAtA[i] = 0xc0
+		i++
+		dAtA[i] = 0x1
+		i++
+		if m.Maintenance {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
 		i++
-		i = encodeVarintMetapb(dAtA, i, uint64(m.Value))
 	}
 	if m.XXX_unrecognized != nil {
 		i += copy(dAtA[i:], m.XXX_unrecognized)
@@ -3471,7 +4850,7 @@ func (m *RecordPair) MarshalTo(dAtA []byte) (int, error) {
 	return i, nil
 }
 
-func (m *Member) Marshal() (dAtA []byte, err error) {
+func (m *SnapshotProgress) Marshal() (dAtA []byte, err error) {
 	size := m.Size()
 	dAtA = make([]byte, size)
 	n, err := m.MarshalTo(dAtA)
@@ -3481,25 +4860,150 @@ func (m *Member) Marshal() (dAtA []byte, err error) {
 	return dAtA[:n], nil
 }
 
-func (m *Member) MarshalTo(dAtA []byte) (int, error) {
+func (m *SnapshotProgress) MarshalTo(dAtA []byte) (int, error) {
 	var i int
 	_ = i
 	var l int
 	_ = l
-	if m.ID != 0 {
+	if m.StoreID != 0 {
 		dAtA[i] = 0x8
 		i++
-		i = encodeVarintMetapb(dAtA, i, uint64(m.ID))
+		i = encodeVarintMetapb(dAtA, i, uint64(m.StoreID))
 	}
-	if len(m.Name) > 0 {
-		dAtA[i] = 0x12
+	if m.ShardID != 0 {
+		dAtA[i] = 0x10
 		i++
-		i = encodeVarintMetapb(dAtA, i, uint64(len(m.Name)))
-		i += copy(dAtA[i:], m.Name)
+		i = encodeVarintMetapb(dAtA, i, uint64(m.ShardID))
 	}
-	if len(m.Addr) > 0 {
-		dAtA[i] = 0x1a
-		i++
+	dAtA[i] = 0x1a
+	i++
+	i = encodeVarintMetapb(dAtA, i, uint64(m.From.Size()))
+	n8, err := m.From.MarshalTo(dAtA[i:])
+	if err != nil {
+		return 0, err
+	}
+	i += n8
+	dAtA[i] = 0x22
+	i++
+	i = encodeVarintMetapb(dAtA, i, uint64(m.To.Size()))
+	n9, err := m.To.MarshalTo(dAtA[i:])
+	if err != nil {
+		return 0, err
+	}
+	i += n9
+	if m.Index != 0 {
+		dAtA[i] = 0x28
+		i++
+		i = encodeVarintMetapb(dAtA, i, uint64(m.Index))
+	}
+	if m.Sending {
+		dAtA[i] = 0x30
+		i++
+		if m.Sending {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i++
+	}
+	if m.TotalBytes != 0 {
+		dAtA[i] = 0x38
+		i++
+		i = encodeVarintMetapb(dAtA, i, uint64(m.TotalBytes))
+	}
+	if m.TransferredBytes != 0 {
+		dAtA[i] = 0x40
+		i++
+		i = encodeVarintMetapb(dAtA, i, uint64(m.TransferredBytes))
+	}
+	if m.StartTime != 0 {
+		dAtA[i] = 0x48
+		i++
+		i = encodeVarintMetapb(dAtA, i, uint64(m.StartTime))
+	}
+	if m.Rate != 0 {
+		dAtA[i] = 0x50
+		i++
+		i = encodeVarintMetapb(dAtA, i, uint64(m.Rate))
+	}
+	if m.ETA != 0 {
+		dAtA[i] = 0x58
+		i++
+		i = encodeVarintMetapb(dAtA, i, uint64(m.ETA))
+	}
+	if len(m.Operator) > 0 {
+		dAtA[i] = 0x62
+		i++
+		i = encodeVarintMetapb(dAtA, i, uint64(len(m.Operator)))
+		i += copy(dAtA[i:], m.Operator)
+	}
+	if m.XXX_unrecognized != nil {
+		i += copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	return i, nil
+}
+
+func (m *RecordPair) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *RecordPair) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.Key) > 0 {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintMetapb(dAtA, i, uint64(len(m.Key)))
+		i += copy(dAtA[i:], m.Key)
+	}
+	if m.Value != 0 {
+		dAtA[i] = 0x10
+		i++
+		i = encodeVarintMetapb(dAtA, i, uint64(m.Value))
+	}
+	if m.XXX_unrecognized != nil {
+		i += copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	return i, nil
+}
+
+func (m *Member) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *Member) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if m.ID != 0 {
+		dAtA[i] = 0x8
+		i++
+		i = encodeVarintMetapb(dAtA, i, uint64(m.ID))
+	}
+	if len(m.Name) > 0 {
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintMetapb(dAtA, i, uint64(len(m.Name)))
+		i += copy(dAtA[i:], m.Name)
+	}
+	if len(m.Addr) > 0 {
+		dAtA[i] = 0x1a
+		i++
 		i = encodeVarintMetapb(dAtA, i, uint64(len(m.Addr)))
 		i += copy(dAtA[i:], m.Addr)
 	}
@@ -3771,6 +5275,16 @@ func (m *DestroyingStatus) MarshalTo(dAtA []byte) (int, error) {
 		}
 		i++
 	}
+	if m.CreatedAt != 0 {
+		dAtA[i] = 0x28
+		i++
+		i = encodeVarintMetapb(dAtA, i, uint64(m.CreatedAt))
+	}
+	if m.UpdatedAt != 0 {
+		dAtA[i] = 0x30
+		i++
+		i = encodeVarintMetapb(dAtA, i, uint64(m.UpdatedAt))
+	}
 	if m.XXX_unrecognized != nil {
 		i += copy(dAtA[i:], m.XXX_unrecognized)
 	}
@@ -3813,11 +5327,11 @@ func (m *ShardExtra) MarshalTo(dAtA []byte) (int, error) {
 		dAtA[i] = 0x12
 		i++
 		i = encodeVarintMetapb(dAtA, i, uint64(m.DestroyingStatus.Size()))
-		n4, err := m.DestroyingStatus.MarshalTo(dAtA[i:])
+		n10, err := m.DestroyingStatus.MarshalTo(dAtA[i:])
 		if err != nil {
 			return 0, err
 		}
-		i += n4
+		i += n10
 	}
 	if m.XXX_unrecognized != nil {
 		i += copy(dAtA[i:], m.XXX_unrecognized)
@@ -3868,6 +5382,121 @@ func (m *ScheduleGroupRule) MarshalTo(dAtA []byte) (int, error) {
 	return i, nil
 }
 
+func (m *RangeRelocation) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *RangeRelocation) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if m.ID != 0 {
+		dAtA[i] = 0x8
+		i++
+		i = encodeVarintMetapb(dAtA, i, uint64(m.ID))
+	}
+	if m.Group != 0 {
+		dAtA[i] = 0x10
+		i++
+		i = encodeVarintMetapb(dAtA, i, uint64(m.Group))
+	}
+	if len(m.Start) > 0 {
+		dAtA[i] = 0x1a
+		i++
+		i = encodeVarintMetapb(dAtA, i, uint64(len(m.Start)))
+		i += copy(dAtA[i:], m.Start)
+	}
+	if len(m.End) > 0 {
+		dAtA[i] = 0x22
+		i++
+		i = encodeVarintMetapb(dAtA, i, uint64(len(m.End)))
+		i += copy(dAtA[i:], m.End)
+	}
+	if len(m.Stores) > 0 {
+		dAtA12 := make([]byte, len(m.Stores)*10)
+		var j11 int
+		for _, num := range m.Stores {
+			for num >= 1<<7 {
+				dAtA12[j11] = uint8(uint64(num)&0x7f | 0x80)
+				num >>= 7
+				j11++
+			}
+			dAtA12[j11] = uint8(num)
+			j11++
+		}
+		dAtA[i] = 0x2a
+		i++
+		i = encodeVarintMetapb(dAtA, i, uint64(j11))
+		i += copy(dAtA[i:], dAtA12[:j11])
+	}
+	if m.XXX_unrecognized != nil {
+		i += copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	return i, nil
+}
+
+func (m *LabelSteering) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *LabelSteering) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if m.ID != 0 {
+		dAtA[i] = 0x8
+		i++
+		i = encodeVarintMetapb(dAtA, i, uint64(m.ID))
+	}
+	if m.Group != 0 {
+		dAtA[i] = 0x10
+		i++
+		i = encodeVarintMetapb(dAtA, i, uint64(m.Group))
+	}
+	if len(m.Start) > 0 {
+		dAtA[i] = 0x1a
+		i++
+		i = encodeVarintMetapb(dAtA, i, uint64(len(m.Start)))
+		i += copy(dAtA[i:], m.Start)
+	}
+	if len(m.End) > 0 {
+		dAtA[i] = 0x22
+		i++
+		i = encodeVarintMetapb(dAtA, i, uint64(len(m.End)))
+		i += copy(dAtA[i:], m.End)
+	}
+	if len(m.Labels) > 0 {
+		for _, msg := range m.Labels {
+			dAtA[i] = 0x2a
+			i++
+			i = encodeVarintMetapb(dAtA, i, uint64(msg.Size()))
+			n, err := msg.MarshalTo(dAtA[i:])
+			if err != nil {
+				return 0, err
+			}
+			i += n
+		}
+	}
+	if m.XXX_unrecognized != nil {
+		i += copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	return i, nil
+}
+
 func (m *RaftMessageBatch) Marshal() (dAtA []byte, err error) {
 	size := m.Size()
 	dAtA = make([]byte, size)
@@ -3895,13 +5524,25 @@ func (m *RaftMessageBatch) MarshalTo(dAtA []byte) (int, error) {
 			i += n
 		}
 	}
+	if len(m.Heartbeats) > 0 {
+		for _, msg := range m.Heartbeats {
+			dAtA[i] = 0x12
+			i++
+			i = encodeVarintMetapb(dAtA, i, uint64(msg.Size()))
+			n, err := msg.MarshalTo(dAtA[i:])
+			if err != nil {
+				return 0, err
+			}
+			i += n
+		}
+	}
 	if m.XXX_unrecognized != nil {
 		i += copy(dAtA[i:], m.XXX_unrecognized)
 	}
 	return i, nil
 }
 
-func (m *RaftMessage) Marshal() (dAtA []byte, err error) {
+func (m *RaftHeartbeat) Marshal() (dAtA []byte, err error) {
 	size := m.Size()
 	dAtA = make([]byte, size)
 	n, err := m.MarshalTo(dAtA)
@@ -3911,7 +5552,7 @@ func (m *RaftMessage) Marshal() (dAtA []byte, err error) {
 	return dAtA[:n], nil
 }
 
-func (m *RaftMessage) MarshalTo(dAtA []byte) (int, error) {
+func (m *RaftHeartbeat) MarshalTo(dAtA []byte) (int, error) {
 	var i int
 	_ = i
 	var l int
@@ -3929,56 +5570,156 @@ func (m *RaftMessage) MarshalTo(dAtA []byte) (int, error) {
 	dAtA[i] = 0x1a
 	i++
 	i = encodeVarintMetapb(dAtA, i, uint64(m.From.Size()))
-	n5, err := m.From.MarshalTo(dAtA[i:])
+	n13, err := m.From.MarshalTo(dAtA[i:])
 	if err != nil {
 		return 0, err
 	}
-	i += n5
+	i += n13
 	dAtA[i] = 0x22
 	i++
 	i = encodeVarintMetapb(dAtA, i, uint64(m.To.Size()))
-	n6, err := m.To.MarshalTo(dAtA[i:])
-	if err != nil {
-		return 0, err
-	}
-	i += n6
-	dAtA[i] = 0x2a
-	i++
-	i = encodeVarintMetapb(dAtA, i, uint64(m.Message.Size()))
-	n7, err := m.Message.MarshalTo(dAtA[i:])
-	if err != nil {
-		return 0, err
-	}
-	i += n7
-	dAtA[i] = 0x32
-	i++
-	i = encodeVarintMetapb(dAtA, i, uint64(m.ShardEpoch.Size()))
-	n8, err := m.ShardEpoch.MarshalTo(dAtA[i:])
+	n14, err := m.To.MarshalTo(dAtA[i:])
 	if err != nil {
 		return 0, err
 	}
-	i += n8
-	if m.IsTombstone {
-		dAtA[i] = 0x38
+	i += n14
+	if m.Response {
+		dAtA[i] = 0x28
 		i++
-		if m.IsTombstone {
+		if m.Response {
 			dAtA[i] = 1
 		} else {
 			dAtA[i] = 0
 		}
 		i++
 	}
-	if len(m.Start) > 0 {
-		dAtA[i] = 0x42
+	if m.Term != 0 {
+		dAtA[i] = 0x30
 		i++
-		i = encodeVarintMetapb(dAtA, i, uint64(len(m.Start)))
-		i += copy(dAtA[i:], m.Start)
+		i = encodeVarintMetapb(dAtA, i, uint64(m.Term))
 	}
-	if len(m.End) > 0 {
-		dAtA[i] = 0x4a
+	if m.Commit != 0 {
+		dAtA[i] = 0x38
 		i++
-		i = encodeVarintMetapb(dAtA, i, uint64(len(m.End)))
-		i += copy(dAtA[i:], m.End)
+		i = encodeVarintMetapb(dAtA, i, uint64(m.Commit))
+	}
+	dAtA[i] = 0x42
+	i++
+	i = encodeVarintMetapb(dAtA, i, uint64(m.ShardEpoch.Size()))
+	n15, err := m.ShardEpoch.MarshalTo(dAtA[i:])
+	if err != nil {
+		return 0, err
+	}
+	i += n15
+	if m.CommitIndex != 0 {
+		dAtA[i] = 0x48
+		i++
+		i = encodeVarintMetapb(dAtA, i, uint64(m.CommitIndex))
+	}
+	if m.AppliedIndex != 0 {
+		dAtA[i] = 0x50
+		i++
+		i = encodeVarintMetapb(dAtA, i, uint64(m.AppliedIndex))
+	}
+	if m.ResolvedTS != 0 {
+		dAtA[i] = 0x58
+		i++
+		i = encodeVarintMetapb(dAtA, i, uint64(m.ResolvedTS))
+	}
+	if m.ResolvedIndex != 0 {
+		dAtA[i] = 0x60
+		i++
+		i = encodeVarintMetapb(dAtA, i, uint64(m.ResolvedIndex))
+	}
+	if m.SendTime != 0 {
+		dAtA[i] = 0x68
+		i++
+		i = encodeVarintMetapb(dAtA, i, uint64(m.SendTime))
+	}
+	if m.XXX_unrecognized != nil {
+		i += copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	return i, nil
+}
+
+func (m *RaftMessage) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *RaftMessage) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if m.ShardID != 0 {
+		dAtA[i] = 0x8
+		i++
+		i = encodeVarintMetapb(dAtA, i, uint64(m.ShardID))
+	}
+	if m.Group != 0 {
+		dAtA[i] = 0x10
+		i++
+		i = encodeVarintMetapb(dAtA, i, uint64(m.Group))
+	}
+	dAtA[i] = 0x1a
+	i++
+	i = encodeVarintMetapb(dAtA, i, uint64(m.From.Size()))
+	n16, err := m.From.MarshalTo(dAtA[i:])
+	if err != nil {
+		return 0, err
+	}
+	i += n16
+	dAtA[i] = 0x22
+	i++
+	i = encodeVarintMetapb(dAtA, i, uint64(m.To.Size()))
+	n17, err := m.To.MarshalTo(dAtA[i:])
+	if err != nil {
+		return 0, err
+	}
+	i += n17
+	dAtA[i] = 0x2a
+	i++
+	i = encodeVarintMetapb(dAtA, i, uint64(m.Message.Size()))
+	n18, err := m.Message.MarshalTo(dAtA[i:])
+	if err != nil {
+		return 0, err
+	}
+	i += n18
+	dAtA[i] = 0x32
+	i++
+	i = encodeVarintMetapb(dAtA, i, uint64(m.ShardEpoch.Size()))
+	n19, err := m.ShardEpoch.MarshalTo(dAtA[i:])
+	if err != nil {
+		return 0, err
+	}
+	i += n19
+	if m.IsTombstone {
+		dAtA[i] = 0x38
+		i++
+		if m.IsTombstone {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i++
+	}
+	if len(m.Start) > 0 {
+		dAtA[i] = 0x42
+		i++
+		i = encodeVarintMetapb(dAtA, i, uint64(len(m.Start)))
+		i += copy(dAtA[i:], m.Start)
+	}
+	if len(m.End) > 0 {
+		dAtA[i] = 0x4a
+		i++
+		i = encodeVarintMetapb(dAtA, i, uint64(len(m.End)))
+		i += copy(dAtA[i:], m.End)
 	}
 	if len(m.Unique) > 0 {
 		dAtA[i] = 0x52
@@ -4011,6 +5752,33 @@ func (m *RaftMessage) MarshalTo(dAtA []byte) (int, error) {
 		i++
 		i = encodeVarintMetapb(dAtA, i, uint64(m.SendTime))
 	}
+	if m.ResolvedTS != 0 {
+		dAtA[i] = 0x70
+		i++
+		i = encodeVarintMetapb(dAtA, i, uint64(m.ResolvedTS))
+	}
+	if m.ResolvedIndex != 0 {
+		dAtA[i] = 0x78
+		i++
+		i = encodeVarintMetapb(dAtA, i, uint64(m.ResolvedIndex))
+	}
+	if len(m.WarmupKeys) > 0 {
+		for _, b := range m.WarmupKeys {
+			dAtA[i] = 0x82
+			i++
+			dAtA[i] = 0x1
+			i++
+			i = encodeVarintMetapb(dAtA, i, uint64(len(b)))
+			i += copy(dAtA[i:], b)
+		}
+	}
+	if m.AppliedIndex != 0 {
+		dAtA[i] = 0x88
+		i++
+		dAtA[i] = 0x1
+		i++
+		i = encodeVarintMetapb(dAtA, i, uint64(m.AppliedIndex))
+	}
 	if m.XXX_unrecognized != nil {
 		i += copy(dAtA[i:], m.XXX_unrecognized)
 	}
@@ -4115,11 +5883,25 @@ func (m *SnapshotChunk) MarshalTo(dAtA []byte) (int, error) {
 	dAtA[i] = 0x1
 	i++
 	i = encodeVarintMetapb(dAtA, i, uint64(m.ConfState.Size()))
-	n9, err := m.ConfState.MarshalTo(dAtA[i:])
+	n20, err := m.ConfState.MarshalTo(dAtA[i:])
 	if err != nil {
 		return 0, err
 	}
-	i += n9
+	i += n20
+	if m.SnapshotSize != 0 {
+		dAtA[i] = 0x88
+		i++
+		dAtA[i] = 0x1
+		i++
+		i = encodeVarintMetapb(dAtA, i, uint64(m.SnapshotSize))
+	}
+	if m.FromStoreID != 0 {
+		dAtA[i] = 0x90
+		i++
+		dAtA[i] = 0x1
+		i++
+		i = encodeVarintMetapb(dAtA, i, uint64(m.FromStoreID))
+	}
 	if m.XXX_unrecognized != nil {
 		i += copy(dAtA[i:], m.XXX_unrecognized)
 	}
@@ -4192,11 +5974,11 @@ func (m *Shard) MarshalTo(dAtA []byte) (int, error) {
 	dAtA[i] = 0x22
 	i++
 	i = encodeVarintMetapb(dAtA, i, uint64(m.Epoch.Size()))
-	n10, err := m.Epoch.MarshalTo(dAtA[i:])
+	n21, err := m.Epoch.MarshalTo(dAtA[i:])
 	if err != nil {
 		return 0, err
 	}
-	i += n10
+	i += n21
 	if m.State != 0 {
 		dAtA[i] = 0x28
 		i++
@@ -4252,6 +6034,65 @@ func (m *Shard) MarshalTo(dAtA []byte) (int, error) {
 			i += n
 		}
 	}
+	if m.DurabilityPolicy != 0 {
+		dAtA[i] = 0x58
+		i++
+		i = encodeVarintMetapb(dAtA, i, uint64(m.DurabilityPolicy))
+	}
+	if m.ACL != nil {
+		dAtA[i] = 0x62
+		i++
+		i = encodeVarintMetapb(dAtA, i, uint64(m.ACL.Size()))
+		n22, err := m.ACL.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n22
+	}
+	if m.XXX_unrecognized != nil {
+		i += copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	return i, nil
+}
+
+func (m *ShardACL) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *ShardACL) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.Owner) > 0 {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintMetapb(dAtA, i, uint64(len(m.Owner)))
+		i += copy(dAtA[i:], m.Owner)
+	}
+	if len(m.AllowedOperations) > 0 {
+		dAtA24 := make([]byte, len(m.AllowedOperations)*10)
+		var j23 int
+		for _, num := range m.AllowedOperations {
+			for num >= 1<<7 {
+				dAtA24[j23] = uint8(uint64(num)&0x7f | 0x80)
+				num >>= 7
+				j23++
+			}
+			dAtA24[j23] = uint8(num)
+			j23++
+		}
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintMetapb(dAtA, i, uint64(j23))
+		i += copy(dAtA[i:], dAtA24[:j23])
+	}
 	if m.XXX_unrecognized != nil {
 		i += copy(dAtA[i:], m.XXX_unrecognized)
 	}
@@ -4317,11 +6158,11 @@ func (m *ShardMetadata) MarshalTo(dAtA []byte) (int, error) {
 	dAtA[i] = 0x1a
 	i++
 	i = encodeVarintMetapb(dAtA, i, uint64(m.Metadata.Size()))
-	n11, err := m.Metadata.MarshalTo(dAtA[i:])
+	n25, err := m.Metadata.MarshalTo(dAtA[i:])
 	if err != nil {
 		return 0, err
 	}
-	i += n11
+	i += n25
 	if m.XXX_unrecognized != nil {
 		i += copy(dAtA[i:], m.XXX_unrecognized)
 	}
@@ -4346,11 +6187,11 @@ func (m *ShardLocalState) MarshalTo(dAtA []byte) (int, error) {
 	dAtA[i] = 0xa
 	i++
 	i = encodeVarintMetapb(dAtA, i, uint64(m.Shard.Size()))
-	n12, err := m.Shard.MarshalTo(dAtA[i:])
+	n26, err := m.Shard.MarshalTo(dAtA[i:])
 	if err != nil {
 		return 0, err
 	}
-	i += n12
+	i += n26
 	if m.State != 0 {
 		dAtA[i] = 0x10
 		i++
@@ -4366,6 +6207,117 @@ func (m *ShardLocalState) MarshalTo(dAtA []byte) (int, error) {
 		}
 		i++
 	}
+	if m.MergeTarget != 0 {
+		dAtA[i] = 0x20
+		i++
+		i = encodeVarintMetapb(dAtA, i, uint64(m.MergeTarget))
+	}
+	if m.FenceIndex != 0 {
+		dAtA[i] = 0x28
+		i++
+		i = encodeVarintMetapb(dAtA, i, uint64(m.FenceIndex))
+	}
+	dAtA[i] = 0x32
+	i++
+	i = encodeVarintMetapb(dAtA, i, uint64(m.MergeTargetEpoch.Size()))
+	n27, err := m.MergeTargetEpoch.MarshalTo(dAtA[i:])
+	if err != nil {
+		return 0, err
+	}
+	i += n27
+	if m.Migrated {
+		dAtA[i] = 0x38
+		i++
+		if m.Migrated {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i++
+	}
+	if m.XXX_unrecognized != nil {
+		i += copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	return i, nil
+}
+
+func (m *BackupManifest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *BackupManifest) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if m.Group != 0 {
+		dAtA[i] = 0x8
+		i++
+		i = encodeVarintMetapb(dAtA, i, uint64(m.Group))
+	}
+	if m.CreatedAt != 0 {
+		dAtA[i] = 0x10
+		i++
+		i = encodeVarintMetapb(dAtA, i, uint64(m.CreatedAt))
+	}
+	if len(m.Shards) > 0 {
+		for _, msg := range m.Shards {
+			dAtA[i] = 0x1a
+			i++
+			i = encodeVarintMetapb(dAtA, i, uint64(msg.Size()))
+			n, err := msg.MarshalTo(dAtA[i:])
+			if err != nil {
+				return 0, err
+			}
+			i += n
+		}
+	}
+	if m.XXX_unrecognized != nil {
+		i += copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	return i, nil
+}
+
+func (m *ShardBackup) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *ShardBackup) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	dAtA[i] = 0xa
+	i++
+	i = encodeVarintMetapb(dAtA, i, uint64(m.Shard.Size()))
+	n28, err := m.Shard.MarshalTo(dAtA[i:])
+	if err != nil {
+		return 0, err
+	}
+	i += n28
+	if m.Index != 0 {
+		dAtA[i] = 0x10
+		i++
+		i = encodeVarintMetapb(dAtA, i, uint64(m.Index))
+	}
+	if len(m.Path) > 0 {
+		dAtA[i] = 0x1a
+		i++
+		i = encodeVarintMetapb(dAtA, i, uint64(len(m.Path)))
+		i += copy(dAtA[i:], m.Path)
+	}
 	if m.XXX_unrecognized != nil {
 		i += copy(dAtA[i:], m.XXX_unrecognized)
 	}
@@ -4459,6 +6411,16 @@ func (m *Store) MarshalTo(dAtA []byte) (int, error) {
 		}
 		i++
 	}
+	if m.Maintenance {
+		dAtA[i] = 0x60
+		i++
+		if m.Maintenance {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i++
+	}
 	if m.XXX_unrecognized != nil {
 		i += copy(dAtA[i:], m.XXX_unrecognized)
 	}
@@ -4499,11 +6461,11 @@ func (m *ShardsPool) MarshalTo(dAtA []byte) (int, error) {
 				dAtA[i] = 0x12
 				i++
 				i = encodeVarintMetapb(dAtA, i, uint64(v.Size()))
-				n13, err := v.MarshalTo(dAtA[i:])
+				n29, err := v.MarshalTo(dAtA[i:])
 				if err != nil {
 					return 0, err
 				}
-				i += n13
+				i += n29
 			}
 		}
 	}
@@ -4628,21 +6590,21 @@ func (m *ShardsPoolCmd) MarshalTo(dAtA []byte) (int, error) {
 		dAtA[i] = 0x12
 		i++
 		i = encodeVarintMetapb(dAtA, i, uint64(m.Create.Size()))
-		n14, err := m.Create.MarshalTo(dAtA[i:])
+		n30, err := m.Create.MarshalTo(dAtA[i:])
 		if err != nil {
 			return 0, err
 		}
-		i += n14
+		i += n30
 	}
 	if m.Alloc != nil {
 		dAtA[i] = 0x1a
 		i++
 		i = encodeVarintMetapb(dAtA, i, uint64(m.Alloc.Size()))
-		n15, err := m.Alloc.MarshalTo(dAtA[i:])
+		n31, err := m.Alloc.MarshalTo(dAtA[i:])
 		if err != nil {
 			return 0, err
 		}
-		i += n15
+		i += n31
 	}
 	if m.XXX_unrecognized != nil {
 		i += copy(dAtA[i:], m.XXX_unrecognized)
@@ -4733,9 +6695,105 @@ func (m *SnapshotInfo) MarshalTo(dAtA []byte) (int, error) {
 		}
 		i++
 	}
-	if m.XXX_unrecognized != nil {
-		i += copy(dAtA[i:], m.XXX_unrecognized)
-	}
+	if m.DeltaBase != 0 {
+		dAtA[i] = 0x18
+		i++
+		i = encodeVarintMetapb(dAtA, i, uint64(m.DeltaBase))
+	}
+	if m.Witness {
+		dAtA[i] = 0x20
+		i++
+		if m.Witness {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i++
+	}
+	if m.Remote {
+		dAtA[i] = 0x28
+		i++
+		if m.Remote {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i++
+	}
+	if m.XXX_unrecognized != nil {
+		i += copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	return i, nil
+}
+
+func (m *SnapshotManifest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *SnapshotManifest) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.Prefix) > 0 {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintMetapb(dAtA, i, uint64(len(m.Prefix)))
+		i += copy(dAtA[i:], m.Prefix)
+	}
+	if len(m.Files) > 0 {
+		for _, msg := range m.Files {
+			dAtA[i] = 0x12
+			i++
+			i = encodeVarintMetapb(dAtA, i, uint64(msg.Size()))
+			n, err := msg.MarshalTo(dAtA[i:])
+			if err != nil {
+				return 0, err
+			}
+			i += n
+		}
+	}
+	if m.XXX_unrecognized != nil {
+		i += copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	return i, nil
+}
+
+func (m *SnapshotFile) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *SnapshotFile) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.Path) > 0 {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintMetapb(dAtA, i, uint64(len(m.Path)))
+		i += copy(dAtA[i:], m.Path)
+	}
+	if m.FileSize != 0 {
+		dAtA[i] = 0x10
+		i++
+		i = encodeVarintMetapb(dAtA, i, uint64(m.FileSize))
+	}
+	if m.XXX_unrecognized != nil {
+		i += copy(dAtA[i:], m.XXX_unrecognized)
+	}
 	return i, nil
 }
 
@@ -4784,6 +6842,9 @@ func (m *Replica) Size() (n int) {
 	if m.InitialMember {
 		n += 2
 	}
+	if m.IsWitness {
+		n += 2
+	}
 	if m.XXX_unrecognized != nil {
 		n += len(m.XXX_unrecognized)
 	}
@@ -4858,6 +6919,52 @@ func (m *ShardStats) Size() (n int) {
 		l = m.Interval.Size()
 		n += 1 + l + sovMetapb(uint64(l))
 	}
+	if m.ResolvedTS != 0 {
+		n += 1 + sovMetapb(uint64(m.ResolvedTS))
+	}
+	if len(m.RecoveringReplicas) > 0 {
+		l = 0
+		for _, e := range m.RecoveringReplicas {
+			l += sovMetapb(uint64(e))
+		}
+		n += 1 + sovMetapb(uint64(l)) + l
+	}
+	if m.Term != 0 {
+		n += 1 + sovMetapb(uint64(m.Term))
+	}
+	if m.CommitIndex != 0 {
+		n += 1 + sovMetapb(uint64(m.CommitIndex))
+	}
+	if m.AppliedIndex != 0 {
+		n += 1 + sovMetapb(uint64(m.AppliedIndex))
+	}
+	if m.TruncatedIndex != 0 {
+		n += 1 + sovMetapb(uint64(m.TruncatedIndex))
+	}
+	if len(m.ReplicaMatchIndexes) > 0 {
+		for _, e := range m.ReplicaMatchIndexes {
+			l = e.Size()
+			n += 1 + l + sovMetapb(uint64(l))
+		}
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *ReplicaMatchIndex) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.ReplicaID != 0 {
+		n += 1 + sovMetapb(uint64(m.ReplicaID))
+	}
+	if m.MatchIndex != 0 {
+		n += 1 + sovMetapb(uint64(m.MatchIndex))
+	}
 	if m.XXX_unrecognized != nil {
 		n += len(m.XXX_unrecognized)
 	}
@@ -4940,6 +7047,75 @@ func (m *StoreStats) Size() (n int) {
 			n += 2 + l + sovMetapb(uint64(l))
 		}
 	}
+	if m.LeaderSoftLimit != 0 {
+		n += 2 + sovMetapb(uint64(m.LeaderSoftLimit))
+	}
+	if len(m.StoppedGroups) > 0 {
+		l = 0
+		for _, e := range m.StoppedGroups {
+			l += sovMetapb(uint64(e))
+		}
+		n += 2 + sovMetapb(uint64(l)) + l
+	}
+	if m.SnapshotThrottled {
+		n += 3
+	}
+	if len(m.SnapshotProgresses) > 0 {
+		for _, e := range m.SnapshotProgresses {
+			l = e.Size()
+			n += 2 + l + sovMetapb(uint64(l))
+		}
+	}
+	if m.Maintenance {
+		n += 3
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *SnapshotProgress) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.StoreID != 0 {
+		n += 1 + sovMetapb(uint64(m.StoreID))
+	}
+	if m.ShardID != 0 {
+		n += 1 + sovMetapb(uint64(m.ShardID))
+	}
+	l = m.From.Size()
+	n += 1 + l + sovMetapb(uint64(l))
+	l = m.To.Size()
+	n += 1 + l + sovMetapb(uint64(l))
+	if m.Index != 0 {
+		n += 1 + sovMetapb(uint64(m.Index))
+	}
+	if m.Sending {
+		n += 2
+	}
+	if m.TotalBytes != 0 {
+		n += 1 + sovMetapb(uint64(m.TotalBytes))
+	}
+	if m.TransferredBytes != 0 {
+		n += 1 + sovMetapb(uint64(m.TransferredBytes))
+	}
+	if m.StartTime != 0 {
+		n += 1 + sovMetapb(uint64(m.StartTime))
+	}
+	if m.Rate != 0 {
+		n += 1 + sovMetapb(uint64(m.Rate))
+	}
+	if m.ETA != 0 {
+		n += 1 + sovMetapb(uint64(m.ETA))
+	}
+	l = len(m.Operator)
+	if l > 0 {
+		n += 1 + l + sovMetapb(uint64(l))
+	}
 	if m.XXX_unrecognized != nil {
 		n += len(m.XXX_unrecognized)
 	}
@@ -5130,6 +7306,12 @@ func (m *DestroyingStatus) Size() (n int) {
 	if m.RemoveData {
 		n += 2
 	}
+	if m.CreatedAt != 0 {
+		n += 1 + sovMetapb(uint64(m.CreatedAt))
+	}
+	if m.UpdatedAt != 0 {
+		n += 1 + sovMetapb(uint64(m.UpdatedAt))
+	}
 	if m.XXX_unrecognized != nil {
 		n += len(m.XXX_unrecognized)
 	}
@@ -5186,6 +7368,71 @@ func (m *ScheduleGroupRule) Size() (n int) {
 	return n
 }
 
+func (m *RangeRelocation) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.ID != 0 {
+		n += 1 + sovMetapb(uint64(m.ID))
+	}
+	if m.Group != 0 {
+		n += 1 + sovMetapb(uint64(m.Group))
+	}
+	l = len(m.Start)
+	if l > 0 {
+		n += 1 + l + sovMetapb(uint64(l))
+	}
+	l = len(m.End)
+	if l > 0 {
+		n += 1 + l + sovMetapb(uint64(l))
+	}
+	if len(m.Stores) > 0 {
+		l = 0
+		for _, e := range m.Stores {
+			l += sovMetapb(uint64(e))
+		}
+		n += 1 + sovMetapb(uint64(l)) + l
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *LabelSteering) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.ID != 0 {
+		n += 1 + sovMetapb(uint64(m.ID))
+	}
+	if m.Group != 0 {
+		n += 1 + sovMetapb(uint64(m.Group))
+	}
+	l = len(m.Start)
+	if l > 0 {
+		n += 1 + l + sovMetapb(uint64(l))
+	}
+	l = len(m.End)
+	if l > 0 {
+		n += 1 + l + sovMetapb(uint64(l))
+	}
+	if len(m.Labels) > 0 {
+		for _, e := range m.Labels {
+			l = e.Size()
+			n += 1 + l + sovMetapb(uint64(l))
+		}
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
 func (m *RaftMessageBatch) Size() (n int) {
 	if m == nil {
 		return 0
@@ -5198,13 +7445,19 @@ func (m *RaftMessageBatch) Size() (n int) {
 			n += 1 + l + sovMetapb(uint64(l))
 		}
 	}
+	if len(m.Heartbeats) > 0 {
+		for _, e := range m.Heartbeats {
+			l = e.Size()
+			n += 1 + l + sovMetapb(uint64(l))
+		}
+	}
 	if m.XXX_unrecognized != nil {
 		n += len(m.XXX_unrecognized)
 	}
 	return n
 }
 
-func (m *RaftMessage) Size() (n int) {
+func (m *RaftHeartbeat) Size() (n int) {
 	if m == nil {
 		return 0
 	}
@@ -5220,34 +7473,29 @@ func (m *RaftMessage) Size() (n int) {
 	n += 1 + l + sovMetapb(uint64(l))
 	l = m.To.Size()
 	n += 1 + l + sovMetapb(uint64(l))
-	l = m.Message.Size()
-	n += 1 + l + sovMetapb(uint64(l))
-	l = m.ShardEpoch.Size()
-	n += 1 + l + sovMetapb(uint64(l))
-	if m.IsTombstone {
+	if m.Response {
 		n += 2
 	}
-	l = len(m.Start)
-	if l > 0 {
-		n += 1 + l + sovMetapb(uint64(l))
-	}
-	l = len(m.End)
-	if l > 0 {
-		n += 1 + l + sovMetapb(uint64(l))
-	}
-	l = len(m.Unique)
-	if l > 0 {
-		n += 1 + l + sovMetapb(uint64(l))
+	if m.Term != 0 {
+		n += 1 + sovMetapb(uint64(m.Term))
 	}
-	if len(m.RuleGroups) > 0 {
-		for _, s := range m.RuleGroups {
-			l = len(s)
-			n += 1 + l + sovMetapb(uint64(l))
-		}
+	if m.Commit != 0 {
+		n += 1 + sovMetapb(uint64(m.Commit))
 	}
+	l = m.ShardEpoch.Size()
+	n += 1 + l + sovMetapb(uint64(l))
 	if m.CommitIndex != 0 {
 		n += 1 + sovMetapb(uint64(m.CommitIndex))
 	}
+	if m.AppliedIndex != 0 {
+		n += 1 + sovMetapb(uint64(m.AppliedIndex))
+	}
+	if m.ResolvedTS != 0 {
+		n += 1 + sovMetapb(uint64(m.ResolvedTS))
+	}
+	if m.ResolvedIndex != 0 {
+		n += 1 + sovMetapb(uint64(m.ResolvedIndex))
+	}
 	if m.SendTime != 0 {
 		n += 1 + sovMetapb(uint64(m.SendTime))
 	}
@@ -5257,15 +7505,83 @@ func (m *RaftMessage) Size() (n int) {
 	return n
 }
 
-func (m *SnapshotChunk) Size() (n int) {
+func (m *RaftMessage) Size() (n int) {
 	if m == nil {
 		return 0
 	}
 	var l int
 	_ = l
-	if m.StoreID != 0 {
-		n += 1 + sovMetapb(uint64(m.StoreID))
-	}
+	if m.ShardID != 0 {
+		n += 1 + sovMetapb(uint64(m.ShardID))
+	}
+	if m.Group != 0 {
+		n += 1 + sovMetapb(uint64(m.Group))
+	}
+	l = m.From.Size()
+	n += 1 + l + sovMetapb(uint64(l))
+	l = m.To.Size()
+	n += 1 + l + sovMetapb(uint64(l))
+	l = m.Message.Size()
+	n += 1 + l + sovMetapb(uint64(l))
+	l = m.ShardEpoch.Size()
+	n += 1 + l + sovMetapb(uint64(l))
+	if m.IsTombstone {
+		n += 2
+	}
+	l = len(m.Start)
+	if l > 0 {
+		n += 1 + l + sovMetapb(uint64(l))
+	}
+	l = len(m.End)
+	if l > 0 {
+		n += 1 + l + sovMetapb(uint64(l))
+	}
+	l = len(m.Unique)
+	if l > 0 {
+		n += 1 + l + sovMetapb(uint64(l))
+	}
+	if len(m.RuleGroups) > 0 {
+		for _, s := range m.RuleGroups {
+			l = len(s)
+			n += 1 + l + sovMetapb(uint64(l))
+		}
+	}
+	if m.CommitIndex != 0 {
+		n += 1 + sovMetapb(uint64(m.CommitIndex))
+	}
+	if m.SendTime != 0 {
+		n += 1 + sovMetapb(uint64(m.SendTime))
+	}
+	if m.ResolvedTS != 0 {
+		n += 1 + sovMetapb(uint64(m.ResolvedTS))
+	}
+	if m.ResolvedIndex != 0 {
+		n += 1 + sovMetapb(uint64(m.ResolvedIndex))
+	}
+	if len(m.WarmupKeys) > 0 {
+		for _, b := range m.WarmupKeys {
+			l = len(b)
+			n += 2 + l + sovMetapb(uint64(l))
+		}
+	}
+	if m.AppliedIndex != 0 {
+		n += 2 + sovMetapb(uint64(m.AppliedIndex))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *SnapshotChunk) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.StoreID != 0 {
+		n += 1 + sovMetapb(uint64(m.StoreID))
+	}
 	if m.ShardID != 0 {
 		n += 1 + sovMetapb(uint64(m.ShardID))
 	}
@@ -5313,6 +7629,12 @@ func (m *SnapshotChunk) Size() (n int) {
 	}
 	l = m.ConfState.Size()
 	n += 2 + l + sovMetapb(uint64(l))
+	if m.SnapshotSize != 0 {
+		n += 2 + sovMetapb(uint64(m.SnapshotSize))
+	}
+	if m.FromStoreID != 0 {
+		n += 2 + sovMetapb(uint64(m.FromStoreID))
+	}
 	if m.XXX_unrecognized != nil {
 		n += len(m.XXX_unrecognized)
 	}
@@ -5384,6 +7706,36 @@ func (m *Shard) Size() (n int) {
 			n += 1 + l + sovMetapb(uint64(l))
 		}
 	}
+	if m.DurabilityPolicy != 0 {
+		n += 1 + sovMetapb(uint64(m.DurabilityPolicy))
+	}
+	if m.ACL != nil {
+		l = m.ACL.Size()
+		n += 1 + l + sovMetapb(uint64(l))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *ShardACL) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Owner)
+	if l > 0 {
+		n += 1 + l + sovMetapb(uint64(l))
+	}
+	if len(m.AllowedOperations) > 0 {
+		l = 0
+		for _, e := range m.AllowedOperations {
+			l += sovMetapb(uint64(e))
+		}
+		n += 1 + sovMetapb(uint64(l)) + l
+	}
 	if m.XXX_unrecognized != nil {
 		n += len(m.XXX_unrecognized)
 	}
@@ -5442,6 +7794,62 @@ func (m *ShardLocalState) Size() (n int) {
 	if m.RemoveData {
 		n += 2
 	}
+	if m.MergeTarget != 0 {
+		n += 1 + sovMetapb(uint64(m.MergeTarget))
+	}
+	if m.FenceIndex != 0 {
+		n += 1 + sovMetapb(uint64(m.FenceIndex))
+	}
+	l = m.MergeTargetEpoch.Size()
+	n += 1 + l + sovMetapb(uint64(l))
+	if m.Migrated {
+		n += 2
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *BackupManifest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.Group != 0 {
+		n += 1 + sovMetapb(uint64(m.Group))
+	}
+	if m.CreatedAt != 0 {
+		n += 1 + sovMetapb(uint64(m.CreatedAt))
+	}
+	if len(m.Shards) > 0 {
+		for _, e := range m.Shards {
+			l = e.Size()
+			n += 1 + l + sovMetapb(uint64(l))
+		}
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *ShardBackup) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = m.Shard.Size()
+	n += 1 + l + sovMetapb(uint64(l))
+	if m.Index != 0 {
+		n += 1 + sovMetapb(uint64(m.Index))
+	}
+	l = len(m.Path)
+	if l > 0 {
+		n += 1 + l + sovMetapb(uint64(l))
+	}
 	if m.XXX_unrecognized != nil {
 		n += len(m.XXX_unrecognized)
 	}
@@ -5495,6 +7903,9 @@ func (m *Store) Size() (n int) {
 	if m.Destroyed {
 		n += 2
 	}
+	if m.Maintenance {
+		n += 2
+	}
 	if m.XXX_unrecognized != nil {
 		n += len(m.XXX_unrecognized)
 	}
@@ -5645,6 +8056,56 @@ func (m *SnapshotInfo) Size() (n int) {
 	if m.Dummy {
 		n += 2
 	}
+	if m.DeltaBase != 0 {
+		n += 1 + sovMetapb(uint64(m.DeltaBase))
+	}
+	if m.Witness {
+		n += 2
+	}
+	if m.Remote {
+		n += 2
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *SnapshotManifest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Prefix)
+	if l > 0 {
+		n += 1 + l + sovMetapb(uint64(l))
+	}
+	if len(m.Files) > 0 {
+		for _, e := range m.Files {
+			l = e.Size()
+			n += 1 + l + sovMetapb(uint64(l))
+		}
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *SnapshotFile) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Path)
+	if l > 0 {
+		n += 1 + l + sovMetapb(uint64(l))
+	}
+	if m.FileSize != 0 {
+		n += 1 + sovMetapb(uint64(m.FileSize))
+	}
 	if m.XXX_unrecognized != nil {
 		n += len(m.XXX_unrecognized)
 	}
@@ -5862,6 +8323,26 @@ func (m *Replica) Unmarshal(dAtA []byte) error {
 				}
 			}
 			m.InitialMember = bool(v != 0)
+		case 5:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field IsWitness", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMetapb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.IsWitness = bool(v != 0)
 		default:
 			iNdEx = preIndex
 			skippy, err := skipMetapb(dAtA[iNdEx:])
@@ -6309,65 +8790,11 @@ func (m *ShardStats) Unmarshal(dAtA []byte) error {
 				return err
 			}
 			iNdEx = postIndex
-		default:
-			iNdEx = preIndex
-			skippy, err := skipMetapb(dAtA[iNdEx:])
-			if err != nil {
-				return err
-			}
-			if skippy < 0 {
-				return ErrInvalidLengthMetapb
-			}
-			if (iNdEx + skippy) < 0 {
-				return ErrInvalidLengthMetapb
-			}
-			if (iNdEx + skippy) > l {
-				return io.ErrUnexpectedEOF
-			}
-			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
-			iNdEx += skippy
-		}
-	}
-
-	if iNdEx > l {
-		return io.ErrUnexpectedEOF
-	}
-	return nil
-}
-func (m *StoreStats) Unmarshal(dAtA []byte) error {
-	l := len(dAtA)
-	iNdEx := 0
-	for iNdEx < l {
-		preIndex := iNdEx
-		var wire uint64
-		for shift := uint(0); ; shift += 7 {
-			if shift >= 64 {
-				return ErrIntOverflowMetapb
-			}
-			if iNdEx >= l {
-				return io.ErrUnexpectedEOF
-			}
-			b := dAtA[iNdEx]
-			iNdEx++
-			wire |= uint64(b&0x7F) << shift
-			if b < 0x80 {
-				break
-			}
-		}
-		fieldNum := int32(wire >> 3)
-		wireType := int(wire & 0x7)
-		if wireType == 4 {
-			return fmt.Errorf("proto: StoreStats: wiretype end group for non-group")
-		}
-		if fieldNum <= 0 {
-			return fmt.Errorf("proto: StoreStats: illegal tag %d (wire type %d)", fieldNum, wire)
-		}
-		switch fieldNum {
-		case 1:
+		case 9:
 			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field StoreID", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field ResolvedTS", wireType)
 			}
-			m.StoreID = 0
+			m.ResolvedTS = 0
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowMetapb
@@ -6377,35 +8804,92 @@ func (m *StoreStats) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				m.StoreID |= uint64(b&0x7F) << shift
+				m.ResolvedTS |= uint64(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-		case 2:
-			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field StartTime", wireType)
-			}
-			m.StartTime = 0
-			for shift := uint(0); ; shift += 7 {
-				if shift >= 64 {
-					return ErrIntOverflowMetapb
-				}
-				if iNdEx >= l {
-					return io.ErrUnexpectedEOF
+		case 10:
+			if wireType == 0 {
+				var v uint64
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowMetapb
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					v |= uint64(b&0x7F) << shift
+					if b < 0x80 {
+						break
+					}
 				}
-				b := dAtA[iNdEx]
-				iNdEx++
-				m.StartTime |= uint64(b&0x7F) << shift
-				if b < 0x80 {
-					break
+				m.RecoveringReplicas = append(m.RecoveringReplicas, v)
+			} else if wireType == 2 {
+				var packedLen int
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowMetapb
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					packedLen |= int(b&0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				if packedLen < 0 {
+					return ErrInvalidLengthMetapb
+				}
+				postIndex := iNdEx + packedLen
+				if postIndex < 0 {
+					return ErrInvalidLengthMetapb
 				}
+				if postIndex > l {
+					return io.ErrUnexpectedEOF
+				}
+				var elementCount int
+				var count int
+				for _, integer := range dAtA[iNdEx:postIndex] {
+					if integer < 128 {
+						count++
+					}
+				}
+				elementCount = count
+				if elementCount != 0 && len(m.RecoveringReplicas) == 0 {
+					m.RecoveringReplicas = make([]uint64, 0, elementCount)
+				}
+				for iNdEx < postIndex {
+					var v uint64
+					for shift := uint(0); ; shift += 7 {
+						if shift >= 64 {
+							return ErrIntOverflowMetapb
+						}
+						if iNdEx >= l {
+							return io.ErrUnexpectedEOF
+						}
+						b := dAtA[iNdEx]
+						iNdEx++
+						v |= uint64(b&0x7F) << shift
+						if b < 0x80 {
+							break
+						}
+					}
+					m.RecoveringReplicas = append(m.RecoveringReplicas, v)
+				}
+			} else {
+				return fmt.Errorf("proto: wrong wireType = %d for field RecoveringReplicas", wireType)
 			}
-		case 3:
-			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Interval", wireType)
+		case 11:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Term", wireType)
 			}
-			var msglen int
+			m.Term = 0
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowMetapb
@@ -6415,33 +8899,16 @@ func (m *StoreStats) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				msglen |= int(b&0x7F) << shift
+				m.Term |= uint64(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			if msglen < 0 {
-				return ErrInvalidLengthMetapb
-			}
-			postIndex := iNdEx + msglen
-			if postIndex < 0 {
-				return ErrInvalidLengthMetapb
-			}
-			if postIndex > l {
-				return io.ErrUnexpectedEOF
-			}
-			if m.Interval == nil {
-				m.Interval = &TimeInterval{}
-			}
-			if err := m.Interval.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
-				return err
-			}
-			iNdEx = postIndex
-		case 4:
+		case 12:
 			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Capacity", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field CommitIndex", wireType)
 			}
-			m.Capacity = 0
+			m.CommitIndex = 0
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowMetapb
@@ -6451,16 +8918,16 @@ func (m *StoreStats) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				m.Capacity |= uint64(b&0x7F) << shift
+				m.CommitIndex |= uint64(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-		case 5:
+		case 13:
 			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Available", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field AppliedIndex", wireType)
 			}
-			m.Available = 0
+			m.AppliedIndex = 0
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowMetapb
@@ -6470,16 +8937,16 @@ func (m *StoreStats) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				m.Available |= uint64(b&0x7F) << shift
+				m.AppliedIndex |= uint64(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-		case 6:
+		case 14:
 			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field UsedSize", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field TruncatedIndex", wireType)
 			}
-			m.UsedSize = 0
+			m.TruncatedIndex = 0
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowMetapb
@@ -6489,16 +8956,16 @@ func (m *StoreStats) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				m.UsedSize |= uint64(b&0x7F) << shift
+				m.TruncatedIndex |= uint64(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-		case 7:
-			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field IsBusy", wireType)
+		case 15:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ReplicaMatchIndexes", wireType)
 			}
-			var v int
+			var msglen int
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowMetapb
@@ -6508,17 +8975,85 @@ func (m *StoreStats) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				v |= int(b&0x7F) << shift
+				msglen |= int(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			m.IsBusy = bool(v != 0)
-		case 8:
+			if msglen < 0 {
+				return ErrInvalidLengthMetapb
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthMetapb
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.ReplicaMatchIndexes = append(m.ReplicaMatchIndexes, ReplicaMatchIndex{})
+			if err := m.ReplicaMatchIndexes[len(m.ReplicaMatchIndexes)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipMetapb(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthMetapb
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthMetapb
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *ReplicaMatchIndex) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowMetapb
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: ReplicaMatchIndex: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: ReplicaMatchIndex: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
 			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field ShardCount", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field ReplicaID", wireType)
 			}
-			m.ShardCount = 0
+			m.ReplicaID = 0
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowMetapb
@@ -6528,16 +9063,16 @@ func (m *StoreStats) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				m.ShardCount |= uint64(b&0x7F) << shift
+				m.ReplicaID |= uint64(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-		case 9:
+		case 2:
 			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field SendingSnapCount", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field MatchIndex", wireType)
 			}
-			m.SendingSnapCount = 0
+			m.MatchIndex = 0
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowMetapb
@@ -6547,16 +9082,70 @@ func (m *StoreStats) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				m.SendingSnapCount |= uint64(b&0x7F) << shift
+				m.MatchIndex |= uint64(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-		case 10:
+		default:
+			iNdEx = preIndex
+			skippy, err := skipMetapb(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthMetapb
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthMetapb
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *StoreStats) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowMetapb
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: StoreStats: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: StoreStats: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
 			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field ReceivingSnapCount", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field StoreID", wireType)
 			}
-			m.ReceivingSnapCount = 0
+			m.StoreID = 0
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowMetapb
@@ -6566,16 +9155,16 @@ func (m *StoreStats) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				m.ReceivingSnapCount |= uint64(b&0x7F) << shift
+				m.StoreID |= uint64(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-		case 11:
+		case 2:
 			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field ApplyingSnapCount", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field StartTime", wireType)
 			}
-			m.ApplyingSnapCount = 0
+			m.StartTime = 0
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowMetapb
@@ -6585,13 +9174,202 @@ func (m *StoreStats) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				m.ApplyingSnapCount |= uint64(b&0x7F) << shift
+				m.StartTime |= uint64(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-		case 12:
-			if wireType != 0 {
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Interval", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMetapb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthMetapb
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthMetapb
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Interval == nil {
+				m.Interval = &TimeInterval{}
+			}
+			if err := m.Interval.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Capacity", wireType)
+			}
+			m.Capacity = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMetapb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Capacity |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 5:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Available", wireType)
+			}
+			m.Available = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMetapb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Available |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 6:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field UsedSize", wireType)
+			}
+			m.UsedSize = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMetapb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.UsedSize |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 7:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field IsBusy", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMetapb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.IsBusy = bool(v != 0)
+		case 8:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ShardCount", wireType)
+			}
+			m.ShardCount = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMetapb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.ShardCount |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 9:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field SendingSnapCount", wireType)
+			}
+			m.SendingSnapCount = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMetapb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.SendingSnapCount |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 10:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ReceivingSnapCount", wireType)
+			}
+			m.ReceivingSnapCount = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMetapb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.ReceivingSnapCount |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 11:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ApplyingSnapCount", wireType)
+			}
+			m.ApplyingSnapCount = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMetapb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.ApplyingSnapCount |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 12:
+			if wireType != 0 {
 				return fmt.Errorf("proto: wrong wireType = %d for field WrittenBytes", wireType)
 			}
 			m.WrittenBytes = 0
@@ -6802,65 +9580,11 @@ func (m *StoreStats) Unmarshal(dAtA []byte) error {
 				return err
 			}
 			iNdEx = postIndex
-		default:
-			iNdEx = preIndex
-			skippy, err := skipMetapb(dAtA[iNdEx:])
-			if err != nil {
-				return err
-			}
-			if skippy < 0 {
-				return ErrInvalidLengthMetapb
-			}
-			if (iNdEx + skippy) < 0 {
-				return ErrInvalidLengthMetapb
-			}
-			if (iNdEx + skippy) > l {
-				return io.ErrUnexpectedEOF
-			}
-			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
-			iNdEx += skippy
-		}
-	}
-
-	if iNdEx > l {
-		return io.ErrUnexpectedEOF
-	}
-	return nil
-}
-func (m *RecordPair) Unmarshal(dAtA []byte) error {
-	l := len(dAtA)
-	iNdEx := 0
-	for iNdEx < l {
-		preIndex := iNdEx
-		var wire uint64
-		for shift := uint(0); ; shift += 7 {
-			if shift >= 64 {
-				return ErrIntOverflowMetapb
-			}
-			if iNdEx >= l {
-				return io.ErrUnexpectedEOF
-			}
-			b := dAtA[iNdEx]
-			iNdEx++
-			wire |= uint64(b&0x7F) << shift
-			if b < 0x80 {
-				break
-			}
-		}
-		fieldNum := int32(wire >> 3)
-		wireType := int(wire & 0x7)
-		if wireType == 4 {
-			return fmt.Errorf("proto: RecordPair: wiretype end group for non-group")
-		}
-		if fieldNum <= 0 {
-			return fmt.Errorf("proto: RecordPair: illegal tag %d (wire type %d)", fieldNum, wire)
-		}
-		switch fieldNum {
-		case 1:
-			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Key", wireType)
+		case 20:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field LeaderSoftLimit", wireType)
 			}
-			var stringLen uint64
+			m.LeaderSoftLimit = 0
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowMetapb
@@ -6870,29 +9594,146 @@ func (m *RecordPair) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				stringLen |= uint64(b&0x7F) << shift
+				m.LeaderSoftLimit |= uint64(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			intStringLen := int(stringLen)
-			if intStringLen < 0 {
-				return ErrInvalidLengthMetapb
-			}
-			postIndex := iNdEx + intStringLen
+		case 21:
+			if wireType == 0 {
+				var v uint64
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowMetapb
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					v |= uint64(b&0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				m.StoppedGroups = append(m.StoppedGroups, v)
+			} else if wireType == 2 {
+				var packedLen int
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowMetapb
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					packedLen |= int(b&0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				if packedLen < 0 {
+					return ErrInvalidLengthMetapb
+				}
+				postIndex := iNdEx + packedLen
+				if postIndex < 0 {
+					return ErrInvalidLengthMetapb
+				}
+				if postIndex > l {
+					return io.ErrUnexpectedEOF
+				}
+				var elementCount int
+				var count int
+				for _, integer := range dAtA[iNdEx:postIndex] {
+					if integer < 128 {
+						count++
+					}
+				}
+				elementCount = count
+				if elementCount != 0 && len(m.StoppedGroups) == 0 {
+					m.StoppedGroups = make([]uint64, 0, elementCount)
+				}
+				for iNdEx < postIndex {
+					var v uint64
+					for shift := uint(0); ; shift += 7 {
+						if shift >= 64 {
+							return ErrIntOverflowMetapb
+						}
+						if iNdEx >= l {
+							return io.ErrUnexpectedEOF
+						}
+						b := dAtA[iNdEx]
+						iNdEx++
+						v |= uint64(b&0x7F) << shift
+						if b < 0x80 {
+							break
+						}
+					}
+					m.StoppedGroups = append(m.StoppedGroups, v)
+				}
+			} else {
+				return fmt.Errorf("proto: wrong wireType = %d for field StoppedGroups", wireType)
+			}
+		case 22:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field SnapshotThrottled", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMetapb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.SnapshotThrottled = bool(v != 0)
+		case 23:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field SnapshotProgresses", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMetapb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthMetapb
+			}
+			postIndex := iNdEx + msglen
 			if postIndex < 0 {
 				return ErrInvalidLengthMetapb
 			}
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Key = string(dAtA[iNdEx:postIndex])
+			m.SnapshotProgresses = append(m.SnapshotProgresses, SnapshotProgress{})
+			if err := m.SnapshotProgresses[len(m.SnapshotProgresses)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
 			iNdEx = postIndex
-		case 2:
+		case 24:
 			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Value", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Maintenance", wireType)
 			}
-			m.Value = 0
+			var v int
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowMetapb
@@ -6902,11 +9743,12 @@ func (m *RecordPair) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				m.Value |= uint64(b&0x7F) << shift
+				v |= int(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
+			m.Maintenance = bool(v != 0)
 		default:
 			iNdEx = preIndex
 			skippy, err := skipMetapb(dAtA[iNdEx:])
@@ -6932,7 +9774,7 @@ func (m *RecordPair) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *Member) Unmarshal(dAtA []byte) error {
+func (m *SnapshotProgress) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -6955,17 +9797,17 @@ func (m *Member) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: Member: wiretype end group for non-group")
+			return fmt.Errorf("proto: SnapshotProgress: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: Member: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: SnapshotProgress: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field ID", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field StoreID", wireType)
 			}
-			m.ID = 0
+			m.StoreID = 0
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowMetapb
@@ -6975,16 +9817,35 @@ func (m *Member) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				m.ID |= uint64(b&0x7F) << shift
+				m.StoreID |= uint64(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
 		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ShardID", wireType)
+			}
+			m.ShardID = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMetapb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.ShardID |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 3:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Name", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field From", wireType)
 			}
-			var stringLen uint64
+			var msglen int
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowMetapb
@@ -6994,29 +9855,30 @@ func (m *Member) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				stringLen |= uint64(b&0x7F) << shift
+				msglen |= int(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			intStringLen := int(stringLen)
-			if intStringLen < 0 {
+			if msglen < 0 {
 				return ErrInvalidLengthMetapb
 			}
-			postIndex := iNdEx + intStringLen
+			postIndex := iNdEx + msglen
 			if postIndex < 0 {
 				return ErrInvalidLengthMetapb
 			}
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Name = string(dAtA[iNdEx:postIndex])
+			if err := m.From.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
 			iNdEx = postIndex
-		case 3:
+		case 4:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Addr", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field To", wireType)
 			}
-			var stringLen uint64
+			var msglen int
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowMetapb
@@ -7026,83 +9888,69 @@ func (m *Member) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				stringLen |= uint64(b&0x7F) << shift
+				msglen |= int(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			intStringLen := int(stringLen)
-			if intStringLen < 0 {
+			if msglen < 0 {
 				return ErrInvalidLengthMetapb
 			}
-			postIndex := iNdEx + intStringLen
+			postIndex := iNdEx + msglen
 			if postIndex < 0 {
 				return ErrInvalidLengthMetapb
 			}
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Addr = string(dAtA[iNdEx:postIndex])
-			iNdEx = postIndex
-		default:
-			iNdEx = preIndex
-			skippy, err := skipMetapb(dAtA[iNdEx:])
-			if err != nil {
+			if err := m.To.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
 				return err
 			}
-			if skippy < 0 {
-				return ErrInvalidLengthMetapb
-			}
-			if (iNdEx + skippy) < 0 {
-				return ErrInvalidLengthMetapb
-			}
-			if (iNdEx + skippy) > l {
-				return io.ErrUnexpectedEOF
+			iNdEx = postIndex
+		case 5:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Index", wireType)
 			}
-			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
-			iNdEx += skippy
-		}
-	}
-
-	if iNdEx > l {
-		return io.ErrUnexpectedEOF
-	}
-	return nil
-}
-func (m *ProphetCluster) Unmarshal(dAtA []byte) error {
-	l := len(dAtA)
-	iNdEx := 0
-	for iNdEx < l {
-		preIndex := iNdEx
-		var wire uint64
-		for shift := uint(0); ; shift += 7 {
-			if shift >= 64 {
-				return ErrIntOverflowMetapb
+			m.Index = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMetapb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Index |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
 			}
-			if iNdEx >= l {
-				return io.ErrUnexpectedEOF
+		case 6:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Sending", wireType)
 			}
-			b := dAtA[iNdEx]
-			iNdEx++
-			wire |= uint64(b&0x7F) << shift
-			if b < 0x80 {
-				break
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMetapb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
 			}
-		}
-		fieldNum := int32(wire >> 3)
-		wireType := int(wire & 0x7)
-		if wireType == 4 {
-			return fmt.Errorf("proto: ProphetCluster: wiretype end group for non-group")
-		}
-		if fieldNum <= 0 {
-			return fmt.Errorf("proto: ProphetCluster: illegal tag %d (wire type %d)", fieldNum, wire)
-		}
-		switch fieldNum {
-		case 1:
+			m.Sending = bool(v != 0)
+		case 7:
 			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field ID", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field TotalBytes", wireType)
 			}
-			m.ID = 0
+			m.TotalBytes = 0
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowMetapb
@@ -7112,16 +9960,16 @@ func (m *ProphetCluster) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				m.ID |= uint64(b&0x7F) << shift
+				m.TotalBytes |= uint64(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-		case 2:
+		case 8:
 			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field MaxReplicaCount", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field TransferredBytes", wireType)
 			}
-			m.MaxReplicaCount = 0
+			m.TransferredBytes = 0
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowMetapb
@@ -7131,18 +9979,107 @@ func (m *ProphetCluster) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				m.MaxReplicaCount |= uint32(b&0x7F) << shift
+				m.TransferredBytes |= uint64(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-		default:
-			iNdEx = preIndex
-			skippy, err := skipMetapb(dAtA[iNdEx:])
-			if err != nil {
-				return err
+		case 9:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field StartTime", wireType)
 			}
-			if skippy < 0 {
+			m.StartTime = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMetapb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.StartTime |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 10:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Rate", wireType)
+			}
+			m.Rate = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMetapb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Rate |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 11:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ETA", wireType)
+			}
+			m.ETA = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMetapb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.ETA |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 12:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Operator", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMetapb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthMetapb
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthMetapb
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Operator = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipMetapb(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
 				return ErrInvalidLengthMetapb
 			}
 			if (iNdEx + skippy) < 0 {
@@ -7161,7 +10098,7 @@ func (m *ProphetCluster) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *TimeInterval) Unmarshal(dAtA []byte) error {
+func (m *RecordPair) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -7184,17 +10121,17 @@ func (m *TimeInterval) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: TimeInterval: wiretype end group for non-group")
+			return fmt.Errorf("proto: RecordPair: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: TimeInterval: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: RecordPair: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
-			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Start", wireType)
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Key", wireType)
 			}
-			m.Start = 0
+			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowMetapb
@@ -7204,16 +10141,29 @@ func (m *TimeInterval) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				m.Start |= uint64(b&0x7F) << shift
+				stringLen |= uint64(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthMetapb
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthMetapb
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Key = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
 		case 2:
 			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field End", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Value", wireType)
 			}
-			m.End = 0
+			m.Value = 0
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowMetapb
@@ -7223,7 +10173,7 @@ func (m *TimeInterval) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				m.End |= uint64(b&0x7F) << shift
+				m.Value |= uint64(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
@@ -7253,7 +10203,7 @@ func (m *TimeInterval) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *Job) Unmarshal(dAtA []byte) error {
+func (m *Member) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -7276,17 +10226,17 @@ func (m *Job) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: Job: wiretype end group for non-group")
+			return fmt.Errorf("proto: Member: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: Job: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: Member: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Type", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field ID", wireType)
 			}
-			m.Type = 0
+			m.ID = 0
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowMetapb
@@ -7296,16 +10246,16 @@ func (m *Job) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				m.Type |= JobType(b&0x7F) << shift
+				m.ID |= uint64(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
 		case 2:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Content", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Name", wireType)
 			}
-			var byteLen int
+			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowMetapb
@@ -7315,31 +10265,29 @@ func (m *Job) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				byteLen |= int(b&0x7F) << shift
+				stringLen |= uint64(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			if byteLen < 0 {
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
 				return ErrInvalidLengthMetapb
 			}
-			postIndex := iNdEx + byteLen
+			postIndex := iNdEx + intStringLen
 			if postIndex < 0 {
 				return ErrInvalidLengthMetapb
 			}
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Content = append(m.Content[:0], dAtA[iNdEx:postIndex]...)
-			if m.Content == nil {
-				m.Content = []byte{}
-			}
+			m.Name = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
 		case 3:
-			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field State", wireType)
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Addr", wireType)
 			}
-			m.State = 0
+			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowMetapb
@@ -7349,11 +10297,24 @@ func (m *Job) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				m.State |= JobState(b&0x7F) << shift
+				stringLen |= uint64(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthMetapb
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthMetapb
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Addr = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipMetapb(dAtA[iNdEx:])
@@ -7379,7 +10340,7 @@ func (m *Job) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *RemoveShardJob) Unmarshal(dAtA []byte) error {
+func (m *ProphetCluster) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -7402,10 +10363,10 @@ func (m *RemoveShardJob) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: RemoveShardJob: wiretype end group for non-group")
+			return fmt.Errorf("proto: ProphetCluster: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: RemoveShardJob: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: ProphetCluster: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
@@ -7428,10 +10389,10 @@ func (m *RemoveShardJob) Unmarshal(dAtA []byte) error {
 				}
 			}
 		case 2:
-			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Replicas", wireType)
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MaxReplicaCount", wireType)
 			}
-			var msglen int
+			m.MaxReplicaCount = 0
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowMetapb
@@ -7441,26 +10402,11 @@ func (m *RemoveShardJob) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				msglen |= int(b&0x7F) << shift
+				m.MaxReplicaCount |= uint32(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			if msglen < 0 {
-				return ErrInvalidLengthMetapb
-			}
-			postIndex := iNdEx + msglen
-			if postIndex < 0 {
-				return ErrInvalidLengthMetapb
-			}
-			if postIndex > l {
-				return io.ErrUnexpectedEOF
-			}
-			m.Replicas = append(m.Replicas, Replica{})
-			if err := m.Replicas[len(m.Replicas)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
-				return err
-			}
-			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipMetapb(dAtA[iNdEx:])
@@ -7486,7 +10432,7 @@ func (m *RemoveShardJob) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *ShardPoolJob) Unmarshal(dAtA []byte) error {
+func (m *TimeInterval) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -7509,17 +10455,17 @@ func (m *ShardPoolJob) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: ShardPoolJob: wiretype end group for non-group")
+			return fmt.Errorf("proto: TimeInterval: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: ShardPoolJob: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: TimeInterval: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
-			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Pools", wireType)
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Start", wireType)
 			}
-			var msglen int
+			m.Start = 0
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowMetapb
@@ -7529,26 +10475,30 @@ func (m *ShardPoolJob) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				msglen |= int(b&0x7F) << shift
+				m.Start |= uint64(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			if msglen < 0 {
-				return ErrInvalidLengthMetapb
-			}
-			postIndex := iNdEx + msglen
-			if postIndex < 0 {
-				return ErrInvalidLengthMetapb
-			}
-			if postIndex > l {
-				return io.ErrUnexpectedEOF
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field End", wireType)
 			}
-			m.Pools = append(m.Pools, ShardPoolJobMeta{})
-			if err := m.Pools[len(m.Pools)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
-				return err
+			m.End = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMetapb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.End |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
 			}
-			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipMetapb(dAtA[iNdEx:])
@@ -7574,7 +10524,7 @@ func (m *ShardPoolJob) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *ShardPoolJobMeta) Unmarshal(dAtA []byte) error {
+func (m *Job) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -7597,17 +10547,17 @@ func (m *ShardPoolJobMeta) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: ShardPoolJobMeta: wiretype end group for non-group")
+			return fmt.Errorf("proto: Job: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: ShardPoolJobMeta: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: Job: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Group", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Type", wireType)
 			}
-			m.Group = 0
+			m.Type = 0
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowMetapb
@@ -7617,16 +10567,16 @@ func (m *ShardPoolJobMeta) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				m.Group |= uint64(b&0x7F) << shift
+				m.Type |= JobType(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
 		case 2:
-			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Capacity", wireType)
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Content", wireType)
 			}
-			m.Capacity = 0
+			var byteLen int
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowMetapb
@@ -7636,16 +10586,31 @@ func (m *ShardPoolJobMeta) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				m.Capacity |= uint64(b&0x7F) << shift
+				byteLen |= int(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
+			if byteLen < 0 {
+				return ErrInvalidLengthMetapb
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthMetapb
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Content = append(m.Content[:0], dAtA[iNdEx:postIndex]...)
+			if m.Content == nil {
+				m.Content = []byte{}
+			}
+			iNdEx = postIndex
 		case 3:
-			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field RangePrefix", wireType)
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field State", wireType)
 			}
-			var byteLen int
+			m.State = 0
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowMetapb
@@ -7655,26 +10620,11 @@ func (m *ShardPoolJobMeta) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				byteLen |= int(b&0x7F) << shift
+				m.State |= JobState(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			if byteLen < 0 {
-				return ErrInvalidLengthMetapb
-			}
-			postIndex := iNdEx + byteLen
-			if postIndex < 0 {
-				return ErrInvalidLengthMetapb
-			}
-			if postIndex > l {
-				return io.ErrUnexpectedEOF
-			}
-			m.RangePrefix = append(m.RangePrefix[:0], dAtA[iNdEx:postIndex]...)
-			if m.RangePrefix == nil {
-				m.RangePrefix = []byte{}
-			}
-			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipMetapb(dAtA[iNdEx:])
@@ -7700,7 +10650,7 @@ func (m *ShardPoolJobMeta) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *DestroyingStatus) Unmarshal(dAtA []byte) error {
+func (m *RemoveShardJob) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -7723,17 +10673,17 @@ func (m *DestroyingStatus) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: DestroyingStatus: wiretype end group for non-group")
+			return fmt.Errorf("proto: RemoveShardJob: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: DestroyingStatus: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: RemoveShardJob: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Index", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field ID", wireType)
 			}
-			m.Index = 0
+			m.ID = 0
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowMetapb
@@ -7743,7 +10693,7 @@ func (m *DestroyingStatus) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				m.Index |= uint64(b&0x7F) << shift
+				m.ID |= uint64(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
@@ -7777,102 +10727,70 @@ func (m *DestroyingStatus) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			if m.Replicas == nil {
-				m.Replicas = make(map[uint64]bool)
-			}
-			var mapkey uint64
-			var mapvalue bool
-			for iNdEx < postIndex {
-				entryPreIndex := iNdEx
-				var wire uint64
-				for shift := uint(0); ; shift += 7 {
-					if shift >= 64 {
-						return ErrIntOverflowMetapb
-					}
-					if iNdEx >= l {
-						return io.ErrUnexpectedEOF
-					}
-					b := dAtA[iNdEx]
-					iNdEx++
-					wire |= uint64(b&0x7F) << shift
-					if b < 0x80 {
-						break
-					}
-				}
-				fieldNum := int32(wire >> 3)
-				if fieldNum == 1 {
-					for shift := uint(0); ; shift += 7 {
-						if shift >= 64 {
-							return ErrIntOverflowMetapb
-						}
-						if iNdEx >= l {
-							return io.ErrUnexpectedEOF
-						}
-						b := dAtA[iNdEx]
-						iNdEx++
-						mapkey |= uint64(b&0x7F) << shift
-						if b < 0x80 {
-							break
-						}
-					}
-				} else if fieldNum == 2 {
-					var mapvaluetemp int
-					for shift := uint(0); ; shift += 7 {
-						if shift >= 64 {
-							return ErrIntOverflowMetapb
-						}
-						if iNdEx >= l {
-							return io.ErrUnexpectedEOF
-						}
-						b := dAtA[iNdEx]
-						iNdEx++
-						mapvaluetemp |= int(b&0x7F) << shift
-						if b < 0x80 {
-							break
-						}
-					}
-					mapvalue = bool(mapvaluetemp != 0)
-				} else {
-					iNdEx = entryPreIndex
-					skippy, err := skipMetapb(dAtA[iNdEx:])
-					if err != nil {
-						return err
-					}
-					if skippy < 0 {
-						return ErrInvalidLengthMetapb
-					}
-					if (iNdEx + skippy) > postIndex {
-						return io.ErrUnexpectedEOF
-					}
-					iNdEx += skippy
-				}
+			m.Replicas = append(m.Replicas, Replica{})
+			if err := m.Replicas[len(m.Replicas)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
 			}
-			m.Replicas[mapkey] = mapvalue
 			iNdEx = postIndex
-		case 3:
-			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field State", wireType)
+		default:
+			iNdEx = preIndex
+			skippy, err := skipMetapb(dAtA[iNdEx:])
+			if err != nil {
+				return err
 			}
-			m.State = 0
-			for shift := uint(0); ; shift += 7 {
-				if shift >= 64 {
-					return ErrIntOverflowMetapb
-				}
-				if iNdEx >= l {
-					return io.ErrUnexpectedEOF
-				}
-				b := dAtA[iNdEx]
-				iNdEx++
-				m.State |= ShardState(b&0x7F) << shift
-				if b < 0x80 {
-					break
-				}
+			if skippy < 0 {
+				return ErrInvalidLengthMetapb
 			}
-		case 4:
-			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field RemoveData", wireType)
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthMetapb
 			}
-			var v int
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *ShardPoolJob) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowMetapb
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: ShardPoolJob: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: ShardPoolJob: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Pools", wireType)
+			}
+			var msglen int
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowMetapb
@@ -7882,12 +10800,26 @@ func (m *DestroyingStatus) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				v |= int(b&0x7F) << shift
+				msglen |= int(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			m.RemoveData = bool(v != 0)
+			if msglen < 0 {
+				return ErrInvalidLengthMetapb
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthMetapb
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Pools = append(m.Pools, ShardPoolJobMeta{})
+			if err := m.Pools[len(m.Pools)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipMetapb(dAtA[iNdEx:])
@@ -7913,7 +10845,7 @@ func (m *DestroyingStatus) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *ShardExtra) Unmarshal(dAtA []byte) error {
+func (m *ShardPoolJobMeta) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -7936,17 +10868,17 @@ func (m *ShardExtra) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: ShardExtra: wiretype end group for non-group")
+			return fmt.Errorf("proto: ShardPoolJobMeta: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: ShardExtra: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: ShardPoolJobMeta: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
-			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Labels", wireType)
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Group", wireType)
 			}
-			var msglen int
+			m.Group = 0
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowMetapb
@@ -7956,124 +10888,35 @@ func (m *ShardExtra) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				msglen |= int(b&0x7F) << shift
+				m.Group |= uint64(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			if msglen < 0 {
-				return ErrInvalidLengthMetapb
-			}
-			postIndex := iNdEx + msglen
-			if postIndex < 0 {
-				return ErrInvalidLengthMetapb
-			}
-			if postIndex > l {
-				return io.ErrUnexpectedEOF
-			}
-			if m.Labels == nil {
-				m.Labels = make(map[string]string)
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Capacity", wireType)
 			}
-			var mapkey string
-			var mapvalue string
-			for iNdEx < postIndex {
-				entryPreIndex := iNdEx
-				var wire uint64
-				for shift := uint(0); ; shift += 7 {
-					if shift >= 64 {
-						return ErrIntOverflowMetapb
-					}
-					if iNdEx >= l {
-						return io.ErrUnexpectedEOF
-					}
-					b := dAtA[iNdEx]
-					iNdEx++
-					wire |= uint64(b&0x7F) << shift
-					if b < 0x80 {
-						break
-					}
+			m.Capacity = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMetapb
 				}
-				fieldNum := int32(wire >> 3)
-				if fieldNum == 1 {
-					var stringLenmapkey uint64
-					for shift := uint(0); ; shift += 7 {
-						if shift >= 64 {
-							return ErrIntOverflowMetapb
-						}
-						if iNdEx >= l {
-							return io.ErrUnexpectedEOF
-						}
-						b := dAtA[iNdEx]
-						iNdEx++
-						stringLenmapkey |= uint64(b&0x7F) << shift
-						if b < 0x80 {
-							break
-						}
-					}
-					intStringLenmapkey := int(stringLenmapkey)
-					if intStringLenmapkey < 0 {
-						return ErrInvalidLengthMetapb
-					}
-					postStringIndexmapkey := iNdEx + intStringLenmapkey
-					if postStringIndexmapkey < 0 {
-						return ErrInvalidLengthMetapb
-					}
-					if postStringIndexmapkey > l {
-						return io.ErrUnexpectedEOF
-					}
-					mapkey = string(dAtA[iNdEx:postStringIndexmapkey])
-					iNdEx = postStringIndexmapkey
-				} else if fieldNum == 2 {
-					var stringLenmapvalue uint64
-					for shift := uint(0); ; shift += 7 {
-						if shift >= 64 {
-							return ErrIntOverflowMetapb
-						}
-						if iNdEx >= l {
-							return io.ErrUnexpectedEOF
-						}
-						b := dAtA[iNdEx]
-						iNdEx++
-						stringLenmapvalue |= uint64(b&0x7F) << shift
-						if b < 0x80 {
-							break
-						}
-					}
-					intStringLenmapvalue := int(stringLenmapvalue)
-					if intStringLenmapvalue < 0 {
-						return ErrInvalidLengthMetapb
-					}
-					postStringIndexmapvalue := iNdEx + intStringLenmapvalue
-					if postStringIndexmapvalue < 0 {
-						return ErrInvalidLengthMetapb
-					}
-					if postStringIndexmapvalue > l {
-						return io.ErrUnexpectedEOF
-					}
-					mapvalue = string(dAtA[iNdEx:postStringIndexmapvalue])
-					iNdEx = postStringIndexmapvalue
-				} else {
-					iNdEx = entryPreIndex
-					skippy, err := skipMetapb(dAtA[iNdEx:])
-					if err != nil {
-						return err
-					}
-					if skippy < 0 {
-						return ErrInvalidLengthMetapb
-					}
-					if (iNdEx + skippy) > postIndex {
-						return io.ErrUnexpectedEOF
-					}
-					iNdEx += skippy
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Capacity |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
 				}
 			}
-			m.Labels[mapkey] = mapvalue
-			iNdEx = postIndex
-		case 2:
+		case 3:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field DestroyingStatus", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field RangePrefix", wireType)
 			}
-			var msglen int
+			var byteLen int
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowMetapb
@@ -8083,26 +10926,24 @@ func (m *ShardExtra) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				msglen |= int(b&0x7F) << shift
+				byteLen |= int(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			if msglen < 0 {
+			if byteLen < 0 {
 				return ErrInvalidLengthMetapb
 			}
-			postIndex := iNdEx + msglen
+			postIndex := iNdEx + byteLen
 			if postIndex < 0 {
 				return ErrInvalidLengthMetapb
 			}
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			if m.DestroyingStatus == nil {
-				m.DestroyingStatus = &DestroyingStatus{}
-			}
-			if err := m.DestroyingStatus.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
-				return err
+			m.RangePrefix = append(m.RangePrefix[:0], dAtA[iNdEx:postIndex]...)
+			if m.RangePrefix == nil {
+				m.RangePrefix = []byte{}
 			}
 			iNdEx = postIndex
 		default:
@@ -8130,7 +10971,7 @@ func (m *ShardExtra) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *ScheduleGroupRule) Unmarshal(dAtA []byte) error {
+func (m *DestroyingStatus) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -8153,17 +10994,17 @@ func (m *ScheduleGroupRule) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: ScheduleGroupRule: wiretype end group for non-group")
+			return fmt.Errorf("proto: DestroyingStatus: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: ScheduleGroupRule: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: DestroyingStatus: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field ID", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Index", wireType)
 			}
-			m.ID = 0
+			m.Index = 0
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowMetapb
@@ -8173,16 +11014,16 @@ func (m *ScheduleGroupRule) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				m.ID |= uint64(b&0x7F) << shift
+				m.Index |= uint64(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
 		case 2:
-			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field GroupID", wireType)
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Replicas", wireType)
 			}
-			m.GroupID = 0
+			var msglen int
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowMetapb
@@ -8192,16 +11033,98 @@ func (m *ScheduleGroupRule) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				m.GroupID |= uint64(b&0x7F) << shift
+				msglen |= int(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
+			if msglen < 0 {
+				return ErrInvalidLengthMetapb
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthMetapb
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Replicas == nil {
+				m.Replicas = make(map[uint64]bool)
+			}
+			var mapkey uint64
+			var mapvalue bool
+			for iNdEx < postIndex {
+				entryPreIndex := iNdEx
+				var wire uint64
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowMetapb
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					wire |= uint64(b&0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				fieldNum := int32(wire >> 3)
+				if fieldNum == 1 {
+					for shift := uint(0); ; shift += 7 {
+						if shift >= 64 {
+							return ErrIntOverflowMetapb
+						}
+						if iNdEx >= l {
+							return io.ErrUnexpectedEOF
+						}
+						b := dAtA[iNdEx]
+						iNdEx++
+						mapkey |= uint64(b&0x7F) << shift
+						if b < 0x80 {
+							break
+						}
+					}
+				} else if fieldNum == 2 {
+					var mapvaluetemp int
+					for shift := uint(0); ; shift += 7 {
+						if shift >= 64 {
+							return ErrIntOverflowMetapb
+						}
+						if iNdEx >= l {
+							return io.ErrUnexpectedEOF
+						}
+						b := dAtA[iNdEx]
+						iNdEx++
+						mapvaluetemp |= int(b&0x7F) << shift
+						if b < 0x80 {
+							break
+						}
+					}
+					mapvalue = bool(mapvaluetemp != 0)
+				} else {
+					iNdEx = entryPreIndex
+					skippy, err := skipMetapb(dAtA[iNdEx:])
+					if err != nil {
+						return err
+					}
+					if skippy < 0 {
+						return ErrInvalidLengthMetapb
+					}
+					if (iNdEx + skippy) > postIndex {
+						return io.ErrUnexpectedEOF
+					}
+					iNdEx += skippy
+				}
+			}
+			m.Replicas[mapkey] = mapvalue
+			iNdEx = postIndex
 		case 3:
-			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Name", wireType)
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field State", wireType)
 			}
-			var stringLen uint64
+			m.State = 0
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowMetapb
@@ -8211,29 +11134,16 @@ func (m *ScheduleGroupRule) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				stringLen |= uint64(b&0x7F) << shift
+				m.State |= ShardState(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			intStringLen := int(stringLen)
-			if intStringLen < 0 {
-				return ErrInvalidLengthMetapb
-			}
-			postIndex := iNdEx + intStringLen
-			if postIndex < 0 {
-				return ErrInvalidLengthMetapb
-			}
-			if postIndex > l {
-				return io.ErrUnexpectedEOF
-			}
-			m.Name = string(dAtA[iNdEx:postIndex])
-			iNdEx = postIndex
 		case 4:
-			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field GroupByLabel", wireType)
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field RemoveData", wireType)
 			}
-			var stringLen uint64
+			var v int
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowMetapb
@@ -8243,24 +11153,50 @@ func (m *ScheduleGroupRule) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				stringLen |= uint64(b&0x7F) << shift
+				v |= int(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			intStringLen := int(stringLen)
-			if intStringLen < 0 {
-				return ErrInvalidLengthMetapb
+			m.RemoveData = bool(v != 0)
+		case 5:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field CreatedAt", wireType)
 			}
-			postIndex := iNdEx + intStringLen
-			if postIndex < 0 {
-				return ErrInvalidLengthMetapb
+			m.CreatedAt = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMetapb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.CreatedAt |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
 			}
-			if postIndex > l {
-				return io.ErrUnexpectedEOF
+		case 6:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field UpdatedAt", wireType)
+			}
+			m.UpdatedAt = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMetapb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.UpdatedAt |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
 			}
-			m.GroupByLabel = string(dAtA[iNdEx:postIndex])
-			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipMetapb(dAtA[iNdEx:])
@@ -8286,7 +11222,7 @@ func (m *ScheduleGroupRule) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *RaftMessageBatch) Unmarshal(dAtA []byte) error {
+func (m *ShardExtra) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -8309,15 +11245,15 @@ func (m *RaftMessageBatch) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: RaftMessageBatch: wiretype end group for non-group")
+			return fmt.Errorf("proto: ShardExtra: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: RaftMessageBatch: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: ShardExtra: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Messages", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Labels", wireType)
 			}
 			var msglen int
 			for shift := uint(0); ; shift += 7 {
@@ -8344,8 +11280,137 @@ func (m *RaftMessageBatch) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Messages = append(m.Messages, RaftMessage{})
-			if err := m.Messages[len(m.Messages)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+			if m.Labels == nil {
+				m.Labels = make(map[string]string)
+			}
+			var mapkey string
+			var mapvalue string
+			for iNdEx < postIndex {
+				entryPreIndex := iNdEx
+				var wire uint64
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowMetapb
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					wire |= uint64(b&0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				fieldNum := int32(wire >> 3)
+				if fieldNum == 1 {
+					var stringLenmapkey uint64
+					for shift := uint(0); ; shift += 7 {
+						if shift >= 64 {
+							return ErrIntOverflowMetapb
+						}
+						if iNdEx >= l {
+							return io.ErrUnexpectedEOF
+						}
+						b := dAtA[iNdEx]
+						iNdEx++
+						stringLenmapkey |= uint64(b&0x7F) << shift
+						if b < 0x80 {
+							break
+						}
+					}
+					intStringLenmapkey := int(stringLenmapkey)
+					if intStringLenmapkey < 0 {
+						return ErrInvalidLengthMetapb
+					}
+					postStringIndexmapkey := iNdEx + intStringLenmapkey
+					if postStringIndexmapkey < 0 {
+						return ErrInvalidLengthMetapb
+					}
+					if postStringIndexmapkey > l {
+						return io.ErrUnexpectedEOF
+					}
+					mapkey = string(dAtA[iNdEx:postStringIndexmapkey])
+					iNdEx = postStringIndexmapkey
+				} else if fieldNum == 2 {
+					var stringLenmapvalue uint64
+					for shift := uint(0); ; shift += 7 {
+						if shift >= 64 {
+							return ErrIntOverflowMetapb
+						}
+						if iNdEx >= l {
+							return io.ErrUnexpectedEOF
+						}
+						b := dAtA[iNdEx]
+						iNdEx++
+						stringLenmapvalue |= uint64(b&0x7F) << shift
+						if b < 0x80 {
+							break
+						}
+					}
+					intStringLenmapvalue := int(stringLenmapvalue)
+					if intStringLenmapvalue < 0 {
+						return ErrInvalidLengthMetapb
+					}
+					postStringIndexmapvalue := iNdEx + intStringLenmapvalue
+					if postStringIndexmapvalue < 0 {
+						return ErrInvalidLengthMetapb
+					}
+					if postStringIndexmapvalue > l {
+						return io.ErrUnexpectedEOF
+					}
+					mapvalue = string(dAtA[iNdEx:postStringIndexmapvalue])
+					iNdEx = postStringIndexmapvalue
+				} else {
+					iNdEx = entryPreIndex
+					skippy, err := skipMetapb(dAtA[iNdEx:])
+					if err != nil {
+						return err
+					}
+					if skippy < 0 {
+						return ErrInvalidLengthMetapb
+					}
+					if (iNdEx + skippy) > postIndex {
+						return io.ErrUnexpectedEOF
+					}
+					iNdEx += skippy
+				}
+			}
+			m.Labels[mapkey] = mapvalue
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field DestroyingStatus", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMetapb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthMetapb
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthMetapb
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.DestroyingStatus == nil {
+				m.DestroyingStatus = &DestroyingStatus{}
+			}
+			if err := m.DestroyingStatus.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
 				return err
 			}
 			iNdEx = postIndex
@@ -8374,7 +11439,7 @@ func (m *RaftMessageBatch) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *RaftMessage) Unmarshal(dAtA []byte) error {
+func (m *ScheduleGroupRule) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -8397,17 +11462,17 @@ func (m *RaftMessage) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: RaftMessage: wiretype end group for non-group")
+			return fmt.Errorf("proto: ScheduleGroupRule: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: RaftMessage: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: ScheduleGroupRule: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field ShardID", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field ID", wireType)
 			}
-			m.ShardID = 0
+			m.ID = 0
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowMetapb
@@ -8417,16 +11482,16 @@ func (m *RaftMessage) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				m.ShardID |= uint64(b&0x7F) << shift
+				m.ID |= uint64(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
 		case 2:
 			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Group", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field GroupID", wireType)
 			}
-			m.Group = 0
+			m.GroupID = 0
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowMetapb
@@ -8436,16 +11501,16 @@ func (m *RaftMessage) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				m.Group |= uint64(b&0x7F) << shift
+				m.GroupID |= uint64(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
 		case 3:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field From", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Name", wireType)
 			}
-			var msglen int
+			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowMetapb
@@ -8455,30 +11520,29 @@ func (m *RaftMessage) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				msglen |= int(b&0x7F) << shift
+				stringLen |= uint64(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			if msglen < 0 {
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
 				return ErrInvalidLengthMetapb
 			}
-			postIndex := iNdEx + msglen
+			postIndex := iNdEx + intStringLen
 			if postIndex < 0 {
 				return ErrInvalidLengthMetapb
 			}
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			if err := m.From.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
-				return err
-			}
+			m.Name = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
 		case 4:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field To", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field GroupByLabel", wireType)
 			}
-			var msglen int
+			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowMetapb
@@ -8488,63 +11552,83 @@ func (m *RaftMessage) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				msglen |= int(b&0x7F) << shift
+				stringLen |= uint64(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			if msglen < 0 {
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
 				return ErrInvalidLengthMetapb
 			}
-			postIndex := iNdEx + msglen
+			postIndex := iNdEx + intStringLen
 			if postIndex < 0 {
 				return ErrInvalidLengthMetapb
 			}
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			if err := m.To.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
-				return err
-			}
+			m.GroupByLabel = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
-		case 5:
-			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Message", wireType)
-			}
-			var msglen int
-			for shift := uint(0); ; shift += 7 {
-				if shift >= 64 {
-					return ErrIntOverflowMetapb
-				}
-				if iNdEx >= l {
-					return io.ErrUnexpectedEOF
-				}
-				b := dAtA[iNdEx]
-				iNdEx++
-				msglen |= int(b&0x7F) << shift
-				if b < 0x80 {
-					break
-				}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipMetapb(dAtA[iNdEx:])
+			if err != nil {
+				return err
 			}
-			if msglen < 0 {
+			if skippy < 0 {
 				return ErrInvalidLengthMetapb
 			}
-			postIndex := iNdEx + msglen
-			if postIndex < 0 {
+			if (iNdEx + skippy) < 0 {
 				return ErrInvalidLengthMetapb
 			}
-			if postIndex > l {
+			if (iNdEx + skippy) > l {
 				return io.ErrUnexpectedEOF
 			}
-			if err := m.Message.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
-				return err
-			}
-			iNdEx = postIndex
-		case 6:
-			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field ShardEpoch", wireType)
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *RangeRelocation) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowMetapb
 			}
-			var msglen int
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: RangeRelocation: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: RangeRelocation: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ID", wireType)
+			}
+			m.ID = 0
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowMetapb
@@ -8554,30 +11638,16 @@ func (m *RaftMessage) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				msglen |= int(b&0x7F) << shift
+				m.ID |= uint64(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			if msglen < 0 {
-				return ErrInvalidLengthMetapb
-			}
-			postIndex := iNdEx + msglen
-			if postIndex < 0 {
-				return ErrInvalidLengthMetapb
-			}
-			if postIndex > l {
-				return io.ErrUnexpectedEOF
-			}
-			if err := m.ShardEpoch.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
-				return err
-			}
-			iNdEx = postIndex
-		case 7:
+		case 2:
 			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field IsTombstone", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Group", wireType)
 			}
-			var v int
+			m.Group = 0
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowMetapb
@@ -8587,13 +11657,12 @@ func (m *RaftMessage) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				v |= int(b&0x7F) << shift
+				m.Group |= uint64(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			m.IsTombstone = bool(v != 0)
-		case 8:
+		case 3:
 			if wireType != 2 {
 				return fmt.Errorf("proto: wrong wireType = %d for field Start", wireType)
 			}
@@ -8627,7 +11696,7 @@ func (m *RaftMessage) Unmarshal(dAtA []byte) error {
 				m.Start = []byte{}
 			}
 			iNdEx = postIndex
-		case 9:
+		case 4:
 			if wireType != 2 {
 				return fmt.Errorf("proto: wrong wireType = %d for field End", wireType)
 			}
@@ -8661,107 +11730,81 @@ func (m *RaftMessage) Unmarshal(dAtA []byte) error {
 				m.End = []byte{}
 			}
 			iNdEx = postIndex
-		case 10:
-			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Unique", wireType)
-			}
-			var stringLen uint64
-			for shift := uint(0); ; shift += 7 {
-				if shift >= 64 {
-					return ErrIntOverflowMetapb
-				}
-				if iNdEx >= l {
-					return io.ErrUnexpectedEOF
-				}
-				b := dAtA[iNdEx]
-				iNdEx++
-				stringLen |= uint64(b&0x7F) << shift
-				if b < 0x80 {
-					break
-				}
-			}
-			intStringLen := int(stringLen)
-			if intStringLen < 0 {
-				return ErrInvalidLengthMetapb
-			}
-			postIndex := iNdEx + intStringLen
-			if postIndex < 0 {
-				return ErrInvalidLengthMetapb
-			}
-			if postIndex > l {
-				return io.ErrUnexpectedEOF
-			}
-			m.Unique = string(dAtA[iNdEx:postIndex])
-			iNdEx = postIndex
-		case 11:
-			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field RuleGroups", wireType)
-			}
-			var stringLen uint64
-			for shift := uint(0); ; shift += 7 {
-				if shift >= 64 {
-					return ErrIntOverflowMetapb
+		case 5:
+			if wireType == 0 {
+				var v uint64
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowMetapb
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					v |= uint64(b&0x7F) << shift
+					if b < 0x80 {
+						break
+					}
 				}
-				if iNdEx >= l {
-					return io.ErrUnexpectedEOF
+				m.Stores = append(m.Stores, v)
+			} else if wireType == 2 {
+				var packedLen int
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowMetapb
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					packedLen |= int(b&0x7F) << shift
+					if b < 0x80 {
+						break
+					}
 				}
-				b := dAtA[iNdEx]
-				iNdEx++
-				stringLen |= uint64(b&0x7F) << shift
-				if b < 0x80 {
-					break
+				if packedLen < 0 {
+					return ErrInvalidLengthMetapb
 				}
-			}
-			intStringLen := int(stringLen)
-			if intStringLen < 0 {
-				return ErrInvalidLengthMetapb
-			}
-			postIndex := iNdEx + intStringLen
-			if postIndex < 0 {
-				return ErrInvalidLengthMetapb
-			}
-			if postIndex > l {
-				return io.ErrUnexpectedEOF
-			}
-			m.RuleGroups = append(m.RuleGroups, string(dAtA[iNdEx:postIndex]))
-			iNdEx = postIndex
-		case 12:
-			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field CommitIndex", wireType)
-			}
-			m.CommitIndex = 0
-			for shift := uint(0); ; shift += 7 {
-				if shift >= 64 {
-					return ErrIntOverflowMetapb
+				postIndex := iNdEx + packedLen
+				if postIndex < 0 {
+					return ErrInvalidLengthMetapb
 				}
-				if iNdEx >= l {
+				if postIndex > l {
 					return io.ErrUnexpectedEOF
 				}
-				b := dAtA[iNdEx]
-				iNdEx++
-				m.CommitIndex |= uint64(b&0x7F) << shift
-				if b < 0x80 {
-					break
-				}
-			}
-		case 13:
-			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field SendTime", wireType)
-			}
-			m.SendTime = 0
-			for shift := uint(0); ; shift += 7 {
-				if shift >= 64 {
-					return ErrIntOverflowMetapb
+				var elementCount int
+				var count int
+				for _, integer := range dAtA[iNdEx:postIndex] {
+					if integer < 128 {
+						count++
+					}
 				}
-				if iNdEx >= l {
-					return io.ErrUnexpectedEOF
+				elementCount = count
+				if elementCount != 0 && len(m.Stores) == 0 {
+					m.Stores = make([]uint64, 0, elementCount)
 				}
-				b := dAtA[iNdEx]
-				iNdEx++
-				m.SendTime |= uint64(b&0x7F) << shift
-				if b < 0x80 {
-					break
+				for iNdEx < postIndex {
+					var v uint64
+					for shift := uint(0); ; shift += 7 {
+						if shift >= 64 {
+							return ErrIntOverflowMetapb
+						}
+						if iNdEx >= l {
+							return io.ErrUnexpectedEOF
+						}
+						b := dAtA[iNdEx]
+						iNdEx++
+						v |= uint64(b&0x7F) << shift
+						if b < 0x80 {
+							break
+						}
+					}
+					m.Stores = append(m.Stores, v)
 				}
+			} else {
+				return fmt.Errorf("proto: wrong wireType = %d for field Stores", wireType)
 			}
 		default:
 			iNdEx = preIndex
@@ -8788,7 +11831,7 @@ func (m *RaftMessage) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *SnapshotChunk) Unmarshal(dAtA []byte) error {
+func (m *LabelSteering) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -8811,17 +11854,17 @@ func (m *SnapshotChunk) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: SnapshotChunk: wiretype end group for non-group")
+			return fmt.Errorf("proto: LabelSteering: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: SnapshotChunk: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: LabelSteering: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field StoreID", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field ID", wireType)
 			}
-			m.StoreID = 0
+			m.ID = 0
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowMetapb
@@ -8831,16 +11874,16 @@ func (m *SnapshotChunk) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				m.StoreID |= uint64(b&0x7F) << shift
+				m.ID |= uint64(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
 		case 2:
 			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field ShardID", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Group", wireType)
 			}
-			m.ShardID = 0
+			m.Group = 0
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowMetapb
@@ -8850,16 +11893,16 @@ func (m *SnapshotChunk) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				m.ShardID |= uint64(b&0x7F) << shift
+				m.Group |= uint64(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
 		case 3:
-			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field ReplicaID", wireType)
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Start", wireType)
 			}
-			m.ReplicaID = 0
+			var byteLen int
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowMetapb
@@ -8869,16 +11912,31 @@ func (m *SnapshotChunk) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				m.ReplicaID |= uint64(b&0x7F) << shift
+				byteLen |= int(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
+			if byteLen < 0 {
+				return ErrInvalidLengthMetapb
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthMetapb
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Start = append(m.Start[:0], dAtA[iNdEx:postIndex]...)
+			if m.Start == nil {
+				m.Start = []byte{}
+			}
+			iNdEx = postIndex
 		case 4:
-			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field From", wireType)
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field End", wireType)
 			}
-			m.From = 0
+			var byteLen int
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowMetapb
@@ -8888,14 +11946,1162 @@ func (m *SnapshotChunk) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				m.From |= uint64(b&0x7F) << shift
+				byteLen |= int(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-		case 5:
-			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field ChunkID", wireType)
+			if byteLen < 0 {
+				return ErrInvalidLengthMetapb
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthMetapb
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.End = append(m.End[:0], dAtA[iNdEx:postIndex]...)
+			if m.End == nil {
+				m.End = []byte{}
+			}
+			iNdEx = postIndex
+		case 5:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Labels", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMetapb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthMetapb
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthMetapb
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Labels = append(m.Labels, Label{})
+			if err := m.Labels[len(m.Labels)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipMetapb(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthMetapb
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthMetapb
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *RaftMessageBatch) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowMetapb
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: RaftMessageBatch: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: RaftMessageBatch: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Messages", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMetapb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthMetapb
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthMetapb
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Messages = append(m.Messages, RaftMessage{})
+			if err := m.Messages[len(m.Messages)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Heartbeats", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMetapb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthMetapb
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthMetapb
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Heartbeats = append(m.Heartbeats, RaftHeartbeat{})
+			if err := m.Heartbeats[len(m.Heartbeats)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipMetapb(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthMetapb
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthMetapb
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *RaftHeartbeat) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowMetapb
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: RaftHeartbeat: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: RaftHeartbeat: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ShardID", wireType)
+			}
+			m.ShardID = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMetapb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.ShardID |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Group", wireType)
+			}
+			m.Group = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMetapb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Group |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field From", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMetapb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthMetapb
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthMetapb
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if err := m.From.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field To", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMetapb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthMetapb
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthMetapb
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if err := m.To.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 5:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Response", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMetapb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.Response = bool(v != 0)
+		case 6:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Term", wireType)
+			}
+			m.Term = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMetapb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Term |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 7:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Commit", wireType)
+			}
+			m.Commit = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMetapb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Commit |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 8:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ShardEpoch", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMetapb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthMetapb
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthMetapb
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if err := m.ShardEpoch.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 9:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field CommitIndex", wireType)
+			}
+			m.CommitIndex = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMetapb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.CommitIndex |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 10:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field AppliedIndex", wireType)
+			}
+			m.AppliedIndex = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMetapb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.AppliedIndex |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 11:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ResolvedTS", wireType)
+			}
+			m.ResolvedTS = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMetapb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.ResolvedTS |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 12:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ResolvedIndex", wireType)
+			}
+			m.ResolvedIndex = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMetapb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.ResolvedIndex |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 13:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field SendTime", wireType)
+			}
+			m.SendTime = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMetapb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.SendTime |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipMetapb(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthMetapb
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthMetapb
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *RaftMessage) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowMetapb
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: RaftMessage: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: RaftMessage: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ShardID", wireType)
+			}
+			m.ShardID = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMetapb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.ShardID |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Group", wireType)
+			}
+			m.Group = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMetapb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Group |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field From", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMetapb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthMetapb
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthMetapb
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if err := m.From.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field To", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMetapb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthMetapb
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthMetapb
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if err := m.To.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 5:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Message", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMetapb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthMetapb
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthMetapb
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if err := m.Message.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 6:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ShardEpoch", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMetapb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthMetapb
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthMetapb
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if err := m.ShardEpoch.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 7:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field IsTombstone", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMetapb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.IsTombstone = bool(v != 0)
+		case 8:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Start", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMetapb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthMetapb
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthMetapb
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Start = append(m.Start[:0], dAtA[iNdEx:postIndex]...)
+			if m.Start == nil {
+				m.Start = []byte{}
+			}
+			iNdEx = postIndex
+		case 9:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field End", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMetapb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthMetapb
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthMetapb
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.End = append(m.End[:0], dAtA[iNdEx:postIndex]...)
+			if m.End == nil {
+				m.End = []byte{}
+			}
+			iNdEx = postIndex
+		case 10:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Unique", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMetapb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthMetapb
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthMetapb
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Unique = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 11:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field RuleGroups", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMetapb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthMetapb
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthMetapb
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.RuleGroups = append(m.RuleGroups, string(dAtA[iNdEx:postIndex]))
+			iNdEx = postIndex
+		case 12:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field CommitIndex", wireType)
+			}
+			m.CommitIndex = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMetapb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.CommitIndex |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 13:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field SendTime", wireType)
+			}
+			m.SendTime = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMetapb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.SendTime |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 14:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ResolvedTS", wireType)
+			}
+			m.ResolvedTS = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMetapb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.ResolvedTS |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 15:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ResolvedIndex", wireType)
+			}
+			m.ResolvedIndex = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMetapb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.ResolvedIndex |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 16:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field WarmupKeys", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMetapb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthMetapb
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthMetapb
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.WarmupKeys = append(m.WarmupKeys, make([]byte, postIndex-iNdEx))
+			copy(m.WarmupKeys[len(m.WarmupKeys)-1], dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 17:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field AppliedIndex", wireType)
+			}
+			m.AppliedIndex = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMetapb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.AppliedIndex |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipMetapb(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthMetapb
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthMetapb
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *SnapshotChunk) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowMetapb
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: SnapshotChunk: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: SnapshotChunk: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field StoreID", wireType)
+			}
+			m.StoreID = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMetapb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.StoreID |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ShardID", wireType)
+			}
+			m.ShardID = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMetapb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.ShardID |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ReplicaID", wireType)
+			}
+			m.ReplicaID = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMetapb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.ReplicaID |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field From", wireType)
+			}
+			m.From = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMetapb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.From |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 5:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ChunkID", wireType)
 			}
 			m.ChunkID = 0
 			for shift := uint(0); ; shift += 7 {
@@ -8907,16 +13113,1020 @@ func (m *SnapshotChunk) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				m.ChunkID |= uint64(b&0x7F) << shift
+				m.ChunkID |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 6:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ChunkSize", wireType)
+			}
+			m.ChunkSize = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMetapb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.ChunkSize |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 7:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ChunkCount", wireType)
+			}
+			m.ChunkCount = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMetapb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.ChunkCount |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 8:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Index", wireType)
+			}
+			m.Index = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMetapb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Index |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 9:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Term", wireType)
+			}
+			m.Term = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMetapb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Term |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 10:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field FilePath", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMetapb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthMetapb
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthMetapb
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.FilePath = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 11:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field FileSize", wireType)
+			}
+			m.FileSize = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMetapb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.FileSize |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 12:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field FileChunkID", wireType)
+			}
+			m.FileChunkID = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMetapb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.FileChunkID |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 13:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field FileChunkCount", wireType)
+			}
+			m.FileChunkCount = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMetapb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.FileChunkCount |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 14:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Data", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMetapb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthMetapb
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthMetapb
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Data = append(m.Data[:0], dAtA[iNdEx:postIndex]...)
+			if m.Data == nil {
+				m.Data = []byte{}
+			}
+			iNdEx = postIndex
+		case 15:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Extra", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMetapb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthMetapb
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthMetapb
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Extra = append(m.Extra[:0], dAtA[iNdEx:postIndex]...)
+			if m.Extra == nil {
+				m.Extra = []byte{}
+			}
+			iNdEx = postIndex
+		case 16:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ConfState", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMetapb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthMetapb
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthMetapb
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if err := m.ConfState.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 17:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field SnapshotSize", wireType)
+			}
+			m.SnapshotSize = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMetapb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.SnapshotSize |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 18:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field FromStoreID", wireType)
+			}
+			m.FromStoreID = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMetapb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.FromStoreID |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipMetapb(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthMetapb
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthMetapb
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *StoreIdent) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowMetapb
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: StoreIdent: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: StoreIdent: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ClusterID", wireType)
+			}
+			m.ClusterID = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMetapb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.ClusterID |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field StoreID", wireType)
+			}
+			m.StoreID = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMetapb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.StoreID |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipMetapb(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthMetapb
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthMetapb
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *Shard) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowMetapb
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: Shard: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: Shard: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ID", wireType)
+			}
+			m.ID = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMetapb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.ID |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Start", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMetapb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthMetapb
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthMetapb
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Start = append(m.Start[:0], dAtA[iNdEx:postIndex]...)
+			if m.Start == nil {
+				m.Start = []byte{}
+			}
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field End", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMetapb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthMetapb
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthMetapb
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.End = append(m.End[:0], dAtA[iNdEx:postIndex]...)
+			if m.End == nil {
+				m.End = []byte{}
+			}
+			iNdEx = postIndex
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Epoch", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMetapb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthMetapb
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthMetapb
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if err := m.Epoch.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 5:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field State", wireType)
+			}
+			m.State = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMetapb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.State |= ShardState(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 6:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Replicas", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMetapb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthMetapb
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthMetapb
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Replicas = append(m.Replicas, Replica{})
+			if err := m.Replicas[len(m.Replicas)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 7:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Group", wireType)
+			}
+			m.Group = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMetapb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Group |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 8:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Unique", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMetapb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthMetapb
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthMetapb
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Unique = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 9:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field RuleGroups", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMetapb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthMetapb
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthMetapb
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.RuleGroups = append(m.RuleGroups, string(dAtA[iNdEx:postIndex]))
+			iNdEx = postIndex
+		case 10:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Labels", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMetapb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthMetapb
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthMetapb
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Labels = append(m.Labels, Label{})
+			if err := m.Labels[len(m.Labels)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 11:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field DurabilityPolicy", wireType)
+			}
+			m.DurabilityPolicy = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMetapb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.DurabilityPolicy |= DurabilityPolicy(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 12:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ACL", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMetapb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthMetapb
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthMetapb
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.ACL == nil {
+				m.ACL = &ShardACL{}
+			}
+			if err := m.ACL.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipMetapb(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthMetapb
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthMetapb
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *ShardACL) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowMetapb
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: ShardACL: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: ShardACL: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Owner", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMetapb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-		case 6:
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthMetapb
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthMetapb
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Owner = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType == 0 {
+				var v OperationClass
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowMetapb
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					v |= OperationClass(b&0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				m.AllowedOperations = append(m.AllowedOperations, v)
+			} else if wireType == 2 {
+				var packedLen int
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowMetapb
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					packedLen |= int(b&0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				if packedLen < 0 {
+					return ErrInvalidLengthMetapb
+				}
+				postIndex := iNdEx + packedLen
+				if postIndex < 0 {
+					return ErrInvalidLengthMetapb
+				}
+				if postIndex > l {
+					return io.ErrUnexpectedEOF
+				}
+				var elementCount int
+				if elementCount != 0 && len(m.AllowedOperations) == 0 {
+					m.AllowedOperations = make([]OperationClass, 0, elementCount)
+				}
+				for iNdEx < postIndex {
+					var v OperationClass
+					for shift := uint(0); ; shift += 7 {
+						if shift >= 64 {
+							return ErrIntOverflowMetapb
+						}
+						if iNdEx >= l {
+							return io.ErrUnexpectedEOF
+						}
+						b := dAtA[iNdEx]
+						iNdEx++
+						v |= OperationClass(b&0x7F) << shift
+						if b < 0x80 {
+							break
+						}
+					}
+					m.AllowedOperations = append(m.AllowedOperations, v)
+				}
+			} else {
+				return fmt.Errorf("proto: wrong wireType = %d for field AllowedOperations", wireType)
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipMetapb(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthMetapb
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthMetapb
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *LogIndex) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowMetapb
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: LogIndex: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: LogIndex: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
 			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field ChunkSize", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Index", wireType)
 			}
-			m.ChunkSize = 0
+			m.Index = 0
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowMetapb
@@ -8926,16 +14136,16 @@ func (m *SnapshotChunk) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				m.ChunkSize |= uint64(b&0x7F) << shift
+				m.Index |= uint64(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-		case 7:
+		case 2:
 			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field ChunkCount", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Term", wireType)
 			}
-			m.ChunkCount = 0
+			m.Term = 0
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowMetapb
@@ -8945,16 +14155,70 @@ func (m *SnapshotChunk) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				m.ChunkCount |= uint64(b&0x7F) << shift
+				m.Term |= uint64(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-		case 8:
+		default:
+			iNdEx = preIndex
+			skippy, err := skipMetapb(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthMetapb
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthMetapb
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *ShardMetadata) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowMetapb
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: ShardMetadata: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: ShardMetadata: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
 			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Index", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field ShardID", wireType)
 			}
-			m.Index = 0
+			m.ShardID = 0
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowMetapb
@@ -8964,16 +14228,16 @@ func (m *SnapshotChunk) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				m.Index |= uint64(b&0x7F) << shift
+				m.ShardID |= uint64(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-		case 9:
+		case 2:
 			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Term", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field LogIndex", wireType)
 			}
-			m.Term = 0
+			m.LogIndex = 0
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowMetapb
@@ -8983,16 +14247,16 @@ func (m *SnapshotChunk) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				m.Term |= uint64(b&0x7F) << shift
+				m.LogIndex |= uint64(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-		case 10:
+		case 3:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field FilePath", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Metadata", wireType)
 			}
-			var stringLen uint64
+			var msglen int
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowMetapb
@@ -9002,29 +14266,84 @@ func (m *SnapshotChunk) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				stringLen |= uint64(b&0x7F) << shift
+				msglen |= int(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			intStringLen := int(stringLen)
-			if intStringLen < 0 {
+			if msglen < 0 {
 				return ErrInvalidLengthMetapb
 			}
-			postIndex := iNdEx + intStringLen
+			postIndex := iNdEx + msglen
 			if postIndex < 0 {
 				return ErrInvalidLengthMetapb
 			}
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.FilePath = string(dAtA[iNdEx:postIndex])
+			if err := m.Metadata.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
 			iNdEx = postIndex
-		case 11:
-			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field FileSize", wireType)
+		default:
+			iNdEx = preIndex
+			skippy, err := skipMetapb(dAtA[iNdEx:])
+			if err != nil {
+				return err
 			}
-			m.FileSize = 0
+			if skippy < 0 {
+				return ErrInvalidLengthMetapb
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthMetapb
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *ShardLocalState) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowMetapb
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: ShardLocalState: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: ShardLocalState: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Shard", wireType)
+			}
+			var msglen int
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowMetapb
@@ -9034,16 +14353,30 @@ func (m *SnapshotChunk) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				m.FileSize |= uint64(b&0x7F) << shift
+				msglen |= int(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-		case 12:
+			if msglen < 0 {
+				return ErrInvalidLengthMetapb
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthMetapb
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if err := m.Shard.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 2:
 			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field FileChunkID", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field State", wireType)
 			}
-			m.FileChunkID = 0
+			m.State = 0
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowMetapb
@@ -9053,16 +14386,16 @@ func (m *SnapshotChunk) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				m.FileChunkID |= uint64(b&0x7F) << shift
+				m.State |= ReplicaState(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-		case 13:
+		case 3:
 			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field FileChunkCount", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field RemoveData", wireType)
 			}
-			m.FileChunkCount = 0
+			var v int
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowMetapb
@@ -9072,16 +14405,17 @@ func (m *SnapshotChunk) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				m.FileChunkCount |= uint64(b&0x7F) << shift
+				v |= int(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-		case 14:
-			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Data", wireType)
+			m.RemoveData = bool(v != 0)
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MergeTarget", wireType)
 			}
-			var byteLen int
+			m.MergeTarget = 0
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowMetapb
@@ -9091,31 +14425,16 @@ func (m *SnapshotChunk) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				byteLen |= int(b&0x7F) << shift
+				m.MergeTarget |= uint64(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			if byteLen < 0 {
-				return ErrInvalidLengthMetapb
-			}
-			postIndex := iNdEx + byteLen
-			if postIndex < 0 {
-				return ErrInvalidLengthMetapb
-			}
-			if postIndex > l {
-				return io.ErrUnexpectedEOF
-			}
-			m.Data = append(m.Data[:0], dAtA[iNdEx:postIndex]...)
-			if m.Data == nil {
-				m.Data = []byte{}
-			}
-			iNdEx = postIndex
-		case 15:
-			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Extra", wireType)
+		case 5:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field FenceIndex", wireType)
 			}
-			var byteLen int
+			m.FenceIndex = 0
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowMetapb
@@ -9125,29 +14444,14 @@ func (m *SnapshotChunk) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				byteLen |= int(b&0x7F) << shift
+				m.FenceIndex |= uint64(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			if byteLen < 0 {
-				return ErrInvalidLengthMetapb
-			}
-			postIndex := iNdEx + byteLen
-			if postIndex < 0 {
-				return ErrInvalidLengthMetapb
-			}
-			if postIndex > l {
-				return io.ErrUnexpectedEOF
-			}
-			m.Extra = append(m.Extra[:0], dAtA[iNdEx:postIndex]...)
-			if m.Extra == nil {
-				m.Extra = []byte{}
-			}
-			iNdEx = postIndex
-		case 16:
+		case 6:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field ConfState", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field MergeTargetEpoch", wireType)
 			}
 			var msglen int
 			for shift := uint(0); ; shift += 7 {
@@ -9174,88 +14478,15 @@ func (m *SnapshotChunk) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			if err := m.ConfState.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+			if err := m.MergeTargetEpoch.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
 				return err
 			}
 			iNdEx = postIndex
-		default:
-			iNdEx = preIndex
-			skippy, err := skipMetapb(dAtA[iNdEx:])
-			if err != nil {
-				return err
-			}
-			if skippy < 0 {
-				return ErrInvalidLengthMetapb
-			}
-			if (iNdEx + skippy) < 0 {
-				return ErrInvalidLengthMetapb
-			}
-			if (iNdEx + skippy) > l {
-				return io.ErrUnexpectedEOF
-			}
-			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
-			iNdEx += skippy
-		}
-	}
-
-	if iNdEx > l {
-		return io.ErrUnexpectedEOF
-	}
-	return nil
-}
-func (m *StoreIdent) Unmarshal(dAtA []byte) error {
-	l := len(dAtA)
-	iNdEx := 0
-	for iNdEx < l {
-		preIndex := iNdEx
-		var wire uint64
-		for shift := uint(0); ; shift += 7 {
-			if shift >= 64 {
-				return ErrIntOverflowMetapb
-			}
-			if iNdEx >= l {
-				return io.ErrUnexpectedEOF
-			}
-			b := dAtA[iNdEx]
-			iNdEx++
-			wire |= uint64(b&0x7F) << shift
-			if b < 0x80 {
-				break
-			}
-		}
-		fieldNum := int32(wire >> 3)
-		wireType := int(wire & 0x7)
-		if wireType == 4 {
-			return fmt.Errorf("proto: StoreIdent: wiretype end group for non-group")
-		}
-		if fieldNum <= 0 {
-			return fmt.Errorf("proto: StoreIdent: illegal tag %d (wire type %d)", fieldNum, wire)
-		}
-		switch fieldNum {
-		case 1:
-			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field ClusterID", wireType)
-			}
-			m.ClusterID = 0
-			for shift := uint(0); ; shift += 7 {
-				if shift >= 64 {
-					return ErrIntOverflowMetapb
-				}
-				if iNdEx >= l {
-					return io.ErrUnexpectedEOF
-				}
-				b := dAtA[iNdEx]
-				iNdEx++
-				m.ClusterID |= uint64(b&0x7F) << shift
-				if b < 0x80 {
-					break
-				}
-			}
-		case 2:
+		case 7:
 			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field StoreID", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Migrated", wireType)
 			}
-			m.StoreID = 0
+			var v int
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowMetapb
@@ -9265,11 +14496,12 @@ func (m *StoreIdent) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				m.StoreID |= uint64(b&0x7F) << shift
+				v |= int(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
+			m.Migrated = bool(v != 0)
 		default:
 			iNdEx = preIndex
 			skippy, err := skipMetapb(dAtA[iNdEx:])
@@ -9295,7 +14527,7 @@ func (m *StoreIdent) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *Shard) Unmarshal(dAtA []byte) error {
+func (m *BackupManifest) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -9318,17 +14550,17 @@ func (m *Shard) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: Shard: wiretype end group for non-group")
+			return fmt.Errorf("proto: BackupManifest: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: Shard: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: BackupManifest: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field ID", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Group", wireType)
 			}
-			m.ID = 0
+			m.Group = 0
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowMetapb
@@ -9338,16 +14570,16 @@ func (m *Shard) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				m.ID |= uint64(b&0x7F) << shift
+				m.Group |= uint64(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
 		case 2:
-			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Start", wireType)
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field CreatedAt", wireType)
 			}
-			var byteLen int
+			m.CreatedAt = 0
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowMetapb
@@ -9357,31 +14589,16 @@ func (m *Shard) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				byteLen |= int(b&0x7F) << shift
+				m.CreatedAt |= int64(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			if byteLen < 0 {
-				return ErrInvalidLengthMetapb
-			}
-			postIndex := iNdEx + byteLen
-			if postIndex < 0 {
-				return ErrInvalidLengthMetapb
-			}
-			if postIndex > l {
-				return io.ErrUnexpectedEOF
-			}
-			m.Start = append(m.Start[:0], dAtA[iNdEx:postIndex]...)
-			if m.Start == nil {
-				m.Start = []byte{}
-			}
-			iNdEx = postIndex
 		case 3:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field End", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Shards", wireType)
 			}
-			var byteLen int
+			var msglen int
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowMetapb
@@ -9391,29 +14608,83 @@ func (m *Shard) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				byteLen |= int(b&0x7F) << shift
+				msglen |= int(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			if byteLen < 0 {
+			if msglen < 0 {
 				return ErrInvalidLengthMetapb
 			}
-			postIndex := iNdEx + byteLen
+			postIndex := iNdEx + msglen
 			if postIndex < 0 {
 				return ErrInvalidLengthMetapb
 			}
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.End = append(m.End[:0], dAtA[iNdEx:postIndex]...)
-			if m.End == nil {
-				m.End = []byte{}
+			m.Shards = append(m.Shards, ShardBackup{})
+			if err := m.Shards[len(m.Shards)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
 			}
 			iNdEx = postIndex
-		case 4:
+		default:
+			iNdEx = preIndex
+			skippy, err := skipMetapb(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthMetapb
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthMetapb
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *ShardBackup) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowMetapb
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: ShardBackup: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: ShardBackup: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Epoch", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Shard", wireType)
 			}
 			var msglen int
 			for shift := uint(0); ; shift += 7 {
@@ -9440,15 +14711,15 @@ func (m *Shard) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			if err := m.Epoch.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+			if err := m.Shard.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
 				return err
 			}
 			iNdEx = postIndex
-		case 5:
+		case 2:
 			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field State", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Index", wireType)
 			}
-			m.State = 0
+			m.Index = 0
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowMetapb
@@ -9458,16 +14729,16 @@ func (m *Shard) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				m.State |= ShardState(b&0x7F) << shift
+				m.Index |= uint64(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-		case 6:
+		case 3:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Replicas", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Path", wireType)
 			}
-			var msglen int
+			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowMetapb
@@ -9477,31 +14748,83 @@ func (m *Shard) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				msglen |= int(b&0x7F) << shift
+				stringLen |= uint64(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			if msglen < 0 {
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
 				return ErrInvalidLengthMetapb
 			}
-			postIndex := iNdEx + msglen
+			postIndex := iNdEx + intStringLen
 			if postIndex < 0 {
 				return ErrInvalidLengthMetapb
 			}
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Replicas = append(m.Replicas, Replica{})
-			if err := m.Replicas[len(m.Replicas)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+			m.Path = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipMetapb(dAtA[iNdEx:])
+			if err != nil {
 				return err
 			}
-			iNdEx = postIndex
-		case 7:
+			if skippy < 0 {
+				return ErrInvalidLengthMetapb
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthMetapb
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *Store) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowMetapb
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: Store: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: Store: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
 			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Group", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field ID", wireType)
 			}
-			m.Group = 0
+			m.ID = 0
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowMetapb
@@ -9511,14 +14834,14 @@ func (m *Shard) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				m.Group |= uint64(b&0x7F) << shift
+				m.ID |= uint64(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-		case 8:
+		case 2:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Unique", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field RaftAddress", wireType)
 			}
 			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
@@ -9546,11 +14869,11 @@ func (m *Shard) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Unique = string(dAtA[iNdEx:postIndex])
+			m.RaftAddress = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
-		case 9:
+		case 3:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field RuleGroups", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field ClientAddress", wireType)
 			}
 			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
@@ -9578,9 +14901,9 @@ func (m *Shard) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.RuleGroups = append(m.RuleGroups, string(dAtA[iNdEx:postIndex]))
+			m.ClientAddress = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
-		case 10:
+		case 4:
 			if wireType != 2 {
 				return fmt.Errorf("proto: wrong wireType = %d for field Labels", wireType)
 			}
@@ -9614,65 +14937,30 @@ func (m *Shard) Unmarshal(dAtA []byte) error {
 				return err
 			}
 			iNdEx = postIndex
-		default:
-			iNdEx = preIndex
-			skippy, err := skipMetapb(dAtA[iNdEx:])
-			if err != nil {
-				return err
-			}
-			if skippy < 0 {
-				return ErrInvalidLengthMetapb
-			}
-			if (iNdEx + skippy) < 0 {
-				return ErrInvalidLengthMetapb
-			}
-			if (iNdEx + skippy) > l {
-				return io.ErrUnexpectedEOF
-			}
-			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
-			iNdEx += skippy
-		}
-	}
-
-	if iNdEx > l {
-		return io.ErrUnexpectedEOF
-	}
-	return nil
-}
-func (m *LogIndex) Unmarshal(dAtA []byte) error {
-	l := len(dAtA)
-	iNdEx := 0
-	for iNdEx < l {
-		preIndex := iNdEx
-		var wire uint64
-		for shift := uint(0); ; shift += 7 {
-			if shift >= 64 {
-				return ErrIntOverflowMetapb
-			}
-			if iNdEx >= l {
-				return io.ErrUnexpectedEOF
-			}
-			b := dAtA[iNdEx]
-			iNdEx++
-			wire |= uint64(b&0x7F) << shift
-			if b < 0x80 {
-				break
+		case 5:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field State", wireType)
 			}
-		}
-		fieldNum := int32(wire >> 3)
-		wireType := int(wire & 0x7)
-		if wireType == 4 {
-			return fmt.Errorf("proto: LogIndex: wiretype end group for non-group")
-		}
-		if fieldNum <= 0 {
-			return fmt.Errorf("proto: LogIndex: illegal tag %d (wire type %d)", fieldNum, wire)
-		}
-		switch fieldNum {
-		case 1:
+			m.State = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMetapb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.State |= StoreState(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 6:
 			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Index", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field StartTime", wireType)
 			}
-			m.Index = 0
+			m.StartTime = 0
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowMetapb
@@ -9682,16 +14970,16 @@ func (m *LogIndex) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				m.Index |= uint64(b&0x7F) << shift
+				m.StartTime |= int64(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-		case 2:
+		case 7:
 			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Term", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field LastHeartbeatTime", wireType)
 			}
-			m.Term = 0
+			m.LastHeartbeatTime = 0
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowMetapb
@@ -9701,70 +14989,80 @@ func (m *LogIndex) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				m.Term |= uint64(b&0x7F) << shift
+				m.LastHeartbeatTime |= int64(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-		default:
-			iNdEx = preIndex
-			skippy, err := skipMetapb(dAtA[iNdEx:])
-			if err != nil {
-				return err
+		case 8:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Version", wireType)
 			}
-			if skippy < 0 {
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMetapb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
 				return ErrInvalidLengthMetapb
 			}
-			if (iNdEx + skippy) < 0 {
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
 				return ErrInvalidLengthMetapb
 			}
-			if (iNdEx + skippy) > l {
+			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
-			iNdEx += skippy
-		}
-	}
-
-	if iNdEx > l {
-		return io.ErrUnexpectedEOF
-	}
-	return nil
-}
-func (m *ShardMetadata) Unmarshal(dAtA []byte) error {
-	l := len(dAtA)
-	iNdEx := 0
-	for iNdEx < l {
-		preIndex := iNdEx
-		var wire uint64
-		for shift := uint(0); ; shift += 7 {
-			if shift >= 64 {
-				return ErrIntOverflowMetapb
+			m.Version = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 9:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field CommitID", wireType)
 			}
-			if iNdEx >= l {
-				return io.ErrUnexpectedEOF
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMetapb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
 			}
-			b := dAtA[iNdEx]
-			iNdEx++
-			wire |= uint64(b&0x7F) << shift
-			if b < 0x80 {
-				break
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthMetapb
 			}
-		}
-		fieldNum := int32(wire >> 3)
-		wireType := int(wire & 0x7)
-		if wireType == 4 {
-			return fmt.Errorf("proto: ShardMetadata: wiretype end group for non-group")
-		}
-		if fieldNum <= 0 {
-			return fmt.Errorf("proto: ShardMetadata: illegal tag %d (wire type %d)", fieldNum, wire)
-		}
-		switch fieldNum {
-		case 1:
-			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field ShardID", wireType)
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthMetapb
 			}
-			m.ShardID = 0
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.CommitID = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 10:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field DeployPath", wireType)
+			}
+			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowMetapb
@@ -9774,16 +15072,29 @@ func (m *ShardMetadata) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				m.ShardID |= uint64(b&0x7F) << shift
+				stringLen |= uint64(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-		case 2:
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthMetapb
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthMetapb
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.DeployPath = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 11:
 			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field LogIndex", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Destroyed", wireType)
 			}
-			m.LogIndex = 0
+			var v int
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowMetapb
@@ -9793,16 +15104,17 @@ func (m *ShardMetadata) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				m.LogIndex |= uint64(b&0x7F) << shift
+				v |= int(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-		case 3:
-			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Metadata", wireType)
+			m.Destroyed = bool(v != 0)
+		case 12:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Maintenance", wireType)
 			}
-			var msglen int
+			var v int
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowMetapb
@@ -9812,25 +15124,12 @@ func (m *ShardMetadata) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				msglen |= int(b&0x7F) << shift
+				v |= int(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			if msglen < 0 {
-				return ErrInvalidLengthMetapb
-			}
-			postIndex := iNdEx + msglen
-			if postIndex < 0 {
-				return ErrInvalidLengthMetapb
-			}
-			if postIndex > l {
-				return io.ErrUnexpectedEOF
-			}
-			if err := m.Metadata.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
-				return err
-			}
-			iNdEx = postIndex
+			m.Maintenance = bool(v != 0)
 		default:
 			iNdEx = preIndex
 			skippy, err := skipMetapb(dAtA[iNdEx:])
@@ -9856,7 +15155,7 @@ func (m *ShardMetadata) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *ShardLocalState) Unmarshal(dAtA []byte) error {
+func (m *ShardsPool) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -9879,15 +15178,15 @@ func (m *ShardLocalState) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: ShardLocalState: wiretype end group for non-group")
+			return fmt.Errorf("proto: ShardsPool: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: ShardLocalState: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: ShardsPool: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Shard", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Pools", wireType)
 			}
 			var msglen int
 			for shift := uint(0); ; shift += 7 {
@@ -9914,49 +15213,92 @@ func (m *ShardLocalState) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			if err := m.Shard.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
-				return err
-			}
-			iNdEx = postIndex
-		case 2:
-			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field State", wireType)
-			}
-			m.State = 0
-			for shift := uint(0); ; shift += 7 {
-				if shift >= 64 {
-					return ErrIntOverflowMetapb
-				}
-				if iNdEx >= l {
-					return io.ErrUnexpectedEOF
-				}
-				b := dAtA[iNdEx]
-				iNdEx++
-				m.State |= ReplicaState(b&0x7F) << shift
-				if b < 0x80 {
-					break
-				}
-			}
-		case 3:
-			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field RemoveData", wireType)
+			if m.Pools == nil {
+				m.Pools = make(map[uint64]*ShardPool)
 			}
-			var v int
-			for shift := uint(0); ; shift += 7 {
-				if shift >= 64 {
-					return ErrIntOverflowMetapb
-				}
-				if iNdEx >= l {
-					return io.ErrUnexpectedEOF
+			var mapkey uint64
+			var mapvalue *ShardPool
+			for iNdEx < postIndex {
+				entryPreIndex := iNdEx
+				var wire uint64
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowMetapb
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					wire |= uint64(b&0x7F) << shift
+					if b < 0x80 {
+						break
+					}
 				}
-				b := dAtA[iNdEx]
-				iNdEx++
-				v |= int(b&0x7F) << shift
-				if b < 0x80 {
-					break
+				fieldNum := int32(wire >> 3)
+				if fieldNum == 1 {
+					for shift := uint(0); ; shift += 7 {
+						if shift >= 64 {
+							return ErrIntOverflowMetapb
+						}
+						if iNdEx >= l {
+							return io.ErrUnexpectedEOF
+						}
+						b := dAtA[iNdEx]
+						iNdEx++
+						mapkey |= uint64(b&0x7F) << shift
+						if b < 0x80 {
+							break
+						}
+					}
+				} else if fieldNum == 2 {
+					var mapmsglen int
+					for shift := uint(0); ; shift += 7 {
+						if shift >= 64 {
+							return ErrIntOverflowMetapb
+						}
+						if iNdEx >= l {
+							return io.ErrUnexpectedEOF
+						}
+						b := dAtA[iNdEx]
+						iNdEx++
+						mapmsglen |= int(b&0x7F) << shift
+						if b < 0x80 {
+							break
+						}
+					}
+					if mapmsglen < 0 {
+						return ErrInvalidLengthMetapb
+					}
+					postmsgIndex := iNdEx + mapmsglen
+					if postmsgIndex < 0 {
+						return ErrInvalidLengthMetapb
+					}
+					if postmsgIndex > l {
+						return io.ErrUnexpectedEOF
+					}
+					mapvalue = &ShardPool{}
+					if err := mapvalue.Unmarshal(dAtA[iNdEx:postmsgIndex]); err != nil {
+						return err
+					}
+					iNdEx = postmsgIndex
+				} else {
+					iNdEx = entryPreIndex
+					skippy, err := skipMetapb(dAtA[iNdEx:])
+					if err != nil {
+						return err
+					}
+					if skippy < 0 {
+						return ErrInvalidLengthMetapb
+					}
+					if (iNdEx + skippy) > postIndex {
+						return io.ErrUnexpectedEOF
+					}
+					iNdEx += skippy
 				}
 			}
-			m.RemoveData = bool(v != 0)
+			m.Pools[mapkey] = mapvalue
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipMetapb(dAtA[iNdEx:])
@@ -9982,7 +15324,7 @@ func (m *ShardLocalState) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *Store) Unmarshal(dAtA []byte) error {
+func (m *ShardPool) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -10005,17 +15347,17 @@ func (m *Store) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: Store: wiretype end group for non-group")
+			return fmt.Errorf("proto: ShardPool: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: Store: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: ShardPool: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field ID", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Capacity", wireType)
 			}
-			m.ID = 0
+			m.Capacity = 0
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowMetapb
@@ -10025,16 +15367,16 @@ func (m *Store) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				m.ID |= uint64(b&0x7F) << shift
+				m.Capacity |= uint64(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
 		case 2:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field RaftAddress", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field RangePrefix", wireType)
 			}
-			var stringLen uint64
+			var byteLen int
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowMetapb
@@ -10044,59 +15386,29 @@ func (m *Store) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				stringLen |= uint64(b&0x7F) << shift
+				byteLen |= int(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			intStringLen := int(stringLen)
-			if intStringLen < 0 {
+			if byteLen < 0 {
 				return ErrInvalidLengthMetapb
 			}
-			postIndex := iNdEx + intStringLen
+			postIndex := iNdEx + byteLen
 			if postIndex < 0 {
 				return ErrInvalidLengthMetapb
 			}
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.RaftAddress = string(dAtA[iNdEx:postIndex])
-			iNdEx = postIndex
-		case 3:
-			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field ClientAddress", wireType)
-			}
-			var stringLen uint64
-			for shift := uint(0); ; shift += 7 {
-				if shift >= 64 {
-					return ErrIntOverflowMetapb
-				}
-				if iNdEx >= l {
-					return io.ErrUnexpectedEOF
-				}
-				b := dAtA[iNdEx]
-				iNdEx++
-				stringLen |= uint64(b&0x7F) << shift
-				if b < 0x80 {
-					break
-				}
-			}
-			intStringLen := int(stringLen)
-			if intStringLen < 0 {
-				return ErrInvalidLengthMetapb
-			}
-			postIndex := iNdEx + intStringLen
-			if postIndex < 0 {
-				return ErrInvalidLengthMetapb
-			}
-			if postIndex > l {
-				return io.ErrUnexpectedEOF
+			m.RangePrefix = append(m.RangePrefix[:0], dAtA[iNdEx:postIndex]...)
+			if m.RangePrefix == nil {
+				m.RangePrefix = []byte{}
 			}
-			m.ClientAddress = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
-		case 4:
+		case 3:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Labels", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field AllocatedShards", wireType)
 			}
 			var msglen int
 			for shift := uint(0); ; shift += 7 {
@@ -10123,137 +15435,16 @@ func (m *Store) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Labels = append(m.Labels, Label{})
-			if err := m.Labels[len(m.Labels)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+			m.AllocatedShards = append(m.AllocatedShards, &AllocatedShard{})
+			if err := m.AllocatedShards[len(m.AllocatedShards)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
 				return err
 			}
 			iNdEx = postIndex
-		case 5:
-			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field State", wireType)
-			}
-			m.State = 0
-			for shift := uint(0); ; shift += 7 {
-				if shift >= 64 {
-					return ErrIntOverflowMetapb
-				}
-				if iNdEx >= l {
-					return io.ErrUnexpectedEOF
-				}
-				b := dAtA[iNdEx]
-				iNdEx++
-				m.State |= StoreState(b&0x7F) << shift
-				if b < 0x80 {
-					break
-				}
-			}
-		case 6:
-			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field StartTime", wireType)
-			}
-			m.StartTime = 0
-			for shift := uint(0); ; shift += 7 {
-				if shift >= 64 {
-					return ErrIntOverflowMetapb
-				}
-				if iNdEx >= l {
-					return io.ErrUnexpectedEOF
-				}
-				b := dAtA[iNdEx]
-				iNdEx++
-				m.StartTime |= int64(b&0x7F) << shift
-				if b < 0x80 {
-					break
-				}
-			}
-		case 7:
+		case 4:
 			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field LastHeartbeatTime", wireType)
-			}
-			m.LastHeartbeatTime = 0
-			for shift := uint(0); ; shift += 7 {
-				if shift >= 64 {
-					return ErrIntOverflowMetapb
-				}
-				if iNdEx >= l {
-					return io.ErrUnexpectedEOF
-				}
-				b := dAtA[iNdEx]
-				iNdEx++
-				m.LastHeartbeatTime |= int64(b&0x7F) << shift
-				if b < 0x80 {
-					break
-				}
-			}
-		case 8:
-			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Version", wireType)
-			}
-			var stringLen uint64
-			for shift := uint(0); ; shift += 7 {
-				if shift >= 64 {
-					return ErrIntOverflowMetapb
-				}
-				if iNdEx >= l {
-					return io.ErrUnexpectedEOF
-				}
-				b := dAtA[iNdEx]
-				iNdEx++
-				stringLen |= uint64(b&0x7F) << shift
-				if b < 0x80 {
-					break
-				}
-			}
-			intStringLen := int(stringLen)
-			if intStringLen < 0 {
-				return ErrInvalidLengthMetapb
-			}
-			postIndex := iNdEx + intStringLen
-			if postIndex < 0 {
-				return ErrInvalidLengthMetapb
-			}
-			if postIndex > l {
-				return io.ErrUnexpectedEOF
-			}
-			m.Version = string(dAtA[iNdEx:postIndex])
-			iNdEx = postIndex
-		case 9:
-			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field CommitID", wireType)
-			}
-			var stringLen uint64
-			for shift := uint(0); ; shift += 7 {
-				if shift >= 64 {
-					return ErrIntOverflowMetapb
-				}
-				if iNdEx >= l {
-					return io.ErrUnexpectedEOF
-				}
-				b := dAtA[iNdEx]
-				iNdEx++
-				stringLen |= uint64(b&0x7F) << shift
-				if b < 0x80 {
-					break
-				}
-			}
-			intStringLen := int(stringLen)
-			if intStringLen < 0 {
-				return ErrInvalidLengthMetapb
-			}
-			postIndex := iNdEx + intStringLen
-			if postIndex < 0 {
-				return ErrInvalidLengthMetapb
-			}
-			if postIndex > l {
-				return io.ErrUnexpectedEOF
-			}
-			m.CommitID = string(dAtA[iNdEx:postIndex])
-			iNdEx = postIndex
-		case 10:
-			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field DeployPath", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Seq", wireType)
 			}
-			var stringLen uint64
+			m.Seq = 0
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowMetapb
@@ -10263,29 +15454,16 @@ func (m *Store) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				stringLen |= uint64(b&0x7F) << shift
+				m.Seq |= uint64(b&0x7F) << shift
 				if b < 0x80 {
 					break
-				}
-			}
-			intStringLen := int(stringLen)
-			if intStringLen < 0 {
-				return ErrInvalidLengthMetapb
-			}
-			postIndex := iNdEx + intStringLen
-			if postIndex < 0 {
-				return ErrInvalidLengthMetapb
-			}
-			if postIndex > l {
-				return io.ErrUnexpectedEOF
+				}
 			}
-			m.DeployPath = string(dAtA[iNdEx:postIndex])
-			iNdEx = postIndex
-		case 11:
+		case 5:
 			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Destroyed", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field AllocatedOffset", wireType)
 			}
-			var v int
+			m.AllocatedOffset = 0
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowMetapb
@@ -10295,12 +15473,11 @@ func (m *Store) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				v |= int(b&0x7F) << shift
+				m.AllocatedOffset |= uint64(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			m.Destroyed = bool(v != 0)
 		default:
 			iNdEx = preIndex
 			skippy, err := skipMetapb(dAtA[iNdEx:])
@@ -10326,7 +15503,7 @@ func (m *Store) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *ShardsPool) Unmarshal(dAtA []byte) error {
+func (m *AllocatedShard) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -10349,17 +15526,55 @@ func (m *ShardsPool) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: ShardsPool: wiretype end group for non-group")
+			return fmt.Errorf("proto: AllocatedShard: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: ShardsPool: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: AllocatedShard: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ShardID", wireType)
+			}
+			m.ShardID = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMetapb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.ShardID |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field AllocatedAt", wireType)
+			}
+			m.AllocatedAt = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMetapb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.AllocatedAt |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 3:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Pools", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Purpose", wireType)
 			}
-			var msglen int
+			var byteLen int
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowMetapb
@@ -10369,106 +15584,25 @@ func (m *ShardsPool) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				msglen |= int(b&0x7F) << shift
+				byteLen |= int(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			if msglen < 0 {
+			if byteLen < 0 {
 				return ErrInvalidLengthMetapb
 			}
-			postIndex := iNdEx + msglen
+			postIndex := iNdEx + byteLen
 			if postIndex < 0 {
 				return ErrInvalidLengthMetapb
 			}
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			if m.Pools == nil {
-				m.Pools = make(map[uint64]*ShardPool)
-			}
-			var mapkey uint64
-			var mapvalue *ShardPool
-			for iNdEx < postIndex {
-				entryPreIndex := iNdEx
-				var wire uint64
-				for shift := uint(0); ; shift += 7 {
-					if shift >= 64 {
-						return ErrIntOverflowMetapb
-					}
-					if iNdEx >= l {
-						return io.ErrUnexpectedEOF
-					}
-					b := dAtA[iNdEx]
-					iNdEx++
-					wire |= uint64(b&0x7F) << shift
-					if b < 0x80 {
-						break
-					}
-				}
-				fieldNum := int32(wire >> 3)
-				if fieldNum == 1 {
-					for shift := uint(0); ; shift += 7 {
-						if shift >= 64 {
-							return ErrIntOverflowMetapb
-						}
-						if iNdEx >= l {
-							return io.ErrUnexpectedEOF
-						}
-						b := dAtA[iNdEx]
-						iNdEx++
-						mapkey |= uint64(b&0x7F) << shift
-						if b < 0x80 {
-							break
-						}
-					}
-				} else if fieldNum == 2 {
-					var mapmsglen int
-					for shift := uint(0); ; shift += 7 {
-						if shift >= 64 {
-							return ErrIntOverflowMetapb
-						}
-						if iNdEx >= l {
-							return io.ErrUnexpectedEOF
-						}
-						b := dAtA[iNdEx]
-						iNdEx++
-						mapmsglen |= int(b&0x7F) << shift
-						if b < 0x80 {
-							break
-						}
-					}
-					if mapmsglen < 0 {
-						return ErrInvalidLengthMetapb
-					}
-					postmsgIndex := iNdEx + mapmsglen
-					if postmsgIndex < 0 {
-						return ErrInvalidLengthMetapb
-					}
-					if postmsgIndex > l {
-						return io.ErrUnexpectedEOF
-					}
-					mapvalue = &ShardPool{}
-					if err := mapvalue.Unmarshal(dAtA[iNdEx:postmsgIndex]); err != nil {
-						return err
-					}
-					iNdEx = postmsgIndex
-				} else {
-					iNdEx = entryPreIndex
-					skippy, err := skipMetapb(dAtA[iNdEx:])
-					if err != nil {
-						return err
-					}
-					if skippy < 0 {
-						return ErrInvalidLengthMetapb
-					}
-					if (iNdEx + skippy) > postIndex {
-						return io.ErrUnexpectedEOF
-					}
-					iNdEx += skippy
-				}
+			m.Purpose = append(m.Purpose[:0], dAtA[iNdEx:postIndex]...)
+			if m.Purpose == nil {
+				m.Purpose = []byte{}
 			}
-			m.Pools[mapkey] = mapvalue
 			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
@@ -10495,7 +15629,7 @@ func (m *ShardsPool) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *ShardPool) Unmarshal(dAtA []byte) error {
+func (m *ShardsPoolCmd) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -10518,17 +15652,17 @@ func (m *ShardPool) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: ShardPool: wiretype end group for non-group")
+			return fmt.Errorf("proto: ShardsPoolCmd: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: ShardPool: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: ShardsPoolCmd: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Capacity", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Type", wireType)
 			}
-			m.Capacity = 0
+			m.Type = 0
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowMetapb
@@ -10538,16 +15672,16 @@ func (m *ShardPool) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				m.Capacity |= uint64(b&0x7F) << shift
+				m.Type |= ShardsPoolCmdType(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
 		case 2:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field RangePrefix", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Create", wireType)
 			}
-			var byteLen int
+			var msglen int
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowMetapb
@@ -10557,29 +15691,31 @@ func (m *ShardPool) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				byteLen |= int(b&0x7F) << shift
+				msglen |= int(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			if byteLen < 0 {
+			if msglen < 0 {
 				return ErrInvalidLengthMetapb
 			}
-			postIndex := iNdEx + byteLen
+			postIndex := iNdEx + msglen
 			if postIndex < 0 {
 				return ErrInvalidLengthMetapb
 			}
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.RangePrefix = append(m.RangePrefix[:0], dAtA[iNdEx:postIndex]...)
-			if m.RangePrefix == nil {
-				m.RangePrefix = []byte{}
+			if m.Create == nil {
+				m.Create = &ShardsPoolCreateCmd{}
+			}
+			if err := m.Create.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
 			}
 			iNdEx = postIndex
 		case 3:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field AllocatedShards", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Alloc", wireType)
 			}
 			var msglen int
 			for shift := uint(0); ; shift += 7 {
@@ -10606,49 +15742,67 @@ func (m *ShardPool) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.AllocatedShards = append(m.AllocatedShards, &AllocatedShard{})
-			if err := m.AllocatedShards[len(m.AllocatedShards)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+			if m.Alloc == nil {
+				m.Alloc = &ShardsPoolAllocCmd{}
+			}
+			if err := m.Alloc.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
 				return err
 			}
 			iNdEx = postIndex
-		case 4:
-			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Seq", wireType)
+		default:
+			iNdEx = preIndex
+			skippy, err := skipMetapb(dAtA[iNdEx:])
+			if err != nil {
+				return err
 			}
-			m.Seq = 0
-			for shift := uint(0); ; shift += 7 {
-				if shift >= 64 {
-					return ErrIntOverflowMetapb
-				}
-				if iNdEx >= l {
-					return io.ErrUnexpectedEOF
-				}
-				b := dAtA[iNdEx]
-				iNdEx++
-				m.Seq |= uint64(b&0x7F) << shift
-				if b < 0x80 {
-					break
-				}
+			if skippy < 0 {
+				return ErrInvalidLengthMetapb
 			}
-		case 5:
-			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field AllocatedOffset", wireType)
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthMetapb
 			}
-			m.AllocatedOffset = 0
-			for shift := uint(0); ; shift += 7 {
-				if shift >= 64 {
-					return ErrIntOverflowMetapb
-				}
-				if iNdEx >= l {
-					return io.ErrUnexpectedEOF
-				}
-				b := dAtA[iNdEx]
-				iNdEx++
-				m.AllocatedOffset |= uint64(b&0x7F) << shift
-				if b < 0x80 {
-					break
-				}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *ShardsPoolCreateCmd) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowMetapb
 			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: ShardsPoolCreateCmd: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: ShardsPoolCreateCmd: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
 		default:
 			iNdEx = preIndex
 			skippy, err := skipMetapb(dAtA[iNdEx:])
@@ -10674,7 +15828,7 @@ func (m *ShardPool) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *AllocatedShard) Unmarshal(dAtA []byte) error {
+func (m *ShardsPoolAllocCmd) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -10697,17 +15851,17 @@ func (m *AllocatedShard) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: AllocatedShard: wiretype end group for non-group")
+			return fmt.Errorf("proto: ShardsPoolAllocCmd: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: AllocatedShard: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: ShardsPoolAllocCmd: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field ShardID", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Group", wireType)
 			}
-			m.ShardID = 0
+			m.Group = 0
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowMetapb
@@ -10717,31 +15871,12 @@ func (m *AllocatedShard) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				m.ShardID |= uint64(b&0x7F) << shift
+				m.Group |= uint64(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
 		case 2:
-			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field AllocatedAt", wireType)
-			}
-			m.AllocatedAt = 0
-			for shift := uint(0); ; shift += 7 {
-				if shift >= 64 {
-					return ErrIntOverflowMetapb
-				}
-				if iNdEx >= l {
-					return io.ErrUnexpectedEOF
-				}
-				b := dAtA[iNdEx]
-				iNdEx++
-				m.AllocatedAt |= uint64(b&0x7F) << shift
-				if b < 0x80 {
-					break
-				}
-			}
-		case 3:
 			if wireType != 2 {
 				return fmt.Errorf("proto: wrong wireType = %d for field Purpose", wireType)
 			}
@@ -10800,7 +15935,7 @@ func (m *AllocatedShard) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *ShardsPoolCmd) Unmarshal(dAtA []byte) error {
+func (m *SnapshotInfo) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -10823,17 +15958,17 @@ func (m *ShardsPoolCmd) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: ShardsPoolCmd: wiretype end group for non-group")
+			return fmt.Errorf("proto: SnapshotInfo: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: ShardsPoolCmd: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: SnapshotInfo: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Type", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Extra", wireType)
 			}
-			m.Type = 0
+			m.Extra = 0
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowMetapb
@@ -10843,16 +15978,16 @@ func (m *ShardsPoolCmd) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				m.Type |= ShardsPoolCmdType(b&0x7F) << shift
+				m.Extra |= uint64(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
 		case 2:
-			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Create", wireType)
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Dummy", wireType)
 			}
-			var msglen int
+			var v int
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowMetapb
@@ -10862,33 +15997,17 @@ func (m *ShardsPoolCmd) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				msglen |= int(b&0x7F) << shift
+				v |= int(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			if msglen < 0 {
-				return ErrInvalidLengthMetapb
-			}
-			postIndex := iNdEx + msglen
-			if postIndex < 0 {
-				return ErrInvalidLengthMetapb
-			}
-			if postIndex > l {
-				return io.ErrUnexpectedEOF
-			}
-			if m.Create == nil {
-				m.Create = &ShardsPoolCreateCmd{}
-			}
-			if err := m.Create.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
-				return err
-			}
-			iNdEx = postIndex
+			m.Dummy = bool(v != 0)
 		case 3:
-			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Alloc", wireType)
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field DeltaBase", wireType)
 			}
-			var msglen int
+			m.DeltaBase = 0
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowMetapb
@@ -10898,82 +16017,51 @@ func (m *ShardsPoolCmd) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				msglen |= int(b&0x7F) << shift
+				m.DeltaBase |= uint64(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			if msglen < 0 {
-				return ErrInvalidLengthMetapb
-			}
-			postIndex := iNdEx + msglen
-			if postIndex < 0 {
-				return ErrInvalidLengthMetapb
-			}
-			if postIndex > l {
-				return io.ErrUnexpectedEOF
-			}
-			if m.Alloc == nil {
-				m.Alloc = &ShardsPoolAllocCmd{}
-			}
-			if err := m.Alloc.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
-				return err
-			}
-			iNdEx = postIndex
-		default:
-			iNdEx = preIndex
-			skippy, err := skipMetapb(dAtA[iNdEx:])
-			if err != nil {
-				return err
-			}
-			if skippy < 0 {
-				return ErrInvalidLengthMetapb
-			}
-			if (iNdEx + skippy) < 0 {
-				return ErrInvalidLengthMetapb
-			}
-			if (iNdEx + skippy) > l {
-				return io.ErrUnexpectedEOF
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Witness", wireType)
 			}
-			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
-			iNdEx += skippy
-		}
-	}
-
-	if iNdEx > l {
-		return io.ErrUnexpectedEOF
-	}
-	return nil
-}
-func (m *ShardsPoolCreateCmd) Unmarshal(dAtA []byte) error {
-	l := len(dAtA)
-	iNdEx := 0
-	for iNdEx < l {
-		preIndex := iNdEx
-		var wire uint64
-		for shift := uint(0); ; shift += 7 {
-			if shift >= 64 {
-				return ErrIntOverflowMetapb
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMetapb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
 			}
-			if iNdEx >= l {
-				return io.ErrUnexpectedEOF
+			m.Witness = bool(v != 0)
+		case 5:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Remote", wireType)
 			}
-			b := dAtA[iNdEx]
-			iNdEx++
-			wire |= uint64(b&0x7F) << shift
-			if b < 0x80 {
-				break
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMetapb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
 			}
-		}
-		fieldNum := int32(wire >> 3)
-		wireType := int(wire & 0x7)
-		if wireType == 4 {
-			return fmt.Errorf("proto: ShardsPoolCreateCmd: wiretype end group for non-group")
-		}
-		if fieldNum <= 0 {
-			return fmt.Errorf("proto: ShardsPoolCreateCmd: illegal tag %d (wire type %d)", fieldNum, wire)
-		}
-		switch fieldNum {
+			m.Remote = bool(v != 0)
 		default:
 			iNdEx = preIndex
 			skippy, err := skipMetapb(dAtA[iNdEx:])
@@ -10999,7 +16087,7 @@ func (m *ShardsPoolCreateCmd) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *ShardsPoolAllocCmd) Unmarshal(dAtA []byte) error {
+func (m *SnapshotManifest) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -11022,17 +16110,17 @@ func (m *ShardsPoolAllocCmd) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: ShardsPoolAllocCmd: wiretype end group for non-group")
+			return fmt.Errorf("proto: SnapshotManifest: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: ShardsPoolAllocCmd: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: SnapshotManifest: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
-			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Group", wireType)
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Prefix", wireType)
 			}
-			m.Group = 0
+			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowMetapb
@@ -11042,16 +16130,29 @@ func (m *ShardsPoolAllocCmd) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				m.Group |= uint64(b&0x7F) << shift
+				stringLen |= uint64(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthMetapb
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthMetapb
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Prefix = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
 		case 2:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Purpose", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Files", wireType)
 			}
-			var byteLen int
+			var msglen int
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowMetapb
@@ -11061,24 +16162,24 @@ func (m *ShardsPoolAllocCmd) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				byteLen |= int(b&0x7F) << shift
+				msglen |= int(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			if byteLen < 0 {
+			if msglen < 0 {
 				return ErrInvalidLengthMetapb
 			}
-			postIndex := iNdEx + byteLen
+			postIndex := iNdEx + msglen
 			if postIndex < 0 {
 				return ErrInvalidLengthMetapb
 			}
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Purpose = append(m.Purpose[:0], dAtA[iNdEx:postIndex]...)
-			if m.Purpose == nil {
-				m.Purpose = []byte{}
+			m.Files = append(m.Files, SnapshotFile{})
+			if err := m.Files[len(m.Files)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
 			}
 			iNdEx = postIndex
 		default:
@@ -11106,7 +16207,7 @@ func (m *ShardsPoolAllocCmd) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *SnapshotInfo) Unmarshal(dAtA []byte) error {
+func (m *SnapshotFile) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -11129,17 +16230,17 @@ func (m *SnapshotInfo) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: SnapshotInfo: wiretype end group for non-group")
+			return fmt.Errorf("proto: SnapshotFile: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: SnapshotInfo: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: SnapshotFile: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
-			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Extra", wireType)
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Path", wireType)
 			}
-			m.Extra = 0
+			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowMetapb
@@ -11149,16 +16250,29 @@ func (m *SnapshotInfo) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				m.Extra |= uint64(b&0x7F) << shift
+				stringLen |= uint64(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthMetapb
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthMetapb
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Path = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
 		case 2:
 			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Dummy", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field FileSize", wireType)
 			}
-			var v int
+			m.FileSize = 0
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowMetapb
@@ -11168,12 +16282,11 @@ func (m *SnapshotInfo) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				v |= int(b&0x7F) << shift
+				m.FileSize |= uint64(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			m.Dummy = bool(v != 0)
 		default:
 			iNdEx = preIndex
 			skippy, err := skipMetapb(dAtA[iNdEx:])
@@ -4,6 +4,10 @@ import (
 	"github.com/fagongzi/util/protoc"
 )
 
+// MinCustomAdminCmdType the min admin cmd type of the custom admin commands,
+// the smaller types are reserved for the predefined admin commands.
+const MinCustomAdminCmdType AdminCmdType = 1024
+
 // IsAdmin returns true if has a admin request
 func (m *RequestBatch) IsAdmin() bool {
 	return len(m.Requests) == 1 && m.Requests[0].Type == Admin
@@ -26,6 +30,13 @@ func (m *RequestBatch) GetConfigChangeRequest() ConfigChangeRequest {
 	return req
 }
 
+// GetConfigChangeV2Request return ConfigChangeV2Request request
+func (m *RequestBatch) GetConfigChangeV2Request() ConfigChangeV2Request {
+	var req ConfigChangeV2Request
+	protoc.MustUnmarshal(&req, m.GetAdminRequest().Cmd)
+	return req
+}
+
 // GetCompactLogRequest return CompactLogRequest request
 func (m *RequestBatch) GetCompactLogRequest() CompactLogRequest {
 	var req CompactLogRequest
@@ -61,6 +72,76 @@ func (m *RequestBatch) GetUpdateLabelsRequest() UpdateLabelsRequest {
 	return req
 }
 
+// GetUpdateDurabilityPolicyRequest return UpdateDurabilityPolicyRequest request
+func (m *RequestBatch) GetUpdateDurabilityPolicyRequest() UpdateDurabilityPolicyRequest {
+	var req UpdateDurabilityPolicyRequest
+	protoc.MustUnmarshal(&req, m.GetAdminRequest().Cmd)
+	return req
+}
+
+// GetCreateReadSnapshotRequest return CreateReadSnapshotRequest request
+func (m *RequestBatch) GetCreateReadSnapshotRequest() CreateReadSnapshotRequest {
+	var req CreateReadSnapshotRequest
+	protoc.MustUnmarshal(&req, m.GetAdminRequest().Cmd)
+	return req
+}
+
+// GetReleaseReadSnapshotRequest return ReleaseReadSnapshotRequest request
+func (m *RequestBatch) GetReleaseReadSnapshotRequest() ReleaseReadSnapshotRequest {
+	var req ReleaseReadSnapshotRequest
+	protoc.MustUnmarshal(&req, m.GetAdminRequest().Cmd)
+	return req
+}
+
+// GetBarrierRequest return BarrierRequest request
+func (m *RequestBatch) GetBarrierRequest() BarrierRequest {
+	var req BarrierRequest
+	protoc.MustUnmarshal(&req, m.GetAdminRequest().Cmd)
+	return req
+}
+
+// GetPrepareMergeRequest return PrepareMergeRequest request
+func (m *RequestBatch) GetPrepareMergeRequest() PrepareMergeRequest {
+	var req PrepareMergeRequest
+	protoc.MustUnmarshal(&req, m.GetAdminRequest().Cmd)
+	return req
+}
+
+// GetMergeShardRequest return MergeShardRequest request
+func (m *RequestBatch) GetMergeShardRequest() MergeShardRequest {
+	var req MergeShardRequest
+	protoc.MustUnmarshal(&req, m.GetAdminRequest().Cmd)
+	return req
+}
+
+// GetRollbackMergeRequest return RollbackMergeRequest request
+func (m *RequestBatch) GetRollbackMergeRequest() RollbackMergeRequest {
+	var req RollbackMergeRequest
+	protoc.MustUnmarshal(&req, m.GetAdminRequest().Cmd)
+	return req
+}
+
+// GetAbortMergeRequest return AbortMergeRequest request
+func (m *RequestBatch) GetAbortMergeRequest() AbortMergeRequest {
+	var req AbortMergeRequest
+	protoc.MustUnmarshal(&req, m.GetAdminRequest().Cmd)
+	return req
+}
+
+// GetPurgeExpiredDataRequest return PurgeExpiredDataRequest request
+func (m *RequestBatch) GetPurgeExpiredDataRequest() PurgeExpiredDataRequest {
+	var req PurgeExpiredDataRequest
+	protoc.MustUnmarshal(&req, m.GetAdminRequest().Cmd)
+	return req
+}
+
+// GetBecomeWitnessRequest return BecomeWitnessRequest request
+func (m *RequestBatch) GetBecomeWitnessRequest() BecomeWitnessRequest {
+	var req BecomeWitnessRequest
+	protoc.MustUnmarshal(&req, m.GetAdminRequest().Cmd)
+	return req
+}
+
 // IsEmpty returns true if is a empty batch
 func (m *RequestBatch) IsEmpty() bool {
 	return len(m.Header.ID) == 0
@@ -112,6 +193,13 @@ func (m *ResponseBatch) GetBatchSplitResponse() BatchSplitResponse {
 	return req
 }
 
+// GetMergeShardResponse return MergeShardResponse Response
+func (m *ResponseBatch) GetMergeShardResponse() MergeShardResponse {
+	var req MergeShardResponse
+	protoc.MustUnmarshal(&req, m.GetAdminResponse().Value)
+	return req
+}
+
 // GetUpdateMetadataResponse return UpdateMetadataResponse Response
 func (m *ResponseBatch) GetUpdateMetadataResponse() UpdateMetadataResponse {
 	var req UpdateMetadataResponse
@@ -122,11 +122,26 @@ func (m TxnRequest) IsRollback() bool {
 	return m.Operation.Op == uint32(InternalTxnOp_Rollback)
 }
 
+// IsAsyncCommit is commit request with async commit on
+func (m TxnRequest) IsAsyncCommit() bool {
+	return m.IsCommit() && m.Options.AsyncCommit
+}
+
 // IsWaitConsensus is wait consensus request
 func (m TxnRequest) IsWaitConsensus() bool {
 	return m.Operation.Op < uint32(InternalTxnOp_Reserved)
 }
 
+// IsPessimisticLock is pessimistic lock request
+func (m TxnRequest) IsPessimisticLock() bool {
+	return m.Operation.Op == uint32(InternalTxnOp_PessimisticLock)
+}
+
+// IsRenewLock is renew lock request
+func (m TxnRequest) IsRenewLock() bool {
+	return m.Operation.Op == uint32(InternalTxnOp_RenewLock)
+}
+
 // HasReadImpacted returns the keys in the keyset will be read
 func (m TxnRequest) HasReadImpacted() bool {
 	return !m.IsInternal() && (m.Operation.ImpactedType == ImpactedType_ReadImpacted ||
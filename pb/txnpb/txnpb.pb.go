@@ -109,6 +109,13 @@ const (
 	InternalTxnOp_Rollback InternalTxnOp = 2
 	// WaitConsensus waiting for consensus to be completed
 	InternalTxnOp_WaitConsensus InternalTxnOp = 3
+	// PessimisticLock lock the impacted keys before read and write in the pessimistic
+	// mode, the request waits in the lock wait queue of the key if the key is locked
+	// by another transaction.
+	InternalTxnOp_PessimisticLock InternalTxnOp = 4
+	// RenewLock renew the TTL of the pessimistic locks of the impacted keys, sent with
+	// the heartbeat.
+	InternalTxnOp_RenewLock InternalTxnOp = 5
 	// Reserved txn reserved operation value, all custom transaction
 	// read and write operation type can not use the value below the
 	// reserved value.
@@ -120,15 +127,19 @@ var InternalTxnOp_name = map[int32]string{
 	1:    "Commit",
 	2:    "Rollback",
 	3:    "WaitConsensus",
+	4:    "PessimisticLock",
+	5:    "RenewLock",
 	1000: "Reserved",
 }
 
 var InternalTxnOp_value = map[string]int32{
-	"Heartbeat":     0,
-	"Commit":        1,
-	"Rollback":      2,
-	"WaitConsensus": 3,
-	"Reserved":      1000,
+	"Heartbeat":       0,
+	"Commit":          1,
+	"Rollback":        2,
+	"WaitConsensus":   3,
+	"PessimisticLock": 4,
+	"RenewLock":       5,
+	"Reserved":        1000,
 }
 
 func (x InternalTxnOp) String() string {
@@ -438,7 +449,11 @@ type TxnOpMeta struct {
 	CompletedWrites map[uint64]KeySet `protobuf:"bytes,4,rep,name=completedWrites,proto3" json:"completedWrites" protobuf_key:"varint,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
 	// InfightWrites record the current transaction has not completed the consensus write
 	// operation. Only when it is a Commit or Rollback transaction, it will be attached.
-	InfightWrites        map[uint64]KeySet `protobuf:"bytes,5,rep,name=infightWrites,proto3" json:"infightWrites" protobuf_key:"varint,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	InfightWrites map[uint64]KeySet `protobuf:"bytes,5,rep,name=infightWrites,proto3" json:"infightWrites" protobuf_key:"varint,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	// LockedKeys record the keys locked by the current transaction in the pessimistic
+	// mode. Only when it is a Commit or Rollback transaction, it will be attached to
+	// release the locks.
+	LockedKeys           map[uint64]KeySet `protobuf:"bytes,6,rep,name=lockedKeys,proto3" json:"lockedKeys" protobuf_key:"varint,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
 	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
 	XXX_unrecognized     []byte            `json:"-"`
 	XXX_sizecache        int32             `json:"-"`
@@ -498,6 +513,13 @@ func (m *TxnOpMeta) GetInfightWrites() map[uint64]KeySet {
 	return nil
 }
 
+func (m *TxnOpMeta) GetLockedKeys() map[uint64]KeySet {
+	if m != nil {
+		return m.LockedKeys
+	}
+	return nil
+}
+
 // KeyRange description of the set of Keys in the range [start, end)
 type KeyRange struct {
 	// Start start key, include
@@ -846,10 +868,14 @@ type TxnBatchResponseHeader struct {
 	// access to TxnRecord will return.
 	Status TxnStatus `protobuf:"varint,2,opt,name=status,proto3,enum=txnpb.TxnStatus" json:"status,omitempty"`
 	// TxnError txn error
-	Error                *TxnError `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}  `json:"-"`
-	XXX_unrecognized     []byte    `json:"-"`
-	XXX_sizecache        int32     `json:"-"`
+	Error *TxnError `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	// Timestamp the clock of the server when the response created, the client updates
+	// its hybrid logical clock by it, so the timestamps of the causally related events
+	// are increasing across the nodes. Zero means the server does not report its clock.
+	Timestamp            uint64   `protobuf:"varint,4,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
 func (m *TxnBatchResponseHeader) Reset()         { *m = TxnBatchResponseHeader{} }
@@ -906,6 +932,13 @@ func (m *TxnBatchResponseHeader) GetError() *TxnError {
 	return nil
 }
 
+func (m *TxnBatchResponseHeader) GetTimestamp() uint64 {
+	if m != nil {
+		return m.Timestamp
+	}
+	return 0
+}
+
 // TxnBatchResponse the response of TxnBatchRequest returns the modified transaction
 // metadata in the header of the response, and the transaction coordinator needs to
 // update the transaction metadata in memory, such as the read and write timestamps
@@ -1026,7 +1059,11 @@ func (m *TxnRequest) GetOptions() RequestOptions {
 // TxnResponse is TxnOperation response
 type TxnResponse struct {
 	// Data is returned by `HandleRead` or `HandleWrite`
-	Data                 []byte   `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+	Data []byte `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+	// MaxReadTimestamp the maximum timestamp at which the impacted key of the
+	// WaitConsensus request has been read, found in the TSCache of the Shard. Only
+	// returned in async commit, used to resolve the commit timestamp.
+	MaxReadTimestamp     uint64   `protobuf:"varint,2,opt,name=maxReadTimestamp,proto3" json:"maxReadTimestamp,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -1072,15 +1109,31 @@ func (m *TxnResponse) GetData() []byte {
 	return nil
 }
 
+func (m *TxnResponse) GetMaxReadTimestamp() uint64 {
+	if m != nil {
+		return m.MaxReadTimestamp
+	}
+	return 0
+}
+
 // RequestOptions request options
 type RequestOptions struct {
 	// CreateTxnRecord the current request requires the creation of a TxnRecord
 	CreateTxnRecord bool `protobuf:"varint,1,opt,name=createTxnRecord,proto3" json:"createTxnRecord,omitempty"`
 	// AasynchronousConsensus current request with asynchronous consensus on
-	AsynchronousConsensus bool     `protobuf:"varint,2,opt,name=asynchronousConsensus,proto3" json:"asynchronousConsensus,omitempty"`
-	XXX_NoUnkeyedLiteral  struct{} `json:"-"`
-	XXX_unrecognized      []byte   `json:"-"`
-	XXX_sizecache         int32    `json:"-"`
+	AsynchronousConsensus bool `protobuf:"varint,2,opt,name=asynchronousConsensus,proto3" json:"asynchronousConsensus,omitempty"`
+	// AsyncCommit the commit request with async commit on. TxnManager sets the status
+	// of the TxnRecord to `Staging` with all the written keys, and the commit request
+	// is sent in parallel with the WaitConsensus requests of all the written keys. The
+	// transaction is committed once all the requests succeed, and the transaction
+	// coordinator resolves the commit timestamp.
+	AsyncCommit bool `protobuf:"varint,3,opt,name=asyncCommit,proto3" json:"asyncCommit,omitempty"`
+	// LockWaitTimeout the milliseconds the PessimisticLock request waits in the lock wait
+	// queue, 0 means no wait.
+	LockWaitTimeout      uint64   `protobuf:"varint,4,opt,name=lockWaitTimeout,proto3" json:"lockWaitTimeout,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
 func (m *RequestOptions) Reset()         { *m = RequestOptions{} }
@@ -1130,12 +1183,28 @@ func (m *RequestOptions) GetAsynchronousConsensus() bool {
 	return false
 }
 
+func (m *RequestOptions) GetAsyncCommit() bool {
+	if m != nil {
+		return m.AsyncCommit
+	}
+	return false
+}
+
+func (m *RequestOptions) GetLockWaitTimeout() uint64 {
+	if m != nil {
+		return m.LockWaitTimeout
+	}
+	return 0
+}
+
 // TxnError txn error, Special errors encountered in transaction operations,
 // which require special handling on the client or server side.
 type TxnError struct {
 	ConflictWithCommittedError *ConflictWithCommittedError `protobuf:"bytes,1,opt,name=conflictWithCommittedError,proto3" json:"conflictWithCommittedError,omitempty"`
 	UncertaintyError           *UncertaintyError           `protobuf:"bytes,2,opt,name=uncertaintyError,proto3" json:"uncertaintyError,omitempty"`
 	AbortedError               *AbortedError               `protobuf:"bytes,3,opt,name=abortedError,proto3" json:"abortedError,omitempty"`
+	DeadlockError              *DeadlockError              `protobuf:"bytes,4,opt,name=deadlockError,proto3" json:"deadlockError,omitempty"`
+	LockWaitTimeoutError       *LockWaitTimeoutError       `protobuf:"bytes,5,opt,name=lockWaitTimeoutError,proto3" json:"lockWaitTimeoutError,omitempty"`
 	XXX_NoUnkeyedLiteral       struct{}                    `json:"-"`
 	XXX_unrecognized           []byte                      `json:"-"`
 	XXX_sizecache              int32                       `json:"-"`
@@ -1195,6 +1264,20 @@ func (m *TxnError) GetAbortedError() *AbortedError {
 	return nil
 }
 
+func (m *TxnError) GetDeadlockError() *DeadlockError {
+	if m != nil {
+		return m.DeadlockError
+	}
+	return nil
+}
+
+func (m *TxnError) GetLockWaitTimeoutError() *LockWaitTimeoutError {
+	if m != nil {
+		return m.LockWaitTimeoutError
+	}
+	return nil
+}
+
 // ConflictWithCommittedError T.ReadTS < C.CommitTS < T.CommitTS, W/W conflict,
 // txn.ReadTS and txn.WriteTS => committedTimestamp+1, and check if the read data
 // has been written in the range [lastForwardTimestamp, committedTimestamp+1].
@@ -1337,6 +1420,173 @@ func (m *AbortedError) XXX_DiscardUnknown() {
 
 var xxx_messageInfo_AbortedError proto.InternalMessageInfo
 
+// DeadlockError the PessimisticLock request waiting for the lock causes a deadlock,
+// the transaction needs to rollback.
+type DeadlockError struct {
+	// Key the key the transaction is waiting for
+	Key                  []byte   `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *DeadlockError) Reset()         { *m = DeadlockError{} }
+func (m *DeadlockError) String() string { return proto.CompactTextString(m) }
+func (*DeadlockError) ProtoMessage()    {}
+func (*DeadlockError) Descriptor() ([]byte, []int) {
+	return fileDescriptor_4cec01c879ff9f20, []int{17}
+}
+func (m *DeadlockError) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *DeadlockError) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_DeadlockError.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalTo(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *DeadlockError) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DeadlockError.Merge(m, src)
+}
+func (m *DeadlockError) XXX_Size() int {
+	return m.Size()
+}
+func (m *DeadlockError) XXX_DiscardUnknown() {
+	xxx_messageInfo_DeadlockError.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DeadlockError proto.InternalMessageInfo
+
+func (m *DeadlockError) GetKey() []byte {
+	if m != nil {
+		return m.Key
+	}
+	return nil
+}
+
+// LockWaitTimeoutError the PessimisticLock request waits for the lock timeout
+type LockWaitTimeoutError struct {
+	// Key the key the transaction is waiting for
+	Key                  []byte   `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *LockWaitTimeoutError) Reset()         { *m = LockWaitTimeoutError{} }
+func (m *LockWaitTimeoutError) String() string { return proto.CompactTextString(m) }
+func (*LockWaitTimeoutError) ProtoMessage()    {}
+func (*LockWaitTimeoutError) Descriptor() ([]byte, []int) {
+	return fileDescriptor_4cec01c879ff9f20, []int{18}
+}
+func (m *LockWaitTimeoutError) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *LockWaitTimeoutError) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_LockWaitTimeoutError.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalTo(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *LockWaitTimeoutError) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_LockWaitTimeoutError.Merge(m, src)
+}
+func (m *LockWaitTimeoutError) XXX_Size() int {
+	return m.Size()
+}
+func (m *LockWaitTimeoutError) XXX_DiscardUnknown() {
+	xxx_messageInfo_LockWaitTimeoutError.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_LockWaitTimeoutError proto.InternalMessageInfo
+
+func (m *LockWaitTimeoutError) GetKey() []byte {
+	if m != nil {
+		return m.Key
+	}
+	return nil
+}
+
+// WaitForEdge an edge of the wait-for graph, used to detect the deadlock of the
+// transactions waiting for the pessimistic locks.
+type WaitForEdge struct {
+	// TxnID the waiting transaction
+	TxnID []byte `protobuf:"bytes,1,opt,name=txnID,proto3" json:"txnID,omitempty"`
+	// WaitForTxnID the transaction holding the lock
+	WaitForTxnID []byte `protobuf:"bytes,2,opt,name=waitForTxnID,proto3" json:"waitForTxnID,omitempty"`
+	// Key the locked key
+	Key                  []byte   `protobuf:"bytes,3,opt,name=key,proto3" json:"key,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *WaitForEdge) Reset()         { *m = WaitForEdge{} }
+func (m *WaitForEdge) String() string { return proto.CompactTextString(m) }
+func (*WaitForEdge) ProtoMessage()    {}
+func (*WaitForEdge) Descriptor() ([]byte, []int) {
+	return fileDescriptor_4cec01c879ff9f20, []int{19}
+}
+func (m *WaitForEdge) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *WaitForEdge) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_WaitForEdge.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalTo(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *WaitForEdge) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_WaitForEdge.Merge(m, src)
+}
+func (m *WaitForEdge) XXX_Size() int {
+	return m.Size()
+}
+func (m *WaitForEdge) XXX_DiscardUnknown() {
+	xxx_messageInfo_WaitForEdge.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_WaitForEdge proto.InternalMessageInfo
+
+func (m *WaitForEdge) GetTxnID() []byte {
+	if m != nil {
+		return m.TxnID
+	}
+	return nil
+}
+
+func (m *WaitForEdge) GetWaitForTxnID() []byte {
+	if m != nil {
+		return m.WaitForTxnID
+	}
+	return nil
+}
+
+func (m *WaitForEdge) GetKey() []byte {
+	if m != nil {
+		return m.Key
+	}
+	return nil
+}
+
 func init() {
 	proto.RegisterEnum("txnpb.IsolationLevel", IsolationLevel_name, IsolationLevel_value)
 	proto.RegisterEnum("txnpb.TxnStatus", TxnStatus_name, TxnStatus_value)
@@ -1350,6 +1600,7 @@ func init() {
 	proto.RegisterType((*TxnOpMeta)(nil), "txnpb.TxnOpMeta")
 	proto.RegisterMapType((map[uint64]KeySet)(nil), "txnpb.TxnOpMeta.CompletedWritesEntry")
 	proto.RegisterMapType((map[uint64]KeySet)(nil), "txnpb.TxnOpMeta.InfightWritesEntry")
+	proto.RegisterMapType((map[uint64]KeySet)(nil), "txnpb.TxnOpMeta.LockedKeysEntry")
 	proto.RegisterType((*KeyRange)(nil), "txnpb.KeyRange")
 	proto.RegisterType((*KeySet)(nil), "txnpb.KeySet")
 	proto.RegisterType((*TxnOperation)(nil), "txnpb.TxnOperation")
@@ -1364,92 +1615,107 @@ func init() {
 	proto.RegisterType((*ConflictWithCommittedError)(nil), "txnpb.ConflictWithCommittedError")
 	proto.RegisterType((*UncertaintyError)(nil), "txnpb.UncertaintyError")
 	proto.RegisterType((*AbortedError)(nil), "txnpb.AbortedError")
+	proto.RegisterType((*DeadlockError)(nil), "txnpb.DeadlockError")
+	proto.RegisterType((*LockWaitTimeoutError)(nil), "txnpb.LockWaitTimeoutError")
+	proto.RegisterType((*WaitForEdge)(nil), "txnpb.WaitForEdge")
 }
 
 func init() { proto.RegisterFile("txnpb.proto", fileDescriptor_4cec01c879ff9f20) }
 
 var fileDescriptor_4cec01c879ff9f20 = []byte{
-	// 1274 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xdc, 0x57, 0xcf, 0x6f, 0x1b, 0xc5,
-	0x17, 0xcf, 0xfa, 0xe7, 0xfa, 0xc5, 0x76, 0x36, 0xd3, 0xa6, 0x5f, 0x7f, 0xad, 0x92, 0xba, 0x5b,
-	0x5a, 0x99, 0x88, 0xa6, 0xc8, 0x85, 0x16, 0x15, 0x21, 0xd1, 0x84, 0x8a, 0x86, 0x80, 0x02, 0x93,
-	0x54, 0x45, 0xdc, 0xc6, 0xbb, 0x53, 0x7b, 0xd5, 0xf5, 0xcc, 0x32, 0x3b, 0x2e, 0x31, 0xe2, 0xc4,
-	0x85, 0x33, 0x7f, 0x05, 0xff, 0x4a, 0x8f, 0xbd, 0x23, 0x55, 0x25, 0x27, 0x24, 0xfe, 0x01, 0x8e,
-	0x68, 0x66, 0x67, 0xbd, 0x3f, 0x92, 0xa2, 0x08, 0x38, 0x71, 0x9b, 0xf7, 0xde, 0x67, 0x3e, 0xef,
-	0xf9, 0x7d, 0x66, 0xde, 0x8e, 0x61, 0x55, 0x1e, 0xb3, 0x68, 0xbc, 0x1d, 0x09, 0x2e, 0x39, 0xaa,
-	0x6b, 0xa3, 0x7f, 0x73, 0x12, 0xc8, 0xe9, 0x7c, 0xbc, 0xed, 0xf1, 0xd9, 0xad, 0x09, 0x9f, 0xf0,
-	0x5b, 0x3a, 0x3a, 0x9e, 0x3f, 0xd1, 0x96, 0x36, 0xf4, 0x2a, 0xd9, 0xe5, 0xfe, 0x51, 0x81, 0xe6,
-	0xd1, 0x31, 0xfb, 0x9c, 0x4a, 0x82, 0x2e, 0x41, 0x25, 0xf0, 0x7b, 0xd6, 0xc0, 0x1a, 0xb6, 0x77,
-	0x1a, 0x27, 0x2f, 0xaf, 0x54, 0xf6, 0x3e, 0xc6, 0x95, 0xc0, 0x47, 0x08, 0x6a, 0x8c, 0xcc, 0x68,
-	0xaf, 0x32, 0xb0, 0x86, 0x2d, 0xac, 0xd7, 0xe8, 0x43, 0xe8, 0x06, 0x31, 0x0f, 0x89, 0x0c, 0x38,
-	0xfb, 0x8c, 0x3e, 0xa3, 0x61, 0xaf, 0x3a, 0xb0, 0x86, 0xdd, 0xd1, 0xc6, 0x76, 0x52, 0xd3, 0x5e,
-	0x21, 0x88, 0x4b, 0x60, 0xf4, 0x36, 0xac, 0xcb, 0x63, 0x86, 0xa9, 0xc7, 0x85, 0x8f, 0xf9, 0x5c,
-	0xd2, 0x7d, 0xba, 0xe8, 0xd5, 0x54, 0x66, 0x7c, 0x3a, 0x80, 0xde, 0x81, 0x0b, 0x4b, 0xe7, 0xe1,
-	0x94, 0x08, 0xff, 0x13, 0xc1, 0xe7, 0x51, 0xaf, 0x3e, 0xb0, 0x86, 0x35, 0x7c, 0x56, 0x08, 0x5d,
-	0x84, 0x3a, 0x8d, 0xb8, 0x37, 0xed, 0x35, 0x06, 0xd6, 0xb0, 0x83, 0x13, 0x03, 0xf5, 0xc1, 0x8e,
-	0x44, 0xc0, 0x45, 0x20, 0x17, 0xbd, 0xa6, 0x0e, 0x2c, 0x6d, 0x74, 0x03, 0xba, 0xdf, 0x8a, 0x40,
-	0xd2, 0xa3, 0x60, 0x46, 0x63, 0x49, 0x66, 0x51, 0xcf, 0xd6, 0xf4, 0x25, 0x2f, 0x7a, 0x13, 0x3a,
-	0x82, 0x12, 0x3f, 0x83, 0xb5, 0x34, 0xac, 0xe8, 0x44, 0x2e, 0xb4, 0x67, 0xe4, 0x38, 0x03, 0x81,
-	0x06, 0x15, 0x7c, 0xee, 0x2f, 0x55, 0x68, 0x1d, 0xa5, 0xb5, 0xa3, 0x11, 0x34, 0x65, 0xa2, 0x83,
-	0x56, 0x60, 0x75, 0xd4, 0x35, 0x9d, 0x34, 0xea, 0xec, 0xd8, 0xcf, 0x5f, 0x5e, 0x59, 0x79, 0xf1,
-	0xf2, 0x8a, 0x85, 0x53, 0x20, 0x1a, 0x42, 0x23, 0x96, 0x44, 0xce, 0x63, 0x2d, 0x4d, 0x77, 0xe4,
-	0x64, 0x5b, 0x0e, 0xb5, 0x1f, 0x9b, 0xb8, 0xaa, 0x3a, 0x24, 0xb1, 0x7c, 0x48, 0x89, 0x90, 0x63,
-	0x4a, 0xa4, 0x56, 0xab, 0x86, 0x8b, 0x4e, 0xf4, 0x08, 0xd6, 0x3c, 0x3e, 0x8b, 0x42, 0x2a, 0xa9,
-	0xff, 0x58, 0xfd, 0xec, 0xb8, 0x57, 0x1b, 0x54, 0x87, 0xab, 0xa3, 0xeb, 0x19, 0x71, 0x52, 0xee,
-	0xf6, 0x6e, 0x11, 0xf7, 0x80, 0x49, 0xb1, 0xd8, 0xa9, 0xa9, 0x12, 0x71, 0x99, 0x03, 0x1d, 0x40,
-	0x27, 0x60, 0x4f, 0x82, 0xc9, 0x54, 0x1a, 0xd2, 0xba, 0x26, 0xbd, 0x76, 0x8a, 0x74, 0x2f, 0x8f,
-	0xca, 0x53, 0x16, 0xf7, 0xf7, 0xbf, 0x84, 0x8b, 0x67, 0xe5, 0x47, 0x0e, 0x54, 0x9f, 0xd2, 0x85,
-	0xee, 0x5f, 0x0d, 0xab, 0x25, 0xba, 0x06, 0xf5, 0x67, 0x24, 0x9c, 0x27, 0x67, 0x77, 0x75, 0xd4,
-	0x31, 0x29, 0xf7, 0xe9, 0xe2, 0x90, 0x4a, 0x9c, 0xc4, 0xee, 0x55, 0xde, 0xb7, 0xfa, 0x07, 0x80,
-	0x4e, 0x67, 0xff, 0x07, 0x84, 0xee, 0xcf, 0x89, 0xba, 0x07, 0x91, 0x56, 0xea, 0xef, 0xa8, 0xdb,
-	0x07, 0x3b, 0xa6, 0xdf, 0xcc, 0x29, 0xf3, 0xa8, 0x96, 0xab, 0x83, 0x97, 0xf6, 0xb9, 0x94, 0x4a,
-	0x52, 0xff, 0xcb, 0x4a, 0x19, 0xd2, 0xff, 0x92, 0x52, 0x23, 0xb0, 0xf7, 0xe9, 0x02, 0x13, 0x36,
-	0xa1, 0x6a, 0x6e, 0xc4, 0x92, 0x08, 0x99, 0x4c, 0x41, 0x9c, 0x18, 0x8a, 0x9c, 0x32, 0x5f, 0x13,
-	0xb5, 0xb1, 0x5a, 0xba, 0x33, 0x68, 0x24, 0x44, 0xe8, 0x32, 0xb4, 0x22, 0x1e, 0x30, 0xb9, 0x4f,
-	0x17, 0x71, 0xcf, 0x1a, 0x54, 0x87, 0x6d, 0x9c, 0x39, 0xd0, 0x4d, 0x68, 0x08, 0x45, 0xac, 0x6e,
-	0xa8, 0xea, 0xe4, 0x5a, 0x56, 0x85, 0x4e, 0x68, 0xba, 0x66, 0x40, 0xe8, 0x12, 0x34, 0x62, 0x2e,
-	0x24, 0xf5, 0xb5, 0xe0, 0x36, 0x36, 0x96, 0xfb, 0xca, 0x82, 0xb6, 0x6e, 0x3e, 0x15, 0x7a, 0x88,
-	0xa2, 0x2e, 0x54, 0x78, 0xa4, 0x8b, 0xec, 0xe0, 0x0a, 0x8f, 0x50, 0x0f, 0x9a, 0x11, 0x59, 0x84,
-	0x9c, 0xa4, 0x55, 0xa6, 0x26, 0xba, 0x05, 0x76, 0x30, 0x8b, 0x88, 0x97, 0x92, 0x96, 0x3b, 0x61,
-	0x2a, 0x58, 0x82, 0xd0, 0x5d, 0x68, 0xa7, 0xeb, 0xa3, 0x45, 0x44, 0xf5, 0x54, 0xee, 0x8e, 0x2e,
-	0xa4, 0x73, 0x3d, 0x17, 0xc2, 0x05, 0x20, 0xda, 0x04, 0x88, 0xcb, 0xc3, 0x39, 0xe7, 0x51, 0x9d,
-	0x92, 0xcb, 0x81, 0xd8, 0xd0, 0xe1, 0xcc, 0xe1, 0xfe, 0x60, 0xc1, 0xda, 0xd1, 0x31, 0xdb, 0x21,
-	0xd2, 0x9b, 0x62, 0x75, 0xcc, 0x63, 0x89, 0xee, 0x41, 0x63, 0x4a, 0x89, 0x4f, 0x85, 0xb9, 0x34,
-	0x97, 0xb3, 0x73, 0x98, 0xc7, 0x3d, 0xd4, 0x98, 0xb4, 0x95, 0xc9, 0x0e, 0x74, 0x1b, 0x6c, 0x91,
-	0x84, 0xd3, 0xde, 0xaf, 0xe7, 0xe7, 0x8d, 0x8e, 0xa4, 0xbf, 0x3d, 0x05, 0xba, 0x21, 0x6c, 0x9c,
-	0xc9, 0x8d, 0x86, 0x50, 0x95, 0xc7, 0xcc, 0x94, 0xe1, 0x94, 0xaf, 0x83, 0xe1, 0x51, 0x10, 0xf4,
-	0x16, 0xd4, 0xa4, 0x6a, 0x5b, 0xa5, 0xf0, 0x39, 0xcc, 0x72, 0xea, 0xc6, 0x69, 0x88, 0xfb, 0x93,
-	0x05, 0x97, 0xb2, 0x74, 0x71, 0xc4, 0x59, 0x4c, 0x4d, 0xbe, 0x1b, 0xf9, 0x7c, 0xe5, 0x59, 0x91,
-	0xcb, 0x76, 0xfe, 0x2f, 0xc0, 0x75, 0xa8, 0x53, 0x21, 0xb8, 0x30, 0x87, 0x60, 0x2d, 0x03, 0x3e,
-	0x50, 0x6e, 0x9c, 0x44, 0xdd, 0x1f, 0x2d, 0x70, 0xca, 0x35, 0xa1, 0x0f, 0x4a, 0x3a, 0xbc, 0x71,
-	0x4a, 0x87, 0x7c, 0xf1, 0x25, 0x21, 0xee, 0x40, 0x4b, 0x98, 0x78, 0xaa, 0x04, 0xca, 0x77, 0x25,
-	0x09, 0x99, 0x4d, 0x19, 0xd4, 0xfd, 0x1e, 0x20, 0xeb, 0x1a, 0xba, 0x0b, 0x2d, 0x9e, 0x9e, 0x7e,
-	0x53, 0xc5, 0x85, 0xbc, 0x0c, 0x26, 0x94, 0xd2, 0x2c, 0xb1, 0xe8, 0x3d, 0x68, 0xf2, 0x48, 0xad,
-	0x62, 0x33, 0x08, 0x52, 0x49, 0x0c, 0xf3, 0x41, 0x12, 0x34, 0x1b, 0x53, 0xac, 0x7b, 0x15, 0x56,
-	0x73, 0xd5, 0xa9, 0x27, 0x90, 0x4f, 0xcc, 0xf0, 0x6e, 0x63, 0xbd, 0x76, 0x23, 0xe8, 0x16, 0x39,
-	0xd0, 0x10, 0xd6, 0x3c, 0x41, 0x89, 0xa4, 0xcb, 0x4f, 0x9a, 0xde, 0x60, 0xe3, 0xb2, 0x1b, 0xbd,
-	0x0b, 0x1b, 0x24, 0x5e, 0x30, 0x6f, 0x2a, 0x38, 0xe3, 0xf3, 0x78, 0x57, 0x25, 0x61, 0xb1, 0x91,
-	0xd1, 0xc6, 0x67, 0x07, 0xdd, 0xdf, 0x2d, 0xb0, 0x53, 0xc1, 0x10, 0x81, 0xbe, 0xc7, 0xd9, 0x93,
-	0x30, 0xf0, 0xe4, 0xe3, 0x40, 0x4e, 0x77, 0xf9, 0x6c, 0x16, 0x48, 0x49, 0x7d, 0x1d, 0x35, 0x2d,
-	0xba, 0x6a, 0x7e, 0xeb, 0xee, 0x6b, 0x81, 0xf8, 0x2f, 0x48, 0xd0, 0x2e, 0x38, 0x73, 0xe6, 0x51,
-	0x21, 0x49, 0xc0, 0xe4, 0x22, 0x21, 0x4e, 0x9a, 0xf8, 0x3f, 0x43, 0xfc, 0xa8, 0x14, 0xc6, 0xa7,
-	0x36, 0xa8, 0x79, 0x42, 0xc6, 0x7a, 0x8c, 0x3d, 0xc8, 0x9d, 0xbf, 0x54, 0xbc, 0xfb, 0xb9, 0x10,
-	0x2e, 0x00, 0xdd, 0x8f, 0xa0, 0xff, 0xfa, 0xba, 0xf5, 0x0b, 0x2b, 0x60, 0xd9, 0x0b, 0xcb, 0x32,
-	0x2f, 0xac, 0x9c, 0xcf, 0xbd, 0x03, 0x4e, 0xb9, 0xc0, 0x73, 0xed, 0xeb, 0x42, 0x3b, 0x5f, 0xd7,
-	0xd6, 0x57, 0xd0, 0x2d, 0xbe, 0x67, 0x51, 0x0f, 0x2e, 0x1e, 0x32, 0x12, 0xc5, 0x53, 0x2e, 0x0f,
-	0xa9, 0x08, 0x48, 0x18, 0x7c, 0x47, 0xc6, 0x21, 0x75, 0x56, 0xd0, 0x3a, 0x74, 0x30, 0x25, 0xfe,
-	0xb2, 0x5a, 0xc7, 0x42, 0xff, 0x87, 0x8d, 0x82, 0x4b, 0x19, 0x07, 0x2c, 0x5c, 0x38, 0x95, 0xad,
-	0xfb, 0xfa, 0x91, 0x90, 0x5c, 0x55, 0xb4, 0x0a, 0xcd, 0x2f, 0x28, 0xf3, 0x03, 0x36, 0x71, 0x56,
-	0x94, 0x71, 0x28, 0xc9, 0x44, 0x19, 0x16, 0xea, 0x40, 0x2b, 0x23, 0xac, 0xa8, 0x98, 0xa9, 0xcf,
-	0xa9, 0x6e, 0x7d, 0x0d, 0x9d, 0x3d, 0x26, 0xa9, 0x60, 0x24, 0xd4, 0x37, 0x41, 0x81, 0x97, 0x4f,
-	0x3a, 0x67, 0x05, 0x01, 0x34, 0x92, 0xbd, 0x8e, 0x85, 0xda, 0x60, 0x63, 0x1e, 0x86, 0x63, 0xe2,
-	0x3d, 0x75, 0x2a, 0xaa, 0xd4, 0xc7, 0x24, 0x90, 0xcb, 0xf3, 0xe5, 0x54, 0x51, 0x07, 0x6c, 0x4c,
-	0x63, 0x2a, 0x9e, 0x51, 0xdf, 0xf9, 0xad, 0xb9, 0xf5, 0x29, 0xb4, 0xf3, 0x03, 0x1f, 0x39, 0xd0,
-	0x56, 0xc5, 0xa7, 0xbe, 0xe4, 0xe7, 0xea, 0xcf, 0xf0, 0xd2, 0x65, 0xa1, 0x0d, 0x58, 0x57, 0xa0,
-	0xa2, 0xbb, 0xb2, 0x75, 0x1d, 0xba, 0xc5, 0x29, 0x88, 0x6c, 0xa8, 0x29, 0xa0, 0xb3, 0x82, 0x5a,
-	0x50, 0xd7, 0x70, 0xc7, 0xda, 0x71, 0x5e, 0xfc, 0xba, 0x69, 0x3d, 0x3f, 0xd9, 0xb4, 0x5e, 0x9c,
-	0x6c, 0x5a, 0xaf, 0x4e, 0x36, 0xad, 0x71, 0x43, 0xff, 0x53, 0xb9, 0xfd, 0x67, 0x00, 0x00, 0x00,
-	0xff, 0xff, 0xdd, 0x3b, 0x27, 0xbf, 0xee, 0x0c, 0x00, 0x00,
+	// 1468 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xd4, 0x57, 0x4f, 0x6f, 0xdb, 0xc6,
+	0x12, 0x37, 0xf5, 0xcf, 0xd2, 0xe8, 0x8f, 0xe9, 0xb5, 0x9d, 0xa7, 0xa7, 0x97, 0xe7, 0x28, 0xcc,
+	0x4b, 0xa0, 0x67, 0x34, 0x4e, 0xa1, 0xb4, 0x49, 0x91, 0xa2, 0x40, 0x63, 0xc7, 0x69, 0x5c, 0x3b,
+	0x70, 0xba, 0x76, 0x90, 0xf6, 0xb8, 0x22, 0x37, 0x16, 0x11, 0x6a, 0x97, 0x25, 0x57, 0x89, 0x54,
+	0x14, 0x3d, 0xf4, 0xd2, 0x8f, 0xd3, 0x2f, 0xd0, 0xde, 0x73, 0xcc, 0xbd, 0x40, 0x90, 0xfa, 0xd4,
+	0x8f, 0xd0, 0x63, 0xb1, 0xcb, 0xa5, 0xf8, 0x47, 0x4a, 0x61, 0x34, 0xbd, 0xf4, 0xb6, 0x33, 0xf3,
+	0x9b, 0xdf, 0xce, 0xce, 0xec, 0xce, 0x90, 0x50, 0x17, 0x13, 0xe6, 0x0f, 0xb6, 0xfd, 0x80, 0x0b,
+	0x8e, 0xca, 0x4a, 0xe8, 0x5c, 0x3f, 0x75, 0xc5, 0x70, 0x3c, 0xd8, 0xb6, 0xf9, 0xe8, 0xc6, 0x29,
+	0x3f, 0xe5, 0x37, 0x94, 0x75, 0x30, 0x7e, 0xaa, 0x24, 0x25, 0xa8, 0x55, 0xe4, 0x65, 0xfd, 0x5e,
+	0x80, 0xe5, 0x93, 0x09, 0x7b, 0x48, 0x05, 0x41, 0x17, 0xa0, 0xe0, 0x3a, 0x6d, 0xa3, 0x6b, 0xf4,
+	0x1a, 0x3b, 0x95, 0xb3, 0xd7, 0x97, 0x0a, 0xfb, 0xf7, 0x70, 0xc1, 0x75, 0x10, 0x82, 0x12, 0x23,
+	0x23, 0xda, 0x2e, 0x74, 0x8d, 0x5e, 0x0d, 0xab, 0x35, 0xfa, 0x04, 0x5a, 0x6e, 0xc8, 0x3d, 0x22,
+	0x5c, 0xce, 0x0e, 0xe9, 0x73, 0xea, 0xb5, 0x8b, 0x5d, 0xa3, 0xd7, 0xea, 0x6f, 0x6c, 0x47, 0x31,
+	0xed, 0x67, 0x8c, 0x38, 0x07, 0x46, 0xef, 0xc1, 0xaa, 0x98, 0x30, 0x4c, 0x6d, 0x1e, 0x38, 0x98,
+	0x8f, 0x05, 0x3d, 0xa0, 0xd3, 0x76, 0x49, 0xee, 0x8c, 0xe7, 0x0d, 0xe8, 0x7d, 0x58, 0x9b, 0x29,
+	0x8f, 0x87, 0x24, 0x70, 0x3e, 0x0b, 0xf8, 0xd8, 0x6f, 0x97, 0xbb, 0x46, 0xaf, 0x84, 0x17, 0x99,
+	0xd0, 0x3a, 0x94, 0xa9, 0xcf, 0xed, 0x61, 0xbb, 0xd2, 0x35, 0x7a, 0x4d, 0x1c, 0x09, 0xa8, 0x03,
+	0x55, 0x3f, 0x70, 0x79, 0xe0, 0x8a, 0x69, 0x7b, 0x59, 0x19, 0x66, 0x32, 0xba, 0x06, 0xad, 0x17,
+	0x81, 0x2b, 0xe8, 0x89, 0x3b, 0xa2, 0xa1, 0x20, 0x23, 0xbf, 0x5d, 0x55, 0xf4, 0x39, 0x2d, 0xfa,
+	0x1f, 0x34, 0x03, 0x4a, 0x9c, 0x04, 0x56, 0x53, 0xb0, 0xac, 0x12, 0x59, 0xd0, 0x18, 0x91, 0x49,
+	0x02, 0x02, 0x05, 0xca, 0xe8, 0xac, 0x5f, 0x8a, 0x50, 0x3b, 0x89, 0x63, 0x47, 0x7d, 0x58, 0x16,
+	0x51, 0x1d, 0x54, 0x05, 0xea, 0xfd, 0x96, 0xce, 0xa4, 0xae, 0xce, 0x4e, 0xf5, 0xe5, 0xeb, 0x4b,
+	0x4b, 0xaf, 0x5e, 0x5f, 0x32, 0x70, 0x0c, 0x44, 0x3d, 0xa8, 0x84, 0x82, 0x88, 0x71, 0xa8, 0x4a,
+	0xd3, 0xea, 0x9b, 0x89, 0xcb, 0xb1, 0xd2, 0x63, 0x6d, 0x97, 0x51, 0x7b, 0x24, 0x14, 0x0f, 0x28,
+	0x09, 0xc4, 0x80, 0x12, 0xa1, 0xaa, 0x55, 0xc2, 0x59, 0x25, 0x7a, 0x0c, 0x2b, 0x36, 0x1f, 0xf9,
+	0x1e, 0x15, 0xd4, 0x79, 0x22, 0x8f, 0x1d, 0xb6, 0x4b, 0xdd, 0x62, 0xaf, 0xde, 0xbf, 0x9a, 0x10,
+	0x47, 0xe1, 0x6e, 0xef, 0x66, 0x71, 0x7b, 0x4c, 0x04, 0xd3, 0x9d, 0x92, 0x0c, 0x11, 0xe7, 0x39,
+	0xd0, 0x11, 0x34, 0x5d, 0xf6, 0xd4, 0x3d, 0x1d, 0x0a, 0x4d, 0x5a, 0x56, 0xa4, 0x57, 0xe6, 0x48,
+	0xf7, 0xd3, 0xa8, 0x34, 0x65, 0xd6, 0xbf, 0xf3, 0x05, 0xac, 0x2f, 0xda, 0x1f, 0x99, 0x50, 0x7c,
+	0x46, 0xa7, 0x2a, 0x7f, 0x25, 0x2c, 0x97, 0xe8, 0x0a, 0x94, 0x9f, 0x13, 0x6f, 0x1c, 0xdd, 0xdd,
+	0x7a, 0xbf, 0xa9, 0xb7, 0x3c, 0xa0, 0xd3, 0x63, 0x2a, 0x70, 0x64, 0xbb, 0x53, 0xf8, 0xc8, 0xe8,
+	0x1c, 0x01, 0x9a, 0xdf, 0xfd, 0x1d, 0x08, 0xad, 0x9f, 0x4b, 0xaa, 0xba, 0x47, 0xbe, 0xaa, 0xd4,
+	0x5f, 0xa9, 0x6e, 0x07, 0xaa, 0x21, 0xfd, 0x7a, 0x4c, 0x99, 0x4d, 0x55, 0xb9, 0x9a, 0x78, 0x26,
+	0x9f, 0xab, 0x52, 0xd1, 0xd6, 0x7f, 0x73, 0xa5, 0x34, 0xe9, 0x39, 0x2b, 0x85, 0xee, 0x03, 0x78,
+	0xdc, 0x7e, 0x46, 0x9d, 0x03, 0x3a, 0x0d, 0xdb, 0x15, 0xc5, 0xd6, 0x9d, 0x63, 0x3b, 0x9c, 0x41,
+	0xd2, 0x54, 0x29, 0xcf, 0x7f, 0x42, 0xc5, 0x3b, 0x87, 0xb0, 0x92, 0x3b, 0xc8, 0xbb, 0xdc, 0x9f,
+	0x3e, 0x54, 0x0f, 0xe8, 0x14, 0x13, 0x76, 0x4a, 0x65, 0x37, 0x0b, 0x05, 0x09, 0x44, 0xd4, 0x9b,
+	0x71, 0x24, 0x48, 0x72, 0xca, 0x1c, 0x45, 0xd4, 0xc0, 0x72, 0x69, 0x8d, 0xa0, 0x12, 0x11, 0xa1,
+	0x8b, 0x50, 0xf3, 0xb9, 0xcb, 0x84, 0x4a, 0xbb, 0xd1, 0x2d, 0xf6, 0x1a, 0x38, 0x51, 0xa0, 0xeb,
+	0x50, 0x09, 0x24, 0xb1, 0xec, 0x1b, 0xb2, 0x22, 0x2b, 0x49, 0x14, 0x6a, 0x43, 0x5d, 0x00, 0x0d,
+	0x42, 0x17, 0xa0, 0x12, 0xf2, 0x40, 0x50, 0x47, 0x5d, 0xc3, 0x2a, 0xd6, 0x92, 0xf5, 0xc6, 0x80,
+	0x86, 0x2a, 0x22, 0x0d, 0x54, 0x6b, 0x47, 0x2d, 0x28, 0x70, 0x5f, 0x05, 0xd9, 0xc4, 0x05, 0xee,
+	0xa3, 0x36, 0x2c, 0xfb, 0x64, 0xea, 0x71, 0x12, 0x47, 0x19, 0x8b, 0xe8, 0x06, 0x54, 0xdd, 0x91,
+	0x4f, 0xec, 0x98, 0x34, 0x9f, 0x09, 0x1d, 0xc1, 0x0c, 0x84, 0x6e, 0x43, 0x23, 0x5e, 0x9f, 0x4c,
+	0x7d, 0xaa, 0x66, 0x45, 0xab, 0xbf, 0x16, 0x4f, 0x9b, 0x94, 0x09, 0x67, 0x80, 0x68, 0x13, 0x20,
+	0xcc, 0x8f, 0x8c, 0x94, 0x46, 0x66, 0x4a, 0xcc, 0xda, 0x74, 0x45, 0x99, 0x13, 0x85, 0xf5, 0xbd,
+	0x01, 0x2b, 0x27, 0x13, 0xb6, 0x43, 0x84, 0x3d, 0xc4, 0xf2, 0xf1, 0x85, 0x02, 0xdd, 0x81, 0xca,
+	0x90, 0x12, 0x87, 0x06, 0xfa, 0x29, 0x5f, 0x4c, 0xee, 0x73, 0x1a, 0xf7, 0x40, 0x61, 0xe2, 0x54,
+	0x46, 0x1e, 0xe8, 0x26, 0x54, 0x83, 0xc8, 0x1c, 0xe7, 0x7e, 0x35, 0xdd, 0x05, 0x95, 0x25, 0x3e,
+	0x7b, 0x0c, 0xb4, 0x3c, 0xd8, 0x58, 0xc8, 0x8d, 0x7a, 0x50, 0x14, 0x13, 0xa6, 0xc3, 0x30, 0xf3,
+	0xcf, 0x4a, 0xf3, 0x48, 0x08, 0xfa, 0x3f, 0x94, 0x84, 0x4c, 0x5b, 0x21, 0x33, 0xa4, 0x93, 0x3d,
+	0x55, 0xe2, 0x14, 0xc4, 0xfa, 0xd1, 0x80, 0x0b, 0xc9, 0x76, 0xa1, 0xcf, 0x59, 0x48, 0xf5, 0x7e,
+	0xd7, 0xd2, 0xfb, 0xe5, 0x3b, 0x58, 0x6a, 0xb7, 0xf3, 0xcf, 0xa5, 0xab, 0x50, 0xa6, 0x41, 0xc0,
+	0x03, 0x7d, 0x09, 0x56, 0x12, 0xe0, 0x9e, 0x54, 0xe3, 0xc8, 0x9a, 0x2d, 0x52, 0x29, 0x5f, 0xa4,
+	0x1f, 0x0c, 0x30, 0xf3, 0x11, 0xa3, 0x8f, 0x73, 0x55, 0xfa, 0xef, 0x5c, 0x95, 0xd2, 0x47, 0xcb,
+	0x95, 0xe9, 0x16, 0xd4, 0x02, 0x6d, 0x8f, 0xeb, 0x84, 0xd2, 0x39, 0x8b, 0x4c, 0xda, 0x29, 0x81,
+	0x5a, 0xdf, 0x02, 0x24, 0x39, 0x45, 0xb7, 0xa1, 0xc6, 0xe3, 0xb7, 0xa1, 0xa3, 0x58, 0x4b, 0x17,
+	0x49, 0x9b, 0x62, 0x9a, 0x19, 0x16, 0x7d, 0x08, 0xcb, 0xdc, 0x97, 0xab, 0x50, 0xb7, 0x89, 0xb8,
+	0x60, 0x9a, 0xf9, 0x28, 0x32, 0x6a, 0xc7, 0x18, 0x6b, 0x3d, 0x84, 0x7a, 0x2a, 0x3a, 0xf9, 0xd9,
+	0xe6, 0x10, 0x3d, 0x70, 0x1a, 0x58, 0xad, 0xd1, 0x16, 0x98, 0x23, 0x32, 0xc1, 0x99, 0x0f, 0x98,
+	0x82, 0xca, 0xe7, 0x9c, 0xde, 0xfa, 0xc9, 0x80, 0x56, 0x76, 0x43, 0xd4, 0x83, 0x15, 0x3b, 0xa0,
+	0x44, 0xd0, 0xd9, 0xcc, 0x56, 0xec, 0x55, 0x9c, 0x57, 0xa3, 0x0f, 0x60, 0x83, 0x84, 0x53, 0x66,
+	0x0f, 0x03, 0xce, 0xf8, 0x38, 0xdc, 0x95, 0x11, 0xb1, 0x50, 0xdf, 0x88, 0x2a, 0x5e, 0x6c, 0x44,
+	0x5d, 0xa8, 0x2b, 0xc3, 0x2e, 0x1f, 0x8d, 0x5c, 0xa1, 0xdb, 0x4d, 0x5a, 0x25, 0x23, 0x90, 0x63,
+	0xe1, 0x09, 0x71, 0x85, 0x8c, 0x94, 0x8f, 0x85, 0xbe, 0x0f, 0x79, 0xb5, 0xfc, 0xb2, 0xad, 0xc6,
+	0xf7, 0x08, 0x11, 0xe8, 0xd8, 0x9c, 0x3d, 0xf5, 0x5c, 0x5b, 0x3c, 0x71, 0xc5, 0x30, 0x22, 0x13,
+	0xd4, 0x51, 0x56, 0x5d, 0x9b, 0xcb, 0x3a, 0xc9, 0xbb, 0x6f, 0x05, 0xe2, 0x3f, 0x21, 0x41, 0xbb,
+	0x60, 0x8e, 0x99, 0x4d, 0x03, 0x41, 0x5c, 0x26, 0xa6, 0x11, 0x71, 0x54, 0xbd, 0x7f, 0x69, 0xe2,
+	0xc7, 0x39, 0x33, 0x9e, 0x73, 0x90, 0x6d, 0x8e, 0x0c, 0x54, 0x77, 0xdd, 0x4b, 0x3d, 0x8b, 0xf8,
+	0xd6, 0xdc, 0x4d, 0x99, 0x70, 0x06, 0x88, 0xee, 0x40, 0xd3, 0xa1, 0xc4, 0x91, 0x49, 0x88, 0x3c,
+	0x4b, 0xca, 0x73, 0x5d, 0x7b, 0xde, 0x4b, 0xdb, 0x70, 0x16, 0x8a, 0x8e, 0x60, 0x3d, 0x97, 0xbc,
+	0x88, 0xa2, 0xac, 0x28, 0xfe, 0xa3, 0x29, 0x0e, 0x17, 0x40, 0xf0, 0x42, 0x47, 0xeb, 0x53, 0xe8,
+	0xbc, 0x3d, 0x89, 0xea, 0xdb, 0xd8, 0x65, 0xc9, 0xfd, 0x33, 0xf4, 0xb7, 0x71, 0x4a, 0x67, 0xdd,
+	0x02, 0x33, 0x9f, 0xad, 0x73, 0xf9, 0xb5, 0xa0, 0x91, 0x4e, 0x92, 0x75, 0x19, 0x9a, 0x99, 0xa3,
+	0xa7, 0x27, 0x72, 0x43, 0x4d, 0x64, 0xab, 0x07, 0xeb, 0x8b, 0x8e, 0xb6, 0x00, 0xf9, 0x15, 0xd4,
+	0x25, 0xea, 0x3e, 0x0f, 0xf6, 0x9c, 0x68, 0x2a, 0x8b, 0x09, 0xdb, 0xbf, 0x17, 0x4f, 0x65, 0x25,
+	0xc8, 0x28, 0x5f, 0x44, 0xa0, 0x13, 0x65, 0x8c, 0x06, 0x5f, 0x46, 0x17, 0x53, 0x17, 0x67, 0xd4,
+	0x5b, 0x5f, 0x42, 0x2b, 0xfb, 0xc7, 0x84, 0xda, 0xb0, 0x7e, 0xcc, 0x88, 0x1f, 0x0e, 0xb9, 0x38,
+	0xa6, 0x81, 0x4b, 0x3c, 0xf7, 0x1b, 0x32, 0xf0, 0xa8, 0xb9, 0x84, 0x56, 0xa1, 0x29, 0x1f, 0xea,
+	0x2c, 0xab, 0xa6, 0x81, 0xfe, 0x0d, 0x1b, 0x19, 0x95, 0x14, 0x8e, 0x98, 0x37, 0x35, 0x0b, 0x5b,
+	0x77, 0xa1, 0x36, 0x6b, 0xbb, 0xa8, 0x0e, 0xcb, 0x8f, 0x28, 0x73, 0x5c, 0x76, 0x6a, 0x2e, 0x49,
+	0xe1, 0x58, 0x90, 0x53, 0x29, 0x18, 0xa8, 0x09, 0xb5, 0x84, 0xb0, 0x20, 0x6d, 0x3a, 0x8f, 0x66,
+	0x71, 0xeb, 0x3b, 0x68, 0xee, 0x33, 0x41, 0x03, 0x46, 0x3c, 0xd5, 0xb7, 0x24, 0x78, 0xf6, 0xd3,
+	0x60, 0x2e, 0x21, 0x80, 0x4a, 0xe4, 0x6b, 0x1a, 0xa8, 0x01, 0x55, 0xcc, 0x3d, 0x6f, 0x40, 0xec,
+	0x67, 0x66, 0x41, 0x86, 0x2a, 0x33, 0x36, 0x7b, 0xe0, 0x66, 0x11, 0xad, 0xc1, 0xca, 0x23, 0x1a,
+	0x86, 0xee, 0xc8, 0x0d, 0x85, 0x6b, 0xcb, 0xcc, 0x9b, 0x25, 0x49, 0x88, 0x29, 0xa3, 0x2f, 0x94,
+	0x58, 0x46, 0x4d, 0xa8, 0x62, 0x1a, 0xd2, 0xe0, 0x39, 0x75, 0xcc, 0xdf, 0x96, 0xb7, 0x3e, 0x87,
+	0x46, 0x7a, 0xc0, 0x23, 0x13, 0x1a, 0xf2, 0x80, 0xb1, 0x2e, 0x4a, 0x89, 0xfa, 0x88, 0x9b, 0xa9,
+	0x0c, 0xb4, 0x01, 0xab, 0x12, 0x94, 0x55, 0x17, 0xb6, 0xae, 0x42, 0x2b, 0x3b, 0xf5, 0x50, 0x15,
+	0x4a, 0x12, 0x68, 0x2e, 0xa1, 0x1a, 0x94, 0x15, 0xdc, 0x34, 0x76, 0xcc, 0x57, 0xbf, 0x6e, 0x1a,
+	0x2f, 0xcf, 0x36, 0x8d, 0x57, 0x67, 0x9b, 0xc6, 0x9b, 0xb3, 0x4d, 0x63, 0x50, 0x51, 0xff, 0xcb,
+	0x37, 0xff, 0x18, 0x00, 0xff, 0x66, 0x14, 0xbc, 0x74, 0x0f, 0x00, 0x00,
 }
 
 func (m *TxnMeta) Marshal() (dAtA []byte, err error) {
@@ -1693,6 +1959,31 @@ func (m *TxnOpMeta) MarshalTo(dAtA []byte) (int, error) {
 			i += n6
 		}
 	}
+	if len(m.LockedKeys) > 0 {
+		for k, _ := range m.LockedKeys {
+			dAtA[i] = 0x32
+			i++
+			v := m.LockedKeys[k]
+			msgSize := 0
+			if (&v) != nil {
+				msgSize = (&v).Size()
+				msgSize += 1 + sovTxnpb(uint64(msgSize))
+			}
+			mapSize := 1 + sovTxnpb(uint64(k)) + msgSize
+			i = encodeVarintTxnpb(dAtA, i, uint64(mapSize))
+			dAtA[i] = 0x8
+			i++
+			i = encodeVarintTxnpb(dAtA, i, uint64(k))
+			dAtA[i] = 0x12
+			i++
+			i = encodeVarintTxnpb(dAtA, i, uint64((&v).Size()))
+			n7, err := (&v).MarshalTo(dAtA[i:])
+			if err != nil {
+				return 0, err
+			}
+			i += n7
+		}
+	}
 	if m.XXX_unrecognized != nil {
 		i += copy(dAtA[i:], m.XXX_unrecognized)
 	}
@@ -1812,11 +2103,11 @@ func (m *TxnOperation) MarshalTo(dAtA []byte) (int, error) {
 	dAtA[i] = 0x1a
 	i++
 	i = encodeVarintTxnpb(dAtA, i, uint64(m.Impacted.Size()))
-	n7, err := m.Impacted.MarshalTo(dAtA[i:])
+	n8, err := m.Impacted.MarshalTo(dAtA[i:])
 	if err != nil {
 		return 0, err
 	}
-	i += n7
+	i += n8
 	if m.ImpactedType != 0 {
 		dAtA[i] = 0x20
 		i++
@@ -1856,11 +2147,11 @@ func (m *TxnBatchRequest) MarshalTo(dAtA []byte) (int, error) {
 	dAtA[i] = 0xa
 	i++
 	i = encodeVarintTxnpb(dAtA, i, uint64(m.Header.Size()))
-	n8, err := m.Header.MarshalTo(dAtA[i:])
+	n9, err := m.Header.MarshalTo(dAtA[i:])
 	if err != nil {
 		return 0, err
 	}
-	i += n8
+	i += n9
 	if len(m.Requests) > 0 {
 		for _, msg := range m.Requests {
 			dAtA[i] = 0x12
@@ -1897,11 +2188,11 @@ func (m *TxnBatchRequestHeader) MarshalTo(dAtA []byte) (int, error) {
 	dAtA[i] = 0xa
 	i++
 	i = encodeVarintTxnpb(dAtA, i, uint64(m.Txn.Size()))
-	n9, err := m.Txn.MarshalTo(dAtA[i:])
+	n10, err := m.Txn.MarshalTo(dAtA[i:])
 	if err != nil {
 		return 0, err
 	}
-	i += n9
+	i += n10
 	if m.Type != 0 {
 		dAtA[i] = 0x10
 		i++
@@ -1931,11 +2222,11 @@ func (m *TxnBatchResponseHeader) MarshalTo(dAtA []byte) (int, error) {
 	dAtA[i] = 0xa
 	i++
 	i = encodeVarintTxnpb(dAtA, i, uint64(m.Txn.Size()))
-	n10, err := m.Txn.MarshalTo(dAtA[i:])
+	n11, err := m.Txn.MarshalTo(dAtA[i:])
 	if err != nil {
 		return 0, err
 	}
-	i += n10
+	i += n11
 	if m.Status != 0 {
 		dAtA[i] = 0x10
 		i++
@@ -1945,11 +2236,16 @@ func (m *TxnBatchResponseHeader) MarshalTo(dAtA []byte) (int, error) {
 		dAtA[i] = 0x1a
 		i++
 		i = encodeVarintTxnpb(dAtA, i, uint64(m.Error.Size()))
-		n11, err := m.Error.MarshalTo(dAtA[i:])
+		n12, err := m.Error.MarshalTo(dAtA[i:])
 		if err != nil {
 			return 0, err
 		}
-		i += n11
+		i += n12
+	}
+	if m.Timestamp != 0 {
+		dAtA[i] = 0x20
+		i++
+		i = encodeVarintTxnpb(dAtA, i, uint64(m.Timestamp))
 	}
 	if m.XXX_unrecognized != nil {
 		i += copy(dAtA[i:], m.XXX_unrecognized)
@@ -1975,11 +2271,11 @@ func (m *TxnBatchResponse) MarshalTo(dAtA []byte) (int, error) {
 	dAtA[i] = 0xa
 	i++
 	i = encodeVarintTxnpb(dAtA, i, uint64(m.Header.Size()))
-	n12, err := m.Header.MarshalTo(dAtA[i:])
+	n13, err := m.Header.MarshalTo(dAtA[i:])
 	if err != nil {
 		return 0, err
 	}
-	i += n12
+	i += n13
 	if len(m.Responses) > 0 {
 		for _, msg := range m.Responses {
 			dAtA[i] = 0x12
@@ -2016,19 +2312,19 @@ func (m *TxnRequest) MarshalTo(dAtA []byte) (int, error) {
 	dAtA[i] = 0xa
 	i++
 	i = encodeVarintTxnpb(dAtA, i, uint64(m.Operation.Size()))
-	n13, err := m.Operation.MarshalTo(dAtA[i:])
+	n14, err := m.Operation.MarshalTo(dAtA[i:])
 	if err != nil {
 		return 0, err
 	}
-	i += n13
+	i += n14
 	dAtA[i] = 0x12
 	i++
 	i = encodeVarintTxnpb(dAtA, i, uint64(m.Options.Size()))
-	n14, err := m.Options.MarshalTo(dAtA[i:])
+	n15, err := m.Options.MarshalTo(dAtA[i:])
 	if err != nil {
 		return 0, err
 	}
-	i += n14
+	i += n15
 	if m.XXX_unrecognized != nil {
 		i += copy(dAtA[i:], m.XXX_unrecognized)
 	}
@@ -2056,6 +2352,11 @@ func (m *TxnResponse) MarshalTo(dAtA []byte) (int, error) {
 		i = encodeVarintTxnpb(dAtA, i, uint64(len(m.Data)))
 		i += copy(dAtA[i:], m.Data)
 	}
+	if m.MaxReadTimestamp != 0 {
+		dAtA[i] = 0x10
+		i++
+		i = encodeVarintTxnpb(dAtA, i, uint64(m.MaxReadTimestamp))
+	}
 	if m.XXX_unrecognized != nil {
 		i += copy(dAtA[i:], m.XXX_unrecognized)
 	}
@@ -2097,6 +2398,21 @@ func (m *RequestOptions) MarshalTo(dAtA []byte) (int, error) {
 		}
 		i++
 	}
+	if m.AsyncCommit {
+		dAtA[i] = 0x18
+		i++
+		if m.AsyncCommit {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i++
+	}
+	if m.LockWaitTimeout != 0 {
+		dAtA[i] = 0x20
+		i++
+		i = encodeVarintTxnpb(dAtA, i, uint64(m.LockWaitTimeout))
+	}
 	if m.XXX_unrecognized != nil {
 		i += copy(dAtA[i:], m.XXX_unrecognized)
 	}
@@ -2122,31 +2438,51 @@ func (m *TxnError) MarshalTo(dAtA []byte) (int, error) {
 		dAtA[i] = 0xa
 		i++
 		i = encodeVarintTxnpb(dAtA, i, uint64(m.ConflictWithCommittedError.Size()))
-		n15, err := m.ConflictWithCommittedError.MarshalTo(dAtA[i:])
+		n16, err := m.ConflictWithCommittedError.MarshalTo(dAtA[i:])
 		if err != nil {
 			return 0, err
 		}
-		i += n15
+		i += n16
 	}
 	if m.UncertaintyError != nil {
 		dAtA[i] = 0x12
 		i++
 		i = encodeVarintTxnpb(dAtA, i, uint64(m.UncertaintyError.Size()))
-		n16, err := m.UncertaintyError.MarshalTo(dAtA[i:])
+		n17, err := m.UncertaintyError.MarshalTo(dAtA[i:])
 		if err != nil {
 			return 0, err
 		}
-		i += n16
+		i += n17
 	}
 	if m.AbortedError != nil {
 		dAtA[i] = 0x1a
 		i++
 		i = encodeVarintTxnpb(dAtA, i, uint64(m.AbortedError.Size()))
-		n17, err := m.AbortedError.MarshalTo(dAtA[i:])
+		n18, err := m.AbortedError.MarshalTo(dAtA[i:])
 		if err != nil {
 			return 0, err
 		}
-		i += n17
+		i += n18
+	}
+	if m.DeadlockError != nil {
+		dAtA[i] = 0x22
+		i++
+		i = encodeVarintTxnpb(dAtA, i, uint64(m.DeadlockError.Size()))
+		n19, err := m.DeadlockError.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n19
+	}
+	if m.LockWaitTimeoutError != nil {
+		dAtA[i] = 0x2a
+		i++
+		i = encodeVarintTxnpb(dAtA, i, uint64(m.LockWaitTimeoutError.Size()))
+		n20, err := m.LockWaitTimeoutError.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n20
 	}
 	if m.XXX_unrecognized != nil {
 		i += copy(dAtA[i:], m.XXX_unrecognized)
@@ -2227,43 +2563,136 @@ func (m *AbortedError) MarshalTo(dAtA []byte) (int, error) {
 	return i, nil
 }
 
-func encodeVarintTxnpb(dAtA []byte, offset int, v uint64) int {
-	for v >= 1<<7 {
-		dAtA[offset] = uint8(v&0x7f | 0x80)
-		v >>= 7
-		offset++
+func (m *DeadlockError) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
 	}
-	dAtA[offset] = uint8(v)
-	return offset + 1
+	return dAtA[:n], nil
 }
-func (m *TxnMeta) Size() (n int) {
-	if m == nil {
-		return 0
-	}
+
+func (m *DeadlockError) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
 	var l int
 	_ = l
-	l = len(m.ID)
-	if l > 0 {
-		n += 1 + l + sovTxnpb(uint64(l))
-	}
-	l = len(m.Name)
-	if l > 0 {
-		n += 1 + l + sovTxnpb(uint64(l))
-	}
-	if m.IsolationLevel != 0 {
-		n += 1 + sovTxnpb(uint64(m.IsolationLevel))
-	}
-	l = len(m.TxnRecordRouteKey)
-	if l > 0 {
-		n += 1 + l + sovTxnpb(uint64(l))
+	if len(m.Key) > 0 {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintTxnpb(dAtA, i, uint64(len(m.Key)))
+		i += copy(dAtA[i:], m.Key)
 	}
-	if m.TxnRecordShardGroup != 0 {
-		n += 1 + sovTxnpb(uint64(m.TxnRecordShardGroup))
+	if m.XXX_unrecognized != nil {
+		i += copy(dAtA[i:], m.XXX_unrecognized)
 	}
-	if m.Epoch != 0 {
-		n += 1 + sovTxnpb(uint64(m.Epoch))
+	return i, nil
+}
+
+func (m *LockWaitTimeoutError) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
 	}
-	if m.Priority != 0 {
+	return dAtA[:n], nil
+}
+
+func (m *LockWaitTimeoutError) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.Key) > 0 {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintTxnpb(dAtA, i, uint64(len(m.Key)))
+		i += copy(dAtA[i:], m.Key)
+	}
+	if m.XXX_unrecognized != nil {
+		i += copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	return i, nil
+}
+
+func (m *WaitForEdge) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *WaitForEdge) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.TxnID) > 0 {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintTxnpb(dAtA, i, uint64(len(m.TxnID)))
+		i += copy(dAtA[i:], m.TxnID)
+	}
+	if len(m.WaitForTxnID) > 0 {
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintTxnpb(dAtA, i, uint64(len(m.WaitForTxnID)))
+		i += copy(dAtA[i:], m.WaitForTxnID)
+	}
+	if len(m.Key) > 0 {
+		dAtA[i] = 0x1a
+		i++
+		i = encodeVarintTxnpb(dAtA, i, uint64(len(m.Key)))
+		i += copy(dAtA[i:], m.Key)
+	}
+	if m.XXX_unrecognized != nil {
+		i += copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	return i, nil
+}
+
+func encodeVarintTxnpb(dAtA []byte, offset int, v uint64) int {
+	for v >= 1<<7 {
+		dAtA[offset] = uint8(v&0x7f | 0x80)
+		v >>= 7
+		offset++
+	}
+	dAtA[offset] = uint8(v)
+	return offset + 1
+}
+func (m *TxnMeta) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.ID)
+	if l > 0 {
+		n += 1 + l + sovTxnpb(uint64(l))
+	}
+	l = len(m.Name)
+	if l > 0 {
+		n += 1 + l + sovTxnpb(uint64(l))
+	}
+	if m.IsolationLevel != 0 {
+		n += 1 + sovTxnpb(uint64(m.IsolationLevel))
+	}
+	l = len(m.TxnRecordRouteKey)
+	if l > 0 {
+		n += 1 + l + sovTxnpb(uint64(l))
+	}
+	if m.TxnRecordShardGroup != 0 {
+		n += 1 + sovTxnpb(uint64(m.TxnRecordShardGroup))
+	}
+	if m.Epoch != 0 {
+		n += 1 + sovTxnpb(uint64(m.Epoch))
+	}
+	if m.Priority != 0 {
 		n += 1 + sovTxnpb(uint64(m.Priority))
 	}
 	if m.WriteTimestamp != 0 {
@@ -2348,6 +2777,15 @@ func (m *TxnOpMeta) Size() (n int) {
 			n += mapEntrySize + 1 + sovTxnpb(uint64(mapEntrySize))
 		}
 	}
+	if len(m.LockedKeys) > 0 {
+		for k, v := range m.LockedKeys {
+			_ = k
+			_ = v
+			l = v.Size()
+			mapEntrySize := 1 + sovTxnpb(uint64(k)) + 1 + l + sovTxnpb(uint64(l))
+			n += mapEntrySize + 1 + sovTxnpb(uint64(mapEntrySize))
+		}
+	}
 	if m.XXX_unrecognized != nil {
 		n += len(m.XXX_unrecognized)
 	}
@@ -2483,6 +2921,9 @@ func (m *TxnBatchResponseHeader) Size() (n int) {
 		l = m.Error.Size()
 		n += 1 + l + sovTxnpb(uint64(l))
 	}
+	if m.Timestamp != 0 {
+		n += 1 + sovTxnpb(uint64(m.Timestamp))
+	}
 	if m.XXX_unrecognized != nil {
 		n += len(m.XXX_unrecognized)
 	}
@@ -2535,6 +2976,9 @@ func (m *TxnResponse) Size() (n int) {
 	if l > 0 {
 		n += 1 + l + sovTxnpb(uint64(l))
 	}
+	if m.MaxReadTimestamp != 0 {
+		n += 1 + sovTxnpb(uint64(m.MaxReadTimestamp))
+	}
 	if m.XXX_unrecognized != nil {
 		n += len(m.XXX_unrecognized)
 	}
@@ -2553,6 +2997,12 @@ func (m *RequestOptions) Size() (n int) {
 	if m.AsynchronousConsensus {
 		n += 2
 	}
+	if m.AsyncCommit {
+		n += 2
+	}
+	if m.LockWaitTimeout != 0 {
+		n += 1 + sovTxnpb(uint64(m.LockWaitTimeout))
+	}
 	if m.XXX_unrecognized != nil {
 		n += len(m.XXX_unrecognized)
 	}
@@ -2577,6 +3027,14 @@ func (m *TxnError) Size() (n int) {
 		l = m.AbortedError.Size()
 		n += 1 + l + sovTxnpb(uint64(l))
 	}
+	if m.DeadlockError != nil {
+		l = m.DeadlockError.Size()
+		n += 1 + l + sovTxnpb(uint64(l))
+	}
+	if m.LockWaitTimeoutError != nil {
+		l = m.LockWaitTimeoutError.Size()
+		n += 1 + l + sovTxnpb(uint64(l))
+	}
 	if m.XXX_unrecognized != nil {
 		n += len(m.XXX_unrecognized)
 	}
@@ -2625,6 +3083,62 @@ func (m *AbortedError) Size() (n int) {
 	return n
 }
 
+func (m *DeadlockError) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Key)
+	if l > 0 {
+		n += 1 + l + sovTxnpb(uint64(l))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *LockWaitTimeoutError) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Key)
+	if l > 0 {
+		n += 1 + l + sovTxnpb(uint64(l))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *WaitForEdge) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.TxnID)
+	if l > 0 {
+		n += 1 + l + sovTxnpb(uint64(l))
+	}
+	l = len(m.WaitForTxnID)
+	if l > 0 {
+		n += 1 + l + sovTxnpb(uint64(l))
+	}
+	l = len(m.Key)
+	if l > 0 {
+		n += 1 + l + sovTxnpb(uint64(l))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
 func sovTxnpb(x uint64) (n int) {
 	for {
 		n++
@@ -3591,65 +4105,11 @@ func (m *TxnOpMeta) Unmarshal(dAtA []byte) error {
 			}
 			m.InfightWrites[mapkey] = *mapvalue
 			iNdEx = postIndex
-		default:
-			iNdEx = preIndex
-			skippy, err := skipTxnpb(dAtA[iNdEx:])
-			if err != nil {
-				return err
-			}
-			if skippy < 0 {
-				return ErrInvalidLengthTxnpb
-			}
-			if (iNdEx + skippy) < 0 {
-				return ErrInvalidLengthTxnpb
-			}
-			if (iNdEx + skippy) > l {
-				return io.ErrUnexpectedEOF
-			}
-			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
-			iNdEx += skippy
-		}
-	}
-
-	if iNdEx > l {
-		return io.ErrUnexpectedEOF
-	}
-	return nil
-}
-func (m *KeyRange) Unmarshal(dAtA []byte) error {
-	l := len(dAtA)
-	iNdEx := 0
-	for iNdEx < l {
-		preIndex := iNdEx
-		var wire uint64
-		for shift := uint(0); ; shift += 7 {
-			if shift >= 64 {
-				return ErrIntOverflowTxnpb
-			}
-			if iNdEx >= l {
-				return io.ErrUnexpectedEOF
-			}
-			b := dAtA[iNdEx]
-			iNdEx++
-			wire |= uint64(b&0x7F) << shift
-			if b < 0x80 {
-				break
-			}
-		}
-		fieldNum := int32(wire >> 3)
-		wireType := int(wire & 0x7)
-		if wireType == 4 {
-			return fmt.Errorf("proto: KeyRange: wiretype end group for non-group")
-		}
-		if fieldNum <= 0 {
-			return fmt.Errorf("proto: KeyRange: illegal tag %d (wire type %d)", fieldNum, wire)
-		}
-		switch fieldNum {
-		case 1:
+		case 6:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Start", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field LockedKeys", wireType)
 			}
-			var byteLen int
+			var msglen int
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowTxnpb
@@ -3659,49 +4119,218 @@ func (m *KeyRange) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				byteLen |= int(b&0x7F) << shift
+				msglen |= int(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			if byteLen < 0 {
+			if msglen < 0 {
 				return ErrInvalidLengthTxnpb
 			}
-			postIndex := iNdEx + byteLen
+			postIndex := iNdEx + msglen
 			if postIndex < 0 {
 				return ErrInvalidLengthTxnpb
 			}
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Start = append(m.Start[:0], dAtA[iNdEx:postIndex]...)
-			if m.Start == nil {
-				m.Start = []byte{}
-			}
-			iNdEx = postIndex
-		case 2:
-			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field End", wireType)
+			if m.LockedKeys == nil {
+				m.LockedKeys = make(map[uint64]KeySet)
 			}
-			var byteLen int
-			for shift := uint(0); ; shift += 7 {
-				if shift >= 64 {
-					return ErrIntOverflowTxnpb
-				}
-				if iNdEx >= l {
-					return io.ErrUnexpectedEOF
-				}
-				b := dAtA[iNdEx]
-				iNdEx++
-				byteLen |= int(b&0x7F) << shift
-				if b < 0x80 {
-					break
+			var mapkey uint64
+			mapvalue := &KeySet{}
+			for iNdEx < postIndex {
+				entryPreIndex := iNdEx
+				var wire uint64
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowTxnpb
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					wire |= uint64(b&0x7F) << shift
+					if b < 0x80 {
+						break
+					}
 				}
-			}
-			if byteLen < 0 {
-				return ErrInvalidLengthTxnpb
-			}
-			postIndex := iNdEx + byteLen
+				fieldNum := int32(wire >> 3)
+				if fieldNum == 1 {
+					for shift := uint(0); ; shift += 7 {
+						if shift >= 64 {
+							return ErrIntOverflowTxnpb
+						}
+						if iNdEx >= l {
+							return io.ErrUnexpectedEOF
+						}
+						b := dAtA[iNdEx]
+						iNdEx++
+						mapkey |= uint64(b&0x7F) << shift
+						if b < 0x80 {
+							break
+						}
+					}
+				} else if fieldNum == 2 {
+					var mapmsglen int
+					for shift := uint(0); ; shift += 7 {
+						if shift >= 64 {
+							return ErrIntOverflowTxnpb
+						}
+						if iNdEx >= l {
+							return io.ErrUnexpectedEOF
+						}
+						b := dAtA[iNdEx]
+						iNdEx++
+						mapmsglen |= int(b&0x7F) << shift
+						if b < 0x80 {
+							break
+						}
+					}
+					if mapmsglen < 0 {
+						return ErrInvalidLengthTxnpb
+					}
+					postmsgIndex := iNdEx + mapmsglen
+					if postmsgIndex < 0 {
+						return ErrInvalidLengthTxnpb
+					}
+					if postmsgIndex > l {
+						return io.ErrUnexpectedEOF
+					}
+					mapvalue = &KeySet{}
+					if err := mapvalue.Unmarshal(dAtA[iNdEx:postmsgIndex]); err != nil {
+						return err
+					}
+					iNdEx = postmsgIndex
+				} else {
+					iNdEx = entryPreIndex
+					skippy, err := skipTxnpb(dAtA[iNdEx:])
+					if err != nil {
+						return err
+					}
+					if skippy < 0 {
+						return ErrInvalidLengthTxnpb
+					}
+					if (iNdEx + skippy) > postIndex {
+						return io.ErrUnexpectedEOF
+					}
+					iNdEx += skippy
+				}
+			}
+			m.LockedKeys[mapkey] = *mapvalue
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipTxnpb(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthTxnpb
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthTxnpb
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *KeyRange) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowTxnpb
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: KeyRange: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: KeyRange: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Start", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTxnpb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthTxnpb
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthTxnpb
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Start = append(m.Start[:0], dAtA[iNdEx:postIndex]...)
+			if m.Start == nil {
+				m.Start = []byte{}
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field End", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTxnpb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthTxnpb
+			}
+			postIndex := iNdEx + byteLen
 			if postIndex < 0 {
 				return ErrInvalidLengthTxnpb
 			}
@@ -4419,6 +5048,25 @@ func (m *TxnBatchResponseHeader) Unmarshal(dAtA []byte) error {
 				return err
 			}
 			iNdEx = postIndex
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Timestamp", wireType)
+			}
+			m.Timestamp = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTxnpb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Timestamp |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
 		default:
 			iNdEx = preIndex
 			skippy, err := skipTxnpb(dAtA[iNdEx:])
@@ -4748,6 +5396,25 @@ func (m *TxnResponse) Unmarshal(dAtA []byte) error {
 				m.Data = []byte{}
 			}
 			iNdEx = postIndex
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MaxReadTimestamp", wireType)
+			}
+			m.MaxReadTimestamp = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTxnpb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.MaxReadTimestamp |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
 		default:
 			iNdEx = preIndex
 			skippy, err := skipTxnpb(dAtA[iNdEx:])
@@ -4842,6 +5509,45 @@ func (m *RequestOptions) Unmarshal(dAtA []byte) error {
 				}
 			}
 			m.AsynchronousConsensus = bool(v != 0)
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field AsyncCommit", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTxnpb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.AsyncCommit = bool(v != 0)
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field LockWaitTimeout", wireType)
+			}
+			m.LockWaitTimeout = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTxnpb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.LockWaitTimeout |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
 		default:
 			iNdEx = preIndex
 			skippy, err := skipTxnpb(dAtA[iNdEx:])
@@ -5004,17 +5710,89 @@ func (m *TxnError) Unmarshal(dAtA []byte) error {
 				return err
 			}
 			iNdEx = postIndex
-		default:
-			iNdEx = preIndex
-			skippy, err := skipTxnpb(dAtA[iNdEx:])
-			if err != nil {
-				return err
-			}
-			if skippy < 0 {
-				return ErrInvalidLengthTxnpb
-			}
-			if (iNdEx + skippy) < 0 {
-				return ErrInvalidLengthTxnpb
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field DeadlockError", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTxnpb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthTxnpb
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthTxnpb
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.DeadlockError == nil {
+				m.DeadlockError = &DeadlockError{}
+			}
+			if err := m.DeadlockError.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 5:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field LockWaitTimeoutError", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTxnpb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthTxnpb
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthTxnpb
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.LockWaitTimeoutError == nil {
+				m.LockWaitTimeoutError = &LockWaitTimeoutError{}
+			}
+			if err := m.LockWaitTimeoutError.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipTxnpb(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthTxnpb
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthTxnpb
 			}
 			if (iNdEx + skippy) > l {
 				return io.ErrUnexpectedEOF
@@ -5229,6 +6007,338 @@ func (m *AbortedError) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
+func (m *DeadlockError) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowTxnpb
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: DeadlockError: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: DeadlockError: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Key", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTxnpb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthTxnpb
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthTxnpb
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Key = append(m.Key[:0], dAtA[iNdEx:postIndex]...)
+			if m.Key == nil {
+				m.Key = []byte{}
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipTxnpb(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthTxnpb
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthTxnpb
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *LockWaitTimeoutError) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowTxnpb
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: LockWaitTimeoutError: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: LockWaitTimeoutError: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Key", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTxnpb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthTxnpb
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthTxnpb
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Key = append(m.Key[:0], dAtA[iNdEx:postIndex]...)
+			if m.Key == nil {
+				m.Key = []byte{}
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipTxnpb(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthTxnpb
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthTxnpb
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *WaitForEdge) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowTxnpb
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: WaitForEdge: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: WaitForEdge: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field TxnID", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTxnpb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthTxnpb
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthTxnpb
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.TxnID = append(m.TxnID[:0], dAtA[iNdEx:postIndex]...)
+			if m.TxnID == nil {
+				m.TxnID = []byte{}
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field WaitForTxnID", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTxnpb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthTxnpb
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthTxnpb
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.WaitForTxnID = append(m.WaitForTxnID[:0], dAtA[iNdEx:postIndex]...)
+			if m.WaitForTxnID == nil {
+				m.WaitForTxnID = []byte{}
+			}
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Key", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTxnpb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthTxnpb
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthTxnpb
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Key = append(m.Key[:0], dAtA[iNdEx:postIndex]...)
+			if m.Key == nil {
+				m.Key = []byte{}
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipTxnpb(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthTxnpb
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthTxnpb
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
 func skipTxnpb(dAtA []byte) (n int, err error) {
 	l := len(dAtA)
 	iNdEx := 0
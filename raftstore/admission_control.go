@@ -0,0 +1,71 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package raftstore
+
+import (
+	"sync/atomic"
+
+	"github.com/matrixorigin/matrixcube/config"
+	"github.com/matrixorigin/matrixcube/pb/rpcpb"
+)
+
+// The reasons of the write requests rejected by the admission control.
+const (
+	admissionInflightExceeded   = "inflight"
+	admissionAppliedLagExceeded = "applied-lag"
+)
+
+// replicaLoad the load of the replica checked by the admission control, it's
+// updated in the event worker and read by the store.
+type replicaLoad struct {
+	// proposing the number of the proposals waiting to be applied
+	proposing int64
+	// appliedLag the number of the committed log entries not applied yet
+	appliedLag int64
+}
+
+func (l *replicaLoad) update(proposing, appliedLag int64) {
+	atomic.StoreInt64(&l.proposing, proposing)
+	atomic.StoreInt64(&l.appliedLag, appliedLag)
+}
+
+// admissionController rejects the write requests to the overloaded shards
+// before they are queued in the leader replicas, so the queues don't grow
+// unbounded under the write bursts. The rejected requests are retried by the
+// proxies.
+type admissionController struct {
+	cfg config.AdmissionConfig
+}
+
+func newAdmissionController(cfg config.AdmissionConfig) *admissionController {
+	return &admissionController{cfg: cfg}
+}
+
+// admit returns the reason why the request to the replica is rejected, empty
+// if it's admitted. Only the write requests to the leader are checked, the
+// followers reject the write requests with the NotLeader error anyway.
+func (ac *admissionController) admit(pr *replica, req rpcpb.Request) string {
+	if ac.cfg.Disable || req.Type != rpcpb.Write || !pr.isLeader() {
+		return ""
+	}
+
+	inflight := uint64(pr.requests.Len()) + uint64(atomic.LoadInt64(&pr.load.proposing))
+	if inflight >= ac.cfg.MaxInflightRequests {
+		return admissionInflightExceeded
+	}
+	if uint64(atomic.LoadInt64(&pr.load.appliedLag)) >= ac.cfg.MaxAppliedLag {
+		return admissionAppliedLagExceeded
+	}
+	return ""
+}
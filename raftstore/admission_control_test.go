@@ -0,0 +1,73 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package raftstore
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/matrixorigin/matrixcube/config"
+	"github.com/matrixorigin/matrixcube/pb/errorpb"
+	"github.com/matrixorigin/matrixcube/pb/rpcpb"
+	"github.com/matrixorigin/matrixcube/util/leaktest"
+	"github.com/matrixorigin/matrixcube/util/task"
+)
+
+func TestAdmissionControllerAdmit(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ac := newAdmissionController(config.AdmissionConfig{MaxInflightRequests: 3, MaxAppliedLag: 10})
+	pr := &replica{replicaID: 1, leaderID: 1, requests: task.New(32)}
+	write := rpcpb.Request{Type: rpcpb.Write}
+	assert.Empty(t, ac.admit(pr, write))
+
+	require.NoError(t, pr.requests.Put(newReqCtx(write, nil)))
+	pr.load.update(2, 0)
+	assert.Equal(t, admissionInflightExceeded, ac.admit(pr, write))
+	assert.Empty(t, ac.admit(pr, rpcpb.Request{Type: rpcpb.Read}))
+
+	pr.load.update(0, 10)
+	assert.Equal(t, admissionAppliedLagExceeded, ac.admit(pr, write))
+
+	// the followers are not checked
+	pr.leaderID = 2
+	assert.Empty(t, ac.admit(pr, write))
+
+	pr.leaderID = 1
+	ac.cfg.Disable = true
+	assert.Empty(t, ac.admit(pr, write))
+}
+
+func TestOnRequestRejectedByAdmission(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	s, cancel := newTestStore(t)
+	defer cancel()
+
+	s.admission = newAdmissionController(config.AdmissionConfig{MaxInflightRequests: 1, MaxAppliedLag: 1})
+	pr := &replica{shardID: 1, replicaID: 1, leaderID: 1, requests: task.New(32), sm: &stateMachine{}}
+	pr.load.update(1, 0)
+	s.addReplica(pr)
+
+	var rsp rpcpb.ResponseBatch
+	require.NoError(t, s.OnRequestWithCB(rpcpb.Request{ID: []byte("r1"), ToShard: 1, Type: rpcpb.Write},
+		func(r rpcpb.ResponseBatch) { rsp = r }))
+	require.NotNil(t, rsp.Header.Error.ServerIsBusy)
+	assert.Equal(t, uint64(1), rsp.Header.Error.ServerIsBusy.ShardID)
+	assert.Equal(t, admissionInflightExceeded, rsp.Header.Error.ServerIsBusy.Reason)
+	assert.True(t, errorpb.Retryable(rsp.Header.Error))
+	assert.Equal(t, []byte("r1"), rsp.Responses[0].ID)
+}
@@ -15,6 +15,7 @@ package raftstore
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/matrixorigin/matrixcube/components/log"
 	"github.com/matrixorigin/matrixcube/pb/errorpb"
@@ -34,6 +35,14 @@ type batch struct {
 	cb           func(rpcpb.ResponseBatch)
 	tp           int // request type of this batch
 	byteSize     int // bytes of this batch
+	// index is the log index of the proposal, proposedAt and committedAt are
+	// used to observe the durations of the proposal stages
+	index       uint64
+	proposedAt  time.Time
+	committedAt time.Time
+	// deadline is the time the admin request must be completed by, zero means
+	// no deadline
+	deadline time.Time
 }
 
 func newBatch(logger *zap.Logger, requestBatch rpcpb.RequestBatch, cb func(rpcpb.ResponseBatch), tp int, byteSize int) batch {
@@ -62,11 +71,25 @@ func (c *batch) isFull(n, max int) bool {
 }
 
 func (c *batch) canBatches(req rpcpb.Request) bool {
+	// the requests of a batch are acknowledged at the same durability level
+	if c.requestBatch.Requests[0].Durability != req.Durability {
+		return false
+	}
 	return (c.requestBatch.Requests[0].IgnoreEpochCheck && req.IgnoreEpochCheck) || // batch IgnoreEpochCheck requests
 		(epochMatch(c.requestBatch.Requests[0].Epoch, req.Epoch) && // batch epoch match requests
 			!c.requestBatch.Requests[0].IgnoreEpochCheck && !req.IgnoreEpochCheck)
 }
 
+// waitAllApplied returns true if the response of the write batch is delayed
+// until the batch is applied by all the replicas.
+func (c *batch) waitAllApplied(resp rpcpb.ResponseBatch) bool {
+	return c.cb != nil &&
+		resp.Header.IsEmpty() &&
+		len(c.requestBatch.Requests) > 0 &&
+		!c.requestBatch.IsAdmin() &&
+		c.requestBatch.Requests[0].Durability == rpcpb.AllReplicasApplied
+}
+
 func (c *batch) resp(resp rpcpb.ResponseBatch) {
 	if c.cb != nil {
 		if len(c.requestBatch.Requests) > 0 {
@@ -136,6 +159,55 @@ func (c *batch) respLargeRaftEntrySize(shardID uint64, size uint64) {
 	c.resp(rsp)
 }
 
+// isExpired returns true if the deadline of the batch passed
+func (c *batch) isExpired(now time.Time) bool {
+	return !c.deadline.IsZero() && now.After(c.deadline)
+}
+
+// isRequestExpired returns true if the deadline of the request passed at the
+// specified time
+func isRequestExpired(req rpcpb.Request, at time.Time) bool {
+	return req.Deadline > 0 && at.UnixNano() > req.Deadline
+}
+
+// setRequestTimeout sets the timeout of the request sent to the store at the
+// specified time from its deadline, it returns false if the deadline passed.
+func setRequestTimeout(req *rpcpb.Request, at time.Time) bool {
+	if req.Deadline == 0 {
+		req.Timeout = 0
+		return true
+	}
+	req.Timeout = req.Deadline - at.UnixNano()
+	return req.Timeout > 0
+}
+
+// resetRequestDeadline resets the deadline of the request received at the
+// specified time by its timeout, the deadline set by the other stores is on
+// their clocks.
+func resetRequestDeadline(req *rpcpb.Request, at time.Time) {
+	req.Deadline = 0
+	if req.Timeout > 0 {
+		req.Deadline = at.UnixNano() + req.Timeout
+	}
+}
+
+func (c *batch) respRequestTimeout(shardID uint64) {
+	rsp := errorPbResp(c.getRequestID(), errorpb.Error{
+		Message:        ErrTimeout.Error(),
+		RequestTimeout: &errorpb.RequestTimeout{ShardID: shardID},
+	})
+	c.resp(rsp)
+}
+
+func (c *batch) respAdminTimeout(shardID uint64) {
+	adminType := uint64(c.requestBatch.GetAdminCmdType())
+	rsp := errorPbResp(c.getRequestID(), errorpb.Error{
+		Message:      NewAdminTimeoutErr(shardID, adminType).Error(),
+		AdminTimeout: &errorpb.AdminTimeout{ShardID: shardID, AdminType: adminType},
+	})
+	c.resp(rsp)
+}
+
 func (c *batch) respOtherError(err error) {
 	rsp := errorOtherCMDResp(err)
 	c.resp(rsp)
@@ -183,6 +255,97 @@ func respShardUnavailable(id uint64, req rpcpb.Request, cb func(responseBatch rp
 	cb(rsp)
 }
 
+func respGroupStopped(group uint64, req rpcpb.Request, cb func(responseBatch rpcpb.ResponseBatch)) {
+	rsp := errorPbResp(uuid.NewV4().Bytes(), errorpb.Error{
+		Message:      NewGroupStoppedErr(group).Error(),
+		GroupStopped: &errorpb.GroupStopped{Group: group},
+	})
+	resp := rpcpb.Response{
+		ID:  req.ID,
+		PID: req.PID,
+	}
+	rsp.Responses = append(rsp.Responses, resp)
+	cb(rsp)
+}
+
+func respGroupMigrated(group uint64, req rpcpb.Request, cb func(responseBatch rpcpb.ResponseBatch)) {
+	rsp := errorPbResp(uuid.NewV4().Bytes(), errorpb.Error{
+		Message:       NewGroupMigratedErr(group).Error(),
+		GroupMigrated: &errorpb.GroupMigrated{Group: group},
+	})
+	resp := rpcpb.Response{
+		ID:  req.ID,
+		PID: req.PID,
+	}
+	rsp.Responses = append(rsp.Responses, resp)
+	cb(rsp)
+}
+
+func respServerIsBusy(id uint64, reason string, req rpcpb.Request, cb func(responseBatch rpcpb.ResponseBatch)) {
+	rsp := errorPbResp(uuid.NewV4().Bytes(), errorpb.Error{
+		Message:      fmt.Sprintf("shard %d is busy, %s exceeded", id, reason),
+		ServerIsBusy: &errorpb.ServerIsBusy{ShardID: id, Reason: reason},
+	})
+	resp := rpcpb.Response{
+		ID:  req.ID,
+		PID: req.PID,
+	}
+	rsp.Responses = append(rsp.Responses, resp)
+	cb(rsp)
+}
+
+func respShardRecovering(id uint64, estimated time.Duration, req rpcpb.Request, cb func(responseBatch rpcpb.ResponseBatch)) {
+	rsp := errorPbResp(uuid.NewV4().Bytes(), errorpb.Error{
+		Message: fmt.Sprintf("shard %d is recovering from snapshot", id),
+		ShardRecovering: &errorpb.ShardRecovering{
+			ShardID:         id,
+			EstimatedMillis: uint64(estimated.Milliseconds()),
+		},
+	})
+	resp := rpcpb.Response{
+		ID:  req.ID,
+		PID: req.PID,
+	}
+	rsp.Responses = append(rsp.Responses, resp)
+	cb(rsp)
+}
+
+func respReadSnapshotNotFound(id uint64, name string, req rpcpb.Request, cb func(responseBatch rpcpb.ResponseBatch)) {
+	rsp := errorPbResp(uuid.NewV4().Bytes(), errorpb.Error{
+		Message:              NewReadSnapshotNotFoundErr(id, name).Error(),
+		ReadSnapshotNotFound: &errorpb.ReadSnapshotNotFound{ShardID: id, Name: name},
+	})
+	resp := rpcpb.Response{
+		ID:  req.ID,
+		PID: req.PID,
+	}
+	rsp.Responses = append(rsp.Responses, resp)
+	cb(rsp)
+}
+
+func respResponseTooLarge(id uint64, maxResponseBytes uint64, req rpcpb.Request, cb func(responseBatch rpcpb.ResponseBatch)) {
+	rsp := errorPbResp(uuid.NewV4().Bytes(), errorpb.Error{
+		Message:          NewResponseTooLargeErr(id, maxResponseBytes).Error(),
+		ResponseTooLarge: &errorpb.ResponseTooLarge{ShardID: id, MaxResponseBytes: maxResponseBytes},
+	})
+	resp := rpcpb.Response{
+		ID:  req.ID,
+		PID: req.PID,
+	}
+	rsp.Responses = append(rsp.Responses, resp)
+	cb(rsp)
+}
+
+func respAccessDenied(err *errorpb.Error, req rpcpb.Request, cb func(responseBatch rpcpb.ResponseBatch)) {
+	rsp := errorPbResp(uuid.NewV4().Bytes(), *err)
+	resp := rpcpb.Response{
+		ID:  req.ID,
+		PID: req.PID,
+	}
+	rsp.Responses = append(rsp.Responses, resp)
+	cb(rsp)
+}
+
 func epochMatch(e1, e2 metapb.ShardEpoch) bool {
 	return e1.ConfigVer == e2.ConfigVer && e1.Generation == e2.Generation
 }
@@ -196,3 +196,16 @@ func newTestAdminRequestBatch(id string, pid int64, cmdType rpcpb.AdminCmdType,
 }
 
 // TODO: add more tests for cmd.go
+
+func TestCanAppendCmdWithDurability(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	cmd := &batch{
+		requestBatch: rpcpb.RequestBatch{
+			Requests: []rpcpb.Request{{IgnoreEpochCheck: true}},
+		},
+	}
+	assert.True(t, cmd.canBatches(rpcpb.Request{IgnoreEpochCheck: true}))
+	assert.False(t, cmd.canBatches(rpcpb.Request{IgnoreEpochCheck: true,
+		Durability: rpcpb.AllReplicasApplied}))
+}
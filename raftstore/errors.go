@@ -58,6 +58,141 @@ func IsShardUnavailableErr(err error) bool {
 	return ok
 }
 
+// GroupStoppedErr is an error indicates the shard group is stopped on the store
+type GroupStoppedErr struct {
+	err string
+}
+
+// NewGroupStoppedErr returns a wrapped error that the shard group is stopped
+func NewGroupStoppedErr(group uint64) error {
+	return GroupStoppedErr{err: fmt.Sprintf("shard group %d is stopped", group)}
+}
+
+// String implements error interface
+func (err GroupStoppedErr) Error() string {
+	return err.err
+}
+
+// IsGroupStoppedErr checks if an error is GroupStoppedErr
+func IsGroupStoppedErr(err error) bool {
+	_, ok := err.(GroupStoppedErr)
+	return ok
+}
+
+// GroupMigratedErr is an error indicates the shard group is migrated to another
+// cluster, see `ShardsProxy.SetGroupMigration`
+type GroupMigratedErr struct {
+	err string
+}
+
+// NewGroupMigratedErr returns a wrapped error that the shard group is migrated
+func NewGroupMigratedErr(group uint64) error {
+	return GroupMigratedErr{err: fmt.Sprintf("shard group %d is migrated", group)}
+}
+
+// String implements error interface
+func (err GroupMigratedErr) Error() string {
+	return err.err
+}
+
+// IsGroupMigratedErr checks if an error is GroupMigratedErr
+func IsGroupMigratedErr(err error) bool {
+	_, ok := err.(GroupMigratedErr)
+	return ok
+}
+
+// ReadSnapshotNotFoundErr is an error indicates the read snapshot of the read
+// request is not found on the shard replica
+type ReadSnapshotNotFoundErr struct {
+	err string
+}
+
+// NewReadSnapshotNotFoundErr returns a wrapped error that the read snapshot is not found
+func NewReadSnapshotNotFoundErr(id uint64, name string) error {
+	return ReadSnapshotNotFoundErr{err: fmt.Sprintf("read snapshot %s of shard %d not found", name, id)}
+}
+
+// String implements error interface
+func (err ReadSnapshotNotFoundErr) Error() string {
+	return err.err
+}
+
+// IsReadSnapshotNotFoundErr checks if an error is ReadSnapshotNotFoundErr
+func IsReadSnapshotNotFoundErr(err error) bool {
+	_, ok := err.(ReadSnapshotNotFoundErr)
+	return ok
+}
+
+// ResponseTooLargeErr is an error indicates the result of the read request
+// exceeds the max response bytes and is not truncated by the storage executor
+type ResponseTooLargeErr struct {
+	err string
+}
+
+// NewResponseTooLargeErr returns a wrapped error that the response is too large
+func NewResponseTooLargeErr(id uint64, maxResponseBytes uint64) error {
+	return ResponseTooLargeErr{err: fmt.Sprintf("response of shard %d exceeds the max response bytes %d",
+		id, maxResponseBytes)}
+}
+
+// String implements error interface
+func (err ResponseTooLargeErr) Error() string {
+	return err.err
+}
+
+// IsResponseTooLargeErr checks if an error is ResponseTooLargeErr
+func IsResponseTooLargeErr(err error) bool {
+	_, ok := err.(ResponseTooLargeErr)
+	return ok
+}
+
+// AccessDeniedErr is an error indicates the request is rejected by the ACL of
+// the shard
+type AccessDeniedErr struct {
+	err string
+}
+
+// NewAccessDeniedErr returns a wrapped error that the request of the tenant is
+// rejected by the ACL of the shard
+func NewAccessDeniedErr(id uint64, tenant string) error {
+	return AccessDeniedErr{err: fmt.Sprintf("access of tenant %q to shard %d denied", tenant, id)}
+}
+
+// String implements error interface
+func (err AccessDeniedErr) Error() string {
+	return err.err
+}
+
+// IsAccessDeniedErr checks if an error is AccessDeniedErr
+func IsAccessDeniedErr(err error) bool {
+	_, ok := err.(AccessDeniedErr)
+	return ok
+}
+
+// AdminTimeoutErr is an error indicates the admin request is not completed
+// before its deadline, the admin request may still take effect later
+type AdminTimeoutErr struct {
+	err string
+}
+
+// NewAdminTimeoutErr returns a wrapped error that the admin request of the
+// shard timed out
+func NewAdminTimeoutErr(id uint64, adminType uint64) error {
+	return AdminTimeoutErr{err: fmt.Sprintf("admin request %s of shard %d timed out",
+		rpcpb.AdminCmdType(adminType).String(), id)}
+}
+
+// String implements error interface
+func (err AdminTimeoutErr) Error() string {
+	return err.err
+}
+
+// IsAdminTimeoutErr checks if an error is AdminTimeoutErr
+func IsAdminTimeoutErr(err error) bool {
+	_, ok := err.(AdminTimeoutErr)
+	return ok
+}
+
 func buildID(id []byte, resp *rpcpb.ResponseBatch) {
 	if resp.Header.IsEmpty() {
 		return
@@ -18,6 +18,7 @@ import (
 
 	"github.com/matrixorigin/matrixcube/util/buf"
 
+	"github.com/matrixorigin/matrixcube/pb/metapb"
 	"github.com/matrixorigin/matrixcube/pb/rpcpb"
 	"github.com/matrixorigin/matrixcube/storage"
 )
@@ -30,13 +31,17 @@ import (
 // writeContext, another optimization is to have it for each worker
 // rather than one for each state machine.
 type writeContext struct {
-	shard        Shard
-	wb           storage.Resetable
-	buf          *buf.ByteBuf
-	batch        storage.Batch
-	responses    [][]byte
+	shard     Shard
+	wb        storage.Resetable
+	buf       *buf.ByteBuf
+	batch     storage.Batch
+	responses [][]byte
+	// ops the number of the storage requests of each request of the batch, the
+	// batch write request is executed as one storage request per write op.
+	ops          []int
 	writtenBytes uint64
 	diffBytes    int64
+	diffKeys     int64
 }
 
 var _ storage.WriteContext = (*writeContext)(nil)
@@ -84,28 +89,55 @@ func (ctx *writeContext) SetDiffBytes(value int64) {
 	ctx.diffBytes = value
 }
 
+func (ctx *writeContext) SetDiffKeys(value int64) {
+	ctx.diffKeys = value
+}
+
 func (ctx *writeContext) initialize(shard Shard, index uint64, batch rpcpb.RequestBatch) {
 	ctx.buf.Clear()
 	ctx.shard = shard
-	ctx.batch = storage.Batch{Index: index}
+	ctx.batch = storage.Batch{Index: index, Timestamp: batch.Header.Timestamp}
 	ctx.responses = ctx.responses[:0]
+	ctx.ops = ctx.ops[:0]
 	ctx.writtenBytes = 0
 	ctx.diffBytes = 0
+	ctx.diffKeys = 0
 
 	for _, r := range batch.Requests {
+		// the requests rejected by the ACL of the shard are not executed
+		if checkShardACL(shard, r, metapb.OperationClass_WriteOperation) != nil {
+			ctx.ops = append(ctx.ops, 0)
+			continue
+		}
+		if len(r.WriteOps) > 0 {
+			for _, op := range r.WriteOps {
+				ctx.batch.Requests = append(ctx.batch.Requests, storage.Request{
+					CmdType: op.CustomType,
+					Key:     op.Key,
+					Cmd:     op.Cmd,
+				})
+			}
+			ctx.ops = append(ctx.ops, len(r.WriteOps))
+			continue
+		}
+
 		ctx.batch.Requests = append(ctx.batch.Requests, storage.Request{
 			CmdType: r.CustomType,
 			Key:     r.Key,
 			Cmd:     r.Cmd,
 		})
+		ctx.ops = append(ctx.ops, 1)
 	}
 }
 
 type readContext struct {
-	shard     Shard
-	buf       *buf.ByteBuf
-	request   storage.Request
-	readBytes uint64
+	shard           Shard
+	buf             *buf.ByteBuf
+	request         storage.Request
+	readBytes       uint64
+	zeroCopy        bool
+	readValue       *storage.ReadValue
+	continuationKey []byte
 }
 
 var _ storage.ReadContext = (*readContext)(nil)
@@ -148,9 +180,41 @@ func (ctx *readContext) SetReadBytes(value uint64) {
 	ctx.readBytes = value
 }
 
-func (ctx *readContext) reset(shard Shard, req storage.Request) {
+func (ctx *readContext) ZeroCopy() bool {
+	return ctx.zeroCopy
+}
+
+func (ctx *readContext) SetReadValue(value *storage.ReadValue) {
+	if !ctx.zeroCopy {
+		panic("BUG: set read value without zero copy")
+	}
+	ctx.readValue = value
+}
+
+// View returns nil, the data storage resolves the view of the read snapshot
+// of the request.
+func (ctx *readContext) View() storage.View {
+	return nil
+}
+
+func (ctx *readContext) SetContinuationKey(key []byte) {
+	ctx.continuationKey = key
+}
+
+// takeReadValue returns the read value and transfers its reference to the
+// caller.
+func (ctx *readContext) takeReadValue() *storage.ReadValue {
+	v := ctx.readValue
+	ctx.readValue = nil
+	return v
+}
+
+func (ctx *readContext) reset(shard Shard, req storage.Request, zeroCopy bool) {
 	ctx.shard = shard
 	ctx.request = req
 	ctx.buf.Clear()
 	ctx.readBytes = 0
+	ctx.zeroCopy = zeroCopy
+	ctx.readValue = nil
+	ctx.continuationKey = nil
 }
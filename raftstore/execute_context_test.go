@@ -63,6 +63,31 @@ func TestWriteContextCanBeInitialized(t *testing.T) {
 	}
 }
 
+func TestWriteContextWithWriteOps(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	fs := vfs.GetTestFS()
+	defer vfs.ReportLeakedFD(fs, t)
+	base := kv.NewBaseStorage(mem.NewStorage(), fs)
+	defer base.Close()
+	ctx := newWriteContext(base)
+
+	requests := newTestRPCRequests(2)
+	requests[1].WriteOps = []rpcpb.WriteOp{
+		{CustomType: 1, Key: []byte("k1"), Cmd: []byte("v1")},
+		{CustomType: 2, Key: []byte("k2"), Cmd: []byte("v2")},
+	}
+	ctx.initialize(Shard{ID: 1}, 0, rpcpb.RequestBatch{Requests: requests})
+	assert.Equal(t, []int{1, 2}, ctx.ops)
+	assert.Equal(t, 3, len(ctx.batch.Requests))
+	assert.Equal(t, requests[0].Key, ctx.batch.Requests[0].Key)
+	for idx, op := range requests[1].WriteOps {
+		assert.Equal(t, op.CustomType, ctx.batch.Requests[idx+1].CmdType)
+		assert.Equal(t, op.Key, ctx.batch.Requests[idx+1].Key)
+		assert.Equal(t, op.Cmd, ctx.batch.Requests[idx+1].Cmd)
+	}
+}
+
 func newTestRPCRequests(n uint64) []rpcpb.Request {
 	var requests []rpcpb.Request
 	for i := uint64(0); i < n; i++ {
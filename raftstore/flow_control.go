@@ -0,0 +1,195 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package raftstore
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/matrixorigin/matrixcube/pb/rpcpb"
+)
+
+const (
+	// creditsStaleIntervals the number of the credits heartbeat intervals after
+	// which the credits of a shard are stale
+	creditsStaleIntervals = 3
+)
+
+type pacedRequest struct {
+	req rpcpb.Request
+	to  string
+}
+
+type shardFlow struct {
+	credits      int64
+	advertisedAt time.Time
+	paced        []pacedRequest
+}
+
+// flowController paces the write requests dispatched by the proxy to each shard
+// by the credits advertised by the leader of the shard. Each dispatched request
+// consumes a credit, and the requests are queued once the credits run out until
+// the next advertisement. Only the busy shards are advertised, the shards without
+// credits advertised recently are not paced. The requests exceeding the max
+// paced requests of a shard are rejected with ErrTooManyPacedRequests.
+type flowController struct {
+	sync.Mutex
+
+	staleness time.Duration
+	maxPaced  int
+	shards    map[uint64]*shardFlow
+}
+
+func newFlowController(staleness time.Duration, maxPaced uint64) *flowController {
+	return &flowController{
+		staleness: staleness,
+		maxPaced:  int(maxPaced),
+		shards:    make(map[uint64]*shardFlow),
+	}
+}
+
+// acquire returns true if the request can be dispatched to the shard now,
+// otherwise the request is paced and returned by a later advertise or expire.
+// ErrTooManyPacedRequests is returned if the request can not be paced.
+func (fc *flowController) acquire(shardID uint64, req rpcpb.Request, to string, now time.Time) (bool, error) {
+	fc.Lock()
+	defer fc.Unlock()
+
+	f, ok := fc.shards[shardID]
+	if !ok {
+		return true, nil
+	}
+	if len(f.paced) == 0 && now.Sub(f.advertisedAt) > fc.staleness {
+		delete(fc.shards, shardID)
+		return true, nil
+	}
+	if len(f.paced) == 0 && f.credits > 0 {
+		f.credits--
+		return true, nil
+	}
+	if fc.maxPaced > 0 && len(f.paced) >= fc.maxPaced {
+		return false, ErrTooManyPacedRequests
+	}
+	f.paced = append(f.paced, pacedRequest{req: req, to: to})
+	return false, nil
+}
+
+// advertise updates the credits of the shards, and returns the paced requests
+// can be dispatched with the new credits.
+func (fc *flowController) advertise(credits []rpcpb.ShardCredits, now time.Time) []pacedRequest {
+	fc.Lock()
+	defer fc.Unlock()
+
+	var released []pacedRequest
+	for _, c := range credits {
+		f, ok := fc.shards[c.ShardID]
+		if !ok {
+			f = &shardFlow{}
+			fc.shards[c.ShardID] = f
+		}
+		f.credits = int64(c.Credits)
+		f.advertisedAt = now
+
+		n := len(f.paced)
+		if int64(n) > f.credits {
+			n = int(f.credits)
+		}
+		released = append(released, f.paced[:n]...)
+		f.paced = append(f.paced[:0], f.paced[n:]...)
+		f.credits -= int64(n)
+	}
+	return released
+}
+
+// expire stops pacing the shards without credits advertised recently, e.g. the
+// leader is moved to another store, and returns their paced requests.
+func (fc *flowController) expire(now time.Time) []pacedRequest {
+	fc.Lock()
+	defer fc.Unlock()
+
+	var released []pacedRequest
+	for id, f := range fc.shards {
+		if now.Sub(f.advertisedAt) > fc.staleness {
+			released = append(released, f.paced...)
+			delete(fc.shards, id)
+		}
+	}
+	return released
+}
+
+// dispose stops pacing all the shards, and returns all the paced requests.
+func (fc *flowController) dispose() []pacedRequest {
+	fc.Lock()
+	defer fc.Unlock()
+
+	var released []pacedRequest
+	for id, f := range fc.shards {
+		released = append(released, f.paced...)
+		delete(fc.shards, id)
+	}
+	return released
+}
+
+// replicaCredits the proposal credits of the replica, the credits are updated
+// in the event worker and advertised by the store.
+type replicaCredits struct {
+	max int64
+	// credits the latest credits of the replica, negative means the replica is
+	// not the leader.
+	credits int64
+	// busy the last advertised credits are less than the max, it's only accessed
+	// by the store.
+	busy bool
+}
+
+func newReplicaCredits(max uint64) *replicaCredits {
+	return &replicaCredits{max: int64(max), credits: -1}
+}
+
+// update updates the credits by the write requests queued, proposing and waiting
+// to be applied. The max credits are reduced if the writes of the replica are
+// stalled.
+func (c *replicaCredits) update(leader bool, inflight int64, stalled bool) {
+	if !leader {
+		atomic.StoreInt64(&c.credits, -1)
+		return
+	}
+
+	max := c.max
+	if stalled {
+		max = max / stalledCreditsDivisor
+	}
+	credits := max - inflight
+	if credits < 0 {
+		credits = 0
+	}
+	atomic.StoreInt64(&c.credits, credits)
+}
+
+// advertisement returns the credits need to be advertised. The credits of a busy
+// leader are always advertised, and the max credits are advertised once after the
+// replica is no longer busy or no longer the leader.
+func (c *replicaCredits) advertisement() (uint64, bool) {
+	credits := atomic.LoadInt64(&c.credits)
+	if credits >= 0 && credits < c.max {
+		c.busy = true
+		return uint64(credits), true
+	}
+	if c.busy {
+		c.busy = false
+		return uint64(c.max), true
+	}
+	return 0, false
+}
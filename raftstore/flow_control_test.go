@@ -0,0 +1,179 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package raftstore
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/matrixorigin/matrixcube/pb/rpcpb"
+	"github.com/matrixorigin/matrixcube/util/leaktest"
+)
+
+func TestFlowControllerPacesByCredits(t *testing.T) {
+	now := time.Now()
+	fc := newFlowController(time.Second, 0)
+	acquire := func(shardID uint64, id string, now time.Time) bool {
+		ok, err := fc.acquire(shardID, rpcpb.Request{ID: []byte(id)}, "s1", now)
+		require.NoError(t, err)
+		return ok
+	}
+
+	// no credits advertised
+	assert.True(t, acquire(1, "r1", now))
+
+	assert.Empty(t, fc.advertise([]rpcpb.ShardCredits{{ShardID: 1, Credits: 1}}, now))
+	assert.True(t, acquire(1, "r2", now))
+	assert.False(t, acquire(1, "r3", now))
+	assert.False(t, acquire(1, "r4", now))
+	// other shards are not paced
+	assert.True(t, acquire(2, "r5", now))
+
+	released := fc.advertise([]rpcpb.ShardCredits{{ShardID: 1, Credits: 1}}, now)
+	require.Equal(t, 1, len(released))
+	assert.Equal(t, []byte("r3"), released[0].req.ID)
+	assert.Equal(t, "s1", released[0].to)
+
+	// the paced requests are dispatched in order before the new requests
+	released = fc.advertise([]rpcpb.ShardCredits{{ShardID: 1, Credits: 2}}, now)
+	require.Equal(t, 1, len(released))
+	assert.Equal(t, []byte("r4"), released[0].req.ID)
+	assert.True(t, acquire(1, "r6", now))
+	assert.False(t, acquire(1, "r7", now))
+
+	assert.Empty(t, fc.expire(now))
+	released = fc.expire(now.Add(time.Second * 2))
+	require.Equal(t, 1, len(released))
+	assert.Equal(t, []byte("r7"), released[0].req.ID)
+	assert.True(t, acquire(1, "r8", now))
+}
+
+func TestFlowControllerStaleCredits(t *testing.T) {
+	now := time.Now()
+	fc := newFlowController(time.Second, 0)
+
+	fc.advertise([]rpcpb.ShardCredits{{ShardID: 1, Credits: 0}}, now)
+	ok, err := fc.acquire(1, rpcpb.Request{ID: []byte("r1")}, "s1", now)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	assert.Equal(t, 1, len(fc.dispose()))
+
+	fc.advertise([]rpcpb.ShardCredits{{ShardID: 1, Credits: 0}}, now)
+	ok, err = fc.acquire(1, rpcpb.Request{ID: []byte("r2")}, "s1", now.Add(time.Second*2))
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Empty(t, fc.shards)
+}
+
+func TestFlowControllerMaxPacedRequests(t *testing.T) {
+	now := time.Now()
+	fc := newFlowController(time.Second, 2)
+	fc.advertise([]rpcpb.ShardCredits{{ShardID: 1, Credits: 0}}, now)
+	for _, id := range []string{"r1", "r2"} {
+		ok, err := fc.acquire(1, rpcpb.Request{ID: []byte(id)}, "s1", now)
+		assert.NoError(t, err)
+		assert.False(t, ok)
+	}
+	_, err := fc.acquire(1, rpcpb.Request{ID: []byte("r3")}, "s1", now)
+	assert.Equal(t, ErrTooManyPacedRequests, err)
+
+	assert.Equal(t, 1, len(fc.advertise([]rpcpb.ShardCredits{{ShardID: 1, Credits: 1}}, now)))
+	ok, err := fc.acquire(1, rpcpb.Request{ID: []byte("r4")}, "s1", now)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestReplicaCreditsAdvertisement(t *testing.T) {
+	c := newReplicaCredits(10)
+	_, ok := c.advertisement()
+	assert.False(t, ok)
+
+	c.update(true, 0, false)
+	_, ok = c.advertisement()
+	assert.False(t, ok)
+
+	c.update(true, 4, false)
+	v, ok := c.advertisement()
+	assert.True(t, ok)
+	assert.Equal(t, uint64(6), v)
+
+	c.update(true, 20, false)
+	v, ok = c.advertisement()
+	assert.True(t, ok)
+	assert.Equal(t, uint64(0), v)
+
+	// not busy, advertised the max credits once
+	c.update(false, 20, false)
+	v, ok = c.advertisement()
+	assert.True(t, ok)
+	assert.Equal(t, uint64(10), v)
+	_, ok = c.advertisement()
+	assert.False(t, ok)
+}
+
+func TestReplicaCreditsStalled(t *testing.T) {
+	c := newReplicaCredits(80)
+	c.update(true, 4, true)
+	v, ok := c.advertisement()
+	assert.True(t, ok)
+	assert.Equal(t, uint64(80/stalledCreditsDivisor-4), v)
+
+	// the max credits are advertised once after the stall recovered
+	c.update(true, 0, false)
+	v, ok = c.advertisement()
+	assert.True(t, ok)
+	assert.Equal(t, uint64(80), v)
+}
+
+func TestProxyPacesWriteRequests(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	var dispatched []string
+	factory := newTestBackendFactory()
+	factory.backends["b1"] = newLocalBackend(func(r rpcpb.Request) error {
+		dispatched = append(dispatched, string(r.ID))
+		return nil
+	})
+	rr, err := newRouterBuilder().build(make(chan rpcpb.EventNotify))
+	assert.NoError(t, err)
+	var failed []string
+	sp, err := newShardsProxyBuilder().
+		withBackendFactory(factory).
+		withFlowControl(time.Minute, 1).
+		withRequestCallback(nil, func(id []byte, err error) {
+			failed = append(failed, string(id))
+		}).
+		build(rr)
+	assert.NoError(t, err)
+
+	shard := Shard{ID: 1}
+	sp.OnCredits([]rpcpb.ShardCredits{{ShardID: 1, Credits: 1}})
+	assert.NoError(t, sp.DispatchTo(rpcpb.Request{ID: []byte("w1"), Type: rpcpb.Write}, shard, "b1"))
+	assert.NoError(t, sp.DispatchTo(rpcpb.Request{ID: []byte("w2"), Type: rpcpb.Write}, shard, "b1"))
+	assert.NoError(t, sp.DispatchTo(rpcpb.Request{ID: []byte("r1"), Type: rpcpb.Read}, shard, "b1"))
+	assert.Equal(t, []string{"w1", "r1"}, dispatched)
+
+	// credits heartbeat of the remote store
+	sp.(*shardsProxy).done(rpcpb.Response{Credits: []rpcpb.ShardCredits{{ShardID: 1, Credits: 1}}})
+	assert.Equal(t, []string{"w1", "r1", "w2"}, dispatched)
+
+	assert.NoError(t, sp.DispatchTo(rpcpb.Request{ID: []byte("w3"), Type: rpcpb.Write}, shard, "b1"))
+	assert.Equal(t, ErrTooManyPacedRequests,
+		sp.DispatchTo(rpcpb.Request{ID: []byte("w4"), Type: rpcpb.Write}, shard, "b1"))
+	assert.NoError(t, sp.Stop())
+	assert.Equal(t, []string{"w3"}, failed)
+}
@@ -14,7 +14,9 @@
 package raftstore
 
 import (
+	"sort"
 	"sync"
+	"sync/atomic"
 
 	"github.com/matrixorigin/matrixcube/components/prophet/util"
 	"github.com/matrixorigin/matrixcube/pb/metapb"
@@ -22,12 +24,16 @@ import (
 
 type replicaGroupController struct {
 	sync.RWMutex
-	rules map[uint64][]metapb.ScheduleGroupRule
+	rules   map[uint64][]metapb.ScheduleGroupRule
+	stopped map[uint64]struct{}
+	// stoppedCount is used to skip the lock in the hot path if no groups stopped
+	stoppedCount int32
 }
 
 func newReplicaGroupController() *replicaGroupController {
 	return &replicaGroupController{
-		rules: make(map[uint64][]metapb.ScheduleGroupRule),
+		rules:   make(map[uint64][]metapb.ScheduleGroupRule),
+		stopped: make(map[uint64]struct{}),
 	}
 }
 
@@ -47,3 +53,54 @@ func (rc *replicaGroupController) getShardGroupKey(shard Shard) string {
 	defer rc.RUnlock()
 	return util.EncodeGroupKey(shard.Group, rc.rules[shard.Group], shard.Labels)
 }
+
+// stopGroup marks the group as stopped, returns false if it's already stopped.
+func (rc *replicaGroupController) stopGroup(group uint64) bool {
+	rc.Lock()
+	defer rc.Unlock()
+	if _, ok := rc.stopped[group]; ok {
+		return false
+	}
+	rc.stopped[group] = struct{}{}
+	atomic.StoreInt32(&rc.stoppedCount, int32(len(rc.stopped)))
+	return true
+}
+
+// startGroup marks the group as started, returns false if it's not stopped.
+func (rc *replicaGroupController) startGroup(group uint64) bool {
+	rc.Lock()
+	defer rc.Unlock()
+	if _, ok := rc.stopped[group]; !ok {
+		return false
+	}
+	delete(rc.stopped, group)
+	atomic.StoreInt32(&rc.stoppedCount, int32(len(rc.stopped)))
+	return true
+}
+
+func (rc *replicaGroupController) isGroupStopped(group uint64) bool {
+	if atomic.LoadInt32(&rc.stoppedCount) == 0 {
+		return false
+	}
+
+	rc.RLock()
+	defer rc.RUnlock()
+	_, ok := rc.stopped[group]
+	return ok
+}
+
+// getStoppedGroups returns the sorted stopped groups.
+func (rc *replicaGroupController) getStoppedGroups() []uint64 {
+	if atomic.LoadInt32(&rc.stoppedCount) == 0 {
+		return nil
+	}
+
+	rc.RLock()
+	defer rc.RUnlock()
+	groups := make([]uint64, 0, len(rc.stopped))
+	for g := range rc.stopped {
+		groups = append(groups, g)
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i] < groups[j] })
+	return groups
+}
@@ -47,3 +47,24 @@ func TestGetShardGroupKey(t *testing.T) {
 	shard := Shard{Labels: []metapb.Label{{Key: "l1", Value: "v1"}}}
 	assert.Equal(t, util.EncodeGroupKey(0, rules, shard.Labels), gc.getShardGroupKey(shard))
 }
+
+func TestStopAndStartGroupController(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	gc := newReplicaGroupController()
+	assert.False(t, gc.isGroupStopped(1))
+	assert.Empty(t, gc.getStoppedGroups())
+
+	assert.True(t, gc.stopGroup(2))
+	assert.True(t, gc.stopGroup(1))
+	assert.False(t, gc.stopGroup(1))
+	assert.True(t, gc.isGroupStopped(1))
+	assert.True(t, gc.isGroupStopped(2))
+	assert.False(t, gc.isGroupStopped(3))
+	assert.Equal(t, []uint64{1, 2}, gc.getStoppedGroups())
+
+	assert.True(t, gc.startGroup(1))
+	assert.False(t, gc.startGroup(1))
+	assert.False(t, gc.isGroupStopped(1))
+	assert.Equal(t, []uint64{2}, gc.getStoppedGroups())
+}
@@ -14,14 +14,27 @@
 package raftstore
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/fagongzi/util/protoc"
 	"github.com/matrixorigin/matrixcube/config"
+	"github.com/matrixorigin/matrixcube/metric"
 	"github.com/matrixorigin/matrixcube/pb/metapb"
 	"github.com/matrixorigin/matrixcube/pb/rpcpb"
+	"github.com/matrixorigin/matrixcube/storage"
+	"github.com/matrixorigin/matrixcube/storage/kv"
+	"github.com/matrixorigin/matrixcube/transport"
 	"github.com/matrixorigin/matrixcube/util/leaktest"
+	"github.com/matrixorigin/matrixcube/util/testutil"
 	"github.com/matrixorigin/matrixcube/util/uuid"
 	"github.com/stretchr/testify/assert"
 )
@@ -80,6 +93,49 @@ func TestSingleClusterStartAndStop(t *testing.T) {
 	c.CheckShardCount(1)
 }
 
+func TestStandaloneStartAndRestart(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping in short mode.")
+		return
+	}
+
+	defer leaktest.AfterTest(t)()
+
+	c := NewSingleTestClusterStore(t,
+		WithTestClusterUseDisk(),
+		WithAppendTestClusterAdjustConfigFunc(func(node int, cfg *config.Config) {
+			cfg.Standalone = true
+		}))
+	c.Start()
+	defer c.Stop()
+
+	c.WaitShardByCountPerNode(1, testWaitTimeout)
+	c.WaitLeadersByCount(1, testWaitTimeout)
+	c.CheckShardCount(1)
+
+	kv := c.CreateTestKVClient(0)
+	defer kv.Close()
+	assert.NoError(t, kv.Set("key", "value", testWaitTimeout))
+	id, err := c.GetProphet().GetClient().AllocID()
+	assert.NoError(t, err)
+
+	c.Restart()
+	c.WaitShardByCountPerNode(1, testWaitTimeout)
+	c.WaitLeadersByCount(1, testWaitTimeout)
+	c.CheckShardCount(1)
+
+	kv2 := c.CreateTestKVClient(0)
+	defer kv2.Close()
+	v, err := kv2.Get("key", testWaitTimeout)
+	assert.NoError(t, err)
+	assert.Equal(t, "value", v)
+
+	// the ids allocated before restart are never reused
+	newID, err := c.GetProphet().GetClient().AllocID()
+	assert.NoError(t, err)
+	assert.True(t, newID > id)
+}
+
 func TestClusterStartAndStop(t *testing.T) {
 	if testing.Short() {
 		t.Skip("skipping in short mode.")
@@ -96,6 +152,69 @@ func TestClusterStartAndStop(t *testing.T) {
 	c.CheckShardCount(1)
 }
 
+func TestClusterStopWithDrain(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping in short mode.")
+		return
+	}
+
+	defer leaktest.AfterTest(t)()
+
+	c := NewTestClusterStore(t,
+		WithAppendTestClusterAdjustConfigFunc(func(node int, cfg *config.Config) {
+			cfg.Raft.StopDrainTimeout.Duration = time.Second * 5
+		}))
+	c.Start()
+	defer c.Stop()
+
+	c.WaitShardByCountPerNode(1, testWaitTimeout)
+	c.WaitLeadersByCount(1, testWaitTimeout)
+	shard := c.GetShardByIndex(0, 0)
+	leader := -1
+	c.EveryStore(func(i int, store Store) {
+		if store == c.GetShardLeaderStore(shard.ID) {
+			leader = i
+		}
+	})
+	assert.True(t, leader >= 0)
+
+	// the leadership is transferred away before the store stopped
+	c.GetStore(leader).Stop()
+	assert.NotEqual(t, c.GetStore(leader), c.GetShardLeaderStore(shard.ID))
+}
+
+func TestDrainLeadership(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping in short mode.")
+		return
+	}
+
+	defer leaktest.AfterTest(t)()
+
+	c := NewTestClusterStore(t)
+	c.Start()
+	defer c.Stop()
+
+	c.WaitShardByCountPerNode(1, testWaitTimeout)
+	c.WaitLeadersByCount(1, testWaitTimeout)
+	shard := c.GetShardByIndex(0, 0)
+	c.WaitAllReplicasChangeToVoter(shard.ID, testWaitTimeout)
+	leader := c.GetShardLeaderStore(shard.ID)
+
+	ctx, cancel := context.WithTimeout(context.Background(), testWaitTimeout)
+	defer cancel()
+	assert.NoError(t, leader.DrainLeadership(ctx))
+	assert.False(t, leader.(*store).getReplica(shard.ID, false).isLeader())
+	assert.True(t, leader.(*store).isRejectingElections())
+
+	// the leadership is not taken back in the grace period
+	kv := c.CreateTestKVClient(0)
+	defer kv.Close()
+	assert.NoError(t, kv.Set("key", "value", testWaitTimeout))
+	time.Sleep(leader.GetConfig().Raft.GetElectionTimeoutDuration() * 2)
+	assert.False(t, leader.(*store).getReplica(shard.ID, false).isLeader())
+}
+
 func TestClusterWithInitClusterStartAndStop(t *testing.T) {
 	if testing.Short() {
 		t.Skip("skipping in short mode.")
@@ -116,6 +235,15 @@ func TestClusterWithInitClusterStartAndStop(t *testing.T) {
 	c.Restart()
 	c.WaitShardByCountPerNode(1, testWaitTimeout)
 	c.CheckShardCount(1)
+	c.EveryStore(func(i int, store Store) {
+		assert.Equal(t, StartupProgress{Loaded: 1, ToStart: 1, Started: 1, Ready: true},
+			store.GetStartupProgress())
+		report, ok := store.GetRecoveryReport()
+		assert.True(t, ok)
+		assert.Equal(t, uint64(1), report.Loaded)
+		assert.Equal(t, uint64(1), report.Started)
+		assert.Empty(t, report.Anomalies)
+	})
 }
 
 func TestClusterStartWithMoreNodes(t *testing.T) {
@@ -229,3 +357,686 @@ func TestAddShardLabel(t *testing.T) {
 
 	c.WaitShardByLabel(sid, "label1", "value1", testWaitTimeout)
 }
+
+func TestLeaseRead(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping in short mode.")
+		return
+	}
+
+	defer leaktest.AfterTest(t)()
+
+	c := NewSingleTestClusterStore(t,
+		WithAppendTestClusterAdjustConfigFunc(func(node int, cfg *config.Config) {
+			cfg.Raft.EnableLeaseRead = true
+		}))
+	c.Start()
+	defer c.Stop()
+
+	c.WaitShardByCountPerNode(1, testWaitTimeout)
+	c.WaitLeadersByCount(1, testWaitTimeout)
+
+	kv := c.CreateTestKVClient(0)
+	defer kv.Close()
+
+	assert.NoError(t, kv.Set("key", "value", testWaitTimeout))
+	// the first read establishes the lease, the following reads are served
+	// locally without ReadIndex.
+	v, err := kv.Get("key", testWaitTimeout)
+	assert.NoError(t, err)
+	assert.Equal(t, "value", v)
+	pr := c.GetStore(0).(*store).getReplica(c.GetShardByIndex(0, 0).ID, true)
+	assert.NotNil(t, pr)
+	assert.True(t, pr.lease.valid(time.Now()))
+
+	readIndexCount := pr.getReadIndexCount()
+	for i := 0; i < 3; i++ {
+		v, err := kv.Get("key", testWaitTimeout)
+		assert.NoError(t, err)
+		assert.Equal(t, "value", v)
+	}
+	assert.Equal(t, readIndexCount, pr.getReadIndexCount())
+
+	assert.NoError(t, kv.Set("key", "value2", testWaitTimeout))
+	v, err = kv.Get("key", testWaitTimeout)
+	assert.NoError(t, err)
+	assert.Equal(t, "value2", v)
+}
+
+func TestFlowControl(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping in short mode.")
+		return
+	}
+
+	defer leaktest.AfterTest(t)()
+
+	c := NewSingleTestClusterStore(t,
+		WithAppendTestClusterAdjustConfigFunc(func(node int, cfg *config.Config) {
+			cfg.Proxy.EnableFlowControl = true
+			cfg.Proxy.MaxShardCredits = 2
+			cfg.Proxy.CreditsHeartbeatInterval.Duration = time.Millisecond * 10
+		}))
+	c.Start()
+	defer c.Stop()
+
+	c.WaitShardByCountPerNode(1, testWaitTimeout)
+	c.WaitLeadersByCount(1, testWaitTimeout)
+
+	kv := c.CreateTestKVClient(0)
+	defer kv.Close()
+
+	// the writes exceeding the credits are paced by the proxy instead of failing
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			assert.NoError(t, kv.Set(fmt.Sprintf("k-%d", i), fmt.Sprintf("v-%d", i), testWaitTimeout))
+		}(i)
+	}
+	wg.Wait()
+
+	for i := 0; i < 20; i++ {
+		v, err := kv.Get(fmt.Sprintf("k-%d", i), testWaitTimeout)
+		assert.NoError(t, err)
+		assert.Equal(t, fmt.Sprintf("v-%d", i), v)
+	}
+	pr := c.GetStore(0).(*store).getReplica(c.GetShardByIndex(0, 0).ID, true)
+	assert.NotNil(t, pr)
+	assert.NotNil(t, pr.credits)
+}
+
+func TestChaosTransportEnabled(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping in short mode.")
+		return
+	}
+
+	defer leaktest.AfterTest(t)()
+
+	var filtered uint64
+	adminAddr := fmt.Sprintf("127.0.0.1:%d", testutil.GenTestPorts(1)[0])
+	c := NewTestClusterStore(t,
+		WithAppendTestClusterAdjustConfigFunc(func(node int, cfg *config.Config) {
+			cfg.Chaos.Enable = true
+			if node == 0 {
+				cfg.Chaos.AdminAddr = adminAddr
+			}
+		}))
+	c.Start()
+	defer c.Stop()
+
+	for i := 0; i < 3; i++ {
+		s := c.GetStore(i).(*store)
+		assert.NotNil(t, s.GetChaosController())
+		_, ok := s.trans.(*transport.ChaosTransport)
+		assert.True(t, ok)
+		// the filter is set to the transport wrapped by the chaos transport
+		s.trans.SetFilter(func(metapb.RaftMessage) bool {
+			atomic.AddUint64(&filtered, 1)
+			return false
+		})
+	}
+
+	c.WaitShardByCountPerNode(1, testWaitTimeout)
+	c.WaitLeadersByCount(1, testWaitTimeout)
+
+	kv := c.CreateTestKVClient(0)
+	defer kv.Close()
+	assert.NoError(t, kv.Set("key", "value", testWaitTimeout))
+
+	assert.True(t, atomic.LoadUint64(&filtered) > 0)
+
+	// drop all messages sent by node 0 using the admin API
+	url := "http://" + adminAddr + transport.ChaosOptionsPath
+	req, err := http.NewRequest(http.MethodPut, url, strings.NewReader(`{"drop-rate": 1}`))
+	assert.NoError(t, err)
+	resp, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.NoError(t, resp.Body.Close())
+
+	chaos := c.GetStore(0).GetChaosController()
+	assert.Equal(t, 1.0, chaos.Options().DropRate)
+	assert.Eventually(t, func() bool {
+		return chaos.Stats().Dropped > 0
+	}, testWaitTimeout, time.Millisecond*10)
+
+	assert.NoError(t, chaos.UpdateOptions(transport.ChaosOptions{}))
+	assert.NoError(t, kv.Set("key", "value2", testWaitTimeout))
+}
+
+func TestMetricServerEnabled(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping in short mode.")
+		return
+	}
+
+	defer leaktest.AfterTest(t)()
+
+	metricAddr := fmt.Sprintf("127.0.0.1:%d", testutil.GenTestPorts(1)[0])
+	c := NewSingleTestClusterStore(t,
+		WithAppendTestClusterAdjustConfigFunc(func(node int, cfg *config.Config) {
+			cfg.Metric.ListenAddr = metricAddr
+		}))
+	c.Start()
+	defer c.Stop()
+
+	c.WaitShardByCountPerNode(1, testWaitTimeout)
+	kv := c.CreateTestKVClient(0)
+	defer kv.Close()
+	assert.NoError(t, kv.Set("key", "value", testWaitTimeout))
+
+	resp, err := http.Get("http://" + metricAddr + metric.Path)
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	body, err := io.ReadAll(resp.Body)
+	assert.NoError(t, err)
+	assert.True(t, strings.Contains(string(body), "matrixcube_raftstore_raft_log_apply_duration_seconds"))
+}
+
+func TestFollowerRead(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping in short mode.")
+		return
+	}
+
+	defer leaktest.AfterTest(t)()
+
+	c := NewTestClusterStore(t)
+	c.Start()
+	defer c.Stop()
+
+	c.WaitShardByCountPerNode(1, testWaitTimeout)
+	c.WaitLeadersByCount(1, testWaitTimeout)
+
+	shard := c.GetShardByIndex(0, 0)
+	c.WaitAllReplicasChangeToVoter(shard.ID, testWaitTimeout)
+
+	var followers []*replica
+	for i := 0; i < 3; i++ {
+		pr := c.GetStore(i).(*store).getReplica(shard.ID, false)
+		assert.NotNil(t, pr)
+		if !pr.isLeader() {
+			followers = append(followers, pr)
+		}
+	}
+	assert.Equal(t, 2, len(followers))
+
+	kv := c.CreateTestKVClient(0)
+	defer kv.Close()
+	followerKV := c.CreateTestKVClientWithAdjust(1, func(req *rpcpb.Request) {
+		req.ReplicaSelectPolicy = rpcpb.SelectFollower
+	})
+	defer followerKV.Close()
+
+	// the follower reads must see the latest writes
+	for i := 0; i < 10; i++ {
+		value := fmt.Sprintf("value%d", i)
+		assert.NoError(t, kv.Set("key", value, testWaitTimeout))
+		v, err := followerKV.Get("key", testWaitTimeout)
+		assert.NoError(t, err)
+		assert.Equal(t, value, v)
+	}
+	assert.True(t, followers[0].getReadIndexCount()+followers[1].getReadIndexCount() >= 10)
+}
+
+func TestFollowerStaleRead(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping in short mode.")
+		return
+	}
+
+	defer leaktest.AfterTest(t)()
+
+	c := NewTestClusterStore(t)
+	c.Start()
+	defer c.Stop()
+
+	c.WaitShardByCountPerNode(1, testWaitTimeout)
+	c.WaitLeadersByCount(1, testWaitTimeout)
+
+	shard := c.GetShardByIndex(0, 0)
+	c.WaitAllReplicasChangeToVoter(shard.ID, testWaitTimeout)
+
+	kv := c.CreateTestKVClient(0)
+	defer kv.Close()
+	assert.NoError(t, kv.Set("key", "value", testWaitTimeout))
+	written := uint64(unixMilli(time.Now()))
+
+	var leader, follower *replica
+	followerNode := 0
+	for i := 0; i < 3; i++ {
+		pr := c.GetStore(i).(*store).getReplica(shard.ID, false)
+		assert.NotNil(t, pr)
+		if pr.isLeader() {
+			leader = pr
+		} else {
+			follower = pr
+			followerNode = i
+		}
+		// the idle leader keeps advancing the resolved ts of all replicas
+		assert.Eventually(t, func() bool {
+			return pr.resolvedTS.get() > written
+		}, testWaitTimeout, time.Millisecond*10)
+	}
+	assert.NotNil(t, leader)
+	assert.NotNil(t, follower)
+
+	// bounded staleness reads are served by any replica without ReadIndex
+	readIndexCount := leader.getReadIndexCount()
+	staleKV := c.CreateTestKVClientWithAdjust(followerNode, func(req *rpcpb.Request) {
+		req.ReplicaSelectPolicy = rpcpb.SelectRandom
+		req.MaxStaleness = uint64(time.Minute / time.Millisecond)
+	})
+	defer staleKV.Close()
+	for i := 0; i < 10; i++ {
+		v, err := staleKV.Get("key", testWaitTimeout)
+		assert.NoError(t, err)
+		assert.Equal(t, "value", v)
+	}
+	assert.Equal(t, readIndexCount, leader.getReadIndexCount())
+
+	// read from the follower directly
+	doneC := make(chan string, 1)
+	errorC := make(chan error, 1)
+	id := string(uuid.NewV4().Bytes())
+	staleKV.(*testKVClient).addContext(id, doneC, errorC)
+	defer staleKV.(*testKVClient).clearContext(id)
+	req := createTestReadReq(id, "key")
+	req.ToShard = shard.ID
+	req.Epoch = follower.getShard().Epoch
+	req.MaxStaleness = uint64(time.Minute / time.Millisecond)
+	assert.True(t, follower.tryStaleRead(req))
+	select {
+	case v := <-doneC:
+		assert.Equal(t, "value", v)
+	case err := <-errorC:
+		assert.NoError(t, err)
+	case <-time.After(testWaitTimeout):
+		assert.Fail(t, "stale read timeout")
+	}
+
+	// the resolved ts lags behind at least the max clock drift, falls back to
+	// the normal read path
+	req.MaxStaleness = 1
+	assert.False(t, follower.tryStaleRead(req))
+	assert.False(t, leader.tryStaleRead(req))
+	v, err := kv.Get("key", testWaitTimeout)
+	assert.NoError(t, err)
+	assert.Equal(t, "value", v)
+	assert.True(t, leader.getReadIndexCount() > readIndexCount)
+}
+
+func TestDiagnoseFollower(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping in short mode.")
+		return
+	}
+
+	defer leaktest.AfterTest(t)()
+
+	c := NewTestClusterStore(t)
+	c.Start()
+	defer c.Stop()
+
+	c.WaitShardByCountPerNode(1, testWaitTimeout)
+	c.WaitLeadersByCount(1, testWaitTimeout)
+
+	shard := c.GetShardByIndex(0, 0)
+	c.WaitAllReplicasChangeToVoter(shard.ID, testWaitTimeout)
+
+	kv := c.CreateTestKVClient(0)
+	defer kv.Close()
+	assert.NoError(t, kv.Set("key", "value", testWaitTimeout))
+
+	for i := 0; i < 3; i++ {
+		pr := c.GetStore(i).(*store).getReplica(shard.ID, false)
+		assert.NotNil(t, pr)
+		if pr.isLeader() {
+			continue
+		}
+
+		respC := make(chan rpcpb.ResponseBatch, 1)
+		req := rpcpb.Request{
+			ID:         uuid.NewV4().Bytes(),
+			ToShard:    shard.ID,
+			Type:       rpcpb.Admin,
+			CustomType: uint64(rpcpb.AdminDiagnose),
+			Cmd:        []byte(`{"log-entries": 2}`),
+		}
+		assert.True(t, pr.tryDiagnose(req, func(resp rpcpb.ResponseBatch) {
+			respC <- resp
+		}))
+
+		select {
+		case resp := <-respC:
+			assert.Empty(t, resp.Header.Error.Message)
+			assert.Equal(t, 1, len(resp.Responses))
+			var d ReplicaDiagnostic
+			assert.NoError(t, json.Unmarshal(resp.Responses[0].Value, &d))
+			assert.Equal(t, pr.replica, d.Replica)
+			assert.False(t, d.Leader)
+			assert.Equal(t, metapb.DurabilityPolicy_AllReplicas.String(), d.DurabilityPolicy)
+			assert.NotEmpty(t, d.RaftStatus)
+			assert.Empty(t, d.Errors)
+			assert.Equal(t, 2, len(d.LogEntries))
+			assert.Equal(t, d.LastIndex, d.LogEntries[1].Index)
+		case <-time.After(testWaitTimeout):
+			assert.Fail(t, "diagnose timeout")
+		}
+	}
+}
+
+func TestStopAndStartGroup(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	c := NewSingleTestClusterStore(t)
+	c.Start()
+	defer c.Stop()
+
+	c.WaitShardByCountPerNode(1, testWaitTimeout)
+	c.WaitLeadersByCount(1, testWaitTimeout)
+
+	kv := c.CreateTestKVClient(0)
+	defer kv.Close()
+	assert.NoError(t, kv.Set("key", "value", testWaitTimeout))
+
+	s := c.GetStore(0).(*store)
+	s.StopGroup(0)
+	err := kv.Set("key", "value2", testWaitTimeout)
+	assert.True(t, IsGroupStoppedErr(err), "%+v", err)
+	hb, err := s.getStoreHeartbeat(time.Now())
+	assert.NoError(t, err)
+	assert.Equal(t, []uint64{0}, hb.Stats.StoppedGroups)
+
+	s.StartGroup(0)
+	c.WaitLeadersByCount(1, testWaitTimeout)
+	assert.NoError(t, kv.Set("key", "value2", testWaitTimeout))
+	v, err := kv.Get("key", testWaitTimeout)
+	assert.NoError(t, err)
+	assert.Equal(t, "value2", v)
+	hb, err = s.getStoreHeartbeat(time.Now())
+	assert.NoError(t, err)
+	assert.Empty(t, hb.Stats.StoppedGroups)
+}
+
+func TestReadSnapshot(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping in short mode.")
+		return
+	}
+
+	defer leaktest.AfterTest(t)()
+
+	c := NewSingleTestClusterStore(t)
+	c.Start()
+	defer c.Stop()
+
+	c.WaitShardByCountPerNode(1, testWaitTimeout)
+	c.WaitLeadersByCount(1, testWaitTimeout)
+	sid := c.GetShardByIndex(0, 0).ID
+
+	// the callbacks of the proxy are owned by the last created client, so the
+	// read snapshot is set by the adjust function
+	readSnapshot := ""
+	kv := c.CreateTestKVClientWithAdjust(0, func(req *rpcpb.Request) {
+		req.ReadSnapshot = readSnapshot
+	})
+	defer kv.Close()
+	assert.NoError(t, kv.Set("key", "v1", testWaitTimeout))
+
+	doAdmin := func(cmdType rpcpb.AdminCmdType, cmd []byte) rpcpb.ResponseBatch {
+		for {
+			ch := make(chan rpcpb.ResponseBatch, 1)
+			c.GetStore(0).OnRequestWithCB(rpcpb.Request{
+				ID:         uuid.NewV4().Bytes(),
+				Type:       rpcpb.Admin,
+				CustomType: uint64(cmdType),
+				ToShard:    sid,
+				Epoch:      c.GetShardByIndex(0, 0).Epoch,
+				Cmd:        cmd,
+			}, func(resp rpcpb.ResponseBatch) {
+				ch <- resp
+			})
+			resp := <-ch
+			if resp.Header.IsEmpty() {
+				return resp
+			}
+		}
+	}
+
+	resp := doAdmin(rpcpb.AdminCreateReadSnapshot,
+		protoc.MustMarshal(&rpcpb.CreateReadSnapshotRequest{Name: "s1"}))
+	assert.Equal(t, rpcpb.AdminCreateReadSnapshot, resp.GetAdminCmdType())
+	assert.NoError(t, kv.Set("key", "v2", testWaitTimeout))
+
+	readSnapshot = "s1"
+	v, err := kv.Get("key", testWaitTimeout)
+	assert.NoError(t, err)
+	assert.Equal(t, "v1", v)
+	readSnapshot = ""
+	v, err = kv.Get("key", testWaitTimeout)
+	assert.NoError(t, err)
+	assert.Equal(t, "v2", v)
+
+	doAdmin(rpcpb.AdminReleaseReadSnapshot,
+		protoc.MustMarshal(&rpcpb.ReleaseReadSnapshotRequest{Name: "s1"}))
+	readSnapshot = "s1"
+	_, err = kv.Get("key", testWaitTimeout)
+	assert.True(t, IsReadSnapshotNotFoundErr(err))
+}
+
+func TestResponseAppliedIndex(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping in short mode.")
+		return
+	}
+
+	defer leaktest.AfterTest(t)()
+
+	c := NewSingleTestClusterStore(t)
+	c.Start()
+	defer c.Stop()
+
+	c.WaitShardByCountPerNode(1, testWaitTimeout)
+	c.WaitLeadersByCount(1, testWaitTimeout)
+	shard := c.GetShardByIndex(0, 0)
+
+	write := func(key, value string) rpcpb.Response {
+		req := createTestWriteReq(string(uuid.NewV4().Bytes()), key, value)
+		req.ToShard = shard.ID
+		req.Epoch = shard.Epoch
+		for {
+			ch := make(chan rpcpb.ResponseBatch, 1)
+			c.GetStore(0).OnRequestWithCB(req, func(resp rpcpb.ResponseBatch) {
+				ch <- resp
+			})
+			resp := <-ch
+			if resp.Header.IsEmpty() {
+				assert.Equal(t, 1, len(resp.Responses))
+				return resp.Responses[0]
+			}
+		}
+	}
+
+	w1 := write("key", "v1")
+	w2 := write("key", "v2")
+	assert.True(t, w1.AppliedIndex > 0)
+	assert.True(t, w2.AppliedIndex > w1.AppliedIndex)
+	assert.True(t, w2.AppliedTerm > 0)
+
+	// the read responses are returned by the shards proxy
+	ch := make(chan rpcpb.Response, 1)
+	errCh := make(chan error, 1)
+	c.GetStore(0).GetShardsProxy().SetCallback(func(resp rpcpb.Response) {
+		ch <- resp
+	}, func(requestID []byte, err error) {
+		errCh <- err
+	})
+	pr := c.GetStore(0).(*store).getReplica(shard.ID, false)
+	assert.NotNil(t, pr)
+	pr.execReadRequest(createTestReadReq(string(uuid.NewV4().Bytes()), "key"))
+	select {
+	case r := <-ch:
+		assert.Equal(t, []byte("v2"), r.Value)
+		assert.True(t, r.AppliedIndex >= w2.AppliedIndex)
+		_, term := pr.sm.getAppliedIndexTerm()
+		assert.Equal(t, term, r.AppliedTerm)
+	case <-time.After(testWaitTimeout):
+		assert.Fail(t, "read timeout")
+	}
+
+	// the result not truncated by the executor can not exceed the max response
+	// bytes
+	req := createTestReadReq(string(uuid.NewV4().Bytes()), "key")
+	req.MaxResponseBytes = 1
+	pr.execReadRequest(req)
+	select {
+	case <-ch:
+		assert.Fail(t, "the response exceeds the max response bytes")
+	case err := <-errCh:
+		assert.True(t, IsResponseTooLargeErr(err))
+	case <-time.After(testWaitTimeout):
+		assert.Fail(t, "read timeout")
+	}
+}
+
+func TestShardHeartbeatRaftWatermarks(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping in short mode.")
+		return
+	}
+
+	defer leaktest.AfterTest(t)()
+
+	c := NewSingleTestClusterStore(t)
+	c.Start()
+	defer c.Stop()
+
+	c.WaitShardByCountPerNode(1, testWaitTimeout)
+	c.WaitLeadersByCount(1, testWaitTimeout)
+	shard := c.GetShardByIndex(0, 0)
+	kv := c.CreateTestKVClient(0)
+	defer kv.Close()
+	assert.NoError(t, kv.Set("key", "value", testWaitTimeout))
+	pr := c.GetStore(0).(*store).getReplica(shard.ID, false)
+	assert.NotNil(t, pr)
+	appliedIndex, _ := pr.sm.getAppliedIndexTerm()
+
+	// the watermarks of the leader are reported to prophet by the heartbeats
+	bc := c.GetStore(0).Prophet().GetBasicCluster()
+	timeout := time.After(testWaitTimeout)
+	for {
+		bc.RLock()
+		res := bc.Shards.GetShard(shard.ID)
+		bc.RUnlock()
+		if res != nil && res.GetAppliedIndex() >= appliedIndex {
+			stats := res.GetStat()
+			assert.True(t, stats.Term > 0)
+			assert.True(t, stats.CommitIndex >= stats.AppliedIndex)
+			assert.True(t, stats.TruncatedIndex < stats.AppliedIndex)
+			assert.Equal(t, 1, len(stats.ReplicaMatchIndexes))
+			lag, ok := res.GetReplicationLag(shard.Replicas[0].ID)
+			assert.True(t, ok)
+			assert.Equal(t, uint64(0), lag)
+			return
+		}
+
+		select {
+		case <-timeout:
+			assert.FailNow(t, "timeout")
+		case <-time.After(time.Millisecond * 100):
+		}
+	}
+}
+
+func TestSyncPiggyback(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	c := NewSingleTestClusterStore(t,
+		DiskTestCluster,
+		WithTestClusterSyncPiggyback())
+	c.Start()
+	defer c.Stop()
+
+	c.WaitShardByCountPerNode(1, testWaitTimeout)
+	c.WaitLeadersByCount(1, testWaitTimeout)
+
+	kv := c.CreateTestKVClient(0)
+	defer kv.Close()
+	for i := 0; i < 10; i++ {
+		assert.NoError(t, kv.Set(fmt.Sprintf("k-%d", i), fmt.Sprintf("v-%d", i), testWaitTimeout))
+	}
+
+	// far less writes than the sample sync interval, the applied indexes are
+	// marked persistent by the fsync of the raft logs
+	shard := c.GetShardByIndex(0, 0)
+	ds := c.GetStore(0).DataStorageByShard(shard)
+	timeout := time.After(testWaitTimeout)
+	for {
+		index, err := ds.GetPersistentLogIndex(shard.ID)
+		assert.NoError(t, err)
+		if index > 0 {
+			break
+		}
+		select {
+		case <-timeout:
+			assert.FailNow(t, "persistent log index not advanced")
+		default:
+			time.Sleep(time.Millisecond * 10)
+		}
+	}
+
+	c.Restart()
+	c.WaitShardByCountPerNode(1, testWaitTimeout)
+	c.WaitLeadersByCount(1, testWaitTimeout)
+
+	kv2 := c.CreateTestKVClient(0)
+	defer kv2.Close()
+	for i := 0; i < 10; i++ {
+		v, err := kv2.Get(fmt.Sprintf("k-%d", i), testWaitTimeout)
+		assert.NoError(t, err)
+		assert.Equal(t, fmt.Sprintf("v-%d", i), v)
+	}
+}
+
+func TestWriteWithAllReplicasApplied(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping in short mode.")
+		return
+	}
+
+	defer leaktest.AfterTest(t)()
+
+	c := NewTestClusterStore(t)
+	c.Start()
+	defer c.Stop()
+
+	c.WaitShardByCountPerNode(1, testWaitTimeout)
+	c.WaitLeadersByCount(1, testWaitTimeout)
+
+	shard := c.GetShardByIndex(0, 0)
+	c.WaitAllReplicasChangeToVoter(shard.ID, testWaitTimeout)
+
+	client := c.CreateTestKVClientWithAdjust(0, func(req *rpcpb.Request) {
+		req.Durability = rpcpb.AllReplicasApplied
+	})
+	defer client.Close()
+
+	// the writes are visible on all the replicas once responded
+	for i := 0; i < 10; i++ {
+		key, value := fmt.Sprintf("k-%d", i), fmt.Sprintf("v-%d", i)
+		assert.NoError(t, client.Set(key, value, testWaitTimeout))
+		for node := 0; node < 3; node++ {
+			pr := c.GetStore(node).(*store).getReplica(shard.ID, false)
+			assert.NotNil(t, pr)
+			v, err := pr.sm.dataStorage.(storage.KVStorageWrapper).
+				GetKVStorage().Get(kv.EncodeDataKey([]byte(key), nil))
+			assert.NoError(t, err)
+			assert.Equal(t, value, string(v), "node %d", node)
+		}
+	}
+}
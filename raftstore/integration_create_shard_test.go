@@ -86,6 +86,60 @@ func TestAddShardWithMultiGroups(t *testing.T) {
 	fn(3)
 }
 
+func TestBulkBootstrapInitShards(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping in short mode.")
+		return
+	}
+
+	defer leaktest.AfterTest(t)()
+
+	n := 10
+	c := NewTestClusterStore(t,
+		WithAppendTestClusterAdjustConfigFunc(func(i int, cfg *config.Config) {
+			cfg.Replication.BulkBootstrapShards = 1
+			cfg.Customize.CustomInitShardsFactory = func() []Shard {
+				var shards []Shard
+				for i := 0; i < n; i++ {
+					shards = append(shards, Shard{
+						Start: []byte(fmt.Sprintf("%02d", i)),
+						End:   []byte(fmt.Sprintf("%02d", i+1)),
+					})
+				}
+				return shards
+			}
+		}))
+
+	c.Start()
+	defer c.Stop()
+	c.WaitShardByCountPerNode(n, testWaitTimeout)
+	c.WaitLeadersByCount(n, testWaitTimeout)
+
+	uniques := make(map[string]struct{})
+	for i := 0; i < n; i++ {
+		uniques[c.GetShardByIndex(0, i).Unique] = struct{}{}
+	}
+	for i := 0; i < n; i++ {
+		assert.Contains(t, uniques, fmt.Sprintf("init-shard-%d", i))
+	}
+
+	kv := c.CreateTestKVClient(0)
+	defer kv.Close()
+	assert.NoError(t, kv.Set("05", "value", testWaitTimeout))
+
+	// the persisted init shards are removed once created
+	for i := 0; i < 3; i++ {
+		timeout := time.After(testWaitTimeout)
+		for len(c.GetStore(i).(*store).mustLoadBulkInitShards()) > 0 {
+			select {
+			case <-timeout:
+				assert.FailNow(t, "timeout")
+			case <-time.After(10 * time.Millisecond):
+			}
+		}
+	}
+}
+
 func TestSpeedupAddShard(t *testing.T) {
 	if testing.Short() {
 		t.Skip("skipping in short mode.")
@@ -0,0 +1,82 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package raftstore
+
+import (
+	"testing"
+
+	"github.com/fagongzi/util/protoc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/matrixorigin/matrixcube/config"
+	"github.com/matrixorigin/matrixcube/pb/rpcpb"
+	"github.com/matrixorigin/matrixcube/util/leaktest"
+)
+
+func TestMergeWithMultiNodesCluster(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping in short mode.")
+		return
+	}
+
+	defer leaktest.AfterTest(t)()
+	c := NewTestClusterStore(t,
+		WithAppendTestClusterAdjustConfigFunc(func(i int, cfg *config.Config) {
+			cfg.Customize.CustomInitShardsFactory = func() []Shard {
+				return []Shard{{End: []byte("b")}, {Start: []byte("b")}}
+			}
+		}))
+
+	c.Start()
+	defer c.Stop()
+	c.WaitShardByCountPerNode(2, testWaitTimeout)
+	c.WaitLeadersByCount(2, testWaitTimeout)
+
+	kv := c.CreateTestKVClient(0)
+	defer kv.Close()
+	require.NoError(t, kv.Set("a", "1", testWaitTimeout))
+	require.NoError(t, kv.Set("c", "2", testWaitTimeout))
+
+	source := c.GetShardByIndex(0, 0)
+	target := c.GetShardByIndex(0, 1)
+	if len(source.End) == 0 {
+		source, target = target, source
+	}
+
+	// merge [nil, b) into [b, nil)
+	s := c.GetShardLeaderStore(source.ID).(*store)
+	target = s.getReplica(target.ID, false).getShard()
+	s.doShardHeartbeatRsp(rpcpb.ShardHeartbeatRsp{
+		ShardID: source.ID,
+		Merge:   &rpcpb.Merge{Target: protoc.MustMarshal(&target)},
+	})
+
+	c.WaitRemovedByShardID(source.ID, testWaitTimeout)
+	c.WaitShardByCountPerNode(1, testWaitTimeout)
+	c.EveryStore(func(i int, s Store) {
+		shard := c.GetShardByID(i, target.ID)
+		assert.Empty(t, shard.Start)
+		assert.Empty(t, shard.End)
+		assert.True(t, shard.Epoch.Generation > source.Epoch.Generation)
+	})
+
+	v, err := kv.Get("a", testWaitTimeout)
+	assert.NoError(t, err)
+	assert.Equal(t, "1", v)
+	v, err = kv.Get("c", testWaitTimeout)
+	assert.NoError(t, err)
+	assert.Equal(t, "2", v)
+	assert.NoError(t, kv.Set("a1", "3", testWaitTimeout))
+}
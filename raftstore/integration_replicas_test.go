@@ -16,11 +16,13 @@ package raftstore
 import (
 	"github.com/matrixorigin/matrixcube/pb/metapb"
 	"testing"
+	"time"
 
 	"github.com/matrixorigin/matrixcube/config"
 	"github.com/matrixorigin/matrixcube/pb/rpcpb"
 	"github.com/matrixorigin/matrixcube/util/leaktest"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestScheduleReplicasWithRules(t *testing.T) {
@@ -58,3 +60,82 @@ func TestScheduleReplicasWithRules(t *testing.T) {
 	assert.NoError(t, err)
 	c.WaitShardByCounts([]int{2, 2, 1}, testWaitTimeout)
 }
+
+func TestChangeReplicasByJointConsensus(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping in short mode.")
+		return
+	}
+
+	defer leaktest.AfterTest(t)()
+	c := NewTestClusterStore(t, DisableScheduleTestCluster)
+	c.Start()
+	defer c.Stop()
+	c.WaitShardByCountPerNode(1, testWaitTimeout)
+	c.WaitLeadersByCount(1, testWaitTimeout)
+
+	shard := c.GetShardByIndex(0, 0)
+	c.WaitAllReplicasChangeToVoter(shard.ID, testWaitTimeout)
+	s := c.GetShardLeaderStore(shard.ID).(*store)
+	pr := s.getReplica(shard.ID, false)
+	require.NotNil(t, pr)
+	shard = pr.getShard()
+	base := shard.Epoch.ConfigVer
+
+	// demote both followers in one ConfChangeV2, it enters the joint state
+	var changes []rpcpb.ConfigChange
+	for _, r := range shard.Replicas {
+		if r.StoreID != s.Meta().ID {
+			r.Role = metapb.ReplicaRole_Learner
+			changes = append(changes, rpcpb.ConfigChange{
+				Replica:    r,
+				ChangeType: metapb.ConfigChangeType_AddLearnerNode,
+			})
+		}
+	}
+	require.Equal(t, 2, len(changes))
+	s.doShardHeartbeatRsp(rpcpb.ShardHeartbeatRsp{
+		ShardID:        shard.ID,
+		ConfigChangeV2: &rpcpb.ConfigChangeV2{Changes: changes},
+	})
+	waitShardConfigVer(t, c, shard.ID, base+2)
+
+	// the prophet may leave the joint state before us, the duplicated request
+	// is rejected
+	s.doShardHeartbeatRsp(rpcpb.ShardHeartbeatRsp{
+		ShardID:        shard.ID,
+		ConfigChangeV2: &rpcpb.ConfigChangeV2{},
+	})
+	waitShardConfigVer(t, c, shard.ID, base+4)
+	c.EveryStore(func(i int, s Store) {
+		for _, r := range c.GetShardByID(i, shard.ID).Replicas {
+			assert.NotEqual(t, metapb.ReplicaRole_IncomingVoter, r.Role)
+			assert.NotEqual(t, metapb.ReplicaRole_DemotingVoter, r.Role)
+		}
+	})
+
+	kv := c.CreateTestKVClient(0)
+	defer kv.Close()
+	assert.NoError(t, kv.Set("a", "1", testWaitTimeout))
+}
+
+func waitShardConfigVer(t *testing.T, c TestRaftCluster, shardID uint64, ver uint64) {
+	timeoutC := time.After(testWaitTimeout)
+	for {
+		done := true
+		c.EveryStore(func(i int, s Store) {
+			if c.GetShardByID(i, shardID).Epoch.ConfigVer < ver {
+				done = false
+			}
+		})
+		if done {
+			return
+		}
+		select {
+		case <-timeoutC:
+			assert.FailNowf(t, "", "wait config ver %d of shard %d timeout", ver, shardID)
+		default:
+			time.Sleep(time.Millisecond * 100)
+		}
+	}
+}
@@ -19,12 +19,15 @@ import (
 	"testing"
 	"time"
 
+	"github.com/fagongzi/util/protoc"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.etcd.io/etcd/raft/v3"
 
 	"github.com/matrixorigin/matrixcube/pb/metapb"
 	"github.com/matrixorigin/matrixcube/pb/rpcpb"
+	"github.com/matrixorigin/matrixcube/storage"
+	"github.com/matrixorigin/matrixcube/storage/kv"
 	"github.com/matrixorigin/matrixcube/util/leaktest"
 )
 
@@ -138,3 +141,104 @@ func TestCompactionAndSnapshot(t *testing.T) {
 		t.Fatalf("failed to get replica")
 	}
 }
+
+func TestCompactionAndDeltaSnapshot(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping in short mode.")
+		return
+	}
+	defer leaktest.AfterTest(t)()
+
+	snapshotTestTimeout := 20 * time.Second
+	skipStore := uint64(0)
+	filter := func(msg metapb.RaftMessage) bool {
+		return msg.To.StoreID == atomic.LoadUint64(&skipStore) ||
+			msg.From.StoreID == atomic.LoadUint64(&skipStore)
+	}
+
+	c := NewTestClusterStore(t,
+		DiskTestCluster,
+		WithTestClusterDeltaSnapshot(1024),
+		WithTestClusterNodeCount(3))
+	c.Start()
+	defer c.Stop()
+
+	for i := 0; i < 3; i++ {
+		store := c.GetStore(i).(*store)
+		store.trans.SetFilter(filter)
+	}
+
+	c.WaitShardByCountPerNode(1, snapshotTestTimeout)
+	c.WaitAllReplicasChangeToVoter(c.GetShardByIndex(0, 0).ID, snapshotTestTimeout)
+	shardID := c.GetShardByIndex(0, 0).ID
+
+	client := c.CreateTestKVClient(0)
+	defer client.Close()
+	getReplica := func(node int) *replica {
+		pr := c.GetStore(node).(*store).getReplica(shardID, false)
+		require.NotNil(t, pr)
+		return pr
+	}
+	appliedIndex := func(node int) uint64 {
+		index, _ := getReplica(node).sm.getAppliedIndexTerm()
+		return index
+	}
+	get := func(node int, key string) string {
+		v, err := getReplica(node).sm.dataStorage.(storage.KVStorageWrapper).
+			GetKVStorage().Get(kv.EncodeDataKey([]byte(key), nil))
+		require.NoError(t, err)
+		return string(v)
+	}
+
+	assert.NoError(t, client.Set("k1", "v1", snapshotTestTimeout))
+	assert.NoError(t, client.Set("k2", "v2", snapshotTestTimeout))
+	for i := 0; appliedIndex(2) < appliedIndex(0); i++ {
+		if i == 100 {
+			t.Fatalf("replica not caught up")
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	// isolate the selected store
+	atomic.StoreUint64(&skipStore, c.GetStore(2).Meta().ID)
+	base := appliedIndex(2)
+	assert.NoError(t, client.Set("k1", "v11", snapshotTestTimeout))
+	assert.NoError(t, client.Set("k3", "v3", snapshotTestTimeout))
+
+	compacted := false
+	for i := 0; i < 2; i++ {
+		pr := getReplica(i)
+		require.NoError(t, pr.sm.dataStorage.Sync([]uint64{shardID}))
+		if !pr.isLeader() {
+			continue
+		}
+		pr.addAdminRequest(rpcpb.AdminCompactLog, &rpcpb.CompactLogRequest{
+			CompactIndex: appliedIndex(i),
+		})
+		for j := 0; j < 10 && !compacted; j++ {
+			_, err := pr.lr.Entries(base+1, base+2, math.MaxUint64)
+			compacted = err == raft.ErrCompacted
+			time.Sleep(time.Second)
+		}
+	}
+	require.True(t, compacted)
+
+	// restore the network, the isolated replica catches up by a delta snapshot
+	atomic.StoreUint64(&skipStore, 0)
+	assert.NoError(t, client.Set("k4", "v4", snapshotTestTimeout))
+	for i := 0; get(2, "k4") != "v4"; i++ {
+		if i == 100 {
+			t.Fatalf("replica not caught up")
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	assert.Equal(t, "v11", get(2, "k1"))
+	assert.Equal(t, "v2", get(2, "k2"))
+	assert.Equal(t, "v3", get(2, "k3"))
+
+	ss, err := getReplica(2).logdb.GetSnapshot(shardID)
+	require.NoError(t, err)
+	var si metapb.SnapshotInfo
+	protoc.MustUnmarshal(&si, ss.Data)
+	assert.Equal(t, base, si.DeltaBase)
+}
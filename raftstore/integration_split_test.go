@@ -18,11 +18,13 @@ import (
 	"testing"
 	"time"
 
+	"github.com/fagongzi/util/protoc"
 	"github.com/matrixorigin/matrixcube/components/prophet/util/typeutil"
 	"github.com/matrixorigin/matrixcube/config"
 	"github.com/matrixorigin/matrixcube/pb/metapb"
 	"github.com/matrixorigin/matrixcube/pb/rpcpb"
 	"github.com/matrixorigin/matrixcube/util/leaktest"
+	"github.com/matrixorigin/matrixcube/util/uuid"
 	"github.com/stretchr/testify/assert"
 	"go.etcd.io/etcd/raft/v3"
 )
@@ -75,6 +77,54 @@ func TestSplitWithMultiNodesCluster(t *testing.T) {
 	checkSplitWithProphet(t, c, sid, 3)
 }
 
+func TestSplitShardByKeys(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping in short mode.")
+		return
+	}
+
+	defer leaktest.AfterTest(t)()
+	c := NewSingleTestClusterStore(t)
+	c.Start()
+	defer c.Stop()
+
+	c.WaitShardByCountPerNode(1, testWaitTimeout)
+	shard := c.GetShardByIndex(0, 0)
+	c.WaitLeadersByCount(1, testWaitTimeout)
+
+	for {
+		ch := make(chan rpcpb.ResponseBatch, 1)
+		assert.NoError(t, c.GetStore(0).OnRequestWithCB(rpcpb.Request{
+			ID:         uuid.NewV4().Bytes(),
+			Type:       rpcpb.Admin,
+			CustomType: uint64(rpcpb.AdminSplitShard),
+			ToShard:    shard.ID,
+			Epoch:      shard.Epoch,
+			Cmd: protoc.MustMarshal(&rpcpb.SplitShardRequest{
+				Keys: [][]byte{[]byte("k2"), []byte("k4")},
+			}),
+		}, func(resp rpcpb.ResponseBatch) {
+			ch <- resp
+		}))
+
+		resp := <-ch
+		if resp.Header.IsEmpty() {
+			assert.Equal(t, rpcpb.AdminBatchSplit, resp.GetAdminCmdType())
+			break
+		}
+	}
+	c.WaitShardByCountOnNode(0, 3, testWaitTimeout)
+	for _, r := range []struct {
+		key, start, end string
+	}{{"k1", "", "k2"}, {"k3", "k2", "k4"}, {"k5", "k4", ""}} {
+		s, ok := c.GetStore(0).GetShardByKey(0, []byte(r.key))
+		assert.True(t, ok)
+		assert.NotEqual(t, shard.ID, s.ID)
+		assert.Equal(t, r.start, string(s.Start))
+		assert.Equal(t, r.end, string(s.End))
+	}
+}
+
 func TestSplitWithSingleClusterAndWriteToOldShard(t *testing.T) {
 	if testing.Short() {
 		t.Skip("skipping in short mode.")
@@ -19,7 +19,9 @@ import (
 
 type applyMetrics struct {
 	// an inaccurate difference in shard size since last reset.
-	approximateDiffHint uint64
+	approximateDiffHint int64
+	// an inaccurate difference in shard keys since last reset.
+	approximateKeysDiffHint int64
 	// delete keys' count since last reset.
 	deleteKeysHint uint64
 	writtenBytes   uint64
@@ -80,6 +82,10 @@ type raftMessageMetrics struct {
 	heartbeat      uint64
 	heartbeatResp  uint64
 	transferLeader uint64
+	// droppedStaleTerm and droppedDuplicate the received messages dropped
+	// before stepping the raft
+	droppedStaleTerm uint64
+	droppedDuplicate uint64
 }
 
 func (m *raftMessageMetrics) flush() {
@@ -122,6 +128,16 @@ func (m *raftMessageMetrics) flush() {
 		metric.AddRaftTransferLeaderMsgsCount(m.transferLeader)
 		m.transferLeader = 0
 	}
+
+	if m.droppedStaleTerm > 0 {
+		metric.AddRaftDroppedStaleTermMsgsCount(m.droppedStaleTerm)
+		m.droppedStaleTerm = 0
+	}
+
+	if m.droppedDuplicate > 0 {
+		metric.AddRaftDroppedDuplicateMsgsCount(m.droppedDuplicate)
+		m.droppedDuplicate = 0
+	}
 }
 
 type raftProposeMetrics struct {
@@ -170,6 +186,8 @@ type raftAdminMetrics struct {
 	removePeerSucceed uint64
 	splitSucceed      uint64
 	compactSucceed    uint64
+	merge             uint64
+	mergeSucceed      uint64
 }
 
 func (m *raftAdminMetrics) incBy(by raftAdminMetrics) {
@@ -180,6 +198,8 @@ func (m *raftAdminMetrics) incBy(by raftAdminMetrics) {
 	m.removePeerSucceed += by.removePeerSucceed
 	m.split += by.split
 	m.splitSucceed += by.splitSucceed
+	m.merge += by.merge
+	m.mergeSucceed += by.mergeSucceed
 	m.compact += by.compact
 	m.compactSucceed += by.compactSucceed
 	m.updateMetadata += by.updateMetadata
@@ -208,6 +228,15 @@ func (m *raftAdminMetrics) flush() {
 		m.splitSucceed = 0
 	}
 
+	if m.merge > 0 {
+		metric.AddRaftAdminCommandMergeCount(m.merge)
+		m.merge = 0
+	}
+	if m.mergeSucceed > 0 {
+		metric.AddRaftAdminCommandMergeSucceedCount(m.mergeSucceed)
+		m.mergeSucceed = 0
+	}
+
 	if m.compact > 0 {
 		metric.AddRaftAdminCommandCompactCount(m.compact)
 		m.compact = 0
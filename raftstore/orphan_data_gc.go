@@ -0,0 +1,292 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package raftstore
+
+import (
+	"bytes"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/juju/ratelimit"
+	"github.com/lni/goutils/syncutil"
+	"go.uber.org/zap"
+
+	"github.com/matrixorigin/matrixcube/components/log"
+	"github.com/matrixorigin/matrixcube/config"
+	"github.com/matrixorigin/matrixcube/metric"
+	"github.com/matrixorigin/matrixcube/storage"
+)
+
+// OrphanRange is a key range of the data storage holding the data not owned by
+// any shard on the store.
+type OrphanRange struct {
+	// Groups the shard groups using the data storage
+	Groups []uint64
+	// Start the start key of the orphan data
+	Start []byte
+	// End the end key of the orphan data, exclusive
+	End []byte
+	// Keys the number of keys of the orphan data
+	Keys uint64
+	// Bytes the bytes of the orphan data
+	Bytes uint64
+	// Removed whether the orphan data is deleted or quarantined
+	Removed bool
+}
+
+// OrphanDataReport is the report of the last orphan data gc of the store.
+type OrphanDataReport struct {
+	// Mode the orphan data gc mode
+	Mode string
+	// StartAt the start time of the gc
+	StartAt time.Time
+	// EndAt the end time of the gc
+	EndAt time.Time
+	// Ranges the orphan ranges found
+	Ranges []OrphanRange
+}
+
+// orphanDataTarget is a data storage to be scanned and the shard groups using
+// it.
+type orphanDataTarget struct {
+	groups []uint64
+	ds     storage.DataStorage
+}
+
+type keyRange struct {
+	start []byte
+	end   []byte
+}
+
+// orphanDataGC is used to find and remove the data of the key ranges not owned
+// by any shard on the store, e.g. the data left by the replicas destroyed
+// without removing data. Only the key ranges between the owned shard ranges are
+// scanned, and the scan is rate limited.
+type orphanDataGC struct {
+	cfg     config.OrphanDataGCConfig
+	logger  *zap.Logger
+	stopper *syncutil.Stopper
+	limiter *ratelimit.Bucket
+	// targets returns the data storages to be scanned
+	targets func() []orphanDataTarget
+	// owned returns the shards on the store using the data storage
+	owned func(storage.DataStorage) []Shard
+	// createLocker blocks the replicas creation on the store, it's held from
+	// checking the orphan data is not owned until the data is removed.
+	createLocker sync.Locker
+
+	mu struct {
+		sync.Mutex
+		report OrphanDataReport
+	}
+}
+
+func newOrphanDataGC(cfg config.OrphanDataGCConfig, logger *zap.Logger,
+	targets func() []orphanDataTarget,
+	owned func(storage.DataStorage) []Shard,
+	createLocker sync.Locker) *orphanDataGC {
+	rate := float64(cfg.BytesPerSecond)
+	return &orphanDataGC{
+		cfg:          cfg,
+		logger:       logger,
+		stopper:      syncutil.NewStopper(),
+		limiter:      ratelimit.NewBucketWithRate(rate, int64(cfg.BytesPerSecond)),
+		targets:      targets,
+		owned:        owned,
+		createLocker: createLocker,
+	}
+}
+
+func (gc *orphanDataGC) start() {
+	if gc.cfg.Mode == config.OrphanDataGCDisabled {
+		return
+	}
+
+	gc.stopper.RunWorker(func() {
+		ticker := time.NewTicker(gc.cfg.Interval.Duration)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-gc.stopper.ShouldStop():
+				return
+			case <-ticker.C:
+				gc.gc()
+			}
+		}
+	})
+}
+
+func (gc *orphanDataGC) close() {
+	gc.stopper.Stop()
+}
+
+func (gc *orphanDataGC) getReport() OrphanDataReport {
+	gc.mu.Lock()
+	defer gc.mu.Unlock()
+	return gc.mu.report
+}
+
+func (gc *orphanDataGC) gc() {
+	report := OrphanDataReport{Mode: gc.cfg.Mode, StartAt: time.Now()}
+	for _, t := range gc.targets() {
+		ds, ok := t.ds.(storage.OrphanDataStorage)
+		if !ok {
+			continue
+		}
+
+		ranges, stopped, err := gc.scan(ds, gc.owned(t.ds))
+		if err != nil {
+			gc.logger.Error("fail to scan orphan data",
+				zap.Uint64s("groups", t.groups),
+				zap.Error(err))
+			continue
+		}
+		for _, r := range ranges {
+			r.Groups = t.groups
+			gc.logger.Warn("orphan data found",
+				zap.Uint64s("groups", r.Groups),
+				log.HexField("from", r.Start),
+				log.HexField("to", r.End),
+				zap.Uint64("keys", r.Keys),
+				zap.Uint64("bytes", r.Bytes),
+				zap.String("mode", gc.cfg.Mode))
+			r.Removed = gc.remove(ds, t.ds, r)
+			report.Ranges = append(report.Ranges, r)
+		}
+		if stopped {
+			return
+		}
+	}
+	report.EndAt = time.Now()
+
+	keys, size := uint64(0), uint64(0)
+	for _, r := range report.Ranges {
+		keys += r.Keys
+		size += r.Bytes
+	}
+	metric.SetOrphanDataOnStore(len(report.Ranges), keys, size)
+
+	gc.mu.Lock()
+	gc.mu.report = report
+	gc.mu.Unlock()
+}
+
+// scan scans the key ranges not owned by the shards, and returns the ranges
+// holding data. The returned boolean value indicates whether the gc is stopped.
+func (gc *orphanDataGC) scan(ds storage.OrphanDataStorage,
+	shards []Shard) ([]OrphanRange, bool, error) {
+	var ranges []OrphanRange
+	for _, kr := range getOrphanKeyRanges(shards) {
+		var r OrphanRange
+		stopped := false
+		if err := ds.ScanData(kr.start, kr.end, func(key []byte, size uint64) (bool, error) {
+			select {
+			case <-gc.stopper.ShouldStop():
+				stopped = true
+				return false, nil
+			default:
+			}
+
+			gc.limiter.Wait(int64(size))
+			if r.Keys == 0 {
+				r.Start = append([]byte(nil), key...)
+			}
+			r.End = append(r.End[:0], key...)
+			r.Keys++
+			r.Bytes += size
+			return true, nil
+		}); err != nil {
+			return nil, false, err
+		}
+		if stopped {
+			return ranges, true, nil
+		}
+		if r.Keys > 0 {
+			// the end key is exclusive
+			r.End = append(r.End, 0)
+			ranges = append(ranges, r)
+		}
+	}
+	return ranges, false, nil
+}
+
+// remove removes the orphan data if it's still not owned by any shard, it
+// returns true if the data is removed.
+func (gc *orphanDataGC) remove(ds storage.OrphanDataStorage,
+	target storage.DataStorage, r OrphanRange) bool {
+	var fn func(start, end []byte) error
+	switch gc.cfg.Mode {
+	case config.OrphanDataGCDelete:
+		fn = ds.RemoveOrphanData
+	case config.OrphanDataGCQuarantine:
+		fn = ds.QuarantineOrphanData
+	default:
+		return false
+	}
+
+	// the shards may be changed during the scan, e.g. a new replica created by
+	// a snapshot. No replica can be created until the data is removed.
+	gc.createLocker.Lock()
+	defer gc.createLocker.Unlock()
+	for _, shard := range gc.owned(target) {
+		if isKeyRangeOverlapped(r.Start, r.End, shard) {
+			gc.logger.Info("skip orphan data, owned by shard",
+				log.HexField("from", r.Start),
+				log.HexField("to", r.End),
+				log.ShardField("shard", shard))
+			return false
+		}
+	}
+
+	if err := fn(r.Start, r.End); err != nil {
+		gc.logger.Error("fail to remove orphan data",
+			log.HexField("from", r.Start),
+			log.HexField("to", r.End),
+			zap.String("mode", gc.cfg.Mode),
+			zap.Error(err))
+		return false
+	}
+	return true
+}
+
+// getOrphanKeyRanges returns the key ranges not covered by the shards, empty
+// start or end key means the min or max key.
+func getOrphanKeyRanges(shards []Shard) []keyRange {
+	sort.Slice(shards, func(i, j int) bool {
+		return bytes.Compare(shards[i].Start, shards[j].Start) < 0
+	})
+
+	var ranges []keyRange
+	covered := []byte(nil)
+	for _, shard := range shards {
+		if bytes.Compare(shard.Start, covered) > 0 {
+			ranges = append(ranges, keyRange{start: covered, end: shard.Start})
+		}
+		if len(shard.End) == 0 {
+			return ranges
+		}
+		if bytes.Compare(shard.End, covered) > 0 {
+			covered = shard.End
+		}
+	}
+	return append(ranges, keyRange{start: covered})
+}
+
+// isKeyRangeOverlapped returns true if the [start, end) key range is overlapped
+// with the shard.
+func isKeyRangeOverlapped(start, end []byte, shard Shard) bool {
+	return (len(end) == 0 || bytes.Compare(shard.Start, end) < 0) &&
+		(len(shard.End) == 0 || bytes.Compare(start, shard.End) < 0)
+}
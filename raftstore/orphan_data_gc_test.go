@@ -0,0 +1,156 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package raftstore
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/matrixorigin/matrixcube/components/log"
+	"github.com/matrixorigin/matrixcube/config"
+	"github.com/matrixorigin/matrixcube/storage"
+	"github.com/matrixorigin/matrixcube/storage/kv"
+	"github.com/matrixorigin/matrixcube/storage/kv/mem"
+	"github.com/matrixorigin/matrixcube/util/leaktest"
+	"github.com/matrixorigin/matrixcube/vfs"
+)
+
+func TestGetOrphanKeyRanges(t *testing.T) {
+	tests := []struct {
+		shards []Shard
+		ranges []keyRange
+	}{
+		{
+			shards: nil,
+			ranges: []keyRange{{}},
+		},
+		{
+			shards: []Shard{{}},
+			ranges: nil,
+		},
+		{
+			shards: []Shard{{End: []byte{2}}},
+			ranges: []keyRange{{start: []byte{2}}},
+		},
+		{
+			shards: []Shard{{Start: []byte{2}}},
+			ranges: []keyRange{{end: []byte{2}}},
+		},
+		{
+			shards: []Shard{{Start: []byte{5}, End: []byte{6}}, {Start: []byte{2}, End: []byte{3}}},
+			ranges: []keyRange{{end: []byte{2}}, {start: []byte{3}, end: []byte{5}}, {start: []byte{6}}},
+		},
+		{
+			shards: []Shard{{Start: []byte{2}, End: []byte{3}}, {Start: []byte{3}}, {End: []byte{2}}},
+			ranges: nil,
+		},
+	}
+
+	for i, c := range tests {
+		assert.Equal(t, c.ranges, getOrphanKeyRanges(c.shards), "index %d", i)
+	}
+}
+
+func TestIsKeyRangeOverlapped(t *testing.T) {
+	assert.True(t, isKeyRangeOverlapped([]byte{1}, []byte{2}, Shard{}))
+	assert.True(t, isKeyRangeOverlapped([]byte{1}, []byte{3}, Shard{Start: []byte{2}}))
+	assert.True(t, isKeyRangeOverlapped([]byte{1}, []byte{3}, Shard{End: []byte{2}}))
+	assert.False(t, isKeyRangeOverlapped([]byte{1}, []byte{2}, Shard{Start: []byte{2}}))
+	assert.False(t, isKeyRangeOverlapped([]byte{2}, []byte{3}, Shard{End: []byte{2}}))
+}
+
+func TestOrphanDataGC(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	owned := []Shard{{ID: 1, Start: []byte{2}, End: []byte{4}}}
+	tests := []struct {
+		mode    string
+		removed bool
+		kept    [][]byte
+	}{
+		{mode: config.OrphanDataGCDryRun, kept: [][]byte{{1}, {2}, {3}, {4}, {5}}},
+		{mode: config.OrphanDataGCDelete, removed: true, kept: [][]byte{{2}, {3}}},
+		{mode: config.OrphanDataGCQuarantine, removed: true, kept: [][]byte{{2}, {3}}},
+	}
+
+	for i, c := range tests {
+		func() {
+			fs := vfs.GetTestFS()
+			base := kv.NewBaseStorage(mem.NewStorage(), fs)
+			ds := kv.NewKVDataStorage(base, nil)
+			defer ds.Close()
+			for _, key := range [][]byte{{1}, {2}, {3}, {4}, {5}} {
+				require.NoError(t, base.Set(kv.EncodeDataKey(key, nil), key, false))
+			}
+
+			cfg := config.OrphanDataGCConfig{Mode: c.mode, BytesPerSecond: 1024}
+			gc := newOrphanDataGC(cfg, log.GetDefaultZapLogger(),
+				func() []orphanDataTarget {
+					return []orphanDataTarget{{groups: []uint64{0}, ds: ds}}
+				}, func(storage.DataStorage) []Shard {
+					return append([]Shard(nil), owned...)
+				}, &sync.Mutex{})
+			gc.gc()
+
+			report := gc.getReport()
+			assert.Equal(t, c.mode, report.Mode, "index %d", i)
+			require.Equal(t, 2, len(report.Ranges), "index %d", i)
+			assert.Equal(t, OrphanRange{Groups: []uint64{0}, Start: []byte{1}, End: []byte{1, 0},
+				Keys: 1, Bytes: 2, Removed: c.removed}, report.Ranges[0], "index %d", i)
+			assert.Equal(t, OrphanRange{Groups: []uint64{0}, Start: []byte{4}, End: []byte{5, 0},
+				Keys: 2, Bytes: 4, Removed: c.removed}, report.Ranges[1], "index %d", i)
+
+			var kept [][]byte
+			require.NoError(t, ds.(storage.OrphanDataStorage).ScanData(nil, nil, func(key []byte, size uint64) (bool, error) {
+				kept = append(kept, append([]byte(nil), key...))
+				return true, nil
+			}))
+			assert.Equal(t, c.kept, kept, "index %d", i)
+		}()
+	}
+}
+
+func TestOrphanDataGCSkipsOwnedRange(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	fs := vfs.GetTestFS()
+	base := kv.NewBaseStorage(mem.NewStorage(), fs)
+	ds := kv.NewKVDataStorage(base, nil)
+	defer ds.Close()
+	require.NoError(t, base.Set(kv.EncodeDataKey([]byte{1}, nil), []byte{1}, false))
+
+	// the shard is created after the scan
+	calls := 0
+	gc := newOrphanDataGC(config.OrphanDataGCConfig{Mode: config.OrphanDataGCDelete, BytesPerSecond: 1024}, log.GetDefaultZapLogger(),
+		func() []orphanDataTarget {
+			return []orphanDataTarget{{groups: []uint64{0}, ds: ds}}
+		}, func(storage.DataStorage) []Shard {
+			calls++
+			if calls > 1 {
+				return []Shard{{ID: 1}}
+			}
+			return nil
+		}, &sync.Mutex{})
+	gc.gc()
+
+	report := gc.getReport()
+	require.Equal(t, 1, len(report.Ranges))
+	assert.False(t, report.Ranges[0].Removed)
+	v, err := base.Get(kv.EncodeDataKey([]byte{1}, nil))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{1}, v)
+}
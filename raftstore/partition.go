@@ -0,0 +1,86 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package raftstore
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+const (
+	partitionKeySize = 8
+)
+
+// EncodePartitionKey returns the routing key of the logical partition of the
+// hash routing groups. The partition keys are ordered by the partition numbers,
+// so each shard of the group manages a range of partitions. The data keys of a
+// partition must be prefixed with its partition key.
+func EncodePartitionKey(partition uint64) []byte {
+	key := make([]byte, partitionKeySize)
+	binary.BigEndian.PutUint64(key, partition)
+	return key
+}
+
+// DecodePartitionKey returns the logical partition of the key prefixed with the
+// partition key, false is returned if the key is too short.
+func DecodePartitionKey(key []byte) (uint64, bool) {
+	if len(key) < partitionKeySize {
+		return 0, false
+	}
+	return binary.BigEndian.Uint64(key), true
+}
+
+// alignPartitionSplitKeys aligns the split keys to the partition boundaries, so
+// all the data of a partition is always managed by a single shard after the
+// split. The split key inside a partition is moved to the start of the next
+// partition, and the keys not in the shard after the alignment are dropped.
+func alignPartitionSplitKeys(shard Shard, splitKeys [][]byte) [][]byte {
+	var aligned [][]byte
+	for _, key := range splitKeys {
+		partition, ok := DecodePartitionKey(key)
+		if !ok {
+			continue
+		}
+		k := EncodePartitionKey(partition)
+		if !bytes.Equal(k, key) {
+			if partition == math.MaxUint64 {
+				continue
+			}
+			k = EncodePartitionKey(partition + 1)
+		}
+
+		if bytes.Compare(k, shard.Start) <= 0 ||
+			checkKeyInShard(k, shard) != nil ||
+			(len(aligned) > 0 && bytes.Compare(k, aligned[len(aligned)-1]) <= 0) {
+			continue
+		}
+		aligned = append(aligned, k)
+	}
+	return aligned
+}
+
+// checkPartitionSplitKeys returns error if any split key is not at a partition
+// boundary, the explicit split keys of the hash routing groups must not split
+// the data of a partition.
+func checkPartitionSplitKeys(splitKeys [][]byte) error {
+	for _, key := range splitKeys {
+		partition, ok := DecodePartitionKey(key)
+		if !ok || !bytes.Equal(EncodePartitionKey(partition), key) {
+			return fmt.Errorf("split key %+v not at the partition boundary", key)
+		}
+	}
+	return nil
+}
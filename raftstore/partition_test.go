@@ -0,0 +1,70 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package raftstore
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncodePartitionKey(t *testing.T) {
+	for _, partition := range []uint64{0, 1, 255, 256, math.MaxUint64} {
+		v, ok := DecodePartitionKey(append(EncodePartitionKey(partition), 'k'))
+		assert.True(t, ok)
+		assert.Equal(t, partition, v)
+	}
+	_, ok := DecodePartitionKey([]byte("k"))
+	assert.False(t, ok)
+}
+
+func TestAlignPartitionSplitKeys(t *testing.T) {
+	key := func(partition uint64, suffix string) []byte {
+		return append(EncodePartitionKey(partition), suffix...)
+	}
+
+	cases := []struct {
+		shard     Shard
+		splitKeys [][]byte
+		expect    [][]byte
+	}{
+		{
+			shard:     Shard{},
+			splitKeys: [][]byte{key(1, ""), key(2, "k"), key(2, "m"), key(4, "k")},
+			expect:    [][]byte{key(1, ""), key(3, ""), key(5, "")},
+		},
+		{
+			shard:     Shard{Start: key(1, ""), End: key(3, "")},
+			splitKeys: [][]byte{key(1, ""), key(1, "k"), key(2, "k")},
+			expect:    [][]byte{key(2, "")},
+		},
+		{
+			shard:     Shard{},
+			splitKeys: [][]byte{[]byte("k"), key(math.MaxUint64, "k")},
+			expect:    nil,
+		},
+	}
+
+	for i, c := range cases {
+		assert.Equal(t, c.expect, alignPartitionSplitKeys(c.shard, c.splitKeys), "index %d", i)
+	}
+}
+
+func TestCheckPartitionSplitKeys(t *testing.T) {
+	assert.NoError(t, checkPartitionSplitKeys([][]byte{EncodePartitionKey(1), EncodePartitionKey(3)}))
+	assert.Error(t, checkPartitionSplitKeys([][]byte{[]byte("k")}))
+	assert.Error(t, checkPartitionSplitKeys([][]byte{EncodePartitionKey(1),
+		append(EncodePartitionKey(2), 'k')}))
+}
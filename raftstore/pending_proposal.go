@@ -15,13 +15,25 @@ package raftstore
 
 import (
 	"bytes"
+	"time"
 
+	"github.com/matrixorigin/matrixcube/metric"
 	"github.com/matrixorigin/matrixcube/pb/rpcpb"
 )
 
 type pendingProposals struct {
 	cmds          []batch
 	confChangeCmd batch
+	stageMetrics  *metric.ProposalStageObservers
+
+	// allApplied the applied proposals waiting to be applied by all the replicas
+	// to be responded, in the order of the log index
+	allApplied []appliedProposal
+}
+
+type appliedProposal struct {
+	c    batch
+	resp rpcpb.ResponseBatch
 }
 
 func newPendingProposals() *pendingProposals {
@@ -37,6 +49,10 @@ func (p *pendingProposals) close() {
 	p.confChangeCmd.notifyShardRemoved()
 	p.confChangeCmd = emptyCMD
 	p.cmds = p.cmds[:0]
+	for _, w := range p.allApplied {
+		w.c.notifyShardRemoved()
+	}
+	p.allApplied = p.allApplied[:0]
 }
 
 func (p *pendingProposals) clear() {
@@ -48,6 +64,7 @@ func (p *pendingProposals) clear() {
 	}
 	p.confChangeCmd = emptyCMD
 	p.cmds = p.cmds[:0]
+	p.clearAllApplied()
 }
 
 func (p *pendingProposals) pop() (batch, bool) {
@@ -65,9 +82,17 @@ func (p *pendingProposals) append(c batch) {
 	p.cmds = append(p.cmds, c)
 }
 
+// size returns the number of the requests in the pending proposals
+func (p *pendingProposals) size() int64 {
+	n := int64(0)
+	for _, c := range p.cmds {
+		n += int64(len(c.requestBatch.Requests))
+	}
+	return n
+}
+
 func (p *pendingProposals) setConfigChange(c batch) {
-	cmdType := c.requestBatch.GetAdminCmdType()
-	if cmdType != rpcpb.AdminConfigChange {
+	if !isConfigChangeRequestBatch(c.requestBatch) {
 		panic("not a config change request")
 	}
 	p.confChangeCmd = c
@@ -77,6 +102,57 @@ func (p *pendingProposals) getConfigChange() batch {
 	return p.confChangeCmd
 }
 
+// committed observes the replication stage of the proposals committed up to
+// the specified index.
+func (p *pendingProposals) committed(index uint64) {
+	now := time.Now()
+	for idx := range p.cmds {
+		c := &p.cmds[idx]
+		if c.index > index {
+			return
+		}
+		if c.committedAt.IsZero() {
+			p.stageMetrics.Observe(metric.ProposalStageReplication, c.proposedAt)
+			c.committedAt = now
+		}
+	}
+}
+
+// applying observes the commit wait stage of the proposals to be applied up to
+// the specified index.
+func (p *pendingProposals) applying(index uint64) {
+	for _, c := range p.cmds {
+		if c.index > index {
+			return
+		}
+		p.stageMetrics.Observe(metric.ProposalStageCommitWait, c.committedAt)
+	}
+}
+
+// expire responds the AdminTimeout error to the pending admin proposals whose
+// deadline passed, and returns the number of the expired proposals. The expired
+// proposals are kept in order without callback, so the responses are dropped
+// if they are applied later, and the merge prepared by an expired
+// AdminPrepareMerge applied later is rolled back, see isMergeExpired.
+func (p *pendingProposals) expire(shardID uint64, now time.Time) int {
+	n := 0
+	for idx := range p.cmds {
+		c := &p.cmds[idx]
+		if c.isExpired(now) {
+			c.respAdminTimeout(shardID)
+			c.cb = nil
+			c.deadline = time.Time{}
+			n++
+		}
+	}
+	if p.confChangeCmd.isExpired(now) {
+		p.confChangeCmd.respAdminTimeout(shardID)
+		p.confChangeCmd = emptyCMD
+		n++
+	}
+	return n
+}
+
 func (p *pendingProposals) notify(id []byte,
 	resp rpcpb.ResponseBatch, confChange bool) {
 	if confChange {
@@ -96,9 +172,48 @@ func (p *pendingProposals) notify(id []byte,
 		}
 		if bytes.Equal(id, c.getRequestID()) {
 			buildID(id, &resp)
+			if c.waitAllApplied(resp) {
+				p.allApplied = append(p.allApplied, appliedProposal{c: c, resp: resp})
+				return
+			}
+			start := time.Now()
 			c.resp(resp)
+			p.stageMetrics.Observe(metric.ProposalStageCallback, start)
 			return
 		}
 		c.notifyStaleCmd()
 	}
 }
+
+// waitingAllApplied returns true if any applied proposal is waiting to be
+// applied by all the replicas.
+func (p *pendingProposals) waitingAllApplied() bool {
+	return len(p.allApplied) > 0
+}
+
+// applied responds the proposals waiting to be applied by all the replicas up
+// to the specified index.
+func (p *pendingProposals) applied(index uint64) {
+	n := 0
+	for idx := range p.allApplied {
+		w := &p.allApplied[idx]
+		if w.c.index > index {
+			break
+		}
+		w.c.resp(w.resp)
+		*w = appliedProposal{}
+		n++
+	}
+	p.allApplied = p.allApplied[n:]
+}
+
+// clearAllApplied responds the StaleCommand error to the proposals waiting to
+// be applied by all the replicas, e.g. the replica is no longer the leader to
+// track the applied indexes of the other replicas. The proposals are applied,
+// so the results of them are unknown to the clients.
+func (p *pendingProposals) clearAllApplied() {
+	for _, w := range p.allApplied {
+		w.c.notifyStaleCmd()
+	}
+	p.allApplied = p.allApplied[:0]
+}
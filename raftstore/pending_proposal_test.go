@@ -15,8 +15,10 @@ package raftstore
 
 import (
 	"testing"
+	"time"
 
 	"github.com/matrixorigin/matrixcube/components/log"
+	"github.com/matrixorigin/matrixcube/metric"
 	"github.com/matrixorigin/matrixcube/pb/errorpb"
 	"github.com/matrixorigin/matrixcube/pb/rpcpb"
 	"github.com/matrixorigin/matrixcube/util/leaktest"
@@ -62,6 +64,29 @@ func TestPendingProposalPop(t *testing.T) {
 	assert.False(t, ok)
 }
 
+func TestPendingProposalCommitted(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	p := newPendingProposals()
+	p.stageMetrics = metric.NewProposalStageObservers("1", "")
+	now := time.Now()
+	p.append(batch{index: 1, proposedAt: now})
+	p.append(batch{index: 2, proposedAt: now})
+	p.append(batch{index: 3, proposedAt: now})
+
+	p.committed(2)
+	assert.False(t, p.cmds[0].committedAt.IsZero())
+	assert.False(t, p.cmds[1].committedAt.IsZero())
+	assert.True(t, p.cmds[2].committedAt.IsZero())
+
+	// committed proposals are not observed again
+	committedAt := p.cmds[0].committedAt
+	p.committed(3)
+	assert.Equal(t, committedAt, p.cmds[0].committedAt)
+	assert.False(t, p.cmds[2].committedAt.IsZero())
+	p.applying(3)
+}
+
 func TestPendingConfigChangeProposalCanBeSetAndGet(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 
@@ -224,3 +249,88 @@ func TestPendingProposalCanNotifyRegularCmd(t *testing.T) {
 	assert.True(t, ok)
 	assert.Equal(t, cmd3, v)
 }
+
+func TestPendingProposalExpire(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	var resps []rpcpb.ResponseBatch
+	cb := func(resp rpcpb.ResponseBatch) {
+		resps = append(resps, resp)
+	}
+	newAdminCmd := func(cmdType rpcpb.AdminCmdType, deadline time.Time) batch {
+		c := newBatch(nil, newTestAdminRequestBatch(string(uuid.NewV4().Bytes()), 0, cmdType, nil), cb, admin, 0)
+		c.deadline = deadline
+		return c
+	}
+
+	now := time.Now()
+	p := newPendingProposals()
+	p.append(newAdminCmd(rpcpb.AdminBatchSplit, now.Add(-time.Second)))
+	p.append(newAdminCmd(rpcpb.AdminCompactLog, now.Add(time.Second)))
+	p.append(newAdminCmd(rpcpb.AdminCompactLog, time.Time{}))
+	p.setConfigChange(newAdminCmd(rpcpb.AdminConfigChange, now.Add(-time.Second)))
+
+	assert.Equal(t, 2, p.expire(1, now))
+	assert.Equal(t, 2, len(resps))
+	for _, resp := range resps {
+		assert.NotNil(t, resp.Header.Error.AdminTimeout)
+		assert.Equal(t, uint64(1), resp.Header.Error.AdminTimeout.ShardID)
+		assert.False(t, errorpb.Retryable(resp.Header.Error))
+	}
+	assert.Equal(t, uint64(rpcpb.AdminBatchSplit), resps[0].Header.Error.AdminTimeout.AdminType)
+	assert.Equal(t, emptyCMD, p.confChangeCmd)
+
+	// the expired proposal is kept in order, the late response is dropped
+	assert.Equal(t, 3, len(p.cmds))
+	assert.Equal(t, 0, p.expire(1, now))
+	p.notify(p.cmds[0].getRequestID(), rpcpb.ResponseBatch{}, false)
+	assert.Equal(t, 2, len(resps))
+}
+
+func TestPendingProposalCanWaitAllApplied(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	var resps []rpcpb.ResponseBatch
+	cb := func(resp rpcpb.ResponseBatch) {
+		resps = append(resps, resp)
+	}
+	newCmd := func(index uint64) batch {
+		c := batch{
+			logger: log.Adjust(nil),
+			requestBatch: rpcpb.RequestBatch{
+				Requests: []rpcpb.Request{{Durability: rpcpb.AllReplicasApplied}},
+				Header: rpcpb.RequestBatchHeader{
+					ID: uuid.NewV4().Bytes(),
+				},
+			},
+			cb: cb,
+		}
+		c.index = index
+		return c
+	}
+	ok := func() rpcpb.ResponseBatch {
+		return rpcpb.ResponseBatch{Responses: []rpcpb.Response{{}}}
+	}
+
+	p := newPendingProposals()
+	cmd1, cmd2, cmd3 := newCmd(1), newCmd(2), newCmd(3)
+	p.append(cmd1)
+	p.append(cmd2)
+	p.append(cmd3)
+	p.notify(cmd1.getRequestID(), ok(), false)
+	p.notify(cmd2.getRequestID(), ok(), false)
+	assert.Empty(t, resps)
+	assert.True(t, p.waitingAllApplied())
+
+	p.applied(1)
+	assert.Equal(t, 1, len(resps))
+	assert.True(t, resps[0].Header.IsEmpty())
+	assert.Equal(t, 1, len(p.allApplied))
+
+	// the waiting proposals are stale after the leader changed
+	p.clear()
+	assert.False(t, p.waitingAllApplied())
+	assert.Equal(t, 3, len(resps))
+	assert.Equal(t, errStaleCMD.Error(), resps[1].Header.Error.Message)
+	assert.Equal(t, errStaleCMD.Error(), resps[2].Header.Error.Message)
+}
@@ -15,6 +15,7 @@ package raftstore
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/matrixorigin/matrixcube/components/log"
 	"github.com/matrixorigin/matrixcube/metric"
@@ -38,13 +39,17 @@ var (
 )
 
 type reqCtx struct {
-	reqType int
-	req     rpcpb.Request
-	cb      func(rpcpb.ResponseBatch)
+	reqType   int
+	req       rpcpb.Request
+	cb        func(rpcpb.ResponseBatch)
+	createdAt time.Time
+	// deadline is inherited by the admin request from the flow issued it, e.g.
+	// the split check, zero means the deadline is set when proposing
+	deadline time.Time
 }
 
 func newReqCtx(req rpcpb.Request, cb func(rpcpb.ResponseBatch)) reqCtx {
-	ctx := reqCtx{req: req, cb: cb}
+	ctx := reqCtx{req: req, cb: cb, createdAt: time.Now()}
 	switch req.Type {
 	case rpcpb.Read:
 		ctx.reqType = read
@@ -115,10 +120,10 @@ func (b *proposalBatch) close() {
 
 // push adds the specified req to a proposalBatch. The epoch value should
 // reflect client's view of the shard when the request is made.
-func (b *proposalBatch) push(group uint64, c reqCtx) {
-	req := c.req
-	cb := c.cb
-	tp := c.reqType
+func (b *proposalBatch) push(group uint64, ctx reqCtx) {
+	req := ctx.req
+	cb := ctx.cb
+	tp := ctx.reqType
 	isAdmin := tp == admin
 
 	// use data key to store
@@ -147,6 +152,8 @@ func (b *proposalBatch) push(group uint64, c reqCtx) {
 		rb.Header.Replica = b.replica
 		rb.Header.ID = uuid.NewV4().Bytes()
 		rb.Requests = append(rb.Requests, req)
-		b.batches = append(b.batches, newBatch(b.logger, rb, cb, tp, n))
+		c := newBatch(b.logger, rb, cb, tp, n)
+		c.deadline = ctx.deadline
+		b.batches = append(b.batches, c)
 	}
 }
@@ -20,8 +20,10 @@ import (
 	"time"
 
 	"github.com/matrixorigin/matrixcube/components/log"
+	"github.com/matrixorigin/matrixcube/metric"
 	"github.com/matrixorigin/matrixcube/pb/errorpb"
 	"github.com/matrixorigin/matrixcube/pb/rpcpb"
+	"github.com/matrixorigin/matrixcube/storage"
 	"github.com/matrixorigin/matrixcube/util"
 	"go.uber.org/zap"
 )
@@ -31,6 +33,9 @@ var (
 	ErrTimeout = errors.New("exec timeout")
 	// ErrKeysNotInShard keys not in shard, request data needs to be split
 	ErrKeysNotInShard = errors.New("keys not in shard, request data needs to be split")
+	// ErrTooManyPacedRequests the write request is rejected by the flow control as
+	// too many write requests to the shard are paced by the proxy
+	ErrTooManyPacedRequests = errors.New("too many paced requests")
 
 	errStopped = errors.New("stopped")
 )
@@ -45,6 +50,11 @@ type SuccessCallback func(resp rpcpb.Response)
 // FailureCallback request failure callback
 type FailureCallback func(requestID []byte, err error)
 
+// ReadValueCallback the success callback of the zero copy read requests, the
+// response value is held by the ReadValue, and the callback takes the ownership
+// of its reference.
+type ReadValueCallback func(resp rpcpb.Response, value *storage.ReadValue)
+
 // RetryController retry controller
 type RetryController interface {
 	// Retry used to control retry if retryable error encountered. returns false means stop retry.
@@ -59,9 +69,35 @@ type ShardsProxy interface {
 	Dispatch(req rpcpb.Request) error
 	DispatchTo(req rpcpb.Request, shard Shard, store string) error
 	SetCallback(SuccessCallback, FailureCallback)
+	// SetReadValueCallback sets the callback of the zero copy read responses, the
+	// values are copied to the responses and passed to the SuccessCallback if
+	// it's not set.
+	SetReadValueCallback(ReadValueCallback)
 	SetRetryController(retryController RetryController)
 	OnResponse(rpcpb.ResponseBatch)
+	// OnReadValueResponse is called with the successful response of the zero copy
+	// read, the proxy takes the ownership of the value.
+	OnReadValueResponse(rpcpb.Response, *storage.ReadValue)
+	// OnCredits is called with the proposal credits advertised by the stores, the
+	// write requests to the shards are paced by the credits if the flow control
+	// is enabled.
+	OnCredits([]rpcpb.ShardCredits)
 	Router() Router
+	// SetGroupMigration sets the phase of the migration of the shard group to the
+	// destination cluster, the phase moves forward one at a time, see the
+	// `MigrationPhase`. The migration is set on each proxy, all the proxies of
+	// the group must be moved to the dual write phase before the group is
+	// switched by `client.SwitchGroupMigration`. The switch is rejected with
+	// `ErrMigrationDiverged` if the replicated writes or the verified reads of
+	// the proxy diverged, or `ErrMigrationPending` until the writes are
+	// replicated.
+	SetGroupMigration(GroupMigration) error
+	// RemoveGroupMigration removes the migration of the shard group, e.g. once all
+	// the clients use the destination cluster directly, or to abort the migration.
+	RemoveGroupMigration(group uint64)
+	// GetGroupMigration returns the status of the migration of the shard group,
+	// false if the group is not being migrated.
+	GetGroupMigration(group uint64) (GroupMigrationStatus, bool)
 }
 
 type backendFactory interface {
@@ -70,19 +106,38 @@ type backendFactory interface {
 
 type backend interface {
 	dispatch(rpcpb.Request) error
+	// queued returns the number of the requests queued in the backend and not
+	// sent yet
+	queued() int64
 	close()
 }
 
 type shardsProxyConfig struct {
-	backendFactory  backendFactory
-	successCallback SuccessCallback
-	failureCallback FailureCallback
-	retryController RetryController
-	logger          *zap.Logger
-	router          Router
-	rpcpb           proxyRPC
-	maxBodySize     int
-	retryInterval   time.Duration
+	backendFactory    backendFactory
+	successCallback   SuccessCallback
+	failureCallback   FailureCallback
+	readValueCallback ReadValueCallback
+	retryController   RetryController
+	logger            *zap.Logger
+	router            Router
+	rpcpb             proxyRPC
+	maxBodySize       int
+	retryInterval     time.Duration
+	// dispatchWorkers dispatch the requests to each target store through the
+	// storeDispatcher of the store
+	dispatchWorkers     bool
+	dispatchBatchSize   int64
+	dispatchBatchWindow time.Duration
+	// creditsStaleness the write requests to the shards are paced by the credits
+	// advertised in the staleness, 0 means the flow control is disabled.
+	creditsStaleness time.Duration
+	// maxPacedRequests the max number of the paced requests of a shard, 0 means
+	// unlimited
+	maxPacedRequests uint64
+	// routeLookup looks up the routes doubted by the proxy from the prophet, nil
+	// means the proxy only relies on the router.
+	routeLookup          routeLookup
+	routeDoubtsThreshold int
 }
 
 type shardsProxyBuilder struct {
@@ -103,6 +158,26 @@ func (sb *shardsProxyBuilder) withMaxBodySize(size int) *shardsProxyBuilder {
 	return sb
 }
 
+func (sb *shardsProxyBuilder) withDispatchWorkers(batchSize uint64, batchWindow time.Duration) *shardsProxyBuilder {
+	sb.cfg.dispatchWorkers = true
+	sb.cfg.dispatchBatchSize = int64(batchSize)
+	sb.cfg.dispatchBatchWindow = batchWindow
+	return sb
+}
+
+func (sb *shardsProxyBuilder) withFlowControl(creditsStaleness time.Duration,
+	maxPacedRequests uint64) *shardsProxyBuilder {
+	sb.cfg.creditsStaleness = creditsStaleness
+	sb.cfg.maxPacedRequests = maxPacedRequests
+	return sb
+}
+
+func (sb *shardsProxyBuilder) withConsistentRouting(doubtsThreshold int, lookup routeLookup) *shardsProxyBuilder {
+	sb.cfg.routeDoubtsThreshold = doubtsThreshold
+	sb.cfg.routeLookup = lookup
+	return sb
+}
+
 func (sb *shardsProxyBuilder) withBackendFactory(factory backendFactory) *shardsProxyBuilder {
 	sb.cfg.backendFactory = factory
 	return sb
@@ -144,20 +219,41 @@ func (sb *shardsProxyBuilder) build(router Router) (ShardsProxy, error) {
 }
 
 type shardsProxy struct {
-	sync.RWMutex
+	sync.Mutex
+
+	cfg    shardsProxyConfig
+	logger *zap.Logger
+	// backends addr -> backend, the backends are created with the lock held and
+	// loaded without lock in the dispatching path.
+	backends sync.Map
+	// flow paces the write requests by the credits, nil if the flow control is
+	// disabled
+	flow *flowController
+	// routes looks up the doubted routes from the prophet, nil if the consistent
+	// routing is disabled
+	routes  *routeResolver
+	stopped bool
 
-	cfg      shardsProxyConfig
-	logger   *zap.Logger
-	backends map[string]backend
-	stopped  bool
+	migrations *groupMigrations
 }
 
 func newShardsProxy(cfg shardsProxyConfig) (ShardsProxy, error) {
-	return &shardsProxy{
-		cfg:      cfg,
-		logger:   cfg.logger,
-		backends: make(map[string]backend),
-	}, nil
+	p := &shardsProxy{
+		cfg:        cfg,
+		logger:     cfg.logger,
+		migrations: newGroupMigrations(cfg.logger),
+	}
+	p.SetCallback(cfg.successCallback, cfg.failureCallback)
+	p.SetReadValueCallback(cfg.readValueCallback)
+	if cfg.creditsStaleness > 0 {
+		p.flow = newFlowController(cfg.creditsStaleness, cfg.maxPacedRequests)
+	}
+	if cfg.routeLookup != nil {
+		p.routes = newRouteResolver(cfg.logger, cfg.router,
+			cfg.routeDoubtsThreshold, cfg.routeLookup)
+		p.routes.start()
+	}
+	return p, nil
 }
 
 func (p *shardsProxy) Start() error {
@@ -186,17 +282,30 @@ func (p *shardsProxy) Stop() error {
 		p.cfg.rpcpb.stop()
 	}
 
-	for k, b := range p.backends {
-		b.close()
-		delete(p.backends, k)
+	p.backends.Range(func(k, v interface{}) bool {
+		v.(backend).close()
+		p.backends.Delete(k)
+		return true
+	})
+	if p.flow != nil {
+		for _, r := range p.flow.dispose() {
+			p.cfg.failureCallback(r.req.ID, errStopped)
+		}
+	}
+	if p.routes != nil {
+		p.routes.stop()
 	}
 	p.stopped = true
 	return nil
 }
 
 func (p *shardsProxy) SetCallback(success SuccessCallback, failure FailureCallback) {
-	p.cfg.successCallback = success
-	p.cfg.failureCallback = failure
+	p.cfg.successCallback = p.migrations.wrapSuccess(success)
+	p.cfg.failureCallback = p.migrations.wrapFailure(failure)
+}
+
+func (p *shardsProxy) SetReadValueCallback(cb ReadValueCallback) {
+	p.cfg.readValueCallback = p.migrations.wrapReadValue(cb)
 }
 
 func (p *shardsProxy) SetRetryController(retryController RetryController) {
@@ -204,14 +313,35 @@ func (p *shardsProxy) SetRetryController(retryController RetryController) {
 }
 
 func (p *shardsProxy) Dispatch(req rpcpb.Request) error {
+	if p.migrations.dispatch(req, p.cfg.successCallback, p.cfg.failureCallback) {
+		return nil
+	}
+
+	var err error
 	if req.ToShard == 0 {
 		shard, store := p.cfg.router.SelectShardWithPolicy(req.Group, req.Key, req.ReplicaSelectPolicy)
-		return p.DispatchTo(req, shard, store.ClientAddress)
+		err = p.DispatchTo(req, shard, store.ClientAddress)
+	} else {
+		err = p.DispatchTo(req,
+			p.cfg.router.GetShard(req.ToShard),
+			p.cfg.router.SelectReplicaStoreWithPolicy(req.ToShard, req.ReplicaSelectPolicy).ClientAddress)
+	}
+	if err != nil {
+		p.migrations.onSource(req.ID, nil, err)
 	}
+	return err
+}
+
+func (p *shardsProxy) SetGroupMigration(migration GroupMigration) error {
+	return p.migrations.set(migration)
+}
 
-	return p.DispatchTo(req,
-		p.cfg.router.GetShard(req.ToShard),
-		p.cfg.router.SelectReplicaStoreWithPolicy(req.ToShard, req.ReplicaSelectPolicy).ClientAddress)
+func (p *shardsProxy) RemoveGroupMigration(group uint64) {
+	p.migrations.remove(group)
+}
+
+func (p *shardsProxy) GetGroupMigration(group uint64) (GroupMigrationStatus, bool) {
+	return p.migrations.get(group)
 }
 
 func (p *shardsProxy) DispatchTo(req rpcpb.Request, shard Shard, to string) error {
@@ -222,8 +352,19 @@ func (p *shardsProxy) DispatchTo(req rpcpb.Request, shard Shard, to string) erro
 			log.RaftRequestField("request", &req))
 	}
 
+	if isRequestExpired(req, time.Now()) {
+		return ErrTimeout
+	}
+
 	// No leader, retry after a leader tick
 	if to == "" {
+		if p.routes != nil {
+			if shard.ID == 0 {
+				p.routes.missing(req.Group, req.Key)
+			} else {
+				p.routes.doubt(shard.ID, time.Now())
+			}
+		}
 		p.retryDispatch(req.ID, "dispatch to nil store")
 		return nil
 	}
@@ -238,9 +379,34 @@ func (p *shardsProxy) DispatchTo(req rpcpb.Request, shard Shard, to string) erro
 	}
 
 	req.Epoch = shard.Epoch
+	if p.flow != nil && req.Type == rpcpb.Write {
+		ok, err := p.flow.acquire(shard.ID, req, to, time.Now())
+		if err != nil {
+			return err
+		}
+		if !ok {
+			if ce := p.logger.Check(zap.DebugLevel, "request paced"); ce != nil {
+				ce.Write(log.HexField("id", req.ID),
+					log.ShardIDField(shard.ID),
+					log.ReasonField("no credits"))
+			}
+			return nil
+		}
+	}
 	return p.forwardToBackend(req, to)
 }
 
+// queued returns the number of the requests queued in the backends and not
+// sent yet.
+func (p *shardsProxy) queued() int64 {
+	n := int64(0)
+	p.backends.Range(func(k, v interface{}) bool {
+		n += v.(backend).queued()
+		return true
+	})
+	return n
+}
+
 func (p *shardsProxy) Router() Router {
 	return p.cfg.router
 }
@@ -256,6 +422,9 @@ func (p *shardsProxy) forwardToBackend(req rpcpb.Request, leader string) error {
 			return errStopped
 		}
 
+		if bc = p.getBackend(leader); bc != nil {
+			return bc.dispatch(req)
+		}
 		bc, err = p.createBackendLocked(leader)
 		if err != nil {
 			return err
@@ -275,11 +444,43 @@ func (p *shardsProxy) OnResponse(resp rpcpb.ResponseBatch) {
 	}
 }
 
-func (p *shardsProxy) getBackend(addr string) backend {
-	p.RLock()
-	defer p.RUnlock()
+func (p *shardsProxy) OnReadValueResponse(rsp rpcpb.Response, value *storage.ReadValue) {
+	if rsp.PID != 0 || p.cfg.readValueCallback == nil {
+		rsp.Value = append([]byte(nil), value.Data()...)
+		value.Release()
+		p.OnResponse(rpcpb.ResponseBatch{Responses: []rpcpb.Response{rsp}})
+		return
+	}
+
+	if ce := p.logger.Check(zap.DebugLevel, "requests done with read value"); ce != nil {
+		ce.Write(log.RaftResponseField("resp", &rsp))
+	}
+	p.cfg.readValueCallback(rsp, value)
+}
+
+func (p *shardsProxy) OnCredits(credits []rpcpb.ShardCredits) {
+	if p.flow == nil {
+		return
+	}
+
+	now := time.Now()
+	p.dispatchPaced(p.flow.advertise(credits, now))
+	p.dispatchPaced(p.flow.expire(now))
+}
+
+func (p *shardsProxy) dispatchPaced(paced []pacedRequest) {
+	for _, r := range paced {
+		if err := p.forwardToBackend(r.req, r.to); err != nil {
+			p.doneWithError(r.req.ID, err)
+		}
+	}
+}
 
-	return p.backends[addr]
+func (p *shardsProxy) getBackend(addr string) backend {
+	if v, ok := p.backends.Load(addr); ok {
+		return v.(backend)
+	}
+	return nil
 }
 
 func (p *shardsProxy) createBackendLocked(addr string) (backend, error) {
@@ -288,16 +489,23 @@ func (p *shardsProxy) createBackendLocked(addr string) (backend, error) {
 		return nil, err
 	}
 
+	if p.cfg.dispatchWorkers {
+		bc = newStoreDispatcher(p.logger, addr, bc, p.doneWithError,
+			p.cfg.dispatchBatchSize, p.cfg.dispatchBatchWindow)
+	}
 	p.addBackendLocked(addr, bc)
 	return bc, nil
 }
 
 func (p *shardsProxy) addBackendLocked(addr string, bc backend) {
-	p.backends[addr] = bc
+	p.backends.Store(addr, bc)
 }
 
 func (p *shardsProxy) onLocalResp(header rpcpb.ResponseBatchHeader, rsp rpcpb.Response) {
-	rsp.Error = header.Error
+	// keep the error of the single response, e.g. AccessDenied
+	if !header.IsEmpty() {
+		rsp.Error = header.Error
+	}
 	p.done(rsp)
 }
 
@@ -306,6 +514,12 @@ func (p *shardsProxy) doneWithError(requestID []byte, err error) {
 }
 
 func (p *shardsProxy) done(rsp rpcpb.Response) {
+	// credits heartbeat of the remote store
+	if len(rsp.ID) == 0 && len(rsp.Credits) > 0 {
+		p.OnCredits(rsp.Credits)
+		return
+	}
+
 	if ce := p.logger.Check(zap.DebugLevel, "requests done"); ce != nil {
 		ce.Write(log.RaftResponseField("resp", &rsp))
 	}
@@ -315,26 +529,84 @@ func (p *shardsProxy) done(rsp rpcpb.Response) {
 		return
 	}
 
+	// the group is switched to the destination cluster of the migration, the
+	// request is retried on it
+	if v := rsp.Error.GroupMigrated; v != nil {
+		if p.migrations.follow(v.Group) {
+			p.retryDispatchAfter(rsp.ID, rsp.Error.String(), 0)
+			return
+		}
+		p.cfg.failureCallback(rsp.ID, NewGroupMigratedErr(v.Group))
+		return
+	}
+
 	if !errorpb.Retryable(rsp.Error) {
 		if rsp.Error.ShardUnavailable != nil {
 			p.cfg.failureCallback(rsp.ID, NewShardUnavailableErr(rsp.Error.ShardUnavailable.ShardID))
 			return
 		}
+		if rsp.Error.GroupStopped != nil {
+			p.cfg.failureCallback(rsp.ID, NewGroupStoppedErr(rsp.Error.GroupStopped.Group))
+			return
+		}
+		if v := rsp.Error.ReadSnapshotNotFound; v != nil {
+			p.cfg.failureCallback(rsp.ID, NewReadSnapshotNotFoundErr(v.ShardID, v.Name))
+			return
+		}
+		if v := rsp.Error.AccessDenied; v != nil {
+			p.cfg.failureCallback(rsp.ID, NewAccessDeniedErr(v.ShardID, v.Tenant))
+			return
+		}
+		if v := rsp.Error.ResponseTooLarge; v != nil {
+			p.cfg.failureCallback(rsp.ID, NewResponseTooLargeErr(v.ShardID, v.MaxResponseBytes))
+			return
+		}
+		if v := rsp.Error.AdminTimeout; v != nil {
+			p.cfg.failureCallback(rsp.ID, NewAdminTimeoutErr(v.ShardID, v.AdminType))
+			return
+		}
+		if rsp.Error.RequestTimeout != nil {
+			p.cfg.failureCallback(rsp.ID, ErrTimeout)
+			return
+		}
 		p.cfg.failureCallback(rsp.ID, errors.New(rsp.Error.String()))
 		return
 	}
 
 	p.adjustRoute(rsp.Error)
-	p.retryDispatch(rsp.ID, rsp.Error.String())
+	p.retryDispatchAfter(rsp.ID, rsp.Error.String(), p.retryIntervalOf(rsp.Error))
+}
+
+// retryIntervalOf returns the interval to retry the request failed with the
+// error, the request to the recovering shard is retried after the estimated
+// recovery time.
+func (p *shardsProxy) retryIntervalOf(err errorpb.Error) time.Duration {
+	if err.ShardRecovering != nil {
+		if estimated := time.Duration(err.ShardRecovering.EstimatedMillis) * time.Millisecond; estimated > p.cfg.retryInterval {
+			return estimated
+		}
+	}
+	return p.cfg.retryInterval
 }
 
 func (p *shardsProxy) adjustRoute(err errorpb.Error) {
 	if err.NotLeader != nil {
 		p.cfg.router.UpdateLeader(err.NotLeader.ShardID, err.NotLeader.Leader.ID)
 	}
+	if p.routes != nil {
+		if err.NotLeader != nil {
+			p.routes.doubt(err.NotLeader.ShardID, time.Now())
+		} else if err.ShardNotFound != nil {
+			p.routes.doubt(err.ShardNotFound.ShardID, time.Now())
+		}
+	}
 }
 
 func (p *shardsProxy) retryDispatch(requestID []byte, err string) {
+	p.retryDispatchAfter(requestID, err, p.cfg.retryInterval)
+}
+
+func (p *shardsProxy) retryDispatchAfter(requestID []byte, err string, interval time.Duration) {
 	if p.cfg.retryController == nil {
 		if ce := p.logger.Check(zap.DebugLevel, "dispatch request failed with no retry"); ce != nil {
 			ce.Write(log.HexField("id", requestID),
@@ -352,16 +624,31 @@ func (p *shardsProxy) retryDispatch(requestID []byte, err string) {
 				log.ReasonField("retry controller return false"),
 				zap.String("cause", err))
 		}
+		metric.IncProxyRetryExhaustedCount()
 		p.cfg.failureCallback(requestID, errors.New(err))
 		return
 	}
 
+	// the request can not be completed before its deadline, no need to wait
+	// for the retry
+	if isRequestExpired(req, time.Now().Add(interval)) {
+		if ce := p.logger.Check(zap.DebugLevel, "dispatch request failed with no retry"); ce != nil {
+			ce.Write(log.HexField("id", requestID),
+				log.ReasonField("deadline passed before retry"),
+				zap.String("cause", err))
+		}
+		metric.IncProxyRetryExhaustedCount()
+		p.cfg.failureCallback(requestID, ErrTimeout)
+		return
+	}
+
 	// FIXME: more efficient retry mechanism
 	if ce := p.logger.Check(zap.DebugLevel, "dispatch request failed, retry later"); ce != nil {
 		ce.Write(log.HexField("id", req.ID),
 			zap.String("cause", err))
 	}
-	util.DefaultTimeoutWheel().Schedule(p.cfg.retryInterval, p.doRetry, req)
+	metric.IncProxyRetryCount()
+	util.DefaultTimeoutWheel().Schedule(interval, p.doRetry, req)
 }
 
 func (p *shardsProxy) doRetry(arg interface{}) {
@@ -379,9 +666,11 @@ func (p *shardsProxy) doRetry(arg interface{}) {
 	}
 }
 
+// keysRangeInShard returns true if the keys range is in the shard, the empty To of
+// the keys range means the end of the key space.
 func keysRangeInShard(keys *rpcpb.Range, shard Shard) bool {
 	return (len(shard.Start) == 0 || bytes.Compare(shard.Start, keys.From) <= 0) &&
-		(len(shard.End) == 0 || bytes.Compare(shard.End, keys.To) >= 0)
+		(len(shard.End) == 0 || (len(keys.To) > 0 && bytes.Compare(shard.End, keys.To) >= 0))
 }
 
 // NewMockShardsProxy returns mock shards proxy to handle request
@@ -27,6 +27,7 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/matrixorigin/matrixcube/components/log"
+	"github.com/matrixorigin/matrixcube/metric"
 	"github.com/matrixorigin/matrixcube/pb/rpcpb"
 	"github.com/matrixorigin/matrixcube/util/stop"
 	"github.com/matrixorigin/matrixcube/util/task"
@@ -90,8 +91,12 @@ func (mb *mockBackend) dispatch(req rpcpb.Request) error {
 	return nil
 }
 
+func (mb *mockBackend) queued() int64 {
+	return 0
+}
+
 func (mb *mockBackend) close() {
-	mb.close()
+
 }
 
 type localBackend struct {
@@ -107,6 +112,10 @@ func (lb *localBackend) dispatch(req rpcpb.Request) error {
 	return lb.handler(req)
 }
 
+func (lb *localBackend) queued() int64 {
+	return 0
+}
+
 func (lb *localBackend) close() {
 
 }
@@ -151,9 +160,14 @@ func (bc *remoteBackend) dispatch(req rpcpb.Request) error {
 	return bc.reqs.Put(req)
 }
 
+func (bc *remoteBackend) queued() int64 {
+	return bc.reqs.Len()
+}
+
 func (bc *remoteBackend) close() {
 	bc.reqs.Put(closeFlag)
 	bc.stopper.Stop()
+	metric.RemoveProxyBackendQueueMetric(bc.addr)
 }
 
 func (bc *remoteBackend) checkConnect() bool {
@@ -196,6 +210,7 @@ func (bc *remoteBackend) writeLoop(ctx context.Context) {
 					zap.Error(err))
 				return
 			}
+			metric.SetProxyBackendQueueMetric(bc.addr, bc.reqs.Len())
 
 			for i := int64(0); i < n; i++ {
 				if items[i] == closeFlag {
@@ -204,17 +219,24 @@ func (bc *remoteBackend) writeLoop(ctx context.Context) {
 					return
 				}
 
+				req := items[i].(rpcpb.Request)
+				if !setRequestTimeout(&req, time.Now()) {
+					bc.failureCallback(req.ID, ErrTimeout)
+					items[i] = nil
+					continue
+				}
 				if ce := bc.logger.Check(zap.DebugLevel, "send request"); ce != nil {
-					ce.Write(log.HexField("id", items[i].(rpcpb.Request).ID))
+					ce.Write(log.HexField("id", req.ID))
 				}
-				bc.conn.Write(items[i])
+				bc.conn.Write(req)
 			}
 
 			err = bc.conn.Flush()
 			if err != nil {
 				for i := int64(0); i < n; i++ {
-					req := items[i].(rpcpb.Request)
-					bc.failureCallback(req.ID, err)
+					if req, ok := items[i].(rpcpb.Request); ok {
+						bc.failureCallback(req.ID, err)
+					}
 				}
 			}
 		}
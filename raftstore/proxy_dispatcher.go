@@ -0,0 +1,139 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package raftstore
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/matrixorigin/matrixcube/components/log"
+	"github.com/matrixorigin/matrixcube/metric"
+	"github.com/matrixorigin/matrixcube/pb/rpcpb"
+	"github.com/matrixorigin/matrixcube/util/stop"
+	"github.com/matrixorigin/matrixcube/util/task"
+)
+
+type queuedRequest struct {
+	req      rpcpb.Request
+	enqueued time.Time
+}
+
+// storeDispatcher is a backend which dispatches the requests to the backend of
+// the target store in its own queue and worker, the requests are taken from
+// the queue in batches, and the worker waits up to the batch window for more
+// requests if the batch is not full.
+type storeDispatcher struct {
+	addr            string
+	logger          *zap.Logger
+	backend         backend
+	failureCallback FailureCallback
+	batchSize       int64
+	batchWindow     time.Duration
+	reqs            *task.Queue
+	stopper         *stop.Stopper
+}
+
+func newStoreDispatcher(logger *zap.Logger,
+	addr string,
+	bc backend,
+	failureCallback FailureCallback,
+	batchSize int64,
+	batchWindow time.Duration) *storeDispatcher {
+	if batchSize <= 0 {
+		batchSize = 1
+	}
+	d := &storeDispatcher{
+		addr:            addr,
+		logger:          log.Adjust(logger).With(zap.String("dispatch-to", addr)),
+		backend:         bc,
+		failureCallback: failureCallback,
+		batchSize:       batchSize,
+		batchWindow:     batchWindow,
+		reqs:            task.New(batchSize),
+	}
+	d.stopper = stop.NewStopper(fmt.Sprintf("proxy-dispatcher-%s", addr))
+	d.stopper.RunTask(context.Background(), d.run)
+	return d
+}
+
+func (d *storeDispatcher) dispatch(req rpcpb.Request) error {
+	return d.reqs.Put(queuedRequest{req: req, enqueued: time.Now()})
+}
+
+func (d *storeDispatcher) queued() int64 {
+	return d.reqs.Len() + d.backend.queued()
+}
+
+func (d *storeDispatcher) close() {
+	for _, v := range d.reqs.Dispose() {
+		d.failureCallback(v.(queuedRequest).req.ID, errStopped)
+	}
+	d.stopper.Stop()
+	d.backend.close()
+	metric.RemoveProxyDispatchQueueMetric(d.addr)
+}
+
+func (d *storeDispatcher) run(ctx context.Context) {
+	d.logger.Info("proxy dispatcher started")
+	defer d.logger.Info("proxy dispatcher stopped")
+
+	items := make([]interface{}, d.batchSize)
+	for {
+		n, err := d.reqs.Get(d.batchSize, items)
+		if err != nil {
+			return
+		}
+		if n < d.batchSize && d.batchWindow > 0 {
+			n += d.fill(items[n:])
+		}
+
+		// the waiting time of the head request is the head-of-line blocking time
+		// of the requests behind it
+		metric.SetProxyDispatchQueueMetric(d.addr, d.reqs.Len(),
+			time.Since(items[0].(queuedRequest).enqueued))
+		for i := int64(0); i < n; i++ {
+			qr := items[i].(queuedRequest)
+			items[i] = nil
+			metric.ObserveProxyDispatchWaitDuration(qr.enqueued)
+			if err := d.backend.dispatch(qr.req); err != nil {
+				if ce := d.logger.Check(zap.DebugLevel, "fail to dispatch request"); ce != nil {
+					ce.Write(log.HexField("id", qr.req.ID), zap.Error(err))
+				}
+				d.failureCallback(qr.req.ID, err)
+			}
+		}
+	}
+}
+
+// fill waits up to the batch window for more requests, and returns the number
+// of the requests added to the items.
+func (d *storeDispatcher) fill(items []interface{}) int64 {
+	n := int64(0)
+	deadline := time.Now().Add(d.batchWindow)
+	for n < int64(len(items)) {
+		timeout := time.Until(deadline)
+		if timeout <= 0 {
+			break
+		}
+		m, err := d.reqs.Poll(int64(len(items))-n, items[n:], timeout)
+		if err != nil {
+			break
+		}
+		n += m
+	}
+	return n
+}
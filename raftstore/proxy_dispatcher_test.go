@@ -0,0 +1,122 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package raftstore
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/matrixorigin/matrixcube/pb/rpcpb"
+	"github.com/matrixorigin/matrixcube/util/leaktest"
+)
+
+func TestStoreDispatcher(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	c := make(chan rpcpb.Request, 10)
+	fc := make(chan []byte, 10)
+	d := newStoreDispatcher(nil, "s1", newLocalBackend(func(r rpcpb.Request) error {
+		if string(r.ID) == "bad" {
+			return errors.New("bad request")
+		}
+		c <- r
+		return nil
+	}), func(id []byte, err error) { fc <- id }, 4, time.Millisecond*10)
+	defer d.close()
+
+	for _, id := range []string{"r1", "bad", "r2"} {
+		assert.NoError(t, d.dispatch(rpcpb.Request{ID: []byte(id)}))
+	}
+	for _, id := range []string{"r1", "r2"} {
+		select {
+		case r := <-c:
+			assert.Equal(t, id, string(r.ID))
+		case <-time.After(time.Second):
+			assert.FailNow(t, "timeout")
+		}
+	}
+	select {
+	case id := <-fc:
+		assert.Equal(t, "bad", string(id))
+	case <-time.After(time.Second):
+		assert.FailNow(t, "timeout")
+	}
+}
+
+func TestStoreDispatcherIsolatesStores(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	block := make(chan struct{})
+	d1 := newStoreDispatcher(nil, "s1", newLocalBackend(func(r rpcpb.Request) error {
+		<-block
+		return nil
+	}), func(id []byte, err error) {}, 1, 0)
+	defer d1.close()
+	defer close(block)
+
+	c := make(chan rpcpb.Request, 1)
+	d2 := newStoreDispatcher(nil, "s2", newLocalBackend(func(r rpcpb.Request) error {
+		c <- r
+		return nil
+	}), func(id []byte, err error) {}, 1, 0)
+	defer d2.close()
+
+	assert.NoError(t, d1.dispatch(rpcpb.Request{ID: []byte("r1")}))
+	assert.NoError(t, d1.dispatch(rpcpb.Request{ID: []byte("r2")}))
+	assert.NoError(t, d2.dispatch(rpcpb.Request{ID: []byte("r3")}))
+	select {
+	case r := <-c:
+		assert.Equal(t, "r3", string(r.ID))
+	case <-time.After(time.Second):
+		assert.FailNow(t, "blocked by the requests to other store")
+	}
+}
+
+func TestStoreDispatcherCloseFailsQueuedRequests(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	block := make(chan struct{})
+	started := make(chan struct{}, 1)
+	fc := make(chan []byte, 10)
+	d := newStoreDispatcher(nil, "s1", newLocalBackend(func(r rpcpb.Request) error {
+		started <- struct{}{}
+		<-block
+		return nil
+	}), func(id []byte, err error) {
+		assert.Equal(t, errStopped, err)
+		fc <- id
+	}, 1, 0)
+
+	assert.NoError(t, d.dispatch(rpcpb.Request{ID: []byte("r1")}))
+	<-started
+	assert.NoError(t, d.dispatch(rpcpb.Request{ID: []byte("r2")}))
+
+	closed := make(chan struct{})
+	go func() {
+		d.close()
+		close(closed)
+	}()
+	select {
+	case id := <-fc:
+		assert.Equal(t, "r2", string(id))
+	case <-time.After(time.Second):
+		assert.FailNow(t, "timeout")
+	}
+	close(block)
+	<-closed
+	assert.Error(t, d.dispatch(rpcpb.Request{ID: []byte("r3")}))
+}
@@ -0,0 +1,548 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package raftstore
+
+import (
+	"bytes"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/matrixorigin/matrixcube/components/log"
+	"github.com/matrixorigin/matrixcube/pb/rpcpb"
+	"github.com/matrixorigin/matrixcube/storage"
+	"go.uber.org/zap"
+)
+
+var (
+	// ErrInvalidMigrationPhase the group migration moves forward one phase at a
+	// time, and is switched only after the dual write phase.
+	ErrInvalidMigrationPhase = errors.New("invalid group migration phase")
+	// ErrMigrationDiverged the group is not switched to the destination cluster as
+	// the replicated writes or the verified reads of the clusters diverged.
+	ErrMigrationDiverged = errors.New("group migration diverged")
+	// ErrMigrationPending the group is not switched to the destination cluster
+	// until all the writes of the group are replicated.
+	ErrMigrationPending = errors.New("group migration has pending requests")
+)
+
+var (
+	// maxMigrationQueueSize the max number of the requests of a group waiting to be
+	// replicated to the destination cluster, the writes beyond it are dropped and
+	// counted as diverged.
+	maxMigrationQueueSize = 100000
+	// migrationPendingTimeout the requests are expired if the source cluster never
+	// responds them within the timeout after their deadlines, the writes are
+	// counted as diverged as their outcomes are unknown.
+	migrationPendingTimeout = time.Minute
+)
+
+// MigrationPhase the phase of the migration of a shard group to the destination
+// cluster on the proxy.
+type MigrationPhase int
+
+const (
+	// MigrationReplicating the existing data of the group is being copied to the
+	// destination cluster by `client.ReplicateGroup`, the requests are served by
+	// the source cluster only. The writes acknowledged by the source cluster are
+	// queued in order, and replayed on the destination cluster once the copy
+	// completed and the group moves to the dual write phase.
+	MigrationReplicating MigrationPhase = iota
+	// MigrationDualWrite the writes acknowledged by the source cluster are
+	// replicated to the destination cluster one at a time in the order of the
+	// acknowledgements, and the responses of the source cluster are returned.
+	// The writes failed on the destination cluster are counted as diverged. With
+	// the Verify, the responses of the replicated writes are compared, and the
+	// reads are also executed on the destination cluster after the previous
+	// writes are replicated and compared.
+	MigrationDualWrite
+	// MigrationSwitched all requests of the group are routed to the destination
+	// cluster once the requests to the source cluster before the switch are
+	// replicated.
+	MigrationSwitched
+)
+
+// MigrationTarget the destination cluster of the group migration, see the
+// `client.NewMigrationTarget`.
+type MigrationTarget interface {
+	// Dispatch executes the request routed by key on the destination cluster, the
+	// cb is called with the response or the error once the request completed.
+	Dispatch(req rpcpb.Request, cb func(rpcpb.Response, error))
+}
+
+// GroupMigration the migration of a shard group to the destination cluster. The
+// shard ids are local to the cluster, so only the requests routed by key are
+// migrated, the requests to the specified shards are always served by the
+// source cluster.
+//
+// The writes are replicated by each proxy in the order acknowledged by the
+// source cluster, the concurrent writes of the same key from different proxies
+// may be replicated in a different order, they are detected by the verified
+// reads.
+//
+// The group is switched to the destination cluster atomically on all the proxies
+// by `client.SwitchGroupMigration`, the shards of the group are fenced as
+// migrated on the source cluster first, so no request is served by the source
+// cluster after the switch. The proxies in the dual write phase follow the
+// switch once their requests are rejected with the GroupMigrated error.
+type GroupMigration struct {
+	Group uint64
+	Phase MigrationPhase
+	// Target the destination cluster, the current target is kept if it's nil and
+	// the group is being migrated.
+	Target MigrationTarget
+	// Verify compares the results of the clusters in the dual write phase.
+	Verify bool
+}
+
+// GroupMigrationStatus the status of the group migration on the proxy.
+type GroupMigrationStatus struct {
+	Group  uint64
+	Phase  MigrationPhase
+	Verify bool
+	// Pending the number of the requests waiting for the responses of the source
+	// cluster or to be replicated to the destination cluster.
+	Pending int
+	// DualWrites the number of the writes replicated to the destination cluster.
+	DualWrites uint64
+	// DivergedWrites the number of the writes failed on the destination cluster,
+	// responded with different results, or with unknown outcomes on the source
+	// cluster.
+	DivergedWrites uint64
+	// VerifiedReads the number of the reads executed on both clusters.
+	VerifiedReads uint64
+	// MismatchedReads the number of the verified reads with different results.
+	MismatchedReads uint64
+}
+
+// groupMigrations the group migrations of the proxy, the requests of the groups
+// not being migrated are dispatched without the lock.
+type groupMigrations struct {
+	logger *zap.Logger
+	// active the number of the group migrations, accessed atomically
+	active int32
+
+	mu struct {
+		sync.Mutex
+		groups map[uint64]*groupMigration
+		// pending the requests waiting for the responses of the source cluster
+		pending map[string]*migrationRequest
+		// lastExpired the last time the pending requests are expired
+		lastExpired time.Time
+	}
+}
+
+type groupMigration struct {
+	GroupMigration
+	status GroupMigrationStatus
+	// pending the number of the requests of the group in groupMigrations.pending
+	pending int
+	// queue the requests to be executed on the destination cluster in order
+	queue []*migrationRequest
+	// replicating a request of the queue is being executed on the destination
+	replicating bool
+}
+
+func (g *groupMigration) diverged() bool {
+	return g.status.DivergedWrites > 0 || g.status.MismatchedReads > 0
+}
+
+type migrationRequest struct {
+	migration *groupMigration
+	req       rpcpb.Request
+	expireAt  time.Time
+	// source the response value of the source cluster, nil if the request is
+	// forwarded to the destination cluster after the switch
+	source  *[]byte
+	success SuccessCallback
+	failure FailureCallback
+}
+
+func (r *migrationRequest) write() bool {
+	return r.req.Type == rpcpb.Write
+}
+
+func newGroupMigrations(logger *zap.Logger) *groupMigrations {
+	m := &groupMigrations{logger: logger}
+	m.mu.groups = make(map[uint64]*groupMigration)
+	m.mu.pending = make(map[string]*migrationRequest)
+	return m
+}
+
+func (m *groupMigrations) set(migration GroupMigration) error {
+	m.mu.Lock()
+	current, ok := m.mu.groups[migration.Group]
+	if !ok {
+		if migration.Target == nil {
+			m.mu.Unlock()
+			return errors.New("missing the target of the group migration")
+		}
+		if migration.Phase == MigrationSwitched {
+			m.mu.Unlock()
+			return ErrInvalidMigrationPhase
+		}
+		current = &groupMigration{}
+		current.status.Group = migration.Group
+		m.mu.groups[migration.Group] = current
+		atomic.AddInt32(&m.active, 1)
+	} else if migration.Phase != current.Phase && migration.Phase != current.Phase+1 {
+		m.mu.Unlock()
+		return ErrInvalidMigrationPhase
+	} else if migration.Phase == MigrationSwitched && current.Phase == MigrationDualWrite {
+		if current.diverged() {
+			m.mu.Unlock()
+			return ErrMigrationDiverged
+		}
+		if current.pending > 0 || current.replicating || len(current.queue) > 0 {
+			m.mu.Unlock()
+			return ErrMigrationPending
+		}
+	}
+
+	if migration.Target == nil {
+		migration.Target = current.Target
+	}
+	current.GroupMigration = migration
+	current.status.Phase = migration.Phase
+	current.status.Verify = migration.Verify
+	m.logger.Info("group migration changed",
+		zap.Uint64("group", migration.Group),
+		zap.Int("phase", int(migration.Phase)),
+		zap.Bool("verify", migration.Verify))
+	// the writes queued in the replicating phase are replayed
+	next := m.nextLocked(current)
+	m.mu.Unlock()
+	m.replicate(next)
+	return nil
+}
+
+// follow switches the group to the destination cluster once the request to the
+// source cluster is rejected by the migrated shard, returns false if the group
+// is not being migrated by the proxy.
+func (m *groupMigrations) follow(group uint64) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	current, ok := m.mu.groups[group]
+	if !ok {
+		return false
+	}
+	if current.Phase != MigrationSwitched {
+		if current.Phase != MigrationDualWrite || current.diverged() {
+			m.logger.Warn("group switched by the source cluster while migration not ready",
+				zap.Uint64("group", group),
+				zap.Int("phase", int(current.Phase)),
+				zap.Uint64("diverged-writes", current.status.DivergedWrites),
+				zap.Uint64("mismatched-reads", current.status.MismatchedReads))
+		}
+		current.Phase = MigrationSwitched
+		current.status.Phase = MigrationSwitched
+		m.logger.Info("group migration switched by the source cluster",
+			zap.Uint64("group", group))
+	}
+	return true
+}
+
+// remove removes the group migration, the requests forwarded to the destination
+// cluster after the switch are still executed in order.
+func (m *groupMigrations) remove(group uint64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	current, ok := m.mu.groups[group]
+	if !ok {
+		return
+	}
+	delete(m.mu.groups, group)
+	atomic.AddInt32(&m.active, -1)
+	for id, r := range m.mu.pending {
+		if r.migration == current {
+			delete(m.mu.pending, id)
+		}
+	}
+	current.pending = 0
+	queue := current.queue[:0]
+	for _, r := range current.queue {
+		if r.source == nil {
+			queue = append(queue, r)
+		}
+	}
+	current.queue = queue
+	m.logger.Info("group migration removed",
+		zap.Uint64("group", group))
+}
+
+func (m *groupMigrations) get(group uint64) (GroupMigrationStatus, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if current, ok := m.mu.groups[group]; ok {
+		status := current.status
+		status.Pending = current.pending + len(current.queue)
+		if current.replicating {
+			status.Pending++
+		}
+		return status, true
+	}
+	return GroupMigrationStatus{}, false
+}
+
+// dispatch dispatches the request of the group being migrated to the destination
+// cluster after the switch, returns false if the request is dispatched to the
+// source cluster. The writes, and the reads to verify, are tracked until the
+// source cluster responds them, the retries of them are tracked only once.
+func (m *groupMigrations) dispatch(req rpcpb.Request, success SuccessCallback, failure FailureCallback) bool {
+	if atomic.LoadInt32(&m.active) == 0 || req.ToShard > 0 {
+		return false
+	}
+
+	now := time.Now()
+	m.mu.Lock()
+	nexts := m.expireLocked(now)
+	dispatched, next := m.dispatchLocked(req, success, failure, now)
+	m.mu.Unlock()
+	for _, next := range append(nexts, next) {
+		m.replicate(next)
+	}
+	return dispatched
+}
+
+func (m *groupMigrations) dispatchLocked(req rpcpb.Request, success SuccessCallback,
+	failure FailureCallback, now time.Time) (bool, *migrationRequest) {
+	current, ok := m.mu.groups[req.Group]
+	if !ok {
+		return false, nil
+	}
+
+	id := string(req.ID)
+	if current.Phase == MigrationSwitched {
+		// the retry of the request rejected by the migrated shard
+		if r, ok := m.mu.pending[id]; ok {
+			delete(m.mu.pending, id)
+			r.migration.pending--
+		}
+		// the requests are forwarded after the requests before the switch, the
+		// queue is executed by the callback of the previous request
+		r := &migrationRequest{migration: current, req: req, success: success, failure: failure}
+		current.queue = append(current.queue, r)
+		return true, m.nextLocked(current)
+	}
+
+	if req.Type != rpcpb.Write &&
+		(req.Type != rpcpb.Read || current.Phase != MigrationDualWrite || !current.Verify) {
+		return false, nil
+	}
+	if _, ok := m.mu.pending[id]; ok {
+		return false, nil
+	}
+	expireAt := now
+	if req.Deadline > 0 {
+		expireAt = time.Unix(0, req.Deadline)
+	}
+	m.mu.pending[id] = &migrationRequest{
+		migration: current,
+		req:       req,
+		expireAt:  expireAt.Add(migrationPendingTimeout),
+	}
+	current.pending++
+	return false, nil
+}
+
+// expireLocked removes the pending requests never responded by the source
+// cluster, and returns the requests to replicate once the pending requests of
+// the groups are removed.
+func (m *groupMigrations) expireLocked(now time.Time) []*migrationRequest {
+	if now.Sub(m.mu.lastExpired) < time.Second {
+		return nil
+	}
+	m.mu.lastExpired = now
+
+	var nexts []*migrationRequest
+	for id, r := range m.mu.pending {
+		if now.Before(r.expireAt) {
+			continue
+		}
+		delete(m.mu.pending, id)
+		r.migration.pending--
+		if r.write() {
+			r.migration.status.DivergedWrites++
+			m.logger.Warn("migrated write expired without response",
+				zap.Uint64("group", r.migration.Group),
+				log.HexField("id", r.req.ID))
+		}
+		if next := m.nextLocked(r.migration); next != nil {
+			nexts = append(nexts, next)
+		}
+	}
+	return nexts
+}
+
+// onSource queues the request acknowledged by the source cluster to replicate
+// it to the destination cluster.
+func (m *groupMigrations) onSource(id []byte, value []byte, err error) {
+	if atomic.LoadInt32(&m.active) == 0 {
+		return
+	}
+
+	m.mu.Lock()
+	r, ok := m.mu.pending[string(id)]
+	if !ok {
+		m.mu.Unlock()
+		return
+	}
+	delete(m.mu.pending, string(id))
+	current := r.migration
+	current.pending--
+
+	if err != nil {
+		if r.write() && !writeNotApplied(err) {
+			current.status.DivergedWrites++
+			m.logger.Warn("migrated write failed with unknown outcome",
+				zap.Uint64("group", current.Group),
+				log.HexField("id", id),
+				zap.Error(err))
+		}
+	} else if len(current.queue) >= maxMigrationQueueSize {
+		if r.write() {
+			current.status.DivergedWrites++
+			m.logger.Warn("migrated write dropped, too many pending requests",
+				zap.Uint64("group", current.Group),
+				log.HexField("id", id))
+		}
+	} else {
+		source := append([]byte(nil), value...)
+		r.source = &source
+		// the requests before the switch are replicated before the requests
+		// forwarded after the switch
+		idx := len(current.queue)
+		for idx > 0 && current.queue[idx-1].source == nil {
+			idx--
+		}
+		current.queue = append(current.queue, nil)
+		copy(current.queue[idx+1:], current.queue[idx:])
+		current.queue[idx] = r
+	}
+	next := m.nextLocked(current)
+	m.mu.Unlock()
+	m.replicate(next)
+}
+
+// nextLocked returns the next request of the queue to execute on the destination
+// cluster, nil if a request is being executed, or the queued writes are not
+// replayed yet. The requests forwarded after the switch wait for the responses
+// of the requests dispatched to the source cluster before the switch.
+func (m *groupMigrations) nextLocked(current *groupMigration) *migrationRequest {
+	if current.replicating || len(current.queue) == 0 ||
+		current.Phase == MigrationReplicating {
+		return nil
+	}
+	r := current.queue[0]
+	if r.source == nil && current.pending > 0 {
+		return nil
+	}
+	current.queue[0] = nil
+	current.queue = current.queue[1:]
+	current.replicating = true
+	return r
+}
+
+func (m *groupMigrations) replicate(r *migrationRequest) {
+	if r == nil {
+		return
+	}
+	r.migration.Target.Dispatch(r.req, func(resp rpcpb.Response, err error) {
+		m.onTarget(r, resp, err)
+	})
+}
+
+// onTarget completes the request executed on the destination cluster, and
+// executes the next request of the group.
+func (m *groupMigrations) onTarget(r *migrationRequest, resp rpcpb.Response, err error) {
+	if r.source == nil {
+		if err != nil {
+			r.failure(r.req.ID, err)
+		} else {
+			resp.ID = r.req.ID
+			r.success(resp)
+		}
+	}
+
+	m.mu.Lock()
+	current := r.migration
+	current.replicating = false
+	if r.source != nil && m.mu.groups[current.Group] == current {
+		m.compareLocked(r, resp.Value, err)
+	}
+	next := m.nextLocked(current)
+	m.mu.Unlock()
+	m.replicate(next)
+}
+
+// compareLocked compares the results of the request of the clusters.
+func (m *groupMigrations) compareLocked(r *migrationRequest, value []byte, err error) {
+	current := r.migration
+	status := &current.status
+	source := *r.source
+	if r.write() {
+		status.DualWrites++
+		if err != nil || (current.Verify && !bytes.Equal(source, value)) {
+			status.DivergedWrites++
+			m.logger.Warn("replicated write diverged",
+				zap.Uint64("group", status.Group),
+				log.HexField("id", r.req.ID),
+				zap.NamedError("target-error", err))
+		}
+		return
+	}
+
+	status.VerifiedReads++
+	if err != nil || !bytes.Equal(source, value) {
+		status.MismatchedReads++
+		m.logger.Warn("verified read mismatched",
+			zap.Uint64("group", status.Group),
+			log.HexField("id", r.req.ID),
+			zap.NamedError("target-error", err))
+	}
+}
+
+// writeNotApplied returns true if the write is rejected by the source cluster
+// without being applied.
+func writeNotApplied(err error) bool {
+	return IsShardUnavailableErr(err) ||
+		IsGroupStoppedErr(err) ||
+		IsGroupMigratedErr(err) ||
+		IsAccessDeniedErr(err)
+}
+
+func (m *groupMigrations) wrapSuccess(cb SuccessCallback) SuccessCallback {
+	return func(resp rpcpb.Response) {
+		m.onSource(resp.ID, resp.Value, nil)
+		cb(resp)
+	}
+}
+
+func (m *groupMigrations) wrapFailure(cb FailureCallback) FailureCallback {
+	return func(requestID []byte, err error) {
+		m.onSource(requestID, nil, err)
+		cb(requestID, err)
+	}
+}
+
+func (m *groupMigrations) wrapReadValue(cb ReadValueCallback) ReadValueCallback {
+	if cb == nil {
+		return nil
+	}
+	return func(resp rpcpb.Response, value *storage.ReadValue) {
+		m.onSource(resp.ID, value.Data(), nil)
+		cb(resp, value)
+	}
+}
@@ -0,0 +1,218 @@
+// Copyright 2022 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package raftstore
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/matrixorigin/matrixcube/components/log"
+	"github.com/matrixorigin/matrixcube/pb/rpcpb"
+)
+
+type testMigrationTarget struct {
+	requests []rpcpb.Request
+	value    []byte
+	err      error
+	// async holds the callbacks until completed by done
+	async bool
+	cbs   []func()
+}
+
+func (t *testMigrationTarget) Dispatch(req rpcpb.Request, cb func(rpcpb.Response, error)) {
+	t.requests = append(t.requests, req)
+	resp, err := rpcpb.Response{Type: req.Type, Value: t.value}, t.err
+	if t.async {
+		t.cbs = append(t.cbs, func() { cb(resp, err) })
+		return
+	}
+	cb(resp, err)
+}
+
+func (t *testMigrationTarget) done() {
+	cb := t.cbs[0]
+	t.cbs = t.cbs[1:]
+	cb()
+}
+
+func TestGroupMigrationPhases(t *testing.T) {
+	m := newGroupMigrations(log.GetDefaultZapLogger())
+	target := &testMigrationTarget{}
+
+	assert.Error(t, m.set(GroupMigration{Group: 1}))
+	assert.Equal(t, ErrInvalidMigrationPhase, m.set(GroupMigration{Group: 1, Phase: MigrationSwitched, Target: target}))
+	assert.NoError(t, m.set(GroupMigration{Group: 1, Phase: MigrationReplicating, Target: target}))
+	assert.Equal(t, ErrInvalidMigrationPhase, m.set(GroupMigration{Group: 1, Phase: MigrationSwitched}))
+	assert.NoError(t, m.set(GroupMigration{Group: 1, Phase: MigrationDualWrite}))
+	assert.Equal(t, ErrInvalidMigrationPhase, m.set(GroupMigration{Group: 1, Phase: MigrationReplicating}))
+
+	// not switched until the writes are replicated
+	write := rpcpb.Request{ID: []byte("w1"), Group: 1, Type: rpcpb.Write}
+	assert.False(t, m.dispatch(write, nil, nil))
+	assert.Equal(t, ErrMigrationPending, m.set(GroupMigration{Group: 1, Phase: MigrationSwitched}))
+	m.onSource(write.ID, nil, nil)
+	assert.NoError(t, m.set(GroupMigration{Group: 1, Phase: MigrationSwitched}))
+	assert.Equal(t, 1, len(target.requests))
+
+	status, ok := m.get(1)
+	assert.True(t, ok)
+	assert.Equal(t, MigrationSwitched, status.Phase)
+	assert.Equal(t, 0, status.Pending)
+
+	m.remove(1)
+	_, ok = m.get(1)
+	assert.False(t, ok)
+}
+
+func TestGroupMigrationReplication(t *testing.T) {
+	m := newGroupMigrations(log.GetDefaultZapLogger())
+	target := &testMigrationTarget{async: true}
+
+	w1 := rpcpb.Request{ID: []byte("w1"), Group: 1, Type: rpcpb.Write}
+	w2 := rpcpb.Request{ID: []byte("w2"), Group: 1, Type: rpcpb.Write}
+
+	// not migrated
+	assert.False(t, m.dispatch(w1, nil, nil))
+	m.onSource(w1.ID, nil, nil)
+
+	// the writes are queued in the replicating phase, the retries and the
+	// requests to the specified shards are not tracked
+	assert.NoError(t, m.set(GroupMigration{Group: 1, Phase: MigrationReplicating, Target: target}))
+	assert.False(t, m.dispatch(w1, nil, nil))
+	assert.False(t, m.dispatch(w1, nil, nil))
+	assert.False(t, m.dispatch(rpcpb.Request{ID: []byte("w3"), Group: 1, Type: rpcpb.Write, ToShard: 1}, nil, nil))
+	assert.False(t, m.dispatch(w2, nil, nil))
+	m.onSource(w2.ID, nil, nil)
+	m.onSource(w1.ID, nil, nil)
+	status, _ := m.get(1)
+	assert.Equal(t, 2, status.Pending)
+	assert.Empty(t, target.requests)
+
+	// replayed one at a time in the order acknowledged by the source cluster
+	assert.NoError(t, m.set(GroupMigration{Group: 1, Phase: MigrationDualWrite}))
+	assert.Equal(t, 1, len(target.requests))
+	assert.Equal(t, w2.ID, target.requests[0].ID)
+	target.done()
+	assert.Equal(t, 2, len(target.requests))
+	assert.Equal(t, w1.ID, target.requests[1].ID)
+	target.done()
+	status, _ = m.get(1)
+	assert.Equal(t, 0, status.Pending)
+	assert.Equal(t, uint64(2), status.DualWrites)
+	assert.Equal(t, uint64(0), status.DivergedWrites)
+}
+
+func TestGroupMigrationDivergence(t *testing.T) {
+	m := newGroupMigrations(log.GetDefaultZapLogger())
+	target := &testMigrationTarget{value: []byte("v1")}
+
+	write := rpcpb.Request{ID: []byte("w1"), Group: 1, Type: rpcpb.Write}
+	read := rpcpb.Request{ID: []byte("r1"), Group: 1, Type: rpcpb.Read}
+	dualWrite := func(verify bool) {
+		m.remove(1)
+		assert.NoError(t, m.set(GroupMigration{Group: 1, Phase: MigrationReplicating, Target: target}))
+		assert.NoError(t, m.set(GroupMigration{Group: 1, Phase: MigrationDualWrite, Verify: verify}))
+	}
+
+	// the reads are verified only with the Verify
+	dualWrite(false)
+	assert.False(t, m.dispatch(read, nil, nil))
+	m.onSource(read.ID, []byte("v2"), nil)
+	status, _ := m.get(1)
+	assert.Equal(t, uint64(0), status.VerifiedReads)
+
+	// the verified read mismatched
+	dualWrite(true)
+	assert.False(t, m.dispatch(read, nil, nil))
+	m.onSource(read.ID, []byte("v2"), nil)
+	status, _ = m.get(1)
+	assert.Equal(t, uint64(1), status.VerifiedReads)
+	assert.Equal(t, uint64(1), status.MismatchedReads)
+	assert.Equal(t, ErrMigrationDiverged, m.set(GroupMigration{Group: 1, Phase: MigrationSwitched}))
+
+	// the write rejected by the source cluster is not replicated
+	dualWrite(true)
+	assert.False(t, m.dispatch(write, nil, nil))
+	m.onSource(write.ID, nil, NewShardUnavailableErr(1))
+	status, _ = m.get(1)
+	assert.Equal(t, uint64(0), status.DivergedWrites)
+
+	// the write with unknown outcome on the source cluster
+	assert.False(t, m.dispatch(write, nil, nil))
+	m.onSource(write.ID, nil, errors.New("failed"))
+	status, _ = m.get(1)
+	assert.Equal(t, uint64(1), status.DivergedWrites)
+	assert.Equal(t, ErrMigrationDiverged, m.set(GroupMigration{Group: 1, Phase: MigrationSwitched}))
+
+	// the write responded with different results
+	dualWrite(true)
+	assert.False(t, m.dispatch(write, nil, nil))
+	m.onSource(write.ID, []byte("v2"), nil)
+	status, _ = m.get(1)
+	assert.Equal(t, uint64(1), status.DivergedWrites)
+
+	// the write never responded by the source cluster is expired
+	dualWrite(false)
+	expired := write
+	expired.Deadline = time.Now().Add(-migrationPendingTimeout * 2).UnixNano()
+	assert.False(t, m.dispatch(expired, nil, nil))
+	m.mu.lastExpired = time.Time{}
+	assert.False(t, m.dispatch(read, nil, nil))
+	status, _ = m.get(1)
+	assert.Equal(t, 0, status.Pending)
+	assert.Equal(t, uint64(1), status.DivergedWrites)
+}
+
+func TestGroupMigrationSwitch(t *testing.T) {
+	m := newGroupMigrations(log.GetDefaultZapLogger())
+	target := &testMigrationTarget{value: []byte("v1")}
+	var successes []rpcpb.Response
+	success := func(resp rpcpb.Response) { successes = append(successes, resp) }
+	failure := func(id []byte, err error) { assert.FailNow(t, "unexpected failure") }
+
+	write := rpcpb.Request{ID: []byte("w1"), Group: 1, Type: rpcpb.Write}
+	read := rpcpb.Request{ID: []byte("r1"), Group: 1, Type: rpcpb.Read}
+	assert.False(t, m.follow(1))
+	assert.NoError(t, m.set(GroupMigration{Group: 1, Phase: MigrationReplicating, Target: target}))
+	assert.NoError(t, m.set(GroupMigration{Group: 1, Phase: MigrationDualWrite}))
+	assert.False(t, m.dispatch(write, success, failure))
+
+	// switched by the source cluster, the requests are forwarded once the
+	// requests before the switch are replicated
+	assert.True(t, m.follow(1))
+	assert.True(t, m.dispatch(read, success, failure))
+	assert.Empty(t, target.requests)
+	m.onSource(write.ID, []byte("v1"), nil)
+	assert.Equal(t, 2, len(target.requests))
+	assert.Equal(t, write.ID, target.requests[0].ID)
+	assert.Equal(t, read.ID, target.requests[1].ID)
+	assert.Equal(t, 1, len(successes))
+	assert.Equal(t, read.ID, successes[0].ID)
+	assert.Equal(t, []byte("v1"), successes[0].Value)
+
+	// the retry of the request rejected by the migrated shard
+	successes = successes[:0]
+	m.remove(1)
+	assert.NoError(t, m.set(GroupMigration{Group: 1, Phase: MigrationReplicating, Target: target}))
+	assert.NoError(t, m.set(GroupMigration{Group: 1, Phase: MigrationDualWrite}))
+	assert.False(t, m.dispatch(write, success, failure))
+	assert.True(t, m.follow(1))
+	assert.True(t, m.dispatch(write, success, failure))
+	assert.Equal(t, 1, len(successes))
+	status, _ := m.get(1)
+	assert.Equal(t, 0, status.Pending)
+}
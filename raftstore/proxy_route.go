@@ -0,0 +1,236 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package raftstore
+
+import (
+	"bytes"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/matrixorigin/matrixcube/components/log"
+	"github.com/matrixorigin/matrixcube/pb/rpcpb"
+	"go.uber.org/zap"
+)
+
+const (
+	// routeDoubtWindow the doubts of a shard older than it are forgotten
+	routeDoubtWindow = time.Minute
+)
+
+// routeLookup returns the authoritative routes of the shards held by the prophet
+type routeLookup func(req rpcpb.GetShardRouteReq) (rpcpb.GetShardRouteRsp, error)
+
+type routeDoubt struct {
+	count int
+	since time.Time
+}
+
+// routeResolver looks up the routes from the prophet for the proxy, instead of
+// waiting for the watcher events, once the route of a shard is missing in the
+// router or is doubted repeatedly, e.g. the requests to the shard failed with the
+// NotLeader error for threshold times. The doubted shards and the missing keys
+// are collected and looked up in batch by a background worker, one lookup per
+// group, so the keys held by the same shard cost a single lookup. The router is
+// updated with the routes not older than the ones it holds.
+type routeResolver struct {
+	sync.Mutex
+
+	logger    *zap.Logger
+	router    Router
+	lookup    routeLookup
+	threshold int
+	doubts    map[uint64]*routeDoubt
+	// pendingShards and pendingKeys are the doubted shards and the missing keys of
+	// each group to look up in the next batch
+	pendingShards map[uint64]struct{}
+	pendingKeys   map[uint64]map[string]struct{}
+	notifyC       chan struct{}
+	stopC         chan struct{}
+	stopped       bool
+	wg            sync.WaitGroup
+}
+
+func newRouteResolver(logger *zap.Logger, router Router, threshold int, lookup routeLookup) *routeResolver {
+	return &routeResolver{
+		logger:        logger,
+		router:        router,
+		lookup:        lookup,
+		threshold:     threshold,
+		doubts:        make(map[uint64]*routeDoubt),
+		pendingShards: make(map[uint64]struct{}),
+		pendingKeys:   make(map[uint64]map[string]struct{}),
+		notifyC:       make(chan struct{}, 1),
+		stopC:         make(chan struct{}),
+	}
+}
+
+// start starts the background worker looking up the pending routes.
+func (rr *routeResolver) start() {
+	rr.wg.Add(1)
+	go func() {
+		defer rr.wg.Done()
+		for {
+			select {
+			case <-rr.stopC:
+				return
+			case <-rr.notifyC:
+				rr.resolvePending()
+			}
+		}
+	}()
+}
+
+// doubt records a doubt of the route of the shard, the route is looked up once
+// the shard is doubted for threshold times within the routeDoubtWindow.
+func (rr *routeResolver) doubt(shardID uint64, now time.Time) {
+	rr.Lock()
+	defer rr.Unlock()
+
+	d, ok := rr.doubts[shardID]
+	if !ok || now.Sub(d.since) > routeDoubtWindow {
+		d = &routeDoubt{since: now}
+		rr.doubts[shardID] = d
+	}
+	d.count++
+	if d.count < rr.threshold {
+		return
+	}
+
+	delete(rr.doubts, shardID)
+	if rr.stopped {
+		return
+	}
+	rr.pendingShards[shardID] = struct{}{}
+	rr.notifyLocked()
+}
+
+// missing looks up the route of the key which has no shard in the router.
+func (rr *routeResolver) missing(group uint64, key []byte) {
+	rr.Lock()
+	defer rr.Unlock()
+
+	if rr.stopped {
+		return
+	}
+	keys, ok := rr.pendingKeys[group]
+	if !ok {
+		keys = make(map[string]struct{})
+		rr.pendingKeys[group] = keys
+	}
+	keys[string(key)] = struct{}{}
+	rr.notifyLocked()
+}
+
+func (rr *routeResolver) notifyLocked() {
+	select {
+	case rr.notifyC <- struct{}{}:
+	default:
+	}
+}
+
+// resolvePending looks up the pending shards and keys. The keys routed by the
+// router meanwhile, e.g. covered by the shards of the previous batch, are skipped.
+func (rr *routeResolver) resolvePending() {
+	rr.Lock()
+	shards, keys := rr.pendingShards, rr.pendingKeys
+	rr.pendingShards = make(map[uint64]struct{})
+	rr.pendingKeys = make(map[uint64]map[string]struct{})
+	rr.Unlock()
+
+	var reqs []rpcpb.GetShardRouteReq
+	for group, groupKeys := range keys {
+		req := rpcpb.GetShardRouteReq{Group: group}
+		for key := range groupKeys {
+			if rr.router.SelectShardIDByKey(group, []byte(key)) == 0 {
+				req.Keys = append(req.Keys, []byte(key))
+			}
+		}
+		if len(req.Keys) > 0 {
+			sort.Slice(req.Keys, func(i, j int) bool {
+				return bytes.Compare(req.Keys[i], req.Keys[j]) < 0
+			})
+			reqs = append(reqs, req)
+		}
+	}
+	sort.Slice(reqs, func(i, j int) bool { return reqs[i].Group < reqs[j].Group })
+	if len(shards) > 0 {
+		if len(reqs) == 0 {
+			reqs = append(reqs, rpcpb.GetShardRouteReq{})
+		}
+		for id := range shards {
+			reqs[0].ShardIDs = append(reqs[0].ShardIDs, id)
+		}
+		sort.Slice(reqs[0].ShardIDs, func(i, j int) bool {
+			return reqs[0].ShardIDs[i] < reqs[0].ShardIDs[j]
+		})
+	}
+
+	for _, req := range reqs {
+		rr.resolve(req)
+	}
+}
+
+func (rr *routeResolver) resolve(req rpcpb.GetShardRouteReq) {
+	rsp, err := rr.lookup(req)
+	if err != nil {
+		rr.logger.Error("fail to lookup shard routes",
+			zap.Uint64s("shards", req.ShardIDs),
+			zap.Uint64("group", req.Group),
+			zap.Int("keys", len(req.Keys)),
+			zap.Error(err))
+		return
+	}
+
+	for _, route := range rsp.Routes {
+		rr.updateRoute(route)
+	}
+}
+
+func (rr *routeResolver) updateRoute(route rpcpb.ShardRoute) {
+	// the router may be updated by the watcher with a newer shard after the
+	// lookup, e.g. the shard is split, never roll it back
+	if current := rr.router.GetShard(route.Shard.ID); current.ID > 0 &&
+		isEpochStale(route.Shard.Epoch, current.Epoch) {
+		rr.logger.Debug("skip stale shard route",
+			log.ShardField("shard", route.Shard),
+			log.EpochField("current-epoch", current.Epoch))
+		return
+	}
+
+	rr.router.UpdateShard(route.Shard)
+	if route.Leader.ID > 0 {
+		if route.LeaderStore.ID > 0 {
+			rr.router.UpdateStore(route.LeaderStore)
+		}
+		rr.router.UpdateLeader(route.Shard.ID, route.Leader.ID)
+	}
+	if ce := rr.logger.Check(zap.DebugLevel, "shard route updated by lookup"); ce != nil {
+		ce.Write(log.ShardField("shard", route.Shard),
+			log.ReplicaField("leader", route.Leader))
+	}
+}
+
+// stop stops the new lookups and waits for the lookup in flight.
+func (rr *routeResolver) stop() {
+	rr.Lock()
+	if rr.stopped {
+		rr.Unlock()
+		return
+	}
+	rr.stopped = true
+	close(rr.stopC)
+	rr.Unlock()
+	rr.wg.Wait()
+}
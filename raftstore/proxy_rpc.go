@@ -14,6 +14,8 @@
 package raftstore
 
 import (
+	"time"
+
 	"github.com/fagongzi/goetty"
 	"github.com/fagongzi/goetty/codec/length"
 	"github.com/matrixorigin/matrixcube/components/log"
@@ -25,6 +27,8 @@ type proxyRPC interface {
 	start() error
 	stop()
 	onResponse(header rpcpb.ResponseBatchHeader, rsp rpcpb.Response)
+	// broadcast sends the response to all the connected proxies
+	broadcast(rsp rpcpb.Response)
 }
 
 type defaultRPC struct {
@@ -71,6 +75,7 @@ func (r *defaultRPC) stop() {
 func (r *defaultRPC) onMessage(rs goetty.IOSession, value interface{}, seq uint64) error {
 	req := value.(rpcpb.Request)
 	req.PID = int64(rs.ID())
+	resetRequestDeadline(&req, time.Now())
 	err := r.handler(req)
 	if err != nil {
 		rsp := rpcpb.Response{}
@@ -83,7 +88,9 @@ func (r *defaultRPC) onMessage(rs goetty.IOSession, value interface{}, seq uint6
 
 func (r *defaultRPC) onResponse(header rpcpb.ResponseBatchHeader, rsp rpcpb.Response) {
 	if rs, _ := r.app.GetSession(uint64(rsp.PID)); rs != nil {
-		rsp.Error = header.Error
+		if !header.IsEmpty() {
+			rsp.Error = header.Error
+		}
 		if ce := r.logger.Check(zap.DebugLevel, "rpcpb received response"); ce != nil {
 			ce.Write(log.HexField("id", rsp.ID),
 				log.RaftResponseField("response", &rsp))
@@ -97,3 +104,10 @@ func (r *defaultRPC) onResponse(header rpcpb.ResponseBatchHeader, rsp rpcpb.Resp
 		}
 	}
 }
+
+func (r *defaultRPC) broadcast(rsp rpcpb.Response) {
+	if err := r.app.Broadcast(rsp); err != nil {
+		r.logger.Debug("fail to broadcast response",
+			zap.Error(err))
+	}
+}
@@ -25,7 +25,10 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/matrixorigin/matrixcube/components/log"
+	"github.com/matrixorigin/matrixcube/pb/errorpb"
+	"github.com/matrixorigin/matrixcube/pb/metapb"
 	"github.com/matrixorigin/matrixcube/pb/rpcpb"
+	"github.com/matrixorigin/matrixcube/storage"
 	"github.com/matrixorigin/matrixcube/util/leaktest"
 	"github.com/matrixorigin/matrixcube/util/testutil"
 )
@@ -133,6 +136,263 @@ func TestLocalDispatch(t *testing.T) {
 	}
 }
 
+func TestRequestTimeout(t *testing.T) {
+	now := time.Now()
+	req := rpcpb.Request{}
+	assert.True(t, setRequestTimeout(&req, now))
+	assert.Equal(t, int64(0), req.Timeout)
+	resetRequestDeadline(&req, now)
+	assert.Equal(t, int64(0), req.Deadline)
+
+	req.Deadline = now.Add(time.Second).UnixNano()
+	assert.True(t, setRequestTimeout(&req, now))
+	assert.Equal(t, int64(time.Second), req.Timeout)
+
+	// the deadline is reset on the clock of the receiving store
+	received := now.Add(time.Hour)
+	resetRequestDeadline(&req, received)
+	assert.Equal(t, received.Add(time.Second).UnixNano(), req.Deadline)
+	assert.False(t, setRequestTimeout(&req, received.Add(time.Second*2)))
+}
+
+func TestDispatchWithDeadline(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	fc := make(chan error, 1)
+	failure := func(id []byte, e error) {
+		select {
+		case fc <- e:
+		default:
+		}
+	}
+	rr, err := newRouterBuilder().build(make(chan rpcpb.EventNotify))
+	assert.NoError(t, err)
+	sp, err := newShardsProxyBuilder().
+		withRetryInterval(time.Second).
+		withBackendFactory(newTestBackendFactory()).
+		withRequestCallback(func(rpcpb.Response) {}, failure).
+		build(rr)
+	assert.NoError(t, err)
+	rc := newMockRetryController()
+	sp.SetRetryController(rc)
+
+	// expired before dispatching
+	req := rpcpb.Request{ID: []byte("k1"), Key: []byte("k1"),
+		Deadline: time.Now().Add(-time.Second).UnixNano()}
+	assert.Equal(t, ErrTimeout, sp.Dispatch(req))
+
+	// the deadline passes before the retry, failed without waiting for it
+	req.Deadline = time.Now().Add(time.Millisecond * 100).UnixNano()
+	rc.setRequest(req, time.Minute)
+	assert.NoError(t, sp.Dispatch(req))
+	select {
+	case e := <-fc:
+		assert.Equal(t, ErrTimeout, e)
+	case <-time.After(time.Millisecond * 50):
+		assert.Fail(t, "need failure callback")
+	}
+
+	// the request timeout error is not retried
+	sp.(*shardsProxy).done(rpcpb.Response{ID: req.ID,
+		Error: errorpb.Error{Message: ErrTimeout.Error(),
+			RequestTimeout: &errorpb.RequestTimeout{ShardID: 1}}})
+	select {
+	case e := <-fc:
+		assert.Equal(t, ErrTimeout, e)
+	case <-time.After(time.Millisecond * 50):
+		assert.Fail(t, "need failure callback")
+	}
+}
+
+func TestDispatchWithDispatchWorkers(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	sc := make(chan rpcpb.Response, 1)
+	success := func(r rpcpb.Response) { sc <- r }
+	factory := newTestBackendFactory()
+	rr, err := newRouterBuilder().build(make(chan rpcpb.EventNotify))
+	assert.NoError(t, err)
+	sp, err := newShardsProxyBuilder().
+		withBackendFactory(factory).
+		withRequestCallback(success, nil).
+		withDispatchWorkers(16, time.Millisecond).
+		build(rr)
+	assert.NoError(t, err)
+	defer sp.Stop()
+
+	req := rpcpb.Request{ID: []byte("k1")}
+	factory.backends["b1"] = newLocalBackend(func(r rpcpb.Request) error {
+		sp.OnResponse(rpcpb.ResponseBatch{Responses: []rpcpb.Response{{ID: r.ID}}})
+		return nil
+	})
+	assert.NoError(t, sp.DispatchTo(req, Shard{}, "b1"))
+	select {
+	case rsp := <-sc:
+		assert.Equal(t, rpcpb.Response{ID: req.ID}, rsp)
+	case <-time.After(time.Second):
+		assert.Fail(t, "need succ")
+	}
+	_, ok := sp.(*shardsProxy).getBackend("b1").(*storeDispatcher)
+	assert.True(t, ok)
+}
+
+func TestDispatchWithConsistentRouting(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	sc := make(chan rpcpb.Response, 1)
+	success := func(r rpcpb.Response) { sc <- r }
+	factory := newTestBackendFactory()
+	rr, err := newRouterBuilder().build(make(chan rpcpb.EventNotify))
+	assert.NoError(t, err)
+
+	var lookups []rpcpb.GetShardRouteReq
+	var mu sync.Mutex
+	lookup := func(req rpcpb.GetShardRouteReq) (rpcpb.GetShardRouteRsp, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		lookups = append(lookups, req)
+		return rpcpb.GetShardRouteRsp{Routes: []rpcpb.ShardRoute{{
+			Shard:       Shard{ID: 1, Group: 1, Replicas: []Replica{{ID: 2, StoreID: 3}}},
+			Leader:      Replica{ID: 2, StoreID: 3},
+			LeaderStore: metapb.Store{ID: 3, ClientAddress: "b1"},
+		}}}, nil
+	}
+	sp, err := newShardsProxyBuilder().
+		withRetryInterval(time.Millisecond*10).
+		withBackendFactory(factory).
+		withRequestCallback(success, nil).
+		withConsistentRouting(2, lookup).
+		build(rr)
+	assert.NoError(t, err)
+	defer sp.Stop()
+
+	rc := newMockRetryController()
+	sp.SetRetryController(rc)
+	factory.backends["b1"] = newLocalBackend(func(r rpcpb.Request) error {
+		sp.OnResponse(rpcpb.ResponseBatch{Responses: []rpcpb.Response{{ID: r.ID}}})
+		return nil
+	})
+
+	// the route missing in the router is looked up
+	req := rpcpb.Request{ID: []byte("k1"), Key: []byte("k1"), Group: 1}
+	rc.setRequest(req, time.Second*10)
+	assert.NoError(t, sp.Dispatch(req))
+	select {
+	case rsp := <-sc:
+		assert.Equal(t, req.ID, rsp.ID)
+	case <-time.After(time.Second * 5):
+		assert.Fail(t, "need succ")
+	}
+	assert.Equal(t, uint64(2), rr.GetShard(1).Replicas[0].ID)
+	assert.Equal(t, "b1", rr.LeaderReplicaStore(1).ClientAddress)
+
+	mu.Lock()
+	assert.Equal(t, []rpcpb.GetShardRouteReq{{Group: 1, Keys: [][]byte{[]byte("k1")}}}, lookups)
+	mu.Unlock()
+}
+
+func TestRouteResolverBatch(t *testing.T) {
+	rr, err := newRouterBuilder().build(make(chan rpcpb.EventNotify))
+	assert.NoError(t, err)
+	rr.UpdateShard(Shard{ID: 2, Group: 1, Start: []byte("a"), End: []byte("b"),
+		Epoch: Epoch{Generation: 2}})
+
+	var lookups []rpcpb.GetShardRouteReq
+	rs := newRouteResolver(log.Adjust(nil), rr, 1, func(req rpcpb.GetShardRouteReq) (rpcpb.GetShardRouteRsp, error) {
+		lookups = append(lookups, req)
+		if req.Group != 1 {
+			return rpcpb.GetShardRouteRsp{}, nil
+		}
+		return rpcpb.GetShardRouteRsp{Routes: []rpcpb.ShardRoute{
+			{Shard: Shard{ID: 2, Group: 1, Start: []byte("a"), End: []byte("c"),
+				Epoch: Epoch{Generation: 1}}},
+			{Shard: Shard{ID: 1, Group: 1, Start: []byte("k"), End: []byte("l"),
+				Epoch: Epoch{Generation: 1}}},
+		}}, nil
+	})
+	defer rs.stop()
+
+	// the keys routed by the router are skipped, the others are looked up with
+	// the doubted shards in one lookup per group
+	rs.missing(1, []byte("a1"))
+	rs.missing(1, []byte("k2"))
+	rs.missing(1, []byte("k1"))
+	rs.missing(1, []byte("k1"))
+	rs.missing(2, []byte("x"))
+	rs.doubt(3, time.Now())
+	rs.doubt(2, time.Now())
+	rs.resolvePending()
+	assert.Equal(t, []rpcpb.GetShardRouteReq{
+		{ShardIDs: []uint64{2, 3}, Group: 1, Keys: [][]byte{[]byte("k1"), []byte("k2")}},
+		{Group: 2, Keys: [][]byte{[]byte("x")}},
+	}, lookups)
+
+	// the route older than the one in the router is skipped
+	assert.Equal(t, []byte("b"), rr.GetShard(2).End)
+	assert.Equal(t, uint64(2), rr.GetShard(2).Epoch.Generation)
+	assert.Equal(t, uint64(1), rr.SelectShardIDByKey(1, []byte("k3")))
+
+	// the key covered by the shard looked up before is not looked up again
+	lookups = nil
+	rs.missing(1, []byte("k3"))
+	rs.resolvePending()
+	assert.Empty(t, lookups)
+}
+
+func TestRouteDoubtsExpired(t *testing.T) {
+	rr, err := newRouterBuilder().build(make(chan rpcpb.EventNotify))
+	assert.NoError(t, err)
+	var lookups []rpcpb.GetShardRouteReq
+	rs := newRouteResolver(log.Adjust(nil), rr, 2, func(req rpcpb.GetShardRouteReq) (rpcpb.GetShardRouteRsp, error) {
+		lookups = append(lookups, req)
+		return rpcpb.GetShardRouteRsp{}, fmt.Errorf("not found")
+	})
+	defer rs.stop()
+
+	now := time.Now()
+	rs.doubt(1, now)
+	rs.doubt(1, now.Add(routeDoubtWindow+time.Second))
+	rs.resolvePending()
+	assert.Empty(t, lookups)
+	rs.doubt(1, now.Add(routeDoubtWindow+time.Second))
+	rs.resolvePending()
+	assert.Equal(t, []rpcpb.GetShardRouteReq{{ShardIDs: []uint64{1}}}, lookups)
+}
+
+func TestOnReadValueResponse(t *testing.T) {
+	var values [][]byte
+	success := func(r rpcpb.Response) { values = append(values, r.Value) }
+	rr, err := newRouterBuilder().build(make(chan rpcpb.EventNotify))
+	assert.NoError(t, err)
+	sp, err := newShardsProxyBuilder().
+		withRequestCallback(success, nil).
+		build(rr)
+	assert.NoError(t, err)
+
+	released := 0
+	data := []byte("value")
+	newValue := func() *storage.ReadValue {
+		return storage.NewReadValue(data, func() { released++ })
+	}
+
+	// the value is copied without the read value callback
+	sp.OnReadValueResponse(rpcpb.Response{ID: []byte("k1")}, newValue())
+	assert.Equal(t, 1, released)
+	assert.Equal(t, [][]byte{data}, values)
+	data[0] = 'V'
+	assert.Equal(t, []byte("value"), values[0])
+
+	var rv *storage.ReadValue
+	sp.SetReadValueCallback(func(r rpcpb.Response, v *storage.ReadValue) { rv = v })
+	sp.OnReadValueResponse(rpcpb.Response{ID: []byte("k2")}, newValue())
+	assert.Equal(t, 1, released)
+	assert.Equal(t, 1, len(values))
+	if assert.NotNil(t, rv) {
+		rv.Release()
+		assert.Equal(t, 2, released)
+	}
+}
+
 func TestRPCDispatch(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 
@@ -214,3 +474,21 @@ func TestRPCDispatch(t *testing.T) {
 		assert.Fail(t, "need succ")
 	}
 }
+
+func TestKeysRangeInShard(t *testing.T) {
+	cases := []struct {
+		shard  Shard
+		keys   rpcpb.Range
+		expect bool
+	}{
+		{shard: Shard{}, keys: rpcpb.Range{}, expect: true},
+		{shard: Shard{Start: []byte("b"), End: []byte("d")}, keys: rpcpb.Range{From: []byte("b"), To: []byte("d")}, expect: true},
+		{shard: Shard{Start: []byte("b"), End: []byte("d")}, keys: rpcpb.Range{From: []byte("a"), To: []byte("c")}, expect: false},
+		{shard: Shard{Start: []byte("b"), End: []byte("d")}, keys: rpcpb.Range{From: []byte("b"), To: []byte("e")}, expect: false},
+		{shard: Shard{Start: []byte("b"), End: []byte("d")}, keys: rpcpb.Range{From: []byte("b")}, expect: false},
+		{shard: Shard{Start: []byte("b")}, keys: rpcpb.Range{From: []byte("b")}, expect: true},
+	}
+	for i, c := range cases {
+		assert.Equal(t, c.expect, keysRangeInShard(&c.keys, c.shard), "index %d", i)
+	}
+}
@@ -15,6 +15,7 @@ package raftstore
 
 import (
 	"bytes"
+	"time"
 
 	"github.com/matrixorigin/matrixcube/components/log"
 	"github.com/matrixorigin/matrixcube/pb/rpcpb"
@@ -27,6 +28,8 @@ type requestExecutor func(req rpcpb.Request)
 type readyRead struct {
 	batch batch
 	index uint64
+	// start is the time when the ReadIndex request was issued
+	start time.Time
 }
 
 type readIndexQueue struct {
@@ -67,10 +70,13 @@ func (q *readIndexQueue) leaderChanged(newLeader Replica) {
 func (q *readIndexQueue) append(c batch) {
 	q.reads = append(q.reads, readyRead{
 		batch: c,
+		start: time.Now(),
 	})
 }
 
-func (q *readIndexQueue) ready(state raft.ReadState) {
+// ready marks the read as ready to be executed once the specified index is
+// applied, the time when the ReadIndex request was issued is returned.
+func (q *readIndexQueue) ready(state raft.ReadState) (time.Time, bool) {
 	if ce := q.logger.Check(zap.DebugLevel, "read index ready"); ce != nil {
 		ce.Write(log.IndexField(state.Index),
 			log.HexField("batch-id", state.RequestCtx))
@@ -81,9 +87,10 @@ func (q *readIndexQueue) ready(state raft.ReadState) {
 			q.reads[idx].index = state.Index
 			q.readyCount++
 			q.lastReadyIdx = idx
-			return
+			return q.reads[idx].start, true
 		}
 	}
+	return time.Time{}, false
 }
 
 func (q *readIndexQueue) process(appliedIndex uint64, exector requestExecutor) bool {
@@ -201,3 +201,18 @@ func TestReadIndexQueueProcessWithReadyApplied(t *testing.T) {
 	assert.Equal(t, 1, q.readyCount)
 	assert.Equal(t, 0, q.lastReadyIdx)
 }
+
+func TestReadIndexQueueReadyReturnsStartTime(t *testing.T) {
+	q := newReadIndexQueue(1, nil)
+	q.append(newTestBatch("1", "k1", 1, rpcpb.Write, 0, nil))
+
+	start, ok := q.ready(raft.ReadState{
+		Index:      1,
+		RequestCtx: q.reads[0].batch.getRequestID(),
+	})
+	assert.True(t, ok)
+	assert.Equal(t, q.reads[0].start, start)
+
+	_, ok = q.ready(raft.ReadState{Index: 1, RequestCtx: []byte("unknown")})
+	assert.False(t, ok)
+}
@@ -16,6 +16,7 @@ package raftstore
 import (
 	"context"
 	"fmt"
+	"sort"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -38,6 +39,7 @@ import (
 	"github.com/matrixorigin/matrixcube/util/task"
 	"go.etcd.io/etcd/raft/v3"
 	"go.etcd.io/etcd/raft/v3/raftpb"
+	trackerPkg "go.etcd.io/etcd/raft/v3/tracker"
 	"go.uber.org/zap"
 )
 
@@ -74,6 +76,8 @@ type replica struct {
 	snapshotter          *snapshotter
 	incomingProposals    *proposalBatch
 	pendingReads         *readIndexQueue
+	lease                *readLease
+	resolvedTS           *resolvedTS
 	pendingProposals     *pendingProposals
 	readStopper          *stop.Stopper
 	sm                   *stateMachine
@@ -90,8 +94,43 @@ type replica struct {
 	// pushedIndex is the log index that has been passed to the state machine to
 	// be applied
 	pushedIndex uint64
-	stats       *replicaStats
-	metrics     localMetrics
+	// pendingApplyEntries the committed entries passed to the state machine but
+	// not applied yet as the apply is paused to wait for the merge source
+	pendingApplyEntries []raftpb.Entry
+	// lastReadIndexTime is the time when the last ReadIndex was issued or
+	// confirmed, it must be accessed in event worker
+	lastReadIndexTime time.Time
+	stats             *replicaStats
+	metrics           localMetrics
+	// stageMetrics observes the durations of the proposal stages
+	stageMetrics *metric.ProposalStageObservers
+	// recoveringAt is the unix nano time the replica started to recover from a
+	// snapshot, 0 if the replica is not recovering. lastRecoverCost is the
+	// nanoseconds taken by the last recovery, used to estimate the time left.
+	recoveringAt    int64
+	lastRecoverCost int64
+	// hotKeys tracks the hot keys shipped to the new leader for warming up
+	hotKeys hotKeys
+	// warmupKeys the hot keys received from the previous leader and warmupTerm
+	// the term of the leadership being warmed up, they must be accessed in event
+	// worker
+	warmupKeys [][]byte
+	warmupTerm uint64
+	// transferTarget is set once the MsgTimeoutNow of a planned leader transfer
+	// is received, it must be accessed in event worker
+	transferTarget bool
+	// fencedSince is the time the leader found the shard fenced, it's used to
+	// unfence the shard after the fence timeout. It must be accessed in event
+	// worker
+	fencedSince time.Time
+	// drainTarget is the replica the leadership transferred to when the store is
+	// draining, it's the leader hint of the rejected requests. It must be accessed
+	// in event worker
+	drainTarget Replica
+	// mergeDeadline is the unix nano time the merge of the frozen shard must be
+	// completed by, the merge is aborted once it passed. 0 means the deadline is
+	// set by the first check of the frozen shard.
+	mergeDeadline int64
 
 	initialized bool
 	closedC     chan struct{}
@@ -103,8 +142,18 @@ type replica struct {
 	// necessarily up-to-date.
 	// this map must access in event worker
 	committedIndexes map[uint64]uint64 // replica-id -> committed index(saved into logdb)
+	// appliedIndexes the applied index reported by all replicas, it's used as the
+	// base index of the delta snapshots. It must access in event worker.
+	appliedIndexes map[uint64]uint64 // replica-id -> applied index
 	// lastCommittedIndex last committed log
 	lastCommittedIndex uint64
+	// messageFilter drops the useless raft messages, it must access in event worker
+	messageFilter *messageFilter
+	// credits the proposal credits advertised to the proxies, nil if the flow
+	// control is disabled
+	credits *replicaCredits
+	// load the load of the replica checked by the admission control
+	load replicaLoad
 
 	destroyTaskFactory destroyReplicaTaskFactory
 	destroyTaskMu      struct {
@@ -115,6 +164,7 @@ type replica struct {
 
 	tickTotalCount   uint64
 	tickHandledCount uint64
+	readIndexCount   uint64
 
 	feature storage.Feature
 }
@@ -124,6 +174,15 @@ type replica struct {
 // 2. Goroutine that calls start method of store: Load all local shards.
 // 3. Prophet event loop: Create shard dynamically.
 func newReplica(store *store, shard Shard, r Replica, reason string) (*replica, error) {
+	return newReplicaWithDataStorage(store, shard, r, store.DataStorageByShard(shard), reason)
+}
+
+// newReplicaWithDataStorage creates the replica on the specified data storage,
+// which may be different from the one resolved for the shard, e.g. the shard
+// created by split is on the data storage of the split shard. The replica is
+// moved to the resolved data storage after started.
+func newReplicaWithDataStorage(store *store, shard Shard, r Replica,
+	ds storage.DataStorage, reason string) (*replica, error) {
 	l := store.logger.With(store.storeField(), log.ShardIDField(shard.ID), log.ReplicaIDField(r.ID))
 
 	l.Info("begin to create replica",
@@ -157,6 +216,8 @@ func newReplica(store *store, shard Shard, r Replica, reason string) (*replica,
 		pendingProposals:  newPendingProposals(),
 		incomingProposals: newProposalBatch(l, maxBatchSize, shard.ID, r),
 		pendingReads:      newReadIndexQueue(shard.ID, l),
+		lease:             newReadLease(store.cfg.Raft.GetLeaseReadDuration()),
+		resolvedTS:        newResolvedTS(),
 		snapshotter:       snapshotter,
 		ticks:             task.New(32),
 		messages:          task.New(32),
@@ -169,21 +230,34 @@ func newReplica(store *store, shard Shard, r Replica, reason string) (*replica,
 		unloadedC:         make(chan struct{}),
 		destroyedC:        make(chan struct{}),
 		committedIndexes:  make(map[uint64]uint64),
+		appliedIndexes:    make(map[uint64]uint64),
+		messageFilter:     newMessageFilter(),
 	}
 	// we are not guaranteed to have a prophet client in tests
 	if store.pd != nil {
 		pr.prophetClient = store.pd.GetClient()
 	}
 
-	storage := store.DataStorageByGroup(shard.Group)
 	pr.sm = newStateMachine(l,
-		storage, pr.logdb, shard, r, pr,
+		ds, pr.logdb, shard, r, pr,
 		func() *replicaCreator {
 			return newReplicaCreator(store)
 		})
+	pr.sm.customAdminHandlers = pr.cfg.Customize.CustomAdminCmdHandlers
+	pr.sm.metadataInterceptor = pr.cfg.Customize.CustomShardMetadataInterceptor
+	if pr.cfg.Proxy.EnableFlowControl {
+		pr.credits = newReplicaCredits(pr.cfg.Proxy.MaxShardCredits)
+	}
+	stageGroup := ""
+	if pr.cfg.Metric.ProposalStagesByGroup {
+		stageGroup = format.Uint64ToString(pr.group)
+	}
+	pr.stageMetrics = metric.NewProposalStageObservers(format.Uint64ToString(pr.storeID), stageGroup)
+	pr.pendingProposals.stageMetrics = pr.stageMetrics
+	pr.sm.stageMetrics = pr.stageMetrics
 	pr.destroyTaskFactory = newDefaultDestroyReplicaTaskFactory(pr.addAction,
 		pr.prophetClient, defaultCheckInterval)
-	pr.feature = storage.Feature()
+	pr.feature = ds.Feature()
 	return pr, nil
 }
 
@@ -195,10 +269,12 @@ func (pr *replica) start(campaign bool) {
 		stop.WithLogger(pr.logger))
 
 	shard := pr.getShard()
-	if err := pr.snapshotter.prepareReplicaSnapshotDir(); err != nil {
+	removed, err := pr.snapshotter.prepareReplicaSnapshotDir()
+	if err != nil {
 		pr.logger.Fatal("failed to create replica snapshot dir",
 			zap.Error(err))
 	}
+	pr.store.startup.addOrphanSnapshots(removed)
 	// pr.initAppliedIndex must be called before pr.initConfState()
 	if err := pr.initAppliedIndex(); err != nil {
 		pr.logger.Fatal("failed to initialize applied index",
@@ -227,8 +303,14 @@ func (pr *replica) start(campaign bool) {
 	}
 
 	pr.setStarted()
+	// the replica created by split or loaded on restart may be not on the data
+	// storage resolved for it
+	pr.addMoveDataStorageAction()
 	// If this shard has only one replica and I am the one, campaign directly.
-	if campaign {
+	if campaign && pr.hasLowerLeaderPriority(shard.Group) {
+		pr.logger.Info("skip campaign",
+			log.ReasonField("lower leader priority"))
+	} else if campaign {
 		pr.logger.Info("try to campaign",
 			log.ReasonField("restart"))
 		pr.addAction(action{actionType: campaignAction})
@@ -331,7 +413,7 @@ func (pr *replica) initAppliedIndex() error {
 	}
 
 	pr.sm.updateAppliedIndexTerm(index, term)
-	pr.appliedIndex = index
+	pr.setAppliedIndex(index)
 	pr.pushedIndex = index
 	pr.logger.Info("applied index loaded",
 		log.IndexField(pr.appliedIndex))
@@ -361,14 +443,7 @@ func (pr *replica) initConfState() error {
 			log.SnapshotField(ss),
 			zap.Uint64("applied-index", pr.appliedIndex))
 	} else {
-		shard := pr.getShard()
-		for _, p := range shard.Replicas {
-			if p.Role == metapb.ReplicaRole_Voter {
-				confState.Voters = append(confState.Voters, p.ID)
-			} else if p.Role == metapb.ReplicaRole_Learner {
-				confState.Learners = append(confState.Learners, p.ID)
-			}
-		}
+		confState = getConfState(pr.getShard())
 		pr.logger.Info("init conf state loaded from dataStorage metadata")
 	}
 	pr.logger.Info("init conf state loaded",
@@ -461,14 +536,91 @@ func (pr *replica) notifyWorker() {
 }
 
 func (pr *replica) doCampaign() error {
+	if pr.isWitness() {
+		pr.logger.Info("skip campaign",
+			log.ReasonField("witness"))
+		return nil
+	}
 	return pr.rn.Campaign()
 }
 
+// hasLowerLeaderPriority returns true if the replicas of the shard group on some
+// stores have a higher leader priority than the replica on this store. Such
+// replica doesn't campaign proactively, the replicas preferred to be leader are
+// left to win the election.
+func (pr *replica) hasLowerLeaderPriority(group uint64) bool {
+	replication := &pr.cfg.Prophet.Replication
+	return replication.GetLeaderPriority(group, pr.cfg.GetLabels()) <
+		replication.GetMaxLeaderPriority(group)
+}
+
 func (pr *replica) onReq(req rpcpb.Request, cb func(rpcpb.ResponseBatch)) error {
 	metric.IncComandCount(format.Uint64ToString(req.CustomType))
+	if target, _ := pr.sm.getMergeTarget(); target > 0 {
+		// the range of the frozen shard is going to be taken over by the target
+		// shard, the clients retry after the routes are refreshed
+		respShardUnavailable(pr.shardID, req, cb)
+		return nil
+	}
+	if recovering, estimated := pr.recovering(time.Now()); recovering {
+		// the data storage is being rewritten by the snapshot, all requests
+		// must wait behind the recovery, the reads are not allowed to bypass the
+		// queue with the lease or the staleness
+		if pr.cfg.Snapshot.RecoveringPolicy == config.RecoveringReject ||
+			uint64(pr.requests.Len()) >= pr.cfg.Snapshot.MaxRecoveringRequests {
+			respShardRecovering(pr.shardID, estimated, req, cb)
+			return nil
+		}
+		return pr.addRequest(newReqCtx(req, cb))
+	}
+	if pr.tryLeaseRead(req) || pr.tryStaleRead(req) || pr.tryDiagnose(req, cb) {
+		return nil
+	}
 	return pr.addRequest(newReqCtx(req, cb))
 }
 
+// tryLeaseRead executes the read request directly when the replica is the
+// leader and holds a valid lease, so the read doesn't have to wait behind the
+// queued proposals or for a ReadIndex round trip.
+func (pr *replica) tryLeaseRead(req rpcpb.Request) bool {
+	if req.Type != rpcpb.Read ||
+		!pr.isLeader() ||
+		pr.isWitness() ||
+		!pr.lease.valid(time.Now()) ||
+		pr.isStaleEpochRead(req) {
+		return false
+	}
+
+	metric.AddRaftProposalReadLocalCount(1)
+	pr.lease.markServed()
+	pr.execReadRequest(req)
+	return true
+}
+
+// tryStaleRead executes the read request directly on any replica when the
+// request tolerates a bounded staleness and the resolved timestamp of the
+// replica is recent enough.
+func (pr *replica) tryStaleRead(req rpcpb.Request) bool {
+	if req.Type != rpcpb.Read ||
+		req.MaxStaleness == 0 ||
+		pr.isWitness() ||
+		!pr.resolvedTS.withinStaleness(time.Now(), req.MaxStaleness) ||
+		pr.isStaleEpochRead(req) {
+		return false
+	}
+
+	metric.AddRaftProposalReadStaleCount(1)
+	pr.execReadRequest(req)
+	return true
+}
+
+// isStaleEpochRead returns true if the read request has to be rejected by the
+// proposal path as usual due to the stale epoch.
+func (pr *replica) isStaleEpochRead(req rpcpb.Request) bool {
+	return !req.IgnoreEpochCheck &&
+		req.Epoch.Generation < pr.getShard().Epoch.Generation
+}
+
 func (pr *replica) maybeExecRead() {
 	pr.pendingReads.process(pr.appliedIndex, pr.execReadRequest)
 }
@@ -485,17 +637,34 @@ func (pr *replica) execReadRequest(req rpcpb.Request) {
 					log.RaftRequestField("request", &req))
 			}
 
+			// FIXME: pr.getShard() has a lock, it's a hot path.
+			shard := pr.getShard()
+			if err := checkShardACL(shard, req, metapb.OperationClass_ReadOperation); err != nil {
+				respAccessDenied(err, req, pr.store.shardsProxy.OnResponse)
+				return
+			}
+
 			ctx := acquireReadCtx()
 			defer releaseReadCtx(ctx)
 
-			// FIXME: pr.getShard() has a lock, it's a hot path.
-			ctx.reset(pr.getShard(), storage.Request{
-				CmdType: req.CustomType,
-				Key:     req.Key,
-				Cmd:     req.Cmd,
-			})
-
-			v, err := pr.sm.dataStorage.Read(ctx)
+			// The remote responses are encoded asynchronously by the rpc, so only
+			// the local requests can be served by the zero copy read.
+			ctx.reset(shard, storage.Request{
+				CmdType:          req.CustomType,
+				Key:              req.Key,
+				Cmd:              req.Cmd,
+				ReadSnapshot:     req.ReadSnapshot,
+				MaxResponseBytes: req.MaxResponseBytes,
+			}, pr.cfg.Raft.EnableZeroCopyRead && req.PID == 0)
+
+			pr.recordHotKey(req.Key)
+			// the read reflects all writes up to the applied index before reading
+			appliedIndex, appliedTerm := pr.sm.getAppliedIndexTerm()
+			v, err := pr.sm.readDataStorage(ctx)
+			if errors.Is(err, storage.ErrReadSnapshotNotFound) {
+				respReadSnapshotNotFound(pr.shardID, req.ReadSnapshot, req, pr.store.shardsProxy.OnResponse)
+				return
+			}
 			if err != nil {
 				// FIXME: some read failures should be tolerated.
 				pr.logger.Fatal("fail to exec read batch",
@@ -510,7 +679,31 @@ func (pr *replica) execReadRequest(req rpcpb.Request) {
 				},
 			})
 
-			requestDone(req, pr.store.shardsProxy.OnResponse, v)
+			rv := ctx.takeReadValue()
+			if rv != nil {
+				v = rv.Data()
+			}
+			// the executor not truncating the result can not exceed the max
+			// response bytes
+			if req.MaxResponseBytes > 0 && len(ctx.continuationKey) == 0 &&
+				uint64(len(v)) > req.MaxResponseBytes {
+				if rv != nil {
+					rv.Release()
+				}
+				respResponseTooLarge(pr.shardID, req.MaxResponseBytes, req, pr.store.shardsProxy.OnResponse)
+				return
+			}
+
+			resp := getResponse(req)
+			resp.ContinuationKey = ctx.continuationKey
+			resp.AppliedIndex = appliedIndex
+			resp.AppliedTerm = appliedTerm
+			if rv != nil {
+				pr.store.shardsProxy.OnReadValueResponse(resp, rv)
+				return
+			}
+			resp.Value = v
+			pr.store.shardsProxy.OnResponse(rpcpb.ResponseBatch{Responses: []rpcpb.Response{resp}})
 		}
 	})
 	if err == stop.ErrUnavailable {
@@ -523,6 +716,12 @@ func (pr *replica) execReadRequest(req rpcpb.Request) {
 	}
 }
 
+func (pr *replica) setAppliedIndex(index uint64) {
+	pr.appliedIndex = index
+	pr.lease.setAppliedIndex(index)
+	pr.resolvedTS.setAppliedIndex(index)
+}
+
 func (pr *replica) pendingReadCount() int {
 	return pr.rn.PendingReadCount()
 }
@@ -560,10 +759,37 @@ func (pr *replica) collectDownReplicas() []metapb.ReplicaStats {
 	return downReplicas
 }
 
+// collectRecoveringReplicas returns the replicas the leader is sending the
+// snapshots to, they are recovering from the snapshots.
+func (pr *replica) collectRecoveringReplicas() []uint64 {
+	var replicas []uint64
+	for id, p := range pr.rn.Status().Progress {
+		if p.State == trackerPkg.StateSnapshot {
+			replicas = append(replicas, id)
+		}
+	}
+	sort.Slice(replicas, func(i, j int) bool { return replicas[i] < replicas[j] })
+	return replicas
+}
+
 // collectPendingReplicas returns a list of replicas that are potentially waiting for
-// snapshots from the leader.
+// snapshots from the leader. The learners which never acknowledged any log entry
+// are pending too, they are still being created on their stores.
 func (pr *replica) collectPendingReplicas() []Replica {
-	return []Replica{}
+	first, _ := pr.lr.FirstIndex()
+	progresses := pr.rn.Status().Progress
+	replicas := []Replica{}
+	for _, r := range pr.getShard().Replicas {
+		if r.ID == pr.replicaID {
+			continue
+		}
+		p, ok := progresses[r.ID]
+		if !ok || p.State == trackerPkg.StateSnapshot || p.Match+1 < first ||
+			(p.Match == 0 && r.Role == metapb.ReplicaRole_Learner) {
+			replicas = append(replicas, r)
+		}
+	}
+	return replicas
 }
 
 func (pr *replica) nextProposalIndex() uint64 {
@@ -578,6 +804,10 @@ func (pr *replica) getTickHandledCount() uint64 {
 	return atomic.LoadUint64(&pr.tickHandledCount)
 }
 
+func (pr *replica) getReadIndexCount() uint64 {
+	return atomic.LoadUint64(&pr.readIndexCount)
+}
+
 func getRaftConfig(id, appliedIndex uint64, lr *LogReader, cfg *config.Config, logger *zap.Logger) *raft.Config {
 	return &raft.Config{
 		ID:                        id,
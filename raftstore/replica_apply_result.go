@@ -23,6 +23,7 @@ import (
 	"github.com/matrixorigin/matrixcube/components/log"
 	"github.com/matrixorigin/matrixcube/pb/metapb"
 	"github.com/matrixorigin/matrixcube/pb/rpcpb"
+	"github.com/matrixorigin/matrixcube/storage"
 )
 
 type applyResult struct {
@@ -45,6 +46,15 @@ type adminResult struct {
 	compactionResult     compactionResult
 	updateMetadataResult updateMetadataResult
 	updateLabelsResult   updateLabelsResult
+	mergeResult          mergeResult
+	becomeWitnessResult  becomeWitnessResult
+	// purgeExpiredDataResult the key the purge of the expired data continues
+	// from
+	purgeExpiredDataResult purgeExpiredDataResult
+}
+
+type purgeExpiredDataResult struct {
+	next []byte
 }
 
 type updateLabelsResult struct {
@@ -65,10 +75,19 @@ type splitResult struct {
 	newShards []Shard
 }
 
+type mergeResult struct {
+	source      Shard
+	sourceIndex uint64
+}
+
 type compactionResult struct {
 	index uint64
 }
 
+type becomeWitnessResult struct {
+	replica Replica
+}
+
 func (pr *replica) notifyPendingProposal(id []byte,
 	resp rpcpb.ResponseBatch, isConfChange bool) {
 	pr.pendingProposals.notify(id, resp, isConfChange)
@@ -85,7 +104,7 @@ func (pr *replica) handleApplyResult(result applyResult) {
 }
 
 func (pr *replica) updateAppliedIndex(result applyResult) {
-	pr.appliedIndex = result.index
+	pr.setAppliedIndex(result.index)
 	pr.maybeExecRead()
 }
 
@@ -94,28 +113,85 @@ func (pr *replica) updateMetricsHints(result applyResult) {
 
 	pr.stats.writtenBytes += result.metrics.writtenBytes
 	pr.stats.writtenKeys += result.metrics.writtenKeys
+	// the approximate size and keys are kept after split, they are used to
+	// estimate the size and keys of the new shards.
+	pr.stats.approximateSize = applyDiffHint(pr.stats.approximateSize, result.metrics.approximateDiffHint)
+	pr.stats.approximateKeys = applyDiffHint(pr.stats.approximateKeys, result.metrics.approximateKeysDiffHint)
 	if result.hasSplitResult() {
 		pr.stats.deleteKeysHint = result.metrics.deleteKeysHint
-		pr.stats.approximateSize = result.metrics.approximateDiffHint
 	} else {
 		pr.stats.deleteKeysHint += result.metrics.deleteKeysHint
-		pr.stats.approximateSize += result.metrics.approximateDiffHint
 	}
 }
 
+// applyDiffHint applies the diff to the approximate value, the value can not be
+// less than 0 as the diff is inaccurate.
+func applyDiffHint(value uint64, diff int64) uint64 {
+	if diff >= 0 {
+		return value + uint64(diff)
+	}
+	if uint64(-diff) >= value {
+		return 0
+	}
+	return value - uint64(-diff)
+}
+
 func (pr *replica) handleAdminResult(result applyResult) {
 	switch result.adminResult.adminType {
-	case rpcpb.AdminConfigChange:
+	case rpcpb.AdminConfigChange, rpcpb.AdminConfigChangeV2:
 		pr.applyConfChange(result.adminResult.configChangeResult)
 	case rpcpb.AdminBatchSplit:
 		pr.applySplit(result.adminResult.splitResult)
+	case rpcpb.AdminBarrier:
+		pr.applyBarrier()
+	case rpcpb.AdminPrepareMerge:
+		pr.applyPrepareMerge()
+	case rpcpb.AdminMergeShard:
+		pr.applyMerge(result.adminResult.mergeResult)
+	case rpcpb.AdminRollbackMerge:
+		pr.applyRollbackMerge()
+	case rpcpb.AdminAbortMerge:
+		pr.applyAbortMerge()
 	case rpcpb.AdminCompactLog:
 		pr.applyCompactionResult(result.adminResult.compactionResult)
 	case rpcpb.AdminUpdateMetadata:
 		pr.applyUpdateMetadataResult(result.adminResult.updateMetadataResult)
 	case rpcpb.AdminUpdateLabels:
 		pr.applyUpdateLabels(result.adminResult.updateLabelsResult)
+	case rpcpb.AdminUpdateDurabilityPolicy:
+		pr.applyUpdateDurabilityPolicy()
+	case rpcpb.AdminBecomeWitness:
+		pr.applyBecomeWitness(result.adminResult.becomeWitnessResult)
+	case rpcpb.AdminCompactShard:
+		pr.applyCompactShard()
+	case rpcpb.AdminPurgeExpiredData:
+		pr.applyPurgeExpiredData(result.adminResult.purgeExpiredDataResult)
+	}
+}
+
+// applyPurgeExpiredData proposes the purge of the next batch of the shard data
+// until the end of the shard is reached, only the leader proposes it.
+func (pr *replica) applyPurgeExpiredData(result purgeExpiredDataResult) {
+	if len(result.next) == 0 || !pr.isLeader() {
+		return
 	}
+	pr.addAdminRequest(rpcpb.AdminPurgeExpiredData,
+		&rpcpb.PurgeExpiredDataRequest{Start: result.next})
+}
+
+// applyCompactShard schedules the compaction of the shard data by the vacuum
+// cleaner, the witness has no data to compact.
+func (pr *replica) applyCompactShard() {
+	if pr.isWitness() {
+		return
+	}
+	shard := pr.getShard()
+	pr.store.vacuumCleaner.addTask(vacuumTask{
+		group:        shard.Group,
+		dataStorage:  pr.sm.dataStorage,
+		compactRange: &keyRange{start: shard.Start, end: shard.End},
+		reason:       "compact-shard",
+	})
 }
 
 func (pr *replica) applyUpdateMetadataResult(cp updateMetadataResult) {
@@ -128,6 +204,20 @@ func (pr *replica) applyUpdateMetadataResult(cp updateMetadataResult) {
 }
 
 func (pr *replica) applyUpdateLabels(result updateLabelsResult) {
+	// the labels may steer the shard to another data storage
+	pr.addMoveDataStorageAction()
+	if pr.aware != nil {
+		pr.aware.Updated(pr.getShard())
+	}
+}
+
+// applyUpdateDurabilityPolicy reports the shard with the new policy to prophet at
+// once, so the roles of the replicas are changed without waiting for the next
+// heartbeat.
+func (pr *replica) applyUpdateDurabilityPolicy() {
+	if pr.isLeader() {
+		pr.addAction(action{actionType: heartbeatAction})
+	}
 	if pr.aware != nil {
 		pr.aware.Updated(pr.getShard())
 	}
@@ -177,6 +267,15 @@ func (pr *replica) applyConfChange(cp configChangeResult) {
 			pr.store.replicaRecords.Delete(replicaID)
 		}
 	}
+	// the roles of the replicas are changed by entering or leaving the joint state
+	for _, replica := range cp.shard.Replicas {
+		if replica.StoreID == pr.storeID {
+			pr.replica = replica
+		}
+		if _, ok := pr.store.replicaRecords.Load(replica.ID); ok {
+			pr.store.replicaRecords.Store(replica.ID, replica)
+		}
+	}
 
 	if pr.isLeader() {
 		// Notify prophet immediately.
@@ -235,8 +334,11 @@ func (pr *replica) applySplit(result splitResult) {
 
 	isLeader := pr.isLeader()
 	reason := fmt.Sprintf("create by shard %d splitted", pr.shardID)
+	// the new shards are saved in the data storage of the split shard
+	ds := pr.sm.dataStorage
 	newReplicaCreator(pr.store).
 		withReason(reason).
+		withDataStorageGetter(func(Shard) storage.DataStorage { return ds }).
 		withStartReplica(false, func(r *replica) {
 			r.stats.approximateKeys = estimatedKeys
 			r.stats.approximateSize = estimatedSize
@@ -131,13 +131,33 @@ func TestUpdateMetricsHints(t *testing.T) {
 	assert.Equal(t, uint64(2), pr.stats.writtenBytes)
 	assert.Equal(t, uint64(2), pr.stats.writtenKeys)
 
+	pr.updateMetricsHints(applyResult{
+		metrics: applyMetrics{
+			approximateDiffHint:     -1,
+			approximateKeysDiffHint: 2,
+		},
+	})
+	assert.Equal(t, uint64(3), pr.stats.approximateSize)
+	assert.Equal(t, uint64(2), pr.stats.approximateKeys)
+
+	pr.updateMetricsHints(applyResult{
+		metrics: applyMetrics{
+			approximateDiffHint:     -10,
+			approximateKeysDiffHint: -1,
+		},
+	})
+	assert.Equal(t, uint64(0), pr.stats.approximateSize)
+	assert.Equal(t, uint64(1), pr.stats.approximateKeys)
+
+	pr.stats.approximateSize = 4
 	pr.updateMetricsHints(applyResult{
 		adminResult: &adminResult{
 			adminType:   rpcpb.AdminBatchSplit,
 			splitResult: splitResult{},
 		},
 	})
-	assert.Equal(t, uint64(0), pr.stats.approximateSize)
+	assert.Equal(t, uint64(4), pr.stats.approximateSize)
+	assert.Equal(t, uint64(1), pr.stats.approximateKeys)
 	assert.Equal(t, uint64(0), pr.stats.deleteKeysHint)
 	assert.Equal(t, uint64(2), pr.stats.writtenBytes)
 	assert.Equal(t, uint64(2), pr.stats.writtenKeys)
@@ -177,3 +197,18 @@ func TestUpdateMetricsHintsCanBeIgnored(t *testing.T) {
 	assert.Equal(t, uint64(2), pr.stats.approximateSize)
 
 }
+
+func TestApplyCompactShard(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	s, cancel := newTestStore(t)
+	defer cancel()
+
+	pr := newTestReplica(Shard{ID: 1, Start: []byte{1}, End: []byte{10}}, Replica{ID: 100}, s)
+	pr.handleAdminResult(applyResult{adminResult: &adminResult{adminType: rpcpb.AdminCompactShard}})
+	tasks := s.vacuumCleaner.getTasks()
+	assert.Equal(t, 1, len(tasks))
+	assert.Equal(t, &keyRange{start: []byte{1}, end: []byte{10}}, tasks[0].compactRange)
+	assert.Nil(t, tasks[0].compactJob)
+	assert.NoError(t, s.vacuum(tasks[0]))
+}
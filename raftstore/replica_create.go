@@ -43,9 +43,12 @@ type replicaCreator struct {
 	campaign                          bool
 	afterStartedFunc, beforeStartFunc func(*replica)
 	replicaRecordGetter               func(Shard) Replica
+	dataStorageGetter                 func(Shard) storage.DataStorage
 	wc                                *logdb.WorkerContext
 	logger                            *zap.Logger
 	shardsMetadata                    []metapb.ShardMetadata
+	// startWorkers the max number of the replicas started concurrently
+	startWorkers int
 }
 
 func newReplicaCreator(store *store) *replicaCreator {
@@ -62,6 +65,13 @@ func (rc *replicaCreator) withReplicaRecordGetter(value func(Shard) Replica) *re
 	return rc
 }
 
+// withDataStorageGetter creates the replicas on the data storages returned by
+// the getter instead of the ones resolved for the shards.
+func (rc *replicaCreator) withDataStorageGetter(value func(Shard) storage.DataStorage) *replicaCreator {
+	rc.dataStorageGetter = value
+	return rc
+}
+
 func (rc *replicaCreator) withReason(reason string) *replicaCreator {
 	rc.reason = reason
 	return rc
@@ -76,6 +86,13 @@ func (rc *replicaCreator) withStartReplica(campaign bool,
 	return rc
 }
 
+// withStartWorkers starts the replicas by at most n goroutines, the callbacks of
+// the withStartReplica are invoked concurrently.
+func (rc *replicaCreator) withStartWorkers(n int) *replicaCreator {
+	rc.startWorkers = n
+	return rc
+}
+
 func (rc *replicaCreator) withSaveMetadata(sync bool) *replicaCreator {
 	rc.sync = sync
 	rc.saveMetadata = true
@@ -117,7 +134,8 @@ func (rc *replicaCreator) getTarget(originShards []Shard) ([]Shard, []*replica)
 	var shards []Shard
 	var replicas []*replica
 	for _, shard := range originShards {
-		pr, err := newReplica(rc.store, shard, rc.getLocalReplica(shard), rc.reason)
+		pr, err := newReplicaWithDataStorage(rc.store, shard, rc.getLocalReplica(shard),
+			rc.getDataStorage(shard), rc.reason)
 		if err != nil {
 			rc.logger.Fatal("failed to create shard",
 				log.ShardField("shard", shard),
@@ -142,7 +160,7 @@ func (rc *replicaCreator) maybeInitReplica(shards []Shard) {
 		return
 	}
 
-	doWithShardsByGroupID(rc.store.DataStorageByGroup, func(ds storage.DataStorage, v []Shard) {
+	doWithShardsByDataStorage(rc.getDataStorage, func(ds storage.DataStorage, v []Shard) {
 		var sm []metapb.ShardMetadata
 		var ids []uint64
 		for _, shard := range v {
@@ -186,12 +204,15 @@ func (rc *replicaCreator) maybeInitReplica(shards []Shard) {
 				zap.Error(err))
 		}
 
-		if rc.sync {
+		// the metadata is synced before passed to the interceptor
+		interceptor := rc.store.cfg.Customize.CustomShardMetadataInterceptor
+		if rc.sync || interceptor != nil {
 			if err := ds.Sync(ids); err != nil {
 				rc.logger.Fatal("failed to sync shards metadata",
 					zap.Error(err))
 			}
 		}
+		interceptShardMetadata(rc.logger, interceptor, sm)
 	}, shards...)
 }
 
@@ -206,7 +227,8 @@ func (rc *replicaCreator) maybeStartReplicas(shards []Shard, replicas []*replica
 		time.Sleep(rc.store.cfg.Test.SaveDynamicallyShardInitStateWait)
 	}
 
-	for idx, pr := range replicas {
+	runWithWorkers(len(replicas), rc.startWorkers, func(idx int) {
+		pr := replicas[idx]
 		if rc.beforeStartFunc != nil {
 			rc.beforeStartFunc(pr)
 		}
@@ -218,9 +240,16 @@ func (rc *replicaCreator) maybeStartReplicas(shards []Shard, replicas []*replica
 		if rc.afterStartedFunc != nil {
 			rc.afterStartedFunc(pr)
 		}
-	}
+	})
 
-	groupBy := groupShardByGroupID(shards)
+	// the ranges of the frozen shards are taken over by the merge targets
+	var routed []Shard
+	for idx, pr := range replicas {
+		if target, _ := pr.sm.getMergeTarget(); target == 0 {
+			routed = append(routed, shards[idx])
+		}
+	}
+	groupBy := groupShardByGroupID(routed)
 	for g, shards := range groupBy {
 		rc.store.updateShardKeyRange(g, shards...)
 	}
@@ -276,11 +305,25 @@ func (rc *replicaCreator) getLocalReplica(shard Shard) Replica {
 	return Replica{}
 }
 
-func doWithShardsByGroupID(dataStorageGetter func(group uint64) storage.DataStorage, fn func(storage.DataStorage, []Shard), shards ...Shard) {
-	groupBy := groupShardByGroupID(shards)
-	for g, v := range groupBy {
-		ds := dataStorageGetter(g)
-		fn(ds, v)
+func (rc *replicaCreator) getDataStorage(shard Shard) storage.DataStorage {
+	if rc.dataStorageGetter != nil {
+		return rc.dataStorageGetter(shard)
+	}
+	return rc.store.DataStorageByShard(shard)
+}
+
+func doWithShardsByDataStorage(dataStorageGetter func(Shard) storage.DataStorage, fn func(storage.DataStorage, []Shard), shards ...Shard) {
+	var storages []storage.DataStorage
+	groupBy := make(map[storage.DataStorage][]Shard)
+	for _, shard := range shards {
+		ds := dataStorageGetter(shard)
+		if _, ok := groupBy[ds]; !ok {
+			storages = append(storages, ds)
+		}
+		groupBy[ds] = append(groupBy[ds], shard)
+	}
+	for _, ds := range storages {
+		fn(ds, groupBy[ds])
 	}
 }
 
@@ -0,0 +1,169 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package raftstore
+
+import (
+	"go.uber.org/zap"
+
+	"github.com/matrixorigin/matrixcube/components/log"
+	"github.com/matrixorigin/matrixcube/pb/metapb"
+	"github.com/matrixorigin/matrixcube/storage"
+)
+
+// addMoveDataStorageAction checks the data storage of the shard in the event
+// worker if the data storage is resolved by the shard.
+func (pr *replica) addMoveDataStorageAction() {
+	if pr.cfg.Storage.ShardDataStorageFactory != nil {
+		pr.addAction(action{actionType: moveDataStorageAction})
+	}
+}
+
+// maybeMoveDataStorage moves the shard to the data storage resolved for it if
+// it's different from the current one, e.g. the labels steering the placement
+// of the shard are updated. The shard is copied to the target by a snapshot of
+// the source and synced, then the metadata of the shard in the source is marked
+// as tombstone before the data in the source is removed. If the store crashed in
+// between, the shard is found in both data storages on restart, the tombstone
+// in the source means the copy in the target is complete, see
+// dedupShardStorage.
+func (pr *replica) maybeMoveDataStorage() {
+	shard := pr.getShard()
+	if shard.State == metapb.ShardState_Destroying ||
+		shard.State == metapb.ShardState_Destroyed {
+		return
+	}
+	from := pr.sm.dataStorage
+	to := pr.store.DataStorageByShard(shard)
+	if to == nil || to == from {
+		return
+	}
+
+	pr.logger.Info("begin to move shard to another data storage")
+	if err := pr.moveDataStorage(from, to); err != nil {
+		pr.logger.Error("failed to move shard to another data storage, retry later",
+			zap.Error(err))
+		// drop the incomplete copy, the move is retried by the next check
+		if err := to.RemoveShard(shard, true); err != nil {
+			pr.logger.Fatal("failed to remove the incomplete copy of shard",
+				zap.Error(err))
+		}
+		return
+	}
+
+	// the move is completed once the source is marked as tombstone
+	index, _ := pr.sm.getAppliedIndexTerm()
+	metadata := pr.sm.newShardMetadata(index+1, shard, metapb.ReplicaState_ReplicaTombstone)
+	metadata.Metadata.RemoveData = true
+	if err := from.SaveShardMetadata([]metapb.ShardMetadata{metadata}); err != nil {
+		pr.logger.Fatal("failed to mark the moved shard as tombstone",
+			zap.Error(err))
+	}
+	if err := from.Sync([]uint64{shard.ID}); err != nil {
+		pr.logger.Fatal("failed to sync the moved shard tombstone",
+			zap.Error(err))
+	}
+
+	pr.sm.setDataStorage(to)
+	pr.feature = to.Feature()
+	if err := from.RemoveShard(shard, true); err != nil {
+		pr.logger.Fatal("failed to remove the moved shard",
+			zap.Error(err))
+	}
+	pr.logger.Info("shard moved to another data storage")
+}
+
+// moveDataStorage copies the shard to the target data storage and makes the
+// copy durable.
+func (pr *replica) moveDataStorage(from, to storage.DataStorage) error {
+	if err := pr.snapshotter.move(from, to); err != nil {
+		return err
+	}
+	return to.Sync([]uint64{pr.shardID})
+}
+
+// shardCopy the shard loaded from a data storage on restart.
+type shardCopy struct {
+	ds  storage.DataStorage
+	sls metapb.ShardLocalState
+}
+
+// dedupShardStorages returns the data storage each shard is loaded from on
+// restart. The initStates are the initial states of the dataStorages in the
+// same order, the shards found in multiple data storages are deduplicated by
+// dedupShardStorage.
+func (s *store) dedupShardStorages(dataStorages []storage.DataStorage,
+	initStates [][]metapb.ShardMetadata, report *RecoveryReport) map[uint64]storage.DataStorage {
+	copies := make(map[uint64]shardCopy)
+	for i, ds := range dataStorages {
+		for _, metadata := range initStates[i] {
+			c := shardCopy{ds: ds, sls: metadata.Metadata}
+			prev, ok := copies[metadata.ShardID]
+			if !ok || prev.ds == ds {
+				copies[metadata.ShardID] = c
+				continue
+			}
+			copies[metadata.ShardID] = s.dedupShardStorage(prev, c, report)
+		}
+	}
+
+	shardStorages := make(map[uint64]storage.DataStorage, len(copies))
+	for id, c := range copies {
+		shardStorages[id] = c.ds
+	}
+	return shardStorages
+}
+
+// dedupShardStorage returns the copy of the shard to load if the shard is found
+// in two data storages, and removes the other one. The shard is in two data
+// storages if the store crashed while moving the shard. If the copy in the
+// source of the move is tombstone, the copy in the target is complete, and the
+// source is removed. Otherwise the copy in the target may be incomplete, so it
+// is removed and the move is redone after the replica started.
+func (s *store) dedupShardStorage(prev, c shardCopy, report *RecoveryReport) shardCopy {
+	shard := c.sls.Shard
+	target := s.DataStorageByShard(shard)
+	if target != prev.ds && target != c.ds {
+		s.logger.Warn("shard found in multiple data storages, skipped",
+			s.storeField(),
+			log.ShardField("metadata", shard))
+		return prev
+	}
+
+	source, moved := prev, c
+	if target == prev.ds {
+		source, moved = c, prev
+	}
+	remove, keep := moved, source
+	reason := "incomplete move between data storages"
+	if source.sls.State == metapb.ReplicaState_ReplicaTombstone {
+		remove, keep = source, moved
+		reason = "moved shard not removed from source data storage"
+	}
+
+	s.logger.Info("remove the duplicated shard",
+		s.storeField(),
+		log.ShardField("metadata", shard),
+		log.ReasonField(reason))
+	if err := remove.ds.RemoveShard(remove.sls.Shard, true); err != nil {
+		s.logger.Fatal("failed to remove the duplicated shard",
+			s.storeField(),
+			log.ShardIDField(shard.ID),
+			zap.Error(err))
+	}
+	report.Anomalies = append(report.Anomalies, RecoveryAnomaly{
+		ShardID: shard.ID,
+		Reason:  reason,
+	})
+	return keep
+}
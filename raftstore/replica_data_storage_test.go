@@ -0,0 +1,146 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package raftstore
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/matrixorigin/matrixcube/pb/metapb"
+	"github.com/matrixorigin/matrixcube/storage"
+	"github.com/matrixorigin/matrixcube/storage/kv"
+	"github.com/matrixorigin/matrixcube/storage/kv/mem"
+	"github.com/matrixorigin/matrixcube/util/leaktest"
+)
+
+func newTestColdDataStorage(t *testing.T, s *store) storage.DataStorage {
+	ds := kv.NewKVDataStorage(kv.NewBaseStorage(mem.NewStorage(), s.cfg.FS), nil)
+	_, err := ds.GetInitialStates()
+	require.NoError(t, err)
+	s.cfg.Storage.ShardDataStorageFactory = func(group uint64, shard Shard) storage.DataStorage {
+		if hasLabel(shard, "storage", "cold") {
+			return ds
+		}
+		return nil
+	}
+	return ds
+}
+
+func TestMoveDataStorage(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	s, cancel := newTestStore(t)
+	defer cancel()
+	target := newTestColdDataStorage(t, s)
+	defer target.Close()
+
+	source := s.DataStorageByGroup(0)
+	shard := Shard{ID: 1, Start: []byte("a"), End: []byte("c")}
+	pr := newTestReplica(shard, Replica{ID: 1}, s)
+	_, err := pr.snapshotter.prepareReplicaSnapshotDir()
+	require.NoError(t, err)
+	require.NoError(t, source.SaveShardMetadata([]metapb.ShardMetadata{{
+		ShardID:  1,
+		LogIndex: 1,
+		Metadata: metapb.ShardLocalState{Shard: shard},
+	}}))
+	key := kv.EncodeDataKey([]byte("b"), nil)
+	sourceKV := source.(storage.KVStorageWrapper).GetKVStorage()
+	require.NoError(t, sourceKV.Set(key, []byte("v"), false))
+
+	// the resolved data storage is not changed
+	pr.maybeMoveDataStorage()
+	assert.Equal(t, source, pr.sm.dataStorage)
+
+	shard.Labels = []metapb.Label{{Key: "storage", Value: "cold"}}
+	pr.sm.updateShard(shard)
+	pr.maybeMoveDataStorage()
+	assert.Equal(t, target, pr.sm.dataStorage)
+
+	v, err := target.(storage.KVStorageWrapper).GetKVStorage().Get(key)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("v"), v)
+	states, err := target.GetInitialStates()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(states))
+
+	v, err = sourceKV.Get(key)
+	assert.NoError(t, err)
+	assert.Empty(t, v)
+	states, err = source.GetInitialStates()
+	assert.NoError(t, err)
+	assert.Empty(t, states)
+}
+
+func TestDedupShardStorage(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	s, cancel := newTestStore(t)
+	defer cancel()
+	target := newTestColdDataStorage(t, s)
+	defer target.Close()
+
+	source := s.DataStorageByGroup(0)
+	shard := Shard{ID: 1, Labels: []metapb.Label{{Key: "storage", Value: "cold"}}}
+	metadata := metapb.ShardMetadata{
+		ShardID:  1,
+		LogIndex: 1,
+		Metadata: metapb.ShardLocalState{Shard: shard},
+	}
+	tombstone := metadata
+	tombstone.LogIndex = 2
+	tombstone.Metadata.State = metapb.ReplicaState_ReplicaTombstone
+	saveBoth := func(sourceMetadata metapb.ShardMetadata) {
+		require.NoError(t, source.SaveShardMetadata([]metapb.ShardMetadata{sourceMetadata}))
+		require.NoError(t, target.SaveShardMetadata([]metapb.ShardMetadata{metadata}))
+	}
+	dedup := func(dataStorages ...storage.DataStorage) (map[uint64]storage.DataStorage, RecoveryReport) {
+		var report RecoveryReport
+		var initStates [][]metapb.ShardMetadata
+		for _, ds := range dataStorages {
+			states, err := ds.GetInitialStates()
+			require.NoError(t, err)
+			initStates = append(initStates, states)
+		}
+		return s.dedupShardStorages(dataStorages, initStates, &report), report
+	}
+	getStates := func(ds storage.DataStorage) []metapb.ShardMetadata {
+		states, err := ds.GetInitialStates()
+		require.NoError(t, err)
+		return states
+	}
+
+	// the copy in the target of the incomplete move is removed
+	saveBoth(metadata)
+	shardStorages, report := dedup(target, source)
+	assert.Equal(t, source, shardStorages[1])
+	assert.Equal(t, 1, len(report.Anomalies))
+	assert.Empty(t, getStates(target))
+	assert.Equal(t, 1, len(getStates(source)))
+
+	// the copy in the target of the completed move is kept
+	saveBoth(tombstone)
+	shardStorages, report = dedup(source, target)
+	assert.Equal(t, target, shardStorages[1])
+	assert.Equal(t, 1, len(report.Anomalies))
+	assert.Empty(t, getStates(source))
+	assert.Equal(t, 1, len(getStates(target)))
+
+	// the shard loaded twice from the same data storage is not duplicated
+	shardStorages, report = dedup(target, target)
+	assert.Equal(t, target, shardStorages[1])
+	assert.Empty(t, report.Anomalies)
+}
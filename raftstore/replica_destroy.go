@@ -88,19 +88,30 @@ func (s *store) destroyReplica(shardID uint64,
 }
 
 // cleanupTombstones is invoked during restart to cleanup data belongs to those
-// shards that have been tombstoned.
-func (s *store) cleanupTombstones(shards []metapb.ShardLocalState) {
+// shards that have been tombstoned in the data storages they loaded from.
+func (s *store) cleanupTombstones(shards []metapb.ShardLocalState,
+	dataStorages map[uint64]storage.DataStorage) {
 	for _, sls := range shards {
 		s.vacuumCleaner.addTask(vacuumTask{
-			shard:      sls.Shard,
-			removeData: sls.RemoveData,
-			reason:     "restart-clean-tombstone",
+			shard:       sls.Shard,
+			dataStorage: dataStorages[sls.Shard.ID],
+			removeData:  sls.RemoveData,
+			reason:      "restart-clean-tombstone",
 		})
 	}
 }
 
 // vacuum is the actual method for handling a vacuum task.
 func (s *store) vacuum(t vacuumTask) error {
+	if t.compactData {
+		s.dropFilteredData(t.group)
+		return nil
+	}
+	if t.compactRange != nil {
+		s.compactRange(t)
+		return nil
+	}
+
 	s.logger.Info("begin to destroy replica",
 		s.storeField(),
 		log.ReasonField(t.reason),
@@ -147,7 +158,14 @@ func (s *store) vacuum(t vacuumTask) error {
 	if err := s.logdb.RemoveReplicaData(t.shard.ID); err != nil {
 		return err
 	}
-	err := s.DataStorageByGroup(t.shard.Group).RemoveShard(t.shard, t.removeData)
+	ds := t.dataStorage
+	if ds == nil && t.replica != nil {
+		ds = t.replica.sm.dataStorage
+	}
+	if ds == nil {
+		ds = s.DataStorageByShard(t.shard)
+	}
+	err := ds.RemoveShard(t.shard, t.removeData)
 	s.logger.Info("delete shard data returned",
 		s.storeField(),
 		log.ShardIDField(t.shard.ID),
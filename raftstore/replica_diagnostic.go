@@ -0,0 +1,196 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package raftstore
+
+import (
+	"encoding/json"
+	"math"
+	"time"
+
+	"github.com/matrixorigin/matrixcube/components/log"
+	"github.com/matrixorigin/matrixcube/logdb"
+	"github.com/matrixorigin/matrixcube/pb/metapb"
+	"github.com/matrixorigin/matrixcube/pb/rpcpb"
+	"go.uber.org/zap"
+)
+
+const (
+	// DefaultDiagnosticLogEntries is the default number of the last log entries
+	// reported by the replica diagnostic.
+	DefaultDiagnosticLogEntries = 64
+	maxDiagnosticApplyErrors    = 16
+)
+
+// ShardDiagnosticRequest is the JSON encoded payload of the AdminDiagnose
+// request.
+type ShardDiagnosticRequest struct {
+	// LogEntries is the number of the last log entries to report, only the
+	// sizes of the entries are reported.
+	LogEntries uint64 `json:"log-entries"`
+}
+
+// ReplicaDiagnostic is the diagnostic info of a replica, it is the JSON encoded
+// response of the AdminDiagnose request.
+type ReplicaDiagnostic struct {
+	StoreID      uint64                 `json:"store-id"`
+	Replica      metapb.Replica         `json:"replica"`
+	Shard        metapb.Shard           `json:"shard"`
+	Leader       bool                   `json:"leader"`
+	RaftStatus   json.RawMessage        `json:"raft-status"`
+	AppliedIndex uint64                 `json:"applied-index"`
+	FirstIndex   uint64                 `json:"first-index"`
+	LastIndex    uint64                 `json:"last-index"`
+	LogEntries   []LogEntryDiagnostic   `json:"log-entries"`
+	Snapshots    []SnapshotDiagnostic   `json:"snapshots"`
+	ApplyErrors  []ApplyErrorDiagnostic `json:"apply-errors"`
+	// DurabilityPolicy is the policy of acknowledging the writes of the shard.
+	DurabilityPolicy string `json:"durability-policy"`
+	// DroppedMessages is the number of the raft messages from each replica
+	// dropped before stepping the raft, replica id -> stats.
+	DroppedMessages map[uint64]DroppedMessageDiagnostic `json:"dropped-messages,omitempty"`
+	// Errors is the errors occurred while collecting the diagnostic info.
+	Errors []string `json:"errors,omitempty"`
+}
+
+// LogEntryDiagnostic is the diagnostic info of a raft log entry.
+type LogEntryDiagnostic struct {
+	Index uint64 `json:"index"`
+	Term  uint64 `json:"term"`
+	Type  string `json:"type"`
+	Size  int    `json:"size"`
+}
+
+// SnapshotDiagnostic is the diagnostic info of a snapshot record in the LogDB.
+type SnapshotDiagnostic struct {
+	Index uint64 `json:"index"`
+	Term  uint64 `json:"term"`
+	Dir   string `json:"dir"`
+}
+
+// ApplyErrorDiagnostic is an error occurred while applying the raft log.
+type ApplyErrorDiagnostic struct {
+	Index uint64    `json:"index"`
+	Time  time.Time `json:"time"`
+	Error string    `json:"error"`
+}
+
+// DroppedMessageDiagnostic is the number of the raft messages from a replica
+// dropped before stepping the raft.
+type DroppedMessageDiagnostic struct {
+	StaleTerm uint64 `json:"stale-term"`
+	Duplicate uint64 `json:"duplicate"`
+}
+
+// tryDiagnose serves the AdminDiagnose request in the raft worker thread of
+// the replica instead of proposing it, so that the followers and the replicas
+// without a leader can be diagnosed too.
+func (pr *replica) tryDiagnose(req rpcpb.Request, cb func(rpcpb.ResponseBatch)) bool {
+	if req.Type != rpcpb.Admin ||
+		rpcpb.AdminCmdType(req.CustomType) != rpcpb.AdminDiagnose {
+		return false
+	}
+
+	dr := ShardDiagnosticRequest{LogEntries: DefaultDiagnosticLogEntries}
+	if len(req.Cmd) > 0 {
+		if err := json.Unmarshal(req.Cmd, &dr); err != nil {
+			requestDoneWithError(req, cb, err)
+			return true
+		}
+	}
+
+	pr.addAction(action{
+		actionType:           diagnoseAction,
+		diagnosticLogEntries: dr.LogEntries,
+		actionCallback: func(arg interface{}) {
+			v, err := json.Marshal(arg)
+			if err != nil {
+				requestDoneWithError(req, cb, err)
+				return
+			}
+			requestDone(req, cb, v)
+		},
+	})
+	return true
+}
+
+func (pr *replica) doDiagnose(act action) {
+	d := ReplicaDiagnostic{
+		StoreID:      pr.storeID,
+		Replica:      pr.replica,
+		Shard:        pr.getShard(),
+		Leader:       pr.isLeader(),
+		AppliedIndex: pr.appliedIndex,
+		ApplyErrors:  append([]ApplyErrorDiagnostic(nil), pr.sm.applyErrors...),
+	}
+	d.DurabilityPolicy = d.Shard.DurabilityPolicy.String()
+	d.DroppedMessages = pr.messageFilter.droppedStats()
+	addError := func(err error) {
+		d.Errors = append(d.Errors, err.Error())
+	}
+
+	if v, err := pr.rn.Status().MarshalJSON(); err != nil {
+		addError(err)
+	} else {
+		d.RaftStatus = v
+	}
+
+	first, err := pr.lr.FirstIndex()
+	if err != nil {
+		addError(err)
+	}
+	last, err := pr.lr.LastIndex()
+	if err != nil {
+		addError(err)
+	}
+	d.FirstIndex, d.LastIndex = first, last
+	if n := act.diagnosticLogEntries; n > 0 && last >= first {
+		low := first
+		if last-first+1 > n {
+			low = last - n + 1
+		}
+		entries, err := pr.lr.Entries(low, last+1, math.MaxUint64)
+		if err != nil {
+			addError(err)
+		}
+		for _, e := range entries {
+			d.LogEntries = append(d.LogEntries, LogEntryDiagnostic{
+				Index: e.Index,
+				Term:  e.Term,
+				Type:  e.Type.String(),
+				Size:  e.Size(),
+			})
+		}
+	}
+
+	snapshots, err := pr.logdb.GetAllSnapshots(pr.shardID)
+	if err != nil && err != logdb.ErrNoSnapshot {
+		addError(err)
+	}
+	for _, ss := range snapshots {
+		env := pr.snapshotter.getRecoverSnapshotEnv(ss)
+		d.Snapshots = append(d.Snapshots, SnapshotDiagnostic{
+			Index: ss.Metadata.Index,
+			Term:  ss.Metadata.Term,
+			Dir:   env.GetFinalDir(),
+		})
+	}
+
+	if ce := pr.logger.Check(zap.DebugLevel, "replica diagnosed"); ce != nil {
+		ce.Write(log.ShardField("metadata", d.Shard),
+			zap.Int("log-entries", len(d.LogEntries)),
+			zap.Int("snapshots", len(d.Snapshots)),
+			zap.Int("apply-errors", len(d.ApplyErrors)))
+	}
+	act.actionCallback(d)
+}
@@ -0,0 +1,44 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package raftstore
+
+// maybeRespondAllApplied responds the writes waiting to be applied by all the
+// replicas, see `rpcpb.AllReplicasApplied`. The applied indexes of the other
+// replicas are the ones attached to their last raft messages to the leader, so
+// the writes are usually responded by the next heartbeat responses.
+func (pr *replica) maybeRespondAllApplied() {
+	if !pr.pendingProposals.waitingAllApplied() {
+		return
+	}
+	if !pr.isLeader() {
+		pr.pendingProposals.clearAllApplied()
+		return
+	}
+	pr.pendingProposals.applied(pr.minAppliedIndex())
+}
+
+// minAppliedIndex returns the min applied index of the current replicas of the
+// shard, the witnesses are skipped as they do not apply the writes.
+func (pr *replica) minAppliedIndex() uint64 {
+	index := pr.appliedIndex
+	for _, r := range pr.getShard().Replicas {
+		if r.ID == pr.replica.ID || r.IsWitness {
+			continue
+		}
+		if applied := pr.appliedIndexes[r.ID]; applied < index {
+			index = applied
+		}
+	}
+	return index
+}
@@ -14,6 +14,7 @@
 package raftstore
 
 import (
+	"sort"
 	"sync/atomic"
 	"time"
 
@@ -44,6 +45,15 @@ type action struct {
 	readMetrics        readMetrics
 	epoch              Epoch
 	actionCallback     func(interface{})
+	// diagnosticLogEntries is the number of the last log entries reported by
+	// the diagnoseAction
+	diagnosticLogEntries uint64
+	// term is the term of the leadership warmed up by the leaderWarmupDoneAction
+	term uint64
+	// mergeSource is the frozen shard to be merged by the mergeAction or to be
+	// fenced against by the abortMergeAction, the targetIndex is the index of its
+	// prepare merge log
+	mergeSource Shard
 }
 
 type readMetrics struct {
@@ -57,6 +67,12 @@ type splitCheckData struct {
 	splitKeys [][]byte
 	splitIDs  []rpcpb.SplitID
 	ctx       []byte
+	// deadline the split request must be completed by, it is inherited by the
+	// split request
+	deadline time.Time
+	// requested the AdminSplitShard request of the client, it's responded by the
+	// split request
+	requested *batch
 }
 
 type snapshotCompactionDetails struct {
@@ -78,11 +94,26 @@ const (
 	logCompactionAction
 	snapshotCompactionAction
 	checkPendingReadsAction
+	diagnoseAction
+	leaderWarmupDoneAction
+	mergeAction
+	abortMergeAction
+	checkFenceAction
+	drainLeaderAction
+	moveDataStorageAction
 )
 
 func (pr *replica) addAdminRequest(adminType rpcpb.AdminCmdType, request protoc.PB) {
+	pr.addAdminRequestWithDeadline(adminType, request, time.Time{})
+}
+
+// addAdminRequestWithDeadline adds the admin request inheriting the deadline
+// of the flow issued it, zero deadline means the deadline is set when the
+// admin request is proposed.
+func (pr *replica) addAdminRequestWithDeadline(adminType rpcpb.AdminCmdType,
+	request protoc.PB, deadline time.Time) {
 	shard := pr.getShard()
-	if err := pr.addRequest(newReqCtx(rpcpb.Request{
+	ctx := newReqCtx(rpcpb.Request{
 		ID:         uuid.NewV4().Bytes(),
 		Group:      shard.Group,
 		ToShard:    shard.ID,
@@ -90,7 +121,9 @@ func (pr *replica) addAdminRequest(adminType rpcpb.AdminCmdType, request protoc.
 		CustomType: uint64(adminType),
 		Epoch:      shard.Epoch,
 		Cmd:        protoc.MustMarshal(request),
-	}, nil)); err != nil {
+	}, nil)
+	ctx.deadline = deadline
+	if err := pr.addRequest(ctx); err != nil {
 		panic(err)
 	}
 }
@@ -205,6 +238,11 @@ func (pr *replica) handleEvent(wc *logdb.WorkerContext) (hasEvent bool, err erro
 	default:
 	}
 
+	if pr.groupController.isGroupStopped(pr.group) {
+		pr.discardEventsOfStoppedGroup(pr.items)
+		return false, nil
+	}
+
 	hasEvent, err = pr.handleInitializedState()
 	if err != nil {
 		return hasEvent, err
@@ -238,10 +276,68 @@ func (pr *replica) handleEvent(wc *logdb.WorkerContext) (hasEvent bool, err erro
 	} else if newEvent {
 		hasEvent = true
 	}
+	if hasEvent {
+		pr.updateLoad()
+		pr.updateCredits()
+		pr.maybeRespondAllApplied()
+	}
 
 	return hasEvent, nil
 }
 
+// updateLoad updates the load of the replica checked by the admission control.
+func (pr *replica) updateLoad() {
+	var appliedLag int64
+	if pr.lastCommittedIndex > pr.appliedIndex {
+		appliedLag = int64(pr.lastCommittedIndex - pr.appliedIndex)
+	}
+	pr.load.update(pr.pendingProposals.size(), appliedLag)
+}
+
+// updateCredits updates the proposal credits of the replica by the requests
+// queued, proposing and waiting to be applied, and by the write stall of the
+// data storage of the group.
+func (pr *replica) updateCredits() {
+	if pr.credits == nil {
+		return
+	}
+
+	inflight := int64(pr.requests.Len()) + pr.pendingProposals.size()
+	if pr.lastCommittedIndex > pr.appliedIndex {
+		inflight += int64(pr.lastCommittedIndex - pr.appliedIndex)
+	}
+	pr.credits.update(pr.isLeader(), inflight, pr.store.isWriteStalled(pr.group))
+}
+
+// discardEventsOfStoppedGroup drops the ticks and the raft messages received
+// while the group is stopped, and rejects the queued requests. The actions are
+// kept and handled after the group started.
+func (pr *replica) discardEventsOfStoppedGroup(items []interface{}) {
+	for pr.ticks.Len() > 0 {
+		if _, err := pr.ticks.Get(readyBatchSize, items); err != nil {
+			break
+		}
+	}
+	for pr.messages.Len() > 0 {
+		if _, err := pr.messages.Get(readyBatchSize, items); err != nil {
+			break
+		}
+	}
+	for pr.requests.Len() > 0 {
+		n, err := pr.requests.Get(readyBatchSize, items)
+		if err != nil {
+			break
+		}
+		for i := int64(0); i < n; i++ {
+			// the internal admin requests without callbacks are dropped, they
+			// are issued again by the prophet operators if still required
+			if req := items[i].(reqCtx); req.cb != nil {
+				respGroupStopped(pr.group, req.req, req.cb)
+			}
+		}
+	}
+}
+
 // apply the already received snapshot
 // for safety, we have to apply the snapshot once it is received and acked. it
 // would corrupt the raft state if we just ignore such snapshots.
@@ -279,6 +375,7 @@ func (pr *replica) handleInitializedState() (bool, error) {
 			return false, err
 		}
 		pr.pushedIndex = ss.Metadata.Index
+		pr.pendingApplyEntries = nil
 	} else {
 		// snapshot is out of date, remove the disk image as we will never apply
 		// the snapshot in the future. keep the logdb record as LogReader needs
@@ -335,6 +432,21 @@ func (pr *replica) handleAction(items []interface{}) (bool, error) {
 			}
 		case checkPendingReadsAction:
 			pr.pendingReads.removeLost()
+		case diagnoseAction:
+			pr.doDiagnose(act)
+		case leaderWarmupDoneAction:
+			pr.finishLeaderWarmup(act.term)
+		case mergeAction:
+			pr.applyPendingEntries()
+			pr.doMerge(act)
+		case abortMergeAction:
+			pr.doAbortMerge(act)
+		case checkFenceAction:
+			pr.doCheckFence()
+		case drainLeaderAction:
+			pr.drainLeader()
+		case moveDataStorageAction:
+			pr.maybeMoveDataStorage()
 		}
 	}
 
@@ -358,15 +470,37 @@ func (pr *replica) handleMessage(items []interface{}) bool {
 	if err != nil {
 		return false
 	}
+	term := pr.rn.BasicStatus().Term
+	pr.messageFilter.reset()
 	for i := int64(0); i < n; i++ {
 		raftMsg := items[i].(metapb.RaftMessage)
 		msg := raftMsg.Message
 		pr.updateReplicasCommittedIndex(raftMsg)
+		if raftMsg.ResolvedTS > 0 {
+			pr.resolvedTS.track(raftMsg.ResolvedIndex, raftMsg.ResolvedTS)
+		}
+		if msg.Type == raftpb.MsgTimeoutNow {
+			if pr.store.isRejectingElections() {
+				pr.logger.Info("leader transfer rejected by the leadership drain",
+					log.ReplicaField("from", raftMsg.From))
+				continue
+			}
+			if len(raftMsg.WarmupKeys) > 0 {
+				pr.warmupKeys = raftMsg.WarmupKeys
+			}
+			pr.markTransferTarget()
+		}
 
 		if pr.isLeader() && msg.From != 0 {
 			pr.replicaHeartbeatsMap.Store(msg.From, time.Now())
 		}
 
+		if pr.messageFilter.filter(msg, term, &pr.metrics.message) {
+			continue
+		}
+		if msg.Type == raftpb.MsgSnap && !pr.canApplySnapshot(msg.Snapshot) {
+			continue
+		}
 		if err := pr.rn.Step(msg); err != nil {
 			pr.logger.Error("fail to step raft",
 				zap.Error(err))
@@ -383,6 +517,7 @@ func (pr *replica) handleMessage(items []interface{}) bool {
 
 func (pr *replica) updateReplicasCommittedIndex(msg metapb.RaftMessage) {
 	pr.committedIndexes[msg.From.ID] = msg.CommitIndex
+	pr.appliedIndexes[msg.From.ID] = msg.AppliedIndex
 }
 
 func (pr *replica) handleTick(items []interface{}) bool {
@@ -400,10 +535,71 @@ func (pr *replica) handleTick(items []interface{}) bool {
 		pr.rn.Tick()
 		atomic.AddUint64(&pr.tickHandledCount, 1)
 	}
+	pr.maybeAdvanceResolvedTS()
+	pr.maybeRenewLease()
+	pr.expireAdminProposals()
+	pr.maybeTransferWitnessLeadership()
 
 	return true
 }
 
+// expireAdminProposals responds the AdminTimeout error to the admin proposals
+// pending longer than the configured timeout, and sends a heartbeat to prophet
+// right away so the operators waiting for the admin requests are re-evaluated
+// with the current state of the shard.
+func (pr *replica) expireAdminProposals() {
+	if n := pr.pendingProposals.expire(pr.shardID, time.Now()); n > 0 {
+		pr.logger.Warn("admin proposals timed out",
+			zap.Int("count", n))
+		pr.prophetHeartbeat()
+	}
+}
+
+// maybeAdvanceResolvedTS issues a ReadIndex without any request on the leader
+// if no ReadIndex was issued or confirmed within the configured interval, so
+// that the resolved timestamp of idle shards keeps advancing. No ReadIndex is
+// issued while another one is pending, or if the store exceeds the configured
+// rate. The read index metrics are not updated as these are not client reads.
+func (pr *replica) maybeAdvanceResolvedTS() {
+	if pr.store.cfg.Raft.DisableResolvedTSAdvance ||
+		!pr.isLeader() ||
+		time.Since(pr.lastReadIndexTime) < pr.store.cfg.Raft.ResolvedTSInterval.Duration ||
+		pr.pendingReadCount() > 0 ||
+		!pr.store.allowResolvedTSAdvance() {
+		return
+	}
+
+	pr.issueIdleReadIndex()
+}
+
+// maybeRenewLease issues a ReadIndex without any request on the leader if the
+// lease serving local reads expires within a heartbeat interval, the lease is
+// renewed by the ReadIndex confirmed by the heartbeats of a quorum. No ReadIndex
+// is issued for the renewal if another ReadIndex was issued within a heartbeat
+// interval.
+func (pr *replica) maybeRenewLease() {
+	heartbeat := pr.store.cfg.Raft.GetHeartbeatDuration()
+	if !pr.isLeader() ||
+		time.Since(pr.lastReadIndexTime) < heartbeat ||
+		!pr.lease.needRenew(time.Now(), heartbeat) {
+		return
+	}
+
+	pr.issueIdleReadIndex()
+}
+
+// issueIdleReadIndex issues a ReadIndex without any request, the read index
+// metrics are not updated as it's not a client read.
+func (pr *replica) issueIdleReadIndex() {
+	pr.issueReadIndex(newBatch(pr.logger,
+		rpcpb.RequestBatch{
+			Header: rpcpb.RequestBatchHeader{
+				ID:      uuid.NewV4().Bytes(),
+				ShardID: pr.shardID,
+			},
+		}, nil, read, 0))
+}
+
 func (pr *replica) handleFeedback(items []interface{}) bool {
 	if size := pr.feedbacks.Len(); size == 0 {
 		return false
@@ -457,17 +653,33 @@ func (pr *replica) handleSnapshotStatus(items []interface{}) bool {
 }
 
 func (pr *replica) prophetHeartbeat() {
-	if !pr.isLeader() {
+	if !pr.isLeader() || pr.isWarmingUp() {
 		return
 	}
 	shard := pr.getShard()
+	stats := pr.stats.heartbeatState(pr.resolvedTS.get())
+	stats.RecoveringReplicas = pr.collectRecoveringReplicas()
+	status := pr.rn.Status()
+	stats.Term = status.Term
+	stats.CommitIndex = status.Commit
+	stats.AppliedIndex = pr.appliedIndex
+	if firstIndex := pr.getFirstIndex(); firstIndex > 0 {
+		stats.TruncatedIndex = firstIndex - 1
+	}
+	for id, p := range status.Progress {
+		stats.ReplicaMatchIndexes = append(stats.ReplicaMatchIndexes,
+			metapb.ReplicaMatchIndex{ReplicaID: id, MatchIndex: p.Match})
+	}
+	sort.Slice(stats.ReplicaMatchIndexes, func(i, j int) bool {
+		return stats.ReplicaMatchIndexes[i].ReplicaID < stats.ReplicaMatchIndexes[j].ReplicaID
+	})
 	req := rpcpb.ShardHeartbeatReq{
-		Term:            pr.rn.BasicStatus().Term,
+		Term:            status.Term,
 		Leader:          &pr.replica,
 		StoreID:         pr.storeID,
 		DownReplicas:    pr.collectDownReplicas(),
 		PendingReplicas: pr.collectPendingReplicas(),
-		Stats:           pr.stats.heartbeatState(),
+		Stats:           stats,
 		GroupKey:        pr.groupController.getShardGroupKey(shard),
 	}
 	pr.logger.Debug("start send shard heartbeat")
@@ -484,12 +696,12 @@ func (pr *replica) doCheckLogCompact(progresses map[uint64]trackerPkg.Progress,
 	}
 
 	var minReplicatedIndex uint64
+	initialized := false
 	for _, p := range progresses {
-		if minReplicatedIndex == 0 {
-			minReplicatedIndex = p.Match
-		}
-		if p.Match < minReplicatedIndex {
+		// the zero match index of a new replica is kept as the min
+		if !initialized || p.Match < minReplicatedIndex {
 			minReplicatedIndex = p.Match
+			initialized = true
 		}
 	}
 	// When an election happened or a new replica is added, replicatedIdx can be 0.
@@ -520,6 +732,15 @@ func (pr *replica) doCheckLogCompact(progresses map[uint64]trackerPkg.Progress,
 	if compactIndex == 0 &&
 		appliedIndex > firstIndex &&
 		appliedIndex-firstIndex >= pr.feature.ForceCompactCount {
+		if pr.canCatchUpByLog(minReplicatedIndex, firstIndex, appliedIndex) {
+			pr.logger.Debug("force log compaction skipped, lagged replica can catch up by log",
+				zap.Uint64("min-replicated-index", minReplicatedIndex),
+				zap.Uint64("applied-index", appliedIndex),
+				zap.Uint64("first-index", firstIndex),
+				zap.Uint64("log-size-hint", pr.stats.raftLogSizeHint),
+				zap.Uint64("shard-size", pr.stats.approximateSize))
+			return
+		}
 		compactIndex = appliedIndex
 	} else if compactIndex == 0 &&
 		pr.stats.raftLogSizeHint >= pr.feature.ForceCompactBytes {
@@ -551,6 +772,36 @@ func (pr *replica) doCheckLogCompact(progresses map[uint64]trackerPkg.Progress,
 	})
 }
 
+// canCatchUpByLog returns true if the lagged replica is expected to catch up by
+// replaying the retained raft log with less traffic than a snapshot. The size of
+// the missing entries is estimated from the size hint of the retained raft log,
+// and the size of the snapshot is estimated by the approximate size of the
+// shard. A replica without a match index yet, e.g. a newly added replica, is
+// expected to miss all the retained entries. The raft log is never retained
+// beyond the ForceCompactBytes.
+func (pr *replica) canCatchUpByLog(minReplicatedIndex, firstIndex, appliedIndex uint64) bool {
+	if minReplicatedIndex == 0 && firstIndex > 0 {
+		minReplicatedIndex = firstIndex - 1
+	}
+	if minReplicatedIndex+1 < firstIndex ||
+		minReplicatedIndex >= appliedIndex ||
+		pr.stats.raftLogSizeHint >= pr.feature.ForceCompactBytes {
+		return false
+	}
+	missing := estimateLogSize(pr.stats.raftLogSizeHint, appliedIndex-firstIndex+1,
+		appliedIndex-minReplicatedIndex)
+	return missing < pr.stats.approximateSize
+}
+
+// estimateLogSize returns the estimated size of n entries of the raft log with
+// the total size of all the retained entries.
+func estimateLogSize(total, entries, n uint64) uint64 {
+	if entries == 0 || n >= entries {
+		return total
+	}
+	return uint64(float64(total) / float64(entries) * float64(n))
+}
+
 func (pr *replica) doLogCompaction(index uint64) error {
 	if index == 0 {
 		return nil
@@ -598,6 +849,7 @@ func (pr *replica) doLogCompaction(index uint64) error {
 	}
 	pr.logger.Info("dummy snapshot saved",
 		log.IndexField(index))
+	pr.compactLogSizeHint(index)
 	// update LogReader's range info to make the compacted entries invisible to
 	// raft.
 	if err := pr.lr.Compact(index); err != nil {
@@ -615,6 +867,21 @@ func (pr *replica) doLogCompaction(index uint64) error {
 	return nil
 }
 
+// compactLogSizeHint removes the estimated size of the compacted entries from
+// the size hint of the retained raft log.
+func (pr *replica) compactLogSizeHint(index uint64) {
+	first, err := pr.lr.FirstIndex()
+	if err != nil || index < first {
+		return
+	}
+	last, err := pr.lr.LastIndex()
+	if err != nil || last < first {
+		return
+	}
+	compacted := estimateLogSize(pr.stats.raftLogSizeHint, last-first+1, index-first+1)
+	pr.stats.raftLogSizeHint -= compacted
+}
+
 func (pr *replica) notifyShutdownToPendings() {
 	// resp all stale requests in batch and queue
 	pr.incomingProposals.close()
@@ -90,6 +90,9 @@ func getCloseableReplica() (*replica, func()) {
 		closedC:           make(chan struct{}),
 		unloadedC:         make(chan struct{}),
 		sm:                &stateMachine{},
+		lease:             newReadLease(0),
+		resolvedTS:        newResolvedTS(),
+		groupController:   newReplicaGroupController(),
 	}, func() { kv.Close() }
 }
 
@@ -297,3 +300,89 @@ func TestDoCheckCompactLog(t *testing.T) {
 	protoc.MustUnmarshal(req, v.(reqCtx).req.Cmd)
 	assert.Equal(t, uint64(100), req.CompactIndex)
 }
+
+func TestDoCheckCompactLogCatchUpByLog(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	s, cancel := newTestStore(t)
+	defer cancel()
+	pr := newTestReplica(Shard{ID: 1}, Replica{ID: 1}, s)
+	pr.leaderID = 1
+	pr.feature.ForceCompactCount = 10
+	pr.feature.ForceCompactBytes = 1000
+	pr.store.cfg.Raft.RaftLog.CompactThreshold = 10
+	pr.sm.setFirstIndex(100)
+	pr.appliedIndex = 199
+	progresses := map[uint64]trackerPkg.Progress{
+		1: {Match: 199},
+		2: {Match: 105},
+	}
+
+	// the missing entries of replica 2 are estimated 470 bytes, less than the
+	// shard size, the raft log is retained for it.
+	pr.stats.raftLogSizeHint = 500
+	pr.stats.approximateSize = 1024
+	pr.doCheckLogCompact(progresses, 199)
+	assert.Equal(t, int64(0), pr.requests.Len())
+
+	// the snapshot is smaller than the missing entries
+	pr.stats.approximateSize = 100
+	pr.doCheckLogCompact(progresses, 199)
+	v, _ := pr.requests.Peek()
+	req := &rpcpb.CompactLogRequest{}
+	protoc.MustUnmarshal(req, v.(reqCtx).req.Cmd)
+	assert.Equal(t, uint64(198), req.CompactIndex)
+
+	// the raft log is never retained beyond the ForceCompactBytes
+	pr.requests = task.New(32)
+	pr.stats.approximateSize = 1024 * 1024
+	pr.stats.raftLogSizeHint = 1000
+	pr.doCheckLogCompact(progresses, 199)
+	assert.Equal(t, int64(1), pr.requests.Len())
+}
+
+func TestDoCheckCompactLogCatchUpByLogNewReplica(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	s, cancel := newTestStore(t)
+	defer cancel()
+	pr := newTestReplica(Shard{ID: 1}, Replica{ID: 1}, s)
+	pr.leaderID = 1
+	pr.feature.ForceCompactCount = 10
+	pr.feature.ForceCompactBytes = 1000
+	pr.store.cfg.Raft.RaftLog.CompactThreshold = 10
+	pr.sm.setFirstIndex(100)
+	pr.appliedIndex = 199
+	// replica 3 is newly added without a match index yet
+	progresses := map[uint64]trackerPkg.Progress{
+		1: {Match: 199},
+		2: {Match: 199},
+		3: {Match: 0},
+	}
+
+	// the new replica misses all the retained entries, which are still less
+	// than the shard size, the raft log is retained for it.
+	pr.stats.raftLogSizeHint = 500
+	pr.stats.approximateSize = 1024
+	pr.doCheckLogCompact(progresses, 199)
+	assert.Equal(t, int64(0), pr.requests.Len())
+	assert.True(t, pr.canCatchUpByLog(0, 100, 199))
+	// the first retained entry is the next entry of the match index
+	assert.True(t, pr.canCatchUpByLog(99, 100, 199))
+	assert.False(t, pr.canCatchUpByLog(98, 100, 199))
+
+	// the snapshot is smaller than the retained entries
+	pr.stats.approximateSize = 400
+	pr.doCheckLogCompact(progresses, 199)
+	v, _ := pr.requests.Peek()
+	req := &rpcpb.CompactLogRequest{}
+	protoc.MustUnmarshal(req, v.(reqCtx).req.Cmd)
+	assert.Equal(t, uint64(198), req.CompactIndex)
+}
+
+func TestEstimateLogSize(t *testing.T) {
+	assert.Equal(t, uint64(0), estimateLogSize(0, 10, 5))
+	assert.Equal(t, uint64(100), estimateLogSize(100, 0, 5))
+	assert.Equal(t, uint64(100), estimateLogSize(100, 10, 20))
+	assert.Equal(t, uint64(50), estimateLogSize(100, 10, 5))
+}
@@ -14,6 +14,10 @@
 package raftstore
 
 import (
+	"fmt"
+	"sync/atomic"
+	"time"
+
 	"github.com/cockroachdb/errors"
 	"github.com/fagongzi/util/protoc"
 	"github.com/matrixorigin/matrixcube/components/log"
@@ -73,6 +77,7 @@ func (pr *replica) handleRequest(items []interface{}) bool {
 		}
 		for i := int64(0); i < n; i++ {
 			req := items[i].(reqCtx)
+			pr.stageMetrics.Observe(metric.ProposalStageQueueWait, req.createdAt)
 			if ce := pr.logger.Check(zap.DebugLevel, "push to proposal batch"); ce != nil {
 				ce.Write(log.HexField("id", req.req.ID))
 			}
@@ -101,9 +106,20 @@ func (pr *replica) handleRequest(items []interface{}) bool {
 }
 
 func (pr *replica) propose(c batch) {
-	if !pr.checkProposal(c) {
+	if !pr.checkProposal(c) || !pr.checkWitnessProposal(c) || !pr.checkDrainProposal(c) ||
+		!pr.checkMergeProposal(c) || !pr.checkCustomAdminCmd(c) || !pr.checkAdminDeadline(&c) || !pr.dropExpiredRequests(&c) ||
+		!pr.checkBackupProposal(&c) {
+		return
+	}
+	if c.requestBatch.IsAdmin() &&
+		c.requestBatch.GetAdminCmdType() == rpcpb.AdminSplitShard {
+		pr.splitShard(c)
 		return
 	}
+	if c.requestBatch.IsAdmin() &&
+		c.requestBatch.GetAdminCmdType() == rpcpb.AdminPrepareMerge {
+		pr.setMergeDeadline(c.deadline)
+	}
 	defer pr.notifyWorker()
 
 	isConfChange := false
@@ -112,6 +128,7 @@ func (pr *replica) propose(c batch) {
 	case readIndex:
 		pr.execReadIndex(c)
 	case proposalNormal:
+		c.index, c.proposedAt = pr.nextProposalIndex(), time.Now()
 		madeProposal = pr.proposeNormal(c)
 	case requestTransferLeader:
 		madeProposal = pr.requestTransferLeader(c)
@@ -126,6 +143,74 @@ func (pr *replica) propose(c batch) {
 	}
 }
 
+// checkAdminDeadline sets the deadline of the admin request which did not
+// inherit one from the flow issued it, and rejects the admin request whose
+// deadline passed before it was proposed.
+func (pr *replica) checkAdminDeadline(c *batch) bool {
+	if !c.requestBatch.IsAdmin() {
+		return true
+	}
+
+	if c.deadline.IsZero() {
+		c.deadline = pr.adminDeadline()
+		return true
+	}
+	if c.isExpired(time.Now()) {
+		pr.logger.Warn("admin request expired before proposing",
+			zap.String("admin-type", c.requestBatch.GetAdminCmdType().String()))
+		c.respAdminTimeout(pr.shardID)
+		return false
+	}
+	return true
+}
+
+// dropExpiredRequests responds the RequestTimeout error to the requests whose
+// deadline passed before they were proposed, and removes them from the batch to
+// avoid wasting the raft work on them. It returns false if all requests of the
+// batch are dropped.
+func (pr *replica) dropExpiredRequests(c *batch) bool {
+	if c.requestBatch.IsAdmin() {
+		return true
+	}
+
+	now := time.Now()
+	var expired []rpcpb.Request
+	requests := c.requestBatch.Requests[:0]
+	for _, req := range c.requestBatch.Requests {
+		if isRequestExpired(req, now) {
+			expired = append(expired, req)
+			c.byteSize -= req.Size()
+			continue
+		}
+		requests = append(requests, req)
+	}
+	if len(expired) == 0 {
+		return true
+	}
+
+	if ce := pr.logger.Check(zap.DebugLevel, "requests expired before proposing"); ce != nil {
+		ce.Write(zap.Int("count", len(expired)))
+	}
+	timeout := newBatch(pr.logger, rpcpb.RequestBatch{
+		Header:   c.requestBatch.Header,
+		Requests: expired,
+	}, c.cb, c.tp, 0)
+	timeout.respRequestTimeout(pr.shardID)
+
+	c.requestBatch.Requests = requests
+	return len(requests) > 0
+}
+
+// adminDeadline returns the deadline of the admin flow started now, zero if the
+// admin proposal timeout is disabled.
+func (pr *replica) adminDeadline() time.Time {
+	timeout := pr.cfg.Raft.AdminProposalTimeout.Duration
+	if timeout <= 0 {
+		return time.Time{}
+	}
+	return time.Now().Add(timeout)
+}
+
 func (pr *replica) updatePendingProposal(c batch, isConfChange bool) {
 	if isConfChange {
 		changeC := pr.pendingProposals.getConfigChange()
@@ -151,11 +236,23 @@ func (pr *replica) execReadIndex(c batch) {
 	if c.tp != read {
 		panic("not a read index request")
 	}
-	if !pr.isLeader() {
+	issued := false
+	if pr.isLeader() {
+		issued = pr.issueReadIndex(c)
+	} else if isFollowerRead(c.requestBatch) {
+		issued = pr.issueFollowerReadIndex(c)
+	}
+	if !issued {
 		pr.respNotLeader(c)
 		return
 	}
+	pr.metrics.propose.readIndex++
+	atomic.AddUint64(&pr.readIndexCount, 1)
+}
 
+// issueReadIndex issues a ReadIndex for the batch on the leader, false is
+// returned if the ReadIndex is dropped by raft.
+func (pr *replica) issueReadIndex(c batch) bool {
 	prevPendingReadCount := pr.pendingReadCount()
 	prevReadyReadCount := pr.readyReadCount()
 
@@ -166,15 +263,15 @@ func (pr *replica) execReadIndex(c batch) {
 
 	if pendingReadCount == prevPendingReadCount &&
 		readyReadCount == prevReadyReadCount {
-		pr.respNotLeader(c)
-		return
+		return false
 	}
-	pr.metrics.propose.readIndex++
 	if ce := pr.logger.Check(zap.DebugLevel, "call read index"); ce != nil {
 		ce.Write(log.HexField("id", c.getRequestID()))
 	}
 
+	pr.lastReadIndexTime = time.Now()
 	pr.pendingReads.append(c)
+	return true
 }
 
 func (pr *replica) proposeNormal(c batch) bool {
@@ -183,6 +280,9 @@ func (pr *replica) proposeNormal(c batch) bool {
 		return false
 	}
 
+	// the leader assigns the current time of the batch, so all the replicas
+	// applying it expire the same data
+	c.requestBatch.Header.Timestamp = time.Now().UnixNano()
 	data := protoc.MustMarshal(&c.requestBatch)
 	size := len(data)
 	metric.ObserveProposalBytes(int64(size))
@@ -193,7 +293,10 @@ func (pr *replica) proposeNormal(c batch) bool {
 	}
 
 	idx := pr.nextProposalIndex()
-	if err := pr.rn.Propose(data); err != nil {
+	start := time.Now()
+	err := pr.rn.Propose(data)
+	pr.stageMetrics.Observe(metric.ProposalStageRaftStep, start)
+	if err != nil {
 		c.resp(errorOtherCMDResp(err))
 		return false
 	}
@@ -235,10 +338,16 @@ func (pr *replica) proposeConfChange(c batch) bool {
 }
 
 func (pr *replica) proposeConfChangeInternal(c batch) error {
-	req := c.requestBatch.GetConfigChangeRequest()
-	cc := pr.toConfChangeI(req, protoc.MustMarshal(&c.requestBatch))
 	var changes []rpcpb.ConfigChangeRequest
-	changes = append(changes, req)
+	var cc raftpb.ConfChangeI
+	if c.requestBatch.GetAdminCmdType() == rpcpb.AdminConfigChangeV2 {
+		changes = c.requestBatch.GetConfigChangeV2Request().Changes
+		cc = pr.toConfChangeV2(changes, protoc.MustMarshal(&c.requestBatch))
+	} else {
+		req := c.requestBatch.GetConfigChangeRequest()
+		changes = append(changes, req)
+		cc = pr.toConfChangeI(req, protoc.MustMarshal(&c.requestBatch))
+	}
 	if err := pr.checkConfChange(changes, cc); err != nil {
 		return err
 	}
@@ -270,6 +379,24 @@ func (pr *replica) toConfChangeI(req rpcpb.ConfigChangeRequest, data []byte) raf
 	}
 }
 
+// toConfChangeV2 returns the ConfChangeV2 of the changes. Multiple changes enter
+// the joint state which is left explicitly by the ConfChangeV2 without changes.
+func (pr *replica) toConfChangeV2(changes []rpcpb.ConfigChangeRequest, data []byte) raftpb.ConfChangeI {
+	cc := &raftpb.ConfChangeV2{
+		Context: data,
+	}
+	if getConfigChangeKind(len(changes)) == enterJointKind {
+		cc.Transition = raftpb.ConfChangeTransitionJointExplicit
+	}
+	for _, req := range changes {
+		cc.Changes = append(cc.Changes, raftpb.ConfChangeSingle{
+			Type:   raftpb.ConfChangeType(req.ChangeType),
+			NodeID: req.Replica.ID,
+		})
+	}
+	return cc
+}
+
 func (pr *replica) requestTransferLeader(c batch) bool {
 	req := c.requestBatch.GetTransferLeaderRequest()
 	// has pending confChange, skip
@@ -296,6 +423,8 @@ func (pr *replica) doTransferLeader(peer Replica) {
 	// Broadcast heartbeat to make sure followers commit the entries immediately.
 	// It's only necessary to ping the target peer, but ping all for simplicity.
 	pr.rn.Ping()
+	// the target replica campaigns regardless of the lease held by us
+	pr.lease.expire()
 	pr.rn.TransferLeader(peer.ID)
 	pr.metrics.propose.transferLeader++
 }
@@ -334,6 +463,30 @@ func (pr *replica) checkProposal(c batch) bool {
 	return true
 }
 
+// checkCustomAdminCmd validates the custom admin command on the leader before
+// proposing it, the followers respond not leader when proposing it.
+func (pr *replica) checkCustomAdminCmd(c batch) bool {
+	if !c.requestBatch.IsAdmin() ||
+		c.requestBatch.GetAdminCmdType() < rpcpb.MinCustomAdminCmdType ||
+		!pr.isLeader() {
+		return true
+	}
+
+	req := c.requestBatch.GetAdminRequest()
+	h, ok := pr.cfg.Customize.CustomAdminCmdHandlers[c.requestBatch.GetAdminCmdType()]
+	if !ok {
+		c.respOtherError(fmt.Errorf("custom admin cmd type %d not registered", req.CustomType))
+		return false
+	}
+	if h.Validate != nil {
+		if err := h.Validate(pr.getShard(), req.Cmd); err != nil {
+			c.respOtherError(err)
+			return false
+		}
+	}
+	return true
+}
+
 func isValidConfigChangeRequest(ccr rpcpb.ConfigChangeRequest) bool {
 	// remove voter or learner
 	if ccr.ChangeType == metapb.ConfigChangeType_RemoveNode {
@@ -437,7 +590,7 @@ func (pr *replica) checkJointState(cci raftpb.ConfChangeI) (*tracker, error) {
 	cc := cci.AsV2()
 	if cc.LeaveJoint() {
 		cfg, _, changes, err = changer.LeaveJoint()
-	} else if autoLeave, _ := cc.EnterJoint(); autoLeave {
+	} else if autoLeave, ok := cc.EnterJoint(); ok {
 		cfg, _, changes, err = changer.EnterJoint(autoLeave, cc.Changes...)
 	} else {
 		cfg, _, changes, err = changer.Simple(cc.Changes...)
@@ -454,7 +607,7 @@ func (pr *replica) checkJointState(cci raftpb.ConfChangeI) (*tracker, error) {
 func (pr *replica) getRequestType(req rpcpb.RequestBatch) requestType {
 	if req.IsAdmin() {
 		switch req.GetAdminCmdType() {
-		case rpcpb.AdminConfigChange:
+		case rpcpb.AdminConfigChange, rpcpb.AdminConfigChangeV2:
 			return proposalConfigChange
 		case rpcpb.AdminTransferLeader:
 			return requestTransferLeader
@@ -14,8 +14,11 @@
 package raftstore
 
 import (
+	"errors"
+	"fmt"
 	"math"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -263,6 +266,37 @@ func TestToConfigChangeIV1(t *testing.T) {
 	assert.Equal(t, data, cc.Context)
 }
 
+func TestToConfigChangeV2(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	p := replica{}
+	data := []byte{0x23, 0xbf}
+	changes := []rpcpb.ConfigChangeRequest{
+		{
+			ChangeType: metapb.ConfigChangeType_AddNode,
+			Replica:    metapb.Replica{ID: 1},
+		},
+		{
+			ChangeType: metapb.ConfigChangeType_AddLearnerNode,
+			Replica:    metapb.Replica{ID: 2},
+		},
+	}
+	cc, ok := p.toConfChangeV2(changes, data).(*raftpb.ConfChangeV2)
+	require.True(t, ok)
+	assert.Equal(t, raftpb.ConfChangeTransitionJointExplicit, cc.Transition)
+	assert.Equal(t, data, cc.Context)
+	require.Equal(t, 2, len(cc.Changes))
+	assert.Equal(t, raftpb.ConfChangeAddNode, cc.Changes[0].Type)
+	assert.Equal(t, uint64(1), cc.Changes[0].NodeID)
+	assert.Equal(t, raftpb.ConfChangeAddLearnerNode, cc.Changes[1].Type)
+	assert.Equal(t, uint64(2), cc.Changes[1].NodeID)
+
+	// leave the joint state
+	cc, ok = p.toConfChangeV2(nil, data).(*raftpb.ConfChangeV2)
+	require.True(t, ok)
+	assert.True(t, cc.LeaveJoint())
+}
+
 func TestInvalidConfigChangeRequestIsRejected(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 
@@ -381,3 +415,112 @@ func TestInvalidConfigChangeRequestIsRejected(t *testing.T) {
 		assert.Equal(t, tt.err, result, "idx: %d", idx)
 	}
 }
+
+func TestCheckCustomAdminCmd(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	cmdType := rpcpb.MinCustomAdminCmdType
+	pr := &replica{replicaID: 1, leaderID: 1}
+	pr.sm = &stateMachine{}
+	pr.cfg.Customize.CustomAdminCmdHandlers = map[rpcpb.AdminCmdType]config.CustomAdminCmdHandler{
+		cmdType: {
+			Validate: func(shard metapb.Shard, cmd []byte) error {
+				if string(cmd) == "bad" {
+					return errors.New("bad cmd")
+				}
+				return nil
+			},
+		},
+	}
+
+	var rsp rpcpb.ResponseBatch
+	newAdminBatch := func(cmdType rpcpb.AdminCmdType, cmd string) batch {
+		rsp = rpcpb.ResponseBatch{}
+		return newBatch(nil, newTestAdminRequestBatch("r1", 0, cmdType, []byte(cmd)),
+			func(r rpcpb.ResponseBatch) { rsp = r }, admin, 0)
+	}
+
+	assert.True(t, pr.checkCustomAdminCmd(newAdminBatch(rpcpb.AdminUpdateLabels, "bad")))
+	assert.True(t, pr.checkCustomAdminCmd(newAdminBatch(cmdType, "c1")))
+	assert.False(t, pr.checkCustomAdminCmd(newAdminBatch(cmdType, "bad")))
+	assert.Equal(t, "bad cmd", rsp.Header.Error.Message)
+	assert.False(t, pr.checkCustomAdminCmd(newAdminBatch(cmdType+1, "c1")))
+	assert.NotEmpty(t, rsp.Header.Error.Message)
+
+	// validated by the leader only
+	pr.leaderID = 2
+	assert.True(t, pr.checkCustomAdminCmd(newAdminBatch(cmdType, "bad")))
+}
+
+func TestCheckAdminDeadline(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	pr := &replica{shardID: 1, logger: log.GetPanicZapLogger()}
+	var rsp rpcpb.ResponseBatch
+	newAdminBatch := func(deadline time.Time) batch {
+		rsp = rpcpb.ResponseBatch{}
+		c := newBatch(nil, newTestAdminRequestBatch("r1", 0, rpcpb.AdminBatchSplit, nil),
+			func(r rpcpb.ResponseBatch) { rsp = r }, admin, 0)
+		c.deadline = deadline
+		return c
+	}
+
+	// the admin proposal timeout is disabled
+	c := newAdminBatch(time.Time{})
+	assert.True(t, pr.checkAdminDeadline(&c))
+	assert.True(t, c.deadline.IsZero())
+
+	pr.cfg.Raft.AdminProposalTimeout.Duration = time.Minute
+	c = newAdminBatch(time.Time{})
+	assert.True(t, pr.checkAdminDeadline(&c))
+	assert.False(t, c.deadline.IsZero())
+
+	// the inherited deadline is kept
+	deadline := time.Now().Add(time.Second)
+	c = newAdminBatch(deadline)
+	assert.True(t, pr.checkAdminDeadline(&c))
+	assert.Equal(t, deadline, c.deadline)
+
+	c = newAdminBatch(time.Now().Add(-time.Second))
+	assert.False(t, pr.checkAdminDeadline(&c))
+	assert.NotNil(t, rsp.Header.Error.AdminTimeout)
+}
+
+func TestDropExpiredRequests(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	pr := &replica{shardID: 1, logger: log.GetPanicZapLogger()}
+	var rsp rpcpb.ResponseBatch
+	now := time.Now()
+	newWriteBatch := func(deadlines ...time.Time) batch {
+		rsp = rpcpb.ResponseBatch{}
+		rb := rpcpb.RequestBatch{}
+		rb.Header.ID = []byte("b1")
+		for idx, deadline := range deadlines {
+			req := rpcpb.Request{ID: []byte(fmt.Sprintf("r%d", idx)), Type: rpcpb.Write}
+			if !deadline.IsZero() {
+				req.Deadline = deadline.UnixNano()
+			}
+			rb.Requests = append(rb.Requests, req)
+		}
+		return newBatch(nil, rb, func(r rpcpb.ResponseBatch) { rsp = r }, write, 0)
+	}
+
+	c := newWriteBatch(time.Time{}, now.Add(time.Minute))
+	assert.True(t, pr.dropExpiredRequests(&c))
+	assert.Equal(t, 2, len(c.requestBatch.Requests))
+	assert.Empty(t, rsp.Responses)
+
+	c = newWriteBatch(time.Time{}, now.Add(-time.Second), now.Add(time.Minute))
+	assert.True(t, pr.dropExpiredRequests(&c))
+	assert.Equal(t, 2, len(c.requestBatch.Requests))
+	assert.Equal(t, []byte("r0"), c.requestBatch.Requests[0].ID)
+	assert.Equal(t, []byte("r2"), c.requestBatch.Requests[1].ID)
+	assert.Equal(t, 1, len(rsp.Responses))
+	assert.Equal(t, []byte("r1"), rsp.Responses[0].ID)
+	assert.NotNil(t, rsp.Responses[0].Error.RequestTimeout)
+
+	c = newWriteBatch(now.Add(-time.Second))
+	assert.False(t, pr.dropExpiredRequests(&c))
+	assert.Equal(t, 1, len(rsp.Responses))
+}
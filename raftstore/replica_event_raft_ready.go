@@ -72,15 +72,20 @@ func (pr *replica) processReady(rd raft.Ready, wc *logdb.WorkerContext) error {
 func (pr *replica) handleRaftState(rd raft.Ready) {
 	// etcd raft won't repeatedly return the same non-empty soft state
 	if rd.SoftState != nil {
+		pr.lease.expire()
 		pr.setLeaderReplicaID(rd.SoftState.Lead)
 		shard := pr.getShard()
 		// If we become leader, send heartbeat to pd
 		if rd.SoftState.RaftState == raft.StateLeader {
 			pr.logger.Info("********become leader now********")
-			pr.prophetHeartbeat()
+			pr.drainTarget = Replica{}
 			pr.resetIncomingProposals()
-			if pr.aware != nil {
-				pr.aware.BecomeLeader(shard)
+			if !pr.maybeStartLeaderWarmup(pr.rn.BasicStatus().Term) {
+				pr.announceLeadership()
+			}
+			pr.maybePrewarmLease()
+			if pr.isWitness() {
+				pr.transferWitnessLeadership()
 			}
 			// When a replica is not started for other reasons, then the map does not contain
 			// information about the replica, and we cannot remove the replica.
@@ -93,6 +98,8 @@ func (pr *replica) handleRaftState(rd raft.Ready) {
 			}
 		} else {
 			pr.logger.Info("********become follower now********")
+			pr.warmupTerm = 0
+			pr.transferTarget = false
 			if pr.aware != nil {
 				pr.aware.BecomeFollower(shard)
 			}
@@ -101,6 +108,18 @@ func (pr *replica) handleRaftState(rd raft.Ready) {
 	}
 }
 
+// announceLeadership reports the leadership to prophet, the router learns the
+// new leader from prophet.
+func (pr *replica) announceLeadership() {
+	if pr.cfg.Raft.EnableLeaderTransferCoordination {
+		pr.updateRouterLeader(pr.replicaID)
+	}
+	pr.prophetHeartbeat()
+	if pr.aware != nil {
+		pr.aware.BecomeLeader(pr.getShard())
+	}
+}
+
 func getEstimatedAppendSize(rd raft.Ready) int {
 	sz := 0
 	for _, e := range rd.Entries {
@@ -147,7 +166,9 @@ func (pr *replica) saveRaftState(rd raft.Ready, wc *logdb.WorkerContext) error {
 		"begin to save raft state"); ce != nil {
 		startTime = time.Now().UnixMilli()
 	}
+	start := time.Now()
 	err := pr.logdb.SaveRaftState(pr.shardID, pr.replicaID, rd, wc)
+	pr.stageMetrics.Observe(metric.ProposalStageLogDBAppend, start)
 	if err != nil {
 		return err
 	}
@@ -160,6 +181,7 @@ func (pr *replica) saveRaftState(rd raft.Ready, wc *logdb.WorkerContext) error {
 	if !raft.IsEmptyHardState(rd.HardState) {
 		pr.lastCommittedIndex = rd.HardState.Commit
 		pr.committedIndexes[pr.replicaID] = pr.lastCommittedIndex
+		pr.pendingProposals.committed(pr.lastCommittedIndex)
 	}
 	return nil
 }
@@ -193,6 +215,7 @@ func (pr *replica) applyCommittedEntries(rd raft.Ready) error {
 			return err
 		}
 		pr.pushedIndex = rd.Snapshot.Metadata.Index
+		pr.pendingApplyEntries = nil
 		pr.logger.Info("snapshot applied into the replica")
 	}
 	for _, entry := range rd.CommittedEntries {
@@ -221,14 +244,34 @@ func (pr *replica) applyCommittedEntries(rd raft.Ready) error {
 }
 
 func (pr *replica) handleReadyToRead(rd raft.Ready) {
+	// the transfer target campaigns regardless of the lease, so the lease must
+	// not be renewed until the leader transfer is done.
+	transferring := len(rd.ReadStates) > 0 && pr.rn.BasicStatus().LeadTransferee != 0
+	// the follower reads neither renew the lease nor establish the resolved ts
+	leader := pr.isLeader()
 	for _, state := range rd.ReadStates {
-		pr.pendingReads.ready(state)
+		if start, ok := pr.pendingReads.ready(state); ok && !transferring && leader {
+			pr.lease.renew(state.Index, start)
+			pr.resolvedTS.track(state.Index, pr.toResolvedTS(start))
+			pr.lastReadIndexTime = time.Now()
+		}
 	}
 	if len(rd.ReadStates) > 0 {
 		pr.maybeExecRead()
 	}
 }
 
+// toResolvedTS returns the resolved timestamp established by the ReadIndex
+// issued at the specified time, the tolerated clock drift is excluded so the
+// followers are able to compare it with their local clocks.
+func (pr *replica) toResolvedTS(start time.Time) uint64 {
+	ts := unixMilli(start.Add(-pr.store.cfg.Raft.MaxLeaseClockDrift.Duration))
+	if ts <= 0 {
+		return 0
+	}
+	return uint64(ts)
+}
+
 func (pr *replica) sendRaftAppendLogMessages(rd raft.Ready) {
 	// MsgApp can be immediately sent to followers so leader and followers can
 	// concurrently persist the logs to disk. For more details, check raft thesis
@@ -256,6 +299,13 @@ func (pr *replica) send(msgs []raftpb.Message, msgAppOnly bool) {
 }
 
 func (pr *replica) sendMessage(msg raftpb.Message) {
+	// the campaign never wins without the votes, and the term is not bumped as
+	// the PreVote is always enabled. The witness never campaigns, as the leader
+	// it would send the snapshots without data to the followers.
+	if isElectionMessage(msg) &&
+		(pr.store.isRejectingElections() || pr.isWitness()) {
+		return
+	}
 	if err := pr.sendRaftMessage(msg); err != nil {
 		// We don't care such failed message transmission, just log the error
 		pr.logger.Debug("fail to send msg",
@@ -275,17 +325,18 @@ func (pr *replica) sendRaftMessage(msg raftpb.Message) error {
 	}
 
 	m := metapb.RaftMessage{
-		ShardID:     pr.shardID,
-		From:        pr.replica,
-		To:          to,
-		Start:       shard.Start,
-		End:         shard.End,
-		ShardEpoch:  shard.Epoch,
-		Group:       shard.Group,
-		Unique:      shard.Unique,
-		RuleGroups:  shard.RuleGroups,
-		Message:     msg,
-		CommitIndex: pr.lastCommittedIndex,
+		ShardID:      pr.shardID,
+		From:         pr.replica,
+		To:           to,
+		Start:        shard.Start,
+		End:          shard.End,
+		ShardEpoch:   shard.Epoch,
+		Group:        shard.Group,
+		Unique:       shard.Unique,
+		RuleGroups:   shard.RuleGroups,
+		Message:      msg,
+		CommitIndex:  pr.lastCommittedIndex,
+		AppliedIndex: pr.appliedIndex,
 		// FIXME: remove this hack
 		SendTime: uint64(time.Now().UnixMilli()),
 	}
@@ -306,8 +357,24 @@ func (pr *replica) sendRaftMessage(msg raftpb.Message) error {
 		m.Start = shard.Start
 		m.End = shard.End
 	}
+	if pr.isLeader() {
+		resolved := pr.resolvedTS.getLatest()
+		m.ResolvedTS = resolved.ts
+		m.ResolvedIndex = resolved.index
+	}
+	if msg.Type == raftpb.MsgTimeoutNow {
+		m.WarmupKeys = pr.getWarmupKeys()
+		pr.preUpdateRouterLeader(msg.To)
+	}
 
 	if msg.Type == raftpb.MsgSnap {
+		if isWitnessReplica(shard, msg.To) {
+			if ss, ok := pr.createWitnessSnapshot(); ok {
+				m.Message.Snapshot = ss
+			}
+		} else if ss, ok := pr.createDeltaSnapshot(msg.To); ok {
+			m.Message.Snapshot = ss
+		}
 		pr.logger.Info("sending a snapshot message")
 		pr.transport.SendSnapshot(m)
 	} else {
@@ -342,11 +409,24 @@ func (pr *replica) doApplyCommittedEntries(entries []raftpb.Entry) error {
 	entries = pr.entriesToApply(entries)
 	if len(entries) > 0 {
 		pr.pushedIndex = entries[len(entries)-1].Index
-		pr.sm.applyCommittedEntries(entries)
-		if pr.sm.isRemoved() {
-			// local replica is removed, keep the shard
-			pr.store.destroyReplica(pr.shardID, false, true, "removed by config change")
-		}
+		pr.pendingProposals.applying(pr.pushedIndex)
+		pr.applyEntries(append(pr.pendingApplyEntries, entries...))
 	}
 	return nil
 }
+
+// applyPendingEntries applies the entries paused to wait for the merge source,
+// it's called once the source shard notifies the merge.
+func (pr *replica) applyPendingEntries() {
+	if len(pr.pendingApplyEntries) > 0 {
+		pr.applyEntries(pr.pendingApplyEntries)
+	}
+}
+
+func (pr *replica) applyEntries(entries []raftpb.Entry) {
+	pr.pendingApplyEntries = pr.sm.applyCommittedEntries(entries)
+	if pr.sm.isRemoved() {
+		// local replica is removed, keep the shard
+		pr.store.destroyReplica(pr.shardID, false, true, "removed by config change")
+	}
+}
@@ -92,7 +92,8 @@ func TestSendRaftMesasgeReturnsErrUnknownReplicaWhenReplicaIsUnknown(t *testing.
 }
 
 type replicaTestTransport struct {
-	messages []metapb.RaftMessage
+	messages   []metapb.RaftMessage
+	progresses []metapb.SnapshotProgress
 }
 
 func (t *replicaTestTransport) Send(m metapb.RaftMessage) bool {
@@ -118,17 +119,32 @@ func (t *replicaTestTransport) SetFilter(func(metapb.RaftMessage) bool) {
 }
 
 func (t *replicaTestTransport) SendingSnapshotCount() uint64 {
-	return 0
+	count := uint64(0)
+	for _, p := range t.progresses {
+		if p.Sending {
+			count++
+		}
+	}
+	return count
+}
+
+func (t *replicaTestTransport) SnapshotThrottled() bool {
+	return false
+}
+
+func (t *replicaTestTransport) SnapshotProgresses() []metapb.SnapshotProgress {
+	return t.progresses
 }
 
 func TestSendRaftMessageAttachsExpectedShardDetails(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	trans := &replicaTestTransport{}
 	r := replica{
-		replica:   Replica{ID: 100},
-		store:     &store{},
-		sm:        &stateMachine{},
-		transport: trans,
+		replica:    Replica{ID: 100},
+		store:      &store{},
+		sm:         &stateMachine{},
+		transport:  trans,
+		resolvedTS: newResolvedTS(),
 	}
 	shard := Shard{
 		Start: []byte("start-key"),
@@ -165,10 +181,11 @@ func testSendMessages(t *testing.T, appendOnly bool) {
 	defer leaktest.AfterTest(t)()
 	trans := &replicaTestTransport{}
 	r := replica{
-		replica:   Replica{ID: 100},
-		store:     &store{},
-		sm:        &stateMachine{},
-		transport: trans,
+		replica:    Replica{ID: 100},
+		store:      &store{},
+		sm:         &stateMachine{},
+		transport:  trans,
+		resolvedTS: newResolvedTS(),
 	}
 	shard := Shard{
 		Start: []byte("start-key"),
@@ -0,0 +1,131 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package raftstore
+
+import (
+	"context"
+	"time"
+
+	"github.com/fagongzi/util/protoc"
+	"go.uber.org/zap"
+
+	"github.com/matrixorigin/matrixcube/components/log"
+	"github.com/matrixorigin/matrixcube/pb/rpcpb"
+)
+
+// A shard is fenced by a barrier entry with the fence flag, e.g. while the
+// consistent backup of its group is being created. The writes applied after the
+// fence are rejected until the shard is unfenced by another barrier entry, so
+// the fence is deterministic on all replicas. The client fencing the shards is
+// expected to unfence them, otherwise the leader unfences the shard after the
+// fence timeout.
+//
+// The shard is fenced as migrated once its group is switched to another
+// cluster, all the requests are rejected with the GroupMigrated error so the
+// proxies of all the clients follow the switch, see `groupMigrations`. The
+// migrated fence never times out.
+//
+// The backup of the group is created in two phases, all the shards are fenced
+// first, then a barrier entry with the backup path is proposed to each shard.
+// The backup is created asynchronously by the replica proposed it once applied,
+// the data of the fenced shard doesn't change meanwhile.
+
+// applyBarrier resets the fence timer of the leader once the fence of the
+// shard is changed.
+func (pr *replica) applyBarrier() {
+	if pr.sm.getFenceIndex() == 0 {
+		pr.fencedSince = time.Time{}
+	} else if pr.fencedSince.IsZero() {
+		pr.fencedSince = time.Now()
+	}
+}
+
+// doCheckFence unfences the shard if it has been fenced longer than the fence
+// timeout since the leader found it fenced, except the migrated shard.
+func (pr *replica) doCheckFence() {
+	index := pr.sm.getFenceIndex()
+	if index == 0 || pr.sm.isMigrated() || !pr.isLeader() {
+		pr.fencedSince = time.Time{}
+		return
+	}
+	if pr.fencedSince.IsZero() {
+		pr.fencedSince = time.Now()
+		return
+	}
+	if time.Since(pr.fencedSince) < pr.cfg.Replication.FenceTimeout.Duration {
+		return
+	}
+
+	pr.logger.Warn("fence timeout, unfence the shard",
+		log.IndexField(index),
+		zap.Duration("fenced", time.Since(pr.fencedSince)))
+	// the timer restarts in case the unfence barrier is lost
+	pr.fencedSince = time.Now()
+	pr.addAdminRequest(rpcpb.AdminBarrier, &rpcpb.BarrierRequest{Unfence: true})
+}
+
+// checkBackupProposal responds the barrier request with the backup path once
+// the backup is created, the backup is created by the read workers once the
+// barrier entry is applied instead of the apply path.
+func (pr *replica) checkBackupProposal(c *batch) bool {
+	if !c.requestBatch.IsAdmin() ||
+		c.requestBatch.GetAdminCmdType() != rpcpb.AdminBarrier ||
+		c.requestBatch.GetBarrierRequest().BackupPath == "" ||
+		c.cb == nil {
+		return true
+	}
+
+	cb := c.cb
+	c.cb = func(resp rpcpb.ResponseBatch) {
+		if resp.Header.IsEmpty() && len(resp.Responses) == 1 {
+			br := rpcpb.BarrierResponse{}
+			protoc.MustUnmarshal(&br, resp.Responses[0].Value)
+			if err := pr.readStopper.RunTask(context.Background(), func(ctx context.Context) {
+				cb(pr.createBackup(resp, br))
+			}); err == nil {
+				return
+			}
+			resp = backupErrorResp(resp, errShardNotFound)
+		}
+		cb(resp)
+	}
+	return true
+}
+
+// createBackup creates the backup of the shard at the path of the barrier
+// response.
+func (pr *replica) createBackup(resp rpcpb.ResponseBatch,
+	br rpcpb.BarrierResponse) rpcpb.ResponseBatch {
+	start := time.Now()
+	if err := pr.sm.dataStorage.CreateSnapshot(pr.shardID, br.BackupPath); err != nil {
+		pr.logger.Error("failed to create backup",
+			log.IndexField(br.Index),
+			zap.String("path", br.BackupPath),
+			zap.Error(err))
+		return backupErrorResp(resp, err)
+	}
+	pr.logger.Info("backup created",
+		log.IndexField(br.Index),
+		zap.String("path", br.BackupPath),
+		zap.Duration("cost", time.Since(start)))
+	return resp
+}
+
+func backupErrorResp(resp rpcpb.ResponseBatch, err error) rpcpb.ResponseBatch {
+	resp.Header.Error.Message = err.Error()
+	for idx := range resp.Responses {
+		resp.Responses[idx].Value = nil
+	}
+	return resp
+}
@@ -0,0 +1,58 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package raftstore
+
+import (
+	"github.com/matrixorigin/matrixcube/components/log"
+	"github.com/matrixorigin/matrixcube/metric"
+	"github.com/matrixorigin/matrixcube/pb/rpcpb"
+	"go.uber.org/zap"
+)
+
+// isFollowerRead returns true if the requests of the batch are the read requests
+// allowed to be served by the follower, i.e. the requests are routed to the
+// follower by the SelectRandom or the SelectFollower ReplicaSelectPolicy. The
+// follower executes the read once the read index confirmed by the leader is
+// applied, so the read is as consistent as the read on the leader.
+func isFollowerRead(batch rpcpb.RequestBatch) bool {
+	if len(batch.Requests) == 0 {
+		return false
+	}
+	for _, req := range batch.Requests {
+		if req.Type != rpcpb.Read ||
+			req.ReplicaSelectPolicy == rpcpb.SelectLeader {
+			return false
+		}
+	}
+	return true
+}
+
+// issueFollowerReadIndex issues a ReadIndex for the batch on the follower, the
+// ReadIndex is forwarded to the leader by raft. false is returned if the leader
+// is unknown and the ReadIndex is dropped by raft.
+func (pr *replica) issueFollowerReadIndex(c batch) bool {
+	if pr.getLeaderReplicaID() == 0 {
+		return false
+	}
+
+	pr.rn.ReadIndex(c.getRequestID())
+	if ce := pr.logger.Check(zap.DebugLevel, "call follower read index"); ce != nil {
+		ce.Write(log.HexField("id", c.getRequestID()),
+			log.ReplicaIDField(pr.getLeaderReplicaID()))
+	}
+
+	metric.AddRaftProposalReadFollowerCount(1)
+	pr.pendingReads.append(c)
+	return true
+}
@@ -0,0 +1,43 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package raftstore
+
+import (
+	"testing"
+
+	"github.com/matrixorigin/matrixcube/pb/rpcpb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsFollowerRead(t *testing.T) {
+	read := func(policy rpcpb.ReplicaSelectPolicy) rpcpb.Request {
+		return rpcpb.Request{Type: rpcpb.Read, ReplicaSelectPolicy: policy}
+	}
+	cases := []struct {
+		batch  rpcpb.RequestBatch
+		expect bool
+	}{
+		{batch: rpcpb.RequestBatch{}, expect: false},
+		{batch: rpcpb.RequestBatch{Requests: []rpcpb.Request{read(rpcpb.SelectLeader)}}, expect: false},
+		{batch: rpcpb.RequestBatch{Requests: []rpcpb.Request{read(rpcpb.SelectRandom)}}, expect: true},
+		{batch: rpcpb.RequestBatch{Requests: []rpcpb.Request{read(rpcpb.SelectFollower), read(rpcpb.SelectRandom)}}, expect: true},
+		{batch: rpcpb.RequestBatch{Requests: []rpcpb.Request{read(rpcpb.SelectFollower), read(rpcpb.SelectLeader)}}, expect: false},
+		{batch: rpcpb.RequestBatch{Requests: []rpcpb.Request{{Type: rpcpb.Write, ReplicaSelectPolicy: rpcpb.SelectFollower}}}, expect: false},
+		{batch: rpcpb.RequestBatch{Requests: []rpcpb.Request{{Type: rpcpb.Admin, ReplicaSelectPolicy: rpcpb.SelectRandom}}}, expect: false},
+	}
+
+	for i, c := range cases {
+		assert.Equal(t, c.expect, isFollowerRead(c.batch), "index %d", i)
+	}
+}
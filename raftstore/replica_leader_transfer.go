@@ -0,0 +1,65 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package raftstore
+
+import (
+	"github.com/matrixorigin/matrixcube/components/log"
+)
+
+// preUpdateRouterLeader points the shard in the router of the store to the
+// leader transfer target before the MsgTimeoutNow is sent, so the requests sent
+// through the store are routed to the new leader without a NotLeader retry once
+// the switch is done. If the transfer fails, the router is corrected by the
+// NotLeader response of the target.
+func (pr *replica) preUpdateRouterLeader(to uint64) {
+	if !pr.cfg.Raft.EnableLeaderTransferCoordination {
+		return
+	}
+	pr.updateRouterLeader(to)
+}
+
+// markTransferTarget records the MsgTimeoutNow of a planned leader transfer, the
+// replica is about to campaign and become the new leader.
+func (pr *replica) markTransferTarget() {
+	if pr.cfg.Raft.EnableLeaderTransferCoordination {
+		pr.transferTarget = true
+	}
+}
+
+// maybePrewarmLease establishes the lease of the new leader of a planned leader
+// transfer right after the switch by a ReadIndex without any request, instead
+// of waiting for the first read. The ReadIndex is confirmed once the new leader
+// committed an entry of its term.
+func (pr *replica) maybePrewarmLease() {
+	transferTarget := pr.transferTarget
+	pr.transferTarget = false
+	if !transferTarget || pr.cfg.Raft.GetLeaseReadDuration() <= 0 {
+		return
+	}
+
+	pr.logger.Info("prewarm the lease of the leader transfer target")
+	pr.issueIdleReadIndex()
+}
+
+// updateRouterLeader updates the leader of the shard in the router of the store,
+// nothing to do if the shard is not known by the router yet.
+func (pr *replica) updateRouterLeader(leader uint64) {
+	if pr.store.router == nil || pr.store.router.GetShard(pr.shardID).ID == 0 {
+		return
+	}
+
+	pr.logger.Debug("update the leader in the router of the store",
+		log.ReplicaIDField(leader))
+	pr.store.router.UpdateLeader(pr.shardID, leader)
+}
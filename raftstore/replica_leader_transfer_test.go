@@ -0,0 +1,63 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package raftstore
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/matrixorigin/matrixcube/components/log"
+	"github.com/matrixorigin/matrixcube/pb/metapb"
+	"github.com/matrixorigin/matrixcube/pb/rpcpb"
+)
+
+func TestPreUpdateRouterLeader(t *testing.T) {
+	rr, err := newRouterBuilder().build(make(chan rpcpb.EventNotify))
+	assert.NoError(t, err)
+	r := rr.(*defaultRouter)
+
+	b := NewTestDataBuilder()
+	shard := b.CreateShard(1, "100/101,200/201")
+	r.UpdateStore(metapb.Store{ID: 101})
+	r.UpdateStore(metapb.Store{ID: 201})
+	r.UpdateShard(shard)
+	r.UpdateLeader(shard.ID, 100)
+
+	pr := &replica{shardID: shard.ID, logger: log.GetPanicZapLogger(), store: &store{router: r}}
+	pr.preUpdateRouterLeader(200)
+	assert.Equal(t, uint64(101), r.LeaderReplicaStore(shard.ID).ID)
+
+	pr.cfg.Raft.EnableLeaderTransferCoordination = true
+	pr.preUpdateRouterLeader(200)
+	assert.Equal(t, uint64(201), r.LeaderReplicaStore(shard.ID).ID)
+
+	// the shard unknown by the router is skipped
+	pr.shardID = 2
+	pr.preUpdateRouterLeader(200)
+}
+
+func TestMarkTransferTarget(t *testing.T) {
+	pr := &replica{logger: log.GetPanicZapLogger()}
+	pr.markTransferTarget()
+	assert.False(t, pr.transferTarget)
+
+	pr.cfg.Raft.EnableLeaderTransferCoordination = true
+	pr.markTransferTarget()
+	assert.True(t, pr.transferTarget)
+
+	// the lease read is disabled, the flag is reset without issuing ReadIndex
+	pr.maybePrewarmLease()
+	assert.False(t, pr.transferTarget)
+}
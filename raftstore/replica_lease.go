@@ -0,0 +1,116 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package raftstore
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// readLease tracks the leader lease of a replica. A lease is established each
+// time a ReadIndex round trip completes. As CheckQuorum is always enabled, a
+// quorum of followers that acked the ReadIndex heartbeat will not vote for
+// any other candidate within the election timeout, so the leader is allowed
+// to serve reads locally until the lease expires.
+//
+// readLease is accessed from both the event worker and request goroutines.
+type readLease struct {
+	sync.RWMutex
+	duration time.Duration
+	// index is the read index returned by the ReadIndex that established the
+	// lease. Local reads are only allowed once the replica has applied it, so
+	// everything committed by previous leaders is visible.
+	index        uint64
+	expireAt     time.Time
+	appliedIndex uint64
+	// fence is the time when the lease was expired last time, the ReadIndex
+	// requests issued before it are not allowed to renew the lease.
+	fence time.Time
+	// served is set once a local read is served by the lease, and is reset by
+	// the renewal. Only the leases in use are renewed before expiring.
+	served uint32
+}
+
+func newReadLease(duration time.Duration) *readLease {
+	return &readLease{duration: duration}
+}
+
+// renew extends the lease, start is the time when the confirmed ReadIndex
+// request was issued.
+func (l *readLease) renew(index uint64, start time.Time) {
+	if l.duration <= 0 {
+		return
+	}
+
+	l.Lock()
+	defer l.Unlock()
+	if !start.After(l.fence) {
+		return
+	}
+	expireAt := start.Add(l.duration)
+	if expireAt.After(l.expireAt) {
+		l.expireAt = expireAt
+	}
+	if index > l.index {
+		l.index = index
+	}
+	atomic.StoreUint32(&l.served, 0)
+}
+
+// expire invalidates the lease, it must be called whenever the leadership is
+// about to change.
+func (l *readLease) expire() {
+	l.Lock()
+	defer l.Unlock()
+	l.expireAt = time.Time{}
+	l.index = 0
+	l.fence = time.Now()
+}
+
+func (l *readLease) setAppliedIndex(index uint64) {
+	l.Lock()
+	defer l.Unlock()
+	l.appliedIndex = index
+}
+
+// valid returns true if local reads can be served at the specified time.
+func (l *readLease) valid(now time.Time) bool {
+	l.RLock()
+	defer l.RUnlock()
+	return l.index > 0 &&
+		l.appliedIndex >= l.index &&
+		now.Before(l.expireAt)
+}
+
+// markServed records a local read served by the lease.
+func (l *readLease) markServed() {
+	if atomic.LoadUint32(&l.served) == 0 {
+		atomic.StoreUint32(&l.served, 1)
+	}
+}
+
+// needRenew returns true if the lease serving local reads expires within the
+// margin, the leader renews it with a ReadIndex in advance so the hot reads
+// don't fall back to the ReadIndex once the lease expired.
+func (l *readLease) needRenew(now time.Time, margin time.Duration) bool {
+	if atomic.LoadUint32(&l.served) == 0 {
+		return false
+	}
+
+	l.RLock()
+	defer l.RUnlock()
+	return l.index > 0 &&
+		l.expireAt.Sub(now) < margin
+}
@@ -0,0 +1,93 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package raftstore
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadLeaseValid(t *testing.T) {
+	now := time.Now()
+	l := newReadLease(time.Second)
+	assert.False(t, l.valid(now))
+
+	l.renew(10, now)
+	assert.False(t, l.valid(now), "read index not applied")
+
+	l.setAppliedIndex(10)
+	assert.True(t, l.valid(now))
+	assert.True(t, l.valid(now.Add(time.Millisecond*999)))
+	assert.False(t, l.valid(now.Add(time.Second)))
+
+	l.expire()
+	assert.False(t, l.valid(now))
+}
+
+func TestReadLeaseRenew(t *testing.T) {
+	now := time.Now()
+	l := newReadLease(time.Second)
+	l.setAppliedIndex(10)
+	l.renew(10, now)
+	// an older ReadIndex confirmation can't move the lease backward
+	l.renew(5, now.Add(-time.Second))
+	assert.Equal(t, uint64(10), l.index)
+	assert.Equal(t, now.Add(time.Second), l.expireAt)
+}
+
+func TestReadLeaseDisabled(t *testing.T) {
+	now := time.Now()
+	l := newReadLease(0)
+	l.setAppliedIndex(10)
+	l.renew(10, now)
+	assert.False(t, l.valid(now))
+}
+
+func TestReadLeaseRenewAfterExpire(t *testing.T) {
+	start := time.Now()
+	l := newReadLease(time.Second)
+	l.setAppliedIndex(10)
+	l.expire()
+	// ReadIndex issued before the lease was expired, e.g. before a leader
+	// transfer started, can't renew the lease
+	l.renew(10, start)
+	assert.False(t, l.valid(time.Now()))
+
+	l.renew(10, time.Now())
+	assert.True(t, l.valid(time.Now()))
+}
+
+func TestReadLeaseNeedRenew(t *testing.T) {
+	now := time.Now()
+	l := newReadLease(time.Second)
+	l.setAppliedIndex(10)
+	assert.False(t, l.needRenew(now, time.Second*2), "no lease")
+
+	l.renew(10, now)
+	assert.False(t, l.needRenew(now.Add(time.Millisecond*900), time.Millisecond*200), "not served")
+
+	l.markServed()
+	assert.False(t, l.needRenew(now, time.Millisecond*200))
+	assert.True(t, l.needRenew(now.Add(time.Millisecond*900), time.Millisecond*200))
+
+	// the renewal resets the served flag
+	l.renew(10, now.Add(time.Millisecond*900))
+	assert.False(t, l.needRenew(now.Add(time.Millisecond*1800), time.Millisecond*200))
+
+	l.markServed()
+	l.expire()
+	assert.False(t, l.needRenew(now, time.Millisecond*200))
+}
@@ -0,0 +1,344 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package raftstore
+
+import (
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/matrixorigin/matrixcube/components/log"
+	"github.com/matrixorigin/matrixcube/pb/metapb"
+	"github.com/matrixorigin/matrixcube/pb/rpcpb"
+)
+
+// The merge of 2 adjacent shards works as follows:
+// 1. The prophet asks the leader of the source shard to merge into the target
+//    shard, the leader proposes the AdminPrepareMerge request.
+// 2. Once the AdminPrepareMerge is applied, the source shard is frozen, no more
+//    logs except the config changes are applied and its range is removed from
+//    the local routes.
+// 3. The frozen source shard notifies the local target replica, the leader of
+//    the target shard proposes the AdminMergeShard request to take over the
+//    range of the source shard. The data of the source shard is shared by the
+//    local replicas of the target shard in the same data storage, so no data is
+//    moved.
+// 4. Once the AdminMergeShard is applied, the source replicas are destroyed
+//    without removing data as the split does.
+//
+// The source shard keeps notifying the target until the merge is completed.
+// The splits and the config changes of both shards are rejected while the merge
+// is pending, and the frozen source shard skips the config changes. If the epoch
+// of the target shard is still changed by the requests proposed before, the
+// merge can never be applied, the leader of the source shard proposes the
+// AdminRollbackMerge request to unfreeze the source shard. The merge not
+// completed before its deadline, e.g. the AdminPrepareMerge proposal timed out
+// but applied later, or the target has no leader on the stores of the source,
+// is aborted by the AdminAbortMerge request of the target shard, which changes
+// the epoch of the target shard so the source shard rolls back the merge.
+
+var (
+	errMergePending = errors.New("merge pending")
+)
+
+// applyPrepareMerge removes the range of the frozen shard from the local routes
+// and notifies the target shard to take over the range.
+func (pr *replica) applyPrepareMerge() {
+	target, index := pr.sm.getMergeTarget()
+	pr.logger.Info("prepare merge applied, current shard is frozen",
+		zap.Uint64("target", target),
+		log.IndexField(index))
+
+	pr.store.removeShardKeyRange(pr.getShard())
+	if pr.aware != nil {
+		pr.aware.Updated(pr.getShard())
+	}
+	pr.notifyMergeTarget()
+}
+
+// applyRollbackMerge restores the range of the shard unfrozen in the local
+// routes.
+func (pr *replica) applyRollbackMerge() {
+	shard := pr.getShard()
+	pr.logger.Info("rollback merge applied, current shard is unfrozen",
+		log.ShardField("shard", shard))
+
+	pr.setMergeDeadline(time.Time{})
+
+	pr.store.updateShardKeyRange(shard.Group, shard)
+	if pr.isLeader() {
+		pr.prophetHeartbeat()
+	}
+	if pr.aware != nil {
+		pr.aware.Updated(shard)
+	}
+}
+
+// applyAbortMerge updates the local routes with the epoch of the target shard
+// fenced against the merge.
+func (pr *replica) applyAbortMerge() {
+	shard := pr.getShard()
+	pr.logger.Info("abort merge applied",
+		log.ShardField("shard", shard))
+
+	pr.store.updateShardKeyRange(shard.Group, shard)
+	if pr.isLeader() {
+		pr.prophetHeartbeat()
+	}
+	if pr.aware != nil {
+		pr.aware.Updated(shard)
+	}
+}
+
+// applyMerge updates the local routes with the merged range and destroys the
+// local replica of the source shard.
+func (pr *replica) applyMerge(result mergeResult) {
+	shard := pr.getShard()
+	pr.logger.Info("merge shard applied",
+		log.ShardField("source", result.source),
+		log.ShardField("new-shard", shard))
+
+	pr.store.updateShardKeyRange(shard.Group, shard)
+	if source := pr.store.getReplica(result.source.ID, false); source != nil {
+		source.startDestroyReplicaTaskAfterMerged(result.sourceIndex)
+	}
+	// the approximate size and keys of the source shard are unknown here, they
+	// are reconciled by the next split check.
+	pr.stats.reconciledAt = time.Time{}
+	if pr.isLeader() {
+		pr.prophetHeartbeat()
+	}
+	if pr.aware != nil {
+		pr.aware.Updated(shard)
+	}
+}
+
+// notifyMergeTarget notifies the local target replica to propose the merge if
+// the shard is frozen to be merged, or starts the destroy task if the target
+// has already taken over the range of the shard.
+func (pr *replica) notifyMergeTarget() {
+	target, index := pr.sm.getMergeTarget()
+	if target == 0 {
+		return
+	}
+
+	tr := pr.store.getReplica(target, false)
+	if tr == nil {
+		pr.logger.Debug("skip notify merge target",
+			zap.Uint64("target", target),
+			log.ReasonField("target replica not found"))
+		return
+	}
+
+	shard := pr.getShard()
+	current := tr.getShard()
+	if isShardRangeCovered(current, shard) {
+		pr.startDestroyReplicaTaskAfterMerged(index)
+		return
+	}
+	epoch := pr.sm.getMergeTargetEpoch()
+	if isEpochNewer(current.Epoch, epoch) {
+		pr.maybeRollbackMerge(target, current.Epoch)
+		return
+	}
+	if pr.isMergeExpired(time.Now()) {
+		tr.addAction(action{
+			actionType:  abortMergeAction,
+			mergeSource: shard,
+			epoch:       epoch,
+		})
+		return
+	}
+	tr.addAction(action{
+		actionType:  mergeAction,
+		mergeSource: shard,
+		targetIndex: index,
+		epoch:       epoch,
+	})
+}
+
+// maybeRollbackMerge proposes the rollback of the merge on the leader once the
+// local target replica has applied the changes of its epoch without taking over
+// the range of the shard, the merge shard request can never be applied as the
+// target shard applies the logs in the same order on all the replicas.
+func (pr *replica) maybeRollbackMerge(target uint64, targetEpoch Epoch) {
+	if !pr.isLeader() || pr.hasDestroyReplicaTask() ||
+		pr.getShard().State == metapb.ShardState_Destroying {
+		return
+	}
+
+	pr.logger.Info("send rollback merge request",
+		zap.Uint64("target", target),
+		log.EpochField("target-epoch", targetEpoch),
+		log.EpochField("expected-target-epoch", pr.sm.getMergeTargetEpoch()))
+	pr.addAdminRequest(rpcpb.AdminRollbackMerge, &rpcpb.RollbackMergeRequest{
+		Target: target,
+	})
+}
+
+// setMergeDeadline sets the deadline of the merge prepared by the proposal of
+// the AdminPrepareMerge, zero deadline resets it.
+func (pr *replica) setMergeDeadline(deadline time.Time) {
+	v := int64(0)
+	if !deadline.IsZero() {
+		v = deadline.UnixNano()
+	}
+	atomic.StoreInt64(&pr.mergeDeadline, v)
+}
+
+// isMergeExpired returns true if the merge of the frozen shard is not completed
+// before its deadline. The merge not prepared by the local proposal, e.g. on the
+// followers or after the leader changed, must be completed within the admin
+// proposal timeout since the first check.
+func (pr *replica) isMergeExpired(now time.Time) bool {
+	timeout := pr.cfg.Raft.AdminProposalTimeout.Duration
+	if timeout <= 0 {
+		return false
+	}
+	deadline := atomic.LoadInt64(&pr.mergeDeadline)
+	if deadline == 0 {
+		atomic.CompareAndSwapInt64(&pr.mergeDeadline, 0, now.Add(timeout).UnixNano())
+		return false
+	}
+	return now.UnixNano() > deadline
+}
+
+// doAbortMerge proposes the AdminAbortMerge request on the leader of the target
+// shard to fence it against the merge of the frozen source shard not completed
+// before the deadline.
+func (pr *replica) doAbortMerge(act action) {
+	if !pr.isLeader() {
+		return
+	}
+
+	current := pr.getShard()
+	if isShardRangeCovered(current, act.mergeSource) ||
+		isEpochNewer(current.Epoch, act.epoch) {
+		return
+	}
+
+	pr.logger.Info("send abort merge request",
+		log.ShardField("source", act.mergeSource),
+		log.EpochField("epoch", act.epoch))
+	pr.addAdminRequest(rpcpb.AdminAbortMerge, &rpcpb.AbortMergeRequest{
+		Source:      act.mergeSource,
+		TargetEpoch: act.epoch,
+	})
+}
+
+// checkMergeProposal rejects the splits and the config changes of the shard
+// frozen to be merged or being the target of a frozen local shard, they change
+// the shards so the merge can never be applied.
+func (pr *replica) checkMergeProposal(c batch) bool {
+	if !c.requestBatch.IsAdmin() {
+		return true
+	}
+	switch c.requestBatch.GetAdminCmdType() {
+	case rpcpb.AdminBatchSplit, rpcpb.AdminSplitShard, rpcpb.AdminConfigChange,
+		rpcpb.AdminConfigChangeV2, rpcpb.AdminBecomeWitness:
+	default:
+		return true
+	}
+
+	if target, _ := pr.sm.getMergeTarget(); target > 0 || pr.isMergeTarget() {
+		pr.logger.Info("proposal rejected",
+			zap.String("type", c.requestBatch.GetAdminCmdType().String()),
+			log.ReasonField("merge pending"))
+		c.respOtherError(errMergePending)
+		return false
+	}
+	return true
+}
+
+// isMergeTarget returns true if a local replica is frozen to be merged into the
+// shard.
+func (pr *replica) isMergeTarget() bool {
+	found := false
+	pr.store.forEachReplica(func(r *replica) bool {
+		if target, _ := r.sm.getMergeTarget(); target == pr.shardID {
+			found = true
+		}
+		return !found
+	})
+	return found
+}
+
+// isEpochNewer returns true if the epoch is newer than the base epoch.
+func isEpochNewer(epoch, base Epoch) bool {
+	return epoch.Generation > base.Generation || epoch.ConfigVer > base.ConfigVer
+}
+
+// startDestroyReplicaTaskAfterMerged starts a task to destroy the replica of
+// the frozen shard once its range is taken over by the target shard.
+func (pr *replica) startDestroyReplicaTaskAfterMerged(prepareMergeLogIndex uint64) {
+	if pr.hasDestroyReplicaTask() {
+		return
+	}
+	pr.startDestroyReplicaTask(prepareMergeLogIndex, false, "merged")
+}
+
+// isMergeSourceApplied returns true if the local replica of the source shard
+// has applied the log at the index, e.g. the prepare merge log.
+func (pr *replica) isMergeSourceApplied(source uint64, index uint64) bool {
+	sr := pr.store.getReplica(source, false)
+	if sr == nil {
+		pr.logger.Debug("merge source replica not found",
+			zap.Uint64("source", source))
+		return false
+	}
+	applied, _ := sr.sm.getAppliedIndexTerm()
+	return applied >= index
+}
+
+func (pr *replica) hasDestroyReplicaTask() bool {
+	pr.destroyTaskMu.Lock()
+	defer pr.destroyTaskMu.Unlock()
+	return pr.destroyTaskMu.hasTask
+}
+
+func (pr *replica) doMerge(act action) {
+	if !pr.isLeader() {
+		return
+	}
+
+	current := pr.getShard()
+	if isShardRangeCovered(current, act.mergeSource) {
+		return
+	}
+	if target, _ := pr.sm.getMergeTarget(); target > 0 ||
+		current.State == metapb.ShardState_Destroying {
+		pr.logger.Info("skip merge shard",
+			log.ShardField("source", act.mergeSource),
+			log.ReasonField("current shard is frozen or destroying"))
+		return
+	}
+	req := &rpcpb.MergeShardRequest{
+		Source:      act.mergeSource,
+		SourceIndex: act.targetIndex,
+		TargetEpoch: act.epoch,
+	}
+	if err := checkMergeTarget(*req, current); err != nil {
+		pr.logger.Error("failed to merge shard",
+			log.ShardField("source", act.mergeSource),
+			zap.Error(err))
+		return
+	}
+
+	pr.logger.Info("send merge shard request",
+		log.ShardField("source", act.mergeSource),
+		zap.Uint64("source-index", act.targetIndex))
+	pr.addAdminRequest(rpcpb.AdminMergeShard, req)
+}
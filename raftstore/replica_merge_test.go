@@ -0,0 +1,138 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package raftstore
+
+import (
+	"testing"
+	"time"
+
+	"github.com/fagongzi/util/protoc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/matrixorigin/matrixcube/pb/rpcpb"
+	"github.com/matrixorigin/matrixcube/util/leaktest"
+)
+
+func TestCheckMergeProposal(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	s, cancel := newTestStore(t)
+	defer cancel()
+
+	source := newTestReplica(Shard{ID: 1, Start: []byte{1}, End: []byte{5}}, Replica{ID: 2}, s)
+	target := newTestReplica(Shard{ID: 3, Start: []byte{5}, End: []byte{10}}, Replica{ID: 4}, s)
+	s.addReplica(source)
+	s.addReplica(target)
+
+	var responses []rpcpb.ResponseBatch
+	cb := func(resp rpcpb.ResponseBatch) {
+		responses = append(responses, resp)
+	}
+	split := newBatch(nil, newTestAdminRequestBatch("a1", 0, rpcpb.AdminBatchSplit, nil), cb, 0, 0)
+	compact := newBatch(nil, newTestAdminRequestBatch("a2", 0, rpcpb.AdminCompactShard, nil), cb, 0, 0)
+	assert.True(t, source.checkMergeProposal(split))
+	assert.True(t, target.checkMergeProposal(split))
+
+	// both shards reject the splits once the source is frozen
+	source.sm.setMergeTarget(3, 10, Epoch{Generation: 2})
+	assert.False(t, source.checkMergeProposal(split))
+	assert.False(t, target.checkMergeProposal(split))
+	assert.True(t, target.checkMergeProposal(compact))
+	require.Equal(t, 2, len(responses))
+	assert.Equal(t, errMergePending.Error(), responses[0].Header.Error.Message)
+}
+
+func TestRollbackMergeOnTargetEpochChanged(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	s, cancel := newTestStore(t)
+	defer cancel()
+
+	source := newTestReplica(Shard{ID: 1, Start: []byte{1}, End: []byte{5}}, Replica{ID: 2}, s)
+	target := newTestReplica(Shard{ID: 3, Epoch: Epoch{Generation: 2}, Start: []byte{5}, End: []byte{10}},
+		Replica{ID: 4}, s)
+	s.addReplica(source)
+	s.addReplica(target)
+	source.leaderID = 2
+	source.sm.setMergeTarget(3, 10, Epoch{Generation: 2})
+
+	// the target is notified to merge with the epoch recorded by the prepare merge
+	source.notifyMergeTarget()
+	assert.Equal(t, int64(0), source.requests.Len())
+	v, err := target.actions.Peek()
+	require.NoError(t, err)
+	assert.Equal(t, mergeAction, v.(action).actionType)
+	assert.Equal(t, Epoch{Generation: 2}, v.(action).epoch)
+
+	// the target split before the merge, the merge can never be applied
+	target.sm.updateShard(Shard{ID: 3, Epoch: Epoch{Generation: 3}, Start: []byte{5}, End: []byte{8}})
+	source.notifyMergeTarget()
+	v, err = source.requests.Peek()
+	require.NoError(t, err)
+	req := &rpcpb.RollbackMergeRequest{}
+	protoc.MustUnmarshal(req, v.(reqCtx).req.Cmd)
+	assert.Equal(t, uint64(3), req.Target)
+	assert.Equal(t, uint64(rpcpb.AdminRollbackMerge), v.(reqCtx).req.CustomType)
+}
+
+func TestAbortMergeOnDeadline(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	s, cancel := newTestStore(t)
+	defer cancel()
+
+	source := newTestReplica(Shard{ID: 1, Start: []byte{1}, End: []byte{5}}, Replica{ID: 2}, s)
+	target := newTestReplica(Shard{ID: 3, Epoch: Epoch{Generation: 2}, Start: []byte{5}, End: []byte{10}},
+		Replica{ID: 4}, s)
+	s.addReplica(source)
+	s.addReplica(target)
+	source.cfg.Raft.AdminProposalTimeout.Duration = time.Minute
+	source.sm.setMergeTarget(3, 10, Epoch{Generation: 2})
+
+	// the deadline of the merge not prepared by the local proposal is set by the
+	// first check
+	now := time.Now()
+	assert.False(t, source.isMergeExpired(now))
+	assert.False(t, source.isMergeExpired(now.Add(time.Second)))
+	assert.True(t, source.isMergeExpired(now.Add(time.Minute+time.Second)))
+
+	// the merge prepared by the expired proposal is aborted
+	source.setMergeDeadline(now.Add(-time.Second))
+	source.notifyMergeTarget()
+	v, err := target.actions.Peek()
+	require.NoError(t, err)
+	assert.Equal(t, abortMergeAction, v.(action).actionType)
+	assert.Equal(t, uint64(1), v.(action).mergeSource.ID)
+	assert.Equal(t, Epoch{Generation: 2}, v.(action).epoch)
+
+	// the target leader fences itself against the merge
+	target.leaderID = 4
+	target.doAbortMerge(v.(action))
+	v, err = target.requests.Peek()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(rpcpb.AdminAbortMerge), v.(reqCtx).req.CustomType)
+	req := &rpcpb.AbortMergeRequest{}
+	protoc.MustUnmarshal(req, v.(reqCtx).req.Cmd)
+	assert.Equal(t, uint64(1), req.Source.ID)
+	assert.Equal(t, Epoch{Generation: 2}, req.TargetEpoch)
+
+	// the source rolls back the merge once the target is fenced
+	target.sm.updateShard(Shard{ID: 3, Epoch: Epoch{Generation: 3}, Start: []byte{5}, End: []byte{10}})
+	source.leaderID = 2
+	source.notifyMergeTarget()
+	v, err = source.requests.Peek()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(rpcpb.AdminRollbackMerge), v.(reqCtx).req.CustomType)
+}
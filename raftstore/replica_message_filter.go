@@ -0,0 +1,133 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package raftstore
+
+import (
+	"go.etcd.io/etcd/raft/v3/raftpb"
+)
+
+// messageDedupKey identifies an idempotent raft message, the duplicated
+// messages received in the same batch are stepped only once.
+type messageDedupKey struct {
+	msgType    raftpb.MessageType
+	from       uint64
+	term       uint64
+	logTerm    uint64
+	index      uint64
+	commit     uint64
+	reject     bool
+	rejectHint uint64
+	entries    int
+}
+
+// messageFilter drops the raft messages known to be useless before stepping
+// the raft, i.e. the messages with a stale term ignored by the raft and the
+// duplicated messages in a batch.
+type messageFilter struct {
+	seen    map[messageDedupKey]struct{}
+	dropped map[uint64]*DroppedMessageDiagnostic
+}
+
+func newMessageFilter() *messageFilter {
+	return &messageFilter{
+		seen:    make(map[messageDedupKey]struct{}),
+		dropped: make(map[uint64]*DroppedMessageDiagnostic),
+	}
+}
+
+// reset resets the dedup state, it's called before each batch of messages.
+func (f *messageFilter) reset() {
+	for k := range f.seen {
+		delete(f.seen, k)
+	}
+}
+
+// filter returns true if the message should be dropped, term is the current
+// term of the raft.
+func (f *messageFilter) filter(msg raftpb.Message, term uint64, metrics *raftMessageMetrics) bool {
+	if isStaleTermMessage(msg, term) {
+		f.stats(msg.From).StaleTerm++
+		metrics.droppedStaleTerm++
+		return true
+	}
+
+	if !isIdempotentMessage(msg) {
+		return false
+	}
+	key := messageDedupKey{
+		msgType:    msg.Type,
+		from:       msg.From,
+		term:       msg.Term,
+		logTerm:    msg.LogTerm,
+		index:      msg.Index,
+		commit:     msg.Commit,
+		reject:     msg.Reject,
+		rejectHint: msg.RejectHint,
+		entries:    len(msg.Entries),
+	}
+	if _, ok := f.seen[key]; ok {
+		f.stats(msg.From).Duplicate++
+		metrics.droppedDuplicate++
+		return true
+	}
+	f.seen[key] = struct{}{}
+	return false
+}
+
+func (f *messageFilter) stats(from uint64) *DroppedMessageDiagnostic {
+	s, ok := f.dropped[from]
+	if !ok {
+		s = &DroppedMessageDiagnostic{}
+		f.dropped[from] = s
+	}
+	return s
+}
+
+// droppedStats returns a copy of the dropped messages stats, replica id -> stats.
+func (f *messageFilter) droppedStats() map[uint64]DroppedMessageDiagnostic {
+	if len(f.dropped) == 0 {
+		return nil
+	}
+	stats := make(map[uint64]DroppedMessageDiagnostic, len(f.dropped))
+	for id, s := range f.dropped {
+		stats[id] = *s
+	}
+	return stats
+}
+
+// isStaleTermMessage returns true if the message is ignored by the raft as its
+// term is lower than the current term. The MsgApp, MsgHeartbeat and MsgPreVote
+// with a lower term are responded by the raft to notify the sender the newer
+// term, so they are never dropped.
+func isStaleTermMessage(msg raftpb.Message, term uint64) bool {
+	if msg.Term == 0 || msg.Term >= term {
+		return false
+	}
+	switch msg.Type {
+	case raftpb.MsgApp, raftpb.MsgHeartbeat, raftpb.MsgPreVote:
+		return false
+	}
+	return true
+}
+
+// isIdempotentMessage returns true if stepping the message again is a no-op.
+// The heartbeats with the context carry the ReadIndex requests, they are never
+// deduplicated.
+func isIdempotentMessage(msg raftpb.Message) bool {
+	switch msg.Type {
+	case raftpb.MsgApp, raftpb.MsgAppResp, raftpb.MsgHeartbeat, raftpb.MsgHeartbeatResp:
+		return len(msg.Context) == 0
+	}
+	return false
+}
@@ -0,0 +1,78 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package raftstore
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.etcd.io/etcd/raft/v3/raftpb"
+)
+
+func TestMessageFilterStaleTerm(t *testing.T) {
+	cases := []struct {
+		msg    raftpb.Message
+		expect bool
+	}{
+		{msg: raftpb.Message{Type: raftpb.MsgVote, Term: 1}, expect: true},
+		{msg: raftpb.Message{Type: raftpb.MsgAppResp, Term: 1}, expect: true},
+		{msg: raftpb.Message{Type: raftpb.MsgVote, Term: 2}, expect: false},
+		{msg: raftpb.Message{Type: raftpb.MsgApp, Term: 1}, expect: false},
+		{msg: raftpb.Message{Type: raftpb.MsgHeartbeat, Term: 1}, expect: false},
+		{msg: raftpb.Message{Type: raftpb.MsgPreVote, Term: 1}, expect: false},
+		{msg: raftpb.Message{Type: raftpb.MsgReadIndex}, expect: false},
+	}
+
+	for i, c := range cases {
+		f := newMessageFilter()
+		metrics := raftMessageMetrics{}
+		c.msg.From = 1
+		assert.Equal(t, c.expect, f.filter(c.msg, 2, &metrics), "index %d", i)
+		if c.expect {
+			assert.Equal(t, uint64(1), metrics.droppedStaleTerm, "index %d", i)
+			assert.Equal(t, map[uint64]DroppedMessageDiagnostic{1: {StaleTerm: 1}}, f.droppedStats(), "index %d", i)
+		} else {
+			assert.Empty(t, f.droppedStats(), "index %d", i)
+		}
+	}
+}
+
+func TestMessageFilterDuplicate(t *testing.T) {
+	f := newMessageFilter()
+	metrics := raftMessageMetrics{}
+
+	hb := raftpb.Message{Type: raftpb.MsgHeartbeat, From: 1, Term: 2, Commit: 10}
+	assert.False(t, f.filter(hb, 2, &metrics))
+	assert.True(t, f.filter(hb, 2, &metrics))
+	hb.Commit = 11
+	assert.False(t, f.filter(hb, 2, &metrics))
+
+	// the heartbeats carrying the read index context are never dropped
+	hb.Context = []byte("ctx")
+	assert.False(t, f.filter(hb, 2, &metrics))
+	assert.False(t, f.filter(hb, 2, &metrics))
+
+	// the votes are not idempotent
+	vote := raftpb.Message{Type: raftpb.MsgVote, From: 2, Term: 2}
+	assert.False(t, f.filter(vote, 2, &metrics))
+	assert.False(t, f.filter(vote, 2, &metrics))
+
+	assert.Equal(t, uint64(1), metrics.droppedDuplicate)
+	assert.Equal(t, map[uint64]DroppedMessageDiagnostic{1: {Duplicate: 1}}, f.droppedStats())
+
+	// the duplicates are checked in each batch
+	f.reset()
+	hb.Context = nil
+	assert.False(t, f.filter(hb, 2, &metrics))
+}
@@ -0,0 +1,122 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package raftstore
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	maxPendingResolvedPairs = 16
+)
+
+// resolvedPair means all writes acknowledged before ts (unix milliseconds) are
+// at or below index.
+type resolvedPair struct {
+	index uint64
+	ts    uint64
+}
+
+// resolvedTS tracks the resolved timestamp of a replica. The leader establishes
+// a resolved pair each time a ReadIndex round trip completes, the ts is the
+// time when the ReadIndex was issued as the leadership is confirmed at that
+// time. The pairs are propagated to the followers within raft messages, the
+// resolved timestamp of a replica advances once it has applied the index of
+// a pair, after that reads at the resolved timestamp can be served locally.
+//
+// resolvedTS is accessed from both the event worker and request goroutines.
+type resolvedTS struct {
+	sync.RWMutex
+	// latest is the latest known pair, the leader sends it to the followers
+	latest resolvedPair
+	// pending pairs are ordered by both index and ts
+	pending      []resolvedPair
+	resolved     uint64
+	appliedIndex uint64
+}
+
+func newResolvedTS() *resolvedTS {
+	return &resolvedTS{}
+}
+
+// track records a resolved pair established by the leader, stale pairs are
+// ignored.
+func (r *resolvedTS) track(index, ts uint64) {
+	r.Lock()
+	defer r.Unlock()
+	if ts <= r.latest.ts || index < r.latest.index {
+		return
+	}
+
+	pair := resolvedPair{index: index, ts: ts}
+	r.latest = pair
+	if index <= r.appliedIndex {
+		r.resolved = ts
+		r.pending = r.pending[:0]
+		return
+	}
+	// replacing the last pending pair with a newer one only delays the
+	// advance of the resolved timestamp
+	if n := len(r.pending); n == maxPendingResolvedPairs {
+		r.pending[n-1] = pair
+		return
+	}
+	r.pending = append(r.pending, pair)
+}
+
+func (r *resolvedTS) setAppliedIndex(index uint64) {
+	r.Lock()
+	defer r.Unlock()
+	r.appliedIndex = index
+	n := 0
+	for _, pair := range r.pending {
+		if pair.index > index {
+			break
+		}
+		r.resolved = pair.ts
+		n++
+	}
+	if n > 0 {
+		r.pending = append(r.pending[:0], r.pending[n:]...)
+	}
+}
+
+// get returns the resolved timestamp in unix milliseconds.
+func (r *resolvedTS) get() uint64 {
+	r.RLock()
+	defer r.RUnlock()
+	return r.resolved
+}
+
+func (r *resolvedTS) getLatest() resolvedPair {
+	r.RLock()
+	defer r.RUnlock()
+	return r.latest
+}
+
+// withinStaleness returns true if the reads with the max staleness in
+// milliseconds can be served locally at the specified time.
+func (r *resolvedTS) withinStaleness(now time.Time, maxStaleness uint64) bool {
+	resolved := r.get()
+	if resolved == 0 {
+		return false
+	}
+	ms := uint64(unixMilli(now))
+	return ms <= resolved || ms-resolved <= maxStaleness
+}
+
+func unixMilli(t time.Time) int64 {
+	return t.UnixNano() / int64(time.Millisecond)
+}
@@ -0,0 +1,71 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package raftstore
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolvedTSTrack(t *testing.T) {
+	r := newResolvedTS()
+	r.track(10, 100)
+	assert.Equal(t, uint64(0), r.get())
+	assert.Equal(t, resolvedPair{index: 10, ts: 100}, r.getLatest())
+
+	// stale pairs are ignored
+	r.track(9, 200)
+	r.track(11, 100)
+	assert.Equal(t, resolvedPair{index: 10, ts: 100}, r.getLatest())
+
+	r.track(20, 200)
+	r.setAppliedIndex(15)
+	assert.Equal(t, uint64(100), r.get())
+	r.setAppliedIndex(20)
+	assert.Equal(t, uint64(200), r.get())
+
+	// applied pairs advance the resolved timestamp immediately
+	r.track(20, 300)
+	assert.Equal(t, uint64(300), r.get())
+	assert.Empty(t, r.pending)
+}
+
+func TestResolvedTSTrackWithFullPending(t *testing.T) {
+	r := newResolvedTS()
+	for i := uint64(1); i <= maxPendingResolvedPairs+2; i++ {
+		r.track(i, i)
+	}
+	assert.Equal(t, maxPendingResolvedPairs, len(r.pending))
+	assert.Equal(t, resolvedPair{index: maxPendingResolvedPairs + 2, ts: maxPendingResolvedPairs + 2},
+		r.pending[maxPendingResolvedPairs-1])
+
+	r.setAppliedIndex(maxPendingResolvedPairs + 2)
+	assert.Equal(t, uint64(maxPendingResolvedPairs+2), r.get())
+	assert.Empty(t, r.pending)
+}
+
+func TestResolvedTSWithinStaleness(t *testing.T) {
+	now := time.Now()
+	r := newResolvedTS()
+	assert.False(t, r.withinStaleness(now, 1000))
+
+	r.setAppliedIndex(1)
+	r.track(1, uint64(unixMilli(now))-500)
+	assert.True(t, r.withinStaleness(now, 1000))
+	assert.True(t, r.withinStaleness(now, 500))
+	assert.False(t, r.withinStaleness(now, 100))
+	assert.True(t, r.withinStaleness(now.Add(-time.Second), 100))
+}
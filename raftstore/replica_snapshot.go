@@ -14,6 +14,9 @@
 package raftstore
 
 import (
+	"sync/atomic"
+	"time"
+
 	"github.com/cockroachdb/errors"
 	"github.com/fagongzi/util/protoc"
 	"go.etcd.io/etcd/raft/v3"
@@ -21,6 +24,7 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/matrixorigin/matrixcube/components/log"
+	"github.com/matrixorigin/matrixcube/metric"
 	"github.com/matrixorigin/matrixcube/pb/metapb"
 	"github.com/matrixorigin/matrixcube/storage"
 )
@@ -32,6 +36,11 @@ func (pr *replica) handleRaftCreateSnapshotRequest() error {
 	pr.logger.Info("requested to create snapshot")
 	ss, created, err := pr.createSnapshot()
 	if err != nil {
+		if errors.Is(err, errWitnessSnapshot) {
+			pr.logger.Warn("skip creating snapshot",
+				log.ReasonField("witness"))
+			return nil
+		}
 		return err
 	}
 	if created {
@@ -42,6 +51,12 @@ func (pr *replica) handleRaftCreateSnapshotRequest() error {
 }
 
 func (pr *replica) createSnapshot() (raftpb.Snapshot, bool, error) {
+	// the witness has no shard data, its snapshot would wipe the data of the
+	// replica applying it
+	if pr.isWitness() {
+		return raftpb.Snapshot{}, false, errWitnessSnapshot
+	}
+	start := time.Now()
 	index, term := pr.sm.getAppliedIndexTerm()
 	if index == 0 {
 		panic("invalid snapshot index")
@@ -90,27 +105,138 @@ func (pr *replica) createSnapshot() (raftpb.Snapshot, bool, error) {
 			zap.Error(err))
 		return raftpb.Snapshot{}, false, err
 	}
+	metric.ObserveSnapshotBuildingDuration(start)
 	logger.Info("snapshot created")
 	return ss, true, nil
 }
 
+// createDeltaSnapshot creates a delta snapshot for the replica with the data
+// changed since the applied index it reported, so it's sent instead of the full
+// snapshot. False is returned if the data storage can not create it.
+func (pr *replica) createDeltaSnapshot(to uint64) (raftpb.Snapshot, bool) {
+	ds, ok := pr.sm.dataStorage.(storage.DeltaSnapshotStorage)
+	if !ok {
+		return raftpb.Snapshot{}, false
+	}
+	base := pr.appliedIndexes[to]
+	index, term := pr.sm.getAppliedIndexTerm()
+	if base == 0 || base >= index {
+		return raftpb.Snapshot{}, false
+	}
+	start := time.Now()
+	logger := pr.logger.With(
+		log.ReplicaIDField(to),
+		zap.Uint64("snapshot-index", index),
+		zap.Uint64("base-index", base))
+
+	ss, ssenv, err := pr.snapshotter.saveDelta(ds, base, pr.sm.getConfState(), index, term)
+	if err != nil {
+		ssenv.MustRemoveTempDir()
+		if errors.Is(err, storage.ErrDeltaSnapshotUnavailable) {
+			logger.Debug("delta snapshot unavailable")
+		} else {
+			logger.Error("failed to save delta snapshot",
+				zap.Error(err))
+		}
+		return raftpb.Snapshot{}, false
+	}
+	if err := pr.snapshotter.commit(ss, ssenv); err != nil {
+		ssenv.MustRemoveTempDir()
+		logger.Error("failed to commit saved delta snapshot",
+			zap.Error(err))
+		return raftpb.Snapshot{}, false
+	}
+	metric.ObserveSnapshotBuildingDuration(start)
+	logger.Info("delta snapshot created")
+	return ss, true
+}
+
+// isTransientSnapshot returns true if the snapshot is only created to be sent
+// to another replica, i.e. the delta and the witness snapshots. It's never
+// recorded in the logdb, so its image is removed once sent.
+func isTransientSnapshot(ss raftpb.Snapshot) bool {
+	if len(ss.Data) == 0 {
+		return false
+	}
+	var si metapb.SnapshotInfo
+	protoc.MustUnmarshal(&si, ss.Data)
+	return si.DeltaBase > 0 || si.Witness
+}
+
+// canApplySnapshot returns false if the snapshot is a delta snapshot whose base
+// index is not applied by the replica yet. The snapshot is dropped in this case,
+// the leader will send another one with the applied index reported later.
+func (pr *replica) canApplySnapshot(ss raftpb.Snapshot) bool {
+	if len(ss.Data) == 0 {
+		return true
+	}
+	var si metapb.SnapshotInfo
+	protoc.MustUnmarshal(&si, ss.Data)
+	if si.DeltaBase <= pr.appliedIndex {
+		return true
+	}
+	pr.logger.Warn("delta snapshot dropped, base index not applied",
+		log.SnapshotField(ss),
+		zap.Uint64("base-index", si.DeltaBase),
+		log.IndexField(pr.appliedIndex))
+	if err := pr.removeSnapshot(ss, false); err != nil {
+		pr.logger.Error("failed to remove dropped snapshot",
+			zap.Error(err))
+	}
+	return false
+}
+
+// recovering returns whether the replica is recovering from a snapshot and the
+// estimated time left. The estimation is based on the time taken by the last
+// recovery, or the time elapsed if it is the first recovery or it has taken
+// longer.
+func (pr *replica) recovering(now time.Time) (bool, time.Duration) {
+	at := atomic.LoadInt64(&pr.recoveringAt)
+	if at == 0 {
+		return false, 0
+	}
+	elapsed := time.Duration(now.UnixNano() - at)
+	if cost := time.Duration(atomic.LoadInt64(&pr.lastRecoverCost)); cost > elapsed {
+		return true, cost - elapsed
+	}
+	return true, elapsed
+}
+
+func (pr *replica) startRecovering() {
+	atomic.StoreInt64(&pr.recoveringAt, time.Now().UnixNano())
+}
+
+func (pr *replica) endRecovering() {
+	at := atomic.SwapInt64(&pr.recoveringAt, 0)
+	atomic.StoreInt64(&pr.lastRecoverCost, time.Now().UnixNano()-at)
+}
+
 func (pr *replica) applySnapshot(ss raftpb.Snapshot) error {
+	pr.startRecovering()
+	defer pr.endRecovering()
+
 	logger := pr.logger.With(log.SnapshotField(ss))
 	// double check whether we are trying to recover from a dummy snapshot
+	var si metapb.SnapshotInfo
 	if len(ss.Data) > 0 {
-		var si metapb.SnapshotInfo
 		protoc.MustUnmarshal(&si, ss.Data)
 		if si.Dummy {
 			logger.Fatal("trying to recover from a dummy snapshot")
 		}
 	}
-	md, err := pr.snapshotter.recover(pr.sm.dataStorage, ss)
+	var md metapb.ShardMetadata
+	var err error
+	if si.Witness {
+		md, err = pr.snapshotter.recoverWitness(pr.sm.dataStorage, ss)
+	} else {
+		md, err = pr.snapshotter.recover(pr.sm.dataStorage, ss)
+	}
 	if err != nil {
 		logger.Error("failed to recover from the snapshot",
 			zap.Error(err))
 		return err
 	}
-	pr.appliedIndex = ss.Metadata.Index
+	pr.setAppliedIndex(ss.Metadata.Index)
 	// when applying initial snapshot, we've already applied the ss record into
 	// the LogReader beforehand, applying the ss record again here would void
 	// the lr.SetRange change.
@@ -119,10 +245,18 @@ func (pr *replica) applySnapshot(ss raftpb.Snapshot) error {
 			return err
 		}
 	}
+	pr.sm.interceptShardMetadata([]metapb.ShardMetadata{md})
 	pr.sm.updateShard(md.Metadata.Shard)
-	// after snapshot applied, the shard range may changed, so we
-	// need update key ranges
-	pr.store.updateShardKeyRange(pr.group, md.Metadata.Shard)
+	pr.sm.initLocalState(md)
+	if md.Metadata.MergeTarget > 0 {
+		// the shard was frozen to be merged, its range is taken over by the
+		// target shard
+		pr.store.removeShardKeyRange(md.Metadata.Shard)
+	} else {
+		// after snapshot applied, the shard range may changed, so we
+		// need update key ranges
+		pr.store.updateShardKeyRange(pr.group, md.Metadata.Shard)
+	}
 	// r.replica is more like a local cached copy of the replica record.
 	pr.replica = *findReplica(pr.getShard(), pr.storeID)
 	pr.sm.updateAppliedIndexTerm(ss.Metadata.Index, ss.Metadata.Term)
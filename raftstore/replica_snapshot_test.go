@@ -16,6 +16,7 @@ package raftstore
 import (
 	"testing"
 
+	"github.com/cockroachdb/errors"
 	"github.com/fagongzi/util/protoc"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -26,6 +27,8 @@ import (
 	"github.com/matrixorigin/matrixcube/logdb"
 	"github.com/matrixorigin/matrixcube/pb/metapb"
 	"github.com/matrixorigin/matrixcube/snapshot"
+	"github.com/matrixorigin/matrixcube/storage"
+	"github.com/matrixorigin/matrixcube/storage/executor/simple"
 	"github.com/matrixorigin/matrixcube/storage/kv"
 	"github.com/matrixorigin/matrixcube/storage/kv/mem"
 	"github.com/matrixorigin/matrixcube/util/fileutil"
@@ -74,15 +77,18 @@ func runReplicaSnapshotTest(t *testing.T,
 		store: &store{
 			workerPool: newWorkerPool(logger, ldb, nil, 96),
 		},
-		actions:     task.New(32),
-		storeID:     100,
-		logger:      logger,
-		logdb:       ldb,
-		sm:          sm,
-		snapshotter: snapshotter,
-		shardID:     1,
-		replica:     replicaRec,
-		lr:          lr,
+		actions:         task.New(32),
+		groupController: newReplicaGroupController(),
+		storeID:         100,
+		logger:          logger,
+		logdb:           ldb,
+		sm:              sm,
+		snapshotter:     snapshotter,
+		shardID:         1,
+		replica:         replicaRec,
+		lr:              lr,
+		lease:           newReadLease(0),
+		resolvedTS:      newResolvedTS(),
 	}
 	r.setStarted()
 	fn(t, r, fs)
@@ -117,6 +123,98 @@ func TestReplicaSnapshotCanBeCreated(t *testing.T) {
 	runReplicaSnapshotTest(t, fn, fs)
 }
 
+func TestWitnessCanNotCreateSnapshot(t *testing.T) {
+	fn := func(t *testing.T, r *replica, fs vfs.FS) {
+		shard := r.getShard()
+		shard.Replicas[0].IsWitness = true
+		r.sm.updateShard(shard)
+		_, created, err := r.createSnapshot()
+		assert.True(t, errors.Is(err, errWitnessSnapshot))
+		assert.False(t, created)
+	}
+	fs := vfs.GetTestFS()
+	runReplicaSnapshotTest(t, fn, fs)
+}
+
+func TestReplicaDeltaSnapshotCanBeCreated(t *testing.T) {
+	fn := func(t *testing.T, r *replica, fs vfs.FS) {
+		r.appliedIndexes = map[uint64]uint64{2: 100}
+		// the delta snapshots are not enabled
+		_, ok := r.createDeltaSnapshot(2)
+		assert.False(t, ok)
+
+		dsMem := mem.NewStorage()
+		base := kv.NewBaseStorage(dsMem, fs)
+		ds := kv.NewKVDataStorage(base, simple.NewSimpleKVExecutor(dsMem),
+			kv.WithDeltaSnapshot(10))
+		defer ds.Close()
+		shard := r.getShard()
+		assert.NoError(t, ds.SaveShardMetadata([]metapb.ShardMetadata{
+			{ShardID: 1, LogIndex: 100, Metadata: metapb.ShardLocalState{Shard: shard}},
+		}))
+		batch := storage.Batch{Index: 101,
+			Requests: []storage.Request{simple.NewWriteRequest([]byte("k1"), []byte("v1"))}}
+		assert.NoError(t, ds.Write(storage.NewSimpleWriteContext(1, dsMem, batch)))
+		r.sm = newStateMachine(r.logger, ds, r.logdb, shard, r.replica, nil, nil)
+		r.sm.updateAppliedIndexTerm(101, 1)
+
+		ss, ok := r.createDeltaSnapshot(2)
+		require.True(t, ok)
+		assert.Equal(t, uint64(101), ss.Metadata.Index)
+		var si metapb.SnapshotInfo
+		protoc.MustUnmarshal(&si, ss.Data)
+		assert.Equal(t, uint64(100), si.DeltaBase)
+		env := r.snapshotter.getRecoverSnapshotEnv(ss)
+		exist, err := fileutil.Exist(fs.PathJoin(env.GetFinalDir(), "db.delta"), fs)
+		assert.NoError(t, err)
+		assert.True(t, exist)
+
+		// the applied index of the replica is unknown or too old
+		_, ok = r.createDeltaSnapshot(3)
+		assert.False(t, ok)
+		r.appliedIndexes[2] = 99
+		_, ok = r.createDeltaSnapshot(2)
+		assert.False(t, ok)
+	}
+	fs := vfs.GetTestFS()
+	runReplicaSnapshotTest(t, fn, fs)
+}
+
+func TestCanApplySnapshot(t *testing.T) {
+	fn := func(t *testing.T, r *replica, fs vfs.FS) {
+		r.appliedIndex = 50
+		assert.True(t, r.canApplySnapshot(raftpb.Snapshot{}))
+		newSnapshot := func(base uint64) raftpb.Snapshot {
+			return raftpb.Snapshot{
+				Data:     protoc.MustMarshal(&metapb.SnapshotInfo{Extra: 1, DeltaBase: base}),
+				Metadata: raftpb.SnapshotMetadata{Index: 100},
+			}
+		}
+		assert.True(t, r.canApplySnapshot(newSnapshot(0)))
+		assert.True(t, r.canApplySnapshot(newSnapshot(50)))
+
+		ss := newSnapshot(60)
+		env := r.snapshotter.getRecoverSnapshotEnv(ss)
+		require.NoError(t, fs.MkdirAll(env.GetFinalDir(), 0755))
+		assert.False(t, r.canApplySnapshot(ss))
+		exist, err := fileutil.Exist(env.GetFinalDir(), fs)
+		assert.NoError(t, err)
+		assert.False(t, exist)
+	}
+	fs := vfs.GetTestFS()
+	runReplicaSnapshotTest(t, fn, fs)
+}
+
+func TestIsTransientSnapshot(t *testing.T) {
+	newSnapshot := func(si metapb.SnapshotInfo) raftpb.Snapshot {
+		return raftpb.Snapshot{Data: protoc.MustMarshal(&si)}
+	}
+	assert.False(t, isTransientSnapshot(raftpb.Snapshot{}))
+	assert.False(t, isTransientSnapshot(newSnapshot(metapb.SnapshotInfo{Extra: 1})))
+	assert.True(t, isTransientSnapshot(newSnapshot(metapb.SnapshotInfo{Extra: 1, DeltaBase: 10})))
+	assert.True(t, isTransientSnapshot(newSnapshot(metapb.SnapshotInfo{Extra: 1, Witness: true})))
+}
+
 // other related tests
 // TestApplyInitialSnapshot
 // TestApplyReceivedSnapshot
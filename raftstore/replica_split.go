@@ -14,9 +14,14 @@
 package raftstore
 
 import (
+	"bytes"
+	"fmt"
+	"time"
+
 	trackerPkg "go.etcd.io/etcd/raft/v3/tracker"
 	"go.uber.org/zap"
 
+	"github.com/fagongzi/util/protoc"
 	"github.com/matrixorigin/matrixcube/components/log"
 	"github.com/matrixorigin/matrixcube/pb/rpcpb"
 )
@@ -26,7 +31,7 @@ func (pr *replica) tryCheckSplit(act action) bool {
 		return false
 	}
 
-	if !pr.needDoCheckSplit() {
+	if !pr.needDoCheckSplit() && !pr.needReconcileStats() {
 		return false
 	}
 
@@ -46,7 +51,7 @@ func (pr *replica) tryCheckSplit(act action) bool {
 			log.ReasonField("missing callback"))
 	}
 
-	act.actionCallback(pr.getShard())
+	act.actionCallback(splitCheckTask{shard: pr.getShard(), size: pr.stats.approximateSize})
 	return true
 }
 
@@ -64,8 +69,21 @@ func (pr *replica) needDoCheckSplit() bool {
 	return pr.stats.approximateSize >= uint64(pr.feature.ShardSplitCheckBytes)
 }
 
+// needReconcileStats returns true if the approximate size and keys maintained
+// at apply time need to be reconciled with the storage, the split check scans
+// the shard and returns the real values.
+func (pr *replica) needReconcileStats() bool {
+	d := pr.feature.ShardStatsReconcileDuration
+	return d > 0 && time.Since(pr.stats.reconciledAt) >= d
+}
+
 func (pr *replica) doSplit(act action) {
+	requested := act.splitCheckData.requested
 	if !pr.isLeader() {
+		if requested != nil {
+			leader, _ := pr.store.getReplicaRecord(pr.getLeaderReplicaID())
+			requested.respNotLeader(pr.shardID, leader)
+		}
 		return
 	}
 
@@ -75,15 +93,13 @@ func (pr *replica) doSplit(act action) {
 		pr.logger.Info("epoch changed, need re-check later",
 			log.EpochField("current-epoch", current.Epoch),
 			log.EpochField("check-epoch", epoch))
+		if requested != nil {
+			requested.resp(errorStaleEpochResp(requested.getRequestID(), current))
+		}
 		return
 	}
 
-	if act.splitCheckData.size > 0 {
-		pr.stats.approximateSize = act.splitCheckData.size
-	}
-	if act.splitCheckData.keys > 0 {
-		pr.stats.approximateKeys = act.splitCheckData.keys
-	}
+	pr.stats.reconcile(act.splitCheckData.size, act.splitCheckData.keys)
 	if len(act.splitCheckData.splitKeys) == 0 {
 		return
 	}
@@ -92,6 +108,17 @@ func (pr *replica) doSplit(act action) {
 		pr.logger.Fatal("missing splitIDs")
 	}
 
+	// the allocated splitIDs are abandoned, the split is checked again later
+	if deadline := act.splitCheckData.deadline; !deadline.IsZero() &&
+		time.Now().After(deadline) {
+		pr.logger.Warn("split expired before proposing, need re-check later",
+			zap.Time("deadline", deadline))
+		if requested != nil {
+			requested.respAdminTimeout(pr.shardID)
+		}
+		return
+	}
+
 	if len(act.splitCheckData.splitIDs) != len(act.splitCheckData.splitKeys)+1 {
 		pr.logger.Fatal("invalid splitIDs len",
 			zap.Int("expect", len(act.splitCheckData.splitKeys)+1),
@@ -118,6 +145,7 @@ func (pr *replica) doSplit(act action) {
 				ID:            act.splitCheckData.splitIDs[idx].NewReplicaIDs[idIdx],
 				StoreID:       r.StoreID,
 				InitialMember: true,
+				IsWitness:     r.IsWitness,
 			})
 		}
 
@@ -130,5 +158,87 @@ func (pr *replica) doSplit(act action) {
 		start = end
 	}
 
-	pr.addAdminRequest(rpcpb.AdminBatchSplit, &req)
+	if requested == nil {
+		pr.addAdminRequestWithDeadline(rpcpb.AdminBatchSplit, &req,
+			act.splitCheckData.deadline)
+		return
+	}
+
+	// the AdminBatchSplit is proposed as the request of the client, so the
+	// client gets the BatchSplitResponse once the split applied.
+	r := requested.requestBatch.GetAdminRequest()
+	r.CustomType = uint64(rpcpb.AdminBatchSplit)
+	r.Epoch = current.Epoch
+	r.Cmd = protoc.MustMarshal(&req)
+	ctx := newReqCtx(r, requested.cb)
+	ctx.deadline = act.splitCheckData.deadline
+	if err := pr.addRequest(ctx); err != nil {
+		requested.respOtherError(err)
+	}
+}
+
+// splitShard splits the shard at the explicit split keys of the AdminSplitShard
+// request. The IDs of the new shards are allocated by prophet in the background,
+// then the split is proposed by the split action as the AdminBatchSplit.
+func (pr *replica) splitShard(c batch) {
+	var req rpcpb.SplitShardRequest
+	if err := req.Unmarshal(c.requestBatch.GetAdminRequest().Cmd); err != nil {
+		c.respOtherError(err)
+		return
+	}
+	shard := pr.getShard()
+	if err := checkSplitKeys(shard, req.Keys); err != nil {
+		c.respOtherError(err)
+		return
+	}
+	if pr.feature.HashRouting {
+		if err := checkPartitionSplitKeys(req.Keys); err != nil {
+			c.respOtherError(err)
+			return
+		}
+	}
+
+	pr.logger.Info("split shard requested",
+		zap.ByteStrings("split-keys", req.Keys))
+	pr.store.stopper.RunWorker(func() {
+		splitIDs, err := pr.prophetClient.AskBatchSplit(shard, uint32(len(req.Keys)+1))
+		if err != nil {
+			pr.logger.Error("fail to ask batch split",
+				zap.Error(err))
+			c.respOtherError(err)
+			return
+		}
+
+		pr.addAction(action{
+			epoch:      shard.Epoch,
+			actionType: splitAction,
+			splitCheckData: splitCheckData{
+				splitKeys: req.Keys,
+				splitIDs:  splitIDs,
+				deadline:  c.deadline,
+				requested: &c,
+			},
+		})
+	})
+}
+
+// checkSplitKeys returns error if the split keys are not in the range of the
+// shard in ascending order, or any key equals to the start key of the shard.
+func checkSplitKeys(shard Shard, splitKeys [][]byte) error {
+	if len(splitKeys) == 0 {
+		return fmt.Errorf("missing split keys")
+	}
+
+	for idx, key := range splitKeys {
+		if err := checkKeyInShard(key, shard); err != nil {
+			return fmt.Errorf("split key %+v not in shard %d", key, shard.ID)
+		}
+		if bytes.Equal(key, shard.Start) {
+			return fmt.Errorf("split key %+v equals to the start of shard %d", key, shard.ID)
+		}
+		if idx > 0 && bytes.Compare(key, splitKeys[idx-1]) <= 0 {
+			return fmt.Errorf("split keys not in ascending order")
+		}
+	}
+	return nil
 }
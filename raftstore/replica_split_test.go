@@ -15,6 +15,7 @@ package raftstore
 
 import (
 	"testing"
+	"time"
 
 	"github.com/fagongzi/util/protoc"
 	"github.com/matrixorigin/matrixcube/components/log"
@@ -46,7 +47,16 @@ func TestTryCheckSplit(t *testing.T) {
 	pr.feature.ShardSplitCheckBytes = 99
 	pr.rn, _ = raft.NewRawNode(getRaftConfig(pr.replicaID, 0, pr.lr, &pr.cfg, log.Adjust(nil)))
 	assert.True(t, pr.tryCheckSplit(action{actionType: checkSplitAction, actionCallback: func(v interface{}) {
-		assert.Equal(t, pr.getShard(), v)
+		assert.Equal(t, splitCheckTask{shard: pr.getShard(), size: 100}, v)
+	}}))
+
+	// check stats reconcile
+	pr.feature.ShardSplitCheckBytes = 200
+	pr.feature.ShardStatsReconcileDuration = time.Minute
+	assert.False(t, pr.tryCheckSplit(action{actionType: checkSplitAction}))
+	pr.stats.reconciledAt = time.Now().Add(-time.Minute)
+	assert.True(t, pr.tryCheckSplit(action{actionType: checkSplitAction, actionCallback: func(v interface{}) {
+		assert.Equal(t, splitCheckTask{shard: pr.getShard(), size: 100}, v)
 	}}))
 }
 
@@ -77,9 +87,11 @@ func TestDoSplit(t *testing.T) {
 
 	// check no split keys, only change memory fields
 	act.epoch = pr.getShard().Epoch
+	reconciledAt := pr.stats.reconciledAt
 	pr.doSplit(act)
 	assert.Equal(t, pr.stats.approximateSize, act.splitCheckData.size)
 	assert.Equal(t, pr.stats.approximateKeys, act.splitCheckData.keys)
+	assert.True(t, pr.stats.reconciledAt.After(reconciledAt))
 
 	// check split panic, len(splitIDs) == len(splitKeys)+1
 	ch := make(chan bool)
@@ -115,3 +127,57 @@ func TestDoSplit(t *testing.T) {
 	assert.Equal(t, pr.getShard().End, req.Requests[1].End)
 	assert.Equal(t, act.splitCheckData.splitIDs[1].NewID, req.Requests[1].NewShardID)
 }
+
+func TestDoSplitRequested(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	s, cancel := newTestStore(t)
+	defer cancel()
+
+	pr := newTestReplica(Shard{ID: 1, Epoch: Epoch{Generation: 2}}, Replica{ID: 2}, s)
+	pr.leaderID = 1
+
+	var responses []rpcpb.ResponseBatch
+	requested := newBatch(nil, newTestAdminRequestBatch("r1", 0, rpcpb.AdminSplitShard,
+		protoc.MustMarshal(&rpcpb.SplitShardRequest{Keys: [][]byte{{1}}})),
+		func(resp rpcpb.ResponseBatch) {
+			responses = append(responses, resp)
+		}, 0, 0)
+	act := action{actionType: splitAction, epoch: pr.getShard().Epoch}
+	act.splitCheckData.splitIDs = []rpcpb.SplitID{{NewID: 100, NewReplicaIDs: []uint64{1000}}, {NewID: 200, NewReplicaIDs: []uint64{2000}}}
+	act.splitCheckData.splitKeys = [][]byte{{1}}
+	act.splitCheckData.requested = &requested
+
+	// check not leader
+	pr.doSplit(act)
+	assert.Equal(t, 1, len(responses))
+	assert.NotNil(t, responses[0].Header.Error.NotLeader)
+	assert.Equal(t, int64(0), pr.requests.Len())
+
+	// check split proposed as the request of the client
+	pr.leaderID = 2
+	pr.doSplit(act)
+	assert.Equal(t, int64(1), pr.requests.Len())
+	v, err := pr.requests.Peek()
+	assert.NoError(t, err)
+	ctx := v.(reqCtx)
+	assert.Equal(t, []byte("r1"), ctx.req.ID)
+	assert.Equal(t, rpcpb.AdminBatchSplit, rpcpb.AdminCmdType(ctx.req.CustomType))
+	assert.Equal(t, pr.getShard().Epoch, ctx.req.Epoch)
+	var req rpcpb.BatchSplitRequest
+	protoc.MustUnmarshal(&req, ctx.req.Cmd)
+	assert.Equal(t, 2, len(req.Requests))
+	ctx.cb(rpcpb.ResponseBatch{})
+	assert.Equal(t, 2, len(responses))
+}
+
+func TestCheckSplitKeys(t *testing.T) {
+	shard := Shard{ID: 1, Start: []byte("b"), End: []byte("f")}
+	assert.Error(t, checkSplitKeys(shard, nil))
+	assert.Error(t, checkSplitKeys(shard, [][]byte{[]byte("a")}))
+	assert.Error(t, checkSplitKeys(shard, [][]byte{[]byte("f")}))
+	assert.Error(t, checkSplitKeys(shard, [][]byte{[]byte("b")}))
+	assert.Error(t, checkSplitKeys(shard, [][]byte{[]byte("d"), []byte("c")}))
+	assert.Error(t, checkSplitKeys(shard, [][]byte{[]byte("c"), []byte("c")}))
+	assert.NoError(t, checkSplitKeys(shard, [][]byte{[]byte("c"), []byte("d")}))
+}
@@ -22,6 +22,7 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/matrixorigin/matrixcube/components/log"
+	"github.com/matrixorigin/matrixcube/config"
 	"github.com/matrixorigin/matrixcube/logdb"
 	"github.com/matrixorigin/matrixcube/metric"
 	"github.com/matrixorigin/matrixcube/pb/errorpb"
@@ -74,21 +75,36 @@ func (ctx *applyContext) initialize(entry raftpb.Entry) {
 type replicaResultHandler interface {
 	handleApplyResult(applyResult)
 	notifyPendingProposal(id []byte, resp rpcpb.ResponseBatch, isConfChange bool)
+	// isMergeSourceApplied returns true if the local replica of the merge source
+	// shard has applied the log at the index.
+	isMergeSourceApplied(source uint64, index uint64) bool
 }
 
 var _ replicaResultHandler = (*replica)(nil)
 
 type stateMachine struct {
-	logger                *zap.Logger
-	shardID               uint64
-	replica               Replica
-	applyCtx              *applyContext
-	writeCtx              *writeContext
-	dataStorage           storage.DataStorage
+	logger      *zap.Logger
+	shardID     uint64
+	replica     Replica
+	applyCtx    *applyContext
+	writeCtx    *writeContext
+	dataStorage storage.DataStorage
+	// dataStorageMu protects the dataStorage read by the read requests from
+	// being changed by moving the shard to another data storage.
+	dataStorageMu         sync.RWMutex
 	logdb                 logdb.LogDB
 	wc                    *logdb.WorkerContext
 	replicaCreatorFactory replicaCreatorFactory
 	resultHandler         replicaResultHandler
+	// customAdminHandlers the handlers of the custom admin commands
+	customAdminHandlers map[rpcpb.AdminCmdType]config.CustomAdminCmdHandler
+	// metadataInterceptor intercepts the persisted shard metadata
+	metadataInterceptor config.ShardMetadataInterceptor
+	// applyErrors is the recent apply errors kept for diagnostics, it is only
+	// accessed in the raft worker thread.
+	applyErrors []ApplyErrorDiagnostic
+	// stageMetrics observes the apply stage of the proposals
+	stageMetrics *metric.ProposalStageObservers
 
 	metadataMu struct {
 		sync.Mutex
@@ -99,6 +115,20 @@ type stateMachine struct {
 		term    uint64
 		// TODO: maybe should move to replica struct
 		firstIndex uint64
+		// mergeTarget the target shard the shard is being merged into and
+		// mergeIndex the index of the prepare merge log, the shard applies no
+		// more logs except the config changes once it is frozen to be merged.
+		mergeTarget uint64
+		mergeIndex  uint64
+		// mergeTargetEpoch the epoch of the target shard when the prepare merge
+		// is proposed
+		mergeTargetEpoch Epoch
+		// fenceIndex the index of the barrier entry fencing the shard, the writes
+		// are rejected once the shard is fenced.
+		fenceIndex uint64
+		// migrated the shard is fenced as its group is migrated to another
+		// cluster, all the requests are rejected.
+		migrated bool
 	}
 }
 
@@ -138,31 +168,57 @@ func (d *stateMachine) getShard() Shard {
 func (d *stateMachine) getConfState() raftpb.ConfState {
 	d.metadataMu.Lock()
 	defer d.metadataMu.Unlock()
+	return getConfState(d.metadataMu.shard)
+}
+
+// getConfState returns the raft ConfState of the replicas of the shard. In the
+// joint state, the incoming voters are voters of the incoming config and the
+// demoting voters are voters of the outgoing config, which become learners once
+// the joint state is left.
+func getConfState(shard Shard) raftpb.ConfState {
 	cs := raftpb.ConfState{}
-	for _, r := range d.metadataMu.shard.Replicas {
-		if r.Role == metapb.ReplicaRole_Voter {
+	joint := false
+	for _, r := range shard.Replicas {
+		switch r.Role {
+		case metapb.ReplicaRole_Voter:
 			cs.Voters = append(cs.Voters, r.ID)
-		} else if r.Role == metapb.ReplicaRole_Learner {
+			cs.VotersOutgoing = append(cs.VotersOutgoing, r.ID)
+		case metapb.ReplicaRole_Learner:
 			cs.Learners = append(cs.Learners, r.ID)
-		} else {
+		case metapb.ReplicaRole_IncomingVoter:
+			joint = true
+			cs.Voters = append(cs.Voters, r.ID)
+		case metapb.ReplicaRole_DemotingVoter:
+			joint = true
+			cs.VotersOutgoing = append(cs.VotersOutgoing, r.ID)
+			cs.LearnersNext = append(cs.LearnersNext, r.ID)
+		default:
 			panic("unknown replica role")
 		}
 	}
+	if !joint {
+		cs.VotersOutgoing = nil
+	}
 	return cs
 }
 
-func (d *stateMachine) applyCommittedEntries(entries []raftpb.Entry) {
+// applyCommittedEntries applies the committed entries in order and returns the
+// entries not applied yet. The apply pauses at the merge shard entry until the
+// local replica of the source shard has applied the prepare merge, the paused
+// entries must be applied again later.
+func (d *stateMachine) applyCommittedEntries(entries []raftpb.Entry) []raftpb.Entry {
 	if len(entries) <= 0 {
-		return
+		return nil
 	}
 
 	d.logger.Debug("apply committed logs",
 		zap.Int("count", len(entries)))
 	start := time.Now()
+	defer metric.ObserveRaftLogApplyDuration(start)
 	// FIXME: the initial idea is to batch multiple entries into the same
 	// executeContext so they can be applied into the stateMachine together.
 	// in the loop below, we are still applying entries one by one.
-	for _, entry := range entries {
+	for i, entry := range entries {
 		d.applyCtx.initialize(entry)
 		d.checkEntryIndexTerm(entry)
 		// notify all clients that current shard has been removed or splitted
@@ -186,6 +242,11 @@ func (d *stateMachine) applyCommittedEntries(entries []raftpb.Entry) {
 			})
 			continue
 		}
+		if d.waitMergeSource(d.applyCtx) {
+			d.logger.Info("apply paused to wait for the merge source",
+				log.IndexField(entry.Index))
+			return entries[i:]
+		}
 
 		ignoreMetrics := d.applyRequestBatch(d.applyCtx)
 		result := applyResult{
@@ -208,7 +269,28 @@ func (d *stateMachine) applyCommittedEntries(entries []raftpb.Entry) {
 		d.updateAppliedIndexTerm(entry.Index, entry.Term)
 		d.resultHandler.handleApplyResult(result)
 	}
-	metric.ObserveRaftLogApplyDuration(start)
+	return nil
+}
+
+// waitMergeSource returns true if the entry is the merge shard request to be
+// applied but the local replica of the source shard has not applied the prepare
+// merge yet. The target takes over the data of the source shard in the same data
+// storage, the writes of the source shard not applied yet would be lost once the
+// source replica is destroyed after the merge.
+func (d *stateMachine) waitMergeSource(ctx *applyContext) bool {
+	if !ctx.req.IsAdmin() ||
+		ctx.req.GetAdminCmdType() != rpcpb.AdminMergeShard ||
+		!d.checkEpoch(ctx.req) {
+		return false
+	}
+
+	req := ctx.req.GetMergeShardRequest()
+	current := d.getShard()
+	if isShardRangeCovered(current, req.Source) ||
+		checkMergeTarget(req, current) != nil {
+		return false
+	}
+	return !d.resultHandler.isMergeSourceApplied(req.Source.ID, req.SourceIndex)
 }
 
 func (d *stateMachine) checkEntryIndexTerm(entry raftpb.Entry) {
@@ -246,6 +328,7 @@ func (d *stateMachine) applyRequestBatch(ctx *applyContext) bool {
 	if d.isRemoved() {
 		d.logger.Fatal("applying entries on removed replica")
 	}
+	start := time.Now()
 	var err error
 	var resp rpcpb.ResponseBatch
 	ignoreMetrics := true
@@ -256,6 +339,7 @@ func (d *stateMachine) applyRequestBatch(ctx *applyContext) bool {
 				log.EpochField("current-epoch", d.getShard().Epoch),
 				log.IndexField(ctx.index))
 		}
+		d.addApplyError(ctx.index, errStaleEpoch)
 		resp = errorStaleEpochResp(ctx.req.Header.ID, d.getShard())
 	} else {
 		if ce := d.logger.Check(zap.DebugLevel, "begin to apply committed log"); ce != nil {
@@ -270,14 +354,32 @@ func (d *stateMachine) applyRequestBatch(ctx *applyContext) bool {
 			}
 			resp, err = d.execAdminRequest(ctx)
 			if err != nil {
+				d.addApplyError(ctx.index, err)
 				resp = errorStaleEpochResp(ctx.req.Header.ID, d.getShard())
 			}
 		} else {
 			if ce := d.logger.Check(zap.DebugLevel, "apply write requests"); ce != nil {
 				ce.Write(log.IndexField(ctx.index))
 			}
-			ignoreMetrics = false
-			resp = d.execWriteRequest(ctx)
+			if d.isMigrated() {
+				// the clients switch to the destination cluster of the group
+				d.addApplyError(ctx.index, errShardFenced)
+				resp = errorPbResp(ctx.req.Header.ID, errorpb.Error{
+					Message:       NewGroupMigratedErr(d.getShard().Group).Error(),
+					GroupMigrated: &errorpb.GroupMigrated{Group: d.getShard().Group},
+				})
+			} else if d.getFenceIndex() > 0 {
+				// the writes after the fence are rejected, the clients retry
+				// once the shard is unfenced
+				d.addApplyError(ctx.index, errShardFenced)
+				resp = errorPbResp(ctx.req.Header.ID, errorpb.Error{
+					Message:          errShardFenced.Error(),
+					ShardUnavailable: &errorpb.ShardUnavailable{ShardID: d.shardID},
+				})
+			} else {
+				ignoreMetrics = false
+				resp = d.execWriteRequest(ctx)
+			}
 		}
 
 		if ce := d.logger.Check(zap.DebugLevel, "apply committed log completed"); ce != nil {
@@ -286,6 +388,7 @@ func (d *stateMachine) applyRequestBatch(ctx *applyContext) bool {
 		}
 	}
 
+	d.stageMetrics.Observe(metric.ProposalStageApply, start)
 	// TODO: this implies that we can't have more than one batch in the
 	// executeContext
 	d.resultHandler.notifyPendingProposal(ctx.req.Header.ID,
@@ -293,10 +396,44 @@ func (d *stateMachine) applyRequestBatch(ctx *applyContext) bool {
 	return ignoreMetrics
 }
 
+func (d *stateMachine) addApplyError(index uint64, err error) {
+	if len(d.applyErrors) == maxDiagnosticApplyErrors {
+		copy(d.applyErrors, d.applyErrors[1:])
+		d.applyErrors = d.applyErrors[:len(d.applyErrors)-1]
+	}
+	d.applyErrors = append(d.applyErrors, ApplyErrorDiagnostic{
+		Index: index,
+		Time:  time.Now(),
+		Error: err.Error(),
+	})
+}
+
 func (d *stateMachine) close() {
 	d.writeCtx.close()
 }
 
+// setDataStorage changes the data storage of the shard, the read requests in
+// progress are finished before changed.
+func (d *stateMachine) setDataStorage(ds storage.DataStorage) {
+	d.dataStorageMu.Lock()
+	defer d.dataStorageMu.Unlock()
+	d.writeCtx.close()
+	d.writeCtx = newWriteContext(ds)
+	d.dataStorage = ds
+}
+
+func (d *stateMachine) getDataStorage() storage.DataStorage {
+	d.dataStorageMu.RLock()
+	defer d.dataStorageMu.RUnlock()
+	return d.dataStorage
+}
+
+func (d *stateMachine) readDataStorage(ctx storage.ReadContext) ([]byte, error) {
+	d.dataStorageMu.RLock()
+	defer d.dataStorageMu.RUnlock()
+	return d.dataStorage.Read(ctx)
+}
+
 func (d *stateMachine) setRemoved() {
 	d.metadataMu.Lock()
 	defer d.metadataMu.Unlock()
@@ -315,6 +452,69 @@ func (d *stateMachine) setSplited() {
 	d.metadataMu.splited = true
 }
 
+func (d *stateMachine) setMergeTarget(target uint64, index uint64, epoch Epoch) {
+	d.metadataMu.Lock()
+	defer d.metadataMu.Unlock()
+	d.metadataMu.mergeTarget = target
+	d.metadataMu.mergeIndex = index
+	d.metadataMu.mergeTargetEpoch = epoch
+}
+
+// getMergeTarget returns the target shard the shard is being merged into and
+// the index of the prepare merge log, the target is 0 if the shard is not
+// frozen to be merged.
+func (d *stateMachine) getMergeTarget() (uint64, uint64) {
+	d.metadataMu.Lock()
+	defer d.metadataMu.Unlock()
+	return d.metadataMu.mergeTarget, d.metadataMu.mergeIndex
+}
+
+// getMergeTargetEpoch returns the epoch of the merge target shard when the
+// prepare merge is proposed.
+func (d *stateMachine) getMergeTargetEpoch() Epoch {
+	d.metadataMu.Lock()
+	defer d.metadataMu.Unlock()
+	return d.metadataMu.mergeTargetEpoch
+}
+
+func (d *stateMachine) setFence(index uint64, migrated bool) {
+	d.metadataMu.Lock()
+	defer d.metadataMu.Unlock()
+	d.metadataMu.fenceIndex = index
+	d.metadataMu.migrated = migrated
+}
+
+// getFenceIndex returns the index of the barrier entry fencing the shard, 0
+// means the shard is not fenced.
+func (d *stateMachine) getFenceIndex() uint64 {
+	d.metadataMu.Lock()
+	defer d.metadataMu.Unlock()
+	return d.metadataMu.fenceIndex
+}
+
+// isMigrated returns true if the shard is fenced as its group is migrated to
+// another cluster.
+func (d *stateMachine) isMigrated() bool {
+	d.metadataMu.Lock()
+	defer d.metadataMu.Unlock()
+	return d.metadataMu.migrated
+}
+
+// initLocalState restores the local states of the shard which are not part of
+// the shard metadata, e.g. after restart or applying a snapshot.
+func (d *stateMachine) initLocalState(md metapb.ShardMetadata) {
+	d.metadataMu.Lock()
+	defer d.metadataMu.Unlock()
+	d.metadataMu.mergeTarget = md.Metadata.MergeTarget
+	d.metadataMu.mergeTargetEpoch = md.Metadata.MergeTargetEpoch
+	d.metadataMu.mergeIndex = 0
+	if md.Metadata.MergeTarget > 0 {
+		d.metadataMu.mergeIndex = md.LogIndex
+	}
+	d.metadataMu.fenceIndex = md.Metadata.FenceIndex
+	d.metadataMu.migrated = md.Metadata.Migrated
+}
+
 func (d *stateMachine) canApply(entry raftpb.Entry) bool {
 	d.metadataMu.Lock()
 	defer d.metadataMu.Unlock()
@@ -327,10 +527,19 @@ func (d *stateMachine) canApply(entry raftpb.Entry) bool {
 	// so we need check shard state.
 	if !d.metadataMu.removed &&
 		!d.metadataMu.splited &&
+		d.metadataMu.mergeTarget == 0 &&
 		d.metadataMu.shard.State != metapb.ShardState_Destroying {
 		return true
 	}
 
+	// The frozen shard only applies the rollback of the merge, the config
+	// changes are skipped as the replicas of the source shard must stay on the
+	// same stores as the target shard.
+	if d.metadataMu.mergeTarget > 0 &&
+		d.metadataMu.shard.State != metapb.ShardState_Destroying {
+		return isRollbackMergeEntry(entry)
+	}
+
 	// In some scenarios, we need to remove the replica that is not online,
 	// so that the deletion task can be completed.
 	return isConfigChangeEntry(entry) &&
@@ -379,7 +588,17 @@ func isConfigChangeEntry(entry raftpb.Entry) bool {
 		entry.Type == raftpb.EntryConfChangeV2
 }
 
+func isRollbackMergeEntry(entry raftpb.Entry) bool {
+	if entry.Type != raftpb.EntryNormal || len(entry.Data) == 0 {
+		return false
+	}
+	req := rpcpb.RequestBatch{}
+	protoc.MustUnmarshal(&req, entry.Data)
+	return req.IsAdmin() && req.GetAdminCmdType() == rpcpb.AdminRollbackMerge
+}
+
 func isConfigChangeRequestBatch(req rpcpb.RequestBatch) bool {
 	return req.IsAdmin() &&
-		req.GetAdminCmdType() == rpcpb.AdminConfigChange
+		(req.GetAdminCmdType() == rpcpb.AdminConfigChange ||
+			req.GetAdminCmdType() == rpcpb.AdminConfigChangeV2)
 }
@@ -15,12 +15,15 @@ package raftstore
 
 import (
 	"bytes"
-	"math"
+	"fmt"
+	"path/filepath"
 	"sort"
+	"time"
 
 	"github.com/cockroachdb/errors"
 	"github.com/fagongzi/util/protoc"
 	"github.com/matrixorigin/matrixcube/components/log"
+	"github.com/matrixorigin/matrixcube/config"
 	"github.com/matrixorigin/matrixcube/pb/metapb"
 	"github.com/matrixorigin/matrixcube/pb/rpcpb"
 	"github.com/matrixorigin/matrixcube/storage"
@@ -32,25 +35,236 @@ var (
 	ErrNotLearnerReplica = errors.New("not learner")
 	ErrReplicaNotFound   = errors.New("replica not found")
 	ErrReplicaDuplicated = errors.New("replica duplicated")
+	ErrNotInJointState   = errors.New("not in joint state")
+
+	errShardFenced     = errors.New("shard is fenced")
+	errBackupNotFenced = errors.New("shard must be fenced to be backed up")
+
+	errMergeShardsMismatch         = errors.New("shards can not be merged")
+	errMergeShardsNotAdjacent      = errors.New("shards to merge are not adjacent")
+	errMergeShardsReplicasMismatch = errors.New("replicas of the shards to merge are not on the same stores")
+	errMergeTargetEpochChanged     = errors.New("epoch of the merge target changed")
+
+	errReadSnapshotNotSupported = errors.New("read snapshot not supported by the data storage")
+	errEmptyReadSnapshotName    = errors.New("empty read snapshot name")
 )
 
 func (d *stateMachine) execAdminRequest(ctx *applyContext) (rpcpb.ResponseBatch, error) {
 	switch ctx.req.GetAdminCmdType() {
 	case rpcpb.AdminConfigChange:
 		return d.doExecConfigChange(ctx)
+	case rpcpb.AdminConfigChangeV2:
+		return d.doExecConfigChangeV2(ctx)
 	case rpcpb.AdminBatchSplit:
 		return d.doExecSplit(ctx)
+	case rpcpb.AdminPrepareMerge:
+		return d.doExecPrepareMerge(ctx)
+	case rpcpb.AdminMergeShard:
+		return d.doExecMergeShard(ctx)
+	case rpcpb.AdminRollbackMerge:
+		return d.doExecRollbackMerge(ctx)
+	case rpcpb.AdminAbortMerge:
+		return d.doExecAbortMerge(ctx)
 	case rpcpb.AdminUpdateMetadata:
 		return d.doUpdateMetadata(ctx)
 	case rpcpb.AdminCompactLog:
 		return d.doExecCompactLog(ctx)
 	case rpcpb.AdminUpdateLabels:
 		return d.doUpdateLabels(ctx)
+	case rpcpb.AdminUpdateDurabilityPolicy:
+		return d.doUpdateDurabilityPolicy(ctx)
+	case rpcpb.AdminCreateReadSnapshot:
+		return d.doCreateReadSnapshot(ctx), nil
+	case rpcpb.AdminReleaseReadSnapshot:
+		return d.doReleaseReadSnapshot(ctx), nil
+	case rpcpb.AdminBarrier:
+		return d.doBarrier(ctx)
+	case rpcpb.AdminBecomeWitness:
+		return d.doExecBecomeWitness(ctx)
+	case rpcpb.AdminCompactShard:
+		return d.doExecCompactShard(ctx), nil
+	case rpcpb.AdminPurgeExpiredData:
+		return d.doExecPurgeExpiredData(ctx), nil
 	}
 
+	if h, ok := d.customAdminHandlers[ctx.req.GetAdminCmdType()]; ok {
+		return d.doExecCustomAdmin(ctx, h), nil
+	}
 	return rpcpb.ResponseBatch{}, nil
 }
 
+func (d *stateMachine) doExecCustomAdmin(ctx *applyContext, h config.CustomAdminCmdHandler) rpcpb.ResponseBatch {
+	req := ctx.req.GetAdminRequest()
+	value, err := h.Apply(d.getShard(), ctx.index, req.Cmd, d.dataStorage)
+	if err != nil {
+		d.logger.Error("fail to apply custom admin request",
+			log.HexField("id", req.ID),
+			zap.Uint64("type", req.CustomType),
+			log.IndexField(ctx.index),
+			zap.Error(err))
+		resp := errorBaseResp(ctx.req.Header.ID)
+		resp.Header.Error.Message = err.Error()
+		return resp
+	}
+
+	ctx.adminResult = &adminResult{
+		adminType: ctx.req.GetAdminCmdType(),
+	}
+	return rpcpb.ResponseBatch{
+		Responses: []rpcpb.Response{{Value: value}},
+	}
+}
+
+// doCreateReadSnapshot creates the read snapshot after all the previous logs
+// applied, so the read snapshots of all the replicas hold the same data.
+func (d *stateMachine) doCreateReadSnapshot(ctx *applyContext) rpcpb.ResponseBatch {
+	req := ctx.req.GetCreateReadSnapshotRequest()
+	err := d.execReadSnapshotCmd(req.Name, func(s storage.ReadSnapshotStorage) error {
+		return s.CreateReadSnapshot(d.shardID, req.Name,
+			time.Duration(req.TTL)*time.Millisecond)
+	})
+	if err != nil {
+		return d.readSnapshotErrorResp(ctx, req.Name, err)
+	}
+
+	d.logger.Info("read snapshot created",
+		zap.String("name", req.Name),
+		log.IndexField(ctx.index))
+	ctx.adminResult = &adminResult{
+		adminType: rpcpb.AdminCreateReadSnapshot,
+	}
+	return newAdminResponseBatch(rpcpb.AdminCreateReadSnapshot,
+		&rpcpb.CreateReadSnapshotResponse{Index: ctx.index})
+}
+
+func (d *stateMachine) doReleaseReadSnapshot(ctx *applyContext) rpcpb.ResponseBatch {
+	req := ctx.req.GetReleaseReadSnapshotRequest()
+	err := d.execReadSnapshotCmd(req.Name, func(s storage.ReadSnapshotStorage) error {
+		return s.ReleaseReadSnapshot(d.shardID, req.Name)
+	})
+	if err != nil {
+		return d.readSnapshotErrorResp(ctx, req.Name, err)
+	}
+
+	d.logger.Info("read snapshot released",
+		zap.String("name", req.Name),
+		log.IndexField(ctx.index))
+	ctx.adminResult = &adminResult{
+		adminType: rpcpb.AdminReleaseReadSnapshot,
+	}
+	return newAdminResponseBatch(rpcpb.AdminReleaseReadSnapshot,
+		&rpcpb.ReleaseReadSnapshotResponse{})
+}
+
+// doBarrier responds the barrier entry with its index, all the previous logs
+// of the shard are applied when the barrier entry is applied. The backup of the
+// fenced shard is requested by the barrier entry with the backup path, it's
+// created by the replica proposed the barrier off the apply path, see
+// checkBackupProposal. No write is applied once the shard is fenced, so the
+// backup contains exactly the writes before the fence.
+func (d *stateMachine) doBarrier(ctx *applyContext) (rpcpb.ResponseBatch, error) {
+	req := ctx.req.GetBarrierRequest()
+	resp := rpcpb.BarrierResponse{Index: ctx.index, Shard: d.getShard()}
+
+	fenceIndex, migrated := d.getFenceIndex(), d.isMigrated()
+	if req.Fence {
+		fenceIndex = ctx.index
+		migrated = migrated || req.Migrated
+	} else if req.Unfence {
+		fenceIndex, migrated = 0, false
+	}
+	if fenceIndex != d.getFenceIndex() || migrated != d.isMigrated() {
+		d.setFence(fenceIndex, migrated)
+		if err := d.saveShardMetedata(ctx.index, ctx.term, d.getShard(),
+			metapb.ReplicaState_Normal); err != nil {
+			d.logger.Fatal("failed to save fence index",
+				zap.Error(err))
+		}
+	}
+
+	if req.BackupPath != "" {
+		if fenceIndex == 0 {
+			d.logger.Error("failed to apply barrier",
+				log.IndexField(ctx.index),
+				zap.Error(errBackupNotFenced))
+			resp := errorBaseResp(ctx.req.Header.ID)
+			resp.Header.Error.Message = errBackupNotFenced.Error()
+			return resp, nil
+		}
+		// the replicas of the shard may share the same file system
+		resp.BackupPath = filepath.Join(req.BackupPath,
+			fmt.Sprintf("%d-%d", d.shardID, d.replica.ID))
+	}
+
+	d.logger.Info("barrier applied",
+		log.IndexField(ctx.index),
+		zap.Uint64("fence-index", fenceIndex),
+		zap.Bool("migrated", migrated),
+		zap.String("backup", resp.BackupPath))
+	ctx.adminResult = &adminResult{
+		adminType: rpcpb.AdminBarrier,
+	}
+	return newAdminResponseBatch(rpcpb.AdminBarrier, &resp), nil
+}
+
+// doExecCompactShard only responds the index of the entry, the compaction of
+// the shard data is scheduled by each replica once applied.
+func (d *stateMachine) doExecCompactShard(ctx *applyContext) rpcpb.ResponseBatch {
+	d.logger.Info("compact shard applied",
+		log.IndexField(ctx.index))
+	ctx.adminResult = &adminResult{
+		adminType: rpcpb.AdminCompactShard,
+	}
+	return newAdminResponseBatch(rpcpb.AdminCompactShard,
+		&rpcpb.CompactShardResponse{Index: ctx.index})
+}
+
+// doExecPurgeExpiredData removes a batch of the data expired at the timestamp
+// assigned by the leader, so all the replicas remove the same data.
+func (d *stateMachine) doExecPurgeExpiredData(ctx *applyContext) rpcpb.ResponseBatch {
+	req := ctx.req.GetPurgeExpiredDataRequest()
+	resp := &rpcpb.PurgeExpiredDataResponse{}
+	if ds, ok := d.dataStorage.(storage.TTLDataStorage); ok && !d.isWitness() {
+		d.writeCtx.initialize(d.getShard(), ctx.index,
+			rpcpb.RequestBatch{Header: ctx.req.Header})
+		next, err := ds.PurgeExpiredData(d.writeCtx, req.Start, purgeExpiredDataBatchKeys)
+		if err != nil {
+			d.logger.Fatal("failed to purge expired data",
+				log.IndexField(ctx.index),
+				zap.Error(err))
+		}
+		resp.Next = next
+	}
+
+	ctx.adminResult = &adminResult{
+		adminType:              rpcpb.AdminPurgeExpiredData,
+		purgeExpiredDataResult: purgeExpiredDataResult{next: resp.Next},
+	}
+	return newAdminResponseBatch(rpcpb.AdminPurgeExpiredData, resp)
+}
+
+func (d *stateMachine) execReadSnapshotCmd(name string, fn func(storage.ReadSnapshotStorage) error) error {
+	if name == "" {
+		return errEmptyReadSnapshotName
+	}
+	s, ok := d.dataStorage.(storage.ReadSnapshotStorage)
+	if !ok {
+		return errReadSnapshotNotSupported
+	}
+	return fn(s)
+}
+
+func (d *stateMachine) readSnapshotErrorResp(ctx *applyContext, name string, err error) rpcpb.ResponseBatch {
+	d.logger.Error("fail to apply read snapshot request",
+		zap.String("type", ctx.req.GetAdminCmdType().String()),
+		zap.String("name", name),
+		log.IndexField(ctx.index),
+		zap.Error(err))
+	resp := errorBaseResp(ctx.req.Header.ID)
+	resp.Header.Error.Message = err.Error()
+	return resp
+}
+
 func (d *stateMachine) doExecCompactLog(ctx *applyContext) (rpcpb.ResponseBatch, error) {
 	ctx.metrics.admin.compact++
 
@@ -196,6 +410,132 @@ func (d *stateMachine) doExecConfigChange(ctx *applyContext) (rpcpb.ResponseBatc
 	return resp, nil
 }
 
+func (d *stateMachine) doExecConfigChangeV2(ctx *applyContext) (rpcpb.ResponseBatch, error) {
+	req := ctx.req.GetConfigChangeV2Request()
+	current := d.getShard()
+
+	d.logger.Info("begin to apply change replica v2",
+		log.IndexField(ctx.index),
+		log.ShardField("current", current),
+		log.ConfigChangesField("requests", req.Changes))
+
+	res := Shard{}
+	protoc.MustUnmarshal(&res, protoc.MustMarshal(&current))
+	var err error
+	if len(req.Changes) == 0 {
+		err = d.leaveJoint(&res)
+	} else {
+		err = d.enterJoint(&res, req.Changes)
+	}
+	if err != nil {
+		return rpcpb.ResponseBatch{}, err
+	}
+
+	state := metapb.ReplicaState_Normal
+	if d.isRemoved() {
+		state = metapb.ReplicaState_ReplicaTombstone
+	}
+	d.updateShard(res)
+	if err := d.saveShardMetedata(ctx.index, ctx.term, res, state); err != nil {
+		d.logger.Fatal("failed to save metadata",
+			zap.Error(err))
+	}
+
+	d.logger.Info("apply change replica v2 completed",
+		log.ShardField("metadata", res),
+		zap.String("state", state.String()))
+
+	resp := newAdminResponseBatch(rpcpb.AdminConfigChangeV2, &rpcpb.ConfigChangeResponse{
+		Shard: res,
+	})
+	ctx.adminResult = &adminResult{
+		adminType: rpcpb.AdminConfigChangeV2,
+		configChangeResult: configChangeResult{
+			index:   ctx.index,
+			changes: req.Changes,
+			shard:   res,
+		},
+	}
+	return resp, nil
+}
+
+// enterJoint applies the changes to the replicas of the shard entering the joint
+// state. The voters added or promoted become incoming voters and the voters
+// demoted become demoting voters until the joint state is left, the voters can
+// only be removed once they are demoted to learners.
+func (d *stateMachine) enterJoint(shard *Shard, changes []rpcpb.ConfigChangeRequest) error {
+	for _, change := range changes {
+		replica := change.Replica
+		p := findReplica(*shard, replica.StoreID)
+		if p != nil && p.ID != replica.ID {
+			return errors.Wrapf(ErrReplicaDuplicated,
+				"shardID %d, replicaID %d found on container %d", shard.ID, p.ID, replica.StoreID)
+		}
+
+		switch change.ChangeType {
+		case metapb.ConfigChangeType_AddNode:
+			if p == nil {
+				replica.Role = metapb.ReplicaRole_IncomingVoter
+				shard.Replicas = append(shard.Replicas, replica)
+			} else if p.Role == metapb.ReplicaRole_Learner {
+				p.Role = metapb.ReplicaRole_IncomingVoter
+			} else {
+				return errors.Wrapf(ErrReplicaDuplicated,
+					"shardID %d, replicaID %d, role %v", shard.ID, p.ID, p.Role)
+			}
+		case metapb.ConfigChangeType_AddLearnerNode:
+			if p == nil {
+				replica.Role = metapb.ReplicaRole_Learner
+				shard.Replicas = append(shard.Replicas, replica)
+			} else if p.Role == metapb.ReplicaRole_Voter {
+				p.Role = metapb.ReplicaRole_DemotingVoter
+			} else {
+				return errors.Wrapf(ErrReplicaDuplicated,
+					"shardID %d, replicaID %d, role %v", shard.ID, p.ID, p.Role)
+			}
+		case metapb.ConfigChangeType_RemoveNode:
+			if p == nil {
+				return errors.Wrapf(ErrReplicaNotFound,
+					"shardID %d, replicaID %d found on container %d", shard.ID, replica.ID, replica.StoreID)
+			}
+			if p.Role != metapb.ReplicaRole_Learner {
+				return errors.Wrapf(ErrRemoveVoter,
+					"shardID %d, replicaID %d, role %v", shard.ID, p.ID, p.Role)
+			}
+			removeReplica(shard, replica.StoreID)
+			if d.replica.ID == replica.ID {
+				d.setRemoved()
+			}
+		default:
+			return errors.Wrapf(ErrInvalidConfigChangeRequest,
+				"shardID %d, change type %v", shard.ID, change.ChangeType)
+		}
+	}
+	shard.Epoch.ConfigVer += uint64(len(changes))
+	return nil
+}
+
+// leaveJoint promotes the incoming voters to voters and demotes the demoting
+// voters to learners.
+func (d *stateMachine) leaveJoint(shard *Shard) error {
+	n := uint64(0)
+	for idx := range shard.Replicas {
+		switch shard.Replicas[idx].Role {
+		case metapb.ReplicaRole_IncomingVoter:
+			shard.Replicas[idx].Role = metapb.ReplicaRole_Voter
+			n++
+		case metapb.ReplicaRole_DemotingVoter:
+			shard.Replicas[idx].Role = metapb.ReplicaRole_Learner
+			n++
+		}
+	}
+	if n == 0 {
+		return errors.Wrapf(ErrNotInJointState, "shardID %d", shard.ID)
+	}
+	shard.Epoch.ConfigVer += n
+	return nil
+}
+
 func (d *stateMachine) doExecSplit(ctx *applyContext) (rpcpb.ResponseBatch, error) {
 	ctx.metrics.admin.split++
 	splitReqs := ctx.req.GetBatchSplitRequest()
@@ -245,6 +585,8 @@ func (d *stateMachine) doExecSplit(ctx *applyContext) (rpcpb.ResponseBatch, erro
 		newShard.Group = current.Group
 		newShard.Unique = current.Unique
 		newShard.RuleGroups = current.RuleGroups
+		newShard.DurabilityPolicy = current.DurabilityPolicy
+		newShard.ACL = current.ACL
 		newShard.Epoch = current.Epoch
 		newShard.Start = req.Start
 		newShard.End = req.End
@@ -275,7 +617,8 @@ func (d *stateMachine) doExecSplit(ctx *applyContext) (rpcpb.ResponseBatch, erro
 			RemoveData: false,
 		},
 	}
-	err := d.dataStorage.Split(old, replicaFactory.getShardsMetadata(), splitReqs.Context)
+	news := replicaFactory.getShardsMetadata()
+	err := d.dataStorage.Split(old, news, splitReqs.Context)
 	if err != nil {
 		if err == storage.ErrAborted {
 			return rpcpb.ResponseBatch{}, nil
@@ -283,6 +626,7 @@ func (d *stateMachine) doExecSplit(ctx *applyContext) (rpcpb.ResponseBatch, erro
 		d.logger.Fatal("failed to split on data storage",
 			zap.Error(err))
 	}
+	d.interceptShardMetadata(append([]metapb.ShardMetadata{old}, news...))
 
 	d.setSplited()
 	d.updateShard(current)
@@ -298,6 +642,231 @@ func (d *stateMachine) doExecSplit(ctx *applyContext) (rpcpb.ResponseBatch, erro
 	return resp, nil
 }
 
+func (d *stateMachine) doExecPrepareMerge(ctx *applyContext) (rpcpb.ResponseBatch, error) {
+	ctx.metrics.admin.merge++
+	target := ctx.req.GetPrepareMergeRequest().Target
+	current := d.getShard()
+
+	d.logger.Info("begin to apply prepare merge",
+		zap.Uint64("index", ctx.index),
+		log.ShardField("target", target))
+
+	if err := checkMergeShards(current, target); err != nil {
+		d.logger.Error("failed to prepare merge",
+			log.ShardField("target", target),
+			zap.Error(err))
+		return rpcpb.ResponseBatch{}, err
+	}
+
+	// The range of the shard is going to be owned by the target shard, the
+	// generation is increased so the clients refresh the routes of the frozen
+	// shard.
+	current.Epoch.Generation++
+	metadata := []metapb.ShardMetadata{
+		{
+			ShardID:  d.shardID,
+			LogIndex: ctx.index,
+			Metadata: metapb.ShardLocalState{
+				Shard:            current,
+				State:            metapb.ReplicaState_Normal,
+				MergeTarget:      target.ID,
+				MergeTargetEpoch: target.Epoch,
+				FenceIndex:       d.getFenceIndex(),
+				Migrated:         d.isMigrated(),
+			},
+		},
+	}
+	if err := d.dataStorage.SaveShardMetadata(metadata); err != nil {
+		d.logger.Fatal("failed to prepare merge",
+			zap.Error(err))
+	}
+	d.interceptShardMetadata(metadata)
+
+	d.updateShard(current)
+	d.setMergeTarget(target.ID, ctx.index, target.Epoch)
+	resp := newAdminResponseBatch(rpcpb.AdminPrepareMerge, &rpcpb.PrepareMergeResponse{})
+	ctx.adminResult = &adminResult{
+		adminType: rpcpb.AdminPrepareMerge,
+	}
+	return resp, nil
+}
+
+func (d *stateMachine) doExecMergeShard(ctx *applyContext) (rpcpb.ResponseBatch, error) {
+	ctx.metrics.admin.merge++
+	req := ctx.req.GetMergeShardRequest()
+	current := d.getShard()
+
+	d.logger.Info("begin to apply merge shard",
+		zap.Uint64("index", ctx.index),
+		log.ShardField("source", req.Source),
+		zap.Uint64("source-index", req.SourceIndex))
+
+	// the merge shard request is proposed again until the frozen source shard
+	// is destroyed, the duplicate requests are ignored.
+	if isShardRangeCovered(current, req.Source) {
+		return newAdminResponseBatch(rpcpb.AdminMergeShard, &rpcpb.MergeShardResponse{
+			Shard: current,
+		}), nil
+	}
+
+	if err := checkMergeTarget(req, current); err != nil {
+		d.logger.Error("failed to merge shard",
+			log.ShardField("source", req.Source),
+			zap.Error(err))
+		return rpcpb.ResponseBatch{}, err
+	}
+
+	if bytes.Equal(req.Source.End, current.Start) {
+		current.Start = req.Source.Start
+	} else {
+		current.End = req.Source.End
+	}
+	// the merged shard must be newer than both shards, so the prophet replaces
+	// the source shard with the merged shard.
+	if req.Source.Epoch.Generation > current.Epoch.Generation {
+		current.Epoch.Generation = req.Source.Epoch.Generation
+	}
+	current.Epoch.Generation++
+	if err := d.saveShardMetedata(ctx.index, ctx.term, current,
+		metapb.ReplicaState_Normal); err != nil {
+		d.logger.Fatal("failed to merge shard",
+			zap.Error(err))
+	}
+
+	d.updateShard(current)
+	ctx.metrics.admin.mergeSucceed++
+	d.logger.Info("shard merged",
+		log.ShardField("source", req.Source),
+		log.ShardField("new-shard", current))
+
+	resp := newAdminResponseBatch(rpcpb.AdminMergeShard, &rpcpb.MergeShardResponse{
+		Shard: current,
+	})
+	ctx.adminResult = &adminResult{
+		adminType: rpcpb.AdminMergeShard,
+		mergeResult: mergeResult{
+			source:      req.Source,
+			sourceIndex: req.SourceIndex,
+		},
+	}
+	return resp, nil
+}
+
+func (d *stateMachine) doExecRollbackMerge(ctx *applyContext) (rpcpb.ResponseBatch, error) {
+	req := ctx.req.GetRollbackMergeRequest()
+	current := d.getShard()
+	target, _ := d.getMergeTarget()
+
+	d.logger.Info("begin to apply rollback merge",
+		zap.Uint64("index", ctx.index),
+		zap.Uint64("target", req.Target))
+
+	resp := newAdminResponseBatch(rpcpb.AdminRollbackMerge, &rpcpb.RollbackMergeResponse{})
+	if target == 0 || target != req.Target {
+		return resp, nil
+	}
+
+	// the range of the shard is owned by the shard again, the generation is
+	// increased so the clients refresh the routes of the shard.
+	current.Epoch.Generation++
+	d.setMergeTarget(0, 0, Epoch{})
+	if err := d.saveShardMetedata(ctx.index, ctx.term, current,
+		metapb.ReplicaState_Normal); err != nil {
+		d.logger.Fatal("failed to rollback merge",
+			zap.Error(err))
+	}
+	d.updateShard(current)
+	d.logger.Info("merge rolled back",
+		zap.Uint64("target", target),
+		log.ShardField("shard", current))
+
+	ctx.adminResult = &adminResult{
+		adminType: rpcpb.AdminRollbackMerge,
+	}
+	return resp, nil
+}
+
+func (d *stateMachine) doExecAbortMerge(ctx *applyContext) (rpcpb.ResponseBatch, error) {
+	req := ctx.req.GetAbortMergeRequest()
+	current := d.getShard()
+
+	d.logger.Info("begin to apply abort merge",
+		zap.Uint64("index", ctx.index),
+		log.ShardField("source", req.Source))
+
+	// the merge is already applied, or can never be applied as the epoch of the
+	// shard changed since the prepare merge
+	resp := newAdminResponseBatch(rpcpb.AdminAbortMerge, &rpcpb.AbortMergeResponse{})
+	if isShardRangeCovered(current, req.Source) ||
+		current.Epoch.Generation != req.TargetEpoch.Generation ||
+		current.Epoch.ConfigVer != req.TargetEpoch.ConfigVer {
+		return resp, nil
+	}
+
+	current.Epoch.Generation++
+	if err := d.saveShardMetedata(ctx.index, ctx.term, current,
+		metapb.ReplicaState_Normal); err != nil {
+		d.logger.Fatal("failed to abort merge",
+			zap.Error(err))
+	}
+	d.updateShard(current)
+	d.logger.Info("merge aborted",
+		log.ShardField("source", req.Source),
+		log.ShardField("shard", current))
+
+	ctx.adminResult = &adminResult{
+		adminType: rpcpb.AdminAbortMerge,
+	}
+	return resp, nil
+}
+
+// checkMergeTarget returns an error if the source shard of the merge request
+// can not be merged into the target shard, the epoch of the target shard must
+// not be changed since the prepare merge is proposed.
+func checkMergeTarget(req rpcpb.MergeShardRequest, target Shard) error {
+	if target.Epoch.Generation != req.TargetEpoch.Generation ||
+		target.Epoch.ConfigVer != req.TargetEpoch.ConfigVer {
+		return errMergeTargetEpochChanged
+	}
+	return checkMergeShards(req.Source, target)
+}
+
+// checkMergeShards returns an error if the source shard can not be merged into
+// the target shard. The shards must be adjacent in the same group, and their
+// replicas must be on the same stores as the data of the source shard is
+// taken over by the local replicas of the target shard.
+func checkMergeShards(source, target Shard) error {
+	if source.ID == target.ID || source.Group != target.Group ||
+		target.State != metapb.ShardState_Running {
+		return errMergeShardsMismatch
+	}
+
+	if !(len(source.End) > 0 && bytes.Equal(source.End, target.Start)) &&
+		!(len(target.End) > 0 && bytes.Equal(target.End, source.Start)) {
+		return errMergeShardsNotAdjacent
+	}
+
+	if len(source.Replicas) != len(target.Replicas) {
+		return errMergeShardsReplicasMismatch
+	}
+	for _, r := range source.Replicas {
+		if findReplica(target, r.StoreID) == nil {
+			return errMergeShardsReplicasMismatch
+		}
+	}
+	return nil
+}
+
+// isShardRangeCovered returns true if the range of the source shard is covered
+// by the target shard.
+func isShardRangeCovered(target, source Shard) bool {
+	if bytes.Compare(target.Start, source.Start) > 0 {
+		return false
+	}
+	return len(target.End) == 0 ||
+		(len(source.End) > 0 && bytes.Compare(source.End, target.End) <= 0)
+}
+
 func (d *stateMachine) doUpdateLabels(ctx *applyContext) (rpcpb.ResponseBatch, error) {
 	updateReq := ctx.req.GetUpdateLabelsRequest()
 	current := d.getShard()
@@ -339,7 +908,7 @@ func (d *stateMachine) doUpdateLabels(ctx *applyContext) (rpcpb.ResponseBatch, e
 		current.Labels = nil
 	}
 
-	err := d.dataStorage.SaveShardMetadata([]metapb.ShardMetadata{
+	metadata := []metapb.ShardMetadata{
 		{
 			ShardID:  d.shardID,
 			LogIndex: ctx.index,
@@ -348,11 +917,13 @@ func (d *stateMachine) doUpdateLabels(ctx *applyContext) (rpcpb.ResponseBatch, e
 				State: metapb.ReplicaState_Normal,
 			},
 		},
-	})
+	}
+	err := d.dataStorage.SaveShardMetadata(metadata)
 	if err != nil {
 		d.logger.Fatal("failed to update labels",
 			zap.Error(err))
 	}
+	d.interceptShardMetadata(metadata)
 
 	sort.Slice(current.Labels, func(i, j int) bool {
 		return current.Labels[i].Key < current.Labels[j].Key
@@ -369,6 +940,39 @@ func (d *stateMachine) doUpdateLabels(ctx *applyContext) (rpcpb.ResponseBatch, e
 	return resp, nil
 }
 
+func (d *stateMachine) doUpdateDurabilityPolicy(ctx *applyContext) (rpcpb.ResponseBatch, error) {
+	updateReq := ctx.req.GetUpdateDurabilityPolicyRequest()
+	current := d.getShard()
+	current.DurabilityPolicy = updateReq.Policy
+
+	metadata := []metapb.ShardMetadata{
+		{
+			ShardID:  d.shardID,
+			LogIndex: ctx.index,
+			Metadata: metapb.ShardLocalState{
+				Shard: current,
+				State: metapb.ReplicaState_Normal,
+			},
+		},
+	}
+	err := d.dataStorage.SaveShardMetadata(metadata)
+	if err != nil {
+		d.logger.Fatal("failed to update durability policy",
+			zap.Error(err))
+	}
+	d.interceptShardMetadata(metadata)
+	d.updateShard(current)
+
+	d.logger.Info("shard durability policy updated",
+		zap.String("policy", current.DurabilityPolicy.String()))
+
+	resp := newAdminResponseBatch(rpcpb.AdminUpdateDurabilityPolicy, &rpcpb.UpdateDurabilityPolicyResponse{})
+	ctx.adminResult = &adminResult{
+		adminType: rpcpb.AdminUpdateDurabilityPolicy,
+	}
+	return resp, nil
+}
+
 func (d *stateMachine) doUpdateMetadata(ctx *applyContext) (rpcpb.ResponseBatch, error) {
 	ctx.metrics.admin.updateMetadata++
 	updateReq := ctx.req.GetUpdateMetadataRequest()
@@ -380,19 +984,21 @@ func (d *stateMachine) doUpdateMetadata(ctx *applyContext) (rpcpb.ResponseBatch,
 			log.ShardField("new-shard", updateReq.Metadata.Shard))
 	}
 
-	err := d.dataStorage.SaveShardMetadata([]metapb.ShardMetadata{
+	metadata := []metapb.ShardMetadata{
 		{
 			ShardID:  d.shardID,
 			LogIndex: ctx.index,
 			Metadata: updateReq.Metadata,
 		},
-	})
+	}
+	err := d.dataStorage.SaveShardMetadata(metadata)
 	if err != nil {
 		d.logger.Fatal("failed to update metadata",
 			log.EpochField("current", current.Epoch),
 			log.ShardField("new-shard", updateReq.Metadata.Shard),
 			zap.Error(err))
 	}
+	d.interceptShardMetadata(metadata)
 
 	d.updateShard(updateReq.Metadata.Shard)
 
@@ -427,6 +1033,9 @@ func (d *stateMachine) doUpdateMetadata(ctx *applyContext) (rpcpb.ResponseBatch,
 }
 
 func (d *stateMachine) execWriteRequest(ctx *applyContext) rpcpb.ResponseBatch {
+	if d.isWitness() {
+		return d.execWitnessWriteRequest(ctx)
+	}
 	d.writeCtx.initialize(d.getShard(), ctx.index, ctx.req)
 	for _, req := range ctx.req.Requests {
 		if ce := d.logger.Check(zap.DebugLevel, "begin to execute write"); ce != nil {
@@ -440,6 +1049,15 @@ func (d *stateMachine) execWriteRequest(ctx *applyContext) rpcpb.ResponseBatch {
 		d.logger.Fatal("failed to exec read cmd",
 			zap.Error(err))
 	}
+	// the writes proposed by the replica are synced before acknowledged
+	if ctx.req.Header.Replica.ID == d.replica.ID &&
+		ctx.req.Requests[0].Durability == rpcpb.LeaderApplied {
+		if err := d.dataStorage.Sync([]uint64{d.shardID}); err != nil {
+			d.logger.Fatal("failed to sync the applied writes",
+				log.IndexField(ctx.index),
+				zap.Error(err))
+		}
+	}
 	for _, req := range ctx.req.Requests {
 		if ce := d.logger.Check(zap.DebugLevel, "write completed"); ce != nil {
 			ce.Write(log.HexField("id", req.ID),
@@ -450,9 +1068,29 @@ func (d *stateMachine) execWriteRequest(ctx *applyContext) rpcpb.ResponseBatch {
 	}
 
 	resp := rpcpb.ResponseBatch{}
-	for _, v := range d.writeCtx.responses {
-		ctx.metrics.writtenKeys++
-		r := rpcpb.Response{Value: v}
+	values := d.writeCtx.responses
+	for idx, req := range ctx.req.Requests {
+		n := d.writeCtx.ops[idx]
+		if n == 0 {
+			r := rpcpb.Response{AppliedIndex: ctx.index, AppliedTerm: ctx.term}
+			r.Error = *checkShardACL(d.writeCtx.shard, req, metapb.OperationClass_WriteOperation)
+			resp.Responses = append(resp.Responses, r)
+			continue
+		}
+		if n > len(values) {
+			d.logger.Fatal("missing write responses",
+				log.HexField("id", req.ID),
+				zap.Int("expect", n),
+				zap.Int("actual", len(values)))
+		}
+		ctx.metrics.writtenKeys += uint64(n)
+		r := rpcpb.Response{AppliedIndex: ctx.index, AppliedTerm: ctx.term}
+		if len(req.WriteOps) > 0 {
+			r.WriteOpValues = values[:n:n]
+		} else {
+			r.Value = values[0]
+		}
+		values = values[n:]
 		resp.Responses = append(resp.Responses, r)
 	}
 	d.updateWriteMetrics()
@@ -461,26 +1099,66 @@ func (d *stateMachine) execWriteRequest(ctx *applyContext) rpcpb.ResponseBatch {
 
 func (d *stateMachine) updateWriteMetrics() {
 	d.applyCtx.metrics.writtenBytes += d.writeCtx.writtenBytes
-	if d.writeCtx.diffBytes < 0 {
-		v := uint64(math.Abs(float64(d.writeCtx.diffBytes)))
-		if v >= d.applyCtx.metrics.approximateDiffHint {
-			d.applyCtx.metrics.approximateDiffHint = 0
-		} else {
-			d.applyCtx.metrics.approximateDiffHint -= v
-		}
-	} else {
-		d.applyCtx.metrics.approximateDiffHint += uint64(d.writeCtx.diffBytes)
-	}
+	d.applyCtx.metrics.approximateDiffHint += d.writeCtx.diffBytes
+	d.applyCtx.metrics.approximateKeysDiffHint += d.writeCtx.diffKeys
 }
 
 func (d *stateMachine) saveShardMetedata(index uint64, term uint64,
 	shard Shard, state metapb.ReplicaState) error {
-	return d.dataStorage.SaveShardMetadata([]metapb.ShardMetadata{{
+	metadata := []metapb.ShardMetadata{d.newShardMetadata(index, shard, state)}
+	if err := d.dataStorage.SaveShardMetadata(metadata); err != nil {
+		return err
+	}
+	d.interceptShardMetadata(metadata)
+	return nil
+}
+
+func (d *stateMachine) newShardMetadata(index uint64, shard Shard,
+	state metapb.ReplicaState) metapb.ShardMetadata {
+	target, _ := d.getMergeTarget()
+	return metapb.ShardMetadata{
 		ShardID:  shard.ID,
 		LogIndex: index,
 		Metadata: metapb.ShardLocalState{
-			State: state,
-			Shard: shard,
+			State:            state,
+			Shard:            shard,
+			MergeTarget:      target,
+			MergeTargetEpoch: d.getMergeTargetEpoch(),
+			FenceIndex:       d.getFenceIndex(),
+			Migrated:         d.isMigrated(),
 		},
-	}})
+	}
+}
+
+// interceptShardMetadata syncs the saved shard metadata to disk and passes it to
+// the custom interceptor.
+func (d *stateMachine) interceptShardMetadata(metadata []metapb.ShardMetadata) {
+	if d.metadataInterceptor == nil || len(metadata) == 0 {
+		return
+	}
+
+	ids := make([]uint64, 0, len(metadata))
+	for _, m := range metadata {
+		ids = append(ids, m.ShardID)
+	}
+	if err := d.dataStorage.Sync(ids); err != nil {
+		d.logger.Fatal("failed to sync shard metadata",
+			zap.Error(err))
+	}
+	interceptShardMetadata(d.logger, d.metadataInterceptor, metadata)
+}
+
+// interceptShardMetadata passes the persisted shard metadata to the custom
+// interceptor, the metadata must be synced to disk before and all belong to
+// the same group.
+func interceptShardMetadata(logger *zap.Logger, interceptor config.ShardMetadataInterceptor,
+	metadata []metapb.ShardMetadata) {
+	if interceptor == nil || len(metadata) == 0 {
+		return
+	}
+
+	if err := interceptor.OnSaved(metadata[0].Metadata.Shard.Group, metadata); err != nil {
+		logger.Fatal("failed to intercept shard metadata",
+			zap.Error(err))
+	}
 }
@@ -14,6 +14,7 @@
 package raftstore
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/fagongzi/util/protoc"
@@ -22,6 +23,7 @@ import (
 	"go.etcd.io/etcd/raft/v3"
 	"go.etcd.io/etcd/raft/v3/raftpb"
 
+	"github.com/matrixorigin/matrixcube/config"
 	"github.com/matrixorigin/matrixcube/pb/metapb"
 	"github.com/matrixorigin/matrixcube/pb/rpcpb"
 	"github.com/matrixorigin/matrixcube/storage"
@@ -180,7 +182,8 @@ func TestDoExecSplit(t *testing.T) {
 	s, cancel := newTestStore(t)
 	defer cancel()
 
-	pr := newTestReplica(Shard{ID: 1, Epoch: Epoch{Generation: 2}, Start: []byte{1}, End: []byte{10}, Replicas: []Replica{{ID: 2}}}, Replica{ID: 2}, s)
+	pr := newTestReplica(Shard{ID: 1, Epoch: Epoch{Generation: 2}, Start: []byte{1}, End: []byte{10}, Replicas: []Replica{{ID: 2}},
+		DurabilityPolicy: metapb.DurabilityPolicy_LeaderOnlyVoter, ACL: &metapb.ShardACL{Owner: "t1"}}, Replica{ID: 2}, s)
 	ctx := newApplyContext()
 
 	ch := make(chan bool)
@@ -217,6 +220,8 @@ func TestDoExecSplit(t *testing.T) {
 	assert.True(t, <-ch)
 
 	// s1 -> s2+s3
+	interceptor := &testShardMetadataInterceptor{}
+	pr.sm.metadataInterceptor = interceptor
 	ctx.index = 100
 	ctx.req = newTestAdminRequestBatch("", 0, rpcpb.AdminBatchSplit, protoc.MustMarshal(&rpcpb.BatchSplitRequest{
 		Requests: []rpcpb.SplitRequest{
@@ -234,8 +239,15 @@ func TestDoExecSplit(t *testing.T) {
 	assert.Equal(t, adminReq.Requests[0].End, adminResp.Shards[0].End)
 	assert.Equal(t, adminReq.Requests[1].Start, adminResp.Shards[1].Start)
 	assert.Equal(t, pr.getShard().End, adminResp.Shards[1].End)
+	assert.Equal(t, metapb.DurabilityPolicy_LeaderOnlyVoter, adminResp.Shards[0].DurabilityPolicy)
+	assert.Equal(t, metapb.DurabilityPolicy_LeaderOnlyVoter, adminResp.Shards[1].DurabilityPolicy)
+	assert.Equal(t, "t1", adminResp.Shards[0].ACL.Owner)
+	assert.Equal(t, "t1", adminResp.Shards[1].ACL.Owner)
 	assert.False(t, pr.sm.canApply(raftpb.Entry{}))
 	assert.True(t, pr.sm.metadataMu.splited)
+	require.Equal(t, 1, len(interceptor.saved))
+	assert.Equal(t, 3, len(interceptor.saved[0]))
+	assert.Equal(t, metapb.ShardState_Destroying, interceptor.saved[0][0].Metadata.Shard.State)
 
 	_, err = pr.sm.dataStorage.GetInitialStates()
 	require.NoError(t, err)
@@ -367,3 +379,440 @@ func TestDoExecCompactLog(t *testing.T) {
 	assert.Equal(t, uint64(3), state.FirstIndex)
 	assert.Equal(t, uint64(3), state.EntryCount)
 }
+
+func TestDoExecCustomAdmin(t *testing.T) {
+	cmdType := rpcpb.MinCustomAdminCmdType + 1
+	h := &testReplicaResultHandler{}
+	f := func(sm *stateMachine) {
+		var applied []uint64
+		sm.customAdminHandlers = map[rpcpb.AdminCmdType]config.CustomAdminCmdHandler{
+			cmdType: {
+				Apply: func(shard metapb.Shard, index uint64, cmd []byte, ds storage.DataStorage) ([]byte, error) {
+					applied = append(applied, index)
+					if string(cmd) == "bad" {
+						return nil, errors.New("bad cmd")
+					}
+					return append([]byte("applied-"), cmd...), nil
+				},
+			},
+		}
+
+		ctx := newApplyContext()
+		ctx.index = 1
+		ctx.req = newTestAdminRequestBatch("r1", 0, cmdType, []byte("c1"))
+		resp, err := sm.execAdminRequest(ctx)
+		assert.NoError(t, err)
+		require.Equal(t, 1, len(resp.Responses))
+		assert.Equal(t, []byte("applied-c1"), resp.Responses[0].Value)
+		require.NotNil(t, ctx.adminResult)
+		assert.Equal(t, cmdType, ctx.adminResult.adminType)
+
+		ctx = newApplyContext()
+		ctx.index = 2
+		ctx.req = newTestAdminRequestBatch("r2", 0, cmdType, []byte("bad"))
+		resp, err = sm.execAdminRequest(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, "bad cmd", resp.Header.Error.Message)
+		assert.Nil(t, ctx.adminResult)
+		assert.Equal(t, []uint64{1, 2}, applied)
+
+		// not registered
+		ctx = newApplyContext()
+		ctx.req = newTestAdminRequestBatch("r3", 0, cmdType+1, nil)
+		resp, err = sm.execAdminRequest(ctx)
+		assert.NoError(t, err)
+		assert.Empty(t, resp.Responses)
+	}
+	runSimpleStateMachineTest(t, f, h)
+}
+
+type testShardMetadataInterceptor struct {
+	groups []uint64
+	saved  [][]metapb.ShardMetadata
+	err    error
+	// ds records the persistent log indexes of the saved shards if not nil
+	ds        storage.DataStorage
+	persisted []uint64
+}
+
+func (t *testShardMetadataInterceptor) Replay(group uint64, metadata []metapb.ShardMetadata) error {
+	return t.err
+}
+
+func (t *testShardMetadataInterceptor) OnSaved(group uint64, metadata []metapb.ShardMetadata) error {
+	t.groups = append(t.groups, group)
+	t.saved = append(t.saved, metadata)
+	if t.ds != nil {
+		index, err := t.ds.GetPersistentLogIndex(metadata[0].ShardID)
+		if err != nil {
+			return err
+		}
+		t.persisted = append(t.persisted, index)
+	}
+	return t.err
+}
+
+func TestShardMetadataInterceptor(t *testing.T) {
+	f := func(sm *stateMachine) {
+		_, err := sm.dataStorage.GetInitialStates()
+		require.NoError(t, err)
+		interceptor := &testShardMetadataInterceptor{ds: sm.dataStorage}
+		sm.metadataInterceptor = interceptor
+		sm.updateShard(Shard{ID: 100, Group: 1})
+
+		ctx := newApplyContext()
+		ctx.index = 1
+		ctx.req = newTestAdminRequestBatch("r1", 0, rpcpb.AdminUpdateLabels, protoc.MustMarshal(&rpcpb.UpdateLabelsRequest{
+			Labels: []metapb.Label{{Key: "k1", Value: "v1"}},
+			Policy: rpcpb.Add,
+		}))
+		_, err = sm.execAdminRequest(ctx)
+		assert.NoError(t, err)
+		assert.NoError(t, sm.saveShardMetedata(2, 1, sm.getShard(), metapb.ReplicaState_ReplicaTombstone))
+
+		assert.Equal(t, []uint64{1, 1}, interceptor.groups)
+		require.Equal(t, 2, len(interceptor.saved))
+		require.Equal(t, 1, len(interceptor.saved[0]))
+		assert.Equal(t, uint64(1), interceptor.saved[0][0].LogIndex)
+		assert.Equal(t, []metapb.Label{{Key: "k1", Value: "v1"}}, interceptor.saved[0][0].Metadata.Shard.Labels)
+		require.Equal(t, 1, len(interceptor.saved[1]))
+		assert.Equal(t, uint64(2), interceptor.saved[1][0].LogIndex)
+		assert.Equal(t, metapb.ReplicaState_ReplicaTombstone, interceptor.saved[1][0].Metadata.State)
+
+		// the intercepted metadata is synced before
+		assert.Equal(t, []uint64{1, 2}, interceptor.persisted)
+		metadata, err := sm.dataStorage.GetInitialStates()
+		assert.NoError(t, err)
+		require.Equal(t, 1, len(metadata))
+		assert.Equal(t, uint64(2), metadata[0].LogIndex)
+
+		interceptor.err = errors.New("catalog failed")
+		assert.Panics(t, func() {
+			_ = sm.saveShardMetedata(3, 1, sm.getShard(), metapb.ReplicaState_Normal)
+		})
+	}
+	runSimpleStateMachineTest(t, f, nil)
+}
+
+func TestDoUpdateDurabilityPolicy(t *testing.T) {
+	f := func(sm *stateMachine) {
+		sm.updateShard(Shard{ID: 100, Group: 1})
+
+		ctx := newApplyContext()
+		ctx.index = 1
+		ctx.req = newTestAdminRequestBatch("r1", 0, rpcpb.AdminUpdateDurabilityPolicy, protoc.MustMarshal(&rpcpb.UpdateDurabilityPolicyRequest{
+			Policy: metapb.DurabilityPolicy_LeaderOnlyVoter,
+		}))
+		_, err := sm.execAdminRequest(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, rpcpb.AdminUpdateDurabilityPolicy, ctx.adminResult.adminType)
+		assert.Equal(t, metapb.DurabilityPolicy_LeaderOnlyVoter, sm.getShard().DurabilityPolicy)
+
+		metadata, err := sm.dataStorage.GetInitialStates()
+		assert.NoError(t, err)
+		require.Equal(t, 1, len(metadata))
+		assert.Equal(t, metapb.DurabilityPolicy_LeaderOnlyVoter, metadata[0].Metadata.Shard.DurabilityPolicy)
+	}
+	runSimpleStateMachineTest(t, f, nil)
+}
+
+func TestDoExecPrepareMerge(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	s, cancel := newTestStore(t)
+	defer cancel()
+
+	pr := newTestReplica(Shard{ID: 1, Epoch: Epoch{Generation: 2}, Start: []byte{1}, End: []byte{5},
+		Replicas: []Replica{{ID: 2, StoreID: 1}}}, Replica{ID: 2, StoreID: 1}, s)
+	ctx := newApplyContext()
+
+	// not adjacent
+	ctx.index = 100
+	ctx.req = newTestAdminRequestBatch("", 0, rpcpb.AdminPrepareMerge, protoc.MustMarshal(&rpcpb.PrepareMergeRequest{
+		Target: Shard{ID: 3, Start: []byte{6}, End: []byte{10}, Replicas: []Replica{{ID: 4, StoreID: 1}}},
+	}))
+	_, err := pr.sm.execAdminRequest(ctx)
+	assert.Equal(t, errMergeShardsNotAdjacent, err)
+	target, _ := pr.sm.getMergeTarget()
+	assert.Equal(t, uint64(0), target)
+
+	ctx.req = newTestAdminRequestBatch("", 0, rpcpb.AdminPrepareMerge, protoc.MustMarshal(&rpcpb.PrepareMergeRequest{
+		Target: Shard{ID: 3, Epoch: Epoch{Generation: 4, ConfigVer: 2}, Start: []byte{5}, End: []byte{10},
+			Replicas: []Replica{{ID: 4, StoreID: 1}}},
+	}))
+	resp, err := pr.sm.execAdminRequest(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(resp.Responses))
+	assert.Equal(t, rpcpb.AdminPrepareMerge, ctx.adminResult.adminType)
+	assert.Equal(t, uint64(3), pr.getShard().Epoch.Generation)
+	target, index := pr.sm.getMergeTarget()
+	assert.Equal(t, uint64(3), target)
+	assert.Equal(t, uint64(100), index)
+	assert.Equal(t, Epoch{Generation: 4, ConfigVer: 2}, pr.sm.getMergeTargetEpoch())
+	// the frozen shard only applies the rollback of the merge
+	rollback := newTestAdminRequestBatch("", 0, rpcpb.AdminRollbackMerge,
+		protoc.MustMarshal(&rpcpb.RollbackMergeRequest{Target: 3}))
+	assert.False(t, pr.sm.canApply(raftpb.Entry{}))
+	assert.False(t, pr.sm.canApply(raftpb.Entry{Type: raftpb.EntryConfChange}))
+	assert.True(t, pr.sm.canApply(raftpb.Entry{Data: protoc.MustMarshal(&rollback)}))
+
+	metadata, err := pr.sm.dataStorage.GetInitialStates()
+	assert.NoError(t, err)
+	require.Equal(t, 1, len(metadata))
+	assert.Equal(t, uint64(3), metadata[0].Metadata.MergeTarget)
+	assert.Equal(t, Epoch{Generation: 4, ConfigVer: 2}, metadata[0].Metadata.MergeTargetEpoch)
+	assert.Equal(t, metapb.ShardState_Running, metadata[0].Metadata.Shard.State)
+
+	// rollback the merge of another target is ignored
+	ctx = newApplyContext()
+	ctx.index = 101
+	ctx.req = newTestAdminRequestBatch("", 0, rpcpb.AdminRollbackMerge,
+		protoc.MustMarshal(&rpcpb.RollbackMergeRequest{Target: 4}))
+	_, err = pr.sm.execAdminRequest(ctx)
+	assert.NoError(t, err)
+	assert.Nil(t, ctx.adminResult)
+	target, _ = pr.sm.getMergeTarget()
+	assert.Equal(t, uint64(3), target)
+
+	ctx.index = 102
+	ctx.req = rollback
+	_, err = pr.sm.execAdminRequest(ctx)
+	assert.NoError(t, err)
+	require.NotNil(t, ctx.adminResult)
+	assert.Equal(t, rpcpb.AdminRollbackMerge, ctx.adminResult.adminType)
+	assert.Equal(t, uint64(4), pr.getShard().Epoch.Generation)
+	target, _ = pr.sm.getMergeTarget()
+	assert.Equal(t, uint64(0), target)
+	assert.True(t, pr.sm.canApply(raftpb.Entry{}))
+
+	metadata, err = pr.sm.dataStorage.GetInitialStates()
+	assert.NoError(t, err)
+	require.Equal(t, 1, len(metadata))
+	assert.Equal(t, uint64(0), metadata[0].Metadata.MergeTarget)
+	assert.Equal(t, uint64(102), metadata[0].LogIndex)
+}
+
+func TestDoExecMergeShard(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	s, cancel := newTestStore(t)
+	defer cancel()
+
+	pr := newTestReplica(Shard{ID: 3, Epoch: Epoch{Generation: 2}, Start: []byte{5}, End: []byte{10},
+		Replicas: []Replica{{ID: 4, StoreID: 1}}}, Replica{ID: 4, StoreID: 1}, s)
+	ctx := newApplyContext()
+
+	source := Shard{ID: 1, Epoch: Epoch{Generation: 5}, Start: []byte{1}, End: []byte{5},
+		Replicas: []Replica{{ID: 2, StoreID: 1}}}
+	// the epoch of the target changed since the prepare merge
+	ctx.index = 100
+	ctx.req = newTestAdminRequestBatch("", 0, rpcpb.AdminMergeShard, protoc.MustMarshal(&rpcpb.MergeShardRequest{
+		Source:      source,
+		SourceIndex: 10,
+		TargetEpoch: Epoch{Generation: 1},
+	}))
+	_, err := pr.sm.execAdminRequest(ctx)
+	assert.Equal(t, errMergeTargetEpochChanged, err)
+	assert.Nil(t, ctx.adminResult)
+
+	ctx.req = newTestAdminRequestBatch("", 0, rpcpb.AdminMergeShard, protoc.MustMarshal(&rpcpb.MergeShardRequest{
+		Source:      source,
+		SourceIndex: 10,
+		TargetEpoch: Epoch{Generation: 2},
+	}))
+	resp, err := pr.sm.execAdminRequest(ctx)
+	assert.NoError(t, err)
+	adminResp := resp.GetMergeShardResponse()
+	assert.Equal(t, []byte{1}, adminResp.Shard.Start)
+	assert.Equal(t, []byte{10}, adminResp.Shard.End)
+	assert.Equal(t, uint64(6), adminResp.Shard.Epoch.Generation)
+	assert.Equal(t, adminResp.Shard, pr.getShard())
+	require.NotNil(t, ctx.adminResult)
+	assert.Equal(t, rpcpb.AdminMergeShard, ctx.adminResult.adminType)
+	assert.Equal(t, uint64(1), ctx.adminResult.mergeResult.source.ID)
+	assert.Equal(t, uint64(10), ctx.adminResult.mergeResult.sourceIndex)
+
+	// duplicate merge request
+	ctx = newApplyContext()
+	ctx.index = 101
+	ctx.req = newTestAdminRequestBatch("", 0, rpcpb.AdminMergeShard, protoc.MustMarshal(&rpcpb.MergeShardRequest{
+		Source:      source,
+		SourceIndex: 10,
+		TargetEpoch: Epoch{Generation: 2},
+	}))
+	_, err = pr.sm.execAdminRequest(ctx)
+	assert.NoError(t, err)
+	assert.Nil(t, ctx.adminResult)
+	assert.Equal(t, uint64(6), pr.getShard().Epoch.Generation)
+
+	metadata, err := pr.sm.dataStorage.GetInitialStates()
+	assert.NoError(t, err)
+	require.Equal(t, 1, len(metadata))
+	assert.Equal(t, []byte{1}, metadata[0].Metadata.Shard.Start)
+	assert.Equal(t, []byte{10}, metadata[0].Metadata.Shard.End)
+}
+
+func TestDoExecAbortMerge(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	s, cancel := newTestStore(t)
+	defer cancel()
+
+	pr := newTestReplica(Shard{ID: 3, Epoch: Epoch{Generation: 2}, Start: []byte{5}, End: []byte{10},
+		Replicas: []Replica{{ID: 4, StoreID: 1}}}, Replica{ID: 4, StoreID: 1}, s)
+	source := Shard{ID: 1, Epoch: Epoch{Generation: 5}, Start: []byte{1}, End: []byte{5},
+		Replicas: []Replica{{ID: 2, StoreID: 1}}}
+	abort := func(index uint64, targetEpoch Epoch) *applyContext {
+		ctx := newApplyContext()
+		ctx.index = index
+		ctx.req = newTestAdminRequestBatch("", 0, rpcpb.AdminAbortMerge, protoc.MustMarshal(&rpcpb.AbortMergeRequest{
+			Source:      source,
+			TargetEpoch: targetEpoch,
+		}))
+		_, err := pr.sm.execAdminRequest(ctx)
+		assert.NoError(t, err)
+		return ctx
+	}
+
+	// the epoch of the target changed since the prepare merge
+	assert.Nil(t, abort(100, Epoch{Generation: 1}).adminResult)
+	assert.Equal(t, uint64(2), pr.getShard().Epoch.Generation)
+
+	ctx := abort(101, Epoch{Generation: 2})
+	require.NotNil(t, ctx.adminResult)
+	assert.Equal(t, rpcpb.AdminAbortMerge, ctx.adminResult.adminType)
+	assert.Equal(t, uint64(3), pr.getShard().Epoch.Generation)
+	metadata, err := pr.sm.dataStorage.GetInitialStates()
+	assert.NoError(t, err)
+	require.Equal(t, 1, len(metadata))
+	assert.Equal(t, uint64(3), metadata[0].Metadata.Shard.Epoch.Generation)
+
+	// the duplicate abort takes no effect
+	assert.Nil(t, abort(102, Epoch{Generation: 2}).adminResult)
+	assert.Equal(t, uint64(3), pr.getShard().Epoch.Generation)
+
+	// the range of the source is already taken over
+	pr.sm.updateShard(Shard{ID: 3, Epoch: Epoch{Generation: 2}, Start: []byte{1}, End: []byte{10}})
+	assert.Nil(t, abort(103, Epoch{Generation: 2}).adminResult)
+	assert.Equal(t, uint64(2), pr.getShard().Epoch.Generation)
+}
+
+func TestCheckMergeShards(t *testing.T) {
+	replicas := []Replica{{ID: 1, StoreID: 1}, {ID: 2, StoreID: 2}}
+	cases := []struct {
+		source Shard
+		target Shard
+		err    error
+	}{
+		{
+			source: Shard{ID: 1, End: []byte("b"), Replicas: replicas},
+			target: Shard{ID: 2, Start: []byte("b"), Replicas: replicas},
+		},
+		{
+			source: Shard{ID: 1, Start: []byte("b"), Replicas: replicas},
+			target: Shard{ID: 2, End: []byte("b"), Replicas: replicas},
+		},
+		{
+			source: Shard{ID: 1, End: []byte("b"), Replicas: replicas},
+			target: Shard{ID: 1, Start: []byte("b"), Replicas: replicas},
+			err:    errMergeShardsMismatch,
+		},
+		{
+			source: Shard{ID: 1, End: []byte("b"), Replicas: replicas},
+			target: Shard{ID: 2, Group: 1, Start: []byte("b"), Replicas: replicas},
+			err:    errMergeShardsMismatch,
+		},
+		{
+			source: Shard{ID: 1, End: []byte("b"), Replicas: replicas},
+			target: Shard{ID: 2, Start: []byte("b"), Replicas: replicas, State: metapb.ShardState_Destroying},
+			err:    errMergeShardsMismatch,
+		},
+		{
+			source: Shard{ID: 1, End: []byte("b"), Replicas: replicas},
+			target: Shard{ID: 2, Start: []byte("c"), Replicas: replicas},
+			err:    errMergeShardsNotAdjacent,
+		},
+		{
+			source: Shard{ID: 1, Replicas: replicas},
+			target: Shard{ID: 2, Replicas: replicas},
+			err:    errMergeShardsNotAdjacent,
+		},
+		{
+			source: Shard{ID: 1, End: []byte("b"), Replicas: replicas},
+			target: Shard{ID: 2, Start: []byte("b"), Replicas: []Replica{{ID: 3, StoreID: 1}, {ID: 4, StoreID: 3}}},
+			err:    errMergeShardsReplicasMismatch,
+		},
+	}
+
+	for i, c := range cases {
+		assert.Equal(t, c.err, checkMergeShards(c.source, c.target), "index %d", i)
+	}
+}
+
+func TestIsShardRangeCovered(t *testing.T) {
+	cases := []struct {
+		target  Shard
+		source  Shard
+		covered bool
+	}{
+		{target: Shard{}, source: Shard{Start: []byte("b")}, covered: true},
+		{target: Shard{End: []byte("c")}, source: Shard{Start: []byte("a"), End: []byte("c")}, covered: true},
+		{target: Shard{Start: []byte("a"), End: []byte("c")}, source: Shard{Start: []byte("b"), End: []byte("c")}, covered: true},
+		{target: Shard{Start: []byte("b"), End: []byte("c")}, source: Shard{Start: []byte("a"), End: []byte("b")}, covered: false},
+		{target: Shard{Start: []byte("a"), End: []byte("b")}, source: Shard{Start: []byte("b"), End: []byte("c")}, covered: false},
+		{target: Shard{Start: []byte("a"), End: []byte("c")}, source: Shard{Start: []byte("b")}, covered: false},
+	}
+
+	for i, c := range cases {
+		assert.Equal(t, c.covered, isShardRangeCovered(c.target, c.source), "index %d", i)
+	}
+}
+
+func TestDoExecBecomeWitness(t *testing.T) {
+	f := func(sm *stateMachine) {
+		sm.updateShard(Shard{ID: 100, Start: []byte{1}, End: []byte{5},
+			Replicas: []Replica{{ID: 100}, {ID: 101, StoreID: 2}}})
+
+		ctx := newApplyContext()
+		ctx.index = 1
+		ctx.req = newTestAdminRequestBatch("r1", 0, rpcpb.AdminBecomeWitness, protoc.MustMarshal(&rpcpb.BecomeWitnessRequest{
+			Replica: Replica{ID: 102, StoreID: 3},
+		}))
+		_, err := sm.execAdminRequest(ctx)
+		assert.Error(t, err)
+
+		ctx.req = newTestAdminRequestBatch("r2", 0, rpcpb.AdminBecomeWitness, protoc.MustMarshal(&rpcpb.BecomeWitnessRequest{
+			Replica: Replica{ID: 100},
+		}))
+		resp, err := sm.execAdminRequest(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(resp.Responses))
+		assert.Equal(t, rpcpb.AdminBecomeWitness, ctx.adminResult.adminType)
+		assert.True(t, ctx.adminResult.becomeWitnessResult.replica.IsWitness)
+		assert.True(t, sm.isWitness())
+		assert.Equal(t, uint64(1), sm.getShard().Epoch.ConfigVer)
+		assert.False(t, sm.getShard().Replicas[1].IsWitness)
+
+		metadata, err := sm.dataStorage.GetInitialStates()
+		assert.NoError(t, err)
+		require.Equal(t, 1, len(metadata))
+		assert.True(t, metadata[0].Metadata.Shard.Replicas[0].IsWitness)
+
+		// already a witness
+		_, err = sm.execAdminRequest(ctx)
+		assert.True(t, errors.Is(err, errWitnessReplica))
+
+		// the writes are not executed by the witness
+		ctx = newApplyContext()
+		ctx.index = 2
+		ctx.term = 1
+		ctx.req = rpcpb.RequestBatch{Requests: []rpcpb.Request{{
+			ID: []byte("w1"), Type: rpcpb.Write, CustomType: 1, Key: []byte{2}, Cmd: []byte("v"),
+		}}}
+		wr := sm.execWriteRequest(ctx)
+		require.Equal(t, 1, len(wr.Responses))
+		assert.Equal(t, uint64(2), wr.Responses[0].AppliedIndex)
+		assert.Empty(t, wr.Responses[0].Value)
+	}
+	runSimpleStateMachineTest(t, f, nil)
+}
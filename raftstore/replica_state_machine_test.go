@@ -16,9 +16,11 @@ package raftstore
 import (
 	"testing"
 
+	"github.com/cockroachdb/errors"
 	cpebble "github.com/cockroachdb/pebble"
 	"github.com/fagongzi/util/protoc"
 	"github.com/matrixorigin/matrixcube/components/log"
+	"github.com/matrixorigin/matrixcube/pb/errorpb"
 	"github.com/matrixorigin/matrixcube/pb/metapb"
 	"github.com/matrixorigin/matrixcube/pb/rpcpb"
 	"github.com/matrixorigin/matrixcube/storage"
@@ -223,6 +225,8 @@ type testReplicaResultHandler struct {
 	id           []byte
 	resp         rpcpb.ResponseBatch
 	isConfChange bool
+	// mergeSourceApplied the applied index of the merge source replica
+	mergeSourceApplied uint64
 }
 
 var _ replicaResultHandler = (*testReplicaResultHandler)(nil)
@@ -239,6 +243,10 @@ func (t *testReplicaResultHandler) notifyPendingProposal(id []byte,
 	t.notified++
 }
 
+func (t *testReplicaResultHandler) isMergeSourceApplied(source uint64, index uint64) bool {
+	return t.mergeSourceApplied >= index
+}
+
 func TestStateMachineApplyNoopEntry(t *testing.T) {
 	h := &testReplicaResultHandler{}
 	f := func(sm *stateMachine) {
@@ -259,6 +267,47 @@ func TestStateMachineApplyNoopEntry(t *testing.T) {
 	runSimpleStateMachineTest(t, f, h)
 }
 
+func TestStateMachineApplyMergeWaitsForSource(t *testing.T) {
+	h := &testReplicaResultHandler{}
+	f := func(sm *stateMachine) {
+		sm.updateShard(Shard{ID: 100, Start: []byte{5}, End: []byte{10},
+			Replicas: []Replica{{ID: 100, StoreID: 1}}})
+		source := Shard{ID: 1, Start: []byte{1}, End: []byte{5},
+			Replicas: []Replica{{ID: 2, StoreID: 1}}}
+		batch := newTestAdminRequestBatch("", 0, rpcpb.AdminMergeShard,
+			protoc.MustMarshal(&rpcpb.MergeShardRequest{Source: source, SourceIndex: 10}))
+		// the merge of the changed target is applied with error without waiting
+		stale := newTestAdminRequestBatch("", 0, rpcpb.AdminMergeShard,
+			protoc.MustMarshal(&rpcpb.MergeShardRequest{Source: source, SourceIndex: 10,
+				TargetEpoch: Epoch{Generation: 1}}))
+		stale.Header.ShardID = 100
+		assert.Empty(t, sm.applyCommittedEntries([]raftpb.Entry{
+			{Index: 1, Term: 1, Type: raftpb.EntryNormal, Data: protoc.MustMarshal(&stale)},
+		}))
+
+		batch.Header.ShardID = 100
+		entries := []raftpb.Entry{
+			{Index: 2, Term: 1, Type: raftpb.EntryNormal, Data: protoc.MustMarshal(&batch)},
+			{Index: 3, Term: 1, Type: raftpb.EntryNormal},
+		}
+
+		// the source replica has not applied the prepare merge
+		h.mergeSourceApplied = 9
+		remaining := sm.applyCommittedEntries(entries)
+		assert.Equal(t, entries, remaining)
+		index, _ := sm.getAppliedIndexTerm()
+		assert.Equal(t, uint64(1), index)
+		assert.Equal(t, []byte{5}, sm.getShard().Start)
+
+		h.mergeSourceApplied = 10
+		assert.Empty(t, sm.applyCommittedEntries(remaining))
+		index, _ = sm.getAppliedIndexTerm()
+		assert.Equal(t, uint64(3), index)
+		assert.Equal(t, []byte{1}, sm.getShard().Start)
+	}
+	runSimpleStateMachineTest(t, f, h)
+}
+
 // TODO: add checks to ensure responses are expected
 // TODO: add checks to ensure epoch is checked
 
@@ -331,7 +380,7 @@ func TestStateMachineApplyNormalEntries(t *testing.T) {
 			Key:     key1,
 			CmdType: 2,
 		}
-		readContext.reset(sm.metadataMu.shard, sr)
+		readContext.reset(sm.metadataMu.shard, sr, false)
 		data, err := sm.dataStorage.Read(readContext)
 		assert.NoError(t, err)
 		assert.Equal(t, value1, data)
@@ -340,7 +389,7 @@ func TestStateMachineApplyNormalEntries(t *testing.T) {
 			Key:     key2,
 			CmdType: 2,
 		}
-		readContext.reset(sm.metadataMu.shard, sr)
+		readContext.reset(sm.metadataMu.shard, sr, false)
 		data, err = sm.dataStorage.Read(readContext)
 		assert.NoError(t, err)
 		assert.Equal(t, value2, data)
@@ -348,6 +397,112 @@ func TestStateMachineApplyNormalEntries(t *testing.T) {
 	runSimpleStateMachineTest(t, f, h)
 }
 
+func TestStateMachineApplyBatchWrite(t *testing.T) {
+	h := &testReplicaResultHandler{}
+	f := func(sm *stateMachine) {
+		batch := rpcpb.RequestBatch{
+			Header: rpcpb.RequestBatchHeader{
+				ID:      []byte{0x1, 0x2, 0x3},
+				ShardID: 1,
+			},
+			Requests: []rpcpb.Request{
+				{
+					ID:         []byte{100},
+					Type:       rpcpb.Write,
+					Key:        []byte("k1"),
+					CustomType: 1,
+					Cmd:        []byte("v1"),
+				},
+				{
+					ID:   []byte{200},
+					Type: rpcpb.Write,
+					WriteOps: []rpcpb.WriteOp{
+						{CustomType: 1, Key: []byte("k2"), Cmd: []byte("v2")},
+						{CustomType: 1, Key: []byte("k3"), Cmd: []byte("v3")},
+					},
+				},
+			},
+		}
+		sm.applyCommittedEntries([]raftpb.Entry{{
+			Index: 1,
+			Term:  1,
+			Type:  raftpb.EntryNormal,
+			Data:  protoc.MustMarshal(&batch),
+		}})
+
+		require.Equal(t, 2, len(h.resp.Responses))
+		assert.Equal(t, []byte("OK"), h.resp.Responses[0].Value)
+		assert.Empty(t, h.resp.Responses[0].WriteOpValues)
+		assert.Empty(t, h.resp.Responses[1].Value)
+		assert.Equal(t, [][]byte{[]byte("OK"), []byte("OK")}, h.resp.Responses[1].WriteOpValues)
+		assert.Equal(t, uint64(1), h.resp.Responses[1].AppliedIndex)
+
+		readContext := newReadContext()
+		for _, k := range []string{"k1", "k2", "k3"} {
+			readContext.reset(sm.metadataMu.shard, storage.Request{Key: []byte(k), CmdType: 2}, false)
+			data, err := sm.dataStorage.Read(readContext)
+			assert.NoError(t, err)
+			assert.Equal(t, []byte("v"+k[1:]), data)
+		}
+	}
+	runSimpleStateMachineTest(t, f, h)
+}
+
+func TestStateMachineApplyWithShardACL(t *testing.T) {
+	h := &testReplicaResultHandler{}
+	f := func(sm *stateMachine) {
+		sm.updateShard(Shard{ID: 100, ACL: &metapb.ShardACL{Owner: "t1"}})
+		batch := rpcpb.RequestBatch{
+			Header: rpcpb.RequestBatchHeader{
+				ID:      []byte{0x1, 0x2, 0x3},
+				ShardID: 100,
+			},
+			Requests: []rpcpb.Request{
+				{
+					ID:         []byte{100},
+					Type:       rpcpb.Write,
+					Key:        []byte("k1"),
+					CustomType: 1,
+					Cmd:        []byte("v1"),
+					Tenant:     "t1",
+				},
+				{
+					ID:         []byte{200},
+					Type:       rpcpb.Write,
+					Key:        []byte("k2"),
+					CustomType: 1,
+					Cmd:        []byte("v2"),
+					Tenant:     "t2",
+				},
+			},
+		}
+		sm.applyCommittedEntries([]raftpb.Entry{{
+			Index: 1,
+			Term:  1,
+			Type:  raftpb.EntryNormal,
+			Data:  protoc.MustMarshal(&batch),
+		}})
+
+		require.Equal(t, 2, len(h.resp.Responses))
+		assert.Equal(t, []byte("OK"), h.resp.Responses[0].Value)
+		assert.False(t, errorpb.HasError(h.resp.Responses[0].Error))
+		require.NotNil(t, h.resp.Responses[1].Error.AccessDenied)
+		assert.Equal(t, "t2", h.resp.Responses[1].Error.AccessDenied.Tenant)
+		assert.Equal(t, uint64(1), h.resp.Responses[1].AppliedIndex)
+
+		readContext := newReadContext()
+		readContext.reset(sm.metadataMu.shard, storage.Request{Key: []byte("k1"), CmdType: 2}, false)
+		data, err := sm.dataStorage.Read(readContext)
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("v1"), data)
+		readContext.reset(sm.metadataMu.shard, storage.Request{Key: []byte("k2"), CmdType: 2}, false)
+		data, err = sm.dataStorage.Read(readContext)
+		assert.NoError(t, err)
+		assert.Empty(t, data)
+	}
+	runSimpleStateMachineTest(t, f, h)
+}
+
 func TestStateMachineApplyConfigChange(t *testing.T) {
 	h := &testReplicaResultHandler{}
 	f := func(sm *stateMachine) {
@@ -394,6 +549,135 @@ func TestStateMachineApplyConfigChange(t *testing.T) {
 	runSimpleStateMachineTest(t, f, h)
 }
 
+func TestStateMachineApplyConfigChangeV2(t *testing.T) {
+	h := &testReplicaResultHandler{}
+	f := func(sm *stateMachine) {
+		sm.updateShard(Shard{
+			ID: 1,
+			Replicas: []Replica{
+				{ID: 1, StoreID: 1, Role: metapb.ReplicaRole_Voter},
+				{ID: 2, StoreID: 2, Role: metapb.ReplicaRole_Voter},
+				{ID: 3, StoreID: 3, Role: metapb.ReplicaRole_Learner},
+			},
+		})
+		apply := func(index uint64, changes []rpcpb.ConfigChangeRequest) {
+			batch := newTestAdminRequestBatch(string([]byte{0x1, 0x2, byte(index)}), 0,
+				rpcpb.AdminConfigChangeV2,
+				protoc.MustMarshal(&rpcpb.ConfigChangeV2Request{Changes: changes}))
+			batch.Header.ShardID = 1
+			batch.Requests[0].Epoch = sm.getShard().Epoch
+			cc := raftpb.ConfChangeV2{Context: protoc.MustMarshal(&batch)}
+			entry := raftpb.Entry{
+				Index: index,
+				Term:  1,
+				Type:  raftpb.EntryConfChangeV2,
+				Data:  protoc.MustMarshal(&cc),
+			}
+			sm.applyCommittedEntries([]raftpb.Entry{entry})
+			assert.Equal(t, index, h.appliedIndex)
+			assert.Equal(t, true, h.isConfChange)
+			require.Equal(t, 1, len(h.resp.Responses))
+		}
+
+		// promote the learner 3 and demote the voter 2 atomically
+		apply(1, []rpcpb.ConfigChangeRequest{
+			{
+				ChangeType: metapb.ConfigChangeType_AddNode,
+				Replica:    Replica{ID: 3, StoreID: 3, Role: metapb.ReplicaRole_Voter},
+			},
+			{
+				ChangeType: metapb.ConfigChangeType_AddLearnerNode,
+				Replica:    Replica{ID: 2, StoreID: 2, Role: metapb.ReplicaRole_Learner},
+			},
+		})
+		shard := sm.getShard()
+		assert.Equal(t, uint64(2), shard.Epoch.ConfigVer)
+		assert.Equal(t, metapb.ReplicaRole_Voter, findReplica(shard, 1).Role)
+		assert.Equal(t, metapb.ReplicaRole_DemotingVoter, findReplica(shard, 2).Role)
+		assert.Equal(t, metapb.ReplicaRole_IncomingVoter, findReplica(shard, 3).Role)
+
+		apply(2, nil)
+		shard = sm.getShard()
+		assert.Equal(t, uint64(4), shard.Epoch.ConfigVer)
+		assert.Equal(t, metapb.ReplicaRole_Voter, findReplica(shard, 1).Role)
+		assert.Equal(t, metapb.ReplicaRole_Learner, findReplica(shard, 2).Role)
+		assert.Equal(t, metapb.ReplicaRole_Voter, findReplica(shard, 3).Role)
+	}
+	runSimpleStateMachineTest(t, f, h)
+}
+
+func TestStateMachineEnterAndLeaveJoint(t *testing.T) {
+	f := func(sm *stateMachine) {
+		shard := Shard{
+			ID: 1,
+			Replicas: []Replica{
+				{ID: 1, StoreID: 1, Role: metapb.ReplicaRole_Voter},
+				{ID: 2, StoreID: 2, Role: metapb.ReplicaRole_Learner},
+			},
+		}
+		assert.True(t, errors.Is(sm.leaveJoint(&shard), ErrNotInJointState))
+		assert.True(t, errors.Is(sm.enterJoint(&shard, []rpcpb.ConfigChangeRequest{
+			{
+				ChangeType: metapb.ConfigChangeType_RemoveNode,
+				Replica:    Replica{ID: 1, StoreID: 1},
+			},
+		}), ErrRemoveVoter))
+
+		shard = Shard{
+			ID: 1,
+			Replicas: []Replica{
+				{ID: 1, StoreID: 1, Role: metapb.ReplicaRole_Voter},
+				{ID: 2, StoreID: 2, Role: metapb.ReplicaRole_Learner},
+			},
+		}
+		require.NoError(t, sm.enterJoint(&shard, []rpcpb.ConfigChangeRequest{
+			{
+				ChangeType: metapb.ConfigChangeType_AddNode,
+				Replica:    Replica{ID: 3, StoreID: 3, Role: metapb.ReplicaRole_Voter},
+			},
+			{
+				ChangeType: metapb.ConfigChangeType_RemoveNode,
+				Replica:    Replica{ID: 2, StoreID: 2},
+			},
+		}))
+		assert.Equal(t, uint64(2), shard.Epoch.ConfigVer)
+		require.Equal(t, 2, len(shard.Replicas))
+		assert.Nil(t, findReplica(shard, 2))
+		assert.Equal(t, metapb.ReplicaRole_IncomingVoter, findReplica(shard, 3).Role)
+
+		require.NoError(t, sm.leaveJoint(&shard))
+		assert.Equal(t, uint64(3), shard.Epoch.ConfigVer)
+		assert.Equal(t, metapb.ReplicaRole_Voter, findReplica(shard, 3).Role)
+	}
+	runSimpleStateMachineTest(t, f, nil)
+}
+
+func TestGetConfState(t *testing.T) {
+	cs := getConfState(Shard{
+		Replicas: []Replica{
+			{ID: 1, Role: metapb.ReplicaRole_Voter},
+			{ID: 2, Role: metapb.ReplicaRole_Learner},
+		},
+	})
+	assert.Equal(t, []uint64{1}, cs.Voters)
+	assert.Equal(t, []uint64{2}, cs.Learners)
+	assert.Empty(t, cs.VotersOutgoing)
+	assert.Empty(t, cs.LearnersNext)
+
+	cs = getConfState(Shard{
+		Replicas: []Replica{
+			{ID: 1, Role: metapb.ReplicaRole_Voter},
+			{ID: 2, Role: metapb.ReplicaRole_DemotingVoter},
+			{ID: 3, Role: metapb.ReplicaRole_IncomingVoter},
+			{ID: 4, Role: metapb.ReplicaRole_Learner},
+		},
+	})
+	assert.Equal(t, []uint64{1, 3}, cs.Voters)
+	assert.Equal(t, []uint64{1, 2}, cs.VotersOutgoing)
+	assert.Equal(t, []uint64{4}, cs.Learners)
+	assert.Equal(t, []uint64{2}, cs.LearnersNext)
+}
+
 func TestStateMachineRejectsStaleEpochEntries(t *testing.T) {
 	h := &testReplicaResultHandler{}
 	f := func(sm *stateMachine) {
@@ -422,10 +706,25 @@ func TestStateMachineRejectsStaleEpochEntries(t *testing.T) {
 		assert.Equal(t, true, h.isConfChange)
 		require.Equal(t, 0, len(h.resp.Responses))
 		assert.Equal(t, "stale command", h.resp.Header.Error.Message)
+		require.Equal(t, 1, len(sm.applyErrors))
+		assert.Equal(t, uint64(1), sm.applyErrors[0].Index)
+		assert.Equal(t, errStaleEpoch.Error(), sm.applyErrors[0].Error)
 	}
 	runSimpleStateMachineTest(t, f, h)
 }
 
+func TestStateMachineKeepsRecentApplyErrors(t *testing.T) {
+	f := func(sm *stateMachine) {
+		for i := uint64(1); i <= maxDiagnosticApplyErrors+2; i++ {
+			sm.addApplyError(i, errStaleEpoch)
+		}
+		require.Equal(t, maxDiagnosticApplyErrors, len(sm.applyErrors))
+		assert.Equal(t, uint64(3), sm.applyErrors[0].Index)
+		assert.Equal(t, uint64(maxDiagnosticApplyErrors+2), sm.applyErrors[maxDiagnosticApplyErrors-1].Index)
+	}
+	runSimpleStateMachineTest(t, f, nil)
+}
+
 func TestStateMachineUpdatesAppliedIndexAfterSkippingEntries(t *testing.T) {
 	h := &testReplicaResultHandler{}
 	f := func(sm *stateMachine) {
@@ -497,3 +796,73 @@ func TestStateMachineCannotApplyIfRemovedFlagSetted(t *testing.T) {
 	}
 	runSimpleStateMachineTest(t, f, h)
 }
+
+func TestStateMachineFence(t *testing.T) {
+	h := &testReplicaResultHandler{}
+	f := func(sm *stateMachine) {
+		require.NoError(t, sm.saveShardMetedata(0, 0, sm.getShard(), metapb.ReplicaState_Normal))
+		newBarrierEntry := func(index uint64, req rpcpb.BarrierRequest) raftpb.Entry {
+			batch := newTestAdminRequestBatch("", 0, rpcpb.AdminBarrier, protoc.MustMarshal(&req))
+			batch.Header.ShardID = 100
+			return raftpb.Entry{Index: index, Term: 1, Data: protoc.MustMarshal(&batch)}
+		}
+		newWriteEntry := func(index uint64) raftpb.Entry {
+			batch := rpcpb.RequestBatch{
+				Header: rpcpb.RequestBatchHeader{ID: []byte{byte(index)}, ShardID: 100},
+				Requests: []rpcpb.Request{
+					{ID: []byte{byte(index)}, Type: rpcpb.Write, Key: []byte("k"), CustomType: 1, Cmd: []byte("v")},
+				},
+			}
+			return raftpb.Entry{Index: index, Term: 1, Data: protoc.MustMarshal(&batch)}
+		}
+
+		// the shard must be fenced to be backed up
+		sm.applyCommittedEntries([]raftpb.Entry{newBarrierEntry(1, rpcpb.BarrierRequest{BackupPath: "backup"})})
+		assert.Equal(t, errBackupNotFenced.Error(), h.resp.Header.Error.Message)
+
+		sm.applyCommittedEntries([]raftpb.Entry{newBarrierEntry(2, rpcpb.BarrierRequest{Fence: true, BackupPath: "backup"})})
+		require.Equal(t, 1, len(h.resp.Responses))
+		var resp rpcpb.BarrierResponse
+		protoc.MustUnmarshal(&resp, h.resp.Responses[0].Value)
+		assert.Equal(t, uint64(2), resp.Index)
+		assert.Equal(t, uint64(100), resp.Shard.ID)
+		assert.Equal(t, "backup/100-100", resp.BackupPath)
+		assert.Equal(t, uint64(2), sm.getFenceIndex())
+		metadata, err := sm.dataStorage.GetInitialStates()
+		require.NoError(t, err)
+		require.Equal(t, 1, len(metadata))
+		assert.Equal(t, uint64(2), metadata[0].Metadata.FenceIndex)
+
+		// the writes are rejected once fenced
+		sm.applyCommittedEntries([]raftpb.Entry{newWriteEntry(3)})
+		require.NotNil(t, h.resp.Header.Error.ShardUnavailable)
+		assert.Equal(t, errShardFenced.Error(), sm.applyErrors[len(sm.applyErrors)-1].Error)
+
+		sm.applyCommittedEntries([]raftpb.Entry{newBarrierEntry(4, rpcpb.BarrierRequest{Unfence: true})})
+		assert.Equal(t, uint64(0), sm.getFenceIndex())
+		sm.applyCommittedEntries([]raftpb.Entry{newWriteEntry(5)})
+		assert.Nil(t, h.resp.Header.Error.ShardUnavailable)
+		require.Equal(t, 1, len(h.resp.Responses))
+		assert.Equal(t, []byte("OK"), h.resp.Responses[0].Value)
+
+		// the migrated shard rejects the writes with the GroupMigrated error
+		sm.applyCommittedEntries([]raftpb.Entry{newBarrierEntry(6, rpcpb.BarrierRequest{Fence: true, Migrated: true})})
+		assert.True(t, sm.isMigrated())
+		metadata, err = sm.dataStorage.GetInitialStates()
+		require.NoError(t, err)
+		assert.True(t, metadata[0].Metadata.Migrated)
+		sm.applyCommittedEntries([]raftpb.Entry{newWriteEntry(7)})
+		require.NotNil(t, h.resp.Header.Error.GroupMigrated)
+		sm.applyCommittedEntries([]raftpb.Entry{newBarrierEntry(8, rpcpb.BarrierRequest{Unfence: true})})
+		assert.False(t, sm.isMigrated())
+
+		sm.initLocalState(metapb.ShardMetadata{LogIndex: 10,
+			Metadata: metapb.ShardLocalState{MergeTarget: 2, FenceIndex: 5, Migrated: true}})
+		assert.Equal(t, uint64(5), sm.getFenceIndex())
+		assert.True(t, sm.isMigrated())
+		target, index := sm.getMergeTarget()
+		assert.Equal(t, uint64(2), target)
+		assert.Equal(t, uint64(10), index)
+	}
+	runSimpleStateMachineTest(t, f, h)
+}
@@ -29,13 +29,24 @@ type replicaStats struct {
 	deleteKeysHint       uint64
 	approximateSize      uint64
 	approximateKeys      uint64
+	// reconciledAt is the last time the approximate size and keys are
+	// reconciled with the values scanned from the storage.
+	reconciledAt time.Time
 }
 
 func newReplicaStats() *replicaStats {
-	return &replicaStats{}
+	return &replicaStats{reconciledAt: time.Now()}
 }
 
-func (rs *replicaStats) heartbeatState() metapb.ShardStats {
+// reconcile replaces the approximate size and keys maintained at apply time
+// with the values scanned from the storage.
+func (rs *replicaStats) reconcile(size, keys uint64) {
+	rs.approximateSize = size
+	rs.approximateKeys = keys
+	rs.reconciledAt = time.Now()
+}
+
+func (rs *replicaStats) heartbeatState(resolvedTS uint64) metapb.ShardStats {
 	now := uint64(time.Now().Unix())
 	stats := metapb.ShardStats{
 		WrittenBytes:    rs.writtenBytes,
@@ -48,6 +59,7 @@ func (rs *replicaStats) heartbeatState() metapb.ShardStats {
 			Start: rs.prophetHeartbeatTime,
 			End:   uint64(time.Now().Unix()),
 		},
+		ResolvedTS: resolvedTS,
 	}
 	rs.prophetHeartbeatTime = now
 	return stats
@@ -15,14 +15,20 @@ package raftstore
 
 import (
 	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.etcd.io/etcd/raft/v3"
 	"go.etcd.io/etcd/raft/v3/raftpb"
 
+	pconfig "github.com/matrixorigin/matrixcube/components/prophet/config"
+	"github.com/matrixorigin/matrixcube/config"
+	"github.com/matrixorigin/matrixcube/pb/errorpb"
 	"github.com/matrixorigin/matrixcube/pb/metapb"
+	"github.com/matrixorigin/matrixcube/pb/rpcpb"
 	"github.com/matrixorigin/matrixcube/util/leaktest"
 	"github.com/matrixorigin/matrixcube/util/stop"
 )
@@ -95,3 +101,77 @@ func newTestReplica(shard Shard, peer Replica, s *store) *replica {
 	pr.setStarted()
 	return pr
 }
+
+func TestTryLeaseReadWithStaleEpoch(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	s, cancel := newTestStore(t)
+	defer cancel()
+
+	pr := newTestReplica(Shard{ID: 1, Epoch: metapb.ShardEpoch{Generation: 2}}, Replica{ID: 1}, s)
+	pr.leaderID = 1
+	pr.lease = newReadLease(time.Minute)
+	pr.lease.renew(1, time.Now())
+	pr.setAppliedIndex(1)
+	assert.True(t, pr.lease.valid(time.Now()))
+
+	// stale epoch reads and non-read requests take the normal path
+	assert.False(t, pr.tryLeaseRead(rpcpb.Request{Type: rpcpb.Read,
+		Epoch: metapb.ShardEpoch{Generation: 1}}))
+	assert.False(t, pr.tryLeaseRead(rpcpb.Request{Type: rpcpb.Write,
+		Epoch: metapb.ShardEpoch{Generation: 2}}))
+	// followers never serve lease reads
+	pr.leaderID = 2
+	assert.False(t, pr.tryLeaseRead(rpcpb.Request{Type: rpcpb.Read,
+		Epoch: metapb.ShardEpoch{Generation: 2}}))
+}
+
+func TestOnReqWhileRecovering(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	s, cancel := newTestStore(t)
+	defer cancel()
+
+	pr := newTestReplica(Shard{ID: 1}, Replica{ID: 1}, s)
+	pr.cfg.Snapshot.RecoveringPolicy = config.RecoveringQueue
+	pr.cfg.Snapshot.MaxRecoveringRequests = 1
+	atomic.StoreInt64(&pr.lastRecoverCost, int64(time.Hour))
+	pr.startRecovering()
+
+	var rsp rpcpb.ResponseBatch
+	cb := func(v rpcpb.ResponseBatch) { rsp = v }
+	assert.NoError(t, pr.onReq(rpcpb.Request{Type: rpcpb.Read}, cb))
+	assert.Equal(t, int64(1), pr.requests.Len())
+	assert.NoError(t, pr.onReq(rpcpb.Request{Type: rpcpb.Read}, cb))
+	assert.Equal(t, int64(1), pr.requests.Len())
+	assert.NotNil(t, rsp.Header.Error.ShardRecovering)
+	assert.Equal(t, uint64(1), rsp.Header.Error.ShardRecovering.ShardID)
+	assert.True(t, rsp.Header.Error.ShardRecovering.EstimatedMillis > 0)
+	assert.True(t, errorpb.Retryable(rsp.Header.Error))
+
+	rsp = rpcpb.ResponseBatch{}
+	pr.cfg.Snapshot.RecoveringPolicy = config.RecoveringReject
+	pr.cfg.Snapshot.MaxRecoveringRequests = 10
+	assert.NoError(t, pr.onReq(rpcpb.Request{Type: rpcpb.Write}, cb))
+	assert.Equal(t, int64(1), pr.requests.Len())
+	assert.NotNil(t, rsp.Header.Error.ShardRecovering)
+
+	pr.endRecovering()
+	recovering, _ := pr.recovering(time.Now())
+	assert.False(t, recovering)
+	assert.True(t, atomic.LoadInt64(&pr.lastRecoverCost) < int64(time.Hour))
+}
+
+func TestHasLowerLeaderPriority(t *testing.T) {
+	pr := &replica{}
+	assert.False(t, pr.hasLowerLeaderPriority(0))
+
+	pr.cfg.Labels = [][]string{{"disk", "hdd"}}
+	pr.cfg.Prophet.Replication.LeaderPriorities = []pconfig.LeaderPriority{
+		{Group: 1, Key: "disk", Value: "ssd", Priority: 10},
+		{Group: 2, Key: "disk", Value: "hdd", Priority: 10},
+	}
+	assert.False(t, pr.hasLowerLeaderPriority(0))
+	assert.True(t, pr.hasLowerLeaderPriority(1))
+	assert.False(t, pr.hasLowerLeaderPriority(2))
+}
@@ -0,0 +1,162 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package raftstore
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/matrixorigin/matrixcube/storage"
+	"go.uber.org/zap"
+)
+
+const (
+	// hotKeysCapacityFactor the hot keys tracker keeps the counts of up to
+	// factor * max warm-up keys keys
+	hotKeysCapacityFactor = 4
+)
+
+// hotKeys tracks the most frequently read keys of the replica using the space
+// saving algorithm, a new key replaces the key with the min count once the
+// tracker is full, so the hot keys stay in the tracker. It is accessed by the
+// read goroutines and the event worker.
+type hotKeys struct {
+	sync.Mutex
+	counts map[string]uint64
+}
+
+func (h *hotKeys) record(key []byte, capacity int) {
+	h.Lock()
+	defer h.Unlock()
+	if h.counts == nil {
+		h.counts = make(map[string]uint64, capacity)
+	}
+	if _, ok := h.counts[string(key)]; ok {
+		h.counts[string(key)]++
+		return
+	}
+	if len(h.counts) < capacity {
+		h.counts[string(key)] = 1
+		return
+	}
+
+	var minKey string
+	var min uint64
+	for k, c := range h.counts {
+		if min == 0 || c < min {
+			minKey, min = k, c
+		}
+	}
+	delete(h.counts, minKey)
+	h.counts[string(key)] = min + 1
+}
+
+// digest returns the up to n hottest keys, hottest first.
+func (h *hotKeys) digest(n int) [][]byte {
+	h.Lock()
+	defer h.Unlock()
+	keys := make([]string, 0, len(h.counts))
+	for k := range h.counts {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		ci, cj := h.counts[keys[i]], h.counts[keys[j]]
+		if ci != cj {
+			return ci > cj
+		}
+		return keys[i] < keys[j]
+	})
+	if len(keys) > n {
+		keys = keys[:n]
+	}
+	values := make([][]byte, 0, len(keys))
+	for _, k := range keys {
+		values = append(values, []byte(k))
+	}
+	return values
+}
+
+func (pr *replica) recordHotKey(key []byte) {
+	if pr.cfg.Raft.EnableLeaderWarmup {
+		pr.hotKeys.record(key, int(pr.cfg.Raft.LeaderWarmupKeys)*hotKeysCapacityFactor)
+	}
+}
+
+// getWarmupKeys returns the hot keys shipped to the leader transfer target.
+func (pr *replica) getWarmupKeys() [][]byte {
+	if !pr.cfg.Raft.EnableLeaderWarmup {
+		return nil
+	}
+	return pr.hotKeys.digest(int(pr.cfg.Raft.LeaderWarmupKeys))
+}
+
+// maybeStartLeaderWarmup prefetches the hot keys received from the previous
+// leader before the leadership of the term is announced, true is returned if
+// the warm-up started, the leadership is announced once the prefetching is
+// completed or the warm-up timeout expired.
+func (pr *replica) maybeStartLeaderWarmup(term uint64) bool {
+	keys := pr.warmupKeys
+	pr.warmupKeys = nil
+	if !pr.cfg.Raft.EnableLeaderWarmup || len(keys) == 0 {
+		return false
+	}
+	ws, ok := pr.sm.dataStorage.(storage.WarmupStorage)
+	if !ok {
+		return false
+	}
+
+	done := func() {
+		pr.addAction(action{actionType: leaderWarmupDoneAction, term: term})
+	}
+	timer := time.AfterFunc(pr.cfg.Raft.LeaderWarmupTimeout.Duration, done)
+	if err := pr.readStopper.RunTask(context.Background(), func(ctx context.Context) {
+		start := time.Now()
+		if err := ws.PrefetchKeys(keys); err != nil {
+			pr.logger.Error("fail to prefetch the warm-up keys",
+				zap.Error(err))
+		}
+		if timer.Stop() {
+			pr.logger.Info("leader warm-up completed",
+				zap.Int("keys", len(keys)),
+				zap.Duration("cost", time.Since(start)))
+			done()
+		}
+	}); err != nil {
+		timer.Stop()
+		return false
+	}
+	pr.warmupTerm = term
+	return true
+}
+
+// finishLeaderWarmup announces the leadership once the warm-up of the term is
+// completed, nothing to do if the replica is no longer the leader of the term.
+func (pr *replica) finishLeaderWarmup(term uint64) {
+	if pr.warmupTerm == 0 || pr.warmupTerm != term {
+		return
+	}
+	pr.warmupTerm = 0
+	if !pr.isLeader() || pr.rn.BasicStatus().Term != term {
+		return
+	}
+	pr.announceLeadership()
+}
+
+// isWarmingUp returns true if the leader is warming up and its leadership has
+// not been announced yet.
+func (pr *replica) isWarmingUp() bool {
+	return pr.warmupTerm > 0
+}
@@ -0,0 +1,140 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package raftstore
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/matrixorigin/matrixcube/components/log"
+	"github.com/matrixorigin/matrixcube/storage"
+	"github.com/matrixorigin/matrixcube/util/stop"
+	"github.com/matrixorigin/matrixcube/util/task"
+)
+
+type testWarmupStorage struct {
+	storage.DataStorage
+	keys  [][]byte
+	block chan struct{}
+}
+
+func (s *testWarmupStorage) PrefetchKeys(keys [][]byte) error {
+	if s.block != nil {
+		<-s.block
+	}
+	s.keys = keys
+	return nil
+}
+
+func TestHotKeys(t *testing.T) {
+	var h hotKeys
+	assert.Empty(t, h.digest(2))
+
+	for i := 0; i < 3; i++ {
+		h.record([]byte("a"), 2)
+	}
+	h.record([]byte("b"), 2)
+	h.record([]byte("b"), 2)
+	assert.Equal(t, [][]byte{[]byte("a"), []byte("b")}, h.digest(2))
+	assert.Equal(t, [][]byte{[]byte("a")}, h.digest(1))
+
+	// c replaces b which has the min count, and inherits the count of b
+	h.record([]byte("c"), 2)
+	assert.Equal(t, 2, len(h.counts))
+	assert.Equal(t, uint64(3), h.counts["c"])
+	assert.Equal(t, [][]byte{[]byte("a"), []byte("c")}, h.digest(2))
+}
+
+func newTestWarmupReplica(enable bool, ds storage.DataStorage) *replica {
+	logger := log.GetPanicZapLogger()
+	pr := &replica{
+		startedC:    make(chan struct{}),
+		store:       &store{workerPool: newWorkerPool(logger, nil, nil, 1)},
+		logger:      logger,
+		actions:     task.New(32),
+		readStopper: stop.NewStopper("TestLeaderWarmup"),
+		sm:          &stateMachine{dataStorage: ds},
+	}
+	pr.setStarted()
+	pr.cfg.Raft.EnableLeaderWarmup = enable
+	pr.cfg.Raft.LeaderWarmupKeys = 2
+	pr.cfg.Raft.LeaderWarmupTimeout.Duration = time.Second
+	return pr
+}
+
+func TestLeaderWarmup(t *testing.T) {
+	keys := [][]byte{[]byte("a"), []byte("b")}
+
+	pr := newTestWarmupReplica(false, &testWarmupStorage{})
+	defer pr.readStopper.Stop()
+	pr.warmupKeys = keys
+	assert.False(t, pr.maybeStartLeaderWarmup(1))
+	assert.Empty(t, pr.getWarmupKeys())
+
+	ds := &testWarmupStorage{}
+	pr = newTestWarmupReplica(true, ds)
+	defer pr.readStopper.Stop()
+	assert.False(t, pr.maybeStartLeaderWarmup(1))
+	pr.recordHotKey([]byte("a"))
+	assert.Equal(t, [][]byte{[]byte("a")}, pr.getWarmupKeys())
+
+	pr.warmupKeys = keys
+	assert.True(t, pr.maybeStartLeaderWarmup(2))
+	assert.Nil(t, pr.warmupKeys)
+	assert.True(t, pr.isWarmingUp())
+	act := waitTestWarmupDone(t, pr)
+	assert.Equal(t, uint64(2), act.term)
+	assert.Equal(t, keys, ds.keys)
+
+	// the done action of another term is ignored
+	pr.finishLeaderWarmup(1)
+	assert.True(t, pr.isWarmingUp())
+}
+
+func TestLeaderWarmupWithUnsupportedStorage(t *testing.T) {
+	pr := newTestWarmupReplica(true, nil)
+	defer pr.readStopper.Stop()
+	pr.warmupKeys = [][]byte{[]byte("a")}
+	assert.False(t, pr.maybeStartLeaderWarmup(1))
+	assert.False(t, pr.isWarmingUp())
+}
+
+func TestLeaderWarmupTimeout(t *testing.T) {
+	ds := &testWarmupStorage{block: make(chan struct{})}
+	pr := newTestWarmupReplica(true, ds)
+	defer pr.readStopper.Stop()
+	defer close(ds.block)
+	pr.cfg.Raft.LeaderWarmupTimeout.Duration = 10 * time.Millisecond
+	pr.warmupKeys = [][]byte{[]byte("a")}
+
+	assert.True(t, pr.maybeStartLeaderWarmup(1))
+	act := waitTestWarmupDone(t, pr)
+	assert.Equal(t, uint64(1), act.term)
+	assert.Empty(t, ds.keys)
+}
+
+func waitTestWarmupDone(t *testing.T, pr *replica) action {
+	for i := 0; i < 100; i++ {
+		if v, err := pr.actions.Peek(); err == nil {
+			act := v.(action)
+			assert.Equal(t, leaderWarmupDoneAction, act.actionType)
+			return act
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.FailNow(t, "wait leader warm-up done timeout")
+	return action{}
+}
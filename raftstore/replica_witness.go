@@ -0,0 +1,240 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package raftstore
+
+import (
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/fagongzi/util/protoc"
+	"go.etcd.io/etcd/raft/v3/raftpb"
+	"go.uber.org/zap"
+
+	"github.com/matrixorigin/matrixcube/components/log"
+	"github.com/matrixorigin/matrixcube/metric"
+	"github.com/matrixorigin/matrixcube/pb/metapb"
+	"github.com/matrixorigin/matrixcube/pb/rpcpb"
+	"github.com/matrixorigin/matrixcube/storage"
+)
+
+// Witness replicas
+//
+// A witness replica stores the raft log but not the shard data, it votes in
+// the elections and counts in the quorum of the log replication, so a shard of
+// 2 full replicas and 1 witness tolerates one failure as a shard of 3 full
+// replicas does, at the cost of 2 copies of the data.
+//
+// 1. The witness only advances the applied index when applying the writes, the
+//    admin requests are applied as usual to keep the shard metadata.
+// 2. The snapshot sent to the witness only contains the shard metadata.
+// 3. The witness never serves the requests and never campaigns, its vote
+//    requests are dropped. A replica demoted while being the leader keeps
+//    transferring the leadership to a full replica until it succeeds, and it
+//    never creates the snapshots for the followers in the meantime.
+// 4. A full replica is demoted to a witness by the AdminBecomeWitness request,
+//    the local shard data is dropped once it is applied. A witness can not be
+//    promoted in place as it has no data, it is replaced by a new full replica.
+
+var (
+	errWitnessReplica  = errors.New("replica is already a witness")
+	errWitnessSnapshot = errors.New("witness can not create snapshot")
+)
+
+// isWitness returns true if the replica is a witness.
+func (pr *replica) isWitness() bool {
+	r := findReplica(pr.getShard(), pr.storeID)
+	return r != nil && r.IsWitness
+}
+
+// isWitnessReplica returns true if the replica of the shard is a witness.
+func isWitnessReplica(shard Shard, replicaID uint64) bool {
+	for _, r := range shard.Replicas {
+		if r.ID == replicaID {
+			return r.IsWitness
+		}
+	}
+	return false
+}
+
+// transferWitnessLeadership transfers the leadership won by the witness to the
+// most up to date full voter, the witness has no data to serve the requests.
+func (pr *replica) transferWitnessLeadership() {
+	progress := pr.rn.Status().Progress
+	var target Replica
+	var match uint64
+	for _, r := range pr.getShard().Replicas {
+		if r.ID == pr.replicaID || r.IsWitness ||
+			r.Role != metapb.ReplicaRole_Voter {
+			continue
+		}
+		if p, ok := progress[r.ID]; ok && (target.ID == 0 || p.Match > match) {
+			target, match = r, p.Match
+		}
+	}
+	if target.ID == 0 {
+		pr.logger.Warn("witness leader has no full voter to transfer leadership")
+		return
+	}
+	pr.logger.Info("transfer leadership of witness",
+		log.ReplicaField("to", target))
+	pr.doTransferLeader(target)
+}
+
+// maybeTransferWitnessLeadership retries transferring the leadership of the
+// witness until it succeeds. The raft aborts the transfer not completed in an
+// election timeout, the next tick starts another one.
+func (pr *replica) maybeTransferWitnessLeadership() {
+	if !pr.isLeader() || !pr.isWitness() ||
+		pr.rn.BasicStatus().LeadTransferee != 0 {
+		return
+	}
+	pr.transferWitnessLeadership()
+}
+
+// checkWitnessProposal rejects the read and write requests on the witness, the
+// clients retry them on the leader elected after the leadership transfer.
+func (pr *replica) checkWitnessProposal(c batch) bool {
+	if c.requestBatch.IsAdmin() || !pr.isWitness() {
+		return true
+	}
+	c.respNotLeader(pr.shardID, Replica{})
+	return false
+}
+
+// createWitnessSnapshot creates the snapshot sent to the witness replica, it
+// only contains the shard metadata at the applied index.
+func (pr *replica) createWitnessSnapshot() (raftpb.Snapshot, bool) {
+	start := time.Now()
+	index, term := pr.sm.getAppliedIndexTerm()
+	md := pr.sm.newShardMetadata(index, pr.getShard(), metapb.ReplicaState_Normal)
+	logger := pr.logger.With(zap.Uint64("snapshot-index", index))
+
+	ss, ssenv, err := pr.snapshotter.saveWitness(md, pr.sm.getConfState(), index, term)
+	if err != nil {
+		ssenv.MustRemoveTempDir()
+		logger.Error("failed to save witness snapshot",
+			zap.Error(err))
+		return raftpb.Snapshot{}, false
+	}
+	if err := pr.snapshotter.commit(ss, ssenv); err != nil {
+		ssenv.MustRemoveTempDir()
+		logger.Error("failed to commit saved witness snapshot",
+			zap.Error(err))
+		return raftpb.Snapshot{}, false
+	}
+	metric.ObserveSnapshotBuildingDuration(start)
+	logger.Info("witness snapshot created")
+	return ss, true
+}
+
+// isWitness returns true if the local replica is a witness.
+func (d *stateMachine) isWitness() bool {
+	r := findReplica(d.getShard(), d.replica.StoreID)
+	return r != nil && r.IsWitness
+}
+
+// execWitnessWriteRequest only advances the applied index of the witness, the
+// write requests are not executed.
+func (d *stateMachine) execWitnessWriteRequest(ctx *applyContext) rpcpb.ResponseBatch {
+	d.writeCtx.initialize(d.getShard(), ctx.index, rpcpb.RequestBatch{})
+	if err := d.dataStorage.Write(d.writeCtx); err != nil {
+		d.logger.Fatal("failed to update applied index of witness",
+			zap.Error(err))
+	}
+
+	resp := rpcpb.ResponseBatch{}
+	for range ctx.req.Requests {
+		resp.Responses = append(resp.Responses,
+			rpcpb.Response{AppliedIndex: ctx.index, AppliedTerm: ctx.term})
+	}
+	return resp
+}
+
+func (d *stateMachine) doExecBecomeWitness(ctx *applyContext) (rpcpb.ResponseBatch, error) {
+	req := ctx.req.GetBecomeWitnessRequest()
+	current := d.getShard()
+
+	res := Shard{}
+	protoc.MustUnmarshal(&res, protoc.MustMarshal(&current))
+	p := findReplica(res, req.Replica.StoreID)
+	if p == nil || p.ID != req.Replica.ID {
+		return rpcpb.ResponseBatch{}, errors.Wrapf(ErrReplicaNotFound,
+			"shardID %d, replicaID %d found on container %d",
+			res.ID, req.Replica.ID, req.Replica.StoreID)
+	}
+	if p.IsWitness {
+		return rpcpb.ResponseBatch{}, errors.Wrapf(errWitnessReplica,
+			"shardID %d, replicaID %d", res.ID, p.ID)
+	}
+	p.IsWitness = true
+	res.Epoch.ConfigVer++
+	replica := *p
+
+	d.updateShard(res)
+	if err := d.saveShardMetedata(ctx.index, ctx.term, res,
+		metapb.ReplicaState_Normal); err != nil {
+		d.logger.Fatal("failed to save metadata",
+			zap.Error(err))
+	}
+	if replica.ID == d.replica.ID {
+		d.removeWitnessData(res)
+	}
+
+	d.logger.Info("replica became witness",
+		log.IndexField(ctx.index),
+		log.ReplicaField("replica", replica),
+		log.ShardField("metadata", res))
+	ctx.adminResult = &adminResult{
+		adminType: rpcpb.AdminBecomeWitness,
+		becomeWitnessResult: becomeWitnessResult{
+			replica: replica,
+		},
+	}
+	return newAdminResponseBatch(rpcpb.AdminBecomeWitness,
+		&rpcpb.BecomeWitnessResponse{}), nil
+}
+
+// removeWitnessData drops the shard data of the local replica demoted to a
+// witness. The data is left if the data storage can not remove it, it's never
+// read by the witness.
+func (d *stateMachine) removeWitnessData(shard Shard) {
+	ds, ok := d.dataStorage.(storage.OrphanDataStorage)
+	if !ok {
+		return
+	}
+	if err := ds.RemoveOrphanData(shard.Start, shard.End); err != nil {
+		d.logger.Error("failed to remove data of witness",
+			zap.Error(err))
+	}
+}
+
+func (pr *replica) applyBecomeWitness(result becomeWitnessResult) {
+	replica := result.replica
+	if replica.StoreID == pr.storeID {
+		pr.replica = replica
+	}
+	if _, ok := pr.store.replicaRecords.Load(replica.ID); ok {
+		pr.store.replicaRecords.Store(replica.ID, replica)
+	}
+
+	if pr.isLeader() {
+		pr.prophetHeartbeat()
+		if replica.ID == pr.replicaID {
+			pr.transferWitnessLeadership()
+		}
+	}
+	if pr.aware != nil {
+		pr.aware.Updated(pr.getShard())
+	}
+}
@@ -23,6 +23,13 @@ func requestDoneWithReplicaRemoved(req rpcpb.Request, cb func(rpcpb.ResponseBatc
 	}}})
 }
 
+func requestDoneWithError(req rpcpb.Request, cb func(rpcpb.ResponseBatch), err error) {
+	r := getResponse(req)
+	cb(rpcpb.ResponseBatch{Responses: []rpcpb.Response{r}, Header: rpcpb.ResponseBatchHeader{Error: errorpb.Error{
+		Message: err.Error(),
+	}}})
+}
+
 func getResponse(req rpcpb.Request) rpcpb.Response {
 	return rpcpb.Response{
 		Type: req.Type,
@@ -39,12 +39,21 @@ type Router interface {
 
 	// AscendRange iterate through all shards in order within [Start, end), and stop when fn returns false.
 	AscendRange(group uint64, start, end []byte, policy rpcpb.ReplicaSelectPolicy, fn func(shard Shard, replicaStore metapb.Store) bool)
+	// AscendRangeWithLimit is similar to AscendRange, but stops after at most limit shards are iterated,
+	// zero limit means no limit.
+	AscendRangeWithLimit(group uint64, start, end []byte, limit int, policy rpcpb.ReplicaSelectPolicy, fn func(shard Shard, replicaStore metapb.Store) bool)
+	// ReverseRange iterate through all shards in reverse order within [Start, end), and stop when fn returns
+	// false or limit shards are iterated, zero limit means no limit.
+	ReverseRange(group uint64, start, end []byte, limit int, policy rpcpb.ReplicaSelectPolicy, fn func(shard Shard, replicaStore metapb.Store) bool)
 	// SelectShardWithPolicy Select a Shard according to the specified Key, and select the Store where the
 	// Shard's Replica is located according to the ReplicaSelectPolicy.
 	SelectShardWithPolicy(group uint64, key []byte, policy rpcpb.ReplicaSelectPolicy) (Shard, metapb.Store)
 	// SelectReplicaStoreWithPolicy select the Store where the shard's replica is located according to the
 	// ReplicaSelectPolicy
 	SelectReplicaStoreWithPolicy(shardID uint64, policy rpcpb.ReplicaSelectPolicy) metapb.Store
+	// SelectShardByPartition select the Shard managing the logical partition of the hash routing group,
+	// and select the Store where the Shard's Replica is located according to the ReplicaSelectPolicy.
+	SelectShardByPartition(group uint64, partition uint64, policy rpcpb.ReplicaSelectPolicy) (Shard, metapb.Store)
 
 	// Deprecated: SelectShard returns a shard and leader store that the key is in the range [shard.Start, shard.End).
 	// If returns leader address is "", means the current shard has no leader. Use `SelectShardWithPolicy` instead.
@@ -55,6 +64,8 @@ type Router interface {
 	ForeachShards(group uint64, fn func(shard Shard) bool)
 	// GetShard returns the shard by shard id
 	GetShard(id uint64) Shard
+	// GetStore returns the store by store id
+	GetStore(id uint64) metapb.Store
 
 	// UpdateLeader update shard leader
 	UpdateLeader(shardID uint64, leaderReplciaID uint64)
@@ -199,13 +210,33 @@ func (r *defaultRouter) SelectShard(group uint64, key []byte) (Shard, string) {
 }
 
 func (r *defaultRouter) AscendRange(group uint64, start, end []byte,
+	policy rpcpb.ReplicaSelectPolicy,
+	fn func(shard Shard, replciaStore metapb.Store) bool) {
+	r.AscendRangeWithLimit(group, start, end, 0, policy, fn)
+}
+
+func (r *defaultRouter) AscendRangeWithLimit(group uint64, start, end []byte, limit int,
 	policy rpcpb.ReplicaSelectPolicy,
 	fn func(shard Shard, replciaStore metapb.Store) bool) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
 	if tree, ok := r.mu.keyRanges[group]; ok {
-		tree.AscendRange(start, end, func(shard *metapb.Shard) bool {
+		tree.AscendRangeWithLimit(start, end, limit, func(shard *metapb.Shard) bool {
+			s := *shard
+			return fn(s, r.selectReplicaStoreByPolicyLocked(s, policy))
+		})
+	}
+}
+
+func (r *defaultRouter) ReverseRange(group uint64, start, end []byte, limit int,
+	policy rpcpb.ReplicaSelectPolicy,
+	fn func(shard Shard, replciaStore metapb.Store) bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if tree, ok := r.mu.keyRanges[group]; ok {
+		tree.ReverseRange(start, end, limit, func(shard *metapb.Shard) bool {
 			s := *shard
 			return fn(s, r.selectReplicaStoreByPolicyLocked(s, policy))
 		})
@@ -219,6 +250,10 @@ func (r *defaultRouter) SelectShardWithPolicy(group uint64, key []byte, policy r
 	return shard, r.selectReplicaStoreByPolicyLocked(shard, policy)
 }
 
+func (r *defaultRouter) SelectShardByPartition(group uint64, partition uint64, policy rpcpb.ReplicaSelectPolicy) (Shard, metapb.Store) {
+	return r.SelectShardWithPolicy(group, EncodePartitionKey(partition), policy)
+}
+
 func (r *defaultRouter) SelectReplicaStoreWithPolicy(shardID uint64, policy rpcpb.ReplicaSelectPolicy) metapb.Store {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
@@ -237,6 +272,8 @@ func (r *defaultRouter) selectReplicaStoreByPolicyLocked(shard Shard, policy rpc
 		return r.getLeaderReplicaStoreLocked(shard.ID)
 	case rpcpb.SelectRandom:
 		return r.mustGetStoreLocked(r.selectStoreLocked(shard))
+	case rpcpb.SelectFollower:
+		return r.selectFollowerStoreLocked(shard)
 	default:
 		panic("not yet implemented")
 	}
@@ -249,6 +286,13 @@ func (r *defaultRouter) GetShard(id uint64) Shard {
 	return r.mu.shards[id]
 }
 
+func (r *defaultRouter) GetStore(id uint64) metapb.Store {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.mu.stores[id]
+}
+
 func (r *defaultRouter) Every(group uint64, mustLeader bool, doFunc func(Shard, metapb.Store) bool) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
@@ -377,6 +421,13 @@ func (r *defaultRouter) handleEvent(evt rpcpb.EventNotify) {
 	case event.ShardEvent:
 		r.updateShardLocked(evt.ShardEvent.Data, evt.ShardEvent.Leader,
 			evt.ShardEvent.Removed, evt.ShardEvent.Create)
+	case event.CreateShardsEvent:
+		r.logger.Info("need to create shards",
+			zap.String("event", event.TypeName(evt.Type)),
+			zap.Int("shard-count", len(evt.InitEvent.Shards)))
+		for _, data := range evt.InitEvent.Shards {
+			r.updateShardLocked(data, 0, false, true)
+		}
 	case event.StoreEvent:
 		r.updateStoreLocked(evt.StoreEvent.Data)
 	case event.ShardStatsEvent:
@@ -528,11 +579,51 @@ func (r *defaultRouter) getRandomReplicaStoreLocked(shardID uint64) metapb.Store
 	return metapb.Store{}
 }
 
+// selectStoreLocked selects the replicas of the shard in turn, the replicas
+// recovering from the snapshots are skipped unless all replicas are recovering.
 func (r *defaultRouter) selectStoreLocked(shard Shard) uint64 {
 	ops := r.mu.opts[shard.ID]
-	storeID := shard.Replicas[int(ops.next())%len(shard.Replicas)].StoreID
+	recovering := r.mu.shardStats[shard.ID].RecoveringReplicas
+	n := len(shard.Replicas)
+	next := int(ops.next())
+	replica := shard.Replicas[next%n]
+	for i := 1; i < n && isRecoveringReplica(recovering, replica.ID); i++ {
+		replica = shard.Replicas[(next+i)%n]
+	}
+	if isRecoveringReplica(recovering, replica.ID) {
+		replica = shard.Replicas[next%n]
+	}
+	r.mu.opts[shard.ID] = ops
+	return replica.StoreID
+}
+
+// selectFollowerStoreLocked selects the store of a follower replica of the shard
+// in turn, the recovering replicas are skipped. The leader store is returned if
+// the shard has no available follower.
+func (r *defaultRouter) selectFollowerStoreLocked(shard Shard) metapb.Store {
+	leader := r.getLeaderReplicaStoreLocked(shard.ID)
+	ops := r.mu.opts[shard.ID]
+	recovering := r.mu.shardStats[shard.ID].RecoveringReplicas
+	n := len(shard.Replicas)
+	next := int(ops.next())
 	r.mu.opts[shard.ID] = ops
-	return storeID
+	for i := 0; i < n; i++ {
+		replica := shard.Replicas[(next+i)%n]
+		if replica.StoreID != leader.ID &&
+			!isRecoveringReplica(recovering, replica.ID) {
+			return r.mustGetStoreLocked(replica.StoreID)
+		}
+	}
+	return leader
+}
+
+func isRecoveringReplica(recovering []uint64, id uint64) bool {
+	for _, v := range recovering {
+		if v == id {
+			return true
+		}
+	}
+	return false
 }
 
 func (r *defaultRouter) searchShardLocked(group uint64, key []byte) Shard {
@@ -312,6 +312,15 @@ func TestSelectShardByPolicy(t *testing.T) {
 			expectShardID:   1,
 			expectStoreID:   []uint64{101, 301},
 		},
+		{
+			key:             b.CreateShard(1, "100/101,200/201,300/301").Start,
+			leaderReplicaID: 100,
+			shard:           b.CreateShard(1, "100/101,200/201,300/301"),
+			stores:          []metapb.Store{{ID: 101}, {ID: 201}, {ID: 301}},
+			policy:          rpcpb.SelectFollower,
+			expectShardID:   1,
+			expectStoreID:   []uint64{201, 301},
+		},
 		{
 			key:             b.CreateShard(2, "").Start,
 			leaderReplicaID: 100,
@@ -408,3 +417,156 @@ func TestAscendRange(t *testing.T) {
 		assert.Equal(t, c.expectStores, stores, "index %d", i)
 	}
 }
+
+func TestReverseRange(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	c := make(chan rpcpb.EventNotify)
+	defer close(c)
+	rr, err := newRouterBuilder().build(c)
+	assert.NoError(t, err)
+	r := rr.(*defaultRouter)
+
+	b := NewTestDataBuilder()
+	s1 := b.CreateShard(1, "10/11,20/21,30/31")
+	s2 := b.CreateShard(2, "100/101,200/201,300/301")
+	s3 := b.CreateShard(3, "1000/1001,2000/2001,3000/3001")
+	r.updateShardLocked(protoc.MustMarshal(&s1), 10, false, false)
+	r.updateShardLocked(protoc.MustMarshal(&s2), 100, false, false)
+	r.updateShardLocked(protoc.MustMarshal(&s3), 1000, false, false)
+	r.updateStoreLocked(protoc.MustMarshal(&metapb.Store{ID: 11}))
+	r.updateStoreLocked(protoc.MustMarshal(&metapb.Store{ID: 21}))
+	r.updateStoreLocked(protoc.MustMarshal(&metapb.Store{ID: 31}))
+	r.updateStoreLocked(protoc.MustMarshal(&metapb.Store{ID: 101}))
+	r.updateStoreLocked(protoc.MustMarshal(&metapb.Store{ID: 201}))
+	r.updateStoreLocked(protoc.MustMarshal(&metapb.Store{ID: 301}))
+	r.updateStoreLocked(protoc.MustMarshal(&metapb.Store{ID: 1001}))
+	r.updateStoreLocked(protoc.MustMarshal(&metapb.Store{ID: 2001}))
+	r.updateStoreLocked(protoc.MustMarshal(&metapb.Store{ID: 3001}))
+
+	cases := []struct {
+		keyRange     []uint64
+		limit        int
+		policy       rpcpb.ReplicaSelectPolicy
+		expectShards []uint64
+		expectStores []uint64
+	}{
+		{
+			keyRange:     []uint64{1, 4},
+			policy:       rpcpb.SelectLeader,
+			expectShards: []uint64{3, 2, 1},
+			expectStores: []uint64{1001, 101, 11},
+		},
+		{
+			keyRange:     []uint64{1, 4},
+			limit:        2,
+			policy:       rpcpb.SelectLeader,
+			expectShards: []uint64{3, 2},
+			expectStores: []uint64{1001, 101},
+		},
+		{
+			keyRange:     []uint64{1, 3},
+			policy:       rpcpb.SelectLeader,
+			expectShards: []uint64{2, 1},
+			expectStores: []uint64{101, 11},
+		},
+		{
+			keyRange:     []uint64{2, 3},
+			policy:       rpcpb.SelectLeader,
+			expectShards: []uint64{2},
+			expectStores: []uint64{101},
+		},
+		{
+			keyRange:     []uint64{0, 1},
+			policy:       rpcpb.SelectLeader,
+			expectShards: nil,
+			expectStores: nil,
+		},
+	}
+
+	for i, c := range cases {
+		var shards []uint64
+		var stores []uint64
+		r.ReverseRange(0, format.Uint64ToBytes(c.keyRange[0]), format.Uint64ToBytes(c.keyRange[1]), c.limit, c.policy, func(shard Shard, replciaStore metapb.Store) bool {
+			shards = append(shards, shard.ID)
+			stores = append(stores, replciaStore.ID)
+			return true
+		})
+		assert.Equal(t, c.expectShards, shards, "index %d", i)
+		assert.Equal(t, c.expectStores, stores, "index %d", i)
+	}
+
+	var shards []uint64
+	r.AscendRangeWithLimit(0, format.Uint64ToBytes(1), format.Uint64ToBytes(4), 2, rpcpb.SelectLeader, func(shard Shard, replciaStore metapb.Store) bool {
+		shards = append(shards, shard.ID)
+		return true
+	})
+	assert.Equal(t, []uint64{1, 2}, shards)
+}
+
+func TestSelectStoreSkipsRecoveringReplicas(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	b := NewTestDataBuilder()
+	rr, err := newRouterBuilder().build(make(chan rpcpb.EventNotify))
+	assert.NoError(t, err)
+	r := rr.(*defaultRouter)
+	shard := b.CreateShard(1, "100/101,200/201,300/301")
+	r.updateShardLocked(protoc.MustMarshal(&shard), 100, false, false)
+	r.mu.shardStats[1] = metapb.ShardStats{ShardID: 1, RecoveringReplicas: []uint64{200, 300}}
+	for i := 0; i < 6; i++ {
+		assert.Equal(t, uint64(101), r.selectStoreLocked(shard))
+	}
+
+	// all replicas are recovering
+	r.mu.shardStats[1] = metapb.ShardStats{ShardID: 1, RecoveringReplicas: []uint64{100, 200, 300}}
+	stores := make(map[uint64]struct{})
+	for i := 0; i < 3; i++ {
+		stores[r.selectStoreLocked(shard)] = struct{}{}
+	}
+	assert.Equal(t, 3, len(stores))
+}
+
+func TestSelectFollowerStore(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	b := NewTestDataBuilder()
+	rr, err := newRouterBuilder().build(make(chan rpcpb.EventNotify))
+	assert.NoError(t, err)
+	r := rr.(*defaultRouter)
+	shard := b.CreateShard(1, "100/101,200/201,300/301")
+	r.updateShardLocked(protoc.MustMarshal(&shard), 100, false, false)
+	for _, id := range []uint64{101, 201, 301} {
+		r.updateStoreLocked(protoc.MustMarshal(&metapb.Store{ID: id}))
+	}
+
+	stores := make(map[uint64]struct{})
+	for i := 0; i < 6; i++ {
+		stores[r.selectFollowerStoreLocked(shard).ID] = struct{}{}
+	}
+	assert.Equal(t, map[uint64]struct{}{201: {}, 301: {}}, stores)
+
+	r.mu.shardStats[1] = metapb.ShardStats{ShardID: 1, RecoveringReplicas: []uint64{200}}
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, uint64(301), r.selectFollowerStoreLocked(shard).ID)
+	}
+
+	// no follower available
+	r.mu.shardStats[1] = metapb.ShardStats{ShardID: 1, RecoveringReplicas: []uint64{200, 300}}
+	assert.Equal(t, uint64(101), r.selectFollowerStoreLocked(shard).ID)
+}
+
+func TestSelectShardByPartition(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	rr, err := newRouterBuilder().build(make(chan rpcpb.EventNotify))
+	assert.NoError(t, err)
+	r := rr.(*defaultRouter)
+	r.updateShardLocked(protoc.MustMarshal(&Shard{ID: 1, End: EncodePartitionKey(10)}), 0, false, false)
+	r.updateShardLocked(protoc.MustMarshal(&Shard{ID: 2, Start: EncodePartitionKey(10)}), 0, false, false)
+
+	for partition, expect := range map[uint64]uint64{0: 1, 9: 1, 10: 2, 100: 2} {
+		shard, _ := r.SelectShardByPartition(0, partition, rpcpb.SelectLeader)
+		assert.Equal(t, expect, shard.ID, "partition %d", partition)
+	}
+}
@@ -0,0 +1,47 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package raftstore
+
+import (
+	"github.com/matrixorigin/matrixcube/pb/errorpb"
+	"github.com/matrixorigin/matrixcube/pb/metapb"
+	"github.com/matrixorigin/matrixcube/pb/rpcpb"
+)
+
+// checkShardACL returns the AccessDenied error if the custom request of the op
+// class is rejected by the ACL of the shard. The ACL is checked by the replica
+// when executing the custom requests, so the requests bypassed the auth of the
+// embedder are still rejected.
+func checkShardACL(shard Shard, req rpcpb.Request, op metapb.OperationClass) *errorpb.Error {
+	acl := shard.ACL
+	if acl == nil || (acl.Owner == "" && len(acl.AllowedOperations) == 0) {
+		return nil
+	}
+
+	if acl.Owner == "" || acl.Owner == req.Tenant {
+		if len(acl.AllowedOperations) == 0 {
+			return nil
+		}
+		for _, allowed := range acl.AllowedOperations {
+			if allowed == op {
+				return nil
+			}
+		}
+	}
+
+	return &errorpb.Error{
+		Message:      NewAccessDeniedErr(shard.ID, req.Tenant).Error(),
+		AccessDenied: &errorpb.AccessDenied{ShardID: shard.ID, Tenant: req.Tenant},
+	}
+}
@@ -0,0 +1,53 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package raftstore
+
+import (
+	"testing"
+
+	"github.com/matrixorigin/matrixcube/pb/errorpb"
+	"github.com/matrixorigin/matrixcube/pb/metapb"
+	"github.com/matrixorigin/matrixcube/pb/rpcpb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckShardACL(t *testing.T) {
+	read, write := metapb.OperationClass_ReadOperation, metapb.OperationClass_WriteOperation
+	cases := []struct {
+		acl    *metapb.ShardACL
+		tenant string
+		op     metapb.OperationClass
+		denied bool
+	}{
+		{acl: nil, tenant: "t1", op: write},
+		{acl: &metapb.ShardACL{}, tenant: "", op: write},
+		{acl: &metapb.ShardACL{Owner: "t1"}, tenant: "t1", op: write},
+		{acl: &metapb.ShardACL{Owner: "t1"}, tenant: "t2", op: read, denied: true},
+		{acl: &metapb.ShardACL{Owner: "t1"}, tenant: "", op: read, denied: true},
+		{acl: &metapb.ShardACL{AllowedOperations: []metapb.OperationClass{read}}, tenant: "t2", op: read},
+		{acl: &metapb.ShardACL{AllowedOperations: []metapb.OperationClass{read}}, tenant: "t2", op: write, denied: true},
+		{acl: &metapb.ShardACL{Owner: "t1", AllowedOperations: []metapb.OperationClass{read, write}}, tenant: "t1", op: write},
+		{acl: &metapb.ShardACL{Owner: "t1", AllowedOperations: []metapb.OperationClass{read}}, tenant: "t1", op: write, denied: true},
+	}
+
+	for i, c := range cases {
+		err := checkShardACL(Shard{ID: 1, ACL: c.acl}, rpcpb.Request{Tenant: c.tenant}, c.op)
+		assert.Equal(t, c.denied, err != nil, "index %d", i)
+		if err != nil {
+			assert.Equal(t, uint64(1), err.AccessDenied.ShardID, "index %d", i)
+			assert.Equal(t, c.tenant, err.AccessDenied.Tenant, "index %d", i)
+			assert.False(t, errorpb.Retryable(*err), "index %d", i)
+		}
+	}
+}
@@ -65,6 +65,16 @@ type recoverable interface {
 
 var _ recoverable = (storage.DataStorage)(nil)
 
+type witnessRecoverable interface {
+	SaveShardMetadata([]metapb.ShardMetadata) error
+}
+
+var _ witnessRecoverable = (storage.DataStorage)(nil)
+
+const (
+	witnessMetadataFilename = "witness.metadata"
+)
+
 type snapshotter struct {
 	logger      *zap.Logger
 	shardID     uint64
@@ -89,43 +99,57 @@ func newSnapshotter(shardID uint64, replicaID uint64,
 	}
 }
 
-func (s *snapshotter) prepareReplicaSnapshotDir() error {
+// prepareReplicaSnapshotDir creates the snapshot dir of the replica, or removes
+// the orphan snapshots in the existing one, returns the number of the removed
+// snapshot dirs.
+func (s *snapshotter) prepareReplicaSnapshotDir() (int, error) {
 	exist, err := fileutil.Exist(s.rootDir, s.fs)
 	if err != nil {
-		return err
+		return 0, err
 	}
 	if !exist {
-		return fileutil.MkdirAll(s.rootDir, s.fs)
+		return 0, fileutil.MkdirAll(s.rootDir, s.fs)
 	}
 	return s.removeOrphanSnapshots()
 }
 
-func (s *snapshotter) removeOrphanSnapshots() error {
+func (s *snapshotter) removeOrphanSnapshots() (int, error) {
 	noss := false
 	ss, err := s.ldb.GetSnapshot(s.shardID)
 	if err != nil {
 		if errors.Is(err, logdb.ErrNoSnapshot) {
 			noss = true
 		} else {
-			return err
+			return 0, err
 		}
 	}
+	// only the snapshot image recorded in the logdb is kept, the delta and the
+	// witness snapshots sent to other replicas are never recorded, they have the
+	// same index as the recorded one when created at the same applied index.
+	var keep string
+	if !noss {
+		var si metapb.SnapshotInfo
+		protoc.MustUnmarshal(&si, ss.Data)
+		keep = snapshot.GetSnapshotDirName(ss.Metadata.Index, si.Extra)
+	}
 	files, err := s.fs.List(s.rootDir)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
+	removed := 0
 	removeDir := func(name string) error {
 		if err := s.fs.RemoveAll(name); err != nil {
 			return err
 		}
+		removed++
 		return fileutil.SyncDir(s.rootDir, s.fs)
 	}
 
 	for _, n := range files {
 		dirInfo, err := s.fs.Stat(s.fs.PathJoin(s.rootDir, n))
 		if err != nil {
-			return err
+			return removed, err
 		}
 		if !dirInfo.IsDir() {
 			continue
@@ -138,7 +162,7 @@ func (s *snapshotter) removeOrphanSnapshots() error {
 			// snapshot dir name with the ".receiving" or ".generating" suffix
 			// implies that it is an incomplete snapshot
 			if err := removeDir(dirName); err != nil {
-				return err
+				return removed, err
 			}
 		} else if s.isSnapshotDirectory(fn) {
 			// fully processed snapshot image with the flag file already removed
@@ -148,21 +172,47 @@ func (s *snapshotter) removeOrphanSnapshots() error {
 				zap.Bool("no-snapshot-in-logdb", noss),
 				log.IndexField(index),
 				log.SnapshotField(ss))
-			if noss || index != ss.Metadata.Index {
+			if noss || fn != keep {
 				if err := removeDir(dirName); err != nil {
-					return err
+					return removed, err
 				}
 			}
 		}
 	}
-	return nil
+	return removed, nil
 }
 
 func (s *snapshotter) save(de saveable,
 	cs raftpb.ConfState, index uint64, term uint64) (ss raftpb.Snapshot,
 	env snapshot.SSEnv, err error) {
-	extra := random.LockGuardedRand.Uint64()
-	env = s.getCreatingSnapshotEnv(extra)
+	return s.doSave(func(dir string) error {
+		return de.CreateSnapshot(s.shardID, dir)
+	}, metapb.SnapshotInfo{}, cs, index, term)
+}
+
+// saveDelta saves a delta snapshot with the data changed since the base index.
+func (s *snapshotter) saveDelta(de storage.DeltaSnapshotStorage, base uint64,
+	cs raftpb.ConfState, index uint64, term uint64) (ss raftpb.Snapshot,
+	env snapshot.SSEnv, err error) {
+	return s.doSave(func(dir string) error {
+		return de.CreateDeltaSnapshot(s.shardID, base, dir)
+	}, metapb.SnapshotInfo{DeltaBase: base}, cs, index, term)
+}
+
+// saveWitness saves a witness snapshot with the shard metadata only.
+func (s *snapshotter) saveWitness(md metapb.ShardMetadata,
+	cs raftpb.ConfState, index uint64, term uint64) (ss raftpb.Snapshot,
+	env snapshot.SSEnv, err error) {
+	return s.doSave(func(dir string) error {
+		return fileutil.CreateFlagFile(dir, witnessMetadataFilename, &md, s.fs)
+	}, metapb.SnapshotInfo{Witness: true}, cs, index, term)
+}
+
+func (s *snapshotter) doSave(create func(dir string) error, si metapb.SnapshotInfo,
+	cs raftpb.ConfState, index uint64, term uint64) (ss raftpb.Snapshot,
+	env snapshot.SSEnv, err error) {
+	si.Extra = random.LockGuardedRand.Uint64()
+	env = s.getCreatingSnapshotEnv(si.Extra)
 	s.logger.Info("saving snapshot",
 		zap.String("tmpdir", env.GetTempDir()))
 	if err := env.CreateTempDir(); err != nil {
@@ -170,14 +220,16 @@ func (s *snapshotter) save(de saveable,
 			zap.Error(err))
 		return raftpb.Snapshot{}, env, err
 	}
-	if err := de.CreateSnapshot(s.shardID, env.GetTempDir()); err != nil {
-		s.logger.Error("data storage failed to create snapshot",
-			zap.Error(err))
+	if err := create(env.GetTempDir()); err != nil {
+		if !errors.Is(err, storage.ErrDeltaSnapshotUnavailable) {
+			s.logger.Error("data storage failed to create snapshot",
+				zap.Error(err))
+		}
 		return raftpb.Snapshot{}, env, err
 	}
 	env.FinalizeIndex(index)
 	return raftpb.Snapshot{
-		Data: protoc.MustMarshal(&metapb.SnapshotInfo{Extra: extra}),
+		Data: protoc.MustMarshal(&si),
 		Metadata: raftpb.SnapshotMetadata{
 			Index:     index,
 			Term:      term,
@@ -186,6 +238,20 @@ func (s *snapshotter) save(de saveable,
 	}, env, nil
 }
 
+// move copies the shard from the data storage to another one by a snapshot
+// created in a temp dir, which is removed after copied.
+func (s *snapshotter) move(from saveable, to recoverable) error {
+	env := s.getCreatingSnapshotEnv(random.LockGuardedRand.Uint64())
+	if err := env.CreateTempDir(); err != nil {
+		return err
+	}
+	defer env.MustRemoveTempDir()
+	if err := from.CreateSnapshot(s.shardID, env.GetTempDir()); err != nil {
+		return err
+	}
+	return to.ApplySnapshot(s.shardID, env.GetTempDir())
+}
+
 func (s *snapshotter) recover(rc recoverable,
 	ss raftpb.Snapshot) (metapb.ShardMetadata, error) {
 	env := s.getRecoverSnapshotEnv(ss)
@@ -216,6 +282,34 @@ func (s *snapshotter) recover(rc recoverable,
 	panic("missing shard metadata after recovering from snapshot")
 }
 
+// recoverWitness saves the shard metadata of the witness snapshot, the witness
+// has no data to recover.
+func (s *snapshotter) recoverWitness(ws witnessRecoverable,
+	ss raftpb.Snapshot) (metapb.ShardMetadata, error) {
+	env := s.getRecoverSnapshotEnv(ss)
+	s.logger.Info("recovering from witness snapshot",
+		zap.String("dir", env.GetFinalDir()))
+	var md metapb.ShardMetadata
+	if err := fileutil.GetFlagFileContent(env.GetFinalDir(),
+		witnessMetadataFilename, &md, s.fs); err != nil {
+		s.logger.Error("failed to get shard metadata from witness snapshot",
+			zap.Error(err))
+		return metapb.ShardMetadata{}, err
+	}
+	if md.ShardID != s.shardID || md.LogIndex != ss.Metadata.Index {
+		s.logger.Fatal("unexpected witness snapshot metadata",
+			zap.Uint64("shard", md.ShardID),
+			zap.Uint64("log-index", md.LogIndex),
+			zap.Uint64("snapshot-index", ss.Metadata.Index))
+	}
+	if err := ws.SaveShardMetadata([]metapb.ShardMetadata{md}); err != nil {
+		s.logger.Error("data storage failed to save witness metadata",
+			zap.Error(err))
+		return metapb.ShardMetadata{}, err
+	}
+	return md, nil
+}
+
 func (s *snapshotter) commit(ss raftpb.Snapshot, env snapshot.SSEnv) error {
 	env.FinalizeIndex(ss.Metadata.Index)
 	if err := env.FinalizeSnapshot(); err != nil {
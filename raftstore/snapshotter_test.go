@@ -33,12 +33,14 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/fagongzi/util/protoc"
 	"github.com/stretchr/testify/assert"
 	"go.etcd.io/etcd/raft/v3/raftpb"
 	"go.uber.org/zap"
 
 	"github.com/matrixorigin/matrixcube/components/log"
 	"github.com/matrixorigin/matrixcube/logdb"
+	"github.com/matrixorigin/matrixcube/pb/metapb"
 	"github.com/matrixorigin/matrixcube/storage/kv/mem"
 	"github.com/matrixorigin/matrixcube/util/leaktest"
 	"github.com/matrixorigin/matrixcube/vfs"
@@ -104,7 +106,8 @@ func TestPrepareReplicaSnapshotDir(t *testing.T) {
 	if _, err := fs.Stat(fp); vfs.IsExist(err) {
 		t.Errorf("replica snapshot dir already created")
 	}
-	assert.NoError(t, ss.prepareReplicaSnapshotDir())
+	_, err := ss.prepareReplicaSnapshotDir()
+	assert.NoError(t, err)
 	if _, err := fs.Stat(fp); vfs.IsNotExist(err) {
 		t.Errorf("replica snapshot dir not created")
 	}
@@ -136,11 +139,11 @@ func testZombieSnapshotDirsCanBeRemoved(t *testing.T, explicit bool) {
 			t.Errorf("failed to create dir %v", err)
 		}
 		if explicit {
-			if err := s.removeOrphanSnapshots(); err != nil {
+			if _, err := s.removeOrphanSnapshots(); err != nil {
 				t.Errorf("failed to process orphaned snapshtos %s", err)
 			}
 		} else {
-			if err := s.prepareReplicaSnapshotDir(); err != nil {
+			if _, err := s.prepareReplicaSnapshotDir(); err != nil {
 				t.Errorf("failed to prepare replica snapshot dir")
 			}
 		}
@@ -169,7 +172,7 @@ func TestSnapshotsNotInLogDBAreRemoved(t *testing.T) {
 		if err := fs.MkdirAll(fd2, 0755); err != nil {
 			t.Errorf("failed to create dir %v", err)
 		}
-		if err := s.removeOrphanSnapshots(); err != nil {
+		if _, err := s.removeOrphanSnapshots(); err != nil {
 			t.Errorf("failed to process orphaned snapshtos %s", err)
 		}
 		if _, err := fs.Stat(fd1); !vfs.IsNotExist(err) {
@@ -212,9 +215,13 @@ func TestOnlyMostRecentSnapshotIsKept(t *testing.T) {
 		if err := fs.MkdirAll(fd3, 0755); err != nil {
 			t.Errorf("failed to create dir %v", err)
 		}
-		if err := s.removeOrphanSnapshots(); err != nil {
+		removed, err := s.removeOrphanSnapshots()
+		if err != nil {
 			t.Errorf("failed to process orphaned snapshtos %s", err)
 		}
+		if removed != 2 {
+			t.Errorf("removed %d, want 2", removed)
+		}
 		if _, err := fs.Stat(fd1); !vfs.IsNotExist(err) {
 			t.Errorf("fd1 %s not removed", fd1)
 		}
@@ -228,6 +235,48 @@ func TestOnlyMostRecentSnapshotIsKept(t *testing.T) {
 	runSnapshotterTest(t, fn, fs)
 }
 
+func TestUnrecordedSnapshotsWithRecordedIndexAreRemoved(t *testing.T) {
+	fs := vfs.GetTestFS()
+	fn := func(t *testing.T, ldb logdb.LogDB, s *snapshotter) {
+		s1 := raftpb.Snapshot{
+			Data: protoc.MustMarshal(&metapb.SnapshotInfo{Extra: 1}),
+			Metadata: raftpb.SnapshotMetadata{
+				Index: 200,
+				Term:  200,
+			},
+		}
+		env1 := s.getRecoverSnapshotEnv(s1)
+		// the delta snapshot sent at the same index is not recorded
+		env2 := s.getCreatingSnapshotEnv(2)
+		env2.FinalizeIndex(s1.Metadata.Index)
+		fd1 := env1.GetFinalDir()
+		fd2 := env2.GetFinalDir()
+		if err := s.saveSnapshot(s1); err != nil {
+			t.Errorf("failed to save snapshot to logdb")
+		}
+		if err := fs.MkdirAll(fd1, 0755); err != nil {
+			t.Errorf("failed to create dir %v", err)
+		}
+		if err := fs.MkdirAll(fd2, 0755); err != nil {
+			t.Errorf("failed to create dir %v", err)
+		}
+		removed, err := s.removeOrphanSnapshots()
+		if err != nil {
+			t.Errorf("failed to process orphaned snapshtos %s", err)
+		}
+		if removed != 1 {
+			t.Errorf("removed %d, want 1", removed)
+		}
+		if _, err := fs.Stat(fd1); vfs.IsNotExist(err) {
+			t.Errorf("fd1 %s removed by mistake", fd1)
+		}
+		if _, err := fs.Stat(fd2); !vfs.IsNotExist(err) {
+			t.Errorf("fd2 %s not removed", fd2)
+		}
+	}
+	runSnapshotterTest(t, fn, fs)
+}
+
 func TestFirstSnapshotBecomeOrphanedIsHandled(t *testing.T) {
 	fs := vfs.GetTestFS()
 	fn := func(t *testing.T, ldb logdb.LogDB, s *snapshotter) {
@@ -237,7 +286,7 @@ func TestFirstSnapshotBecomeOrphanedIsHandled(t *testing.T) {
 		if err := fs.MkdirAll(fd1, 0755); err != nil {
 			t.Errorf("failed to create dir %v", err)
 		}
-		if err := s.removeOrphanSnapshots(); err != nil {
+		if _, err := s.removeOrphanSnapshots(); err != nil {
 			t.Errorf("failed to process orphaned snapshtos %s", err)
 		}
 		if _, err := fs.Stat(fd1); !vfs.IsNotExist(err) {
@@ -281,7 +330,7 @@ func TestOrphanedSnapshotRecordIsRemoved(t *testing.T) {
 			t.Errorf("failed to save snapshot to logdb")
 		}
 		// s1 will be removed, s2 will be kept
-		if err := s.removeOrphanSnapshots(); err != nil {
+		if _, err := s.removeOrphanSnapshots(); err != nil {
 			t.Errorf("failed to process orphaned snapshtos %s", err)
 		}
 		if _, err := fs.Stat(fd1); vfs.IsExist(err) {
@@ -346,7 +395,7 @@ func TestOrphanedSnapshotsCanBeProcessed(t *testing.T) {
 		// foler is expected to be kept
 		// fd2 doesn't has its record in logdb, while the most recent snapshot record
 		// in logdb is not for fd2, fd2 will be entirely removed
-		if err := s.removeOrphanSnapshots(); err != nil {
+		if _, err := s.removeOrphanSnapshots(); err != nil {
 			t.Errorf("failed to process orphaned snapshtos %s", err)
 		}
 		if _, err := fs.Stat(fd1); vfs.IsNotExist(err) {
@@ -15,11 +15,14 @@ package raftstore
 
 import (
 	"bytes"
+	"container/heap"
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/lni/goutils/syncutil"
 	"github.com/matrixorigin/matrixcube/components/log"
+	"github.com/matrixorigin/matrixcube/metric"
 	"github.com/matrixorigin/matrixcube/pb/metapb"
 	"github.com/matrixorigin/matrixcube/storage"
 	"go.uber.org/zap"
@@ -27,32 +30,102 @@ import (
 
 type splitCheckFunc func(shard Shard, size uint64) (currentApproximateSize uint64,
 	currentApproximateKeys uint64, splitKeys [][]byte, ctx []byte, err error)
-type featureGetter func(uint64) storage.Feature
+type featureGetter func(Shard) storage.Feature
 
+// splitCheckTask is a shard waiting to be checked, the shards exceed the split
+// check size most are checked first.
+type splitCheckTask struct {
+	shard    Shard
+	size     uint64
+	priority float64
+	addedAt  time.Time
+	index    int
+}
+
+// splitCheckQueue is a heap of the split check tasks, the highest priority and
+// the oldest task is on the top.
+type splitCheckQueue []*splitCheckTask
+
+func (q splitCheckQueue) Len() int { return len(q) }
+
+func (q splitCheckQueue) Less(i, j int) bool {
+	if q[i].priority == q[j].priority {
+		return q[i].addedAt.Before(q[j].addedAt)
+	}
+	return q[i].priority > q[j].priority
+}
+
+func (q splitCheckQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+func (q *splitCheckQueue) Push(x interface{}) {
+	task := x.(*splitCheckTask)
+	task.index = len(*q)
+	*q = append(*q, task)
+}
+
+func (q *splitCheckQueue) Pop() interface{} {
+	old := *q
+	n := len(old)
+	task := old[n-1]
+	old[n-1] = nil
+	task.index = -1
+	*q = old[:n-1]
+	return task
+}
+
+// splitChecker runs the split checks of the shards concurrently, the number of
+// concurrent checks is bounded by the workers, and the total approximate size
+// of the shards being checked is bounded by the IO budget, since a split check
+// may scan the whole shard. At least one check is running if the shard exceeds
+// the budget on its own.
 type splitChecker struct {
+	maxWaitToCheck    int
+	workers           int
+	ioBudget          uint64
 	replicaGetter     replicaGetter
 	featureGetterFunc featureGetter
-	checkFuncFactory  func(group uint64) splitCheckFunc
+	checkFuncFactory  func(shard Shard) splitCheckFunc
 	stopper           *syncutil.Stopper
-	shardsC           chan Shard
+	notifyC           chan struct{}
 
 	mu struct {
 		sync.Mutex
-		running bool
+		running  bool
+		queue    splitCheckQueue
+		tasks    map[uint64]*splitCheckTask
+		checking map[uint64]struct{}
+		// inflightBytes is the total approximate size of the shards being checked
+		inflightBytes uint64
 	}
 }
 
-func newSplitChecker(maxWaitToCheck int,
+func newSplitChecker(maxWaitToCheck int, workers int, ioBudget uint64,
 	replicaGetter replicaGetter,
 	featureGetter featureGetter,
-	checkFuncFactory func(group uint64) splitCheckFunc) *splitChecker {
-	return &splitChecker{
+	checkFuncFactory func(shard Shard) splitCheckFunc) *splitChecker {
+	if maxWaitToCheck <= 0 {
+		maxWaitToCheck = 1
+	}
+	if workers <= 0 {
+		workers = 1
+	}
+	sc := &splitChecker{
+		maxWaitToCheck:    maxWaitToCheck,
+		workers:           workers,
+		ioBudget:          ioBudget,
 		stopper:           syncutil.NewStopper(),
 		replicaGetter:     replicaGetter,
 		checkFuncFactory:  checkFuncFactory,
 		featureGetterFunc: featureGetter,
-		shardsC:           make(chan Shard, maxWaitToCheck),
+		notifyC:           make(chan struct{}, 1),
 	}
+	sc.mu.tasks = make(map[uint64]*splitCheckTask)
+	sc.mu.checking = make(map[uint64]struct{})
+	return sc
 }
 
 func (sc *splitChecker) start() {
@@ -64,17 +137,83 @@ func (sc *splitChecker) start() {
 	}
 
 	sc.mu.running = true
-	go func() {
-		for {
-			select {
-			case <-sc.stopper.ShouldStop():
-				close(sc.shardsC)
-				return
-			case shard := <-sc.shardsC:
-				sc.doChecker(shard)
+	for i := 0; i < sc.workers; i++ {
+		sc.stopper.RunWorker(func() {
+			for {
+				if task, ok := sc.next(); ok {
+					sc.doChecker(task.shard)
+					sc.done(task)
+					continue
+				}
+
+				select {
+				case <-sc.stopper.ShouldStop():
+					return
+				case <-sc.notifyC:
+				}
 			}
+		})
+	}
+}
+
+// next pops the task on the top of the queue, if the IO budget allows.
+func (sc *splitChecker) next() (*splitCheckTask, bool) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+
+	select {
+	case <-sc.stopper.ShouldStop():
+		return nil, false
+	default:
+	}
+
+	if len(sc.mu.queue) == 0 {
+		return nil, false
+	}
+	task := sc.mu.queue[0]
+	if sc.ioBudget > 0 && sc.mu.inflightBytes > 0 &&
+		sc.mu.inflightBytes+task.size > sc.ioBudget {
+		return nil, false
+	}
+
+	heap.Pop(&sc.mu.queue)
+	delete(sc.mu.tasks, task.shard.ID)
+	sc.mu.checking[task.shard.ID] = struct{}{}
+	sc.mu.inflightBytes += task.size
+	metric.ObserveSplitCheckWaitDuration(task.addedAt)
+	sc.updateQueueMetricLocked()
+	if len(sc.mu.queue) > 0 {
+		sc.notify()
+	}
+	return task, true
+}
+
+func (sc *splitChecker) done(task *splitCheckTask) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+
+	delete(sc.mu.checking, task.shard.ID)
+	sc.mu.inflightBytes -= task.size
+	if len(sc.mu.queue) > 0 {
+		sc.notify()
+	}
+}
+
+func (sc *splitChecker) notify() {
+	select {
+	case sc.notifyC <- struct{}{}:
+	default:
+	}
+}
+
+func (sc *splitChecker) updateQueueMetricLocked() {
+	var age time.Duration
+	for _, task := range sc.mu.queue {
+		if v := time.Since(task.addedAt); v > age {
+			age = v
 		}
-	}()
+	}
+	metric.SetSplitCheckQueueMetric(len(sc.mu.queue), age)
 }
 
 func (sc *splitChecker) doChecker(shard Shard) bool {
@@ -84,6 +223,7 @@ func (sc *splitChecker) doChecker(shard Shard) bool {
 	}
 
 	pr.logger.Info("start split check job")
+	deadline := pr.adminDeadline()
 
 	epoch := shard.Epoch
 	current := pr.getShard()
@@ -94,14 +234,18 @@ func (sc *splitChecker) doChecker(shard Shard) bool {
 		return false
 	}
 
-	policy := sc.featureGetterFunc(shard.Group)
-	fn := sc.checkFuncFactory(shard.Group)
+	policy := sc.featureGetterFunc(shard)
+	fn := sc.checkFuncFactory(shard)
 	size, keys, splitKeys, ctx, err := fn(shard, policy.ShardCapacityBytes)
 	if err != nil {
 		pr.logger.Fatal("fail to scan split key",
 			zap.Error(err))
 	}
 
+	if policy.HashRouting {
+		splitKeys = alignPartitionSplitKeys(shard, splitKeys)
+	}
+
 	pr.logger.Debug("split check result",
 		log.ShardField("metadata", shard),
 		zap.Uint64("size", size),
@@ -134,6 +278,7 @@ func (sc *splitChecker) doChecker(shard Shard) bool {
 	act.splitCheckData.keys = keys
 	act.splitCheckData.size = size
 	act.splitCheckData.splitKeys = splitKeys
+	act.splitCheckData.deadline = deadline
 
 	// need to exec split request
 	if len(splitKeys) > 0 {
@@ -160,17 +305,22 @@ func (sc *splitChecker) doChecker(shard Shard) bool {
 
 func (sc *splitChecker) close() {
 	sc.mu.Lock()
-	defer sc.mu.Unlock()
-
 	if !sc.mu.running {
+		sc.mu.Unlock()
 		return
 	}
+	sc.mu.running = false
+	sc.mu.Unlock()
 
+	// the workers require the lock to pop the tasks
 	sc.stopper.Stop()
-	sc.mu.running = false
 }
 
-func (sc *splitChecker) add(shard Shard) {
+// add adds the shard to be checked with its approximate size. The shard already
+// waiting to be checked is updated with the latest metadata and size, but keeps
+// its position in terms of waiting time. If the queue is full, the task with the
+// lowest priority is dropped.
+func (sc *splitChecker) add(shard Shard, size uint64) {
 	sc.mu.Lock()
 	defer sc.mu.Unlock()
 
@@ -183,8 +333,51 @@ func (sc *splitChecker) add(shard Shard) {
 		return
 	}
 
-	select {
-	case sc.shardsC <- shard:
-	default:
+	if _, ok := sc.mu.checking[shard.ID]; ok {
+		return
+	}
+
+	priority := sc.getPriority(shard, size)
+	if task, ok := sc.mu.tasks[shard.ID]; ok {
+		task.shard = shard
+		task.size = size
+		task.priority = priority
+		heap.Fix(&sc.mu.queue, task.index)
+		sc.notify()
+		return
+	}
+
+	if len(sc.mu.queue) >= sc.maxWaitToCheck {
+		lowest := sc.mu.queue[0]
+		for _, task := range sc.mu.queue {
+			if sc.mu.queue.Less(lowest.index, task.index) {
+				lowest = task
+			}
+		}
+		if lowest.priority >= priority {
+			return
+		}
+		heap.Remove(&sc.mu.queue, lowest.index)
+		delete(sc.mu.tasks, lowest.shard.ID)
+	}
+
+	task := &splitCheckTask{
+		shard:    shard,
+		size:     size,
+		priority: priority,
+		addedAt:  time.Now(),
+	}
+	heap.Push(&sc.mu.queue, task)
+	sc.mu.tasks[shard.ID] = task
+	sc.updateQueueMetricLocked()
+	sc.notify()
+}
+
+// getPriority returns how many times the shard size exceeds the split check size.
+func (sc *splitChecker) getPriority(shard Shard, size uint64) float64 {
+	checkBytes := sc.featureGetterFunc(shard).ShardSplitCheckBytes
+	if checkBytes == 0 {
+		return float64(size)
 	}
+	return float64(size) / float64(checkBytes)
 }
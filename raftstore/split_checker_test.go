@@ -15,6 +15,7 @@ package raftstore
 
 import (
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/matrixorigin/matrixcube/components/prophet/mock/mockclient"
@@ -28,43 +29,43 @@ import (
 func TestSplitCheckerAdd(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 
-	sc := newSplitChecker(1, nil, func(u uint64) storage.Feature {
+	sc := newSplitChecker(1, 1, 0, nil, func(shard Shard) storage.Feature {
 		return storage.Feature{
 			ShardCapacityBytes: 100,
 		}
 	}, nil)
 
-	sc.add(Shard{})
-	assert.Equal(t, 0, len(sc.shardsC))
+	sc.add(Shard{}, 0)
+	assert.Equal(t, 0, len(sc.mu.queue))
 
 	sc.mu.running = true
 	for i := 0; i < 10; i++ {
-		sc.add(Shard{ID: uint64(i)})
-		assert.Equal(t, 1, len(sc.shardsC))
+		sc.add(Shard{ID: uint64(i)}, 0)
+		assert.Equal(t, 1, len(sc.mu.queue))
 	}
 }
 
 func TestSplitCheckerAddWithInvalidShardState(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 
-	sc := newSplitChecker(1, nil, func(u uint64) storage.Feature {
+	sc := newSplitChecker(1, 1, 0, nil, func(shard Shard) storage.Feature {
 		return storage.Feature{
 			ShardCapacityBytes: 100,
 		}
 	}, nil)
 	sc.mu.running = true
 
-	sc.add(Shard{State: metapb.ShardState_Destroying})
-	assert.Equal(t, 0, len(sc.shardsC))
+	sc.add(Shard{State: metapb.ShardState_Destroying}, 0)
+	assert.Equal(t, 0, len(sc.mu.queue))
 
-	sc.add(Shard{State: metapb.ShardState_Destroyed})
-	assert.Equal(t, 0, len(sc.shardsC))
+	sc.add(Shard{State: metapb.ShardState_Destroyed}, 0)
+	assert.Equal(t, 0, len(sc.mu.queue))
 }
 
 func TestSplitCheckerStartAndClose(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 
-	sc := newSplitChecker(1, nil, func(u uint64) storage.Feature {
+	sc := newSplitChecker(1, 1, 0, nil, func(shard Shard) storage.Feature {
 		return storage.Feature{
 			ShardCapacityBytes: 100,
 		}
@@ -86,11 +87,11 @@ func TestSplitCheckerDoCheck(t *testing.T) {
 	var splitKeys [][]byte
 	var err error
 	trg := newTestReplicaGetter()
-	sc := newSplitChecker(1, trg, func(u uint64) storage.Feature {
+	sc := newSplitChecker(1, 1, 0, trg, func(shard Shard) storage.Feature {
 		return storage.Feature{
 			ShardCapacityBytes: 100,
 		}
-	}, func(group uint64) splitCheckFunc {
+	}, func(shard Shard) splitCheckFunc {
 		return func(shard Shard, size uint64) (uint64, uint64, [][]byte, []byte, error) {
 			return currentSize, currentKeys, splitKeys, nil, err
 		}
@@ -111,8 +112,12 @@ func TestSplitCheckerDoCheck(t *testing.T) {
 	// ok with no split
 	assert.True(t, sc.doChecker(pr.getShard()))
 	assert.Equal(t, int64(1), pr.actions.Len())
-	act, _ := pr.actions.Peek()
+	v, _ := pr.actions.Peek()
 	pr.actions.Get(1, make([]interface{}, 1))
+	act := v.(action)
+	// the split inherits the deadline since the split check started
+	assert.False(t, act.splitCheckData.deadline.IsZero())
+	act.splitCheckData.deadline = time.Time{}
 	assert.Equal(t, action{actionType: splitAction, epoch: pr.getShard().Epoch, splitCheckData: splitCheckData{keys: currentKeys, size: currentSize, splitKeys: splitKeys}}, act)
 
 	// ok and need split
@@ -125,7 +130,116 @@ func TestSplitCheckerDoCheck(t *testing.T) {
 	pr.prophetClient = client
 	assert.True(t, sc.doChecker(pr.getShard()))
 	assert.Equal(t, int64(1), pr.actions.Len())
-	act, _ = pr.actions.Peek()
+	v, _ = pr.actions.Peek()
+	act = v.(action)
+	assert.False(t, act.splitCheckData.deadline.IsZero())
+	act.splitCheckData.deadline = time.Time{}
 	assert.Equal(t, action{actionType: splitAction, epoch: pr.getShard().Epoch, splitCheckData: splitCheckData{keys: currentKeys, size: currentSize, splitKeys: splitKeys, splitIDs: splitIDs}}, act)
 
 }
+
+func TestSplitCheckerPriority(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	sc := newSplitChecker(3, 1, 0, nil, func(shard Shard) storage.Feature {
+		return storage.Feature{
+			ShardSplitCheckBytes: 100,
+		}
+	}, nil)
+	sc.mu.running = true
+
+	sc.add(Shard{ID: 1}, 150)
+	sc.add(Shard{ID: 2}, 300)
+	sc.add(Shard{ID: 3}, 200)
+	assert.Equal(t, 3, len(sc.mu.queue))
+
+	// update the waiting shard
+	sc.add(Shard{ID: 1, Epoch: Epoch{Generation: 1}}, 400)
+	assert.Equal(t, 3, len(sc.mu.queue))
+
+	// the queue is full, the shard with lower priority is dropped
+	sc.add(Shard{ID: 4}, 100)
+	assert.Equal(t, 3, len(sc.mu.queue))
+	sc.add(Shard{ID: 5}, 250)
+	assert.Equal(t, 3, len(sc.mu.queue))
+
+	var ids []uint64
+	for {
+		task, ok := sc.next()
+		if !ok {
+			break
+		}
+		ids = append(ids, task.shard.ID)
+		if task.shard.ID == 1 {
+			assert.Equal(t, uint64(1), task.shard.Epoch.Generation)
+		}
+		sc.done(task)
+	}
+	assert.Equal(t, []uint64{1, 2, 5}, ids)
+	assert.Empty(t, sc.mu.tasks)
+}
+
+func TestSplitCheckerIOBudget(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	sc := newSplitChecker(10, 2, 100, nil, func(shard Shard) storage.Feature {
+		return storage.Feature{
+			ShardSplitCheckBytes: 10,
+		}
+	}, nil)
+	sc.mu.running = true
+
+	// the shard exceeds the budget on its own can be checked
+	sc.add(Shard{ID: 1}, 120)
+	sc.add(Shard{ID: 2}, 50)
+	sc.add(Shard{ID: 3}, 40)
+
+	t1, ok := sc.next()
+	assert.True(t, ok)
+	assert.Equal(t, uint64(1), t1.shard.ID)
+	_, ok = sc.next()
+	assert.False(t, ok)
+
+	// the shard being checked is not added again
+	sc.add(Shard{ID: 1}, 120)
+	assert.Equal(t, 2, len(sc.mu.queue))
+
+	sc.done(t1)
+	t2, ok := sc.next()
+	assert.True(t, ok)
+	assert.Equal(t, uint64(2), t2.shard.ID)
+	t3, ok := sc.next()
+	assert.True(t, ok)
+	assert.Equal(t, uint64(3), t3.shard.ID)
+	assert.Equal(t, uint64(90), sc.mu.inflightBytes)
+
+	sc.done(t2)
+	sc.done(t3)
+	assert.Equal(t, uint64(0), sc.mu.inflightBytes)
+	assert.Empty(t, sc.mu.checking)
+}
+
+func TestSplitCheckerRunWorkers(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	sc := newSplitChecker(10, 2, 0, newTestReplicaGetter(), func(shard Shard) storage.Feature {
+		return storage.Feature{
+			ShardSplitCheckBytes: 10,
+		}
+	}, nil)
+	sc.start()
+	defer sc.close()
+
+	for i := uint64(1); i <= 5; i++ {
+		sc.add(Shard{ID: i}, i*10)
+	}
+	for {
+		sc.mu.Lock()
+		n := len(sc.mu.queue) + len(sc.mu.checking)
+		sc.mu.Unlock()
+		if n == 0 {
+			break
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+}
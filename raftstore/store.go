@@ -14,13 +14,19 @@
 package raftstore
 
 import (
+	"context"
 	"fmt"
+	"net"
+	"net/http"
+	"runtime/debug"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/RoaringBitmap/roaring/roaring64"
 	"github.com/fagongzi/util/protoc"
+	"github.com/juju/ratelimit"
 	"github.com/lni/goutils/syncutil"
 	"github.com/matrixorigin/matrixcube/aware"
 	"github.com/matrixorigin/matrixcube/components/log"
@@ -29,11 +35,14 @@ import (
 	putil "github.com/matrixorigin/matrixcube/components/prophet/util"
 	"github.com/matrixorigin/matrixcube/config"
 	"github.com/matrixorigin/matrixcube/logdb"
+	"github.com/matrixorigin/matrixcube/metric"
 	"github.com/matrixorigin/matrixcube/pb/errorpb"
 	"github.com/matrixorigin/matrixcube/pb/metapb"
 	"github.com/matrixorigin/matrixcube/pb/rpcpb"
+	"github.com/matrixorigin/matrixcube/snapshot"
 	"github.com/matrixorigin/matrixcube/storage"
 	"github.com/matrixorigin/matrixcube/storage/kv/pebble"
+	"github.com/matrixorigin/matrixcube/storage/stats"
 	"github.com/matrixorigin/matrixcube/transport"
 	"github.com/matrixorigin/matrixcube/util"
 	"go.etcd.io/etcd/raft/v3/raftpb"
@@ -43,6 +52,8 @@ import (
 const (
 	// DO NOT CHANGE
 	snapshotDirName = "snapshots"
+
+	fileSnapshotStoragePrefix = "file://"
 )
 
 // Store manage a set of raft group
@@ -65,6 +76,8 @@ type Store interface {
 	OnRequestWithCB(req rpcpb.Request, cb func(resp rpcpb.ResponseBatch)) error
 	// DataStorageByGroup returns a DataStorage of the shard group
 	DataStorageByGroup(uint64) storage.DataStorage
+	// DataStorageByShard returns the DataStorage resolved for the shard
+	DataStorageByShard(Shard) storage.DataStorage
 	// MaybeLeader returns the shard replica maybe leader
 	MaybeLeader(uint64) bool
 	// MustAllocID returns an uint64 id, panic if it has an error
@@ -78,6 +91,56 @@ type Store interface {
 	CreateShardPool(...metapb.ShardPoolJobMeta) (ShardsPool, error)
 	// GetShardPool returns `ShardsPool`, nil if `CreateShardPool` not completed
 	GetShardPool() ShardsPool
+	// GetChaosController returns the ChaosController used to inject faults into
+	// the raft transport at runtime, nil if `Config.Chaos.Enable` is false.
+	GetChaosController() transport.ChaosController
+	// StopGroup stops handling the replicas of the shard group on the store, e.g.
+	// while migrating the storage engine of the group. The replicas of the group
+	// stop processing raft messages and ticks, and the requests of the group are
+	// rejected with `GroupStoppedErr` until `StartGroup` is called. The stopped
+	// groups are reported in the store heartbeats, and are not persisted, all
+	// groups are started after the store restarts.
+	StopGroup(group uint64)
+	// StartGroup resumes handling the replicas of the stopped shard group.
+	StartGroup(group uint64)
+	// GetOrphanDataReport returns the report of the last orphan data gc, see
+	// `Config.OrphanDataGC`.
+	GetOrphanDataReport() OrphanDataReport
+	// GetStartupProgress returns the progress of the store starting, the store is
+	// ready once all replicas are started and the Start returned.
+	GetStartupProgress() StartupProgress
+	// GetRecoveryReport returns the report of the replicas recovered by the store
+	// starting, false if the store is not ready yet.
+	GetRecoveryReport() (RecoveryReport, bool)
+	// GetSnapshotProgresses returns the progresses of the snapshots of the shard
+	// being sent and received by the store, 0 shard means all the shards. Use the
+	// `prophet.Client.ListSnapshotProgresses` for the snapshots of all the stores
+	// with the prophet operators they are sent for.
+	GetSnapshotProgresses(shard uint64) []metapb.SnapshotProgress
+	// CompactRange schedules the manual compaction of the data storage of the
+	// group for the local shards in the key range [start, end), e.g. to reclaim
+	// the space after the bulk deletes. The shards are compacted one by one in
+	// the background, use GetCompactRangeProgress to check the progress. Use the
+	// `client.CompactShard` to compact a shard on all its replicas.
+	CompactRange(group uint64, start, end []byte) error
+	// GetCompactRangeProgress returns the progress of the last CompactRange of
+	// the group, false if no CompactRange of the group.
+	GetCompactRangeProgress(group uint64) (CompactRangeProgress, bool)
+	// GetShardByKey returns the local shard of the group covering the key, false
+	// if no replica on the store covers the key.
+	GetShardByKey(group uint64, key []byte) (Shard, bool)
+	// GetKeyCoverageGaps returns the key ranges of the group whose coverage by the
+	// local shards differs from the router, ordered by the start keys.
+	GetKeyCoverageGaps(group uint64) []KeyCoverageGap
+	// DiagnoseStoreNotMatch explains why the request would be rejected with the
+	// store not match error by the store.
+	DiagnoseStoreNotMatch(req rpcpb.Request) StoreNotMatchDiagnostic
+	// DrainLeadership transfers the leaderships of the replicas on the store to
+	// the healthy replicas of the other stores, e.g. before the store restarts in
+	// a rolling upgrade. The replicas of the store reject the new leader elections
+	// during the drain and the `Config.Raft.DrainGracePeriod` after it. Returns
+	// nil once no leader left on the store, or the error of the ctx.
+	DrainLeadership(ctx context.Context) error
 }
 
 type store struct {
@@ -93,9 +156,14 @@ type store struct {
 	kvStorage             storage.KVStorage
 	logdb                 logdb.LogDB
 	trans                 transport.Trans
+	chaos                 *transport.ChaosTransport
+	chaosAdmin            *http.Server
+	metricServer          *http.Server
 	shardsProxy           ShardsProxy
+	proxyRPC              proxyRPC
 	router                Router
 	splitChecker          *splitChecker
+	orphanDataGC          *orphanDataGC
 	watcher               prophet.EventWatcher
 	vacuumCleaner         *vacuumCleaner
 	createShardsProtector *createShardsProtector
@@ -103,8 +171,12 @@ type store struct {
 	replicaRecords        sync.Map // replica id -> metapb.Replica
 	replicas              sync.Map // shard id -> *replica
 	droppedVoteMsgs       sync.Map // shard id -> raftpb.Message
+	compactRanges         sync.Map // group id -> *compactRangeJob
+	// createReplicaMu blocks the replicas creation, see orphanDataGC
+	createReplicaMu sync.Mutex
 
 	state    uint32
+	draining uint32
 	stopOnce sync.Once
 
 	aware   aware.ShardStateAware
@@ -114,8 +186,26 @@ type store struct {
 	// shard pool processor
 	shardPool       *dynamicShardsPool
 	groupController *replicaGroupController
+	writeStalls     *writeStallDetector
+	admission       *admissionController
+	// syncPiggyback is nil if the sync piggyback is disabled
+	syncPiggyback *syncPiggyback
+	// resolvedTSLimiter limits the ReadIndexes issued to advance the resolved
+	// timestamps, nil means unlimited
+	resolvedTSLimiter *ratelimit.Bucket
 
 	storageStatsReader storageStatsReader
+	// flowStatsMu guards the accumulated flow of the data storages reported by
+	// the last store heartbeat
+	flowStatsMu struct {
+		sync.Mutex
+		last stats.Stats
+	}
+	startup startupProgress
+
+	// rejectElectionsUntil the unix nanos until which the replicas of the store
+	// reject the new leader elections, see DrainLeadership
+	rejectElectionsUntil int64
 
 	mu struct {
 		sync.RWMutex
@@ -126,27 +216,57 @@ type store struct {
 // NewStore returns a raft store
 func NewStore(cfg *config.Config) Store {
 	cfg.Adjust()
-	kv := pebble.CreateLogDBStorage(cfg.DataPath, cfg.FS, cfg.Logger)
+	kv := cfg.Storage.LogDBStorage
+	if kv == nil {
+		kv = pebble.CreateLogDBStorage(cfg.DataPath, cfg.FS, pebble.NewMemoryOptions(cfg.Memory),
+			pebble.NewIOPriorityConfig("logdb", cfg.IOPriority), cfg.Logger)
+	}
 	logger := cfg.Logger.Named("store").With(zap.String("store", cfg.Prophet.Name))
+	compression, err := logdb.ParseCompressionType(cfg.Raft.RaftLog.Compression)
+	if err != nil {
+		logger.Fatal("invalid raft log compression",
+			zap.Error(err))
+	}
+	ldbOpts := []logdb.Option{
+		logdb.WithEntryCompression(compression, int(cfg.Raft.RaftLog.CompressThreshold)),
+	}
+	if cfg.Raft.RaftLog.EnableGroupCommit {
+		ldbOpts = append(ldbOpts, logdb.WithGroupCommit())
+	}
+	var piggyback *syncPiggyback
+	if cfg.Raft.RaftLog.EnableSyncPiggyback {
+		piggyback = &syncPiggyback{}
+		ldbOpts = append(ldbOpts, logdb.WithSyncListener(piggyback))
+	}
+	ldb := logdb.NewKVLogDB(kv, logger.Named("logdb"), ldbOpts...)
 	s := &store{
 		kvStorage:             kv,
 		meta:                  metapb.Store{},
 		cfg:                   cfg,
 		logger:                logger,
-		logdb:                 logdb.NewKVLogDB(kv, logger.Named("logdb")),
+		logdb:                 ldb,
 		stopper:               syncutil.NewStopper(),
 		createShardsProtector: newCreateShardsProtector(),
 		groupController:       newReplicaGroupController(),
+		writeStalls:           newWriteStallDetector(cfg.WriteStall.L0FilesThreshold),
+		admission:             newAdmissionController(cfg.Admission),
 	}
 
+	s.syncPiggyback = piggyback
+	s.resolvedTSLimiter = ratelimit.NewBucketWithRate(float64(cfg.Raft.MaxResolvedTSAdvanceRate),
+		int64(cfg.Raft.MaxResolvedTSAdvanceRate))
 	s.vacuumCleaner = newVacuumCleaner(s.vacuum)
-	// TODO: make maxWaitToChecker configurable
-	s.splitChecker = newSplitChecker(4, &storeReplicaGetter{s},
-		func(group uint64) storage.Feature {
-			return s.cfg.Storage.DataStorageFactory(group).Feature()
-		}, func(group uint64) splitCheckFunc {
-			return s.cfg.Storage.DataStorageFactory(group).SplitCheck
+	s.splitChecker = newSplitChecker(int(s.cfg.Worker.MaxWaitToSplitCheck),
+		int(s.cfg.Worker.SplitCheckWorkers),
+		uint64(s.cfg.Worker.SplitCheckIOBudget),
+		&storeReplicaGetter{s},
+		func(shard Shard) storage.Feature {
+			return s.getShardDataStorage(shard).Feature()
+		}, func(shard Shard) splitCheckFunc {
+			return s.getShardDataStorage(shard).SplitCheck
 		})
+	s.orphanDataGC = newOrphanDataGC(s.cfg.OrphanDataGC, s.logger.Named("orphan-data-gc"),
+		s.getOrphanDataTargets, s.getOwnedShards, &s.createReplicaMu)
 	s.workerPool = newWorkerPool(s.logger, s.logdb, &storeReplicaLoader{s}, s.cfg.Worker.RaftEventWorkers)
 	s.shardPool = newDynamicShardsPool(cfg, s.logger)
 
@@ -169,7 +289,16 @@ func (s *store) GetConfig() *config.Config {
 }
 
 func (s *store) Start() {
+	startAt := time.Now()
 	s.logger.Info("begin to start raftstore")
+	if s.cfg.Memory.GCPercent != 0 {
+		old := debug.SetGCPercent(s.cfg.Memory.GCPercent)
+		s.logger.Info("go gc percent changed",
+			s.storeField(),
+			zap.Int("old", old),
+			zap.Int("new", s.cfg.Memory.GCPercent))
+	}
+
 	s.workerPool.start()
 	s.logger.Info("worker pool started",
 		s.storeField())
@@ -190,7 +319,13 @@ func (s *store) Start() {
 	s.logger.Info("raft internal transport created",
 		s.storeField())
 
-	s.startShards()
+	if n := s.startSyncPiggyback(); n > 0 {
+		s.logger.Info("sync piggyback started",
+			s.storeField(),
+			zap.Int("data-storages", n))
+	}
+
+	report := s.startShards()
 	s.logger.Info("shards started",
 		s.storeField())
 
@@ -199,10 +334,27 @@ func (s *store) Start() {
 		s.storeField(),
 		log.ListenAddressField(s.cfg.RaftAddr))
 
+	if s.startChaosAdmin() {
+		s.logger.Info("chaos admin started",
+			s.storeField(),
+			log.ListenAddressField(s.cfg.Chaos.AdminAddr))
+	}
+
+	if s.startMetricServer() {
+		s.logger.Info("metric server started",
+			s.storeField(),
+			log.ListenAddressField(s.cfg.Metric.ListenAddr))
+	}
+
 	s.startTimerTasks()
 	s.logger.Info("shard timer based tasks started",
 		s.storeField())
 
+	s.orphanDataGC.start()
+	s.logger.Info("orphan data gc started",
+		s.storeField(),
+		zap.String("mode", s.cfg.OrphanDataGC.Mode))
+
 	s.startRouter()
 	s.logger.Info("router started",
 		s.storeField())
@@ -213,6 +365,10 @@ func (s *store) Start() {
 		log.ListenAddressField(s.cfg.ClientAddr))
 
 	s.handleStoreHeartbeatTask(time.Now())
+	report.TotalCost = time.Since(startAt)
+	s.startup.setReport(report)
+	s.startup.setReady()
+	s.logRecoveryReport(report)
 }
 
 func (s *store) Stop() {
@@ -222,10 +378,16 @@ func (s *store) Stop() {
 		s.logger.Info("begin to stop raftstore",
 			s.storeField())
 
+		s.drain()
+
 		s.splitChecker.close()
 		s.logger.Info("split checker closed",
 			s.storeField())
 
+		s.orphanDataGC.close()
+		s.logger.Info("orphan data gc closed",
+			s.storeField())
+
 		s.pd.Stop()
 		s.logger.Info("pd stopped",
 			s.storeField())
@@ -235,6 +397,18 @@ func (s *store) Stop() {
 		s.logger.Info("vacuum cleaner closed",
 			s.storeField())
 
+		if s.chaosAdmin != nil {
+			s.chaosAdmin.Close()
+			s.logger.Info("chaos admin stopped",
+				s.storeField())
+		}
+
+		if s.metricServer != nil {
+			s.metricServer.Close()
+			s.logger.Info("metric server stopped",
+				s.storeField())
+		}
+
 		s.trans.Close()
 		s.logger.Info("raft internal transport stopped",
 			s.storeField())
@@ -275,8 +449,10 @@ func (s *store) Stop() {
 		s.logger.Info("proxy stopped",
 			s.storeField())
 
-		s.kvStorage.Close()
-		s.logger.Info("kvStorage closed")
+		if s.cfg.Storage.LogDBStorage == nil {
+			s.kvStorage.Close()
+			s.logger.Info("kvStorage closed")
+		}
 	})
 }
 
@@ -390,6 +566,41 @@ func (s *store) OnRequestWithCB(req rpcpb.Request, cb func(resp rpcpb.ResponseBa
 		}
 	}
 
+	if s.groupController.isGroupStopped(pr.group) {
+		if ce := s.logger.Check(zap.DebugLevel, "fail to handle request"); ce != nil {
+			ce.Write(log.RequestIDField(req.ID),
+				s.storeField(),
+				log.ShardIDField(pr.shardID),
+				log.ReasonField("group stopped"))
+		}
+		respGroupStopped(pr.group, req, cb)
+		return nil
+	}
+
+	if req.Type != rpcpb.Admin && pr.sm.isMigrated() {
+		if ce := s.logger.Check(zap.DebugLevel, "fail to handle request"); ce != nil {
+			ce.Write(log.RequestIDField(req.ID),
+				s.storeField(),
+				log.ShardIDField(pr.shardID),
+				log.ReasonField("group migrated"))
+		}
+		respGroupMigrated(pr.group, req, cb)
+		return nil
+	}
+
+	if reason := s.admission.admit(pr, req); reason != "" {
+		if ce := s.logger.Check(zap.DebugLevel, "fail to handle request"); ce != nil {
+			ce.Write(log.RequestIDField(req.ID),
+				s.storeField(),
+				log.ShardIDField(pr.shardID),
+				log.ReasonField("server is busy"),
+				zap.String("exceeded", reason))
+		}
+		metric.IncAdmissionRejectedCount(reason)
+		respServerIsBusy(pr.shardID, reason, req, cb)
+		return nil
+	}
+
 	if err := pr.onReq(req, cb); err != nil {
 		if s.isShardUnavailable(pr.getShardID()) {
 			respShardUnavailable(pr.getShardID(), req, cb)
@@ -404,6 +615,25 @@ func (s *store) DataStorageByGroup(group uint64) storage.DataStorage {
 	return s.cfg.Storage.DataStorageFactory(group)
 }
 
+func (s *store) DataStorageByShard(shard Shard) storage.DataStorage {
+	if factory := s.cfg.Storage.ShardDataStorageFactory; factory != nil {
+		if ds := factory(shard.Group, shard); ds != nil {
+			return ds
+		}
+	}
+	return s.DataStorageByGroup(shard.Group)
+}
+
+// getShardDataStorage returns the data storage the replica of the shard is in,
+// the data storage resolved for the shard is returned if the replica is not
+// found.
+func (s *store) getShardDataStorage(shard Shard) storage.DataStorage {
+	if pr := s.getReplica(shard.ID, false); pr != nil {
+		return pr.sm.getDataStorage()
+	}
+	return s.DataStorageByShard(shard)
+}
+
 func (s *store) MaybeLeader(shard uint64) bool {
 	return nil != s.getReplica(shard, true)
 }
@@ -431,43 +661,248 @@ func (s *store) startProphet() {
 	s.cfg.Prophet.Adjust(nil, false)
 
 	s.pdStartedC = make(chan struct{})
-	s.pd = prophet.NewProphet(s.cfg)
+	if s.cfg.Standalone {
+		s.pd = prophet.NewStandaloneProphet(s.cfg)
+	} else {
+		s.pd = prophet.NewProphet(s.cfg)
+	}
 	s.pd.Start()
 	<-s.pdStartedC
 	s.shardPool.setProphetClient(s.pd.GetClient())
 }
 
 func (s *store) createTransport() {
-	s.trans = transport.NewTransport(s.logger,
+	trans := transport.NewTransport(s.logger,
 		s.cfg.RaftAddr, s.Meta().ID, s.handle, s.unreachable, s.snapshotStatus,
 		s.GetReplicaSnapshotDir, s.containerResolver, s.cfg.FS)
+	trans.SetSnapshotRateLimit(uint64(s.cfg.Snapshot.SendRateLimit),
+		uint64(s.cfg.Snapshot.SendRatePerStoreLimit))
+	trans.SetHeartbeatCoalesceInterval(s.cfg.Raft.CoalesceHeartbeatInterval.Duration)
+	if ss := s.createSnapshotStorage(); ss != nil {
+		trans.SetSnapshotStorage(ss, s.cfg.Snapshot.Remote.Retention.Duration)
+	}
+	s.trans = trans
 	if s.cfg.Customize.CustomWrapNewTransport != nil {
 		s.trans = s.cfg.Customize.CustomWrapNewTransport(s.trans)
 	}
+	if s.cfg.Chaos.Enable {
+		chaos, err := transport.NewChaosTransport(s.logger, s.Meta().ID,
+			s.trans, s.cfg.Chaos.Options)
+		if err != nil {
+			s.logger.Fatal("fail to create chaos transport",
+				s.storeField(),
+				zap.Error(err))
+		}
+		s.chaos = chaos
+		s.trans = chaos
+	}
 	if s.cfg.Customize.CustomTransportFilter != nil {
 		s.trans.SetFilter(s.cfg.Customize.CustomTransportFilter)
 	}
 }
 
+func (s *store) GetSnapshotProgresses(shard uint64) []metapb.SnapshotProgress {
+	var progresses []metapb.SnapshotProgress
+	for _, p := range s.trans.SnapshotProgresses() {
+		if shard == 0 || p.ShardID == shard {
+			progresses = append(progresses, p)
+		}
+	}
+	return progresses
+}
+
+// createSnapshotStorage returns the remote snapshot storage, nil if disabled.
+func (s *store) createSnapshotStorage() snapshot.SnapshotStorage {
+	endpoint := s.cfg.Snapshot.Remote.Endpoint
+	if endpoint == "" {
+		return nil
+	}
+
+	if strings.HasPrefix(endpoint, fileSnapshotStoragePrefix) {
+		return snapshot.NewFSSnapshotStorage(strings.TrimPrefix(endpoint,
+			fileSnapshotStoragePrefix), s.cfg.FS)
+	}
+	if s.cfg.Customize.CustomSnapshotStorageFactory == nil {
+		s.logger.Fatal("missing snapshot storage factory of the remote snapshot endpoint",
+			s.storeField(),
+			zap.String("endpoint", endpoint))
+	}
+	ss, err := s.cfg.Customize.CustomSnapshotStorageFactory(s.cfg.Snapshot.Remote)
+	if err != nil {
+		s.logger.Fatal("failed to create the remote snapshot storage",
+			s.storeField(),
+			zap.String("endpoint", endpoint),
+			zap.Error(err))
+	}
+	return ss
+}
+
+func (s *store) GetChaosController() transport.ChaosController {
+	if s.chaos == nil {
+		return nil
+	}
+	return s.chaos
+}
+
+func (s *store) StopGroup(group uint64) {
+	if s.groupController.stopGroup(group) {
+		s.logger.Info("shard group stopped",
+			s.storeField(),
+			zap.Uint64("group", group))
+	}
+}
+
+func (s *store) StartGroup(group uint64) {
+	if !s.groupController.startGroup(group) {
+		return
+	}
+
+	// the pending actions of the replicas are kept while the group is stopped
+	s.forEachReplica(func(pr *replica) bool {
+		if pr.group == group {
+			pr.notifyWorker()
+		}
+		return true
+	})
+	s.logger.Info("shard group started",
+		s.storeField(),
+		zap.Uint64("group", group))
+}
+
+func (s *store) GetOrphanDataReport() OrphanDataReport {
+	return s.orphanDataGC.getReport()
+}
+
+// getOrphanDataTargets returns the data storages used by the shard groups, a
+// data storage may be shared by multiple groups.
+func (s *store) getOrphanDataTargets() []orphanDataTarget {
+	var targets []orphanDataTarget
+	s.cfg.Storage.ForeachDataStorageFunc(func(group uint64, ds storage.DataStorage) {
+		for idx := range targets {
+			if targets[idx].ds == ds {
+				targets[idx].groups = append(targets[idx].groups, group)
+				return
+			}
+		}
+		targets = append(targets, orphanDataTarget{groups: []uint64{group}, ds: ds})
+	})
+	return targets
+}
+
+// getOwnedShards returns the shards of the replicas on the store using the
+// data storage.
+func (s *store) getOwnedShards(ds storage.DataStorage) []Shard {
+	var shards []Shard
+	s.forEachReplica(func(pr *replica) bool {
+		// the shard being moved is owned by both the data storages
+		if pr.sm.getDataStorage() == ds || s.DataStorageByShard(pr.getShard()) == ds {
+			shards = append(shards, pr.getShard())
+		}
+		return true
+	})
+	return shards
+}
+
+func (s *store) startChaosAdmin() bool {
+	if s.chaos == nil || s.cfg.Chaos.AdminAddr == "" {
+		return false
+	}
+
+	l, err := net.Listen("tcp", s.cfg.Chaos.AdminAddr)
+	if err != nil {
+		s.logger.Fatal("fail to start chaos admin",
+			s.storeField(),
+			zap.Error(err))
+	}
+	s.chaosAdmin = &http.Server{Handler: transport.NewChaosHandler(s.chaos)}
+	go func() {
+		if err := s.chaosAdmin.Serve(l); err != nil && err != http.ErrServerClosed {
+			s.logger.Error("chaos admin stopped",
+				s.storeField(),
+				zap.Error(err))
+		}
+	}()
+	return true
+}
+
+// startMetricServer starts the http listener to scrape the metrics if the
+// listen address is configured.
+func (s *store) startMetricServer() bool {
+	if s.cfg.Metric.ListenAddr == "" {
+		return false
+	}
+
+	l, err := net.Listen("tcp", s.cfg.Metric.ListenAddr)
+	if err != nil {
+		s.logger.Fatal("fail to start metric server",
+			s.storeField(),
+			zap.Error(err))
+	}
+	s.metricServer = &http.Server{Handler: metric.NewHandler()}
+	go func() {
+		if err := s.metricServer.Serve(l); err != nil && err != http.ErrServerClosed {
+			s.logger.Error("metric server stopped",
+				s.storeField(),
+				zap.Error(err))
+		}
+	}()
+	return true
+}
+
 func (s *store) startTransport() {
 	s.trans.Start()
 }
 
-func (s *store) startShards() {
+// startShards loads and starts the replicas found in the data storages, and
+// returns the recovery report without the total cost.
+func (s *store) startShards() RecoveryReport {
+	stopC := make(chan struct{})
+	defer close(stopC)
+	go s.reportStartupProgress(stopC)
+
+	var report RecoveryReport
+	phaseAt := time.Now()
+
 	totalCount := 0
 	tombstoneCount := 0
 
 	var tombstones []metapb.ShardLocalState
 	shards := make(map[uint64]metapb.ShardLocalState)
 	localDestroyings := make(map[uint64]metapb.ShardMetadata)
+	// the shards with the local states to restore, e.g. frozen to be merged or
+	// fenced
+	localStates := make(map[uint64]metapb.ShardMetadata)
 	confirmShards := roaring64.New()
+	var groups []uint64
+	var dataStorages []storage.DataStorage
 	s.cfg.Storage.ForeachDataStorageFunc(func(group uint64, ds storage.DataStorage) {
-		initStates, err := ds.GetInitialStates()
+		groups = append(groups, group)
+		dataStorages = append(dataStorages, ds)
+	})
+	// the initial states of the data storages are loaded concurrently
+	groupsInitStates := make([][]metapb.ShardMetadata, len(groups))
+	runWithWorkers(len(groups), int(s.cfg.Worker.StartupWorkers), func(i int) {
+		initStates, err := dataStorages[i].GetInitialStates()
 		if err != nil {
 			s.logger.Fatal("fail to get initial state",
 				s.storeField(),
 				zap.Error(err))
 		}
+		groupsInitStates[i] = initStates
+		s.startup.addLoaded(len(initStates))
+	})
+	// the data storages the shards loaded from
+	shardStorages := s.dedupShardStorages(dataStorages, groupsInitStates, &report)
+
+	for i, group := range groups {
+		initStates := groupsInitStates[i]
+		if interceptor := s.cfg.Customize.CustomShardMetadataInterceptor; interceptor != nil {
+			if err := interceptor.Replay(group, initStates); err != nil {
+				s.logger.Fatal("fail to replay shard metadata",
+					s.storeField(),
+					zap.Error(err))
+			}
+		}
 
 		for _, metadata := range initStates {
 			totalCount++
@@ -479,6 +914,10 @@ func (s *store) startShards() {
 					zap.Uint64("actual", metadata.ShardID))
 			}
 
+			if shardStorages[sls.Shard.ID] != dataStorages[i] {
+				continue
+			}
+
 			if sls.State == metapb.ReplicaState_ReplicaTombstone {
 				tombstones = append(tombstones, sls)
 				tombstoneCount++
@@ -493,20 +932,34 @@ func (s *store) startShards() {
 				continue
 			}
 
+			if findReplica(sls.Shard, s.Meta().ID) == nil {
+				s.logger.Fatal("local shard belongs to another store",
+					s.storeField(),
+					log.ShardField("metadata", sls.Shard))
+			}
+
 			if metadata.Metadata.Shard.State == metapb.ShardState_Destroying {
 				s.createShardsProtector.addDestroyed(sls.Shard.ID)
 				localDestroyings[metadata.ShardID] = metadata
 			} else {
+				if sls.MergeTarget > 0 || sls.FenceIndex > 0 {
+					localStates[metadata.ShardID] = metadata
+				}
 				confirmShards.Add(sls.Shard.ID)
 			}
 
 			shards[sls.Shard.ID] = sls
 		}
-	})
+	}
 
+	report.LoadCost = time.Since(phaseAt)
+	phaseAt = time.Now()
+
+	checkFailed := 0
 	for {
 		rsp, err := s.pd.GetClient().CheckShardState(confirmShards)
 		if err != nil {
+			checkFailed++
 			s.logger.Error("failed to check init shards, retry later",
 				zap.Error(err))
 			continue
@@ -519,35 +972,71 @@ func (s *store) startShards() {
 				s.createShardsProtector.addDestroyed(id)
 				tombstones = append(tombstones, shards[id])
 				delete(shards, id)
+				delete(localStates, id)
+				report.Anomalies = append(report.Anomalies, RecoveryAnomaly{
+					ShardID: id,
+					Reason:  "destroyed by prophet but not tombstone in store",
+				})
 			}
 		}
 		break
 	}
+	if checkFailed > 0 {
+		report.Anomalies = append(report.Anomalies, RecoveryAnomaly{
+			Reason: fmt.Sprintf("check init shards failed %d times", checkFailed),
+		})
+	}
+	report.ConfirmCost = time.Since(phaseAt)
+	phaseAt = time.Now()
 
 	var readyBootstrapShards []Shard
 	for _, sls := range shards {
 		readyBootstrapShards = append(readyBootstrapShards, sls.Shard)
 	}
 
+	s.startup.setToStart(len(readyBootstrapShards))
 	newReplicaCreator(s).
 		withReason("restart").
-		withStartReplica(true, nil, func(r *replica) {
+		withStartWorkers(int(s.cfg.Worker.StartupWorkers)).
+		withDataStorageGetter(func(shard Shard) storage.DataStorage {
+			return shardStorages[shard.ID]
+		}).
+		withStartReplica(true, func(r *replica) {
+			if metadata, ok := localStates[r.shardID]; ok {
+				r.sm.initLocalState(metadata)
+			}
+		}, func(r *replica) {
 			if metadata, ok := localDestroyings[r.shardID]; ok {
 				r.startDestroyReplicaTask(metadata.LogIndex, metadata.Metadata.RemoveData, "restart")
 			}
+			s.startup.incStarted()
 		}).
 		create(readyBootstrapShards)
+	report.StartCost = time.Since(phaseAt)
+	phaseAt = time.Now()
 
-	s.cleanupTombstones(tombstones)
+	s.cleanupTombstones(tombstones, shardStorages)
+	report.CleanupCost = time.Since(phaseAt)
 
 	s.logger.Info("shards started",
 		s.storeField(),
 		zap.Int("total", totalCount),
 		zap.Int("bootstrap", len(readyBootstrapShards)),
 		zap.Int("tombstone", tombstoneCount))
+
+	progress := s.startup.get()
+	report.Loaded = progress.Loaded
+	report.Started = progress.Started
+	report.TombstonesCleaned = uint64(len(tombstones))
+	report.DestroyingResumed = uint64(len(localDestroyings))
+	report.LocalStatesRestored = uint64(len(localStates))
+	report.OrphanSnapshotsRemoved = atomic.LoadUint64(&s.startup.orphanSnapshots)
+	return report
 }
 
 func (s *store) addReplica(pr *replica) bool {
+	s.createReplicaMu.Lock()
+	defer s.createReplicaMu.Unlock()
 	_, loaded := s.replicas.LoadOrStore(pr.shardID, pr)
 	return !loaded
 }
@@ -567,17 +1056,32 @@ func (s *store) startShardsProxy() {
 		s.OnRequest)
 
 	l := s.logger.Named("proxy").With(s.storeField())
-	sp, err := newShardsProxyBuilder().
+	builder := newShardsProxyBuilder().
 		withLogger(l).
 		withBackendFactory(newBackendFactory(l, s)).
 		withMaxBodySize(maxBodySize).
-		withRPC(rpc).
-		build(s.router)
+		withRPC(rpc)
+	if s.cfg.Proxy.EnableDispatchWorkers {
+		builder = builder.withDispatchWorkers(s.cfg.Proxy.DispatchBatchSize,
+			s.cfg.Proxy.DispatchBatchWindow.Duration)
+	}
+	if s.cfg.Proxy.EnableFlowControl {
+		builder = builder.withFlowControl(s.cfg.Proxy.CreditsHeartbeatInterval.Duration*creditsStaleIntervals,
+			s.cfg.Proxy.MaxPacedRequests)
+	}
+	if s.cfg.Proxy.EnableConsistentRouting {
+		builder = builder.withConsistentRouting(s.cfg.Proxy.RouteDoubtsThreshold,
+			func(req rpcpb.GetShardRouteReq) (rpcpb.GetShardRouteRsp, error) {
+				return s.pd.GetClient().GetShardRoute(req)
+			})
+	}
+	sp, err := builder.build(s.router)
 	if err != nil {
 		s.logger.Fatal("fail to create shards proxy", zap.Error(err))
 	}
 
 	s.shardsProxy = sp
+	s.proxyRPC = rpc
 	err = s.shardsProxy.Start()
 	if err != nil {
 		s.logger.Fatal("fail to start shards proxy",
@@ -602,6 +1106,28 @@ func (s *store) forEachReplica(consumerFunc func(*replica) bool) {
 	})
 }
 
+// hasOverlappedReplica returns true if the key range of the replica is
+// overlapped with another replica of the same group on the store.
+func (s *store) hasOverlappedReplica(id uint64) bool {
+	pr := s.getReplica(id, false)
+	if pr == nil {
+		return false
+	}
+
+	shard := pr.getShard()
+	overlapped := false
+	s.forEachReplica(func(r *replica) bool {
+		if r.shardID == id {
+			return true
+		}
+		other := r.getShard()
+		overlapped = other.Group == shard.Group &&
+			isKeyRangeOverlapped(shard.Start, shard.End, other)
+		return !overlapped
+	})
+	return overlapped
+}
+
 func (s *store) getReplica(id uint64, mustLeader bool) *replica {
 	if value, ok := s.replicas.Load(id); ok {
 		pr := value.(*replica)
@@ -662,7 +1188,7 @@ func (s *store) validateShard(req rpcpb.RequestBatch) (errorpb.Error, bool) {
 		}, true
 	}
 
-	if !pr.isLeader() {
+	if !pr.isLeader() && !isFollowerRead(req) {
 		err := new(errorpb.NotLeader)
 		err.ShardID = shardID
 		err.Leader, _ = s.getReplicaRecord(pr.getLeaderReplicaID())
@@ -706,13 +1232,18 @@ func checkEpoch(shard Shard, req rpcpb.RequestBatch) bool {
 
 	if req.IsAdmin() {
 		switch req.GetAdminCmdType() {
-		case rpcpb.AdminBatchSplit:
+		case rpcpb.AdminBatchSplit, rpcpb.AdminSplitShard:
 			checkVer = true
-		case rpcpb.AdminConfigChange:
+		case rpcpb.AdminConfigChange, rpcpb.AdminConfigChangeV2, rpcpb.AdminBecomeWitness:
 			checkConfVer = true
 		case rpcpb.AdminTransferLeader:
 			checkVer = true
 			checkConfVer = true
+		case rpcpb.AdminBarrier:
+			checkVer = true
+		case rpcpb.AdminPrepareMerge, rpcpb.AdminMergeShard:
+			checkVer = true
+			checkConfVer = true
 		}
 	} else {
 		// for normal command, we don't care conf version.
@@ -844,11 +1375,23 @@ func (s *store) snapshotStatus(shardID uint64,
 				pr.removeSnapshot(ss, false)
 			}
 		case <-s.stopper.ShouldStop():
+			// the transient snapshot is never sent again after restarted
+			if isTransientSnapshot(ss) {
+				if pr := s.getReplica(shardID, true); pr != nil {
+					pr.removeSnapshot(ss, false)
+				}
+			}
 			return
 		}
 	})
 }
 
+// allowResolvedTSAdvance returns true if a ReadIndex can be issued to advance
+// the resolved timestamp of a shard now.
+func (s *store) allowResolvedTSAdvance() bool {
+	return s.resolvedTSLimiter == nil || s.resolvedTSLimiter.TakeAvailable(1) == 1
+}
+
 func (s *store) getReplicaCount() uint64 {
 	n := uint64(0)
 	s.replicas.Range(func(key, value interface{}) bool {
@@ -956,21 +1499,29 @@ func (s *store) getStoreHeartbeat(last time.Time) (rpcpb.StoreHeartbeatReq, erro
 		stats.ShardCount++
 		return true
 	})
-	// FIXME: provide this count from the new implementation
-	// stats.ReceivingSnapCount = s.snapshotManager.ReceiveSnapCount()
+	// the snapshot counts are limited by the max-snapshot-count of the prophet,
+	// the store sending or receiving more snapshots is not selected by the
+	// schedulers until the transfers finished
+	stats.SnapshotProgresses = s.trans.SnapshotProgresses()
+	for _, p := range stats.SnapshotProgresses {
+		if !p.Sending {
+			stats.ReceivingSnapCount++
+		}
+	}
 	stats.SendingSnapCount = s.trans.SendingSnapshotCount()
+	stats.SnapshotThrottled = s.trans.SnapshotThrottled()
 	stats.StartTime = uint64(s.Meta().StartTime)
+	stats.LeaderSoftLimit = s.cfg.Replication.LeaderSoftLimit
+	stats.StoppedGroups = s.groupController.getStoppedGroups()
 
-	s.cfg.Storage.ForeachDataStorageFunc(func(_ uint64, db storage.DataStorage) {
-		st := db.Stats()
-		stats.WrittenBytes += st.WrittenBytes
-		stats.WrittenKeys += st.WrittenKeys
-		stats.ReadKeys += st.ReadKeys
-		stats.ReadBytes += st.ReadBytes
-	})
+	flow := s.getFlowStats()
+	stats.WrittenBytes = flow.WrittenBytes
+	stats.WrittenKeys = flow.WrittenKeys
+	stats.ReadKeys = flow.ReadKeys
+	stats.ReadBytes = flow.ReadBytes
 
-	// TODO: is busy
-	stats.IsBusy = false
+	// the store is busy if the writes are stalled, the balancing avoids it
+	stats.IsBusy = !s.cfg.WriteStall.DisableMitigation && s.writeStalls.hasStalled()
 	stats.Interval = &metapb.TimeInterval{
 		Start: uint64(last.Unix()),
 		End:   uint64(time.Now().Unix()),
@@ -1008,7 +1559,9 @@ func (s *store) startHandleShardHeartbeat() {
 
 func (s *store) doShardHeartbeatRsp(rsp rpcpb.ShardHeartbeatRsp) {
 	if rsp.DestroyDirectly {
-		s.destroyReplica(rsp.ShardID, true, true, "remove by pd")
+		// the data of the duplicate shard overlapped by another local replica
+		// belongs to the other replica
+		s.destroyReplica(rsp.ShardID, true, !s.hasOverlappedReplica(rsp.ShardID), "remove by pd")
 		return
 	}
 
@@ -1035,7 +1588,22 @@ func (s *store) doShardHeartbeatRsp(rsp rpcpb.ShardHeartbeatRsp) {
 			s.storeField(),
 			log.ShardIDField(rsp.ShardID),
 			log.ConfigChangesFieldWithHeartbeatResp("changes", rsp))
-		panic("ConfigChangeV2 request from prophet")
+		req := &rpcpb.ConfigChangeV2Request{}
+		for _, change := range rsp.ConfigChangeV2.Changes {
+			req.Changes = append(req.Changes, rpcpb.ConfigChangeRequest{
+				ChangeType: change.ChangeType,
+				Replica:    change.Replica,
+			})
+		}
+		pr.addAdminRequest(rpcpb.AdminConfigChangeV2, req)
+	} else if rsp.BecomeWitness != nil {
+		s.logger.Info("send become witness request",
+			s.storeField(),
+			log.ShardIDField(rsp.ShardID),
+			log.ReplicaField("replica", rsp.BecomeWitness.Replica))
+		pr.addAdminRequest(rpcpb.AdminBecomeWitness, &rpcpb.BecomeWitnessRequest{
+			Replica: rsp.BecomeWitness.Replica,
+		})
 	} else if rsp.TransferLeader != nil {
 		s.logger.Info("send transfer leader request",
 			s.storeField(),
@@ -1043,10 +1611,40 @@ func (s *store) doShardHeartbeatRsp(rsp rpcpb.ShardHeartbeatRsp) {
 		pr.addAdminRequest(rpcpb.AdminTransferLeader, &rpcpb.TransferLeaderRequest{
 			Replica: rsp.TransferLeader.Replica,
 		})
+	} else if rsp.UpdateLabels != nil {
+		s.logger.Info("send update labels request",
+			s.storeField(),
+			log.ShardIDField(rsp.ShardID),
+			zap.String("policy", rsp.UpdateLabels.Policy.String()))
+		pr.addAdminRequest(rpcpb.AdminUpdateLabels, rsp.UpdateLabels)
+	} else if rsp.Merge != nil {
+		if target, _ := pr.sm.getMergeTarget(); target > 0 {
+			return
+		}
+		var target Shard
+		protoc.MustUnmarshal(&target, rsp.Merge.Target)
+		// the target shard must be known by the local replica with the same range
+		tr := s.getReplica(target.ID, false)
+		if tr == nil || tr.getShard().Epoch.Generation != target.Epoch.Generation {
+			s.logger.Info("skip merge shard",
+				s.storeField(),
+				log.ShardIDField(rsp.ShardID),
+				log.ShardField("target", target),
+				log.ReasonField("target replica not found or stale"))
+			return
+		}
+		s.logger.Info("send prepare merge request",
+			s.storeField(),
+			log.ShardIDField(rsp.ShardID),
+			log.ShardField("target", target))
+		pr.addAdminRequest(rpcpb.AdminPrepareMerge, &rpcpb.PrepareMergeRequest{
+			Target: tr.getShard(),
+		})
 	} else if rsp.SplitShard != nil {
 		// currently, pd only support use keys to splits
 		switch rsp.SplitShard.Policy {
 		case metapb.CheckPolicy_USEKEY:
+			deadline := pr.adminDeadline()
 			shard := pr.getShard()
 			splitIDs, err := pr.store.pd.GetClient().AskBatchSplit(shard, uint32(len(rsp.SplitShard.Keys)))
 			if err != nil {
@@ -1062,12 +1660,49 @@ func (s *store) doShardHeartbeatRsp(rsp rpcpb.ShardHeartbeatRsp) {
 				splitCheckData: splitCheckData{
 					splitKeys: rsp.SplitShard.Keys,
 					splitIDs:  splitIDs,
+					deadline:  deadline,
 				},
 			})
 		}
 	}
 }
 
+// getFlowStats returns the read and written flow of the data storages since
+// the last store heartbeat. The data storages report the accumulated flow,
+// but prophet expects the flow within the heartbeat interval to calculate the
+// read and write rates of the store.
+func (s *store) getFlowStats() stats.Stats {
+	s.flowStatsMu.Lock()
+	defer s.flowStatsMu.Unlock()
+
+	var current stats.Stats
+	s.cfg.Storage.ForeachDataStorageFunc(func(_ uint64, db storage.DataStorage) {
+		st := db.Stats()
+		current.WrittenBytes += st.WrittenBytes
+		current.WrittenKeys += st.WrittenKeys
+		current.ReadKeys += st.ReadKeys
+		current.ReadBytes += st.ReadBytes
+	})
+
+	last := s.flowStatsMu.last
+	s.flowStatsMu.last = current
+	return stats.Stats{
+		WrittenBytes: flowDelta(current.WrittenBytes, last.WrittenBytes),
+		WrittenKeys:  flowDelta(current.WrittenKeys, last.WrittenKeys),
+		ReadKeys:     flowDelta(current.ReadKeys, last.ReadKeys),
+		ReadBytes:    flowDelta(current.ReadBytes, last.ReadBytes),
+	}
+}
+
+// flowDelta returns the flow since the last value, the accumulated value is
+// reset if the data storage was reopened.
+func flowDelta(current, last uint64) uint64 {
+	if current < last {
+		return current
+	}
+	return current - last
+}
+
 type storageStatsReader interface {
 	stats() (storageStats, error)
 }
@@ -14,12 +14,14 @@
 package raftstore
 
 import (
+	"fmt"
 	"math"
 	"time"
 
 	"github.com/fagongzi/util/protoc"
 	"github.com/matrixorigin/matrixcube/keys"
 	"github.com/matrixorigin/matrixcube/pb/metapb"
+	"github.com/matrixorigin/matrixcube/pb/rpcpb"
 	"github.com/matrixorigin/matrixcube/storage"
 	"go.uber.org/zap"
 )
@@ -59,13 +61,26 @@ func (s *store) initMeta() {
 }
 
 func (s *store) doBootstrapCluster(bootstrap bool) {
-	defer s.postBootstrapped()
+	var bulkShards []Shard
+	defer func() {
+		s.postBootstrapped()
+		if len(bulkShards) > 0 {
+			s.bulkCreateInitShards(bulkShards)
+		}
+	}()
 
 	s.logger.Info("begin to bootstrap the cluster",
 		s.storeField())
 	s.initMeta()
 
 	if s.mustLoadStoreMetadata() {
+		// the store restarted before the init shards are created in bulk
+		if bulkShards = s.mustLoadBulkInitShards(); len(bulkShards) > 0 {
+			s.logger.Info("resume to bootstrap the cluster without init shards",
+				s.storeField(),
+				zap.Int("bulk-shards", len(bulkShards)))
+			s.mustPutBulkBootstrapped()
+		}
 		return
 	}
 
@@ -90,12 +105,37 @@ func (s *store) doBootstrapCluster(bootstrap bool) {
 			zap.Bool("bootstrapped", ok))
 
 		if !ok {
+			var shards []Shard
+			if s.cfg.Customize.CustomInitShardsFactory != nil {
+				shards = s.cfg.Customize.CustomInitShardsFactory()
+			}
+			if n := s.cfg.Replication.BulkBootstrapShards; n > 0 && len(shards) > n {
+				s.logger.Info("begin to bootstrap the cluster without init shards",
+					s.storeField(),
+					zap.Int("bulk-shards", len(shards)))
+				// the init shards are persisted before the cluster is bootstrapped,
+				// otherwise they are lost if the store restarts before they are created.
+				for idx := range shards {
+					if shards[idx].Unique == "" {
+						shards[idx].Unique = fmt.Sprintf("init-shard-%d", idx)
+					}
+				}
+				s.mustSaveBulkInitShards(shards)
+				if !s.mustPutBulkBootstrapped() {
+					s.logger.Info("the cluster is already bootstrapped, skip init shards",
+						s.storeField())
+					s.mustRemoveBulkInitShards()
+					return
+				}
+				bulkShards = shards
+				return
+			}
+
 			s.logger.Info("begin to bootstrap the cluster with init shards",
 				s.storeField())
 			var initShards []Shard
 			var resources []*Shard
 			if s.cfg.Customize.CustomInitShardsFactory != nil {
-				shards := s.cfg.Customize.CustomInitShardsFactory()
 				for _, shard := range shards {
 					s.doCreateInitShard(&shard)
 					initShards = append(initShards, shard)
@@ -135,6 +175,88 @@ func (s *store) postBootstrapped() {
 	close(s.pdStartedC)
 }
 
+// bulkCreateInitShards creates the init shards by prophet in bulk. Prophet
+// assigns the replicas of the init shards to the stores, and the stores create
+// the replicas in parallel once the creation event is received. The init shards
+// are given the uniques before they are persisted, so the retries and the resumes
+// after restart are idempotent.
+func (s *store) bulkCreateInitShards(shards []Shard) {
+	s.logger.Info("begin to create init shards in bulk",
+		s.storeField(),
+		zap.Int("count", len(shards)))
+	for {
+		err := s.pd.GetClient().AsyncBulkAddShards(shards...)
+		if err == nil {
+			break
+		}
+
+		s.logger.Error("failed to create init shards in bulk, retry later",
+			s.storeField(),
+			zap.Error(err))
+		select {
+		case <-s.stopper.ShouldStop():
+			return
+		case <-time.After(time.Second):
+		}
+	}
+	s.mustRemoveBulkInitShards()
+	s.logger.Info("init shards created in bulk",
+		s.storeField(),
+		zap.Int("count", len(shards)))
+}
+
+// mustPutBulkBootstrapped bootstraps the cluster without the init shards, returns
+// false if the cluster is already bootstrapped.
+func (s *store) mustPutBulkBootstrapped() bool {
+	ok, err := s.pd.GetStorage().PutBootstrapped(s.meta)
+	if err != nil {
+		s.logger.Fatal("failed to bootstrap cluster",
+			s.storeField(),
+			zap.Error(err))
+	}
+	return ok
+}
+
+func (s *store) mustSaveBulkInitShards(shards []Shard) {
+	req := rpcpb.CreateShardsReq{Bulk: true}
+	for _, shard := range shards {
+		req.Shards = append(req.Shards, protoc.MustMarshal(&shard))
+	}
+	if err := s.kvStorage.Set(keys.GetBulkInitShardsKey(), protoc.MustMarshal(&req), true); err != nil {
+		s.logger.Fatal("failed to save init shards",
+			s.storeField(),
+			zap.Error(err))
+	}
+}
+
+func (s *store) mustLoadBulkInitShards() []Shard {
+	data, err := s.kvStorage.Get(keys.GetBulkInitShardsKey())
+	if err != nil {
+		s.logger.Fatal("failed to load init shards",
+			s.storeField(),
+			zap.Error(err))
+	}
+	if len(data) == 0 {
+		return nil
+	}
+
+	req := rpcpb.CreateShardsReq{}
+	protoc.MustUnmarshal(&req, data)
+	shards := make([]Shard, len(req.Shards))
+	for idx, v := range req.Shards {
+		protoc.MustUnmarshal(&shards[idx], v)
+	}
+	return shards
+}
+
+func (s *store) mustRemoveBulkInitShards() {
+	if err := s.kvStorage.Delete(keys.GetBulkInitShardsKey(), true); err != nil {
+		s.logger.Fatal("failed to remove init shards",
+			s.storeField(),
+			zap.Error(err))
+	}
+}
+
 func (s *store) mustPutStore() {
 	for {
 		if err := s.pd.GetClient().PutStore(s.meta); err != nil {
@@ -164,6 +286,23 @@ func (s *store) mustSaveStoreMetadata() {
 		s.logger.Fatal("local store is not empty and has already hard data",
 			s.storeField())
 	}
+	// the data storage with shards but without the store identity belongs to
+	// another store, joining the cluster with it corrupts the cluster metadata.
+	s.cfg.Storage.ForeachDataStorageFunc(func(group uint64, ds storage.DataStorage) {
+		initStates, err := ds.GetInitialStates()
+		if err != nil {
+			s.logger.Fatal("failed to check data storage",
+				s.storeField(),
+				zap.Uint64("group", group),
+				zap.Error(err))
+		}
+		if len(initStates) > 0 {
+			s.logger.Fatal("data storage is not empty but the store identity is missing",
+				s.storeField(),
+				zap.Uint64("group", group),
+				zap.Int("shards", len(initStates)))
+		}
+	})
 
 	v := &metapb.StoreIdent{
 		StoreID:   s.meta.GetID(),
@@ -195,6 +334,11 @@ func (s *store) mustLoadStoreMetadata() bool {
 				zap.Uint64("local", v.ClusterID),
 				zap.Uint64("prophet", s.pd.GetClusterID()))
 		}
+		if v.StoreID == 0 {
+			s.logger.Fatal("invalid local store metadata",
+				s.storeField(),
+				zap.Uint64("cluster", v.ClusterID))
+		}
 
 		s.meta.SetID(v.StoreID)
 		s.logger.Info("load local store metadata",
@@ -219,7 +363,7 @@ func (s *store) doCreateInitShard(shard *Shard) {
 }
 
 func (s *store) removeInitShards(shards ...Shard) {
-	doWithShardsByGroupID(s.DataStorageByGroup, func(ds storage.DataStorage, v []Shard) {
+	doWithShardsByDataStorage(s.DataStorageByShard, func(ds storage.DataStorage, v []Shard) {
 		for _, shard := range v {
 			if err := ds.RemoveShard(shard, true); err != nil {
 				s.logger.Fatal("failed to remove init shards",
@@ -16,6 +16,13 @@ package raftstore
 import (
 	"testing"
 
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/matrixorigin/matrixcube/components/log"
+	"github.com/matrixorigin/matrixcube/pb/metapb"
 	"github.com/matrixorigin/matrixcube/util/leaktest"
 )
 
@@ -28,3 +35,24 @@ func TestDoBootstrapCluster(t *testing.T) {
 
 	c.Restart()
 }
+
+func TestSaveStoreMetadataWithNonEmptyDataStorage(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	s, cancel := newTestStore(t)
+	defer cancel()
+	s.logger = log.GetDefaultZapLogger(zap.OnFatal(zapcore.WriteThenPanic))
+
+	ds := s.DataStorageByGroup(0)
+	assert.NoError(t, ds.SaveShardMetadata([]metapb.ShardMetadata{
+		{
+			ShardID:  1,
+			LogIndex: 1,
+			Metadata: metapb.ShardLocalState{
+				Shard: Shard{ID: 1, Replicas: []Replica{{ID: 1, StoreID: 100}}},
+			},
+		},
+	}))
+	require.NoError(t, ds.Sync(nil))
+	assert.Panics(t, func() { s.mustSaveStoreMetadata() })
+}
@@ -0,0 +1,65 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package raftstore
+
+import (
+	"go.uber.org/zap"
+
+	"github.com/matrixorigin/matrixcube/storage"
+)
+
+// dropFilteredData drops the data filtered by the compaction filter of the data
+// storages of the group on the store. The data is filtered at the GC safe point of
+// the group managed by prophet, the data storages keep the filtering disabled until
+// the safe point is set.
+func (s *store) dropFilteredData(group uint64) {
+	safePoint, err := s.pd.GetClient().GetGCSafePoint(group)
+	if err != nil {
+		s.logger.Error("fail to get gc safe point",
+			s.storeField(),
+			zap.Uint64("group", group),
+			zap.Error(err))
+		return
+	}
+	if safePoint == 0 {
+		return
+	}
+
+	s.cfg.Storage.ForeachDataStorageFunc(func(g uint64, ds storage.DataStorage) {
+		if g != group {
+			return
+		}
+		cds, ok := ds.(storage.CompactionFilterDataStorage)
+		if !ok {
+			return
+		}
+
+		cds.SetGCSafePoint(safePoint)
+		n, err := cds.DropFilteredData()
+		if err != nil {
+			s.logger.Error("fail to drop filtered data",
+				s.storeField(),
+				zap.Uint64("group", group),
+				zap.Error(err))
+			return
+		}
+		if n > 0 {
+			s.logger.Debug("filtered data dropped",
+				s.storeField(),
+				zap.Uint64("group", group),
+				zap.Uint64("safe-point", safePoint),
+				zap.Uint64("keys", n))
+		}
+	})
+}
@@ -0,0 +1,234 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package raftstore
+
+import (
+	"context"
+	"math"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/matrixorigin/matrixcube/components/log"
+	"github.com/matrixorigin/matrixcube/pb/metapb"
+	"go.etcd.io/etcd/raft/v3/raftpb"
+)
+
+var (
+	drainCheckInterval = time.Millisecond * 10
+)
+
+// drain is the pre-stop phase of the store, the leaderships of the replicas are
+// transferred away and the queued requests of the proxy are flushed before the
+// store stops, until the StopDrainTimeout expired. The requests of the leaders
+// in transferring are rejected with the transfer targets as the leader hints,
+// so the clients switch to the new leaders once the transfers are done instead
+// of waiting for the elections after the store stopped.
+func (s *store) drain() {
+	timeout := s.cfg.Raft.StopDrainTimeout.Duration
+	if timeout <= 0 {
+		return
+	}
+
+	atomic.AddUint32(&s.draining, 1)
+	s.logger.Info("begin to drain",
+		s.storeField(),
+		zap.Duration("timeout", timeout))
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	start := time.Now()
+	leaders, queued, err := s.drainLeaders(ctx, true)
+	if err != nil {
+		s.logger.Warn("drain timeout",
+			s.storeField(),
+			zap.Int("leaders", leaders),
+			zap.Int64("queued", queued))
+	}
+
+	s.logger.Info("drain completed",
+		s.storeField(),
+		zap.Int("leaders", leaders),
+		zap.Duration("cost", time.Since(start)))
+}
+
+// DrainLeadership transfers the leaderships of the replicas on the store to the
+// healthy replicas of the other stores, e.g. before restarting the store in a
+// rolling upgrade. The replicas of the store reject the new leader elections
+// from the beginning of the drain until the DrainGracePeriod after it returned,
+// so the leaderships are not taken back before the store is restarted. Returns
+// nil once no leader left on the store, or the error of the ctx if it's done
+// first.
+func (s *store) DrainLeadership(ctx context.Context) error {
+	s.rejectElections(time.Unix(0, math.MaxInt64))
+	defer s.rejectElections(time.Now().Add(s.cfg.Raft.DrainGracePeriod.Duration))
+
+	atomic.AddUint32(&s.draining, 1)
+	defer atomic.AddUint32(&s.draining, ^uint32(0))
+	s.logger.Info("begin to drain leadership",
+		s.storeField())
+
+	start := time.Now()
+	leaders, _, err := s.drainLeaders(ctx, false)
+	if err != nil {
+		s.logger.Warn("drain leadership not completed",
+			s.storeField(),
+			zap.Int("leaders", leaders),
+			zap.Error(err))
+		return err
+	}
+
+	s.logger.Info("drain leadership completed",
+		s.storeField(),
+		zap.Duration("cost", time.Since(start)))
+	return nil
+}
+
+// drainLeaders transfers the leaderships of the replicas away until no leader
+// left on the store and, if waitQueued, no request queued in the proxy. Returns
+// the number of the leaders and the queued requests left if the ctx is done
+// first.
+func (s *store) drainLeaders(ctx context.Context, waitQueued bool) (int, int64, error) {
+	ticker := time.NewTicker(drainCheckInterval)
+	defer ticker.Stop()
+	// the failed transfers are retried every election timeout, the transfer is
+	// aborted by raft if it's not done in an election timeout.
+	retryInterval := s.cfg.Raft.GetElectionTimeoutDuration()
+	var lastTransfer time.Time
+	for {
+		now := time.Now()
+		if now.Sub(lastTransfer) >= retryInterval {
+			lastTransfer = now
+			s.forEachReplica(func(pr *replica) bool {
+				if pr.isLeader() {
+					pr.addAction(action{actionType: drainLeaderAction})
+				}
+				return true
+			})
+		}
+
+		leaders, queued := s.getDrainState()
+		if !waitQueued {
+			queued = 0
+		}
+		if leaders == 0 && queued == 0 {
+			return 0, 0, nil
+		}
+		select {
+		case <-ctx.Done():
+			return leaders, queued, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *store) isDraining() bool {
+	return atomic.LoadUint32(&s.draining) > 0
+}
+
+// rejectElections makes the replicas of the store reject the new leader
+// elections until the specified time.
+func (s *store) rejectElections(until time.Time) {
+	atomic.StoreInt64(&s.rejectElectionsUntil, until.UnixNano())
+}
+
+// isRejectingElections returns true if the replicas of the store neither
+// campaign nor accept the leader transfers, see DrainLeadership.
+func (s *store) isRejectingElections() bool {
+	return time.Now().UnixNano() < atomic.LoadInt64(&s.rejectElectionsUntil)
+}
+
+// getDrainState returns the number of the leaders on the store and the number
+// of the requests queued in the proxy.
+func (s *store) getDrainState() (int, int64) {
+	leaders := 0
+	s.forEachReplica(func(pr *replica) bool {
+		if pr.isLeader() {
+			leaders++
+		}
+		return true
+	})
+	queued := int64(0)
+	if p, ok := s.shardsProxy.(*shardsProxy); ok {
+		queued = p.queued()
+	}
+	return leaders, queued
+}
+
+// drainLeader transfers the leadership to the most up to date full voter when
+// the store is draining.
+func (pr *replica) drainLeader() {
+	if !pr.isLeader() {
+		return
+	}
+	// has pending confChange, retry later
+	if pr.rn.PendingConfIndex() > pr.appliedIndex {
+		return
+	}
+
+	target, ok := pr.getDrainTarget()
+	if !ok {
+		pr.logger.Warn("no replica to transfer leadership for drain")
+		return
+	}
+	pr.logger.Info("transfer leadership for drain",
+		log.ReplicaField("to", target))
+	pr.drainTarget = target
+	pr.preUpdateRouterLeader(target.ID)
+	pr.doTransferLeader(target)
+}
+
+// getDrainTarget returns the full voter with the max matched index which is
+// allowed to be the new leader.
+func (pr *replica) getDrainTarget() (Replica, bool) {
+	status := pr.rn.Status()
+	var target Replica
+	match := uint64(0)
+	for _, r := range pr.getShard().Replicas {
+		if r.ID == pr.replicaID || r.IsWitness ||
+			r.Role != metapb.ReplicaRole_Voter ||
+			!pr.isTransferLeaderAllowed(r) {
+			continue
+		}
+		// the replicas not active recently may be stopped, transferring the
+		// leadership to them never completes
+		p, ok := status.Progress[r.ID]
+		if !ok || !p.RecentActive {
+			continue
+		}
+		if target.ID == 0 || p.Match > match {
+			target, match = r, p.Match
+		}
+	}
+	return target, target.ID != 0
+}
+
+// checkDrainProposal rejects the read and write requests of the leader whose
+// leadership is being transferred for drain, the transfer target is the leader
+// hint of the NotLeader response.
+func (pr *replica) checkDrainProposal(c batch) bool {
+	if c.requestBatch.IsAdmin() || pr.drainTarget.ID == 0 ||
+		!pr.store.isDraining() || !pr.isLeader() {
+		return true
+	}
+	c.respNotLeader(pr.shardID, pr.drainTarget)
+	return false
+}
+
+// isElectionMessage returns true if the message is sent by the replica to ask for
+// the votes of the other replicas.
+func isElectionMessage(msg raftpb.Message) bool {
+	return msg.Type == raftpb.MsgPreVote || msg.Type == raftpb.MsgVote
+}
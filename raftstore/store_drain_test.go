@@ -0,0 +1,87 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package raftstore
+
+import (
+	"testing"
+	"time"
+
+	"github.com/matrixorigin/matrixcube/pb/rpcpb"
+	"github.com/matrixorigin/matrixcube/util/leaktest"
+	"github.com/stretchr/testify/assert"
+	"go.etcd.io/etcd/raft/v3/raftpb"
+)
+
+func TestCheckDrainProposal(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	s, cancel := newTestStore(t)
+	defer cancel()
+
+	pr := newTestReplica(Shard{ID: 1}, Replica{ID: 1}, s)
+	pr.leaderID = 1
+
+	var responses []rpcpb.ResponseBatch
+	cb := func(resp rpcpb.ResponseBatch) {
+		responses = append(responses, resp)
+	}
+	write := newTestBatch("w1", "k1", 1, rpcpb.Write, 0, cb)
+	admin := newBatch(nil, newTestAdminRequestBatch("a1", 0, rpcpb.AdminCompactShard, nil), cb, 0, 0)
+
+	// not draining
+	pr.drainTarget = Replica{ID: 2}
+	assert.True(t, pr.checkDrainProposal(write))
+
+	s.draining = 1
+	assert.True(t, pr.checkDrainProposal(admin))
+	assert.False(t, pr.checkDrainProposal(write))
+	assert.Equal(t, 1, len(responses))
+	assert.Equal(t, Replica{ID: 2}, responses[0].Header.Error.NotLeader.Leader)
+
+	// no transfer in progress
+	pr.drainTarget = Replica{}
+	assert.True(t, pr.checkDrainProposal(write))
+
+	// not leader
+	pr.drainTarget = Replica{ID: 2}
+	pr.leaderID = 2
+	assert.True(t, pr.checkDrainProposal(write))
+}
+
+func TestDrainWithoutTimeout(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	s, cancel := newTestStore(t)
+	defer cancel()
+
+	s.drain()
+	assert.False(t, s.isDraining())
+}
+
+func TestRejectElections(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	s, cancel := newTestStore(t)
+	defer cancel()
+
+	assert.False(t, s.isRejectingElections())
+	s.rejectElections(time.Now().Add(time.Minute))
+	assert.True(t, s.isRejectingElections())
+	s.rejectElections(time.Now().Add(-time.Second))
+	assert.False(t, s.isRejectingElections())
+
+	assert.True(t, isElectionMessage(raftpb.Message{Type: raftpb.MsgPreVote}))
+	assert.True(t, isElectionMessage(raftpb.Message{Type: raftpb.MsgVote}))
+	assert.False(t, isElectionMessage(raftpb.Message{Type: raftpb.MsgVoteResp}))
+}
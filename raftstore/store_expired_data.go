@@ -0,0 +1,43 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package raftstore
+
+import (
+	"github.com/matrixorigin/matrixcube/pb/rpcpb"
+	"github.com/matrixorigin/matrixcube/storage"
+)
+
+// purgeExpiredDataBatchKeys the max number of the keys scanned by an
+// AdminPurgeExpiredData request.
+const purgeExpiredDataBatchKeys = 1024
+
+// purgeExpiredData proposes the removal of the expired data of the shards of the
+// group whose leaders are on the store. The purge goes through raft with the
+// timestamp assigned by the leader, so all the replicas remove the same data.
+// Each request removes a bounded batch of the shard data, the next batch is
+// proposed once the previous one applied.
+func (s *store) purgeExpiredData(group uint64) {
+	s.forEachReplica(func(pr *replica) bool {
+		if pr.group != group || !pr.isLeader() {
+			return true
+		}
+		// the shards of the group may be in different data storages
+		if _, ok := pr.sm.getDataStorage().(storage.TTLDataStorage); !ok {
+			return true
+		}
+		pr.addAdminRequest(rpcpb.AdminPurgeExpiredData,
+			&rpcpb.PurgeExpiredDataRequest{})
+		return true
+	})
+}
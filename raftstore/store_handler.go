@@ -41,6 +41,10 @@ func (s *store) onRaftMessage(msg metapb.RaftMessage) {
 		return
 	}
 
+	if s.groupController.isGroupStopped(msg.Group) {
+		return
+	}
+
 	if msg.IsTombstone {
 		// we receive a message tells us to remove ourself.
 		s.handleDestroyReplicaMessage(msg)
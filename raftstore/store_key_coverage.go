@@ -0,0 +1,191 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package raftstore
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/matrixorigin/matrixcube/pb/rpcpb"
+)
+
+// The reasons of the key coverage gaps and the store not match diagnostics.
+const (
+	// ReasonNoLocalReplica the router has the shard on the store, but there is
+	// no replica of it on the store, e.g. the replica is not created yet or is
+	// already removed.
+	ReasonNoLocalReplica = "no local replica"
+	// ReasonNotInRouter the shard of the local replica is not in the router.
+	ReasonNotInRouter = "shard not in router"
+	// ReasonStoreNotInRouter the router has the shard of the local replica, but
+	// not the replica on the store.
+	ReasonStoreNotInRouter = "store not in router replicas"
+	// ReasonStaleLocalShard the local shard has an older epoch than the router.
+	ReasonStaleLocalShard = "stale local shard"
+	// ReasonStaleRouterShard the router has an older epoch of the local shard.
+	ReasonStaleRouterShard = "stale router shard"
+	// ReasonRangeMismatch the local shard and the router have the same epoch of
+	// the shard with different key ranges.
+	ReasonRangeMismatch = "range mismatch"
+	// ReasonNoLocalShardCoversKey no local shard of the group covers the key.
+	ReasonNoLocalShardCoversKey = "no local shard covers key"
+	// ReasonReplicaClosed the local replica is closed and rejects the requests.
+	ReasonReplicaClosed = "replica closed"
+)
+
+// KeyCoverageGap is a key range of the shard group whose coverage by the local
+// shards of the store differs from the router.
+type KeyCoverageGap struct {
+	// Start, End is the key range [Start, End) of the gap
+	Start []byte `json:"start"`
+	End   []byte `json:"end"`
+	// LocalShard is the local shard covering the range, empty if none
+	LocalShard Shard `json:"local-shard"`
+	// RouterShard is the shard covering the range in the router, empty if none
+	RouterShard Shard `json:"router-shard"`
+	// Reason is why the coverage differs
+	Reason string `json:"reason"`
+}
+
+// StoreNotMatchDiagnostic explains why a request would be rejected with the
+// store not match error by the store.
+type StoreNotMatchDiagnostic struct {
+	StoreID uint64 `json:"store-id"`
+	// Matched is true if the request is handled by a local replica instead of
+	// being rejected with the store not match error
+	Matched bool `json:"matched"`
+	// Reason is why the request is rejected, empty if matched
+	Reason string `json:"reason,omitempty"`
+	// LocalShard is the local shard selected for the request, empty if none
+	LocalShard Shard `json:"local-shard"`
+	// RouterShard is the shard selected for the request by the router, empty
+	// if none
+	RouterShard Shard `json:"router-shard"`
+	// Gap is the coverage gap of the router shard or the local shard, nil if
+	// their coverages are the same
+	Gap *KeyCoverageGap `json:"gap,omitempty"`
+}
+
+func (s *store) GetShardByKey(group uint64, key []byte) (Shard, bool) {
+	pr, err := s.selectShard(group, key)
+	if err != nil {
+		return Shard{}, false
+	}
+	return pr.getShard(), true
+}
+
+func (s *store) GetKeyCoverageGaps(group uint64) []KeyCoverageGap {
+	storeID := s.Meta().ID
+	var gaps []KeyCoverageGap
+	checked := make(map[uint64]struct{})
+	s.forEachReplica(func(pr *replica) bool {
+		if pr.group != group {
+			return true
+		}
+		checked[pr.shardID] = struct{}{}
+		if gap := s.checkKeyCoverage(storeID, pr.getShard(),
+			s.router.GetShard(pr.shardID), true); gap != nil {
+			gaps = append(gaps, *gap)
+		}
+		return true
+	})
+	s.router.ForeachShards(group, func(shard Shard) bool {
+		if _, ok := checked[shard.ID]; ok {
+			return true
+		}
+		if gap := s.checkKeyCoverage(storeID, Shard{}, shard, false); gap != nil {
+			gaps = append(gaps, *gap)
+		}
+		return true
+	})
+
+	sort.Slice(gaps, func(i, j int) bool {
+		return bytes.Compare(gaps[i].Start, gaps[j].Start) < 0
+	})
+	return gaps
+}
+
+func (s *store) DiagnoseStoreNotMatch(req rpcpb.Request) StoreNotMatchDiagnostic {
+	storeID := s.Meta().ID
+	d := StoreNotMatchDiagnostic{StoreID: storeID}
+
+	var pr *replica
+	if req.ToShard > 0 {
+		d.RouterShard = s.router.GetShard(req.ToShard)
+		pr = s.getReplica(req.ToShard, false)
+	} else {
+		d.RouterShard, _ = s.router.SelectShardWithPolicy(req.Group, req.Key,
+			rpcpb.SelectLeader)
+		if shard := s.searchShard(req.Group, req.Key); shard.ID > 0 {
+			pr = s.getReplica(shard.ID, false)
+		}
+	}
+
+	if pr == nil {
+		d.Gap = s.checkKeyCoverage(storeID, Shard{}, d.RouterShard, false)
+		switch {
+		case d.Gap != nil:
+			d.Reason = d.Gap.Reason
+		case req.ToShard > 0:
+			d.Reason = ReasonNoLocalReplica
+		default:
+			d.Reason = ReasonNoLocalShardCoversKey
+		}
+		return d
+	}
+
+	d.LocalShard = pr.getShard()
+	if d.RouterShard.ID == d.LocalShard.ID {
+		d.Gap = s.checkKeyCoverage(storeID, d.LocalShard, d.RouterShard, true)
+	}
+	if pr.closed() {
+		d.Reason = ReasonReplicaClosed
+		return d
+	}
+	d.Matched = true
+	return d
+}
+
+// checkKeyCoverage returns the coverage gap of the local shard and the router
+// shard of the same shard, nil if there is no gap. The local shard is empty if
+// there is no local replica of the shard.
+func (s *store) checkKeyCoverage(storeID uint64, local, router Shard,
+	hasLocal bool) *KeyCoverageGap {
+	gap := &KeyCoverageGap{LocalShard: local, RouterShard: router}
+	if !hasLocal {
+		if router.ID == 0 || findReplica(router, storeID) == nil {
+			return nil
+		}
+		gap.Start, gap.End = router.Start, router.End
+		gap.Reason = ReasonNoLocalReplica
+		return gap
+	}
+
+	gap.Start, gap.End = local.Start, local.End
+	switch {
+	case router.ID == 0:
+		gap.Reason = ReasonNotInRouter
+	case local.Epoch.Generation < router.Epoch.Generation:
+		gap.Reason = ReasonStaleLocalShard
+	case local.Epoch.Generation > router.Epoch.Generation:
+		gap.Reason = ReasonStaleRouterShard
+	case !bytes.Equal(local.Start, router.Start) || !bytes.Equal(local.End, router.End):
+		gap.Reason = ReasonRangeMismatch
+	case findReplica(router, storeID) == nil:
+		gap.Reason = ReasonStoreNotInRouter
+	default:
+		return nil
+	}
+	return gap
+}
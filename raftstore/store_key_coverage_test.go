@@ -0,0 +1,126 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package raftstore
+
+import (
+	"testing"
+
+	"github.com/matrixorigin/matrixcube/pb/metapb"
+	"github.com/matrixorigin/matrixcube/pb/rpcpb"
+	"github.com/matrixorigin/matrixcube/util/leaktest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestKeyCoverageStore(t *testing.T) (*store, func()) {
+	s, cancel := newTestStore(t)
+	s.meta.ID = 1
+	rr, err := newRouterBuilder().build(make(chan rpcpb.EventNotify))
+	require.NoError(t, err)
+	s.router = rr
+
+	onStore := []Replica{{ID: 1, StoreID: 1}}
+	offStore := []Replica{{ID: 2, StoreID: 2}}
+	local := []Shard{
+		{ID: 1, Start: []byte("a"), End: []byte("b"), Replicas: onStore},
+		{ID: 2, Start: []byte("b"), End: []byte("c"), Replicas: onStore,
+			Epoch: metapb.ShardEpoch{Generation: 1}},
+		{ID: 4, Start: []byte("d"), End: []byte("e"), Replicas: onStore},
+	}
+	for _, shard := range local {
+		pr := &replica{shardID: shard.ID, group: shard.Group}
+		pr.sm = &stateMachine{}
+		pr.sm.metadataMu.shard = shard
+		s.addReplica(pr)
+	}
+	s.updateShardKeyRange(0, local...)
+
+	// shard 2 is split in the router, shard 3 is not created on the store, the
+	// shard 4 is not in the router and the shard 5 has no replica on the store
+	rr.UpdateShard(local[0])
+	rr.UpdateShard(Shard{ID: 2, Start: []byte("b"), End: []byte("bb"), Replicas: onStore,
+		Epoch: metapb.ShardEpoch{Generation: 2}})
+	rr.UpdateShard(Shard{ID: 3, Start: []byte("c"), End: []byte("d"), Replicas: onStore})
+	rr.UpdateShard(Shard{ID: 5, Start: []byte("e"), End: []byte("f"), Replicas: offStore})
+	return s, cancel
+}
+
+func TestGetShardByKey(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	s, cancel := newTestKeyCoverageStore(t)
+	defer cancel()
+
+	shard, ok := s.GetShardByKey(0, []byte("a1"))
+	assert.True(t, ok)
+	assert.Equal(t, uint64(1), shard.ID)
+
+	shard, ok = s.GetShardByKey(0, []byte("bb1"))
+	assert.True(t, ok)
+	assert.Equal(t, uint64(2), shard.ID)
+
+	_, ok = s.GetShardByKey(0, []byte("c1"))
+	assert.False(t, ok)
+	_, ok = s.GetShardByKey(1, []byte("a1"))
+	assert.False(t, ok)
+}
+
+func TestGetKeyCoverageGaps(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	s, cancel := newTestKeyCoverageStore(t)
+	defer cancel()
+
+	gaps := s.GetKeyCoverageGaps(0)
+	require.Equal(t, 3, len(gaps))
+	assert.Equal(t, uint64(2), gaps[0].LocalShard.ID)
+	assert.Equal(t, ReasonStaleLocalShard, gaps[0].Reason)
+	assert.Equal(t, uint64(3), gaps[1].RouterShard.ID)
+	assert.Equal(t, uint64(0), gaps[1].LocalShard.ID)
+	assert.Equal(t, ReasonNoLocalReplica, gaps[1].Reason)
+	assert.Equal(t, uint64(4), gaps[2].LocalShard.ID)
+	assert.Equal(t, ReasonNotInRouter, gaps[2].Reason)
+
+	assert.Empty(t, s.GetKeyCoverageGaps(1))
+}
+
+func TestDiagnoseStoreNotMatch(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	s, cancel := newTestKeyCoverageStore(t)
+	defer cancel()
+
+	d := s.DiagnoseStoreNotMatch(rpcpb.Request{Key: []byte("a1")})
+	assert.True(t, d.Matched)
+	assert.Equal(t, uint64(1), d.LocalShard.ID)
+	assert.Nil(t, d.Gap)
+
+	d = s.DiagnoseStoreNotMatch(rpcpb.Request{Key: []byte("c1")})
+	assert.False(t, d.Matched)
+	assert.Equal(t, ReasonNoLocalReplica, d.Reason)
+	assert.Equal(t, uint64(3), d.RouterShard.ID)
+
+	d = s.DiagnoseStoreNotMatch(rpcpb.Request{Key: []byte("e1")})
+	assert.False(t, d.Matched)
+	assert.Equal(t, ReasonNoLocalShardCoversKey, d.Reason)
+
+	d = s.DiagnoseStoreNotMatch(rpcpb.Request{ToShard: 2})
+	assert.True(t, d.Matched)
+	require.NotNil(t, d.Gap)
+	assert.Equal(t, ReasonStaleLocalShard, d.Gap.Reason)
+
+	d = s.DiagnoseStoreNotMatch(rpcpb.Request{ToShard: 5})
+	assert.False(t, d.Matched)
+	assert.Equal(t, ReasonNoLocalReplica, d.Reason)
+}
@@ -0,0 +1,198 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package raftstore
+
+import (
+	"bytes"
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/matrixorigin/matrixcube/components/log"
+	"github.com/matrixorigin/matrixcube/storage"
+)
+
+var (
+	errCompactRangeRunning = errors.New("compact range of the group is running")
+)
+
+// CompactRangeProgress is the progress of the manual compaction of the local
+// shards of a group in a key range, see `Store.CompactRange`.
+type CompactRangeProgress struct {
+	// Group the shard group
+	Group uint64
+	// Start the start key of the range
+	Start []byte
+	// End the end key of the range, empty means the max key
+	End []byte
+	// Total the number of the local shards in the range to compact
+	Total uint64
+	// Compacted the number of the shards compacted
+	Compacted uint64
+	// Failed the number of the shards failed to compact
+	Failed uint64
+	// Done whether all the shards are processed
+	Done bool
+	// Cost the time spent on the compaction until done
+	Cost time.Duration
+}
+
+// compactRangeJob tracks the progress of a CompactRange, the shards of the job
+// are compacted one by one by the vacuum cleaner.
+type compactRangeJob struct {
+	group     uint64
+	start     []byte
+	end       []byte
+	total     uint64
+	compacted uint64
+	failed    uint64
+	createdAt time.Time
+	cost      int64
+}
+
+func (j *compactRangeJob) done() bool {
+	return atomic.LoadUint64(&j.compacted)+atomic.LoadUint64(&j.failed) >= j.total
+}
+
+// finish records the result of a shard compaction, returns true if it's the
+// last shard of the job.
+func (j *compactRangeJob) finish(err error) bool {
+	if err != nil {
+		atomic.AddUint64(&j.failed, 1)
+	} else {
+		atomic.AddUint64(&j.compacted, 1)
+	}
+	if j.done() {
+		atomic.StoreInt64(&j.cost, int64(time.Since(j.createdAt)))
+		return true
+	}
+	return false
+}
+
+func (j *compactRangeJob) progress() CompactRangeProgress {
+	return CompactRangeProgress{
+		Group:     j.group,
+		Start:     j.start,
+		End:       j.end,
+		Total:     j.total,
+		Compacted: atomic.LoadUint64(&j.compacted),
+		Failed:    atomic.LoadUint64(&j.failed),
+		Done:      j.done(),
+		Cost:      time.Duration(atomic.LoadInt64(&j.cost)),
+	}
+}
+
+func (s *store) CompactRange(group uint64, start, end []byte) error {
+	if _, ok := s.DataStorageByGroup(group).(storage.ManualCompactionStorage); !ok {
+		return storage.ErrManualCompactionNotSupported
+	}
+	if v, ok := s.compactRanges.Load(group); ok && !v.(*compactRangeJob).done() {
+		return errCompactRangeRunning
+	}
+
+	var ranges []keyRange
+	var storages []storage.DataStorage
+	s.forEachReplica(func(pr *replica) bool {
+		if pr.group != group || pr.isWitness() {
+			return true
+		}
+		shard := pr.getShard()
+		if isKeyRangeOverlapped(start, end, shard) {
+			ranges = append(ranges, clipKeyRange(start, end, shard))
+			storages = append(storages, pr.sm.getDataStorage())
+		}
+		return true
+	})
+
+	job := &compactRangeJob{
+		group:     group,
+		start:     start,
+		end:       end,
+		total:     uint64(len(ranges)),
+		createdAt: time.Now(),
+	}
+	s.compactRanges.Store(group, job)
+	s.logger.Info("compact range started",
+		s.storeField(),
+		zap.Uint64("group", group),
+		log.HexField("from", start),
+		log.HexField("to", end),
+		zap.Int("shards", len(ranges)))
+	for i := range ranges {
+		s.vacuumCleaner.addTask(vacuumTask{
+			group:        group,
+			dataStorage:  storages[i],
+			compactRange: &ranges[i],
+			compactJob:   job,
+			reason:       "compact-range",
+		})
+	}
+	return nil
+}
+
+func (s *store) GetCompactRangeProgress(group uint64) (CompactRangeProgress, bool) {
+	v, ok := s.compactRanges.Load(group)
+	if !ok {
+		return CompactRangeProgress{}, false
+	}
+	return v.(*compactRangeJob).progress(), true
+}
+
+// compactRange compacts the key range of the group in the data storage, the
+// failures are only logged as the compaction has no effect on the data.
+func (s *store) compactRange(t vacuumTask) {
+	base := t.dataStorage
+	if base == nil {
+		base = s.DataStorageByGroup(t.group)
+	}
+	ds, ok := base.(storage.ManualCompactionStorage)
+	if !ok {
+		return
+	}
+
+	r := t.compactRange
+	err := ds.CompactRange(r.start, r.end)
+	if err != nil {
+		s.logger.Error("fail to compact range",
+			s.storeField(),
+			log.ReasonField(t.reason),
+			log.HexField("from", r.start),
+			log.HexField("to", r.end),
+			zap.Error(err))
+	}
+	if t.compactJob != nil && t.compactJob.finish(err) {
+		p := t.compactJob.progress()
+		s.logger.Info("compact range completed",
+			s.storeField(),
+			zap.Uint64("group", p.Group),
+			zap.Uint64("compacted", p.Compacted),
+			zap.Uint64("failed", p.Failed),
+			zap.Duration("cost", p.Cost))
+	}
+}
+
+// clipKeyRange returns the intersection of the [start, end) key range and the
+// overlapped shard, empty start or end key means the min or max key.
+func clipKeyRange(start, end []byte, shard Shard) keyRange {
+	r := keyRange{start: shard.Start, end: shard.End}
+	if bytes.Compare(start, r.start) > 0 {
+		r.start = start
+	}
+	if len(end) > 0 && (len(r.end) == 0 || bytes.Compare(end, r.end) < 0) {
+		r.end = end
+	}
+	return r
+}
@@ -0,0 +1,79 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package raftstore
+
+import (
+	"testing"
+
+	"github.com/matrixorigin/matrixcube/util/leaktest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompactRange(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	s, cancel := newTestStore(t)
+	defer cancel()
+	_, ok := s.GetCompactRangeProgress(0)
+	assert.False(t, ok)
+
+	s.addReplica(newTestReplica(Shard{ID: 1, End: []byte("b")}, Replica{ID: 1}, s))
+	s.addReplica(newTestReplica(Shard{ID: 2, Start: []byte("b"), End: []byte("d")}, Replica{ID: 2}, s))
+	s.addReplica(newTestReplica(Shard{ID: 3, Start: []byte("d")}, Replica{ID: 3}, s))
+	s.addReplica(newTestReplica(Shard{ID: 4, Group: 1}, Replica{ID: 4}, s))
+
+	assert.NoError(t, s.CompactRange(0, []byte("a"), []byte("c")))
+	p, ok := s.GetCompactRangeProgress(0)
+	assert.True(t, ok)
+	assert.Equal(t, uint64(2), p.Total)
+	assert.False(t, p.Done)
+	assert.Equal(t, errCompactRangeRunning, s.CompactRange(0, nil, nil))
+
+	tasks := s.vacuumCleaner.getTasks()
+	assert.Equal(t, 2, len(tasks))
+	for _, task := range tasks {
+		assert.NoError(t, s.vacuum(task))
+	}
+	p, _ = s.GetCompactRangeProgress(0)
+	assert.Equal(t, uint64(2), p.Compacted)
+	assert.Equal(t, uint64(0), p.Failed)
+	assert.True(t, p.Done)
+
+	assert.NoError(t, s.CompactRange(1, []byte("a"), []byte("b")))
+	p, _ = s.GetCompactRangeProgress(1)
+	assert.Equal(t, uint64(1), p.Total)
+
+	// no shard of the group
+	assert.NoError(t, s.CompactRange(2, nil, nil))
+	p, _ = s.GetCompactRangeProgress(2)
+	assert.Equal(t, uint64(0), p.Total)
+	assert.True(t, p.Done)
+}
+
+func TestClipKeyRange(t *testing.T) {
+	cases := []struct {
+		start, end []byte
+		shard      Shard
+		expect     keyRange
+	}{
+		{nil, nil, Shard{}, keyRange{}},
+		{nil, nil, Shard{Start: []byte("b"), End: []byte("d")}, keyRange{start: []byte("b"), end: []byte("d")}},
+		{[]byte("c"), nil, Shard{Start: []byte("b"), End: []byte("d")}, keyRange{start: []byte("c"), end: []byte("d")}},
+		{[]byte("a"), []byte("c"), Shard{Start: []byte("b")}, keyRange{start: []byte("b"), end: []byte("c")}},
+		{[]byte("a"), []byte("e"), Shard{Start: []byte("b"), End: []byte("d")}, keyRange{start: []byte("b"), end: []byte("d")}},
+	}
+	for i, c := range cases {
+		assert.Equal(t, c.expect, clipKeyRange(c.start, c.end, c.shard), "index %d", i)
+	}
+}
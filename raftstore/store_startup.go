@@ -0,0 +1,238 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package raftstore
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/matrixorigin/matrixcube/components/log"
+	"github.com/matrixorigin/matrixcube/metric"
+)
+
+var (
+	startupProgressLogInterval = time.Second * 5
+)
+
+// StartupProgress is the progress of the store starting, the replicas found in
+// the data storages are loaded and then started.
+type StartupProgress struct {
+	// Loaded the number of the replicas loaded from the data storages, including
+	// the tombstones
+	Loaded uint64
+	// ToStart the number of the replicas to be started, 0 until all data storages
+	// are loaded and the replicas are confirmed by the prophet
+	ToStart uint64
+	// Started the number of the replicas started
+	Started uint64
+	// Ready whether the store is started and ready to serve requests
+	Ready bool
+}
+
+// RecoveryReport is the report of the replicas recovered by the store starting,
+// it is available once the store is ready. The orchestration can log the report
+// or alert on the abnormal restarts by the anomalies.
+type RecoveryReport struct {
+	// Loaded the number of the replicas loaded from the data storages, including
+	// the tombstones
+	Loaded uint64
+	// Started the number of the replicas started
+	Started uint64
+	// TombstonesCleaned the number of the tombstone replicas to be cleaned up,
+	// including the replicas confirmed destroyed by the prophet
+	TombstonesCleaned uint64
+	// DestroyingResumed the number of the replicas in the destroying state whose
+	// destroy tasks are resumed
+	DestroyingResumed uint64
+	// LocalStatesRestored the number of the replicas whose local states, e.g.
+	// frozen to be merged or fenced, are restored
+	LocalStatesRestored uint64
+	// OrphanSnapshotsRemoved the number of the incomplete or stale snapshot dirs
+	// removed when the replicas are started
+	OrphanSnapshotsRemoved uint64
+	// LoadCost the time spent on loading the replicas from the data storages
+	LoadCost time.Duration
+	// ConfirmCost the time spent on confirming the replicas with the prophet
+	ConfirmCost time.Duration
+	// StartCost the time spent on starting the replicas
+	StartCost time.Duration
+	// CleanupCost the time spent on scheduling the tombstones cleanup
+	CleanupCost time.Duration
+	// TotalCost the time spent on the whole store starting
+	TotalCost time.Duration
+	// Anomalies the abnormal states found during the recovery
+	Anomalies []RecoveryAnomaly
+}
+
+// RecoveryAnomaly is an abnormal state found during the recovery.
+type RecoveryAnomaly struct {
+	// ShardID the shard id, 0 if the anomaly is not about a shard
+	ShardID uint64
+	// Reason the description of the anomaly
+	Reason string
+}
+
+// startupProgress tracks the progress of the store starting.
+type startupProgress struct {
+	loaded          uint64
+	toStart         uint64
+	started         uint64
+	orphanSnapshots uint64
+	ready           uint32
+
+	mu struct {
+		sync.Mutex
+		report RecoveryReport
+	}
+}
+
+func (p *startupProgress) addLoaded(n int) {
+	atomic.AddUint64(&p.loaded, uint64(n))
+}
+
+func (p *startupProgress) setToStart(n int) {
+	atomic.StoreUint64(&p.toStart, uint64(n))
+}
+
+func (p *startupProgress) incStarted() {
+	atomic.AddUint64(&p.started, 1)
+}
+
+func (p *startupProgress) addOrphanSnapshots(n int) {
+	atomic.AddUint64(&p.orphanSnapshots, uint64(n))
+}
+
+func (p *startupProgress) setReady() {
+	atomic.StoreUint32(&p.ready, 1)
+}
+
+func (p *startupProgress) setReport(report RecoveryReport) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.mu.report = report
+}
+
+func (p *startupProgress) getReport() (RecoveryReport, bool) {
+	if atomic.LoadUint32(&p.ready) == 0 {
+		return RecoveryReport{}, false
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	report := p.mu.report
+	report.Anomalies = append([]RecoveryAnomaly(nil), p.mu.report.Anomalies...)
+	return report, true
+}
+
+func (p *startupProgress) get() StartupProgress {
+	return StartupProgress{
+		Loaded:  atomic.LoadUint64(&p.loaded),
+		ToStart: atomic.LoadUint64(&p.toStart),
+		Started: atomic.LoadUint64(&p.started),
+		Ready:   atomic.LoadUint32(&p.ready) == 1,
+	}
+}
+
+func (s *store) GetStartupProgress() StartupProgress {
+	return s.startup.get()
+}
+
+func (s *store) GetRecoveryReport() (RecoveryReport, bool) {
+	return s.startup.getReport()
+}
+
+// logRecoveryReport logs the recovery report, the anomalies are logged as
+// warnings.
+func (s *store) logRecoveryReport(report RecoveryReport) {
+	s.logger.Info("recovery report",
+		s.storeField(),
+		zap.Uint64("loaded", report.Loaded),
+		zap.Uint64("started", report.Started),
+		zap.Uint64("tombstones-cleaned", report.TombstonesCleaned),
+		zap.Uint64("destroying-resumed", report.DestroyingResumed),
+		zap.Uint64("local-states-restored", report.LocalStatesRestored),
+		zap.Uint64("orphan-snapshots-removed", report.OrphanSnapshotsRemoved),
+		zap.Duration("load-cost", report.LoadCost),
+		zap.Duration("confirm-cost", report.ConfirmCost),
+		zap.Duration("start-cost", report.StartCost),
+		zap.Duration("cleanup-cost", report.CleanupCost),
+		zap.Duration("total-cost", report.TotalCost),
+		zap.Int("anomalies", len(report.Anomalies)))
+	for _, a := range report.Anomalies {
+		s.logger.Warn("recovery anomaly",
+			s.storeField(),
+			log.ShardIDField(a.ShardID),
+			log.ReasonField(a.Reason))
+	}
+}
+
+// reportStartupProgress logs and updates the metrics of the startup progress
+// periodically until the stopC is closed.
+func (s *store) reportStartupProgress(stopC chan struct{}) {
+	ticker := time.NewTicker(startupProgressLogInterval)
+	defer ticker.Stop()
+
+	report := func() {
+		p := s.startup.get()
+		metric.SetStartupReplicasMetric(p.Loaded, p.Started)
+		s.logger.Info("startup progress",
+			s.storeField(),
+			zap.Uint64("loaded", p.Loaded),
+			zap.Uint64("to-start", p.ToStart),
+			zap.Uint64("started", p.Started))
+	}
+	for {
+		select {
+		case <-stopC:
+			report()
+			return
+		case <-ticker.C:
+			report()
+		}
+	}
+}
+
+// runWithWorkers invokes the fn with each index in [0, n) by at most workers
+// goroutines, and waits for all invocations to complete.
+func runWithWorkers(n int, workers int, fn func(int)) {
+	if workers > n {
+		workers = n
+	}
+	if workers <= 1 {
+		for i := 0; i < n; i++ {
+			fn(i)
+		}
+		return
+	}
+
+	var wg sync.WaitGroup
+	next := int64(-1)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				i := int(atomic.AddInt64(&next, 1))
+				if i >= n {
+					return
+				}
+				fn(i)
+			}
+		}()
+	}
+	wg.Wait()
+}
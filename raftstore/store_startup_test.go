@@ -0,0 +1,72 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package raftstore
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunWithWorkers(t *testing.T) {
+	for _, workers := range []int{0, 1, 3, 20} {
+		var mu sync.Mutex
+		seen := make(map[int]int)
+		runWithWorkers(10, workers, func(i int) {
+			mu.Lock()
+			defer mu.Unlock()
+			seen[i]++
+		})
+		assert.Equal(t, 10, len(seen), "workers %d", workers)
+		for i := 0; i < 10; i++ {
+			assert.Equal(t, 1, seen[i], "workers %d", workers)
+		}
+	}
+}
+
+func TestStartupProgress(t *testing.T) {
+	var p startupProgress
+	assert.Equal(t, StartupProgress{}, p.get())
+
+	p.addLoaded(3)
+	p.addLoaded(2)
+	p.setToStart(4)
+	p.incStarted()
+	assert.Equal(t, StartupProgress{Loaded: 5, ToStart: 4, Started: 1}, p.get())
+
+	p.setReady()
+	assert.True(t, p.get().Ready)
+}
+
+func TestRecoveryReport(t *testing.T) {
+	var p startupProgress
+	p.setReport(RecoveryReport{
+		Loaded:    2,
+		Anomalies: []RecoveryAnomaly{{ShardID: 1, Reason: "test"}},
+	})
+	_, ok := p.getReport()
+	assert.False(t, ok)
+
+	p.setReady()
+	report, ok := p.getReport()
+	assert.True(t, ok)
+	assert.Equal(t, uint64(2), report.Loaded)
+	assert.Equal(t, []RecoveryAnomaly{{ShardID: 1, Reason: "test"}}, report.Anomalies)
+
+	// the returned anomalies are copied
+	report.Anomalies[0].ShardID = 2
+	report, _ = p.getReport()
+	assert.Equal(t, uint64(1), report.Anomalies[0].ShardID)
+}
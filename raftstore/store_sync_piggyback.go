@@ -0,0 +1,73 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package raftstore
+
+import (
+	"sync/atomic"
+
+	"github.com/matrixorigin/matrixcube/logdb"
+	"github.com/matrixorigin/matrixcube/storage"
+)
+
+var _ logdb.SyncListener = (*syncPiggyback)(nil)
+
+// syncPiggyback marks the writes of the data storages sharing the WAL with the
+// logdb persistent after each fsync of the raft logs, so the data storages do
+// not need to fsync themselves.
+type syncPiggyback struct {
+	// storages is a []storage.SyncPiggybackStorage
+	storages atomic.Value
+}
+
+func (p *syncPiggyback) PrepareSync() func() {
+	storages, _ := p.storages.Load().([]storage.SyncPiggybackStorage)
+	if len(storages) == 0 {
+		return nil
+	}
+
+	seqs := make([]uint64, len(storages))
+	for idx, s := range storages {
+		seqs[idx] = s.PrepareSync()
+	}
+	return func() {
+		for idx, s := range storages {
+			s.Synced(seqs[idx])
+		}
+	}
+}
+
+// startSyncPiggyback enables the sync piggyback of the data storages sharing
+// the WAL with the logdb, returns the number of these data storages.
+func (s *store) startSyncPiggyback() int {
+	if s.syncPiggyback == nil {
+		return 0
+	}
+
+	var storages []storage.SyncPiggybackStorage
+	seen := make(map[storage.SyncPiggybackStorage]struct{})
+	s.cfg.Storage.ForeachDataStorageFunc(func(_ uint64, ds storage.DataStorage) {
+		ps, ok := ds.(storage.SyncPiggybackStorage)
+		if !ok || !ps.SharesWAL(s.kvStorage) {
+			return
+		}
+		if _, ok := seen[ps]; ok {
+			return
+		}
+		seen[ps] = struct{}{}
+		ps.EnableSyncPiggyback()
+		storages = append(storages, ps)
+	})
+	s.syncPiggyback.storages.Store(storages)
+	return len(storages)
+}
@@ -18,10 +18,12 @@ import (
 	"time"
 
 	"github.com/fagongzi/util/protoc"
+	"github.com/juju/ratelimit"
 	"github.com/matrixorigin/matrixcube/pb/metapb"
 	"github.com/matrixorigin/matrixcube/pb/rpcpb"
 	"github.com/matrixorigin/matrixcube/storage"
 	skv "github.com/matrixorigin/matrixcube/storage/kv"
+	"github.com/matrixorigin/matrixcube/storage/stats"
 	"github.com/matrixorigin/matrixcube/transport"
 	"github.com/matrixorigin/matrixcube/util"
 	"github.com/matrixorigin/matrixcube/util/leaktest"
@@ -354,6 +356,50 @@ func TestGetStoreHeartbeat(t *testing.T) {
 	req, err := s.getStoreHeartbeat(time.Now())
 	assert.NoError(t, err)
 	assert.Equal(t, uint64(2), req.Stats.ShardCount)
+	assert.Equal(t, uint64(0), req.Stats.SendingSnapCount)
+	assert.Equal(t, uint64(0), req.Stats.ReceivingSnapCount)
+
+	// the snapshot counts are reported to the prophet
+	s.trans = &replicaTestTransport{progresses: []metapb.SnapshotProgress{
+		{ShardID: 1, Sending: true}, {ShardID: 2}, {ShardID: 3},
+	}}
+	req, err = s.getStoreHeartbeat(time.Now())
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), req.Stats.SendingSnapCount)
+	assert.Equal(t, uint64(2), req.Stats.ReceivingSnapCount)
+	assert.Equal(t, 3, len(req.Stats.SnapshotProgresses))
+}
+
+func TestGetFlowStats(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	s, cancel := newTestStore(t)
+	defer cancel()
+
+	s.getFlowStats()
+	current := s.flowStatsMu.last
+	// no flow since the last heartbeat
+	assert.Equal(t, stats.Stats{}, s.getFlowStats())
+
+	// the accumulated flow is reset by reopening the data storage
+	s.flowStatsMu.last.ReadBytes = current.ReadBytes + 1
+	assert.Equal(t, current.ReadBytes, s.getFlowStats().ReadBytes)
+}
+
+func TestFlowDelta(t *testing.T) {
+	assert.Equal(t, uint64(0), flowDelta(10, 10))
+	assert.Equal(t, uint64(5), flowDelta(15, 10))
+	assert.Equal(t, uint64(5), flowDelta(5, 10))
+}
+
+func TestAllowResolvedTSAdvance(t *testing.T) {
+	s := &store{}
+	assert.True(t, s.allowResolvedTSAdvance())
+
+	s.resolvedTSLimiter = ratelimit.NewBucketWithRate(1, 2)
+	assert.True(t, s.allowResolvedTSAdvance())
+	assert.True(t, s.allowResolvedTSAdvance())
+	assert.False(t, s.allowResolvedTSAdvance())
 }
 
 func TestDoShardHeartbeatRsp(t *testing.T) {
@@ -399,6 +445,40 @@ func TestDoShardHeartbeatRsp(t *testing.T) {
 			},
 			adminTargetReq: &rpcpb.TransferLeaderRequest{},
 		},
+		{
+			rsp: rpcpb.ShardHeartbeatRsp{ShardID: 1, ConfigChangeV2: &rpcpb.ConfigChangeV2{
+				Changes: []rpcpb.ConfigChange{
+					{
+						Replica:    metapb.Replica{ID: 1, StoreID: 1, Role: metapb.ReplicaRole_Voter},
+						ChangeType: metapb.ConfigChangeType_AddNode,
+					},
+					{
+						Replica:    metapb.Replica{ID: 2, StoreID: 2, Role: metapb.ReplicaRole_Learner},
+						ChangeType: metapb.ConfigChangeType_AddLearnerNode,
+					},
+				},
+			}},
+			fn: func(s *store) *replica {
+				pr := &replica{shardID: 1, startedC: make(chan struct{}), requests: task.New(32), actions: task.New(32)}
+				pr.store = s
+				close(pr.startedC)
+				s.addReplica(pr)
+				return pr
+			},
+			adminReq: &rpcpb.ConfigChangeV2Request{
+				Changes: []rpcpb.ConfigChangeRequest{
+					{
+						ChangeType: metapb.ConfigChangeType_AddNode,
+						Replica:    metapb.Replica{ID: 1, StoreID: 1, Role: metapb.ReplicaRole_Voter},
+					},
+					{
+						ChangeType: metapb.ConfigChangeType_AddLearnerNode,
+						Replica:    metapb.Replica{ID: 2, StoreID: 2, Role: metapb.ReplicaRole_Learner},
+					},
+				},
+			},
+			adminTargetReq: &rpcpb.ConfigChangeV2Request{},
+		},
 	}
 
 	for _, c := range cases {
@@ -14,11 +14,16 @@
 package raftstore
 
 import (
+	"runtime"
 	"time"
 
 	"github.com/RoaringBitmap/roaring/roaring64"
+	"github.com/fagongzi/util/format"
 	putil "github.com/matrixorigin/matrixcube/components/prophet/util"
+	"github.com/matrixorigin/matrixcube/metric"
+	"github.com/matrixorigin/matrixcube/pb/rpcpb"
 	"github.com/matrixorigin/matrixcube/storage"
+	"github.com/matrixorigin/matrixcube/storage/stats"
 	"go.uber.org/zap"
 )
 
@@ -44,6 +49,16 @@ func (s *store) startTimerTasks() {
 		debugTicker := time.NewTicker(time.Second * 10)
 		defer debugTicker.Stop()
 
+		writeStallCheckTicker := time.NewTicker(s.cfg.WriteStall.CheckInterval.Duration)
+		defer writeStallCheckTicker.Stop()
+
+		var creditsHeartbeatC <-chan time.Time
+		if s.cfg.Proxy.EnableFlowControl {
+			creditsHeartbeatTicker := time.NewTicker(s.cfg.Proxy.CreditsHeartbeatInterval.Duration)
+			defer creditsHeartbeatTicker.Stop()
+			creditsHeartbeatC = creditsHeartbeatTicker.C
+		}
+
 		for {
 			select {
 			case <-s.stopper.ShouldStop():
@@ -58,25 +73,50 @@ func (s *store) startTimerTasks() {
 				s.handleShardHeartbeatTask()
 			case <-storeheartbeatTicker.C:
 				s.handleStoreHeartbeatTask(last)
+				s.handleMemoryMetricsTask()
 				last = time.Now()
 			case <-refreshScheduleGroupRuleTicker.C:
 				s.handleRefreshScheduleGroupRule()
 			case <-debugTicker.C:
 				s.doLogDebugInfo()
+			case <-creditsHeartbeatC:
+				s.handleCreditsHeartbeatTask()
+			case <-writeStallCheckTicker.C:
+				s.handleWriteStallCheckTask()
 			}
 		}
 	})
 
+	// the group tasks are started once even if the group has multiple data
+	// storages
+	started := make(map[uint64]struct{})
 	s.cfg.Storage.ForeachDataStorageFunc(func(group uint64, ds storage.DataStorage) {
+		if _, ok := started[group]; ok {
+			return
+		}
+		started[group] = struct{}{}
 		s.stopper.RunWorker(func() {
 			policy := ds.Feature()
-			if policy.DisableShardSplit {
+			var splitCheckC, purgeExpiredDataC, compactDataC <-chan time.Time
+			if !policy.DisableShardSplit {
+				splitCheckTicker := time.NewTicker(policy.ShardSplitCheckDuration)
+				defer splitCheckTicker.Stop()
+				splitCheckC = splitCheckTicker.C
+			}
+			if _, ok := ds.(storage.TTLDataStorage); ok && policy.ExpiredDataPurgeDuration > 0 {
+				purgeExpiredDataTicker := time.NewTicker(policy.ExpiredDataPurgeDuration)
+				defer purgeExpiredDataTicker.Stop()
+				purgeExpiredDataC = purgeExpiredDataTicker.C
+			}
+			if _, ok := ds.(storage.CompactionFilterDataStorage); ok && policy.CompactionFilterDuration > 0 {
+				compactDataTicker := time.NewTicker(policy.CompactionFilterDuration)
+				defer compactDataTicker.Stop()
+				compactDataC = compactDataTicker.C
+			}
+			if splitCheckC == nil && purgeExpiredDataC == nil && compactDataC == nil {
 				return
 			}
 
-			splitCheckTicker := time.NewTicker(policy.ShardSplitCheckDuration)
-			defer splitCheckTicker.Stop()
-
 			for {
 				select {
 				case <-s.stopper.ShouldStop():
@@ -84,8 +124,12 @@ func (s *store) startTimerTasks() {
 						s.storeField())
 					return
 
-				case <-splitCheckTicker.C:
+				case <-splitCheckC:
 					s.handleSplitCheckTask(group)
+				case <-purgeExpiredDataC:
+					s.handlePurgeExpiredDataTask(group)
+				case <-compactDataC:
+					s.handleCompactDataTask(group)
 				}
 			}
 		})
@@ -139,7 +183,8 @@ func (s *store) handleSplitCheckTask(group uint64) {
 		if pr.group == group &&
 			pr.isLeader() {
 			pr.addAction(action{actionType: checkSplitAction, actionCallback: func(arg interface{}) {
-				s.splitChecker.add(arg.(Shard))
+				task := arg.(splitCheckTask)
+				s.splitChecker.add(task.shard, task.size)
 			}})
 		}
 
@@ -147,13 +192,128 @@ func (s *store) handleSplitCheckTask(group uint64) {
 	})
 }
 
+// handlePurgeExpiredDataTask proposes the purge of the expired data of the
+// shards of the group led by the store.
+func (s *store) handlePurgeExpiredDataTask(group uint64) {
+	s.purgeExpiredData(group)
+}
+
+// handleCompactDataTask drops the data filtered by the compaction filter of the
+// data storages of the group by the vacuum cleaner, so the removal does not run
+// concurrently with the removal of the shard data.
+func (s *store) handleCompactDataTask(group uint64) {
+	s.vacuumCleaner.addTask(vacuumTask{
+		group:       group,
+		compactData: true,
+		reason:      "compact-data",
+	})
+}
+
 func (s *store) handleShardHeartbeatTask() {
 	s.forEachReplica(func(pr *replica) bool {
 		if pr.isLeader() {
 			pr.addAction(action{actionType: heartbeatAction})
 		}
+		// the frozen shard keeps notifying the target until the merge completed
+		pr.notifyMergeTarget()
+		if pr.sm.getFenceIndex() > 0 {
+			pr.addAction(action{actionType: checkFenceAction})
+		}
+		return true
+	})
+}
+
+// handleCreditsHeartbeatTask advertises the proposal credits of the busy shards
+// to the local proxy and the remote proxies connected to the store.
+func (s *store) handleCreditsHeartbeatTask() {
+	var credits []rpcpb.ShardCredits
+	s.forEachReplica(func(pr *replica) bool {
+		if pr.credits != nil {
+			if c, ok := pr.credits.advertisement(); ok {
+				credits = append(credits, rpcpb.ShardCredits{ShardID: pr.shardID, Credits: c})
+			}
+		}
 		return true
 	})
+
+	// the local proxy is always notified to expire the stale credits
+	s.shardsProxy.OnCredits(credits)
+	if len(credits) > 0 && s.proxyRPC != nil {
+		s.proxyRPC.broadcast(rpcpb.Response{Credits: credits})
+	}
+}
+
+// handleMemoryMetricsTask updates the memory used by the go runtime, the logdb
+// and the data storages of the store.
+func (s *store) handleMemoryMetricsTask() {
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+	metric.SetMemoryMetric("go-heap", ms.HeapInuse)
+	metric.SetMemoryMetric("go-heap-idle", ms.HeapIdle-ms.HeapReleased)
+	metric.SetMemoryMetric("go-stack", ms.StackInuse)
+	metric.SetMemoryMetric("go-runtime", ms.MSpanInuse+ms.MCacheInuse+ms.BuckHashSys+ms.GCSys+ms.OtherSys)
+
+	if r, ok := s.kvStorage.(storage.MemoryStatsReader); ok {
+		setStorageMemoryMetric("logdb", r.MemoryStats())
+	}
+
+	// the groups may share the same data storage
+	var data stats.MemoryStats
+	readers := make(map[storage.MemoryStatsReader]struct{})
+	s.cfg.Storage.ForeachDataStorageFunc(func(_ uint64, ds storage.DataStorage) {
+		if r, ok := ds.(storage.MemoryStatsReader); ok {
+			if _, ok := readers[r]; ok {
+				return
+			}
+			readers[r] = struct{}{}
+			st := r.MemoryStats()
+			data.BlockCache += st.BlockCache
+			data.TableCache += st.TableCache
+			data.MemTable += st.MemTable
+		}
+	})
+	setStorageMemoryMetric("data", data)
+}
+
+func setStorageMemoryMetric(name string, st stats.MemoryStats) {
+	metric.SetMemoryMetric(name+"-block-cache", st.BlockCache)
+	metric.SetMemoryMetric(name+"-table-cache", st.TableCache)
+	metric.SetMemoryMetric(name+"-memtable", st.MemTable)
+}
+
+// handleWriteStallCheckTask checks the data storages of the groups for the write
+// stalls, and updates the stalled groups and the write stall metrics.
+func (s *store) handleWriteStallCheckTask() {
+	stalled := make(map[uint64]stats.WriteStallStats)
+	s.cfg.Storage.ForeachDataStorageFunc(func(group uint64, ds storage.DataStorage) {
+		r, ok := ds.(storage.WriteStallStatsReader)
+		if !ok {
+			return
+		}
+		st := r.WriteStallStats()
+		v := s.writeStalls.isStalled(st)
+		metric.SetStorageWriteStallMetric(format.Uint64ToString(group), v, st.L0Files)
+		if v {
+			stalled[group] = st
+		}
+	})
+
+	began, ended := s.writeStalls.update(stalled)
+	for _, group := range began {
+		st := stalled[group]
+		s.logger.Warn("write stall detected",
+			s.storeField(),
+			zap.Uint64("group", group),
+			zap.Bool("stalling", st.Stalled),
+			zap.Uint64("stalls", st.Stalls),
+			zap.Int64("l0-files", st.L0Files),
+			zap.Int32("l0-sublevels", st.L0Sublevels))
+	}
+	for _, group := range ended {
+		s.logger.Info("write stall recovered",
+			s.storeField(),
+			zap.Uint64("group", group))
+	}
 }
 
 func (s *store) handleCompactLogTask() {
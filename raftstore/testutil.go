@@ -80,6 +80,8 @@ type testClusterOptions struct {
 	disableSchedule       bool
 	enableParallelTest    bool
 	useProphetInitCluster bool
+	deltaSnapshotEntries  uint64
+	syncPiggyback         bool
 
 	storageStatsReaderFunc func(*store) storageStatsReader
 }
@@ -216,6 +218,21 @@ func WithTestClusterSplitPolicy(shardCapacityBytes, shardSplitCheckBytes uint64)
 	}
 }
 
+// WithTestClusterDeltaSnapshot enables the delta snapshots of the data storages
+func WithTestClusterDeltaSnapshot(entries uint64) TestClusterOption {
+	return func(opts *testClusterOptions) {
+		opts.deltaSnapshotEntries = entries
+	}
+}
+
+// WithTestClusterSyncPiggyback creates the data storages on the kv storages of
+// the logdb, and enables the sync piggyback
+func WithTestClusterSyncPiggyback() TestClusterOption {
+	return func(opts *testClusterOptions) {
+		opts.syncPiggyback = true
+	}
+}
+
 func recreateTestTempDir(fs vfs.FS, tmpDir string) {
 	fs.RemoveAll(tmpDir)
 	fs.MkdirAll(tmpDir, 0755)
@@ -1144,13 +1161,17 @@ func (c *testRaftCluster) resetNode(node int, init bool) {
 		} else {
 			kvs = mem.NewStorage()
 		}
+		if c.opts.syncPiggyback {
+			cfg.Storage.LogDBStorage = kvs
+			cfg.Raft.RaftLog.EnableSyncPiggyback = true
+		}
 		base := kv.NewBaseStorage(kvs, cfg.FS)
 		dataStorage = kv.NewKVDataStorage(base, simple.NewSimpleKVExecutor(kvs),
 			kv.WithLogger(cfg.Logger), kv.WithFeature(storage.Feature{
 				ShardSplitCheckDuration: time.Millisecond * 100,
 				ShardCapacityBytes:      c.opts.shardCapacityBytes,
 				ShardSplitCheckBytes:    c.opts.shardSplitCheckBytes,
-			}))
+			}), kv.WithDeltaSnapshot(c.opts.deltaSnapshotEntries))
 
 		cfg.Storage.DataStorageFactory = func(group uint64) storage.DataStorage {
 			return dataStorage
@@ -1310,7 +1331,7 @@ func (c *testRaftCluster) removeDataIfSucceed() {
 
 func (c *testRaftCluster) closeLogDBKVStorage() {
 	for _, s := range c.stores {
-		if s != nil && s.kvStorage != nil {
+		if s != nil && s.kvStorage != nil && s.cfg.Storage.LogDBStorage == nil {
 			s.kvStorage.Close()
 		}
 	}
@@ -15,26 +15,46 @@ package raftstore
 
 import (
 	"sync"
+	"sync/atomic"
 
 	"github.com/lni/goutils/syncutil"
+
+	"github.com/matrixorigin/matrixcube/metric"
+	"github.com/matrixorigin/matrixcube/storage"
 )
 
 type vacuumFunc = func(vacuumTask) error
 
 type vacuumTask struct {
-	shard        Shard
-	replica      *replica
+	shard   Shard
+	replica *replica
+	// dataStorage the data storage the task runs on, the one of the replica or
+	// resolved for the shard or the group is used if nil
+	dataStorage  storage.DataStorage
 	shardRemoved bool
 	removeData   bool
 	reason       string
+	// compactData drops the data filtered by the compaction filter of the data
+	// storages of the group instead of destroying a replica
+	compactData bool
+	// compactRange compacts the key range of the group in the data storage by
+	// the manual compaction instead of destroying a replica
+	compactRange *keyRange
+	// compactJob the CompactRange job of the compactRange, nil if the range is
+	// compacted by the AdminCompactShard request
+	compactJob *compactRangeJob
+	group      uint64
 }
 
 // vacuumCleaner is used to cleanup shard data belongs to shards that have been
-// destroyed.
+// destroyed, the expired data of the shards with TTL and the data filtered by
+// the compaction filter.
 type vacuumCleaner struct {
 	stopper *syncutil.Stopper
 	notifyC chan struct{}
 	vf      vacuumFunc
+	// backlog the number of the tasks pending or being processed
+	backlog int64
 
 	mu struct {
 		sync.Mutex
@@ -73,6 +93,7 @@ func (v *vacuumCleaner) addTask(t vacuumTask) {
 	v.mu.Lock()
 	defer v.mu.Unlock()
 	v.mu.pending = append(v.mu.pending, t)
+	metric.SetVacuumQueueMetric(atomic.AddInt64(&v.backlog, 1))
 	select {
 	case v.notifyC <- struct{}{}:
 	default:
@@ -100,6 +121,7 @@ func (v *vacuumCleaner) vacuum() bool {
 				if err := v.vf(task); err != nil {
 					panic(err)
 				}
+				metric.SetVacuumQueueMetric(atomic.AddInt64(&v.backlog, -1))
 				select {
 				case <-v.stopper.ShouldStop():
 					return true
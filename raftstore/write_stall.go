@@ -0,0 +1,93 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package raftstore
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/matrixorigin/matrixcube/storage/stats"
+)
+
+const (
+	// stalledCreditsDivisor the max proposal credits of the shards of the stalled
+	// groups are divided by it
+	stalledCreditsDivisor = 8
+)
+
+// writeStallDetector tracks the groups whose data storages are stalled. It's
+// updated by the write stall check task of the store, and read by the replicas
+// to slow down the proposals and by the store heartbeat.
+type writeStallDetector struct {
+	sync.RWMutex
+	l0FilesThreshold int64
+	stalled          map[uint64]stats.WriteStallStats
+	// stalledCount is used to skip the lock in the hot path if no groups stalled
+	stalledCount int32
+}
+
+func newWriteStallDetector(l0FilesThreshold int64) *writeStallDetector {
+	return &writeStallDetector{
+		l0FilesThreshold: l0FilesThreshold,
+		stalled:          make(map[uint64]stats.WriteStallStats),
+	}
+}
+
+// isStalled returns true if the data storage with the stats is stalled
+func (d *writeStallDetector) isStalled(st stats.WriteStallStats) bool {
+	return st.Stalled || st.L0Files >= d.l0FilesThreshold
+}
+
+// update updates the stalled groups, and returns the groups became stalled and
+// the groups recovered since the last update.
+func (d *writeStallDetector) update(stalled map[uint64]stats.WriteStallStats) ([]uint64, []uint64) {
+	d.Lock()
+	defer d.Unlock()
+
+	var began, ended []uint64
+	for group := range stalled {
+		if _, ok := d.stalled[group]; !ok {
+			began = append(began, group)
+		}
+	}
+	for group := range d.stalled {
+		if _, ok := stalled[group]; !ok {
+			ended = append(ended, group)
+		}
+	}
+	d.stalled = stalled
+	atomic.StoreInt32(&d.stalledCount, int32(len(stalled)))
+	return began, ended
+}
+
+func (d *writeStallDetector) isGroupStalled(group uint64) bool {
+	if !d.hasStalled() {
+		return false
+	}
+
+	d.RLock()
+	defer d.RUnlock()
+	_, ok := d.stalled[group]
+	return ok
+}
+
+func (d *writeStallDetector) hasStalled() bool {
+	return atomic.LoadInt32(&d.stalledCount) > 0
+}
+
+// isWriteStalled returns true if the proposals of the group should be slowed down
+// by the write stall of the data storage.
+func (s *store) isWriteStalled(group uint64) bool {
+	return !s.cfg.WriteStall.DisableMitigation && s.writeStalls.isGroupStalled(group)
+}
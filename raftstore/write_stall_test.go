@@ -0,0 +1,87 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package raftstore
+
+import (
+	"testing"
+
+	"github.com/matrixorigin/matrixcube/config"
+	"github.com/matrixorigin/matrixcube/storage"
+	"github.com/matrixorigin/matrixcube/storage/stats"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+type testStallDataStorage struct {
+	storage.DataStorage
+	st stats.WriteStallStats
+}
+
+func (s *testStallDataStorage) WriteStallStats() stats.WriteStallStats {
+	return s.st
+}
+
+func TestWriteStallDetector(t *testing.T) {
+	d := newWriteStallDetector(10)
+	assert.False(t, d.isStalled(stats.WriteStallStats{L0Files: 9}))
+	assert.True(t, d.isStalled(stats.WriteStallStats{L0Files: 10}))
+	assert.True(t, d.isStalled(stats.WriteStallStats{Stalled: true}))
+
+	assert.False(t, d.hasStalled())
+	began, ended := d.update(map[uint64]stats.WriteStallStats{1: {}, 2: {}})
+	assert.ElementsMatch(t, []uint64{1, 2}, began)
+	assert.Empty(t, ended)
+	assert.True(t, d.hasStalled())
+	assert.True(t, d.isGroupStalled(1))
+	assert.False(t, d.isGroupStalled(3))
+
+	began, ended = d.update(map[uint64]stats.WriteStallStats{2: {}, 3: {}})
+	assert.Equal(t, []uint64{3}, began)
+	assert.Equal(t, []uint64{1}, ended)
+
+	began, ended = d.update(map[uint64]stats.WriteStallStats{})
+	assert.Empty(t, began)
+	assert.ElementsMatch(t, []uint64{2, 3}, ended)
+	assert.False(t, d.hasStalled())
+	assert.False(t, d.isGroupStalled(2))
+}
+
+func TestHandleWriteStallCheckTask(t *testing.T) {
+	storages := map[uint64]*testStallDataStorage{
+		1: {st: stats.WriteStallStats{L0Files: 2}},
+		2: {st: stats.WriteStallStats{Stalled: true, Stalls: 1}},
+	}
+	cfg := &config.Config{}
+	cfg.WriteStall.L0FilesThreshold = 10
+	cfg.Storage.ForeachDataStorageFunc = func(cb func(uint64, storage.DataStorage)) {
+		for group, ds := range storages {
+			cb(group, ds)
+		}
+	}
+	s := &store{cfg: cfg, logger: zap.L(), writeStalls: newWriteStallDetector(10)}
+
+	s.handleWriteStallCheckTask()
+	assert.False(t, s.isWriteStalled(1))
+	assert.True(t, s.isWriteStalled(2))
+
+	storages[1].st.L0Files = 10
+	storages[2].st.Stalled = false
+	s.handleWriteStallCheckTask()
+	assert.True(t, s.isWriteStalled(1))
+	assert.False(t, s.isWriteStalled(2))
+
+	// only the metrics are updated without the mitigation
+	cfg.WriteStall.DisableMitigation = true
+	assert.False(t, s.isWriteStalled(1))
+}
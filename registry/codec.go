@@ -0,0 +1,252 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+var (
+	errInvalidData = errors.New("invalid registry data")
+)
+
+const (
+	putCmd uint64 = iota + 1
+	deleteCmd
+	getCmd
+	listCmd
+	changesCmd
+)
+
+// the keys of the registry in the shard, the revision key holds the current
+// revision, the change keys hold the changes ordered by revision, and the entry
+// keys hold the entries.
+const (
+	revisionPrefix byte = 0x00
+	changePrefix   byte = 0x01
+	entryPrefix    byte = 0x02
+)
+
+var (
+	revisionKey = []byte{revisionPrefix}
+)
+
+func entryKey(key []byte) []byte {
+	v := make([]byte, 1+len(key))
+	v[0] = entryPrefix
+	copy(v[1:], key)
+	return v
+}
+
+func changeKey(revision uint64) []byte {
+	v := make([]byte, 9)
+	v[0] = changePrefix
+	binary.BigEndian.PutUint64(v[1:], revision)
+	return v
+}
+
+// prefixEnd returns the smallest key greater than all the keys with the prefix.
+func prefixEnd(prefix []byte) []byte {
+	end := make([]byte, len(prefix))
+	copy(end, prefix)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i]++
+			return end[:i+1]
+		}
+	}
+	return nil
+}
+
+// EventType is the type of the registry change.
+type EventType byte
+
+const (
+	// PutEvent the entry is created or updated
+	PutEvent EventType = iota
+	// DeleteEvent the entry is deleted
+	DeleteEvent
+)
+
+// Entry is a key-value pair in the registry, the Revision is the revision of
+// the last change of the entry.
+type Entry struct {
+	Key      []byte
+	Value    []byte
+	Revision uint64
+}
+
+// Event is a change of the registry, the Entry of the DeleteEvent has no value.
+type Event struct {
+	Type  EventType
+	Entry Entry
+}
+
+type encoder struct {
+	data []byte
+}
+
+func (e *encoder) uint64(v uint64) {
+	var tmp [8]byte
+	binary.BigEndian.PutUint64(tmp[:], v)
+	e.data = append(e.data, tmp[:]...)
+}
+
+func (e *encoder) byte(v byte) {
+	e.data = append(e.data, v)
+}
+
+func (e *encoder) bytes(v []byte) {
+	var tmp [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(tmp[:], uint64(len(v)))
+	e.data = append(e.data, tmp[:n]...)
+	e.data = append(e.data, v...)
+}
+
+func (e *encoder) entry(v Entry) {
+	e.bytes(v.Key)
+	e.uint64(v.Revision)
+	e.bytes(v.Value)
+}
+
+// decoder decodes the data written by the encoder, the first error is kept and
+// all later reads return zero values.
+type decoder struct {
+	data []byte
+	err  error
+}
+
+func (d *decoder) uint64() uint64 {
+	if d.err != nil || len(d.data) < 8 {
+		d.err = errInvalidData
+		return 0
+	}
+	v := binary.BigEndian.Uint64(d.data)
+	d.data = d.data[8:]
+	return v
+}
+
+func (d *decoder) byte() byte {
+	if d.err != nil || len(d.data) < 1 {
+		d.err = errInvalidData
+		return 0
+	}
+	v := d.data[0]
+	d.data = d.data[1:]
+	return v
+}
+
+func (d *decoder) bytes() []byte {
+	if d.err != nil {
+		return nil
+	}
+	n, size := binary.Uvarint(d.data)
+	if size <= 0 || uint64(len(d.data)-size) < n {
+		d.err = errInvalidData
+		return nil
+	}
+	v := d.data[size : size+int(n)]
+	d.data = d.data[size+int(n):]
+	return v
+}
+
+func (d *decoder) entry() Entry {
+	return Entry{Key: d.bytes(), Revision: d.uint64(), Value: d.bytes()}
+}
+
+// entryValue is the stored value of the entry, the revision followed by the
+// value.
+func encodeEntryValue(revision uint64, value []byte) []byte {
+	e := encoder{data: make([]byte, 0, 8+len(value))}
+	e.uint64(revision)
+	e.data = append(e.data, value...)
+	return e.data
+}
+
+func decodeEntryValue(data []byte) (uint64, []byte, error) {
+	if len(data) < 8 {
+		return 0, nil, errInvalidData
+	}
+	return binary.BigEndian.Uint64(data), data[8:], nil
+}
+
+func encodeChange(tp EventType, key, value []byte) []byte {
+	e := encoder{}
+	e.byte(byte(tp))
+	e.bytes(key)
+	e.bytes(value)
+	return e.data
+}
+
+func encodeListResponse(revision uint64, entries []Entry) []byte {
+	e := encoder{}
+	e.uint64(revision)
+	e.uint64(uint64(len(entries)))
+	for _, v := range entries {
+		e.entry(v)
+	}
+	return e.data
+}
+
+func decodeListResponse(data []byte) ([]Entry, uint64, error) {
+	d := decoder{data: data}
+	revision := d.uint64()
+	n := d.uint64()
+	var entries []Entry
+	for i := uint64(0); i < n && d.err == nil; i++ {
+		entries = append(entries, d.entry())
+	}
+	return entries, revision, d.err
+}
+
+func encodeChangesResponse(compacted bool, revision uint64, events []Event) []byte {
+	e := encoder{}
+	if compacted {
+		e.byte(1)
+	} else {
+		e.byte(0)
+	}
+	e.uint64(revision)
+	e.uint64(uint64(len(events)))
+	for _, v := range events {
+		e.byte(byte(v.Type))
+		e.entry(v.Entry)
+	}
+	return e.data
+}
+
+func decodeChangesResponse(data []byte) (bool, uint64, []Event, error) {
+	d := decoder{data: data}
+	compacted := d.byte() == 1
+	revision := d.uint64()
+	n := d.uint64()
+	var events []Event
+	for i := uint64(0); i < n && d.err == nil; i++ {
+		events = append(events, Event{Type: EventType(d.byte()), Entry: d.entry()})
+	}
+	return compacted, revision, events, d.err
+}
+
+func encodeUint64(v uint64) []byte {
+	e := encoder{data: make([]byte, 0, 8)}
+	e.uint64(v)
+	return e.data
+}
+
+func decodeUint64(data []byte) (uint64, error) {
+	d := decoder{data: data}
+	v := d.uint64()
+	return v, d.err
+}
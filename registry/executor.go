@@ -0,0 +1,232 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/matrixorigin/matrixcube/storage"
+	"github.com/matrixorigin/matrixcube/storage/kv"
+	"github.com/matrixorigin/matrixcube/util"
+)
+
+const (
+	// DefaultRetention is the default number of the recent changes kept for the
+	// watchers.
+	DefaultRetention uint64 = 10000
+	// maxChangesPerRead the max number of the changes returned by one read
+	maxChangesPerRead = 1024
+)
+
+var (
+	dataRevisionKey = kv.EncodeDataKey(revisionKey, nil)
+)
+
+// executor is the executor of the registry shard group. It keeps the entries,
+// the current revision and the recent changes in the shard, every write bumps
+// the revision and records the change, the changes older than the retention
+// are removed.
+type executor struct {
+	kv        storage.KVStorage
+	retention uint64
+}
+
+var _ storage.Executor = (*executor)(nil)
+
+// NewExecutor returns the executor of the registry shard group, the retention
+// is the number of the recent changes kept for the watchers. The registry
+// group must have a single shard managing the whole key range, so the data
+// storage of the group should disable the shard split.
+func NewExecutor(kvStore storage.KVStorage, retention uint64) storage.Executor {
+	if retention == 0 {
+		retention = DefaultRetention
+	}
+	return &executor{kv: kvStore, retention: retention}
+}
+
+func (e *executor) UpdateWriteBatch(ctx storage.WriteContext) error {
+	revision, err := e.getRevision()
+	if err != nil {
+		return err
+	}
+
+	writtenBytes := uint64(0)
+	wb := ctx.WriteBatch().(util.WriteBatch)
+	requests := ctx.Batch().Requests
+	for j := range requests {
+		req := requests[j]
+		key := kv.DecodeDataKey(req.Key)
+		if len(key) == 0 || key[0] != entryPrefix {
+			return fmt.Errorf("invalid registry key %+v", key)
+		}
+
+		revision++
+		var change []byte
+		switch req.CmdType {
+		case putCmd:
+			wb.Set(req.Key, encodeEntryValue(revision, req.Cmd))
+			change = encodeChange(PutEvent, key[1:], req.Cmd)
+		case deleteCmd:
+			wb.Delete(req.Key)
+			change = encodeChange(DeleteEvent, key[1:], nil)
+		default:
+			panic(fmt.Errorf("invalid registry write cmd %d", req.CmdType))
+		}
+		wb.Set(kv.EncodeDataKey(changeKey(revision), nil), change)
+		if revision > e.retention {
+			wb.Delete(kv.EncodeDataKey(changeKey(revision-e.retention), nil))
+		}
+		writtenBytes += uint64(len(req.Key) + len(req.Cmd) + len(change))
+		ctx.AppendResponse(encodeUint64(revision))
+	}
+	wb.Set(dataRevisionKey, encodeUint64(revision))
+
+	ctx.SetDiffBytes(int64(writtenBytes))
+	ctx.SetWrittenBytes(writtenBytes)
+	return nil
+}
+
+func (e *executor) ApplyWriteBatch(r storage.Resetable) error {
+	wb := r.(util.WriteBatch)
+	return e.kv.Write(wb, false)
+}
+
+func (e *executor) Read(ctx storage.ReadContext) ([]byte, error) {
+	req := ctx.Request()
+	switch req.CmdType {
+	case getCmd:
+		v, err := e.kv.Get(req.Key)
+		if err != nil {
+			return nil, err
+		}
+		ctx.SetReadBytes(uint64(len(v)))
+		return v, nil
+	case listCmd:
+		return e.list(ctx, req.Key)
+	case changesCmd:
+		since, err := decodeUint64(req.Cmd)
+		if err != nil {
+			return nil, err
+		}
+		key := kv.DecodeDataKey(req.Key)
+		if len(key) == 0 || key[0] != entryPrefix {
+			return nil, fmt.Errorf("invalid registry key %+v", key)
+		}
+		return e.changes(ctx, key[1:], since)
+	default:
+		panic(fmt.Errorf("invalid registry read cmd %d", req.CmdType))
+	}
+}
+
+// list returns the current revision and the entries with the prefix.
+func (e *executor) list(ctx storage.ReadContext, prefix []byte) ([]byte, error) {
+	view := e.kv.GetView()
+	defer view.Close()
+
+	revision, err := e.getRevisionInView(view)
+	if err != nil {
+		return nil, err
+	}
+
+	readBytes := uint64(0)
+	var entries []Entry
+	err = e.kv.ScanInView(view, prefix, prefixEnd(prefix), func(key, value []byte) (bool, error) {
+		rev, v, err := decodeEntryValue(value)
+		if err != nil {
+			return false, err
+		}
+		readBytes += uint64(len(key) + len(value))
+		entries = append(entries, Entry{Key: kv.DecodeDataKey(key)[1:], Value: v, Revision: rev})
+		return true, nil
+	}, true)
+	if err != nil {
+		return nil, err
+	}
+	ctx.SetReadBytes(readBytes)
+	return encodeListResponse(revision, entries), nil
+}
+
+// changes returns the changes of the entries with the prefix after the since
+// revision. The compacted flag is set if the changes after the since revision
+// are removed. At most maxChangesPerRead changes are scanned, the returned
+// revision is the revision of the last scanned change in this case.
+func (e *executor) changes(ctx storage.ReadContext, prefix []byte, since uint64) ([]byte, error) {
+	view := e.kv.GetView()
+	defer view.Close()
+
+	revision, err := e.getRevisionInView(view)
+	if err != nil {
+		return nil, err
+	}
+	if revision > e.retention && since < revision-e.retention {
+		return encodeChangesResponse(true, revision, nil), nil
+	}
+	if since >= revision {
+		return encodeChangesResponse(false, revision, nil), nil
+	}
+
+	n := 0
+	readBytes := uint64(0)
+	var events []Event
+	start := kv.EncodeDataKey(changeKey(since+1), nil)
+	end := kv.EncodeDataKey(changeKey(revision+1), nil)
+	err = e.kv.ScanInView(view, start, end, func(key, value []byte) (bool, error) {
+		rev, err := decodeUint64(kv.DecodeDataKey(key)[1:])
+		if err != nil {
+			return false, err
+		}
+		d := decoder{data: value}
+		tp := EventType(d.byte())
+		k := d.bytes()
+		v := d.bytes()
+		if d.err != nil {
+			return false, d.err
+		}
+		readBytes += uint64(len(key) + len(value))
+		if bytes.HasPrefix(k, prefix) {
+			events = append(events, Event{Type: tp, Entry: Entry{Key: k, Value: v, Revision: rev}})
+		}
+		n++
+		if n == maxChangesPerRead {
+			revision = rev
+			return false, nil
+		}
+		return true, nil
+	}, true)
+	if err != nil {
+		return nil, err
+	}
+	ctx.SetReadBytes(readBytes)
+	return encodeChangesResponse(false, revision, events), nil
+}
+
+func (e *executor) getRevision() (uint64, error) {
+	v, err := e.kv.Get(dataRevisionKey)
+	if err != nil || len(v) == 0 {
+		return 0, err
+	}
+	return decodeUint64(v)
+}
+
+func (e *executor) getRevisionInView(view storage.View) (uint64, error) {
+	revision := uint64(0)
+	err := e.kv.ScanInView(view, dataRevisionKey, kv.NextKey(dataRevisionKey, nil),
+		func(key, value []byte) (bool, error) {
+			v, err := decodeUint64(value)
+			revision = v
+			return false, err
+		}, false)
+	return revision, err
+}
@@ -0,0 +1,153 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/matrixorigin/matrixcube/storage"
+	"github.com/matrixorigin/matrixcube/storage/kv"
+	"github.com/matrixorigin/matrixcube/storage/kv/mem"
+	"github.com/matrixorigin/matrixcube/vfs"
+)
+
+type testDataStorage struct {
+	t     *testing.T
+	kv    storage.KVStorage
+	ds    storage.DataStorage
+	index uint64
+}
+
+func newTestDataStorage(t *testing.T, retention uint64) *testDataStorage {
+	kvs := mem.NewStorage()
+	base := kv.NewBaseStorage(kvs, vfs.GetTestFS())
+	return &testDataStorage{t: t, kv: kvs, ds: kv.NewKVDataStorage(base, NewExecutor(kvs, retention))}
+}
+
+func (s *testDataStorage) write(requests ...storage.Request) []uint64 {
+	s.index++
+	ctx := storage.NewSimpleWriteContext(1, s.kv, storage.Batch{Index: s.index, Requests: requests})
+	assert.NoError(s.t, s.ds.Write(ctx))
+	var revisions []uint64
+	for _, rsp := range ctx.Responses() {
+		v, err := decodeUint64(rsp)
+		assert.NoError(s.t, err)
+		revisions = append(revisions, v)
+	}
+	return revisions
+}
+
+func (s *testDataStorage) read(req storage.Request) []byte {
+	v, err := s.ds.Read(storage.NewSimpleReadContext(1, req))
+	assert.NoError(s.t, err)
+	return v
+}
+
+func (s *testDataStorage) get(key string) (uint64, []byte) {
+	v := s.read(storage.Request{CmdType: getCmd, Key: entryKey([]byte(key))})
+	if len(v) == 0 {
+		return 0, nil
+	}
+	revision, value, err := decodeEntryValue(v)
+	assert.NoError(s.t, err)
+	return revision, value
+}
+
+func (s *testDataStorage) changes(prefix string, since uint64) (bool, uint64, []Event) {
+	compacted, revision, events, err := decodeChangesResponse(s.read(storage.Request{
+		CmdType: changesCmd,
+		Key:     entryKey([]byte(prefix)),
+		Cmd:     encodeUint64(since),
+	}))
+	assert.NoError(s.t, err)
+	return compacted, revision, events
+}
+
+func newPutRequest(key, value string) storage.Request {
+	return storage.Request{CmdType: putCmd, Key: entryKey([]byte(key)), Cmd: []byte(value)}
+}
+
+func newDeleteRequest(key string) storage.Request {
+	return storage.Request{CmdType: deleteCmd, Key: entryKey([]byte(key))}
+}
+
+func TestExecutorPutAndDelete(t *testing.T) {
+	s := newTestDataStorage(t, 0)
+	defer s.ds.Close()
+
+	assert.Equal(t, []uint64{1, 2}, s.write(newPutRequest("a", "v1"), newPutRequest("b", "v2")))
+	rev, value := s.get("a")
+	assert.Equal(t, uint64(1), rev)
+	assert.Equal(t, []byte("v1"), value)
+
+	assert.Equal(t, []uint64{3}, s.write(newPutRequest("a", "v3")))
+	rev, value = s.get("a")
+	assert.Equal(t, uint64(3), rev)
+	assert.Equal(t, []byte("v3"), value)
+
+	assert.Equal(t, []uint64{4}, s.write(newDeleteRequest("a")))
+	rev, value = s.get("a")
+	assert.Equal(t, uint64(0), rev)
+	assert.Nil(t, value)
+}
+
+func TestExecutorList(t *testing.T) {
+	s := newTestDataStorage(t, 0)
+	defer s.ds.Close()
+
+	s.write(newPutRequest("t1/a", "1"), newPutRequest("t1/b", "2"), newPutRequest("t2/a", "3"))
+	entries, revision, err := decodeListResponse(s.read(storage.Request{CmdType: listCmd, Key: entryKey([]byte("t1/"))}))
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(3), revision)
+	assert.Equal(t, []Entry{
+		{Key: []byte("t1/a"), Value: []byte("1"), Revision: 1},
+		{Key: []byte("t1/b"), Value: []byte("2"), Revision: 2},
+	}, entries)
+
+	entries, _, err = decodeListResponse(s.read(storage.Request{CmdType: listCmd, Key: entryKey(nil)}))
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(entries))
+}
+
+func TestExecutorChanges(t *testing.T) {
+	s := newTestDataStorage(t, 3)
+	defer s.ds.Close()
+
+	s.write(newPutRequest("t1/a", "1"), newPutRequest("t2/a", "2"))
+	s.write(newDeleteRequest("t1/a"))
+
+	compacted, revision, events := s.changes("t1/", 0)
+	assert.False(t, compacted)
+	assert.Equal(t, uint64(3), revision)
+	assert.Equal(t, []Event{
+		{Type: PutEvent, Entry: Entry{Key: []byte("t1/a"), Value: []byte("1"), Revision: 1}},
+		{Type: DeleteEvent, Entry: Entry{Key: []byte("t1/a"), Value: []byte{}, Revision: 3}},
+	}, events)
+
+	compacted, revision, events = s.changes("t1/", 3)
+	assert.False(t, compacted)
+	assert.Equal(t, uint64(3), revision)
+	assert.Empty(t, events)
+
+	// the changes older than the retention are removed
+	s.write(newPutRequest("t1/b", "4"))
+	compacted, revision, _ = s.changes("t1/", 0)
+	assert.True(t, compacted)
+	assert.Equal(t, uint64(4), revision)
+	compacted, _, events = s.changes("t1/", 1)
+	assert.False(t, compacted)
+	assert.Equal(t, 2, len(events))
+}
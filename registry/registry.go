@@ -0,0 +1,203 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package registry provides a small cluster wide metadata registry backed by a
+// dedicated shard group, the executors which need the shared schema or config
+// are able to store and watch it without another coordination service.
+//
+// The registry group must have a single shard managing the whole key range,
+// its data storage uses the executor returned by NewExecutor with the shard
+// split disabled. The shard is usually created by the CustomInitShardsFactory.
+package registry
+
+import (
+	"context"
+	"time"
+
+	"github.com/matrixorigin/matrixcube/client"
+)
+
+const (
+	defaultWatchInterval = time.Millisecond * 200
+)
+
+// Registry is the client of the registry. All changes are ordered by the
+// revision of the registry, the Watch returns the changes after the specified
+// revision.
+type Registry interface {
+	// Put puts the key-value pair, and returns the revision of the change.
+	Put(ctx context.Context, key, value []byte) (uint64, error)
+	// Delete deletes the key, and returns the revision of the change.
+	Delete(ctx context.Context, key []byte) (uint64, error)
+	// Get returns the entry of the key, false is returned if the key not found.
+	Get(ctx context.Context, key []byte) (Entry, bool, error)
+	// List returns the entries with the prefix and the current revision.
+	List(ctx context.Context, prefix []byte) ([]Entry, uint64, error)
+	// Watch watches the changes of the entries with the prefix after the
+	// revision, the channel is closed once the ctx is done. Use the revision
+	// returned by List to watch the changes after listing.
+	Watch(ctx context.Context, prefix []byte, revision uint64) <-chan WatchResponse
+}
+
+// WatchResponse is the changes received by the watcher. The Revision is the
+// revision the watcher has caught up to. If Reset is set, the changes after the
+// watched revision are compacted and Events are all the current entries as
+// PutEvents, the watcher should drop the entries it has before applying them.
+type WatchResponse struct {
+	Events   []Event
+	Revision uint64
+	Reset    bool
+}
+
+// Option is the option of the registry
+type Option func(*registry)
+
+// WithWatchInterval sets the interval of the watchers polling the changes
+func WithWatchInterval(interval time.Duration) Option {
+	return func(r *registry) {
+		r.watchInterval = interval
+	}
+}
+
+type registry struct {
+	client        client.Client
+	group         uint64
+	watchInterval time.Duration
+}
+
+// NewRegistry returns the registry on the shard group.
+func NewRegistry(c client.Client, group uint64, opts ...Option) Registry {
+	r := &registry{client: c, group: group, watchInterval: defaultWatchInterval}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+func (r *registry) Put(ctx context.Context, key, value []byte) (uint64, error) {
+	f := r.client.Write(ctx, putCmd, value, r.options(key)...)
+	defer f.Close()
+
+	v, err := f.Get()
+	if err != nil {
+		return 0, err
+	}
+	return decodeUint64(v)
+}
+
+func (r *registry) Delete(ctx context.Context, key []byte) (uint64, error) {
+	f := r.client.Write(ctx, deleteCmd, nil, r.options(key)...)
+	defer f.Close()
+
+	v, err := f.Get()
+	if err != nil {
+		return 0, err
+	}
+	return decodeUint64(v)
+}
+
+func (r *registry) Get(ctx context.Context, key []byte) (Entry, bool, error) {
+	f := r.client.Read(ctx, getCmd, nil, r.options(key)...)
+	defer f.Close()
+
+	v, err := f.Get()
+	if err != nil || len(v) == 0 {
+		return Entry{}, false, err
+	}
+	revision, value, err := decodeEntryValue(v)
+	if err != nil {
+		return Entry{}, false, err
+	}
+	return Entry{Key: key, Value: value, Revision: revision}, true, nil
+}
+
+func (r *registry) List(ctx context.Context, prefix []byte) ([]Entry, uint64, error) {
+	f := r.client.Read(ctx, listCmd, nil, r.options(prefix)...)
+	defer f.Close()
+
+	v, err := f.Get()
+	if err != nil {
+		return nil, 0, err
+	}
+	return decodeListResponse(v)
+}
+
+func (r *registry) Watch(ctx context.Context, prefix []byte, revision uint64) <-chan WatchResponse {
+	c := make(chan WatchResponse, 16)
+	go r.watch(ctx, prefix, revision, c)
+	return c
+}
+
+func (r *registry) watch(ctx context.Context, prefix []byte, revision uint64, c chan WatchResponse) {
+	defer close(c)
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+
+		rsp, err := r.changes(ctx, prefix, revision)
+		if err == nil && (rsp.Reset || len(rsp.Events) > 0) {
+			select {
+			case c <- rsp:
+			case <-ctx.Done():
+				return
+			}
+		}
+		interval := r.watchInterval
+		if err == nil {
+			// poll again at once if the revision is moved, more changes may be
+			// pending as the changes are returned in batches
+			if rsp.Revision > revision {
+				interval = 0
+			}
+			revision = rsp.Revision
+		}
+		timer.Reset(interval)
+	}
+}
+
+func (r *registry) changes(ctx context.Context, prefix []byte, revision uint64) (WatchResponse, error) {
+	f := r.client.Read(ctx, changesCmd, encodeUint64(revision), r.options(prefix)...)
+	defer f.Close()
+
+	v, err := f.Get()
+	if err != nil {
+		return WatchResponse{}, err
+	}
+	compacted, current, events, err := decodeChangesResponse(v)
+	if err != nil {
+		return WatchResponse{}, err
+	}
+	if !compacted {
+		return WatchResponse{Events: events, Revision: current}, nil
+	}
+
+	entries, current, err := r.List(ctx, prefix)
+	if err != nil {
+		return WatchResponse{}, err
+	}
+	rsp := WatchResponse{Revision: current, Reset: true}
+	for _, e := range entries {
+		rsp.Events = append(rsp.Events, Event{Type: PutEvent, Entry: e})
+	}
+	return rsp, nil
+}
+
+func (r *registry) options(key []byte) []client.Option {
+	return []client.Option{client.WithShardGroup(r.group), client.WithRouteKey(entryKey(key))}
+}
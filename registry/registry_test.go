@@ -0,0 +1,122 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/matrixorigin/matrixcube/client"
+	"github.com/matrixorigin/matrixcube/config"
+	"github.com/matrixorigin/matrixcube/raftstore"
+	"github.com/matrixorigin/matrixcube/storage"
+	"github.com/matrixorigin/matrixcube/storage/kv"
+	"github.com/matrixorigin/matrixcube/storage/kv/mem"
+	"github.com/matrixorigin/matrixcube/util/leaktest"
+)
+
+func readTestWatchResponse(t *testing.T, c <-chan WatchResponse) WatchResponse {
+	select {
+	case rsp := <-c:
+		return rsp
+	case <-time.After(time.Second * 10):
+		assert.FailNow(t, "timeout")
+	}
+	return WatchResponse{}
+}
+
+func TestRegistry(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	var ds storage.DataStorage
+	c := raftstore.NewSingleTestClusterStore(t, raftstore.WithAppendTestClusterAdjustConfigFunc(func(node int, cfg *config.Config) {
+		kvs := mem.NewStorage()
+		ds = kv.NewKVDataStorage(kv.NewBaseStorage(kvs, cfg.FS), NewExecutor(kvs, 2),
+			kv.WithFeature(storage.Feature{DisableShardSplit: true}))
+		cfg.Storage.DataStorageFactory = func(group uint64) storage.DataStorage {
+			return ds
+		}
+		cfg.Storage.ForeachDataStorageFunc = func(cb func(uint64, storage.DataStorage)) {
+			cb(0, ds)
+		}
+	}))
+	c.Start()
+	defer func() {
+		c.Stop()
+		ds.Close()
+	}()
+	c.WaitShardByCountPerNode(1, time.Second*10)
+
+	cli := client.NewClient(client.Cfg{Store: c.GetStore(0)})
+	assert.NoError(t, cli.Start())
+	defer cli.Stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	r := NewRegistry(cli, 0, WithWatchInterval(time.Millisecond*10))
+	rev, err := r.Put(ctx, []byte("t1/a"), []byte("v1"))
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), rev)
+
+	entry, ok, err := r.Get(ctx, []byte("t1/a"))
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, Entry{Key: []byte("t1/a"), Value: []byte("v1"), Revision: 1}, entry)
+
+	entries, rev, err := r.List(ctx, []byte("t1/"))
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), rev)
+	assert.Equal(t, 1, len(entries))
+
+	watchCtx, watchCancel := context.WithCancel(ctx)
+	defer watchCancel()
+	w := r.Watch(watchCtx, []byte("t1/"), rev)
+
+	_, err = r.Put(ctx, []byte("t2/a"), []byte("v2"))
+	assert.NoError(t, err)
+	rev, err = r.Delete(ctx, []byte("t1/a"))
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(3), rev)
+
+	rsp := readTestWatchResponse(t, w)
+	assert.False(t, rsp.Reset)
+	assert.Equal(t, uint64(3), rsp.Revision)
+	assert.Equal(t, 1, len(rsp.Events))
+	assert.Equal(t, DeleteEvent, rsp.Events[0].Type)
+	assert.Equal(t, []byte("t1/a"), rsp.Events[0].Entry.Key)
+
+	_, ok, err = r.Get(ctx, []byte("t1/a"))
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	// the watcher falls behind the retention is reset with the current entries
+	watchCancel()
+	for range w {
+	}
+	_, err = r.Put(ctx, []byte("t1/b"), []byte("v3"))
+	assert.NoError(t, err)
+	w = r.Watch(ctx, []byte("t1/"), 1)
+	rsp = readTestWatchResponse(t, w)
+	assert.True(t, rsp.Reset)
+	assert.Equal(t, uint64(4), rsp.Revision)
+	assert.Equal(t, 1, len(rsp.Events))
+	assert.Equal(t, []byte("t1/b"), rsp.Events[0].Entry.Key)
+	cancel()
+	for range w {
+	}
+}
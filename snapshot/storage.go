@@ -0,0 +1,160 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snapshot
+
+import (
+	"io"
+	"strings"
+	"time"
+
+	"github.com/matrixorigin/matrixcube/util/fileutil"
+	"github.com/matrixorigin/matrixcube/vfs"
+)
+
+const (
+	fsSnapshotStorageTempSuffix = ".uploading"
+)
+
+// SnapshotStorage is a remote storage of the snapshot images shared by all the
+// stores, e.g. an object store. The sending store uploads the snapshot image to
+// it and sends the manifest of the image only, the receiving store downloads
+// the image from it directly.
+type SnapshotStorage interface {
+	// Upload uploads the content of the reader as the object of the key.
+	Upload(key string, r io.Reader) error
+	// Download returns the content of the object of the key.
+	Download(key string) (io.ReadCloser, error)
+	// Delete deletes the object of the key, it's not an error if the object
+	// does not exist.
+	Delete(key string) error
+	// List returns the objects with the key prefix.
+	List(prefix string) ([]SnapshotObject, error)
+}
+
+// SnapshotObject is an object in the SnapshotStorage
+type SnapshotObject struct {
+	Key string
+	// LastModified the time of the object uploaded
+	LastModified time.Time
+}
+
+type fsSnapshotStorage struct {
+	dir string
+	fs  vfs.FS
+}
+
+var _ SnapshotStorage = (*fsSnapshotStorage)(nil)
+
+// NewFSSnapshotStorage returns a SnapshotStorage storing the objects as the
+// files in the dir, the key is the path relative to the dir. The dir must be
+// shared by all the stores, e.g. a NFS mount.
+func NewFSSnapshotStorage(dir string, fs vfs.FS) SnapshotStorage {
+	return &fsSnapshotStorage{dir: dir, fs: fs}
+}
+
+func (s *fsSnapshotStorage) Upload(key string, r io.Reader) (err error) {
+	fp := s.path(key)
+	if err := s.fs.MkdirAll(s.fs.PathDir(fp), 0755); err != nil {
+		return err
+	}
+	// the object is invisible until it's completely uploaded
+	tmp := fp + fsSnapshotStorageTempSuffix
+	f, err := s.fs.Create(tmp)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			s.fs.Remove(tmp)
+		}
+	}()
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := s.fs.Rename(tmp, fp); err != nil {
+		return err
+	}
+	return fileutil.SyncDir(s.fs.PathDir(fp), s.fs)
+}
+
+func (s *fsSnapshotStorage) Download(key string) (io.ReadCloser, error) {
+	return s.fs.Open(s.path(key))
+}
+
+func (s *fsSnapshotStorage) Delete(key string) error {
+	fp := s.path(key)
+	exist, err := fileutil.Exist(fp, s.fs)
+	if err != nil || !exist {
+		return err
+	}
+	return s.fs.Remove(fp)
+}
+
+func (s *fsSnapshotStorage) List(prefix string) ([]SnapshotObject, error) {
+	exist, err := fileutil.Exist(s.dir, s.fs)
+	if err != nil || !exist {
+		return nil, err
+	}
+
+	var objects []SnapshotObject
+	if err := s.walk("", func(key string, modTime time.Time) {
+		if strings.HasPrefix(key, prefix) &&
+			!strings.HasSuffix(key, fsSnapshotStorageTempSuffix) {
+			objects = append(objects, SnapshotObject{Key: key, LastModified: modTime})
+		}
+	}); err != nil {
+		return nil, err
+	}
+	return objects, nil
+}
+
+func (s *fsSnapshotStorage) walk(dir string, fn func(key string, modTime time.Time)) error {
+	names, err := s.fs.List(s.path(dir))
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		key := name
+		if dir != "" {
+			key = dir + "/" + name
+		}
+		fi, err := s.fs.Stat(s.path(key))
+		if err != nil {
+			return err
+		}
+		if fi.IsDir() {
+			if err := s.walk(key, fn); err != nil {
+				return err
+			}
+			continue
+		}
+		fn(key, fi.ModTime())
+	}
+	return nil
+}
+
+func (s *fsSnapshotStorage) path(key string) string {
+	if key == "" {
+		return s.dir
+	}
+	return s.fs.PathJoin(append([]string{s.dir}, strings.Split(key, "/")...)...)
+}
@@ -0,0 +1,70 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snapshot
+
+import (
+	"bytes"
+	"io/ioutil"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/matrixorigin/matrixcube/vfs"
+)
+
+func TestFSSnapshotStorage(t *testing.T) {
+	fs := vfs.GetTestFS()
+	defer reportLeakedFD(fs, t)
+	dir := "/tmp/snapshot_storage_test_dir_safe_to_delete"
+	require.NoError(t, fs.RemoveAll(dir))
+	defer func() {
+		require.NoError(t, fs.RemoveAll(dir))
+	}()
+
+	s := NewFSSnapshotStorage(dir, fs)
+	objects, err := s.List("")
+	require.NoError(t, err)
+	assert.Empty(t, objects)
+
+	require.NoError(t, s.Upload("1/a/f1", bytes.NewReader([]byte("v1"))))
+	require.NoError(t, s.Upload("1/a/b/f2", bytes.NewReader([]byte("v2"))))
+	require.NoError(t, s.Upload("2/a/f3", bytes.NewReader([]byte("v3"))))
+
+	objects, err = s.List("1/")
+	require.NoError(t, err)
+	var keys []string
+	for _, o := range objects {
+		keys = append(keys, o.Key)
+		assert.False(t, o.LastModified.IsZero())
+	}
+	sort.Strings(keys)
+	assert.Equal(t, []string{"1/a/b/f2", "1/a/f1"}, keys)
+
+	r, err := s.Download("1/a/b/f2")
+	require.NoError(t, err)
+	v, err := ioutil.ReadAll(r)
+	require.NoError(t, err)
+	require.NoError(t, r.Close())
+	assert.Equal(t, []byte("v2"), v)
+
+	require.NoError(t, s.Delete("1/a/b/f2"))
+	require.NoError(t, s.Delete("1/a/b/f2"))
+	_, err = s.Download("1/a/b/f2")
+	assert.Error(t, err)
+	objects, err = s.List("")
+	require.NoError(t, err)
+	assert.Equal(t, 2, len(objects))
+}
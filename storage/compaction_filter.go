@@ -0,0 +1,53 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+// CompactionFilter is provided by the executor to drop the records which are
+// logically deleted or expired, e.g. the MVCC versions older than the GC safe
+// point, without proposing the deletes through raft.
+type CompactionFilter interface {
+	// Filter returns true if the key can be dropped. The key is the key written
+	// by the executor and the value is its latest value. The safePoint is the GC
+	// safe point of the shard group managed by prophet, no txn reads at a
+	// timestamp before it, so the records which are invisible to all the reads
+	// at or after the safePoint can be dropped safely. The Filter must be
+	// deterministic for the same key, value and safePoint.
+	Filter(key, value []byte, safePoint uint64) bool
+}
+
+// CompactionFilterDataStorage is implemented by the DataStorage created with a
+// CompactionFilter. The keys are checked by the filter when they are rewritten
+// by the compactions of the underlying storage, and the filtered keys are
+// dropped by each replica locally at the interval of the
+// CompactionFilterDuration of the Feature.
+type CompactionFilterDataStorage interface {
+	// SetGCSafePoint sets the GC safe point used to filter the keys, the safe
+	// point never goes backwards.
+	SetGCSafePoint(safePoint uint64)
+	// DropFilteredData drops the keys found filtered by the compactions since
+	// the last call, the keys rewritten since then are dropped only if they are
+	// still filtered. It returns the number of the keys dropped.
+	DropFilteredData() (uint64, error)
+}
+
+// CompactionObserverStorage is implemented by the KVStorages reporting the keys
+// written by their compactions, which is used to run the CompactionFilter without
+// scanning the data. A KVStorage reports to a single observer, so the data
+// storages created with a CompactionFilter must not share the KVStorage.
+type CompactionObserverStorage interface {
+	// SetCompactionObserver sets the func called with each key and value written
+	// by the flushes and the compactions, the key and value must not be retained
+	// after the func returns. The func is called concurrently by the compactions.
+	SetCompactionObserver(fn func(key, value []byte)) error
+}
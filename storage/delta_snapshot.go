@@ -0,0 +1,26 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+// DeltaSnapshotStorage is implemented by the DataStorage which supports the delta
+// snapshots. A delta snapshot only contains the data of the shard changed since a
+// base index, it's sent instead of the full snapshot to the replica which already
+// applied the base index but is behind the compacted raft log. The delta snapshot
+// is applied by the ApplySnapshot of the DataStorage.
+type DeltaSnapshotStorage interface {
+	// CreateDeltaSnapshot creates a delta snapshot of the shard under the path
+	// with the data changed since the base index. ErrDeltaSnapshotUnavailable is
+	// returned if the changes since the base index are not known.
+	CreateDeltaSnapshot(shardID uint64, base uint64, path string) error
+}
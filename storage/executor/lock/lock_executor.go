@@ -0,0 +1,370 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lock
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/matrixorigin/matrixcube/storage"
+	"github.com/matrixorigin/matrixcube/util"
+)
+
+// Lock storage layout
+//
+// Each lock is stored as a key-value pair, the key is the name of the lock, the
+// value is the fencing token and the expire time in unix milliseconds, both in 8
+// bytes big endian, followed by the owner. A released lock keeps its fencing
+// token with an empty owner, so the fencing tokens of a lock are increasing even
+// if the lock is released and acquired again.
+//
+// The time is not read from the local clock when the commands are applied, which
+// is different on the replicas, the writes use the timestamp assigned by the
+// leader proposing the batch, see `storage.Batch.Timestamp`, so all the replicas
+// see the same time. The reads use the clock of the replica serving the read. The
+// clocks of the clients are never used, so the clock skew of the stores should be
+// much less than the TTL of the locks.
+//
+// The invalid requests are responded with the error flag and the error message,
+// `DecodeResult` returns the error.
+
+var (
+	errInvalidCmd       = errors.New("invalid lock cmd")
+	errInvalidLock      = errors.New("invalid lock value")
+	errMissingTimestamp = errors.New("missing the leader timestamp of the lock cmd")
+)
+
+const (
+	acquireCmd = 1
+	renewCmd   = 2
+	releaseCmd = 3
+	getCmd     = 4
+)
+
+const (
+	tokenLen    = 8
+	expireAtLen = 8
+	headerLen   = tokenLen + expireAtLen
+	cmdLen      = 16
+
+	succeededFlag = 1
+	errorFlag     = 2
+)
+
+// Lock is the state of a lock.
+type Lock struct {
+	// Owner is the owner holding the lock, empty if the lock is not held
+	Owner []byte
+	// Token is the fencing token of the lock, it's increased every time the
+	// lock is acquired by a new owner. The resources protected by the lock
+	// should reject the requests with a token less than the last one seen.
+	Token uint64
+	// ExpireAt is the time the lock expires unless renewed
+	ExpireAt time.Time
+}
+
+// Result is the response of the lock requests.
+type Result struct {
+	// Succeeded is true if the acquire, renew or release succeeded, or the lock
+	// is held for the get request
+	Succeeded bool
+	// Lock is the current state of the lock after the request
+	Lock Lock
+}
+
+// lockExecutor is a executor of the distributed locks with TTL.
+type lockExecutor struct {
+	kv storage.KVStorage
+	// now returns the current time of the reads
+	now func() time.Time
+}
+
+var _ storage.Executor = (*lockExecutor)(nil)
+
+// NewLockExecutor returns a lock executor that supports acquire, renew, release
+// and get commands of the distributed locks with TTL and fencing tokens. The
+// applications enable the lock service for a group by using it as the executor
+// of the data storage of the group in `Config.Storage.DataStorageFactory`.
+func NewLockExecutor(kv storage.KVStorage) storage.Executor {
+	return &lockExecutor{kv: kv, now: time.Now}
+}
+
+func (e *lockExecutor) UpdateWriteBatch(ctx storage.WriteContext) error {
+	writtenBytes := uint64(0)
+	diffKeys := int64(0)
+	wb := ctx.WriteBatch().(util.WriteBatch)
+	requests := ctx.Batch().Requests
+	// the requests must see the locks written by the previous requests of the
+	// batch, which are not applied to the kv yet.
+	pending := make(map[string][]byte)
+	now := time.Unix(0, ctx.Batch().Timestamp)
+	for j := range requests {
+		req := requests[j]
+		cmd, err := decodeCmd(req.Cmd)
+		if err != nil {
+			ctx.AppendResponse(encodeError(err))
+			continue
+		}
+		if ctx.Batch().Timestamp == 0 {
+			ctx.AppendResponse(encodeError(errMissingTimestamp))
+			continue
+		}
+
+		value, ok := pending[string(req.Key)]
+		if !ok {
+			value, err = e.kv.Get(req.Key)
+			if err != nil {
+				return err
+			}
+		}
+		exists := len(value) > 0
+		l, err := decodeLock(value)
+		if err != nil {
+			ctx.AppendResponse(encodeError(err))
+			continue
+		}
+
+		succeeded := false
+		switch req.CmdType {
+		case acquireCmd:
+			if len(cmd.owner) == 0 {
+				// the lock without owner is not held
+			} else if l.heldBy(cmd.owner, now) {
+				succeeded = true
+				l.ExpireAt = now.Add(cmd.ttl)
+			} else if !l.held(now) {
+				succeeded = true
+				l.Owner = cmd.owner
+				l.Token++
+				l.ExpireAt = now.Add(cmd.ttl)
+			}
+		case renewCmd:
+			if l.heldBy(cmd.owner, now) && l.Token == cmd.token {
+				succeeded = true
+				l.ExpireAt = now.Add(cmd.ttl)
+			}
+		case releaseCmd:
+			if bytes.Equal(l.Owner, cmd.owner) && len(l.Owner) > 0 &&
+				l.Token == cmd.token {
+				succeeded = true
+				l.Owner = nil
+				l.ExpireAt = time.Time{}
+			}
+		default:
+			ctx.AppendResponse(encodeError(fmt.Errorf("invalid write cmd %d", req.CmdType)))
+			continue
+		}
+
+		if succeeded {
+			v := encodeLock(l)
+			wb.Set(req.Key, v)
+			pending[string(req.Key)] = v
+			writtenBytes += uint64(len(req.Key) + len(v))
+			if !exists {
+				diffKeys++
+			}
+		}
+		ctx.AppendResponse(encodeResult(succeeded, l))
+	}
+
+	writtenBytes += uint64(16)
+	ctx.SetDiffBytes(int64(writtenBytes))
+	ctx.SetDiffKeys(diffKeys)
+	ctx.SetWrittenBytes(writtenBytes)
+	return nil
+}
+
+func (e *lockExecutor) ApplyWriteBatch(r storage.Resetable) error {
+	wb := r.(util.WriteBatch)
+	return e.kv.Write(wb, false)
+}
+
+func (e *lockExecutor) Read(ctx storage.ReadContext) ([]byte, error) {
+	req := ctx.Request()
+	switch req.CmdType {
+	case getCmd:
+		value, err := e.get(ctx.View(), req.Key)
+		if err != nil {
+			return nil, err
+		}
+		l, err := decodeLock(value)
+		if err != nil {
+			return encodeError(err), nil
+		}
+		ctx.SetReadBytes(uint64(len(value)))
+		return encodeResult(l.held(e.now()), l), nil
+	default:
+		return encodeError(fmt.Errorf("invalid read cmd %d", req.CmdType)), nil
+	}
+}
+
+func (e *lockExecutor) get(view storage.View, key []byte) ([]byte, error) {
+	if view == nil {
+		return e.kv.Get(key)
+	}
+
+	var value []byte
+	end := append(append(make([]byte, 0, len(key)+1), key...), 0)
+	err := e.kv.ScanInView(view, key, end, func(key, v []byte) (bool, error) {
+		value = v
+		return false, nil
+	}, true)
+	return value, err
+}
+
+// held returns true if the lock is held by any owner at the time.
+func (l Lock) held(now time.Time) bool {
+	return len(l.Owner) > 0 && now.Before(l.ExpireAt)
+}
+
+// heldBy returns true if the lock is held by the owner at the time.
+func (l Lock) heldBy(owner []byte, now time.Time) bool {
+	return l.held(now) && bytes.Equal(l.Owner, owner)
+}
+
+// cmd is the decoded lock cmd, encoded as:
+// ttl(8 bytes) + token(8 bytes) + owner
+type cmd struct {
+	ttl   time.Duration
+	token uint64
+	owner []byte
+}
+
+func encodeCmd(ttl time.Duration, token uint64, owner []byte) []byte {
+	v := make([]byte, cmdLen+len(owner))
+	binary.BigEndian.PutUint64(v, uint64(ttl.Milliseconds()))
+	binary.BigEndian.PutUint64(v[8:], token)
+	copy(v[cmdLen:], owner)
+	return v
+}
+
+func decodeCmd(v []byte) (cmd, error) {
+	if len(v) < cmdLen {
+		return cmd{}, errInvalidCmd
+	}
+	return cmd{
+		ttl:   time.Duration(binary.BigEndian.Uint64(v)) * time.Millisecond,
+		token: binary.BigEndian.Uint64(v[8:]),
+		owner: v[cmdLen:],
+	}, nil
+}
+
+func encodeLock(l Lock) []byte {
+	v := make([]byte, headerLen+len(l.Owner))
+	binary.BigEndian.PutUint64(v, l.Token)
+	binary.BigEndian.PutUint64(v[tokenLen:], toUnixMilli(l.ExpireAt))
+	copy(v[headerLen:], l.Owner)
+	return v
+}
+
+func decodeLock(v []byte) (Lock, error) {
+	if len(v) == 0 {
+		return Lock{}, nil
+	}
+	if len(v) < headerLen {
+		return Lock{}, errInvalidLock
+	}
+	l := Lock{Token: binary.BigEndian.Uint64(v)}
+	if expireAt := binary.BigEndian.Uint64(v[tokenLen:]); expireAt > 0 {
+		l.ExpireAt = unixMilli(expireAt)
+	}
+	if len(v) > headerLen {
+		l.Owner = append([]byte{}, v[headerLen:]...)
+	}
+	return l, nil
+}
+
+func encodeResult(succeeded bool, l Lock) []byte {
+	v := encodeLock(l)
+	resp := make([]byte, 1+len(v))
+	if succeeded {
+		resp[0] = succeededFlag
+	}
+	copy(resp[1:], v)
+	return resp
+}
+
+func encodeError(err error) []byte {
+	return append([]byte{errorFlag}, err.Error()...)
+}
+
+// toUnixMilli returns the unix milliseconds of the time, 0 if the time is zero.
+func toUnixMilli(t time.Time) uint64 {
+	if t.IsZero() {
+		return 0
+	}
+	return uint64(t.UnixNano() / int64(time.Millisecond))
+}
+
+func unixMilli(ms uint64) time.Time {
+	return time.Unix(0, int64(ms)*int64(time.Millisecond))
+}
+
+// NewAcquireRequest returns the request acquiring the lock for the owner with
+// the TTL. The lock is acquired if it's not held or expired, the
+// fencing token is increased, and the TTL is renewed if it's already held by the
+// owner with the fencing token unchanged.
+func NewAcquireRequest(key, owner []byte, ttl time.Duration) storage.Request {
+	return storage.Request{
+		CmdType: acquireCmd,
+		Key:     key,
+		Cmd:     encodeCmd(ttl, 0, owner),
+	}
+}
+
+// NewRenewRequest returns the request renewing the TTL of the lock held by the
+// owner with the fencing token, it fails if the lock is expired.
+func NewRenewRequest(key, owner []byte, token uint64, ttl time.Duration) storage.Request {
+	return storage.Request{
+		CmdType: renewCmd,
+		Key:     key,
+		Cmd:     encodeCmd(ttl, token, owner),
+	}
+}
+
+// NewReleaseRequest returns the request releasing the lock held by the owner with
+// the fencing token.
+func NewReleaseRequest(key, owner []byte, token uint64) storage.Request {
+	return storage.Request{
+		CmdType: releaseCmd,
+		Key:     key,
+		Cmd:     encodeCmd(0, token, owner),
+	}
+}
+
+// NewGetRequest returns the request reading the state of the lock.
+func NewGetRequest(key []byte) storage.Request {
+	return storage.Request{
+		CmdType: getCmd,
+		Key:     key,
+	}
+}
+
+// DecodeResult decodes the response of the lock requests.
+func DecodeResult(resp []byte) (Result, error) {
+	if len(resp) == 0 {
+		return Result{}, fmt.Errorf("invalid lock response")
+	}
+	if resp[0]&errorFlag != 0 {
+		return Result{}, errors.New(string(resp[1:]))
+	}
+	l, err := decodeLock(resp[1:])
+	if err != nil {
+		return Result{}, err
+	}
+	return Result{Succeeded: resp[0]&succeededFlag != 0, Lock: l}, nil
+}
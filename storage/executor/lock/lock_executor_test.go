@@ -0,0 +1,127 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lock
+
+import (
+	"testing"
+	"time"
+
+	"github.com/matrixorigin/matrixcube/storage"
+	"github.com/matrixorigin/matrixcube/storage/kv"
+	"github.com/matrixorigin/matrixcube/storage/kv/mem"
+	"github.com/matrixorigin/matrixcube/vfs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLockExecutor(t *testing.T) {
+	base := kv.NewBaseStorage(mem.NewStorage(), vfs.GetTestFS())
+	e := &lockExecutor{kv: base}
+	ds := kv.NewKVDataStorage(base, e)
+	defer ds.Close()
+
+	index := uint64(0)
+	writeAt := func(now time.Time, requests ...storage.Request) []Result {
+		index++
+		ctx := storage.NewSimpleWriteContext(1, base,
+			storage.Batch{Index: index, Timestamp: now.UnixNano(), Requests: requests})
+		require.NoError(t, ds.Write(ctx))
+		var results []Result
+		for _, resp := range ctx.Responses() {
+			r, err := DecodeResult(resp)
+			require.NoError(t, err)
+			results = append(results, r)
+		}
+		return results
+	}
+	get := func(now time.Time) Result {
+		e.now = func() time.Time { return now }
+		resp, err := ds.Read(storage.NewSimpleReadContext(1, NewGetRequest([]byte("l1"))))
+		require.NoError(t, err)
+		r, err := DecodeResult(resp)
+		require.NoError(t, err)
+		return r
+	}
+
+	now := time.Unix(1000, 0)
+	ttl := time.Second * 10
+	key := []byte("l1")
+	o1, o2 := []byte("o1"), []byte("o2")
+
+	assert.False(t, get(now).Succeeded)
+	results := writeAt(now, NewAcquireRequest(key, o1, ttl),
+		// held by o1 in the same batch
+		NewAcquireRequest(key, o2, ttl))
+	assert.True(t, results[0].Succeeded)
+	assert.Equal(t, uint64(1), results[0].Lock.Token)
+	assert.Equal(t, now.Add(ttl), results[0].Lock.ExpireAt)
+	assert.False(t, results[1].Succeeded)
+	assert.Equal(t, o1, results[1].Lock.Owner)
+	// re-entrant
+	results = writeAt(now.Add(time.Second), NewAcquireRequest(key, o1, ttl))
+	assert.True(t, results[0].Succeeded)
+	assert.Equal(t, uint64(1), results[0].Lock.Token)
+	assert.Equal(t, now.Add(time.Second+ttl), results[0].Lock.ExpireAt)
+
+	r := get(now)
+	assert.True(t, r.Succeeded)
+	assert.Equal(t, o1, r.Lock.Owner)
+	assert.False(t, get(now.Add(time.Second+ttl)).Succeeded)
+
+	// renew with the fencing token
+	results = writeAt(now.Add(time.Second*5), NewRenewRequest(key, o1, 2, ttl),
+		NewRenewRequest(key, o2, 1, ttl),
+		NewRenewRequest(key, o1, 1, ttl))
+	assert.False(t, results[0].Succeeded)
+	assert.False(t, results[1].Succeeded)
+	assert.True(t, results[2].Succeeded)
+	assert.Equal(t, now.Add(time.Second*5+ttl), results[2].Lock.ExpireAt)
+
+	// the expired lock is acquired by others with a new fencing token
+	expired := now.Add(time.Second*5 + ttl)
+	results = writeAt(expired, NewRenewRequest(key, o1, 1, ttl),
+		NewAcquireRequest(key, o2, ttl),
+		NewReleaseRequest(key, o1, 1))
+	assert.False(t, results[0].Succeeded)
+	assert.True(t, results[1].Succeeded)
+	assert.Equal(t, uint64(2), results[1].Lock.Token)
+	assert.False(t, results[2].Succeeded)
+
+	// the fencing token is kept after released
+	results = writeAt(expired, NewReleaseRequest(key, o2, 2),
+		NewAcquireRequest(key, o1, ttl))
+	assert.True(t, results[0].Succeeded)
+	assert.Empty(t, results[0].Lock.Owner)
+	assert.True(t, results[1].Succeeded)
+	assert.Equal(t, uint64(3), results[1].Lock.Token)
+
+	assert.False(t, writeAt(now, NewAcquireRequest([]byte("l2"), nil, ttl))[0].Succeeded)
+
+	// the invalid requests are responded with the errors
+	index++
+	ctx := storage.NewSimpleWriteContext(1, base, storage.Batch{Index: index,
+		Requests: []storage.Request{NewAcquireRequest(key, o1, ttl),
+			{CmdType: acquireCmd, Key: key, Cmd: []byte("invalid")},
+			{CmdType: getCmd, Key: key, Cmd: encodeCmd(ttl, 0, o1)}}})
+	require.NoError(t, ds.Write(ctx))
+	require.Equal(t, 3, len(ctx.Responses()))
+	for _, resp := range ctx.Responses() {
+		_, err := DecodeResult(resp)
+		assert.Error(t, err)
+	}
+	resp, err := ds.Read(storage.NewSimpleReadContext(1, storage.Request{CmdType: acquireCmd, Key: key}))
+	require.NoError(t, err)
+	_, err = DecodeResult(resp)
+	assert.Error(t, err)
+}
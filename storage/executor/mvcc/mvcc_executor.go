@@ -0,0 +1,511 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mvcc
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/matrixorigin/matrixcube/pb/metapb"
+	"github.com/matrixorigin/matrixcube/storage"
+	"github.com/matrixorigin/matrixcube/storage/kv"
+	"github.com/matrixorigin/matrixcube/util"
+)
+
+// MVCC storage layout
+//
+// Each version of a key is stored as a separate key-value pair, the key is the
+// user key followed by the bitwise inverted commit timestamp in 8 bytes big
+// endian, so the versions of a key are sorted from the newest to the oldest.
+// The value is a kind byte followed by the user value, a delete is stored as a
+// tombstone version. A read at a timestamp returns the newest version whose
+// commit timestamp is not greater than the read timestamp.
+//
+// The versions of a key are not contiguous if the key is a prefix of another
+// key, e.g. the versions of "a" and "a\xff" are interleaved, the reads and the
+// GC track the keys whose versions are not finished yet to handle it.
+//
+// Each response is a flag byte followed by the result of the request, the
+// invalid requests are responded with the error flag and the error message
+// rather than failing the executor, `DecodeResponse` returns the error.
+
+const (
+	putCmd    = 1
+	deleteCmd = 2
+	gcCmd     = 3
+	getCmd    = 4
+	scanCmd   = 5
+)
+
+const (
+	tsLen = 8
+	// maxGCScanVersions the max number of the versions scanned by a gc request,
+	// the gc of a shard is split into many requests to keep the apply short
+	maxGCScanVersions = 4096
+
+	tombstoneKind byte = 0
+	valueKind     byte = 1
+
+	errorFlag byte = 1
+)
+
+var (
+	// OK response with put and delete
+	OK = []byte("OK")
+
+	errInvalidCmd = errors.New("invalid mvcc cmd")
+)
+
+// mvccExecutor is a kv executor storing the multi-versioned keys.
+type mvccExecutor struct {
+	kv storage.KVStorage
+}
+
+var _ storage.Executor = (*mvccExecutor)(nil)
+
+// NewMVCCExecutor returns a mvcc kv executor that supports put/delete at a
+// commit timestamp, get/scan at a read timestamp and gc of the old versions.
+// The user keys should not be the prefix of other user keys, e.g. fixed length
+// keys, or the data storage should be created with `kv.WithSplitKeyFunc(SplitKey)`
+// to keep all the versions of a key in the same shard.
+func NewMVCCExecutor(kv storage.KVStorage) storage.Executor {
+	return &mvccExecutor{kv: kv}
+}
+
+func (e *mvccExecutor) UpdateWriteBatch(ctx storage.WriteContext) error {
+	writtenBytes := uint64(0)
+	diffKeys := int64(0)
+	wb := ctx.WriteBatch().(util.WriteBatch)
+	requests := ctx.Batch().Requests
+	for j := range requests {
+		req := requests[j]
+		switch req.CmdType {
+		case putCmd, deleteCmd:
+			ts, value, err := decodeTS(req.Cmd)
+			if err != nil {
+				ctx.AppendResponse(encodeError(err))
+				continue
+			}
+			var v []byte
+			if req.CmdType == putCmd {
+				v = encodeValue(valueKind, value)
+			} else {
+				v = encodeValue(tombstoneKind, nil)
+			}
+			key := EncodeKey(req.Key, ts)
+			wb.Set(key, v)
+			writtenBytes += uint64(len(key) + len(v))
+			diffKeys++
+		case gcCmd:
+			safeTS, _, err := decodeTS(req.Cmd)
+			if err != nil {
+				ctx.AppendResponse(encodeError(err))
+				continue
+			}
+			start, end := clipToShard(ctx.Shard(), req.Key, nil)
+			n, next, err := e.gc(wb, start, end, safeTS)
+			if err != nil {
+				return err
+			}
+			diffKeys -= int64(n)
+			ctx.AppendResponse(encodeResult(next))
+			continue
+		default:
+			panic(fmt.Errorf("invalid write cmd %d", req.CmdType))
+		}
+		ctx.AppendResponse(encodeResult(OK))
+	}
+
+	writtenBytes += uint64(16)
+	ctx.SetDiffBytes(int64(writtenBytes))
+	ctx.SetDiffKeys(diffKeys)
+	ctx.SetWrittenBytes(writtenBytes)
+	return nil
+}
+
+func (e *mvccExecutor) ApplyWriteBatch(r storage.Resetable) error {
+	wb := r.(util.WriteBatch)
+	return e.kv.Write(wb, false)
+}
+
+func (e *mvccExecutor) Read(ctx storage.ReadContext) ([]byte, error) {
+	req := ctx.Request()
+	switch req.CmdType {
+	case getCmd:
+		ts, _, err := decodeTS(req.Cmd)
+		if err != nil {
+			return encodeError(err), nil
+		}
+		v, err := e.get(ctx.View(), ctx.Shard(), req.Key, ts)
+		if err != nil {
+			return nil, err
+		}
+		ctx.SetReadBytes(uint64(len(v)))
+		return encodeResult(v), nil
+	case scanCmd:
+		ts, end, err := decodeTS(req.Cmd)
+		if err != nil {
+			return encodeError(err), nil
+		}
+		start, end := clipToShard(ctx.Shard(), req.Key, kv.EncodeShardEnd(end, nil))
+		v, next, err := e.scan(ctx.View(), start, end, ts, req.MaxResponseBytes)
+		if err != nil {
+			return nil, err
+		}
+		if len(next) > 0 {
+			ctx.SetContinuationKey(next)
+		}
+		ctx.SetReadBytes(uint64(len(v)))
+		return encodeResult(v), nil
+	default:
+		panic(fmt.Errorf("invalid read cmd %d", req.CmdType))
+	}
+}
+
+func (e *mvccExecutor) iterate(view storage.View, start, end []byte,
+	handler func(key, value []byte) (bool, error)) error {
+	if view != nil {
+		return e.kv.ScanInView(view, start, end, handler, false)
+	}
+	return e.kv.Scan(start, end, handler, false)
+}
+
+// clipToShard returns the range [start, end) clipped to the range of the shard,
+// the empty start or end means the start or end of the shard.
+func clipToShard(shard metapb.Shard, start, end []byte) ([]byte, []byte) {
+	shardStart := kv.EncodeShardStart(shard.Start, nil)
+	if len(start) == 0 || bytes.Compare(start, shardStart) < 0 {
+		start = shardStart
+	}
+	shardEnd := kv.EncodeShardEnd(shard.End, nil)
+	if len(end) == 0 || bytes.Compare(end, shardEnd) > 0 {
+		end = shardEnd
+	}
+	return start, end
+}
+
+// get returns the value of the newest version of the key not newer than ts, the
+// versions of the keys prefixed by the key are only iterated in the shard.
+func (e *mvccExecutor) get(view storage.View, shard metapb.Shard, key []byte, ts uint64) ([]byte, error) {
+	var value []byte
+	start, end := clipToShard(shard, EncodeKey(key, ts), prefixEnd(key))
+	if bytes.Compare(start, end) >= 0 {
+		return nil, nil
+	}
+	err := e.iterate(view, start, end,
+		func(k, v []byte) (bool, error) {
+			// the versions of other keys prefixed by the key
+			if len(k) != len(key)+tsLen {
+				return true, nil
+			}
+			if v[0] == valueKind {
+				value = append([]byte{}, v[1:]...)
+			}
+			return false, nil
+		})
+	return value, err
+}
+
+// scan returns the encoded key-value pairs of the newest versions of the keys
+// in the range [start, end) not newer than ts, in the order of the keys. If the
+// maxBytes > 0, the result is truncated before exceeding it, but at least one
+// pair is returned, and the user key to continue the scan from is returned.
+func (e *mvccExecutor) scan(view storage.View, start, end []byte, ts uint64,
+	maxBytes uint64) ([]byte, []byte, error) {
+	var keys, values [][]byte
+	tracker := &versionTracker{}
+	err := e.iterate(view, start, end, func(k, v []byte) (bool, error) {
+		key, version := decodeKey(k)
+		if key == nil || version > ts {
+			return true, nil
+		}
+		// the versions of the keys before the start prefixed by the start, e.g.
+		// the scan continued from a continuation key
+		if bytes.Compare(key, start) < 0 {
+			return true, nil
+		}
+		// only the newest visible version of the key is returned
+		if tracker.visit(k, key) > 1 {
+			return true, nil
+		}
+		if v[0] == valueKind {
+			keys = append(keys, append([]byte{}, kv.DecodeDataKey(key)...))
+			values = append(values, append([]byte{}, v[1:]...))
+		}
+		return true, nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	idx := make([]int, len(keys))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.Slice(idx, func(i, j int) bool {
+		return bytes.Compare(keys[idx[i]], keys[idx[j]]) < 0
+	})
+	var resp []byte
+	for n, i := range idx {
+		size := len(resp)
+		resp = appendBytes(resp, keys[i])
+		resp = appendBytes(resp, values[i])
+		if n > 0 && maxBytes > 0 && uint64(len(resp)) > maxBytes {
+			return resp[:size], keys[i], nil
+		}
+	}
+	return resp, nil, nil
+}
+
+// gc removes the versions of the keys in the range [start, end) which are not
+// visible to the reads at or after the safeTS, i.e. the versions older than the
+// newest version not newer than the safeTS, and the newest version itself if it
+// is a tombstone. At most maxGCScanVersions versions are scanned, it returns the
+// number of the removed versions and the user key to continue the gc from, nil if
+// the whole range is done. It only stops at the key whose versions are not
+// interleaved with the versions of the scanned keys.
+func (e *mvccExecutor) gc(wb util.WriteBatch, start, end []byte, safeTS uint64) (int, []byte, error) {
+	n := 0
+	scanned := 0
+	var next []byte
+	tracker := &versionTracker{}
+	err := e.kv.Scan(start, end, func(k, v []byte) (bool, error) {
+		key, version := decodeKey(k)
+		if scanned >= maxGCScanVersions && key != nil && tracker.finished(k) {
+			next = append([]byte{}, kv.DecodeDataKey(key)...)
+			return false, nil
+		}
+		scanned++
+		if key == nil || version > safeTS {
+			return true, nil
+		}
+		if tracker.visit(k, key) > 1 || v[0] == tombstoneKind {
+			wb.Delete(k)
+			n++
+		}
+		return true, nil
+	}, true)
+	return n, next, err
+}
+
+// versionTracker counts the visited versions of the keys whose versions may be
+// not finished, i.e. the keys prefixing the current position of the iteration.
+type versionTracker struct {
+	// keys are sorted by the length, each key is a prefix of the next one
+	keys   [][]byte
+	counts []int
+}
+
+// visit records the version of the key at the position, and returns the number
+// of the visited versions of the key.
+func (t *versionTracker) visit(position, key []byte) int {
+	t.advance(position)
+	idx := sort.Search(len(t.keys), func(i int) bool {
+		return len(t.keys[i]) >= len(key)
+	})
+	if idx < len(t.keys) && len(t.keys[idx]) == len(key) {
+		t.counts[idx]++
+		return t.counts[idx]
+	}
+	t.keys = append(t.keys, nil)
+	t.counts = append(t.counts, 0)
+	copy(t.keys[idx+1:], t.keys[idx:])
+	copy(t.counts[idx+1:], t.counts[idx:])
+	t.keys[idx] = append([]byte{}, key...)
+	t.counts[idx] = 1
+	return 1
+}
+
+// finished returns true if all the versions of the visited keys are visited at
+// the position, i.e. no visited key prefixes the position.
+func (t *versionTracker) finished(position []byte) bool {
+	t.advance(position)
+	return len(t.keys) == 0
+}
+
+// advance drops the keys not prefixing the position, all their versions are
+// visited.
+func (t *versionTracker) advance(position []byte) {
+	for len(t.keys) > 0 && !bytes.HasPrefix(position, t.keys[len(t.keys)-1]) {
+		t.keys = t.keys[:len(t.keys)-1]
+		t.counts = t.counts[:len(t.counts)-1]
+	}
+}
+
+// EncodeKey returns the storage key of the version of the key at the ts.
+func EncodeKey(key []byte, ts uint64) []byte {
+	v := make([]byte, len(key)+tsLen)
+	copy(v, key)
+	binary.BigEndian.PutUint64(v[len(key):], math.MaxUint64-ts)
+	return v
+}
+
+// decodeKey returns the user key and the ts of the storage key, the returned
+// key is nil if it's not a valid storage key.
+func decodeKey(key []byte) ([]byte, uint64) {
+	if len(key) < tsLen {
+		return nil, 0
+	}
+	n := len(key) - tsLen
+	return key[:n], math.MaxUint64 - binary.BigEndian.Uint64(key[n:])
+}
+
+// SplitKey returns the user key of the storage key, it's used as the split key
+// of the shard to keep all the versions of the user key in the same shard.
+func SplitKey(key []byte) []byte {
+	if k, _ := decodeKey(key); k != nil {
+		return k
+	}
+	return key
+}
+
+func prefixEnd(key []byte) []byte {
+	end := append([]byte{}, key...)
+	for i := len(end) - 1; i >= 0; i-- {
+		end[i]++
+		if end[i] != 0 {
+			return end[:i+1]
+		}
+	}
+	return nil
+}
+
+func encodeValue(kind byte, value []byte) []byte {
+	v := make([]byte, 1+len(value))
+	v[0] = kind
+	copy(v[1:], value)
+	return v
+}
+
+func encodeTS(ts uint64, data []byte) []byte {
+	v := make([]byte, tsLen+len(data))
+	binary.BigEndian.PutUint64(v, ts)
+	copy(v[tsLen:], data)
+	return v
+}
+
+func decodeTS(cmd []byte) (uint64, []byte, error) {
+	if len(cmd) < tsLen {
+		return 0, nil, errInvalidCmd
+	}
+	return binary.BigEndian.Uint64(cmd), cmd[tsLen:], nil
+}
+
+func encodeResult(v []byte) []byte {
+	return append([]byte{0}, v...)
+}
+
+func encodeError(err error) []byte {
+	return append([]byte{errorFlag}, err.Error()...)
+}
+
+func appendBytes(dst, v []byte) []byte {
+	var size [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(size[:], uint64(len(v)))
+	return append(append(dst, size[:n]...), v...)
+}
+
+// NewPutRequest returns the request writing the version of the key at the
+// commit ts.
+func NewPutRequest(key, value []byte, ts uint64) storage.Request {
+	return storage.Request{
+		CmdType: putCmd,
+		Key:     key,
+		Cmd:     encodeTS(ts, value),
+	}
+}
+
+// NewDeleteRequest returns the request deleting the key at the commit ts.
+func NewDeleteRequest(key []byte, ts uint64) storage.Request {
+	return storage.Request{
+		CmdType: deleteCmd,
+		Key:     key,
+		Cmd:     encodeTS(ts, nil),
+	}
+}
+
+// NewGCRequest returns the request removing the versions of the keys of the
+// shard from the start key which are not visible to the reads at or after the
+// safe ts. A request only scans a limited number of versions, the result is
+// the key to continue the gc of the shard from by the next request, empty if
+// all the keys of the shard are done.
+func NewGCRequest(start []byte, safeTS uint64) storage.Request {
+	return storage.Request{
+		CmdType: gcCmd,
+		Key:     start,
+		Cmd:     encodeTS(safeTS, nil),
+	}
+}
+
+// NewGetRequest returns the request reading the value of the key at the ts,
+// the result is nil if the key doesn't exist at the ts.
+func NewGetRequest(key []byte, ts uint64) storage.Request {
+	return storage.Request{
+		CmdType: getCmd,
+		Key:     key,
+		Cmd:     encodeTS(ts, nil),
+	}
+}
+
+// NewScanRequest returns the request reading the keys in the range [start, end)
+// of the shard at the ts, the response is decoded by `DecodeScanResponse`.
+func NewScanRequest(start, end []byte, ts uint64) storage.Request {
+	return storage.Request{
+		CmdType: scanCmd,
+		Key:     start,
+		Cmd:     encodeTS(ts, end),
+	}
+}
+
+// DecodeResponse returns the result of the response, or the error if the
+// request is invalid. The result is nil if it's empty.
+func DecodeResponse(resp []byte) ([]byte, error) {
+	if len(resp) == 0 {
+		return nil, fmt.Errorf("invalid mvcc response")
+	}
+	if resp[0]&errorFlag != 0 {
+		return nil, errors.New(string(resp[1:]))
+	}
+	if len(resp) == 1 {
+		return nil, nil
+	}
+	return resp[1:], nil
+}
+
+// DecodeScanResponse decodes the keys and values of the scan response.
+func DecodeScanResponse(resp []byte) ([][]byte, [][]byte, error) {
+	resp, err := DecodeResponse(resp)
+	if err != nil {
+		return nil, nil, err
+	}
+	var keys, values [][]byte
+	for len(resp) > 0 {
+		var fields [2][]byte
+		for i := range fields {
+			size, n := binary.Uvarint(resp)
+			if n <= 0 || uint64(len(resp)-n) < size {
+				return nil, nil, fmt.Errorf("invalid mvcc scan response")
+			}
+			fields[i] = resp[n : n+int(size)]
+			resp = resp[n+int(size):]
+		}
+		keys = append(keys, fields[0])
+		values = append(values, fields[1])
+	}
+	return keys, values, nil
+}
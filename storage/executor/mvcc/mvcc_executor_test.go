@@ -0,0 +1,288 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mvcc
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/matrixorigin/matrixcube/pb/metapb"
+	"github.com/matrixorigin/matrixcube/storage"
+	"github.com/matrixorigin/matrixcube/storage/kv"
+	"github.com/matrixorigin/matrixcube/storage/kv/mem"
+	"github.com/matrixorigin/matrixcube/vfs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestDataStorage(t *testing.T) (storage.DataStorage, storage.KVStorage) {
+	base := kv.NewBaseStorage(mem.NewStorage(), vfs.GetTestFS())
+	return kv.NewKVDataStorage(base, NewMVCCExecutor(base),
+		kv.WithSplitKeyFunc(SplitKey)), base
+}
+
+func TestReadAndWrite(t *testing.T) {
+	ds, base := newTestDataStorage(t)
+	defer ds.Close()
+
+	index := uint64(0)
+	write := func(requests ...storage.Request) [][]byte {
+		index++
+		ctx := storage.NewSimpleWriteContext(1, base,
+			storage.Batch{Index: index, Requests: requests})
+		require.NoError(t, ds.Write(ctx))
+		return ctx.Responses()
+	}
+	get := func(key string, ts uint64) []byte {
+		resp, err := ds.Read(storage.NewSimpleReadContext(1, NewGetRequest([]byte(key), ts)))
+		require.NoError(t, err)
+		v, err := DecodeResponse(resp)
+		require.NoError(t, err)
+		return v
+	}
+	versions := func() int {
+		n := 0
+		require.NoError(t, base.Scan(kv.EncodeShardStart(nil, nil), kv.EncodeShardEnd(nil, nil),
+			func(key, value []byte) (bool, error) {
+				n++
+				return true, nil
+			}, false))
+		return n
+	}
+
+	write(NewPutRequest([]byte("k1"), []byte("v1"), 10),
+		NewPutRequest([]byte("k1"), []byte("v2"), 20))
+	write(NewDeleteRequest([]byte("k1"), 30),
+		// the versions are interleaved with the versions of k1
+		NewPutRequest([]byte("k1\xff"), []byte("v3"), 15),
+		NewPutRequest([]byte("k2"), []byte("v4"), 10))
+
+	assert.Nil(t, get("k1", 5))
+	assert.Equal(t, []byte("v1"), get("k1", 10))
+	assert.Equal(t, []byte("v1"), get("k1", 15))
+	assert.Equal(t, []byte("v2"), get("k1", 25))
+	assert.Nil(t, get("k1", 30))
+	assert.Nil(t, get("k1\xff", 10))
+	assert.Equal(t, []byte("v3"), get("k1\xff", 20))
+
+	resp, err := ds.Read(storage.NewSimpleReadContext(1, NewScanRequest(nil, nil, 25)))
+	require.NoError(t, err)
+	keys, values, err := DecodeScanResponse(resp)
+	require.NoError(t, err)
+	assert.Equal(t, [][]byte{[]byte("k1"), []byte("k1\xff"), []byte("k2")}, keys)
+	assert.Equal(t, [][]byte{[]byte("v2"), []byte("v3"), []byte("v4")}, values)
+
+	resp, err = ds.Read(storage.NewSimpleReadContext(1, NewScanRequest([]byte("k1\xff"), []byte("k2"), 40)))
+	require.NoError(t, err)
+	keys, _, err = DecodeScanResponse(resp)
+	require.NoError(t, err)
+	assert.Equal(t, [][]byte{[]byte("k1\xff")}, keys)
+
+	// the version at 10 is invisible at the safe ts 25
+	assert.Equal(t, 5, versions())
+	next, err := DecodeResponse(write(NewGCRequest(nil, 25))[0])
+	require.NoError(t, err)
+	assert.Empty(t, next)
+	assert.Equal(t, 4, versions())
+	assert.Equal(t, []byte("v2"), get("k1", 25))
+
+	// all the versions of the deleted key are removed
+	write(NewGCRequest(nil, 30))
+	assert.Equal(t, 2, versions())
+	assert.Nil(t, get("k1", 30))
+	assert.Equal(t, []byte("v3"), get("k1\xff", 30))
+	assert.Equal(t, []byte("v4"), get("k2", 30))
+}
+
+func TestGCInBatches(t *testing.T) {
+	ds, base := newTestDataStorage(t)
+	defer ds.Close()
+
+	var requests []storage.Request
+	for i := 0; i < maxGCScanVersions; i++ {
+		key := []byte(fmt.Sprintf("k%05d", i))
+		requests = append(requests, NewPutRequest(key, []byte("v1"), 10),
+			NewPutRequest(key, []byte("v2"), 20))
+	}
+	index := uint64(1)
+	ctx := storage.NewSimpleWriteContext(1, base, storage.Batch{Index: index, Requests: requests})
+	require.NoError(t, ds.Write(ctx))
+
+	var start []byte
+	batches := 0
+	for {
+		index++
+		ctx := storage.NewSimpleWriteContext(1, base,
+			storage.Batch{Index: index, Requests: []storage.Request{NewGCRequest(start, 20)}})
+		require.NoError(t, ds.Write(ctx))
+		batches++
+		next, err := DecodeResponse(ctx.Responses()[0])
+		require.NoError(t, err)
+		if start = next; len(start) == 0 {
+			break
+		}
+		// the batch stops at the boundary of the keys
+		assert.Equal(t, []byte(fmt.Sprintf("k%05d", maxGCScanVersions/2)), start)
+	}
+	assert.Equal(t, 2, batches)
+
+	n := 0
+	require.NoError(t, base.Scan(kv.EncodeShardStart(nil, nil), kv.EncodeShardEnd(nil, nil),
+		func(key, value []byte) (bool, error) {
+			n++
+			return true, nil
+		}, false))
+	assert.Equal(t, maxGCScanVersions, n)
+}
+
+type testShardReadContext struct {
+	*storage.SimpleReadContext
+	shard metapb.Shard
+}
+
+func (c testShardReadContext) Shard() metapb.Shard {
+	return c.shard
+}
+
+func TestReadInShard(t *testing.T) {
+	ds, base := newTestDataStorage(t)
+	defer ds.Close()
+
+	ctx := storage.NewSimpleWriteContext(1, base, storage.Batch{Index: 1, Requests: []storage.Request{
+		NewPutRequest([]byte("a"), []byte("v1"), 10),
+		NewPutRequest([]byte("a\xff"), []byte("v2"), 10),
+		NewPutRequest([]byte("b"), []byte("v3"), 10),
+	}})
+	require.NoError(t, ds.Write(ctx))
+
+	shard := metapb.Shard{ID: 1, Start: []byte("a"), End: []byte("b")}
+	read := func(req storage.Request) []byte {
+		v, err := ds.Read(testShardReadContext{storage.NewSimpleReadContext(1, req), shard})
+		require.NoError(t, err)
+		return v
+	}
+	get := func(key string) []byte {
+		v, err := DecodeResponse(read(NewGetRequest([]byte(key), 20)))
+		require.NoError(t, err)
+		return v
+	}
+	assert.Equal(t, []byte("v1"), get("a"))
+	assert.Nil(t, get("b"))
+	keys, _, err := DecodeScanResponse(read(NewScanRequest(nil, nil, 20)))
+	require.NoError(t, err)
+	assert.Equal(t, [][]byte{[]byte("a"), []byte("a\xff")}, keys)
+}
+
+func TestInvalidRequests(t *testing.T) {
+	ds, base := newTestDataStorage(t)
+	defer ds.Close()
+
+	// the cmds without the ts are responded with an error rather than failing
+	// the executor, the valid requests of the batch are still applied
+	ctx := storage.NewSimpleWriteContext(1, base, storage.Batch{Index: 1, Requests: []storage.Request{
+		{CmdType: putCmd, Key: []byte("k1"), Cmd: []byte{1}},
+		{CmdType: gcCmd, Cmd: nil},
+		NewPutRequest([]byte("k2"), []byte("v2"), 10),
+	}})
+	require.NoError(t, ds.Write(ctx))
+	require.Equal(t, 3, len(ctx.Responses()))
+	for _, resp := range ctx.Responses()[:2] {
+		_, err := DecodeResponse(resp)
+		assert.Equal(t, errInvalidCmd, err)
+	}
+	v, err := DecodeResponse(ctx.Responses()[2])
+	require.NoError(t, err)
+	assert.Equal(t, OK, v)
+
+	for _, cmdType := range []uint64{getCmd, scanCmd} {
+		resp, err := ds.Read(storage.NewSimpleReadContext(1,
+			storage.Request{CmdType: cmdType, Key: []byte("k2"), Cmd: []byte{1, 2}}))
+		require.NoError(t, err)
+		_, err = DecodeResponse(resp)
+		assert.Equal(t, errInvalidCmd, err)
+	}
+	_, _, err = DecodeScanResponse(encodeError(errInvalidCmd))
+	assert.Equal(t, errInvalidCmd, err)
+
+	resp, err := ds.Read(storage.NewSimpleReadContext(1, NewGetRequest([]byte("k2"), 10)))
+	require.NoError(t, err)
+	v, err = DecodeResponse(resp)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("v2"), v)
+}
+
+func TestVersionTracker(t *testing.T) {
+	tracker := &versionTracker{}
+	assert.Equal(t, 1, tracker.visit(EncodeKey([]byte("a"), 2), []byte("a")))
+	assert.Equal(t, 1, tracker.visit(EncodeKey([]byte("a\xff"), 1), []byte("a\xff")))
+	assert.Equal(t, 2, tracker.visit(EncodeKey([]byte("a"), 1), []byte("a")))
+	assert.Equal(t, 1, tracker.visit(EncodeKey([]byte("b"), 1), []byte("b")))
+	assert.Equal(t, 1, len(tracker.keys))
+}
+
+func TestSplitKey(t *testing.T) {
+	assert.Equal(t, []byte("k1"), SplitKey(EncodeKey([]byte("k1"), 100)))
+	assert.Equal(t, []byte("k"), SplitKey([]byte("k")))
+}
+
+func TestPrefixEnd(t *testing.T) {
+	assert.Equal(t, []byte("b"), prefixEnd([]byte("a")))
+	assert.Equal(t, []byte("b"), prefixEnd([]byte("a\xff")))
+	assert.Nil(t, prefixEnd([]byte("\xff")))
+}
+
+func TestScanWithMaxResponseBytes(t *testing.T) {
+	ds, base := newTestDataStorage(t)
+	defer ds.Close()
+
+	ctx := storage.NewSimpleWriteContext(1, base, storage.Batch{Index: 1, Requests: []storage.Request{
+		NewPutRequest([]byte("k1"), []byte("v1"), 10),
+		// the versions are interleaved with the versions of k1
+		NewPutRequest([]byte("k1\xff"), []byte("v2"), 10),
+		NewPutRequest([]byte("k2"), []byte("v3"), 10),
+	}})
+	require.NoError(t, ds.Write(ctx))
+
+	// each response is truncated to a single key, and the scan continues from
+	// the continuation key without the keys returned before
+	var keys, values [][]byte
+	var start []byte
+	for i := 0; i < 3; i++ {
+		req := NewScanRequest(start, nil, 20)
+		req.MaxResponseBytes = 1
+		ctx := storage.NewSimpleReadContext(1, req)
+		resp, err := ds.Read(ctx)
+		require.NoError(t, err)
+		k, v, err := DecodeScanResponse(resp)
+		require.NoError(t, err)
+		require.Equal(t, 1, len(k))
+		keys = append(keys, k...)
+		values = append(values, v...)
+		start = ctx.ContinuationKey()
+	}
+	assert.Empty(t, start)
+	assert.Equal(t, [][]byte{[]byte("k1"), []byte("k1\xff"), []byte("k2")}, keys)
+	assert.Equal(t, [][]byte{[]byte("v1"), []byte("v2"), []byte("v3")}, values)
+
+	// not truncated within the max response bytes
+	req := NewScanRequest(nil, nil, 20)
+	req.MaxResponseBytes = 1024
+	rctx := storage.NewSimpleReadContext(1, req)
+	resp, err := ds.Read(rctx)
+	require.NoError(t, err)
+	k, _, err := DecodeScanResponse(resp)
+	require.NoError(t, err)
+	assert.Equal(t, 3, len(k))
+	assert.Empty(t, rctx.ContinuationKey())
+}
@@ -14,6 +14,9 @@
 package simple
 
 import (
+	"bytes"
+	"encoding/binary"
+	"errors"
 	"fmt"
 
 	"github.com/matrixorigin/matrixcube/storage"
@@ -21,8 +24,20 @@ import (
 )
 
 const (
-	setCmd = 1
-	getCmd = 2
+	setCmd         = 1
+	getCmd         = 2
+	checkAndSetCmd = 3
+)
+
+const (
+	// flags of the check and set request
+	expectExists = 1
+	// flags of the check and set response
+	casSucceeded = 1
+	casOldExists = 2
+	// the invalid check and set request is responded with the error message
+	// instead of the old value
+	casInvalid = 4
 )
 
 var (
@@ -37,24 +52,61 @@ type simpleKVExecutor struct {
 
 var _ storage.Executor = (*simpleKVExecutor)(nil)
 
-// NewSimpleKVExecutor returns a simple kv executor that supports set/get
-// commands.
+// NewSimpleKVExecutor returns a simple kv executor that supports set/get and
+// check and set commands.
 func NewSimpleKVExecutor(kv storage.KVStorage) storage.Executor {
 	return &simpleKVExecutor{kv: kv}
 }
 
 func (ce *simpleKVExecutor) UpdateWriteBatch(ctx storage.WriteContext) error {
 	writtenBytes := uint64(0)
+	writtenKeys := int64(0)
 	r := ctx.WriteBatch()
 	wb := r.(util.WriteBatch)
 	batch := ctx.Batch()
 	requests := batch.Requests
+	// the check and set requests must see the values written by the previous
+	// requests of the batch, which are not applied to the kv yet.
+	var pending map[string][]byte
+	if hasCheckAndSet(requests) {
+		pending = make(map[string][]byte)
+	}
 	for j := range requests {
 		switch requests[j].CmdType {
 		case setCmd:
 			wb.Set(requests[j].Key, requests[j].Cmd)
+			if pending != nil {
+				pending[string(requests[j].Key)] = requests[j].Cmd
+			}
 			writtenBytes += uint64(len(requests[j].Key) + len(requests[j].Cmd))
+			writtenKeys++
 			ctx.AppendResponse(OK)
+		case checkAndSetCmd:
+			expected, value, err := decodeCheckAndSet(requests[j].Cmd)
+			if err != nil {
+				ctx.AppendResponse(encodeCheckAndSetError(err))
+				continue
+			}
+			old, ok := pending[string(requests[j].Key)]
+			if !ok {
+				old, err = ce.kv.Get(requests[j].Key)
+				if err != nil {
+					return err
+				}
+			}
+			if len(old) == 0 {
+				// the kv storage reads an empty value as absent
+				old = nil
+			}
+			succeeded := (expected == nil && old == nil) ||
+				(expected != nil && old != nil && bytes.Equal(expected, old))
+			if succeeded {
+				wb.Set(requests[j].Key, value)
+				pending[string(requests[j].Key)] = value
+				writtenBytes += uint64(len(requests[j].Key) + len(value))
+				writtenKeys++
+			}
+			ctx.AppendResponse(encodeCheckAndSetResponse(succeeded, old))
 		default:
 			panic(fmt.Errorf("invalid write cmd %d", requests[j].CmdType))
 		}
@@ -62,10 +114,20 @@ func (ce *simpleKVExecutor) UpdateWriteBatch(ctx storage.WriteContext) error {
 
 	writtenBytes += uint64(16)
 	ctx.SetDiffBytes(int64(writtenBytes))
+	ctx.SetDiffKeys(writtenKeys)
 	ctx.SetWrittenBytes(writtenBytes)
 	return nil
 }
 
+func hasCheckAndSet(requests []storage.Request) bool {
+	for _, req := range requests {
+		if req.CmdType == checkAndSetCmd {
+			return true
+		}
+	}
+	return false
+}
+
 func (ce *simpleKVExecutor) ApplyWriteBatch(r storage.Resetable) error {
 	wb := r.(util.WriteBatch)
 	return ce.kv.Write(wb, false)
@@ -75,6 +137,23 @@ func (ce *simpleKVExecutor) Read(ctx storage.ReadContext) ([]byte, error) {
 	request := ctx.Request()
 	switch request.CmdType {
 	case getCmd:
+		if view := ctx.View(); view != nil {
+			return ce.getInView(ctx, view, request.Key)
+		}
+		if ctx.ZeroCopy() {
+			if kv, ok := ce.kv.(storage.ZeroCopyKVStore); ok {
+				v, err := kv.GetReadValue(request.Key)
+				if err != nil {
+					return nil, err
+				}
+				if v != nil {
+					ctx.SetReadBytes(uint64(len(v.Data())))
+					ctx.SetReadValue(v)
+				}
+				return nil, nil
+			}
+		}
+
 		v, err := ce.kv.Get(request.Key)
 		if err != nil {
 			return nil, err
@@ -86,6 +165,20 @@ func (ce *simpleKVExecutor) Read(ctx storage.ReadContext) ([]byte, error) {
 	}
 }
 
+func (ce *simpleKVExecutor) getInView(ctx storage.ReadContext, view storage.View, key []byte) ([]byte, error) {
+	var value []byte
+	end := append(append(make([]byte, 0, len(key)+1), key...), 0)
+	err := ce.kv.ScanInView(view, key, end, func(key, v []byte) (bool, error) {
+		value = v
+		return false, nil
+	}, true)
+	if err != nil {
+		return nil, err
+	}
+	ctx.SetReadBytes(uint64(len(value)))
+	return value, nil
+}
+
 // NewWriteRequest return write request
 func NewWriteRequest(k, v []byte) storage.Request {
 	return storage.Request{
@@ -102,3 +195,78 @@ func NewReadRequest(k []byte) storage.Request {
 		Key:     k,
 	}
 }
+
+// NewCheckAndSetRequest returns a check and set request, which atomically sets
+// the value of the key only if the current value equals the expected value, a
+// nil expected value means the key must not exist. The response is parsed by
+// ParseCheckAndSetResponse.
+func NewCheckAndSetRequest(k, expected, v []byte) storage.Request {
+	return storage.Request{
+		CmdType: checkAndSetCmd,
+		Key:     k,
+		Cmd:     encodeCheckAndSet(expected, v),
+	}
+}
+
+// ParseCheckAndSetResponse parses the response of the check and set request,
+// returns whether the value is set and the old value of the key, the old value
+// is nil if the key did not exist. The error is returned if the request is
+// invalid.
+func ParseCheckAndSetResponse(resp []byte) (bool, []byte, error) {
+	if len(resp) == 0 {
+		return false, nil, fmt.Errorf("invalid check and set response")
+	}
+	if resp[0]&casInvalid != 0 {
+		return false, nil, errors.New(string(resp[1:]))
+	}
+	var old []byte
+	if resp[0]&casOldExists != 0 {
+		old = resp[1:]
+	}
+	return resp[0]&casSucceeded != 0, old, nil
+}
+
+// encodeCheckAndSet encodes the check and set cmd as:
+// flags(1 byte) + expected length(uvarint) + expected + value
+func encodeCheckAndSet(expected, v []byte) []byte {
+	buf := make([]byte, 1+binary.MaxVarintLen64+len(expected)+len(v))
+	if expected != nil {
+		buf[0] = expectExists
+	}
+	n := 1 + binary.PutUvarint(buf[1:], uint64(len(expected)))
+	n += copy(buf[n:], expected)
+	n += copy(buf[n:], v)
+	return buf[:n]
+}
+
+func decodeCheckAndSet(cmd []byte) ([]byte, []byte, error) {
+	if len(cmd) == 0 {
+		return nil, nil, fmt.Errorf("invalid check and set cmd")
+	}
+	size, n := binary.Uvarint(cmd[1:])
+	if n <= 0 || uint64(len(cmd)-1-n) < size {
+		return nil, nil, fmt.Errorf("invalid check and set cmd")
+	}
+	offset := 1 + n
+	var expected []byte
+	if cmd[0]&expectExists != 0 {
+		expected = cmd[offset : offset+int(size)]
+	}
+	return expected, cmd[offset+int(size):], nil
+}
+
+func encodeCheckAndSetResponse(succeeded bool, old []byte) []byte {
+	resp := make([]byte, 1+len(old))
+	if succeeded {
+		resp[0] |= casSucceeded
+	}
+	if old != nil {
+		resp[0] |= casOldExists
+	}
+	copy(resp[1:], old)
+	return resp
+}
+
+func encodeCheckAndSetError(err error) []byte {
+	return append([]byte{casInvalid}, err.Error()...)
+}
@@ -70,6 +70,7 @@ func TestReadAndWrite(t *testing.T) {
 			assert.True(t, reflect.DeepEqual(c.responses, ctx.Responses()), "index %d, responses %+v", i, ctx.Responses())
 			assert.True(t, ctx.GetWrittenBytes() > 0, "index %d", i)
 			assert.True(t, ctx.GetDiffBytes() > 0, "index %d", i)
+			assert.Equal(t, int64(len(c.requests.Requests)), ctx.GetDiffKeys(), "index %d", i)
 		} else {
 			for idx, req := range c.requests.Requests {
 				ctx := storage.NewSimpleReadContext(c.shard, req)
@@ -130,3 +131,90 @@ func joinResponses(values ...[][]byte) [][]byte {
 	}
 	return res
 }
+
+func TestZeroCopyRead(t *testing.T) {
+	kv := mem.NewStorage()
+	defer kv.Close()
+	executor := NewSimpleKVExecutor(kv)
+
+	wc := storage.NewSimpleWriteContext(1, kv, newWriteRequests(1, 0, 1))
+	assert.NoError(t, executor.UpdateWriteBatch(wc))
+	assert.NoError(t, executor.ApplyWriteBatch(wc.WriteBatch()))
+
+	ctx := storage.NewSimpleReadContext(1, NewReadRequest([]byte("1-0")))
+	ctx.SetZeroCopy(true)
+	rsp, err := executor.Read(ctx)
+	assert.NoError(t, err)
+	assert.Nil(t, rsp)
+	v := ctx.GetReadValue()
+	if assert.NotNil(t, v) {
+		assert.Equal(t, []byte("1-0"), v.Data())
+		assert.Equal(t, uint64(3), ctx.GetReadBytes())
+		v.Retain()
+		v.Release()
+		assert.Equal(t, []byte("1-0"), v.Data())
+		v.Release()
+		assert.Nil(t, v.Data())
+		assert.Panics(t, func() { v.Release() })
+	}
+
+	ctx = storage.NewSimpleReadContext(1, NewReadRequest([]byte("1-1")))
+	ctx.SetZeroCopy(true)
+	rsp, err = executor.Read(ctx)
+	assert.NoError(t, err)
+	assert.Nil(t, rsp)
+	assert.Nil(t, ctx.GetReadValue())
+}
+
+func TestCheckAndSet(t *testing.T) {
+	kv := mem.NewStorage()
+	defer kv.Close()
+	executor := NewSimpleKVExecutor(kv)
+
+	write := func(requests ...storage.Request) [][]byte {
+		ctx := storage.NewSimpleWriteContext(1, kv, storage.Batch{Index: 1, Requests: requests})
+		assert.NoError(t, executor.UpdateWriteBatch(ctx))
+		assert.NoError(t, executor.ApplyWriteBatch(ctx.WriteBatch()))
+		return ctx.Responses()
+	}
+	check := func(resp []byte, succeeded bool, old []byte) {
+		ok, v, err := ParseCheckAndSetResponse(resp)
+		assert.NoError(t, err)
+		assert.Equal(t, succeeded, ok)
+		assert.Equal(t, old, v)
+	}
+
+	// set if absent
+	responses := write(NewCheckAndSetRequest([]byte("k1"), nil, []byte("v1")))
+	check(responses[0], true, nil)
+	responses = write(NewCheckAndSetRequest([]byte("k1"), nil, []byte("v2")))
+	check(responses[0], false, []byte("v1"))
+
+	// compare the current value
+	responses = write(NewCheckAndSetRequest([]byte("k1"), []byte("v2"), []byte("v3")),
+		NewCheckAndSetRequest([]byte("k1"), []byte("v1"), []byte("v3")))
+	check(responses[0], false, []byte("v1"))
+	check(responses[1], true, []byte("v1"))
+
+	// see the previous writes in the same batch
+	responses = write(NewWriteRequest([]byte("k2"), []byte("v1")),
+		NewCheckAndSetRequest([]byte("k2"), []byte("v1"), []byte("v2")),
+		NewCheckAndSetRequest([]byte("k2"), []byte("v2"), []byte("v3")))
+	assert.Equal(t, OK, responses[0])
+	check(responses[1], true, []byte("v1"))
+	check(responses[2], true, []byte("v2"))
+
+	// the invalid request is responded with the error
+	responses = write(storage.Request{CmdType: checkAndSetCmd, Key: []byte("k1"), Cmd: []byte{expectExists, 10}},
+		NewCheckAndSetRequest([]byte("k1"), []byte("v3"), []byte("v3")))
+	_, _, err := ParseCheckAndSetResponse(responses[0])
+	assert.Error(t, err)
+	check(responses[1], true, []byte("v3"))
+
+	rsp, err := executor.Read(storage.NewSimpleReadContext(1, NewReadRequest([]byte("k1"))))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("v3"), rsp)
+	rsp, err = executor.Read(storage.NewSimpleReadContext(1, NewReadRequest([]byte("k2"))))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("v3"), rsp)
+}
@@ -21,6 +21,9 @@ var (
 	prefixLen       = 1
 	metaPrefix byte = 0x00
 	dataPrefix byte = 0x01
+	// quarantinePrefix is used for the orphan data moved out of the data key
+	// space.
+	quarantinePrefix byte = 0x02
 
 	minStartKey = []byte{dataPrefix}
 	maxEndKey   = []byte{dataPrefix + 1}
@@ -31,6 +34,11 @@ func EncodeDataKey(keys []byte, buffer *buf.ByteBuf) []byte {
 	return doAppendPrefix(keys, dataPrefix, buffer)
 }
 
+// EncodeQuarantineKey encodes the key of the quarantined orphan data.
+func EncodeQuarantineKey(key []byte, buffer *buf.ByteBuf) []byte {
+	return doAppendPrefix(key, quarantinePrefix, buffer)
+}
+
 // DecodeDataKey returns the origin data key.
 // Note that no data copy is generated here, only a slice of the key is returned
 func DecodeDataKey(key []byte) []byte {
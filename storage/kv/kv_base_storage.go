@@ -67,6 +67,42 @@ func (s *BaseStorage) Stats() stats.Stats {
 	return s.kv.Stats()
 }
 
+// MemoryStats returns the memory used by the wrapped KVStorage, zero if it
+// does not report the memory used.
+func (s *BaseStorage) MemoryStats() stats.MemoryStats {
+	if r, ok := s.kv.(storage.MemoryStatsReader); ok {
+		return r.MemoryStats()
+	}
+	return stats.MemoryStats{}
+}
+
+// WriteStallStats returns the write stall state of the wrapped KVStorage, zero
+// if it does not report the write stalls.
+func (s *BaseStorage) WriteStallStats() stats.WriteStallStats {
+	if r, ok := s.kv.(storage.WriteStallStatsReader); ok {
+		return r.WriteStallStats()
+	}
+	return stats.WriteStallStats{}
+}
+
+// CompactRange compacts the key range [start, end) of the wrapped KVStorage,
+// returns error if it does not support the manual compaction.
+func (s *BaseStorage) CompactRange(start, end []byte) error {
+	if c, ok := s.kv.(storage.ManualCompactionStorage); ok {
+		return c.CompactRange(start, end)
+	}
+	return storage.ErrManualCompactionNotSupported
+}
+
+// SetCompactionObserver sets the compaction observer of the wrapped KVStorage,
+// returns error if it does not report the keys written by its compactions.
+func (s *BaseStorage) SetCompactionObserver(fn func(key, value []byte)) error {
+	if o, ok := s.kv.(storage.CompactionObserverStorage); ok {
+		return o.SetCompactionObserver(fn)
+	}
+	return storage.ErrCompactionObserverNotSupported
+}
+
 func (s *BaseStorage) Write(wb util.WriteBatch, sync bool) error {
 	return s.kv.Write(wb, sync)
 }
@@ -0,0 +1,150 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kv
+
+import (
+	"sort"
+	"sync/atomic"
+
+	"go.uber.org/zap"
+
+	"github.com/matrixorigin/matrixcube/storage"
+	"github.com/matrixorigin/matrixcube/util"
+)
+
+const (
+	// maxFilteredKeys the max number of the filtered keys kept until they are
+	// dropped, the keys found after exceeded are found again by the following
+	// compactions.
+	maxFilteredKeys = 100000
+)
+
+// setCompactionObserver registers the observer checking the keys rewritten by the
+// compactions of the base storage by the compaction filter.
+func (kv *kvDataStorage) setCompactionObserver() {
+	if kv.opts.compactionFilter == nil {
+		return
+	}
+	o, ok := kv.base.(storage.CompactionObserverStorage)
+	if !ok {
+		return
+	}
+	if err := o.SetCompactionObserver(kv.observeCompaction); err != nil {
+		kv.opts.logger.Warn("compaction filter disabled",
+			zap.Error(err))
+	}
+}
+
+// SetGCSafePoint sets the GC safe point used by the compaction filter.
+func (kv *kvDataStorage) SetGCSafePoint(safePoint uint64) {
+	for {
+		old := atomic.LoadUint64(&kv.gcSafePoint)
+		if safePoint <= old ||
+			atomic.CompareAndSwapUint64(&kv.gcSafePoint, old, safePoint) {
+			return
+		}
+	}
+}
+
+// observeCompaction records the data keys filtered at the current safe point,
+// which are rewritten by the compactions of the base storage.
+func (kv *kvDataStorage) observeCompaction(key, value []byte) {
+	safePoint := atomic.LoadUint64(&kv.gcSafePoint)
+	if safePoint == 0 || len(key) == 0 || key[0] != dataPrefix ||
+		!kv.filter(key, value, safePoint) {
+		return
+	}
+
+	kv.filtered.Lock()
+	defer kv.filtered.Unlock()
+	if len(kv.filtered.keys) < maxFilteredKeys {
+		kv.filtered.keys[string(key)] = struct{}{}
+	}
+}
+
+// DropFilteredData drops the keys found filtered by the compactions. The keys
+// may be rewritten since they were found, so each key is dropped only if its
+// latest value is still filtered. Like the purge of the expired data, the
+// removal is local to the replica and not tracked for the delta snapshots.
+func (kv *kvDataStorage) DropFilteredData() (uint64, error) {
+	safePoint := atomic.LoadUint64(&kv.gcSafePoint)
+	if kv.opts.compactionFilter == nil || safePoint == 0 {
+		return 0, nil
+	}
+
+	kv.filtered.Lock()
+	found := kv.filtered.keys
+	kv.filtered.keys = make(map[string]struct{})
+	kv.filtered.Unlock()
+	if len(found) == 0 {
+		return 0, nil
+	}
+
+	keys := make([]string, 0, len(found))
+	for key := range found {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	dropped := uint64(0)
+	for len(keys) > 0 {
+		n := len(keys)
+		if n > maxPurgeBatchKeys {
+			n = maxPurgeBatchKeys
+		}
+		v, err := kv.dropFilteredKeys(keys[:n], safePoint)
+		dropped += v
+		if err != nil {
+			return dropped, err
+		}
+		keys = keys[n:]
+	}
+	return dropped, nil
+}
+
+func (kv *kvDataStorage) filter(key, value []byte, safePoint uint64) bool {
+	if kv.opts.ttl > 0 {
+		_, value = decodeTTLValue(value)
+	}
+	return kv.opts.compactionFilter.Filter(DecodeDataKey(key), value, safePoint)
+}
+
+// dropFilteredKeys removes the keys which are still filtered, the writes are
+// serialized with the removal by the purgeMu, so a key rewritten after it's
+// checked is not removed.
+func (kv *kvDataStorage) dropFilteredKeys(keys []string, safePoint uint64) (uint64, error) {
+	kv.purgeMu.Lock()
+	defer kv.purgeMu.Unlock()
+
+	wb := kv.base.NewWriteBatch().(util.WriteBatch)
+	defer wb.Close()
+	n := uint64(0)
+	for _, key := range keys {
+		v, err := kv.base.Get([]byte(key))
+		if err != nil {
+			return 0, err
+		}
+		if len(v) > 0 && kv.filter([]byte(key), v, safePoint) {
+			wb.Delete([]byte(key))
+			n++
+		}
+	}
+	if n == 0 {
+		return 0, nil
+	}
+	if err := kv.base.Write(wb, false); err != nil {
+		return 0, err
+	}
+	return n, nil
+}
@@ -0,0 +1,92 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kv
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/matrixorigin/matrixcube/storage"
+	"github.com/matrixorigin/matrixcube/util/leaktest"
+	"github.com/matrixorigin/matrixcube/vfs"
+)
+
+// testCompactionFilter drops the tombstone values once the safe point reaches
+// 100.
+type testCompactionFilter struct{}
+
+func (f testCompactionFilter) Filter(key, value []byte, safePoint uint64) bool {
+	return string(value) == "tombstone" && safePoint >= 100
+}
+
+func TestDropFilteredData(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	fs := vfs.GetTestFS()
+	defer vfs.ReportLeakedFD(fs, t)
+	kv := getTestPebbleStorage(t, fs)
+	base := NewBaseStorage(kv, fs)
+	ds := NewKVDataStorage(base, nil, WithCompactionFilter(testCompactionFilter{}))
+	defer func() {
+		require.NoError(t, fs.RemoveAll(testDir))
+	}()
+	defer ds.Close()
+	assert.Equal(t, time.Minute*10, ds.Feature().CompactionFilterDuration)
+
+	set := func(key, value string) {
+		require.NoError(t, kv.Set(EncodeDataKey([]byte(key), nil), []byte(value), false))
+	}
+	get := func(key string) []byte {
+		v, err := kv.Get(EncodeDataKey([]byte(key), nil))
+		require.NoError(t, err)
+		return v
+	}
+	compact := func() {
+		require.NoError(t, ds.(storage.ManualCompactionStorage).CompactRange(nil, nil))
+	}
+
+	cds := ds.(storage.CompactionFilterDataStorage)
+	cds.SetGCSafePoint(50)
+	set("k1", "v1")
+	set("k2", "tombstone")
+	set("k3", "tombstone")
+	set("k4", "v4")
+	compact()
+	n, err := cds.DropFilteredData()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(0), n)
+
+	// the safe point never goes backwards
+	cds.SetGCSafePoint(100)
+	cds.SetGCSafePoint(50)
+	set("k4", "tombstone")
+	set("k5", "tombstone")
+	compact()
+	// the key rewritten after found filtered is kept
+	set("k5", "v5")
+	n, err = cds.DropFilteredData()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(3), n)
+	for _, key := range []string{"k2", "k3", "k4"} {
+		assert.Empty(t, get(key))
+	}
+	assert.Equal(t, []byte("v1"), get("k1"))
+	assert.Equal(t, []byte("v5"), get("k5"))
+
+	n, err = cds.DropFilteredData()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(0), n)
+}
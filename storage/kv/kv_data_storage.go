@@ -14,6 +14,7 @@
 package kv
 
 import (
+	"bytes"
 	"fmt"
 	"math"
 	"sync"
@@ -33,6 +34,9 @@ import (
 
 var (
 	mb = uint64(1024 * 1024)
+	// maxQuarantineBatchBytes the max bytes of the orphan data moved to the
+	// quarantine key space in a write batch.
+	maxQuarantineBatchBytes = 4 * mb
 )
 
 // Option option func
@@ -42,6 +46,19 @@ type options struct {
 	sampleSync uint64
 	logger     *zap.Logger
 	feature    storage.Feature
+	// deltaSnapshotEntries the number of the last log entries of each shard whose
+	// changed keys are tracked for the delta snapshots
+	deltaSnapshotEntries uint64
+	// ttl the time to live of the data written, 0 means never expire
+	ttl time.Duration
+	// now returns the current time used to expire the data
+	now func() time.Time
+	// compactionFilter drops the data logically deleted, nil if disabled
+	compactionFilter storage.CompactionFilter
+	// splitKey returns the split key of the key found by the split check
+	splitKey func(key []byte) []byte
+	// readSnapshotTTL the default TTL of the read snapshots
+	readSnapshotTTL time.Duration
 }
 
 // WithSampleSync set sync sample interval. `Cube` will call the `GetPersistentLogIndex` method of `DataStorage` to obtain
@@ -68,8 +85,58 @@ func WithFeature(feature storage.Feature) Option {
 	}
 }
 
+// WithDeltaSnapshot enables the delta snapshots, the keys changed by the last
+// entries log entries written to each shard are tracked in memory, so the delta
+// snapshots can be created for the replicas whose applied index is within these
+// log entries. The tracked changes are lost after restart.
+func WithDeltaSnapshot(entries uint64) Option {
+	return func(opts *options) {
+		opts.deltaSnapshotEntries = entries
+	}
+}
+
+// WithTTL sets the time to live of the data written through the WriteContext,
+// the expiration time is computed from the Timestamp of the write batch and
+// encoded in the value of each key. The executor must read the data through the
+// KVStorage returned by `NewTTLKVStorage`, so the encoded values are decoded and
+// the expired keys are invisible. The data written before the TTL is enabled is
+// rewritten to never expire when the data storage is created, and the TTL can
+// not be disabled once enabled.
+func WithTTL(ttl time.Duration) Option {
+	return func(opts *options) {
+		opts.ttl = ttl
+	}
+}
+
+// WithCompactionFilter sets the CompactionFilter of the executor, the data
+// filtered is found by the compactions of the base storage and dropped
+// periodically instead of being deleted explicitly by the executor.
+func WithCompactionFilter(filter storage.CompactionFilter) Option {
+	return func(opts *options) {
+		opts.compactionFilter = filter
+	}
+}
+
+// WithSplitKeyFunc sets the func returning the split key of the key found by
+// the split check, the executor storing multiple keys for a logical key can
+// use it to keep all the keys of the logical key in the same shard.
+func WithSplitKeyFunc(fn func(key []byte) []byte) Option {
+	return func(opts *options) {
+		opts.splitKey = fn
+	}
+}
+
+// WithReadSnapshotTTL sets the default TTL of the read snapshots, the read
+// snapshot not read within the TTL is released, so a forgotten read snapshot
+// does not pin the data forever. Default is 10 minutes.
+func WithReadSnapshotTTL(ttl time.Duration) Option {
+	return func(opts *options) {
+		opts.readSnapshotTTL = ttl
+	}
+}
+
 func newOptions() *options {
-	return &options{}
+	return &options{now: time.Now}
 }
 
 func (opts *options) adjust() {
@@ -85,6 +152,10 @@ func (opts *options) adjust() {
 		opts.feature.ShardCapacityBytes = 96 * 1024 * 1024
 	}
 
+	if opts.feature.ShardStatsReconcileDuration == 0 {
+		opts.feature.ShardStatsReconcileDuration = time.Minute * 10
+	}
+
 	if opts.feature.ShardSplitCheckBytes == 0 {
 		opts.feature.ShardSplitCheckBytes = opts.feature.ShardCapacityBytes * 80 / 100
 	}
@@ -97,6 +168,18 @@ func (opts *options) adjust() {
 		opts.feature.ForceCompactBytes = opts.feature.ShardCapacityBytes * 3 / 4
 	}
 
+	if opts.ttl > 0 && opts.feature.ExpiredDataPurgeDuration == 0 {
+		opts.feature.ExpiredDataPurgeDuration = time.Minute * 10
+	}
+
+	if opts.compactionFilter != nil && opts.feature.CompactionFilterDuration == 0 {
+		opts.feature.CompactionFilterDuration = time.Minute * 10
+	}
+
+	if opts.readSnapshotTTL == 0 {
+		opts.readSnapshotTTL = time.Minute * 10
+	}
+
 	opts.logger = log.Adjust(opts.logger).Named("kv-data-storage")
 }
 
@@ -105,17 +188,50 @@ type kvDataStorage struct {
 	base       storage.KVBaseStorage
 	executor   storage.Executor
 	writeCount uint64
+	// changes the changes tracked for the delta snapshots, nil if disabled
+	changes *changeTracker
+	// purgeMu serializes the writes and the removal of the filtered keys, so a
+	// key rewritten after it's found filtered is not removed.
+	purgeMu sync.Mutex
+	// piggyback is 1 if the fsync is piggybacked on the fsync of the shared WAL
+	piggyback uint32
+	// gcSafePoint the GC safe point used by the compaction filter
+	gcSafePoint uint64
+	// filtered the keys found filtered by the compactions of the base storage
+	filtered struct {
+		sync.Mutex
+		keys map[string]struct{}
+	}
 
 	mu struct {
 		sync.RWMutex
 		loaded                   bool
 		lastAppliedIndexes       map[uint64]uint64
 		persistentAppliedIndexes map[uint64]uint64
+		// writeSeq the sequence of the last write completed, only increased if
+		// the fsync is piggybacked
+		writeSeq uint64
+		// unsynced the writes not persisted by the fsync of the shared WAL in
+		// the order of the sequence
+		unsynced []unsyncedWrite
+	}
+
+	snapshots struct {
+		sync.Mutex
+		// views shard id -> name -> read snapshot
+		views map[uint64]map[string]*readSnapshot
 	}
 }
 
 var _ storage.DataStorage = (*kvDataStorage)(nil)
 var _ storage.KVStorageWrapper = (*kvDataStorage)(nil)
+var _ storage.OrphanDataStorage = (*kvDataStorage)(nil)
+var _ storage.WarmupStorage = (*kvDataStorage)(nil)
+var _ storage.ReadSnapshotStorage = (*kvDataStorage)(nil)
+var _ storage.DeltaSnapshotStorage = (*kvDataStorage)(nil)
+var _ storage.TTLDataStorage = (*kvDataStorage)(nil)
+var _ storage.CompactionFilterDataStorage = (*kvDataStorage)(nil)
+var _ storage.ManualCompactionStorage = (*kvDataStorage)(nil)
 
 // NewKVDataStorage returns data storage based on a kv base storage.
 func NewKVDataStorage(base storage.KVBaseStorage,
@@ -127,11 +243,20 @@ func NewKVDataStorage(base storage.KVBaseStorage,
 	}
 	s.mu.lastAppliedIndexes = make(map[uint64]uint64)
 	s.mu.persistentAppliedIndexes = make(map[uint64]uint64)
+	s.snapshots.views = make(map[uint64]map[string]*readSnapshot)
+	s.filtered.keys = make(map[string]struct{})
 
 	for _, opt := range opts {
 		opt(s.opts)
 	}
 	s.opts.adjust()
+	if s.opts.deltaSnapshotEntries > 0 {
+		s.changes = newChangeTracker(s.opts.deltaSnapshotEntries)
+	}
+	if err := s.upgradeTTLFormat(); err != nil {
+		panic(err)
+	}
+	s.setCompactionObserver()
 	return s
 }
 
@@ -154,22 +279,41 @@ func (kv *kvDataStorage) Write(ctx storage.WriteContext) error {
 	for idx := range batch.Requests {
 		batch.Requests[idx].Key = EncodeDataKey(batch.Requests[idx].Key, ctx.ByteBuf())
 	}
-	if err := kv.executor.UpdateWriteBatch(ctx); err != nil {
+	wc := ctx
+	if kv.opts.ttl > 0 {
+		wc = newTTLWriteContext(ctx, kv.batchTime(batch).Add(kv.opts.ttl).UnixNano())
+	}
+	if err := kv.executor.UpdateWriteBatch(wc); err != nil {
 		return err
 	}
 	r := ctx.WriteBatch()
 	defer r.Reset()
 
+	kv.trackChanges(ctx.Shard().ID, batch.Index, r)
 	kv.setAppliedIndexToWriteBatch(ctx, batch.Index)
 	kv.updateAppliedIndex(ctx.Shard().ID, batch.Index)
-	if err := kv.executor.ApplyWriteBatch(r); err != nil {
+	kv.purgeMu.Lock()
+	err := kv.executor.ApplyWriteBatch(r)
+	kv.purgeMu.Unlock()
+	if err != nil {
 		return err
 	}
+	kv.trackUnsynced(ctx.Shard().ID, batch.Index)
 	return kv.trySync()
 }
 
 func (kv *kvDataStorage) Read(ctx storage.ReadContext) ([]byte, error) {
-	return kv.executor.Read(readContext{base: ctx})
+	name := ctx.Request().ReadSnapshot
+	if name == "" {
+		return kv.executor.Read(readContext{base: ctx})
+	}
+
+	rs, err := kv.acquireReadSnapshot(ctx.Shard().ID, name)
+	if err != nil {
+		return nil, err
+	}
+	defer kv.releaseReadSnapshotRef(rs)
+	return kv.executor.Read(readContext{base: ctx, view: rs.view})
 }
 
 func (kv *kvDataStorage) SaveShardMetadata(metadatas []metapb.ShardMetadata) error {
@@ -201,6 +345,9 @@ func (kv *kvDataStorage) SaveShardMetadata(metadatas []metapb.ShardMetadata) err
 	if err := kv.base.Write(wb, false); err != nil {
 		return err
 	}
+	for _, m := range metadatas {
+		kv.trackUnsynced(m.ShardID, m.LogIndex)
+	}
 
 	return kv.trySync()
 }
@@ -315,7 +462,12 @@ func (kv *kvDataStorage) RemoveShard(shard metapb.Shard, removeData bool) error
 	kv.mu.Lock()
 	delete(kv.mu.lastAppliedIndexes, shard.ID)
 	delete(kv.mu.persistentAppliedIndexes, shard.ID)
+	kv.removeUnsyncedLocked(shard.ID)
 	kv.mu.Unlock()
+	kv.releaseShardReadSnapshots(shard.ID)
+	if kv.changes != nil {
+		kv.changes.remove(shard.ID)
+	}
 	return kv.base.RangeDelete(min, max, false)
 }
 
@@ -329,15 +481,25 @@ func (kv *kvDataStorage) SplitCheck(shard metapb.Shard,
 	sum := uint64(0)
 	appendSplitKey := false
 	var splitKeys [][]byte
+	var last []byte
 
 	start := EncodeShardStart(shard.Start, nil)
 	end := EncodeShardEnd(shard.End, nil)
 	if err := kv.base.Scan(start, end, func(key, val []byte) (bool, error) {
-		if appendSplitKey {
-			splitKeys = append(splitKeys, key[1:])
+		splitKey := key[1:]
+		if kv.opts.splitKey != nil {
+			splitKey = kv.opts.splitKey(splitKey)
+		}
+		// the split key must be greater than the split key of the first key
+		// of the new shard, otherwise the new shard is empty
+		if appendSplitKey && bytes.Compare(splitKey, last) > 0 {
+			splitKeys = append(splitKeys, splitKey)
 			appendSplitKey = false
 			sum = 0
 		}
+		if sum == 0 {
+			last = splitKey
+		}
 		n := uint64(len(key[1:]) + len(val))
 		sum += n
 		total += n
@@ -353,6 +515,25 @@ func (kv *kvDataStorage) SplitCheck(shard metapb.Shard,
 	return total, keys, splitKeys, nil, nil
 }
 
+func (kv *kvDataStorage) PrefetchKeys(keys [][]byte) error {
+	for _, key := range keys {
+		if _, err := kv.base.Get(EncodeDataKey(key, nil)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CompactRange compacts the data keys of the user key range [start, end) in
+// the base storage, the empty end means the max key.
+func (kv *kvDataStorage) CompactRange(start, end []byte) error {
+	c, ok := kv.base.(storage.ManualCompactionStorage)
+	if !ok {
+		return storage.ErrManualCompactionNotSupported
+	}
+	return c.CompactRange(EncodeShardStart(start, nil), EncodeShardEnd(end, nil))
+}
+
 func (kv *kvDataStorage) Split(old metapb.ShardMetadata,
 	news []metapb.ShardMetadata, ctx []byte) error {
 	return kv.SaveShardMetadata(append(news, old))
@@ -362,6 +543,57 @@ func (kv *kvDataStorage) Feature() storage.Feature {
 	return kv.opts.feature
 }
 
+func (kv *kvDataStorage) ScanData(start, end []byte,
+	handler func(key []byte, size uint64) (bool, error)) error {
+	return kv.base.Scan(EncodeShardStart(start, nil), EncodeShardEnd(end, nil),
+		func(key, val []byte) (bool, error) {
+			return handler(DecodeDataKey(key), uint64(len(key)-prefixLen+len(val)))
+		}, false)
+}
+
+func (kv *kvDataStorage) RemoveOrphanData(start, end []byte) error {
+	min := EncodeShardStart(start, nil)
+	max := EncodeShardEnd(end, nil)
+	kv.opts.logger.Info("remove orphan data",
+		log.HexField("from", min),
+		log.HexField("to", max))
+	return kv.base.RangeDelete(min, max, false)
+}
+
+func (kv *kvDataStorage) QuarantineOrphanData(start, end []byte) error {
+	min := EncodeShardStart(start, nil)
+	max := EncodeShardEnd(end, nil)
+	kv.opts.logger.Info("quarantine orphan data",
+		log.HexField("from", min),
+		log.HexField("to", max))
+
+	// The quarantined data is written before the data range deleted, it is safe
+	// to redo if failed in the middle.
+	wb := kv.base.NewWriteBatch().(util.WriteBatch)
+	defer wb.Close()
+	size := uint64(0)
+	if err := kv.base.Scan(min, max, func(key, val []byte) (bool, error) {
+		wb.Set(EncodeQuarantineKey(DecodeDataKey(key), nil), val)
+		size += uint64(len(key) + len(val))
+		if size >= maxQuarantineBatchBytes {
+			if err := kv.base.Write(wb, false); err != nil {
+				return false, err
+			}
+			wb.Reset()
+			size = 0
+		}
+		return true, nil
+	}, true); err != nil {
+		return err
+	}
+	if size > 0 {
+		if err := kv.base.Write(wb, false); err != nil {
+			return err
+		}
+	}
+	return kv.base.RangeDelete(min, max, false)
+}
+
 func (kv *kvDataStorage) setAppliedIndexToWriteBatch(ctx storage.WriteContext, index uint64) {
 	r := ctx.WriteBatch()
 	wb := r.(util.WriteBatch)
@@ -372,6 +604,31 @@ func (kv *kvDataStorage) setAppliedIndexToWriteBatch(ctx storage.WriteContext, i
 	wb.Set(key, val)
 }
 
+// getAppliedIndex returns the applied index of the shard, the persistent applied
+// index loaded after restart is returned if nothing written since then.
+func (kv *kvDataStorage) getAppliedIndex(shardID uint64) uint64 {
+	kv.mu.RLock()
+	defer kv.mu.RUnlock()
+	if index, ok := kv.mu.lastAppliedIndexes[shardID]; ok {
+		return index
+	}
+	return kv.mu.persistentAppliedIndexes[shardID]
+}
+
+// trackChanges tracks the data keys changed by the write batch of the log entry
+// for the delta snapshots. The changes of the shard are dropped if the write
+// batch can not iterate its keys.
+func (kv *kvDataStorage) trackChanges(shardID uint64, index uint64, r storage.Resetable) {
+	if kv.changes == nil {
+		return
+	}
+	if wb, ok := r.(util.WriteBatchKeysIterator); ok {
+		kv.changes.track(shardID, index, getChangedKeys(wb))
+	} else {
+		kv.changes.remove(shardID)
+	}
+}
+
 func (kv *kvDataStorage) updateAppliedIndex(shardID uint64, index uint64) {
 	kv.mu.Lock()
 	defer kv.mu.Unlock()
@@ -381,6 +638,9 @@ func (kv *kvDataStorage) updateAppliedIndex(shardID uint64, index uint64) {
 // trySync syncs the data to disk every interval and then mark the appliedIndex
 // values of the raft log as persistented.
 func (kv *kvDataStorage) trySync() error {
+	if kv.syncPiggybacked() {
+		return nil
+	}
 	n := atomic.AddUint64(&kv.writeCount, 1)
 	if n%kv.opts.sampleSync != 0 {
 		return nil
@@ -395,18 +655,191 @@ func (kv *kvDataStorage) trySync() error {
 
 // delegate method
 func (kv *kvDataStorage) Close() error {
+	kv.snapshots.Lock()
+	ids := make([]uint64, 0, len(kv.snapshots.views))
+	for id := range kv.snapshots.views {
+		ids = append(ids, id)
+	}
+	kv.snapshots.Unlock()
+	for _, id := range ids {
+		kv.releaseShardReadSnapshots(id)
+	}
 	return kv.base.Close()
 }
 
+// readSnapshot is a named read snapshot of a shard, the view is closed once it
+// is released and no read is using it. It's released once not read within the
+// ttl.
+type readSnapshot struct {
+	view     storage.View
+	refs     int
+	released bool
+	ttl      time.Duration
+	// expireAt is extended by each read
+	expireAt time.Time
+	timer    *time.Timer
+}
+
+func (kv *kvDataStorage) CreateReadSnapshot(shardID uint64, name string, ttl time.Duration) error {
+	kv.snapshots.Lock()
+	defer kv.snapshots.Unlock()
+
+	views, ok := kv.snapshots.views[shardID]
+	if !ok {
+		views = make(map[string]*readSnapshot)
+		kv.snapshots.views[shardID] = views
+	}
+	if _, ok := views[name]; ok {
+		return storage.ErrReadSnapshotExists
+	}
+	if ttl == 0 {
+		ttl = kv.opts.readSnapshotTTL
+	}
+	rs := &readSnapshot{
+		view:     kv.base.GetView(),
+		ttl:      ttl,
+		expireAt: kv.opts.now().Add(ttl),
+	}
+	rs.timer = time.AfterFunc(ttl, func() {
+		kv.expireReadSnapshot(shardID, name, rs)
+	})
+	views[name] = rs
+	return nil
+}
+
+func (kv *kvDataStorage) ReleaseReadSnapshot(shardID uint64, name string) error {
+	kv.snapshots.Lock()
+	defer kv.snapshots.Unlock()
+
+	rs, ok := kv.snapshots.views[shardID][name]
+	if !ok {
+		return nil
+	}
+	return kv.removeReadSnapshotLocked(shardID, name, rs)
+}
+
+// expireReadSnapshot releases the read snapshot if it's not read within the
+// ttl, otherwise checks it again once the extended ttl passed.
+func (kv *kvDataStorage) expireReadSnapshot(shardID uint64, name string, rs *readSnapshot) {
+	kv.snapshots.Lock()
+	defer kv.snapshots.Unlock()
+
+	if current, ok := kv.snapshots.views[shardID][name]; !ok || current != rs {
+		return
+	}
+	if rs.refs > 0 {
+		rs.timer.Reset(rs.ttl)
+		return
+	}
+	if d := rs.expireAt.Sub(kv.opts.now()); d > 0 {
+		rs.timer.Reset(d)
+		return
+	}
+	kv.opts.logger.Info("read snapshot expired",
+		log.ShardIDField(shardID),
+		zap.String("name", name),
+		zap.Duration("ttl", rs.ttl))
+	if err := kv.removeReadSnapshotLocked(shardID, name, rs); err != nil {
+		kv.opts.logger.Error("failed to close read snapshot",
+			log.ShardIDField(shardID),
+			zap.Error(err))
+	}
+}
+
+func (kv *kvDataStorage) removeReadSnapshotLocked(shardID uint64, name string, rs *readSnapshot) error {
+	views := kv.snapshots.views[shardID]
+	delete(views, name)
+	if len(views) == 0 {
+		delete(kv.snapshots.views, shardID)
+	}
+	rs.timer.Stop()
+	rs.released = true
+	return kv.closeReadSnapshotLocked(rs)
+}
+
+func (kv *kvDataStorage) releaseShardReadSnapshots(shardID uint64) {
+	kv.snapshots.Lock()
+	defer kv.snapshots.Unlock()
+
+	for _, rs := range kv.snapshots.views[shardID] {
+		rs.timer.Stop()
+		rs.released = true
+		if err := kv.closeReadSnapshotLocked(rs); err != nil {
+			kv.opts.logger.Error("failed to close read snapshot",
+				log.ShardIDField(shardID),
+				zap.Error(err))
+		}
+	}
+	delete(kv.snapshots.views, shardID)
+}
+
+func (kv *kvDataStorage) acquireReadSnapshot(shardID uint64, name string) (*readSnapshot, error) {
+	kv.snapshots.Lock()
+	defer kv.snapshots.Unlock()
+
+	rs, ok := kv.snapshots.views[shardID][name]
+	if !ok {
+		return nil, storage.ErrReadSnapshotNotFound
+	}
+	rs.refs++
+	rs.expireAt = kv.opts.now().Add(rs.ttl)
+	return rs, nil
+}
+
+func (kv *kvDataStorage) releaseReadSnapshotRef(rs *readSnapshot) {
+	kv.snapshots.Lock()
+	defer kv.snapshots.Unlock()
+
+	rs.refs--
+	rs.expireAt = kv.opts.now().Add(rs.ttl)
+	if err := kv.closeReadSnapshotLocked(rs); err != nil {
+		kv.opts.logger.Error("failed to close read snapshot",
+			zap.Error(err))
+	}
+}
+
+func (kv *kvDataStorage) closeReadSnapshotLocked(rs *readSnapshot) error {
+	if !rs.released || rs.refs > 0 || rs.view == nil {
+		return nil
+	}
+	view := rs.view
+	rs.view = nil
+	return view.Close()
+}
+
 func (kv *kvDataStorage) CreateSnapshot(shardID uint64, path string) error {
 	return kv.base.CreateSnapshot(shardID, path)
 }
 
+// CreateDeltaSnapshot creates a delta snapshot with the changes tracked since the
+// base index.
+func (kv *kvDataStorage) CreateDeltaSnapshot(shardID uint64, base uint64, path string) error {
+	ds, ok := kv.base.(deltaSnapshotBase)
+	if !ok || kv.changes == nil {
+		return storage.ErrDeltaSnapshotUnavailable
+	}
+	changes, ok := kv.changes.changesSince(shardID, base)
+	if !ok {
+		return storage.ErrDeltaSnapshotUnavailable
+	}
+	return ds.createDeltaSnapshot(shardID, base, changes, path)
+}
+
 func (kv *kvDataStorage) ApplySnapshot(shardID uint64, path string) error {
 	// FIXME: kv.base.ApplySnapshot is not atomic
 	// kvDataStorage.ApplySnapshot suffers from the same issue
-	if err := kv.base.ApplySnapshot(shardID, path); err != nil {
-		return err
+	delta := false
+	if ds, ok := kv.base.(deltaSnapshotBase); ok {
+		applied, err := ds.applyDeltaSnapshot(shardID, path, kv.getAppliedIndex(shardID))
+		if err != nil {
+			return err
+		}
+		delta = applied
+	}
+	if !delta {
+		if err := kv.base.ApplySnapshot(shardID, path); err != nil {
+			return err
+		}
 	}
 	key := EncodeShardMetadataKey(keys.GetAppliedIndexKey(shardID, nil), nil)
 	v, err := kv.base.Get(key)
@@ -419,6 +852,9 @@ func (kv *kvDataStorage) ApplySnapshot(shardID uint64, path string) error {
 	var idx metapb.LogIndex
 	protoc.MustUnmarshal(&idx, v)
 	kv.updateAppliedIndex(shardID, idx.Index)
+	if kv.changes != nil {
+		kv.changes.reset(shardID, idx.Index)
+	}
 	return kv.Sync(nil)
 }
 
@@ -426,21 +862,45 @@ func (kv *kvDataStorage) Stats() stats.Stats {
 	return kv.base.Stats()
 }
 
+func (kv *kvDataStorage) MemoryStats() stats.MemoryStats {
+	if r, ok := kv.base.(storage.MemoryStatsReader); ok {
+		return r.MemoryStats()
+	}
+	return stats.MemoryStats{}
+}
+
+func (kv *kvDataStorage) WriteStallStats() stats.WriteStallStats {
+	if r, ok := kv.base.(storage.WriteStallStatsReader); ok {
+		return r.WriteStallStats()
+	}
+	return stats.WriteStallStats{}
+}
+
 func (kv *kvDataStorage) updatePersistentAppliedIndexes() {
 	kv.mu.Lock()
 	for k, v := range kv.mu.lastAppliedIndexes {
 		kv.mu.persistentAppliedIndexes[k] = v
 	}
+	kv.mu.unsynced = kv.mu.unsynced[:0]
 	kv.mu.Unlock()
 }
 
 type readContext struct {
 	base storage.ReadContext
+	view storage.View
 }
 
 func (c readContext) ByteBuf() *buf.ByteBuf { return c.base.ByteBuf() }
 func (c readContext) Shard() metapb.Shard   { return c.base.Shard() }
 func (c readContext) SetReadBytes(v uint64) { c.base.SetReadBytes(v) }
+func (c readContext) ZeroCopy() bool        { return c.base.ZeroCopy() }
+func (c readContext) View() storage.View    { return c.view }
+func (c readContext) SetReadValue(v *storage.ReadValue) {
+	c.base.SetReadValue(v)
+}
+func (c readContext) SetContinuationKey(key []byte) {
+	c.base.SetContinuationKey(key)
+}
 func (c readContext) Request() storage.Request {
 	req := c.base.Request()
 	req.Key = EncodeDataKey(req.Key, c.base.ByteBuf())
@@ -17,6 +17,7 @@ import (
 	"fmt"
 	"reflect"
 	"testing"
+	"time"
 
 	cpebble "github.com/cockroachdb/pebble"
 	"github.com/fagongzi/util/format"
@@ -454,6 +455,192 @@ func TestSplitCheck(t *testing.T) {
 	assert.Empty(t, ctx)
 }
 
+func TestSplitCheckWithSplitKeyFunc(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	fs := vfs.GetTestFS()
+	defer vfs.ReportLeakedFD(fs, t)
+	kv := getTestPebbleStorage(t, fs)
+	base := NewBaseStorage(kv, fs)
+	// the last byte is the version of the logical key
+	ds := NewKVDataStorage(base, nil, WithSplitKeyFunc(func(key []byte) []byte {
+		return key[:len(key)-1]
+	}))
+	defer func() {
+		require.NoError(t, fs.RemoveAll(testDir))
+	}()
+	defer ds.Close()
+
+	require.NoError(t, kv.Set(EncodeDataKey([]byte{1, 1}, nil), []byte{1}, false))
+	require.NoError(t, kv.Set(EncodeDataKey([]byte{1, 2}, nil), []byte{1}, false))
+	require.NoError(t, kv.Set(EncodeDataKey([]byte{2, 1}, nil), []byte{2}, false))
+	require.NoError(t, kv.Set(EncodeDataKey([]byte{2, 2}, nil), []byte{2}, false))
+
+	// all the keys of the logical key are kept in the same shard
+	_, keys, splitKeys, _, err := ds.SplitCheck(metapb.Shard{}, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(4), keys)
+	assert.Equal(t, [][]byte{{2}}, splitKeys)
+}
+
+func TestOrphanData(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	fs := vfs.GetTestFS()
+	defer vfs.ReportLeakedFD(fs, t)
+	kv := getTestPebbleStorage(t, fs)
+	base := NewBaseStorage(kv, fs)
+	ds := NewKVDataStorage(base, nil)
+	defer func() {
+		require.NoError(t, fs.RemoveAll(testDir))
+	}()
+	defer ds.Close()
+
+	require.NoError(t, kv.Set(EncodeDataKey([]byte{1}, nil), []byte{1}, false))
+	require.NoError(t, kv.Set(EncodeDataKey([]byte{2}, nil), []byte{2}, false))
+	require.NoError(t, kv.Set(EncodeDataKey([]byte{3}, nil), []byte{3}, false))
+
+	scan := func(start, end []byte) [][]byte {
+		var values [][]byte
+		assert.NoError(t, ds.(storage.OrphanDataStorage).ScanData(start, end, func(key []byte, size uint64) (bool, error) {
+			assert.Equal(t, uint64(2), size)
+			values = append(values, append([]byte(nil), key...))
+			return true, nil
+		}))
+		return values
+	}
+	assert.Equal(t, [][]byte{{1}, {2}, {3}}, scan(nil, nil))
+	assert.Equal(t, [][]byte{{2}}, scan([]byte{2}, []byte{3}))
+
+	assert.NoError(t, ds.(storage.OrphanDataStorage).RemoveOrphanData([]byte{1}, []byte{2}))
+	assert.Equal(t, [][]byte{{2}, {3}}, scan(nil, nil))
+
+	assert.NoError(t, ds.(storage.OrphanDataStorage).QuarantineOrphanData([]byte{3}, nil))
+	assert.Equal(t, [][]byte{{2}}, scan(nil, nil))
+	v, err := kv.Get(EncodeQuarantineKey([]byte{3}, nil))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{3}, v)
+}
+
+func TestPrefetchKeys(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	fs := vfs.GetTestFS()
+	defer vfs.ReportLeakedFD(fs, t)
+	kv := getTestPebbleStorage(t, fs)
+	base := NewBaseStorage(kv, fs)
+	ds := NewKVDataStorage(base, nil)
+	defer func() {
+		require.NoError(t, fs.RemoveAll(testDir))
+	}()
+	defer ds.Close()
+
+	require.NoError(t, kv.Set(EncodeDataKey([]byte{1}, nil), []byte{1}, false))
+	assert.NoError(t, ds.(storage.WarmupStorage).PrefetchKeys([][]byte{{1}, {2}}))
+}
+
+func TestCompactRange(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	fs := vfs.GetTestFS()
+	defer vfs.ReportLeakedFD(fs, t)
+	kv := getTestPebbleStorage(t, fs)
+	base := NewBaseStorage(kv, fs)
+	ds := NewKVDataStorage(base, nil)
+	defer func() {
+		require.NoError(t, fs.RemoveAll(testDir))
+	}()
+	defer ds.Close()
+
+	require.NoError(t, kv.Set(EncodeDataKey([]byte{1}, nil), []byte{1}, false))
+	require.NoError(t, kv.Set(EncodeDataKey([]byte{2}, nil), []byte{2}, false))
+	require.NoError(t, kv.Delete(EncodeDataKey([]byte{1}, nil), false))
+	assert.NoError(t, ds.(storage.ManualCompactionStorage).CompactRange([]byte{1}, []byte{2}))
+	assert.NoError(t, ds.(storage.ManualCompactionStorage).CompactRange(nil, nil))
+	v, err := kv.Get(EncodeDataKey([]byte{2}, nil))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{2}, v)
+}
+
+func TestReadSnapshot(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	fs := vfs.GetTestFS()
+	defer vfs.ReportLeakedFD(fs, t)
+	kv := getTestPebbleStorage(t, fs)
+	base := NewBaseStorage(kv, fs)
+	ds := NewKVDataStorage(base, simple.NewSimpleKVExecutor(base))
+	defer func() {
+		require.NoError(t, fs.RemoveAll(testDir))
+	}()
+	defer ds.Close()
+
+	read := func(name string) ([]byte, error) {
+		req := simple.NewReadRequest([]byte("k1"))
+		req.ReadSnapshot = name
+		return ds.Read(storage.NewSimpleReadContext(1, req))
+	}
+	rs := ds.(storage.ReadSnapshotStorage)
+	require.NoError(t, kv.Set(EncodeDataKey([]byte("k1"), nil), []byte("v1"), false))
+	assert.NoError(t, rs.CreateReadSnapshot(1, "s1", 0))
+	assert.Equal(t, storage.ErrReadSnapshotExists, rs.CreateReadSnapshot(1, "s1", 0))
+	assert.NoError(t, rs.CreateReadSnapshot(2, "s1", 0))
+
+	require.NoError(t, kv.Set(EncodeDataKey([]byte("k1"), nil), []byte("v2"), false))
+	v, err := read("s1")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("v1"), v)
+	v, err = read("")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("v2"), v)
+	_, err = read("s2")
+	assert.Equal(t, storage.ErrReadSnapshotNotFound, err)
+
+	assert.NoError(t, rs.ReleaseReadSnapshot(1, "s1"))
+	assert.NoError(t, rs.ReleaseReadSnapshot(1, "s1"))
+	_, err = read("s1")
+	assert.Equal(t, storage.ErrReadSnapshotNotFound, err)
+
+	// the read snapshots are released with the shard
+	assert.NoError(t, ds.RemoveShard(metapb.Shard{ID: 2}, false))
+	ds.(*kvDataStorage).snapshots.Lock()
+	assert.Empty(t, ds.(*kvDataStorage).snapshots.views)
+	ds.(*kvDataStorage).snapshots.Unlock()
+}
+
+func TestReadSnapshotTTL(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	fs := vfs.GetTestFS()
+	defer vfs.ReportLeakedFD(fs, t)
+	kv := getTestPebbleStorage(t, fs)
+	base := NewBaseStorage(kv, fs)
+	ds := NewKVDataStorage(base, simple.NewSimpleKVExecutor(base))
+	defer func() {
+		require.NoError(t, fs.RemoveAll(testDir))
+	}()
+	defer ds.Close()
+
+	read := func(name string) error {
+		req := simple.NewReadRequest([]byte("k1"))
+		req.ReadSnapshot = name
+		_, err := ds.Read(storage.NewSimpleReadContext(1, req))
+		return err
+	}
+	rs := ds.(storage.ReadSnapshotStorage)
+	ttl := time.Millisecond * 200
+	require.NoError(t, rs.CreateReadSnapshot(1, "s1", ttl))
+	// the ttl is extended by the reads
+	for i := 0; i < 3; i++ {
+		time.Sleep(ttl * 3 / 5)
+		assert.NoError(t, read("s1"))
+	}
+	assert.Eventually(t, func() bool {
+		ds.(*kvDataStorage).snapshots.Lock()
+		defer ds.(*kvDataStorage).snapshots.Unlock()
+		return len(ds.(*kvDataStorage).snapshots.views) == 0
+	}, time.Second*5, time.Millisecond*10)
+	assert.Equal(t, storage.ErrReadSnapshotNotFound, read("s1"))
+
+	// the name can be used again once expired
+	assert.NoError(t, rs.CreateReadSnapshot(1, "s1", 0))
+	assert.NoError(t, read("s1"))
+}
+
 func newTestShardMetadata(n uint64) []metapb.ShardMetadata {
 	var values []metapb.ShardMetadata
 	for i := uint64(1); i < n; i++ {
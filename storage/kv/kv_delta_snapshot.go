@@ -0,0 +1,337 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kv
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"sync"
+
+	"github.com/cockroachdb/errors"
+	"github.com/cockroachdb/pebble"
+	"github.com/fagongzi/util/protoc"
+
+	"github.com/matrixorigin/matrixcube/pb/metapb"
+	"github.com/matrixorigin/matrixcube/storage"
+	"github.com/matrixorigin/matrixcube/util"
+	"github.com/matrixorigin/matrixcube/util/fileutil"
+	"github.com/matrixorigin/matrixcube/vfs"
+)
+
+const (
+	deltaSnapshotFileName = "db.delta"
+)
+
+var (
+	deltaSetRecord         = []byte{1}
+	deltaDeleteRecord      = []byte{2}
+	deltaDeleteRangeRecord = []byte{3}
+)
+
+// changedKey is a key set or deleted by a log entry, or a range deleted by the
+// log entry if the end is specified.
+type changedKey struct {
+	key []byte
+	end []byte
+}
+
+type changedEntry struct {
+	index uint64
+	keys  []changedKey
+}
+
+// shardChanges the changes of a shard, all the keys changed by the log entries
+// after the since index are tracked.
+type shardChanges struct {
+	since   uint64
+	entries []changedEntry
+}
+
+// changeTracker tracks the keys changed by the last log entries applied to the
+// shards in memory, the delta snapshots are created with the tracked changes.
+type changeTracker struct {
+	sync.Mutex
+	maxEntries int
+	shards     map[uint64]*shardChanges
+}
+
+func newChangeTracker(maxEntries uint64) *changeTracker {
+	return &changeTracker{
+		maxEntries: int(maxEntries),
+		shards:     make(map[uint64]*shardChanges),
+	}
+}
+
+// track records the keys changed by the log entry of the shard, the oldest log
+// entries are dropped once more than maxEntries log entries tracked.
+func (t *changeTracker) track(shardID uint64, index uint64, keys []changedKey) {
+	t.Lock()
+	defer t.Unlock()
+
+	sc, ok := t.shards[shardID]
+	if !ok || index <= sc.since {
+		sc = &shardChanges{since: index - 1}
+		t.shards[shardID] = sc
+	}
+	sc.entries = append(sc.entries, changedEntry{index: index, keys: keys})
+	if n := len(sc.entries) - t.maxEntries; n > 0 {
+		sc.since = sc.entries[n-1].index
+		sc.entries = append(sc.entries[:0], sc.entries[n:]...)
+	}
+}
+
+// reset drops the tracked changes of the shard, the changes after the since
+// index will be tracked.
+func (t *changeTracker) reset(shardID uint64, since uint64) {
+	t.Lock()
+	defer t.Unlock()
+	t.shards[shardID] = &shardChanges{since: since}
+}
+
+func (t *changeTracker) remove(shardID uint64) {
+	t.Lock()
+	defer t.Unlock()
+	delete(t.shards, shardID)
+}
+
+// changesSince returns the keys changed by the log entries after the base
+// index, false is returned if the changes are not tracked.
+func (t *changeTracker) changesSince(shardID uint64, base uint64) ([]changedKey, bool) {
+	t.Lock()
+	defer t.Unlock()
+
+	sc, ok := t.shards[shardID]
+	if !ok || base < sc.since {
+		return nil, false
+	}
+	var keys []changedKey
+	for _, e := range sc.entries {
+		if e.index > base {
+			keys = append(keys, e.keys...)
+		}
+	}
+	return keys, true
+}
+
+// getChangedKeys returns the data keys changed by the write batch.
+func getChangedKeys(wb util.WriteBatchKeysIterator) []changedKey {
+	var keys []changedKey
+	wb.IterateKeys(func(key, end []byte) {
+		if len(key) == 0 || (end == nil && key[0] != dataPrefix) {
+			return
+		}
+		c := changedKey{key: append([]byte(nil), key...)}
+		if end != nil {
+			c.end = append([]byte(nil), end...)
+		}
+		keys = append(keys, c)
+	})
+	return keys
+}
+
+// deltaSnapshotBase is implemented by the base storages which can create and
+// apply the delta snapshots with the tracked changes.
+type deltaSnapshotBase interface {
+	createDeltaSnapshot(shardID uint64, base uint64, changes []changedKey, path string) error
+	// applyDeltaSnapshot applies the delta snapshot under the path, false is
+	// returned if there is no delta snapshot under the path.
+	applyDeltaSnapshot(shardID uint64, path string, appliedIndex uint64) (bool, error)
+}
+
+var _ deltaSnapshotBase = (*BaseStorage)(nil)
+
+// createDeltaSnapshot creates a delta snapshot file under the giving path. The
+// snapshot file has the same header as the full snapshot followed by the base
+// index, and the records to set or delete the changed keys. The ranges deleted
+// are written first, so the keys set in the ranges are not deleted by the
+// ranges when applied.
+func (s *BaseStorage) createDeltaSnapshot(shardID uint64, base uint64,
+	changes []changedKey, path string) error {
+	view := s.kv.GetView()
+	defer view.Close()
+
+	snap := view.Raw().(*pebble.Snapshot)
+	appliedIndexKey, appliedIndexValue, err := s.getAppliedIndex(snap, shardID)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get applied index in CreateDeltaSnapshot")
+	}
+	metadataKey, metadataValue, err := s.getShardMetadata(snap, shardID)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get shard in CreateDeltaSnapshot")
+	}
+
+	var sls metapb.ShardMetadata
+	var logIndex metapb.LogIndex
+	protoc.MustUnmarshal(&sls, metadataValue)
+	protoc.MustUnmarshal(&logIndex, appliedIndexValue)
+	// the metadata changes, e.g. the range changed by the split, are not tracked
+	if base < sls.LogIndex || base > logIndex.Index {
+		return storage.ErrDeltaSnapshotUnavailable
+	}
+
+	if err := s.fs.MkdirAll(path, 0755); err != nil {
+		return err
+	}
+	f, err := s.fs.Create(s.fs.PathJoin(path, deltaSnapshotFileName))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	shard := sls.Metadata.Shard
+	start := EncodeShardStart(shard.Start, nil)
+	end := EncodeShardEnd(shard.End, nil)
+	baseValue := make([]byte, 8)
+	binary.BigEndian.PutUint64(baseValue, base)
+	for _, v := range [][]byte{start, end, appliedIndexKey, appliedIndexValue,
+		metadataKey, metadataValue, baseValue} {
+		if err := writeBytes(f, v); err != nil {
+			return err
+		}
+	}
+
+	for _, c := range changes {
+		if c.end == nil {
+			continue
+		}
+		from, to := c.key, c.end
+		if bytes.Compare(from, start) < 0 {
+			from = start
+		}
+		if bytes.Compare(to, end) > 0 {
+			to = end
+		}
+		if bytes.Compare(from, to) >= 0 {
+			continue
+		}
+		if err := writeRecord(f, deltaDeleteRangeRecord, from, to); err != nil {
+			return err
+		}
+		iter := snap.NewIter(&pebble.IterOptions{LowerBound: from, UpperBound: to})
+		for iter.First(); iter.Valid(); iter.Next() {
+			if err := writeRecord(f, deltaSetRecord, iter.Key(), iter.Value()); err != nil {
+				iter.Close()
+				return err
+			}
+		}
+		if err := iter.Close(); err != nil {
+			return err
+		}
+	}
+
+	written := make(map[string]struct{})
+	for _, c := range changes {
+		if c.end != nil ||
+			bytes.Compare(c.key, start) < 0 ||
+			bytes.Compare(c.key, end) >= 0 {
+			continue
+		}
+		if _, ok := written[string(c.key)]; ok {
+			continue
+		}
+		written[string(c.key)] = struct{}{}
+
+		v, closer, err := snap.Get(c.key)
+		if errors.Is(err, pebble.ErrNotFound) {
+			err = writeRecord(f, deltaDeleteRecord, c.key)
+		} else if err == nil {
+			err = writeRecord(f, deltaSetRecord, c.key, v)
+			closer.Close()
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *BaseStorage) applyDeltaSnapshot(shardID uint64, path string,
+	appliedIndex uint64) (bool, error) {
+	file := s.fs.PathJoin(path, deltaSnapshotFileName)
+	if exist, err := fileutil.Exist(file, s.fs); err != nil || !exist {
+		return false, err
+	}
+	f, err := s.fs.Open(file)
+	if err != nil {
+		return true, err
+	}
+	defer f.Close()
+
+	header := make([][]byte, 7)
+	for idx := range header {
+		if header[idx], err = readBytes(f); err != nil {
+			return true, err
+		}
+		if len(header[idx]) == 0 {
+			panic("incomplete header in delta snapshot")
+		}
+	}
+	if base := binary.BigEndian.Uint64(header[6]); base > appliedIndex {
+		return true, errors.Wrapf(storage.ErrDeltaSnapshotBaseNotApplied,
+			"base %d, applied %d", base, appliedIndex)
+	}
+
+	batch := s.kv.NewWriteBatch().(util.WriteBatch)
+	defer batch.Close()
+	batch.Set(header[2], header[3])
+	batch.Set(header[4], header[5])
+	for {
+		record, err := readBytes(f)
+		if err != nil {
+			return true, err
+		}
+		if len(record) == 0 {
+			break
+		}
+		key, err := readBytes(f)
+		if err != nil {
+			return true, err
+		}
+		switch {
+		case bytes.Equal(record, deltaSetRecord):
+			value, err := readBytes(f)
+			if err != nil {
+				return true, err
+			}
+			batch.Set(key, value)
+		case bytes.Equal(record, deltaDeleteRecord):
+			batch.Delete(key)
+		case bytes.Equal(record, deltaDeleteRangeRecord):
+			end, err := readBytes(f)
+			if err != nil {
+				return true, err
+			}
+			batch.DeleteRange(key, end)
+		default:
+			panic(fmt.Sprintf("unknown record %+v in delta snapshot", record))
+		}
+	}
+	if err := s.kv.Write(batch, true); err != nil {
+		return true, err
+	}
+	return true, s.kv.Sync()
+}
+
+func writeRecord(f vfs.File, record []byte, values ...[]byte) error {
+	if err := writeBytes(f, record); err != nil {
+		return err
+	}
+	for _, v := range values {
+		if err := writeBytes(f, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
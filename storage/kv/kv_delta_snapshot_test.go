@@ -0,0 +1,181 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kv
+
+import (
+	"testing"
+
+	"github.com/cockroachdb/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/matrixorigin/matrixcube/pb/metapb"
+	"github.com/matrixorigin/matrixcube/storage"
+	"github.com/matrixorigin/matrixcube/storage/kv/mem"
+	"github.com/matrixorigin/matrixcube/util"
+	"github.com/matrixorigin/matrixcube/vfs"
+)
+
+const (
+	testSetCmd uint64 = iota + 1
+	testDeleteCmd
+	testDeleteRangeCmd
+)
+
+// testDeltaExecutor sets, deletes or deletes the range of the keys
+type testDeltaExecutor struct {
+	kv storage.KVStorage
+}
+
+func (e *testDeltaExecutor) UpdateWriteBatch(ctx storage.WriteContext) error {
+	wb := ctx.WriteBatch().(util.WriteBatch)
+	for _, req := range ctx.Batch().Requests {
+		switch req.CmdType {
+		case testSetCmd:
+			wb.Set(req.Key, req.Cmd)
+		case testDeleteCmd:
+			wb.Delete(req.Key)
+		case testDeleteRangeCmd:
+			wb.DeleteRange(req.Key, EncodeDataKey(req.Cmd, nil))
+		}
+		ctx.AppendResponse(nil)
+	}
+	return nil
+}
+
+func (e *testDeltaExecutor) ApplyWriteBatch(r storage.Resetable) error {
+	return e.kv.Write(r.(util.WriteBatch), false)
+}
+
+func (e *testDeltaExecutor) Read(ctx storage.ReadContext) ([]byte, error) {
+	panic("not implemented")
+}
+
+func TestChangeTracker(t *testing.T) {
+	ct := newChangeTracker(2)
+	_, ok := ct.changesSince(1, 0)
+	assert.False(t, ok)
+
+	ct.track(1, 2, []changedKey{{key: []byte("a")}})
+	ct.track(1, 3, []changedKey{{key: []byte("b")}})
+	keys, ok := ct.changesSince(1, 1)
+	assert.True(t, ok)
+	assert.Equal(t, []changedKey{{key: []byte("a")}, {key: []byte("b")}}, keys)
+	keys, ok = ct.changesSince(1, 2)
+	assert.True(t, ok)
+	assert.Equal(t, []changedKey{{key: []byte("b")}}, keys)
+	_, ok = ct.changesSince(1, 0)
+	assert.False(t, ok)
+
+	// the oldest log entry is dropped
+	ct.track(1, 5, []changedKey{{key: []byte("c")}})
+	_, ok = ct.changesSince(1, 1)
+	assert.False(t, ok)
+	keys, ok = ct.changesSince(1, 2)
+	assert.True(t, ok)
+	assert.Equal(t, 2, len(keys))
+
+	ct.reset(1, 10)
+	keys, ok = ct.changesSince(1, 10)
+	assert.True(t, ok)
+	assert.Empty(t, keys)
+	ct.remove(1)
+	_, ok = ct.changesSince(1, 10)
+	assert.False(t, ok)
+}
+
+func TestDeltaSnapshot(t *testing.T) {
+	fs := vfs.GetTestFS()
+	defer vfs.ReportLeakedFD(fs, t)
+	dir := "delta-snapshot-dir-safe-to-delete"
+	shardID := uint64(1)
+	require.NoError(t, fs.RemoveAll(dir))
+	defer func() {
+		require.NoError(t, fs.RemoveAll(dir))
+	}()
+
+	newDataStorage := func(opts ...Option) storage.DataStorage {
+		kv := mem.NewStorage()
+		base := NewBaseStorage(kv, fs)
+		ds := NewKVDataStorage(base, &testDeltaExecutor{kv: kv}, opts...)
+		_, err := ds.GetInitialStates()
+		require.NoError(t, err)
+		require.NoError(t, ds.SaveShardMetadata([]metapb.ShardMetadata{{
+			ShardID:  shardID,
+			LogIndex: 1,
+			Metadata: metapb.ShardLocalState{Shard: metapb.Shard{ID: shardID,
+				Start: []byte("a"), End: []byte("x")}},
+		}}))
+		return ds
+	}
+	write := func(ds storage.DataStorage, index uint64, requests ...storage.Request) {
+		batch := storage.Batch{Index: index, Requests: requests}
+		ctx := storage.NewSimpleWriteContext(shardID, ds.(*kvDataStorage).base, batch)
+		require.NoError(t, ds.Write(ctx))
+	}
+	set := func(k, v string) storage.Request {
+		return storage.Request{CmdType: testSetCmd, Key: []byte(k), Cmd: []byte(v)}
+	}
+	scan := func(ds storage.DataStorage) map[string]string {
+		values := make(map[string]string)
+		assert.NoError(t, ds.(*kvDataStorage).base.Scan(EncodeShardStart(nil, nil),
+			EncodeShardEnd(nil, nil), func(key, value []byte) (bool, error) {
+				values[string(DecodeDataKey(key))] = string(value)
+				return true, nil
+			}, true))
+		return values
+	}
+
+	leader := newDataStorage(WithDeltaSnapshot(10))
+	defer leader.Close()
+	follower := newDataStorage(WithDeltaSnapshot(10))
+	defer follower.Close()
+	for _, ds := range []storage.DataStorage{leader, follower} {
+		write(ds, 2, set("b", "v1"), set("c", "v1"), set("d1", "v1"),
+			set("d2", "v1"), set("e", "v1"))
+	}
+	write(leader, 3, set("b", "v2"),
+		storage.Request{CmdType: testDeleteCmd, Key: []byte("c")})
+	write(leader, 4, storage.Request{CmdType: testDeleteRangeCmd,
+		Key: []byte("d"), Cmd: []byte("e")}, set("d2", "v2"), set("f", "v2"))
+
+	dds := leader.(storage.DeltaSnapshotStorage)
+	assert.True(t, errors.Is(dds.CreateDeltaSnapshot(shardID, 0, dir),
+		storage.ErrDeltaSnapshotUnavailable))
+	require.NoError(t, dds.CreateDeltaSnapshot(shardID, 2, dir))
+	require.NoError(t, follower.ApplySnapshot(shardID, dir))
+	assert.Equal(t, map[string]string{"b": "v2", "d2": "v2", "e": "v1", "f": "v2"},
+		scan(follower))
+	assert.Equal(t, scan(leader), scan(follower))
+	assert.Equal(t, uint64(4), follower.(*kvDataStorage).getAppliedIndex(shardID))
+
+	// the changes are tracked since the applied snapshot
+	_, ok := follower.(*kvDataStorage).changes.changesSince(shardID, 4)
+	assert.True(t, ok)
+	_, ok = follower.(*kvDataStorage).changes.changesSince(shardID, 3)
+	assert.False(t, ok)
+
+	// the base index is not applied
+	require.NoError(t, fs.RemoveAll(dir))
+	other := newDataStorage()
+	defer other.Close()
+	write(other, 2, set("b", "v1"))
+	require.NoError(t, dds.CreateDeltaSnapshot(shardID, 3, dir))
+	assert.True(t, errors.Is(other.ApplySnapshot(shardID, dir),
+		storage.ErrDeltaSnapshotBaseNotApplied))
+
+	// the changes are not tracked
+	assert.True(t, errors.Is(other.(storage.DeltaSnapshotStorage).CreateDeltaSnapshot(shardID, 1, dir),
+		storage.ErrDeltaSnapshotUnavailable))
+}
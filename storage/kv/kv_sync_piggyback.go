@@ -0,0 +1,93 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kv
+
+import (
+	"sync/atomic"
+
+	"github.com/matrixorigin/matrixcube/storage"
+)
+
+var _ storage.SyncPiggybackStorage = (*kvDataStorage)(nil)
+
+// unsyncedWrite is a write not persisted by the fsync of the shared WAL yet.
+type unsyncedWrite struct {
+	seq          uint64
+	shardID      uint64
+	appliedIndex uint64
+}
+
+// SharesWAL returns true if the data storage is created on the kv storage.
+func (kv *kvDataStorage) SharesWAL(s storage.KVStorage) bool {
+	if base, ok := kv.base.(*BaseStorage); ok {
+		return base.kv == s
+	}
+	return false
+}
+
+func (kv *kvDataStorage) EnableSyncPiggyback() {
+	atomic.StoreUint32(&kv.piggyback, 1)
+}
+
+func (kv *kvDataStorage) PrepareSync() uint64 {
+	kv.mu.RLock()
+	defer kv.mu.RUnlock()
+	return kv.mu.writeSeq
+}
+
+func (kv *kvDataStorage) Synced(seq uint64) {
+	kv.mu.Lock()
+	defer kv.mu.Unlock()
+	n := 0
+	for _, w := range kv.mu.unsynced {
+		if w.seq > seq {
+			break
+		}
+		kv.mu.persistentAppliedIndexes[w.shardID] = w.appliedIndex
+		n++
+	}
+	kv.mu.unsynced = append(kv.mu.unsynced[:0], kv.mu.unsynced[n:]...)
+}
+
+func (kv *kvDataStorage) syncPiggybacked() bool {
+	return atomic.LoadUint32(&kv.piggyback) == 1
+}
+
+// trackUnsynced tracks the applied index of the write completed, which is
+// marked persistent by the next fsync of the shared WAL.
+func (kv *kvDataStorage) trackUnsynced(shardID uint64, appliedIndex uint64) {
+	if !kv.syncPiggybacked() {
+		return
+	}
+
+	kv.mu.Lock()
+	defer kv.mu.Unlock()
+	kv.mu.writeSeq++
+	kv.mu.unsynced = append(kv.mu.unsynced, unsyncedWrite{
+		seq:          kv.mu.writeSeq,
+		shardID:      shardID,
+		appliedIndex: appliedIndex,
+	})
+}
+
+// removeUnsyncedLocked removes the unsynced writes of the removed shard.
+func (kv *kvDataStorage) removeUnsyncedLocked(shardID uint64) {
+	unsynced := kv.mu.unsynced[:0]
+	for _, w := range kv.mu.unsynced {
+		if w.shardID != shardID {
+			unsynced = append(unsynced, w)
+		}
+	}
+	kv.mu.unsynced = unsynced
+}
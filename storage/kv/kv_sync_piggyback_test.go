@@ -0,0 +1,121 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kv
+
+import (
+	"fmt"
+	"testing"
+
+	cpebble "github.com/cockroachdb/pebble"
+	pvfs "github.com/lni/vfs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/matrixorigin/matrixcube/pb/metapb"
+	"github.com/matrixorigin/matrixcube/storage"
+	"github.com/matrixorigin/matrixcube/storage/executor/simple"
+	"github.com/matrixorigin/matrixcube/storage/kv/mem"
+	"github.com/matrixorigin/matrixcube/storage/kv/pebble"
+	"github.com/matrixorigin/matrixcube/util/leaktest"
+	"github.com/matrixorigin/matrixcube/vfs"
+)
+
+func TestSyncPiggyback(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	memfs := vfs.NewMemFS()
+	defer vfs.ReportLeakedFD(memfs, t)
+	opts := &cpebble.Options{
+		FS: vfs.NewPebbleFS(memfs),
+	}
+	require.NoError(t, memfs.MkdirAll("/test-data", 0755))
+	dir, err := memfs.OpenDir("/")
+	assert.NoError(t, err)
+	require.NoError(t, dir.Sync())
+	shardID := uint64(1)
+	func() {
+		kv, err := pebble.NewStorage("test-data", nil, opts)
+		assert.NoError(t, err)
+		base := NewBaseStorage(kv, memfs)
+		s := NewKVDataStorage(base, simple.NewSimpleKVExecutor(base), WithSampleSync(1))
+		defer func() {
+			// to emulate a crash
+			memfs.(*pvfs.MemFS).SetIgnoreSyncs(true)
+			s.Close()
+		}()
+		_, err = s.GetInitialStates()
+		assert.NoError(t, err)
+
+		other := mem.NewStorage()
+		defer other.Close()
+		ps := s.(storage.SyncPiggybackStorage)
+		assert.True(t, ps.SharesWAL(kv))
+		assert.False(t, ps.SharesWAL(other))
+		ps.EnableSyncPiggyback()
+
+		write := func(index uint64) {
+			var batch storage.Batch
+			batch.Index = index
+			k := []byte(fmt.Sprintf("%d", index))
+			batch.Requests = append(batch.Requests, simple.NewWriteRequest(k, k))
+			ctx := storage.NewSimpleWriteContext(shardID, base, batch)
+			assert.NoError(t, s.Write(ctx))
+		}
+		persistent := func() uint64 {
+			v, err := s.GetPersistentLogIndex(shardID)
+			assert.NoError(t, err)
+			return v
+		}
+
+		// the sampled fsync is skipped
+		assert.NoError(t, s.SaveShardMetadata([]metapb.ShardMetadata{{
+			ShardID:  shardID,
+			LogIndex: 1,
+			Metadata: metapb.ShardLocalState{Shard: metapb.Shard{ID: shardID}},
+		}}))
+		write(2)
+		write(3)
+		assert.Equal(t, uint64(0), persistent())
+
+		// the writes completed before PrepareSync are marked persistent
+		seq := ps.PrepareSync()
+		write(4)
+		assert.NoError(t, kv.Set([]byte("raft-log"), []byte("raft-log"), true))
+		ps.Synced(seq)
+		assert.Equal(t, uint64(3), persistent())
+
+		seq = ps.PrepareSync()
+		ps.Synced(seq)
+		assert.Equal(t, uint64(4), persistent())
+
+		// not persisted by any fsync
+		write(5)
+		assert.Equal(t, uint64(4), persistent())
+	}()
+
+	memfs.(*pvfs.MemFS).ResetToSyncedState()
+	memfs.(*pvfs.MemFS).SetIgnoreSyncs(false)
+	kv, err := pebble.NewStorage("test-data", nil, opts)
+	assert.NoError(t, err)
+	base := NewBaseStorage(kv, memfs)
+	s := NewKVDataStorage(base, simple.NewSimpleKVExecutor(base))
+	defer s.Close()
+	_, err = s.GetInitialStates()
+	assert.NoError(t, err)
+	index, err := s.GetPersistentLogIndex(shardID)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(4), index)
+	v, err := kv.Get([]byte("raft-log"))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("raft-log"), v)
+}
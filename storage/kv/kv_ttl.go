@@ -0,0 +1,354 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kv
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"time"
+
+	"github.com/matrixorigin/matrixcube/storage"
+	"github.com/matrixorigin/matrixcube/storage/stats"
+	"github.com/matrixorigin/matrixcube/util"
+)
+
+var (
+	// expireAtLen the length of the expiration time encoded before the values of
+	// the data keys.
+	expireAtLen = 8
+	// maxPurgeBatchKeys the max number of the filtered keys removed in a write
+	// batch.
+	maxPurgeBatchKeys = 1024
+	// maxTTLFormatBatchKeys the max number of the keys written before the TTL is
+	// enabled rewritten in a write batch.
+	maxTTLFormatBatchKeys = 1024
+	// ttlFormatKey the metadata key recording the values of the data keys are
+	// encoded with the expiration time. The value is ttlFormatDone once all the
+	// data written before the TTL is enabled is rewritten, otherwise it's the key
+	// the rewriting continues from.
+	ttlFormatKey  = EncodeShardMetadataKey([]byte("ttl-format"), nil)
+	ttlFormatDone = []byte{metaPrefix}
+
+	errTTLCanNotBeDisabled = errors.New("TTL can not be disabled on the data written with TTL")
+)
+
+// encodeTTLValue encodes the expiration time in unix nanoseconds before the
+// value, 0 means never expire.
+func encodeTTLValue(value []byte, expireAt int64) []byte {
+	v := make([]byte, expireAtLen+len(value))
+	binary.BigEndian.PutUint64(v, uint64(expireAt))
+	copy(v[expireAtLen:], value)
+	return v
+}
+
+// decodeTTLValue returns the expiration time and the origin value. Note that no
+// data copy is generated here, only a slice of the value is returned.
+func decodeTTLValue(value []byte) (int64, []byte) {
+	if len(value) < expireAtLen {
+		return 0, value
+	}
+	return int64(binary.BigEndian.Uint64(value)), value[expireAtLen:]
+}
+
+func isExpired(expireAt int64, now time.Time) bool {
+	return expireAt > 0 && expireAt <= now.UnixNano()
+}
+
+func isDataKey(key []byte) bool {
+	return len(key) > 0 && key[0] == dataPrefix
+}
+
+// ttlWriteBatch encodes the expiration time into the values of the data keys
+// set to the wrapped write batch.
+type ttlWriteBatch struct {
+	util.WriteBatch
+	expireAt int64
+}
+
+func (wb *ttlWriteBatch) Set(key []byte, value []byte) {
+	if isDataKey(key) {
+		value = encodeTTLValue(value, wb.expireAt)
+	}
+	wb.WriteBatch.Set(key, value)
+}
+
+// ttlWriteContext provides the executor with the write batch encoding the
+// expiration time of the data written.
+type ttlWriteContext struct {
+	storage.WriteContext
+	wb *ttlWriteBatch
+}
+
+func newTTLWriteContext(ctx storage.WriteContext, expireAt int64) storage.WriteContext {
+	return &ttlWriteContext{
+		WriteContext: ctx,
+		wb: &ttlWriteBatch{
+			WriteBatch: ctx.WriteBatch().(util.WriteBatch),
+			expireAt:   expireAt,
+		},
+	}
+}
+
+func (ctx *ttlWriteContext) WriteBatch() storage.Resetable {
+	return ctx.wb
+}
+
+// ttlKVStorage is the KVStorage used by the executor of a data storage with
+// TTL. The values of the data keys are decoded and the expired keys are
+// skipped. The values set directly or by the write batches it creates expire
+// after the ttl from the local time, the executor should write through the
+// WriteBatch of the WriteContext so all the replicas expire the data at the
+// same time.
+type ttlKVStorage struct {
+	kv  storage.KVStorage
+	ttl time.Duration
+	now func() time.Time
+}
+
+var _ storage.KVStorage = (*ttlKVStorage)(nil)
+
+// NewTTLKVStorage returns the KVStorage to be used by the executor of the data
+// storage created with the `WithTTL` option of the same ttl.
+func NewTTLKVStorage(kv storage.KVStorage, ttl time.Duration) storage.KVStorage {
+	return &ttlKVStorage{kv: kv, ttl: ttl, now: time.Now}
+}
+
+func (s *ttlKVStorage) Close() error {
+	return s.kv.Close()
+}
+
+func (s *ttlKVStorage) Stats() stats.Stats {
+	return s.kv.Stats()
+}
+
+func (s *ttlKVStorage) NewWriteBatch() storage.Resetable {
+	return &ttlWriteBatch{
+		WriteBatch: s.kv.NewWriteBatch().(util.WriteBatch),
+		expireAt:   s.expireAt(),
+	}
+}
+
+func (s *ttlKVStorage) GetView() storage.View {
+	return s.kv.GetView()
+}
+
+func (s *ttlKVStorage) Write(wb util.WriteBatch, sync bool) error {
+	if v, ok := wb.(*ttlWriteBatch); ok {
+		wb = v.WriteBatch
+	}
+	return s.kv.Write(wb, sync)
+}
+
+func (s *ttlKVStorage) Set(key []byte, value []byte, sync bool) error {
+	if isDataKey(key) {
+		value = encodeTTLValue(value, s.expireAt())
+	}
+	return s.kv.Set(key, value, sync)
+}
+
+func (s *ttlKVStorage) expireAt() int64 {
+	return s.now().Add(s.ttl).UnixNano()
+}
+
+func (s *ttlKVStorage) Get(key []byte) ([]byte, error) {
+	v, err := s.kv.Get(key)
+	if err != nil || len(v) == 0 || !isDataKey(key) {
+		return v, err
+	}
+	expireAt, value := decodeTTLValue(v)
+	if isExpired(expireAt, s.now()) {
+		return nil, nil
+	}
+	return value, nil
+}
+
+func (s *ttlKVStorage) Delete(key []byte, sync bool) error {
+	return s.kv.Delete(key, sync)
+}
+
+func (s *ttlKVStorage) Scan(start, end []byte,
+	handler func(key, value []byte) (bool, error), copy bool) error {
+	return s.kv.Scan(start, end, s.filter(handler), copy)
+}
+
+func (s *ttlKVStorage) ScanInView(view storage.View, start, end []byte,
+	handler func(key, value []byte) (bool, error), copy bool) error {
+	return s.kv.ScanInView(view, start, end, s.filter(handler), copy)
+}
+
+func (s *ttlKVStorage) PrefixScan(prefix []byte,
+	handler func(key, value []byte) (bool, error), copy bool) error {
+	return s.kv.PrefixScan(prefix, s.filter(handler), copy)
+}
+
+func (s *ttlKVStorage) RangeDelete(start, end []byte, sync bool) error {
+	return s.kv.RangeDelete(start, end, sync)
+}
+
+func (s *ttlKVStorage) Seek(key []byte) ([]byte, []byte, error) {
+	now := s.now()
+	for {
+		k, v, err := s.kv.Seek(key)
+		if err != nil || len(k) == 0 || !isDataKey(k) {
+			return k, v, err
+		}
+		expireAt, value := decodeTTLValue(v)
+		if !isExpired(expireAt, now) {
+			return k, value, nil
+		}
+		key = NextKey(k, nil)
+	}
+}
+
+func (s *ttlKVStorage) Sync() error {
+	return s.kv.Sync()
+}
+
+// filter returns the scan handler skipping the expired keys and decoding the
+// values of the data keys.
+func (s *ttlKVStorage) filter(handler func(key, value []byte) (bool, error)) func(key, value []byte) (bool, error) {
+	now := s.now()
+	return func(key, value []byte) (bool, error) {
+		if !isDataKey(key) {
+			return handler(key, value)
+		}
+		expireAt, v := decodeTTLValue(value)
+		if isExpired(expireAt, now) {
+			return true, nil
+		}
+		return handler(key, v)
+	}
+}
+
+// PurgeExpiredData removes the keys of the shard of the WriteContext expired at
+// the Timestamp of the batch, at most limit keys from the start key are scanned.
+// It's applied by all the replicas at the same log index, so the same keys are
+// removed and the removal is tracked for the delta snapshots. It returns the key
+// the next purge continues from, nil if the end of the shard is reached.
+func (kv *kvDataStorage) PurgeExpiredData(ctx storage.WriteContext,
+	start []byte, limit int) ([]byte, error) {
+	batch := ctx.Batch()
+	if batch.Index == 0 {
+		panic("empty batch?")
+	}
+
+	shard := ctx.Shard()
+	r := ctx.WriteBatch()
+	defer r.Reset()
+	var next []byte
+	if kv.opts.ttl > 0 {
+		if bytes.Compare(start, shard.Start) < 0 {
+			start = shard.Start
+		}
+		now := kv.batchTime(batch)
+		wb := r.(util.WriteBatch)
+		n := 0
+		if err := kv.base.Scan(EncodeShardStart(start, nil), EncodeShardEnd(shard.End, nil),
+			func(key, value []byte) (bool, error) {
+				if n >= limit {
+					next = DecodeDataKey(key)
+					return false, nil
+				}
+				n++
+				if expireAt, _ := decodeTTLValue(value); isExpired(expireAt, now) {
+					wb.Delete(key)
+				}
+				return true, nil
+			}, true); err != nil {
+			return nil, err
+		}
+	}
+
+	kv.trackChanges(shard.ID, batch.Index, r)
+	kv.setAppliedIndexToWriteBatch(ctx, batch.Index)
+	kv.updateAppliedIndex(shard.ID, batch.Index)
+	kv.purgeMu.Lock()
+	err := kv.base.Write(r.(util.WriteBatch), false)
+	kv.purgeMu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	kv.trackUnsynced(shard.ID, batch.Index)
+	return next, kv.trySync()
+}
+
+// batchTime returns the current time of the write batch assigned by the leader,
+// the local time is used if the batch has no timestamp.
+func (kv *kvDataStorage) batchTime(batch storage.Batch) time.Time {
+	if batch.Timestamp == 0 {
+		return kv.opts.now()
+	}
+	return time.Unix(0, batch.Timestamp)
+}
+
+// upgradeTTLFormat encodes the expiration time into the values of the data
+// written before the TTL is enabled, the data never expires. The progress is
+// saved with each batch of the rewritten keys, so the rewriting continues after
+// restart without encoding a value twice.
+func (kv *kvDataStorage) upgradeTTLFormat() error {
+	v, err := kv.base.Get(ttlFormatKey)
+	if err != nil {
+		return err
+	}
+	if kv.opts.ttl == 0 {
+		if len(v) > 0 {
+			return errTTLCanNotBeDisabled
+		}
+		return nil
+	}
+	if bytes.Equal(v, ttlFormatDone) {
+		return nil
+	}
+
+	start := minStartKey
+	if len(v) > 0 {
+		start = v
+	}
+	for {
+		next, err := kv.upgradeTTLFormatBatch(start)
+		if err != nil {
+			return err
+		}
+		if len(next) == 0 {
+			return nil
+		}
+		start = next
+	}
+}
+
+func (kv *kvDataStorage) upgradeTTLFormatBatch(start []byte) ([]byte, error) {
+	wb := kv.base.NewWriteBatch().(util.WriteBatch)
+	defer wb.Close()
+
+	var next []byte
+	n := 0
+	if err := kv.base.Scan(start, maxEndKey, func(key, value []byte) (bool, error) {
+		if n >= maxTTLFormatBatchKeys {
+			next = key
+			return false, nil
+		}
+		n++
+		wb.Set(key, encodeTTLValue(value, 0))
+		return true, nil
+	}, true); err != nil {
+		return nil, err
+	}
+	if len(next) == 0 {
+		wb.Set(ttlFormatKey, ttlFormatDone)
+	} else {
+		wb.Set(ttlFormatKey, next)
+	}
+	return next, kv.base.Write(wb, true)
+}
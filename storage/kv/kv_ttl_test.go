@@ -0,0 +1,183 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kv
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/matrixorigin/matrixcube/storage"
+	"github.com/matrixorigin/matrixcube/storage/executor/simple"
+	"github.com/matrixorigin/matrixcube/util/leaktest"
+	"github.com/matrixorigin/matrixcube/vfs"
+)
+
+func TestEncodeAndDecodeTTLValue(t *testing.T) {
+	expireAt, v := decodeTTLValue(encodeTTLValue([]byte("v1"), 100))
+	assert.Equal(t, int64(100), expireAt)
+	assert.Equal(t, []byte("v1"), v)
+
+	now := time.Unix(0, 100)
+	assert.False(t, isExpired(0, now))
+	assert.False(t, isExpired(101, now))
+	assert.True(t, isExpired(100, now))
+}
+
+func TestTTL(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	fs := vfs.GetTestFS()
+	defer vfs.ReportLeakedFD(fs, t)
+	kv := getTestPebbleStorage(t, fs)
+	base := NewBaseStorage(kv, fs)
+	executorKV := NewTTLKVStorage(base, time.Second)
+	ds := NewKVDataStorage(base, simple.NewSimpleKVExecutor(executorKV), WithTTL(time.Second))
+	defer func() {
+		require.NoError(t, fs.RemoveAll(testDir))
+	}()
+	defer ds.Close()
+	assert.Equal(t, time.Minute*10, ds.Feature().ExpiredDataPurgeDuration)
+
+	// the local time of the replica is ignored, the timestamp of the batch is
+	// used
+	ds.(*kvDataStorage).opts.now = func() time.Time { return time.Unix(0, 0) }
+	now := time.Now()
+	executorKV.(*ttlKVStorage).now = func() time.Time { return now }
+	write := func(index uint64, key string) {
+		ctx := storage.NewSimpleWriteContext(1, base, storage.Batch{
+			Index:     index,
+			Timestamp: now.UnixNano(),
+			Requests:  []storage.Request{simple.NewWriteRequest([]byte(key), []byte(key))},
+		})
+		require.NoError(t, ds.Write(ctx))
+	}
+	read := func(key string) []byte {
+		v, err := ds.Read(storage.NewSimpleReadContext(1, simple.NewReadRequest([]byte(key))))
+		require.NoError(t, err)
+		return v
+	}
+	purge := func(index uint64, start []byte, limit int) []byte {
+		ctx := storage.NewSimpleWriteContext(1, base, storage.Batch{
+			Index:     index,
+			Timestamp: now.UnixNano(),
+		})
+		next, err := ds.(storage.TTLDataStorage).PurgeExpiredData(ctx, start, limit)
+		require.NoError(t, err)
+		return next
+	}
+
+	write(1, "k1")
+	write(2, "k2")
+	assert.Equal(t, []byte("k1"), read("k1"))
+	k, v, err := executorKV.Seek(EncodeDataKey([]byte("k1"), nil))
+	assert.NoError(t, err)
+	assert.Equal(t, EncodeDataKey([]byte("k1"), nil), k)
+	assert.Equal(t, []byte("k1"), v)
+
+	// k1 and k2 expired, k2 is rewritten
+	now = now.Add(time.Second)
+	write(3, "k2")
+	assert.Empty(t, read("k1"))
+	assert.Equal(t, []byte("k2"), read("k2"))
+	k, _, err = executorKV.Seek(EncodeDataKey([]byte("k1"), nil))
+	assert.NoError(t, err)
+	assert.Equal(t, EncodeDataKey([]byte("k2"), nil), k)
+
+	// the keys are purged in batches
+	assert.Equal(t, []byte("k2"), purge(4, nil, 1))
+	assert.Equal(t, uint64(4), ds.(*kvDataStorage).getAppliedIndex(1))
+	assert.Empty(t, purge(5, []byte("k2"), 1))
+	v, err = base.Get(EncodeDataKey([]byte("k1"), nil))
+	assert.NoError(t, err)
+	assert.Empty(t, v)
+	v, err = base.Get(EncodeDataKey([]byte("k2"), nil))
+	assert.NoError(t, err)
+	assert.NotEmpty(t, v)
+
+	// the values set directly expire after the ttl
+	require.NoError(t, executorKV.Set(EncodeDataKey([]byte("k3"), nil), []byte("k3"), false))
+	v, err = executorKV.Get(EncodeDataKey([]byte("k3"), nil))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("k3"), v)
+	now = now.Add(time.Second)
+	v, err = executorKV.Get(EncodeDataKey([]byte("k3"), nil))
+	assert.NoError(t, err)
+	assert.Empty(t, v)
+}
+
+func TestTTLUpgradesDataWrittenWithoutTTL(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	fs := vfs.GetTestFS()
+	defer vfs.ReportLeakedFD(fs, t)
+	kv := getTestPebbleStorage(t, fs)
+	base := NewBaseStorage(kv, fs)
+	defer func() {
+		require.NoError(t, fs.RemoveAll(testDir))
+	}()
+	defer base.Close()
+
+	old := maxTTLFormatBatchKeys
+	maxTTLFormatBatchKeys = 1
+	defer func() {
+		maxTTLFormatBatchKeys = old
+	}()
+	// the values longer than the encoded expiration time
+	value := []byte("value-written-without-ttl")
+	require.NoError(t, kv.Set(EncodeDataKey([]byte("k1"), nil), value, false))
+	require.NoError(t, kv.Set(EncodeDataKey([]byte("k2"), nil), value, false))
+	// the rewriting is interrupted after k1
+	next, err := (&kvDataStorage{base: base}).upgradeTTLFormatBatch(minStartKey)
+	require.NoError(t, err)
+	assert.Equal(t, EncodeDataKey([]byte("k2"), nil), next)
+
+	executorKV := NewTTLKVStorage(base, time.Second)
+	NewKVDataStorage(base, simple.NewSimpleKVExecutor(executorKV), WithTTL(time.Second))
+	for _, key := range []string{"k1", "k2"} {
+		v, err := executorKV.Get(EncodeDataKey([]byte(key), nil))
+		assert.NoError(t, err)
+		assert.Equal(t, value, v)
+	}
+	v, err := kv.Get(ttlFormatKey)
+	assert.NoError(t, err)
+	assert.Equal(t, ttlFormatDone, v)
+
+	assert.Panics(t, func() {
+		NewKVDataStorage(base, nil)
+	})
+}
+
+func TestPurgeExpiredDataWithoutTTL(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	fs := vfs.GetTestFS()
+	defer vfs.ReportLeakedFD(fs, t)
+	kv := getTestPebbleStorage(t, fs)
+	base := NewBaseStorage(kv, fs)
+	ds := NewKVDataStorage(base, nil)
+	defer func() {
+		require.NoError(t, fs.RemoveAll(testDir))
+	}()
+	defer ds.Close()
+	assert.Equal(t, time.Duration(0), ds.Feature().ExpiredDataPurgeDuration)
+
+	require.NoError(t, kv.Set(EncodeDataKey([]byte("k1"), nil), encodeTTLValue([]byte("k1"), 1), false))
+	ctx := storage.NewSimpleWriteContext(1, base, storage.Batch{Index: 1, Timestamp: 2})
+	next, err := ds.(storage.TTLDataStorage).PurgeExpiredData(ctx, nil, 1)
+	assert.NoError(t, err)
+	assert.Empty(t, next)
+	v, err := kv.Get(EncodeDataKey([]byte("k1"), nil))
+	assert.NoError(t, err)
+	assert.NotEmpty(t, v)
+}
@@ -0,0 +1,70 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pebble
+
+import (
+	"sync/atomic"
+
+	cpebble "github.com/cockroachdb/pebble"
+)
+
+// compactionObserver reports the keys written by the flushes and the compactions
+// to the observer set by SetCompactionObserver. The pebble has no compaction
+// filter, the table property collectors are the hook called with each key added
+// to the sstables.
+type compactionObserver struct {
+	fn atomic.Value
+}
+
+// wrap adds the table property collector reporting the keys to the options.
+func (o *compactionObserver) wrap(opts *cpebble.Options) {
+	opts.TablePropertyCollectors = append(opts.TablePropertyCollectors,
+		func() cpebble.TablePropertyCollector {
+			return &observerCollector{o: o}
+		})
+}
+
+func (o *compactionObserver) set(fn func(key, value []byte)) {
+	o.fn.Store(fn)
+}
+
+func (o *compactionObserver) get() func(key, value []byte) {
+	if fn, ok := o.fn.Load().(func(key, value []byte)); ok {
+		return fn
+	}
+	return nil
+}
+
+// observerCollector is created for each sstable written.
+type observerCollector struct {
+	o *compactionObserver
+}
+
+func (c *observerCollector) Add(key cpebble.InternalKey, value []byte) error {
+	if key.Kind() != cpebble.InternalKeyKindSet {
+		return nil
+	}
+	if fn := c.o.get(); fn != nil {
+		fn(key.UserKey, value)
+	}
+	return nil
+}
+
+func (c *observerCollector) Finish(userProps map[string]string) error {
+	return nil
+}
+
+func (c *observerCollector) Name() string {
+	return "matrixcube.compaction-observer"
+}
@@ -0,0 +1,47 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pebble
+
+import (
+	"sync"
+	"testing"
+
+	cpebble "github.com/cockroachdb/pebble"
+	"github.com/cockroachdb/pebble/vfs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompactionObserver(t *testing.T) {
+	s, err := NewStorage("test-data", nil, &cpebble.Options{FS: vfs.NewMem()})
+	require.NoError(t, err)
+	defer s.Close()
+
+	var mu sync.Mutex
+	observed := make(map[string]string)
+	assert.NoError(t, s.SetCompactionObserver(func(key, value []byte) {
+		mu.Lock()
+		defer mu.Unlock()
+		observed[string(key)] = string(value)
+	}))
+
+	assert.NoError(t, s.Set([]byte("k1"), []byte("v1"), false))
+	assert.NoError(t, s.Set([]byte("k2"), []byte("v2"), false))
+	assert.NoError(t, s.Delete([]byte("k2"), false))
+	assert.NoError(t, s.CompactRange([]byte("k"), []byte("l")))
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, map[string]string{"k1": "v1"}, observed)
+}
@@ -15,8 +15,10 @@ package pebble
 
 import (
 	"reflect"
+	"sync/atomic"
 
 	cpebble "github.com/cockroachdb/pebble"
+	"github.com/matrixorigin/matrixcube/storage/stats"
 	"go.uber.org/zap"
 )
 
@@ -49,3 +51,40 @@ func getEventListener(logger *zap.Logger) cpebble.EventListener {
 		},
 	}
 }
+
+// writeStall tracks the write stalls of the pebble by the events
+type writeStall struct {
+	stalled int32
+	stalls  uint64
+}
+
+// wrap returns the event listener tracking the write stalls, the write stall
+// events are passed to the listener l.
+func (ws *writeStall) wrap(l cpebble.EventListener) cpebble.EventListener {
+	begin, end := l.WriteStallBegin, l.WriteStallEnd
+	l.WriteStallBegin = func(info cpebble.WriteStallBeginInfo) {
+		atomic.StoreInt32(&ws.stalled, 1)
+		atomic.AddUint64(&ws.stalls, 1)
+		if begin != nil {
+			begin(info)
+		}
+	}
+	l.WriteStallEnd = func() {
+		atomic.StoreInt32(&ws.stalled, 0)
+		if end != nil {
+			end()
+		}
+	}
+	return l
+}
+
+// WriteStallStats returns the write stall state and the L0 of the pebble
+func (s *Storage) WriteStallStats() stats.WriteStallStats {
+	m := s.db.Metrics()
+	return stats.WriteStallStats{
+		Stalled:     atomic.LoadInt32(&s.stall.stalled) == 1,
+		Stalls:      atomic.LoadUint64(&s.stall.stalls),
+		L0Files:     m.Levels[0].NumFiles,
+		L0Sublevels: m.Levels[0].Sublevels,
+	}
+}
@@ -4,7 +4,9 @@ import (
 	"testing"
 
 	cpebble "github.com/cockroachdb/pebble"
+	"github.com/cockroachdb/pebble/vfs"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestHasEventListener(t *testing.T) {
@@ -25,3 +27,34 @@ func TestHasEventListener(t *testing.T) {
 	assert.True(t, hasEventListener(cpebble.EventListener{WriteStallBegin: func(dsi cpebble.WriteStallBeginInfo) {}}))
 	assert.True(t, hasEventListener(cpebble.EventListener{WriteStallEnd: func() {}}))
 }
+
+func TestWriteStallStats(t *testing.T) {
+	stalled := false
+	opts := &cpebble.Options{
+		FS: vfs.NewMem(),
+		EventListener: cpebble.EventListener{
+			WriteStallBegin: func(cpebble.WriteStallBeginInfo) { stalled = true },
+			WriteStallEnd:   func() { stalled = false },
+		},
+	}
+	s, err := NewStorage("test-data", nil, opts)
+	require.NoError(t, err)
+	defer s.Close()
+
+	assert.NoError(t, s.Set([]byte("k1"), []byte("v1"), false))
+	assert.NoError(t, s.db.Flush())
+	st := s.WriteStallStats()
+	assert.False(t, st.Stalled)
+	assert.Equal(t, uint64(0), st.Stalls)
+	assert.Equal(t, int64(1), st.L0Files)
+
+	opts.EventListener.WriteStallBegin(cpebble.WriteStallBeginInfo{Reason: "test"})
+	assert.True(t, stalled)
+	st = s.WriteStallStats()
+	assert.True(t, st.Stalled)
+	assert.Equal(t, uint64(1), st.Stalls)
+
+	opts.EventListener.WriteStallEnd()
+	assert.False(t, stalled)
+	assert.False(t, s.WriteStallStats().Stalled)
+}
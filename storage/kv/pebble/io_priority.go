@@ -0,0 +1,193 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pebble
+
+import (
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	cpebble "github.com/cockroachdb/pebble"
+	"github.com/cockroachdb/pebble/vfs"
+	"github.com/juju/ratelimit"
+	"github.com/matrixorigin/matrixcube/config"
+	"github.com/matrixorigin/matrixcube/metric"
+)
+
+const (
+	defaultApplyBusyWindow = time.Second
+)
+
+// IOClass is the class of the disk writes of the pebble storage
+type IOClass string
+
+const (
+	// ApplyIO the writes of the WAL and the manifest, which are on the path of
+	// applying the user writes
+	ApplyIO IOClass = "apply"
+	// FlushIO the writes of the sstables by the memtable flushes, which are not
+	// throttled as the apply writes are stopped once the memtables are full
+	FlushIO IOClass = "flush"
+	// CompactionIO the writes of the sstables by the background compaction
+	CompactionIO IOClass = "compaction"
+)
+
+// IOPriorityConfig is the config of the disk io priority of a pebble storage.
+// The applications usually create a storage for each shard group, so the io
+// priority is configured per group.
+type IOPriorityConfig struct {
+	// Name is the name of the storage, used as the label of the io metrics,
+	// e.g. the group of the data storage.
+	Name string
+	// CompactionBytesPerSecond is the rate limit of the compaction writes while
+	// the apply writes are busy, 0 means no limit.
+	CompactionBytesPerSecond int64
+	// ApplyBusyWindow the apply writes are considered busy within the window
+	// after the last apply write, the compaction writes are not throttled once
+	// the apply writes are idle. Default is 1s.
+	ApplyBusyWindow time.Duration
+}
+
+// NewIOPriorityConfig returns the io priority config of the pebble storage named
+// name adjusted by the io priority config of the store.
+func NewIOPriorityConfig(name string, cfg config.IOPriorityConfig) IOPriorityConfig {
+	return IOPriorityConfig{
+		Name:                     name,
+		CompactionBytesPerSecond: int64(cfg.CompactionBytesPerSecond),
+		ApplyBusyWindow:          cfg.ApplyBusyWindow.Duration,
+	}
+}
+
+// ioPriorityFS is a pebble vfs.FS which prioritizes the apply writes over the
+// compaction writes. The writes are classified by the file names and the table
+// created events of the pebble, the compaction writes are throttled by the rate
+// limit if the apply writes are busy.
+type ioPriorityFS struct {
+	// lastApply the unix nano of the last apply write
+	lastApply int64
+
+	vfs.FS
+	cfg     IOPriorityConfig
+	limiter *ratelimit.Bucket
+	now     func() time.Time
+	// tables path -> *ioPriorityFile, the sstables created and not classified by
+	// the table created event yet
+	tables sync.Map
+}
+
+// NewIOPriorityFS returns a pebble vfs.FS prioritizing the apply writes over
+// the compaction writes, use it as the pebble.Options.FS of the storage created
+// by NewStorage, which classifies the flush writes by the pebble events.
+func NewIOPriorityFS(fs vfs.FS, cfg IOPriorityConfig) vfs.FS {
+	if cfg.ApplyBusyWindow == 0 {
+		cfg.ApplyBusyWindow = defaultApplyBusyWindow
+	}
+
+	pfs := &ioPriorityFS{FS: fs, cfg: cfg, now: time.Now}
+	if cfg.CompactionBytesPerSecond > 0 {
+		pfs.limiter = ratelimit.NewBucketWithRate(float64(cfg.CompactionBytesPerSecond),
+			cfg.CompactionBytesPerSecond)
+	}
+	return pfs
+}
+
+func (fs *ioPriorityFS) Create(name string) (vfs.File, error) {
+	f, err := fs.FS.Create(name)
+	if err != nil {
+		return nil, err
+	}
+	return fs.wrap(f, name), nil
+}
+
+func (fs *ioPriorityFS) ReuseForWrite(oldname, newname string) (vfs.File, error) {
+	f, err := fs.FS.ReuseForWrite(oldname, newname)
+	if err != nil {
+		return nil, err
+	}
+	return fs.wrap(f, newname), nil
+}
+
+func (fs *ioPriorityFS) wrap(f vfs.File, name string) vfs.File {
+	pf := &ioPriorityFile{File: f, fs: fs, name: name, class: ApplyIO}
+	if strings.HasSuffix(name, ".sst") {
+		// classified by the table created event before the first write
+		pf.class = CompactionIO
+		fs.tables.Store(name, pf)
+	}
+	return pf
+}
+
+// wrapListener returns the event listener classifying the sstables written by
+// the flushes, the table created events are passed to the listener l.
+func (fs *ioPriorityFS) wrapListener(l cpebble.EventListener) cpebble.EventListener {
+	created := l.TableCreated
+	l.TableCreated = func(info cpebble.TableCreateInfo) {
+		fs.tableCreated(info)
+		if created != nil {
+			created(info)
+		}
+	}
+	return l
+}
+
+func (fs *ioPriorityFS) tableCreated(info cpebble.TableCreateInfo) {
+	if v, ok := fs.tables.Load(info.Path); ok {
+		fs.tables.Delete(info.Path)
+		if info.Reason == "flushing" {
+			v.(*ioPriorityFile).class = FlushIO
+		}
+	}
+}
+
+func (fs *ioPriorityFS) applyBusy() bool {
+	last := atomic.LoadInt64(&fs.lastApply)
+	return last > 0 && fs.now().UnixNano()-last < int64(fs.cfg.ApplyBusyWindow)
+}
+
+// beforeWrite records the write and returns the duration the write should be
+// throttled.
+func (fs *ioPriorityFS) beforeWrite(class IOClass, size int) time.Duration {
+	metric.AddStorageIOWrittenBytes(fs.cfg.Name, string(class), uint64(size))
+	switch class {
+	case ApplyIO:
+		atomic.StoreInt64(&fs.lastApply, fs.now().UnixNano())
+	case CompactionIO:
+		if fs.limiter != nil && fs.applyBusy() {
+			return fs.limiter.Take(int64(size))
+		}
+	}
+	return 0
+}
+
+type ioPriorityFile struct {
+	vfs.File
+
+	fs    *ioPriorityFS
+	name  string
+	class IOClass
+}
+
+func (f *ioPriorityFile) Write(p []byte) (int, error) {
+	if d := f.fs.beforeWrite(f.class, len(p)); d > 0 {
+		metric.AddStorageIOThrottledDuration(f.fs.cfg.Name, string(f.class), d)
+		time.Sleep(d)
+	}
+	return f.File.Write(p)
+}
+
+func (f *ioPriorityFile) Close() error {
+	f.fs.tables.Delete(f.name)
+	return f.File.Close()
+}
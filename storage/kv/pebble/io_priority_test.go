@@ -0,0 +1,105 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pebble
+
+import (
+	"testing"
+	"time"
+
+	cpebble "github.com/cockroachdb/pebble"
+	"github.com/cockroachdb/pebble/vfs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIOPriorityFSClassifiesWrites(t *testing.T) {
+	fs := NewIOPriorityFS(vfs.NewMem(), IOPriorityConfig{}).(*ioPriorityFS)
+
+	f, err := fs.Create("000001.log")
+	assert.NoError(t, err)
+	assert.Equal(t, ApplyIO, f.(*ioPriorityFile).class)
+	assert.NoError(t, f.Close())
+
+	f, err = fs.Create("000002.sst")
+	assert.NoError(t, err)
+	assert.Equal(t, CompactionIO, f.(*ioPriorityFile).class)
+	assert.NoError(t, f.Close())
+
+	f, err = fs.ReuseForWrite("000001.log", "000003.log")
+	assert.NoError(t, err)
+	assert.Equal(t, ApplyIO, f.(*ioPriorityFile).class)
+	assert.NoError(t, f.Close())
+
+	// the sstables are classified by the table created events
+	flush, err := fs.Create("000004.sst")
+	assert.NoError(t, err)
+	compaction, err := fs.Create("000005.sst")
+	assert.NoError(t, err)
+	l := fs.wrapListener(cpebble.EventListener{})
+	l.TableCreated(cpebble.TableCreateInfo{Reason: "flushing", Path: "000004.sst"})
+	l.TableCreated(cpebble.TableCreateInfo{Reason: "compacting", Path: "000005.sst"})
+	assert.Equal(t, FlushIO, flush.(*ioPriorityFile).class)
+	assert.Equal(t, CompactionIO, compaction.(*ioPriorityFile).class)
+	assert.NoError(t, flush.Close())
+	assert.NoError(t, compaction.Close())
+	fs.tables.Range(func(key, value interface{}) bool {
+		assert.Fail(t, "table not removed", key)
+		return true
+	})
+}
+
+func TestIOPriorityFSThrottlesCompactionWhileApplyBusy(t *testing.T) {
+	now := time.Unix(100, 0)
+	fs := NewIOPriorityFS(vfs.NewMem(), IOPriorityConfig{
+		CompactionBytesPerSecond: 1024,
+		ApplyBusyWindow:          time.Second,
+	}).(*ioPriorityFS)
+	fs.now = func() time.Time { return now }
+
+	// apply writes are idle, the compaction writes are not throttled
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, time.Duration(0), fs.beforeWrite(CompactionIO, 1024))
+	}
+
+	// apply and flush writes are never throttled
+	assert.Equal(t, time.Duration(0), fs.beforeWrite(ApplyIO, 1024*1024))
+	assert.True(t, fs.applyBusy())
+	assert.Equal(t, time.Duration(0), fs.beforeWrite(FlushIO, 1024*1024))
+	assert.Equal(t, time.Duration(0), fs.beforeWrite(CompactionIO, 1024))
+	assert.True(t, fs.beforeWrite(CompactionIO, 1024) > 0)
+
+	// apply writes become idle
+	now = now.Add(time.Second)
+	assert.False(t, fs.applyBusy())
+	assert.Equal(t, time.Duration(0), fs.beforeWrite(CompactionIO, 1024*1024))
+}
+
+func TestStorageWithIOPriorityFS(t *testing.T) {
+	fs := NewIOPriorityFS(vfs.NewMem(), IOPriorityConfig{
+		Name:                     "test",
+		CompactionBytesPerSecond: 1024 * 1024,
+	})
+	s, err := NewStorage("/test", nil, &cpebble.Options{FS: fs})
+	assert.NoError(t, err)
+	defer s.Close()
+
+	assert.NoError(t, s.Set([]byte("k1"), []byte("v1"), true))
+	assert.NoError(t, s.db.Flush())
+	fs.(*ioPriorityFS).tables.Range(func(key, value interface{}) bool {
+		assert.Fail(t, "flushed table not classified", key)
+		return true
+	})
+	v, err := s.Get([]byte("k1"))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("v1"), v)
+}
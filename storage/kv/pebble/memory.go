@@ -0,0 +1,84 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pebble
+
+import (
+	"github.com/cockroachdb/pebble"
+	"github.com/matrixorigin/matrixcube/config"
+	"github.com/matrixorigin/matrixcube/storage/stats"
+)
+
+const (
+	numLevels = 7
+)
+
+// MemoryOptions the memory settings of the pebble
+type MemoryOptions struct {
+	// CacheSize block cache size, 0 means the pebble default
+	CacheSize int64
+	// BlockSize target uncompressed size of the sstable blocks of all levels, 0
+	// means the pebble default
+	BlockSize int
+	// MemTableSize size of a memtable, 0 means the pebble default
+	MemTableSize int
+	// MemTableStopWritesThreshold max number of the queued memtables before the
+	// writes are stopped, 0 means the pebble default
+	MemTableStopWritesThreshold int
+}
+
+// NewMemoryOptions returns the memory settings of the pebble adjusted by the
+// memory config of the store.
+func NewMemoryOptions(cfg config.MemoryConfig) MemoryOptions {
+	return MemoryOptions{
+		CacheSize:                   int64(cfg.CacheSize),
+		BlockSize:                   int(cfg.BlockSize),
+		MemTableSize:                int(cfg.MemTableSize),
+		MemTableStopWritesThreshold: cfg.MemTableStopWritesThreshold,
+	}
+}
+
+// Apply applies the memory settings to the pebble options. The returned cache,
+// if not nil, is referenced by the options and must be released by calling its
+// Unref after the pebble is opened.
+func (m MemoryOptions) Apply(opts *pebble.Options) *pebble.Cache {
+	if m.MemTableSize > 0 {
+		opts.MemTableSize = m.MemTableSize
+	}
+	if m.MemTableStopWritesThreshold > 0 {
+		opts.MemTableStopWritesThreshold = m.MemTableStopWritesThreshold
+	}
+	if m.BlockSize > 0 {
+		if len(opts.Levels) == 0 {
+			opts.Levels = make([]pebble.LevelOptions, numLevels)
+		}
+		for i := range opts.Levels {
+			opts.Levels[i].BlockSize = m.BlockSize
+		}
+	}
+	if m.CacheSize > 0 {
+		opts.Cache = pebble.NewCache(m.CacheSize)
+		return opts.Cache
+	}
+	return nil
+}
+
+// MemoryStats returns the memory used by the pebble
+func (s *Storage) MemoryStats() stats.MemoryStats {
+	m := s.db.Metrics()
+	return stats.MemoryStats{
+		BlockCache: uint64(m.BlockCache.Size),
+		TableCache: uint64(m.TableCache.Size),
+		MemTable:   m.MemTable.Size,
+	}
+}
@@ -0,0 +1,53 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pebble
+
+import (
+	"testing"
+
+	cpebble "github.com/cockroachdb/pebble"
+	"github.com/cockroachdb/pebble/vfs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryOptionsApply(t *testing.T) {
+	opts := &cpebble.Options{FS: vfs.NewMem()}
+	cache := MemoryOptions{
+		CacheSize:                   1024 * 1024,
+		BlockSize:                   32 * 1024,
+		MemTableSize:                4 * 1024 * 1024,
+		MemTableStopWritesThreshold: 2,
+	}.Apply(opts)
+	require.NotNil(t, cache)
+	assert.Equal(t, numLevels, len(opts.Levels))
+	assert.Equal(t, 32*1024, opts.Levels[numLevels-1].BlockSize)
+	assert.Equal(t, 4*1024*1024, opts.MemTableSize)
+	assert.Equal(t, 2, opts.MemTableStopWritesThreshold)
+
+	s, err := NewStorage("test-data", nil, opts)
+	cache.Unref()
+	require.NoError(t, err)
+	defer s.Close()
+
+	assert.NoError(t, s.Set([]byte("k1"), []byte("v1"), false))
+	st := s.MemoryStats()
+	assert.True(t, st.MemTable > 0)
+
+	// the zero values are ignored
+	opts = &cpebble.Options{MemTableSize: 1024}
+	assert.Nil(t, MemoryOptions{}.Apply(opts))
+	assert.Equal(t, 1024, opts.MemTableSize)
+	assert.Empty(t, opts.Levels)
+}
@@ -41,18 +41,30 @@ func (v *view) Raw() interface{} {
 
 // Storage returns a kv storage based on badger
 type Storage struct {
-	db    *pebble.DB
-	stats stats.Stats
+	db       *pebble.DB
+	stats    stats.Stats
+	stall    *writeStall
+	observer *compactionObserver
 }
 
 var _ storage.KVStorage = (*Storage)(nil)
+var _ storage.ZeroCopyKVStore = (*Storage)(nil)
+var _ storage.MemoryStatsReader = (*Storage)(nil)
+var _ storage.WriteStallStatsReader = (*Storage)(nil)
+var _ storage.ManualCompactionStorage = (*Storage)(nil)
+var _ storage.CompactionObserverStorage = (*Storage)(nil)
 
 // CreateLogDBStorage creates the underlying storage that will be used by the
 // LogDB.
-func CreateLogDBStorage(rootDir string, fs vfs.FS, logger *zap.Logger) storage.KVStorage {
+func CreateLogDBStorage(rootDir string, fs vfs.FS, memory MemoryOptions,
+	ioPriority IOPriorityConfig, logger *zap.Logger) storage.KVStorage {
 	path := fs.PathJoin(rootDir, "logdb")
+	pfs := vfs.NewPebbleFS(fs)
+	if ioPriority.CompactionBytesPerSecond > 0 {
+		pfs = NewIOPriorityFS(pfs, ioPriority)
+	}
 	opts := &pebble.Options{
-		FS:                          vfs.NewPebbleFS(fs),
+		FS:                          pfs,
 		MemTableSize:                1024 * 1024 * 64,
 		MemTableStopWritesThreshold: 8,
 		MaxManifestFileSize:         1024 * 1024,
@@ -60,7 +72,11 @@ func CreateLogDBStorage(rootDir string, fs vfs.FS, logger *zap.Logger) storage.K
 		EventListener:               getEventListener(log.Adjust(logger).Named("pebble")),
 		MaxOpenFiles:                1024,
 	}
+	cache := memory.Apply(opts)
 	kv, err := NewStorage(path, logger, opts)
+	if cache != nil {
+		cache.Unref()
+	}
 	if err != nil {
 		panic(err)
 	}
@@ -72,13 +88,22 @@ func NewStorage(dir string, logger *zap.Logger, opts *pebble.Options) (*Storage,
 	if !hasEventListener(opts.EventListener) {
 		opts.EventListener = getEventListener(log.Adjust(logger).Named("pebble"))
 	}
+	stall := &writeStall{}
+	opts.EventListener = stall.wrap(opts.EventListener)
+	if pfs, ok := opts.FS.(*ioPriorityFS); ok {
+		opts.EventListener = pfs.wrapListener(opts.EventListener)
+	}
+	observer := &compactionObserver{}
+	observer.wrap(opts)
 	db, err := pebble.Open(dir, opts)
 	if err != nil {
 		return nil, err
 	}
 
 	return &Storage{
-		db: db,
+		db:       db,
+		stall:    stall,
+		observer: observer,
 	}, nil
 }
 
@@ -124,6 +149,25 @@ func (s *Storage) Get(key []byte) ([]byte, error) {
 	return v, nil
 }
 
+// GetReadValue returns the value of the key without copying, the value pins
+// the pebble block holding it until the value is released.
+func (s *Storage) GetReadValue(key []byte) (*storage.ReadValue, error) {
+	value, closer, err := s.db.Get(key)
+	if err == pebble.ErrNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(value) == 0 {
+		closer.Close()
+		return nil, nil
+	}
+	atomic.AddUint64(&s.stats.ReadKeys, 1)
+	atomic.AddUint64(&s.stats.ReadBytes, uint64(len(key)+len(value)))
+	return storage.NewReadValue(value, func() { closer.Close() }), nil
+}
+
 // Delete remove the key from the storage
 func (s *Storage) Delete(key []byte, sync bool) error {
 	atomic.AddUint64(&s.stats.WrittenKeys, 1)
@@ -307,6 +351,19 @@ func (s *Storage) Seek(target []byte) ([]byte, []byte, error) {
 	return key, value, nil
 }
 
+// CompactRange compacts the key range [start, end) by the pebble manual
+// compaction.
+func (s *Storage) CompactRange(start, end []byte) error {
+	return s.db.Compact(start, end)
+}
+
+// SetCompactionObserver sets the func called with each key and value written by
+// the flushes and the compactions.
+func (s *Storage) SetCompactionObserver(fn func(key, value []byte)) error {
+	s.observer.set(fn)
+	return nil
+}
+
 // Sync persist data to disk
 func (s *Storage) Sync() error {
 	atomic.AddUint64(&s.stats.SyncCount, 1)
@@ -353,6 +410,9 @@ type writeBatch struct {
 	stats *stats.Stats
 }
 
+var _ util.WriteBatchKeysIterator = (*writeBatch)(nil)
+var _ util.WriteBatchMerger = (*writeBatch)(nil)
+
 func (wb *writeBatch) Delete(key []byte) {
 	wb.batch.Delete(key, nil)
 	atomic.AddUint64(&wb.stats.WrittenBytes, uint64(len(key)))
@@ -374,3 +434,24 @@ func (wb *writeBatch) Reset() {
 func (wb *writeBatch) Close() {
 	wb.batch.Close()
 }
+
+func (wb *writeBatch) Merge(other util.WriteBatch) error {
+	return wb.batch.Apply(other.(*writeBatch).batch, nil)
+}
+
+func (wb *writeBatch) IterateKeys(handler func(key, end []byte)) {
+	r := wb.batch.Reader()
+	for {
+		kind, key, value, ok := r.Next()
+		if !ok {
+			return
+		}
+		switch kind {
+		case pebble.InternalKeyKindSet, pebble.InternalKeyKindDelete,
+			pebble.InternalKeyKindSingleDelete:
+			handler(key, nil)
+		case pebble.InternalKeyKindRangeDelete:
+			handler(key, value)
+		}
+	}
+}
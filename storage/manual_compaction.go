@@ -0,0 +1,23 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+// ManualCompactionStorage is implemented by the storages supporting the manual
+// compaction of a key range, e.g. to reclaim the space and speed up the scans
+// after the bulk deletes.
+type ManualCompactionStorage interface {
+	// CompactRange compacts the data in the key range [start, end) and blocks
+	// until the compaction is completed.
+	CompactRange(start, end []byte) error
+}
@@ -0,0 +1,31 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+// OrphanDataStorage is implemented by the DataStorage which supports removing
+// the orphan data, i.e. the data of the key ranges not owned by any shard on
+// the store. The key ranges are [start, end) ranges of the keys used by the
+// shards, empty start or end means the min or max key.
+type OrphanDataStorage interface {
+	// ScanData scans the data within the key range, the handler is invoked with
+	// each key and the size of the key-value pair until the handler function
+	// returns false. The key can not be retained after the handler returns.
+	ScanData(start, end []byte, handler func(key []byte, size uint64) (bool, error)) error
+	// RemoveOrphanData removes the data within the key range.
+	RemoveOrphanData(start, end []byte) error
+	// QuarantineOrphanData moves the data within the key range out of the data
+	// key space, so the data is not visible to any shard but can be restored
+	// manually.
+	QuarantineOrphanData(start, end []byte) error
+}
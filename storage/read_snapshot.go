@@ -0,0 +1,33 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import "time"
+
+// ReadSnapshotStorage is implemented by the DataStorage which supports the named
+// read snapshots of the shards. A read snapshot pins a point in time view of the
+// data, the read requests with the name of the read snapshot are served from the
+// view, so the long scans do not see the concurrent writes. The read snapshots
+// are kept in memory and lost after restart.
+type ReadSnapshotStorage interface {
+	// CreateReadSnapshot creates the named read snapshot of the shard with the
+	// current data, ErrReadSnapshotExists is returned if the name is used. The
+	// read snapshot not read within the ttl is released, 0 means the default TTL
+	// of the data storage.
+	CreateReadSnapshot(shardID uint64, name string, ttl time.Duration) error
+	// ReleaseReadSnapshot releases the named read snapshot of the shard, the
+	// view is closed once all the reads on it completed. It's a no-op if the
+	// read snapshot is not found.
+	ReleaseReadSnapshot(shardID uint64, name string) error
+}
@@ -0,0 +1,73 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"sync/atomic"
+)
+
+// ReadValue is a reference counted value read from the storage engine. It is
+// used by the zero copy reads to pass the buffer owned by the storage engine to
+// the client without copying. The data must not be modified, and must not be
+// used after the last reference is released.
+type ReadValue struct {
+	data    []byte
+	refs    int32
+	release func()
+}
+
+// NewReadValue returns a ReadValue holding one reference, the release func is
+// called once the last reference is released.
+func NewReadValue(data []byte, release func()) *ReadValue {
+	return &ReadValue{
+		data:    data,
+		refs:    1,
+		release: release,
+	}
+}
+
+// Data returns the value, it is only valid until the last reference released.
+func (v *ReadValue) Data() []byte {
+	return v.data
+}
+
+// Retain adds a reference to the value.
+func (v *ReadValue) Retain() {
+	if atomic.AddInt32(&v.refs, 1) <= 1 {
+		panic("BUG: retain a released read value")
+	}
+}
+
+// Release releases a reference of the value.
+func (v *ReadValue) Release() {
+	n := atomic.AddInt32(&v.refs, -1)
+	if n < 0 {
+		panic("BUG: read value released too many times")
+	}
+	if n == 0 {
+		v.data = nil
+		if v.release != nil {
+			v.release()
+		}
+	}
+}
+
+// ZeroCopyKVStore is implemented by the KVStore which can return the values
+// without copying them out of the storage engine.
+type ZeroCopyKVStore interface {
+	// GetReadValue returns the value associated with the key without copying,
+	// nil is returned if the key doesn't exist. The caller owns the returned
+	// reference.
+	GetReadValue(key []byte) (*ReadValue, error)
+}
@@ -22,3 +22,25 @@ type Stats struct {
 	// SyncCount number of `Sync` method called
 	SyncCount uint64
 }
+
+// MemoryStats memory used by the storage
+type MemoryStats struct {
+	// BlockCache bytes used by the block cache
+	BlockCache uint64
+	// TableCache bytes used by the table cache
+	TableCache uint64
+	// MemTable bytes allocated by the memtables and the large batches
+	MemTable uint64
+}
+
+// WriteStallStats write stall state of the storage
+type WriteStallStats struct {
+	// Stalled the writes are being stalled by the storage
+	Stalled bool
+	// Stalls number of the write stalls began since the storage opened
+	Stalls uint64
+	// L0Files number of the files in the L0 of the LSM tree
+	L0Files int64
+	// L0Sublevels number of the sublevels in the L0 of the LSM tree
+	L0Sublevels int32
+}
@@ -31,6 +31,27 @@ var (
 	// ErrShardNotFound is returned by the data storage to indicate that the
 	// requested shard is not found.
 	ErrShardNotFound = errors.New("shard not found")
+	// ErrReadSnapshotNotFound is returned by the data storage to indicate that
+	// the read snapshot of the request is not found.
+	ErrReadSnapshotNotFound = errors.New("read snapshot not found")
+	// ErrReadSnapshotExists is returned by the data storage to indicate that
+	// the read snapshot with the same name is already created on the shard.
+	ErrReadSnapshotExists = errors.New("read snapshot already exists")
+	// ErrDeltaSnapshotUnavailable is returned by the data storage to indicate
+	// that the delta snapshot can not be created as the changes since the base
+	// index are not tracked, the full snapshot should be used instead.
+	ErrDeltaSnapshotUnavailable = errors.New("delta snapshot unavailable")
+	// ErrDeltaSnapshotBaseNotApplied is returned by the data storage to indicate
+	// that the delta snapshot can not be applied as its base index is not
+	// applied to the shard yet.
+	ErrDeltaSnapshotBaseNotApplied = errors.New("base index of delta snapshot not applied")
+	// ErrManualCompactionNotSupported is returned by the storage to indicate
+	// that the underlying storage engine does not support the manual compaction.
+	ErrManualCompactionNotSupported = errors.New("manual compaction not supported")
+	// ErrCompactionObserverNotSupported is returned by the storage to indicate
+	// that the underlying storage engine does not report the keys written by
+	// its compactions.
+	ErrCompactionObserverNotSupported = errors.New("compaction observer not supported")
 )
 
 // Closeable is an instance that can be closed.
@@ -169,12 +190,28 @@ type Feature struct {
 	// value that changes after each Write call. Whenever this value exceeds the size set by the
 	// current field, a real check is made to see if a split is needed, involving real IO operations.
 	ShardSplitCheckBytes uint64
+	// ShardStatsReconcileDuration the approximate size and keys of the Shard are maintained
+	// incrementally after each Write call, they are reconciled with the real values scanned
+	// from the storage at this interval even if the Shard does not need to be split. 0 means
+	// never reconcile.
+	ShardStatsReconcileDuration time.Duration
 	// DisableShardSplit disable shard split
 	DisableShardSplit bool
+	// HashRouting the shards of the group are addressed by the logical partition
+	// numbers instead of the byte-ordered keys, the data keys are prefixed with
+	// the partition keys returned by `raftstore.EncodePartitionKey`. The shards
+	// are only split at the partition boundaries.
+	HashRouting bool
 	// ForceCompactCount force compaction when the number of Raft logs reaches the specified number
 	ForceCompactCount uint64
 	// ForceCompactBytes force compaction when the number of Raft logs reaches the specified bytes
 	ForceCompactBytes uint64
+	// ExpiredDataPurgeDuration the interval to purge the expired data of the shards if the
+	// data storage implements the TTLDataStorage, 0 means never purge.
+	ExpiredDataPurgeDuration time.Duration
+	// CompactionFilterDuration the interval to drop the data filtered by the compaction
+	// filter if the data storage implements the CompactionFilterDataStorage, 0 means never.
+	CompactionFilterDuration time.Duration
 }
 
 // WriteContext contains the details of write requests to be handled by the
@@ -202,6 +239,10 @@ type WriteContext interface {
 	// contributes to the scheduler's auto-rebalancing feature.
 	// This method must be called before `Read` or `Write` returns.
 	SetWrittenBytes(uint64)
+	// SetDiffKeys set the diff of the number of keys stored in storage after
+	// Write is executed, it is used to maintain the approximate keys of the
+	// shard incrementally.
+	SetDiffKeys(int64)
 	// SetDiffBytes set the diff of the bytes stored in storage after Write is
 	// executed. This is an approximation value used to modify the approximate
 	// amount of data in the `Shard` which is used for triggering the auto-split
@@ -220,6 +261,21 @@ type ReadContext interface {
 	// the current context. This is an approximation value that contributes to the
 	// scheduler's auto-rebalancing feature.
 	SetReadBytes(uint64)
+	// ZeroCopy returns true if the read result can be returned by SetReadValue
+	// without copying it out of the storage engine.
+	ZeroCopy() bool
+	// SetReadValue sets the result of the read request, the reference of the value
+	// is transferred to the context and the result returned by Read is ignored. It
+	// can only be called if ZeroCopy returns true.
+	SetReadValue(*ReadValue)
+	// View returns the view of the read snapshot the request reads from, nil
+	// means the request reads the current data. The executor should read the
+	// data in the view by the `ScanInView` of the KVStore if it's not nil.
+	View() View
+	// SetContinuationKey sets the key the read continues from if the result is
+	// truncated to the MaxResponseBytes of the request, the client receives the
+	// partial result with the continuation key.
+	SetContinuationKey([]byte)
 }
 
 // Batch contains a list of requests. For write batches, all requests are from
@@ -231,6 +287,10 @@ type Batch struct {
 	Index uint64
 	// Requests is the requests included in the batch.
 	Requests []Request
+	// Timestamp is the unix nanoseconds assigned by the leader proposing the
+	// write batch, all the replicas see the same Timestamp of the batch. 0 if
+	// the batch is not proposed by a leader.
+	Timestamp int64
 }
 
 // Request is the custom request type.
@@ -241,6 +301,13 @@ type Request struct {
 	Key []byte
 	// Cmd is the content of the request.
 	Cmd []byte
+	// ReadSnapshot is the name of the read snapshot the read request reads
+	// from, empty means reading the current data.
+	ReadSnapshot string
+	// MaxResponseBytes is the max size of the response of the read request, 0
+	// means no limit. The executor should truncate the result and set the
+	// continuation key if the result exceeds it.
+	MaxResponseBytes uint64
 }
 
 // SimpleWriteContext is a simple WriteContext implementation used for testing.
@@ -252,6 +319,7 @@ type SimpleWriteContext struct {
 	responses    [][]byte
 	writtenBytes uint64
 	diffBytes    int64
+	diffKeys     int64
 }
 
 var _ WriteContext = (*SimpleWriteContext)(nil)
@@ -277,15 +345,21 @@ func (ctx *SimpleWriteContext) AppendResponse(value []byte) {
 }
 func (ctx *SimpleWriteContext) SetWrittenBytes(value uint64) { ctx.writtenBytes = value }
 func (ctx *SimpleWriteContext) SetDiffBytes(value int64)     { ctx.diffBytes = value }
+func (ctx *SimpleWriteContext) SetDiffKeys(value int64)      { ctx.diffKeys = value }
 func (ctx *SimpleWriteContext) GetWrittenBytes() uint64      { return ctx.writtenBytes }
 func (ctx *SimpleWriteContext) GetDiffBytes() int64          { return ctx.diffBytes }
+func (ctx *SimpleWriteContext) GetDiffKeys() int64           { return ctx.diffKeys }
 func (ctx *SimpleWriteContext) Responses() [][]byte          { return ctx.responses }
 
 type SimpleReadContext struct {
-	buf       *buf.ByteBuf
-	shard     metapb.Shard
-	request   Request
-	readBytes uint64
+	buf             *buf.ByteBuf
+	shard           metapb.Shard
+	request         Request
+	readBytes       uint64
+	zeroCopy        bool
+	readValue       *ReadValue
+	view            View
+	continuationKey []byte
 }
 
 // NewSimpleReadContext returns a testing context.
@@ -303,6 +377,26 @@ func (c *SimpleReadContext) Shard() metapb.Shard           { return c.shard }
 func (c *SimpleReadContext) Request() Request              { return c.request }
 func (c *SimpleReadContext) SetReadBytes(readBytes uint64) { c.readBytes = readBytes }
 func (c *SimpleReadContext) GetReadBytes() uint64          { return c.readBytes }
+func (c *SimpleReadContext) ZeroCopy() bool                { return c.zeroCopy }
+func (c *SimpleReadContext) SetZeroCopy(value bool)        { c.zeroCopy = value }
+func (c *SimpleReadContext) SetReadValue(value *ReadValue) { c.readValue = value }
+func (c *SimpleReadContext) GetReadValue() *ReadValue      { return c.readValue }
+func (c *SimpleReadContext) View() View                    { return c.view }
+func (c *SimpleReadContext) SetView(view View)             { c.view = view }
+func (c *SimpleReadContext) SetContinuationKey(key []byte) { c.continuationKey = key }
+func (c *SimpleReadContext) ContinuationKey() []byte       { return c.continuationKey }
+
+// MemoryStatsReader is implemented by the storages reporting the memory used
+type MemoryStatsReader interface {
+	// MemoryStats returns the memory used by the storage
+	MemoryStats() stats.MemoryStats
+}
+
+// WriteStallStatsReader is implemented by the storages reporting the write stalls
+type WriteStallStatsReader interface {
+	// WriteStallStats returns the write stall state of the storage
+	WriteStallStats() stats.WriteStallStats
+}
 
 // KVStorageWrapper is a KVStorage wrapper
 type KVStorageWrapper interface {
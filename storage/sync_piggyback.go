@@ -0,0 +1,34 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+// SyncPiggybackStorage is implemented by the data storages whose writes can be
+// persisted by the fsync of another KVStorage sharing the same WAL, e.g. the
+// logdb of the store on the same pebble instance. The fsync of the data storage
+// itself is skipped after enabled, the applied indexes of the writes completed
+// before a fsync of the shared WAL are marked persistent after it.
+type SyncPiggybackStorage interface {
+	// SharesWAL returns true if the writes of the storage are persisted by the
+	// fsync of the kv storage.
+	SharesWAL(kv KVStorage) bool
+	// EnableSyncPiggyback stops the sampled fsync of the storage, the persistent
+	// applied indexes are advanced by the PrepareSync and the Synced calls.
+	EnableSyncPiggyback()
+	// PrepareSync is called before the fsync of the shared WAL, returns the
+	// sequence of the last write completed.
+	PrepareSync() uint64
+	// Synced is called after the fsync of the shared WAL, the applied indexes of
+	// the writes up to the seq returned by PrepareSync are persistent.
+	Synced(seq uint64)
+}
@@ -0,0 +1,27 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+// TTLDataStorage is implemented by the DataStorage which supports the automatic
+// expiration of the data. The expired data is invisible to the reads, and it is
+// physically removed by the purge proposed by the leader of each shard at the
+// interval of the ExpiredDataPurgeDuration of the Feature.
+type TTLDataStorage interface {
+	// PurgeExpiredData removes the data of the shard of the WriteContext expired
+	// at the Timestamp of the Batch, at most limit keys from the start key are
+	// scanned. The applied index of the shard is updated to the Index of the
+	// Batch. It returns the key the next purge continues from, nil if the end of
+	// the shard is reached.
+	PurgeExpiredData(ctx WriteContext, start []byte, limit int) ([]byte, error)
+}
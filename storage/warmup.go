@@ -0,0 +1,23 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+// WarmupStorage is implemented by the DataStorage which supports prefetching
+// the data of the keys into the caches of the storage engine. It's used to warm
+// up the new leader of a shard with the hot keys of the previous leader.
+type WarmupStorage interface {
+	// PrefetchKeys reads the data of the keys into the caches, the keys not
+	// found are ignored.
+	PrefetchKeys(keys [][]byte) error
+}
@@ -0,0 +1,269 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transport
+
+import (
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"go.uber.org/zap"
+
+	"github.com/matrixorigin/matrixcube/components/log"
+	"github.com/matrixorigin/matrixcube/components/prophet/util/typeutil"
+	"github.com/matrixorigin/matrixcube/pb/metapb"
+)
+
+var (
+	// ErrInvalidChaosOptions indicates that the chaos options are invalid.
+	ErrInvalidChaosOptions = errors.New("invalid chaos options")
+)
+
+// ChaosOptions are the fault injection presets applied to raft messages sent
+// by the chaos transport.
+type ChaosOptions struct {
+	// DropRate is the probability in [0, 1] of a message to be dropped.
+	DropRate float64 `toml:"drop-rate" json:"drop-rate"`
+	// DuplicateRate is the probability in [0, 1] of a message to be sent twice.
+	DuplicateRate float64 `toml:"duplicate-rate" json:"duplicate-rate"`
+	// MinDelay and MaxDelay, each regular message is delayed by a duration
+	// uniformly distributed in [MinDelay, MaxDelay]. Delayed messages might be
+	// reordered.
+	MinDelay typeutil.Duration `toml:"min-delay" json:"min-delay"`
+	MaxDelay typeutil.Duration `toml:"max-delay" json:"max-delay"`
+	// Partitions are sets of store IDs, messages are only allowed between stores
+	// in the same set. Stores not included in any set are not partitioned.
+	Partitions [][]uint64 `toml:"partitions" json:"partitions"`
+}
+
+// Validate returns an error if the options are invalid.
+func (o ChaosOptions) Validate() error {
+	if o.DropRate < 0 || o.DropRate > 1 {
+		return errors.Wrapf(ErrInvalidChaosOptions, "drop rate %f", o.DropRate)
+	}
+	if o.DuplicateRate < 0 || o.DuplicateRate > 1 {
+		return errors.Wrapf(ErrInvalidChaosOptions, "duplicate rate %f", o.DuplicateRate)
+	}
+	if o.MinDelay.Duration < 0 || o.MaxDelay.Duration < o.MinDelay.Duration {
+		return errors.Wrapf(ErrInvalidChaosOptions, "delay range [%s, %s]",
+			o.MinDelay, o.MaxDelay)
+	}
+	stores := make(map[uint64]struct{})
+	for _, p := range o.Partitions {
+		for _, id := range p {
+			if _, ok := stores[id]; ok {
+				return errors.Wrapf(ErrInvalidChaosOptions,
+					"store %d in multiple partitions", id)
+			}
+			stores[id] = struct{}{}
+		}
+	}
+	return nil
+}
+
+func (o ChaosOptions) partitionOf() map[uint64]int {
+	m := make(map[uint64]int)
+	for idx, p := range o.Partitions {
+		for _, id := range p {
+			m[id] = idx
+		}
+	}
+	return m
+}
+
+// ChaosStats counts the faults injected by the chaos transport.
+type ChaosStats struct {
+	Dropped     uint64 `json:"dropped"`
+	Partitioned uint64 `json:"partitioned"`
+	Delayed     uint64 `json:"delayed"`
+	Duplicated  uint64 `json:"duplicated"`
+}
+
+// ChaosController is used to update the fault injection presets at runtime.
+type ChaosController interface {
+	// UpdateOptions validates and applies the options, the zero value disables
+	// all faults.
+	UpdateOptions(ChaosOptions) error
+	// Options returns the current options.
+	Options() ChaosOptions
+	// Stats returns the injected faults since the transport was created.
+	Stats() ChaosStats
+}
+
+type chaosState struct {
+	options   ChaosOptions
+	partition map[uint64]int
+}
+
+// ChaosTransport wraps a Trans to inject faults into the sent raft messages,
+// it is intended for resilience testing in staging environments.
+type ChaosTransport struct {
+	Trans
+
+	logger  *zap.Logger
+	storeID uint64
+	state   atomic.Value // *chaosState
+	closed  uint32
+	stats   ChaosStats
+	mu      struct {
+		sync.Mutex
+		rand *rand.Rand
+	}
+}
+
+var _ Trans = (*ChaosTransport)(nil)
+var _ ChaosController = (*ChaosTransport)(nil)
+
+// NewChaosTransport returns a chaos transport which wraps the specified Trans
+// used by the specified store.
+func NewChaosTransport(logger *zap.Logger, storeID uint64,
+	trans Trans, options ChaosOptions) (*ChaosTransport, error) {
+	t := &ChaosTransport{
+		Trans:   trans,
+		logger:  log.Adjust(logger).Named("chaos"),
+		storeID: storeID,
+	}
+	t.mu.rand = rand.New(rand.NewSource(time.Now().UnixNano()))
+	if err := t.UpdateOptions(options); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// UpdateOptions implements the ChaosController interface.
+func (t *ChaosTransport) UpdateOptions(options ChaosOptions) error {
+	if err := options.Validate(); err != nil {
+		return err
+	}
+	t.state.Store(&chaosState{
+		options:   options,
+		partition: options.partitionOf(),
+	})
+	t.logger.Info("chaos options updated",
+		zap.Float64("drop-rate", options.DropRate),
+		zap.Float64("duplicate-rate", options.DuplicateRate),
+		zap.Duration("min-delay", options.MinDelay.Duration),
+		zap.Duration("max-delay", options.MaxDelay.Duration),
+		zap.Any("partitions", options.Partitions))
+	return nil
+}
+
+// Options implements the ChaosController interface.
+func (t *ChaosTransport) Options() ChaosOptions {
+	return t.getState().options
+}
+
+// Stats implements the ChaosController interface.
+func (t *ChaosTransport) Stats() ChaosStats {
+	return ChaosStats{
+		Dropped:     atomic.LoadUint64(&t.stats.Dropped),
+		Partitioned: atomic.LoadUint64(&t.stats.Partitioned),
+		Delayed:     atomic.LoadUint64(&t.stats.Delayed),
+		Duplicated:  atomic.LoadUint64(&t.stats.Duplicated),
+	}
+}
+
+// Send sends the message with the faults injected.
+func (t *ChaosTransport) Send(m metapb.RaftMessage) bool {
+	state := t.getState()
+	if t.shouldDrop(state, m) {
+		return false
+	}
+
+	if t.hit(state.options.DuplicateRate) {
+		atomic.AddUint64(&t.stats.Duplicated, 1)
+		t.sendWithDelay(state, m)
+	}
+	return t.sendWithDelay(state, m)
+}
+
+// SendSnapshot sends the snapshot message, snapshots are only affected by the
+// drop rate and partitions.
+func (t *ChaosTransport) SendSnapshot(m metapb.RaftMessage) bool {
+	if t.shouldDrop(t.getState(), m) {
+		return false
+	}
+	return t.Trans.SendSnapshot(m)
+}
+
+// Close closes the underlying Trans, delayed messages not sent yet are
+// discarded.
+func (t *ChaosTransport) Close() error {
+	atomic.StoreUint32(&t.closed, 1)
+	return t.Trans.Close()
+}
+
+func (t *ChaosTransport) getState() *chaosState {
+	return t.state.Load().(*chaosState)
+}
+
+func (t *ChaosTransport) shouldDrop(state *chaosState, m metapb.RaftMessage) bool {
+	if t.isPartitioned(state, m.To.StoreID) {
+		atomic.AddUint64(&t.stats.Partitioned, 1)
+		return true
+	}
+	if t.hit(state.options.DropRate) {
+		atomic.AddUint64(&t.stats.Dropped, 1)
+		return true
+	}
+	return false
+}
+
+func (t *ChaosTransport) isPartitioned(state *chaosState, to uint64) bool {
+	from, ok := state.partition[t.storeID]
+	if !ok {
+		return false
+	}
+	target, ok := state.partition[to]
+	return ok && from != target
+}
+
+func (t *ChaosTransport) sendWithDelay(state *chaosState, m metapb.RaftMessage) bool {
+	delay := t.delay(state.options)
+	if delay == 0 {
+		return t.Trans.Send(m)
+	}
+
+	atomic.AddUint64(&t.stats.Delayed, 1)
+	time.AfterFunc(delay, func() {
+		if atomic.LoadUint32(&t.closed) == 0 {
+			t.Trans.Send(m)
+		}
+	})
+	return true
+}
+
+func (t *ChaosTransport) hit(rate float64) bool {
+	if rate <= 0 {
+		return false
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.mu.rand.Float64() < rate
+}
+
+func (t *ChaosTransport) delay(options ChaosOptions) time.Duration {
+	min, max := options.MinDelay.Duration, options.MaxDelay.Duration
+	if max == 0 {
+		return 0
+	}
+	if max == min {
+		return min
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return min + time.Duration(t.mu.rand.Int63n(int64(max-min)))
+}
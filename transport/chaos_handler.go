@@ -0,0 +1,69 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transport
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+const (
+	// ChaosOptionsPath is the admin API path of the chaos options, GET returns
+	// the current options, PUT replaces them with the JSON encoded body.
+	ChaosOptionsPath = "/chaos/options"
+	// ChaosStatsPath is the admin API path of the chaos stats.
+	ChaosStatsPath = "/chaos/stats"
+)
+
+// NewChaosHandler returns the http.Handler of the chaos admin API, which is
+// used by operators to change the fault injection presets at runtime.
+func NewChaosHandler(c ChaosController) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(ChaosOptionsPath, func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			writeJSON(w, c.Options())
+		case http.MethodPut:
+			var options ChaosOptions
+			if err := json.NewDecoder(r.Body).Decode(&options); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			if err := c.UpdateOptions(options); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			writeJSON(w, c.Options())
+		default:
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed),
+				http.StatusMethodNotAllowed)
+		}
+	})
+	mux.HandleFunc(ChaosStatsPath, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed),
+				http.StatusMethodNotAllowed)
+			return
+		}
+		writeJSON(w, c.Stats())
+	})
+	return mux
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,77 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transport
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChaosHandler(t *testing.T) {
+	trans := &testTrans{}
+	ct, err := NewChaosTransport(nil, 1, trans, ChaosOptions{})
+	require.NoError(t, err)
+	h := NewChaosHandler(ct)
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodPut, ChaosOptionsPath,
+		strings.NewReader(`{"drop-rate": 1, "min-delay": "10ms", "max-delay": "20ms"}`)))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 1.0, ct.Options().DropRate)
+	assert.Equal(t, time.Millisecond*20, ct.Options().MaxDelay.Duration)
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, ChaosOptionsPath, nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	var options ChaosOptions
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &options))
+	assert.Equal(t, ct.Options(), options)
+
+	assert.False(t, ct.Send(newTestChaosMessage(2)))
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, ChaosStatsPath, nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	var stats ChaosStats
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &stats))
+	assert.Equal(t, uint64(1), stats.Dropped)
+}
+
+func TestChaosHandlerWithInvalidRequest(t *testing.T) {
+	trans := &testTrans{}
+	ct, err := NewChaosTransport(nil, 1, trans, ChaosOptions{})
+	require.NoError(t, err)
+	h := NewChaosHandler(ct)
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodPut, ChaosOptionsPath,
+		strings.NewReader(`{"drop-rate": 2}`)))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, ChaosOptions{}, ct.Options())
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodPut, ChaosOptionsPath,
+		strings.NewReader(`not json`)))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodPost, ChaosStatsPath, nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+}
@@ -0,0 +1,175 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transport
+
+import (
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/matrixorigin/matrixcube/components/prophet/util/typeutil"
+	"github.com/matrixorigin/matrixcube/pb/metapb"
+)
+
+type testTrans struct {
+	sync.Mutex
+	sent      []metapb.RaftMessage
+	snapshots []metapb.RaftMessage
+}
+
+func (t *testTrans) Send(m metapb.RaftMessage) bool {
+	t.Lock()
+	defer t.Unlock()
+	t.sent = append(t.sent, m)
+	return true
+}
+
+func (t *testTrans) SendSnapshot(m metapb.RaftMessage) bool {
+	t.Lock()
+	defer t.Unlock()
+	t.snapshots = append(t.snapshots, m)
+	return true
+}
+
+func (t *testTrans) sentCount() int {
+	t.Lock()
+	defer t.Unlock()
+	return len(t.sent)
+}
+
+func (t *testTrans) SetFilter(func(metapb.RaftMessage) bool) {}
+func (t *testTrans) SendingSnapshotCount() uint64            { return 0 }
+func (t *testTrans) SnapshotThrottled() bool                 { return false }
+func (t *testTrans) Start() error                            { return nil }
+func (t *testTrans) Close() error                            { return nil }
+
+func (t *testTrans) SnapshotProgresses() []metapb.SnapshotProgress { return nil }
+
+func newTestChaosMessage(to uint64) metapb.RaftMessage {
+	return metapb.RaftMessage{To: metapb.Replica{StoreID: to}}
+}
+
+func TestChaosOptionsValidate(t *testing.T) {
+	assert.NoError(t, ChaosOptions{}.Validate())
+	assert.Error(t, ChaosOptions{DropRate: 1.1}.Validate())
+	assert.Error(t, ChaosOptions{DuplicateRate: -0.1}.Validate())
+	assert.Error(t, ChaosOptions{MinDelay: typeutil.NewDuration(time.Second)}.Validate())
+	assert.Error(t, ChaosOptions{Partitions: [][]uint64{{1, 2}, {2, 3}}}.Validate())
+	assert.NoError(t, ChaosOptions{Partitions: [][]uint64{{1, 2}, {3}}}.Validate())
+}
+
+func TestChaosTransportNoFaults(t *testing.T) {
+	trans := &testTrans{}
+	ct, err := NewChaosTransport(nil, 1, trans, ChaosOptions{})
+	require.NoError(t, err)
+
+	assert.True(t, ct.Send(newTestChaosMessage(2)))
+	assert.True(t, ct.SendSnapshot(newTestChaosMessage(2)))
+	assert.Equal(t, 1, trans.sentCount())
+	assert.Equal(t, 1, len(trans.snapshots))
+	assert.Equal(t, ChaosStats{}, ct.Stats())
+}
+
+func TestChaosTransportDrop(t *testing.T) {
+	trans := &testTrans{}
+	ct, err := NewChaosTransport(nil, 1, trans, ChaosOptions{DropRate: 1})
+	require.NoError(t, err)
+
+	assert.False(t, ct.Send(newTestChaosMessage(2)))
+	assert.False(t, ct.SendSnapshot(newTestChaosMessage(2)))
+	assert.Equal(t, 0, trans.sentCount())
+	assert.Equal(t, uint64(2), ct.Stats().Dropped)
+
+	require.NoError(t, ct.UpdateOptions(ChaosOptions{}))
+	assert.True(t, ct.Send(newTestChaosMessage(2)))
+	assert.Equal(t, 1, trans.sentCount())
+}
+
+func TestChaosTransportDuplicate(t *testing.T) {
+	trans := &testTrans{}
+	ct, err := NewChaosTransport(nil, 1, trans, ChaosOptions{DuplicateRate: 1})
+	require.NoError(t, err)
+
+	assert.True(t, ct.Send(newTestChaosMessage(2)))
+	assert.Equal(t, 2, trans.sentCount())
+	assert.Equal(t, uint64(1), ct.Stats().Duplicated)
+}
+
+func TestChaosTransportPartition(t *testing.T) {
+	trans := &testTrans{}
+	ct, err := NewChaosTransport(nil, 1, trans, ChaosOptions{
+		Partitions: [][]uint64{{1, 2}, {3}},
+	})
+	require.NoError(t, err)
+
+	assert.True(t, ct.Send(newTestChaosMessage(2)))
+	assert.False(t, ct.Send(newTestChaosMessage(3)))
+	// store 4 is not partitioned
+	assert.True(t, ct.Send(newTestChaosMessage(4)))
+	assert.Equal(t, 2, trans.sentCount())
+	assert.Equal(t, uint64(1), ct.Stats().Partitioned)
+}
+
+func TestChaosTransportDelay(t *testing.T) {
+	trans := &testTrans{}
+	ct, err := NewChaosTransport(nil, 1, trans, ChaosOptions{
+		MinDelay: typeutil.NewDuration(time.Millisecond * 10),
+		MaxDelay: typeutil.NewDuration(time.Millisecond * 20),
+	})
+	require.NoError(t, err)
+
+	assert.True(t, ct.Send(newTestChaosMessage(2)))
+	assert.Equal(t, 0, trans.sentCount())
+	assert.Equal(t, uint64(1), ct.Stats().Delayed)
+	assert.Eventually(t, func() bool {
+		return trans.sentCount() == 1
+	}, time.Second, time.Millisecond*5)
+}
+
+func TestChaosTransportInvalidOptions(t *testing.T) {
+	trans := &testTrans{}
+	_, err := NewChaosTransport(nil, 1, trans, ChaosOptions{DropRate: 2})
+	assert.Error(t, err)
+
+	ct, err := NewChaosTransport(nil, 1, trans, ChaosOptions{DropRate: 0.5})
+	require.NoError(t, err)
+	assert.Error(t, ct.UpdateOptions(ChaosOptions{DropRate: -1}))
+	assert.Equal(t, 0.5, ct.Options().DropRate)
+}
+
+func TestChaosOptionsDecode(t *testing.T) {
+	var options ChaosOptions
+	_, err := toml.Decode(`
+drop-rate = 0.1
+min-delay = "10ms"
+max-delay = "1s"
+partitions = [[1, 2], [3]]
+`, &options)
+	require.NoError(t, err)
+	assert.Equal(t, 0.1, options.DropRate)
+	assert.Equal(t, time.Millisecond*10, options.MinDelay.Duration)
+	assert.Equal(t, time.Second, options.MaxDelay.Duration)
+	assert.Equal(t, [][]uint64{{1, 2}, {3}}, options.Partitions)
+
+	var decoded ChaosOptions
+	data, err := json.Marshal(options)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, options, decoded)
+}
@@ -63,9 +63,10 @@ func chunkKey(c metapb.SnapshotChunk) string {
 }
 
 type tracked struct {
-	first metapb.SnapshotChunk
-	tick  uint64
-	next  uint64
+	first    metapb.SnapshotChunk
+	tick     uint64
+	next     uint64
+	progress *snapshotProgress
 }
 
 type ssLock struct {
@@ -90,6 +91,9 @@ type Chunk struct {
 	tick      uint64
 	gcTick    uint64
 
+	// remote is the storage the remote snapshots are downloaded from
+	remote snapshot.SnapshotStorage
+
 	mu struct {
 		sync.Mutex
 		tracked map[string]*tracked
@@ -222,8 +226,9 @@ func (c *Chunk) record(chunk metapb.SnapshotChunk) *tracked {
 		}
 		// add the first chunk to the tracked map
 		td = &tracked{
-			next:  1,
-			first: chunk,
+			next:     1,
+			first:    chunk,
+			progress: newReceivingProgress(chunk),
 		}
 		c.mu.tracked[key] = td
 	} else {
@@ -275,11 +280,23 @@ func (c *Chunk) addLocked(chunk metapb.SnapshotChunk) bool {
 			zap.String("key", key))
 		return false
 	}
-	if err := c.save(chunk); err != nil {
+	if isRemoteSnapshotChunk(chunk) {
+		if err := c.downloadRemoteSnapshot(chunk, td.progress); err != nil {
+			c.removeTempDir(chunk)
+			c.logger.Error("failed to download remote snapshot",
+				zap.String("key", key),
+				zap.Error(err))
+			c.reset(key)
+			return false
+		}
+		defer c.removeRemoteSnapshot(chunk)
+	} else if err := c.save(chunk); err != nil {
 		c.removeTempDir(chunk)
 		c.logger.Fatal("failed to save chunk",
 			zap.String("key", key),
 			zap.Error(err))
+	} else {
+		td.progress.add(uint64(len(chunk.Data)))
 	}
 	if chunk.IsLastChunk() {
 		c.logger.Debug("last snapshot chunk received",
@@ -374,8 +391,11 @@ func (c *Chunk) toMessage(chunk metapb.SnapshotChunk) metapb.RaftMessageBatch {
 	if chunk.ChunkID != 0 {
 		panic("not the first snapshot chunk")
 	}
+	var sent metapb.SnapshotInfo
+	protoc.MustUnmarshal(&sent, chunk.Extra)
 	si := &metapb.SnapshotInfo{
-		Extra: chunk.From,
+		Extra:     chunk.From,
+		DeltaBase: sent.DeltaBase,
 	}
 	s := raftpb.Snapshot{
 		Metadata: raftpb.SnapshotMetadata{
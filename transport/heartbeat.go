@@ -0,0 +1,192 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transport
+
+import (
+	"sync"
+	"time"
+
+	"go.etcd.io/etcd/raft/v3/raftpb"
+
+	"github.com/matrixorigin/matrixcube/pb/metapb"
+)
+
+// canCoalesce returns true if the message can be sent as a coalesced
+// heartbeat. The heartbeats creating the replica on the target store and the
+// heartbeats carrying the ReadIndex context are sent as is, the former need
+// the shard range and the latter are latency sensitive.
+func canCoalesce(m metapb.RaftMessage) bool {
+	if m.IsTombstone || len(m.Message.Context) > 0 {
+		return false
+	}
+	switch m.Message.Type {
+	case raftpb.MsgHeartbeat:
+		return m.Message.Commit != 0
+	case raftpb.MsgHeartbeatResp:
+		return true
+	}
+	return false
+}
+
+func toHeartbeat(m metapb.RaftMessage) metapb.RaftHeartbeat {
+	return metapb.RaftHeartbeat{
+		ShardID:       m.ShardID,
+		Group:         m.Group,
+		From:          m.From,
+		To:            m.To,
+		Response:      m.Message.Type == raftpb.MsgHeartbeatResp,
+		Term:          m.Message.Term,
+		Commit:        m.Message.Commit,
+		ShardEpoch:    m.ShardEpoch,
+		CommitIndex:   m.CommitIndex,
+		AppliedIndex:  m.AppliedIndex,
+		ResolvedTS:    m.ResolvedTS,
+		ResolvedIndex: m.ResolvedIndex,
+		SendTime:      m.SendTime,
+	}
+}
+
+func fromHeartbeat(hb metapb.RaftHeartbeat) metapb.RaftMessage {
+	msgType := raftpb.MsgHeartbeat
+	if hb.Response {
+		msgType = raftpb.MsgHeartbeatResp
+	}
+	return metapb.RaftMessage{
+		ShardID:    hb.ShardID,
+		Group:      hb.Group,
+		From:       hb.From,
+		To:         hb.To,
+		ShardEpoch: hb.ShardEpoch,
+		Message: raftpb.Message{
+			Type:   msgType,
+			From:   hb.From.ID,
+			To:     hb.To.ID,
+			Term:   hb.Term,
+			Commit: hb.Commit,
+		},
+		CommitIndex:   hb.CommitIndex,
+		AppliedIndex:  hb.AppliedIndex,
+		ResolvedTS:    hb.ResolvedTS,
+		ResolvedIndex: hb.ResolvedIndex,
+		SendTime:      hb.SendTime,
+	}
+}
+
+// expandHeartbeats returns the batch with the coalesced heartbeats expanded
+// back into the per shard raft messages.
+func expandHeartbeats(batch metapb.RaftMessageBatch) metapb.RaftMessageBatch {
+	if len(batch.Heartbeats) == 0 {
+		return batch
+	}
+	for _, hb := range batch.Heartbeats {
+		batch.Messages = append(batch.Messages, fromHeartbeat(hb))
+	}
+	batch.Heartbeats = nil
+	return batch
+}
+
+// heartbeatQueue buffers the heartbeats sent to a target store, the buffered
+// heartbeats are sent as a single message batch once flushed. Only the latest
+// heartbeat between two replicas is kept.
+type heartbeatQueue struct {
+	sync.Mutex
+	heartbeats []metapb.RaftHeartbeat
+	index      map[nodeInfo]int
+	// flushC is received by the sending worker of the target store
+	flushC chan []metapb.RaftHeartbeat
+}
+
+func newHeartbeatQueue() *heartbeatQueue {
+	return &heartbeatQueue{
+		index:  make(map[nodeInfo]int),
+		flushC: make(chan []metapb.RaftHeartbeat, 1),
+	}
+}
+
+func (q *heartbeatQueue) add(m metapb.RaftMessage) {
+	q.Lock()
+	defer q.Unlock()
+	key := nodeInfo{ShardID: m.ShardID, ReplicaID: m.To.ID}
+	if idx, ok := q.index[key]; ok {
+		q.heartbeats[idx] = toHeartbeat(m)
+		return
+	}
+	q.index[key] = len(q.heartbeats)
+	q.heartbeats = append(q.heartbeats, toHeartbeat(m))
+}
+
+// flush hands the buffered heartbeats over to the sending worker, the
+// heartbeats are kept and merged with the later ones if the worker is busy.
+func (q *heartbeatQueue) flush() {
+	q.Lock()
+	defer q.Unlock()
+	if len(q.heartbeats) == 0 {
+		return
+	}
+	select {
+	case q.flushC <- q.heartbeats:
+		q.heartbeats = nil
+		q.index = make(map[nodeInfo]int)
+	default:
+	}
+}
+
+// SetHeartbeatCoalesceInterval enables the coalescing of the raft heartbeats,
+// the heartbeats of all the shards shared with a target store are buffered
+// and sent as a single message batch every interval, 0 means disabled. It must
+// be called before Start.
+func (t *Transport) SetHeartbeatCoalesceInterval(interval time.Duration) {
+	t.heartbeatInterval = interval
+}
+
+func (t *Transport) coalesceHeartbeatsEnabled() bool {
+	return t.heartbeatInterval > 0
+}
+
+func (t *Transport) startHeartbeatFlusher() {
+	if !t.coalesceHeartbeatsEnabled() {
+		return
+	}
+	t.stopper.RunWorker(func() {
+		ticker := time.NewTicker(t.heartbeatInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				t.flushHeartbeats()
+			case <-t.stopper.ShouldStop():
+				return
+			}
+		}
+	})
+}
+
+func (t *Transport) flushHeartbeats() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, q := range t.mu.heartbeats {
+		q.flush()
+	}
+}
+
+// expandHeartbeatsHandler wraps the handler to expand the coalesced heartbeats
+// of the received message batches.
+func expandHeartbeatsHandler(handler MessageHandler) MessageHandler {
+	if handler == nil {
+		return nil
+	}
+	return func(batch metapb.RaftMessageBatch) {
+		handler(expandHeartbeats(batch))
+	}
+}
@@ -0,0 +1,163 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transport
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"go.etcd.io/etcd/raft/v3/raftpb"
+
+	"github.com/matrixorigin/matrixcube/pb/metapb"
+	"github.com/matrixorigin/matrixcube/util/leaktest"
+	"github.com/matrixorigin/matrixcube/vfs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type capturedBatches struct {
+	sync.Mutex
+	batches []metapb.RaftMessageBatch
+}
+
+func (c *capturedBatches) get() []metapb.RaftMessageBatch {
+	c.Lock()
+	defer c.Unlock()
+	return append([]metapb.RaftMessageBatch(nil), c.batches...)
+}
+
+type captureConnection struct {
+	c *capturedBatches
+}
+
+func (c *captureConnection) Close() {}
+
+func (c *captureConnection) SendMessageBatch(batch metapb.RaftMessageBatch) error {
+	c.c.Lock()
+	defer c.c.Unlock()
+	// the messages are reused by the transport once sent
+	batch.Messages = append([]metapb.RaftMessage(nil), batch.Messages...)
+	c.c.batches = append(c.c.batches, batch)
+	return nil
+}
+
+type captureTransport struct {
+	NOOPTransport
+	c *capturedBatches
+}
+
+func (g *captureTransport) GetConnection(ctx context.Context,
+	target string) (Connection, error) {
+	return &captureConnection{c: g.c}, nil
+}
+
+func newTestHeartbeat(shardID uint64, commit uint64) metapb.RaftMessage {
+	return metapb.RaftMessage{
+		ShardID: shardID,
+		From:    metapb.Replica{ID: shardID*10 + 1, StoreID: 1},
+		To:      metapb.Replica{ID: shardID*10 + 2, StoreID: 2},
+		Message: raftpb.Message{
+			Type:   raftpb.MsgHeartbeat,
+			From:   shardID*10 + 1,
+			To:     shardID*10 + 2,
+			Term:   2,
+			Commit: commit,
+		},
+		CommitIndex:  commit,
+		AppliedIndex: commit,
+	}
+}
+
+func TestCanCoalesce(t *testing.T) {
+	assert.True(t, canCoalesce(newTestHeartbeat(1, 10)))
+	assert.False(t, canCoalesce(newTestHeartbeat(1, 0)))
+
+	m := newTestHeartbeat(1, 10)
+	m.Message.Context = []byte("read-index")
+	assert.False(t, canCoalesce(m))
+
+	m = newTestHeartbeat(1, 10)
+	m.IsTombstone = true
+	assert.False(t, canCoalesce(m))
+
+	m = newTestHeartbeat(1, 0)
+	m.Message.Type = raftpb.MsgHeartbeatResp
+	assert.True(t, canCoalesce(m))
+	m.Message.Type = raftpb.MsgApp
+	assert.False(t, canCoalesce(m))
+}
+
+func TestExpandHeartbeats(t *testing.T) {
+	m := newTestHeartbeat(1, 10)
+	m.ResolvedTS = 100
+	m.ResolvedIndex = 9
+	resp := newTestHeartbeat(2, 0)
+	resp.Message.Type = raftpb.MsgHeartbeatResp
+
+	batch := expandHeartbeats(metapb.RaftMessageBatch{
+		Messages:   []metapb.RaftMessage{{ShardID: 3}},
+		Heartbeats: []metapb.RaftHeartbeat{toHeartbeat(m), toHeartbeat(resp)},
+	})
+	assert.Empty(t, batch.Heartbeats)
+	require.Equal(t, 3, len(batch.Messages))
+	assert.Equal(t, uint64(3), batch.Messages[0].ShardID)
+	assert.Equal(t, m, batch.Messages[1])
+	assert.Equal(t, resp, batch.Messages[2])
+}
+
+func TestCoalesceHeartbeats(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	fs := vfs.GetTestFS()
+	defer vfs.ReportLeakedFD(fs, t)
+
+	trans := NewTransport(nil, testTransportAddr, 1,
+		nil, nil, nil,
+		getTestSnapshotDir, func(storeID uint64) (string, error) { return "127.0.0.1:12345", nil }, fs)
+	c := &capturedBatches{}
+	trans.trans = &captureTransport{c: c}
+	// the heartbeats are flushed by the test
+	trans.SetHeartbeatCoalesceInterval(time.Hour)
+	require.NoError(t, trans.Start())
+	defer trans.Close()
+
+	app := newTestHeartbeat(1, 10)
+	app.Message.Type = raftpb.MsgApp
+	assert.True(t, trans.Send(app))
+	assert.True(t, trans.Send(newTestHeartbeat(1, 10)))
+	assert.True(t, trans.Send(newTestHeartbeat(2, 10)))
+	assert.True(t, trans.Send(newTestHeartbeat(1, 11)))
+	trans.flushHeartbeats()
+
+	var heartbeats []metapb.RaftHeartbeat
+	var messages []metapb.RaftMessage
+	for i := 0; i < 100; i++ {
+		heartbeats, messages = nil, nil
+		for _, batch := range c.get() {
+			heartbeats = append(heartbeats, batch.Heartbeats...)
+			messages = append(messages, batch.Messages...)
+		}
+		if len(heartbeats) > 0 && len(messages) > 0 {
+			break
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+	require.Equal(t, 1, len(messages))
+	assert.Equal(t, raftpb.MsgApp, messages[0].Message.Type)
+	require.Equal(t, 2, len(heartbeats))
+	assert.Equal(t, uint64(1), heartbeats[0].ShardID)
+	assert.Equal(t, uint64(11), heartbeats[0].Commit)
+	assert.Equal(t, uint64(2), heartbeats[1].ShardID)
+}
@@ -31,11 +31,13 @@ package transport
 
 import (
 	"context"
+	"time"
 
 	"github.com/cockroachdb/errors"
 	"github.com/fagongzi/util/protoc"
 	"go.uber.org/zap"
 
+	"github.com/matrixorigin/matrixcube/metric"
 	"github.com/matrixorigin/matrixcube/pb/metapb"
 	"github.com/matrixorigin/matrixcube/snapshot"
 	"github.com/matrixorigin/matrixcube/vfs"
@@ -61,6 +63,13 @@ type job struct {
 	shardID           uint64
 	replicaID         uint64
 	snapshotChunkSize uint64
+	// addr and limiter are used to limit the bandwidth of sending the chunks to
+	// the target, no limit if the limiter is nil
+	addr    string
+	limiter *snapshotLimiter
+	// progress is the progress of sending the snapshot, the chunks of the remote
+	// snapshot are not counted as the image is uploaded instead
+	progress *snapshotProgress
 }
 
 func newJob(logger *zap.Logger,
@@ -138,6 +147,8 @@ func (j *job) sendSnapshot() error {
 }
 
 func (j *job) sendChunks(chunks []metapb.SnapshotChunk) error {
+	start := time.Now()
+	size := int64(0)
 	chunkData := make([]byte, j.snapshotChunkSize)
 	for _, chunk := range chunks {
 		select {
@@ -145,18 +156,34 @@ func (j *job) sendChunks(chunks []metapb.SnapshotChunk) error {
 			return ErrStopped
 		default:
 		}
-		env := j.getEnv(chunk)
-		data, err := loadChunkData(chunk,
-			env.GetFinalDir(), chunkData, j.snapshotChunkSize, j.fs)
-		if err != nil {
-			j.logger.Fatal("failed to load chunk data",
-				zap.Error(err))
+		// the chunk of the remote snapshot contains the manifest only
+		data := chunk.Data
+		remote := isRemoteSnapshotChunk(chunk)
+		if !remote {
+			env := j.getEnv(chunk)
+			var err error
+			data, err = loadChunkData(chunk,
+				env.GetFinalDir(), chunkData, j.snapshotChunkSize, j.fs)
+			if err != nil {
+				j.logger.Fatal("failed to load chunk data",
+					zap.Error(err))
+			}
+			chunk.Data = data
+		}
+		if j.limiter != nil &&
+			!j.limiter.wait(j.addr, int64(len(data)), j.stopc) {
+			return ErrStopped
 		}
-		chunk.Data = data
 		if err := j.conn.SendChunk(chunk); err != nil {
 			return err
 		}
+		if j.progress != nil && !remote {
+			j.progress.add(uint64(len(data)))
+		}
+		size += int64(len(data))
 	}
+	metric.ObserveSnapshotBytes(size)
+	metric.ObserveSnapshotSendingDuration(start)
 	return nil
 }
 
@@ -63,6 +63,9 @@ func (t *Transport) sendSnapshot(m metapb.RaftMessage) bool {
 	if m.Message.Type != raftpb.MsgSnap {
 		panic("not a snapshot message")
 	}
+	if t.remote != nil {
+		return t.sendRemoteSnapshot(m)
+	}
 	env := t.getEnv(m)
 	chunks, err := splitSnapshotMessage(m,
 		env.GetFinalDir(), defaultSnapshotChunkSize, t.fs)
@@ -87,7 +90,9 @@ func (t *Transport) sendSnapshot(m metapb.RaftMessage) bool {
 	if job == nil {
 		return false
 	}
+	job.progress = t.trackSending(m, chunks[0].SnapshotSize)
 	shutdown := func() {
+		t.untrackSending(job.progress)
 		atomic.AddUint64(&t.jobs, ^uint64(0))
 	}
 	t.stopper.RunWorker(func() {
@@ -116,8 +121,39 @@ func (t *Transport) createJob(shardID uint64, toReplicaID uint64,
 			zap.Uint64("job-count", r))
 		return nil
 	}
-	return newJob(t.logger, t.ctx, shardID, toReplicaID,
+	j := newJob(t.logger, t.ctx, shardID, toReplicaID,
 		sz, t.trans, t.dir, t.stopper.ShouldStop(), defaultSnapshotChunkSize, t.fs)
+	j.addr = addr
+	j.limiter = t.getSnapshotLimiter()
+	return j
+}
+
+// SetSnapshotRateLimit sets the max bandwidth in bytes per second used for
+// sending the snapshots, global limits the total bandwidth of the store and
+// perStore limits the bandwidth to each target store, 0 means unlimited. The
+// snapshots already being sent are not affected.
+func (t *Transport) SetSnapshotRateLimit(global, perStore uint64) {
+	if global == 0 && perStore == 0 {
+		t.snapshotLimiter.Store((*snapshotLimiter)(nil))
+		return
+	}
+	t.snapshotLimiter.Store(newSnapshotLimiter(global, perStore))
+}
+
+// SnapshotThrottled returns true if the snapshot sending is being throttled by
+// the rate limit, or was throttled since the last call.
+func (t *Transport) SnapshotThrottled() bool {
+	if l := t.getSnapshotLimiter(); l != nil {
+		return l.isThrottled()
+	}
+	return false
+}
+
+func (t *Transport) getSnapshotLimiter() *snapshotLimiter {
+	if v := t.snapshotLimiter.Load(); v != nil {
+		return v.(*snapshotLimiter)
+	}
+	return nil
 }
 
 func (t *Transport) processSnapshot(c *job, ss raftpb.Snapshot, addr string) {
@@ -165,7 +201,18 @@ func splitSnapshotMessage(m metapb.RaftMessage,
 	if m.Message.Type != raftpb.MsgSnap {
 		panic("not a snapshot message")
 	}
-	return getChunks(m, snapshotDir, "", chunkSize, fs)
+	chunks, err := getChunks(m, snapshotDir, "", chunkSize, fs)
+	if err != nil {
+		return nil, err
+	}
+	size := uint64(0)
+	for _, chunk := range chunks {
+		size += chunk.ChunkSize
+	}
+	for idx := range chunks {
+		chunks[idx].SnapshotSize = size
+	}
+	return chunks, nil
 }
 
 func getChunks(m metapb.RaftMessage,
@@ -233,6 +280,7 @@ func splitBySnapshotFile(msg metapb.RaftMessage,
 			ShardID:        msg.ShardID,
 			ReplicaID:      msg.To.ID,
 			From:           msg.From.ID,
+			FromStoreID:    msg.From.StoreID,
 			FileChunkID:    i,
 			FileChunkCount: chunkCount,
 			ChunkID:        startChunkID + i,
@@ -0,0 +1,107 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transport
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/juju/ratelimit"
+)
+
+// snapshotLimiter limits the bandwidth used for sending the snapshot chunks,
+// both the total bandwidth of the store and the bandwidth to each target store
+// are limited.
+type snapshotLimiter struct {
+	global   *ratelimit.Bucket
+	perStore int64
+	// waiting the number of the chunks waiting for the bandwidth
+	waiting int64
+	// throttled is set once a chunk is throttled, and cleared once reported
+	throttled uint32
+
+	mu struct {
+		sync.Mutex
+		stores map[string]*ratelimit.Bucket
+	}
+}
+
+// newSnapshotLimiter returns a snapshotLimiter with the rates in bytes per
+// second, 0 means unlimited.
+func newSnapshotLimiter(global, perStore uint64) *snapshotLimiter {
+	l := &snapshotLimiter{perStore: int64(perStore)}
+	if global > 0 {
+		l.global = newRateBucket(int64(global))
+	}
+	l.mu.stores = make(map[string]*ratelimit.Bucket)
+	return l
+}
+
+// newRateBucket returns a bucket with the rate in bytes per second, at most 1
+// second of bandwidth can be burst.
+func newRateBucket(rate int64) *ratelimit.Bucket {
+	return ratelimit.NewBucketWithRate(float64(rate), rate)
+}
+
+// wait blocks until the bandwidth for sending n bytes to the target addr is
+// available, false is returned if the stopc is closed while waiting.
+func (l *snapshotLimiter) wait(addr string, n int64, stopc chan struct{}) bool {
+	var d time.Duration
+	if b := l.getStoreBucket(addr); b != nil {
+		d = b.Take(n)
+	}
+	if l.global != nil {
+		if gd := l.global.Take(n); gd > d {
+			d = gd
+		}
+	}
+	if d <= 0 {
+		return true
+	}
+
+	atomic.StoreUint32(&l.throttled, 1)
+	atomic.AddInt64(&l.waiting, 1)
+	defer atomic.AddInt64(&l.waiting, -1)
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-stopc:
+		return false
+	}
+}
+
+func (l *snapshotLimiter) getStoreBucket(addr string) *ratelimit.Bucket {
+	if l.perStore == 0 {
+		return nil
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	b, ok := l.mu.stores[addr]
+	if !ok {
+		b = newRateBucket(l.perStore)
+		l.mu.stores[addr] = b
+	}
+	return b
+}
+
+// isThrottled returns true if any chunk is waiting for the bandwidth, or was
+// throttled since the last call.
+func (l *snapshotLimiter) isThrottled() bool {
+	return atomic.SwapUint32(&l.throttled, 0) == 1 ||
+		atomic.LoadInt64(&l.waiting) > 0
+}
@@ -0,0 +1,78 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transport
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/matrixorigin/matrixcube/util/leaktest"
+)
+
+func TestSnapshotLimiterPerStore(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	l := newSnapshotLimiter(0, 1000)
+	stopc := make(chan struct{})
+	// the burst of the first second is not throttled
+	assert.True(t, l.wait("a1", 1000, stopc))
+	assert.True(t, l.wait("a2", 1000, stopc))
+	assert.False(t, l.isThrottled())
+
+	st := time.Now()
+	assert.True(t, l.wait("a1", 100, stopc))
+	assert.True(t, time.Since(st) >= time.Millisecond*50)
+	assert.True(t, l.isThrottled())
+	assert.False(t, l.isThrottled())
+}
+
+func TestSnapshotLimiterGlobal(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	l := newSnapshotLimiter(1000, 0)
+	stopc := make(chan struct{})
+	assert.True(t, l.wait("a1", 1000, stopc))
+	assert.False(t, l.isThrottled())
+
+	st := time.Now()
+	assert.True(t, l.wait("a2", 100, stopc))
+	assert.True(t, time.Since(st) >= time.Millisecond*50)
+	assert.True(t, l.isThrottled())
+}
+
+func TestSnapshotLimiterCanBeStopped(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	l := newSnapshotLimiter(1, 0)
+	stopc := make(chan struct{})
+	close(stopc)
+	assert.True(t, l.wait("a1", 1, stopc))
+	assert.False(t, l.wait("a1", 1000, stopc))
+	assert.Equal(t, int64(0), l.waiting)
+}
+
+func TestTransportSnapshotRateLimit(t *testing.T) {
+	trans := &Transport{}
+	assert.Nil(t, trans.getSnapshotLimiter())
+	assert.False(t, trans.SnapshotThrottled())
+
+	trans.SetSnapshotRateLimit(100, 0)
+	assert.NotNil(t, trans.getSnapshotLimiter())
+
+	trans.SetSnapshotRateLimit(0, 0)
+	assert.Nil(t, trans.getSnapshotLimiter())
+	assert.False(t, trans.SnapshotThrottled())
+}
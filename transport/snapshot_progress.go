@@ -0,0 +1,110 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transport
+
+import (
+	"sort"
+	"sync/atomic"
+	"time"
+
+	"github.com/matrixorigin/matrixcube/pb/metapb"
+)
+
+// snapshotProgress is the progress of a snapshot being sent or received
+type snapshotProgress struct {
+	// transferred is the bytes sent or received so far
+	transferred uint64
+	info        metapb.SnapshotProgress
+	startedAt   time.Time
+}
+
+func newSnapshotProgress(info metapb.SnapshotProgress) *snapshotProgress {
+	return &snapshotProgress{info: info, startedAt: time.Now()}
+}
+
+func (p *snapshotProgress) add(n uint64) {
+	atomic.AddUint64(&p.transferred, n)
+}
+
+// get returns the progress with the average rate since the transfer started
+// and the ETA estimated by the rate.
+func (p *snapshotProgress) get(now time.Time) metapb.SnapshotProgress {
+	info := p.info
+	info.TransferredBytes = atomic.LoadUint64(&p.transferred)
+	info.StartTime = p.startedAt.Unix()
+	if elapsed := now.Sub(p.startedAt); elapsed > 0 {
+		info.Rate = uint64(float64(info.TransferredBytes) / elapsed.Seconds())
+	}
+	if info.Rate > 0 && info.TotalBytes > info.TransferredBytes {
+		info.ETA = (info.TotalBytes - info.TransferredBytes + info.Rate - 1) / info.Rate
+	}
+	return info
+}
+
+// SnapshotProgresses returns the progresses of the snapshots being sent and
+// received by the store.
+func (t *Transport) SnapshotProgresses() []metapb.SnapshotProgress {
+	now := time.Now()
+	var progresses []metapb.SnapshotProgress
+	t.sending.Range(func(key, value interface{}) bool {
+		progresses = append(progresses, key.(*snapshotProgress).get(now))
+		return true
+	})
+	progresses = append(progresses, t.chunks.progresses(now)...)
+	sort.Slice(progresses, func(i, j int) bool {
+		if progresses[i].ShardID != progresses[j].ShardID {
+			return progresses[i].ShardID < progresses[j].ShardID
+		}
+		return progresses[i].Sending && !progresses[j].Sending
+	})
+	return progresses
+}
+
+func (t *Transport) trackSending(m metapb.RaftMessage, total uint64) *snapshotProgress {
+	p := newSnapshotProgress(metapb.SnapshotProgress{
+		StoreID:    t.storeID,
+		ShardID:    m.ShardID,
+		From:       m.From,
+		To:         m.To,
+		Index:      m.Message.Snapshot.Metadata.Index,
+		Sending:    true,
+		TotalBytes: total,
+	})
+	t.sending.Store(p, struct{}{})
+	return p
+}
+
+func (t *Transport) untrackSending(p *snapshotProgress) {
+	t.sending.Delete(p)
+}
+
+// progresses returns the progresses of the snapshots being received.
+func (c *Chunk) progresses(now time.Time) []metapb.SnapshotProgress {
+	var progresses []metapb.SnapshotProgress
+	for _, td := range c.getTracked() {
+		progresses = append(progresses, td.progress.get(now))
+	}
+	return progresses
+}
+
+func newReceivingProgress(chunk metapb.SnapshotChunk) *snapshotProgress {
+	return newSnapshotProgress(metapb.SnapshotProgress{
+		StoreID:    chunk.StoreID,
+		ShardID:    chunk.ShardID,
+		From:       metapb.Replica{ID: chunk.From, StoreID: chunk.FromStoreID},
+		To:         metapb.Replica{ID: chunk.ReplicaID, StoreID: chunk.StoreID},
+		Index:      chunk.Index,
+		TotalBytes: chunk.SnapshotSize,
+	})
+}
@@ -0,0 +1,296 @@
+// Copyright 2021 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transport
+
+import (
+	"fmt"
+	"io"
+	"sync/atomic"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/fagongzi/util/protoc"
+	"go.uber.org/zap"
+
+	"github.com/matrixorigin/matrixcube/components/log"
+	"github.com/matrixorigin/matrixcube/pb/metapb"
+	"github.com/matrixorigin/matrixcube/snapshot"
+	"github.com/matrixorigin/matrixcube/vfs"
+)
+
+var (
+	remoteSnapshotGCInterval = time.Minute
+)
+
+// SetSnapshotStorage makes the snapshots transferred through the remote
+// snapshot storage, the snapshot images are uploaded to it and only the
+// manifests are sent to the target stores. The uploaded images are removed by
+// the receivers once downloaded, and the ones not downloaded are removed by the
+// sender after the retention. It must be called before Start.
+func (t *Transport) SetSnapshotStorage(storage snapshot.SnapshotStorage, retention time.Duration) {
+	t.remote = storage
+	t.remoteRetention = retention
+	t.chunks.remote = storage
+}
+
+// sendRemoteSnapshot uploads the snapshot image to the remote snapshot storage
+// and sends the manifest of the image to the target.
+func (t *Transport) sendRemoteSnapshot(m metapb.RaftMessage) bool {
+	env := t.getEnv(m)
+	dir := env.GetFinalDir()
+	files, err := listSnapshotFiles(dir, "", t.fs)
+	if err != nil {
+		t.logger.Error("failed to list snapshot files",
+			zap.Error(err))
+		return false
+	}
+	targetInfo, resolved := t.resolve(m.To.StoreID, m.ShardID)
+	if !resolved {
+		return false
+	}
+
+	// fail fast
+	if !t.getCircuitBreaker(targetInfo.addr).Ready() {
+		return false
+	}
+
+	job := t.createJob(m.ShardID, m.To.ID, targetInfo.addr, false, 1)
+	if job == nil {
+		return false
+	}
+	total := uint64(0)
+	for _, file := range files {
+		total += file.FileSize
+	}
+	job.progress = t.trackSending(m, total)
+	t.stopper.RunWorker(func() {
+		defer atomic.AddUint64(&t.jobs, ^uint64(0))
+		defer t.untrackSending(job.progress)
+		chunk, err := t.uploadSnapshot(m, dir, files, job.progress)
+		if err != nil {
+			t.logger.Error("failed to upload snapshot",
+				log.RaftMessageField("message", m),
+				zap.Error(err))
+			t.sendSnapshotNotification(m.ShardID, m.To.ID, m.Message.Snapshot, true)
+			return
+		}
+		job.addSnapshot([]metapb.SnapshotChunk{chunk})
+		t.processSnapshot(job, m.Message.Snapshot, targetInfo.addr)
+	})
+	return true
+}
+
+// uploadSnapshot uploads the files of the snapshot image, returns the chunk
+// containing the manifest of the uploaded image.
+func (t *Transport) uploadSnapshot(m metapb.RaftMessage, dir string,
+	files []metapb.SnapshotFile, progress *snapshotProgress) (metapb.SnapshotChunk, error) {
+	ss := m.Message.Snapshot
+	manifest := metapb.SnapshotManifest{
+		Prefix: fmt.Sprintf("%d/%d-%d-%d-%d-%d", t.storeID, m.ShardID,
+			m.From.ID, m.To.ID, ss.Metadata.Index, time.Now().UnixNano()),
+		Files: files,
+	}
+	for _, file := range files {
+		if err := t.uploadSnapshotFile(t.fs.PathJoin(dir, file.Path),
+			remoteSnapshotFileKey(manifest, file)); err != nil {
+			return metapb.SnapshotChunk{}, err
+		}
+		progress.add(file.FileSize)
+	}
+
+	si := metapb.SnapshotInfo{}
+	protoc.MustUnmarshal(&si, ss.Data)
+	si.Remote = true
+	data := protoc.MustMarshal(&manifest)
+	return metapb.SnapshotChunk{
+		StoreID:        m.To.StoreID,
+		FromStoreID:    m.From.StoreID,
+		ShardID:        m.ShardID,
+		ReplicaID:      m.To.ID,
+		From:           m.From.ID,
+		ChunkCount:     1,
+		ChunkSize:      uint64(len(data)),
+		FileChunkCount: 1,
+		FileSize:       uint64(len(data)),
+		Index:          ss.Metadata.Index,
+		Term:           ss.Metadata.Term,
+		ConfState:      ss.Metadata.ConfState,
+		Extra:          protoc.MustMarshal(&si),
+		Data:           data,
+		SnapshotSize:   progress.info.TotalBytes,
+	}, nil
+}
+
+func (t *Transport) uploadSnapshotFile(fp string, key string) error {
+	f, err := t.fs.Open(fp)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return t.remote.Upload(key, f)
+}
+
+func (t *Transport) startRemoteSnapshotGC() {
+	if t.remote == nil {
+		return
+	}
+	t.stopper.RunWorker(func() {
+		ticker := time.NewTicker(remoteSnapshotGCInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				t.gcRemoteSnapshots(time.Now())
+			case <-t.stopper.ShouldStop():
+				return
+			}
+		}
+	})
+}
+
+// gcRemoteSnapshots removes the snapshot images uploaded by the store but not
+// downloaded by the target within the retention.
+func (t *Transport) gcRemoteSnapshots(now time.Time) {
+	objects, err := t.remote.List(fmt.Sprintf("%d/", t.storeID))
+	if err != nil {
+		t.logger.Error("failed to list remote snapshots",
+			zap.Error(err))
+		return
+	}
+	for _, o := range objects {
+		if now.Sub(o.LastModified) < t.remoteRetention {
+			continue
+		}
+		if err := t.remote.Delete(o.Key); err != nil {
+			t.logger.Error("failed to remove expired remote snapshot",
+				zap.String("key", o.Key),
+				zap.Error(err))
+			return
+		}
+		t.logger.Debug("expired remote snapshot removed",
+			zap.String("key", o.Key))
+	}
+}
+
+// downloadRemoteSnapshot downloads the files of the snapshot image in the
+// manifest to the temp dir of the snapshot.
+func (c *Chunk) downloadRemoteSnapshot(chunk metapb.SnapshotChunk,
+	progress *snapshotProgress) error {
+	if c.remote == nil {
+		return errors.New("snapshot storage not set")
+	}
+	env := c.getEnv(chunk)
+	if err := env.CreateTempDir(); err != nil {
+		return err
+	}
+	manifest := metapb.SnapshotManifest{}
+	protoc.MustUnmarshal(&manifest, chunk.Data)
+	for _, file := range manifest.Files {
+		fp := c.fs.PathJoin(env.GetTempDir(), file.Path)
+		if err := c.fs.MkdirAll(c.fs.PathDir(fp), 0755); err != nil {
+			return err
+		}
+		if err := c.downloadRemoteSnapshotFile(remoteSnapshotFileKey(manifest, file),
+			fp, file.FileSize); err != nil {
+			return err
+		}
+		progress.add(file.FileSize)
+	}
+	return nil
+}
+
+func (c *Chunk) downloadRemoteSnapshotFile(key string, fp string, size uint64) (err error) {
+	r, err := c.remote.Download(key)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = firstError(err, r.Close())
+	}()
+	f, err := c.fs.Create(fp)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = firstError(err, f.Close())
+	}()
+	n, err := io.Copy(f, r)
+	if err != nil {
+		return err
+	}
+	if uint64(n) != size {
+		return fmt.Errorf("remote snapshot file %s size mismatch, expect %d, but %d",
+			key, size, n)
+	}
+	return f.Sync()
+}
+
+// removeRemoteSnapshot removes the snapshot image in the manifest from the
+// remote snapshot storage.
+func (c *Chunk) removeRemoteSnapshot(chunk metapb.SnapshotChunk) {
+	manifest := metapb.SnapshotManifest{}
+	protoc.MustUnmarshal(&manifest, chunk.Data)
+	for _, file := range manifest.Files {
+		key := remoteSnapshotFileKey(manifest, file)
+		if err := c.remote.Delete(key); err != nil {
+			c.logger.Warn("failed to remove remote snapshot, removed by gc later",
+				zap.String("key", key),
+				zap.Error(err))
+			return
+		}
+	}
+}
+
+func isRemoteSnapshotChunk(chunk metapb.SnapshotChunk) bool {
+	si := metapb.SnapshotInfo{}
+	protoc.MustUnmarshal(&si, chunk.Extra)
+	return si.Remote
+}
+
+func remoteSnapshotFileKey(manifest metapb.SnapshotManifest, file metapb.SnapshotFile) string {
+	return manifest.Prefix + "/" + file.Path
+}
+
+// listSnapshotFiles returns the files in the snapshot dir, the path of each
+// file is relative to the snapshot dir.
+func listSnapshotFiles(snapshotDir string, checkDir string,
+	fs vfs.FS) ([]metapb.SnapshotFile, error) {
+	dir := snapshotDir
+	if len(checkDir) > 0 {
+		dir = fs.PathJoin(snapshotDir, checkDir)
+	}
+	names, err := fs.List(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []metapb.SnapshotFile
+	for _, name := range names {
+		fi, err := fs.Stat(fs.PathJoin(dir, name))
+		if err != nil {
+			return nil, err
+		}
+		path := fs.PathJoin(checkDir, name)
+		if fi.IsDir() {
+			sub, err := listSnapshotFiles(snapshotDir, path, fs)
+			if err != nil {
+				return nil, err
+			}
+			files = append(files, sub...)
+			continue
+		}
+		files = append(files, metapb.SnapshotFile{Path: path, FileSize: uint64(fi.Size())})
+	}
+	return files, nil
+}
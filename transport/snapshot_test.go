@@ -30,6 +30,7 @@
 package transport
 
 import (
+	"bytes"
 	"crypto/rand"
 	"fmt"
 	"path/filepath"
@@ -406,3 +407,152 @@ func TestSnapshotCanBeTransported(t *testing.T) {
 	status.waitMessageCount(t, 1, 10*time.Second)
 	status.waitStatusCount(t, 1, 10*time.Second)
 }
+
+func TestRemoteSnapshotCanBeTransported(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	fs := vfs.GetTestFS()
+	defer vfs.ReportLeakedFD(fs, t)
+	require.NoError(t, fs.RemoveAll(testSnapshotDir))
+	defer func() {
+		require.NoError(t, fs.RemoveAll(testSnapshotDir))
+	}()
+	extra := uint64(12345)
+	index := uint64(100)
+	si := &metapb.SnapshotInfo{
+		Extra: extra,
+	}
+	raftMsg := metapb.RaftMessage{
+		ShardID: 1,
+		From:    metapb.Replica{ID: 1},
+		To:      metapb.Replica{ID: 2},
+		Message: raftpb.Message{
+			Type: raftpb.MsgSnap,
+			From: 1,
+			To:   2,
+			Term: 1,
+			Snapshot: raftpb.Snapshot{
+				Data: protoc.MustMarshal(si),
+				Metadata: raftpb.SnapshotMetadata{
+					Index: index,
+					Term:  1,
+				},
+			},
+		},
+	}
+
+	dir := getTestSnapshotDir(1, 2)
+	require.NoError(t, fs.MkdirAll(dir, 0755))
+
+	env := snapshot.NewSSEnv(getTestSnapshotDir, 1, 1, index, extra,
+		snapshot.CreatingMode, fs)
+	env.FinalizeIndex(index)
+	require.NoError(t, generateTestSnapshotDirWithFiles(10, 1024, env.GetFinalDir(), fs))
+	remote := snapshot.NewFSSnapshotStorage(fs.PathJoin(testSnapshotDir, "remote"), fs)
+	logger := log.GetDefaultZapLoggerWithLevel(zap.DebugLevel)
+	status := &testTransportStatus{}
+	trans := NewTransport(logger, testTransportAddr, 2,
+		status.MessageHandler, status.UnreachableHandler, status.SnapshotStatusHandler,
+		getTestSnapshotDir, testStoreResolver, fs)
+	trans.SetSnapshotStorage(remote, time.Hour)
+	require.NoError(t, trans.Start())
+	defer trans.Close()
+	assert.True(t, trans.SendSnapshot(raftMsg))
+	status.waitMessageCount(t, 1, 10*time.Second)
+	status.waitStatusCount(t, 1, 10*time.Second)
+
+	// the downloaded snapshot image is removed from the remote snapshot storage
+	objects, err := remote.List("")
+	require.NoError(t, err)
+	assert.Empty(t, objects)
+}
+
+func TestGCRemoteSnapshots(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	fs := vfs.GetTestFS()
+	defer vfs.ReportLeakedFD(fs, t)
+	require.NoError(t, fs.RemoveAll(testSnapshotDir))
+	defer func() {
+		require.NoError(t, fs.RemoveAll(testSnapshotDir))
+	}()
+
+	remote := snapshot.NewFSSnapshotStorage(testSnapshotDir, fs)
+	require.NoError(t, remote.Upload("2/1-1-2-100-1/f1", bytes.NewReader([]byte("v1"))))
+	require.NoError(t, remote.Upload("3/1-1-2-100-1/f1", bytes.NewReader([]byte("v1"))))
+
+	trans := &Transport{logger: log.GetDefaultZapLogger(), storeID: 2, chunks: &Chunk{}}
+	trans.SetSnapshotStorage(remote, time.Hour)
+	trans.gcRemoteSnapshots(time.Now())
+	objects, err := remote.List("")
+	require.NoError(t, err)
+	assert.Equal(t, 2, len(objects))
+
+	// only the expired snapshot images uploaded by the store are removed
+	trans.gcRemoteSnapshots(time.Now().Add(time.Hour))
+	objects, err = remote.List("")
+	require.NoError(t, err)
+	require.Equal(t, 1, len(objects))
+	assert.Equal(t, "3/1-1-2-100-1/f1", objects[0].Key)
+}
+
+func TestSnapshotProgress(t *testing.T) {
+	p := newSnapshotProgress(metapb.SnapshotProgress{ShardID: 1, TotalBytes: 1000})
+	p.startedAt = time.Unix(100, 0)
+	p.add(100)
+	p.add(300)
+	v := p.get(time.Unix(104, 0))
+	assert.Equal(t, uint64(400), v.TransferredBytes)
+	assert.Equal(t, int64(100), v.StartTime)
+	assert.Equal(t, uint64(100), v.Rate)
+	assert.Equal(t, uint64(6), v.ETA)
+
+	// nothing transferred yet
+	p = newSnapshotProgress(metapb.SnapshotProgress{ShardID: 1, TotalBytes: 1000})
+	v = p.get(p.startedAt)
+	assert.Equal(t, uint64(0), v.Rate)
+	assert.Equal(t, uint64(0), v.ETA)
+}
+
+func TestReceivingSnapshotProgress(t *testing.T) {
+	fs := vfs.GetTestFS()
+	defer vfs.ReportLeakedFD(fs, t)
+	require.NoError(t, generateTestSnapshotDir(testSnapshotDir, fs))
+	defer func() {
+		require.NoError(t, fs.RemoveAll(testSnapshotDir))
+	}()
+	m := metapb.RaftMessage{
+		ShardID: 1,
+		From:    metapb.Replica{ID: 1, StoreID: 1},
+		To:      metapb.Replica{ID: 2, StoreID: 2},
+		Message: raftpb.Message{
+			Type: raftpb.MsgSnap,
+			Snapshot: raftpb.Snapshot{
+				Metadata: raftpb.SnapshotMetadata{Index: 100, Term: 1},
+				Data:     protoc.MustMarshal(&metapb.SnapshotInfo{}),
+			},
+		},
+	}
+	chunks, err := splitSnapshotMessage(m, testSnapshotDir, 1000, fs)
+	require.NoError(t, err)
+	for _, chunk := range chunks {
+		assert.Equal(t, uint64(4096), chunk.SnapshotSize)
+		assert.Equal(t, uint64(1), chunk.FromStoreID)
+	}
+
+	c := NewChunk(log.GetDefaultZapLogger(), nil, getTestSnapshotDir, fs)
+	td := c.record(chunks[0])
+	require.NotNil(t, td)
+	td.progress.add(chunks[0].ChunkSize)
+	progresses := c.progresses(time.Now())
+	require.Equal(t, 1, len(progresses))
+	p := progresses[0]
+	assert.False(t, p.Sending)
+	assert.Equal(t, uint64(2), p.StoreID)
+	assert.Equal(t, metapb.Replica{ID: 1, StoreID: 1}, p.From)
+	assert.Equal(t, metapb.Replica{ID: 2, StoreID: 2}, p.To)
+	assert.Equal(t, uint64(100), p.Index)
+	assert.Equal(t, uint64(4096), p.TotalBytes)
+	assert.Equal(t, uint64(1000), p.TransferredBytes)
+
+	c.reset(chunkKey(chunks[0]))
+	assert.Empty(t, c.progresses(time.Now()))
+}
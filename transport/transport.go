@@ -61,6 +61,12 @@ type Trans interface {
 	SendSnapshot(metapb.RaftMessage) bool
 	SetFilter(func(metapb.RaftMessage) bool)
 	SendingSnapshotCount() uint64
+	// SnapshotThrottled returns true if the snapshot sending is throttled by the
+	// rate limit since the last call.
+	SnapshotThrottled() bool
+	// SnapshotProgresses returns the progresses of the snapshots being sent and
+	// received.
+	SnapshotProgresses() []metapb.SnapshotProgress
 	Start() error
 	Close() error
 }
@@ -139,8 +145,9 @@ type targetInfo struct {
 type Transport struct {
 	mu struct {
 		sync.Mutex
-		queues   map[string]chan metapb.RaftMessage
-		breakers map[string]*circuit.Breaker
+		queues     map[string]chan metapb.RaftMessage
+		heartbeats map[string]*heartbeatQueue
+		breakers   map[string]*circuit.Breaker
 	}
 	logger         *zap.Logger
 	storeID        uint64
@@ -159,6 +166,21 @@ type Transport struct {
 	addrs          sync.Map // storeID -> targetInfo
 	addrsRevert    sync.Map // addr -> storeID
 	fs             vfs.FS
+
+	// snapshotLimiter is the *snapshotLimiter limiting the bandwidth used for
+	// sending the snapshots, nil means unlimited.
+	snapshotLimiter atomic.Value
+	// heartbeatInterval is the interval of sending the coalesced heartbeats,
+	// 0 means the heartbeats are sent as regular messages.
+	heartbeatInterval time.Duration
+	// remote is the storage the snapshots are transferred through, nil means
+	// the snapshots are streamed to the target stores.
+	remote snapshot.SnapshotStorage
+	// remoteRetention is the duration the uploaded snapshots are kept if not
+	// downloaded by the target stores.
+	remoteRetention time.Duration
+	// sending is the *snapshotProgress of the snapshots being sent
+	sending sync.Map
 }
 
 func NewTransport(logger *zap.Logger, addr string,
@@ -169,7 +191,7 @@ func NewTransport(logger *zap.Logger, addr string,
 	t := &Transport{
 		logger:         log.Adjust(logger),
 		storeID:        storeID,
-		handler:        handler,
+		handler:        expandHeartbeatsHandler(handler),
 		unreachable:    unreachable,
 		snapshotStatus: snapshotStatus,
 		dir:            dir,
@@ -178,8 +200,9 @@ func NewTransport(logger *zap.Logger, addr string,
 		fs:             fs,
 	}
 	t.chunks = NewChunk(t.logger, t.handler, t.dir, fs)
-	t.trans = NewTCPTransport(logger, addr, handler, t.chunks.Add)
+	t.trans = NewTCPTransport(logger, addr, t.handler, t.chunks.Add)
 	t.mu.queues = make(map[string]chan metapb.RaftMessage)
+	t.mu.heartbeats = make(map[string]*heartbeatQueue)
 	t.mu.breakers = make(map[string]*circuit.Breaker)
 	t.ctx, t.cancel = context.WithCancel(context.Background())
 
@@ -200,6 +223,8 @@ func NewTransport(logger *zap.Logger, addr string,
 }
 
 func (t *Transport) Start() error {
+	t.startHeartbeatFlusher()
+	t.startRemoteSnapshotGC()
 	return t.trans.Start()
 }
 
@@ -223,7 +248,12 @@ func (t *Transport) SetFilter(f func(metapb.RaftMessage) bool) {
 }
 
 func (t *Transport) SendingSnapshotCount() uint64 {
-	return 0
+	count := uint64(0)
+	t.sending.Range(func(key, value interface{}) bool {
+		count++
+		return true
+	})
+	return count
 }
 
 func (t *Transport) Send(m metapb.RaftMessage) bool {
@@ -257,24 +287,32 @@ func (t *Transport) Send(m metapb.RaftMessage) bool {
 	if !ok {
 		ch = make(chan metapb.RaftMessage, sendQueueLen)
 		t.mu.queues[targetInfo.key] = ch
+		t.mu.heartbeats[targetInfo.key] = newHeartbeatQueue()
 	}
+	hq := t.mu.heartbeats[targetInfo.key]
 	t.mu.Unlock()
 
 	if !ok {
 		shutdownQueue := func() {
 			t.mu.Lock()
 			delete(t.mu.queues, targetInfo.key)
+			delete(t.mu.heartbeats, targetInfo.key)
 			t.mu.Unlock()
 		}
 		t.stopper.RunWorker(func() {
 			affected := make(nodeMap)
-			if !t.connectAndProcess(targetInfo.addr, ch, affected) {
+			if !t.connectAndProcess(targetInfo.addr, ch, hq, affected) {
 				t.notifyUnreachable(targetInfo.addr, affected)
 			}
 			shutdownQueue()
 		})
 	}
 
+	if t.coalesceHeartbeatsEnabled() && canCoalesce(m) {
+		hq.add(m)
+		return true
+	}
+
 	select {
 	case ch <- m:
 		return true
@@ -285,7 +323,7 @@ func (t *Transport) Send(m metapb.RaftMessage) bool {
 }
 
 func (t *Transport) connectAndProcess(addr string,
-	ch chan metapb.RaftMessage, affected nodeMap) bool {
+	ch chan metapb.RaftMessage, hq *heartbeatQueue, affected nodeMap) bool {
 	breaker := t.getCircuitBreaker(addr)
 	successes := breaker.Successes()
 	consecFailures := breaker.ConsecFailures()
@@ -305,7 +343,7 @@ func (t *Transport) connectAndProcess(addr string,
 			t.logger.Debug("connection established",
 				zap.String("addr", addr))
 		}
-		return t.processMessages(addr, ch, conn, affected)
+		return t.processMessages(addr, ch, hq, conn, affected)
 	}(); err != nil {
 		t.logger.Warn("circuit breaker failed",
 			zap.String("addr", addr),
@@ -325,7 +363,8 @@ func (t *Transport) notifyUnreachable(addr string, affected nodeMap) {
 }
 
 func (t *Transport) processMessages(addr string,
-	ch chan metapb.RaftMessage, conn Connection, affected nodeMap) error {
+	ch chan metapb.RaftMessage, hq *heartbeatQueue, conn Connection,
+	affected nodeMap) error {
 	idleTimer := time.NewTimer(idleTimeout)
 	defer idleTimer.Stop()
 	sz := uint64(0)
@@ -344,6 +383,17 @@ func (t *Transport) processMessages(addr string,
 			return nil
 		case <-idleTimer.C:
 			return nil
+		case heartbeats := <-hq.flushC:
+			for _, hb := range heartbeats {
+				affected[nodeInfo{ShardID: hb.ShardID, ReplicaID: hb.From.ID}] = struct{}{}
+			}
+			if err := t.sendMessageBatch(conn,
+				metapb.RaftMessageBatch{Heartbeats: heartbeats}); err != nil {
+				t.logger.Error("send heartbeats failed",
+					zap.String("target", addr),
+					zap.Error(err))
+				return err
+			}
 		case req := <-ch:
 			n := nodeInfo{
 				ShardID:   req.ShardID,
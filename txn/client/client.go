@@ -19,6 +19,7 @@ import (
 	"github.com/matrixorigin/matrixcube/components/log"
 	"github.com/matrixorigin/matrixcube/pb/txnpb"
 	"github.com/matrixorigin/matrixcube/txn/util"
+	"github.com/matrixorigin/matrixcube/util/stop"
 	"go.uber.org/zap"
 )
 
@@ -26,6 +27,9 @@ import (
 type TxnClient interface {
 	// NewTxn create transaction operation handles to perform transaction operations.
 	NewTxn(opts ...TxnOption) TxnOperator
+	// Close close the txn client, the async tasks of the committed transactions, e.g. updating
+	// the TxnRecord of the async commit transactions, are stopped.
+	Close()
 }
 
 var _ TxnClient = (*txnClient)(nil)
@@ -36,6 +40,7 @@ type txnClient struct {
 	txnPriorityGenerator TxnPriorityGenerator
 	txnClocker           TxnClocker
 	dispatcher           BatchDispatcher
+	stopper              *stop.Stopper
 }
 
 // NewTxnClient create a txn client
@@ -70,10 +75,16 @@ func (tc *txnClient) NewTxn(opts ...TxnOption) TxnOperator {
 	return newTxnOperator(txn,
 		tc.dispatcher,
 		tc.txnClocker,
+		tc.stopper,
 		tc.logger,
 		options)
 }
 
+// Close close the txn client
+func (tc *txnClient) Close() {
+	tc.stopper.Stop()
+}
+
 func (tc *txnClient) adjust() {
 	if tc.logger == nil {
 		tc.logger = log.Adjust(nil).Named("txn")
@@ -86,6 +97,8 @@ func (tc *txnClient) adjust() {
 	if tc.txnPriorityGenerator == nil {
 		tc.txnPriorityGenerator = newTxnPriorityGenerator()
 	}
+
+	tc.stopper = stop.NewStopper("txn-client", stop.WithLogger(tc.logger))
 }
 
 type txnMetaGetter interface {
@@ -112,6 +125,12 @@ type TxnOperator interface {
 	// Rollback rollback transactions, once the transaction is rolled back, the transaction's temporary
 	// write data is cleaned up asynchronously.
 	Rollback(ctx context.Context) error
+	// Lock acquires the pessimistic locks of the keys in the shard group, the keys already locked by
+	// the current transaction are skipped. The first lock of the transaction creates the TxnRecord.
+	// Returns `ErrTxnLockWaitTimeout` if any key can not be locked in the lock wait timeout, and
+	// `ErrTxnDeadlock` if waiting for the lock causes a deadlock. The lock does not wait if the
+	// pessimistic mode is not enabled.
+	Lock(ctx context.Context, shardGroup uint64, keys [][]byte) error
 	// Commit commit transaction will return as soon as the status of TxnRecord will be changed to
 	// `Committed`. The data written in the transaction still exists as temporary data, but this data
 	// needs to be treated as committed data, and TxnManager will start an asynchronous task to convert
@@ -124,12 +143,14 @@ var _ TxnOperator = (*txnOperator)(nil)
 func newTxnOperator(txnMeta txnpb.TxnMeta,
 	dispatcher BatchDispatcher,
 	txnClocker TxnClocker,
+	asyncTasks *stop.Stopper,
 	logger *zap.Logger,
 	opts txnOptions) TxnOperator {
 	return &txnOperator{
 		tc: newTxnCoordinator(txnMeta,
 			dispatcher,
 			txnClocker,
+			asyncTasks,
 			logger,
 			opts),
 	}
@@ -140,6 +161,10 @@ type txnOperator struct {
 }
 
 func (to *txnOperator) Write(ctx context.Context, operations []txnpb.TxnOperation) error {
+	if err := to.tc.lockWrites(ctx, operations); err != nil {
+		return err
+	}
+
 	_, err := to.doSend(ctx, operations, txnpb.TxnRequestType_Write, false)
 	return err
 }
@@ -147,6 +172,10 @@ func (to *txnOperator) Write(ctx context.Context, operations []txnpb.TxnOperatio
 func (to *txnOperator) WriteAndCommit(ctx context.Context, operations []txnpb.TxnOperation) error {
 	defer to.close()
 
+	if err := to.tc.lockWrites(ctx, operations); err != nil {
+		return err
+	}
+
 	_, err := to.doSend(ctx, operations, txnpb.TxnRequestType_Write, true)
 	return err
 }
@@ -170,6 +199,10 @@ func (to *txnOperator) Rollback(ctx context.Context) error {
 	return err
 }
 
+func (to *txnOperator) Lock(ctx context.Context, shardGroup uint64, keys [][]byte) error {
+	return to.tc.lock(ctx, shardGroup, keys)
+}
+
 func (to *txnOperator) Commit(ctx context.Context) error {
 	defer to.close()
 
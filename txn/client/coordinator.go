@@ -16,6 +16,7 @@ package client
 import (
 	"bytes"
 	"context"
+	"sort"
 	"sync"
 	"time"
 
@@ -28,16 +29,23 @@ import (
 	"go.uber.org/zap"
 )
 
+const (
+	asyncCommitTxnRecordTimeout = time.Second * 10
+)
+
 // txnCoordinator txn coordinator
 type txnCoordinator interface {
 	txnMetaGetter
 	send(ctx context.Context, batchRequest txnpb.TxnBatchRequest) (txnpb.TxnBatchResponse, error)
+	lock(ctx context.Context, shardGroup uint64, keys [][]byte) error
+	lockWrites(ctx context.Context, operations []txnpb.TxnOperation) error
 	stop()
 }
 
 func newTxnCoordinator(txnMeta txnpb.TxnMeta,
 	sender BatchDispatcher,
 	txnClocker TxnClocker,
+	asyncTasks *stop.Stopper,
 	logger *zap.Logger,
 	opts txnOptions) *coordinator {
 	c := &coordinator{
@@ -46,11 +54,13 @@ func newTxnCoordinator(txnMeta txnpb.TxnMeta,
 		txnClocker: txnClocker,
 		opts:       opts,
 		stopper:    stop.NewStopper("txn-"+txnMeta.Name, stop.WithLogger(logger)),
+		asyncTasks: asyncTasks,
 	}
 	c.mu.txnMeta = txnMeta
 	c.mu.status = txnpb.TxnStatus_Pending
 	c.mu.infightWrites = make(map[uint64]*keys.KeyTree)
 	c.mu.completedWrites = make(map[uint64]*txnpb.KeySet)
+	c.mu.lockedKeys = make(map[uint64]*keys.KeyTree)
 	return c
 }
 
@@ -60,6 +70,9 @@ type coordinator struct {
 	txnClocker TxnClocker
 	opts       txnOptions
 	stopper    *stop.Stopper
+	// asyncTasks runs the tasks outliving the txn, e.g. updating the TxnRecord of the
+	// async commit txn after the commit returned.
+	asyncTasks *stop.Stopper
 
 	mu struct {
 		sync.Mutex
@@ -76,6 +89,9 @@ type coordinator struct {
 		// completedWrites record the current transaction has completed the consensus write
 		// operation.
 		completedWrites map[uint64]*txnpb.KeySet
+		// lockedKeys record the keys locked by the current transaction in pessimistic mode,
+		// the locks are renewed by heartbeat and released by commit or rollback.
+		lockedKeys map[uint64]*keys.KeyTree
 		// sequence indicates the current number of write operations in the transaction.
 		// Of all the operations in the transaction, only the write operation will increase
 		// the value of the field.
@@ -89,6 +105,16 @@ func (c *coordinator) send(ctx context.Context, batchRequest txnpb.TxnBatchReque
 		c.logger.Fatal("empty batch request")
 	}
 
+	if n == 1 && batchRequest.HasCommit() && c.opts.optimize.asyncCommit {
+		asyncCommitRequest, ok, err := c.prepareAsyncCommit()
+		if err != nil {
+			return txnpb.TxnBatchResponse{}, err
+		}
+		if ok {
+			return c.asyncCommit(ctx, asyncCommitRequest)
+		}
+	}
+
 	hasCommitOrRollback := batchRequest.HasCommitOrRollback()
 	createTxnRecord, err := c.prepareSend(ctx, &batchRequest)
 	if err != nil {
@@ -151,7 +177,8 @@ func (c *coordinator) prepareSend(ctx context.Context, batchRequest *txnpb.TxnBa
 		c.mu.sequence++
 		c.addInfightWritesLocked(batchRequest)
 
-		if c.mu.sequence == 1 {
+		// the TxnRecord may be created by the pessimistic lock
+		if c.mu.sequence == 1 && len(c.mu.txnMeta.TxnRecordRouteKey) == 0 {
 			if !batchRequest.Requests[0].Operation.Impacted.HasPointKeys() {
 				c.logger.Fatal("first write in txn must has point key to save txn record.")
 			}
@@ -177,12 +204,299 @@ func (c *coordinator) prepareSend(ctx context.Context, batchRequest *txnpb.TxnBa
 	if batchRequest.HasCommitOrRollback() {
 		batchRequest.Header.Txn.InfightWrites = c.inflightKeySetLocked()
 		batchRequest.Header.Txn.CompletedWrites = c.completedKeySetLocked()
+		batchRequest.Header.Txn.LockedKeys = c.lockedKeySetLocked()
 		c.mu.ending = true
 	}
 
 	return createTxnRecord, nil
 }
 
+// prepareAsyncCommit returns the async commit request, false if the txn can not be
+// committed asynchronously. The async commit request contains the WaitConsensus requests
+// of all the written keys, the last request is the commit request with async commit on.
+func (c *coordinator) prepareAsyncCommit() (txnpb.TxnBatchRequest, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.canSendLocked(); err != nil {
+		return txnpb.TxnBatchRequest{}, false, err
+	}
+	if !c.canAsyncCommitLocked() {
+		return txnpb.TxnBatchRequest{}, false, nil
+	}
+
+	var batchRequest txnpb.TxnBatchRequest
+	batchRequest.Header.Type = txnpb.TxnRequestType_Write
+	addWaitConsensus := func(g uint64, key []byte) {
+		batchRequest.AddRequest(txnpb.TxnRequest{
+			Operation: txnpb.TxnOperation{
+				Op:         uint32(txnpb.InternalTxnOp_WaitConsensus),
+				Impacted:   txnpb.KeySet{PointKeys: [][]byte{key}},
+				ShardGroup: g,
+			},
+		})
+	}
+	for _, g := range c.writtenShardGroupsLocked() {
+		if tree, ok := c.mu.infightWrites[g]; ok {
+			tree.Ascend(func(key []byte) bool {
+				addWaitConsensus(g, key)
+				return true
+			})
+		}
+		if keySet, ok := c.mu.completedWrites[g]; ok {
+			for _, key := range keySet.PointKeys {
+				addWaitConsensus(g, key)
+			}
+		}
+	}
+	batchRequest.AddRequest(txnpb.TxnRequest{
+		Operation: txnpb.TxnOperation{Op: uint32(txnpb.InternalTxnOp_Commit)},
+		Options:   txnpb.RequestOptions{AsyncCommit: true},
+	})
+
+	batchRequest.Header.Txn.TxnMeta = c.mu.txnMeta
+	batchRequest.Header.Txn.Sequence = c.mu.sequence
+	batchRequest.Header.Txn.InfightWrites = c.inflightKeySetLocked()
+	batchRequest.Header.Txn.CompletedWrites = c.completedKeySetLocked()
+	batchRequest.Header.Txn.LockedKeys = c.lockedKeySetLocked()
+	c.mu.ending = true
+	return batchRequest, true, nil
+}
+
+// canAsyncCommitLocked returns true if the TxnRecord is created, and all the written keys
+// are point keys and the number of them is not greater than the limit.
+func (c *coordinator) canAsyncCommitLocked() bool {
+	if c.mu.sequence == 0 {
+		return false
+	}
+
+	n := c.infightKeyCountLocked()
+	for _, keySet := range c.mu.completedWrites {
+		if keySet.HasKeyRanges() {
+			return false
+		}
+		n += len(keySet.PointKeys)
+	}
+	return n > 0 && n <= c.opts.optimize.maxAsyncCommitKeys
+}
+
+func (c *coordinator) writtenShardGroupsLocked() []uint64 {
+	groups := make([]uint64, 0, len(c.mu.completedWrites)+len(c.mu.infightWrites))
+	for g := range c.mu.completedWrites {
+		groups = append(groups, g)
+	}
+	for g := range c.mu.infightWrites {
+		if _, ok := c.mu.completedWrites[g]; !ok {
+			groups = append(groups, g)
+		}
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		return groups[i] < groups[j]
+	})
+	return groups
+}
+
+// asyncCommit sends the async commit request, the txn is committed once all the requests
+// succeed. The commit timestamp is resolved from the maximum read timestamps of the written
+// keys, then the TxnRecord is updated to `Committed` with the commit timestamp asynchronously.
+func (c *coordinator) asyncCommit(ctx context.Context, batchRequest txnpb.TxnBatchRequest) (txnpb.TxnBatchResponse, error) {
+	resp, err := c.sender.Send(ctx, batchRequest)
+	resp, err = c.handleResponse(resp, err, false, false)
+	if err != nil {
+		return resp, err
+	}
+
+	c.mu.Lock()
+	commitTimestamp := c.resolveCommitTimestampLocked(resp)
+	c.mu.txnMeta.WriteTimestamp = commitTimestamp
+	c.mu.status = txnpb.TxnStatus_Committed
+	c.mu.infightWrites = make(map[uint64]*keys.KeyTree)
+	c.mu.completedWrites = make(map[uint64]*txnpb.KeySet)
+	for g, keySet := range batchRequest.Header.Txn.InfightWrites {
+		c.addToCompletedWritesLocked(txnpb.TxnOperation{Impacted: keySet, ShardGroup: g})
+	}
+	for g, keySet := range batchRequest.Header.Txn.CompletedWrites {
+		c.addToCompletedWritesLocked(txnpb.TxnOperation{Impacted: keySet, ShardGroup: g})
+	}
+	commit := endTxn(txnpb.InternalTxnOp_Commit)
+	commit.Header.Txn.TxnMeta = c.mu.txnMeta
+	commit.Header.Txn.Sequence = c.mu.sequence
+	commit.Header.Txn.CompletedWrites = c.completedKeySetLocked()
+	commit.Header.Txn.LockedKeys = c.lockedKeySetLocked()
+	c.mu.Unlock()
+
+	util.LogTxnMeta(c.logger, zap.DebugLevel, "txn async committed", commit.Header.Txn.TxnMeta)
+	c.startAsyncCommitTxnRecordTask(commit)
+	return resp, nil
+}
+
+// resolveCommitTimestampLocked returns the commit timestamp of the async commit txn, which
+// is greater than the maximum read timestamps of all the written keys, so the reads at the
+// timestamps are not changed by the txn.
+func (c *coordinator) resolveCommitTimestampLocked(resp txnpb.TxnBatchResponse) uint64 {
+	commitTimestamp := c.mu.txnMeta.WriteTimestamp
+	for idx := range resp.Responses {
+		maxReadTimestamp := resp.Responses[idx].MaxReadTimestamp
+		if maxReadTimestamp > 0 &&
+			c.txnClocker.Compare(commitTimestamp, maxReadTimestamp) <= 0 {
+			commitTimestamp = c.txnClocker.Next(maxReadTimestamp)
+		}
+	}
+	return commitTimestamp
+}
+
+// startAsyncCommitTxnRecordTask updates the staging TxnRecord of the async committed txn
+// to `Committed` with the resolved commit timestamp, and lets TxnManager resolve the
+// temporary data.
+func (c *coordinator) startAsyncCommitTxnRecordTask(commit txnpb.TxnBatchRequest) {
+	err := c.asyncTasks.RunTask(context.Background(), func(ctx context.Context) {
+		ctx, cancel := context.WithTimeout(ctx, asyncCommitTxnRecordTimeout)
+		defer cancel()
+
+		// It's okay if it fails, the staging TxnRecord has all the written keys, and
+		// it will be committed by the others when they encounter the temporary data.
+		resp, err := c.sender.Send(ctx, commit)
+		if err != nil {
+			c.logger.Error("async commit txn record failed",
+				zap.Error(err))
+			return
+		}
+		if resp.Header.Error != nil {
+			c.logger.Error("async commit txn record failed",
+				zap.String("error", resp.Header.Error.String()))
+		}
+	})
+	if err != nil {
+		c.logger.Error("failed to start async commit txn record task",
+			zap.Error(err))
+	}
+}
+
+// lockWrites locks the point keys written by the operations in pessimistic mode.
+func (c *coordinator) lockWrites(ctx context.Context, operations []txnpb.TxnOperation) error {
+	if !c.opts.pessimistic.enabled {
+		return nil
+	}
+
+	var groups []uint64
+	lockKeys := make(map[uint64][][]byte)
+	for idx := range operations {
+		if operations[idx].ImpactedType == txnpb.ImpactedType_ReadImpacted ||
+			!operations[idx].Impacted.HasPointKeys() {
+			continue
+		}
+		g := operations[idx].ShardGroup
+		if _, ok := lockKeys[g]; !ok {
+			groups = append(groups, g)
+		}
+		lockKeys[g] = append(lockKeys[g], operations[idx].Impacted.PointKeys...)
+	}
+
+	for _, g := range groups {
+		if err := c.lock(ctx, g, lockKeys[g]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// lock acquires the pessimistic locks of the keys not locked by the txn yet. The first
+// lock of the txn creates the TxnRecord, and starts the heartbeat to renew the locks.
+func (c *coordinator) lock(ctx context.Context, shardGroup uint64, lockKeys [][]byte) error {
+	batchRequest, createTxnRecord, err := c.prepareLock(shardGroup, lockKeys)
+	if err != nil || len(batchRequest.Requests) == 0 {
+		return err
+	}
+
+	resp, err := c.sender.Send(ctx, batchRequest)
+	if !createTxnRecord {
+		if _, err := c.handleResponse(resp, err, false, false); err != nil {
+			return err
+		}
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		c.addToLockedKeysLocked(shardGroup, batchRequest.Requests[0].Operation.Impacted.PointKeys)
+		return nil
+	}
+
+	if err == nil && resp.Header.Error == nil {
+		c.addToLockedKeysLocked(shardGroup, batchRequest.Requests[0].Operation.Impacted.PointKeys)
+		// the PessimisticLock requests are served by the lock service instead of the txn
+		// server, so the TxnRecord is created by a separate heartbeat.
+		resp, err = c.sender.Send(ctx, c.getCreateTxnRecordBatchRequestLocked())
+	}
+	if err != nil || resp.Header.Error != nil {
+		// the TxnRecord will be created by the next lock or write
+		c.mu.txnMeta.TxnRecordRouteKey = nil
+		c.mu.txnMeta.TxnRecordShardGroup = 0
+	}
+	_, err = c.handleResponse(resp, err, true, true)
+	return err
+}
+
+// prepareLock returns the PessimisticLock request of the keys not locked by the txn, the
+// lock is held if the TxnRecord needs to be created.
+func (c *coordinator) prepareLock(shardGroup uint64, lockKeys [][]byte) (batchRequest txnpb.TxnBatchRequest, createTxnRecord bool, err error) {
+	c.mu.Lock()
+	defer func() {
+		if !createTxnRecord {
+			c.mu.Unlock()
+		}
+	}()
+
+	if err := c.canSendLocked(); err != nil {
+		return batchRequest, false, err
+	}
+
+	var keySet txnpb.KeySet
+	added := make(map[string]struct{}, len(lockKeys))
+	tree := c.mu.lockedKeys[shardGroup]
+	for _, key := range lockKeys {
+		if _, ok := added[hack.SliceToString(key)]; ok ||
+			(tree != nil && tree.Contains(key)) {
+			continue
+		}
+		added[hack.SliceToString(key)] = struct{}{}
+		keySet.AddPointKeys([][]byte{key})
+	}
+	if keySet.IsEmpty() {
+		return batchRequest, false, nil
+	}
+
+	request := txnpb.TxnRequest{
+		Operation: txnpb.TxnOperation{
+			Op:         uint32(txnpb.InternalTxnOp_PessimisticLock),
+			Impacted:   keySet,
+			ShardGroup: shardGroup,
+		},
+	}
+	if c.opts.pessimistic.enabled {
+		request.Options.LockWaitTimeout = uint64(c.opts.pessimistic.lockWaitTimeout.Milliseconds())
+	}
+	if len(c.mu.txnMeta.TxnRecordRouteKey) == 0 {
+		c.mu.txnMeta.TxnRecordRouteKey = keySet.PointKeys[0]
+		c.mu.txnMeta.TxnRecordShardGroup = shardGroup
+		createTxnRecord = true
+	}
+
+	batchRequest.Header.Type = txnpb.TxnRequestType_Write
+	batchRequest.Header.Txn.TxnMeta = c.mu.txnMeta
+	batchRequest.Header.Txn.Sequence = c.mu.sequence
+	batchRequest.AddRequest(request)
+	return batchRequest, createTxnRecord, nil
+}
+
+func (c *coordinator) addToLockedKeysLocked(shardGroup uint64, lockKeys [][]byte) {
+	if tree, ok := c.mu.lockedKeys[shardGroup]; ok {
+		tree.AddMany(lockKeys)
+		return
+	}
+
+	tree := keys.NewKeyTree(32)
+	tree.AddMany(lockKeys)
+	c.mu.lockedKeys[shardGroup] = tree
+}
+
 func (c *coordinator) maybeInsertWaitConsensusLocked(batchRequest *txnpb.TxnBatchRequest) {
 	n := c.infightKeyCountLocked()
 	if !c.supportAsyncConsensus() ||
@@ -378,6 +692,10 @@ func (c *coordinator) handleResponse(resp txnpb.TxnBatchResponse, err error, loc
 			return txnpb.TxnBatchResponse{}, ErrTxnAborted
 		} else if resp.Header.Error.UncertaintyError != nil {
 			return txnpb.TxnBatchResponse{}, ErrTxnUncertainty
+		} else if resp.Header.Error.DeadlockError != nil {
+			return txnpb.TxnBatchResponse{}, ErrTxnDeadlock
+		} else if resp.Header.Error.LockWaitTimeoutError != nil {
+			return txnpb.TxnBatchResponse{}, ErrTxnLockWaitTimeout
 		}
 		return txnpb.TxnBatchResponse{}, ErrTxnConflict
 	}
@@ -431,6 +749,7 @@ func (c *coordinator) doHeartbeat() bool {
 		return false
 	}
 
+	c.renewLocks()
 	resp, err := c.sender.Send(context.TODO(), c.getHeartbeatBatchRequest())
 	if err != nil {
 		c.logger.Error("send heartbeat failed",
@@ -474,6 +793,20 @@ func (c *coordinator) doHeartbeat() bool {
 	return true
 }
 
+// renewLocks renews the pessimistic locks held by the txn, the locks failed to renew
+// are renewed by the next heartbeat.
+func (c *coordinator) renewLocks() {
+	batchRequest := c.getRenewLockBatchRequest()
+	if len(batchRequest.Requests) == 0 {
+		return
+	}
+
+	if _, err := c.sender.Send(context.TODO(), batchRequest); err != nil {
+		c.logger.Error("send renew lock failed",
+			zap.Error(err))
+	}
+}
+
 // startAsyncCleanTxnTask once the heartbeat is discovered and the state of the
 // transaction is Aborted, if all temporary data of the transaction is cleaned up
 // immediately.
@@ -594,6 +927,60 @@ func (c *coordinator) getHeartbeatBatchRequest() txnpb.TxnBatchRequest {
 	return batchRequest
 }
 
+// getCreateTxnRecordBatchRequestLocked returns the heartbeat creating the TxnRecord of
+// the txn started by the pessimistic lock.
+func (c *coordinator) getCreateTxnRecordBatchRequestLocked() txnpb.TxnBatchRequest {
+	ts, _ := c.txnClocker.Now()
+	var batchRequest txnpb.TxnBatchRequest
+	batchRequest.Header.Txn.TxnMeta = c.mu.txnMeta
+	batchRequest.Header.Txn.Sequence = c.mu.sequence
+	batchRequest.Header.Type = txnpb.TxnRequestType_Write
+	batchRequest.AddRequest(txnpb.TxnRequest{
+		Operation: txnpb.TxnOperation{
+			Op:         uint32(txnpb.InternalTxnOp_Heartbeat),
+			ShardGroup: c.mu.txnMeta.TxnRecordShardGroup,
+			Timestamp:  ts,
+		},
+		Options: txnpb.RequestOptions{CreateTxnRecord: true},
+	})
+	return batchRequest
+}
+
+// getRenewLockBatchRequest returns the RenewLock requests of the pessimistic locks held
+// by the txn, which are sent with the heartbeat, so the locks are expired once the
+// heartbeat stopped. The RenewLock requests are served by the lock service, so they
+// are not sent in the same batch with the heartbeat served by the txn server.
+func (c *coordinator) getRenewLockBatchRequest() txnpb.TxnBatchRequest {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var batchRequest txnpb.TxnBatchRequest
+	if len(c.mu.lockedKeys) == 0 {
+		return batchRequest
+	}
+
+	batchRequest.Header.Txn.TxnMeta = c.mu.txnMeta
+	batchRequest.Header.Type = txnpb.TxnRequestType_Write
+	groups := make([]uint64, 0, len(c.mu.lockedKeys))
+	for g := range c.mu.lockedKeys {
+		groups = append(groups, g)
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		return groups[i] < groups[j]
+	})
+	lockedKeys := c.lockedKeySetLocked()
+	for _, g := range groups {
+		batchRequest.Requests = append(batchRequest.Requests, txnpb.TxnRequest{
+			Operation: txnpb.TxnOperation{
+				Op:         uint32(txnpb.InternalTxnOp_RenewLock),
+				Impacted:   lockedKeys[g],
+				ShardGroup: g,
+			},
+		})
+	}
+	return batchRequest
+}
+
 func (c *coordinator) supportAsyncConsensus() bool {
 	return c.opts.optimize.asynchronousConsensus &&
 		!c.maxInfightBytesExceedLocked()
@@ -623,3 +1010,23 @@ func (c *coordinator) completedKeySetLocked() map[uint64]txnpb.KeySet {
 	}
 	return allCompleted
 }
+
+func (c *coordinator) lockedKeySetLocked() map[uint64]txnpb.KeySet {
+	if len(c.mu.lockedKeys) == 0 {
+		return nil
+	}
+
+	allLocked := make(map[uint64]txnpb.KeySet, len(c.mu.lockedKeys))
+	for g, v := range c.mu.lockedKeys {
+		set := txnpb.KeySet{
+			PointKeys: make([][]byte, 0, v.Len()),
+			Sorted:    true,
+		}
+		v.Ascend(func(key []byte) bool {
+			set.PointKeys = append(set.PointKeys, key)
+			return true
+		})
+		allLocked[g] = set
+	}
+	return allLocked
+}
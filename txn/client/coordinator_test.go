@@ -23,6 +23,7 @@ import (
 	"github.com/matrixorigin/matrixcube/pb/txnpb"
 	"github.com/matrixorigin/matrixcube/util/keys"
 	"github.com/matrixorigin/matrixcube/util/leaktest"
+	"github.com/matrixorigin/matrixcube/util/stop"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/zap"
 )
@@ -857,12 +858,258 @@ func TestCommitWillAttachedInfightAndCompletedWrites(t *testing.T) {
 	assert.Equal(t, 2, len(commit.Header.Txn.CompletedWrites[0].PointKeys))
 }
 
+func TestAsyncCommit(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	var asyncCommit txnpb.TxnBatchRequest
+	commitC := make(chan txnpb.TxnBatchRequest, 1)
+	sender := newMockBatchDispatcher(func(req txnpb.TxnBatchRequest) (txnpb.TxnBatchResponse, error) {
+		resp := txnpb.TxnBatchResponse{Header: txnpb.TxnBatchResponseHeader{Txn: req.Header.Txn.TxnMeta}}
+		if req.HasCommit() {
+			if !req.Requests[len(req.Requests)-1].IsAsyncCommit() {
+				commitC <- req
+				return resp, nil
+			}
+			asyncCommit = req
+			for idx := range req.Requests {
+				var maxReadTimestamp uint64
+				if req.Requests[idx].Operation.Impacted.HasPointKey([]byte("k2")) {
+					maxReadTimestamp = 10
+				}
+				resp.Responses = append(resp.Responses, txnpb.TxnResponse{MaxReadTimestamp: maxReadTimestamp})
+			}
+		}
+		return resp, nil
+	})
+	tc := newTestTxnCoordinator(sender, "mock-txn", "t1", 0)
+	defer tc.asyncTasks.Stop()
+	defer tc.stop()
+
+	tc.mu.Lock()
+	tc.opts.optimize.asynchronousConsensus = true
+	tc.opts.optimize.maxInfilghtKeysBytes = 1024
+	tc.opts.optimize.asyncCommit = true
+	tc.opts.optimize.maxAsyncCommitKeys = 10
+	tc.mu.Unlock()
+
+	_, err := tc.send(context.Background(), newTestWriteTxnOperation(false, "k1", "k2"))
+	assert.NoError(t, err)
+	_, err = tc.send(context.Background(), endTxn(txnpb.InternalTxnOp_Commit))
+	assert.NoError(t, err)
+
+	// the written keys are waited in parallel with the commit
+	assert.Equal(t, 3, len(asyncCommit.Requests))
+	assert.True(t, asyncCommit.Requests[0].IsWaitConsensus())
+	assert.True(t, asyncCommit.Requests[1].IsWaitConsensus())
+	assert.True(t, asyncCommit.Requests[2].IsAsyncCommit())
+	assert.Equal(t, txnpb.TxnStatus_Committed, tc.getStatus())
+	assert.Equal(t, uint64(11), tc.getTxnMeta().WriteTimestamp)
+
+	// the txn record is committed asynchronously with the resolved commit timestamp
+	select {
+	case commit := <-commitC:
+		assert.Equal(t, uint64(11), commit.Header.Txn.WriteTimestamp)
+		assert.Equal(t, 2, len(commit.Header.Txn.CompletedWrites[0].PointKeys))
+	case <-time.After(time.Second * 10):
+		assert.FailNow(t, "missing async commit txn record")
+	}
+}
+
+func TestAsyncCommitWithKeyRangeWrites(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	var commit txnpb.TxnBatchRequest
+	sender := newMockBatchDispatcher(func(req txnpb.TxnBatchRequest) (txnpb.TxnBatchResponse, error) {
+		if req.HasCommit() {
+			commit = req
+		}
+		return txnpb.TxnBatchResponse{Header: txnpb.TxnBatchResponseHeader{Txn: req.Header.Txn.TxnMeta}}, nil
+	})
+	tc := newTestTxnCoordinator(sender, "mock-txn", "t1", 0)
+	defer tc.stop()
+
+	tc.mu.Lock()
+	tc.opts.optimize.asyncCommit = true
+	tc.opts.optimize.maxAsyncCommitKeys = 10
+	tc.mu.Unlock()
+
+	_, err := tc.send(context.Background(), newTestWriteTxnOperation(false, "k1"))
+	assert.NoError(t, err)
+	tc.mu.Lock()
+	tc.mu.completedWrites[0].AddKeyRanges([]txnpb.KeyRange{{Start: []byte("k2"), End: []byte("k3")}})
+	tc.mu.Unlock()
+
+	_, err = tc.send(context.Background(), endTxn(txnpb.InternalTxnOp_Commit))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(commit.Requests))
+	assert.False(t, commit.Requests[0].IsAsyncCommit())
+}
+
+func TestResolveCommitTimestamp(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	tc := newTestTxnCoordinator(newMockBatchDispatcher(nil), "mock-txn", "t1", 0)
+	defer tc.stop()
+
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	tc.mu.txnMeta.WriteTimestamp = 5
+	assert.Equal(t, uint64(5), tc.resolveCommitTimestampLocked(txnpb.TxnBatchResponse{
+		Responses: []txnpb.TxnResponse{{}, {MaxReadTimestamp: 3}},
+	}))
+	assert.Equal(t, uint64(6), tc.resolveCommitTimestampLocked(txnpb.TxnBatchResponse{
+		Responses: []txnpb.TxnResponse{{MaxReadTimestamp: 5}, {MaxReadTimestamp: 3}},
+	}))
+	assert.Equal(t, uint64(9), tc.resolveCommitTimestampLocked(txnpb.TxnBatchResponse{
+		Responses: []txnpb.TxnResponse{{MaxReadTimestamp: 8}, {MaxReadTimestamp: 6}},
+	}))
+}
+
+func TestLock(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	var requests []txnpb.TxnBatchRequest
+	var createTxnRecord txnpb.TxnBatchRequest
+	sender := newMockBatchDispatcher(func(req txnpb.TxnBatchRequest) (txnpb.TxnBatchResponse, error) {
+		if req.Requests[0].Operation.Op != uint32(txnpb.InternalTxnOp_Heartbeat) {
+			requests = append(requests, req)
+		} else if req.Requests[0].Options.CreateTxnRecord {
+			createTxnRecord = req
+		}
+		return txnpb.TxnBatchResponse{Header: txnpb.TxnBatchResponseHeader{Txn: req.Header.Txn.TxnMeta}}, nil
+	})
+	tc := newTestTxnCoordinator(sender, "mock-txn", "t1", 0)
+	defer tc.stop()
+	tc.mu.Lock()
+	tc.opts.pessimistic.enabled = true
+	tc.opts.pessimistic.lockWaitTimeout = time.Second
+	tc.mu.Unlock()
+
+	// the first lock creates the txn record by a separate heartbeat
+	assert.NoError(t, tc.lock(context.Background(), 0, [][]byte{[]byte("k1"), []byte("k2"), []byte("k1")}))
+	assert.Equal(t, 1, len(requests))
+	assert.True(t, requests[0].Requests[0].IsPessimisticLock())
+	assert.False(t, requests[0].Requests[0].Options.CreateTxnRecord)
+	assert.Equal(t, 1, len(createTxnRecord.Requests))
+	assert.Equal(t, []byte("k1"), createTxnRecord.Header.Txn.TxnRecordRouteKey)
+	assert.Equal(t, uint64(1000), requests[0].Requests[0].Options.LockWaitTimeout)
+	assert.Equal(t, [][]byte{[]byte("k1"), []byte("k2")}, requests[0].Requests[0].Operation.Impacted.PointKeys)
+	assert.Equal(t, []byte("k1"), tc.getTxnMeta().TxnRecordRouteKey)
+	tc.mu.Lock()
+	assert.True(t, tc.mu.heartbeating)
+	tc.mu.Unlock()
+
+	// the locked keys are skipped
+	assert.NoError(t, tc.lock(context.Background(), 0, [][]byte{[]byte("k2")}))
+	assert.Equal(t, 1, len(requests))
+	assert.NoError(t, tc.lock(context.Background(), 0, [][]byte{[]byte("k2"), []byte("k3")}))
+	assert.Equal(t, 2, len(requests))
+	assert.False(t, requests[1].Requests[0].Options.CreateTxnRecord)
+	assert.Equal(t, [][]byte{[]byte("k3")}, requests[1].Requests[0].Operation.Impacted.PointKeys)
+
+	// the txn record is created by the lock, not the first write
+	_, err := tc.send(context.Background(), newTestWriteTxnOperation(false, "k4"))
+	assert.NoError(t, err)
+	assert.False(t, requests[2].Requests[0].Options.CreateTxnRecord)
+	assert.Equal(t, []byte("k1"), tc.getTxnMeta().TxnRecordRouteKey)
+
+	// the locked keys are released by commit
+	_, err = tc.send(context.Background(), endTxn(txnpb.InternalTxnOp_Commit))
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(requests[3].Header.Txn.LockedKeys[0].PointKeys))
+}
+
+func TestLockWithLockErrors(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	var txnErr txnpb.TxnError
+	var last txnpb.TxnBatchRequest
+	sender := newMockBatchDispatcher(func(req txnpb.TxnBatchRequest) (txnpb.TxnBatchResponse, error) {
+		last = req
+		resp := txnpb.TxnBatchResponse{Header: txnpb.TxnBatchResponseHeader{Txn: req.Header.Txn.TxnMeta}}
+		if req.Requests[0].IsPessimisticLock() {
+			resp.Header.Error = &txnErr
+		}
+		return resp, nil
+	})
+	tc := newTestTxnCoordinator(sender, "mock-txn", "t1", 0)
+	defer tc.stop()
+
+	txnErr = txnpb.TxnError{LockWaitTimeoutError: &txnpb.LockWaitTimeoutError{}}
+	assert.Equal(t, ErrTxnLockWaitTimeout, tc.lock(context.Background(), 0, [][]byte{[]byte("k1")}))
+	assert.Empty(t, tc.getTxnMeta().TxnRecordRouteKey)
+
+	txnErr = txnpb.TxnError{DeadlockError: &txnpb.DeadlockError{}}
+	assert.Equal(t, ErrTxnDeadlock, tc.lock(context.Background(), 0, [][]byte{[]byte("k2")}))
+	assert.Empty(t, tc.getTxnMeta().TxnRecordRouteKey)
+	tc.mu.Lock()
+	assert.Empty(t, tc.mu.lockedKeys)
+	assert.False(t, tc.mu.heartbeating)
+	tc.mu.Unlock()
+
+	// the txn record is created by the first write
+	_, err := tc.send(context.Background(), newTestWriteTxnOperation(false, "k3"))
+	assert.NoError(t, err)
+	assert.True(t, last.Requests[0].Options.CreateTxnRecord)
+	assert.Equal(t, []byte("k3"), tc.getTxnMeta().TxnRecordRouteKey)
+}
+
+func TestLockWrites(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	var requests []txnpb.TxnBatchRequest
+	sender := newMockBatchDispatcher(func(req txnpb.TxnBatchRequest) (txnpb.TxnBatchResponse, error) {
+		requests = append(requests, req)
+		return txnpb.TxnBatchResponse{Header: txnpb.TxnBatchResponseHeader{Txn: req.Header.Txn.TxnMeta}}, nil
+	})
+	tc := newTestTxnCoordinator(sender, "mock-txn", "t1", 0)
+	defer tc.stop()
+
+	operations := []txnpb.TxnOperation{
+		newTestWriteTxnOperation(false, "k1").Requests[0].Operation,
+		newTestPointReadTxnOperation("k2").Requests[0].Operation,
+	}
+	assert.NoError(t, tc.lockWrites(context.Background(), operations))
+	assert.Empty(t, requests)
+
+	tc.opts.pessimistic.enabled = true
+	assert.NoError(t, tc.lockWrites(context.Background(), operations))
+	assert.Equal(t, 2, len(requests))
+	assert.Equal(t, [][]byte{[]byte("k1")}, requests[0].Requests[0].Operation.Impacted.PointKeys)
+	assert.True(t, requests[1].Requests[0].Options.CreateTxnRecord)
+}
+
+func TestHeartbeatRenewLocks(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	tc := newTestTxnCoordinator(newMockBatchDispatcher(nil), "mock-txn", "t1", 0)
+	defer tc.stop()
+
+	req := tc.getRenewLockBatchRequest()
+	assert.Empty(t, req.Requests)
+
+	tc.mu.Lock()
+	tc.addToLockedKeysLocked(2, [][]byte{[]byte("k2")})
+	tc.addToLockedKeysLocked(1, [][]byte{[]byte("k1"), []byte("k0")})
+	tc.mu.Unlock()
+	// the locks are not renewed in the batch of the heartbeat
+	assert.Equal(t, 1, len(tc.getHeartbeatBatchRequest().Requests))
+	req = tc.getRenewLockBatchRequest()
+	assert.Equal(t, 2, len(req.Requests))
+	assert.True(t, req.Requests[0].IsRenewLock())
+	assert.Equal(t, uint64(1), req.Requests[0].Operation.ShardGroup)
+	assert.Equal(t, [][]byte{[]byte("k0"), []byte("k1")}, req.Requests[0].Operation.Impacted.PointKeys)
+	assert.True(t, req.Requests[1].IsRenewLock())
+	assert.Equal(t, uint64(2), req.Requests[1].Operation.ShardGroup)
+}
+
 func newTestTxnCoordinator(sender BatchDispatcher, name string, id string, epoch uint32) *coordinator {
 	clocker := newMockTxnClocker(0)
 	ts, skew := clocker.Now()
 	tc := newTxnCoordinator(newTestSITxn(name, id, ts, skew, epoch),
 		sender,
 		clocker,
+		stop.NewStopper("txn-client"),
 		log.GetPanicZapLoggerWithLevel(zap.DebugLevel),
 		txnOptions{heartbeatDuration: time.Millisecond * 10})
 	tc.mu.infightWrites[0] = keys.NewKeyTree(32)
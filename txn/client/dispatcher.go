@@ -77,9 +77,10 @@ func (s *batchDispatcher) Send(ctx context.Context, request txnpb.TxnBatchReques
 	}
 
 	// try to split pre-commit WaitConsensus requests and commit, make sure the commit or rollback
-	// request can see the reuslt of the in-fight writes.
+	// request can see the reuslt of the in-fight writes. The async commit request is sent in
+	// parallel with the WaitConsensus requests.
 	n := len(request.Requests)
-	if request.HasCommitOrRollback() && n > 1 {
+	if request.HasCommitOrRollback() && n > 1 && !request.Requests[n-1].IsAsyncCommit() {
 		idx := request.GetLastPreCommitRequestIdx()
 		if idx > 0 {
 			request.Switch(idx, n-1)
@@ -130,6 +131,7 @@ func (s *batchDispatcher) routeRequest(request txnpb.TxnBatchRequest) (uint64, m
 	appendRequest := func(toShard uint64, req txnpb.TxnRequest) {
 		if m, ok := requests[toShard]; ok {
 			m.Requests = append(m.Requests, req)
+			requests[toShard] = m
 		} else {
 			newBatchRequest := txnpb.TxnBatchRequest{
 				Header:   request.Header,
@@ -158,6 +160,10 @@ func (s *batchDispatcher) routeRequest(request txnpb.TxnBatchRequest) (uint64, m
 					request.Requests[idx].Operation.Impacted.PointKeys[0])
 				appendRequest(toShard, request.Requests[idx])
 				break
+			case txnpb.InternalTxnOp_PessimisticLock,
+				txnpb.InternalTxnOp_RenewLock:
+				s.routeLockRequest(request.Requests[idx], appendRequest)
+				break
 			}
 		} else {
 			routeInfos, err := s.router.Route(request.Requests[idx].Operation)
@@ -179,6 +185,26 @@ func (s *batchDispatcher) routeRequest(request txnpb.TxnBatchRequest) (uint64, m
 	return createTxnRecordShard, requests
 }
 
+// routeLockRequest splits the keys of the PessimisticLock or RenewLock request by shard.
+func (s *batchDispatcher) routeLockRequest(req txnpb.TxnRequest, appendRequest func(uint64, txnpb.TxnRequest)) {
+	var shards []uint64
+	splitted := make(map[uint64][][]byte)
+	for _, key := range req.Operation.Impacted.PointKeys {
+		shard := s.client.Router().SelectShardIDByKey(req.Operation.ShardGroup, key)
+		if _, ok := splitted[shard]; !ok {
+			shards = append(shards, shard)
+		}
+		splitted[shard] = append(splitted[shard], key)
+	}
+
+	for _, shard := range shards {
+		keySet := txnpb.KeySet{PointKeys: splitted[shard]}
+		shardReq := req
+		shardReq.Operation.Impacted = keySet
+		appendRequest(shard, shardReq)
+	}
+}
+
 func (s *batchDispatcher) doSendToShard(ctx context.Context, shard uint64, req txnpb.TxnBatchRequest, result *dispatchResult) {
 	s.stopper.RunTask(ctx, func(ctx context.Context) {
 		resp, err := s.doSyncSend(ctx, shard, req)
@@ -208,8 +234,11 @@ func (s *batchDispatcher) doSyncSend(ctx context.Context, shard uint64, req txnp
 		if err == raftstore.ErrKeysNotInShard || raftstore.IsShardUnavailableErr(err) {
 			return s.handleNeedReRoute(ctx, req)
 		}
+		return resp, err
 	}
 
+	s.txnClocker.Update(resp.Header.Timestamp)
+
 	return resp, err
 }
 
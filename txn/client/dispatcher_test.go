@@ -98,6 +98,15 @@ func TestRouteRequest(t *testing.T) {
 	assert.Equal(t, req.Requests[0].Operation.Impacted.PointKeys[:1], m[1].Requests[0].Operation.Impacted.PointKeys)
 	assert.Equal(t, req.Requests[0].Operation.Impacted.PointKeys[1:], m[2].Requests[0].Operation.Impacted.PointKeys)
 
+	// the requests to the same shard are kept in a batch
+	req = txnpb.TxnBatchRequest{}
+	appendTestInternalRequest(&req, 1, txnpb.InternalTxnOp_WaitConsensus)
+	appendTestCommitRequest(&req, 1)
+	s, m = bd.routeRequest(req)
+	assert.Equal(t, s, uint64(0))
+	assert.Equal(t, 1, len(m))
+	assert.Equal(t, 2, len(m[1].Requests))
+	assert.True(t, m[1].Requests[1].IsCommit())
 }
 
 func TestDispatcherSend(t *testing.T) {
@@ -968,6 +977,85 @@ func TestDispatcherSendCommitAndWithSwitchWaitConsensus(t *testing.T) {
 	assert.Equal(t, "ok", string(resp.Responses[1].Data))
 }
 
+func TestDispatcherSendAsyncCommitWithNoSwitchWaitConsensus(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	router := raftstore.NewMockRouter()
+	addTestShard(router, 1, "10/11,20/21,30/31")
+
+	var mu sync.Mutex
+	var received []rpcpb.Request
+	client := newTestRaftstoreClient(router, func(r rpcpb.Request) (rpcpb.ResponseBatch, error) {
+		mu.Lock()
+		received = append(received, r)
+		mu.Unlock()
+		return rpcpb.ResponseBatch{Responses: []rpcpb.Response{{ID: r.ID, TxnBatchResponse: &txnpb.TxnBatchResponse{
+			Responses: []txnpb.TxnResponse{{Data: []byte("ok")}, {Data: []byte("ok")}},
+		}}}}, nil
+	})
+	defer client.Stop()
+
+	bd := newTestBatchDispatcher(client)
+	defer bd.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+	defer cancel()
+
+	var req txnpb.TxnBatchRequest
+	appendTestInternalRequest(&req, 1, txnpb.InternalTxnOp_WaitConsensus)
+	appendTestCommitRequest(&req, 1)
+	req.Requests[1].Options.AsyncCommit = true
+	resp, err := bd.Send(ctx, req)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(resp.Responses))
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, 1, len(received))
+	assert.Equal(t, 2, len(received[0].TxnBatchRequest.Requests))
+	assert.True(t, received[0].TxnBatchRequest.Requests[1].IsAsyncCommit())
+}
+
+func TestRouteLockRequest(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	router := raftstore.NewMockRouter()
+	addTestShard(router, 1, "10/11,20/21,30/31")
+	addTestShard(router, 2, "100/101,200/201,300/301")
+
+	client := newTestRaftstoreClient(router, func(r rpcpb.Request) (rpcpb.ResponseBatch, error) { return rpcpb.ResponseBatch{}, nil })
+	defer client.Stop()
+
+	bd := newTestBatchDispatcher(client)
+	defer bd.Close()
+
+	var req txnpb.TxnBatchRequest
+	req.Header.Type = txnpb.TxnRequestType_Write
+	req.Header.Txn.TxnRecordRouteKey = format.Uint64ToBytes(2)
+	req.Requests = append(req.Requests, txnpb.TxnRequest{
+		Operation: txnpb.TxnOperation{
+			Op: uint32(txnpb.InternalTxnOp_PessimisticLock),
+			Impacted: txnpb.KeySet{PointKeys: [][]byte{format.Uint64ToBytes(2),
+				format.Uint64ToBytes(1), format.Uint64ToBytes(2)}},
+		},
+		Options: txnpb.RequestOptions{LockWaitTimeout: 10},
+	})
+	s, m := bd.routeRequest(req)
+	assert.Equal(t, uint64(0), s)
+	assert.Equal(t, 2, len(m))
+	assert.Equal(t, [][]byte{format.Uint64ToBytes(1)}, m[1].Requests[0].Operation.Impacted.PointKeys)
+	assert.Equal(t, uint64(10), m[1].Requests[0].Options.LockWaitTimeout)
+	assert.Equal(t, [][]byte{format.Uint64ToBytes(2), format.Uint64ToBytes(2)}, m[2].Requests[0].Operation.Impacted.PointKeys)
+
+	req.Requests[0].Operation.Op = uint32(txnpb.InternalTxnOp_RenewLock)
+	req.Requests[0].Options = txnpb.RequestOptions{}
+	s, m = bd.routeRequest(req)
+	assert.Equal(t, uint64(0), s)
+	assert.Equal(t, 2, len(m))
+	assert.True(t, m[1].Requests[0].IsRenewLock())
+	assert.True(t, m[2].Requests[0].IsRenewLock())
+}
+
 func newTestBatchRequest(ids ...uint64) txnpb.TxnBatchRequest {
 	keys := make([][]byte, 0, len(ids))
 	for _, id := range ids {
@@ -27,4 +27,10 @@ var (
 	ErrTxnConflict = errors.New("transaction operations encounter conflicts")
 	// ErrTxnUncertainty uncertain clock error encountered
 	ErrTxnUncertainty = errors.New("uncertain clock error encountered")
+	// ErrTxnDeadlock waiting for the pessimistic lock causes a deadlock, the client needs to
+	// rollback the transaction
+	ErrTxnDeadlock = errors.New("deadlock found when waiting for the pessimistic lock")
+	// ErrTxnLockWaitTimeout waiting for the pessimistic lock timeout, the locks already held
+	// are kept and the lock can be retried
+	ErrTxnLockWaitTimeout = errors.New("pessimistic lock wait timeout")
 )
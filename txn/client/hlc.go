@@ -0,0 +1,350 @@
+// Copyright 2022 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"encoding/binary"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/matrixorigin/matrixcube/components/log"
+	"github.com/matrixorigin/matrixcube/pb/rpcpb"
+	"github.com/matrixorigin/matrixcube/storage"
+	"go.uber.org/zap"
+)
+
+// HLC timestamp layout
+//
+// A HLC timestamp is a uint64, the high 48 bits are the physical time in unix
+// milliseconds, the low 16 bits are the logical counter. The logical counter is
+// increased if the physical time does not move forward, so the timestamps issued
+// by a clock are strictly increasing, and the timestamps can be compared as
+// uint64 directly.
+
+const (
+	logicalBits = 16
+	maxLogical  = 1<<logicalBits - 1
+
+	defaultPersistInterval = time.Second * 3
+	persistRetryInterval   = time.Millisecond * 100
+)
+
+// TimestampStore persists the upper bound of the physical time of the HLC, the
+// timestamps issued after restart are greater than the persisted upper bound, so
+// the HLC is monotonic across restarts even if the local clock goes backwards.
+type TimestampStore interface {
+	// Load returns the persisted timestamp, 0 if nothing persisted
+	Load() (uint64, error)
+	// Save persists the timestamp
+	Save(ts uint64) error
+}
+
+// HLCOption the option to create the HLC txn clocker
+type HLCOption func(*hlcTxnClocker)
+
+// WithHLCLogger set logger for the HLC txn clocker
+func WithHLCLogger(logger *zap.Logger) HLCOption {
+	return func(c *hlcTxnClocker) {
+		c.logger = logger
+	}
+}
+
+// WithHLCTimestampStore set the TimestampStore for the HLC txn clocker, the upper
+// bound of the physical time is persisted every persistInterval ahead. Without
+// the store the HLC is only monotonic in the process lifetime.
+func WithHLCTimestampStore(store TimestampStore, persistInterval time.Duration) HLCOption {
+	return func(c *hlcTxnClocker) {
+		c.store = store
+		c.persistInterval = persistInterval
+	}
+}
+
+// withHLCPhysicalClock set the physical clock for testing
+func withHLCPhysicalClock(physicalClock func() time.Time) HLCOption {
+	return func(c *hlcTxnClocker) {
+		c.physicalClock = physicalClock
+	}
+}
+
+var _ TxnClocker = (*hlcTxnClocker)(nil)
+
+type hlcTxnClocker struct {
+	logger          *zap.Logger
+	maxSkew         time.Duration
+	store           TimestampStore
+	persistInterval time.Duration
+	physicalClock   func() time.Time
+	// persistMu serializes the persistence of the upper bound, which is done out of
+	// the mu, so the timestamps under the persisted upper bound are still issued.
+	persistMu sync.Mutex
+
+	mu struct {
+		sync.Mutex
+		// physical the physical time of the last timestamp in unix milliseconds
+		physical uint64
+		// logical the logical counter of the last timestamp
+		logical uint64
+		// persisted the persisted upper bound of the physical time
+		persisted uint64
+	}
+}
+
+// NewHLCTxnClocker returns a hybrid logical clock based TxnClocker, the maxSkew is
+// the max clock skew of the nodes in the cluster, which decides the uncertainty
+// interval of the txns. The clock is updated by the timestamps piggy-backed on the
+// txn responses, so a txn started after another one finished on any node always gets
+// a greater timestamp.
+func NewHLCTxnClocker(maxSkew time.Duration, opts ...HLCOption) (TxnClocker, error) {
+	c := &hlcTxnClocker{maxSkew: maxSkew}
+	for _, opt := range opts {
+		opt(c)
+	}
+	c.logger = log.Adjust(c.logger).Named("hlc")
+	if c.physicalClock == nil {
+		c.physicalClock = time.Now
+	}
+	if c.persistInterval <= 0 {
+		c.persistInterval = defaultPersistInterval
+	}
+
+	if c.store != nil {
+		ts, err := c.store.Load()
+		if err != nil {
+			return nil, err
+		}
+		c.mu.physical = physicalTime(ts)
+		c.mu.persisted = c.mu.physical
+		c.logger.Info("HLC upper bound loaded",
+			zap.Uint64("physical", c.mu.physical))
+	}
+	return c, nil
+}
+
+func (c *hlcTxnClocker) Now() (current uint64, maxSkew uint64) {
+	for {
+		physical := c.physicalNow()
+		c.mu.Lock()
+		p, l := c.mu.physical, c.mu.logical
+		if physical > p {
+			p, l = physical, 0
+		} else {
+			p, l = tickLogical(p, l)
+		}
+		if c.setLocked(p, l) {
+			current = c.timestampLocked()
+			c.mu.Unlock()
+			return current, uint64(c.maxSkew.Milliseconds()) << logicalBits
+		}
+		c.mu.Unlock()
+		c.waitPersisted(p)
+	}
+}
+
+func (c *hlcTxnClocker) Compare(ts1, ts2 uint64) int {
+	if ts1 == ts2 {
+		return 0
+	}
+	if ts1 > ts2 {
+		return 1
+	}
+	return -1
+}
+
+func (c *hlcTxnClocker) Next(ts uint64) uint64 {
+	return ts + 1
+}
+
+// Update updates the clock by the remote timestamp, the remote timestamp ahead of
+// the local physical time more than the max clock skew is ignored, otherwise the
+// clock can be pushed arbitrarily far by a broken clock of the other node.
+func (c *hlcTxnClocker) Update(ts uint64) {
+	if ts == 0 {
+		return
+	}
+
+	remotePhysical, remoteLogical := physicalTime(ts), logicalTime(ts)
+	for {
+		physical := c.physicalNow()
+		if remotePhysical > physical &&
+			remotePhysical-physical > uint64(c.maxSkew.Milliseconds()) {
+			c.logger.Warn("remote clock is ahead more than the max clock skew, ignored",
+				zap.Uint64("remote", remotePhysical),
+				zap.Uint64("local", physical),
+				zap.Duration("max-skew", c.maxSkew))
+			return
+		}
+
+		c.mu.Lock()
+		p, l := c.mu.physical, c.mu.logical
+		switch {
+		case physical > p && physical > remotePhysical:
+			p, l = physical, 0
+		case remotePhysical > p:
+			p, l = tickLogical(remotePhysical, remoteLogical)
+		case remotePhysical == p:
+			if remoteLogical > l {
+				l = remoteLogical
+			}
+			p, l = tickLogical(p, l)
+		default:
+			p, l = tickLogical(p, l)
+		}
+		if c.setLocked(p, l) {
+			c.mu.Unlock()
+			return
+		}
+		c.mu.Unlock()
+		c.waitPersisted(p)
+	}
+}
+
+// setLocked sets the clock if the physical time is under the persisted upper bound,
+// returns false if the upper bound must be persisted first.
+func (c *hlcTxnClocker) setLocked(physical, logical uint64) bool {
+	if c.store != nil && physical >= c.mu.persisted {
+		return false
+	}
+	c.mu.physical = physical
+	c.mu.logical = logical
+	return true
+}
+
+// waitPersisted persists the upper bound of the physical time ahead of the
+// persistInterval if the physical time reaches the persisted one. The persisted
+// upper bound is only loaded at startup, the timestamps greater than it are not
+// issued until it's persisted, otherwise the monotonicity is broken after restart.
+// The persistence is retried if failed.
+func (c *hlcTxnClocker) waitPersisted(physical uint64) {
+	c.persistMu.Lock()
+	defer c.persistMu.Unlock()
+
+	for {
+		c.mu.Lock()
+		persisted := c.mu.persisted
+		c.mu.Unlock()
+		if physical < persisted {
+			return
+		}
+
+		upper := physical + uint64(c.persistInterval.Milliseconds())
+		err := c.store.Save(upper << logicalBits)
+		if err == nil {
+			c.mu.Lock()
+			c.mu.persisted = upper
+			c.mu.Unlock()
+			return
+		}
+		c.logger.Error("failed to persist the HLC upper bound, retry later",
+			zap.Uint64("physical", upper),
+			zap.Error(err))
+		time.Sleep(persistRetryInterval)
+	}
+}
+
+func (c *hlcTxnClocker) timestampLocked() uint64 {
+	return c.mu.physical<<logicalBits | c.mu.logical
+}
+
+func (c *hlcTxnClocker) physicalNow() uint64 {
+	return uint64(c.physicalClock().UnixNano() / int64(time.Millisecond))
+}
+
+func tickLogical(physical, logical uint64) (uint64, uint64) {
+	if logical == maxLogical {
+		return physical + 1, 0
+	}
+	return physical, logical + 1
+}
+
+func physicalTime(ts uint64) uint64 {
+	return ts >> logicalBits
+}
+
+func logicalTime(ts uint64) uint64 {
+	return ts & maxLogical
+}
+
+// NewHLCRequestHandler returns the `Config.Customize.CustomShardProxyRequestHandler`
+// of the store, which updates the clock of the store by the timestamps of the txn
+// requests, and sets the clock as the timestamp of the txn responses, so the HLC of
+// the clients learns the clocks of the stores. The requests are handled by the
+// handler first if not nil, e.g. the lock service, and served by the next, i.e. the
+// `OnRequestWithCB` of the store, if not handled.
+func NewHLCRequestHandler(clocker TxnClocker,
+	handler func(rpcpb.Request, func(rpcpb.ResponseBatch)) (bool, error),
+	next func(rpcpb.Request, func(rpcpb.ResponseBatch)) error) func(rpcpb.Request, func(rpcpb.ResponseBatch)) (bool, error) {
+	return func(req rpcpb.Request, cb func(rpcpb.ResponseBatch)) (bool, error) {
+		if req.Type != rpcpb.Txn || req.TxnBatchRequest == nil {
+			if handler != nil {
+				return handler(req, cb)
+			}
+			return false, nil
+		}
+
+		txn := req.TxnBatchRequest.Header.Txn
+		if txn.WriteTimestamp > txn.ReadTimestamp {
+			clocker.Update(txn.WriteTimestamp)
+		} else {
+			clocker.Update(txn.ReadTimestamp)
+		}
+		timestamped := func(resp rpcpb.ResponseBatch) {
+			for idx := range resp.Responses {
+				if txnResp := resp.Responses[idx].TxnBatchResponse; txnResp != nil {
+					txnResp.Header.Timestamp, _ = clocker.Now()
+				}
+			}
+			cb(resp)
+		}
+		if handler != nil {
+			if handled, err := handler(req, timestamped); err != nil || handled {
+				return handled, err
+			}
+		}
+		return true, next(req, timestamped)
+	}
+}
+
+var _ TimestampStore = (*kvTimestampStore)(nil)
+
+type kvTimestampStore struct {
+	kv  storage.KVStore
+	key []byte
+}
+
+// NewKVTimestampStore returns a TimestampStore persisting the timestamp in the key
+// of the kv store.
+func NewKVTimestampStore(kv storage.KVStore, key []byte) TimestampStore {
+	return &kvTimestampStore{kv: kv, key: key}
+}
+
+func (s *kvTimestampStore) Load() (uint64, error) {
+	v, err := s.kv.Get(s.key)
+	if err != nil {
+		return 0, err
+	}
+	if len(v) == 0 {
+		return 0, nil
+	}
+	if len(v) != 8 {
+		return 0, fmt.Errorf("invalid persisted timestamp %+v", v)
+	}
+	return binary.BigEndian.Uint64(v), nil
+}
+
+func (s *kvTimestampStore) Save(ts uint64) error {
+	v := make([]byte, 8)
+	binary.BigEndian.PutUint64(v, ts)
+	return s.kv.Set(s.key, v, true)
+}
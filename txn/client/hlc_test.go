@@ -0,0 +1,188 @@
+// Copyright 2022 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/matrixorigin/matrixcube/pb/rpcpb"
+	"github.com/matrixorigin/matrixcube/pb/txnpb"
+	"github.com/matrixorigin/matrixcube/storage/kv/mem"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestHLCTxnClocker(t *testing.T, now *time.Time, opts ...HLCOption) *hlcTxnClocker {
+	opts = append(opts, withHLCPhysicalClock(func() time.Time { return *now }))
+	c, err := NewHLCTxnClocker(time.Millisecond*500, opts...)
+	require.NoError(t, err)
+	return c.(*hlcTxnClocker)
+}
+
+func TestHLCNow(t *testing.T) {
+	now := time.Unix(100, 0)
+	c := newTestHLCTxnClocker(t, &now)
+
+	ts, maxSkew := c.Now()
+	assert.Equal(t, uint64(100000), physicalTime(ts))
+	assert.Equal(t, uint64(0), logicalTime(ts))
+	assert.Equal(t, uint64(500)<<logicalBits, maxSkew)
+
+	// the physical time not moved forward
+	ts2, _ := c.Now()
+	assert.Equal(t, ts+1, ts2)
+	assert.True(t, c.Compare(ts2, ts) > 0)
+	assert.True(t, c.Compare(ts, ts2) < 0)
+	assert.Equal(t, 0, c.Compare(ts, ts))
+
+	// the physical time goes backwards
+	now = time.Unix(99, 0)
+	ts3, _ := c.Now()
+	assert.Equal(t, ts2+1, ts3)
+
+	// the logical counter overflows
+	c.mu.logical = maxLogical
+	ts4, _ := c.Now()
+	assert.Equal(t, uint64(100001), physicalTime(ts4))
+	assert.Equal(t, uint64(0), logicalTime(ts4))
+
+	now = time.Unix(101, 0)
+	ts5, _ := c.Now()
+	assert.Equal(t, uint64(101000)<<logicalBits, ts5)
+}
+
+func TestHLCUpdate(t *testing.T) {
+	now := time.Unix(100, 0)
+	c := newTestHLCTxnClocker(t, &now)
+	ts, _ := c.Now()
+
+	c.Update(0)
+	ts2, _ := c.Now()
+	assert.Equal(t, ts+1, ts2)
+
+	// the remote clock is behind
+	c.Update(ts - 10)
+	ts3, _ := c.Now()
+	assert.True(t, ts3 > ts2)
+
+	// the remote clock is ahead
+	remote := uint64(100200)<<logicalBits | 10
+	c.Update(remote)
+	ts4, _ := c.Now()
+	assert.True(t, ts4 > remote)
+	assert.Equal(t, uint64(100200), physicalTime(ts4))
+
+	// the same physical time with a greater logical counter
+	remote = uint64(100200)<<logicalBits | 100
+	c.Update(remote)
+	ts5, _ := c.Now()
+	assert.True(t, ts5 > remote)
+
+	// the physical clock catches up
+	now = time.Unix(101, 0)
+	c.Update(remote)
+	ts6, _ := c.Now()
+	assert.Equal(t, uint64(101000), physicalTime(ts6))
+
+	// the remote clock is ahead more than the max clock skew
+	c.Update(uint64(102000) << logicalBits)
+	ts7, _ := c.Now()
+	assert.Equal(t, ts6+1, ts7)
+}
+
+func TestHLCRestart(t *testing.T) {
+	kv := mem.NewStorage()
+	defer kv.Close()
+	store := NewKVTimestampStore(kv, []byte("hlc"))
+
+	now := time.Unix(100, 0)
+	c := newTestHLCTxnClocker(t, &now, WithHLCTimestampStore(store, time.Second))
+	ts, _ := c.Now()
+	persisted, err := store.Load()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(101000), physicalTime(persisted))
+
+	// not persisted again until the physical time reaches the upper bound
+	now = time.Unix(100, int64(time.Millisecond*500))
+	ts, _ = c.Now()
+	v, err := store.Load()
+	require.NoError(t, err)
+	assert.Equal(t, persisted, v)
+
+	// restart with the local clock goes backwards
+	now = time.Unix(90, 0)
+	c = newTestHLCTxnClocker(t, &now, WithHLCTimestampStore(store, time.Second))
+	ts2, _ := c.Now()
+	assert.True(t, ts2 > ts)
+	assert.True(t, ts2 > persisted)
+	v, err = store.Load()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(102000), physicalTime(v))
+}
+
+type failingTimestampStore struct {
+	TimestampStore
+	failures int
+}
+
+func (s *failingTimestampStore) Save(ts uint64) error {
+	if s.failures > 0 {
+		s.failures--
+		return errors.New("save failed")
+	}
+	return s.TimestampStore.Save(ts)
+}
+
+func TestHLCPersistRetry(t *testing.T) {
+	kv := mem.NewStorage()
+	defer kv.Close()
+	store := &failingTimestampStore{TimestampStore: NewKVTimestampStore(kv, []byte("hlc")), failures: 2}
+
+	now := time.Unix(100, 0)
+	c := newTestHLCTxnClocker(t, &now, WithHLCTimestampStore(store, time.Second))
+	ts, _ := c.Now()
+	assert.Equal(t, 0, store.failures)
+	persisted, err := store.Load()
+	require.NoError(t, err)
+	assert.True(t, persisted > ts)
+}
+
+func TestHLCRequestHandler(t *testing.T) {
+	now := time.Unix(100, 0)
+	c := newTestHLCTxnClocker(t, &now)
+
+	var served rpcpb.Request
+	var resp rpcpb.ResponseBatch
+	h := NewHLCRequestHandler(c, nil, func(req rpcpb.Request, cb func(rpcpb.ResponseBatch)) error {
+		served = req
+		cb(rpcpb.ResponseBatch{Responses: []rpcpb.Response{{TxnBatchResponse: &txnpb.TxnBatchResponse{}}}})
+		return nil
+	})
+
+	// the non txn requests are left to the store
+	handled, err := h(rpcpb.Request{Type: rpcpb.Write}, func(rpcpb.ResponseBatch) {})
+	assert.NoError(t, err)
+	assert.False(t, handled)
+
+	remote := uint64(100200)<<logicalBits | 10
+	req := rpcpb.Request{Type: rpcpb.Txn, TxnBatchRequest: &txnpb.TxnBatchRequest{}}
+	req.TxnBatchRequest.Header.Txn.ReadTimestamp = remote
+	handled, err = h(req, func(r rpcpb.ResponseBatch) { resp = r })
+	assert.NoError(t, err)
+	assert.True(t, handled)
+	assert.Equal(t, req, served)
+	assert.True(t, resp.Responses[0].TxnBatchResponse.Header.Timestamp > remote)
+}
@@ -95,6 +95,9 @@ type TxnClocker interface {
 	Compare(ts1, ts2 uint64) int
 	// Next returns the next timestamp of ts
 	Next(ts uint64) uint64
+	// Update updates the clock by the timestamp received from the other nodes, the
+	// timestamps returned by `Now` after update are greater than ts.
+	Update(ts uint64)
 }
 
 var _ TxnIDGenerator = (*uuidTxnIDGenerator)(nil)
@@ -146,3 +149,12 @@ func (tc *mockTxnClocker) Compare(ts1, ts2 uint64) int {
 func (tc *mockTxnClocker) Next(ts uint64) uint64 {
 	return ts + 1
 }
+
+func (tc *mockTxnClocker) Update(ts uint64) {
+	tc.Lock()
+	defer tc.Unlock()
+
+	if ts > tc.ts {
+		tc.ts = ts
+	}
+}
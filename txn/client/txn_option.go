@@ -6,6 +6,10 @@ import (
 	"github.com/matrixorigin/matrixcube/pb/txnpb"
 )
 
+const (
+	defaultMaxAsyncCommitKeys = 256
+)
+
 // TxnOption txn option used to create Transaction
 type TxnOption func(*txnOptions)
 
@@ -13,9 +17,15 @@ type txnOptions struct {
 	name              string
 	isolationLevel    txnpb.IsolationLevel
 	heartbeatDuration time.Duration
-	optimize          struct {
+	pessimistic       struct {
+		enabled         bool
+		lockWaitTimeout time.Duration
+	}
+	optimize struct {
 		asynchronousConsensus bool
 		maxInfilghtKeysBytes  int
+		asyncCommit           bool
+		maxAsyncCommitKeys    int
 	}
 }
 
@@ -26,6 +36,9 @@ func (opts *txnOptions) adjust() {
 	if opts.heartbeatDuration == 0 {
 		opts.heartbeatDuration = time.Second
 	}
+	if opts.optimize.asyncCommit && opts.optimize.maxAsyncCommitKeys <= 0 {
+		opts.optimize.maxAsyncCommitKeys = defaultMaxAsyncCommitKeys
+	}
 }
 
 // WithTxnOptionName set txn name
@@ -66,3 +79,32 @@ func WithTxnOptionEnableAsynchronousConsensus(maxInfilghtKeysBytes int) TxnOptio
 		opts.optimize.maxInfilghtKeysBytes = maxInfilghtKeysBytes
 	}
 }
+
+// WithTxnOptionEnableAsyncCommit enable async commit. By default the commit request is sent to
+// TxnManager after all the in-flight writes have completed the consensus. If async commit is
+// enabled, the commit request is sent in parallel with the WaitConsensus requests of all the
+// written keys, and the transaction is committed once all of them succeed, which saves a round
+// trip of the commit. The commit timestamp is resolved from the maximum read timestamps of the
+// written keys, and the TxnRecord is updated to `Committed` asynchronously. Async commit is not
+// used if the transaction writes any key range or more than `maxKeys` keys, 256 is used if
+// `maxKeys` is 0.
+func WithTxnOptionEnableAsyncCommit(maxKeys int) TxnOption {
+	return func(opts *txnOptions) {
+		opts.optimize.asyncCommit = true
+		opts.optimize.maxAsyncCommitKeys = maxKeys
+	}
+}
+
+// WithTxnOptionPessimistic enable pessimistic locking mode. In pessimistic mode, the point keys
+// written by `Write` and `WriteAndCommit` are locked before the write, and the keys can also be
+// locked explicitly by `Lock`. The keys locked by another transaction can not be locked until
+// the lock is released by commit or rollback, or expired due to the heartbeat stopped. The lock
+// waits at most `lockWaitTimeout` in the lock wait queue of the key, and 0 means no wait. The
+// locks are renewed by the txn heartbeat, and the deadlock found when waiting for the lock is
+// reported as `ErrTxnDeadlock`.
+func WithTxnOptionPessimistic(lockWaitTimeout time.Duration) TxnOption {
+	return func(opts *txnOptions) {
+		opts.pessimistic.enabled = true
+		opts.pessimistic.lockWaitTimeout = lockWaitTimeout
+	}
+}
@@ -0,0 +1,297 @@
+// Copyright 2022 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lock
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/fagongzi/util/hack"
+	"github.com/matrixorigin/matrixcube/components/log"
+	"github.com/matrixorigin/matrixcube/pb/txnpb"
+	"go.uber.org/zap"
+)
+
+var (
+	// ErrDeadlock waiting for the lock causes a deadlock
+	ErrDeadlock = errors.New("deadlock found when waiting for the lock")
+	// ErrLockWaitTimeout waiting for the lock timeout
+	ErrLockWaitTimeout = errors.New("lock wait timeout")
+)
+
+const (
+	minRetryInterval = time.Millisecond * 10
+	maxRetryInterval = time.Second
+	// detectDeadlockInterval is the interval the waiters add their wait-for edges to
+	// the deadlock detector again. It must be less than the TTL of the wait-for edges
+	// kept by the prophet leader, so the edges are kept as long as the txns wait, and
+	// point to the current holders of the locks.
+	detectDeadlockInterval = time.Second * 3
+)
+
+// DeadlockDetector detects the deadlock of the txns waiting for the pessimistic
+// locks. The prophet client implements it by the wait-for graph kept by the
+// prophet leader, so the deadlocks across the shards are detected.
+type DeadlockDetector interface {
+	// DetectDeadlock adds the wait-for edge, returns true if the edge causes a
+	// deadlock, and the edge is not added.
+	DetectDeadlock(edge txnpb.WaitForEdge) (bool, error)
+	// CleanUpWaitFor removes all the wait-for edges of the waiting txn.
+	CleanUpWaitFor(txnID []byte) error
+}
+
+// Storage keeps the pessimistic locks. The locks kept by a storage replicated by
+// raft, e.g. the one returned by NewExecutorStorage, survive the leader changes of
+// the shards and the restarts of the stores.
+type Storage interface {
+	// Lock locks the key for the txn with the TTL, the TTL is renewed if the txn
+	// already holds the lock. Returns the txn holding the lock if the key is
+	// locked by another txn, nil if the lock is held by the txn.
+	Lock(ctx context.Context, key, txnID []byte, ttl time.Duration) ([]byte, error)
+	// Unlock releases the lock of the key if it's held by the txn.
+	Unlock(ctx context.Context, key, txnID []byte) error
+}
+
+// Table is the pessimistic lock table of a shard group on a store. The locks are
+// kept in the Storage, the table only keeps the wait queues of the locked keys in
+// memory. The txns locking a key wait in the wait queue of the key, and retry to
+// lock the key in FIFO order once the lock is released by the table. The first
+// waiter also retries periodically, since the lock can be expired, or released
+// by another store, e.g. the one received the commit of the txn holding the lock.
+// A lock is kept for the TTL unless renewed by the heartbeat of the txn, so the
+// locks of the crashed txns do not block the others forever. The wait queues are
+// lost if the store is no longer the leader, the waiters are retried by the
+// clients then.
+type Table struct {
+	logger   *zap.Logger
+	group    uint64
+	ttl      time.Duration
+	storage  Storage
+	detector DeadlockDetector
+
+	mu struct {
+		sync.Mutex
+		queues map[string][]*waiter
+	}
+}
+
+type waiter struct {
+	txnID   []byte
+	notifyC chan struct{}
+}
+
+// NewTable returns a lock table of the shard group, the detector can be nil if the
+// deadlock detection is not required, then the deadlocked txns wait until timeout.
+func NewTable(group uint64, ttl time.Duration, storage Storage, detector DeadlockDetector, logger *zap.Logger) *Table {
+	t := &Table{
+		logger:   log.Adjust(logger).Named("lock-table"),
+		group:    group,
+		ttl:      ttl,
+		storage:  storage,
+		detector: detector,
+	}
+	t.mu.queues = make(map[string][]*waiter)
+	return t
+}
+
+// Acquire locks the key for the txn, the lock of the txn is renewed if the txn
+// already holds it. If the key is locked by another txn, it waits for the lock
+// at most waitTimeout, ErrLockWaitTimeout is returned if timeout, and ErrDeadlock
+// is returned if waiting for the lock causes a deadlock.
+func (t *Table) Acquire(ctx context.Context, txnID, key []byte, waitTimeout time.Duration) error {
+	holder, err := t.storage.Lock(ctx, t.lockKey(key), txnID, t.ttl)
+	if err != nil || holder == nil {
+		return err
+	}
+	if waitTimeout <= 0 {
+		return ErrLockWaitTimeout
+	}
+
+	w := t.addWaiter(key, txnID)
+	defer t.removeWaiter(key, w)
+	if t.detectDeadlock(txnID, holder, key) {
+		return ErrDeadlock
+	}
+	defer t.cleanUpWaitFor(txnID)
+
+	timer := time.NewTimer(waitTimeout)
+	defer timer.Stop()
+	retry := time.NewTicker(t.retryInterval())
+	defer retry.Stop()
+	detect := time.NewTicker(detectDeadlockInterval)
+	defer detect.Stop()
+	for {
+		select {
+		case <-w.notifyC:
+		case <-retry.C:
+			if !t.isFirstWaiter(key, w) {
+				continue
+			}
+		case <-detect.C:
+			if t.detectDeadlock(txnID, holder, key) {
+				return ErrDeadlock
+			}
+			continue
+		case <-timer.C:
+			return ErrLockWaitTimeout
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+
+		holder, err = t.storage.Lock(ctx, t.lockKey(key), txnID, t.ttl)
+		if err != nil || holder == nil {
+			return err
+		}
+	}
+}
+
+// Release releases the locks of the keys held by the txn, the first waiter of the
+// key is notified to lock the key.
+func (t *Table) Release(ctx context.Context, txnID []byte, keys [][]byte) error {
+	for _, key := range keys {
+		if err := t.storage.Unlock(ctx, t.lockKey(key), txnID); err != nil {
+			return err
+		}
+		t.notifyFirstWaiter(key)
+	}
+	return nil
+}
+
+// Renew renews the TTL of the locks of the keys held by the txn.
+func (t *Table) Renew(ctx context.Context, txnID []byte, keys [][]byte) error {
+	for _, key := range keys {
+		holder, err := t.storage.Lock(ctx, t.lockKey(key), txnID, t.ttl)
+		if err != nil {
+			return err
+		}
+		if holder != nil {
+			t.logger.Warn("lock expired before renewed",
+				log.TxnIDField(txnID),
+				log.HexField("key", key),
+				log.HexField("holder", holder))
+		}
+	}
+	return nil
+}
+
+func (t *Table) lockKey(key []byte) []byte {
+	v := make([]byte, 8+len(key))
+	binary.BigEndian.PutUint64(v, t.group)
+	copy(v[8:], key)
+	return v
+}
+
+func (t *Table) addWaiter(key, txnID []byte) *waiter {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	w := &waiter{txnID: txnID, notifyC: make(chan struct{}, 1)}
+	t.mu.queues[string(key)] = append(t.mu.queues[string(key)], w)
+	return w
+}
+
+// removeWaiter removes the waiter from the wait queue of the key, the next waiter
+// is notified if the first waiter removed.
+func (t *Table) removeWaiter(key []byte, w *waiter) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	waiters := t.mu.queues[hack.SliceToString(key)]
+	for idx, v := range waiters {
+		if v == w {
+			waiters = append(waiters[:idx], waiters[idx+1:]...)
+			if idx == 0 && len(waiters) > 0 {
+				notify(waiters[0])
+			}
+			break
+		}
+	}
+	if len(waiters) == 0 {
+		delete(t.mu.queues, hack.SliceToString(key))
+		return
+	}
+	t.mu.queues[hack.SliceToString(key)] = waiters
+}
+
+func (t *Table) isFirstWaiter(key []byte, w *waiter) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	waiters := t.mu.queues[hack.SliceToString(key)]
+	return len(waiters) > 0 && waiters[0] == w
+}
+
+func (t *Table) notifyFirstWaiter(key []byte) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if waiters := t.mu.queues[hack.SliceToString(key)]; len(waiters) > 0 {
+		notify(waiters[0])
+	}
+}
+
+func notify(w *waiter) {
+	select {
+	case w.notifyC <- struct{}{}:
+	default:
+	}
+}
+
+// detectDeadlock adds the wait-for edge of the txn to the detector, returns true
+// if the edge causes a deadlock.
+func (t *Table) detectDeadlock(txnID, holder, key []byte) bool {
+	if t.detector == nil {
+		return false
+	}
+
+	deadlock, err := t.detector.DetectDeadlock(txnpb.WaitForEdge{
+		TxnID:        txnID,
+		WaitForTxnID: holder,
+		Key:          key,
+	})
+	if err != nil {
+		// keep waiting, the deadlocked txns wait until timeout
+		t.logger.Error("failed to detect deadlock",
+			log.TxnIDField(txnID),
+			log.HexField("key", key),
+			zap.Error(err))
+		return false
+	}
+	return deadlock
+}
+
+func (t *Table) cleanUpWaitFor(txnID []byte) {
+	if t.detector == nil {
+		return
+	}
+	if err := t.detector.CleanUpWaitFor(txnID); err != nil {
+		t.logger.Error("failed to clean up wait-for edges",
+			log.TxnIDField(txnID),
+			zap.Error(err))
+	}
+}
+
+func (t *Table) retryInterval() time.Duration {
+	interval := t.ttl / 4
+	if interval < minRetryInterval {
+		return minRetryInterval
+	}
+	if interval > maxRetryInterval {
+		return maxRetryInterval
+	}
+	return interval
+}
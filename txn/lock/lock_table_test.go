@@ -0,0 +1,222 @@
+// Copyright 2022 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lock
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/matrixorigin/matrixcube/pb/txnpb"
+	"github.com/stretchr/testify/assert"
+)
+
+type testDetector struct {
+	sync.Mutex
+	deadlock bool
+	edges    []txnpb.WaitForEdge
+	cleanUps [][]byte
+}
+
+func (d *testDetector) DetectDeadlock(edge txnpb.WaitForEdge) (bool, error) {
+	d.Lock()
+	defer d.Unlock()
+	d.edges = append(d.edges, edge)
+	return d.deadlock, nil
+}
+
+func (d *testDetector) getEdges() []txnpb.WaitForEdge {
+	d.Lock()
+	defer d.Unlock()
+	return append([]txnpb.WaitForEdge(nil), d.edges...)
+}
+
+func (d *testDetector) CleanUpWaitFor(txnID []byte) error {
+	d.Lock()
+	defer d.Unlock()
+	d.cleanUps = append(d.cleanUps, txnID)
+	return nil
+}
+
+type testLock struct {
+	txnID    []byte
+	expireAt time.Time
+}
+
+type testStorage struct {
+	mu    sync.Mutex
+	locks map[string]testLock
+}
+
+func newTestStorage() *testStorage {
+	return &testStorage{locks: make(map[string]testLock)}
+}
+
+func (s *testStorage) Lock(ctx context.Context, key, txnID []byte, ttl time.Duration) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	if l, ok := s.locks[string(key)]; ok && !bytes.Equal(l.txnID, txnID) && now.Before(l.expireAt) {
+		return l.txnID, nil
+	}
+	s.locks[string(key)] = testLock{txnID: txnID, expireAt: now.Add(ttl)}
+	return nil, nil
+}
+
+func (s *testStorage) Unlock(ctx context.Context, key, txnID []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if l, ok := s.locks[string(key)]; ok && bytes.Equal(l.txnID, txnID) {
+		delete(s.locks, string(key))
+	}
+	return nil
+}
+
+func (s *testStorage) holder(t *Table, key []byte) ([]byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	l, ok := s.locks[string(t.lockKey(key))]
+	if !ok || !time.Now().Before(l.expireAt) {
+		return nil, false
+	}
+	return l.txnID, true
+}
+
+func (t *Table) waiters(key []byte) int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return len(t.mu.queues[string(key)])
+}
+
+func TestAcquireAndRelease(t *testing.T) {
+	s := newTestStorage()
+	table := NewTable(1, time.Minute, s, nil, nil)
+	ctx := context.Background()
+
+	assert.NoError(t, table.Acquire(ctx, []byte("t1"), []byte("k1"), 0))
+	// re-entrant
+	assert.NoError(t, table.Acquire(ctx, []byte("t1"), []byte("k1"), 0))
+	assert.Equal(t, ErrLockWaitTimeout, table.Acquire(ctx, []byte("t2"), []byte("k1"), 0))
+	// the locks of the groups are separated
+	assert.NoError(t, NewTable(2, time.Minute, s, nil, nil).Acquire(ctx, []byte("t2"), []byte("k1"), 0))
+
+	// release by other txn is ignored
+	assert.NoError(t, table.Release(ctx, []byte("t2"), [][]byte{[]byte("k1")}))
+	holder, ok := s.holder(table, []byte("k1"))
+	assert.True(t, ok)
+	assert.Equal(t, []byte("t1"), holder)
+
+	assert.NoError(t, table.Release(ctx, []byte("t1"), [][]byte{[]byte("k1")}))
+	_, ok = s.holder(table, []byte("k1"))
+	assert.False(t, ok)
+}
+
+func TestAcquireWaitInOrder(t *testing.T) {
+	s := newTestStorage()
+	table := NewTable(1, time.Minute, s, nil, nil)
+	ctx := context.Background()
+	assert.NoError(t, table.Acquire(ctx, []byte("t1"), []byte("k1"), 0))
+
+	var wg sync.WaitGroup
+	granted := make(chan string, 2)
+	for idx, txn := range []string{"t2", "t3"} {
+		wg.Add(1)
+		go func(txn string) {
+			defer wg.Done()
+			assert.NoError(t, table.Acquire(ctx, []byte(txn), []byte("k1"), time.Minute))
+			granted <- txn
+			assert.NoError(t, table.Release(ctx, []byte(txn), [][]byte{[]byte("k1")}))
+		}(txn)
+		// keep the order of the waiters
+		for table.waiters([]byte("k1")) != idx+1 {
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	assert.NoError(t, table.Release(ctx, []byte("t1"), [][]byte{[]byte("k1")}))
+	wg.Wait()
+	assert.Equal(t, "t2", <-granted)
+	assert.Equal(t, "t3", <-granted)
+	_, ok := s.holder(table, []byte("k1"))
+	assert.False(t, ok)
+	assert.Equal(t, 0, table.waiters([]byte("k1")))
+}
+
+func TestAcquireWaitTimeout(t *testing.T) {
+	detector := &testDetector{}
+	table := NewTable(1, time.Minute, newTestStorage(), detector, nil)
+	ctx := context.Background()
+	assert.NoError(t, table.Acquire(ctx, []byte("t1"), []byte("k1"), 0))
+	assert.Equal(t, ErrLockWaitTimeout,
+		table.Acquire(ctx, []byte("t2"), []byte("k1"), time.Millisecond*20))
+
+	assert.Equal(t, 0, table.waiters([]byte("k1")))
+	assert.Equal(t, []txnpb.WaitForEdge{{TxnID: []byte("t2"), WaitForTxnID: []byte("t1"), Key: []byte("k1")}},
+		detector.getEdges())
+	assert.Equal(t, [][]byte{[]byte("t2")}, detector.cleanUps)
+}
+
+func TestAcquireWithDeadlock(t *testing.T) {
+	detector := &testDetector{deadlock: true}
+	table := NewTable(1, time.Minute, newTestStorage(), detector, nil)
+	ctx := context.Background()
+	assert.NoError(t, table.Acquire(ctx, []byte("t1"), []byte("k1"), 0))
+	assert.Equal(t, ErrDeadlock, table.Acquire(ctx, []byte("t2"), []byte("k1"), time.Minute))
+	assert.Equal(t, 0, table.waiters([]byte("k1")))
+}
+
+func TestAcquireExpiredLock(t *testing.T) {
+	s := newTestStorage()
+	table := NewTable(1, time.Millisecond*50, s, nil, nil)
+	ctx := context.Background()
+	assert.NoError(t, table.Acquire(ctx, []byte("t1"), []byte("k1"), 0))
+	assert.NoError(t, table.Acquire(ctx, []byte("t2"), []byte("k1"), time.Second))
+	holder, _ := s.holder(table, []byte("k1"))
+	assert.Equal(t, []byte("t2"), holder)
+}
+
+func TestAcquireLockReleasedByOtherTable(t *testing.T) {
+	// the tables of the old and new leader stores share the replicated locks
+	s := newTestStorage()
+	old := NewTable(1, time.Minute, s, nil, nil)
+	table := NewTable(1, time.Minute, s, nil, nil)
+	ctx := context.Background()
+	assert.NoError(t, old.Acquire(ctx, []byte("t1"), []byte("k1"), 0))
+	assert.Equal(t, ErrLockWaitTimeout, table.Acquire(ctx, []byte("t2"), []byte("k1"), 0))
+
+	c := make(chan error)
+	go func() {
+		c <- table.Acquire(ctx, []byte("t2"), []byte("k1"), time.Minute)
+	}()
+	for table.waiters([]byte("k1")) != 1 {
+		time.Sleep(time.Millisecond)
+	}
+	assert.NoError(t, old.Release(ctx, []byte("t1"), [][]byte{[]byte("k1")}))
+	assert.NoError(t, <-c)
+	holder, _ := s.holder(table, []byte("k1"))
+	assert.Equal(t, []byte("t2"), holder)
+}
+
+func TestRenew(t *testing.T) {
+	table := NewTable(1, time.Millisecond*100, newTestStorage(), nil, nil)
+	ctx := context.Background()
+	assert.NoError(t, table.Acquire(ctx, []byte("t1"), []byte("k1"), 0))
+	for i := 0; i < 4; i++ {
+		time.Sleep(time.Millisecond * 40)
+		assert.NoError(t, table.Renew(ctx, []byte("t1"), [][]byte{[]byte("k1")}))
+	}
+	assert.Equal(t, ErrLockWaitTimeout, table.Acquire(ctx, []byte("t2"), []byte("k1"), 0))
+}
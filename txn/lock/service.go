@@ -0,0 +1,178 @@
+// Copyright 2022 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lock
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/matrixorigin/matrixcube/components/log"
+	"github.com/matrixorigin/matrixcube/pb/errorpb"
+	"github.com/matrixorigin/matrixcube/pb/rpcpb"
+	"github.com/matrixorigin/matrixcube/pb/txnpb"
+	"github.com/matrixorigin/matrixcube/util/stop"
+	"go.uber.org/zap"
+)
+
+// Service serves the PessimisticLock and RenewLock requests of the txns in the
+// pessimistic mode on a store, and releases the pessimistic locks attached to the
+// commit and rollback requests. It's installed by `HandleRequest` as the
+// `Config.Customize.CustomShardProxyRequestHandler` of the store, the other
+// requests are left to the store.
+type Service struct {
+	logger   *zap.Logger
+	ttl      time.Duration
+	storage  Storage
+	detector DeadlockDetector
+	stopper  *stop.Stopper
+
+	mu struct {
+		sync.Mutex
+		tables map[uint64]*Table
+	}
+}
+
+// NewService returns the lock service keeping the locks with the TTL in the storage,
+// the detector can be nil if the deadlock detection is not required.
+func NewService(ttl time.Duration, storage Storage, detector DeadlockDetector, logger *zap.Logger) *Service {
+	s := &Service{
+		logger:   log.Adjust(logger).Named("lock-service"),
+		ttl:      ttl,
+		storage:  storage,
+		detector: detector,
+	}
+	s.stopper = stop.NewStopper("lock-service", stop.WithLogger(s.logger))
+	s.mu.tables = make(map[uint64]*Table)
+	return s
+}
+
+// Close stops the service, the waiting lock requests are canceled.
+func (s *Service) Close() {
+	s.stopper.Stop()
+}
+
+// HandleRequest handles the txn request if it only contains the PessimisticLock or
+// RenewLock requests, the response is returned by the cb once all the locks are
+// acquired or renewed. The locks of the commit and rollback requests are released
+// in the background, and the requests are left to the store.
+func (s *Service) HandleRequest(req rpcpb.Request, cb func(rpcpb.ResponseBatch)) (bool, error) {
+	if req.Type != rpcpb.Txn || req.TxnBatchRequest == nil {
+		return false, nil
+	}
+
+	batch := *req.TxnBatchRequest
+	if batch.HasCommitOrRollback() {
+		if len(batch.Header.Txn.LockedKeys) > 0 {
+			s.release(batch.Header.Txn)
+		}
+		return false, nil
+	}
+	if !isLockBatch(batch) {
+		return false, nil
+	}
+
+	err := s.stopper.RunTask(context.Background(), func(ctx context.Context) {
+		resp := rpcpb.Response{ID: req.ID, PID: req.PID, Type: rpcpb.Txn}
+		txnResp, err := s.handle(ctx, batch)
+		if err != nil {
+			cb(rpcpb.ResponseBatch{
+				Header:    rpcpb.ResponseBatchHeader{Error: errorpb.Error{Message: err.Error()}},
+				Responses: []rpcpb.Response{resp},
+			})
+			return
+		}
+		resp.TxnBatchResponse = &txnResp
+		cb(rpcpb.ResponseBatch{Responses: []rpcpb.Response{resp}})
+	})
+	return err == nil, err
+}
+
+func (s *Service) handle(ctx context.Context, batch txnpb.TxnBatchRequest) (txnpb.TxnBatchResponse, error) {
+	txnID := batch.Header.Txn.ID
+	resp := txnpb.TxnBatchResponse{}
+	resp.Header.Txn = batch.Header.Txn.TxnMeta
+	for _, req := range batch.Requests {
+		table := s.getTable(req.Operation.ShardGroup)
+		keys := req.Operation.Impacted.PointKeys
+		if req.IsRenewLock() {
+			if err := table.Renew(ctx, txnID, keys); err != nil {
+				return txnpb.TxnBatchResponse{}, err
+			}
+		} else {
+			waitTimeout := time.Duration(req.Options.LockWaitTimeout) * time.Millisecond
+			for _, key := range keys {
+				switch err := table.Acquire(ctx, txnID, key, waitTimeout); err {
+				case nil:
+				case ErrDeadlock:
+					resp.Header.Error = &txnpb.TxnError{DeadlockError: &txnpb.DeadlockError{Key: key}}
+					return resp, nil
+				case ErrLockWaitTimeout:
+					resp.Header.Error = &txnpb.TxnError{LockWaitTimeoutError: &txnpb.LockWaitTimeoutError{Key: key}}
+					return resp, nil
+				default:
+					return txnpb.TxnBatchResponse{}, err
+				}
+			}
+		}
+		resp.Responses = append(resp.Responses, txnpb.TxnResponse{})
+	}
+	return resp, nil
+}
+
+func (s *Service) release(txn txnpb.TxnOpMeta) {
+	groups := make([]uint64, 0, len(txn.LockedKeys))
+	for g := range txn.LockedKeys {
+		groups = append(groups, g)
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		return groups[i] < groups[j]
+	})
+
+	s.stopper.RunTask(context.Background(), func(ctx context.Context) {
+		for _, g := range groups {
+			if err := s.getTable(g).Release(ctx, txn.ID, txn.LockedKeys[g].PointKeys); err != nil {
+				// the locks are expired after the TTL
+				s.logger.Error("failed to release locks",
+					log.TxnIDField(txn.ID),
+					zap.Uint64("group", g),
+					zap.Error(err))
+			}
+		}
+	})
+}
+
+func (s *Service) getTable(group uint64) *Table {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if t, ok := s.mu.tables[group]; ok {
+		return t
+	}
+	t := NewTable(group, s.ttl, s.storage, s.detector, s.logger)
+	s.mu.tables[group] = t
+	return t
+}
+
+// isLockBatch returns true if the batch only contains the PessimisticLock or
+// RenewLock requests.
+func isLockBatch(batch txnpb.TxnBatchRequest) bool {
+	for _, req := range batch.Requests {
+		if !req.IsPessimisticLock() && !req.IsRenewLock() {
+			return false
+		}
+	}
+	return len(batch.Requests) > 0
+}
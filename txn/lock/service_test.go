@@ -0,0 +1,89 @@
+// Copyright 2022 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lock
+
+import (
+	"testing"
+	"time"
+
+	"github.com/matrixorigin/matrixcube/pb/rpcpb"
+	"github.com/matrixorigin/matrixcube/pb/txnpb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServiceHandleRequest(t *testing.T) {
+	s := newTestStorage()
+	service := NewService(time.Minute, s, nil, nil)
+	defer service.Close()
+
+	newRequest := func(txn string, requests ...txnpb.TxnRequest) rpcpb.Request {
+		batch := txnpb.TxnBatchRequest{Requests: requests}
+		batch.Header.Txn.ID = []byte(txn)
+		return rpcpb.Request{ID: []byte(txn), Type: rpcpb.Txn, TxnBatchRequest: &batch}
+	}
+	newLock := func(op txnpb.InternalTxnOp, keys ...string) txnpb.TxnRequest {
+		req := txnpb.TxnRequest{Operation: txnpb.TxnOperation{Op: uint32(op), ShardGroup: 1}}
+		for _, key := range keys {
+			req.Operation.Impacted.PointKeys = append(req.Operation.Impacted.PointKeys, []byte(key))
+		}
+		return req
+	}
+	handle := func(req rpcpb.Request) txnpb.TxnBatchResponse {
+		c := make(chan rpcpb.ResponseBatch, 1)
+		handled, err := service.HandleRequest(req, func(resp rpcpb.ResponseBatch) {
+			c <- resp
+		})
+		assert.NoError(t, err)
+		assert.True(t, handled)
+		resp := <-c
+		assert.Empty(t, resp.Header.Error.Message)
+		assert.Equal(t, req.ID, resp.Responses[0].ID)
+		return *resp.Responses[0].TxnBatchResponse
+	}
+
+	// the other requests are left to the store
+	handled, err := service.HandleRequest(rpcpb.Request{Type: rpcpb.Write}, nil)
+	assert.NoError(t, err)
+	assert.False(t, handled)
+	handled, err = service.HandleRequest(newRequest("t1", newLock(txnpb.InternalTxnOp_Heartbeat),
+		newLock(txnpb.InternalTxnOp_RenewLock, "k1")), nil)
+	assert.NoError(t, err)
+	assert.False(t, handled)
+
+	resp := handle(newRequest("t1", newLock(txnpb.InternalTxnOp_PessimisticLock, "k1", "k2")))
+	assert.Nil(t, resp.Header.Error)
+	assert.Equal(t, []byte("t1"), resp.Header.Txn.ID)
+	assert.Equal(t, 1, len(resp.Responses))
+	resp = handle(newRequest("t1", newLock(txnpb.InternalTxnOp_RenewLock, "k1", "k2")))
+	assert.Nil(t, resp.Header.Error)
+	resp = handle(newRequest("t2", newLock(txnpb.InternalTxnOp_PessimisticLock, "k2")))
+	assert.Equal(t, []byte("k2"), resp.Header.Error.LockWaitTimeoutError.Key)
+
+	// the locks are released by the commit, which is left to the store
+	commit := newRequest("t1", newLock(txnpb.InternalTxnOp_Commit))
+	commit.TxnBatchRequest.Header.Txn.LockedKeys = map[uint64]txnpb.KeySet{
+		1: {PointKeys: [][]byte{[]byte("k1"), []byte("k2")}},
+	}
+	handled, err = service.HandleRequest(commit, nil)
+	assert.NoError(t, err)
+	assert.False(t, handled)
+	for {
+		if _, ok := s.holder(service.getTable(1), []byte("k2")); !ok {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	resp = handle(newRequest("t2", newLock(txnpb.InternalTxnOp_PessimisticLock, "k2")))
+	assert.Nil(t, resp.Header.Error)
+}
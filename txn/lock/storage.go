@@ -0,0 +1,87 @@
+// Copyright 2022 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lock
+
+import (
+	"bytes"
+	"context"
+	"time"
+
+	"github.com/matrixorigin/matrixcube/client"
+	"github.com/matrixorigin/matrixcube/storage"
+	lockExecutor "github.com/matrixorigin/matrixcube/storage/executor/lock"
+)
+
+type executorStorage struct {
+	client  client.Client
+	group   uint64
+	timeout time.Duration
+}
+
+// NewExecutorStorage returns the Storage keeping the pessimistic locks in the shard
+// group served by the lock executor, see `storage/executor/lock`. The locks are
+// replicated by raft, so they are kept after the leaders of the shards of the txns
+// changed. The timeout is the timeout of each request sent to the shard group.
+func NewExecutorStorage(c client.Client, group uint64, timeout time.Duration) Storage {
+	return &executorStorage{client: c, group: group, timeout: timeout}
+}
+
+func (s *executorStorage) Lock(ctx context.Context, key, txnID []byte, ttl time.Duration) ([]byte, error) {
+	result, err := s.write(ctx, lockExecutor.NewAcquireRequest(key, txnID, ttl))
+	if err != nil {
+		return nil, err
+	}
+	if result.Succeeded {
+		return nil, nil
+	}
+	return result.Lock.Owner, nil
+}
+
+func (s *executorStorage) Unlock(ctx context.Context, key, txnID []byte) error {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	req := lockExecutor.NewGetRequest(key)
+	f := s.client.Read(ctx, req.CmdType, req.Cmd,
+		client.WithShardGroup(s.group), client.WithRouteKey(req.Key))
+	defer f.Close()
+	resp, err := f.Get()
+	if err != nil {
+		return err
+	}
+	result, err := lockExecutor.DecodeResult(resp)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(result.Lock.Owner, txnID) {
+		return nil
+	}
+
+	_, err = s.write(ctx, lockExecutor.NewReleaseRequest(key, txnID, result.Lock.Token))
+	return err
+}
+
+func (s *executorStorage) write(ctx context.Context, req storage.Request) (lockExecutor.Result, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	f := s.client.Write(ctx, req.CmdType, req.Cmd,
+		client.WithShardGroup(s.group), client.WithRouteKey(req.Key))
+	defer f.Close()
+	resp, err := f.Get()
+	if err != nil {
+		return lockExecutor.Result{}, err
+	}
+	return lockExecutor.DecodeResult(resp)
+}
@@ -0,0 +1,244 @@
+// Copyright 2022 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tscache
+
+import (
+	"bytes"
+	"sync"
+
+	"github.com/fagongzi/util/hack"
+	"github.com/matrixorigin/matrixcube/aware"
+	"github.com/matrixorigin/matrixcube/pb/metapb"
+	"github.com/matrixorigin/matrixcube/pb/rpcpb"
+	"github.com/matrixorigin/matrixcube/pb/txnpb"
+	"github.com/matrixorigin/matrixcube/txn/client"
+)
+
+const (
+	defaultMaxKeys = 100000
+)
+
+// Cache is the timestamp cache of the shards led by a store, which keeps the max
+// timestamps the keys of the shards were read at by the txns. The async commit
+// resolves the commit timestamp from the max read timestamps returned with the
+// WaitConsensus responses, the commit timestamp is greater than all of them, so
+// the reads are not changed by the committed txn.
+//
+// The cache is installed by `HandleRequest` as the
+// `Config.Customize.CustomShardProxyRequestHandler` of the store, and it must be
+// notified of the leader changes as a `aware.ShardStateAware`. The reads served
+// by the other stores are unknown, so the low watermark of a shard is set to the
+// max timestamp of the cluster once the store becomes the leader, i.e. now plus
+// the max clock skew.
+type Cache struct {
+	clocker client.TxnClocker
+	maxKeys int
+	next    func(rpcpb.Request, func(rpcpb.ResponseBatch)) error
+
+	mu struct {
+		sync.Mutex
+		shards map[uint64]*shardCache
+	}
+}
+
+var _ aware.ShardStateAware = (*Cache)(nil)
+
+type shardCache struct {
+	// lowWater the max read timestamp of the keys not in the cache
+	lowWater uint64
+	keys     map[string]uint64
+	ranges   []readRange
+}
+
+type readRange struct {
+	start, end []byte
+	ts         uint64
+}
+
+// NewCache returns the timestamp cache, which keeps at most maxKeys keys and key
+// ranges of each shard, the low watermark is raised if exceeded. The next is the
+// `OnRequestWithCB` of the store, which serves the requests handled by the cache.
+func NewCache(clocker client.TxnClocker, maxKeys int,
+	next func(rpcpb.Request, func(rpcpb.ResponseBatch)) error) *Cache {
+	if maxKeys <= 0 {
+		maxKeys = defaultMaxKeys
+	}
+	c := &Cache{clocker: clocker, maxKeys: maxKeys, next: next}
+	c.mu.shards = make(map[uint64]*shardCache)
+	return c
+}
+
+// HandleRequest records the read timestamps of the txn read requests, and returns
+// the max read timestamps of the keys of the WaitConsensus requests, which are sent
+// by the async commit to the shards of the written keys. The requests are served by
+// the store.
+func (c *Cache) HandleRequest(req rpcpb.Request, cb func(rpcpb.ResponseBatch)) (bool, error) {
+	if req.Type != rpcpb.Txn || req.TxnBatchRequest == nil {
+		return false, nil
+	}
+
+	batch := *req.TxnBatchRequest
+	if batch.Header.Type == txnpb.TxnRequestType_Read {
+		c.addReads(req.ToShard, batch)
+		return false, nil
+	}
+	if !hasWaitConsensus(batch) {
+		return false, nil
+	}
+
+	return true, c.next(req, func(resp rpcpb.ResponseBatch) {
+		for idx := range resp.Responses {
+			if resp.Responses[idx].TxnBatchResponse != nil {
+				c.setMaxReadTimestamps(req.ToShard, batch, resp.Responses[idx].TxnBatchResponse)
+			}
+		}
+		cb(resp)
+	})
+}
+
+// GetMaxReadTimestamp returns the max timestamp the key of the shard was read at.
+func (c *Cache) GetMaxReadTimestamp(shard uint64, key []byte) uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	sc := c.getShardLocked(shard)
+	ts := sc.lowWater
+	if v, ok := sc.keys[hack.SliceToString(key)]; ok && v > ts {
+		ts = v
+	}
+	for _, r := range sc.ranges {
+		if r.ts > ts && bytes.Compare(key, r.start) >= 0 &&
+			(len(r.end) == 0 || bytes.Compare(key, r.end) < 0) {
+			ts = r.ts
+		}
+	}
+	return ts
+}
+
+func (c *Cache) addReads(shard uint64, batch txnpb.TxnBatchRequest) {
+	ts := batch.Header.Txn.ReadTimestamp
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	sc := c.getShardLocked(shard)
+	for _, req := range batch.Requests {
+		if req.IsInternal() {
+			continue
+		}
+		for _, key := range req.Operation.Impacted.PointKeys {
+			if v, ok := sc.keys[hack.SliceToString(key)]; !ok || v < ts {
+				sc.keys[string(key)] = ts
+			}
+		}
+		for _, r := range req.Operation.Impacted.Ranges {
+			sc.ranges = append(sc.ranges, readRange{start: r.Start, end: r.End, ts: ts})
+		}
+	}
+	if len(sc.keys)+len(sc.ranges) > c.maxKeys {
+		sc.evict()
+	}
+}
+
+func (c *Cache) setMaxReadTimestamps(shard uint64, batch txnpb.TxnBatchRequest, resp *txnpb.TxnBatchResponse) {
+	for idx, req := range batch.Requests {
+		if idx >= len(resp.Responses) {
+			return
+		}
+		if isWaitConsensus(req) && len(req.Operation.Impacted.PointKeys) > 0 {
+			resp.Responses[idx].MaxReadTimestamp = c.GetMaxReadTimestamp(shard,
+				req.Operation.Impacted.PointKeys[0])
+		}
+	}
+}
+
+func (c *Cache) getShardLocked(shard uint64) *shardCache {
+	if sc, ok := c.mu.shards[shard]; ok {
+		return sc
+	}
+
+	now, maxSkew := c.clocker.Now()
+	sc := &shardCache{
+		lowWater: now + maxSkew,
+		keys:     make(map[string]uint64),
+	}
+	c.mu.shards[shard] = sc
+	return sc
+}
+
+// resetShard drops the cache of the shard, which is created with a new low watermark
+// on the next access.
+func (c *Cache) resetShard(shard uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.mu.shards, shard)
+}
+
+// evict drops all the keys and ranges, the low watermark is raised to the max read
+// timestamp of them.
+func (sc *shardCache) evict() {
+	for _, ts := range sc.keys {
+		if ts > sc.lowWater {
+			sc.lowWater = ts
+		}
+	}
+	for _, r := range sc.ranges {
+		if r.ts > sc.lowWater {
+			sc.lowWater = r.ts
+		}
+	}
+	sc.keys = make(map[string]uint64)
+	sc.ranges = nil
+}
+
+func hasWaitConsensus(batch txnpb.TxnBatchRequest) bool {
+	for _, req := range batch.Requests {
+		if isWaitConsensus(req) {
+			return true
+		}
+	}
+	return false
+}
+
+func isWaitConsensus(req txnpb.TxnRequest) bool {
+	return req.Operation.Op == uint32(txnpb.InternalTxnOp_WaitConsensus)
+}
+
+// Created implements aware.ShardStateAware
+func (c *Cache) Created(metapb.Shard) {}
+
+// Updated implements aware.ShardStateAware
+func (c *Cache) Updated(metapb.Shard) {}
+
+// Splited implements aware.ShardStateAware
+func (c *Cache) Splited(metapb.Shard) {}
+
+// Destroyed implements aware.ShardStateAware
+func (c *Cache) Destroyed(shard metapb.Shard) {
+	c.resetShard(shard.ID)
+}
+
+// BecomeLeader implements aware.ShardStateAware, the reads served by the previous
+// leaders are covered by the new low watermark of the shard.
+func (c *Cache) BecomeLeader(shard metapb.Shard) {
+	c.resetShard(shard.ID)
+}
+
+// BecomeFollower implements aware.ShardStateAware
+func (c *Cache) BecomeFollower(shard metapb.Shard) {
+	c.resetShard(shard.ID)
+}
+
+// SnapshotApplied implements aware.ShardStateAware
+func (c *Cache) SnapshotApplied(metapb.Shard) {}
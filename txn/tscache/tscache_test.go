@@ -0,0 +1,118 @@
+// Copyright 2022 MatrixOrigin.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tscache
+
+import (
+	"testing"
+
+	"github.com/matrixorigin/matrixcube/pb/metapb"
+	"github.com/matrixorigin/matrixcube/pb/rpcpb"
+	"github.com/matrixorigin/matrixcube/pb/txnpb"
+	"github.com/stretchr/testify/assert"
+)
+
+type testClocker struct {
+	now, maxSkew uint64
+}
+
+func (c *testClocker) Now() (uint64, uint64)       { return c.now, c.maxSkew }
+func (c *testClocker) Compare(ts1, ts2 uint64) int { return int(ts1) - int(ts2) }
+func (c *testClocker) Next(ts uint64) uint64       { return ts + 1 }
+func (c *testClocker) Update(ts uint64)            {}
+
+func newTestRequest(shard uint64, rt txnpb.TxnRequestType, ts uint64, requests ...txnpb.TxnRequest) rpcpb.Request {
+	batch := txnpb.TxnBatchRequest{Requests: requests}
+	batch.Header.Type = rt
+	batch.Header.Txn.ReadTimestamp = ts
+	return rpcpb.Request{Type: rpcpb.Txn, ToShard: shard, TxnBatchRequest: &batch}
+}
+
+func newTestTxnRequest(op uint32, keys ...string) txnpb.TxnRequest {
+	req := txnpb.TxnRequest{Operation: txnpb.TxnOperation{Op: op}}
+	for _, key := range keys {
+		req.Operation.Impacted.PointKeys = append(req.Operation.Impacted.PointKeys, []byte(key))
+	}
+	return req
+}
+
+func TestCacheRecordReads(t *testing.T) {
+	clocker := &testClocker{now: 10, maxSkew: 5}
+	c := NewCache(clocker, 0, nil)
+
+	assert.Equal(t, uint64(15), c.GetMaxReadTimestamp(1, []byte("k1")))
+
+	read := newTestTxnRequest(uint32(txnpb.InternalTxnOp_Reserved)+1, "k1")
+	read.Operation.Impacted.AddKeyRanges([]txnpb.KeyRange{{Start: []byte("r1"), End: []byte("r3")}})
+	handled, err := c.HandleRequest(newTestRequest(1, txnpb.TxnRequestType_Read, 20, read), nil)
+	assert.NoError(t, err)
+	assert.False(t, handled)
+	handled, err = c.HandleRequest(newTestRequest(1, txnpb.TxnRequestType_Read, 18, read), nil)
+	assert.NoError(t, err)
+	assert.False(t, handled)
+
+	assert.Equal(t, uint64(20), c.GetMaxReadTimestamp(1, []byte("k1")))
+	assert.Equal(t, uint64(20), c.GetMaxReadTimestamp(1, []byte("r2")))
+	assert.Equal(t, uint64(15), c.GetMaxReadTimestamp(1, []byte("r3")))
+	assert.Equal(t, uint64(15), c.GetMaxReadTimestamp(1, []byte("k2")))
+
+	// the reads served by the previous leaders are unknown
+	clocker.now = 30
+	c.BecomeLeader(metapb.Shard{ID: 1})
+	assert.Equal(t, uint64(35), c.GetMaxReadTimestamp(1, []byte("k1")))
+}
+
+func TestCacheEvict(t *testing.T) {
+	c := NewCache(&testClocker{}, 2, nil)
+
+	read := newTestTxnRequest(uint32(txnpb.InternalTxnOp_Reserved)+1, "k1", "k2")
+	c.HandleRequest(newTestRequest(1, txnpb.TxnRequestType_Read, 10, read), nil)
+	assert.Equal(t, uint64(0), c.GetMaxReadTimestamp(1, []byte("k3")))
+
+	read = newTestTxnRequest(uint32(txnpb.InternalTxnOp_Reserved)+1, "k3")
+	c.HandleRequest(newTestRequest(1, txnpb.TxnRequestType_Read, 20, read), nil)
+	assert.Equal(t, uint64(20), c.GetMaxReadTimestamp(1, []byte("k4")))
+	assert.Equal(t, uint64(0), c.GetMaxReadTimestamp(2, []byte("k4")))
+}
+
+func TestCacheSetMaxReadTimestamps(t *testing.T) {
+	var served rpcpb.Request
+	c := NewCache(&testClocker{}, 0, func(req rpcpb.Request, cb func(rpcpb.ResponseBatch)) error {
+		served = req
+		resp := txnpb.TxnBatchResponse{Responses: make([]txnpb.TxnResponse, len(req.TxnBatchRequest.Requests))}
+		cb(rpcpb.ResponseBatch{Responses: []rpcpb.Response{{Type: rpcpb.Txn, TxnBatchResponse: &resp}}})
+		return nil
+	})
+
+	read := newTestTxnRequest(uint32(txnpb.InternalTxnOp_Reserved)+1, "k1")
+	c.HandleRequest(newTestRequest(1, txnpb.TxnRequestType_Read, 20, read), nil)
+
+	// the writes are left to the store
+	write := newTestTxnRequest(uint32(txnpb.InternalTxnOp_Reserved)+1, "k1")
+	handled, err := c.HandleRequest(newTestRequest(1, txnpb.TxnRequestType_Write, 20, write), nil)
+	assert.NoError(t, err)
+	assert.False(t, handled)
+
+	var resp rpcpb.ResponseBatch
+	req := newTestRequest(1, txnpb.TxnRequestType_Write, 10,
+		newTestTxnRequest(uint32(txnpb.InternalTxnOp_WaitConsensus), "k1"),
+		newTestTxnRequest(uint32(txnpb.InternalTxnOp_WaitConsensus), "k2"))
+	handled, err = c.HandleRequest(req, func(r rpcpb.ResponseBatch) {
+		resp = r
+	})
+	assert.NoError(t, err)
+	assert.True(t, handled)
+	assert.Equal(t, req, served)
+	assert.Equal(t, uint64(20), resp.Responses[0].TxnBatchResponse.Responses[0].MaxReadTimestamp)
+	assert.Equal(t, uint64(0), resp.Responses[0].TxnBatchResponse.Responses[1].MaxReadTimestamp)
+}
@@ -175,6 +175,12 @@ func (t *ShardTree) NextShard(start []byte) *metapb.Shard {
 
 // AscendRange asc iterator the tree in the range [start, end) until fn returns false
 func (t *ShardTree) AscendRange(start, end []byte, fn func(shard *metapb.Shard) bool) {
+	t.AscendRangeWithLimit(start, end, 0, fn)
+}
+
+// AscendRangeWithLimit asc iterator the tree in the range [start, end) until fn returns
+// false or limit shards are iterated. Zero limit means no limit.
+func (t *ShardTree) AscendRangeWithLimit(start, end []byte, limit int, fn func(shard *metapb.Shard) bool) {
 	t.RLock()
 	defer t.RUnlock()
 
@@ -182,15 +188,45 @@ func (t *ShardTree) AscendRange(start, end []byte, fn func(shard *metapb.Shard)
 	if startShard == nil {
 		return
 	}
+	count := 0
 	t.tree.DescendLessOrEqual(startShard, func(item btree.Item) bool {
 		if len(end) > 0 && bytes.Compare(item.(*ShardItem).Shard.Start, end) >= 0 {
 			return false
 		}
 
-		return fn(&item.(*ShardItem).Shard)
+		count++
+		return fn(&item.(*ShardItem).Shard) && (limit <= 0 || count < limit)
 	})
 }
 
+// ReverseRange desc iterator the tree in the range [start, end) until fn returns false
+// or limit shards are iterated. Zero limit means no limit. The iteration begins with the
+// last Shard which start key is less than end, and ends with the Shard contains start.
+func (t *ShardTree) ReverseRange(start, end []byte, limit int, fn func(shard *metapb.Shard) bool) {
+	t.RLock()
+	defer t.RUnlock()
+
+	count := 0
+	iter := func(item btree.Item) bool {
+		shard := &item.(*ShardItem).Shard
+		if len(end) > 0 && bytes.Compare(shard.Start, end) >= 0 {
+			return true
+		}
+		if len(shard.End) > 0 && bytes.Compare(shard.End, start) <= 0 {
+			return false
+		}
+
+		count++
+		return fn(shard) && (limit <= 0 || count < limit)
+	}
+
+	if len(end) == 0 {
+		t.tree.Ascend(iter)
+		return
+	}
+	t.tree.AscendGreaterOrEqual(&ShardItem{Shard: metapb.Shard{Start: end}}, iter)
+}
+
 // Search returns a Shard that contains the key.
 func (t *ShardTree) Search(key []byte) metapb.Shard {
 	shard := metapb.Shard{Start: key}
@@ -228,3 +228,81 @@ func TestAscendRange(t *testing.T) {
 		assert.Equal(t, c.expectShards, shards)
 	}
 }
+
+func TestAscendRangeWithLimit(t *testing.T) {
+	values := []metapb.Shard{
+		{ID: 1, Start: nil, End: []byte{5}},
+		{ID: 2, Start: []byte{5}, End: []byte{10}},
+		{ID: 3, Start: []byte{10}, End: nil},
+	}
+	tree := NewShardTree()
+	tree.Update(values...)
+
+	cases := []struct {
+		start        []byte
+		end          []byte
+		limit        int
+		expectShards []metapb.Shard
+	}{
+		{start: nil, end: nil, limit: 0, expectShards: values},
+		{start: nil, end: nil, limit: 1, expectShards: values[:1]},
+		{start: nil, end: nil, limit: 2, expectShards: values[:2]},
+		{start: nil, end: nil, limit: 4, expectShards: values},
+		{start: []byte{5}, end: nil, limit: 1, expectShards: values[1:2]},
+	}
+
+	for i, c := range cases {
+		var shards []metapb.Shard
+		tree.AscendRangeWithLimit(c.start, c.end, c.limit, func(shard *metapb.Shard) bool {
+			shards = append(shards, *shard)
+			return true
+		})
+		assert.Equal(t, c.expectShards, shards, "index %d", i)
+	}
+}
+
+func TestReverseRange(t *testing.T) {
+	values := []metapb.Shard{
+		{ID: 1, Start: nil, End: []byte{5}},
+		{ID: 2, Start: []byte{5}, End: []byte{10}},
+		{ID: 3, Start: []byte{10}, End: nil},
+	}
+	tree := NewShardTree()
+	tree.Update(values...)
+
+	cases := []struct {
+		start     []byte
+		end       []byte
+		limit     int
+		expectIDs []uint64
+	}{
+		{start: nil, end: nil, expectIDs: []uint64{3, 2, 1}},
+		{start: nil, end: []byte{5}, expectIDs: []uint64{1}},
+		{start: nil, end: []byte{6}, expectIDs: []uint64{2, 1}},
+		{start: []byte{5}, end: []byte{6}, expectIDs: []uint64{2}},
+		{start: []byte{6}, end: []byte{10}, expectIDs: []uint64{2}},
+		{start: []byte{4}, end: []byte{11}, expectIDs: []uint64{3, 2, 1}},
+		{start: []byte{5}, end: nil, expectIDs: []uint64{3, 2}},
+		{start: []byte{10}, end: nil, expectIDs: []uint64{3}},
+		{start: nil, end: nil, limit: 1, expectIDs: []uint64{3}},
+		{start: nil, end: []byte{6}, limit: 1, expectIDs: []uint64{2}},
+		{start: []byte{4}, end: []byte{11}, limit: 2, expectIDs: []uint64{3, 2}},
+	}
+
+	for i, c := range cases {
+		var ids []uint64
+		tree.ReverseRange(c.start, c.end, c.limit, func(shard *metapb.Shard) bool {
+			ids = append(ids, shard.ID)
+			return true
+		})
+		assert.Equal(t, c.expectIDs, ids, "index %d", i)
+	}
+
+	// stops if fn returns false
+	var ids []uint64
+	tree.ReverseRange(nil, nil, 0, func(shard *metapb.Shard) bool {
+		ids = append(ids, shard.ID)
+		return false
+	})
+	assert.Equal(t, []uint64{3}, ids)
+}
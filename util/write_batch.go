@@ -26,3 +26,18 @@ type WriteBatch interface {
 	// Close close the batch
 	Close()
 }
+
+// WriteBatchMerger is implemented by the write batches which can merge the
+// changes of another write batch of the same type.
+type WriteBatchMerger interface {
+	// Merge appends the changes of the other batch to the batch
+	Merge(other WriteBatch) error
+}
+
+// WriteBatchKeysIterator is implemented by the write batches which can iterate
+// the keys changed by the batch.
+type WriteBatchKeysIterator interface {
+	// IterateKeys invokes the handler on each key set or deleted by the batch,
+	// the end is only specified for the range deleted by DeleteRange.
+	IterateKeys(handler func(key, end []byte))
+}